@@ -60,7 +60,7 @@ func runServer(port int) (*server.Server, error) {
 		return nil, err
 	}
 
-	server, err := server.New(config, sessionToken, db, logger, "", nil)
+	server, err := server.New(config, sessionToken, db, logger, "", nil, nil)
 	if err != nil {
 		fmt.Println("ERROR INITIALIZING THE SERVER", err)
 		return nil, err
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	mmModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin"
+)
+
+const commandTrigger = "focalboard"
+
+const commandHelp = "* `/focalboard create <title>` - Create a card on the board of the channel, with #options, @users and a trailing \"due <date>\"\n" +
+	"* `/focalboard link <card number or link>` - Post a preview of a card\n" +
+	"* `/focalboard boards` - List the boards of the channel"
+
+// commandApp is the part of the app used by the slash command.
+type commandApp interface {
+	DoesUserHaveWorkspaceAccess(ctx context.Context, userID string, workspaceID string) bool
+	CheckBlockAccess(ctx context.Context, c store.Container, userID, blockID, role string) error
+	GetBoards(ctx context.Context, c store.Container, userID string, archived bool) ([]model.Block, error)
+	GetBoard(ctx context.Context, c store.Container, boardID string) (*model.Block, error)
+	GetCard(ctx context.Context, c store.Container, cardID string) (*model.Block, error)
+	GetCardByNumber(ctx context.Context, c store.Container, boardID string, number int64) (*model.Block, error)
+	QuickAddCard(ctx context.Context, c store.Container, boardID, text, userID string) (*model.Block, error)
+	CardPermalink(ctx context.Context, c store.Container, boardID, cardID string) string
+}
+
+// errCommand is an error whose message is shown to the user who ran the
+// command.
+type errCommand string
+
+func (e errCommand) Error() string {
+	return string(e)
+}
+
+func (p *Plugin) registerCommand() error {
+	autocomplete := mmModel.NewAutocompleteData(commandTrigger, "[command]", "Create and link the cards of the boards of the channel")
+	create := mmModel.NewAutocompleteData("create", "<title>", "Create a card on the board of the channel")
+	create.AddTextArgument("Title of the card, with #options, @users and a trailing \"due <date>\"", "<title>", "")
+	autocomplete.AddCommand(create)
+	link := mmModel.NewAutocompleteData("link", "<card number or link>", "Post a preview of a card")
+	link.AddTextArgument("Number of the card on the board of the channel, or link to the card", "<card number or link>", "")
+	autocomplete.AddCommand(link)
+	autocomplete.AddCommand(mmModel.NewAutocompleteData("boards", "", "List the boards of the channel"))
+
+	return p.API.RegisterCommand(&mmModel.Command{
+		Trigger:          commandTrigger,
+		DisplayName:      "Focalboard",
+		Description:      "Create and link the cards of the boards of the channel",
+		AutoComplete:     true,
+		AutoCompleteDesc: "Available commands: create, link, boards",
+		AutoCompleteHint: "[command]",
+		AutocompleteData: autocomplete,
+	})
+}
+
+// ExecuteCommand runs the /focalboard command in the workspace of the
+// channel. The errors are only shown to the user who ran it.
+func (p *Plugin) ExecuteCommand(_ *plugin.Context, args *mmModel.CommandArgs) (*mmModel.CommandResponse, *mmModel.AppError) {
+	fields := strings.Fields(args.Command)
+	if len(fields) == 0 || fields[0] != "/"+commandTrigger {
+		return ephemeralResponse(commandHelp), nil
+	}
+	subcommand, text := "", ""
+	if len(fields) > 1 {
+		subcommand = fields[1]
+		text = strings.Join(fields[2:], " ")
+	}
+
+	response, err := p.executeCommand(context.Background(), args, subcommand, text)
+	var commandErr errCommand
+	if errors.As(err, &commandErr) {
+		return ephemeralResponse(commandErr.Error()), nil
+	}
+	if err != nil {
+		p.API.LogError("Unable to execute the command", "command", args.Command, "error", err.Error())
+		return ephemeralResponse("Something went wrong, please try again."), nil
+	}
+	return response, nil
+}
+
+func (p *Plugin) executeCommand(ctx context.Context, args *mmModel.CommandArgs, subcommand, text string) (*mmModel.CommandResponse, error) {
+	switch subcommand {
+	case "create", "link", "boards":
+	default:
+		return ephemeralResponse(commandHelp), nil
+	}
+
+	c, err := p.commandContainer(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	switch subcommand {
+	case "create":
+		return p.executeCreate(ctx, c, args.UserId, text)
+	case "link":
+		return p.executeLink(ctx, c, args.UserId, text)
+	default:
+		return p.executeBoards(ctx, c, args.UserId)
+	}
+}
+
+// commandContainer returns the workspace of the channel the command was
+// run in, which the user must have access to.
+func (p *Plugin) commandContainer(ctx context.Context, args *mmModel.CommandArgs) (store.Container, error) {
+	channel, appErr := p.API.GetChannel(args.ChannelId)
+	if appErr != nil {
+		return store.Container{}, fmt.Errorf("unable to get the channel: %w", appErr)
+	}
+	if !p.app.DoesUserHaveWorkspaceAccess(ctx, args.UserId, channel.Id) {
+		return store.Container{}, errCommand("You don't have access to the boards of this channel.")
+	}
+	return store.Container{WorkspaceID: channel.Id}, nil
+}
+
+func (p *Plugin) executeCreate(ctx context.Context, c store.Container, userID, text string) (*mmModel.CommandResponse, error) {
+	if text == "" {
+		return nil, errCommand("Please enter the title of the card: `/focalboard create <title>`")
+	}
+	board, err := p.defaultBoard(ctx, c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	card, err := p.app.QuickAddCard(ctx, c, board.ID, text, userID)
+	if errors.Is(err, app.ErrEmptyQuickAddTitle) {
+		return nil, errCommand("Please enter the title of the card, besides its properties.")
+	}
+	if errors.Is(err, app.ErrBoardAccessDenied) {
+		return nil, errCommand(fmt.Sprintf("You can't add cards to %s.", boardTitle(*board)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	link := p.app.CardPermalink(ctx, c, board.ID, card.ID)
+	return ephemeralResponse(fmt.Sprintf("Created [%s](%s) on %s.", cardTitle(*card), link, boardTitle(*board))), nil
+}
+
+func (p *Plugin) executeLink(ctx context.Context, c store.Container, userID, text string) (*mmModel.CommandResponse, error) {
+	if text == "" {
+		return nil, errCommand("Please enter the number of the card, or its link: `/focalboard link <card number or link>`")
+	}
+
+	var card *model.Block
+	if number, err := strconv.ParseInt(strings.TrimPrefix(text, "#"), 10, 64); err == nil {
+		board, err := p.defaultBoard(ctx, c, userID)
+		if err != nil {
+			return nil, err
+		}
+		if card, err = p.app.GetCardByNumber(ctx, c, board.ID, number); err != nil {
+			return nil, err
+		}
+		if card == nil {
+			return nil, errCommand("Card not found.")
+		}
+	} else {
+		var cardID string
+		c, cardID = parseCardLink(c, text)
+		if cardID == "" {
+			return nil, errCommand("Please enter the number of the card, or its link.")
+		}
+		card = &model.Block{ID: cardID}
+	}
+
+	board, card, err := p.getViewableCard(ctx, c, userID, card.ID)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, errCommand("Card not found.")
+	}
+
+	return &mmModel.CommandResponse{
+		ResponseType: mmModel.CommandResponseTypeInChannel,
+		Attachments:  []*mmModel.SlackAttachment{p.cardPreview(ctx, c, *board, *card, boardProperties(*board))},
+	}, nil
+}
+
+// getViewableCard returns the card of the workspace and its board, or nil
+// if the user can't view them.
+func (p *Plugin) getViewableCard(ctx context.Context, c store.Container, userID, cardID string) (*model.Block, *model.Block, error) {
+	if !p.app.DoesUserHaveWorkspaceAccess(ctx, userID, c.WorkspaceID) {
+		return nil, nil, nil
+	}
+	card, err := p.app.GetCard(ctx, c, cardID)
+	if err != nil || card == nil {
+		return nil, nil, err
+	}
+
+	err = p.app.CheckBlockAccess(ctx, c, userID, card.RootID, model.BoardRoleViewer)
+	if errors.Is(err, app.ErrBoardAccessDenied) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	board, err := p.app.GetBoard(ctx, c, card.RootID)
+	if err != nil || board == nil {
+		return nil, nil, err
+	}
+	return board, card, nil
+}
+
+func (p *Plugin) executeBoards(ctx context.Context, c store.Container, userID string) (*mmModel.CommandResponse, error) {
+	boards, err := p.app.GetBoards(ctx, c, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(boards) == 0 {
+		return ephemeralResponse("This channel has no boards yet."), nil
+	}
+	sortBoards(boards)
+
+	lines := []string{"Boards of this channel:"}
+	for i, board := range boards {
+		line := fmt.Sprintf("* [%s](%s)", boardTitle(board), p.boardPermalink(c, board.ID))
+		if i == 0 {
+			line += " (default)"
+		}
+		lines = append(lines, line)
+	}
+	return ephemeralResponse(strings.Join(lines, "\n")), nil
+}
+
+// defaultBoard returns the board of the channel the cards are created on,
+// its first board the user can view.
+func (p *Plugin) defaultBoard(ctx context.Context, c store.Container, userID string) (*model.Block, error) {
+	boards, err := p.app.GetBoards(ctx, c, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(boards) == 0 {
+		return nil, errCommand("This channel has no boards yet.")
+	}
+	sortBoards(boards)
+	return &boards[0], nil
+}
+
+// sortBoards sorts the boards in the order of their creation.
+func sortBoards(boards []model.Block) {
+	sort.SliceStable(boards, func(i, j int) bool {
+		if boards[i].CreateAt != boards[j].CreateAt {
+			return boards[i].CreateAt < boards[j].CreateAt
+		}
+		return boards[i].ID < boards[j].ID
+	})
+}
+
+// parseCardLink returns the workspace and the ID of the card of a link
+// like <root>[/workspace/<workspaceID>]/<boardID>/<viewID>/<cardID>, the
+// workspace defaulting to the one of the channel.
+func parseCardLink(c store.Container, link string) (store.Container, string) {
+	u, err := url.Parse(strings.Trim(link, "<>"))
+	if err != nil || u.Path == "" {
+		return c, ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == "workspace" {
+			c = store.Container{WorkspaceID: segments[i+1]}
+			segments = segments[i+2:]
+			break
+		}
+	}
+	if len(segments) < 3 {
+		return c, ""
+	}
+	return c, segments[len(segments)-1]
+}
+
+func (p *Plugin) boardPermalink(c store.Container, boardID string) string {
+	link := p.serverRoot
+	if c.WorkspaceID != "0" {
+		link += "/workspace/" + c.WorkspaceID
+	}
+	return fmt.Sprintf("%s/%s", link, boardID)
+}
+
+// cardPreview returns an attachment with the title of the card, its board
+// and the values of the properties, in their order.
+func (p *Plugin) cardPreview(ctx context.Context, c store.Container, board, card model.Block, properties []map[string]interface{}) *mmModel.SlackAttachment {
+	link := p.app.CardPermalink(ctx, c, board.ID, card.ID)
+	attachment := &mmModel.SlackAttachment{
+		Fallback:  fmt.Sprintf("%s (%s)", cardTitle(card), link),
+		Title:     cardTitle(card),
+		TitleLink: link,
+		Text:      fmt.Sprintf("Card of [%s](%s)", boardTitle(board), p.boardPermalink(c, board.ID)),
+	}
+
+	values, _ := card.Fields["properties"].(map[string]interface{})
+	for _, property := range properties {
+		id, _ := property["id"].(string)
+		value := p.propertyValue(property, values[id])
+		if value == "" {
+			continue
+		}
+		name, _ := property["name"].(string)
+		attachment.Fields = append(attachment.Fields, &mmModel.SlackAttachmentField{Title: name, Value: value, Short: true})
+	}
+	return attachment
+}
+
+// boardProperties returns the card properties of the board.
+func boardProperties(board model.Block) []map[string]interface{} {
+	properties := []map[string]interface{}{}
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		if property, ok := item.(map[string]interface{}); ok {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// propertyValue returns the value of a property of a card as text, the
+// options by name, the users by username and the dates formatted.
+func (p *Plugin) propertyValue(property map[string]interface{}, value interface{}) string {
+	options := map[string]string{}
+	items, _ := property["options"].([]interface{})
+	for _, item := range items {
+		if option, ok := item.(map[string]interface{}); ok {
+			id, _ := option["id"].(string)
+			options[id], _ = option["value"].(string)
+		}
+	}
+
+	propertyType, _ := property["type"].(string)
+	switch propertyType {
+	case "select":
+		id, _ := value.(string)
+		return options[id]
+	case "multiSelect":
+		ids, _ := value.([]interface{})
+		names := []string{}
+		for _, id := range ids {
+			if id, ok := id.(string); ok && options[id] != "" {
+				names = append(names, options[id])
+			}
+		}
+		return strings.Join(names, ", ")
+	case "person":
+		userID, _ := value.(string)
+		if userID == "" {
+			return ""
+		}
+		user, appErr := p.API.GetUser(userID)
+		if appErr != nil {
+			return ""
+		}
+		return "@" + user.Username
+	case "date":
+		text, _ := value.(string)
+		var date struct {
+			From int64 `json:"from"`
+		}
+		if err := json.Unmarshal([]byte(text), &date); err != nil || date.From == 0 {
+			return ""
+		}
+		return time.Unix(0, date.From*int64(time.Millisecond)).UTC().Format("January 2, 2006")
+	}
+	text, _ := value.(string)
+	return text
+}
+
+func cardTitle(card model.Block) string {
+	return reminderCardTitle(card.Title)
+}
+
+func boardTitle(board model.Block) string {
+	if board.Title == "" {
+		return "Untitled board"
+	}
+	return board.Title
+}
+
+func ephemeralResponse(text string) *mmModel.CommandResponse {
+	return &mmModel.CommandResponse{
+		ResponseType: mmModel.CommandResponseTypeEphemeral,
+		Text:         text,
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mmModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+)
+
+// testCommandApp is an app with the blocks of the workspaces the users
+// have access to.
+type testCommandApp struct {
+	access     map[string][]string
+	blocks     map[string][]model.Block
+	restricted map[string]bool
+	created    []string
+	cardGets   int
+}
+
+func (a *testCommandApp) DoesUserHaveWorkspaceAccess(_ context.Context, userID string, workspaceID string) bool {
+	for _, id := range a.access[userID] {
+		if id == workspaceID {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *testCommandApp) CheckBlockAccess(_ context.Context, _ store.Container, _, blockID, _ string) error {
+	if a.restricted[blockID] {
+		return app.ErrBoardAccessDenied
+	}
+	return nil
+}
+
+func (a *testCommandApp) GetBoards(_ context.Context, c store.Container, _ string, _ bool) ([]model.Block, error) {
+	boards := []model.Block{}
+	for _, block := range a.blocks[c.WorkspaceID] {
+		if block.Type == "board" {
+			boards = append(boards, block)
+		}
+	}
+	return boards, nil
+}
+
+func (a *testCommandApp) getBlock(c store.Container, blockID, blockType string) *model.Block {
+	for _, block := range a.blocks[c.WorkspaceID] {
+		if block.ID == blockID && block.Type == blockType {
+			block := block
+			return &block
+		}
+	}
+	return nil
+}
+
+func (a *testCommandApp) GetBoard(_ context.Context, c store.Container, boardID string) (*model.Block, error) {
+	return a.getBlock(c, boardID, "board"), nil
+}
+
+func (a *testCommandApp) GetCard(_ context.Context, c store.Container, cardID string) (*model.Block, error) {
+	a.cardGets++
+	return a.getBlock(c, cardID, "card"), nil
+}
+
+func (a *testCommandApp) GetCardByNumber(_ context.Context, c store.Container, boardID string, number int64) (*model.Block, error) {
+	for _, block := range a.blocks[c.WorkspaceID] {
+		if n, ok := model.CardNumber(block); ok && n == number && block.Type == "card" && block.RootID == boardID {
+			block := block
+			return &block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (a *testCommandApp) QuickAddCard(_ context.Context, _ store.Container, boardID, text, _ string) (*model.Block, error) {
+	if text == "#urgent" {
+		return nil, app.ErrEmptyQuickAddTitle
+	}
+	a.created = append(a.created, boardID+": "+text)
+	return &model.Block{ID: "new-card", RootID: boardID, ParentID: boardID, Type: "card", Title: text}, nil
+}
+
+func (a *testCommandApp) CardPermalink(_ context.Context, c store.Container, boardID, cardID string) string {
+	return "http://localhost/workspace/" + c.WorkspaceID + "/" + boardID + "/view-1/" + cardID
+}
+
+func setupCommandTest(t *testing.T) (*Plugin, *plugintest.API, *testCommandApp) {
+	api := &plugintest.API{}
+	t.Cleanup(func() { api.AssertExpectations(t) })
+
+	commandApp := &testCommandApp{
+		access: map[string][]string{"user-1": {"channel-1", "channel-2"}},
+		blocks: map[string][]model.Block{
+			"channel-1": {
+				{ID: "board-2", RootID: "board-2", Type: "board", Title: "Later", CreateAt: 2},
+				{ID: "board-1", RootID: "board-1", Type: "board", Title: "Roadmap", CreateAt: 1, Fields: map[string]interface{}{
+					"cardProperties": []interface{}{
+						map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+							map[string]interface{}{"id": "done", "value": "Done"},
+						}},
+						map[string]interface{}{"id": "owner", "name": "Owner", "type": "person"},
+						map[string]interface{}{"id": "due", "name": "Due", "type": "date"},
+						map[string]interface{}{"id": "empty", "name": "Empty", "type": "text"},
+					},
+				}},
+				{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card", Title: "Fix login bug", Fields: map[string]interface{}{
+					model.CardNumberField: float64(3),
+					"properties": map[string]interface{}{
+						"status": "done",
+						"owner":  "sara-id",
+						"due":    `{"from":1646352000000}`,
+					},
+				}},
+			},
+			"channel-2": {
+				{ID: "board-3", RootID: "board-3", Type: "board", Title: "Private"},
+				{ID: "card-2", RootID: "board-3", ParentID: "board-3", Type: "card", Title: "Secret"},
+			},
+			"channel-3": {
+				{ID: "board-4", RootID: "board-4", Type: "board", Title: "Other"},
+				{ID: "card-3", RootID: "board-4", ParentID: "board-4", Type: "card", Title: "Other card"},
+			},
+		},
+		restricted: map[string]bool{"board-3": true},
+	}
+
+	p := &Plugin{app: commandApp, serverRoot: "http://localhost"}
+	p.SetAPI(api)
+	return p, api, commandApp
+}
+
+func executeCommand(t *testing.T, p *Plugin, channelID, command string) *mmModel.CommandResponse {
+	response, appErr := p.ExecuteCommand(nil, &mmModel.CommandArgs{UserId: "user-1", ChannelId: channelID, Command: command})
+	require.Nil(t, appErr)
+	require.NotNil(t, response)
+	return response
+}
+
+func expectChannel(api *plugintest.API, channelID string) {
+	api.On("GetChannel", channelID).Return(&mmModel.Channel{Id: channelID}, nil)
+}
+
+func TestRegisterCommand(t *testing.T) {
+	p, api, _ := setupCommandTest(t)
+	api.On("RegisterCommand", mock.MatchedBy(func(command *mmModel.Command) bool {
+		return command.Trigger == commandTrigger && command.AutoComplete && len(command.AutocompleteData.SubCommands) == 3
+	})).Return(nil)
+
+	require.NoError(t, p.registerCommand())
+}
+
+func TestExecuteCommand(t *testing.T) {
+	t.Run("should show the help", func(t *testing.T) {
+		p, _, _ := setupCommandTest(t)
+		for _, command := range []string{"/focalboard", "/focalboard help", "/focalboard unknown"} {
+			response := executeCommand(t, p, "channel-1", command)
+			require.Equal(t, mmModel.CommandResponseTypeEphemeral, response.ResponseType)
+			require.Equal(t, commandHelp, response.Text)
+		}
+	})
+
+	t.Run("should fail without access to the channel's workspace", func(t *testing.T) {
+		p, api, _ := setupCommandTest(t)
+		expectChannel(api, "channel-3")
+
+		response := executeCommand(t, p, "channel-3", "/focalboard boards")
+		require.Equal(t, mmModel.CommandResponseTypeEphemeral, response.ResponseType)
+		require.Equal(t, "You don't have access to the boards of this channel.", response.Text)
+	})
+
+	t.Run("should fail if the channel can't be found", func(t *testing.T) {
+		p, api, _ := setupCommandTest(t)
+		api.On("GetChannel", "missing").Return(nil, mmModel.NewAppError("GetChannel", "not_found", nil, "", 404))
+		api.On("LogError", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+		response := executeCommand(t, p, "missing", "/focalboard boards")
+		require.Equal(t, mmModel.CommandResponseTypeEphemeral, response.ResponseType)
+		require.Equal(t, "Something went wrong, please try again.", response.Text)
+	})
+}
+
+func TestExecuteBoardsCommand(t *testing.T) {
+	p, api, commandApp := setupCommandTest(t)
+	expectChannel(api, "channel-1")
+
+	response := executeCommand(t, p, "channel-1", "/focalboard boards")
+	require.Equal(t, mmModel.CommandResponseTypeEphemeral, response.ResponseType)
+	require.Equal(t, "Boards of this channel:\n"+
+		"* [Roadmap](http://localhost/workspace/channel-1/board-1) (default)\n"+
+		"* [Later](http://localhost/workspace/channel-1/board-2)", response.Text)
+
+	commandApp.access["user-1"] = append(commandApp.access["user-1"], "channel-4")
+	expectChannel(api, "channel-4")
+	response = executeCommand(t, p, "channel-4", "/focalboard boards")
+	require.Equal(t, "This channel has no boards yet.", response.Text)
+}
+
+func TestExecuteCreateCommand(t *testing.T) {
+	t.Run("should create the card on the default board", func(t *testing.T) {
+		p, api, commandApp := setupCommandTest(t)
+		expectChannel(api, "channel-1")
+
+		response := executeCommand(t, p, "channel-1", "/focalboard create Fix  login #urgent due friday")
+		require.Equal(t, mmModel.CommandResponseTypeEphemeral, response.ResponseType)
+		require.Equal(t, "Created [Fix login #urgent due friday](http://localhost/workspace/channel-1/board-1/view-1/new-card) on Roadmap.", response.Text)
+		require.Equal(t, []string{"board-1: Fix login #urgent due friday"}, commandApp.created)
+	})
+
+	t.Run("should fail without a title", func(t *testing.T) {
+		p, api, commandApp := setupCommandTest(t)
+		expectChannel(api, "channel-1")
+
+		response := executeCommand(t, p, "channel-1", "/focalboard create")
+		require.Equal(t, mmModel.CommandResponseTypeEphemeral, response.ResponseType)
+		require.Contains(t, response.Text, "Please enter the title of the card")
+
+		response = executeCommand(t, p, "channel-1", "/focalboard create #urgent")
+		require.Equal(t, "Please enter the title of the card, besides its properties.", response.Text)
+		require.Empty(t, commandApp.created)
+	})
+
+	t.Run("should fail without a board", func(t *testing.T) {
+		p, api, commandApp := setupCommandTest(t)
+		commandApp.access["user-1"] = append(commandApp.access["user-1"], "channel-4")
+		expectChannel(api, "channel-4")
+
+		response := executeCommand(t, p, "channel-4", "/focalboard create Fix login")
+		require.Equal(t, mmModel.CommandResponseTypeEphemeral, response.ResponseType)
+		require.Equal(t, "This channel has no boards yet.", response.Text)
+	})
+}
+
+func TestExecuteLinkCommand(t *testing.T) {
+	t.Run("should post the preview of the card by number", func(t *testing.T) {
+		p, api, _ := setupCommandTest(t)
+		expectChannel(api, "channel-1")
+		api.On("GetUser", "sara-id").Return(&mmModel.User{Id: "sara-id", Username: "sara"}, nil)
+
+		response := executeCommand(t, p, "channel-1", "/focalboard link #3")
+		require.Equal(t, mmModel.CommandResponseTypeInChannel, response.ResponseType)
+		require.Len(t, response.Attachments, 1)
+		attachment := response.Attachments[0]
+		require.Equal(t, "Fix login bug", attachment.Title)
+		require.Equal(t, "http://localhost/workspace/channel-1/board-1/view-1/card-1", attachment.TitleLink)
+		require.Equal(t, "Card of [Roadmap](http://localhost/workspace/channel-1/board-1)", attachment.Text)
+		require.Equal(t, []*mmModel.SlackAttachmentField{
+			{Title: "Status", Value: "Done", Short: true},
+			{Title: "Owner", Value: "@sara", Short: true},
+			{Title: "Due", Value: "March 4, 2022", Short: true},
+		}, attachment.Fields)
+	})
+
+	t.Run("should post the preview of the card by link", func(t *testing.T) {
+		p, api, _ := setupCommandTest(t)
+		expectChannel(api, "channel-1")
+		api.On("GetUser", "sara-id").Return(&mmModel.User{Id: "sara-id", Username: "sara"}, nil)
+
+		response := executeCommand(t, p, "channel-1", "/focalboard link http://localhost/plugins/focalboard/workspace/channel-1/board-1/view-1/card-1")
+		require.Equal(t, mmModel.CommandResponseTypeInChannel, response.ResponseType)
+		require.Equal(t, "Fix login bug", response.Attachments[0].Title)
+	})
+
+	t.Run("should not post the cards the user can't view", func(t *testing.T) {
+		p, api, _ := setupCommandTest(t)
+		expectChannel(api, "channel-1")
+
+		for _, command := range []string{
+			"/focalboard link #4",
+			"/focalboard link http://localhost/workspace/channel-1/board-1/view-1/missing",
+			"/focalboard link http://localhost/workspace/channel-2/board-3/view-1/card-2",
+			"/focalboard link http://localhost/workspace/channel-3/board-4/view-1/card-3",
+		} {
+			response := executeCommand(t, p, "channel-1", command)
+			require.Equal(t, mmModel.CommandResponseTypeEphemeral, response.ResponseType, command)
+			require.Equal(t, "Card not found.", response.Text, command)
+		}
+	})
+
+	t.Run("should fail with an invalid link", func(t *testing.T) {
+		p, api, _ := setupCommandTest(t)
+		expectChannel(api, "channel-1")
+
+		response := executeCommand(t, p, "channel-1", "/focalboard link card-1")
+		require.Equal(t, mmModel.CommandResponseTypeEphemeral, response.ResponseType)
+		require.Equal(t, "Please enter the number of the card, or its link.", response.Text)
+	})
+}
+
+func TestParseCardLink(t *testing.T) {
+	c := store.Container{WorkspaceID: "channel-1"}
+	testCases := []struct {
+		link        string
+		workspaceID string
+		cardID      string
+	}{
+		{"http://localhost/plugins/focalboard/workspace/channel-2/board-1/view-1/card-1", "channel-2", "card-1"},
+		{"<http://localhost/boards/workspace/channel-2/board-1/view-1/card-1>", "channel-2", "card-1"},
+		{"http://localhost/board-1/view-1/card-1", "channel-1", "card-1"},
+		{"http://localhost/workspace/channel-2/board-1", "channel-2", ""},
+		{"card-1", "channel-1", ""},
+		{"", "channel-1", ""},
+	}
+	for _, tc := range testCases {
+		container, cardID := parseCardLink(c, tc.link)
+		require.Equal(t, tc.workspaceID, container.WorkspaceID, tc.link)
+		require.Equal(t, tc.cardID, cardID, tc.link)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/notify"
+
+	mmModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin"
+)
+
+const (
+	botUsername    = "boards"
+	botDisplayName = "Boards"
+	botDescription = "Created by the Boards plugin."
+)
+
+// notifier posts the mentions, due date reminders, changes of the
+// watched blocks and notices of the deletion of the inactive workspaces
+// as direct messages from the plugin bot.
+type notifier struct {
+	api   plugin.API
+	botID string
+}
+
+func newNotifier(api plugin.API, botID string) *notifier {
+	return &notifier{
+		api:   api,
+		botID: botID,
+	}
+}
+
+func (n *notifier) NotifyMention(mention notify.Mention) error {
+	author := "Someone"
+	if mention.AuthorUsername != "" {
+		author = "@" + mention.AuthorUsername
+	}
+
+	message := fmt.Sprintf("%s mentioned you in a [card](%s):\n> %s", author, mention.Permalink, mention.Message)
+	return n.postDirectMessage(mention.UserID, message)
+}
+
+func (n *notifier) NotifyDueDate(reminder notify.DueDateReminder) error {
+	dueAt := time.Unix(0, reminder.DueAt*int64(time.Millisecond)).UTC()
+	message := fmt.Sprintf("[%s](%s) is due on %s.", reminderCardTitle(reminder.CardTitle), reminder.Permalink, dueAt.Format("January 2, 2006 15:04 MST"))
+	return n.postDirectMessage(reminder.UserID, message)
+}
+
+func (n *notifier) NotifyBlockChange(change notify.BlockChange) error {
+	author := "Someone"
+	if change.AuthorUsername != "" {
+		author = "@" + change.AuthorUsername
+	}
+
+	message := fmt.Sprintf("%s changed [%s](%s), which you are watching.", author, reminderCardTitle(change.Title), change.Permalink)
+	return n.postDirectMessage(change.UserID, message)
+}
+
+func (n *notifier) NotifyWorkspaceDeletion(deletion notify.WorkspaceDeletion) error {
+	lastActivityAt := time.Unix(0, deletion.LastActivityAt*int64(time.Millisecond)).UTC()
+	deleteAt := time.Unix(0, deletion.DeleteAt*int64(time.Millisecond)).UTC()
+	message := fmt.Sprintf("The boards of a team you belong to haven't been updated since %s. They will be archived and deleted on %s, unless they are updated before.",
+		lastActivityAt.Format("January 2, 2006"), deleteAt.Format("January 2, 2006 15:04 MST"))
+	return n.postDirectMessage(deletion.UserID, message)
+}
+
+func (n *notifier) postDirectMessage(userID, message string) error {
+	channel, appErr := n.api.GetDirectChannel(n.botID, userID)
+	if appErr != nil {
+		return fmt.Errorf("unable to get the direct channel: %w", appErr)
+	}
+
+	post := &mmModel.Post{
+		UserId:    n.botID,
+		ChannelId: channel.Id,
+		Message:   message,
+	}
+	if _, appErr := n.api.CreatePost(post); appErr != nil {
+		return fmt.Errorf("unable to post the direct message: %w", appErr)
+	}
+
+	return nil
+}
+
+func reminderCardTitle(title string) string {
+	if title == "" {
+		return "Untitled card"
+	}
+	return title
+}
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"database/sql"
 	"fmt"
 	"net/http"
 	"path"
 	"sync"
+	"time"
 
 	"github.com/mattermost/focalboard/server/auth"
 	"github.com/mattermost/focalboard/server/server"
@@ -34,6 +36,12 @@ type Plugin struct {
 
 	server          *server.Server
 	wsPluginAdapter *ws.PluginAdapter
+
+	// app and serverRoot are used by the slash command and the link
+	// previews
+	app         commandApp
+	serverRoot  string
+	unfurlCache *unfurlCache
 }
 
 func (p *Plugin) OnActivate() error {
@@ -115,13 +123,27 @@ func (p *Plugin) OnActivate() error {
 		LocalOnly:               false,
 		EnableLocalMode:         false,
 		LocalModeSocketLocation: "",
+		TrashRetentionDays:      30,
+		DueDatePropertyName:     config.DefaultDueDatePropertyName,
+		FileRetentionDays:       config.DefaultFileRetentionDays,
+		MaxFileSize:             *mmconfig.FileSettings.MaxFileSize,
 		AuthMode:                "mattermost",
 	}
-	var db store.Store
-	db, err = sqlstore.New(cfg.DBType, cfg.DBConfigString, cfg.DBTablePrefix, logger, sqlDB, true)
+	sqlStore, err := sqlstore.New(cfg.DBType, cfg.DBConfigString, cfg.DBTablePrefix, logger, sqlDB, true)
+	if err != nil {
+		return fmt.Errorf("error initializing the DB: %w", err)
+	}
+
+	// the master is returned when no replica is configured
+	replicaDB, err := client.Store.GetReplicaDB()
 	if err != nil {
 		return fmt.Errorf("error initializing the DB: %w", err)
 	}
+	if replicaDB != sqlDB {
+		sqlStore.SetReplicas([]*sql.DB{replicaDB}, config.DefaultDBReplicaForcePrimaryWindow*time.Second)
+	}
+
+	var db store.Store = sqlStore
 	if cfg.AuthMode == server.MattermostAuthMod {
 		layeredStore, err2 := mattermostauthlayer.New(cfg.DBType, sqlDB, db, logger)
 		if err2 != nil {
@@ -129,17 +151,38 @@ func (p *Plugin) OnActivate() error {
 		}
 		db = layeredStore
 	}
+	db = newPreferencesStore(db, p.API)
 
 	p.wsPluginAdapter = ws.NewPluginAdapter(p.API, auth.New(cfg, db))
+	p.unfurlCache = newUnfurlCache()
+	wsAdapter := &unfurlCacheAdapter{PluginAdapter: p.wsPluginAdapter, cache: p.unfurlCache}
+
+	botID, err := client.Bot.EnsureBot(&mmModel.Bot{
+		Username:    botUsername,
+		DisplayName: botDisplayName,
+		Description: botDescription,
+	})
+	if err != nil {
+		return fmt.Errorf("error ensuring the bot: %w", err)
+	}
 
-	server, err := server.New(cfg, "", db, logger, serverID, p.wsPluginAdapter)
+	server, err := server.New(cfg, "", db, logger, serverID, wsAdapter, newNotifier(p.API, botID))
 	if err != nil {
 		fmt.Println("ERROR INITIALIZING THE SERVER", err)
 		return err
 	}
 
 	p.server = server
-	return server.Start()
+	p.app = server.App()
+	p.serverRoot = cfg.ServerRoot
+	if err := server.Start(); err != nil {
+		return err
+	}
+
+	if err := p.registerCommand(); err != nil {
+		return fmt.Errorf("error registering the command: %w", err)
+	}
+	return nil
 }
 
 func (p *Plugin) OnWebSocketConnect(webConnID, userID string) {
@@ -154,6 +197,10 @@ func (p *Plugin) WebSocketMessageHasBeenPosted(webConnID, userID string, req *mm
 	p.wsPluginAdapter.WebSocketMessageHasBeenPosted(webConnID, userID, req)
 }
 
+func (p *Plugin) OnPluginClusterEvent(_ *plugin.Context, ev mmModel.PluginClusterEvent) {
+	p.wsPluginAdapter.HandleClusterEvent(ev)
+}
+
 func (p *Plugin) OnDeactivate() error {
 	return p.server.Shutdown()
 }
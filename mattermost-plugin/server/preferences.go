@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	mmModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin"
+)
+
+// preferencesCategoryPrefix namespaces the categories of the preferences
+// of the boards among the ones of Mattermost.
+const preferencesCategoryPrefix = "focalboard_"
+
+// preferencesStore is the store of the plugin, whose preferences of the
+// users are kept by Mattermost with the other preferences of the users,
+// rather than in the preferences table.
+type preferencesStore struct {
+	store.Store
+	api plugin.API
+}
+
+func newPreferencesStore(db store.Store, api plugin.API) *preferencesStore {
+	return &preferencesStore{
+		Store: db,
+		api:   api,
+	}
+}
+
+func (s *preferencesStore) GetPreferences(_ context.Context, userID string) ([]model.Preference, error) {
+	mmPreferences, appErr := s.api.GetPreferencesForUser(userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	preferences := []model.Preference{}
+	for _, mmPreference := range mmPreferences {
+		if !strings.HasPrefix(mmPreference.Category, preferencesCategoryPrefix) {
+			continue
+		}
+		preferences = append(preferences, model.Preference{
+			UserID:   mmPreference.UserId,
+			Category: strings.TrimPrefix(mmPreference.Category, preferencesCategoryPrefix),
+			Name:     mmPreference.Name,
+			Value:    mmPreference.Value,
+		})
+	}
+	return preferences, nil
+}
+
+func (s *preferencesStore) UpsertPreferences(_ context.Context, userID string, preferences []model.Preference) error {
+	if appErr := s.api.UpdatePreferencesForUser(userID, mmPreferences(userID, preferences)); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+func (s *preferencesStore) DeletePreferences(_ context.Context, userID string, preferences []model.Preference) error {
+	if appErr := s.api.DeletePreferencesForUser(userID, mmPreferences(userID, preferences)); appErr != nil {
+		return appErr
+	}
+	return nil
+}
+
+// mmPreferences returns the Mattermost preferences of the preferences of
+// the user, in the namespace of the boards.
+func mmPreferences(userID string, preferences []model.Preference) []mmModel.Preference {
+	mmPreferences := make([]mmModel.Preference, 0, len(preferences))
+	for _, preference := range preferences {
+		mmPreferences = append(mmPreferences, mmModel.Preference{
+			UserId:   userID,
+			Category: preferencesCategoryPrefix + preference.Category,
+			Name:     preference.Name,
+			Value:    preference.Value,
+		})
+	}
+	return mmPreferences
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+
+	mmModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+)
+
+func TestPreferencesStore(t *testing.T) {
+	ctx := context.Background()
+	api := &plugintest.API{}
+	t.Cleanup(func() { api.AssertExpectations(t) })
+	s := newPreferencesStore(nil, api)
+
+	t.Run("should only return the preferences of the boards", func(t *testing.T) {
+		api.On("GetPreferencesForUser", "user-1").Return([]mmModel.Preference{
+			{UserId: "user-1", Category: "display_settings", Name: "use_military_time", Value: "true"},
+			{UserId: "user-1", Category: "focalboard_client_display", Name: "theme", Value: "dark"},
+		}, nil).Once()
+
+		preferences, err := s.GetPreferences(ctx, "user-1")
+		require.NoError(t, err)
+		require.Equal(t, []model.Preference{
+			{UserID: "user-1", Category: "client_display", Name: "theme", Value: "dark"},
+		}, preferences)
+	})
+
+	t.Run("should set and delete the preferences in the namespace of the boards", func(t *testing.T) {
+		expected := []mmModel.Preference{
+			{UserId: "user-1", Category: "focalboard_client_tours", Name: "onboarding", Value: "dismissed"},
+		}
+		api.On("UpdatePreferencesForUser", "user-1", expected).Return(nil).Once()
+		api.On("DeletePreferencesForUser", "user-1", expected).Return(nil).Once()
+
+		preferences := []model.Preference{{Category: "client_tours", Name: "onboarding", Value: "dismissed"}}
+		require.NoError(t, s.UpsertPreferences(ctx, "user-1", preferences))
+		require.NoError(t, s.DeletePreferences(ctx, "user-1", preferences))
+	})
+
+	t.Run("should return the errors of Mattermost", func(t *testing.T) {
+		api.On("GetPreferencesForUser", "user-2").Return(nil, mmModel.NewAppError("GetPreferencesForUser", "app.preference.get_all.app_error", nil, "", 500)).Once()
+
+		_, err := s.GetPreferences(ctx, "user-2")
+		require.Error(t, err)
+	})
+}
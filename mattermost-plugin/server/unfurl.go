@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/ws"
+
+	mmModel "github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin"
+)
+
+const (
+	// unfurlCacheTTL is how long the previews of the cards are cached,
+	// for the changes of the other nodes of a cluster and the changes of
+	// the permissions, which don't invalidate them
+	unfurlCacheTTL = time.Minute
+
+	// unfurlCacheMaxEntries is the number of cached previews over which
+	// the cache is cleared
+	unfurlCacheMaxEntries = 10000
+
+	// unfurlMaxLinks is the number of card links of a post that are
+	// unfurled
+	unfurlMaxLinks = 5
+)
+
+var linkRegexp = regexp.MustCompile(`https?://[^\s<>()]+`)
+
+// unfurlCacheKey is the key of the preview of a card for a user, the
+// previews being fetched with the permissions of the poster.
+type unfurlCacheKey struct {
+	userID      string
+	workspaceID string
+	cardID      string
+}
+
+// unfurlCacheEntry is the preview of a card, nil if the user can't view
+// the card.
+type unfurlCacheEntry struct {
+	preview  *mmModel.SlackAttachment
+	boardID  string
+	expireAt time.Time
+}
+
+// unfurlCache caches the previews of the cards briefly, so that a card
+// linked in a busy channel isn't fetched for each message. The previews
+// are invalidated by the changes of their card and of its board.
+type unfurlCache struct {
+	mutex   sync.Mutex
+	entries map[unfurlCacheKey]unfurlCacheEntry
+	now     func() time.Time
+}
+
+func newUnfurlCache() *unfurlCache {
+	return &unfurlCache{
+		entries: map[unfurlCacheKey]unfurlCacheEntry{},
+		now:     time.Now,
+	}
+}
+
+func (uc *unfurlCache) get(key unfurlCacheKey) (unfurlCacheEntry, bool) {
+	uc.mutex.Lock()
+	defer uc.mutex.Unlock()
+
+	entry, ok := uc.entries[key]
+	if !ok || !uc.now().Before(entry.expireAt) {
+		return unfurlCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (uc *unfurlCache) set(key unfurlCacheKey, preview *mmModel.SlackAttachment, boardID string) {
+	uc.mutex.Lock()
+	defer uc.mutex.Unlock()
+
+	now := uc.now()
+	if len(uc.entries) >= unfurlCacheMaxEntries {
+		for key, entry := range uc.entries {
+			if !now.Before(entry.expireAt) {
+				delete(uc.entries, key)
+			}
+		}
+		if len(uc.entries) >= unfurlCacheMaxEntries {
+			uc.entries = map[unfurlCacheKey]unfurlCacheEntry{}
+		}
+	}
+	uc.entries[key] = unfurlCacheEntry{preview: preview, boardID: boardID, expireAt: now.Add(unfurlCacheTTL)}
+}
+
+// invalidate removes the previews of the cards and of the boards of the
+// workspace, or all the previews of the workspace if there are no IDs.
+func (uc *unfurlCache) invalidate(workspaceID string, blockIDs []string) {
+	uc.mutex.Lock()
+	defer uc.mutex.Unlock()
+
+	ids := map[string]bool{}
+	for _, id := range blockIDs {
+		ids[id] = true
+	}
+	for key, entry := range uc.entries {
+		if key.workspaceID == workspaceID && (len(ids) == 0 || ids[key.cardID] || ids[entry.boardID]) {
+			delete(uc.entries, key)
+		}
+	}
+}
+
+// unfurlCacheAdapter invalidates the previews of the cards with the block
+// changes broadcast to the websocket clients, and with the block cache
+// invalidations of the other nodes of the cluster.
+type unfurlCacheAdapter struct {
+	*ws.PluginAdapter
+	cache *unfurlCache
+}
+
+func (ua *unfurlCacheAdapter) invalidateBlocks(workspaceID string, blocks []model.Block) {
+	ids := []string{}
+	for _, block := range blocks {
+		ids = append(ids, block.ID)
+	}
+	if len(ids) > 0 {
+		ua.cache.invalidate(workspaceID, ids)
+	}
+}
+
+func (ua *unfurlCacheAdapter) BroadcastBlockChange(workspaceID string, block model.Block) {
+	ua.invalidateBlocks(workspaceID, []model.Block{block})
+	ua.PluginAdapter.BroadcastBlockChange(workspaceID, block)
+}
+
+func (ua *unfurlCacheAdapter) BroadcastBlockChanges(workspaceID string, blocks []model.Block) {
+	ua.invalidateBlocks(workspaceID, blocks)
+	ua.PluginAdapter.BroadcastBlockChanges(workspaceID, blocks)
+}
+
+func (ua *unfurlCacheAdapter) BroadcastBlockDelete(workspaceID, blockID, parentID, rootID string) {
+	ua.invalidateBlocks(workspaceID, []model.Block{{ID: blockID}})
+	ua.PluginAdapter.BroadcastBlockDelete(workspaceID, blockID, parentID, rootID)
+}
+
+func (ua *unfurlCacheAdapter) OnBlockCacheInvalidation(handler func(workspaceID string, rootIDs []string)) {
+	ua.PluginAdapter.OnBlockCacheInvalidation(func(workspaceID string, rootIDs []string) {
+		ua.cache.invalidate(workspaceID, rootIDs)
+		handler(workspaceID, rootIDs)
+	})
+}
+
+// MessageWillBePosted adds a preview of the cards of the channel's
+// workspace linked in the message, with the title, the board, the
+// assignee and the status of the cards. Only the cards the poster can
+// view are unfurled.
+func (p *Plugin) MessageWillBePosted(_ *plugin.Context, post *mmModel.Post) (*mmModel.Post, string) {
+	if p.app == nil || post.UserId == "" || post.IsSystemMessage() {
+		return post, ""
+	}
+
+	previews := p.linkPreviews(context.Background(), post)
+	if len(previews) == 0 {
+		return post, ""
+	}
+	mmModel.ParseSlackAttachment(post, append(post.Attachments(), previews...))
+	return post, ""
+}
+
+func (p *Plugin) linkPreviews(ctx context.Context, post *mmModel.Post) []*mmModel.SlackAttachment {
+	c := store.Container{WorkspaceID: post.ChannelId}
+	seen := map[string]bool{}
+	previews := []*mmModel.SlackAttachment{}
+	for _, link := range linkRegexp.FindAllString(post.Message, -1) {
+		if len(seen) == unfurlMaxLinks {
+			break
+		}
+		if !strings.HasPrefix(link, p.serverRoot+"/") {
+			continue
+		}
+		linkContainer, cardID := parseCardLink(c, link)
+		if cardID == "" || linkContainer != c || seen[cardID] {
+			continue
+		}
+		seen[cardID] = true
+
+		if preview := p.unfurlCard(ctx, c, post.UserId, cardID); preview != nil {
+			previews = append(previews, preview)
+		}
+	}
+	return previews
+}
+
+// unfurlCard returns the preview of the card for the user, or nil if the
+// user can't view it.
+func (p *Plugin) unfurlCard(ctx context.Context, c store.Container, userID, cardID string) *mmModel.SlackAttachment {
+	key := unfurlCacheKey{userID: userID, workspaceID: c.WorkspaceID, cardID: cardID}
+	if entry, ok := p.unfurlCache.get(key); ok {
+		return copyAttachment(entry.preview)
+	}
+
+	board, card, err := p.getViewableCard(ctx, c, userID, cardID)
+	if err != nil {
+		p.API.LogError("Unable to unfurl the card", "cardID", cardID, "error", err.Error())
+		return nil
+	}
+	var preview *mmModel.SlackAttachment
+	boardID := ""
+	if card != nil {
+		preview = p.cardPreview(ctx, c, *board, *card, summaryProperties(*board))
+		boardID = board.ID
+	}
+	p.unfurlCache.set(key, preview, boardID)
+	return copyAttachment(preview)
+}
+
+// summaryProperties returns the assignee and the status properties of the
+// board, its first person property and its select property named
+// "Status", or else its first select property.
+func summaryProperties(board model.Block) []map[string]interface{} {
+	var assignee, status map[string]interface{}
+	statusNamed := false
+	for _, property := range boardProperties(board) {
+		propertyType, _ := property["type"].(string)
+		name, _ := property["name"].(string)
+		switch {
+		case propertyType == "person" && assignee == nil:
+			assignee = property
+		case propertyType == "select" && !statusNamed && strings.EqualFold(strings.TrimSpace(name), "status"):
+			status = property
+			statusNamed = true
+		case propertyType == "select" && status == nil:
+			status = property
+		}
+	}
+
+	properties := []map[string]interface{}{}
+	for _, property := range []map[string]interface{}{assignee, status} {
+		if property != nil {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// copyAttachment returns a copy of the cached preview, which the post
+// modifies.
+func copyAttachment(attachment *mmModel.SlackAttachment) *mmModel.SlackAttachment {
+	if attachment == nil {
+		return nil
+	}
+	attachmentCopy := *attachment
+	attachmentCopy.Fields = make([]*mmModel.SlackAttachmentField, len(attachment.Fields))
+	for i, field := range attachment.Fields {
+		fieldCopy := *field
+		attachmentCopy.Fields[i] = &fieldCopy
+	}
+	return &attachmentCopy
+}
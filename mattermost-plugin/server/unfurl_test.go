@@ -0,0 +1,138 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mmModel "github.com/mattermost/mattermost-server/v6/model"
+)
+
+func setupUnfurlTest(t *testing.T) (*Plugin, *testCommandApp) {
+	p, api, commandApp := setupCommandTest(t)
+	p.unfurlCache = newUnfurlCache()
+	api.On("GetUser", "sara-id").Return(&mmModel.User{Id: "sara-id", Username: "sara"}, nil).Maybe()
+	return p, commandApp
+}
+
+func postMessage(p *Plugin, channelID, message string) *mmModel.Post {
+	post, rejection := p.MessageWillBePosted(nil, &mmModel.Post{UserId: "user-1", ChannelId: channelID, Message: message})
+	if rejection != "" {
+		return nil
+	}
+	return post
+}
+
+func TestMessageWillBePosted(t *testing.T) {
+	t.Run("should unfurl the card links of the channel's workspace", func(t *testing.T) {
+		p, _ := setupUnfurlTest(t)
+
+		post := postMessage(p, "channel-1", "See http://localhost/workspace/channel-1/board-1/view-1/card-1, and this repeated "+
+			"<http://localhost/workspace/channel-1/board-1/view-2/card-1>")
+		require.Equal(t, mmModel.PostTypeSlackAttachment, post.Type)
+		attachments := post.Attachments()
+		require.Len(t, attachments, 1)
+		require.Equal(t, "Fix login bug", attachments[0].Title)
+		require.Equal(t, "http://localhost/workspace/channel-1/board-1/view-1/card-1", attachments[0].TitleLink)
+		require.Equal(t, "Card of [Roadmap](http://localhost/workspace/channel-1/board-1)", attachments[0].Text)
+		require.Equal(t, []*mmModel.SlackAttachmentField{
+			{Title: "Owner", Value: "@sara", Short: true},
+			{Title: "Status", Value: "Done", Short: true},
+		}, attachments[0].Fields)
+	})
+
+	t.Run("should not unfurl the other links", func(t *testing.T) {
+		p, _ := setupUnfurlTest(t)
+
+		for _, message := range []string{
+			"No link",
+			"http://example.com/workspace/channel-1/board-1/view-1/card-1",
+			"http://localhost/workspace/channel-1/board-1",
+			"http://localhost/workspace/channel-2/board-1/view-1/card-1",
+			"http://localhost/workspace/channel-1/board-1/view-1/missing",
+		} {
+			post := postMessage(p, "channel-1", message)
+			require.Empty(t, post.Type, message)
+			require.Empty(t, post.Attachments(), message)
+		}
+	})
+
+	t.Run("should not unfurl the cards the poster can't view", func(t *testing.T) {
+		p, _ := setupUnfurlTest(t)
+
+		post := postMessage(p, "channel-2", "http://localhost/workspace/channel-2/board-3/view-1/card-2")
+		require.Empty(t, post.Attachments())
+		post = postMessage(p, "channel-3", "http://localhost/workspace/channel-3/board-4/view-1/card-3")
+		require.Empty(t, post.Attachments())
+	})
+
+	t.Run("should cache the previews until the card changes", func(t *testing.T) {
+		p, commandApp := setupUnfurlTest(t)
+		link := "http://localhost/workspace/channel-1/board-1/view-1/card-1"
+
+		postMessage(p, "channel-1", link)
+		post := postMessage(p, "channel-1", link)
+		require.Len(t, post.Attachments(), 1)
+		require.Equal(t, 1, commandApp.cardGets)
+
+		commandApp.blocks["channel-1"][2].Title = "Fixed login bug"
+		p.unfurlCache.invalidate("channel-1", []string{"card-1"})
+		post = postMessage(p, "channel-1", link)
+		require.Equal(t, "Fixed login bug", post.Attachments()[0].Title)
+		require.Equal(t, 2, commandApp.cardGets)
+	})
+}
+
+func TestUnfurlCache(t *testing.T) {
+	now := time.Now()
+	cache := newUnfurlCache()
+	cache.now = func() time.Time { return now }
+
+	key := func(userID, workspaceID, cardID string) unfurlCacheKey {
+		return unfurlCacheKey{userID: userID, workspaceID: workspaceID, cardID: cardID}
+	}
+	fill := func() {
+		cache.set(key("user-1", "workspace-1", "card-1"), &mmModel.SlackAttachment{Title: "Card 1"}, "board-1")
+		cache.set(key("user-2", "workspace-1", "card-1"), nil, "")
+		cache.set(key("user-1", "workspace-1", "card-2"), &mmModel.SlackAttachment{Title: "Card 2"}, "board-2")
+		cache.set(key("user-1", "workspace-2", "card-3"), &mmModel.SlackAttachment{Title: "Card 3"}, "board-1")
+	}
+	cached := func(userID, workspaceID, cardID string) bool {
+		_, ok := cache.get(key(userID, workspaceID, cardID))
+		return ok
+	}
+
+	t.Run("should expire the previews", func(t *testing.T) {
+		fill()
+		require.True(t, cached("user-1", "workspace-1", "card-1"))
+		entry, _ := cache.get(key("user-2", "workspace-1", "card-1"))
+		require.Nil(t, entry.preview)
+
+		now = now.Add(unfurlCacheTTL)
+		require.False(t, cached("user-1", "workspace-1", "card-1"))
+	})
+
+	t.Run("should invalidate the previews of a card for every user", func(t *testing.T) {
+		fill()
+		cache.invalidate("workspace-1", []string{"card-1"})
+		require.False(t, cached("user-1", "workspace-1", "card-1"))
+		require.False(t, cached("user-2", "workspace-1", "card-1"))
+		require.True(t, cached("user-1", "workspace-1", "card-2"))
+	})
+
+	t.Run("should invalidate the previews of the cards of a board", func(t *testing.T) {
+		fill()
+		cache.invalidate("workspace-1", []string{"board-1"})
+		require.False(t, cached("user-1", "workspace-1", "card-1"))
+		require.True(t, cached("user-1", "workspace-1", "card-2"))
+		require.True(t, cached("user-1", "workspace-2", "card-3"))
+	})
+
+	t.Run("should invalidate the previews of a workspace", func(t *testing.T) {
+		fill()
+		cache.invalidate("workspace-1", nil)
+		require.False(t, cached("user-1", "workspace-1", "card-2"))
+		require.True(t, cached("user-1", "workspace-2", "card-3"))
+	})
+}
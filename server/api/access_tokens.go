@@ -0,0 +1,245 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+const maxAccessTokenDescriptionLength = 255
+
+// AccessTokenRequest is a request to create a personal access token
+// swagger:model
+type AccessTokenRequest struct {
+	// Description of the token
+	// required: false
+	Description string `json:"description"`
+
+	// Expiry time in milliseconds, omit for a token that never expires
+	// required: false
+	ExpireAt int64 `json:"expireAt"`
+}
+
+// nativeAuthAllowed returns false, after sending the error response, in
+// single-user mode or with Mattermost authentication, where the users
+// can't manage their own tokens or MFA.
+func (a *API) nativeAuthAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if len(a.singleUserToken) > 0 {
+		// Not permitted in single-user mode
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "not permitted in single-user mode", nil)
+		return false
+	}
+	if a.MattermostAuth {
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "not permitted with Mattermost authentication", nil)
+		return false
+	}
+	return true
+}
+
+func (a *API) handleCreateAccessToken(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/users/me/tokens createAccessToken
+	//
+	// Creates a personal access token for the current user. The token is
+	// only returned by this call.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: description and expiry of the token
+	//   required: false
+	//   schema:
+	//     "$ref": "#/definitions/AccessTokenRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/AccessToken"
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var request AccessTokenRequest
+	if len(requestBody) > 0 {
+		if err = json.Unmarshal(requestBody, &request); err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+			return
+		}
+	}
+
+	if len(request.Description) > maxAccessTokenDescriptionLength {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "description is too long", nil)
+		return
+	}
+	if request.ExpireAt < 0 || (request.ExpireAt != 0 && request.ExpireAt <= utils.GetMillis()) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "expireAt must be in the future", nil)
+		return
+	}
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	auditRec := a.makeAuditRecord(r, "createAccessToken", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("expireAt", request.ExpireAt)
+
+	token, err := a.app.CreateAccessToken(session.UserID, request.Description, request.ExpireAt)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	a.logger.Debug("POST access token", mlog.String("tokenID", token.ID))
+	auditRec.AddMeta("tokenID", token.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleGetAccessTokens(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/users/me/tokens getAccessTokens
+	//
+	// Returns the personal access tokens of the current user, without the
+	// tokens themselves
+	//
+	// ---
+	// produces:
+	// - application/json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/AccessToken"
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	auditRec := a.makeAuditRecord(r, "getAccessTokens", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+
+	tokens, err := a.app.GetAccessTokens(session.UserID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("tokenCount", len(tokens))
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteAccessToken(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /api/v1/users/me/tokens/{tokenID} deleteAccessToken
+	//
+	// Revokes a personal access token of the current user
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: tokenID
+	//   in: path
+	//   description: ID of the token to revoke
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: token not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	tokenID := mux.Vars(r)["tokenID"]
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	auditRec := a.makeAuditRecord(r, "deleteAccessToken", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("tokenID", tokenID)
+
+	err := a.app.RevokeAccessToken(session.UserID, tokenID)
+	if errors.Is(err, sql.ErrNoRows) {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	a.logger.Debug("DELETE access token", mlog.String("tokenID", tokenID))
+	auditRec.Success()
+}
@@ -2,21 +2,67 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/store"
 
 	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 )
 
+const (
+	defaultAdminWorkspacesPageSize        = 100
+	defaultAdminWebhookDeliveriesPageSize = 100
+	defaultAdminAuditEntriesPageSize      = 100
+	defaultAdminUsersPageSize             = 100
+)
+
+// AdminSetPasswordData is the new password of a user set by an admin
+// swagger:model
 type AdminSetPasswordData struct {
+	// The new password
+	// required: true
 	Password string `json:"password"`
 }
 
 func (a *API) handleAdminSetPassword(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/admin/users/{username}/password adminSetPassword
+	//
+	// Sets the password of a user. Only available over the local admin socket
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: Username of the user
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: The new password
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/AdminSetPasswordData"
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
 	vars := mux.Vars(r)
 	username := vars["username"]
 
@@ -29,7 +75,7 @@ func (a *API) handleAdminSetPassword(w http.ResponseWriter, r *http.Request) {
 	var requestData AdminSetPasswordData
 	err = json.Unmarshal(requestBody, &requestData)
 	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
 		return
 	}
 
@@ -43,6 +89,10 @@ func (a *API) handleAdminSetPassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = a.app.UpdateUserPassword(username, requestData.Password)
+	if errors.Is(err, app.ErrLDAPPasswordChange) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, err.Error(), err)
+		return
+	}
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
@@ -53,3 +103,821 @@ func (a *API) handleAdminSetPassword(w http.ResponseWriter, r *http.Request) {
 	jsonStringResponse(w, http.StatusOK, "{}")
 	auditRec.Success()
 }
+
+func (a *API) handleAdminGetWorkspaces(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var modifiedSince int64
+	if modifiedSinceStr := query.Get("modified_since"); modifiedSinceStr != "" {
+		var err error
+		modifiedSince, err = strconv.ParseInt(modifiedSinceStr, 10, 64)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid modified_since", err)
+			return
+		}
+	}
+
+	limit := defaultAdminWorkspacesPageSize
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "adminGetWorkspaces", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("modifiedSince", modifiedSince)
+
+	workspaces, err := a.app.GetWorkspaces(ctx, modifiedSince, limit)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(workspaces)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleAdminDeleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /api/v1/workspaces/{workspaceID} adminDeleteWorkspace
+	//
+	// Deletes a workspace with its blocks and settings. Only available over
+	// the local admin socket
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	workspaceID := vars["workspaceID"]
+
+	auditRec := a.makeAuditRecord(r, "adminDeleteWorkspace", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("workspaceID", workspaceID)
+
+	err := a.app.DeleteWorkspace(ctx, workspaceID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("AdminDeleteWorkspace", mlog.String("workspaceID", workspaceID))
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleAdminGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/webhook_deliveries adminGetWebhookDeliveries
+	//
+	// Returns the webhook deliveries of a workspace that failed, the newest
+	// first. Only available over the local admin socket
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: Maximum number of deliveries to return, defaults to 100
+	//   required: false
+	//   type: integer
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/WebhookDelivery"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	workspaceID := mux.Vars(r)["workspaceID"]
+
+	limit := defaultAdminWebhookDeliveriesPageSize
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "adminGetWebhookDeliveries", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("workspaceID", workspaceID)
+
+	deliveries, err := a.app.GetWebhookDeliveries(workspaceID, limit)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(deliveries)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleAdminCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/templates adminCreateTemplate
+	//
+	// Creates a global template from a board. Only available over the local
+	// admin socket
+	//
+	// ---
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: The board to create the template from
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateTemplateRequest"
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Block"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var requestData model.CreateTemplateRequest
+	err = json.Unmarshal(requestBody, &requestData)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if requestData.WorkspaceID == "" || requestData.BoardID == "" {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "workspaceId and boardId are required", nil)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "adminCreateTemplate", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("workspaceID", requestData.WorkspaceID)
+	auditRec.AddMeta("boardID", requestData.BoardID)
+
+	container := store.Container{
+		WorkspaceID: requestData.WorkspaceID,
+	}
+	template, err := a.app.CreateGlobalTemplate(ctx, container, requestData.BoardID, "system")
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if template == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	a.logger.Debug("AdminCreateTemplate",
+		mlog.String("boardID", requestData.BoardID),
+		mlog.String("templateID", template.ID),
+	)
+
+	data, err := json.Marshal(template)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("templateID", template.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleAdminCleanupFiles(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/admin/cleanup adminCleanupFiles
+	//
+	// Removes the files that no block references anymore, older than the
+	// retention period. Only available over the local admin socket
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: dry_run
+	//   in: query
+	//   description: Only report the files that would be removed when true
+	//   required: false
+	//   type: boolean
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/FileCleanupResult"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	dryRun := false
+	if dryRunStr := r.URL.Query().Get("dry_run"); dryRunStr != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid dry_run", err)
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "adminCleanupFiles", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("dryRun", dryRun)
+
+	result, err := a.app.CleanupOrphanedFiles(ctx, dryRun)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("AdminCleanupFiles",
+		mlog.Bool("dryRun", dryRun),
+		mlog.Int("filesExamined", result.FilesExamined),
+		mlog.Int("filesRemoved", result.FilesRemoved),
+	)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("filesRemoved", result.FilesRemoved)
+	auditRec.Success()
+}
+
+func (a *API) handleAdminGetAuditEntries(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/admin/audit adminGetAuditEntries
+	//
+	// Returns a page of the audit entries, the newest first. Only available
+	// over the local admin socket
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: actor_id
+	//   in: query
+	//   description: Only the entries of this user
+	//   required: false
+	//   type: string
+	// - name: workspace_id
+	//   in: query
+	//   description: Only the entries of this workspace
+	//   required: false
+	//   type: string
+	// - name: action
+	//   in: query
+	//   description: Only the entries of this action
+	//   required: false
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only the entries since this time, in milliseconds
+	//   required: false
+	//   type: integer
+	// - name: until
+	//   in: query
+	//   description: Only the entries until this time, in milliseconds
+	//   required: false
+	//   type: integer
+	// - name: page
+	//   in: query
+	//   description: Page to return, starting at 0
+	//   required: false
+	//   type: integer
+	// - name: per_page
+	//   in: query
+	//   description: Number of entries of a page, defaults to 100
+	//   required: false
+	//   type: integer
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/AuditEntry"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	query := r.URL.Query()
+
+	opts := model.QueryAuditEntriesOptions{
+		ActorID:     query.Get("actor_id"),
+		WorkspaceID: query.Get("workspace_id"),
+		Action:      query.Get("action"),
+		PerPage:     defaultAdminAuditEntriesPageSize,
+	}
+
+	if sinceStr := query.Get("since"); sinceStr != "" {
+		since, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil || since < 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid since", err)
+			return
+		}
+		opts.Since = since
+	}
+	if untilStr := query.Get("until"); untilStr != "" {
+		until, err := strconv.ParseInt(untilStr, 10, 64)
+		if err != nil || until < 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid until", err)
+			return
+		}
+		opts.Until = until
+	}
+
+	if pageStr := query.Get("page"); pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid page", err)
+			return
+		}
+		opts.Page = page
+	}
+	if perPageStr := query.Get("per_page"); perPageStr != "" {
+		perPage, err := strconv.Atoi(perPageStr)
+		if err != nil || perPage <= 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid per_page", err)
+			return
+		}
+		opts.PerPage = perPage
+	}
+
+	auditRec := a.makeAuditRecord(r, "adminGetAuditEntries", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("actorID", opts.ActorID)
+	auditRec.AddMeta("workspaceID", opts.WorkspaceID)
+	auditRec.AddMeta("action", opts.Action)
+
+	entries, err := a.app.GetAuditEntries(opts)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleAdminGetMigrations(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/admin/migrations adminGetMigrations
+	//
+	// Returns the migrations applied to the database and the pending ones.
+	// Only available over the local admin socket
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/MigrationStatus"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+
+	auditRec := a.makeAuditRecord(r, "adminGetMigrations", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+
+	status, err := a.app.GetMigrationStatus(ctx)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleAdminGetWorkspaceRetention(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/admin/workspaces/retention adminGetWorkspaceRetention
+	//
+	// Returns the inactive workspaces affected by the next run of the
+	// cleanup policy, whose members are notified, or which are archived and
+	// deleted, without changing them, even while the policy is disabled.
+	// Only available over the local admin socket
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/WorkspaceRetentionPlan"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+
+	auditRec := a.makeAuditRecord(r, "adminGetWorkspaceRetention", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+
+	plan, err := a.app.GetWorkspaceRetentionPlan(ctx)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("workspaceCount", len(plan.Workspaces))
+	auditRec.Success()
+}
+
+func (a *API) handleAdminGetUsers(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/admin/users adminGetUsers
+	//
+	// Returns a page of the users of the server, including the deactivated ones. Requires an admin.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: active
+	//   in: query
+	//   description: Only the active users when true, or the deactivated ones when false
+	//   required: false
+	//   type: boolean
+	// - name: username
+	//   in: query
+	//   description: Only the users whose username starts with this prefix
+	//   required: false
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: Page, starting at 0
+	//   required: false
+	//   type: integer
+	// - name: per_page
+	//   in: query
+	//   description: Number of users per page, 100 by default
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/User"
+	//   '403':
+	//     description: the user isn't an admin
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	query := r.URL.Query()
+
+	opts := model.QueryUsersOptions{
+		Username: query.Get("username"),
+		PerPage:  defaultAdminUsersPageSize,
+	}
+
+	if activeStr := query.Get("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid active", err)
+			return
+		}
+		opts.Active = &active
+	}
+	if pageStr := query.Get("page"); pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid page", err)
+			return
+		}
+		opts.Page = page
+	}
+	if perPageStr := query.Get("per_page"); perPageStr != "" {
+		perPage, err := strconv.Atoi(perPageStr)
+		if err != nil || perPage <= 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid per_page", err)
+			return
+		}
+		opts.PerPage = perPage
+	}
+
+	auditRec := a.makeAuditRecord(r, "adminGetUsers", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("username", opts.Username)
+
+	users, err := a.app.GetUsers(opts)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(users)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleAdminDeactivateUser(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /api/v1/admin/users/{userID}/deactivate adminDeactivateUser
+	//
+	// Deactivates a user and logs them out. Requires an admin.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: the admin deactivates their own user
+	//   '403':
+	//     description: the user isn't an admin
+	//   '404':
+	//     description: user not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := mux.Vars(r)["userID"]
+	session := r.Context().Value(sessionContextKey).(*model.Session)
+
+	auditRec := a.makeAuditRecord(r, "adminDeactivateUser", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("userID", userID)
+
+	err := a.app.DeactivateUser(session.UserID, userID)
+	if errors.Is(err, app.ErrCannotDeactivateSelf) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("AdminDeactivateUser", mlog.String("userID", userID))
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleAdminActivateUser(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /api/v1/admin/users/{userID}/activate adminActivateUser
+	//
+	// Activates a deactivated user again. Requires an admin.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '403':
+	//     description: the user isn't an admin
+	//   '404':
+	//     description: user not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := mux.Vars(r)["userID"]
+	session := r.Context().Value(sessionContextKey).(*model.Session)
+
+	auditRec := a.makeAuditRecord(r, "adminActivateUser", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("userID", userID)
+
+	if err := a.app.ActivateUser(session.UserID, userID); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("AdminActivateUser", mlog.String("userID", userID))
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleAdminResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /api/v1/admin/users/{userID}/password adminResetUserPassword
+	//
+	// Sets the password of a user and logs them out. Requires an admin.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: The new password
+	//   required: true
+	//   schema:
+	//     type: object
+	//     properties:
+	//       password:
+	//         type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid password
+	//   '403':
+	//     description: the user isn't an admin, or the user logs in with the LDAP server
+	//   '404':
+	//     description: user not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	userID := mux.Vars(r)["userID"]
+	session := r.Context().Value(sessionContextKey).(*model.Session)
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var requestData AdminSetPasswordData
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "adminResetUserPassword", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("userID", userID)
+
+	err = a.app.ResetUserPassword(session.UserID, userID, requestData.Password)
+	var invalidPasswordErr *auth.InvalidPasswordError
+	if errors.As(err, &invalidPasswordErr) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if errors.Is(err, app.ErrLDAPPasswordChange) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("AdminResetUserPassword", mlog.String("userID", userID))
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleAdminGetStatistics(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/admin/statistics adminGetStatistics
+	//
+	// Returns the statistics of the workspaces of the server. Requires an admin.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/WorkspaceStats"
+	//   '403':
+	//     description: the user isn't an admin
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+
+	auditRec := a.makeAuditRecord(r, "adminGetStatistics", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+
+	stats, err := a.app.GetWorkspaceStats(ctx)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
@@ -1,20 +1,31 @@
 package api
 
 import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/auth"
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/importer"
+	"github.com/mattermost/focalboard/server/services/metrics"
+	"github.com/mattermost/focalboard/server/services/ratelimit"
 	"github.com/mattermost/focalboard/server/services/store"
 	"github.com/mattermost/focalboard/server/utils"
 
@@ -24,15 +35,48 @@ import (
 const (
 	HeaderRequestedWith    = "X-Requested-With"
 	HeaderRequestedWithXML = "XMLHttpRequest"
-	SingleUser             = "single-user"
-	UploadFormFileKey      = "file"
+	// HeaderServerFilter tells if the filter of the view was applied
+	// completely by the server, or partially and left to the client
+	HeaderServerFilter         = "X-Server-Filter"
+	HeaderServerFilterComplete = "complete"
+	HeaderServerFilterPartial  = "partial"
+	SingleUser                 = "single-user"
+	UploadFormFileKey          = "file"
+	JiraImportMappingFormKey   = "mapping"
 )
 
 const (
 	ErrorNoWorkspaceCode    = 1000
 	ErrorNoWorkspaceMessage = "No workspace"
+
+	ErrorFileTooLargeCode       = 1001
+	ErrorFileTypeNotAllowedCode = 1002
+	ErrorTooManyRequestsCode    = 1003
+	ErrorMfaRequiredCode        = 1004
+	ErrorBlockLockedCode        = 1005
+	ErrorBoardArchivedCode      = 1006
+	ErrorVersionConflictCode    = 1007
+	ErrorIdempotencyKeyCode     = 1008
+)
+
+// uploadFormOverhead is the size allowed for the multipart encoding of
+// an upload on top of the maximum file size.
+const uploadFormOverhead = 64 * 1024
+
+// BlocksMaxBodySize is the size of the largest body of the requests
+// inserting blocks, which is read whole before being parsed.
+const BlocksMaxBodySize = 32 * 1024 * 1024
+
+const (
+	searchMinQueryLength  = 3
+	searchDefaultPageSize = 50
+	searchMaxPageSize     = 200
 )
 
+// errWorkspaceMismatch is returned for the requests to a workspace other
+// than the root one with native auth, as it's the only workspace.
+var errWorkspaceMismatch = errors.New("the workspace doesn't exist, only the root workspace is available")
+
 type PermissionError struct {
 	msg string
 }
@@ -45,65 +89,203 @@ func (pe PermissionError) Error() string {
 // REST APIs
 
 type API struct {
-	app             *app.App
-	authService     string
-	singleUserToken string
-	MattermostAuth  bool
-	logger          *mlog.Logger
-	audit           *audit.Audit
+	app                *app.App
+	authService        string
+	singleUserToken    string
+	MattermostAuth     bool
+	logger             *mlog.Logger
+	audit              *audit.Audit
+	rateLimiter        *RateLimiter
+	compressor         *Compressor
+	cors               *CORSPolicy
+	instrumentation    metrics.Instrumentation
+	inboundHookLimiter *ratelimit.Limiter
 }
 
-func NewAPI(app *app.App, singleUserToken string, authService string, logger *mlog.Logger, audit *audit.Audit) *API {
+func NewAPI(app *app.App, singleUserToken string, authService string, logger *mlog.Logger, audit *audit.Audit,
+	rateLimiter *RateLimiter, compressor *Compressor, cors *CORSPolicy, instrumentation metrics.Instrumentation) *API {
 	return &API{
-		app:             app,
-		singleUserToken: singleUserToken,
-		authService:     authService,
-		logger:          logger,
-		audit:           audit,
+		app:                app,
+		singleUserToken:    singleUserToken,
+		authService:        authService,
+		logger:             logger,
+		audit:              audit,
+		rateLimiter:        rateLimiter,
+		compressor:         compressor,
+		cors:               cors,
+		instrumentation:    instrumentation,
+		inboundHookLimiter: ratelimit.New(inboundHookRateLimitPerSecond, inboundHookRateLimitBurst, ratelimit.DefaultMaxBuckets),
 	}
 }
 
 func (a *API) RegisterRoutes(r *mux.Router) {
+	r.Use(a.logRequests)
+	r.Use(a.applyCORS)
+
+	// the preflight requests of the allowed origins are answered for all
+	// the routes of the API, before they're matched by their method
+	if a.cors != nil {
+		r.PathPrefix("/api/").Methods("OPTIONS").HandlerFunc(a.handlePreflight)
+	}
+
+	// the readiness probes don't send the CSRF header, and shouldn't be
+	// rate limited
+	r.HandleFunc("/api/v1/ping", a.handlePing).Methods("GET")
+	r.HandleFunc("/api/v1/health", a.handleHealth).Methods("GET")
+	r.HandleFunc("/api/v1/readiness", a.handleReadiness).Methods("GET")
+
+	// the spec and the docs UI are opened by the browsers and
+	// the API tools, without the CSRF header
+	r.HandleFunc("/api/v1/openapi.json", a.handleGetOpenAPISpec).Methods("GET")
+	r.HandleFunc("/api/v1/docs", a.handleGetAPIDocs).Methods("GET")
+
+	// the calendar apps, the frames of the embedded boards, the
+	// integrations posting to the inbound hooks and GitHub don't send the
+	// CSRF header either, the feeds, the embeds and the hooks are
+	// authenticated by their token and signature
+	r.Handle("/api/v1/workspaces/{workspaceID}/boards/{boardID}/calendar.ics", a.rateLimit(http.HandlerFunc(a.handleGetBoardCalendar))).Methods("GET")
+	r.Handle("/api/v1/workspaces/{workspaceID}/boards/{boardID}/embed", a.rateLimit(http.HandlerFunc(a.handleGetBoardEmbed))).Methods("GET")
+	r.Handle("/api/v1/hooks/{hookToken}", a.rateLimit(http.HandlerFunc(a.handlePostInboundHookDelivery))).Methods("POST")
+	r.Handle("/api/v1/workspaces/{workspaceID}/boards/{boardID}/github/webhook", a.rateLimit(http.HandlerFunc(a.handlePostGitHubWebhook))).Methods("POST")
+
+	// the single sign-on logins are navigations of the browsers to the
+	// server and back from the identity provider, protected by their state
+	r.Handle("/api/v1/oauth/login", a.rateLimit(http.HandlerFunc(a.handleOIDCLogin))).Methods("GET")
+	r.Handle("/api/v1/oauth/callback", a.rateLimit(http.HandlerFunc(a.handleOIDCCallback))).Methods("GET")
+
 	apiv1 := r.PathPrefix("/api/v1").Subrouter()
+	apiv1.Use(a.instrumentRequests)
+	apiv1.Use(a.compressResponses)
+	apiv1.Use(a.rateLimit)
 	apiv1.Use(a.requireCSRFToken)
 
 	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks", a.sessionRequired(a.handleGetBlocks)).Methods("GET")
-	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks", a.sessionRequired(a.handlePostBlocks)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks", a.sessionRequired(a.idempotent(a.handlePostBlocks))).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks", a.sessionRequired(a.handlePatchBlocks)).Methods("PATCH")
 	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/{blockID}", a.sessionRequired(a.handleDeleteBlock)).Methods("DELETE")
 	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/{blockID}", a.sessionRequired(a.handlePatchBlock)).Methods("PATCH")
 	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/{blockID}/subtree", a.attachSession(a.handleGetSubTree, false)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/resolve/{blockID}", a.attachSession(a.handleResolveBlock, false)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/{blockID}/undelete", a.sessionRequired(a.handleUndeleteBlock)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/{blockID}/history", a.sessionRequired(a.handleGetBlockHistory)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/{blockID}/revert/{historyVersion}", a.sessionRequired(a.handleRevertBlock)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/{blockID}/duplicate", a.sessionRequired(a.idempotent(a.handleDuplicateBoard))).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/trash", a.sessionRequired(a.handleGetDeletedBlocks)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/search", a.sessionRequired(a.handleSearchBlocks)).Methods("GET")
+
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards", a.sessionRequired(a.handleGetBoards)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/metadata", a.sessionRequired(a.handleGetBoardMetadata)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/presence", a.sessionRequired(a.handleGetBoardPresence)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/export/csv", a.sessionRequired(a.handleExportBoardCSV)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/export/markdown", a.sessionRequired(a.handleExportBoardMarkdown)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/statistics", a.sessionRequired(a.handleGetBoardStatistics)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/cards/{cardID}/activity", a.sessionRequired(a.handleGetCardActivity)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/cards/{cardID}/backlinks", a.sessionRequired(a.handleGetCardBacklinks)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/cards/{cardID}/move", a.sessionRequired(a.handleMoveCard)).Methods("PATCH")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/cards/by-number/{number}", a.sessionRequired(a.handleGetCardByNumber)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/cards/quick-add", a.sessionRequired(a.idempotent(a.handleQuickAddCard))).Methods("POST")
 
 	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/export", a.sessionRequired(a.handleExport)).Methods("GET")
-	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/import", a.sessionRequired(a.handleImport)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/import", a.guestForbidden(a.handleImport)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/import/trello", a.guestForbidden(a.handleImportTrello)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/import/jira", a.guestForbidden(a.handleImportJira)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/import/notion", a.guestForbidden(a.handleImportNotion)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/import/asana", a.guestForbidden(a.handleImportAsana)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/archive", a.guestForbidden(a.handleExportWorkspaceArchive)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/archive", a.guestForbidden(a.handleImportWorkspaceArchive)).Methods("POST")
 
 	apiv1.HandleFunc("/workspaces/{workspaceID}/sharing/{rootID}", a.sessionRequired(a.handlePostSharing)).Methods("POST")
 	apiv1.HandleFunc("/workspaces/{workspaceID}/sharing/{rootID}", a.sessionRequired(a.handleGetSharing)).Methods("GET")
-
-	apiv1.HandleFunc("/workspaces/{workspaceID}", a.sessionRequired(a.handleGetWorkspace)).Methods("GET")
-	apiv1.HandleFunc("/workspaces/{workspaceID}/regenerate_signup_token", a.sessionRequired(a.handlePostWorkspaceRegenerateSignupToken)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/sharing/{rootID}/tokens", a.sessionRequired(a.handlePostSharingToken)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/sharing/{rootID}/tokens/{token}", a.sessionRequired(a.handleDeleteSharingToken)).Methods("DELETE")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/calendar", a.sessionRequired(a.handleGetCalendarFeed)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/calendar", a.sessionRequired(a.handlePostCalendarFeed)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/calendar", a.sessionRequired(a.handleDeleteCalendarFeed)).Methods("DELETE")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/inbound-hook", a.sessionRequired(a.handleGetInboundHook)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/inbound-hook", a.sessionRequired(a.handlePostInboundHook)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/inbound-hook", a.sessionRequired(a.handleDeleteInboundHook)).Methods("DELETE")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/inbound-hook/deliveries", a.sessionRequired(a.handleGetInboundHookDeliveries)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/github", a.sessionRequired(a.handleGetGitHubIntegration)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/github", a.sessionRequired(a.handlePutGitHubIntegration)).Methods("PUT")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/github", a.sessionRequired(a.handleDeleteGitHubIntegration)).Methods("DELETE")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/github/sync", a.sessionRequired(a.handlePostGitHubSync)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/embed", a.sessionRequired(a.handlePostBoardEmbed)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/members", a.sessionRequired(a.handleGetBoardMembers)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/members", a.sessionRequired(a.handlePostBoardMember)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/members/{userID}", a.sessionRequired(a.handlePutBoardMember)).Methods("PUT")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/members/{userID}", a.sessionRequired(a.handleDeleteBoardMember)).Methods("DELETE")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/boards/{boardID}/guest_invites", a.sessionRequired(a.handlePostGuestInvite)).Methods("POST")
+
+	apiv1.HandleFunc("/workspaces/{workspaceID}", a.guestForbidden(a.handleGetWorkspace)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/settings", a.guestForbidden(a.handlePatchWorkspaceSettings)).Methods("PATCH")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/settings/default_card_template", a.guestForbidden(a.handlePutDefaultCardTemplate)).Methods("PUT")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/regenerate_signup_token", a.guestForbidden(a.handlePostWorkspaceRegenerateSignupToken)).Methods("POST")
 	apiv1.HandleFunc("/workspaces/{workspaceID}/users", a.sessionRequired(a.getWorkspaceUsers)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/users/search", a.sessionRequired(a.handleSearchWorkspaceUsers)).Methods("GET")
 
 	// User APIs
 	apiv1.HandleFunc("/users/me", a.sessionRequired(a.handleGetMe)).Methods("GET")
+	apiv1.HandleFunc("/users/me/tokens", a.sessionRequired(a.handleCreateAccessToken)).Methods("POST")
+	apiv1.HandleFunc("/users/me/tokens", a.sessionRequired(a.handleGetAccessTokens)).Methods("GET")
+	apiv1.HandleFunc("/users/me/tokens/{tokenID}", a.sessionRequired(a.handleDeleteAccessToken)).Methods("DELETE")
+	apiv1.HandleFunc("/users/me/mfa/activate", a.sessionRequired(a.handleActivateMfa)).Methods("POST")
+	apiv1.HandleFunc("/users/me/mfa/confirm", a.sessionRequired(a.handleConfirmMfa)).Methods("POST")
+	apiv1.HandleFunc("/users/me/mfa/deactivate", a.sessionRequired(a.handleDeactivateMfa)).Methods("POST")
+	apiv1.HandleFunc("/users/me/digest", a.sessionRequired(a.handleUpdateDigestSettings)).Methods("PUT")
+	apiv1.HandleFunc("/users/me/preferences", a.sessionRequired(a.handleGetPreferences)).Methods("GET")
+	apiv1.HandleFunc("/users/me/preferences", a.sessionRequired(a.handleUpdatePreferences)).Methods("PUT")
+	apiv1.HandleFunc("/users/me/preferences/delete", a.sessionRequired(a.handleDeletePreferences)).Methods("POST")
+	apiv1.HandleFunc("/users/password-reset", a.handlePasswordReset).Methods("POST")
+	apiv1.HandleFunc("/users/password-reset/complete", a.handleCompletePasswordReset).Methods("POST")
 	apiv1.HandleFunc("/users/{userID}", a.sessionRequired(a.handleGetUser)).Methods("GET")
 	apiv1.HandleFunc("/users/{userID}/changepassword", a.sessionRequired(a.handleChangePassword)).Methods("POST")
+	apiv1.HandleFunc("/users/{userID}/image", a.sessionRequired(a.handleGetUserImage)).Methods("GET")
+	apiv1.HandleFunc("/users/{userID}/image", a.sessionRequired(a.handleSetUserImage)).Methods("PUT")
+
+	// Admin APIs of the users of the standalone server
+	apiv1.HandleFunc("/admin/users", a.systemAdminRequired(a.handleAdminGetUsers)).Methods("GET")
+	apiv1.HandleFunc("/admin/users/{userID}/deactivate", a.systemAdminRequired(a.handleAdminDeactivateUser)).Methods("PUT")
+	apiv1.HandleFunc("/admin/users/{userID}/activate", a.systemAdminRequired(a.handleAdminActivateUser)).Methods("PUT")
+	apiv1.HandleFunc("/admin/users/{userID}/password", a.systemAdminRequired(a.handleAdminResetUserPassword)).Methods("PUT")
+	apiv1.HandleFunc("/admin/statistics", a.systemAdminRequired(a.handleAdminGetStatistics)).Methods("GET")
 
 	apiv1.HandleFunc("/login", a.handleLogin).Methods("POST")
+	apiv1.HandleFunc("/logout", a.sessionRequired(a.handleLogout)).Methods("POST")
 	apiv1.HandleFunc("/register", a.handleRegister).Methods("POST")
+	apiv1.HandleFunc("/register/guest", a.handleRegisterGuest).Methods("POST")
 	apiv1.HandleFunc("/clientConfig", a.getClientConfig).Methods("GET")
+	apiv1.HandleFunc("/constants", a.handleGetConstants).Methods("GET")
 
 	apiv1.HandleFunc("/workspaces/{workspaceID}/{rootID}/files", a.sessionRequired(a.handleUploadFile)).Methods("POST")
 
 	apiv1.HandleFunc("/workspaces", a.sessionRequired(a.handleGetUserWorkspaces)).Methods("GET")
 
+	apiv1.HandleFunc("/templates", a.guestForbidden(a.handleGetTemplates)).Methods("GET")
+	apiv1.HandleFunc("/notifications", a.sessionRequired(a.handleGetNotifications)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/subscriptions", a.sessionRequired(a.handleGetSubscriptions)).Methods("GET")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/{blockID}/subscription", a.sessionRequired(a.handleCreateSubscription)).Methods("POST")
+	apiv1.HandleFunc("/workspaces/{workspaceID}/blocks/{blockID}/subscription", a.sessionRequired(a.handleDeleteSubscription)).Methods("DELETE")
+
 	// Get Files API
 
 	files := r.PathPrefix("/files").Subrouter()
+	files.Use(a.compressResponses)
 	files.HandleFunc("/workspaces/{workspaceID}/{rootID}/{filename}", a.attachSession(a.handleServeFile, false)).Methods("GET")
 }
 
 func (a *API) RegisterAdminRoutes(r *mux.Router) {
+	r.Use(a.logRequests)
+
 	r.HandleFunc("/api/v1/admin/users/{username}/password", a.adminRequired(a.handleAdminSetPassword)).Methods("POST")
+	r.HandleFunc("/api/v1/workspaces", a.adminRequired(a.handleAdminGetWorkspaces)).Methods("GET")
+	r.HandleFunc("/api/v1/workspaces/{workspaceID}", a.adminRequired(a.handleAdminDeleteWorkspace)).Methods("DELETE")
+	r.HandleFunc("/api/v1/workspaces/{workspaceID}/webhook_deliveries", a.adminRequired(a.handleAdminGetWebhookDeliveries)).Methods("GET")
+	r.HandleFunc("/api/v1/templates", a.adminRequired(a.handleAdminCreateTemplate)).Methods("POST")
+	r.HandleFunc("/api/v1/admin/cleanup", a.adminRequired(a.handleAdminCleanupFiles)).Methods("POST")
+	r.HandleFunc("/api/v1/admin/audit", a.adminRequired(a.handleAdminGetAuditEntries)).Methods("GET")
+	r.HandleFunc("/api/v1/admin/migrations", a.adminRequired(a.handleAdminGetMigrations)).Methods("GET")
+	r.HandleFunc("/api/v1/admin/workspaces/retention", a.adminRequired(a.handleAdminGetWorkspaceRetention)).Methods("GET")
+	r.HandleFunc("/api/v1/ping", a.adminRequired(a.handlePing)).Methods("GET")
 }
 
 func (a *API) requireCSRFToken(next http.Handler) http.Handler {
@@ -119,6 +301,23 @@ func (a *API) requireCSRFToken(next http.Handler) http.Handler {
 }
 
 func (a *API) getClientConfig(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/clientConfig getClientConfig
+	//
+	// Returns the configuration of the server needed by the clients
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ClientConfig"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
 	clientConfig := a.app.GetClientConfig()
 
 	configData, err := json.Marshal(clientConfig)
@@ -129,26 +328,60 @@ func (a *API) getClientConfig(w http.ResponseWriter, r *http.Request) {
 	jsonBytesResponse(w, http.StatusOK, configData)
 }
 
+func (a *API) handleGetConstants(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/constants getConstants
+	//
+	// Returns the enumerations shared by the server and the clients, like
+	// the types of the card properties and the colors of their options
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Constants"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	data, err := json.Marshal(model.GetConstants())
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
 func (a *API) checkCSRFToken(r *http.Request) bool {
 	token := r.Header.Get(HeaderRequestedWith)
 	return token == HeaderRequestedWithXML
 }
 
-func (a *API) hasValidReadTokenForBlock(r *http.Request, container store.Container, blockID string) bool {
+// hasValidReadTokenForBlock checks the read token of the request. The
+// only error returned is auth.ErrReadTokenExpired, other errors are
+// logged and the token is considered invalid.
+func (a *API) hasValidReadTokenForBlock(r *http.Request, container store.Container, blockID string) (bool, error) {
+	ctx := r.Context()
 	query := r.URL.Query()
 	readToken := query.Get("read_token")
 
 	if len(readToken) < 1 {
-		return false
+		return false, nil
 	}
 
-	isValid, err := a.app.IsValidReadToken(container, blockID, readToken)
+	isValid, err := a.app.IsValidReadToken(ctx, container, blockID, readToken)
+	if errors.Is(err, auth.ErrReadTokenExpired) {
+		return false, err
+	}
 	if err != nil {
 		a.logger.Error("IsValidReadToken ERROR", mlog.Err(err))
-		return false
+		return false, nil
 	}
 
-	return isValid
+	return isValid, nil
 }
 
 func (a *API) getContainerAllowingReadTokenForBlock(r *http.Request, blockID string) (*store.Container, error) {
@@ -169,19 +402,28 @@ func (a *API) getContainerAllowingReadTokenForBlock(r *http.Request, blockID str
 		}
 
 		// Has session and access to workspace
-		if session != nil && a.app.DoesUserHaveWorkspaceAccess(session.UserID, container.WorkspaceID) {
+		if session != nil && a.app.DoesUserHaveWorkspaceAccess(ctx, session.UserID, container.WorkspaceID) {
 			return &container, nil
 		}
 
 		// No session, but has valid read token (read-only mode)
-		if len(blockID) > 0 && a.hasValidReadTokenForBlock(r, container, blockID) {
-			return &container, nil
+		if len(blockID) > 0 {
+			isValid, err := a.hasValidReadTokenForBlock(r, container, blockID)
+			if err != nil {
+				return nil, err
+			}
+			if isValid {
+				return &container, nil
+			}
 		}
 
 		return nil, PermissionError{"access denied to workspace"}
 	}
 
 	// Native auth: always use root workspace
+	if workspaceID := mux.Vars(r)["workspaceID"]; workspaceID != "" && workspaceID != "0" {
+		return nil, errWorkspaceMismatch
+	}
 	container := store.Container{
 		WorkspaceID: "0",
 	}
@@ -192,8 +434,14 @@ func (a *API) getContainerAllowingReadTokenForBlock(r *http.Request, blockID str
 	}
 
 	// No session, but has valid read token (read-only mode)
-	if len(blockID) > 0 && a.hasValidReadTokenForBlock(r, container, blockID) {
-		return &container, nil
+	if len(blockID) > 0 {
+		isValid, err := a.hasValidReadTokenForBlock(r, container, blockID)
+		if err != nil {
+			return nil, err
+		}
+		if isValid {
+			return &container, nil
+		}
 	}
 
 	return nil, PermissionError{"access denied to workspace"}
@@ -203,6 +451,43 @@ func (a *API) getContainer(r *http.Request) (*store.Container, error) {
 	return a.getContainerAllowingReadTokenForBlock(r, "")
 }
 
+// getContainerForBlock returns the container of the block, like
+// getContainerAllowingReadTokenForBlock, when the user of the session
+// also has the role on the board of the block. A valid read token of the
+// board lets the users that aren't its members view it.
+func (a *API) getContainerForBlock(r *http.Request, blockID, role string) (*store.Container, error) {
+	container, err := a.getContainerAllowingReadTokenForBlock(r, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, _ := r.Context().Value(sessionContextKey).(*model.Session)
+	if session == nil {
+		return container, nil
+	}
+
+	err = a.app.CheckBlockAccess(r.Context(), *container, session.UserID, blockID, role)
+	if errors.Is(err, app.ErrBoardAccessDenied) && role == model.BoardRoleViewer {
+		if isValid, _ := a.hasValidReadTokenForBlock(r, *container, blockID); isValid {
+			return container, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return container, nil
+}
+
+// filterBlocksForSession returns the blocks that the user of the session
+// can view, or all of them for the read tokens.
+func (a *API) filterBlocksForSession(r *http.Request, container store.Container, blocks []model.Block) ([]model.Block, error) {
+	session, _ := r.Context().Value(sessionContextKey).(*model.Session)
+	if session == nil {
+		return blocks, nil
+	}
+	return a.app.FilterBlocksForUser(container, session.UserID, blocks)
+}
+
 func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation GET /api/v1/workspaces/{workspaceID}/blocks getBlocks
 	//
@@ -227,24 +512,59 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 	//   description: Type of blocks to return, omit to specify all types
 	//   required: false
 	//   type: string
+	// - name: view_id
+	//   in: query
+	//   description: ID of a view, to return its board, the child blocks of the board and only the cards meeting the filter of the view
+	//   required: false
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: Maximum number of blocks to return, defaults to 500. With limit or after, the response is a page of blocks
+	//   required: false
+	//   type: integer
+	// - name: after
+	//   in: query
+	//   description: Cursor of the page, the nextCursor of the previous page
+	//   required: false
+	//   type: string
+	// - name: If-None-Match
+	//   in: header
+	//   description: ETag of the blocks the client already has, ignored with view_id, limit and after
+	//   required: false
+	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
-	//     description: success
+	//     description: success, a BlocksPage with limit or after
+	//     headers:
+	//       ETag:
+	//         type: string
+	//       X-Server-Filter:
+	//         description: with view_id, complete if the filter of the view was applied, partial if the cards still have to be filtered
+	//         type: string
 	//     schema:
 	//       type: array
 	//       items:
 	//         "$ref": "#/definitions/Block"
+	//   '304':
+	//     description: the blocks didn't change since the ETag of If-None-Match
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
+	ctx := r.Context()
 	query := r.URL.Query()
 	parentID := query.Get("parent_id")
 	blockType := query.Get("type")
 	all := query.Get("all")
+	viewID := query.Get("view_id")
+	if viewID != "" {
+		a.handleGetViewBlocks(w, r, viewID)
+		return
+	}
+
 	container, err := a.getContainer(r)
 	if err != nil {
 		a.noContainerErrorResponse(w, r.URL.Path, err)
@@ -257,20 +577,73 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("blockType", blockType)
 	auditRec.AddMeta("all", all)
 
+	// the clients that don't page get a bare array, as they always did
+	_, hasLimit := query["limit"]
+	_, hasAfter := query["after"]
+	if hasLimit || hasAfter {
+		opts := model.QueryBlocksPageOptions{
+			ParentID:  parentID,
+			BlockType: blockType,
+			All:       all != "",
+			Limit:     model.BlocksDefaultPageSize,
+		}
+		if limitStr := query.Get("limit"); limitStr != "" {
+			opts.Limit, err = strconv.Atoi(limitStr)
+			if err != nil || opts.Limit <= 0 || opts.Limit > model.BlocksMaxPageSize {
+				a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid limit", err)
+				return
+			}
+		}
+		if after := query.Get("after"); after != "" {
+			opts.After, err = model.ParseBlocksCursor(after)
+			if err != nil {
+				a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid after", err)
+				return
+			}
+		}
+		a.getBlocksPage(w, r, *container, opts, auditRec)
+		return
+	}
+
+	// the digest is much cheaper to get than the blocks, so the clients
+	// that already have the latest blocks don't get them again
+	digest, err := a.app.GetBlocksDigest(ctx, *container, model.QueryBlocksDigestOptions{
+		ParentID:  parentID,
+		BlockType: blockType,
+		All:       all != "",
+	})
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	etag := blocksETag(digest)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		auditRec.AddMeta("notModified", true)
+		auditRec.Success()
+		return
+	}
+
 	var blocks []model.Block
 	if all != "" {
-		blocks, err = a.app.GetAllBlocks(*container)
+		blocks, err = a.app.GetAllBlocks(ctx, *container)
 		if err != nil {
 			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 			return
 		}
 	} else {
-		blocks, err = a.app.GetBlocks(*container, parentID, blockType)
+		blocks, err = a.app.GetBlocks(ctx, *container, parentID, blockType)
 		if err != nil {
 			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 			return
 		}
 	}
+	blocks, err = a.filterBlocksForSession(r, *container, blocks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
 
 	a.logger.Debug("GetBlocks",
 		mlog.String("parentID", parentID),
@@ -290,6 +663,106 @@ func (a *API) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
 	auditRec.Success()
 }
 
+// getBlocksPage writes a page of the blocks. The ETags aren't supported,
+// as the digest of the blocks covers all the pages.
+func (a *API) getBlocksPage(w http.ResponseWriter, r *http.Request, container store.Container, opts model.QueryBlocksPageOptions, auditRec *audit.Record) {
+	page, err := a.app.GetBlocksPage(r.Context(), container, opts)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	page.Blocks, err = a.filterBlocksForSession(r, container, page.Blocks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("GetBlocksPage",
+		mlog.String("parentID", opts.ParentID),
+		mlog.String("blockType", opts.BlockType),
+		mlog.Int("block_count", len(page.Blocks)),
+	)
+
+	data, err := json.Marshal(page)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("blockCount", len(page.Blocks))
+	auditRec.Success()
+}
+
+// handleGetViewBlocks returns the blocks of the board of the view, with
+// only the cards meeting the filter of the view. The ETags aren't
+// supported, as the digest of the blocks doesn't cover the filter.
+func (a *API) handleGetViewBlocks(w http.ResponseWriter, r *http.Request, viewID string) {
+	ctx := r.Context()
+	container, err := a.getContainerForBlock(r, viewID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getViewBlocks", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("viewID", viewID)
+
+	blocks, complete, err := a.app.GetViewBlocks(ctx, *container, viewID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	blocks, err = a.filterBlocksForSession(r, *container, blocks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("GetViewBlocks",
+		mlog.String("viewID", viewID),
+		mlog.Bool("complete", complete),
+		mlog.Int("block_count", len(blocks)),
+	)
+
+	json, err := json.Marshal(blocks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	if complete {
+		w.Header().Set(HeaderServerFilter, HeaderServerFilterComplete)
+	} else {
+		w.Header().Set(HeaderServerFilter, HeaderServerFilterPartial)
+	}
+	jsonBytesResponse(w, http.StatusOK, json)
+
+	auditRec.AddMeta("blockCount", len(blocks))
+	auditRec.AddMeta("complete", complete)
+	auditRec.Success()
+}
+
+// blocksETag returns the entity tag of the blocks summarized by the
+// digest.
+func blocksETag(digest *model.BlocksDigest) string {
+	return fmt.Sprintf(`"%d-%d"`, digest.Count, digest.UpdateAt)
+}
+
+// etagMatches tells if the If-None-Match header of the request matches
+// the entity tag, the weak tags of the compressed responses matching too.
+func etagMatches(r *http.Request, etag string) bool {
+	for _, value := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		value = strings.TrimPrefix(strings.TrimSpace(value), "W/")
+		if value == etag || value == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 func stampModificationMetadata(r *http.Request, blocks []model.Block, auditRec *audit.Record) {
 	ctx := r.Context()
 	session := ctx.Value(sessionContextKey).(*model.Session)
@@ -323,6 +796,11 @@ func (a *API) handlePostBlocks(w http.ResponseWriter, r *http.Request) {
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
+	// - name: Idempotency-Key
+	//   in: header
+	//   description: Key of the request, for its retries to get its response instead of creating the blocks again
+	//   required: false
+	//   type: string
 	// - name: Body
 	//   in: body
 	//   description: array of blocks to insert or update
@@ -336,6 +814,16 @@ func (a *API) handlePostBlocks(w http.ResponseWriter, r *http.Request) {
 	// responses:
 	//   '200':
 	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/BlocksUpsertResult"
+	//   '400':
+	//     description: invalid block, with the invalid_block code and the blockId detail
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: the board of the block is archived, or the idempotency key was used for another request
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -347,9 +835,9 @@ func (a *API) handlePostBlocks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	requestBody, err := ioutil.ReadAll(r.Body)
+	requestBody, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, BlocksMaxBodySize))
 	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		a.errorResponse(w, r.URL.Path, http.StatusRequestEntityTooLarge, "the request is too large", err)
 		return
 	}
 
@@ -357,27 +845,25 @@ func (a *API) handlePostBlocks(w http.ResponseWriter, r *http.Request) {
 
 	err = json.Unmarshal(requestBody, &blocks)
 	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
 		return
 	}
 
 	for _, block := range blocks {
 		// Error checking
-		if len(block.Type) < 1 {
-			message := fmt.Sprintf("missing type for block id %s", block.ID)
-			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, message, nil)
-			return
+		var message string
+		switch {
+		case len(block.Type) < 1:
+			message = fmt.Sprintf("missing type for block id %s", block.ID)
+		case block.CreateAt < 1:
+			message = fmt.Sprintf("invalid createAt for block id %s", block.ID)
+		case block.UpdateAt < 1:
+			message = fmt.Sprintf("invalid UpdateAt for block id %s", block.ID)
 		}
 
-		if block.CreateAt < 1 {
-			message := fmt.Sprintf("invalid createAt for block id %s", block.ID)
-			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, message, nil)
-			return
-		}
-
-		if block.UpdateAt < 1 {
-			message := fmt.Sprintf("invalid UpdateAt for block id %s", block.ID)
-			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, message, nil)
+		if message != "" {
+			details := map[string]interface{}{"blockId": block.ID}
+			a.errorResponseWithDetails(w, r.URL.Path, http.StatusBadRequest, model.ErrorCodeInvalidBlock, message, details, nil)
 			return
 		}
 	}
@@ -390,14 +876,34 @@ func (a *API) handlePostBlocks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	session := ctx.Value(sessionContextKey).(*model.Session)
 
-	err = a.app.InsertBlocks(*container, blocks, session.UserID)
+	// the content of the default card template is inserted along with the
+	// new blank cards
+	blocks, err = a.app.ApplyDefaultCardTemplate(ctx, *container, blocks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	result, err := a.app.InsertBlocks(ctx, *container, blocks, session.UserID)
+	if a.invalidBlockResponse(w, r.URL.Path, err) {
+		return
+	}
+	if a.quotaExceededResponse(w, r.URL.Path, err) {
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(result)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
 	a.logger.Debug("POST Blocks", mlog.Int("block_count", len(blocks)))
-	jsonStringResponse(w, http.StatusOK, "{}")
+	jsonBytesResponse(w, http.StatusOK, data)
 
 	auditRec.AddMeta("blockCount", len(blocks))
 	auditRec.Success()
@@ -424,6 +930,10 @@ func (a *API) handleGetUser(w http.ResponseWriter, r *http.Request) {
 	//     description: success
 	//     schema:
 	//       "$ref": "#/definitions/User"
+	//   '404':
+	//     description: user not found
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -509,30 +1019,33 @@ func (a *API) handleGetMe(w http.ResponseWriter, r *http.Request) {
 	auditRec.Success()
 }
 
-func (a *API) handleDeleteBlock(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation DELETE /api/v1/workspaces/{workspaceID}/blocks/{blockID} deleteBlock
+func (a *API) handleUpdateDigestSettings(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /api/v1/users/me/digest updateDigestSettings
 	//
-	// Deletes a block
+	// Sets how often the currently logged-in user receives the email
+	// digest of the boards they watch
 	//
 	// ---
 	// produces:
 	// - application/json
 	// parameters:
-	// - name: workspaceID
-	//   in: path
-	//   description: Workspace ID
-	//   required: true
-	//   type: string
-	// - name: blockID
-	//   in: path
-	//   description: ID of block to delete
+	// - name: Body
+	//   in: body
+	//   description: the digest settings
 	//   required: true
-	//   type: string
+	//   schema:
+	//     "$ref": "#/definitions/DigestSettings"
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/DigestSettings"
+	//   '400':
+	//     description: invalid frequency
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -540,37 +1053,49 @@ func (a *API) handleDeleteBlock(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 	session := ctx.Value(sessionContextKey).(*model.Session)
-	userID := session.UserID
 
-	vars := mux.Vars(r)
-	blockID := vars["blockID"]
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
 
-	container, err := a.getContainer(r)
+	var settings model.DigestSettings
+	err = json.Unmarshal(requestBody, &settings)
 	if err != nil {
-		a.noContainerErrorResponse(w, r.URL.Path, err)
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
 		return
 	}
 
-	auditRec := a.makeAuditRecord(r, "deleteBlock", audit.Fail)
+	auditRec := a.makeAuditRecord(r, "updateDigestSettings", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
-	auditRec.AddMeta("blockID", blockID)
+	auditRec.AddMeta("frequency", settings.Frequency)
+
+	updated, err := a.app.UpdateDigestFrequency(session.UserID, settings.Frequency)
+	if errors.Is(err, app.ErrInvalidDigestFrequency) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
 
-	err = a.app.DeleteBlock(*container, blockID, userID)
+	data, err := json.Marshal(updated)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	a.logger.Debug("DELETE Block", mlog.String("blockID", blockID))
-	jsonStringResponse(w, http.StatusOK, "{}")
+	jsonBytesResponse(w, http.StatusOK, data)
 
 	auditRec.Success()
 }
 
-func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation PATCH /api/v1/workspaces/{workspaceID}/blocks/{blockID} patchBlock
+func (a *API) handleDeleteBlock(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /api/v1/workspaces/{workspaceID}/blocks/{blockID} deleteBlock
 	//
-	// Partially updates a block
+	// Deletes a block
 	//
 	// ---
 	// produces:
@@ -583,20 +1108,22 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 	//   type: string
 	// - name: blockID
 	//   in: path
-	//   description: ID of block to patch
+	//   description: ID of block to delete
 	//   required: true
 	//   type: string
-	// - name: Body
-	//   in: body
-	//   description: block patch to apply
-	//   required: true
-	//   schema:
-	//     "$ref": "#/definitions/BlockPatch"
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
+	//   '404':
+	//     description: block not found
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: the board of the block is archived
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
@@ -615,39 +1142,26 @@ func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	requestBody, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
-		return
-	}
-
-	var patch *model.BlockPatch
-	err = json.Unmarshal(requestBody, &patch)
-	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
-		return
-	}
-
-	auditRec := a.makeAuditRecord(r, "patchBlock", audit.Fail)
+	auditRec := a.makeAuditRecord(r, "deleteBlock", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("blockID", blockID)
 
-	err = a.app.PatchBlock(*container, blockID, patch, userID)
+	err = a.app.DeleteBlock(ctx, *container, blockID, userID)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	a.logger.Debug("PATCH Block", mlog.String("blockID", blockID))
+	a.logger.Debug("DELETE Block", mlog.String("blockID", blockID))
 	jsonStringResponse(w, http.StatusOK, "{}")
 
 	auditRec.Success()
 }
 
-func (a *API) handleGetSubTree(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation GET /api/v1/workspaces/{workspaceID}/blocks/{blockID}/subtree getSubTree
+func (a *API) handleUndeleteBlock(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/blocks/{blockID}/undelete undeleteBlock
 	//
-	// Returns the blocks of a subtree
+	// Restores a block from the trash
 	//
 	// ---
 	// produces:
@@ -660,82 +1174,72 @@ func (a *API) handleGetSubTree(w http.ResponseWriter, r *http.Request) {
 	//   type: string
 	// - name: blockID
 	//   in: path
-	//   description: The ID of the root block of the subtree
+	//   description: ID of block to restore
 	//   required: true
 	//   type: string
-	// - name: l
-	//   in: query
-	//   description: The number of levels to return. 2 or 3. Defaults to 2.
-	//   required: false
-	//   type: integer
-	//   minimum: 2
-	//   maximum: 3
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
 	//     schema:
-	//       type: array
-	//       items:
-	//         "$ref": "#/definitions/Block"
+	//       "$ref": "#/definitions/Block"
+	//   '404':
+	//     description: block not found
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: the board of the block is archived
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+
 	vars := mux.Vars(r)
 	blockID := vars["blockID"]
 
-	container, err := a.getContainerAllowingReadTokenForBlock(r, blockID)
+	container, err := a.getContainer(r)
 	if err != nil {
 		a.noContainerErrorResponse(w, r.URL.Path, err)
 		return
 	}
 
-	query := r.URL.Query()
-	levels, err := strconv.ParseInt(query.Get("l"), 10, 32)
-	if err != nil {
-		levels = 2
-	}
-
-	if levels != 2 && levels != 3 {
-		a.logger.Error("Invalid levels", mlog.Int64("levels", levels))
-		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid levels", nil)
-		return
-	}
-
-	auditRec := a.makeAuditRecord(r, "getSubTree", audit.Fail)
-	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec := a.makeAuditRecord(r, "undeleteBlock", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
 	auditRec.AddMeta("blockID", blockID)
 
-	blocks, err := a.app.GetSubTree(*container, blockID, int(levels))
+	block, err := a.app.UndeleteBlock(ctx, *container, blockID, userID)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
+	if block == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
 
-	a.logger.Debug("GetSubTree",
-		mlog.Int64("levels", levels),
-		mlog.String("blockID", blockID),
-		mlog.Int("block_count", len(blocks)),
-	)
-	json, err := json.Marshal(blocks)
+	data, err := json.Marshal(block)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	jsonBytesResponse(w, http.StatusOK, json)
+	a.logger.Debug("UNDELETE Block", mlog.String("blockID", blockID))
+	jsonBytesResponse(w, http.StatusOK, data)
 
-	auditRec.AddMeta("blockCount", len(blocks))
 	auditRec.Success()
 }
 
-func (a *API) handleExport(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation GET /api/v1/workspaces/{workspaceID}/blocks/export exportBlocks
+func (a *API) handleGetDeletedBlocks(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/blocks/trash getDeletedBlocks
 	//
-	// Returns all blocks
+	// Returns the blocks in the trash
 	//
 	// ---
 	// produces:
@@ -746,6 +1250,11 @@ func (a *API) handleExport(w http.ResponseWriter, r *http.Request) {
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only return blocks deleted after this timestamp, in milliseconds
+	//   required: false
+	//   type: integer
 	// security:
 	// - BearerAuth: []
 	// responses:
@@ -760,87 +1269,61 @@ func (a *API) handleExport(w http.ResponseWriter, r *http.Request) {
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
+	ctx := r.Context()
 	query := r.URL.Query()
-	rootID := query.Get("root_id")
+
+	var since int64
+	if sinceStr := query.Get("since"); sinceStr != "" {
+		var err error
+		since, err = strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil || since < 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid since", err)
+			return
+		}
+	}
+
 	container, err := a.getContainer(r)
 	if err != nil {
 		a.noContainerErrorResponse(w, r.URL.Path, err)
 		return
 	}
 
-	auditRec := a.makeAuditRecord(r, "export", audit.Fail)
+	auditRec := a.makeAuditRecord(r, "getDeletedBlocks", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelRead, auditRec)
-	auditRec.AddMeta("rootID", rootID)
+	auditRec.AddMeta("since", since)
 
-	var blocks []model.Block
-	if rootID == "" {
-		blocks, err = a.app.GetAllBlocks(*container)
-	} else {
-		blocks, err = a.app.GetBlocksWithRootID(*container, rootID)
+	blocks, err := a.app.GetDeletedBlocks(ctx, *container, since)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
 	}
+	blocks, err = a.filterBlocksForSession(r, *container, blocks)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	a.logger.Debug("raw blocks", mlog.Int("block_count", len(blocks)))
-	auditRec.AddMeta("rawCount", len(blocks))
-
-	blocks = filterOrphanBlocks(blocks)
-
-	a.logger.Debug("EXPORT filtered blocks", mlog.Int("block_count", len(blocks)))
-	auditRec.AddMeta("filteredCount", len(blocks))
+	a.logger.Debug("GetDeletedBlocks",
+		mlog.String("workspaceID", container.WorkspaceID),
+		mlog.Int("block_count", len(blocks)),
+	)
 
-	json, err := json.Marshal(blocks)
+	data, err := json.Marshal(blocks)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	jsonBytesResponse(w, http.StatusOK, json)
+	jsonBytesResponse(w, http.StatusOK, data)
 
+	auditRec.AddMeta("blockCount", len(blocks))
 	auditRec.Success()
 }
 
-func filterOrphanBlocks(blocks []model.Block) (ret []model.Block) {
-	queue := make([]model.Block, 0)
-	childrenOfBlockWithID := make(map[string]*[]model.Block)
-
-	// Build the trees from nodes
-	for _, block := range blocks {
-		if len(block.ParentID) == 0 {
-			// Queue root blocks to process first
-			queue = append(queue, block)
-		} else {
-			siblings := childrenOfBlockWithID[block.ParentID]
-			if siblings != nil {
-				*siblings = append(*siblings, block)
-			} else {
-				siblings := []model.Block{block}
-				childrenOfBlockWithID[block.ParentID] = &siblings
-			}
-		}
-	}
-
-	// Map the trees to an array, which skips orphaned nodes
-	blocks = make([]model.Block, 0)
-	for len(queue) > 0 {
-		block := queue[0]
-		queue = queue[1:] // dequeue
-		blocks = append(blocks, block)
-		children := childrenOfBlockWithID[block.ID]
-		if children != nil {
-			queue = append(queue, (*children)...)
-		}
-	}
-
-	return blocks
-}
-
-func (a *API) handleImport(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation POST /api/v1/workspaces/{workspaceID}/blocks/import importBlocks
+func (a *API) handleGetBlockHistory(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/blocks/{blockID}/history getBlockHistory
 	//
-	// Import blocks
+	// Returns the previous versions of a block
 	//
 	// ---
 	// produces:
@@ -851,70 +1334,96 @@ func (a *API) handleImport(w http.ResponseWriter, r *http.Request) {
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
-	// - name: Body
-	//   in: body
-	//   description: array of blocks to import
+	// - name: blockID
+	//   in: path
+	//   description: ID of the block
 	//   required: true
-	//   schema:
-	//     type: array
-	//     items:
-	//       "$ref": "#/definitions/Block"
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: Maximum number of versions to return, omit to return all of them
+	//   required: false
+	//   type: integer
+	// - name: descending
+	//   in: query
+	//   description: Return the most recent versions first
+	//   required: false
+	//   type: boolean
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Block"
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
-	container, err := a.getContainer(r)
-	if err != nil {
-		a.noContainerErrorResponse(w, r.URL.Path, err)
-		return
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	blockID := vars["blockID"]
+	query := r.URL.Query()
+
+	opts := model.QueryBlockHistoryOptions{}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.ParseUint(limitStr, 10, 64)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+		opts.Limit = limit
+	}
+	if descendingStr := query.Get("descending"); descendingStr != "" {
+		descending, err := strconv.ParseBool(descendingStr)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid descending", err)
+			return
+		}
+		opts.Descending = descending
 	}
 
-	requestBody, err := ioutil.ReadAll(r.Body)
+	container, err := a.getContainerForBlock(r, blockID, model.BoardRoleViewer)
 	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		a.noContainerErrorResponse(w, r.URL.Path, err)
 		return
 	}
 
-	var blocks []model.Block
+	auditRec := a.makeAuditRecord(r, "getBlockHistory", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("blockID", blockID)
 
-	err = json.Unmarshal(requestBody, &blocks)
+	blocks, err := a.app.GetBlockHistory(ctx, *container, blockID, opts)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	auditRec := a.makeAuditRecord(r, "import", audit.Fail)
-	defer a.audit.LogRecord(audit.LevelModify, auditRec)
-
-	stampModificationMetadata(r, blocks, auditRec)
+	a.logger.Debug("GetBlockHistory",
+		mlog.String("blockID", blockID),
+		mlog.Int("version_count", len(blocks)),
+	)
 
-	ctx := r.Context()
-	session := ctx.Value(sessionContextKey).(*model.Session)
-	err = a.app.InsertBlocks(*container, blocks, session.UserID)
+	data, err := json.Marshal(blocks)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	jsonStringResponse(w, http.StatusOK, "{}")
+	jsonBytesResponse(w, http.StatusOK, data)
 
-	a.logger.Debug("IMPORT Blocks", mlog.Int("block_count", len(blocks)))
-	auditRec.AddMeta("blockCount", len(blocks))
+	auditRec.AddMeta("versionCount", len(blocks))
 	auditRec.Success()
 }
 
-// Sharing
-
-func (a *API) handleGetSharing(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation GET /api/v1/workspaces/{workspaceID}/sharing/{rootID} getSharing
+func (a *API) handleGetBoardMetadata(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/metadata getBoardMetadata
 	//
-	// Returns sharing information for a root block
+	// Returns the comment count, last content update, checklist progress
+	// and values of the computed properties of the cards of a board
 	//
 	// ---
 	// produces:
@@ -925,9 +1434,9 @@ func (a *API) handleGetSharing(w http.ResponseWriter, r *http.Request) {
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
-	// - name: rootID
+	// - name: boardID
 	//   in: path
-	//   description: ID of the root block
+	//   description: ID of the board
 	//   required: true
 	//   type: string
 	// security:
@@ -936,56 +1445,57 @@ func (a *API) handleGetSharing(w http.ResponseWriter, r *http.Request) {
 	//   '200':
 	//     description: success
 	//     schema:
-	//       "$ref": "#/definitions/Sharing"
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/CardMetadata"
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
-	vars := mux.Vars(r)
-	rootID := vars["rootID"]
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
 
-	container, err := a.getContainer(r)
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleViewer)
 	if err != nil {
 		a.noContainerErrorResponse(w, r.URL.Path, err)
 		return
 	}
 
-	auditRec := a.makeAuditRecord(r, "getSharing", audit.Fail)
+	auditRec := a.makeAuditRecord(r, "getBoardMetadata", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelRead, auditRec)
-	auditRec.AddMeta("rootID", rootID)
+	auditRec.AddMeta("boardID", boardID)
 
-	sharing, err := a.app.GetSharing(*container, rootID)
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	metadata, err := a.app.GetBoardMetadata(ctx, *container, session.UserID, boardID)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	sharingData, err := json.Marshal(sharing)
+	a.logger.Debug("GetBoardMetadata",
+		mlog.String("boardID", boardID),
+		mlog.Int("card_count", len(metadata)),
+	)
+
+	data, err := json.Marshal(metadata)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	jsonBytesResponse(w, http.StatusOK, sharingData)
+	jsonBytesResponse(w, http.StatusOK, data)
 
-	if sharing == nil {
-		sharing = &model.Sharing{}
-	}
-	a.logger.Debug("GET sharing",
-		mlog.String("rootID", rootID),
-		mlog.String("shareID", sharing.ID),
-		mlog.Bool("enabled", sharing.Enabled),
-	)
-	auditRec.AddMeta("shareID", sharing.ID)
-	auditRec.AddMeta("enabled", sharing.Enabled)
+	auditRec.AddMeta("cardCount", len(metadata))
 	auditRec.Success()
 }
 
-func (a *API) handlePostSharing(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation POST /api/v1/workspaces/{workspaceID}/sharing/{rootID} postSharing
+func (a *API) handleGetBoards(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards getBoards
 	//
-	// Sets sharing information for a root block
+	// Returns the boards of the workspace, without the templates. The
+	// archived boards are only returned with the archived parameter, to
+	// restore them
 	//
 	// ---
 	// produces:
@@ -996,79 +1506,68 @@ func (a *API) handlePostSharing(w http.ResponseWriter, r *http.Request) {
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
-	// - name: rootID
-	//   in: path
-	//   description: ID of the root block
-	//   required: true
-	//   type: string
-	// - name: Body
-	//   in: body
-	//   description: sharing information for a root block
-	//   required: true
-	//   schema:
-	//     "$ref": "#/definitions/Sharing"
+	// - name: archived
+	//   in: query
+	//   description: true to return the archived boards instead of the others
+	//   required: false
+	//   type: boolean
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
-	//   default:
-	//     description: internal error
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Block"
+	//   default:
+	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
 	container, err := a.getContainer(r)
 	if err != nil {
 		a.noContainerErrorResponse(w, r.URL.Path, err)
 		return
 	}
 
-	requestBody, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
-		return
+	archived := false
+	if value := r.URL.Query().Get("archived"); value != "" {
+		if archived, err = strconv.ParseBool(value); err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid archived parameter", err)
+			return
+		}
 	}
 
-	var sharing model.Sharing
-
-	err = json.Unmarshal(requestBody, &sharing)
+	boards, err := a.app.GetBoards(ctx, *container, session.UserID, archived)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	auditRec := a.makeAuditRecord(r, "postSharing", audit.Fail)
-	defer a.audit.LogRecord(audit.LevelModify, auditRec)
-	auditRec.AddMeta("shareID", sharing.ID)
-	auditRec.AddMeta("enabled", sharing.Enabled)
-
-	// Stamp ModifiedBy
-	ctx := r.Context()
-	session := ctx.Value(sessionContextKey).(*model.Session)
-	userID := session.UserID
-	if userID == SingleUser {
-		userID = ""
-	}
-	sharing.ModifiedBy = userID
+	a.logger.Debug("GetBoards",
+		mlog.String("workspaceID", container.WorkspaceID),
+		mlog.Bool("archived", archived),
+		mlog.Int("board_count", len(boards)),
+	)
 
-	err = a.app.UpsertSharing(*container, sharing)
+	data, err := json.Marshal(boards)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	jsonStringResponse(w, http.StatusOK, "{}")
-
-	a.logger.Debug("POST sharing", mlog.String("sharingID", sharing.ID))
-	auditRec.Success()
+	jsonBytesResponse(w, http.StatusOK, data)
 }
 
-// Workspace
-
-func (a *API) handleGetWorkspace(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation GET /api/v1/workspaces/{workspaceID} getWorkspace
+func (a *API) handleGetBoardPresence(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/presence getBoardPresence
 	//
-	// Returns information of the root workspace
+	// Returns the IDs of the users viewing a board. The changes are sent
+	// over the websocket as PRESENCE_JOIN and PRESENCE_LEAVE messages.
 	//
 	// ---
 	// produces:
@@ -1079,206 +1578,204 @@ func (a *API) handleGetWorkspace(w http.ResponseWriter, r *http.Request) {
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
 	//     schema:
-	//       "$ref": "#/definitions/Workspace"
+	//       type: array
+	//       items:
+	//         type: string
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
-	var workspace *model.Workspace
-	var err error
-
-	if a.MattermostAuth {
-		vars := mux.Vars(r)
-		workspaceID := vars["workspaceID"]
-
-		ctx := r.Context()
-		session := ctx.Value(sessionContextKey).(*model.Session)
-		if !a.app.DoesUserHaveWorkspaceAccess(session.UserID, workspaceID) {
-			a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "user does not have workspace access", nil)
-			return
-		}
+	boardID := mux.Vars(r)["boardID"]
 
-		workspace, err = a.app.GetWorkspace(workspaceID)
-		if err != nil {
-			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
-		}
-		if workspace == nil {
-			a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "invalid workspace", nil)
-			return
-		}
-	} else {
-		workspace, err = a.app.GetRootWorkspace()
-		if err != nil {
-			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
-			return
-		}
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
 	}
 
-	auditRec := a.makeAuditRecord(r, "getWorkspace", audit.Fail)
-	defer a.audit.LogRecord(audit.LevelRead, auditRec)
-	auditRec.AddMeta("resultWorkspaceID", workspace.ID)
+	viewers := a.app.GetBoardPresence(*container, boardID)
 
-	workspaceData, err := json.Marshal(workspace)
+	data, err := json.Marshal(viewers)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	jsonBytesResponse(w, http.StatusOK, workspaceData)
-	auditRec.Success()
+	jsonBytesResponse(w, http.StatusOK, data)
 }
 
-func (a *API) handlePostWorkspaceRegenerateSignupToken(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation POST /api/v1/workspaces/{workspaceID}/regenerate_signup_token regenerateSignupToken
+func (a *API) handleExportBoardCSV(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/export/csv exportBoardCSV
 	//
-	// Regenerates the signup token for the root workspace
+	// Exports the cards of a board as CSV
 	//
 	// ---
 	// produces:
-	// - application/json
+	// - text/csv
 	// parameters:
 	// - name: workspaceID
 	//   in: path
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: viewID
+	//   in: query
+	//   description: ID of the view whose visible properties are exported, omit to export all the properties
+	//   required: false
+	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
+	//   '404':
+	//     description: board or view not found
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
-	workspace, err := a.app.GetRootWorkspace()
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+	viewID := r.URL.Query().Get("viewID")
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleViewer)
 	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		a.noContainerErrorResponse(w, r.URL.Path, err)
 		return
 	}
 
-	auditRec := a.makeAuditRecord(r, "regenerateSignupToken", audit.Fail)
-	defer a.audit.LogRecord(audit.LevelModify, auditRec)
-
-	workspace.SignupToken = utils.CreateGUID()
+	auditRec := a.makeAuditRecord(r, "exportBoardCSV", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("viewID", viewID)
 
-	err = a.app.UpsertWorkspaceSignupToken(*workspace)
+	export, err := a.app.NewBoardCSVExport(ctx, *container, boardID, viewID)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
+	if export == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
 
-	jsonStringResponse(w, http.StatusOK, "{}")
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.Filename()))
+	w.WriteHeader(http.StatusOK)
+
+	// the rows are streamed, so once they are being written errors can
+	// only be logged
+	if err := export.Write(ctx, w); err != nil {
+		a.logger.Error("ExportBoardCSV failed", mlog.String("boardID", boardID), mlog.Err(err))
+		return
+	}
+
+	a.logger.Debug("ExportBoardCSV", mlog.String("boardID", boardID))
 	auditRec.Success()
 }
 
-// File upload
-
-func (a *API) handleServeFile(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation GET /workspaces/{workspaceID}/{rootID}/{fileID} getFile
+func (a *API) handleExportBoardMarkdown(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/export/markdown exportBoardMarkdown
 	//
-	// Returns the contents of an uploaded file
+	// Exports a board as a Markdown document, with a section per group of
+	// cards of the view
 	//
 	// ---
 	// produces:
-	// - application/json
-	// - image/jpg
-	// - image/png
+	// - text/markdown
 	// parameters:
 	// - name: workspaceID
 	//   in: path
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
-	// - name: rootID
+	// - name: boardID
 	//   in: path
-	//   description: ID of the root block
+	//   description: ID of the board
 	//   required: true
 	//   type: string
-	// - name: fileID
-	//   in: path
-	//   description: ID of the file
-	//   required: true
+	// - name: viewID
+	//   in: query
+	//   description: ID of the view whose grouping and visible properties are exported, omit for the first view of the board
+	//   required: false
 	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
+	//   '404':
+	//     description: board or view not found
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
-	vars := mux.Vars(r)
-	workspaceID := vars["workspaceID"]
-	rootID := vars["rootID"]
-	filename := vars["filename"]
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+	viewID := r.URL.Query().Get("viewID")
 
-	// Caller must have access to the root block's container
-	_, err := a.getContainerAllowingReadTokenForBlock(r, rootID)
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleViewer)
 	if err != nil {
 		a.noContainerErrorResponse(w, r.URL.Path, err)
 		return
 	}
 
-	auditRec := a.makeAuditRecord(r, "getFile", audit.Fail)
+	auditRec := a.makeAuditRecord(r, "exportBoardMarkdown", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelRead, auditRec)
-	auditRec.AddMeta("rootID", rootID)
-	auditRec.AddMeta("filename", filename)
-
-	contentType := "image/jpg"
-
-	fileExtension := strings.ToLower(filepath.Ext(filename))
-	if fileExtension == "png" {
-		contentType = "image/png"
-	}
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("viewID", viewID)
 
-	w.Header().Set("Content-Type", contentType)
-
-	fileReader, err := a.app.GetFileReader(workspaceID, rootID, filename)
+	export, err := a.app.NewBoardMarkdownExport(ctx, *container, boardID, viewID)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
-	defer fileReader.Close()
-	http.ServeContent(w, r, filename, time.Now(), fileReader)
-	auditRec.Success()
-}
+	if export == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
 
-// FileUploadResponse is the response to a file upload
-// swagger:model
-type FileUploadResponse struct {
-	// The FileID to retrieve the uploaded file
-	// required: true
-	FileID string `json:"fileId"`
-}
+	var buf bytes.Buffer
+	if err := export.Write(&buf); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
 
-func FileUploadResponseFromJSON(data io.Reader) (*FileUploadResponse, error) {
-	var fileUploadResponse FileUploadResponse
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.Filename()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
 
-	if err := json.NewDecoder(data).Decode(&fileUploadResponse); err != nil {
-		return nil, err
-	}
-	return &fileUploadResponse, nil
+	a.logger.Debug("ExportBoardMarkdown", mlog.String("boardID", boardID))
+	auditRec.Success()
 }
 
-func (a *API) handleUploadFile(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation POST /api/v1/workspaces/{workspaceID}/{rootID}/files uploadFile
+func (a *API) handleGetBoardStatistics(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/statistics getBoardStatistics
 	//
-	// Upload a binary file, attached to a root block
+	// Returns the number of cards of a board by option and by person, and
+	// the number of cards created and completed by week
 	//
 	// ---
-	// consumes:
-	// - multipart/form-data
 	// produces:
 	// - application/json
 	// parameters:
@@ -1287,61 +1784,78 @@ func (a *API) handleUploadFile(w http.ResponseWriter, r *http.Request) {
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
-	// - name: rootID
+	// - name: boardID
 	//   in: path
-	//   description: ID of the root block
+	//   description: ID of the board
 	//   required: true
 	//   type: string
-	// - name: uploaded file
-	//   in: formData
-	//   type: file
-	//   description: The file to upload
+	// - name: selectPropertyID
+	//   in: query
+	//   description: ID of the select property to group the cards by, defaults to the first select property
+	//   required: false
+	//   type: string
+	// - name: personPropertyID
+	//   in: query
+	//   description: ID of the person property to group the cards by, defaults to the first person property
+	//   required: false
+	//   type: string
+	// - name: doneOptionID
+	//   in: query
+	//   description: ID of the option of the select property of the completed cards, omit to not count completions
+	//   required: false
+	//   type: string
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
 	//     schema:
-	//       "$ref": "#/definitions/FileUploadResponse"
+	//       "$ref": "#/definitions/BoardStatistics"
+	//   '400':
+	//     description: the board has no such property or option
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: board not found
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
-	vars := mux.Vars(r)
-	workspaceID := vars["workspaceID"]
-	rootID := vars["rootID"]
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+	query := r.URL.Query()
+	opts := model.BoardStatisticsOptions{
+		SelectPropertyID: query.Get("selectPropertyID"),
+		PersonPropertyID: query.Get("personPropertyID"),
+		DoneOptionID:     query.Get("doneOptionID"),
+	}
 
-	// Caller must have access to the root block's container
-	_, err := a.getContainerAllowingReadTokenForBlock(r, rootID)
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleViewer)
 	if err != nil {
 		a.noContainerErrorResponse(w, r.URL.Path, err)
 		return
 	}
 
-	file, handle, err := r.FormFile(UploadFormFileKey)
-	if err != nil {
-		fmt.Fprintf(w, "%v", err)
+	auditRec := a.makeAuditRecord(r, "getBoardStatistics", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	stats, err := a.app.ComputeBoardStats(ctx, *container, boardID, opts)
+	if errors.Is(err, app.ErrInvalidStatisticsProperty) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
 		return
 	}
-	defer file.Close()
-
-	auditRec := a.makeAuditRecord(r, "uploadFile", audit.Fail)
-	defer a.audit.LogRecord(audit.LevelModify, auditRec)
-	auditRec.AddMeta("rootID", rootID)
-	auditRec.AddMeta("filename", handle.Filename)
-
-	fileID, err := a.app.SaveFile(file, workspaceID, rootID, handle.Filename)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
+	if stats == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
 
-	a.logger.Debug("uploadFile",
-		mlog.String("filename", handle.Filename),
-		mlog.String("fileID", fileID),
-	)
-	data, err := json.Marshal(FileUploadResponse{FileID: fileID})
+	data, err := json.Marshal(stats)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
@@ -1349,14 +1863,14 @@ func (a *API) handleUploadFile(w http.ResponseWriter, r *http.Request) {
 
 	jsonBytesResponse(w, http.StatusOK, data)
 
-	auditRec.AddMeta("fileID", fileID)
+	auditRec.AddMeta("cardCount", stats.CardCount)
 	auditRec.Success()
 }
 
-func (a *API) getWorkspaceUsers(w http.ResponseWriter, r *http.Request) {
-	// swagger:operation GET /api/v1/workspaces/{workspaceID}/users getWorkspaceUsers
+func (a *API) handleGetCardActivity(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/cards/{cardID}/activity getCardActivity
 	//
-	// Returns workspace users
+	// Returns a page of the activity of a card and of its children, the most recent first
 	//
 	// ---
 	// produces:
@@ -1367,40 +1881,80 @@ func (a *API) getWorkspaceUsers(w http.ResponseWriter, r *http.Request) {
 	//   description: Workspace ID
 	//   required: true
 	//   type: string
+	// - name: cardID
+	//   in: path
+	//   description: ID of the card
+	//   required: true
+	//   type: string
+	// - name: before
+	//   in: query
+	//   description: Cursor of the page, the before value of the previous page
+	//   required: false
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: Maximum number of versions to return, defaults to 50, at most 200
+	//   required: false
+	//   type: integer
 	// security:
 	// - BearerAuth: []
 	// responses:
 	//   '200':
 	//     description: success
 	//     schema:
-	//       type: array
-	//       items:
-	//         "$ref": "#/definitions/User"
+	//       "$ref": "#/definitions/CardActivityPage"
+	//   '404':
+	//     description: card not found
 	//   default:
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
-	vars := mux.Vars(r)
-	workspaceID := vars["workspaceID"]
-
 	ctx := r.Context()
-	session := ctx.Value(sessionContextKey).(*model.Session)
-	if !a.app.DoesUserHaveWorkspaceAccess(session.UserID, workspaceID) {
-		a.errorResponse(w, r.URL.Path, http.StatusForbidden, "Access denied to workspace", PermissionError{"access denied to workspace"})
+	cardID := mux.Vars(r)["cardID"]
+	query := r.URL.Query()
+
+	limit := model.CardActivityDefaultPageSize
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > model.CardActivityMaxPageSize {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+	}
+
+	var before int64
+	if beforeStr := query.Get("before"); beforeStr != "" {
+		var err error
+		before, err = strconv.ParseInt(beforeStr, 10, 64)
+		if err != nil || before < 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid before", err)
+			return
+		}
+	}
+
+	container, err := a.getContainerForBlock(r, cardID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
 		return
 	}
 
-	auditRec := a.makeAuditRecord(r, "getUsers", audit.Fail)
+	auditRec := a.makeAuditRecord(r, "getCardActivity", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("cardID", cardID)
 
-	users, err := a.app.GetWorkspaceUsers(workspaceID)
+	page, err := a.app.GetCardActivity(ctx, *container, cardID, limit, before)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
+	if page == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
 
-	data, err := json.Marshal(users)
+	data, err := json.Marshal(page)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
@@ -1408,75 +1962,3316 @@ func (a *API) getWorkspaceUsers(w http.ResponseWriter, r *http.Request) {
 
 	jsonBytesResponse(w, http.StatusOK, data)
 
-	auditRec.AddMeta("userCount", len(users))
+	auditRec.AddMeta("activityCount", len(page.Activity))
 	auditRec.Success()
 }
 
-// Response helpers
-
-func (a *API) errorResponse(w http.ResponseWriter, api string, code int, message string, sourceError error) {
-	a.logger.Error("API ERROR",
-		mlog.Int("code", code),
-		mlog.Err(sourceError),
-		mlog.String("msg", message),
-		mlog.String("api", api),
-	)
-	w.Header().Set("Content-Type", "application/json")
-	data, err := json.Marshal(model.ErrorResponse{Error: message, ErrorCode: code})
-	if err != nil {
-		data = []byte("{}")
-	}
-	w.WriteHeader(code)
-	_, _ = w.Write(data)
-}
-
-func (a *API) errorResponseWithCode(w http.ResponseWriter, api string, statusCode int, errorCode int, message string, sourceError error) {
-	a.logger.Error("API ERROR",
-		mlog.Int("status", statusCode),
-		mlog.Int("code", errorCode),
-		mlog.Err(sourceError),
-		mlog.String("msg", message),
-		mlog.String("api", api),
-	)
+func (a *API) handleGetCardBacklinks(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/cards/{cardID}/backlinks getCardBacklinks
+	//
+	// Returns the relation properties of the cards linking to a card
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: cardID
+	//   in: path
+	//   description: ID of the card
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/CardBacklink"
+	//   '404':
+	//     description: card not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	cardID := mux.Vars(r)["cardID"]
+
+	container, err := a.getContainerForBlock(r, cardID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getCardBacklinks", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("cardID", cardID)
+
+	backlinks, err := a.app.GetCardBacklinks(ctx, *container, cardID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if backlinks == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(backlinks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("backlinkCount", len(backlinks))
+	auditRec.Success()
+}
+
+func (a *API) handleGetCardByNumber(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/cards/by-number/{number} getCardByNumber
+	//
+	// Returns the card of a board with a number
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: number
+	//   in: path
+	//   description: Number of the card in the board
+	//   required: true
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Block"
+	//   '400':
+	//     description: invalid number
+	//   '404':
+	//     description: card not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+
+	number, err := strconv.ParseInt(vars["number"], 10, 64)
+	if err != nil || number < 1 {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid number", err)
+		return
+	}
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getCardByNumber", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("number", number)
+
+	card, err := a.app.GetCardByNumber(ctx, *container, boardID, number)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if card == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(card)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("cardID", card.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleSearchBlocks(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/blocks/search searchBlocks
+	//
+	// Returns the cards whose title or property values match the query
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: q
+	//   in: query
+	//   description: The text to search for, at least 3 characters long
+	//   required: true
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: Maximum number of results, defaults to 50
+	//   required: false
+	//   type: integer
+	//   maximum: 200
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/BlockSearchResult"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	query := r.URL.Query()
+	terms := strings.TrimSpace(query.Get("q"))
+	if utf8.RuneCountInString(terms) < searchMinQueryLength {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, fmt.Sprintf("the query must be at least %d characters long", searchMinQueryLength), nil)
+		return
+	}
+
+	limit := searchDefaultPageSize
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+		if limit > searchMaxPageSize {
+			limit = searchMaxPageSize
+		}
+	}
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "searchBlocks", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+
+	results, err := a.app.SearchBlocks(ctx, *container, terms, limit)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	results, err = a.app.FilterSearchResultsForUser(*container, session.UserID, results)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("SearchBlocks",
+		mlog.String("workspaceID", container.WorkspaceID),
+		mlog.Int("result_count", len(results)),
+	)
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("resultCount", len(results))
+	auditRec.Success()
+}
+
+func (a *API) handleDuplicateBoard(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/blocks/{blockID}/duplicate duplicateBoard
+	//
+	// Duplicates a board with all its blocks, including the comments
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: blockID
+	//   in: path
+	//   description: ID of the board to duplicate
+	//   required: true
+	//   type: string
+	// - name: asTemplate
+	//   in: query
+	//   description: Create the copy as a template
+	//   required: false
+	//   type: boolean
+	// - name: Idempotency-Key
+	//   in: header
+	//   description: Key of the request, for its retries to get its response instead of creating the blocks again
+	//   required: false
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: the new board. Files referenced by fileId are copied to the new board, so the fileId values are kept.
+	//     schema:
+	//       "$ref": "#/definitions/Block"
+	//   '404':
+	//     description: board not found
+	//   '409':
+	//     description: the idempotency key was used for another request
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+
+	vars := mux.Vars(r)
+	boardID := vars["blockID"]
+
+	asTemplate := false
+	if asTemplateStr := r.URL.Query().Get("asTemplate"); asTemplateStr != "" {
+		var err error
+		asTemplate, err = strconv.ParseBool(asTemplateStr)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid asTemplate", err)
+			return
+		}
+	}
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "duplicateBoard", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("asTemplate", asTemplate)
+
+	board, err := a.app.DuplicateBoard(ctx, *container, boardID, asTemplate, userID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if board == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(board)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("DUPLICATE Board", mlog.String("boardID", boardID), mlog.String("newBoardID", board.ID))
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("newBoardID", board.ID)
+	auditRec.Success()
+}
+
+func (a *API) handlePatchBlock(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PATCH /api/v1/workspaces/{workspaceID}/blocks/{blockID} patchBlock
+	//
+	// Partially updates a block
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: blockID
+	//   in: path
+	//   description: ID of block to patch
+	//   required: true
+	//   type: string
+	// - name: force
+	//   in: query
+	//   description: Whether to patch the block even if another user holds its editing lock
+	//   required: false
+	//   type: boolean
+	// - name: If-Match
+	//   in: header
+	//   description: Version the block must have for the patch to be applied
+	//   required: false
+	//   type: integer
+	// - name: Body
+	//   in: body
+	//   description: block patch to apply
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/BlockPatch"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, the block before and after the patch so that it can be undone
+	//     schema:
+	//       "$ref": "#/definitions/BlockPatchResult"
+	//   '409':
+	//     description: the block is locked by another user, its board is archived, or it doesn't have the expected version, in which case the stored block is in the block detail
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: block not found
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+
+	vars := mux.Vars(r)
+	blockID := vars["blockID"]
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var patch *model.BlockPatch
+	err = json.Unmarshal(requestBody, &patch)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	patch.ExpectedVersion, err = expectedVersion(r)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "patchBlock", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("blockID", blockID)
+
+	force := r.URL.Query().Get("force") == "true"
+	auditRec.AddMeta("force", force)
+
+	result, err := a.app.PatchBlock(ctx, *container, blockID, patch, userID, force)
+	if a.invalidBlockResponse(w, r.URL.Path, err) {
+		return
+	}
+	if errors.Is(err, app.ErrBlockLocked) {
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusConflict, ErrorBlockLockedCode, err.Error(), err)
+		return
+	}
+	var conflictErr app.BlockVersionConflictError
+	if errors.As(err, &conflictErr) {
+		a.writeErrorResponse(w, r.URL.Path, http.StatusConflict, model.ErrorResponse{
+			Message:   err.Error(),
+			ErrorCode: ErrorVersionConflictCode,
+			Details:   map[string]interface{}{"block": conflictErr.Block},
+		}, err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("PATCH Block", mlog.String("blockID", blockID))
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+// expectedVersion returns the block version of the If-Match header of
+// the request, which can be quoted like an entity tag, or nil if the
+// header isn't set.
+func expectedVersion(r *http.Request) (*int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return nil, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(header, "W/"), `"`), 10, 64)
+	if err != nil || version < 0 {
+		return nil, errors.New("the If-Match header must be a block version")
+	}
+	return &version, nil
+}
+
+func (a *API) handleRevertBlock(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/blocks/{blockID}/revert/{historyVersion} revertBlock
+	//
+	// Restores a version of a block from its history. The version is written as a new version, the history
+	// being kept. The values of the card properties and the options deleted from the board since are left out
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: blockID
+	//   in: path
+	//   description: ID of the block to revert
+	//   required: true
+	//   type: string
+	// - name: historyVersion
+	//   in: path
+	//   description: Update time of the version, as returned by the history of the block
+	//   required: true
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, the block before and after the revert
+	//     schema:
+	//       "$ref": "#/definitions/BlockPatchResult"
+	//   '400':
+	//     description: the parent of the version was deleted
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: block or version not found
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: the block is locked by another user, or its board is archived
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+
+	vars := mux.Vars(r)
+	blockID := vars["blockID"]
+	version, err := strconv.ParseInt(vars["historyVersion"], 10, 64)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid history version", err)
+		return
+	}
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "revertBlock", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("blockID", blockID)
+	auditRec.AddMeta("historyVersion", version)
+
+	result, err := a.app.RevertBlock(ctx, *container, blockID, version, userID)
+	if a.invalidBlockResponse(w, r.URL.Path, err) {
+		return
+	}
+	if errors.Is(err, app.ErrBlockVersionNotFound) {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if errors.Is(err, app.ErrInvalidRevert) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if errors.Is(err, app.ErrBlockLocked) {
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusConflict, ErrorBlockLockedCode, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("RevertBlock", mlog.String("blockID", blockID), mlog.Int64("historyVersion", version))
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleMoveCard(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PATCH /api/v1/workspaces/{workspaceID}/cards/{cardID}/move moveCard
+	//
+	// Moves a card between two cards of its board. The card gets an order key between the keys of its new
+	// neighbors, so that the concurrent moves of other cards are kept
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: cardID
+	//   in: path
+	//   description: ID of the card to move
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the neighbors of the card after the move
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CardMove"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, the moved card
+	//     schema:
+	//       "$ref": "#/definitions/Block"
+	//   '400':
+	//     description: the block isn't a card, or a neighbor isn't a card of its board
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: card not found
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: the card is locked by another user, or its board is archived
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+
+	vars := mux.Vars(r)
+	cardID := vars["cardID"]
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var move model.CardMove
+	err = json.Unmarshal(requestBody, &move)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "moveCard", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("cardID", cardID)
+	auditRec.AddMeta("beforeID", move.BeforeID)
+	auditRec.AddMeta("afterID", move.AfterID)
+
+	card, err := a.app.MoveCard(ctx, *container, cardID, move, userID)
+	if errors.Is(err, app.ErrInvalidCardMove) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if errors.Is(err, app.ErrBlockLocked) {
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusConflict, ErrorBlockLockedCode, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("MoveCard", mlog.String("cardID", cardID), mlog.String("order", fmt.Sprint(card.Fields[model.CardOrderField])))
+
+	data, err := json.Marshal(card)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handlePatchBlocks(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PATCH /api/v1/workspaces/{workspaceID}/blocks patchBlocks
+	//
+	// Applies the same partial update to several cards at once. Either all the cards are updated, or none is
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: force
+	//   in: query
+	//   description: Whether to patch the cards even if another user holds the editing lock of one of them
+	//   required: false
+	//   type: boolean
+	// - name: Body
+	//   in: body
+	//   description: IDs of the cards and patch to apply to each of them
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/BlocksPatch"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, the patched cards in the order of the IDs
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Block"
+	//   '400':
+	//     description: one of the blocks isn't a card of the workspace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '409':
+	//     description: one of the cards is locked by another user, or its board is archived
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var blocksPatch *model.BlocksPatch
+	err = json.Unmarshal(requestBody, &blocksPatch)
+	if err != nil || blocksPatch == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "patchBlocks", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("blockCount", len(blocksPatch.BlockIDs))
+
+	force := r.URL.Query().Get("force") == "true"
+	auditRec.AddMeta("force", force)
+
+	blocks, err := a.app.PatchBlocks(ctx, *container, blocksPatch, userID, force)
+	if a.invalidBlockResponse(w, r.URL.Path, err) {
+		return
+	}
+	if errors.Is(err, app.ErrInvalidBlocksPatch) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if errors.Is(err, app.ErrBlockLocked) {
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusConflict, ErrorBlockLockedCode, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(blocks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("PATCH Blocks", mlog.Int("block_count", len(blocks)))
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handleGetSubTree(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/blocks/{blockID}/subtree getSubTree
+	//
+	// Returns the blocks of a subtree
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: blockID
+	//   in: path
+	//   description: The ID of the root block of the subtree
+	//   required: true
+	//   type: string
+	// - name: l
+	//   in: query
+	//   description: The number of levels to return. 2 or 3. Defaults to 2.
+	//   required: false
+	//   type: integer
+	//   minimum: 2
+	//   maximum: 3
+	// - name: If-None-Match
+	//   in: header
+	//   description: ETag of the blocks the client already has
+	//   required: false
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     headers:
+	//       ETag:
+	//         type: string
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Block"
+	//   '304':
+	//     description: the blocks didn't change since the ETag of If-None-Match
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	blockID := vars["blockID"]
+
+	container, err := a.getContainerForBlock(r, blockID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	query := r.URL.Query()
+	levels, err := strconv.ParseInt(query.Get("l"), 10, 32)
+	if err != nil {
+		levels = 2
+	}
+
+	if levels != 2 && levels != 3 {
+		a.logger.Error("Invalid levels", mlog.Int64("levels", levels))
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid levels", nil)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getSubTree", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("blockID", blockID)
+
+	json, digest, err := a.app.GetSubTreeJSON(ctx, *container, blockID, int(levels))
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("GetSubTree",
+		mlog.Int64("levels", levels),
+		mlog.String("blockID", blockID),
+		mlog.Int64("block_count", digest.Count),
+	)
+	etag := blocksETag(&digest)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		auditRec.AddMeta("notModified", true)
+		auditRec.Success()
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, json)
+
+	auditRec.AddMeta("blockCount", digest.Count)
+	auditRec.Success()
+}
+
+func (a *API) handleExport(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/blocks/export exportBlocks
+	//
+	// Returns all blocks
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Block"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	query := r.URL.Query()
+	rootID := query.Get("root_id")
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "export", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("rootID", rootID)
+
+	var blocks []model.Block
+	if rootID == "" {
+		blocks, err = a.app.GetAllBlocks(ctx, *container)
+	} else {
+		blocks, err = a.app.GetBlocksWithRootID(ctx, *container, rootID)
+	}
+	if err == nil {
+		blocks, err = a.filterBlocksForSession(r, *container, blocks)
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("raw blocks", mlog.Int("block_count", len(blocks)))
+	auditRec.AddMeta("rawCount", len(blocks))
+
+	blocks = filterOrphanBlocks(blocks)
+
+	a.logger.Debug("EXPORT filtered blocks", mlog.Int("block_count", len(blocks)))
+	auditRec.AddMeta("filteredCount", len(blocks))
+
+	json, err := json.Marshal(blocks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, json)
+
+	auditRec.Success()
+}
+
+func filterOrphanBlocks(blocks []model.Block) (ret []model.Block) {
+	queue := make([]model.Block, 0)
+	childrenOfBlockWithID := make(map[string]*[]model.Block)
+
+	// Build the trees from nodes
+	for _, block := range blocks {
+		if len(block.ParentID) == 0 {
+			// Queue root blocks to process first
+			queue = append(queue, block)
+		} else {
+			siblings := childrenOfBlockWithID[block.ParentID]
+			if siblings != nil {
+				*siblings = append(*siblings, block)
+			} else {
+				siblings := []model.Block{block}
+				childrenOfBlockWithID[block.ParentID] = &siblings
+			}
+		}
+	}
+
+	// Map the trees to an array, which skips orphaned nodes
+	blocks = make([]model.Block, 0)
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:] // dequeue
+		blocks = append(blocks, block)
+		children := childrenOfBlockWithID[block.ID]
+		if children != nil {
+			queue = append(queue, (*children)...)
+		}
+	}
+
+	return blocks
+}
+
+func (a *API) handleImport(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/blocks/import importBlocks
+	//
+	// Import blocks
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: array of blocks to import
+	//   required: true
+	//   schema:
+	//     type: array
+	//     items:
+	//       "$ref": "#/definitions/Block"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var blocks []model.Block
+
+	err = json.Unmarshal(requestBody, &blocks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "import", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+
+	stampModificationMetadata(r, blocks, auditRec)
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	_, err = a.app.InsertBlocks(ctx, *container, blocks, session.UserID)
+	if a.invalidBlockResponse(w, r.URL.Path, err) {
+		return
+	}
+	if a.quotaExceededResponse(w, r.URL.Path, err) {
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	a.logger.Debug("IMPORT Blocks", mlog.Int("block_count", len(blocks)))
+	auditRec.AddMeta("blockCount", len(blocks))
+	auditRec.Success()
+}
+
+func (a *API) handleImportTrello(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/import/trello importTrello
+	//
+	// Imports a board from a Trello JSON export
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the Trello board JSON export
+	//   required: true
+	//   schema:
+	//     type: object
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ImportSummary"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var board importer.TrelloBoard
+	if err = json.Unmarshal(requestBody, &board); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid Trello board", err)
+		return
+	}
+	if board.ID == "" && board.Name == "" {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid Trello board", nil)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "importTrello", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("trelloBoardID", board.ID)
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	summary, err := a.app.ImportTrello(ctx, *container, board, session.UserID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("ImportTrello",
+		mlog.String("trelloBoardID", board.ID),
+		mlog.Int("cardsCreated", summary.CardsCreated),
+		mlog.Int("skipped", len(summary.Skipped)),
+	)
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("cardsCreated", summary.CardsCreated)
+	auditRec.Success()
+}
+
+func (a *API) handleImportJira(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/import/jira importJira
+	//
+	// Imports a board from a Jira JSON or CSV export. The preview mode
+	// returns the fields of the issues with a suggested mapping, which the
+	// commit mode imports the issues with
+	//
+	// ---
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: mode
+	//   in: query
+	//   description: preview or commit, preview by default
+	//   required: false
+	//   type: string
+	// - name: mapping
+	//   in: formData
+	//   description: the JSON of the JiraImportMapping, before the file, required by the commit mode
+	//   required: false
+	//   type: string
+	// - name: file
+	//   in: formData
+	//   description: the Jira export
+	//   required: true
+	//   type: file
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, a JiraImportPreview in the preview mode
+	//     schema:
+	//       "$ref": "#/definitions/ImportSummary"
+	//   '400':
+	//     description: invalid export or mapping
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = jiraImportModePreview
+	}
+	if mode != jiraImportModePreview && mode != jiraImportModeCommit {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid mode", nil)
+		return
+	}
+
+	maxFileSize := a.app.GetClientConfig().MaxFileSize
+	if maxFileSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, maxFileSize+uploadFormOverhead)
+	}
+	file, mapping, err := jiraImportForm(r)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid Jira import", err)
+		return
+	}
+
+	if mode == jiraImportModePreview {
+		preview, err := a.app.PreviewJiraImport(file)
+		if a.invalidJiraImportResponse(w, r.URL.Path, err) {
+			return
+		}
+
+		data, err := json.Marshal(preview)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+			return
+		}
+		jsonBytesResponse(w, http.StatusOK, data)
+		return
+	}
+
+	if mapping == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid Jira import", errors.New("missing mapping"))
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "importJira", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("fieldCount", len(mapping.Fields))
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	summary, err := a.app.ImportJira(ctx, *container, file, *mapping, session.UserID)
+	if a.invalidJiraImportResponse(w, r.URL.Path, err) {
+		return
+	}
+
+	a.logger.Debug("ImportJira",
+		mlog.Int("cardsCreated", summary.CardsCreated),
+		mlog.Int("skipped", len(summary.Skipped)),
+	)
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("cardsCreated", summary.CardsCreated)
+	auditRec.Success()
+}
+
+const (
+	jiraImportModePreview = "preview"
+	jiraImportModeCommit  = "commit"
+)
+
+// jiraImportForm returns the reader of the export of a Jira import form,
+// reading the form up to the file so that the export is streamed, with
+// the mapping that precedes it, if any.
+func jiraImportForm(r *http.Request) (io.Reader, *model.JiraImportMapping, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var mapping *model.JiraImportMapping
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("missing file")
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		switch part.FormName() {
+		case JiraImportMappingFormKey:
+			mapping = &model.JiraImportMapping{}
+			if err := json.NewDecoder(part).Decode(mapping); err != nil {
+				return nil, nil, fmt.Errorf("invalid mapping: %w", err)
+			}
+		case UploadFormFileKey:
+			return part, mapping, nil
+		}
+	}
+}
+
+// invalidJiraImportResponse writes the response of the errors of the
+// Jira imports, and tells if there was one.
+func (a *API) invalidJiraImportResponse(w http.ResponseWriter, api string, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var invalid importer.InvalidJiraImportError
+	switch {
+	case errors.As(err, &invalid):
+		a.errorResponse(w, api, http.StatusBadRequest, invalid.Reason, err)
+	case a.quotaExceededResponse(w, api, err):
+	default:
+		a.errorResponse(w, api, http.StatusInternalServerError, "", err)
+	}
+	return true
+}
+
+func (a *API) handleImportNotion(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/import/notion importNotion
+	//
+	// Imports the databases of a Notion export as boards, with the pages
+	// of their rows as the content of the cards
+	//
+	// ---
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: file
+	//   in: formData
+	//   description: the zip of the Notion export, in the markdown and CSV format
+	//   required: true
+	//   type: file
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, with the rows whose values don't fit the inferred properties
+	//     schema:
+	//       "$ref": "#/definitions/ImportSummary"
+	//   '400':
+	//     description: invalid export
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	part, _, err := uploadFormFile(r)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid Notion export", err)
+		return
+	}
+
+	// zip files are read from their end, so the upload is spooled to a
+	// temporary file rather than held in memory
+	file, err := ioutil.TempFile("", "focalboard-notion-*.zip")
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	size, err := io.Copy(file, part)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid Notion export", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "importNotion", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	summary, err := a.app.ImportNotion(ctx, *container, archive, session.UserID)
+	var invalid importer.InvalidNotionExportError
+	if errors.As(err, &invalid) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, invalid.Reason, err)
+		return
+	}
+	if a.quotaExceededResponse(w, r.URL.Path, err) {
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("ImportNotion",
+		mlog.Int("boardsCreated", summary.BoardsCreated),
+		mlog.Int("cardsCreated", summary.CardsCreated),
+		mlog.Int("skipped", len(summary.Skipped)),
+	)
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("boardsCreated", summary.BoardsCreated)
+	auditRec.AddMeta("cardsCreated", summary.CardsCreated)
+	auditRec.Success()
+}
+
+func (a *API) handleImportAsana(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/import/asana importAsana
+	//
+	// Imports a board from an Asana project JSON export. Importing the
+	// export of the same project again updates the board of the previous
+	// import
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the Asana project JSON export
+	//   required: true
+	//   schema:
+	//     type: object
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ImportSummary"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var export importer.AsanaExport
+	if err = json.Unmarshal(requestBody, &export); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid Asana export", err)
+		return
+	}
+	if export.Data == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid Asana export", nil)
+		return
+	}
+
+	project := export.Project()
+	auditRec := a.makeAuditRecord(r, "importAsana", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("asanaProjectGID", project.GID)
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	summary, err := a.app.ImportAsana(ctx, *container, export, session.UserID)
+	if a.quotaExceededResponse(w, r.URL.Path, err) {
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("ImportAsana",
+		mlog.String("asanaProjectGID", project.GID),
+		mlog.Int("cardsCreated", summary.CardsCreated),
+		mlog.Int("skipped", len(summary.Skipped)),
+	)
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("cardsCreated", summary.CardsCreated)
+	auditRec.Success()
+}
+
+func (a *API) handleExportWorkspaceArchive(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/archive exportWorkspaceArchive
+	//
+	// Exports the blocks and files of the workspace as a zip archive
+	//
+	// ---
+	// produces:
+	// - application/zip
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: file
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "exportWorkspaceArchive", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+
+	filename := fmt.Sprintf("workspace-%s-%s.zip", container.WorkspaceID, time.Now().Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	// the archive is streamed, so once it is being written errors can
+	// only be logged
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	if err := a.app.ExportWorkspaceArchive(ctx, *container, session.UserID, w); err != nil {
+		a.logger.Error("ExportWorkspaceArchive failed", mlog.String("workspaceID", container.WorkspaceID), mlog.Err(err))
+		return
+	}
+
+	a.logger.Debug("ExportWorkspaceArchive", mlog.String("workspaceID", container.WorkspaceID))
+	auditRec.Success()
+}
+
+func (a *API) handleImportWorkspaceArchive(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/archive importWorkspaceArchive
+	//
+	// Imports the blocks and files of a workspace archive, with new IDs
+	//
+	// ---
+	// consumes:
+	// - application/zip
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the zip archive exported by exportWorkspaceArchive
+	//   required: true
+	//   schema:
+	//     type: string
+	//     format: binary
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ImportSummary"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	// zip files are read from their end, so the body is spooled to a
+	// temporary file rather than held in memory
+	file, err := ioutil.TempFile("", "focalboard-archive-*.zip")
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	size, err := io.Copy(file, r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	archive, err := zip.NewReader(file, size)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid workspace archive", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "importWorkspaceArchive", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	summary, err := a.app.ImportWorkspaceArchive(ctx, *container, archive, session.UserID)
+	if errors.Is(err, app.ErrInvalidArchive) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if a.quotaExceededResponse(w, r.URL.Path, err) {
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("ImportWorkspaceArchive",
+		mlog.String("workspaceID", container.WorkspaceID),
+		mlog.Int("boardsCreated", summary.BoardsCreated),
+		mlog.Int("skipped", len(summary.Skipped)),
+	)
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("boardsCreated", summary.BoardsCreated)
+	auditRec.Success()
+}
+
+// Sharing
+
+func (a *API) handleGetSharing(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/sharing/{rootID} getSharing
+	//
+	// Returns sharing information for a root block
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: rootID
+	//   in: path
+	//   description: ID of the root block
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Sharing"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	vars := mux.Vars(r)
+	rootID := vars["rootID"]
+
+	container, err := a.getContainerForBlock(r, rootID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getSharing", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("rootID", rootID)
+
+	sharing, err := a.app.GetSharing(*container, rootID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	sharingData, err := json.Marshal(sharing)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, sharingData)
+
+	if sharing == nil {
+		sharing = &model.Sharing{}
+	}
+	a.logger.Debug("GET sharing",
+		mlog.String("rootID", rootID),
+		mlog.String("shareID", sharing.ID),
+		mlog.Bool("enabled", sharing.Enabled),
+	)
+	auditRec.AddMeta("shareID", sharing.ID)
+	auditRec.AddMeta("enabled", sharing.Enabled)
+	auditRec.Success()
+}
+
+func (a *API) handlePostSharing(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/sharing/{rootID} postSharing
+	//
+	// Sets sharing information for a root block
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: rootID
+	//   in: path
+	//   description: ID of the root block
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: sharing information for a root block
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/Sharing"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var sharing model.Sharing
+
+	err = json.Unmarshal(requestBody, &sharing)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "postSharing", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("shareID", sharing.ID)
+	auditRec.AddMeta("enabled", sharing.Enabled)
+
+	// Stamp ModifiedBy
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+	if userID == SingleUser {
+		userID = ""
+	}
+	sharing.ModifiedBy = userID
+
+	err = a.app.CheckBlockAccess(ctx, *container, session.UserID, sharing.ID, model.BoardRoleAdmin)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	err = a.app.UpsertSharing(*container, sharing)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	a.logger.Debug("POST sharing", mlog.String("sharingID", sharing.ID))
+	auditRec.Success()
+}
+
+// SharingTokenRequest is a request to create a sharing token
+// swagger:model
+type SharingTokenRequest struct {
+	// Expiry time in milliseconds, omit for a token that never expires
+	// required: false
+	ExpireAt int64 `json:"expireAt"`
+
+	// Token to revoke and replace with the new one
+	// required: false
+	Replaces string `json:"replaces"`
+}
+
+func (a *API) handlePostSharingToken(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/sharing/{rootID}/tokens postSharingToken
+	//
+	// Creates a read-only access token for a root block
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: rootID
+	//   in: path
+	//   description: ID of the root block
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: expiry of the token, and the token it replaces if any
+	//   required: false
+	//   schema:
+	//     "$ref": "#/definitions/SharingTokenRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/SharingToken"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	vars := mux.Vars(r)
+	rootID := vars["rootID"]
+
+	container, err := a.getContainerForBlock(r, rootID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var request SharingTokenRequest
+	if len(requestBody) > 0 {
+		if err = json.Unmarshal(requestBody, &request); err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+			return
+		}
+	}
+
+	if request.ExpireAt < 0 || (request.ExpireAt != 0 && request.ExpireAt <= utils.GetMillis()) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "expireAt must be in the future", nil)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "postSharingToken", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("rootID", rootID)
+	auditRec.AddMeta("expireAt", request.ExpireAt)
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+	if userID == SingleUser {
+		userID = ""
+	}
+
+	token, err := a.app.RegenerateSharingToken(*container, rootID, request.ExpireAt, request.Replaces, userID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(token)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	a.logger.Debug("POST sharing token", mlog.String("rootID", rootID))
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteSharingToken(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /api/v1/workspaces/{workspaceID}/sharing/{rootID}/tokens/{token} deleteSharingToken
+	//
+	// Revokes a read-only access token of a root block
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: rootID
+	//   in: path
+	//   description: ID of the root block
+	//   required: true
+	//   type: string
+	// - name: token
+	//   in: path
+	//   description: The token to revoke
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: token not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	vars := mux.Vars(r)
+	rootID := vars["rootID"]
+	token := vars["token"]
+
+	container, err := a.getContainerForBlock(r, rootID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteSharingToken", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("rootID", rootID)
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	err = a.app.RevokeSharingToken(*container, rootID, token, session.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	a.logger.Debug("DELETE sharing token", mlog.String("rootID", rootID))
+	auditRec.Success()
+}
+
+// Workspace
+
+func (a *API) handleGetWorkspace(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID} getWorkspace
+	//
+	// Returns information of the root workspace
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Workspace"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	var workspace *model.Workspace
+	var err error
+
+	if a.MattermostAuth {
+		vars := mux.Vars(r)
+		workspaceID := vars["workspaceID"]
+
+		session := ctx.Value(sessionContextKey).(*model.Session)
+		if !a.app.DoesUserHaveWorkspaceAccess(ctx, session.UserID, workspaceID) {
+			a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "user does not have workspace access", nil)
+			return
+		}
+
+		workspace, err = a.app.GetWorkspace(ctx, workspaceID)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		}
+		if workspace == nil {
+			a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "invalid workspace", nil)
+			return
+		}
+	} else {
+		workspace, err = a.app.GetRootWorkspace(ctx)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "getWorkspace", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("resultWorkspaceID", workspace.ID)
+
+	workspaceData, err := json.Marshal(workspace)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, workspaceData)
+	auditRec.Success()
+}
+
+func (a *API) handlePatchWorkspaceSettings(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PATCH /api/v1/workspaces/{workspaceID}/settings patchWorkspaceSettings
+	//
+	// Partially updates the settings of a workspace, keys not present
+	// in the patch are left untouched
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: settings patch to apply
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/WorkspaceSettingsPatch"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/WorkspaceSettings"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	patch, err := model.WorkspaceSettingsPatchFromJSON(requestBody)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid settings patch", err)
+		return
+	}
+
+	if err = patch.IsValid(); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "patchWorkspaceSettings", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("workspaceID", container.WorkspaceID)
+
+	settings, err := a.app.PatchWorkspaceSettings(ctx, container.WorkspaceID, patch, session.UserID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("PATCH WorkspaceSettings", mlog.String("workspaceID", container.WorkspaceID))
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handlePutDefaultCardTemplate(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /api/v1/workspaces/{workspaceID}/settings/default_card_template setDefaultCardTemplate
+	//
+	// Sets the card template applied to the new blank cards of the boards
+	// of the workspace that don't set their own, or clears it
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the card template, empty to clear the default
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/DefaultCardTemplateRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/WorkspaceSettings"
+	//   '400':
+	//     description: the block isn't a card template of the workspace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var request model.DefaultCardTemplateRequest
+	if err = json.Unmarshal(requestBody, &request); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "setDefaultCardTemplate", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("workspaceID", container.WorkspaceID)
+	auditRec.AddMeta("templateID", request.TemplateID)
+
+	settings, err := a.app.SetDefaultCardTemplate(ctx, container.WorkspaceID, request.TemplateID, session.UserID)
+	if errors.Is(err, app.ErrInvalidCardTemplate) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("PUT DefaultCardTemplate",
+		mlog.String("workspaceID", container.WorkspaceID),
+		mlog.String("templateID", request.TemplateID),
+	)
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.Success()
+}
+
+func (a *API) handlePostWorkspaceRegenerateSignupToken(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/regenerate_signup_token regenerateSignupToken
+	//
+	// Regenerates the signup token for the root workspace
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	workspace, err := a.app.GetRootWorkspace(ctx)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "regenerateSignupToken", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+
+	workspace.SignupToken = utils.CreateGUID()
+
+	err = a.app.UpsertWorkspaceSignupToken(ctx, *workspace)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+// File upload
+
+func (a *API) handleServeFile(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /files/workspaces/{workspaceID}/{rootID}/{filename} getFile
+	//
+	// Returns the contents of an uploaded file, or the thumbnail of an
+	// image. The original image is returned while its thumbnail isn't
+	// generated. The files other than images are downloaded as
+	// attachments, with the name they were uploaded with
+	//
+	// ---
+	// produces:
+	// - application/json
+	// - application/octet-stream
+	// - image/jpeg
+	// - image/png
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: rootID
+	//   in: path
+	//   description: ID of the root block
+	//   required: true
+	//   type: string
+	// - name: filename
+	//   in: path
+	//   description: ID of the file
+	//   required: true
+	//   type: string
+	// - name: thumbnail
+	//   in: query
+	//   description: Whether to return the thumbnail of the image, of at most 400 pixels
+	//   required: false
+	//   type: boolean
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	vars := mux.Vars(r)
+	workspaceID := vars["workspaceID"]
+	rootID := vars["rootID"]
+	filename := vars["filename"]
+
+	// Caller must have access to the root block's container
+	_, err := a.getContainerForBlock(r, rootID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getFile", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("rootID", rootID)
+	auditRec.AddMeta("filename", filename)
+
+	if r.URL.Query().Get("thumbnail") == "true" {
+		thumbnailReader, modTime, err := a.app.GetThumbnailReader(workspaceID, rootID, filename)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+			return
+		}
+		if thumbnailReader != nil {
+			defer thumbnailReader.Close()
+			w.Header().Set("Content-Type", "image/jpeg")
+			http.ServeContent(w, r, filename, modTime, thumbnailReader)
+			auditRec.Success()
+			return
+		}
+	}
+
+	info, err := a.app.GetFileInfo(workspaceID, rootID, filename)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	contentType, disposition := fileContentHeaders(info, filename)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	fileReader, err := a.app.GetFileReader(workspaceID, rootID, filename)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	defer fileReader.Close()
+
+	// the reader is seekable for every files driver, so ServeContent
+	// answers range requests by only reading the requested bytes
+	modTime, err := a.app.GetFileModTime(workspaceID, rootID, filename)
+	if err != nil {
+		a.logger.Warn("GetFileModTime failed", mlog.String("filename", filename), mlog.Err(err))
+		modTime = time.Now()
+	}
+	http.ServeContent(w, r, filename, modTime, fileReader)
+	auditRec.Success()
+}
+
+// inlineFileTypes are the MIME types of the images that are shown by the
+// browsers, the other files being downloaded.
+var inlineFileTypes = map[string]bool{
+	"image/bmp":  true,
+	"image/gif":  true,
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+// fileContentHeaders returns the content type and disposition of a
+// file, from its record or else from its extension. The files other than
+// images are attachments, with the name they were uploaded with.
+func fileContentHeaders(info *model.FileInfo, filename string) (string, string) {
+	contentType := ""
+	name := filename
+	if info != nil {
+		contentType = info.MimeType
+		if info.Name != "" {
+			name = info.Name
+		}
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(strings.ToLower(filepath.Ext(filename)))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if inlineFileTypes[mediaType] {
+		return contentType, "inline"
+	}
+	return contentType, mime.FormatMediaType("attachment", map[string]string{"filename": name})
+}
+
+// FileUploadResponse is the response to a file upload
+// swagger:model
+type FileUploadResponse struct {
+	// The FileID to retrieve the uploaded file
+	// required: true
+	FileID string `json:"fileId"`
+
+	// The name of the uploaded file
+	Filename string `json:"filename,omitempty"`
+
+	// The size of the file in bytes
+	Size int64 `json:"size,omitempty"`
+
+	// The MIME type of the file
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+func FileUploadResponseFromJSON(data io.Reader) (*FileUploadResponse, error) {
+	var fileUploadResponse FileUploadResponse
+
+	if err := json.NewDecoder(data).Decode(&fileUploadResponse); err != nil {
+		return nil, err
+	}
+	return &fileUploadResponse, nil
+}
+
+func (a *API) handleUploadFile(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/{rootID}/files uploadFile
+	//
+	// Upload a binary file, attached to a root block
+	//
+	// ---
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: rootID
+	//   in: path
+	//   description: ID of the root block
+	//   required: true
+	//   type: string
+	// - name: uploaded file
+	//   in: formData
+	//   type: file
+	//   description: The file to upload
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/FileUploadResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	vars := mux.Vars(r)
+	workspaceID := vars["workspaceID"]
+	rootID := vars["rootID"]
+
+	// Caller must have access to the root block's container
+	_, err := a.getContainerForBlock(r, rootID, model.BoardRoleEditor)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	// the file is streamed to the files storage rather than parsed as a
+	// form, so that large files are rejected without being read entirely
+	maxFileSize := a.app.GetClientConfig().MaxFileSize
+	if maxFileSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, maxFileSize+uploadFormOverhead)
+	}
+	file, filename, err := uploadFormFile(r)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid file upload", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "uploadFile", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("rootID", rootID)
+	auditRec.AddMeta("filename", filename)
+
+	info, err := a.app.UploadFileInfo(file, workspaceID, rootID, filename)
+	if errors.Is(err, app.ErrFileTooLarge) {
+		message := fmt.Sprintf("the file is larger than the maximum size of %d bytes", maxFileSize)
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusRequestEntityTooLarge, ErrorFileTooLargeCode, message, err)
+		return
+	}
+	if errors.Is(err, app.ErrFileTypeNotAllowed) {
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusUnsupportedMediaType, ErrorFileTypeNotAllowedCode, "the file type isn't allowed", err)
+		return
+	}
+	if a.quotaExceededResponse(w, r.URL.Path, err) {
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("uploadFile",
+		mlog.String("filename", filename),
+		mlog.String("fileID", info.ID),
+	)
+	data, err := json.Marshal(FileUploadResponse{
+		FileID:   info.ID,
+		Filename: info.Name,
+		Size:     info.Size,
+		MimeType: info.MimeType,
+	})
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("fileID", info.ID)
+	auditRec.Success()
+}
+
+// uploadFormFile returns the reader and the name of the file of an
+// upload form, reading the form up to the file.
+func uploadFormFile(r *http.Request) (io.Reader, string, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, "", errors.New("missing file")
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if part.FormName() == UploadFormFileKey {
+			return part, part.FileName(), nil
+		}
+	}
+}
+
+func (a *API) getWorkspaceUsers(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/users getWorkspaceUsers
+	//
+	// Returns workspace users
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: board_id
+	//   in: query
+	//   description: ID of a board, whose guests are returned along with the users of the workspace
+	//   required: false
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/User"
+	//   '403':
+	//     description: the user can't view the board
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	vars := mux.Vars(r)
+	workspaceID := vars["workspaceID"]
+	boardID := r.URL.Query().Get("board_id")
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	if !a.app.DoesUserHaveWorkspaceAccess(ctx, session.UserID, workspaceID) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, "Access denied to workspace", PermissionError{"access denied to workspace"})
+		return
+	}
+	if boardID != "" {
+		err := a.app.CheckBlockAccess(ctx, store.Container{WorkspaceID: workspaceID}, session.UserID, boardID, model.BoardRoleViewer)
+		if err != nil {
+			a.noContainerErrorResponse(w, r.URL.Path, err)
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "getUsers", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	users, err := a.app.GetWorkspaceUsers(workspaceID, boardID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(users)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("userCount", len(users))
+	auditRec.Success()
+}
+
+func (a *API) handleSearchWorkspaceUsers(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/users/search searchWorkspaceUsers
+	//
+	// Returns the first users of the workspace whose username, or nickname in plugin mode, starts with the
+	// query, to be assigned to a person property. The deactivated users and the bots are left out
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: q
+	//   in: query
+	//   description: prefix of the username or of the nickname
+	//   required: false
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/UserSearchResult"
+	//   '403':
+	//     description: access denied to the workspace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	vars := mux.Vars(r)
+	workspaceID := vars["workspaceID"]
+	query := r.URL.Query().Get("q")
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	if !a.app.DoesUserHaveWorkspaceAccess(ctx, session.UserID, workspaceID) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, "Access denied to workspace", PermissionError{"access denied to workspace"})
+		return
+	}
+
+	users, err := a.app.SearchWorkspaceUsers(ctx, workspaceID, query)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(users)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+// Response helpers
+
+// errorResponse writes an error response with the code of the status. A
+// missing row in the store is a not found error rather than an internal
+// one.
+func (a *API) errorResponse(w http.ResponseWriter, api string, statusCode int, message string, sourceError error) {
+	a.writeErrorResponse(w, api, statusCode, model.ErrorResponse{Message: message}, sourceError)
+}
+
+// errorResponseWithCode writes an error response with the numeric code
+// of the error, along with the code of the status.
+func (a *API) errorResponseWithCode(w http.ResponseWriter, api string, statusCode int, errorCode int, message string, sourceError error) {
+	a.writeErrorResponse(w, api, statusCode, model.ErrorResponse{Message: message, ErrorCode: errorCode}, sourceError)
+}
+
+// errorResponseWithDetails writes an error response with a more specific
+// code than the one of the status, and the details of the error.
+func (a *API) errorResponseWithDetails(w http.ResponseWriter, api string, statusCode int, code string, message string, details map[string]interface{}, sourceError error) {
+	a.writeErrorResponse(w, api, statusCode, model.ErrorResponse{Code: code, Message: message, Details: details}, sourceError)
+}
+
+// invalidBlockResponse writes a bad request response if the error is a
+// violated rule of a block type, an invalid relation or invalid
+// properties of a card, an invalid computed property or option color of
+// a board, an invalid recurrence, an attachment without its file, or an
+// invalid field of a code block, and tells if it did.
+func (a *API) invalidBlockResponse(w http.ResponseWriter, api string, err error) bool {
+	var typeErr app.InvalidBlockTypeError
+	var relationErr app.InvalidRelationError
+	var recurrenceErr app.InvalidRecurrenceError
+	var propertiesErr app.InvalidPropertiesError
+	var computedErr app.InvalidComputedPropertyError
+	var optionErr app.InvalidPropertyOptionError
+	var attachmentErr app.InvalidAttachmentError
+	var codeErr app.InvalidCodeBlockError
+	var details map[string]interface{}
+	switch {
+	case errors.As(err, &typeErr):
+		details = map[string]interface{}{"blockId": typeErr.BlockID, "type": typeErr.Type, "rule": typeErr.Rule}
+	case errors.As(err, &relationErr):
+		details = map[string]interface{}{"blockId": relationErr.BlockID, "propertyId": relationErr.PropertyID}
+	case errors.As(err, &recurrenceErr):
+		details = map[string]interface{}{"blockId": recurrenceErr.BlockID}
+	case errors.As(err, &propertiesErr):
+		details = map[string]interface{}{"blockId": propertiesErr.BlockID, "propertyIds": propertiesErr.PropertyIDs}
+	case errors.As(err, &computedErr):
+		details = map[string]interface{}{"blockId": computedErr.BlockID, "propertyId": computedErr.PropertyID}
+	case errors.As(err, &optionErr):
+		details = map[string]interface{}{
+			"blockId":     optionErr.BlockID,
+			"propertyId":  optionErr.PropertyID,
+			"optionId":    optionErr.OptionID,
+			"validColors": model.PropertyOptionColors,
+		}
+	case errors.As(err, &attachmentErr):
+		details = map[string]interface{}{"blockId": attachmentErr.BlockID, "fileId": attachmentErr.FileID}
+	case errors.As(err, &codeErr):
+		details = map[string]interface{}{"blockId": codeErr.BlockID, "field": codeErr.Field}
+	default:
+		return false
+	}
+	a.errorResponseWithDetails(w, api, http.StatusBadRequest, model.ErrorCodeInvalidBlock, err.Error(), details, err)
+	return true
+}
+
+// quotaExceededResponse writes a forbidden response if the error is an
+// exceeded quota of the workspace, and tells if it did.
+func (a *API) quotaExceededResponse(w http.ResponseWriter, api string, err error) bool {
+	if !errors.Is(err, app.ErrQuotaExceeded) {
+		return false
+	}
+	a.errorResponseWithDetails(w, api, http.StatusForbidden, model.ErrorCodeQuotaExceeded, "the workspace quota is exceeded", nil, err)
+	return true
+}
+
+func (a *API) writeErrorResponse(w http.ResponseWriter, api string, statusCode int, response model.ErrorResponse, sourceError error) {
+	if statusCode == http.StatusInternalServerError && errors.Is(sourceError, sql.ErrNoRows) {
+		statusCode = http.StatusNotFound
+		response.Code = model.ErrorCodeNotFound
+	}
+	if statusCode == http.StatusInternalServerError && errors.Is(sourceError, app.ErrBoardAccessDenied) {
+		statusCode = http.StatusForbidden
+		response.Code = model.ErrorCodeForbidden
+		response.Message = sourceError.Error()
+	}
+	var mismatchErr app.WorkspaceMismatchError
+	if statusCode == http.StatusInternalServerError && errors.As(sourceError, &mismatchErr) {
+		statusCode = http.StatusBadRequest
+		response.Code = model.ErrorCodeWorkspaceMismatch
+		response.Message = sourceError.Error()
+		response.Details = map[string]interface{}{"blockId": mismatchErr.BlockID, "referenceId": mismatchErr.ReferenceID}
+	}
+	if statusCode == http.StatusInternalServerError && errors.Is(sourceError, app.ErrBoardArchived) {
+		statusCode = http.StatusConflict
+		response.Code = model.ErrorCodeConflict
+		response.ErrorCode = ErrorBoardArchivedCode
+		response.Message = sourceError.Error()
+	}
+	if response.Code == "" {
+		response.Code = errorCodeForStatus(statusCode)
+	}
+	if response.ErrorCode == 0 {
+		response.ErrorCode = statusCode
+	}
+	if response.Message == "" {
+		response.Message = strings.ToLower(http.StatusText(statusCode))
+	}
+	response.Error = response.Message
+
+	a.logger.Error("API ERROR",
+		mlog.Int("status", statusCode),
+		mlog.String("code", response.Code),
+		mlog.Int("errorCode", response.ErrorCode),
+		mlog.Err(sourceError),
+		mlog.String("msg", response.Message),
+		mlog.String("api", api),
+	)
+	w.Header().Set("Content-Type", "application/json")
+	data, err := json.Marshal(response)
+	if err != nil {
+		data = []byte("{}")
+	}
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(data)
+}
+
+// errorCodeForStatus returns the error code of the responses with the
+// status that don't have a more specific one.
+func errorCodeForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return model.ErrorCodeBadRequest
+	case http.StatusUnauthorized:
+		return model.ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return model.ErrorCodeForbidden
+	case http.StatusNotFound:
+		return model.ErrorCodeNotFound
+	case http.StatusConflict:
+		return model.ErrorCodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return model.ErrorCodePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return model.ErrorCodeRateLimited
+	case http.StatusNotImplemented:
+		return model.ErrorCodeNotImplemented
+	default:
+		return model.ErrorCodeInternal
+	}
+}
+
+func (a *API) noContainerErrorResponse(w http.ResponseWriter, api string, sourceError error) {
+	// expired tokens look like a missing board, so that they don't
+	// reveal that the board exists
+	if errors.Is(sourceError, auth.ErrReadTokenExpired) {
+		a.errorResponse(w, api, http.StatusNotFound, "", sourceError)
+		return
+	}
+
+	if errors.Is(sourceError, app.ErrBoardAccessDenied) {
+		a.errorResponse(w, api, http.StatusForbidden, sourceError.Error(), sourceError)
+		return
+	}
+
+	if errors.Is(sourceError, errWorkspaceMismatch) {
+		a.errorResponseWithDetails(w, api, http.StatusBadRequest, model.ErrorCodeWorkspaceMismatch, sourceError.Error(), nil, sourceError)
+		return
+	}
+
+	var permissionError PermissionError
+	if errors.As(sourceError, &permissionError) {
+		a.errorResponseWithCode(w, api, http.StatusForbidden, ErrorNoWorkspaceCode, ErrorNoWorkspaceMessage, sourceError)
+		return
+	}
+
+	a.errorResponseWithCode(w, api, http.StatusBadRequest, ErrorNoWorkspaceCode, ErrorNoWorkspaceMessage, sourceError)
+}
+
+func jsonStringResponse(w http.ResponseWriter, code int, message string) { //nolint:unparam
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	fmt.Fprint(w, message)
+}
+
+func jsonBytesResponse(w http.ResponseWriter, code int, json []byte) { //nolint:unparam
 	w.Header().Set("Content-Type", "application/json")
-	data, err := json.Marshal(model.ErrorResponse{Error: message, ErrorCode: errorCode})
+	w.WriteHeader(code)
+	_, _ = w.Write(json)
+}
+
+func (a *API) handleGetUserWorkspaces(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces getUserWorkspaces
+	//
+	// Returns a page of the workspaces the current user belongs to
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: cursor
+	//   in: query
+	//   description: ID of the last workspace of the previous page
+	//   required: false
+	//   type: string
+	// - name: limit
+	//   in: query
+	//   description: Maximum number of workspaces to return, defaults to 100, at most 1000
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/UserWorkspacesPage"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	query := r.URL.Query()
+	cursor := query.Get("cursor")
+	limit := model.UserWorkspacesDefaultPageSize
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > model.UserWorkspacesMaxPageSize {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userWorkspaces, hasMore, err := a.app.GetUserWorkspaces(ctx, session.UserID, cursor, limit)
 	if err != nil {
-		data = []byte("{}")
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
 	}
-	w.WriteHeader(statusCode)
-	_, _ = w.Write(data)
+
+	data, err := json.Marshal(model.UserWorkspacesPage{
+		Workspaces: userWorkspaces,
+		HasMore:    hasMore,
+	})
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
 }
 
-func (a *API) noContainerErrorResponse(w http.ResponseWriter, api string, sourceError error) {
-	a.errorResponseWithCode(w, api, http.StatusBadRequest, ErrorNoWorkspaceCode, ErrorNoWorkspaceMessage, sourceError)
+func (a *API) handleGetTemplates(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/templates getTemplates
+	//
+	// Returns the board templates available to every workspace
+	//
+	// ---
+	// produces:
+	// - application/json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/BoardTemplate"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	templates, err := a.app.GetGlobalTemplates(ctx)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("GetTemplates", mlog.Int("templateCount", len(templates)))
+
+	data, err := json.Marshal(templates)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
 }
 
-func jsonStringResponse(w http.ResponseWriter, code int, message string) { //nolint:unparam
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	fmt.Fprint(w, message)
+func (a *API) handleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/notifications getNotifications
+	//
+	// Returns the notifications of the mentions, due dates and watched blocks of the current user, the most
+	// recent first
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: limit
+	//   in: query
+	//   description: Maximum number of notifications, defaults to 50
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Notification"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	limit := model.NotificationDefaultPageSize
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid limit", err)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	auditRec := a.makeAuditRecord(r, "getNotifications", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+
+	notifications, err := a.app.GetNotifications(session.UserID, limit)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.logger.Debug("GetNotifications",
+		mlog.String("userID", session.UserID),
+		mlog.Int("notificationCount", len(notifications)),
+	)
+
+	data, err := json.Marshal(notifications)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("notificationCount", len(notifications))
+	auditRec.Success()
 }
 
-func jsonBytesResponse(w http.ResponseWriter, code int, json []byte) { //nolint:unparam
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	_, _ = w.Write(json)
+func (a *API) handleGetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/subscriptions getSubscriptions
+	//
+	// Returns the cards and the boards of the workspace that the current user watches
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Subscription"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	subscriptions, err := a.app.GetSubscriptionsForUser(ctx, *container, session.UserID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(subscriptions)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
 }
 
-func (a *API) handleGetUserWorkspaces(w http.ResponseWriter, r *http.Request) {
+func (a *API) handleCreateSubscription(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/blocks/{blockID}/subscription createSubscription
+	//
+	// Watches a card or a board, the current user being notified of its changes by the other users. Consecutive
+	// changes are only notified once within the notification window
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: blockID
+	//   in: path
+	//   description: ID of the card or of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Subscription"
+	//   '400':
+	//     description: the block isn't a card or a board
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: the user can't view the board
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: block not found
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
 	ctx := r.Context()
 	session := ctx.Value(sessionContextKey).(*model.Session)
-	userWorkspaces, err := a.app.GetUserWorkspaces(session.UserID)
+	blockID := mux.Vars(r)["blockID"]
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "createSubscription", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("blockID", blockID)
+
+	subscription, err := a.app.CreateSubscription(ctx, *container, blockID, session.UserID)
+	if errors.Is(err, app.ErrInvalidSubscription) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
-	data, err := json.Marshal(userWorkspaces)
+	data, err := json.Marshal(subscription)
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
 		return
 	}
 
 	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /api/v1/workspaces/{workspaceID}/blocks/{blockID}/subscription deleteSubscription
+	//
+	// Stops watching a card or a board
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: blockID
+	//   in: path
+	//   description: ID of the card or of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: the user doesn't watch the block
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	blockID := mux.Vars(r)["blockID"]
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteSubscription", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("blockID", blockID)
+
+	if err := a.app.DeleteSubscription(ctx, *container, blockID, session.UserID); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
 }
@@ -1,8 +1,8 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/audit"
 	"github.com/mattermost/focalboard/server/services/auth"
@@ -50,9 +51,9 @@ type LoginRequest struct {
 	// required: true
 	Password string `json:"password"`
 
-	// MFA token
+	// MFA token, either a TOTP code or a recovery code, required when the
+	// user has MFA enabled
 	// required: false
-	// swagger:ignore
 	MfaToken string `json:"mfa_token"`
 }
 
@@ -131,6 +132,38 @@ func (rd *ChangePasswordRequest) IsValid() error {
 	return isValidPassword(rd.NewPassword)
 }
 
+// PasswordResetRequest is a request to email a password reset link
+// swagger:model
+type PasswordResetRequest struct {
+	// Email of the user
+	// required: true
+	Email string `json:"email"`
+}
+
+// CompletePasswordResetRequest is a request to reset a password with the
+// emailed token
+// swagger:model
+type CompletePasswordResetRequest struct {
+	// Password reset token
+	// required: true
+	Token string `json:"token"`
+
+	// New password
+	// required: true
+	NewPassword string `json:"newPassword"`
+}
+
+// IsValid validates a password reset completion request.
+func (rd *CompletePasswordResetRequest) IsValid() error {
+	if rd.Token == "" {
+		return ParamError{"token is required"}
+	}
+	if rd.NewPassword == "" {
+		return ParamError{"new password is required"}
+	}
+	return isValidPassword(rd.NewPassword)
+}
+
 func isValidPassword(password string) error {
 	if len(password) < MinimumPasswordLength {
 		return ParamError{fmt.Sprintf("password must be at least %d characters", MinimumPasswordLength)}
@@ -159,13 +192,21 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 	//     schema:
 	//       "$ref": "#/definitions/LoginResponse"
 	//   '401':
-	//     description: invalid login
+	//     description: invalid login, or missing mfa_token with error code 1004
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: password login disabled, the single sign-on is enforced, or the users are registered in the directory
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
+	//   '503':
+	//     description: the LDAP server is unavailable
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 
 	if len(a.singleUserToken) > 0 {
 		// Not permitted in single-user mode
@@ -182,7 +223,7 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 	var loginData LoginRequest
 	err = json.Unmarshal(requestBody, &loginData)
 	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
 		return
 	}
 
@@ -192,7 +233,25 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("type", loginData.Type)
 
 	if loginData.Type == "normal" {
-		token, err := a.app.Login(loginData.Username, loginData.Email, loginData.Password, loginData.MfaToken)
+		token, err := a.app.Login(loginData.Username, loginData.Email, loginData.Password, loginData.MfaToken, clientAddress(r))
+		var lockedErr *app.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			w.Header().Set("Retry-After", retryAfterSeconds(lockedErr.RetryAfter))
+			a.errorResponseWithCode(w, r.URL.Path, http.StatusTooManyRequests, ErrorTooManyRequestsCode, "too many failed logins", err)
+			return
+		}
+		if errors.Is(err, app.ErrPasswordLoginDisabled) {
+			a.errorResponse(w, r.URL.Path, http.StatusForbidden, err.Error(), err)
+			return
+		}
+		if errors.Is(err, app.ErrLDAPUnavailable) {
+			a.errorResponse(w, r.URL.Path, http.StatusServiceUnavailable, "the LDAP server is unavailable", err)
+			return
+		}
+		if errors.Is(err, app.ErrMfaRequired) {
+			a.errorResponseWithCode(w, r.URL.Path, http.StatusUnauthorized, ErrorMfaRequiredCode, "mfa token required", err)
+			return
+		}
 		if err != nil {
 			a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "incorrect login", err)
 			return
@@ -211,6 +270,63 @@ func (a *API) handleLogin(w http.ResponseWriter, r *http.Request) {
 	a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid login type", nil)
 }
 
+func (a *API) handleLogout(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/logout logout
+	//
+	// Logout user, revoking the session token
+	//
+	// ---
+	// produces:
+	// - application/json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if len(a.singleUserToken) > 0 {
+		// Not permitted in single-user mode
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "not permitted in single-user mode", nil)
+		return
+	}
+	if a.MattermostAuth {
+		// The sessions are managed by Mattermost
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "not permitted with Mattermost authentication", nil)
+		return
+	}
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	if model.IsAccessToken(session.Token) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "personal access tokens must be revoked instead", nil)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "logout", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	if err := a.app.Logout(session); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	// the sessions of the single sign-on are kept in a cookie
+	if _, location := auth.ParseAuthTokenFromRequest(r); location == auth.TokenLocationCookie {
+		http.SetCookie(w, &http.Cookie{Name: auth.SessionCookieToken, Path: "/", MaxAge: -1, HttpOnly: true})
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
 func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
 	// swagger:operation POST /api/v1/register register
 	//
@@ -231,11 +347,16 @@ func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
 	//     description: success
 	//   '401':
 	//     description: invalid registration token
+	//   '403':
+	//     description: password login disabled, the single sign-on is enforced
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: internal error
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
 
+	ctx := r.Context()
 	if len(a.singleUserToken) > 0 {
 		// Not permitted in single-user mode
 		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "not permitted in single-user mode", nil)
@@ -251,13 +372,13 @@ func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
 	var registerData RegisterRequest
 	err = json.Unmarshal(requestBody, &registerData)
 	if err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
 		return
 	}
 
 	// Validate token
 	if len(registerData.Token) > 0 {
-		workspace, err2 := a.app.GetRootWorkspace()
+		workspace, err2 := a.app.GetRootWorkspace(ctx)
 		if err2 != nil {
 			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err2)
 			return
@@ -290,6 +411,10 @@ func (a *API) handleRegister(w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("username", registerData.Username)
 
 	err = a.app.RegisterUser(registerData.Username, registerData.Email, registerData.Password)
+	if errors.Is(err, app.ErrPasswordLoginDisabled) || errors.Is(err, app.ErrLDAPRegistrationDisabled) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, err.Error(), err)
+		return
+	}
 	if err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
 		return
@@ -328,6 +453,10 @@ func (a *API) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	//     description: invalid request
 	//     schema:
 	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: the password of an LDAP user can only be changed in the directory
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
 	//   '500':
 	//     description: internal error
 	//     schema:
@@ -350,7 +479,7 @@ func (a *API) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 
 	var requestData ChangePasswordRequest
 	if err = json.Unmarshal(requestBody, &requestData); err != nil {
-		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
 		return
 	}
 
@@ -362,11 +491,175 @@ func (a *API) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	auditRec := a.makeAuditRecord(r, "changePassword", audit.Fail)
 	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
 
-	if err = a.app.ChangePassword(userID, requestData.OldPassword, requestData.NewPassword); err != nil {
+	err = a.app.ChangePassword(userID, requestData.OldPassword, requestData.NewPassword)
+	if errors.Is(err, app.ErrLDAPPasswordChange) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handlePasswordReset(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/users/password-reset passwordReset
+	//
+	// Emails a password reset link to the user with the email. The
+	// response is the same whether or not the email is registered.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: Password reset request
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PasswordResetRequest"
+	// responses:
+	//   '200':
+	//     description: success
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '501':
+	//     description: no email server configured
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if len(a.singleUserToken) > 0 {
+		// Not permitted in single-user mode
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "not permitted in single-user mode", nil)
+		return
+	}
+	if a.MattermostAuth {
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "not permitted with Mattermost authentication", nil)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var requestData PasswordResetRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if strings.TrimSpace(requestData.Email) == "" {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "email is required", nil)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "passwordReset", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	err = a.app.RequestPasswordReset(requestData.Email)
+	if errors.Is(err, app.ErrEmailNotConfigured) {
+		a.errorResponse(w, r.URL.Path, http.StatusNotImplemented, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+func (a *API) handleCompletePasswordReset(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/users/password-reset/complete completePasswordReset
+	//
+	// Sets a new password with an emailed password reset token, and logs
+	// the user out of all their sessions
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: Password reset completion request
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CompletePasswordResetRequest"
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid request, or invalid or expired token
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: the password of an LDAP user can only be changed in the directory
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if len(a.singleUserToken) > 0 {
+		// Not permitted in single-user mode
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "not permitted in single-user mode", nil)
+		return
+	}
+	if a.MattermostAuth {
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "not permitted with Mattermost authentication", nil)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var requestData CompletePasswordResetRequest
+	if err = json.Unmarshal(requestBody, &requestData); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	if err = requestData.IsValid(); err != nil {
 		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
 		return
 	}
 
+	auditRec := a.makeAuditRecord(r, "completePasswordReset", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	err = a.app.CompletePasswordReset(requestData.Token, requestData.NewPassword)
+	if errors.Is(err, app.ErrInvalidPasswordResetToken) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if errors.Is(err, app.ErrLDAPPasswordChange) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
 	jsonStringResponse(w, http.StatusOK, "{}")
 	auditRec.Success()
 }
@@ -396,7 +689,7 @@ func (a *API) attachSession(handler func(w http.ResponseWriter, r *http.Request)
 				CreateAt:    now,
 				UpdateAt:    now,
 			}
-			ctx := context.WithValue(r.Context(), sessionContextKey, session)
+			ctx := withSession(r.Context(), session)
 			handler(w, r.WithContext(ctx))
 			return
 		}
@@ -413,7 +706,7 @@ func (a *API) attachSession(handler func(w http.ResponseWriter, r *http.Request)
 				CreateAt:    now,
 				UpdateAt:    now,
 			}
-			ctx := context.WithValue(r.Context(), sessionContextKey, session)
+			ctx := withSession(r.Context(), session)
 			handler(w, r.WithContext(ctx))
 			return
 		}
@@ -440,11 +733,56 @@ func (a *API) attachSession(handler func(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), sessionContextKey, session)
+		ctx := withSession(r.Context(), session)
 		handler(w, r.WithContext(ctx))
 	}
 }
 
+// systemAdminRequired requires the session of a user who administers
+// the server. The users are managed by Mattermost with its
+// authentication, and there is no user to administer in single-user
+// mode.
+func (a *API) systemAdminRequired(handler func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return a.sessionRequired(func(w http.ResponseWriter, r *http.Request) {
+		if !a.nativeAuthAllowed(w, r) {
+			return
+		}
+
+		session := r.Context().Value(sessionContextKey).(*model.Session)
+		isAdmin, err := a.app.IsUserAdmin(session.UserID)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+			return
+		}
+		if !isAdmin {
+			a.errorResponse(w, r.URL.Path, http.StatusForbidden, "the user isn't an admin", PermissionError{"the user isn't an admin"})
+			return
+		}
+
+		handler(w, r)
+	})
+}
+
+// guestForbidden returns a handler requiring a session, whose user isn't
+// a guest. The guests only have access to the boards they are a member
+// of, and not to the settings and data of the workspace.
+func (a *API) guestForbidden(handler func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return a.sessionRequired(func(w http.ResponseWriter, r *http.Request) {
+		session := r.Context().Value(sessionContextKey).(*model.Session)
+		guest, err := a.app.IsUserGuest(session.UserID)
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+			return
+		}
+		if guest {
+			a.errorResponse(w, r.URL.Path, http.StatusForbidden, "not permitted for guests", PermissionError{"not permitted for guests"})
+			return
+		}
+
+		handler(w, r)
+	})
+}
+
 func (a *API) adminRequired(handler func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Currently, admin APIs require local unix connections
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/thumbnail"
+)
+
+const (
+	// avatarMaxSize is the size of the largest image uploaded as an
+	// avatar.
+	avatarMaxSize = 10 * 1024 * 1024
+
+	// avatarCacheControl is the caching of the avatars requested with
+	// the time they were set, whose URL changes with them.
+	avatarCacheControl = "private, max-age=31536000, immutable"
+)
+
+// UserImageResponse is the response to an upload of the avatar of a user
+// swagger:model
+type UserImageResponse struct {
+	// Time in milliseconds the avatar was set, passed as the t parameter
+	// of its URL to refresh the caches
+	// required: true
+	UpdateAt int64 `json:"updateAt"`
+}
+
+func (a *API) handleSetUserImage(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /api/v1/users/{userID}/image setUserImage
+	//
+	// Sets the avatar of the currently logged-in user, cropped to a square
+	// and scaled to 128 pixels. The GIF, JPEG and PNG images are supported
+	//
+	// ---
+	// consumes:
+	// - multipart/form-data
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID of the currently logged-in user
+	//   required: true
+	//   type: string
+	// - name: file
+	//   in: formData
+	//   type: file
+	//   description: The image of the avatar, of at most 10 MB
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/UserImageResponse"
+	//   '400':
+	//     description: invalid or unsupported image
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: the avatar of another or a deactivated user
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '413':
+	//     description: image too large
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := mux.Vars(r)["userID"]
+	if userID != session.UserID {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, "the avatars can only be set by their user", nil)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, avatarMaxSize+uploadFormOverhead)
+	file, _, err := uploadFormFile(r)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid file upload", err)
+		return
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(file, avatarMaxSize+1))
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid file upload", err)
+		return
+	}
+	if len(data) > avatarMaxSize {
+		message := fmt.Sprintf("the image is larger than the maximum size of %d bytes", avatarMaxSize)
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusRequestEntityTooLarge, ErrorFileTooLargeCode, message, nil)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "setUserImage", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("userID", userID)
+
+	updateAt, err := a.app.SetUserAvatar(userID, bytes.NewReader(data))
+	if errors.Is(err, app.ErrAvatarUserDeactivated) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, err.Error(), err)
+		return
+	}
+	if errors.Is(err, thumbnail.ErrUnsupported) || errors.Is(err, thumbnail.ErrImageTooLarge) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	response, err := json.Marshal(UserImageResponse{UpdateAt: updateAt})
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, response)
+	auditRec.Success()
+}
+
+func (a *API) handleGetUserImage(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/users/{userID}/image getUserImage
+	//
+	// Returns the PNG avatar of a user sharing a workspace with the
+	// currently logged-in user, or a generated identicon if they have
+	// none. The avatar is cached for long when requested with the time it
+	// was set, from the focalboard_avatarUpdateAt prop of the user
+	//
+	// ---
+	// produces:
+	// - image/png
+	// - application/json
+	// parameters:
+	// - name: userID
+	//   in: path
+	//   description: User ID
+	//   required: true
+	//   type: string
+	// - name: t
+	//   in: query
+	//   description: Time in milliseconds the avatar was set
+	//   required: false
+	//   type: integer
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '403':
+	//     description: the user doesn't share a workspace
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := mux.Vars(r)["userID"]
+
+	reader, modTime, err := a.app.GetUserAvatar(ctx, session.UserID, userID)
+	if errors.Is(err, app.ErrAvatarAccessDenied) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, "", err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	// the URLs with the time change with the avatar, the others are
+	// revalidated
+	if r.URL.Query().Get("t") != "" {
+		w.Header().Set("Cache-Control", avatarCacheControl)
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "avatar.png", modTime, reader)
+}
@@ -0,0 +1,344 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// BoardMemberRequest is a request to add a member to a board, or to
+// change the role of a member
+// swagger:model
+type BoardMemberRequest struct {
+	// ID of the user, omitted when changing the role of a member
+	// required: false
+	UserID string `json:"userId"`
+
+	// Role of the user on the board: viewer, editor or admin
+	// required: true
+	Role string `json:"role"`
+}
+
+func (a *API) handleGetBoardMembers(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/members getBoardMembers
+	//
+	// Returns the members of a board, none when the board is open to the
+	// workspace
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/BoardMember"
+	//   '403':
+	//     description: the user can't view the board
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getBoardMembers", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	members, err := a.app.GetBoardMembers(*container, boardID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	auditRec.AddMeta("memberCount", len(members))
+	auditRec.Success()
+}
+
+func (a *API) handlePostBoardMember(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/boards/{boardID}/members postBoardMember
+	//
+	// Adds a member to a board. Adding the first member restricts the
+	// board to its members, the user making the request becoming its
+	// admin
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the user and its role
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/BoardMemberRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/BoardMember"
+	//   '400':
+	//     description: invalid role, guest admin, or user outside of the workspace
+	//   '403':
+	//     description: the user isn't an admin of the board
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+
+	var request BoardMemberRequest
+	if !a.readBoardMemberRequest(w, r, &request) {
+		return
+	}
+	if request.UserID == "" {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "missing userId", nil)
+		return
+	}
+
+	a.upsertBoardMember(w, r, "postBoardMember", model.BoardMember{BoardID: boardID, UserID: request.UserID, Role: request.Role})
+}
+
+func (a *API) handlePutBoardMember(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /api/v1/workspaces/{workspaceID}/boards/{boardID}/members/{userID} putBoardMember
+	//
+	// Changes the role of a member of a board, adding it if needed
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: userID
+	//   in: path
+	//   description: ID of the user
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the role of the user
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/BoardMemberRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/BoardMember"
+	//   '400':
+	//     description: invalid role, guest admin, or the board would be left without an admin
+	//   '403':
+	//     description: the user isn't an admin of the board
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	vars := mux.Vars(r)
+
+	var request BoardMemberRequest
+	if !a.readBoardMemberRequest(w, r, &request) {
+		return
+	}
+
+	a.upsertBoardMember(w, r, "putBoardMember", model.BoardMember{BoardID: vars["boardID"], UserID: vars["userID"], Role: request.Role})
+}
+
+// readBoardMemberRequest reads the body of the request, and tells if it
+// did or wrote an error response.
+func (a *API) readBoardMemberRequest(w http.ResponseWriter, r *http.Request, request *BoardMemberRequest) bool {
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return false
+	}
+
+	if err = json.Unmarshal(requestBody, request); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return false
+	}
+	return true
+}
+
+func (a *API) upsertBoardMember(w http.ResponseWriter, r *http.Request, action string, member model.BoardMember) {
+	ctx := r.Context()
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, action, audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", member.BoardID)
+	auditRec.AddMeta("userID", member.UserID)
+	auditRec.AddMeta("role", member.Role)
+
+	if !a.app.DoesUserHaveWorkspaceAccess(ctx, member.UserID, container.WorkspaceID) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "the user isn't a member of the workspace", nil)
+		return
+	}
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	updated, err := a.app.UpsertBoardMember(*container, session.UserID, member)
+	if a.boardMemberErrorResponse(w, r, err) {
+		return
+	}
+
+	data, err := json.Marshal(updated)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	a.logger.Debug("UPSERT board member", mlog.String("boardID", member.BoardID), mlog.String("userID", member.UserID))
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteBoardMember(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /api/v1/workspaces/{workspaceID}/boards/{boardID}/members/{userID} deleteBoardMember
+	//
+	// Removes a member from a board. Removing the last member opens the
+	// board to the workspace again
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: userID
+	//   in: path
+	//   description: ID of the user
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: the board would be left without an admin
+	//   '403':
+	//     description: the user isn't an admin of the board
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	userID := vars["userID"]
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteBoardMember", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("userID", userID)
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	err = a.app.DeleteBoardMember(*container, session.UserID, boardID, userID)
+	if a.boardMemberErrorResponse(w, r, err) {
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	a.logger.Debug("DELETE board member", mlog.String("boardID", boardID), mlog.String("userID", userID))
+	auditRec.Success()
+}
+
+// boardMemberErrorResponse writes the error response of a change of the
+// members of a board, and tells if it did.
+func (a *API) boardMemberErrorResponse(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, app.ErrInvalidBoardRole), errors.Is(err, app.ErrLastBoardAdmin), errors.Is(err, app.ErrGuestBoardAdmin):
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, app.ErrBoardAccessDenied):
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, err.Error(), err)
+	default:
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+	}
+	return true
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// calendarCacheControl lets the calendar apps, which poll the feeds,
+// reuse them for a while. The feeds are private, as their token is part
+// of their URL.
+const calendarCacheControl = "private, max-age=900"
+
+func (a *API) handleGetBoardCalendar(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/calendar.ics getBoardCalendar
+	//
+	// Returns the calendar feed of a board, with an event per date
+	// property of each card. The feed is authenticated by its token
+	// instead of a session
+	//
+	// ---
+	// produces:
+	// - text/calendar
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: token
+	//   in: query
+	//   description: The token of the calendar feed of the board
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: board not found or invalid token
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	workspaceID := vars["workspaceID"]
+
+	if !a.MattermostAuth && workspaceID != "0" {
+		a.noContainerErrorResponse(w, r.URL.Path, errWorkspaceMismatch)
+		return
+	}
+	container := store.Container{
+		WorkspaceID: workspaceID,
+	}
+
+	auditRec := a.makeAuditRecord(r, "getBoardCalendar", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	// invalid tokens look like a missing board, so that they don't
+	// reveal that the board exists
+	isValid, err := a.app.IsValidCalendarToken(container, boardID, r.URL.Query().Get("token"))
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if !isValid {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	calendar, err := a.app.NewBoardCalendar(ctx, container, boardID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if calendar == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", boardID+".ics"))
+	w.Header().Set("Cache-Control", calendarCacheControl)
+	w.WriteHeader(http.StatusOK)
+
+	// the events are streamed, so once they are being written errors can
+	// only be logged
+	if err := calendar.Write(ctx, w); err != nil {
+		a.logger.Error("GetBoardCalendar failed", mlog.String("boardID", boardID), mlog.Err(err))
+		return
+	}
+
+	auditRec.Success()
+}
+
+func (a *API) handleGetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/calendar getCalendarFeed
+	//
+	// Returns the token of the calendar feed of a board
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/CalendarFeed"
+	//   '404':
+	//     description: the board has no calendar feed
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleViewer)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getCalendarFeed", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	feed, err := a.app.GetCalendarFeed(*container, boardID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if feed == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(feed)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handlePostCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/boards/{boardID}/calendar postCalendarFeed
+	//
+	// Creates the calendar feed of a board with a new token, revoking the
+	// previous one if any
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/CalendarFeed"
+	//   '404':
+	//     description: board not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "postCalendarFeed", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+	if userID == SingleUser {
+		userID = ""
+	}
+
+	feed, err := a.app.CreateCalendarFeed(ctx, *container, boardID, userID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if feed == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(feed)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	a.logger.Debug("POST calendar feed", mlog.String("boardID", boardID))
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /api/v1/workspaces/{workspaceID}/boards/{boardID}/calendar deleteCalendarFeed
+	//
+	// Revokes the calendar feed of a board
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: the board has no calendar feed
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteCalendarFeed", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	err = a.app.RevokeCalendarFeed(*container, boardID, session.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	a.logger.Debug("DELETE calendar feed", mlog.String("boardID", boardID))
+	auditRec.Success()
+}
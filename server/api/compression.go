@@ -0,0 +1,269 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/services/config"
+)
+
+// compressionMinSize is the size of the smallest responses compressed,
+// the smaller ones gaining less than the cost of compressing them.
+const compressionMinSize = 1024
+
+// compressionEncoding is a content encoding of the responses.
+type compressionEncoding struct {
+	name string
+
+	// newWriter returns a writer compressing to w, the level being
+	// between 1 and 9 like the gzip levels
+	newWriter func(w io.Writer, level int) (io.WriteCloser, error)
+}
+
+var gzipEncoding = compressionEncoding{
+	name: "gzip",
+	newWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+		return gzip.NewWriterLevel(w, level)
+	},
+}
+
+// compressionEncodings are the supported encodings, the preferred one
+// first. Brotli is only supported by the builds with the brotli tag.
+var compressionEncodings = []compressionEncoding{gzipEncoding}
+
+// compressedContentTypes are the types of the contents that are already
+// compressed, like the attachments, that aren't compressed again.
+var compressedContentTypes = map[string]bool{
+	"application/gzip":             true,
+	"application/x-gzip":           true,
+	"application/zip":              true,
+	"application/x-7z-compressed":  true,
+	"application/x-bzip2":          true,
+	"application/x-rar-compressed": true,
+	"application/x-xz":             true,
+	"application/zstd":             true,
+	"application/pdf":              true,
+	"application/octet-stream":     true,
+}
+
+// Compressor compresses the API responses with the encodings accepted by
+// the clients.
+type Compressor struct {
+	level int
+}
+
+// NewCompressor returns the compressor for the configuration, or nil if
+// the responses aren't compressed.
+func NewCompressor(cfg *config.Configuration) *Compressor {
+	level := cfg.CompressionLevel
+	if level < 0 {
+		return nil
+	}
+	if level == 0 || level > gzip.BestCompression {
+		level = config.DefaultCompressionLevel
+	}
+	return &Compressor{level: level}
+}
+
+// negotiate returns the preferred encoding accepted by the request, if
+// any.
+func (c *Compressor) negotiate(r *http.Request) (compressionEncoding, bool) {
+	accepted := map[string]bool{}
+	for _, item := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(item, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		quality := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				quality, _ = strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			}
+		}
+		accepted[name] = quality > 0
+	}
+
+	for _, encoding := range compressionEncodings {
+		if enabled, ok := accepted[encoding.name]; (ok && enabled) || (!ok && accepted["*"]) {
+			return encoding, true
+		}
+	}
+	return compressionEncoding{}, false
+}
+
+// compressResponses compresses the responses of at least
+// compressionMinSize bytes, with the encoding negotiated with the client.
+func (a *API) compressResponses(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.compressor == nil || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		encoding, ok := a.compressor.negotiate(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		cw := &compressResponseWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+			level:          a.compressor.level,
+			statusCode:     http.StatusOK,
+		}
+		defer cw.Close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressResponseWriter buffers the start of a response until it's
+// known to be large enough to be compressed.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding compressionEncoding
+	level    int
+
+	statusCode  int
+	wroteHeader bool
+	buffer      bytes.Buffer
+
+	// decided tells if the response is either compressed by writer, or
+	// written as is when writer is nil
+	decided bool
+	writer  io.WriteCloser
+}
+
+func (w *compressResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.statusCode = statusCode
+	if statusCode < http.StatusOK || statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+func (w *compressResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.decided {
+		if w.writer != nil {
+			return w.writer.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buffer.Write(data)
+	if w.buffer.Len() >= compressionMinSize {
+		if err := w.flushBuffer(w.compressible()); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// compressible tells if the response can be compressed, which the
+// responses already encoded and the compressed contents can't.
+func (w *compressResponseWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buffer.Bytes())
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if compressedContentTypes[mediaType] {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "image/") {
+		return mediaType == "image/svg+xml"
+	}
+	return !strings.HasPrefix(mediaType, "video/") && !strings.HasPrefix(mediaType, "audio/")
+}
+
+// decide writes the header of the response, compressed or not.
+func (w *compressResponseWriter) decide(compress bool) {
+	w.decided = true
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding.name)
+		header.Del("Content-Length")
+		writer, err := w.encoding.newWriter(w.ResponseWriter, w.level)
+		if err == nil {
+			w.writer = writer
+		} else {
+			header.Del("Content-Encoding")
+		}
+	}
+	// the encodings of a response are different representations, which
+	// can't share the strong entity tag of the handler, and the response
+	// not modified carries the tag of the compressed one
+	if w.writer != nil || w.statusCode == http.StatusNotModified {
+		if etag := w.Header().Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			w.Header().Set("ETag", "W/"+etag)
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.statusCode)
+}
+
+// flushBuffer writes the buffered start of the response, compressed or
+// not.
+func (w *compressResponseWriter) flushBuffer(compress bool) error {
+	w.decide(compress)
+	data := w.buffer.Bytes()
+	w.buffer = bytes.Buffer{}
+	if w.writer != nil {
+		_, err := w.writer.Write(data)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(data)
+	return err
+}
+
+// Flush sends the response written so far, compressing it if it's large
+// enough.
+func (w *compressResponseWriter) Flush() {
+	if !w.decided {
+		if !w.wroteHeader {
+			w.WriteHeader(http.StatusOK)
+		}
+		_ = w.flushBuffer(w.buffer.Len() >= compressionMinSize && w.compressible())
+	}
+	if flusher, ok := w.writer.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close ends the response, writing the responses too small to be
+// compressed as is.
+func (w *compressResponseWriter) Close() error {
+	if !w.decided {
+		if !w.wroteHeader {
+			// the handler wrote nothing, leave the default response
+			return nil
+		}
+		if err := w.flushBuffer(false); err != nil {
+			return err
+		}
+	}
+	if w.writer != nil {
+		return w.writer.Close()
+	}
+	return nil
+}
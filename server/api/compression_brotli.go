@@ -0,0 +1,22 @@
+//go:build brotli
+// +build brotli
+
+package api
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// the brotli encoding needs the github.com/andybalholm/brotli module,
+// so it's only built with the brotli tag
+func init() {
+	compressionEncodings = append([]compressionEncoding{{
+		name: "br",
+		newWriter: func(w io.Writer, level int) (io.WriteCloser, error) {
+			// the brotli levels go up to 11, the gzip ones to 9
+			return brotli.NewWriterLevel(w, level*brotli.BestCompression/9), nil
+		},
+	}}, compressionEncodings...)
+}
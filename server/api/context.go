@@ -4,6 +4,9 @@ import (
 	"context"
 	"net"
 	"net/http"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
 )
 
 type contextKey int
@@ -11,6 +14,7 @@ type contextKey int
 const (
 	httpConnContextKey contextKey = iota
 	sessionContextKey
+	requestInfoContextKey
 )
 
 // SetContextConn stores the connection in the request context.
@@ -27,3 +31,11 @@ func GetContextConn(r *http.Request) net.Conn {
 
 	return value.(net.Conn)
 }
+
+// withSession stores the session in the request context, and passes its
+// ID to the store.
+func withSession(ctx context.Context, session *model.Session) context.Context {
+	setRequestUserID(ctx, session.UserID)
+	ctx = context.WithValue(ctx, sessionContextKey, session)
+	return store.WithSessionID(ctx, session.ID)
+}
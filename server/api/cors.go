@@ -0,0 +1,195 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/services/config"
+)
+
+// corsAllowedMethods are the methods the cross-origin requests can use.
+const corsAllowedMethods = "GET, POST, PUT, PATCH, DELETE"
+
+// corsExposedHeaders are the headers of the responses the cross-origin
+// requests can read, besides the headers every response exposes.
+var corsExposedHeaders = []string{
+	HeaderServerFilter,
+	idempotentReplayedHeader,
+	"ETag",
+	"Retry-After",
+	RequestIDHeader,
+}
+
+// CORSPolicy allows the requests of the allowed origins from other sites.
+// The origins are allowed exactly, or by a wildcard subdomain pattern like
+// https://*.example.com. Only the exact origins can send their
+// credentials, the subdomains matching a pattern may be controlled by
+// others.
+type CORSPolicy struct {
+	exactOrigins    map[string]bool
+	wildcardOrigins []corsOriginPattern
+	allowedHeaders  string
+	maxAge          string
+}
+
+// corsOriginPattern is the scheme and the host suffix of the origins
+// matching a wildcard subdomain pattern.
+type corsOriginPattern struct {
+	scheme     string
+	hostSuffix string
+}
+
+// NewCORSPolicy returns the CORS policy for the configuration, or nil if
+// no origin is allowed, the cross-origin requests being left to the
+// browsers to block. The invalid origins of the configuration are
+// ignored.
+func NewCORSPolicy(cfg *config.Configuration) *CORSPolicy {
+	policy := &CORSPolicy{exactOrigins: map[string]bool{}}
+	for _, origin := range cfg.CORSAllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+		scheme, host, ok := splitOrigin(origin)
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(host, "*.") {
+			suffix := strings.TrimPrefix(host, "*")
+			if strings.Contains(suffix, "*") {
+				continue
+			}
+			policy.wildcardOrigins = append(policy.wildcardOrigins, corsOriginPattern{scheme: scheme, hostSuffix: suffix})
+			continue
+		}
+		if strings.Contains(host, "*") {
+			continue
+		}
+		policy.exactOrigins[scheme+"://"+host] = true
+	}
+	if len(policy.exactOrigins) == 0 && len(policy.wildcardOrigins) == 0 {
+		return nil
+	}
+
+	headers := cfg.CORSAllowedHeaders
+	if len(headers) == 0 {
+		headers = config.DefaultCORSAllowedHeaders
+	}
+	policy.allowedHeaders = strings.Join(headers, ", ")
+
+	maxAge := cfg.CORSMaxAge
+	if maxAge <= 0 {
+		maxAge = config.DefaultCORSMaxAge
+	}
+	policy.maxAge = strconv.Itoa(maxAge)
+
+	return policy
+}
+
+// splitOrigin returns the scheme and the host, with its port, of an
+// origin, which has no path, query or user.
+func splitOrigin(origin string) (string, string, bool) {
+	parts := strings.SplitN(origin, "://", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	if strings.ContainsAny(parts[1], "/?#@") {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// AllowsOrigin tells if the origin is allowed, and if it's allowed
+// exactly rather than by a wildcard pattern.
+func (p *CORSPolicy) AllowsOrigin(origin string) (allowed bool, exact bool) {
+	scheme, host, ok := splitOrigin(strings.ToLower(origin))
+	if !ok {
+		return false, false
+	}
+	if p.exactOrigins[scheme+"://"+host] {
+		return true, true
+	}
+	for _, pattern := range p.wildcardOrigins {
+		if scheme == pattern.scheme && strings.HasSuffix(host, pattern.hostSuffix) && len(host) > len(pattern.hostSuffix) {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// CheckOrigin tells if the request can be served for its origin: the
+// requests without an origin, the same-origin requests and the requests of
+// the allowed origins are, the others aren't. It checks the websocket
+// upgrades as well.
+func (p *CORSPolicy) CheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || isSameOrigin(r, origin) {
+		return true
+	}
+	allowed, _ := p.AllowsOrigin(origin)
+	return allowed
+}
+
+// isSameOrigin tells if the origin is the host the request was sent to.
+func isSameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// setHeaders sets the CORS headers of the response to the request of an
+// allowed origin.
+func (p *CORSPolicy) setHeaders(w http.ResponseWriter, origin string, exact bool) {
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if exact {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// handlePreflight answers the preflight requests of the API, for the
+// allowed origins only. The rejected ones get no CORS headers, which
+// doesn't tell the allowed origins.
+func (a *API) handlePreflight(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Origin")
+	origin := r.Header.Get("Origin")
+	allowed, exact := a.cors.AllowsOrigin(origin)
+	if !allowed {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	a.cors.setHeaders(w, origin, exact)
+	w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+	w.Header().Set("Access-Control-Allow-Headers", a.cors.allowedHeaders)
+	w.Header().Set("Access-Control-Max-Age", a.cors.maxAge)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyCORS sets the CORS headers of the API responses to the requests of
+// the allowed origins, and rejects the cross-origin requests of the
+// others.
+func (a *API) applyCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if a.cors == nil || origin == "" || !strings.HasPrefix(r.URL.Path, "/api/") || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if isSameOrigin(r, origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		allowed, exact := a.cors.AllowsOrigin(origin)
+		if !allowed {
+			a.errorResponse(w, r.URL.Path, http.StatusForbidden, "origin not allowed", nil)
+			return
+		}
+
+		a.cors.setHeaders(w, origin, exact)
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+		next.ServeHTTP(w, r)
+	})
+}
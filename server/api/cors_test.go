@@ -0,0 +1,181 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+func setupCORSRouter(t *testing.T, cfg *config.Configuration) *mux.Router {
+	t.Helper()
+	a := NewAPI(nil, "", "", mlog.CreateConsoleTestLogger(false, mlog.LvlDebug), nil, nil, nil, NewCORSPolicy(cfg), nil)
+
+	r := mux.NewRouter()
+	r.Use(a.applyCORS)
+	if a.cors != nil {
+		r.PathPrefix("/api/").Methods("OPTIONS").HandlerFunc(a.handlePreflight)
+	}
+	r.HandleFunc("/api/v1/blocks", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods("GET", "PATCH")
+	return r
+}
+
+func serveCORSRequest(r *mux.Router, method string, origin string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "http://boards.example.com/api/v1/blocks", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSPreflight(t *testing.T) {
+	cfg := &config.Configuration{
+		CORSAllowedOrigins: []string{"https://app.example.org", "https://*.example.net"},
+		CORSAllowedHeaders: []string{"Content-Type", "X-Requested-With"},
+		CORSMaxAge:         120,
+	}
+	r := setupCORSRouter(t, cfg)
+	preflight := map[string]string{"Access-Control-Request-Method": "PATCH"}
+
+	t.Run("exact origin", func(t *testing.T) {
+		rec := serveCORSRequest(r, http.MethodOptions, "https://app.example.org", preflight)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, "https://app.example.org", rec.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		require.Equal(t, corsAllowedMethods, rec.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "Content-Type, X-Requested-With", rec.Header().Get("Access-Control-Allow-Headers"))
+		require.Equal(t, "120", rec.Header().Get("Access-Control-Max-Age"))
+		require.Equal(t, "Origin", rec.Header().Get("Vary"))
+	})
+
+	t.Run("wildcard origin without credentials", func(t *testing.T) {
+		rec := serveCORSRequest(r, http.MethodOptions, "https://team.example.net", preflight)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, "https://team.example.net", rec.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("disallowed origins", func(t *testing.T) {
+		origins := []string{
+			"https://evil.example.com",
+			"http://app.example.org",
+			"https://app.example.org.evil.com",
+			"https://example.net",
+			"http://team.example.net",
+			"https://evilexample.net",
+			"null",
+		}
+		for _, origin := range origins {
+			rec := serveCORSRequest(r, http.MethodOptions, origin, preflight)
+			require.Equal(t, http.StatusForbidden, rec.Code, origin)
+			require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"), origin)
+			require.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"), origin)
+			require.Empty(t, rec.Header().Get("Access-Control-Allow-Headers"), origin)
+			require.Empty(t, rec.Body.String(), origin)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		r := setupCORSRouter(t, &config.Configuration{CORSAllowedOrigins: []string{"https://app.example.org/"}})
+		rec := serveCORSRequest(r, http.MethodOptions, "https://APP.example.org", preflight)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Idempotency-Key")
+		require.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	})
+}
+
+func TestCORSRequests(t *testing.T) {
+	cfg := &config.Configuration{
+		CORSAllowedOrigins: []string{"https://app.example.org", "https://*.example.net"},
+	}
+	r := setupCORSRouter(t, cfg)
+
+	t.Run("exact origin", func(t *testing.T) {
+		rec := serveCORSRequest(r, http.MethodGet, "https://app.example.org", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "https://app.example.org", rec.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+		exposed := strings.Split(rec.Header().Get("Access-Control-Expose-Headers"), ", ")
+		for _, header := range []string{HeaderServerFilter, idempotentReplayedHeader, "ETag", "Retry-After", RequestIDHeader} {
+			require.Contains(t, exposed, header)
+		}
+	})
+
+	t.Run("wildcard origin without credentials", func(t *testing.T) {
+		rec := serveCORSRequest(r, http.MethodPatch, "https://a.b.example.net", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "https://a.b.example.net", rec.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		rec := serveCORSRequest(r, http.MethodGet, "https://evil.example.com", nil)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		require.NotContains(t, rec.Body.String(), "example.org")
+		require.NotContains(t, rec.Body.String(), "example.net")
+	})
+
+	t.Run("same origin", func(t *testing.T) {
+		rec := serveCORSRequest(r, http.MethodGet, "http://boards.example.com", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("no origin", func(t *testing.T) {
+		rec := serveCORSRequest(r, http.MethodGet, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		r := setupCORSRouter(t, &config.Configuration{})
+		rec := serveCORSRequest(r, http.MethodGet, "https://evil.example.com", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+		rec = serveCORSRequest(r, http.MethodOptions, "https://app.example.org", nil)
+		require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestCORSCheckOrigin(t *testing.T) {
+	policy := NewCORSPolicy(&config.Configuration{
+		CORSAllowedOrigins: []string{"https://app.example.org", "https://*.example.net", "*", "https://*.*.example.com", "app.example.com"},
+	})
+	require.NotNil(t, policy)
+
+	upgrade := func(origin string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://boards.example.com/ws", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		return req
+	}
+
+	require.True(t, policy.CheckOrigin(upgrade("")))
+	require.True(t, policy.CheckOrigin(upgrade("http://boards.example.com")))
+	require.True(t, policy.CheckOrigin(upgrade("https://app.example.org")))
+	require.True(t, policy.CheckOrigin(upgrade("https://team.example.net")))
+	require.False(t, policy.CheckOrigin(upgrade("https://evil.example.com")))
+	require.False(t, policy.CheckOrigin(upgrade("https://a.b.example.com")))
+	require.False(t, policy.CheckOrigin(upgrade("null")))
+
+	require.Nil(t, NewCORSPolicy(&config.Configuration{CORSAllowedOrigins: []string{"*", "example.org"}}))
+}
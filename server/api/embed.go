@@ -0,0 +1,282 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// BoardEmbedRequest is a request to create a signed embed URL of a board
+// swagger:model
+type BoardEmbedRequest struct {
+	// Expiry time in milliseconds, omit for the default duration of 30 days
+	// required: false
+	ExpireAt int64 `json:"expireAt"`
+}
+
+// embedTemplate is the minimal page of an embedded board, listing its
+// cards, with the blocks of the board for the scripts of the embedding
+// site.
+var embedTemplate = template.Must(template.New("embed").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Board.Title}}</title>
+</head>
+<body>
+<h1>{{.Board.Title}}</h1>
+<ul>
+{{- range .Cards}}
+<li>{{.Title}}</li>
+{{- end}}
+</ul>
+<script type="application/json" id="focalboard-blocks">{{.Blocks}}</script>
+</body>
+</html>
+`))
+
+func (a *API) handlePostBoardEmbed(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/boards/{boardID}/embed postBoardEmbed
+	//
+	// Creates a signed URL giving read-only access to a board, to embed it
+	// in other sites
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: expiry of the URL
+	//   required: false
+	//   schema:
+	//     "$ref": "#/definitions/BoardEmbedRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/BoardEmbed"
+	//   '400':
+	//     description: invalid expiry
+	//   '404':
+	//     description: board not found
+	//   '501':
+	//     description: no embed signing key configured
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var request BoardEmbedRequest
+	if len(requestBody) > 0 {
+		if err = json.Unmarshal(requestBody, &request); err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "postBoardEmbed", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("expireAt", request.ExpireAt)
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	userID := session.UserID
+	if userID == SingleUser {
+		userID = ""
+	}
+
+	embed, err := a.app.CreateBoardEmbed(ctx, *container, boardID, request.ExpireAt, userID)
+	switch {
+	case errors.Is(err, app.ErrEmbedNotConfigured):
+		a.errorResponse(w, r.URL.Path, http.StatusNotImplemented, err.Error(), err)
+		return
+	case errors.Is(err, app.ErrInvalidEmbedExpiry):
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	case err != nil:
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	case embed == nil:
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(embed)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	a.logger.Debug("POST board embed", mlog.String("boardID", boardID))
+	auditRec.Success()
+}
+
+func (a *API) handleGetBoardEmbed(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/embed getBoardEmbed
+	//
+	// Returns the blocks of a board, or a minimal page listing its cards,
+	// for a signed embed URL. The URL is authenticated by its signature
+	// instead of a session
+	//
+	// ---
+	// produces:
+	// - application/json
+	// - text/html
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: expires
+	//   in: query
+	//   description: Expiry time of the URL in milliseconds
+	//   required: true
+	//   type: integer
+	// - name: signature
+	//   in: query
+	//   description: Signature of the URL
+	//   required: true
+	//   type: string
+	// - name: format
+	//   in: query
+	//   description: html for the page of the board, omit for the blocks as JSON
+	//   required: false
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Block"
+	//   '404':
+	//     description: board not found, or invalid or expired signature
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	boardID := vars["boardID"]
+	workspaceID := vars["workspaceID"]
+	query := r.URL.Query()
+
+	if !a.MattermostAuth && workspaceID != "0" {
+		a.noContainerErrorResponse(w, r.URL.Path, errWorkspaceMismatch)
+		return
+	}
+	container := store.Container{
+		WorkspaceID: workspaceID,
+	}
+
+	auditRec := a.makeAuditRecord(r, "getBoardEmbed", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	// invalid and expired signatures look like a missing board, so that
+	// they don't reveal that the board exists
+	expireAt, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if !a.app.IsValidEmbedSignature(container, boardID, expireAt, query.Get("signature")) {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	board, blocks, err := a.app.GetEmbeddedBoard(ctx, container, boardID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if board == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(blocks)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	// the embeds are shown in the frames of other sites, so the
+	// X-Frame-Options set before, e.g. by Mattermost, is removed, and the
+	// sites allowed to embed them are set by the frame-ancestors directive
+	w.Header().Del("X-Frame-Options")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors "+a.app.GetEmbedFrameAncestors())
+
+	if query.Get("format") != "html" {
+		jsonBytesResponse(w, http.StatusOK, data)
+		auditRec.Success()
+		return
+	}
+
+	cards := []model.Block{}
+	for _, block := range blocks {
+		if isTemplate, _ := block.Fields["isTemplate"].(bool); block.Type == "card" && block.ParentID == board.ID && !isTemplate {
+			cards = append(cards, block)
+		}
+	}
+
+	// json.Marshal escapes <, > and &, so the blocks can't close their
+	// script element
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	err = embedTemplate.Execute(w, struct {
+		Board  *model.Block
+		Cards  []model.Block
+		Blocks template.JS
+	}{board, cards, template.JS(data)}) //nolint:gosec
+	if err != nil {
+		a.logger.Error("GetBoardEmbed failed", mlog.String("boardID", boardID), mlog.Err(err))
+		return
+	}
+
+	auditRec.Success()
+}
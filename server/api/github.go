@@ -0,0 +1,479 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/github"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// gitHubWebhookMaxBodySize is the largest GitHub webhook payload
+// accepted, the issues events being far smaller.
+const gitHubWebhookMaxBodySize = 1024 * 1024
+
+// GitHubIntegrationRequest is the configuration of the GitHub integration
+// of a board
+// swagger:model
+type GitHubIntegrationRequest struct {
+	// The repository, as "owner/name"
+	// required: true
+	Repository string `json:"repository"`
+
+	// The GitHub token, stored encrypted. Required when the integration
+	// is created, the current token is kept if empty
+	// required: false
+	Token string `json:"token"`
+
+	// Only the issues with all these labels are synced
+	// required: false
+	LabelFilter []string `json:"labelFilter"`
+
+	// Whether closing a card of an open issue comments on the issue
+	// required: false
+	CommentOnClose bool `json:"commentOnClose"`
+}
+
+func (a *API) handleGetGitHubIntegration(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/github getGitHubIntegration
+	//
+	// Returns the GitHub integration of a board, without its secrets
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/GitHubIntegration"
+	//   '404':
+	//     description: the board has no GitHub integration
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getGitHubIntegration", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	integration, err := a.app.GetGitHubIntegration(*container, boardID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if integration == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(integration)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handlePutGitHubIntegration(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /api/v1/workspaces/{workspaceID}/boards/{boardID}/github putGitHubIntegration
+	//
+	// Configures the GitHub integration of a board, which syncs the issues of a repository into cards, adding
+	// the State and Labels properties to the board if it lacks them. The issues are polled, and the GitHub
+	// webhook of the repository can be pointed to the webhook URL of the board, with the returned secret,
+	// for the changes to sync right away. The cards are changed on behalf of the calling user
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the configuration of the integration
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/GitHubIntegrationRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, the integration with the secret of its webhook
+	//     schema:
+	//       "$ref": "#/definitions/GitHubIntegration"
+	//   '400':
+	//     description: invalid configuration
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: board not found
+	//   '501':
+	//     description: no integrations encryption key is configured
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var request GitHubIntegrationRequest
+	if err = json.Unmarshal(requestBody, &request); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "putGitHubIntegration", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("repository", request.Repository)
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	config := app.GitHubIntegrationConfig{
+		Repository:     request.Repository,
+		Token:          request.Token,
+		LabelFilter:    request.LabelFilter,
+		CommentOnClose: request.CommentOnClose,
+	}
+	integration, err := a.app.ConfigureGitHubIntegration(ctx, *container, boardID, session.UserID, config)
+	var configErr app.InvalidGitHubIntegrationError
+	switch {
+	case errors.Is(err, app.ErrIntegrationsNotConfigured):
+		a.errorResponse(w, r.URL.Path, http.StatusNotImplemented, err.Error(), err)
+		return
+	case errors.As(err, &configErr):
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	case errors.Is(err, app.ErrBlockLocked):
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusConflict, ErrorBlockLockedCode, err.Error(), err)
+		return
+	case err != nil:
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	case integration == nil:
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(integration)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	a.logger.Debug("PUT GitHub integration", mlog.String("boardID", boardID), mlog.String("repository", integration.Repository))
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteGitHubIntegration(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /api/v1/workspaces/{workspaceID}/boards/{boardID}/github deleteGitHubIntegration
+	//
+	// Deletes the GitHub integration of a board. Its cards are kept
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: the board has no GitHub integration
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteGitHubIntegration", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	err = a.app.DeleteGitHubIntegration(*container, boardID, session.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	a.logger.Debug("DELETE GitHub integration", mlog.String("boardID", boardID))
+	auditRec.Success()
+}
+
+func (a *API) handlePostGitHubSync(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/boards/{boardID}/github/sync postGitHubSync
+	//
+	// Syncs the GitHub integration of a board now, instead of waiting for the next poll
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success, the integration after the sync
+	//     schema:
+	//       "$ref": "#/definitions/GitHubIntegration"
+	//   '404':
+	//     description: the board has no GitHub integration
+	//   '429':
+	//     description: the GitHub rate limit of the integration is exhausted
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '501':
+	//     description: no integrations encryption key is configured
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '502':
+	//     description: the sync failed
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "postGitHubSync", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	integration, err := a.app.SyncGitHubIntegration(ctx, *container, boardID)
+	var rateLimitErr github.RateLimitError
+	switch {
+	case errors.Is(err, app.ErrIntegrationsNotConfigured):
+		a.errorResponse(w, r.URL.Path, http.StatusNotImplemented, err.Error(), err)
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", err)
+		return
+	case errors.As(err, &rateLimitErr):
+		w.Header().Set("Retry-After", retryAfterSeconds(time.Until(rateLimitErr.ResetAt)))
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusTooManyRequests, ErrorTooManyRequestsCode, err.Error(), err)
+		return
+	case err != nil:
+		a.errorResponse(w, r.URL.Path, http.StatusBadGateway, err.Error(), err)
+		return
+	}
+
+	data, err := json.Marshal(integration)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handlePostGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/boards/{boardID}/github/webhook postGitHubWebhook
+	//
+	// Receives the GitHub webhook deliveries of the repository of the GitHub integration of a board, syncing
+	// the issue of the "issues" events. The deliveries are authenticated by their signature with the secret of
+	// the integration instead of a session
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: X-GitHub-Event
+	//   in: header
+	//   description: The name of the event
+	//   required: true
+	//   type: string
+	// - name: X-Hub-Signature-256
+	//   in: header
+	//   description: The HMAC-SHA256 signature of the payload with the webhook secret
+	//   required: true
+	//   type: string
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid payload
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '401':
+	//     description: invalid signature
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: the board has no GitHub integration
+	//   '501':
+	//     description: no integrations encryption key is configured
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	workspaceID := vars["workspaceID"]
+	boardID := vars["boardID"]
+
+	if !a.MattermostAuth && workspaceID != "0" {
+		a.noContainerErrorResponse(w, r.URL.Path, errWorkspaceMismatch)
+		return
+	}
+
+	container := store.Container{
+		WorkspaceID: workspaceID,
+	}
+
+	auditRec := a.makeAuditRecord(r, "postGitHubWebhook", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	requestBody, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, gitHubWebhookMaxBodySize))
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusRequestEntityTooLarge, "the payload is too large", err)
+		return
+	}
+
+	event := r.Header.Get(github.EventHeader)
+	err = a.app.HandleGitHubWebhook(ctx, container, boardID, event, requestBody, r.Header.Get(github.SignatureHeader))
+	var payloadErr app.InvalidGitHubIntegrationError
+	switch {
+	case errors.Is(err, app.ErrIntegrationsNotConfigured):
+		a.errorResponse(w, r.URL.Path, http.StatusNotImplemented, err.Error(), err)
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", err)
+		return
+	case errors.Is(err, app.ErrInvalidGitHubSignature):
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, err.Error(), err)
+		return
+	case errors.As(err, &payloadErr):
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	case err != nil:
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	a.logger.Debug("GitHub webhook", mlog.String("boardID", boardID), mlog.String("event", event))
+	auditRec.AddMeta("event", event)
+	auditRec.Success()
+}
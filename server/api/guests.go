@@ -0,0 +1,227 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GuestInviteRequest is a request to invite an external user to a board
+// as a guest
+// swagger:model
+type GuestInviteRequest struct {
+	// Role of the guest on the board: viewer or editor
+	// required: true
+	Role string `json:"role"`
+}
+
+// GuestInviteResponse is a guest invite, with its token
+// swagger:model
+type GuestInviteResponse struct {
+	// Token to sign up as a guest with, only returned once
+	// required: true
+	Token string `json:"token"`
+
+	// ID of the board the guest is invited to
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// Role of the guest on the board
+	// required: true
+	Role string `json:"role"`
+
+	// Expiry time in milliseconds
+	// required: true
+	ExpireAt int64 `json:"expireAt"`
+}
+
+func (a *API) handlePostGuestInvite(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/boards/{boardID}/guest_invites postGuestInvite
+	//
+	// Creates a single-use invite for an external user to sign up as a
+	// guest, who only has access to the board. The token is only returned
+	// by this call.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the role of the guest
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/GuestInviteRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/GuestInviteResponse"
+	//   '400':
+	//     description: invalid role
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//   '403':
+	//     description: the user can't manage the members of the board
+	//   '404':
+	//     description: board not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainer(r)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var request GuestInviteRequest
+	if err = json.Unmarshal(requestBody, &request); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "postGuestInvite", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("role", request.Role)
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+	token, invite, err := a.app.CreateGuestInvite(ctx, *container, session.UserID, boardID, request.Role)
+	if errors.Is(err, app.ErrInvalidBoardRole) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(GuestInviteResponse{
+		Token:    token,
+		BoardID:  invite.BoardID,
+		Role:     invite.Role,
+		ExpireAt: invite.ExpireAt,
+	})
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	a.logger.Debug("POST guest invite", mlog.String("boardID", boardID))
+	auditRec.Success()
+}
+
+func (a *API) handleRegisterGuest(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/register/guest registerGuest
+	//
+	// Registers a guest with the token of a guest invite, adding them to
+	// the board of the invite
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   description: Register request, with the token of the invite
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/RegisterRequest"
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid registration data
+	//   '401':
+	//     description: invalid or expired invite, or not permitted in single-user mode or with Mattermost authentication
+	//   '403':
+	//     description: password login disabled, the single sign-on is enforced
+	//   '500':
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var registerData RegisterRequest
+	if err = json.Unmarshal(requestBody, &registerData); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+	if registerData.Token == "" {
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, "invalid token", nil)
+		return
+	}
+	if err = registerData.IsValid(); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "registerGuest", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+	auditRec.AddMeta("username", registerData.Username)
+
+	user, err := a.app.RegisterGuest(registerData.Token, registerData.Username, registerData.Email, registerData.Password)
+	if errors.Is(err, app.ErrInvalidGuestInvite) {
+		a.errorResponse(w, r.URL.Path, http.StatusUnauthorized, err.Error(), err)
+		return
+	}
+	if errors.Is(err, app.ErrPasswordLoginDisabled) {
+		a.errorResponse(w, r.URL.Path, http.StatusForbidden, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	auditRec.AddMeta("userID", user.ID)
+	auditRec.Success()
+}
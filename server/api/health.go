@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+func (a *API) handlePing(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/ping ping
+	//
+	// Checks the database is reachable, for readiness probes. Over the
+	// local admin socket, the response includes the error of the check
+	// and the statistics of the connection pool
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ServerHealth"
+	//   '503':
+	//     description: the database check failed
+	//     schema:
+	//       "$ref": "#/definitions/ServerHealth"
+
+	ctx := r.Context()
+	_, isAdmin := GetContextConn(r).(*net.UnixConn)
+	health := a.app.GetServerHealth(ctx, isAdmin)
+
+	data, err := json.Marshal(health)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	code := http.StatusOK
+	if health.Status != model.HealthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	jsonBytesResponse(w, code, data)
+}
+
+func (a *API) handleHealth(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/health health
+	//
+	// Checks the server is alive, for liveness probes. The dependencies
+	// of the server aren't checked
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Liveness"
+
+	data, err := json.Marshal(a.app.GetLiveness())
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/readiness readiness
+	//
+	// Checks the server can serve requests, for readiness probes: the
+	// database is reachable, the filestore is writable and the migrations
+	// are applied. The server isn't ready either while it's shutting down
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/Readiness"
+	//   '503':
+	//     description: a check failed, or the server is shutting down
+	//     schema:
+	//       "$ref": "#/definitions/Readiness"
+
+	readiness := a.app.GetReadiness(r.Context())
+
+	data, err := json.Marshal(readiness)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	code := http.StatusOK
+	if readiness.Status != model.HealthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	jsonBytesResponse(w, code, data)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// idempotentReplayedHeader is set on the stored responses returned for
+// the retries of a request with an idempotency key.
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// responseRecorder records the status and the body of a response while
+// writing it.
+type responseRecorder struct {
+	statusRecorder
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+// idempotent handles the requests with an Idempotency-Key header once
+// per key and user, the retries of a request getting the response of the
+// first one. The requests that fail with a server error aren't recorded,
+// so that their retries are handled again. A key used for another
+// request is rejected with a conflict.
+func (a *API) idempotent(handler func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(model.IdempotencyKeyHeader)
+		if key == "" {
+			handler(w, r)
+			return
+		}
+		if len(key) > model.IdempotencyKeyMaxLength {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "the idempotency key is too long", nil)
+			return
+		}
+
+		ctx := r.Context()
+		session := ctx.Value(sessionContextKey).(*model.Session)
+
+		// the body is hashed whole, so it's limited like the blocks
+		requestBody, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, BlocksMaxBodySize))
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusRequestEntityTooLarge, "the request is too large", err)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+		hash.Write(requestBody)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		idempotencyKey, claimed, err := a.app.ClaimIdempotencyKey(ctx, session.UserID, key, requestHash)
+		if errors.Is(err, app.ErrIdempotencyKeyReused) || errors.Is(err, app.ErrIdempotencyKeyInProgress) {
+			a.errorResponseWithCode(w, r.URL.Path, http.StatusConflict, ErrorIdempotencyKeyCode, err.Error(), err)
+			return
+		}
+		if err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+			return
+		}
+		if !claimed {
+			w.Header().Set(idempotentReplayedHeader, "true")
+			jsonStringResponse(w, idempotencyKey.StatusCode, idempotencyKey.Response)
+			return
+		}
+
+		recorder := &responseRecorder{statusRecorder: statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}}
+		handler(recorder, r)
+
+		if recorder.statusCode >= http.StatusInternalServerError {
+			err = a.app.ReleaseIdempotencyKey(*idempotencyKey)
+		} else {
+			err = a.app.SetIdempotencyKeyResponse(*idempotencyKey, recorder.statusCode, recorder.body.String())
+		}
+		if err != nil {
+			a.logger.Error("Unable to record the response of the idempotency key", mlog.String("userID", session.UserID), mlog.Err(err))
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+const (
+	// inboundHookRateLimitPerSecond and inboundHookRateLimitBurst limit
+	// the deliveries per inbound hook token, whatever the client address
+	inboundHookRateLimitPerSecond = 1
+	inboundHookRateLimitBurst     = 20
+
+	// inboundHookMaxBodySize is the largest payload accepted by the
+	// inbound hooks
+	inboundHookMaxBodySize = 64 * 1024
+)
+
+// InboundHookRequest is the configuration of a new inbound webhook of a
+// board
+// swagger:model
+type InboundHookRequest struct {
+	// Whether unknown option names create new options of the select
+	// properties of the board, instead of failing the delivery
+	// required: false
+	AllowNewOptions bool `json:"allowNewOptions"`
+}
+
+func (a *API) handlePostInboundHookDelivery(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/hooks/{hookToken} postInboundHookDelivery
+	//
+	// Creates a card of the board of an inbound webhook, or updates one of its cards if the payload has a card
+	// ID. The properties are set by name, and the select properties by option name. The hook is authenticated
+	// by its token instead of a session, and its deliveries are rate limited per token
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: hookToken
+	//   in: path
+	//   description: The token of the inbound webhook
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the card to create or update
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/InboundHookPayload"
+	// responses:
+	//   '200':
+	//     description: success, the created or updated card
+	//     schema:
+	//       "$ref": "#/definitions/Block"
+	//   '400':
+	//     description: invalid payload, or the payload doesn't match the properties of the board
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '403':
+	//     description: the creator of the hook can't edit the board, or the workspace quota is exceeded
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: invalid token, or card not found
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '429':
+	//     description: too many deliveries for the token
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	token := mux.Vars(r)["hookToken"]
+
+	// the requests with invalid tokens are limited as well, so that the
+	// tokens can't be guessed
+	allowed, retryAfter := a.inboundHookLimiter.Allow(model.HashToken(token))
+	if !allowed {
+		w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+		a.errorResponseWithCode(w, r.URL.Path, http.StatusTooManyRequests, ErrorTooManyRequestsCode, "too many requests", nil)
+		return
+	}
+
+	hook, err := a.app.GetInboundHookByToken(token)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if hook == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "postInboundHookDelivery", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", hook.BoardID)
+
+	requestBody, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, inboundHookMaxBodySize))
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusRequestEntityTooLarge, "the payload is too large", err)
+		return
+	}
+
+	// every delivery is recorded with the status of its response, for
+	// debugging the integration
+	recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+	cardID := ""
+	deliveryErr := ""
+	defer func() {
+		a.app.RecordInboundHookDelivery(*hook, string(requestBody), cardID, recorder.statusCode, deliveryErr)
+	}()
+
+	var payload model.InboundHookPayload
+	decoder := json.NewDecoder(bytes.NewReader(requestBody))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&payload); err != nil {
+		deliveryErr = err.Error()
+		a.errorResponse(recorder, r.URL.Path, http.StatusBadRequest, "invalid payload", err)
+		return
+	}
+
+	card, err := a.app.ApplyInboundHook(ctx, *hook, payload)
+	var payloadErr app.InvalidInboundHookPayloadError
+	if err != nil {
+		deliveryErr = err.Error()
+	}
+	if errors.As(err, &payloadErr) {
+		a.errorResponse(recorder, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if errors.Is(err, app.ErrBlockLocked) {
+		a.errorResponseWithCode(recorder, r.URL.Path, http.StatusConflict, ErrorBlockLockedCode, err.Error(), err)
+		return
+	}
+	if a.invalidBlockResponse(recorder, r.URL.Path, err) {
+		return
+	}
+	if a.quotaExceededResponse(recorder, r.URL.Path, err) {
+		return
+	}
+	if err != nil {
+		a.errorResponse(recorder, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	cardID = card.ID
+
+	a.logger.Debug("InboundHookDelivery", mlog.String("boardID", hook.BoardID), mlog.String("cardID", card.ID))
+
+	data, err := json.Marshal(card)
+	if err != nil {
+		a.errorResponse(recorder, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	jsonBytesResponse(recorder, http.StatusOK, data)
+
+	auditRec.AddMeta("cardID", card.ID)
+	auditRec.Success()
+}
+
+func (a *API) handleGetInboundHook(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/inbound-hook getInboundHook
+	//
+	// Returns the inbound webhook of a board, without its token
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/InboundHook"
+	//   '404':
+	//     description: the board has no inbound webhook
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getInboundHook", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	hook, err := a.app.GetInboundHook(*container, boardID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if hook == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(hook)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handlePostInboundHook(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/workspaces/{workspaceID}/boards/{boardID}/inbound-hook postInboundHook
+	//
+	// Creates the inbound webhook of a board with a new token, revoking the previous one if any. The token is
+	// only returned by this call. The cards are changed through the hook on behalf of the calling user
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// - name: Body
+	//   in: body
+	//   description: the configuration of the hook
+	//   required: false
+	//   schema:
+	//     "$ref": "#/definitions/InboundHookRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/InboundHook"
+	//   '400':
+	//     description: invalid request
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: board not found
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var request InboundHookRequest
+	if len(requestBody) > 0 {
+		if err = json.Unmarshal(requestBody, &request); err != nil {
+			a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+			return
+		}
+	}
+
+	auditRec := a.makeAuditRecord(r, "postInboundHook", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+	auditRec.AddMeta("allowNewOptions", request.AllowNewOptions)
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	hook, err := a.app.CreateInboundHook(ctx, *container, boardID, session.UserID, request.AllowNewOptions)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+	if hook == nil {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	data, err := json.Marshal(hook)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+
+	a.logger.Debug("POST inbound hook", mlog.String("boardID", boardID))
+	auditRec.Success()
+}
+
+func (a *API) handleDeleteInboundHook(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation DELETE /api/v1/workspaces/{workspaceID}/boards/{boardID}/inbound-hook deleteInboundHook
+	//
+	// Revokes the inbound webhook of a board
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: the board has no inbound webhook
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "deleteInboundHook", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	err = a.app.RevokeInboundHook(*container, boardID, session.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+
+	a.logger.Debug("DELETE inbound hook", mlog.String("boardID", boardID))
+	auditRec.Success()
+}
+
+func (a *API) handleGetInboundHookDeliveries(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/boards/{boardID}/inbound-hook/deliveries getInboundHookDeliveries
+	//
+	// Returns the last 50 deliveries of the inbound webhook of a board, the most recent first, with their payload
+	// and the status of their response
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: boardID
+	//   in: path
+	//   description: ID of the board
+	//   required: true
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/InboundHookDelivery"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	boardID := mux.Vars(r)["boardID"]
+
+	container, err := a.getContainerForBlock(r, boardID, model.BoardRoleAdmin)
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "getInboundHookDeliveries", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("boardID", boardID)
+
+	deliveries, err := a.app.GetInboundHookDeliveries(*container, boardID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(deliveries)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
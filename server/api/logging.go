@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// RequestIDHeader is the header of the ID of a request. The ID sent by
+// the client, or by a proxy, is kept if it's valid, and the ID of every
+// request is returned in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// redactedValue replaces the values of the sensitive parameters in the
+// logs.
+const redactedValue = "REDACTED"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// sensitiveParams are the substrings of the names of the query
+// parameters whose values are never logged.
+var sensitiveParams = []string{"token", "password", "secret", "signature", "code", "key"}
+
+// requestInfo is the information of a request logged on its completion,
+// the user being set once the session is known.
+type requestInfo struct {
+	id     string
+	userID string
+}
+
+// GetRequestID returns the ID of the request of the context, if any.
+func GetRequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(requestInfoContextKey).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// setRequestUserID records the user of the request of the context, for
+// its completion log.
+func setRequestUserID(ctx context.Context, userID string) {
+	if info, ok := ctx.Value(requestInfoContextKey).(*requestInfo); ok {
+		info.userID = userID
+	}
+}
+
+// logRequests assigns an ID to the requests, returned in the response and
+// passed to the websocket server in the request header, and logs them on
+// their completion. The API requests are logged at the info level, the
+// other ones at the debug level.
+func (a *API) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = utils.CreateGUID()
+		}
+		r.Header.Set(RequestIDHeader, id)
+		w.Header().Set(RequestIDHeader, id)
+
+		info := &requestInfo{id: id}
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), requestInfoContextKey, info)))
+
+		route := r.URL.Path
+		if currentRoute := mux.CurrentRoute(r); currentRoute != nil {
+			if template, err := currentRoute.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		level := mlog.LvlDebug
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			level = mlog.LvlInfo
+		}
+		a.logger.Log(level, "HTTP request",
+			mlog.String("requestID", id),
+			mlog.String("method", r.Method),
+			mlog.String("route", route),
+			mlog.String("query", redactQuery(r.URL.Query())),
+			mlog.Int("status", recorder.statusCode),
+			mlog.Duration("duration", time.Since(start)),
+			mlog.String("userID", info.userID),
+		)
+	})
+}
+
+// redactQuery returns the query with the values of the sensitive
+// parameters redacted, sorted by parameter.
+func redactQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	params := []string{}
+	for _, key := range keys {
+		for _, value := range query[key] {
+			if isSensitiveParam(key) {
+				value = redactedValue
+			}
+			params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func isSensitiveParam(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveParams {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+)
+
+// MfaActivateResponse is the TOTP secret of an MFA activation
+// swagger:model
+type MfaActivateResponse struct {
+	// Base32 encoded TOTP secret
+	// required: true
+	Secret string `json:"secret"`
+
+	// otpauth URL of the secret, for the QR code of the authenticator apps
+	// required: true
+	URL string `json:"url"`
+}
+
+// MfaCodeRequest is a request with an MFA code
+// swagger:model
+type MfaCodeRequest struct {
+	// TOTP code, or a recovery code when deactivating
+	// required: true
+	Code string `json:"code"`
+}
+
+// MfaConfirmResponse is the recovery codes of a confirmed MFA activation
+// swagger:model
+type MfaConfirmResponse struct {
+	// One-time recovery codes, only returned by this call
+	// required: true
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+func (a *API) handleActivateMfa(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/users/me/mfa/activate activateMfa
+	//
+	// Generates a TOTP secret for the current user. MFA is only enabled
+	// once a code of the secret is confirmed.
+	//
+	// ---
+	// produces:
+	// - application/json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/MfaActivateResponse"
+	//   '400':
+	//     description: mfa is already active
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '501':
+	//     description: no mfa encryption key configured
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	secret, url, err := a.app.ActivateMfa(session.UserID)
+	if err != nil {
+		a.mfaErrorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(MfaActivateResponse{Secret: secret, URL: url})
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
+
+func (a *API) handleConfirmMfa(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/users/me/mfa/confirm confirmMfa
+	//
+	// Enables MFA for the current user after checking a code of the
+	// activated secret, and returns the recovery codes
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: TOTP code of the activated secret
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/MfaCodeRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/MfaConfirmResponse"
+	//   '400':
+	//     description: invalid code, or mfa not activated
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	request, ok := a.readMfaCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	auditRec := a.makeAuditRecord(r, "confirmMfa", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	recoveryCodes, err := a.app.ConfirmMfa(session.UserID, request.Code)
+	if err != nil {
+		a.mfaErrorResponse(w, r, err)
+		return
+	}
+
+	data, err := json.Marshal(MfaConfirmResponse{RecoveryCodes: recoveryCodes})
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.Success()
+}
+
+func (a *API) handleDeactivateMfa(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/users/me/mfa/deactivate deactivateMfa
+	//
+	// Disables MFA for the current user, after checking a TOTP code or a
+	// recovery code
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: TOTP code or recovery code
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/MfaCodeRequest"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//   '400':
+	//     description: invalid code, or mfa not active
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	request, ok := a.readMfaCodeRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	auditRec := a.makeAuditRecord(r, "deactivateMfa", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	if err := a.app.DeactivateMfa(session.UserID, request.Code); err != nil {
+		a.mfaErrorResponse(w, r, err)
+		return
+	}
+
+	jsonStringResponse(w, http.StatusOK, "{}")
+	auditRec.Success()
+}
+
+// readMfaCodeRequest returns false, after sending the error response, if
+// the request body isn't a valid MfaCodeRequest.
+func (a *API) readMfaCodeRequest(w http.ResponseWriter, r *http.Request) (*MfaCodeRequest, bool) {
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return nil, false
+	}
+
+	var request MfaCodeRequest
+	if err = json.Unmarshal(requestBody, &request); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return nil, false
+	}
+	if request.Code == "" {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "code is required", nil)
+		return nil, false
+	}
+	return &request, true
+}
+
+func (a *API) mfaErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, app.ErrMfaNotConfigured):
+		a.errorResponse(w, r.URL.Path, http.StatusNotImplemented, err.Error(), err)
+	case errors.Is(err, app.ErrMfaAlreadyActive), errors.Is(err, app.ErrMfaNotActive), errors.Is(err, app.ErrInvalidMfaCode):
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, err.Error(), err)
+	default:
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+	}
+}
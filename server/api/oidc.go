@@ -0,0 +1,189 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/auth"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+const (
+	// oidcLoginCookie keeps the state, the nonce and the code verifier of
+	// a login at the identity provider until its callback
+	oidcLoginCookie     = "FOCALBOARDOIDCLOGIN"
+	oidcLoginCookiePath = "/api/v1/oauth"
+	oidcLoginMaxAge     = 10 * time.Minute
+)
+
+// The errors of the logins at the identity provider the clients are
+// redirected with.
+const (
+	oidcErrorProvider         = "provider_error"
+	oidcErrorInvalidState     = "invalid_state"
+	oidcErrorEmailNotVerified = "email_not_verified"
+	oidcErrorEmailLinked      = "email_linked"
+	oidcErrorUserDeactivated  = "user_deactivated"
+	oidcErrorLoginFailed      = "login_failed"
+)
+
+func (a *API) handleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/oauth/login oidcLogin
+	//
+	// Starts a single sign-on login, redirecting to the identity provider,
+	// which redirects back to the callback
+	//
+	// ---
+	// responses:
+	//   '302':
+	//     description: redirect to the identity provider
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '404':
+	//     description: single sign-on not configured
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   '500':
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	login, err := a.app.StartOIDCLogin(r.Context())
+	if errors.Is(err, app.ErrOIDCNotConfigured) {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, err.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcLoginCookie,
+		Value:    strings.Join([]string{login.State, login.Nonce, login.CodeVerifier}, "."),
+		Path:     oidcLoginCookiePath,
+		MaxAge:   int(oidcLoginMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, login.URL, http.StatusFound)
+}
+
+func (a *API) handleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/oauth/callback oidcCallback
+	//
+	// Completes a single sign-on login, with the code of the identity
+	// provider, redirecting to the client with the session cookie, or with
+	// the sso_error of the login
+	//
+	// ---
+	// parameters:
+	// - name: code
+	//   in: query
+	//   description: Code of the login
+	//   required: false
+	//   type: string
+	// - name: state
+	//   in: query
+	//   description: State of the login
+	//   required: true
+	//   type: string
+	// - name: error
+	//   in: query
+	//   description: Error of the identity provider
+	//   required: false
+	//   type: string
+	// responses:
+	//   '302':
+	//     description: redirect to the client
+	//   '401':
+	//     description: not permitted in single-user mode or with Mattermost authentication
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	if !a.nativeAuthAllowed(w, r) {
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "oidcLogin", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelAuth, auditRec)
+
+	// the login can only be completed once
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcLoginCookie,
+		Path:     oidcLoginCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := r.URL.Query()
+	if query.Get("error") != "" {
+		auditRec.AddMeta("error", query.Get("error"))
+		http.Redirect(w, r, a.app.GetOIDCLoginRedirectURL(oidcErrorProvider), http.StatusFound)
+		return
+	}
+
+	var parts []string
+	if cookie, err := r.Cookie(oidcLoginCookie); err == nil {
+		parts = strings.Split(cookie.Value, ".")
+	}
+	state := query.Get("state")
+	if len(parts) != 3 || state == "" || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(state)) != 1 {
+		http.Redirect(w, r, a.app.GetOIDCLoginRedirectURL(oidcErrorInvalidState), http.StatusFound)
+		return
+	}
+
+	token, err := a.app.LoginWithOIDC(r.Context(), query.Get("code"), parts[2], parts[1], clientAddress(r))
+	if err != nil {
+		a.logger.Warn("Single sign-on login failed", mlog.Err(err))
+		http.Redirect(w, r, a.app.GetOIDCLoginRedirectURL(oidcLoginError(err)), http.StatusFound)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.SessionCookieToken,
+		Value:    token,
+		Path:     "/",
+		Expires:  time.Unix(a.app.SessionExpireAt(time.Now().Unix()), 0),
+		HttpOnly: true,
+		Secure:   isSecureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, a.app.GetOIDCLoginRedirectURL(""), http.StatusFound)
+	auditRec.Success()
+}
+
+// oidcLoginError returns the error of a failed login the client is
+// redirected with.
+func oidcLoginError(err error) string {
+	switch {
+	case errors.Is(err, app.ErrOIDCEmailNotVerified):
+		return oidcErrorEmailNotVerified
+	case errors.Is(err, app.ErrOIDCEmailLinked):
+		return oidcErrorEmailLinked
+	case errors.Is(err, app.ErrOIDCUserDeactivated):
+		return oidcErrorUserDeactivated
+	}
+	return oidcErrorLoginFailed
+}
+
+// isSecureRequest tells if the request was sent over TLS, to the server
+// or to the proxy in front of it.
+func isSecureRequest(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
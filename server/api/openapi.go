@@ -0,0 +1,60 @@
+package api
+
+import (
+	_ "embed" // the spec and the docs UI are embedded
+	"net/http"
+)
+
+//go:generate go run ../swagger/openapigen -api . -models .,../model -o openapi.json
+
+// openAPISpec is the OpenAPI 3 spec of the API, generated from the
+// swagger:operation comments of the handlers.
+//go:embed openapi.json
+var openAPISpec []byte
+
+// openAPIDocs is the docs UI of the API, rendering the spec without
+// loading any external script.
+//go:embed openapi_docs.html
+var openAPIDocs []byte
+
+func (a *API) handleGetOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/openapi.json getOpenAPISpec
+	//
+	// Returns the OpenAPI 3 spec of the API
+	//
+	// ---
+	// produces:
+	// - application/json
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: object
+
+	jsonBytesResponse(w, http.StatusOK, openAPISpec)
+}
+
+func (a *API) handleGetAPIDocs(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/docs getAPIDocs
+	//
+	// Returns the docs UI of the API, if enabled by the enable_api_docs
+	// setting
+	//
+	// ---
+	// produces:
+	// - text/html
+	// responses:
+	//   '200':
+	//     description: success
+	//   '404':
+	//     description: the docs UI is disabled
+
+	if !a.app.IsAPIDocsEnabled() {
+		a.errorResponse(w, r.URL.Path, http.StatusNotFound, "", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openAPIDocs)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+var pathVariable = regexp.MustCompile(`{([^}:]+)(:[^}]*)?}`)
+
+type specOperation struct {
+	Parameters []struct {
+		Name string `json:"name"`
+		In   string `json:"in"`
+	} `json:"parameters"`
+}
+
+// TestOpenAPISpec fails if a registered route is missing from the spec,
+// or is documented with other path parameters. The spec is regenerated
+// by go generate ./api.
+func TestOpenAPISpec(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]specOperation `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(openAPISpec, &spec))
+
+	a := NewAPI(nil, "", "", nil, nil, nil, nil, nil, nil)
+	routers := []*mux.Router{mux.NewRouter(), mux.NewRouter()}
+	a.RegisterRoutes(routers[0])
+	a.RegisterAdminRoutes(routers[1])
+
+	missing := []string{}
+	for _, router := range routers {
+		err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				return nil
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				return nil
+			}
+
+			variables := []string{}
+			for _, match := range pathVariable.FindAllStringSubmatch(template, -1) {
+				variables = append(variables, match[1])
+			}
+			sort.Strings(variables)
+			path := pathVariable.ReplaceAllString(template, "{$1}")
+
+			for _, method := range methods {
+				op, ok := spec.Paths[path][strings.ToLower(method)]
+				if !ok {
+					missing = append(missing, method+" "+path)
+					continue
+				}
+
+				params := []string{}
+				for _, p := range op.Parameters {
+					if p.In == "path" {
+						params = append(params, p.Name)
+					}
+				}
+				sort.Strings(params)
+				require.Equal(t, variables, params, "path parameters of %s %s", method, path)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	require.Empty(t, missing, "routes missing from the spec, document them with swagger:operation comments and run go generate ./api")
+}
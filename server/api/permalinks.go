@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+func (a *API) handleResolveBlock(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/workspaces/{workspaceID}/resolve/{blockID} resolveBlock
+	//
+	// Returns the current board of a block, the default view of the board
+	// and the card of the block, for the links to the block that keep
+	// working when it moves to another board. The deleted blocks resolve
+	// to a tombstone, with the board they lived on. The location is only
+	// returned if the caller can view the board, the read tokens giving
+	// access to their shared board only
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: workspaceID
+	//   in: path
+	//   description: Workspace ID
+	//   required: true
+	//   type: string
+	// - name: blockID
+	//   in: path
+	//   description: ID of the block
+	//   required: true
+	//   type: string
+	// - name: read_token
+	//   in: query
+	//   description: Read token of a shared board, for the callers without a session
+	//   required: false
+	//   type: string
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       "$ref": "#/definitions/ResolvedBlock"
+	//   '404':
+	//     description: the workspace never had the block
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session, _ := ctx.Value(sessionContextKey).(*model.Session)
+	blockID := mux.Vars(r)["blockID"]
+	readToken := r.URL.Query().Get("read_token")
+
+	var container *store.Container
+	var err error
+	if session == nil && readToken != "" {
+		container, err = a.getContainerForReadToken(r)
+	} else {
+		container, err = a.getContainer(r)
+	}
+	if err != nil {
+		a.noContainerErrorResponse(w, r.URL.Path, err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, "resolveBlock", audit.Fail)
+	defer a.audit.LogRecord(audit.LevelRead, auditRec)
+	auditRec.AddMeta("blockID", blockID)
+
+	userID := ""
+	if session != nil {
+		userID = session.UserID
+	}
+	resolved, err := a.app.ResolveBlock(ctx, *container, blockID, userID, readToken)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	data, err := json.Marshal(resolved)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+	auditRec.AddMeta("canAccess", resolved.CanAccess)
+	auditRec.Success()
+}
+
+// getContainerForReadToken returns the container of the request of a
+// viewer without a session, whose read token is checked against the
+// board of each block by the handler.
+func (a *API) getContainerForReadToken(r *http.Request) (*store.Container, error) {
+	workspaceID := mux.Vars(r)["workspaceID"]
+	if !a.MattermostAuth {
+		// Native auth: always use root workspace
+		if workspaceID != "" && workspaceID != "0" {
+			return nil, errWorkspaceMismatch
+		}
+		workspaceID = "0"
+	}
+	return &store.Container{WorkspaceID: workspaceID}, nil
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/audit"
+)
+
+func (a *API) handleGetPreferences(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation GET /api/v1/users/me/preferences getPreferences
+	//
+	// Returns the preferences of the currently logged-in user
+	//
+	// ---
+	// produces:
+	// - application/json
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Preference"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	preferences, err := a.app.GetUserPreferences(ctx, session.UserID)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.preferencesResponse(w, r, preferences)
+}
+
+func (a *API) handleUpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation PUT /api/v1/users/me/preferences updatePreferences
+	//
+	// Sets a batch of preferences of the currently logged-in user, and
+	// returns all their preferences. The categories of the preferences
+	// set by the clients start with client_
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: the preferences, at most 100
+	//   required: true
+	//   schema:
+	//     type: array
+	//     items:
+	//       "$ref": "#/definitions/Preference"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Preference"
+	//   '400':
+	//     description: invalid preferences
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	a.changePreferences(w, r, "updatePreferences", a.app.UpdateUserPreferences)
+}
+
+func (a *API) handleDeletePreferences(w http.ResponseWriter, r *http.Request) {
+	// swagger:operation POST /api/v1/users/me/preferences/delete deletePreferences
+	//
+	// Removes a batch of preferences of the currently logged-in user, by
+	// their category and name, and returns their remaining preferences
+	//
+	// ---
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: Body
+	//   in: body
+	//   description: the preferences, at most 100
+	//   required: true
+	//   schema:
+	//     type: array
+	//     items:
+	//       "$ref": "#/definitions/Preference"
+	// security:
+	// - BearerAuth: []
+	// responses:
+	//   '200':
+	//     description: success
+	//     schema:
+	//       type: array
+	//       items:
+	//         "$ref": "#/definitions/Preference"
+	//   '400':
+	//     description: invalid preferences
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+	//   default:
+	//     description: internal error
+	//     schema:
+	//       "$ref": "#/definitions/ErrorResponse"
+
+	a.changePreferences(w, r, "deletePreferences", a.app.DeleteUserPreferences)
+}
+
+// changePreferences applies the change to the preferences of the request
+// body for the currently logged-in user, and responds with all their
+// preferences.
+func (a *API) changePreferences(w http.ResponseWriter, r *http.Request, action string,
+	change func(ctx context.Context, userID string, preferences []model.Preference) ([]model.Preference, error)) {
+	ctx := r.Context()
+	session := ctx.Value(sessionContextKey).(*model.Session)
+
+	requestBody, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	var preferences []model.Preference
+	if err = json.Unmarshal(requestBody, &preferences); err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, "invalid request", err)
+		return
+	}
+
+	auditRec := a.makeAuditRecord(r, action, audit.Fail)
+	defer a.audit.LogRecord(audit.LevelModify, auditRec)
+	auditRec.AddMeta("count", len(preferences))
+
+	updated, err := change(ctx, session.UserID, preferences)
+	var invalidErr app.InvalidPreferencesError
+	if errors.As(err, &invalidErr) {
+		a.errorResponse(w, r.URL.Path, http.StatusBadRequest, invalidErr.Error(), err)
+		return
+	}
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	a.preferencesResponse(w, r, updated)
+	auditRec.Success()
+}
+
+func (a *API) preferencesResponse(w http.ResponseWriter, r *http.Request, preferences []model.Preference) {
+	data, err := json.Marshal(preferences)
+	if err != nil {
+		a.errorResponse(w, r.URL.Path, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	jsonBytesResponse(w, http.StatusOK, data)
+}
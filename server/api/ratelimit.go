@@ -0,0 +1,133 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/ratelimit"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// rateLimitSessionTTL is how long the tokens of the valid sessions are
+// remembered by the rate limiter.
+const rateLimitSessionTTL = time.Minute
+
+// RateLimiter limits the API requests per session or personal access
+// token, or per client address for the requests without a valid token.
+// The single user token and the sessions of the admins get the admin
+// limits.
+type RateLimiter struct {
+	limiter      *ratelimit.Limiter
+	adminLimiter *ratelimit.Limiter
+
+	// sessions are the hashes of the tokens known to be valid, with
+	// whether their user is an admin, whose requests are limited per
+	// token without resolving their session
+	sessions *ratelimit.Cache
+}
+
+// NewRateLimiter returns the rate limiter for the configuration, or nil
+// if the requests aren't limited.
+func NewRateLimiter(cfg *config.Configuration) *RateLimiter {
+	if cfg.RateLimitPerSecond <= 0 {
+		return nil
+	}
+
+	limiter := ratelimit.New(cfg.RateLimitPerSecond, cfg.RateLimitBurst, ratelimit.DefaultMaxBuckets)
+	adminLimiter := limiter
+	if cfg.AdminRateLimitPerSecond > 0 {
+		adminLimiter = ratelimit.New(cfg.AdminRateLimitPerSecond, cfg.AdminRateLimitBurst, ratelimit.DefaultMaxBuckets)
+	}
+
+	return &RateLimiter{
+		limiter:      limiter,
+		adminLimiter: adminLimiter,
+		sessions:     ratelimit.NewCache(rateLimitSessionTTL, ratelimit.DefaultMaxBuckets),
+	}
+}
+
+func (a *API) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.rateLimiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limiter := a.rateLimiter.limiter
+		key := "ip:" + clientAddress(r)
+		token, _ := auth.ParseAuthTokenFromRequest(r)
+		tokenHash := ""
+		if token != "" {
+			sum := sha256.Sum256([]byte(token))
+			tokenHash = hex.EncodeToString(sum[:])
+		}
+		resolve := false
+		switch {
+		case token != "" && token == a.singleUserToken:
+			limiter = a.rateLimiter.adminLimiter
+			key = "token:" + tokenHash
+		case a.MattermostAuth && r.Header.Get("Mattermost-User-Id") != "":
+			key = "user:" + r.Header.Get("Mattermost-User-Id")
+		case token != "":
+			// the unknown tokens share the bucket of their address, so
+			// that a new fake token neither gets a full bucket nor reaches
+			// the database before being limited
+			if admin, ok := a.rateLimiter.sessions.Get(tokenHash); ok {
+				key = "token:" + tokenHash
+				if admin.(bool) {
+					limiter = a.rateLimiter.adminLimiter
+				}
+			} else {
+				resolve = true
+			}
+		}
+
+		allowed, retryAfter := limiter.Allow(key)
+		if !allowed {
+			w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+			a.errorResponseWithCode(w, r.URL.Path, http.StatusTooManyRequests, ErrorTooManyRequestsCode, "too many requests", nil)
+			return
+		}
+
+		// the session of an admitted token is resolved once, the next
+		// requests being limited per token
+		if resolve {
+			if session, err := a.app.GetSession(token); err == nil {
+				admin, err := a.app.IsUserAdmin(session.UserID)
+				if err != nil {
+					a.logger.Warn("Unable to check if the user is an admin", mlog.String("userID", session.UserID), mlog.Err(err))
+				}
+				a.rateLimiter.sessions.Add(tokenHash, admin)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// retryAfterSeconds returns the Retry-After header value for the duration,
+// in whole seconds rounded up.
+func retryAfterSeconds(retryAfter time.Duration) string {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
+
+// clientAddress returns the address of the client without its port.
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
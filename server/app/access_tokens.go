@@ -0,0 +1,61 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/pkg/errors"
+)
+
+// accessTokenSecretLength is the number of random bytes of a personal
+// access token.
+const accessTokenSecretLength = 32
+
+// CreateAccessToken creates a personal access token for the user. The
+// returned token is the only copy of it, as only its hash is stored.
+func (a *App) CreateAccessToken(userID, description string, expireAt int64) (*model.AccessToken, error) {
+	secret := make([]byte, accessTokenSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "unable to generate the access token")
+	}
+	token := model.AccessTokenPrefix + hex.EncodeToString(secret)
+
+	accessToken := model.AccessToken{
+		ID:          utils.CreateGUID(),
+		UserID:      userID,
+		Description: description,
+		TokenHash:   model.HashToken(token),
+		CreateAt:    utils.GetMillis(),
+		ExpireAt:    expireAt,
+	}
+
+	if err := a.store.CreateAccessToken(accessToken); err != nil {
+		return nil, err
+	}
+
+	a.recordAuditEntry(model.AuditActionCreateAccessToken, userID, "", accessToken.ID, map[string]interface{}{
+		"description": description,
+		"expireAt":    expireAt,
+	})
+
+	accessToken.Token = token
+	return &accessToken, nil
+}
+
+// GetAccessTokens returns the personal access tokens of the user, without
+// the tokens themselves.
+func (a *App) GetAccessTokens(userID string) ([]model.AccessToken, error) {
+	return a.store.GetAccessTokensForUser(userID)
+}
+
+// RevokeAccessToken deletes a personal access token of the user.
+func (a *App) RevokeAccessToken(userID, tokenID string) error {
+	if err := a.store.DeleteAccessToken(userID, tokenID); err != nil {
+		return err
+	}
+
+	a.recordAuditEntry(model.AuditActionRevokeAccessToken, userID, "", tokenID, nil)
+	return nil
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// GetCardActivity returns a page of the activity of the card and of its
+// children, the most recent first, or nil if the card doesn't exist.
+// The versions that didn't change the title or the fields of a block
+// are left out.
+func (a *App) GetCardActivity(ctx context.Context, c store.Container, cardID string, limit int, before int64) (*model.CardActivityPage, error) {
+	card, err := a.store.GetBlock(ctx, c, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil || card.Type != "card" {
+		return nil, nil
+	}
+
+	entries, hasMore, err := a.store.GetCardActivity(ctx, c, cardID, limit, before)
+	if err != nil {
+		return nil, err
+	}
+
+	propertyNames := map[string]string{}
+	board, err := a.store.GetBlock(ctx, c, card.RootID)
+	if err != nil {
+		return nil, err
+	}
+	if board != nil {
+		for _, property := range csvProperties(*board, nil) {
+			propertyNames[property.id] = property.name
+		}
+	}
+
+	page := &model.CardActivityPage{
+		Activity: []model.CardActivity{},
+		HasMore:  hasMore,
+		Before:   before,
+	}
+	for _, entry := range entries {
+		page.Before = entry.Block.UpdateAt
+
+		activity := model.CardActivity{
+			BlockID:    entry.Block.ID,
+			BlockType:  entry.Block.Type,
+			ModifiedBy: entry.Block.ModifiedBy,
+			UpdateAt:   entry.Block.UpdateAt,
+			Changes:    []model.BlockChange{},
+		}
+
+		switch {
+		case entry.Block.DeleteAt != 0:
+			activity.Action = model.CardActivityDeleted
+		case entry.Previous == nil || entry.Previous.DeleteAt != 0:
+			activity.Action = model.CardActivityCreated
+			activity.Changes = DiffBlocks(model.Block{}, entry.Block)
+		default:
+			activity.Action = model.CardActivityUpdated
+			activity.Changes = DiffBlocks(*entry.Previous, entry.Block)
+			if len(activity.Changes) == 0 {
+				continue
+			}
+		}
+
+		for i := range activity.Changes {
+			if activity.Changes[i].PropertyID != "" {
+				activity.Changes[i].PropertyName = propertyNames[activity.Changes[i].PropertyID]
+			}
+		}
+		page.Activity = append(page.Activity, activity)
+	}
+
+	return page, nil
+}
+
+// DiffBlocks returns the changes of the title and of the fields from one
+// version of a block to the next. The card properties are compared one
+// by one, so that each changed property is a change of its own. The
+// changes are sorted by field, then by property.
+func DiffBlocks(previous, block model.Block) []model.BlockChange {
+	changes := []model.BlockChange{}
+	if previous.Title != block.Title {
+		changes = append(changes, model.BlockChange{
+			Field:    "title",
+			OldValue: valueOrNil(previous.Title),
+			NewValue: valueOrNil(block.Title),
+		})
+	}
+
+	for _, key := range changedKeys(previous.Fields, block.Fields) {
+		if key != "properties" {
+			changes = append(changes, model.BlockChange{
+				Field:    key,
+				OldValue: previous.Fields[key],
+				NewValue: block.Fields[key],
+			})
+			continue
+		}
+
+		// the properties are replaced as a whole when one changes, so
+		// anything that isn't a map compares as no property at all
+		oldProperties, _ := previous.Fields[key].(map[string]interface{})
+		newProperties, _ := block.Fields[key].(map[string]interface{})
+		for _, propertyID := range changedKeys(oldProperties, newProperties) {
+			changes = append(changes, model.BlockChange{
+				Field:      key,
+				PropertyID: propertyID,
+				OldValue:   oldProperties[propertyID],
+				NewValue:   newProperties[propertyID],
+			})
+		}
+	}
+
+	return changes
+}
+
+// changedKeys returns the sorted keys whose values differ between the
+// maps, a missing key being the same as a null value.
+func changedKeys(previous, current map[string]interface{}) []string {
+	keys := []string{}
+	for key, value := range current {
+		if !reflect.DeepEqual(previous[key], value) {
+			keys = append(keys, key)
+		}
+	}
+	for key, value := range previous {
+		if _, ok := current[key]; !ok && value != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func valueOrNil(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffBlocks(t *testing.T) {
+	testCases := []struct {
+		name     string
+		previous model.Block
+		block    model.Block
+		expected []model.BlockChange
+	}{
+		{
+			name:     "no changes",
+			previous: model.Block{Title: "Card", Fields: map[string]interface{}{"icon": "x"}},
+			block:    model.Block{Title: "Card", Fields: map[string]interface{}{"icon": "x"}},
+			expected: []model.BlockChange{},
+		},
+		{
+			name:     "title changed",
+			previous: model.Block{Title: "Card"},
+			block:    model.Block{Title: "Renamed"},
+			expected: []model.BlockChange{{Field: "title", OldValue: "Card", NewValue: "Renamed"}},
+		},
+		{
+			name:     "title set",
+			previous: model.Block{},
+			block:    model.Block{Title: "Comment"},
+			expected: []model.BlockChange{{Field: "title", OldValue: nil, NewValue: "Comment"}},
+		},
+		{
+			name:     "field set and removed",
+			previous: model.Block{Fields: map[string]interface{}{"icon": "x"}},
+			block:    model.Block{Fields: map[string]interface{}{"isTemplate": true}},
+			expected: []model.BlockChange{
+				{Field: "icon", OldValue: "x", NewValue: nil},
+				{Field: "isTemplate", OldValue: nil, NewValue: true},
+			},
+		},
+		{
+			name:     "null field is the same as no field",
+			previous: model.Block{Fields: map[string]interface{}{"icon": nil}},
+			block:    model.Block{Fields: map[string]interface{}{}},
+			expected: []model.BlockChange{},
+		},
+		{
+			name:     "nested field changed",
+			previous: model.Block{Fields: map[string]interface{}{"contentOrder": []interface{}{"a"}}},
+			block:    model.Block{Fields: map[string]interface{}{"contentOrder": []interface{}{"a", "b"}}},
+			expected: []model.BlockChange{
+				{Field: "contentOrder", OldValue: []interface{}{"a"}, NewValue: []interface{}{"a", "b"}},
+			},
+		},
+		{
+			name: "properties changed one by one",
+			previous: model.Block{Fields: map[string]interface{}{"properties": map[string]interface{}{
+				"status": "todo",
+				"owner":  "user-1",
+				"notes":  "Notes",
+			}}},
+			block: model.Block{Fields: map[string]interface{}{"properties": map[string]interface{}{
+				"status":   "done",
+				"owner":    "user-1",
+				"estimate": "3",
+			}}},
+			expected: []model.BlockChange{
+				{Field: "properties", PropertyID: "estimate", OldValue: nil, NewValue: "3"},
+				{Field: "properties", PropertyID: "notes", OldValue: "Notes", NewValue: nil},
+				{Field: "properties", PropertyID: "status", OldValue: "todo", NewValue: "done"},
+			},
+		},
+		{
+			name:     "properties set on a new card",
+			previous: model.Block{},
+			block:    model.Block{Fields: map[string]interface{}{"properties": map[string]interface{}{"status": "todo"}}},
+			expected: []model.BlockChange{
+				{Field: "properties", PropertyID: "status", OldValue: nil, NewValue: "todo"},
+			},
+		},
+		{
+			name:     "invalid properties",
+			previous: model.Block{Fields: map[string]interface{}{"properties": "invalid"}},
+			block:    model.Block{Fields: map[string]interface{}{"properties": map[string]interface{}{"status": "todo"}}},
+			expected: []model.BlockChange{
+				{Field: "properties", PropertyID: "status", OldValue: nil, NewValue: "todo"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, DiffBlocks(tc.previous, tc.block))
+		})
+	}
+}
+
+func TestGetCardActivity(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := &model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "status", "name": "Status", "type": "select"},
+			},
+		},
+	}
+	card := &model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "Card"}
+	version := func(id, blockType, title string, updateAt, deleteAt int64, properties map[string]interface{}) model.Block {
+		return model.Block{
+			ID:         id,
+			Type:       blockType,
+			Title:      title,
+			ModifiedBy: "user-1",
+			UpdateAt:   updateAt,
+			DeleteAt:   deleteAt,
+			Fields:     map[string]interface{}{"properties": properties},
+		}
+	}
+	previous := func(block model.Block) *model.Block {
+		return &block
+	}
+
+	t.Run("should compute the changes of the versions", func(t *testing.T) {
+		entries := []model.BlockHistoryEntry{
+			{
+				Block:    version("comment-1", "comment", "Comment", 5, 5, nil),
+				Previous: previous(version("comment-1", "comment", "Comment", 2, 0, nil)),
+			},
+			{
+				Block:    version("card-1", "card", "Card", 4, 0, map[string]interface{}{"status": "done"}),
+				Previous: previous(version("card-1", "card", "Card", 3, 0, map[string]interface{}{"status": "todo"})),
+			},
+			{
+				Block:    version("card-1", "card", "Card", 3, 0, map[string]interface{}{"status": "todo"}),
+				Previous: previous(version("card-1", "card", "Card", 1, 0, map[string]interface{}{"status": "todo"})),
+			},
+			{
+				Block: version("comment-1", "comment", "Comment", 2, 0, nil),
+			},
+		}
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(card, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().GetCardActivity(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1"), gomock.Eq(4), gomock.Eq(int64(0))).
+			Return(entries, true, nil)
+
+		page, err := th.App.GetCardActivity(ctx, container, "card-1", 4, 0)
+		require.NoError(t, err)
+		require.True(t, page.HasMore)
+		require.EqualValues(t, 2, page.Before)
+		require.Len(t, page.Activity, 3, "the versions without changes are left out")
+
+		require.Equal(t, model.CardActivityDeleted, page.Activity[0].Action)
+		require.Empty(t, page.Activity[0].Changes)
+
+		require.Equal(t, model.CardActivityUpdated, page.Activity[1].Action)
+		require.Equal(t, "user-1", page.Activity[1].ModifiedBy)
+		require.Equal(t, []model.BlockChange{
+			{Field: "properties", PropertyID: "status", PropertyName: "Status", OldValue: "todo", NewValue: "done"},
+		}, page.Activity[1].Changes)
+
+		require.Equal(t, model.CardActivityCreated, page.Activity[2].Action)
+		require.Equal(t, "comment", page.Activity[2].BlockType)
+		require.Equal(t, []model.BlockChange{{Field: "title", NewValue: "Comment"}}, page.Activity[2].Changes)
+	})
+
+	t.Run("should return nil if the card doesn't exist", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(nil, nil)
+
+		page, err := th.App.GetCardActivity(ctx, container, "card-1", 4, 0)
+		require.NoError(t, err)
+		require.Nil(t, page)
+	})
+}
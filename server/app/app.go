@@ -1,9 +1,18 @@
 package app
 
 import (
+	"database/sql"
+	"errors"
+
 	"github.com/mattermost/focalboard/server/auth"
 	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/email"
+	"github.com/mattermost/focalboard/server/services/github"
+	"github.com/mattermost/focalboard/server/services/ldap"
 	"github.com/mattermost/focalboard/server/services/metrics"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/services/oidc"
+	"github.com/mattermost/focalboard/server/services/ratelimit"
 	"github.com/mattermost/focalboard/server/services/store"
 	"github.com/mattermost/focalboard/server/services/webhook"
 	"github.com/mattermost/focalboard/server/ws"
@@ -14,34 +23,80 @@ import (
 )
 
 type Services struct {
-	Auth         *auth.Auth
-	Store        store.Store
-	FilesBackend filestore.FileBackend
-	Webhook      *webhook.Client
-	Metrics      *metrics.Metrics
-	Logger       *mlog.Logger
+	Auth              *auth.Auth
+	Store             store.Store
+	FilesBackend      filestore.FileBackend
+	Webhook           *webhook.Client
+	WebhookDispatcher *webhook.Dispatcher
+	Notifier          notify.Notifier
+	EmailSender       email.Sender
+	Metrics           *metrics.Metrics
+	Logger            *mlog.Logger
 }
 
 type App struct {
-	config       *config.Configuration
-	store        store.Store
-	auth         *auth.Auth
-	wsAdapter    ws.Adapter
-	filesBackend filestore.FileBackend
-	webhook      *webhook.Client
-	metrics      *metrics.Metrics
-	logger       *mlog.Logger
+	config            *config.Configuration
+	store             store.Store
+	auth              *auth.Auth
+	wsAdapter         ws.Adapter
+	filesBackend      filestore.FileBackend
+	webhook           *webhook.Client
+	webhookDispatcher *webhook.Dispatcher
+	github            *github.Client
+	oidc              *oidc.Client
+	ldap              *ldap.Client
+	notifier          notify.Notifier
+	emailSender       email.Sender
+	metrics           *metrics.Metrics
+	logger            *mlog.Logger
+	userLoginLockout  *ratelimit.Lockout
+	ipLoginLockout    *ratelimit.Lockout
+	workspaceUsage    *workspaceUsageCache
+	blockCache        *blockCache
+
+	cardOrderRebalance *cardOrderRebalanceQueue
+	thumbnails         *thumbnailQueue
+
+	// draining is set to 1 when the server starts shutting down
+	draining int32
 }
 
 func New(config *config.Configuration, wsAdapter ws.Adapter, services Services) *App {
+	var cache *blockCache
+	if config.EnableBlockCache {
+		cache = newBlockCache(config.BlockCacheMaxBytes, services.Metrics)
+		wsAdapter = newBlockCacheAdapter(wsAdapter, cache)
+	}
+
 	return &App{
-		config:       config,
-		store:        services.Store,
-		auth:         services.Auth,
-		wsAdapter:    wsAdapter,
-		filesBackend: services.FilesBackend,
-		webhook:      services.Webhook,
-		metrics:      services.Metrics,
-		logger:       services.Logger,
+		config:            config,
+		store:             services.Store,
+		auth:              services.Auth,
+		wsAdapter:         wsAdapter,
+		filesBackend:      services.FilesBackend,
+		webhook:           services.Webhook,
+		webhookDispatcher: services.WebhookDispatcher,
+		github:            github.NewClient(config.GitHubAPIURL),
+		oidc:              newOIDCClient(config),
+		ldap:              newLDAPClient(config),
+		notifier:          services.Notifier,
+		emailSender:       services.EmailSender,
+		metrics:           services.Metrics,
+		logger:            services.Logger,
+		userLoginLockout:  newLoginLockout(config, config.LoginLockoutThreshold, services.Store),
+		ipLoginLockout:    newLoginLockout(config, config.LoginLockoutIPThreshold, services.Store),
+		workspaceUsage:    newWorkspaceUsageCache(),
+		blockCache:        cache,
+
+		cardOrderRebalance: newCardOrderRebalanceQueue(),
+		thumbnails:         newThumbnailQueue(),
+	}
+}
+
+// rollbackTx rolls back the transaction, unless it was committed. It's
+// deferred right after the transaction is started.
+func (a *App) rollbackTx(tx store.Tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		a.logger.Warn("Transaction rollback error", mlog.Err(err))
 	}
 }
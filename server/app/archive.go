@@ -0,0 +1,276 @@
+package app
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+const (
+	archiveBlocksFilename = "blocks.jsonl"
+	archiveFilesDir       = "files"
+)
+
+// ErrInvalidArchive is returned when importing an archive that isn't a
+// workspace archive or whose blocks are inconsistent.
+var ErrInvalidArchive = errors.New("invalid workspace archive")
+
+// archiveFile is a file referenced by a block of an archive.
+type archiveFile struct {
+	rootID string
+	fileID string
+}
+
+// ExportWorkspaceArchive writes a zip archive of the workspace to w,
+// with a blocks.jsonl entry with a line per block followed by the files
+// referenced by the blocks in the files directory. The blocks are
+// written as they are read from the store, so the archive is never held
+// in memory. Only the boards that the user can view are exported.
+func (a *App) ExportWorkspaceArchive(ctx context.Context, c store.Container, userID string, w io.Writer) error {
+	roles, err := a.getUserBoardRoles(c, userID)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+
+	blocksWriter, err := archive.Create(archiveBlocksFilename)
+	if err != nil {
+		return err
+	}
+
+	files := []archiveFile{}
+	seen := map[string]bool{}
+	encoder := json.NewEncoder(blocksWriter)
+	err = a.store.StreamAllBlocks(ctx, c, func(block model.Block) error {
+		if roles.check(model.BoardRoleViewer, block.BoardID()) != nil {
+			return nil
+		}
+		if fileID := blockFileID(block); fileID != "" && !seen[fileID] {
+			seen[fileID] = true
+			if isArchiveFileID(fileID) {
+				files = append(files, archiveFile{rootID: block.RootID, fileID: fileID})
+			} else {
+				// the file would be read from, and extracted to, outside
+				// of the directory of its board
+				a.logger.Warn("ExportWorkspaceArchive: invalid file ID",
+					mlog.String("blockID", block.ID),
+					mlog.String("fileID", fileID),
+				)
+			}
+		}
+		return encoder.Encode(block)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := a.exportArchiveFile(archive, c.WorkspaceID, file); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func (a *App) exportArchiveFile(archive *zip.Writer, workspaceID string, file archiveFile) error {
+	reader, err := a.GetFileReader(workspaceID, file.rootID, file.fileID)
+	if err != nil {
+		// a missing file doesn't prevent the export of the rest
+		a.logger.Warn("ExportWorkspaceArchive: file not found",
+			mlog.String("rootID", file.rootID),
+			mlog.String("fileID", file.fileID),
+			mlog.Err(err),
+		)
+		return nil
+	}
+	defer reader.Close()
+
+	fileWriter, err := archive.Create(path.Join(archiveFilesDir, file.fileID))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fileWriter, reader)
+	return err
+}
+
+// ImportWorkspaceArchive imports the blocks and files of an archive
+// written by ExportWorkspaceArchive into the workspace. The blocks get
+// new IDs, and the files new names that the blocks are updated to
+// reference. Every block must belong to a root block of the archive,
+// otherwise nothing is imported. The blocks are inserted in a single
+// transaction, rolled back if the context is canceled.
+func (a *App) ImportWorkspaceArchive(ctx context.Context, c store.Container, archive *zip.Reader, userID string) (*model.ImportSummary, error) {
+	blocks, err := readArchiveBlocks(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool, len(blocks))
+	for _, block := range blocks {
+		ids[block.ID] = true
+	}
+
+	rootIDs := []string{}
+	byRootID := map[string][]model.Block{}
+	for _, block := range blocks {
+		if !ids[block.RootID] {
+			return nil, fmt.Errorf("%w: the root %s of block %s isn't in the archive", ErrInvalidArchive, block.RootID, block.ID)
+		}
+		if _, ok := byRootID[block.RootID]; !ok {
+			rootIDs = append(rootIDs, block.RootID)
+		}
+		byRootID[block.RootID] = append(byRootID[block.RootID], block)
+	}
+
+	archiveFiles := map[string]*zip.File{}
+	for _, file := range archive.File {
+		if strings.HasPrefix(file.Name, archiveFilesDir+"/") {
+			archiveFiles[strings.TrimPrefix(file.Name, archiveFilesDir+"/")] = file
+		}
+	}
+
+	summary := &model.ImportSummary{
+		Skipped: []model.ImportSkippedItem{},
+	}
+	newBlocks := make([]model.Block, 0, len(blocks))
+	writtenFiles := []string{}
+	for _, rootID := range rootIDs {
+		// the import is aborted between the boards when the request is,
+		// on shutdown, nothing being inserted yet
+		if err := ctx.Err(); err != nil {
+			a.removeFiles(writtenFiles)
+			return nil, err
+		}
+
+		rootBlocks, _ := duplicateBlockTree(byRootID[rootID], rootID)
+
+		for i := range rootBlocks {
+			block := &rootBlocks[i]
+			switch block.Type {
+			case "board":
+				summary.BoardsCreated++
+			case "card":
+				summary.CardsCreated++
+			case "comment":
+				summary.CommentsCreated++
+			}
+
+			fileID := blockFileID(*block)
+			if fileID == "" {
+				continue
+			}
+			file, ok := archiveFiles[fileID]
+			if !ok {
+				summary.Skip("file", fileID, "", "the file isn't in the archive")
+				continue
+			}
+
+			newFileID, err := a.importArchiveFile(file, c.WorkspaceID, block.RootID)
+			if err != nil {
+				a.removeFiles(writtenFiles)
+				return nil, err
+			}
+			writtenFiles = append(writtenFiles, filepath.Join(c.WorkspaceID, block.RootID, newFileID))
+			block.Fields["fileId"] = newFileID
+		}
+
+		newBlocks = append(newBlocks, rootBlocks...)
+	}
+
+	if _, err := a.InsertBlocks(ctx, c, newBlocks, userID); err != nil {
+		a.removeFiles(writtenFiles)
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+func (a *App) importArchiveFile(file *zip.File, workspaceID, rootID string) (string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	defer reader.Close()
+
+	newFileID := utils.CreateGUID() + strings.ToLower(filepath.Ext(file.Name))
+	if _, err := a.filesBackend.WriteFile(reader, filepath.Join(workspaceID, rootID, newFileID)); err != nil {
+		return "", fmt.Errorf("unable to store the file in the files storage: %w", err)
+	}
+
+	return newFileID, nil
+}
+
+// removeFiles removes the files written by an import that failed.
+func (a *App) removeFiles(filePaths []string) {
+	for _, filePath := range filePaths {
+		if err := a.filesBackend.RemoveFile(filePath); err != nil {
+			a.logger.Error("unable to remove an imported file", mlog.String("path", filePath), mlog.Err(err))
+		}
+	}
+}
+
+func readArchiveBlocks(archive *zip.Reader) ([]model.Block, error) {
+	var blocksFile *zip.File
+	for _, file := range archive.File {
+		if file.Name == archiveBlocksFilename {
+			blocksFile = file
+			break
+		}
+	}
+	if blocksFile == nil {
+		return nil, fmt.Errorf("%w: %s not found", ErrInvalidArchive, archiveBlocksFilename)
+	}
+
+	reader, err := blocksFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+	}
+	defer reader.Close()
+
+	blocks := []model.Block{}
+	decoder := json.NewDecoder(reader)
+	for {
+		var block model.Block
+		err := decoder.Decode(&block)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err)
+		}
+		if block.ID == "" || block.RootID == "" {
+			return nil, fmt.Errorf("%w: block without an id or a rootId", ErrInvalidArchive)
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// blockFileID returns the name of the file referenced by the block, if
+// any.
+func blockFileID(block model.Block) string {
+	fileID, _ := block.Fields["fileId"].(string)
+	return fileID
+}
+
+// isArchiveFileID returns whether the file ID is a single element of a
+// path, the name of a file in the directory of its board.
+func isArchiveFileID(fileID string) bool {
+	return filepath.Base(fileID) == fileID && fileID != "." && fileID != ".." && !strings.ContainsAny(fileID, `/\`)
+}
@@ -0,0 +1,234 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/filestore"
+	"github.com/mattermost/mattermost-server/v6/shared/filestore/mocks"
+)
+
+type testFileReader struct {
+	*bytes.Reader
+}
+
+func (r testFileReader) Close() error { return nil }
+
+func TestWorkspaceArchive(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectOpenBoards()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	blocks := []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board", Title: "Board"},
+		{
+			ID:       "card-1",
+			ParentID: "board-1",
+			RootID:   "board-1",
+			Type:     "card",
+			Fields:   map[string]interface{}{"contentOrder": []interface{}{"image-1"}},
+		},
+		{
+			ID:       "image-1",
+			ParentID: "card-1",
+			RootID:   "board-1",
+			Type:     "image",
+			Fields:   map[string]interface{}{"fileId": "file-1.png"},
+		},
+		{
+			ID:       "image-2",
+			ParentID: "card-1",
+			RootID:   "board-1",
+			Type:     "image",
+			Fields:   map[string]interface{}{"fileId": "missing.png"},
+		},
+		{ID: "comment-1", ParentID: "card-1", RootID: "board-1", Type: "comment", Title: "Hello"},
+	}
+
+	export := func(t *testing.T) []byte {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		th.Store.EXPECT().StreamAllBlocks(gomock.Any(), gomock.Eq(container), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ st.Container, fn func(model.Block) error) error {
+				for _, block := range blocks {
+					if err := fn(block); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		filePath := filepath.Join("0", "board-1", "file-1.png")
+		mockedFileBackend.On("FileExists", filePath).Return(true, nil)
+		mockedFileBackend.On("Reader", filePath).Return(func(string) filestore.ReadCloseSeeker {
+			return testFileReader{bytes.NewReader([]byte("image data"))}
+		}, nil)
+		missingPath := filepath.Join("0", "board-1", "missing.png")
+		mockedFileBackend.On("FileExists", missingPath).Return(false, nil)
+		mockedFileBackend.On("FileExists", "missing.png").Return(false, nil)
+		mockedFileBackend.On("Reader", missingPath).Return(nil, &TestError{})
+
+		var buf bytes.Buffer
+		require.NoError(t, th.App.ExportWorkspaceArchive(ctx, container, "user-id", &buf))
+		return buf.Bytes()
+	}
+
+	t.Run("should export the blocks and their files", func(t *testing.T) {
+		data := export(t)
+
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		require.Len(t, archive.File, 2)
+		require.Equal(t, "blocks.jsonl", archive.File[0].Name)
+		require.Equal(t, "files/file-1.png", archive.File[1].Name)
+
+		reader, err := archive.File[0].Open()
+		require.NoError(t, err)
+		lines, err := ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		require.Len(t, strings.Split(strings.TrimSpace(string(lines)), "\n"), len(blocks))
+	})
+
+	t.Run("should not export the files outside of the directory of their board", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		traversal := model.Block{
+			ID:       "image-3",
+			ParentID: "card-1",
+			RootID:   "board-1",
+			Type:     "image",
+			Fields:   map[string]interface{}{"fileId": "../../other-workspace/board-2/file-2.png"},
+		}
+		th.Store.EXPECT().StreamAllBlocks(gomock.Any(), gomock.Eq(container), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ st.Container, fn func(model.Block) error) error {
+				return fn(traversal)
+			})
+
+		var buf bytes.Buffer
+		require.NoError(t, th.App.ExportWorkspaceArchive(ctx, container, "user-id", &buf))
+
+		// the block is exported without its file, which is never read
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		require.Len(t, archive.File, 1)
+		require.Equal(t, "blocks.jsonl", archive.File[0].Name)
+		mockedFileBackend.AssertNotCalled(t, "FileExists", mock.Anything)
+		mockedFileBackend.AssertNotCalled(t, "Reader", mock.Anything)
+	})
+
+	t.Run("should import the blocks with new IDs and file names", func(t *testing.T) {
+		data := export(t)
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		var writtenPath, written string
+		mockedFileBackend.On("WriteFile", mock.Anything, mock.Anything).Return(func(reader io.Reader, path string) int64 {
+			content, _ := ioutil.ReadAll(reader)
+			writtenPath, written = path, string(content)
+			return int64(len(content))
+		}, nil)
+
+		var inserted []model.Block
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		// the new board isn't stored yet
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Any()).Return(nil, nil)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-id-1")).
+			DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				inserted = blocks
+				return &model.BlocksUpsertResult{}, nil
+			})
+
+		summary, err := th.App.ImportWorkspaceArchive(ctx, container, archive, "user-id-1")
+		require.NoError(t, err)
+		require.Equal(t, 1, summary.BoardsCreated)
+		require.Equal(t, 1, summary.CardsCreated)
+		require.Equal(t, 1, summary.CommentsCreated)
+		require.Len(t, summary.Skipped, 1)
+		require.Equal(t, "missing.png", summary.Skipped[0].ID)
+
+		require.Len(t, inserted, len(blocks))
+		byType := map[string]model.Block{}
+		for _, block := range inserted {
+			require.NotContains(t, []string{"board-1", "card-1", "image-1", "image-2", "comment-1"}, block.ID)
+			if block.ID != block.RootID {
+				require.Equal(t, inserted[0].ID, block.RootID)
+			}
+			if block.Fields["fileId"] != "missing.png" {
+				byType[block.Type] = block
+			}
+		}
+
+		image := byType["image"]
+		fileID := image.Fields["fileId"].(string)
+		require.NotEqual(t, "file-1.png", fileID)
+		require.True(t, strings.HasSuffix(fileID, ".png"))
+		require.Equal(t, filepath.Join("0", image.RootID, fileID), writtenPath)
+		require.Equal(t, "image data", written)
+		require.Equal(t, []interface{}{image.ID}, byType["card"].Fields["contentOrder"])
+	})
+
+	t.Run("should refuse blocks whose root isn't in the archive", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := zip.NewWriter(&buf)
+		entry, err := writer.Create("blocks.jsonl")
+		require.NoError(t, err)
+		_, err = entry.Write([]byte(`{"id":"card-1","parentId":"board-1","rootId":"board-1","type":"card"}` + "\n"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+
+		_, err = th.App.ImportWorkspaceArchive(ctx, container, archive, "user-id-1")
+		require.ErrorIs(t, err, ErrInvalidArchive)
+	})
+
+	t.Run("should abort the import when the context is canceled", func(t *testing.T) {
+		data := export(t)
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = th.App.ImportWorkspaceArchive(canceledCtx, container, archive, "user-id-1")
+		require.ErrorIs(t, err, context.Canceled)
+		mockedFileBackend.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should refuse an archive without blocks", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, zip.NewWriter(&buf).Close())
+
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+
+		_, err = th.App.ImportWorkspaceArchive(ctx, container, archive, "user-id-1")
+		require.ErrorIs(t, err, ErrInvalidArchive)
+	})
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// InvalidAttachmentError is returned when an attachment block references
+// a file that isn't stored for its board in the workspace.
+type InvalidAttachmentError struct {
+	BlockID string
+	FileID  string
+}
+
+func (e InvalidAttachmentError) Error() string {
+	if e.FileID == "" {
+		return fmt.Sprintf("attachment %s has no file", e.BlockID)
+	}
+	return fmt.Sprintf("attachment %s references %s, which isn't a file of its board", e.BlockID, e.FileID)
+}
+
+// validateAttachment checks that the file of the attachment block, if
+// it's new or its file changed from the version returned by getStored, is
+// in the files storage for its board. The files are stored by workspace,
+// so a file of another workspace is never found.
+func (a *App) validateAttachment(c store.Container, block *model.Block, getStored func(blockID string) (*model.Block, error)) error {
+	fileID, _ := block.Fields[model.AttachmentFileIDField].(string)
+	if fileID == "" {
+		return InvalidAttachmentError{BlockID: block.ID}
+	}
+	stored, err := getStored(block.ID)
+	if err != nil {
+		return err
+	}
+	if stored != nil && stored.Fields[model.AttachmentFileIDField] == fileID && stored.RootID == block.RootID {
+		return nil
+	}
+
+	if filepath.Base(fileID) != fileID {
+		return InvalidAttachmentError{BlockID: block.ID, FileID: fileID}
+	}
+	exists, err := a.filesBackend.FileExists(filepath.Join(c.WorkspaceID, block.RootID, fileID))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return InvalidAttachmentError{BlockID: block.ID, FileID: fileID}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/mattermost-server/v6/shared/filestore/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAttachment(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	attachment := func(fileID string) model.Block {
+		return model.Block{
+			ID:     "attachment-1",
+			RootID: "board-1",
+			Type:   model.AttachmentBlockType,
+			Fields: map[string]interface{}{
+				model.AttachmentFileIDField:   fileID,
+				model.AttachmentFilenameField: "report.pdf",
+			},
+		}
+	}
+	notStored := func(string) (*model.Block, error) { return nil, nil }
+
+	setupFileBackend := func() *mocks.FileBackend {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", filepath.Join("0", "board-1", "file-1.pdf")).Return(true, nil)
+		mockedFileBackend.On("FileExists", filepath.Join("0", "board-1", "missing.pdf")).Return(false, nil)
+		return mockedFileBackend
+	}
+
+	t.Run("should accept stored files", func(t *testing.T) {
+		setupFileBackend()
+
+		err := th.App.validateBlockFields(container, []model.Block{attachment("file-1.pdf")}, notStored)
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject missing files", func(t *testing.T) {
+		setupFileBackend()
+
+		err := th.App.validateBlockFields(container, []model.Block{attachment("missing.pdf")}, notStored)
+		require.Equal(t, InvalidAttachmentError{BlockID: "attachment-1", FileID: "missing.pdf"}, err)
+	})
+
+	t.Run("should reject files of other boards", func(t *testing.T) {
+		setupFileBackend()
+
+		err := th.App.validateBlockFields(container, []model.Block{attachment("../board-2/file-1.pdf")}, notStored)
+		require.Equal(t, InvalidAttachmentError{BlockID: "attachment-1", FileID: "../board-2/file-1.pdf"}, err)
+	})
+
+	t.Run("should reject attachments without a file", func(t *testing.T) {
+		setupFileBackend()
+
+		err := th.App.validateBlockFields(container, []model.Block{attachment("")}, notStored)
+		require.Equal(t, InvalidAttachmentError{BlockID: "attachment-1"}, err)
+	})
+
+	t.Run("should not check the unchanged files", func(t *testing.T) {
+		mockedFileBackend := setupFileBackend()
+		stored := attachment("missing.pdf")
+
+		err := th.App.validateBlockFields(container, []model.Block{attachment("missing.pdf")}, func(string) (*model.Block, error) {
+			return &stored, nil
+		})
+		require.NoError(t, err)
+		mockedFileBackend.AssertNotCalled(t, "FileExists", filepath.Join("0", "board-1", "missing.pdf"))
+	})
+
+	t.Run("should return the errors of the files storage", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", filepath.Join("0", "board-1", "file-1.pdf")).Return(false, errors.New("unavailable"))
+
+		err := th.App.validateBlockFields(container, []model.Block{attachment("file-1.pdf")}, notStored)
+		require.EqualError(t, err, "unavailable")
+	})
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// recordAuditEntry stores an audit entry of an operation that already
+// succeeded or failed, so an error storing it is only logged.
+func (a *App) recordAuditEntry(action, actorID, workspaceID, entityID string, payload map[string]interface{}) {
+	entry := model.AuditEntry{
+		ID:          utils.CreateGUID(),
+		ActorID:     actorID,
+		WorkspaceID: workspaceID,
+		Action:      action,
+		EntityID:    entityID,
+		Payload:     payload,
+		CreateAt:    utils.GetMillis(),
+	}
+
+	if err := a.store.InsertAuditEntry(entry); err != nil {
+		a.logger.Error("Unable to store the audit entry",
+			mlog.String("action", action),
+			mlog.String("actorID", actorID),
+			mlog.String("entityID", entityID),
+			mlog.Err(err),
+		)
+	}
+}
+
+func (a *App) GetAuditEntries(opts model.QueryAuditEntriesOptions) ([]model.AuditEntry, error) {
+	return a.store.GetAuditEntries(opts)
+}
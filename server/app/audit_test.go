@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+// auditEntryMatcher matches the audit entries of an action by an actor
+// on an entity.
+type auditEntryMatcher struct {
+	action   string
+	actorID  string
+	entityID string
+}
+
+func (m auditEntryMatcher) Matches(x interface{}) bool {
+	entry, ok := x.(model.AuditEntry)
+	if !ok {
+		return false
+	}
+	return entry.ID != "" && entry.CreateAt > 0 &&
+		entry.Action == m.action && entry.ActorID == m.actorID && entry.EntityID == m.entityID
+}
+
+func (m auditEntryMatcher) String() string {
+	return fmt.Sprintf("is an audit entry of %s by %s on %s", m.action, m.actorID, m.entityID)
+}
+
+func expectAuditEntry(th *TestHelper, action, actorID, entityID string) *gomock.Call {
+	return th.Store.EXPECT().InsertAuditEntry(auditEntryMatcher{action, actorID, entityID}).Return(nil)
+}
+
+func TestDeleteBlockAudit(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectSingleWorkspace()
+	th.expectOpenBoards()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	block := &model.Block{ID: "block-id", ParentID: "board-id", RootID: "board-id", Type: "card", Title: "Card"}
+	th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows).AnyTimes()
+
+	t.Run("should record the deletion", func(t *testing.T) {
+		th.Store.EXPECT().GetParentID(gomock.Any(), gomock.Eq(container), gomock.Eq("block-id")).Return("board-id", nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-id")).Return(block, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-id")).Return(&model.Block{ID: "board-id", Type: "board"}, nil)
+		th.expectTx()
+		th.Store.EXPECT().DeleteBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-id"), gomock.Eq("user-id")).Return(nil)
+		th.Store.EXPECT().GetCardBacklinks(gomock.Any(), gomock.Eq(container), gomock.Eq("block-id")).Return(nil, nil)
+		th.Store.EXPECT().InsertAuditEntry(gomock.Any()).DoAndReturn(func(entry model.AuditEntry) error {
+			require.Equal(t, model.AuditActionDeleteBlock, entry.Action)
+			require.Equal(t, "user-id", entry.ActorID)
+			require.Equal(t, "0", entry.WorkspaceID)
+			require.Equal(t, "block-id", entry.EntityID)
+			require.Equal(t, "Card", entry.Payload["title"])
+			require.Equal(t, "board-id", entry.Payload["rootId"])
+			return nil
+		})
+
+		require.NoError(t, th.App.DeleteBlock(ctx, container, "block-id", "user-id"))
+	})
+
+	t.Run("should not fail if the entry can't be stored", func(t *testing.T) {
+		th.Store.EXPECT().GetParentID(gomock.Any(), gomock.Eq(container), gomock.Eq("block-id")).Return("board-id", nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-id")).Return(block, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-id")).Return(&model.Block{ID: "board-id", Type: "board"}, nil)
+		th.expectTx()
+		th.Store.EXPECT().DeleteBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-id"), gomock.Eq("user-id")).Return(nil)
+		th.Store.EXPECT().GetCardBacklinks(gomock.Any(), gomock.Eq(container), gomock.Eq("block-id")).Return(nil, nil)
+		th.Store.EXPECT().InsertAuditEntry(gomock.Any()).Return(errors.New("database error"))
+
+		require.NoError(t, th.App.DeleteBlock(ctx, container, "block-id", "user-id"))
+	})
+}
+
+func TestLogout(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	session := &model.Session{ID: "session-id", UserID: "user-id"}
+
+	t.Run("should delete the session", func(t *testing.T) {
+		th.Store.EXPECT().DeleteSession("session-id").Return(nil)
+		expectAuditEntry(th, model.AuditActionLogout, "user-id", "user-id")
+
+		require.NoError(t, th.App.Logout(session))
+	})
+
+	t.Run("should fail if the session can't be deleted", func(t *testing.T) {
+		th.Store.EXPECT().DeleteSession("session-id").Return(errors.New("database error"))
+
+		require.Error(t, th.App.Logout(session))
+	})
+}
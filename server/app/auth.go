@@ -1,6 +1,9 @@
 package app
 
 import (
+	"context"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/auth"
@@ -24,9 +27,14 @@ func (a *App) GetSession(token string) (*model.Session, error) {
 	return a.auth.GetSession(token)
 }
 
+// SessionExpireAt returns the expiry of a session active at the given time.
+func (a *App) SessionExpireAt(now int64) int64 {
+	return a.auth.SessionExpireAt(now)
+}
+
 // IsValidReadToken validates the read token for a block.
-func (a *App) IsValidReadToken(c store.Container, blockID string, readToken string) (bool, error) {
-	return a.auth.IsValidReadToken(c, blockID, readToken)
+func (a *App) IsValidReadToken(ctx context.Context, c store.Container, blockID string, readToken string) (bool, error) {
+	return a.auth.IsValidReadToken(ctx, c, blockID, readToken)
 }
 
 // GetRegisteredUserCount returns the number of registered users.
@@ -66,14 +74,63 @@ func (a *App) GetUser(id string) (*model.User, error) {
 }
 
 // Login create a new user session if the authentication data is valid.
-func (a *App) Login(username, email, password, mfaToken string) (string, error) {
+// The password is checked against the local user, or the LDAP server when
+// it's the auth backend. The logins are rejected with a LoginLockedError
+// after too many failures for the username or the client address, with
+// ErrMfaRequired when the user has MFA and no code is given, and with
+// ErrPasswordLoginDisabled when the single sign-on is enforced.
+func (a *App) Login(username, email, password, mfaToken, address string) (string, error) {
+	if !a.IsPasswordLoginEnabled() {
+		return "", ErrPasswordLoginDisabled
+	}
+	a.metrics.IncrementLoginAttemptCount(1)
+
+	if err := a.checkLoginLockout(username, email, address); err != nil {
+		return "", err
+	}
+
+	var user *model.User
+	var err error
+	if a.ldap != nil {
+		user, err = a.authenticateWithLDAP(username, email, password, address)
+	} else {
+		user, err = a.authenticateWithPassword(username, email, password, address)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if user.MfaActive {
+		if mfaToken == "" {
+			return "", ErrMfaRequired
+		}
+		valid, err := a.verifyMfaCode(user, mfaToken)
+		if err != nil {
+			return "", err
+		}
+		if !valid {
+			a.loginFailed(user.ID, username, email, address)
+			return "", ErrInvalidMfaCode
+		}
+	}
+
+	method := loginMethodPassword
+	if isLDAPUser(user) {
+		method = loginMethodLDAP
+	}
+	return a.createSession(user, address, method)
+}
+
+// authenticateWithPassword checks the password of the local user with
+// the username, or the email.
+func (a *App) authenticateWithPassword(username, email, password, address string) (*model.User, error) {
 	var user *model.User
 	if username != "" {
 		var err error
 		user, err = a.store.GetUserByUsername(username)
 		if err != nil {
-			a.metrics.IncrementLoginFailCount(1)
-			return "", errors.Wrap(err, "invalid username or password")
+			a.loginFailed("", username, email, address)
+			return nil, errors.Wrap(err, "invalid username or password")
 		}
 	}
 
@@ -81,21 +138,26 @@ func (a *App) Login(username, email, password, mfaToken string) (string, error)
 		var err error
 		user, err = a.store.GetUserByEmail(email)
 		if err != nil {
-			a.metrics.IncrementLoginFailCount(1)
-			return "", errors.Wrap(err, "invalid username or password")
+			a.loginFailed("", username, email, address)
+			return nil, errors.Wrap(err, "invalid username or password")
 		}
 	}
 	if user == nil {
-		a.metrics.IncrementLoginFailCount(1)
-		return "", errors.New("invalid username or password")
+		a.loginFailed("", username, email, address)
+		return nil, errors.New("invalid username or password")
 	}
 
 	if !auth.ComparePassword(user.Password, password) {
-		a.metrics.IncrementLoginFailCount(1)
+		a.loginFailed(user.ID, username, email, address)
 		a.logger.Debug("Invalid password for user", mlog.String("userID", user.ID))
-		return "", errors.New("invalid username or password")
+		return nil, errors.New("invalid username or password")
 	}
+	return user, nil
+}
 
+// createSession creates a new session of the user logged in with the
+// method, and returns its token.
+func (a *App) createSession(user *model.User, address, method string) (string, error) {
 	authService := user.AuthService
 	if authService == "" {
 		authService = "native"
@@ -107,6 +169,7 @@ func (a *App) Login(username, email, password, mfaToken string) (string, error)
 		UserID:      user.ID,
 		AuthService: authService,
 		Props:       map[string]interface{}{},
+		ExpireAt:    a.auth.SessionExpireAt(time.Now().Unix()),
 	}
 	err := a.store.CreateSession(&session)
 	if err != nil {
@@ -114,13 +177,88 @@ func (a *App) Login(username, email, password, mfaToken string) (string, error)
 	}
 
 	a.metrics.IncrementLoginCount(1)
+	a.clearLoginFailures(user)
+	a.recordAuditEntry(model.AuditActionLogin, user.ID, "", user.ID, map[string]interface{}{
+		"authService": authService,
+		"method":      method,
+		"address":     address,
+	})
 
-	// TODO: MFA verification
 	return session.Token, nil
 }
 
-// RegisterUser creates a new user if the provided data is valid.
+// loginFailed counts and audits a failed login, with the ID of the user
+// if it was found.
+func (a *App) loginFailed(userID, username, email, address string) {
+	a.metrics.IncrementLoginFailCount(1)
+	a.countLoginFailure(userID, username, email, address)
+	a.recordAuditEntry(model.AuditActionLoginFailed, userID, "", userID, map[string]interface{}{
+		"username": username,
+		"email":    email,
+		"address":  address,
+	})
+}
+
+// Logout deletes the session, so that its token can't be used anymore.
+func (a *App) Logout(session *model.Session) error {
+	if err := a.store.DeleteSession(session.ID); err != nil {
+		return errors.Wrap(err, "unable to delete session")
+	}
+
+	a.recordAuditEntry(model.AuditActionLogout, session.UserID, "", session.UserID, nil)
+	return nil
+}
+
+// RegisterUser creates a new user if the provided data is valid, and
+// the password logins aren't disabled. The LDAP users can't register,
+// they're provisioned on their first login.
 func (a *App) RegisterUser(username, email, password string) error {
+	if !a.IsPasswordLoginEnabled() {
+		return ErrPasswordLoginDisabled
+	}
+	if a.ldap != nil {
+		return ErrLDAPRegistrationDisabled
+	}
+	if err := a.checkNewUser(username, email, password); err != nil {
+		return err
+	}
+
+	_, err := a.createUser(username, email, password, false)
+	return err
+}
+
+// createUser creates a new user, or guest, checked by checkNewUser, and
+// returns it.
+func (a *App) createUser(username, email, password string, guest bool) (*model.User, error) {
+	// the first user administers the server
+	userCount, err := a.store.GetRegisteredUserCount()
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to count the users")
+	}
+
+	user := &model.User{
+		ID:          uuid.New().String(),
+		Username:    username,
+		Email:       email,
+		Password:    auth.HashPassword(password),
+		MfaSecret:   "",
+		AuthService: a.config.AuthMode,
+		AuthData:    "",
+		Props:       map[string]interface{}{},
+		IsAdmin:     userCount == 0 && !guest,
+		IsGuest:     guest,
+	}
+	err = a.store.CreateUser(user)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to create the new user")
+	}
+
+	return user, nil
+}
+
+// checkNewUser returns an error if a user already has the username or
+// the email, or if the password is invalid.
+func (a *App) checkNewUser(username, email, password string) error {
 	var user *model.User
 	if username != "" {
 		var err error
@@ -143,29 +281,19 @@ func (a *App) RegisterUser(username, email, password string) error {
 		MinimumLength: 6,
 	}
 
-	err := auth.IsPasswordValid(password, passwordSettings)
-	if err != nil {
+	if err := auth.IsPasswordValid(password, passwordSettings); err != nil {
 		return errors.Wrap(err, "Invalid password")
 	}
-
-	err = a.store.CreateUser(&model.User{
-		ID:          uuid.New().String(),
-		Username:    username,
-		Email:       email,
-		Password:    auth.HashPassword(password),
-		MfaSecret:   "",
-		AuthService: a.config.AuthMode,
-		AuthData:    "",
-		Props:       map[string]interface{}{},
-	})
-	if err != nil {
-		return errors.Wrap(err, "Unable to create the new user")
-	}
-
 	return nil
 }
 
+// UpdateUserPassword sets the password of the user with the username,
+// unless the user logs in with the LDAP server.
 func (a *App) UpdateUserPassword(username, password string) error {
+	if user, err := a.store.GetUserByUsername(username); err == nil && isLDAPUser(user) {
+		return ErrLDAPPasswordChange
+	}
+
 	err := a.store.UpdateUserPassword(username, auth.HashPassword(password))
 	if err != nil {
 		return err
@@ -174,6 +302,8 @@ func (a *App) UpdateUserPassword(username, password string) error {
 	return nil
 }
 
+// ChangePassword changes the password of the user, checking the old one,
+// unless the user logs in with the LDAP server.
 func (a *App) ChangePassword(userID, oldPassword, newPassword string) error {
 	var user *model.User
 	if userID != "" {
@@ -187,6 +317,9 @@ func (a *App) ChangePassword(userID, oldPassword, newPassword string) error {
 	if user == nil {
 		return errors.New("invalid username or password")
 	}
+	if isLDAPUser(user) {
+		return ErrLDAPPasswordChange
+	}
 
 	if !auth.ComparePassword(user.Password, oldPassword) {
 		a.logger.Debug("Invalid password for user", mlog.String("userID", user.ID))
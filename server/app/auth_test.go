@@ -1,6 +1,7 @@
 package app
 
 import (
+	"database/sql"
 	"fmt"
 	"testing"
 
@@ -44,10 +45,13 @@ func TestLogin(t *testing.T) {
 	th.Store.EXPECT().GetUserByUsername("testUsername").Return(mockUser, nil).Times(2)
 	th.Store.EXPECT().GetUserByEmail("testEmail").Return(mockUser, nil)
 	th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil).Times(2)
+	expectAuditEntry(th, model.AuditActionLoginFailed, "", "").Times(3)
+	expectAuditEntry(th, model.AuditActionLoginFailed, mockUser.ID, mockUser.ID)
+	expectAuditEntry(th, model.AuditActionLogin, mockUser.ID, mockUser.ID).Times(2)
 
 	for _, test := range testcases {
 		t.Run(test.title, func(t *testing.T) {
-			token, err := th.App.Login(test.userName, test.email, test.password, test.mfa)
+			token, err := th.App.Login(test.userName, test.email, test.password, test.mfa, "")
 			if test.isError {
 				require.Error(t, err)
 			} else {
@@ -110,6 +114,7 @@ func TestRegisterUser(t *testing.T) {
 	th.Store.EXPECT().GetUserByUsername("newUsername").Return(mockUser, errors.New("user not found"))
 	th.Store.EXPECT().GetUserByEmail("existingEmail").Return(mockUser, nil)
 	th.Store.EXPECT().GetUserByEmail("newEmail").Return(nil, errors.New("email not found"))
+	th.Store.EXPECT().GetRegisteredUserCount().Return(1, nil)
 	th.Store.EXPECT().CreateUser(gomock.Any()).Return(nil)
 
 	for _, test := range testcases {
@@ -140,6 +145,9 @@ func TestUpdateUserPassword(t *testing.T) {
 		{"success, username", "testUsername", "testPassword", false},
 	}
 
+	th.Store.EXPECT().GetUserByUsername("").Return(nil, sql.ErrNoRows)
+	th.Store.EXPECT().GetUserByUsername("badUsername").Return(nil, sql.ErrNoRows)
+	th.Store.EXPECT().GetUserByUsername("testUsername").Return(mockUser, nil)
 	th.Store.EXPECT().UpdateUserPassword("", gomock.Any()).Return(errors.New("user not found"))
 	th.Store.EXPECT().UpdateUserPassword("badUsername", gomock.Any()).Return(errors.New("user not found"))
 	th.Store.EXPECT().UpdateUserPassword("testUsername", gomock.Any()).Return(nil)
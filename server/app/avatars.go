@@ -0,0 +1,107 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/thumbnail"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/pkg/errors"
+)
+
+// avatarsDirectory is the directory of the files storage with the avatars
+// of the users, apart from the directories of the workspaces.
+const avatarsDirectory = "users"
+
+var (
+	// ErrAvatarUserDeactivated is returned when the avatar of a
+	// deactivated user is set, which is kept as it was.
+	ErrAvatarUserDeactivated = errors.New("the avatar of a deactivated user can't be changed")
+
+	// ErrAvatarAccessDenied is returned when the avatar of a user who
+	// shares no workspace with the viewer is requested.
+	ErrAvatarAccessDenied = errors.New("the user doesn't share a workspace")
+)
+
+func avatarPath(userID string) string {
+	return filepath.Join(avatarsDirectory, userID, "avatar.png")
+}
+
+// SetUserAvatar stores the avatar of the user, cropped and scaled from the
+// image without its metadata, and returns the time it was set, which is
+// kept in the props of the user.
+func (a *App) SetUserAvatar(userID string, image io.ReadSeeker) (int64, error) {
+	user, err := a.store.GetUserByID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrAvatarUserDeactivated
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to get the user")
+	}
+
+	var buf bytes.Buffer
+	if err = thumbnail.GenerateAvatar(image, &buf, thumbnail.AvatarSize); err != nil {
+		return 0, err
+	}
+	if _, err = a.filesBackend.WriteFile(&buf, avatarPath(userID)); err != nil {
+		return 0, fmt.Errorf("unable to store the avatar in the files storage: %w", err)
+	}
+
+	updateAt := utils.GetMillis()
+	if user.Props == nil {
+		user.Props = map[string]interface{}{}
+	}
+	user.Props[model.UserPropAvatarUpdateAt] = updateAt
+	if err = a.store.UpdateUser(user); err != nil {
+		return 0, err
+	}
+
+	a.recordAuditEntry(model.AuditActionUpdateAvatar, userID, "", userID, nil)
+	return updateAt, nil
+}
+
+// GetUserAvatar returns the avatar of the user for the viewer, who shares
+// a workspace with them, and the time it was set. The users without an
+// avatar get their identicon, with a zero time. The avatars of the
+// deactivated users are still returned.
+func (a *App) GetUserAvatar(ctx context.Context, viewerID, userID string) (io.ReadSeeker, time.Time, error) {
+	if viewerID != userID {
+		shares, err := a.store.SharesWorkspace(ctx, viewerID, userID)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if !shares {
+			return nil, time.Time{}, ErrAvatarAccessDenied
+		}
+	}
+
+	filePath := avatarPath(userID)
+	exists, err := a.filesBackend.FileExists(filePath)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("unable to check the avatar in the files storage: %w", err)
+	}
+	if !exists {
+		var buf bytes.Buffer
+		if err := thumbnail.GenerateIdenticon(userID, &buf, thumbnail.AvatarSize); err != nil {
+			return nil, time.Time{}, err
+		}
+		return bytes.NewReader(buf.Bytes()), time.Time{}, nil
+	}
+
+	modTime, err := a.filesBackend.FileModTime(filePath)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("unable to get the avatar time: %w", err)
+	}
+	data, err := a.filesBackend.ReadFile(filePath)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("unable to read the avatar from the files storage: %w", err)
+	}
+	return bytes.NewReader(data), modTime, nil
+}
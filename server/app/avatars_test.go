@@ -0,0 +1,147 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/thumbnail"
+
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost-server/v6/shared/filestore/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func testAvatarImage(t *testing.T) *bytes.Reader {
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 300; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestSetUserAvatar(t *testing.T) {
+	t.Run("should store the avatar and its time", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		var written []byte
+		mockedFileBackend.On("WriteFile", mock.Anything, "users/user-1/avatar.png").Return(func(reader io.Reader, path string) int64 {
+			written, _ = ioutil.ReadAll(reader)
+			return int64(len(written))
+		}, nil)
+
+		var updated *model.User
+		th.Store.EXPECT().GetUserByID("user-1").Return(&model.User{ID: "user-1"}, nil)
+		th.Store.EXPECT().UpdateUser(gomock.Any()).DoAndReturn(func(user *model.User) error {
+			updated = user
+			return nil
+		})
+		expectAuditEntry(th, model.AuditActionUpdateAvatar, "user-1", "user-1")
+
+		updateAt, err := th.App.SetUserAvatar("user-1", testAvatarImage(t))
+		require.NoError(t, err)
+		require.NotZero(t, updateAt)
+		require.Equal(t, updateAt, updated.Props[model.UserPropAvatarUpdateAt])
+
+		avatar, err := png.Decode(bytes.NewReader(written))
+		require.NoError(t, err)
+		require.Equal(t, image.Rect(0, 0, thumbnail.AvatarSize, thumbnail.AvatarSize), avatar.Bounds())
+	})
+
+	t.Run("should not change the avatar of a deactivated user", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		th.Store.EXPECT().GetUserByID("user-1").Return(nil, sql.ErrNoRows)
+
+		_, err := th.App.SetUserAvatar("user-1", testAvatarImage(t))
+		require.ErrorIs(t, err, ErrAvatarUserDeactivated)
+		mockedFileBackend.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject the unsupported images", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		th.Store.EXPECT().GetUserByID("user-1").Return(&model.User{ID: "user-1"}, nil)
+
+		_, err := th.App.SetUserAvatar("user-1", bytes.NewReader([]byte("not an image")))
+		require.ErrorIs(t, err, thumbnail.ErrUnsupported)
+		mockedFileBackend.AssertNotCalled(t, "WriteFile", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetUserAvatar(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should return the stored avatar", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		modTime := time.Now().Truncate(time.Second)
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", "users/user-2/avatar.png").Return(true, nil)
+		mockedFileBackend.On("FileModTime", "users/user-2/avatar.png").Return(modTime, nil)
+		mockedFileBackend.On("ReadFile", "users/user-2/avatar.png").Return([]byte("avatar"), nil)
+		th.Store.EXPECT().SharesWorkspace(ctx, "user-1", "user-2").Return(true, nil)
+
+		reader, updateAt, err := th.App.GetUserAvatar(ctx, "user-1", "user-2")
+		require.NoError(t, err)
+		require.Equal(t, modTime, updateAt)
+		data, err := ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, "avatar", string(data))
+	})
+
+	t.Run("should return the identicon of a user without an avatar", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		mockedFileBackend.On("FileExists", "users/user-1/avatar.png").Return(false, nil)
+
+		// the users can always get their own avatar
+		reader, updateAt, err := th.App.GetUserAvatar(ctx, "user-1", "user-1")
+		require.NoError(t, err)
+		require.True(t, updateAt.IsZero())
+
+		identicon, err := png.Decode(reader)
+		require.NoError(t, err)
+		require.Equal(t, image.Rect(0, 0, thumbnail.AvatarSize, thumbnail.AvatarSize), identicon.Bounds())
+	})
+
+	t.Run("should not return the avatar of another workspace", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+		th.Store.EXPECT().SharesWorkspace(ctx, "user-1", "user-2").Return(false, nil)
+
+		_, _, err := th.App.GetUserAvatar(ctx, "user-1", "user-2")
+		require.ErrorIs(t, err, ErrAvatarAccessDenied)
+		mockedFileBackend.AssertNotCalled(t, "FileExists", mock.Anything)
+	})
+}
@@ -0,0 +1,279 @@
+package app
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/metrics"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/ws"
+)
+
+// blockCacheKey is the key of the blocks of a board in the block cache.
+type blockCacheKey struct {
+	workspaceID string
+	rootID      string
+}
+
+// blockCacheEntry is the JSON of the subtree of a board, with its digest.
+type blockCacheEntry struct {
+	key    blockCacheKey
+	data   []byte
+	digest model.BlocksDigest
+}
+
+// blockCache is an LRU cache of the JSON of the 3 levels subtrees of the
+// boards, the blocks fetched when a board is opened. Its entries are
+// invalidated by the block changes broadcast to the websocket clients,
+// and in a cluster by the block changes of the other nodes.
+type blockCache struct {
+	mutex    sync.Mutex
+	maxBytes int64
+	size     int64
+	lru      *list.List // the entries, the most recently used first
+	entries  map[blockCacheKey]*list.Element
+
+	// generation counts the invalidations. The fills started before an
+	// invalidation of their board don't store their blocks, which may
+	// already be stale, the invalidations being kept while fills are
+	// running.
+	generation            uint64
+	fills                 int
+	invalidatedRoots      map[blockCacheKey]uint64
+	invalidatedWorkspaces map[string]uint64
+
+	metrics *metrics.Metrics
+}
+
+func newBlockCache(maxBytes int64, m *metrics.Metrics) *blockCache {
+	if maxBytes <= 0 {
+		maxBytes = config.DefaultBlockCacheMaxBytes
+	}
+	return &blockCache{
+		maxBytes:              maxBytes,
+		lru:                   list.New(),
+		entries:               map[blockCacheKey]*list.Element{},
+		invalidatedRoots:      map[blockCacheKey]uint64{},
+		invalidatedWorkspaces: map[string]uint64{},
+		metrics:               m,
+	}
+}
+
+// get returns the cached entry of the board, if any.
+func (bc *blockCache) get(key blockCacheKey) (*blockCacheEntry, bool) {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	element, ok := bc.entries[key]
+	if !ok {
+		bc.metrics.IncrementBlockCacheMisses()
+		return nil, false
+	}
+	bc.metrics.IncrementBlockCacheHits()
+	bc.lru.MoveToFront(element)
+	return element.Value.(*blockCacheEntry), true
+}
+
+// beginFill starts fetching the blocks of an entry, returning the
+// generation to pass to endFill.
+func (bc *blockCache) beginFill() uint64 {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+	bc.fills++
+	return bc.generation
+}
+
+// endFill stores the entry fetched since the generation, unless its
+// board was invalidated meanwhile. A nil entry only ends the fill.
+func (bc *blockCache) endFill(generation uint64, entry *blockCacheEntry) {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	bc.fills--
+	if entry != nil && bc.invalidatedRoots[entry.key] <= generation &&
+		bc.invalidatedWorkspaces[entry.key.workspaceID] <= generation {
+		bc.add(entry)
+	}
+	if bc.fills == 0 {
+		bc.invalidatedRoots = map[blockCacheKey]uint64{}
+		bc.invalidatedWorkspaces = map[string]uint64{}
+	}
+}
+
+// add stores the entry, evicting the least recently used ones beyond
+// the size of the cache. The entries larger than the cache aren't stored.
+func (bc *blockCache) add(entry *blockCacheEntry) {
+	size := int64(len(entry.data))
+	if size > bc.maxBytes {
+		return
+	}
+	if element, ok := bc.entries[entry.key]; ok {
+		bc.remove(element)
+	}
+	bc.entries[entry.key] = bc.lru.PushFront(entry)
+	bc.size += size
+
+	for bc.size > bc.maxBytes {
+		bc.remove(bc.lru.Back())
+	}
+	bc.metrics.ObserveBlockCacheBytes(bc.size)
+}
+
+func (bc *blockCache) remove(element *list.Element) {
+	entry := bc.lru.Remove(element).(*blockCacheEntry)
+	delete(bc.entries, entry.key)
+	bc.size -= int64(len(entry.data))
+}
+
+// invalidate removes the entries of the boards of the workspace, or of
+// the whole workspace when no root IDs are given.
+func (bc *blockCache) invalidate(workspaceID string, rootIDs []string) {
+	bc.mutex.Lock()
+	defer bc.mutex.Unlock()
+
+	bc.generation++
+	if len(rootIDs) == 0 {
+		if bc.fills > 0 {
+			bc.invalidatedWorkspaces[workspaceID] = bc.generation
+		}
+		for key, element := range bc.entries {
+			if key.workspaceID == workspaceID {
+				bc.remove(element)
+			}
+		}
+	}
+	for _, rootID := range rootIDs {
+		key := blockCacheKey{workspaceID: workspaceID, rootID: rootID}
+		if bc.fills > 0 {
+			bc.invalidatedRoots[key] = bc.generation
+		}
+		if element, ok := bc.entries[key]; ok {
+			bc.remove(element)
+		}
+	}
+	bc.metrics.ObserveBlockCacheBytes(bc.size)
+}
+
+// blockCacheAdapter invalidates the block cache with the block changes
+// broadcast to the websocket clients, before they fetch the blocks
+// again. In a cluster, the invalidations are also sent to the other
+// nodes.
+type blockCacheAdapter struct {
+	ws.Adapter
+	cache   *blockCache
+	cluster ws.BlockCacheInvalidator
+}
+
+func newBlockCacheAdapter(adapter ws.Adapter, cache *blockCache) *blockCacheAdapter {
+	cacheAdapter := &blockCacheAdapter{Adapter: adapter, cache: cache}
+	if cluster, ok := adapter.(ws.BlockCacheInvalidator); ok {
+		cluster.OnBlockCacheInvalidation(cache.invalidate)
+		cacheAdapter.cluster = cluster
+	}
+	return cacheAdapter
+}
+
+func (ca *blockCacheAdapter) invalidate(workspaceID string, rootIDs []string) {
+	ca.cache.invalidate(workspaceID, rootIDs)
+	if ca.cluster != nil {
+		ca.cluster.PublishBlockCacheInvalidation(workspaceID, rootIDs)
+	}
+}
+
+// invalidateBlocks invalidates the boards of the blocks.
+func (ca *blockCacheAdapter) invalidateBlocks(workspaceID string, blocks []model.Block) {
+	seen := map[string]bool{}
+	rootIDs := []string{}
+	for i := range blocks {
+		for _, id := range []string{blocks[i].RootID, blocks[i].ID} {
+			if id != "" && !seen[id] {
+				seen[id] = true
+				rootIDs = append(rootIDs, id)
+			}
+		}
+	}
+	if len(rootIDs) > 0 {
+		ca.invalidate(workspaceID, rootIDs)
+	}
+}
+
+func (ca *blockCacheAdapter) BroadcastBlockChange(workspaceID string, block model.Block) {
+	ca.invalidateBlocks(workspaceID, []model.Block{block})
+	ca.Adapter.BroadcastBlockChange(workspaceID, block)
+}
+
+func (ca *blockCacheAdapter) BroadcastBlockChanges(workspaceID string, blocks []model.Block) {
+	ca.invalidateBlocks(workspaceID, blocks)
+	ca.Adapter.BroadcastBlockChanges(workspaceID, blocks)
+}
+
+func (ca *blockCacheAdapter) BroadcastBlockDelete(workspaceID, blockID, parentID, rootID string) {
+	ca.invalidateBlocks(workspaceID, []model.Block{{ID: blockID, RootID: rootID}})
+	ca.Adapter.BroadcastBlockDelete(workspaceID, blockID, parentID, rootID)
+}
+
+// invalidateWorkspaceBlocks invalidates the cached blocks of the
+// workspace, if the cache is enabled.
+func (a *App) invalidateWorkspaceBlocks(workspaceID string) {
+	if cacheAdapter, ok := a.wsAdapter.(*blockCacheAdapter); ok {
+		cacheAdapter.invalidate(workspaceID, nil)
+	}
+}
+
+// GetSubTreeJSON returns the JSON of the blocks of a subtree, like
+// GetSubTree, and their digest. The 3 levels subtrees of the boards are
+// cached when the block cache is enabled.
+func (a *App) GetSubTreeJSON(ctx context.Context, c store.Container, blockID string, levels int) ([]byte, model.BlocksDigest, error) {
+	if a.blockCache == nil || levels < 3 {
+		data, digest, _, err := a.marshalSubTree(ctx, c, blockID, levels)
+		return data, digest, err
+	}
+
+	key := blockCacheKey{workspaceID: c.WorkspaceID, rootID: blockID}
+	if entry, ok := a.blockCache.get(key); ok {
+		return entry.data, entry.digest, nil
+	}
+
+	var entry *blockCacheEntry
+	generation := a.blockCache.beginFill()
+	defer func() { a.blockCache.endFill(generation, entry) }()
+
+	data, digest, isBoard, err := a.marshalSubTree(ctx, c, blockID, levels)
+	if err != nil {
+		return nil, model.BlocksDigest{}, err
+	}
+	// only the subtrees of the boards are invalidated by their root ID
+	if isBoard {
+		entry = &blockCacheEntry{key: key, data: data, digest: digest}
+	}
+	return data, digest, nil
+}
+
+// marshalSubTree returns the JSON of the blocks of a subtree and their
+// digest, and tells if the subtree is a board.
+func (a *App) marshalSubTree(ctx context.Context, c store.Container, blockID string, levels int) ([]byte, model.BlocksDigest, bool, error) {
+	blocks, err := a.GetSubTree(ctx, c, blockID, levels)
+	if err != nil {
+		return nil, model.BlocksDigest{}, false, err
+	}
+	data, err := json.Marshal(blocks)
+	if err != nil {
+		return nil, model.BlocksDigest{}, false, err
+	}
+
+	digest := model.BlocksDigest{Count: int64(len(blocks))}
+	isBoard := false
+	for i := range blocks {
+		if blocks[i].UpdateAt > digest.UpdateAt {
+			digest.UpdateAt = blocks[i].UpdateAt
+		}
+		if blocks[i].ID == blockID && blocks[i].RootID == blockID {
+			isBoard = true
+		}
+	}
+	return data, digest, isBoard, nil
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/ws"
+	"github.com/stretchr/testify/require"
+)
+
+// testClusterAdapter is an adapter of a node of a cluster, recording the
+// block cache invalidations it publishes.
+type testClusterAdapter struct {
+	ws.Adapter
+	published [][]string
+	handler   func(workspaceID string, rootIDs []string)
+}
+
+func (ca *testClusterAdapter) BroadcastBlockChanges(workspaceID string, blocks []model.Block) {}
+
+func (ca *testClusterAdapter) BroadcastBlockDelete(workspaceID, blockID, parentID, rootID string) {}
+
+func (ca *testClusterAdapter) PublishBlockCacheInvalidation(workspaceID string, rootIDs []string) {
+	ca.published = append(ca.published, append([]string{workspaceID}, rootIDs...))
+}
+
+func (ca *testClusterAdapter) OnBlockCacheInvalidation(handler func(workspaceID string, rootIDs []string)) {
+	ca.handler = handler
+}
+
+func testBlockCacheEntry(workspaceID, rootID string, size int) *blockCacheEntry {
+	return &blockCacheEntry{
+		key:  blockCacheKey{workspaceID: workspaceID, rootID: rootID},
+		data: make([]byte, size),
+	}
+}
+
+func TestBlockCache(t *testing.T) {
+	fill := func(cache *blockCache, entry *blockCacheEntry) {
+		cache.endFill(cache.beginFill(), entry)
+	}
+	cached := func(cache *blockCache, workspaceID, rootID string) bool {
+		_, ok := cache.get(blockCacheKey{workspaceID: workspaceID, rootID: rootID})
+		return ok
+	}
+
+	t.Run("evict the least recently used entries", func(t *testing.T) {
+		cache := newBlockCache(100, nil)
+		fill(cache, testBlockCacheEntry("0", "board-1", 40))
+		fill(cache, testBlockCacheEntry("0", "board-2", 40))
+		require.True(t, cached(cache, "0", "board-1"))
+
+		fill(cache, testBlockCacheEntry("0", "board-3", 40))
+		require.True(t, cached(cache, "0", "board-1"))
+		require.False(t, cached(cache, "0", "board-2"))
+		require.True(t, cached(cache, "0", "board-3"))
+		require.EqualValues(t, 80, cache.size)
+	})
+
+	t.Run("don't store the entries larger than the cache", func(t *testing.T) {
+		cache := newBlockCache(100, nil)
+		fill(cache, testBlockCacheEntry("0", "board-1", 101))
+		require.False(t, cached(cache, "0", "board-1"))
+		require.Zero(t, cache.size)
+	})
+
+	t.Run("invalidate the boards", func(t *testing.T) {
+		cache := newBlockCache(100, nil)
+		fill(cache, testBlockCacheEntry("0", "board-1", 10))
+		fill(cache, testBlockCacheEntry("0", "board-2", 10))
+		fill(cache, testBlockCacheEntry("other", "board-3", 10))
+
+		cache.invalidate("0", []string{"board-1"})
+		require.False(t, cached(cache, "0", "board-1"))
+		require.True(t, cached(cache, "0", "board-2"))
+
+		cache.invalidate("0", nil)
+		require.False(t, cached(cache, "0", "board-2"))
+		require.True(t, cached(cache, "other", "board-3"))
+		require.EqualValues(t, 10, cache.size)
+	})
+
+	t.Run("don't store the blocks fetched before an invalidation", func(t *testing.T) {
+		cache := newBlockCache(100, nil)
+		generation := cache.beginFill()
+		cache.invalidate("0", []string{"board-1"})
+		cache.endFill(generation, testBlockCacheEntry("0", "board-1", 10))
+		require.False(t, cached(cache, "0", "board-1"))
+
+		generation = cache.beginFill()
+		cache.invalidate("0", nil)
+		cache.endFill(generation, testBlockCacheEntry("0", "board-2", 10))
+		require.False(t, cached(cache, "0", "board-2"))
+
+		fill(cache, testBlockCacheEntry("0", "board-1", 10))
+		require.True(t, cached(cache, "0", "board-1"))
+		require.Empty(t, cache.invalidatedRoots)
+	})
+}
+
+func TestBlockCacheAdapter(t *testing.T) {
+	cluster := &testClusterAdapter{}
+	cache := newBlockCache(100, nil)
+	adapter := newBlockCacheAdapter(cluster, cache)
+	fill := func(rootID string) {
+		cache.endFill(cache.beginFill(), testBlockCacheEntry("0", rootID, 10))
+	}
+
+	t.Run("invalidate the boards of the changed blocks", func(t *testing.T) {
+		fill("board-1")
+		fill("board-2")
+		adapter.BroadcastBlockChanges("0", []model.Block{
+			{ID: "card-1", RootID: "board-1"},
+			{ID: "card-2", RootID: "board-1"},
+		})
+		require.NotContains(t, cache.entries, blockCacheKey{workspaceID: "0", rootID: "board-1"})
+		require.Contains(t, cache.entries, blockCacheKey{workspaceID: "0", rootID: "board-2"})
+		require.Equal(t, []string{"0", "board-1", "card-1", "card-2"}, cluster.published[len(cluster.published)-1])
+
+		adapter.BroadcastBlockDelete("0", "board-2", "", "board-2")
+		require.NotContains(t, cache.entries, blockCacheKey{workspaceID: "0", rootID: "board-2"})
+		require.Equal(t, []string{"0", "board-2"}, cluster.published[len(cluster.published)-1])
+	})
+
+	t.Run("invalidate the boards changed by the other nodes", func(t *testing.T) {
+		fill("board-1")
+		published := len(cluster.published)
+		cluster.handler("0", []string{"board-1"})
+		require.NotContains(t, cache.entries, blockCacheKey{workspaceID: "0", rootID: "board-1"})
+		require.Len(t, cluster.published, published)
+	})
+}
+
+func TestGetSubTreeJSON(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.blockCache = newBlockCache(1024, nil)
+	th.App.wsAdapter = newBlockCacheAdapter(th.App.wsAdapter, th.App.blockCache)
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	blocks := []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board", UpdateAt: 10},
+		{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card", UpdateAt: 20},
+	}
+
+	t.Run("cache the subtrees of the boards", func(t *testing.T) {
+		th.Store.EXPECT().GetSubTree3(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(blocks, nil).Times(1)
+
+		data, digest, err := th.App.GetSubTreeJSON(ctx, container, "board-1", 3)
+		require.NoError(t, err)
+		require.Equal(t, model.BlocksDigest{Count: 2, UpdateAt: 20}, digest)
+
+		cachedData, cachedDigest, err := th.App.GetSubTreeJSON(ctx, container, "board-1", 3)
+		require.NoError(t, err)
+		require.Equal(t, data, cachedData)
+		require.Equal(t, digest, cachedDigest)
+	})
+
+	t.Run("fetch the blocks again after a change", func(t *testing.T) {
+		th.App.wsAdapter.BroadcastBlockChange("0", blocks[1])
+		th.Store.EXPECT().GetSubTree3(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(blocks[:1], nil).Times(1)
+
+		_, digest, err := th.App.GetSubTreeJSON(ctx, container, "board-1", 3)
+		require.NoError(t, err)
+		require.Equal(t, model.BlocksDigest{Count: 1, UpdateAt: 10}, digest)
+	})
+
+	t.Run("don't cache the subtrees of the other blocks", func(t *testing.T) {
+		th.Store.EXPECT().GetSubTree3(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(blocks[1:], nil).Times(2)
+
+		for i := 0; i < 2; i++ {
+			_, _, err := th.App.GetSubTreeJSON(ctx, container, "card-1", 3)
+			require.NoError(t, err)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// ErrBlockVersionNotFound is returned when reverting a block to a version
+// that isn't in its history.
+var ErrBlockVersionNotFound = errors.New("the version isn't in the history of the block")
+
+// ErrInvalidRevert is returned when a block can't be reverted to a
+// version, the parent of the version having been deleted since.
+var ErrInvalidRevert = errors.New("the block can't be reverted to the version")
+
+// RevertBlock restores a version of the block from its history, the
+// version being identified by its update time. The version is applied as
+// a patch of the block, so that it's recorded as a new version and the
+// history stays append-only. The values of the card properties that the
+// board doesn't have anymore are left out, as well as the options deleted
+// since.
+func (a *App) RevertBlock(ctx context.Context, c store.Container, blockID string, version int64, userID string) (*model.BlockPatchResult, error) {
+	history, err := a.store.GetBlockHistory(ctx, c, blockID, model.QueryBlockHistoryOptions{Descending: true})
+	if err != nil {
+		return nil, err
+	}
+	var target *model.Block
+	for i := range history {
+		if history[i].UpdateAt == version && history[i].DeleteAt == 0 {
+			target = &history[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, ErrBlockVersionNotFound
+	}
+
+	current, err := a.store.GetBlock(ctx, c, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		// the deleted blocks are restored with UndeleteBlock
+		return nil, sql.ErrNoRows
+	}
+
+	if target.ParentID != "" && target.ParentID != current.ParentID {
+		parent, err := a.store.GetBlock(ctx, c, target.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, fmt.Errorf("%w: the parent %s was deleted", ErrInvalidRevert, target.ParentID)
+		}
+	}
+
+	if target.Type == "card" {
+		board, err := a.store.GetBlock(ctx, c, target.RootID)
+		if err != nil {
+			return nil, err
+		}
+		if board != nil {
+			if properties, ok := target.Fields["properties"].(map[string]interface{}); ok {
+				target.Fields["properties"] = revertedProperties(*board, properties)
+			}
+		}
+	}
+
+	return a.PatchBlock(ctx, c, blockID, revertPatch(*current, *target), userID, false)
+}
+
+// revertedProperties returns the values of the card properties that the
+// board still has, without the options that were deleted since.
+func revertedProperties(board model.Block, properties map[string]interface{}) map[string]interface{} {
+	byID := map[string]csvProperty{}
+	for _, property := range csvProperties(board, nil) {
+		byID[property.id] = property
+	}
+
+	reverted := map[string]interface{}{}
+	for id, value := range properties {
+		property, ok := byID[id]
+		if !ok {
+			continue
+		}
+
+		switch property.propertyType {
+		case "select":
+			if _, ok := property.options[fmt.Sprint(value)]; !ok {
+				continue
+			}
+		case "multiSelect":
+			values, _ := value.([]interface{})
+			kept := []interface{}{}
+			for _, optionID := range values {
+				if _, ok := property.options[fmt.Sprint(optionID)]; ok {
+					kept = append(kept, optionID)
+				}
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			value = kept
+		}
+		reverted[id] = value
+	}
+	return reverted
+}
+
+// revertPatch returns the patch changing the block back to the version.
+func revertPatch(current, version model.Block) *model.BlockPatch {
+	patch := &model.BlockPatch{
+		UpdatedFields: map[string]interface{}{},
+		DeletedFields: []string{},
+	}
+	if version.ParentID != current.ParentID {
+		patch.ParentID = &version.ParentID
+	}
+	if version.RootID != current.RootID {
+		patch.RootID = &version.RootID
+	}
+	if version.Schema != current.Schema {
+		patch.Schema = &version.Schema
+	}
+	if version.Type != current.Type {
+		patch.Type = &version.Type
+	}
+	if version.Title != current.Title {
+		patch.Title = &version.Title
+	}
+
+	for key, value := range version.Fields {
+		if !reflect.DeepEqual(current.Fields[key], value) {
+			patch.UpdatedFields[key] = value
+		}
+	}
+	for key := range current.Fields {
+		if _, ok := version.Fields[key]; !ok {
+			patch.DeletedFields = append(patch.DeletedFields, key)
+		}
+	}
+	sort.Strings(patch.DeletedFields)
+	return patch
+}
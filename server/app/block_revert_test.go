@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevertBlock(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectSingleWorkspace()
+	th.expectOpenBoards()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows).AnyTimes()
+
+	board := &model.Block{ID: "board-1", RootID: "board-1", Type: "board", Fields: map[string]interface{}{
+		"cardProperties": []interface{}{
+			map[string]interface{}{"id": "status", "type": "select", "options": []interface{}{
+				map[string]interface{}{"id": "todo", "value": "To do"},
+			}},
+			map[string]interface{}{"id": "tags", "type": "multiSelect", "options": []interface{}{
+				map[string]interface{}{"id": "bug", "value": "Bug"},
+			}},
+			map[string]interface{}{"id": "owner", "type": "person"},
+		},
+	}}
+	// the patches modify the returned blocks
+	current := func() *model.Block {
+		return &model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "New", UpdateAt: 3, Fields: map[string]interface{}{
+			"icon":       "y",
+			"extra":      true,
+			"properties": map[string]interface{}{"status": "todo"},
+		}}
+	}
+	version := model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "Old", UpdateAt: 2, Fields: map[string]interface{}{
+		"icon": "x",
+		"properties": map[string]interface{}{
+			"status":  "deleted-option",
+			"tags":    []interface{}{"bug", "deleted-option"},
+			"owner":   "user-1",
+			"deleted": "value",
+		},
+	}}
+
+	t.Run("should apply the version as a patch", func(t *testing.T) {
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1"), gomock.Any()).
+			Return([]model.Block{*current(), version, {ID: "card-1", UpdateAt: 1}}, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).
+			DoAndReturn(func(context.Context, st.Container, string) (*model.Block, error) { return current(), nil }).AnyTimes()
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil).AnyTimes()
+		th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return("board-1", nil)
+
+		title := "Old"
+		th.Store.EXPECT().PatchBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1"), gomock.Eq(&model.BlockPatch{
+			Title: &title,
+			UpdatedFields: map[string]interface{}{
+				"icon":       "x",
+				"properties": map[string]interface{}{"tags": []interface{}{"bug"}, "owner": "user-1"},
+			},
+			DeletedFields: []string{"extra"},
+		}), gomock.Eq("user-id")).Return(nil)
+
+		result, err := th.App.RevertBlock(ctx, container, "card-1", 2, "user-id")
+		require.NoError(t, err)
+		require.Equal(t, "New", result.Before.Title)
+	})
+
+	t.Run("should fail if the version isn't in the history", func(t *testing.T) {
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1"), gomock.Any()).
+			Return([]model.Block{*current(), {ID: "card-1", UpdateAt: 1, DeleteAt: 1}}, nil)
+
+		_, err := th.App.RevertBlock(ctx, container, "card-1", 1, "user-id")
+		require.ErrorIs(t, err, ErrBlockVersionNotFound)
+	})
+
+	t.Run("should fail if the parent of the version was deleted", func(t *testing.T) {
+		moved := version
+		moved.ParentID = "board-2"
+		moved.RootID = "board-2"
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1"), gomock.Any()).
+			Return([]model.Block{*current(), moved}, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-2")).Return(nil, nil)
+
+		_, err := th.App.RevertBlock(ctx, container, "card-1", 2, "user-id")
+		require.ErrorIs(t, err, ErrInvalidRevert)
+	})
+}
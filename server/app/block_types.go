@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// InvalidBlockTypeError is returned when a block violates a rule of its
+// type, one of the model.BlockRule rules.
+type InvalidBlockTypeError struct {
+	BlockID string
+	Type    string
+	Rule    string
+
+	// the type of the parent, or the missing field
+	Detail string
+}
+
+func (e InvalidBlockTypeError) Error() string {
+	switch e.Rule {
+	case model.BlockRuleKnownType:
+		return fmt.Sprintf("block %s has the unknown type %q", e.BlockID, e.Type)
+	case model.BlockRuleRoot:
+		return fmt.Sprintf("block %s of type %s can't have a parent", e.BlockID, e.Type)
+	case model.BlockRuleRequiredField:
+		return fmt.Sprintf("block %s of type %s has no %s field", e.BlockID, e.Type, e.Detail)
+	case model.BlockRuleParentType:
+		if e.Detail == "" {
+			return fmt.Sprintf("block %s of type %s has no parent", e.BlockID, e.Type)
+		}
+		return fmt.Sprintf("block %s of type %s can't be the child of a block of type %s", e.BlockID, e.Type, e.Detail)
+	case model.BlockRuleChildType:
+		return fmt.Sprintf("a block of type %s can't be the parent of block %s of type %s", e.Detail, e.BlockID, e.Type)
+	}
+	return fmt.Sprintf("block %s of type %s violates the %s rule", e.BlockID, e.Type, e.Rule)
+}
+
+// blockValidator checks a block of its type being inserted or patched,
+// and may normalize its fields. getStored returns the stored version of
+// a block, nil if it's new.
+type blockValidator func(a *App, c store.Container, block *model.Block, getStored func(blockID string) (*model.Block, error)) error
+
+// blockValidators are the validators of the block types whose fields are
+// checked beyond the rules of model.BlockTypes. A new block type is
+// checked by adding its validator.
+var blockValidators = map[string]blockValidator{
+	"board":                   (*App).validateBoard,
+	model.AttachmentBlockType: (*App).validateAttachment,
+	model.CodeBlockType:       (*App).validateCodeBlock,
+}
+
+// validateBlockTypes checks the blocks that aren't deleted against the
+// rules of their types: their type is known unless the experimental
+// block types are enabled, they have the required fields, and they are
+// children of blocks of the allowed types, among the blocks or read from
+// the given store. The blocks whose parent isn't found aren't checked
+// against it.
+func (a *App) validateBlockTypes(ctx context.Context, st store.Store, c store.Container, blocks []model.Block) error {
+	byID := map[string]*model.Block{}
+	for i := range blocks {
+		byID[blocks[i].ID] = &blocks[i]
+	}
+
+	for _, block := range blocks {
+		if block.DeleteAt != 0 {
+			continue
+		}
+		rules, ok := model.BlockTypes[block.Type]
+		if !ok {
+			if a.config.EnableExperimentalBlockTypes && block.Type != "" {
+				continue
+			}
+			return InvalidBlockTypeError{BlockID: block.ID, Type: block.Type, Rule: model.BlockRuleKnownType}
+		}
+
+		for _, field := range rules.RequiredFields {
+			if value, ok := block.Fields[field]; !ok || value == nil || value == "" {
+				return InvalidBlockTypeError{BlockID: block.ID, Type: block.Type, Rule: model.BlockRuleRequiredField, Detail: field}
+			}
+		}
+
+		if len(rules.ParentTypes) == 0 {
+			if block.ParentID != "" {
+				return InvalidBlockTypeError{BlockID: block.ID, Type: block.Type, Rule: model.BlockRuleRoot}
+			}
+			continue
+		}
+		if block.ParentID == "" {
+			return InvalidBlockTypeError{BlockID: block.ID, Type: block.Type, Rule: model.BlockRuleParentType}
+		}
+		parent, ok := byID[block.ParentID]
+		if !ok {
+			stored, err := st.GetBlock(ctx, c, block.ParentID)
+			if err != nil {
+				return err
+			}
+			parent = stored
+		}
+		if parent == nil {
+			continue
+		}
+		if !rules.IsParentType(parent.Type) {
+			return InvalidBlockTypeError{BlockID: block.ID, Type: block.Type, Rule: model.BlockRuleParentType, Detail: parent.Type}
+		}
+		if parentRules, ok := model.BlockTypes[parent.Type]; ok && !parentRules.IsChildType(block.Type) {
+			return InvalidBlockTypeError{BlockID: block.ID, Type: block.Type, Rule: model.BlockRuleChildType, Detail: parent.Type}
+		}
+	}
+	return nil
+}
+
+// validateBlockFields checks the blocks that aren't deleted with the
+// validators of their types.
+func (a *App) validateBlockFields(c store.Container, blocks []model.Block, getStored func(blockID string) (*model.Block, error)) error {
+	for i := range blocks {
+		validate, ok := blockValidators[blocks[i].Type]
+		if !ok || blocks[i].DeleteAt != 0 {
+			continue
+		}
+		if err := validate(a, c, &blocks[i], getStored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
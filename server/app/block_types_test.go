@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockTypes(t *testing.T) {
+	// the children of each type are the blocks it's a parent type of
+	for blockType, rules := range model.BlockTypes {
+		for _, parentType := range rules.ParentTypes {
+			require.True(t, model.BlockTypes[parentType].IsChildType(blockType), "%s children of %s", blockType, parentType)
+		}
+		for _, childType := range rules.ChildTypes {
+			require.True(t, model.BlockTypes[childType].IsParentType(blockType), "%s parent of %s", blockType, childType)
+		}
+	}
+}
+
+func TestValidateBlockTypes(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	board := model.Block{ID: "board-1", RootID: "board-1", Type: "board"}
+	card := model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"}
+
+	t.Run("should accept the blocks of a board", func(t *testing.T) {
+		blocks := []model.Block{
+			board,
+			card,
+			{ID: "view-1", ParentID: "board-1", RootID: "board-1", Type: "view"},
+			{ID: "text-1", ParentID: "card-1", RootID: "board-1", Type: "text"},
+			{ID: "image-1", ParentID: "card-1", RootID: "board-1", Type: "image", Fields: map[string]interface{}{"fileId": "file.png"}},
+			{ID: "comment-1", ParentID: "card-1", RootID: "board-1", Type: "comment"},
+		}
+		require.NoError(t, th.App.validateBlockTypes(ctx, th.Store, container, blocks))
+	})
+
+	t.Run("should check the stored parents", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(&card, nil)
+		blocks := []model.Block{{ID: "checkbox-1", ParentID: "card-1", RootID: "board-1", Type: "checkbox"}}
+		require.NoError(t, th.App.validateBlockTypes(ctx, th.Store, container, blocks))
+
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(&board, nil)
+		blocks = []model.Block{{ID: "comment-1", ParentID: "board-1", RootID: "board-1", Type: "comment"}}
+		err := th.App.validateBlockTypes(ctx, th.Store, container, blocks)
+		require.Equal(t, InvalidBlockTypeError{BlockID: "comment-1", Type: "comment", Rule: model.BlockRuleParentType, Detail: "board"}, err)
+	})
+
+	t.Run("should reject the blocks violating the rules of their type", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			block    model.Block
+			expected InvalidBlockTypeError
+		}{
+			{
+				"view of a card",
+				model.Block{ID: "view-1", ParentID: "card-1", Type: "view"},
+				InvalidBlockTypeError{BlockID: "view-1", Type: "view", Rule: model.BlockRuleParentType, Detail: "card"},
+			},
+			{
+				"card without a parent",
+				model.Block{ID: "card-2", Type: "card"},
+				InvalidBlockTypeError{BlockID: "card-2", Type: "card", Rule: model.BlockRuleParentType},
+			},
+			{
+				"board with a parent",
+				model.Block{ID: "board-2", ParentID: "board-1", Type: "board"},
+				InvalidBlockTypeError{BlockID: "board-2", Type: "board", Rule: model.BlockRuleRoot},
+			},
+			{
+				"image without a file",
+				model.Block{ID: "image-1", ParentID: "card-1", Type: "image", Fields: map[string]interface{}{"fileId": ""}},
+				InvalidBlockTypeError{BlockID: "image-1", Type: "image", Rule: model.BlockRuleRequiredField, Detail: "fileId"},
+			},
+			{
+				"unknown type",
+				model.Block{ID: "poll-1", ParentID: "card-1", Type: "poll"},
+				InvalidBlockTypeError{BlockID: "poll-1", Type: "poll", Rule: model.BlockRuleKnownType},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				err := th.App.validateBlockTypes(ctx, th.Store, container, []model.Block{board, card, tc.block})
+				require.Equal(t, tc.expected, err)
+			})
+		}
+	})
+
+	t.Run("should accept experimental types when enabled", func(t *testing.T) {
+		th.App.config.EnableExperimentalBlockTypes = true
+		defer func() { th.App.config.EnableExperimentalBlockTypes = false }()
+
+		blocks := []model.Block{board, card, {ID: "poll-1", ParentID: "card-1", RootID: "board-1", Type: "poll"}}
+		require.NoError(t, th.App.validateBlockTypes(ctx, th.Store, container, blocks))
+
+		err := th.App.validateBlockTypes(ctx, th.Store, container, []model.Block{{ID: "block-1"}})
+		require.Equal(t, InvalidBlockTypeError{BlockID: "block-1", Rule: model.BlockRuleKnownType}, err)
+	})
+
+	t.Run("should not check the deleted blocks", func(t *testing.T) {
+		blocks := []model.Block{{ID: "poll-1", ParentID: "card-1", Type: "poll", DeleteAt: 1}}
+		require.NoError(t, th.App.validateBlockTypes(ctx, th.Store, container, blocks))
+	})
+}
@@ -1,101 +1,551 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/store"
 )
 
-func (a *App) GetBlocks(c store.Container, parentID string, blockType string) ([]model.Block, error) {
+// ErrBlockLocked is returned when patching a block whose editing lock is
+// held by another user.
+var ErrBlockLocked = errors.New("the block is locked by another user")
+
+// ErrInvalidBlocksPatch is returned when patching several blocks at once
+// and one of them can't be patched, in which case none is.
+var ErrInvalidBlocksPatch = errors.New("invalid blocks patch")
+
+// BlockVersionConflictError is returned when patching a block that was
+// modified since the version expected by the patch. Block is the stored
+// block, for the client to merge its changes with.
+type BlockVersionConflictError struct {
+	Block *model.Block
+}
+
+func (e BlockVersionConflictError) Error() string {
+	return fmt.Sprintf("block %s was modified, its version is %d", e.Block.ID, e.Block.Version)
+}
+
+func (e BlockVersionConflictError) Unwrap() error {
+	return model.ErrBlockVersionConflict
+}
+
+func (a *App) GetBlocks(ctx context.Context, c store.Container, parentID string, blockType string) ([]model.Block, error) {
 	if blockType != "" && parentID != "" {
-		return a.store.GetBlocksWithParentAndType(c, parentID, blockType)
+		return a.store.GetBlocksWithParentAndType(ctx, c, parentID, blockType)
 	}
 
 	if blockType != "" {
-		return a.store.GetBlocksWithType(c, blockType)
+		return a.store.GetBlocksWithType(ctx, c, blockType)
 	}
 
-	return a.store.GetBlocksWithParent(c, parentID)
+	return a.store.GetBlocksWithParent(ctx, c, parentID)
 }
 
-func (a *App) GetBlocksWithRootID(c store.Container, rootID string) ([]model.Block, error) {
-	return a.store.GetBlocksWithRootID(c, rootID)
+func (a *App) GetBlocksPage(ctx context.Context, c store.Container, opts model.QueryBlocksPageOptions) (*model.BlocksPage, error) {
+	return a.store.GetBlocksPage(ctx, c, opts)
 }
 
-func (a *App) GetRootID(c store.Container, blockID string) (string, error) {
-	return a.store.GetRootID(c, blockID)
+func (a *App) GetBlocksDigest(ctx context.Context, c store.Container, opts model.QueryBlocksDigestOptions) (*model.BlocksDigest, error) {
+	return a.store.GetBlocksDigest(ctx, c, opts)
 }
 
-func (a *App) GetParentID(c store.Container, blockID string) (string, error) {
-	return a.store.GetParentID(c, blockID)
+func (a *App) GetBlocksWithRootID(ctx context.Context, c store.Container, rootID string) ([]model.Block, error) {
+	return a.store.GetBlocksWithRootID(ctx, c, rootID)
 }
 
-func (a *App) PatchBlock(c store.Container, blockID string, blockPatch *model.BlockPatch, userID string) error {
-	err := a.store.PatchBlock(c, blockID, blockPatch, userID)
+func (a *App) GetRootID(ctx context.Context, c store.Container, blockID string) (string, error) {
+	return a.store.GetRootID(ctx, c, blockID)
+}
+
+func (a *App) GetParentID(ctx context.Context, c store.Container, blockID string) (string, error) {
+	return a.store.GetParentID(ctx, c, blockID)
+}
+
+// PatchBlock applies the patch to the block, and returns the block before
+// and after the patch so that the clients can undo it. Unless force is
+// set, it fails with ErrBlockLocked if another user holds the editing
+// lock of the block. If the patch has an expected version, it fails with
+// a BlockVersionConflictError holding the stored block if the block has
+// another version.
+func (a *App) PatchBlock(ctx context.Context, c store.Container, blockID string, blockPatch *model.BlockPatch, userID string, force bool) (*model.BlockPatchResult, error) {
+	if holder := a.wsAdapter.GetBlockLockHolder(c.WorkspaceID, blockID); !force && holder != "" && holder != userID {
+		return nil, ErrBlockLocked
+	}
+
+	roles, err := a.getUserBoardRoles(c, userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if roles.restricted() {
+		rootID, err := a.store.GetRootID(ctx, c, blockID)
+		if err != nil {
+			return nil, err
+		}
+		boardIDs := []string{rootID}
+		if blockPatch.RootID != nil {
+			boardIDs = append(boardIDs, *blockPatch.RootID)
+		}
+		if err := roles.check(model.BoardRoleEditor, boardIDs...); err != nil {
+			return nil, err
+		}
+	}
+
+	wasArchived, err := a.checkPatchNotArchived(ctx, c, blockID, blockPatch)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := a.store.GetBlock(ctx, c, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if before != nil && blockPatch.ExpectedVersion != nil && *blockPatch.ExpectedVersion != before.Version {
+		return nil, BlockVersionConflictError{Block: before}
+	}
+	if before != nil && (patchesRelations(blockPatch) || blockPatch.ParentID != nil || len(blockPatch.UpdatedFields) > 0 || len(blockPatch.DeletedFields) > 0) {
+		stored := copyBlockFields(*before)
+		patched := copyBlockFields(*before)
+		getStored := func(string) (*model.Block, error) { return &stored, nil }
+		validated := blockPatch.Patch(&patched)
+		if err := a.validateBlocks(ctx, a.store, c, []model.Block{*validated}, getStored); err != nil {
+			return nil, err
+		}
+		// the validators of the content blocks may normalize their fields
+		for key := range blockPatch.UpdatedFields {
+			blockPatch.UpdatedFields[key] = validated.Fields[key]
+		}
+	}
+
+	webhooks := a.workspaceWebhooks(ctx, c.WorkspaceID)
+
+	err = a.store.PatchBlock(ctx, c, blockID, blockPatch, userID)
+	if errors.Is(err, model.ErrBlockVersionConflict) {
+		// the block was modified after it was read
+		current, getErr := a.store.GetBlock(ctx, c, blockID)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if current != nil {
+			return nil, BlockVersionConflictError{Block: current}
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 	a.metrics.IncrementBlocksPatched(1)
-	block, err := a.store.GetBlock(c, blockID)
+	block, err := a.store.GetBlock(ctx, c, blockID)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 	a.wsAdapter.BroadcastBlockChange(c.WorkspaceID, *block)
+	if block.Type == "board" && isArchived(*block) && !wasArchived {
+		a.wsAdapter.BroadcastBoardArchived(c.WorkspaceID, block.ID)
+	}
+	a.broadcastChecklistProgress(ctx, c, []model.Block{*block})
 	go a.webhook.NotifyUpdate(*block)
-	return nil
+	a.notifyBlockChanged(c, webhooks, before, block, userID)
+	if hasMentions(block) {
+		// the request context is done once the response is written
+		go a.notifyMentions(context.Background(), c, *block, userID)
+	}
+	a.notifySubscribers(ctx, c, []model.Block{*block}, userID)
+	return &model.BlockPatchResult{Before: before, After: block}, nil
+}
+
+// PatchBlocks applies the same patch to several cards in a single
+// transaction, and broadcasts their changes in a single message. It
+// fails with ErrInvalidBlocksPatch, patching none of the cards, if one
+// of the blocks isn't a card of the workspace, and, unless force is
+// set, with ErrBlockLocked if another user holds the editing lock of
+// one of them. The patched cards are returned in the order of the IDs.
+func (a *App) PatchBlocks(ctx context.Context, c store.Container, blocksPatch *model.BlocksPatch, userID string, force bool) ([]model.Block, error) {
+	blockIDs := blocksPatch.BlockIDs
+	if len(blockIDs) == 0 || len(blockIDs) > model.BlocksPatchMaxBlocks {
+		return nil, fmt.Errorf("%w: between 1 and %d blocks can be patched", ErrInvalidBlocksPatch, model.BlocksPatchMaxBlocks)
+	}
+	if blocksPatch.Patch.Type != nil && *blocksPatch.Patch.Type != "card" {
+		return nil, fmt.Errorf("%w: the cards can't change type", ErrInvalidBlocksPatch)
+	}
+
+	seen := map[string]bool{}
+	for _, blockID := range blockIDs {
+		if seen[blockID] {
+			return nil, fmt.Errorf("%w: block %s is repeated", ErrInvalidBlocksPatch, blockID)
+		}
+		seen[blockID] = true
+
+		if holder := a.wsAdapter.GetBlockLockHolder(c.WorkspaceID, blockID); !force && holder != "" && holder != userID {
+			return nil, ErrBlockLocked
+		}
+	}
+
+	roles, err := a.getUserBoardRoles(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := a.workspaceWebhooks(ctx, c.WorkspaceID)
+	before := map[string]*model.Block{}
+
+	tx, err := a.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer a.rollbackTx(tx)
+
+	blocks := make([]model.Block, 0, len(blockIDs))
+	boardIDs := make([]string, 0, 2*len(blockIDs))
+	for _, blockID := range blockIDs {
+		block, err := tx.GetBlock(ctx, c, blockID)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil || block.Type != "card" {
+			return nil, fmt.Errorf("%w: block %s isn't a card of the workspace", ErrInvalidBlocksPatch, blockID)
+		}
+
+		previous := copyBlockFields(*block)
+		before[blockID] = &previous
+		boardID := block.BoardID()
+		if block.Fields == nil {
+			block.Fields = map[string]interface{}{}
+		}
+		patched := *blocksPatch.Patch.Patch(block)
+		if err := roles.check(model.BoardRoleEditor, boardID, patched.BoardID()); err != nil {
+			return nil, err
+		}
+		boardIDs = append(boardIDs, boardID, patched.BoardID())
+		blocks = append(blocks, patched)
+	}
+	if err := a.checkBoardsNotArchived(ctx, tx, c, boardIDs...); err != nil {
+		return nil, err
+	}
+
+	if blocksPatch.Patch.ParentID != nil || blocksPatch.Patch.RootID != nil {
+		if err := a.validateBlockWorkspaces(ctx, tx, c, blocks); err != nil {
+			return nil, err
+		}
+	}
+	if blocksPatch.Patch.ParentID != nil {
+		if err := a.validateBlockTypes(ctx, tx, c, blocks); err != nil {
+			return nil, err
+		}
+	}
+	if patchesRelations(&blocksPatch.Patch) {
+		if err := a.validateRelations(ctx, tx, c, blocks); err != nil {
+			return nil, err
+		}
+		getStored := func(blockID string) (*model.Block, error) { return before[blockID], nil }
+		if err := a.validateCardProperties(ctx, tx, c, blocks, getStored); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.InsertBlocks(ctx, c, blocks, userID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	a.metrics.IncrementBlocksPatched(len(blocks))
+	a.wsAdapter.BroadcastBlockChanges(c.WorkspaceID, blocks)
+	a.notifyBlocksChanged(ctx, c, webhooks, before, blocks, userID)
+	return blocks, nil
+}
+
+// validateBlocks checks that the parents and roots of the blocks are in
+// their workspace, the blocks against the rules of their types, the
+// references of the cards and recurrences among the blocks, the computed
+// properties of the boards, the fields of the blocks of the types with a
+// validator, and the properties of the cards changed from the versions
+// returned by getStored. The referenced blocks are either among
+// the blocks or read from the given store, which can be a transaction.
+func (a *App) validateBlocks(ctx context.Context, st store.Store, c store.Container, blocks []model.Block, getStored func(blockID string) (*model.Block, error)) error {
+	if err := a.validateBlockWorkspaces(ctx, st, c, blocks); err != nil {
+		return err
+	}
+	if err := a.validateBlockTypes(ctx, st, c, blocks); err != nil {
+		return err
+	}
+	if err := a.validateRelations(ctx, st, c, blocks); err != nil {
+		return err
+	}
+	if err := a.validateRecurrences(ctx, st, c, blocks); err != nil {
+		return err
+	}
+	if err := validateComputedProperties(blocks); err != nil {
+		return err
+	}
+	if err := a.validateBlockFields(c, blocks, getStored); err != nil {
+		return err
+	}
+	return a.validateCardProperties(ctx, st, c, blocks, getStored)
+}
+
+// storedBlockGetter returns a function returning the stored version of a
+// block, before the blocks being inserted replace it.
+func storedBlockGetter(ctx context.Context, st store.Store, c store.Container) func(blockID string) (*model.Block, error) {
+	return func(blockID string) (*model.Block, error) {
+		return st.GetBlock(ctx, c, blockID)
+	}
 }
 
-func (a *App) InsertBlock(c store.Container, block model.Block, userID string) error {
-	err := a.store.InsertBlock(c, &block, userID)
+func (a *App) InsertBlock(ctx context.Context, c store.Container, block model.Block, userID string) error {
+	if err := a.checkBlocksWritable(c, userID, []model.Block{block}); err != nil {
+		return err
+	}
+	if err := a.checkBlocksNotArchived(ctx, c, []model.Block{block}); err != nil {
+		return err
+	}
+	if err := a.validateBlocks(ctx, a.store, c, []model.Block{block}, storedBlockGetter(ctx, a.store, c)); err != nil {
+		return err
+	}
+
+	err := a.store.InsertBlock(ctx, c, &block, userID)
 	if err == nil {
 		a.metrics.IncrementBlocksInserted(1)
 	}
 	return err
 }
 
-func (a *App) InsertBlocks(c store.Container, blocks []model.Block, userID string) error {
-	for i := range blocks {
-		err := a.store.InsertBlock(c, &blocks[i], userID)
-		if err != nil {
-			return err
+func (a *App) InsertBlocks(ctx context.Context, c store.Container, blocks []model.Block, userID string) (*model.BlocksUpsertResult, error) {
+	if err := a.checkBlocksWritable(c, userID, blocks); err != nil {
+		return nil, err
+	}
+	if err := a.checkBlocksNotArchived(ctx, c, blocks); err != nil {
+		return nil, err
+	}
+	if err := a.validateBlocks(ctx, a.store, c, blocks, storedBlockGetter(ctx, a.store, c)); err != nil {
+		return nil, err
+	}
+	if err := a.checkBlockQuota(ctx, c, blocks); err != nil {
+		return nil, err
+	}
+
+	webhooks := a.workspaceWebhooks(ctx, c.WorkspaceID)
+	before := map[string]*model.Block{}
+	if len(webhooks) > 0 {
+		for i := range blocks {
+			block, err := a.store.GetBlock(ctx, c, blocks[i].ID)
+			if err != nil {
+				return nil, err
+			}
+			before[blocks[i].ID] = block
 		}
+	}
+
+	result, err := a.store.InsertBlocks(ctx, c, blocks, userID)
+	if err != nil {
+		return nil, err
+	}
+	a.addWorkspaceUsage(c.WorkspaceID, int64(len(result.Inserted)), 0)
+
+	a.blocksInserted(ctx, c, webhooks, before, blocks, userID)
+	a.broadcastChecklistProgress(ctx, c, blocks)
+	return result, nil
+}
 
+// blocksInserted broadcasts the inserted blocks and notifies the
+// webhooks, the mentioned users and the subscribers. The blocks before
+// the insert are keyed by ID, and are only needed by the webhooks.
+func (a *App) blocksInserted(ctx context.Context, c store.Container, webhooks []model.WorkspaceWebhook, before map[string]*model.Block, blocks []model.Block, userID string) {
+	a.metrics.IncrementBlocksInserted(len(blocks))
+	for i := range blocks {
 		a.wsAdapter.BroadcastBlockChange(c.WorkspaceID, blocks[i])
-		a.metrics.IncrementBlocksInserted(len(blocks))
-		go a.webhook.NotifyUpdate(blocks[i])
 	}
+	a.notifyBlocksChanged(ctx, c, webhooks, before, blocks, userID)
+}
 
-	return nil
+// notifyBlocksChanged notifies the webhooks, the mentioned users and the
+// subscribers of the changed blocks.
+func (a *App) notifyBlocksChanged(ctx context.Context, c store.Container, webhooks []model.WorkspaceWebhook, before map[string]*model.Block, blocks []model.Block, userID string) {
+	for i := range blocks {
+		go a.webhook.NotifyUpdate(blocks[i])
+		a.notifyBlockChanged(c, webhooks, before[blocks[i].ID], &blocks[i], userID)
+		if hasMentions(&blocks[i]) {
+			go a.notifyMentions(context.Background(), c, blocks[i], userID)
+		}
+	}
+	a.notifySubscribers(ctx, c, blocks, userID)
 }
 
-func (a *App) GetSubTree(c store.Container, blockID string, levels int) ([]model.Block, error) {
+func (a *App) GetSubTree(ctx context.Context, c store.Container, blockID string, levels int) ([]model.Block, error) {
 	// Only 2 or 3 levels are supported for now
 	if levels >= 3 {
-		return a.store.GetSubTree3(c, blockID)
+		return a.store.GetSubTree3(ctx, c, blockID)
 	}
-	return a.store.GetSubTree2(c, blockID)
+	return a.store.GetSubTree2(ctx, c, blockID)
 }
 
-func (a *App) GetAllBlocks(c store.Container) ([]model.Block, error) {
-	return a.store.GetAllBlocks(c)
+func (a *App) GetAllBlocks(ctx context.Context, c store.Container) ([]model.Block, error) {
+	return a.store.GetAllBlocks(ctx, c)
 }
 
-func (a *App) DeleteBlock(c store.Container, blockID string, modifiedBy string) error {
-	parentID, err := a.GetParentID(c, blockID)
+func (a *App) DeleteBlock(ctx context.Context, c store.Container, blockID string, modifiedBy string) error {
+	if err := a.checkBlockWorkspace(ctx, c, blockID); err != nil {
+		return err
+	}
+	parentID, err := a.GetParentID(ctx, c, blockID)
 	if err != nil {
 		return err
 	}
 
-	err = a.store.DeleteBlock(c, blockID, modifiedBy)
+	webhooks := a.workspaceWebhooks(ctx, c.WorkspaceID)
+	before, err := a.store.GetBlock(ctx, c, blockID)
 	if err != nil {
 		return err
 	}
+	if before != nil {
+		if err := a.checkBlocksWritable(c, modifiedBy, []model.Block{*before}); err != nil {
+			return err
+		}
+		if err := a.checkBlocksNotArchived(ctx, c, []model.Block{*before}); err != nil {
+			return err
+		}
+	}
 
-	a.wsAdapter.BroadcastBlockDelete(c.WorkspaceID, blockID, parentID)
+	var unlinked []model.Block
+	if before != nil && before.Type == "card" {
+		unlinked, err = a.deleteCard(ctx, c, blockID, modifiedBy)
+	} else {
+		err = a.store.DeleteBlock(ctx, c, blockID, modifiedBy)
+	}
+	if err != nil {
+		return err
+	}
+	// the deleted children aren't known, so the blocks are counted again
+	a.forgetWorkspaceUsage(c.WorkspaceID)
+	a.wsAdapter.BroadcastBlockChanges(c.WorkspaceID, unlinked)
+
+	rootID := ""
+	if before != nil {
+		rootID = before.RootID
+	}
+	a.wsAdapter.BroadcastBlockDelete(c.WorkspaceID, blockID, parentID, rootID)
+	if before != nil {
+		a.broadcastChecklistProgress(ctx, c, []model.Block{*before})
+	}
 	a.metrics.IncrementBlocksDeleted(1)
+	payload := map[string]interface{}{"parentId": parentID}
+	if before != nil {
+		payload["type"] = before.Type
+		payload["title"] = before.Title
+		payload["rootId"] = before.RootID
+	}
+	a.recordAuditEntry(model.AuditActionDeleteBlock, modifiedBy, c.WorkspaceID, blockID, payload)
+	if before != nil && len(webhooks) > 0 {
+		a.notifyBlockChanged(c, webhooks, before, nil, modifiedBy)
+	}
 
 	return nil
 }
 
-func (a *App) GetBlockCountsByType() (map[string]int64, error) {
-	return a.store.GetBlockCountsByType()
+// deleteCard deletes the card, and removes its ID from the relation
+// properties of the cards that link to it in the same transaction. It
+// returns the cards it was removed from.
+func (a *App) deleteCard(ctx context.Context, c store.Container, cardID, modifiedBy string) ([]model.Block, error) {
+	tx, err := a.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer a.rollbackTx(tx)
+
+	if err := tx.DeleteBlock(ctx, c, cardID, modifiedBy); err != nil {
+		return nil, err
+	}
+	unlinked, err := a.unlinkCard(ctx, tx, c, cardID, modifiedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return unlinked, nil
+}
+
+func (a *App) UndeleteBlock(ctx context.Context, c store.Container, blockID string, modifiedBy string) (*model.Block, error) {
+	roles, err := a.getUserBoardRoles(c, modifiedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// the deleted block is only found in its history
+	history, err := a.store.GetBlockHistory(ctx, c, blockID, model.QueryBlockHistoryOptions{Limit: 1, Descending: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(history) > 0 {
+		if err := roles.check(model.BoardRoleEditor, history[0].BoardID()); err != nil {
+			return nil, err
+		}
+		if err := a.checkBlocksNotArchived(ctx, c, history[:1]); err != nil {
+			return nil, err
+		}
+	}
+
+	err = a.store.RestoreBlock(ctx, c, blockID, modifiedBy)
+	if err != nil {
+		return nil, err
+	}
+	a.forgetWorkspaceUsage(c.WorkspaceID)
+
+	block, err := a.store.GetBlock(ctx, c, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+
+	a.wsAdapter.BroadcastBlockChange(c.WorkspaceID, *block)
+	a.broadcastChecklistProgress(ctx, c, []model.Block{*block})
+	go a.webhook.NotifyUpdate(*block)
+	a.notifyBlockChanged(c, a.workspaceWebhooks(ctx, c.WorkspaceID), nil, block, modifiedBy)
+
+	return block, nil
+}
+
+func (a *App) GetDeletedBlocks(ctx context.Context, c store.Container, since int64) ([]model.Block, error) {
+	return a.store.GetDeletedBlocks(ctx, c, since)
+}
+
+func (a *App) GetBlockHistory(ctx context.Context, c store.Container, blockID string, opts model.QueryBlockHistoryOptions) ([]model.Block, error) {
+	return a.store.GetBlockHistory(ctx, c, blockID, opts)
+}
+
+// GetCardByNumber returns the card of the board with the number, or nil
+// if there is none.
+func (a *App) GetCardByNumber(ctx context.Context, c store.Container, boardID string, number int64) (*model.Block, error) {
+	return a.store.GetCardByNumber(ctx, c, boardID, number)
+}
+
+// GetCard returns the card with the ID, or nil if the block doesn't exist
+// or isn't a card.
+func (a *App) GetCard(ctx context.Context, c store.Container, cardID string) (*model.Block, error) {
+	return a.getBlockWithType(ctx, c, cardID, "card")
+}
+
+// GetBoard returns the board with the ID, or nil if the block doesn't
+// exist or isn't a board.
+func (a *App) GetBoard(ctx context.Context, c store.Container, boardID string) (*model.Block, error) {
+	return a.getBlockWithType(ctx, c, boardID, "board")
+}
+
+func (a *App) getBlockWithType(ctx context.Context, c store.Container, blockID, blockType string) (*model.Block, error) {
+	block, err := a.store.GetBlock(ctx, c, blockID)
+	if err != nil || block == nil || block.Type != blockType {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (a *App) SearchBlocks(ctx context.Context, c store.Container, query string, limit int) ([]model.BlockSearchResult, error) {
+	return a.store.SearchBlocks(ctx, c, query, limit)
+}
+
+func (a *App) GetBlockCountsByType(ctx context.Context) (map[string]int64, error) {
+	return a.store.GetBlockCountsByType(ctx)
 }
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/mattermost/focalboard/server/model"
@@ -19,6 +21,7 @@ func (be blockError) Error() string {
 }
 
 func TestGetParentID(t *testing.T) {
+	ctx := context.Background()
 	th, tearDown := SetupTestHelper(t)
 	defer tearDown()
 
@@ -26,39 +29,263 @@ func TestGetParentID(t *testing.T) {
 		WorkspaceID: "0",
 	}
 	t.Run("success query", func(t *testing.T) {
-		th.Store.EXPECT().GetParentID(gomock.Eq(container), gomock.Eq("test-id")).Return("test-parent-id", nil)
-		result, err := th.App.GetParentID(container, "test-id")
+		th.Store.EXPECT().GetParentID(gomock.Any(), gomock.Eq(container), gomock.Eq("test-id")).Return("test-parent-id", nil)
+		result, err := th.App.GetParentID(ctx, container, "test-id")
 		require.NoError(t, err)
 		require.Equal(t, "test-parent-id", result)
 	})
 
 	t.Run("fail query", func(t *testing.T) {
-		th.Store.EXPECT().GetParentID(gomock.Eq(container), gomock.Eq("test-id")).Return("", blockError{"block-not-found"})
-		_, err := th.App.GetParentID(container, "test-id")
+		th.Store.EXPECT().GetParentID(gomock.Any(), gomock.Eq(container), gomock.Eq("test-id")).Return("", blockError{"block-not-found"})
+		_, err := th.App.GetParentID(ctx, container, "test-id")
 		require.Error(t, err)
 		require.ErrorIs(t, err, blockError{"block-not-found"})
 	})
 }
 
 func TestInsertBlock(t *testing.T) {
+	ctx := context.Background()
 	th, tearDown := SetupTestHelper(t)
 	defer tearDown()
+	th.expectOpenBoards()
 
 	container := st.Container{
 		WorkspaceID: "0",
 	}
 
 	t.Run("success scenerio", func(t *testing.T) {
-		block := model.Block{}
-		th.Store.EXPECT().InsertBlock(gomock.Eq(container), gomock.Eq(&block), gomock.Eq("user-id-1")).Return(nil)
-		err := th.App.InsertBlock(container, block, "user-id-1")
+		block := model.Block{Type: "board"}
+		th.Store.EXPECT().InsertBlock(gomock.Any(), gomock.Eq(container), gomock.Eq(&block), gomock.Eq("user-id-1")).Return(nil)
+		err := th.App.InsertBlock(ctx, container, block, "user-id-1")
 		require.NoError(t, err)
 	})
 
 	t.Run("error scenerio", func(t *testing.T) {
-		block := model.Block{}
-		th.Store.EXPECT().InsertBlock(gomock.Eq(container), gomock.Eq(&block), gomock.Eq("user-id-1")).Return(blockError{"error"})
-		err := th.App.InsertBlock(container, block, "user-id-1")
+		block := model.Block{Type: "board"}
+		th.Store.EXPECT().InsertBlock(gomock.Any(), gomock.Eq(container), gomock.Eq(&block), gomock.Eq("user-id-1")).Return(blockError{"error"})
+		err := th.App.InsertBlock(ctx, container, block, "user-id-1")
 		require.Error(t, err, "error")
 	})
 }
+
+func TestInsertBlocks(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectOpenBoards()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("success scenario", func(t *testing.T) {
+		blocks := []model.Block{{ID: "block-1", Type: "board"}, {ID: "block-2", Type: "board"}}
+		want := &model.BlocksUpsertResult{Inserted: []string{"block-2"}, Updated: []string{"block-1"}}
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-1")).Return(&model.Block{ID: "block-1"}, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-2")).Return(nil, nil)
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Eq(blocks), gomock.Eq("user-id-1")).Return(want, nil)
+
+		result, err := th.App.InsertBlocks(ctx, container, blocks, "user-id-1")
+		require.NoError(t, err)
+		require.Equal(t, want, result)
+	})
+
+	t.Run("error scenario", func(t *testing.T) {
+		blocks := []model.Block{{ID: "block-1", Type: "board"}}
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-1")).Return(&model.Block{ID: "block-1"}, nil)
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Eq(blocks), gomock.Eq("user-id-1")).Return(nil, blockError{"error"})
+
+		result, err := th.App.InsertBlocks(ctx, container, blocks, "user-id-1")
+		require.Error(t, err)
+		require.Nil(t, result)
+	})
+}
+
+func TestPatchBlockVersion(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectOpenBoards()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	board := func(version int64) *model.Block {
+		return &model.Block{ID: "board-1", RootID: "board-1", Type: "board", Version: version}
+	}
+	th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows).AnyTimes()
+	th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return("board-1", nil).AnyTimes()
+
+	t.Run("should reject another version without patching the block", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board(2), nil).Times(2)
+
+		title := "Renamed"
+		version := int64(1)
+		_, err := th.App.PatchBlock(ctx, container, "board-1", &model.BlockPatch{Title: &title, ExpectedVersion: &version}, "user-id", false)
+		var conflictErr BlockVersionConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.ErrorIs(t, err, model.ErrBlockVersionConflict)
+		require.Equal(t, int64(2), conflictErr.Block.Version)
+	})
+
+	t.Run("should return the stored block if it was modified concurrently", func(t *testing.T) {
+		title := "Renamed"
+		version := int64(2)
+		patch := &model.BlockPatch{Title: &title, ExpectedVersion: &version}
+		gomock.InOrder(
+			th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board(2), nil).Times(2),
+			th.Store.EXPECT().PatchBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq(patch), gomock.Eq("user-id")).Return(model.ErrBlockVersionConflict),
+			th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board(3), nil),
+		)
+
+		_, err := th.App.PatchBlock(ctx, container, "board-1", patch, "user-id", false)
+		var conflictErr BlockVersionConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		require.Equal(t, int64(3), conflictErr.Block.Version)
+	})
+}
+
+func TestPatchBlocks(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectOpenBoards()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	card := func(id string) *model.Block {
+		return &model.Block{
+			ID:     id,
+			RootID: "board-1",
+			Type:   "card",
+			Fields: map[string]interface{}{
+				"icon":       "x",
+				"properties": map[string]interface{}{"status": "todo", "owner": "user-1"},
+			},
+		}
+	}
+	patch := model.BlockPatch{
+		UpdatedFields:     map[string]interface{}{"icon": "y"},
+		UpdatedProperties: map[string]interface{}{"status": "done"},
+	}
+
+	t.Run("should patch the cards in one transaction", func(t *testing.T) {
+		tx := th.expectTx()
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(card("card-1"), nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-2")).Return(card("card-2"), nil)
+		// read by the archive check and the validations of the relations and of the properties
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(&model.Block{ID: "board-1", Type: "board"}, nil).Times(3)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-id-1")).Return(&model.BlocksUpsertResult{}, nil)
+
+		blocks, err := th.App.PatchBlocks(ctx, container, &model.BlocksPatch{BlockIDs: []string{"card-2", "card-1"}, Patch: patch}, "user-id-1", false)
+		require.NoError(t, err)
+		require.True(t, tx.committed)
+		require.Len(t, blocks, 2)
+		require.Equal(t, "card-2", blocks[0].ID)
+		require.Equal(t, "card-1", blocks[1].ID)
+		for _, block := range blocks {
+			require.Equal(t, "y", block.Fields["icon"])
+			require.Equal(t, map[string]interface{}{"status": "done", "owner": "user-1"}, block.Fields["properties"])
+		}
+	})
+
+	t.Run("should patch none of the cards if a block isn't a card", func(t *testing.T) {
+		tx := th.expectTx()
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(card("card-1"), nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(&model.Block{ID: "board-1", Type: "board"}, nil)
+
+		_, err := th.App.PatchBlocks(ctx, container, &model.BlocksPatch{BlockIDs: []string{"card-1", "board-1"}, Patch: patch}, "user-id-1", false)
+		require.ErrorIs(t, err, ErrInvalidBlocksPatch)
+		require.False(t, tx.committed)
+		require.True(t, tx.rolledBack)
+	})
+
+	t.Run("should patch none of the cards if a patched property is invalid", func(t *testing.T) {
+		tx := th.expectTx()
+		board := &model.Block{ID: "board-1", Type: "board", Fields: map[string]interface{}{
+			"validateCardProperties": true,
+			"cardProperties": []interface{}{
+				map[string]interface{}{
+					"id":         "status",
+					"type":       "select",
+					"options":    []interface{}{map[string]interface{}{"id": "todo"}},
+					"validation": map[string]interface{}{"optionsOnly": true},
+				},
+			},
+		}}
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(card("card-1"), nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil).Times(3)
+
+		_, err := th.App.PatchBlocks(ctx, container, &model.BlocksPatch{BlockIDs: []string{"card-1"}, Patch: patch}, "user-id-1", false)
+		var propertiesErr InvalidPropertiesError
+		require.ErrorAs(t, err, &propertiesErr)
+		require.Equal(t, []string{"status"}, propertiesErr.PropertyIDs)
+		require.True(t, tx.rolledBack)
+	})
+
+	t.Run("should patch none of the cards if a block isn't in the workspace", func(t *testing.T) {
+		th.expectTx()
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-3")).Return(nil, nil)
+
+		_, err := th.App.PatchBlocks(ctx, container, &model.BlocksPatch{BlockIDs: []string{"card-3"}, Patch: patch}, "user-id-1", false)
+		require.ErrorIs(t, err, ErrInvalidBlocksPatch)
+	})
+
+	t.Run("should reject invalid patches", func(t *testing.T) {
+		boardType := "board"
+		invalidPatches := []*model.BlocksPatch{
+			{BlockIDs: []string{}},
+			{BlockIDs: make([]string, model.BlocksPatchMaxBlocks+1)},
+			{BlockIDs: []string{"card-1", "card-1"}},
+			{BlockIDs: []string{"card-1"}, Patch: model.BlockPatch{Type: &boardType}},
+		}
+		for _, blocksPatch := range invalidPatches {
+			_, err := th.App.PatchBlocks(ctx, container, blocksPatch, "user-id-1", false)
+			require.ErrorIs(t, err, ErrInvalidBlocksPatch)
+		}
+	})
+}
+
+func TestUndeleteBlock(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectOpenBoards()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("success scenario", func(t *testing.T) {
+		block := model.Block{ID: "block-1", RootID: "block-1"}
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), gomock.Eq(container), gomock.Eq("block-1"), gomock.Any()).Return([]model.Block{block}, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-1")).Return(&block, nil)
+		th.Store.EXPECT().RestoreBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-1"), gomock.Eq("user-id-1")).Return(nil)
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-1")).Return(&block, nil)
+
+		result, err := th.App.UndeleteBlock(ctx, container, "block-1", "user-id-1")
+		require.NoError(t, err)
+		require.Equal(t, &block, result)
+	})
+
+	t.Run("error scenario", func(t *testing.T) {
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), gomock.Eq(container), gomock.Eq("block-1"), gomock.Any()).Return(nil, nil)
+		th.Store.EXPECT().RestoreBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-1"), gomock.Eq("user-id-1")).Return(blockError{"error"})
+
+		result, err := th.App.UndeleteBlock(ctx, container, "block-1", "user-id-1")
+		require.Error(t, err)
+		require.Nil(t, result)
+	})
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// ErrBoardArchived is returned when writing the blocks of an archived
+// board, which are read-only until the board is unarchived.
+var ErrBoardArchived = errors.New("the board is archived")
+
+// isArchived tells if the board is archived.
+func isArchived(board model.Block) bool {
+	archived, _ := board.Fields[model.BoardArchivedField].(bool)
+	return archived
+}
+
+// GetBoards returns the boards of the workspace that the user can view,
+// either the archived ones or the others. The templates are left out.
+func (a *App) GetBoards(ctx context.Context, c store.Container, userID string, archived bool) ([]model.Block, error) {
+	blocks, err := a.store.GetBlocksWithType(ctx, c, "board")
+	if err != nil {
+		return nil, err
+	}
+
+	boards := []model.Block{}
+	for _, block := range blocks {
+		if !isTemplate(block) && isArchived(block) == archived {
+			boards = append(boards, block)
+		}
+	}
+	return a.FilterBlocksForUser(c, userID, boards)
+}
+
+// checkBoardsNotArchived returns ErrBoardArchived if one of the boards is
+// archived. The boards are read from the given store, which can be a
+// transaction, and the IDs that aren't boards are ignored.
+func (a *App) checkBoardsNotArchived(ctx context.Context, st store.Store, c store.Container, boardIDs ...string) error {
+	seen := map[string]bool{}
+	for _, boardID := range boardIDs {
+		if boardID == "" || seen[boardID] {
+			continue
+		}
+		seen[boardID] = true
+
+		board, err := st.GetBlock(ctx, c, boardID)
+		if err != nil {
+			return err
+		}
+		if board != nil && board.Type == "board" && isArchived(*board) {
+			return ErrBoardArchived
+		}
+	}
+	return nil
+}
+
+// checkBlocksNotArchived returns ErrBoardArchived if the board of one of
+// the blocks is archived.
+func (a *App) checkBlocksNotArchived(ctx context.Context, c store.Container, blocks []model.Block) error {
+	boardIDs := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		boardIDs = append(boardIDs, block.BoardID())
+	}
+	return a.checkBoardsNotArchived(ctx, a.store, c, boardIDs...)
+}
+
+// checkPatchNotArchived returns ErrBoardArchived if the board of the
+// block, or the board it's moved to, is archived, unless the patch only
+// unarchives the board. It tells if the board of the block was archived.
+func (a *App) checkPatchNotArchived(ctx context.Context, c store.Container, blockID string, patch *model.BlockPatch) (bool, error) {
+	rootID, err := a.store.GetRootID(ctx, c, blockID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	board, err := a.store.GetBlock(ctx, c, rootID)
+	if err != nil {
+		return false, err
+	}
+	if board != nil && board.Type == "board" && isArchived(*board) {
+		if blockID == rootID && patch.IsUnarchivePatch() {
+			return true, nil
+		}
+		return true, ErrBoardArchived
+	}
+
+	if patch.RootID != nil {
+		return false, a.checkBoardsNotArchived(ctx, a.store, c, *patch.RootID)
+	}
+	return false, nil
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardArchive(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectSingleWorkspace()
+	th.expectOpenBoards()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	// the patches modify the returned blocks
+	archivedBoard := func() *model.Block {
+		return &model.Block{ID: "board-1", RootID: "board-1", Type: "board", Fields: map[string]interface{}{
+			model.BoardArchivedField: true,
+		}}
+	}
+	th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows).AnyTimes()
+
+	t.Run("should list the archived boards or the others", func(t *testing.T) {
+		boards := []model.Block{
+			*archivedBoard(),
+			{ID: "board-2", RootID: "board-2", Type: "board", Fields: map[string]interface{}{}},
+			{ID: "template-1", RootID: "template-1", Type: "board", Fields: map[string]interface{}{"isTemplate": true}},
+		}
+		th.Store.EXPECT().GetBlocksWithType(gomock.Any(), gomock.Eq(container), gomock.Eq("board")).Return(boards, nil).Times(2)
+
+		result, err := th.App.GetBoards(ctx, container, "user-id", true)
+		require.NoError(t, err)
+		require.Equal(t, boards[:1], result)
+
+		result, err = th.App.GetBoards(ctx, container, "user-id", false)
+		require.NoError(t, err)
+		require.Equal(t, boards[1:2], result)
+	})
+
+	t.Run("should reject the blocks inserted into an archived board", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(archivedBoard(), nil)
+
+		_, err := th.App.InsertBlocks(ctx, container, []model.Block{{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"}}, "user-id")
+		require.ErrorIs(t, err, ErrBoardArchived)
+	})
+
+	t.Run("should reject the patches of the blocks of an archived board", func(t *testing.T) {
+		th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return("board-1", nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(archivedBoard(), nil)
+
+		title := "Renamed"
+		_, err := th.App.PatchBlock(ctx, container, "card-1", &model.BlockPatch{Title: &title}, "user-id", false)
+		require.ErrorIs(t, err, ErrBoardArchived)
+	})
+
+	t.Run("should reject the patches of an archived board other than unarchiving it", func(t *testing.T) {
+		th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return("board-1", nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(archivedBoard(), nil)
+
+		_, err := th.App.PatchBlock(ctx, container, "board-1", &model.BlockPatch{
+			UpdatedFields: map[string]interface{}{model.BoardArchivedField: false, "icon": "x"},
+		}, "user-id", false)
+		require.ErrorIs(t, err, ErrBoardArchived)
+	})
+
+	t.Run("should unarchive a board", func(t *testing.T) {
+		patch := &model.BlockPatch{UpdatedFields: map[string]interface{}{model.BoardArchivedField: false}}
+		unarchived := &model.Block{ID: "board-1", RootID: "board-1", Type: "board", Fields: map[string]interface{}{
+			model.BoardArchivedField: false,
+		}}
+		th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return("board-1", nil)
+		gomock.InOrder(
+			th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(archivedBoard(), nil).Times(2),
+			th.Store.EXPECT().PatchBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq(patch), gomock.Eq("user-id")).Return(nil),
+			th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(unarchived, nil),
+		)
+
+		result, err := th.App.PatchBlock(ctx, container, "board-1", patch, "user-id", false)
+		require.NoError(t, err)
+		require.Equal(t, true, result.Before.Fields[model.BoardArchivedField])
+		require.Equal(t, unarchived, result.After)
+	})
+
+	t.Run("should reject the deletion of the blocks of an archived board", func(t *testing.T) {
+		th.Store.EXPECT().GetParentID(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return("board-1", nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).
+			Return(&model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"}, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(archivedBoard(), nil)
+
+		require.ErrorIs(t, th.App.DeleteBlock(ctx, container, "card-1", "user-id"), ErrBoardArchived)
+	})
+}
@@ -0,0 +1,272 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// ErrBoardAccessDenied is returned when the user doesn't have the role
+// required on a board restricted to its members.
+var ErrBoardAccessDenied = errors.New("the user doesn't have access to the board")
+
+// ErrInvalidBoardRole is returned when a board member is given a role
+// that isn't one of the board roles.
+var ErrInvalidBoardRole = errors.New("invalid board role")
+
+// ErrLastBoardAdmin is returned when a change would leave a board with
+// members but without an admin to manage them.
+var ErrLastBoardAdmin = errors.New("the board must keep an admin")
+
+// ErrGuestBoardAdmin is returned when a guest is made an admin of a
+// board, the guests can't manage the members of the boards.
+var ErrGuestBoardAdmin = errors.New("guests can't be admins of a board")
+
+// systemUserID is the user of the changes made by the server itself, like
+// the imports of the commands, which aren't restricted by the boards.
+const systemUserID = "system"
+
+// GetBoardMembers returns the members of the board, which is open to the
+// users of the workspace when it has none.
+func (a *App) GetBoardMembers(c store.Container, boardID string) ([]model.BoardMember, error) {
+	return a.store.GetBoardMembers(c, boardID)
+}
+
+// CheckBlockAccess returns ErrBoardAccessDenied if the board of the block
+// is restricted and the user doesn't have at least the role on it. The
+// access to the workspace is checked first by the callers, and the
+// blocks that don't exist are left to them.
+func (a *App) CheckBlockAccess(ctx context.Context, c store.Container, userID, blockID, role string) error {
+	roles, err := a.getUserBoardRoles(c, userID)
+	if err != nil || !roles.restricted() {
+		return err
+	}
+
+	rootID, err := a.store.GetRootID(ctx, c, blockID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return roles.check(role, rootID)
+}
+
+// FilterBlocksForUser returns the blocks of the boards that the user can
+// view, in the same order.
+func (a *App) FilterBlocksForUser(c store.Container, userID string, blocks []model.Block) ([]model.Block, error) {
+	if len(blocks) == 0 {
+		return blocks, nil
+	}
+
+	roles, err := a.getUserBoardRoles(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !roles.restricted() {
+		return blocks, nil
+	}
+
+	visible := make([]model.Block, 0, len(blocks))
+	for _, block := range blocks {
+		if roles.check(model.BoardRoleViewer, block.BoardID()) == nil {
+			visible = append(visible, block)
+		}
+	}
+	return visible, nil
+}
+
+// FilterSearchResultsForUser returns the search results of the boards
+// that the user can view, in the same order.
+func (a *App) FilterSearchResultsForUser(c store.Container, userID string, results []model.BlockSearchResult) ([]model.BlockSearchResult, error) {
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	roles, err := a.getUserBoardRoles(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !roles.restricted() {
+		return results, nil
+	}
+
+	visible := make([]model.BlockSearchResult, 0, len(results))
+	for _, result := range results {
+		if roles.check(model.BoardRoleViewer, result.BoardID) == nil {
+			visible = append(visible, result)
+		}
+	}
+	return visible, nil
+}
+
+// boardRoles are the roles of a user on the boards of a workspace that
+// are restricted to their members, an empty role if the user isn't one
+// of them. The guests only have access to the boards they are a member
+// of, the others to the open boards too.
+type boardRoles struct {
+	roles map[string]string
+	guest bool
+}
+
+// restricted tells if the user doesn't have access to some boards.
+func (r boardRoles) restricted() bool {
+	return r.guest || len(r.roles) > 0
+}
+
+// check returns ErrBoardAccessDenied if the user doesn't have the
+// required role on one of the boards.
+func (r boardRoles) check(required string, boardIDs ...string) error {
+	for _, boardID := range boardIDs {
+		role, member := r.roles[boardID]
+		if !member && !r.guest {
+			continue
+		}
+		if !model.BoardRoleAllows(role, required) {
+			return ErrBoardAccessDenied
+		}
+	}
+	return nil
+}
+
+// getUserBoardRoles returns the roles of the user on the boards of the
+// workspace. The system user has access to every board.
+func (a *App) getUserBoardRoles(c store.Container, userID string) (boardRoles, error) {
+	if userID == systemUserID {
+		return boardRoles{}, nil
+	}
+
+	guest, err := a.auth.IsGuest(userID)
+	if err != nil {
+		return boardRoles{}, err
+	}
+	roles, err := a.store.GetBoardRolesForUser(c, userID)
+	if err != nil {
+		return boardRoles{}, err
+	}
+	return boardRoles{roles: roles, guest: guest}, nil
+}
+
+// checkBlocksWritable returns ErrBoardAccessDenied if the user can't edit
+// the boards of the blocks.
+func (a *App) checkBlocksWritable(c store.Container, userID string, blocks []model.Block) error {
+	roles, err := a.getUserBoardRoles(c, userID)
+	if err != nil || !roles.restricted() {
+		return err
+	}
+
+	for _, block := range blocks {
+		if err := roles.check(model.BoardRoleEditor, block.BoardID()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpsertBoardMember adds the member to the board, or changes its role.
+// Only the admins of the board can manage its members, and any user of
+// the workspace but the guests can restrict an open board, becoming its
+// first admin.
+func (a *App) UpsertBoardMember(c store.Container, actorID string, member model.BoardMember) (*model.BoardMember, error) {
+	if !model.IsValidBoardRole(member.Role) {
+		return nil, ErrInvalidBoardRole
+	}
+	if member.Role == model.BoardRoleAdmin {
+		guest, err := a.auth.IsGuest(member.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if guest {
+			return nil, ErrGuestBoardAdmin
+		}
+	}
+
+	roles, err := a.auth.GetBoardRoles(c, member.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := utils.GetMillis()
+	if roles == nil {
+		guest, err := a.auth.IsGuest(actorID)
+		if err != nil {
+			return nil, err
+		}
+		if guest {
+			return nil, ErrBoardAccessDenied
+		}
+		if member.UserID == actorID && member.Role != model.BoardRoleAdmin {
+			return nil, ErrLastBoardAdmin
+		}
+		if member.UserID != actorID {
+			admin := model.BoardMember{BoardID: member.BoardID, UserID: actorID, Role: model.BoardRoleAdmin, CreateAt: now}
+			if err := a.store.UpsertBoardMember(c, admin); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if roles[actorID] != model.BoardRoleAdmin {
+			return nil, ErrBoardAccessDenied
+		}
+		if member.Role != model.BoardRoleAdmin && isLastBoardAdmin(roles, member.UserID) {
+			return nil, ErrLastBoardAdmin
+		}
+	}
+
+	member.CreateAt = now
+	if err := a.store.UpsertBoardMember(c, member); err != nil {
+		return nil, err
+	}
+
+	a.recordAuditEntry(model.AuditActionUpsertBoardMember, actorID, c.WorkspaceID, member.BoardID, map[string]interface{}{
+		"userId": member.UserID,
+		"role":   member.Role,
+	})
+	return &member, nil
+}
+
+// DeleteBoardMember removes the member from the board. The admins of the
+// board can remove any member, and the members can leave the board. The
+// last admin can only leave once the other members are removed, which
+// opens the board to the workspace again.
+func (a *App) DeleteBoardMember(c store.Container, actorID, boardID, userID string) error {
+	roles, err := a.auth.GetBoardRoles(c, boardID)
+	if err != nil {
+		return err
+	}
+	if _, ok := roles[userID]; !ok {
+		return nil
+	}
+	if actorID != userID && roles[actorID] != model.BoardRoleAdmin {
+		return ErrBoardAccessDenied
+	}
+	if len(roles) > 1 && isLastBoardAdmin(roles, userID) {
+		return ErrLastBoardAdmin
+	}
+
+	if err := a.store.DeleteBoardMember(c, boardID, userID); err != nil {
+		return err
+	}
+
+	a.recordAuditEntry(model.AuditActionDeleteBoardMember, actorID, c.WorkspaceID, boardID, map[string]interface{}{
+		"userId": userID,
+	})
+	return nil
+}
+
+// isLastBoardAdmin tells if the user is the only admin among the roles.
+func isLastBoardAdmin(roles map[string]string, userID string) bool {
+	if roles[userID] != model.BoardRoleAdmin {
+		return false
+	}
+	for id, role := range roles {
+		if id != userID && role == model.BoardRoleAdmin {
+			return false
+		}
+	}
+	return true
+}
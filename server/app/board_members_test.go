@@ -0,0 +1,161 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+// boardMemberMatcher matches a board member, ignoring its create time.
+type boardMemberMatcher struct {
+	userID string
+	role   string
+}
+
+func (m boardMemberMatcher) Matches(x interface{}) bool {
+	member, ok := x.(model.BoardMember)
+	return ok && member.BoardID == "board-id" && member.UserID == m.userID && member.Role == m.role
+}
+
+func (m boardMemberMatcher) String() string {
+	return "member " + m.userID + " with role " + m.role
+}
+
+func TestUpsertBoardMember(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("should make the user restricting an open board its admin", func(t *testing.T) {
+		th.Store.EXPECT().GetBoardMembers(gomock.Eq(container), gomock.Eq("board-id")).Return([]model.BoardMember{}, nil)
+		th.Store.EXPECT().GetUserByID(gomock.Eq("user-id-1")).Return(&model.User{ID: "user-id-1"}, nil)
+		th.Store.EXPECT().UpsertBoardMember(gomock.Eq(container), boardMemberMatcher{"user-id-1", model.BoardRoleAdmin}).Return(nil)
+		th.Store.EXPECT().UpsertBoardMember(gomock.Eq(container), boardMemberMatcher{"user-id-2", model.BoardRoleViewer}).Return(nil)
+		expectAuditEntry(th, model.AuditActionUpsertBoardMember, "user-id-1", "board-id")
+
+		member, err := th.App.UpsertBoardMember(container, "user-id-1", model.BoardMember{BoardID: "board-id", UserID: "user-id-2", Role: model.BoardRoleViewer})
+		require.NoError(t, err)
+		require.Equal(t, model.BoardRoleViewer, member.Role)
+	})
+
+	t.Run("should only let the admins manage the members", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(gomock.Eq("user-id-2")).Return(&model.User{ID: "user-id-2"}, nil)
+		th.Store.EXPECT().GetBoardMembers(gomock.Eq(container), gomock.Eq("board-id")).Return([]model.BoardMember{
+			{BoardID: "board-id", UserID: "user-id-1", Role: model.BoardRoleAdmin},
+			{BoardID: "board-id", UserID: "user-id-2", Role: model.BoardRoleEditor},
+		}, nil)
+
+		_, err := th.App.UpsertBoardMember(container, "user-id-2", model.BoardMember{BoardID: "board-id", UserID: "user-id-2", Role: model.BoardRoleAdmin})
+		require.ErrorIs(t, err, ErrBoardAccessDenied)
+	})
+
+	t.Run("should keep an admin", func(t *testing.T) {
+		th.Store.EXPECT().GetBoardMembers(gomock.Eq(container), gomock.Eq("board-id")).Return([]model.BoardMember{
+			{BoardID: "board-id", UserID: "user-id-1", Role: model.BoardRoleAdmin},
+			{BoardID: "board-id", UserID: "user-id-2", Role: model.BoardRoleEditor},
+		}, nil).Times(2)
+
+		_, err := th.App.UpsertBoardMember(container, "user-id-1", model.BoardMember{BoardID: "board-id", UserID: "user-id-1", Role: model.BoardRoleEditor})
+		require.ErrorIs(t, err, ErrLastBoardAdmin)
+
+		err = th.App.DeleteBoardMember(container, "user-id-1", "board-id", "user-id-1")
+		require.ErrorIs(t, err, ErrLastBoardAdmin)
+	})
+
+	t.Run("should not let a guest restrict an open board or be an admin", func(t *testing.T) {
+		guest := &model.User{ID: "guest-id", IsGuest: true}
+		th.Store.EXPECT().GetBoardMembers(gomock.Eq(container), gomock.Eq("board-id")).Return([]model.BoardMember{}, nil)
+		th.Store.EXPECT().GetUserByID(gomock.Eq("guest-id")).Return(guest, nil).Times(2)
+
+		_, err := th.App.UpsertBoardMember(container, "guest-id", model.BoardMember{BoardID: "board-id", UserID: "user-id-2", Role: model.BoardRoleViewer})
+		require.ErrorIs(t, err, ErrBoardAccessDenied)
+
+		_, err = th.App.UpsertBoardMember(container, "user-id-1", model.BoardMember{BoardID: "board-id", UserID: "guest-id", Role: model.BoardRoleAdmin})
+		require.ErrorIs(t, err, ErrGuestBoardAdmin)
+	})
+
+	t.Run("should reject an invalid role", func(t *testing.T) {
+		_, err := th.App.UpsertBoardMember(container, "user-id-1", model.BoardMember{BoardID: "board-id", UserID: "user-id-2", Role: "owner"})
+		require.ErrorIs(t, err, ErrInvalidBoardRole)
+	})
+
+	t.Run("should let a member leave the board", func(t *testing.T) {
+		th.Store.EXPECT().GetBoardMembers(gomock.Eq(container), gomock.Eq("board-id")).Return([]model.BoardMember{
+			{BoardID: "board-id", UserID: "user-id-1", Role: model.BoardRoleAdmin},
+			{BoardID: "board-id", UserID: "user-id-2", Role: model.BoardRoleViewer},
+		}, nil)
+		th.Store.EXPECT().DeleteBoardMember(gomock.Eq(container), gomock.Eq("board-id"), gomock.Eq("user-id-2")).Return(nil)
+		expectAuditEntry(th, model.AuditActionDeleteBoardMember, "user-id-2", "board-id")
+
+		require.NoError(t, th.App.DeleteBoardMember(container, "user-id-2", "board-id", "user-id-2"))
+	})
+}
+
+func TestBoardAccess(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	roles := map[string]string{"viewer-board": model.BoardRoleViewer, "private-board": ""}
+	th.Store.EXPECT().GetUserByID(gomock.Eq("user-id")).Return(&model.User{ID: "user-id"}, nil).AnyTimes()
+
+	t.Run("should filter the blocks of the boards the user can't view", func(t *testing.T) {
+		th.Store.EXPECT().GetBoardRolesForUser(gomock.Eq(container), gomock.Eq("user-id")).Return(roles, nil)
+
+		blocks := []model.Block{
+			{ID: "private-board", RootID: "private-board"},
+			{ID: "card-1", RootID: "viewer-board"},
+			{ID: "card-2", RootID: "open-board"},
+		}
+		visible, err := th.App.FilterBlocksForUser(container, "user-id", blocks)
+		require.NoError(t, err)
+		require.Equal(t, blocks[1:], visible)
+	})
+
+	t.Run("should not let a viewer insert blocks", func(t *testing.T) {
+		th.Store.EXPECT().GetBoardRolesForUser(gomock.Eq(container), gomock.Eq("user-id")).Return(roles, nil)
+
+		_, err := th.App.InsertBlocks(ctx, container, []model.Block{{ID: "card-3", RootID: "viewer-board"}}, "user-id")
+		require.ErrorIs(t, err, ErrBoardAccessDenied)
+	})
+
+	t.Run("should not let a viewer patch a block", func(t *testing.T) {
+		th.Store.EXPECT().GetBoardRolesForUser(gomock.Eq(container), gomock.Eq("user-id")).Return(roles, nil)
+		th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return("viewer-board", nil)
+
+		title := "Renamed"
+		_, err := th.App.PatchBlock(ctx, container, "card-1", &model.BlockPatch{Title: &title}, "user-id", false)
+		require.ErrorIs(t, err, ErrBoardAccessDenied)
+	})
+
+	t.Run("should check the board of a block", func(t *testing.T) {
+		th.Store.EXPECT().GetBoardRolesForUser(gomock.Eq(container), gomock.Eq("user-id")).Return(roles, nil).Times(2)
+		th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return("viewer-board", nil).Times(2)
+
+		require.NoError(t, th.App.CheckBlockAccess(ctx, container, "user-id", "card-1", model.BoardRoleViewer))
+		require.ErrorIs(t, th.App.CheckBlockAccess(ctx, container, "user-id", "card-1", model.BoardRoleEditor), ErrBoardAccessDenied)
+	})
+
+	t.Run("should only let a guest view the boards they are a member of", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(gomock.Eq("guest-id")).Return(&model.User{ID: "guest-id", IsGuest: true}, nil)
+		th.Store.EXPECT().GetBoardRolesForUser(gomock.Eq(container), gomock.Eq("guest-id")).Return(map[string]string{"viewer-board": model.BoardRoleViewer}, nil)
+
+		blocks := []model.Block{
+			{ID: "card-1", RootID: "viewer-board"},
+			{ID: "card-2", RootID: "open-board"},
+		}
+		visible, err := th.App.FilterBlocksForUser(container, "guest-id", blocks)
+		require.NoError(t, err)
+		require.Equal(t, blocks[:1], visible)
+	})
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// ErrInvalidStatisticsProperty is returned when the board statistics
+// are requested for a property or option the board doesn't have.
+var ErrInvalidStatisticsProperty = errors.New("invalid statistics property")
+
+// ComputeBoardStats computes the number of cards of the board by option
+// of a select property and by user of a person property, and the number
+// of cards created and completed by week. It returns nil if the board
+// doesn't exist.
+func (a *App) ComputeBoardStats(ctx context.Context, c store.Container, boardID string, opts model.BoardStatisticsOptions) (*model.BoardStatistics, error) {
+	board, err := a.store.GetBlock(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil || board.Type != "board" {
+		return nil, nil
+	}
+
+	selectProperty, err := findStatisticsProperty(*board, "select", opts.SelectPropertyID)
+	if err != nil {
+		return nil, err
+	}
+	personProperty, err := findStatisticsProperty(*board, "person", opts.PersonPropertyID)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DoneOptionID != "" {
+		if _, ok := selectProperty.options[opts.DoneOptionID]; !ok {
+			return nil, ErrInvalidStatisticsProperty
+		}
+	}
+
+	stats := &model.BoardStatistics{
+		BoardID:          board.ID,
+		SelectPropertyID: selectProperty.id,
+		PersonPropertyID: personProperty.id,
+		DoneOptionID:     opts.DoneOptionID,
+		ByOption:         []model.PropertyValueCount{},
+		ByPerson:         []model.PropertyValueCount{},
+	}
+
+	// without a select property, the cards are counted under a single
+	// empty value
+	byOption, err := a.store.CountCardsByProperty(ctx, c, board.ID, selectProperty.id)
+	if err != nil {
+		return nil, err
+	}
+	for _, count := range byOption {
+		stats.CardCount += count.Count
+		if selectProperty.id != "" {
+			count.Name = selectProperty.options[count.Value]
+			stats.ByOption = append(stats.ByOption, count)
+		}
+	}
+
+	if personProperty.id != "" {
+		stats.ByPerson, err = a.store.CountCardsByProperty(ctx, c, board.ID, personProperty.id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	since := statisticsWeekStart(time.Now()).AddDate(0, 0, -7*(model.BoardStatisticsWeeks-1))
+	sinceMillis := since.UnixNano() / int64(time.Millisecond)
+	created, err := a.store.CountCardsCreatedByWeek(ctx, c, board.ID, sinceMillis)
+	if err != nil {
+		return nil, err
+	}
+	completed := map[int64]int64{}
+	if opts.DoneOptionID != "" {
+		completed, err = a.store.CountCardsCompletedByWeek(ctx, c, board.ID, selectProperty.id, opts.DoneOptionID, sinceMillis)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for week := int64(0); week < model.BoardStatisticsWeeks; week++ {
+		stats.Throughput = append(stats.Throughput, model.WeeklyThroughput{
+			WeekStart: sinceMillis + week*model.BoardStatisticsWeekMillis,
+			Created:   created[week],
+			Completed: completed[week],
+		})
+	}
+
+	return stats, nil
+}
+
+// findStatisticsProperty returns the card property of the board with the
+// ID and type, or the first one of the type if the ID is empty. The
+// returned property has an empty ID if the board has none of the type.
+func findStatisticsProperty(board model.Block, propertyType, propertyID string) (csvProperty, error) {
+	for _, property := range csvProperties(board, nil) {
+		if property.propertyType != propertyType {
+			continue
+		}
+		if propertyID == "" || property.id == propertyID {
+			return property, nil
+		}
+	}
+
+	if propertyID != "" {
+		return csvProperty{}, ErrInvalidStatisticsProperty
+	}
+	return csvProperty{options: map[string]string{}}, nil
+}
+
+// statisticsWeekStart returns the start of the week of the time, on
+// Monday in UTC.
+func statisticsWeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
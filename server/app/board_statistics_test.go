@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeBoardStats(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := &model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "notes", "name": "Notes", "type": "text"},
+				map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+					map[string]interface{}{"id": "todo", "value": "To do"},
+					map[string]interface{}{"id": "done", "value": "Done"},
+				}},
+				map[string]interface{}{"id": "owner", "name": "Owner", "type": "person"},
+			},
+		},
+	}
+
+	t.Run("should count the cards by option, person and week", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().CountCardsByProperty(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("status")).
+			Return([]model.PropertyValueCount{{Value: "done", Count: 2}, {Value: "", Count: 1}}, nil)
+		th.Store.EXPECT().CountCardsByProperty(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("owner")).
+			Return([]model.PropertyValueCount{{Value: "user-1", Count: 3}}, nil)
+		th.Store.EXPECT().CountCardsCreatedByWeek(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Any()).
+			Return(map[int64]int64{0: 1, 11: 2}, nil)
+		th.Store.EXPECT().CountCardsCompletedByWeek(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("status"), gomock.Eq("done"), gomock.Any()).
+			Return(map[int64]int64{11: 2}, nil)
+
+		stats, err := th.App.ComputeBoardStats(ctx, container, "board-1", model.BoardStatisticsOptions{DoneOptionID: "done"})
+		require.NoError(t, err)
+		require.EqualValues(t, 3, stats.CardCount)
+		require.Equal(t, []model.PropertyValueCount{
+			{Value: "done", Name: "Done", Count: 2},
+			{Value: "", Name: "", Count: 1},
+		}, stats.ByOption)
+		require.Equal(t, []model.PropertyValueCount{{Value: "user-1", Count: 3}}, stats.ByPerson)
+
+		require.Len(t, stats.Throughput, model.BoardStatisticsWeeks)
+		require.EqualValues(t, 1, stats.Throughput[0].Created)
+		require.EqualValues(t, 2, stats.Throughput[11].Created)
+		require.EqualValues(t, 2, stats.Throughput[11].Completed)
+		require.Equal(t, stats.Throughput[0].WeekStart+11*model.BoardStatisticsWeekMillis, stats.Throughput[11].WeekStart)
+	})
+
+	t.Run("should fail for a property of another type", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+
+		_, err := th.App.ComputeBoardStats(ctx, container, "board-1", model.BoardStatisticsOptions{SelectPropertyID: "notes"})
+		require.ErrorIs(t, err, ErrInvalidStatisticsProperty)
+	})
+
+	t.Run("should fail for an option of another property", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+
+		_, err := th.App.ComputeBoardStats(ctx, container, "board-1", model.BoardStatisticsOptions{DoneOptionID: "unknown"})
+		require.ErrorIs(t, err, ErrInvalidStatisticsProperty)
+	})
+
+	t.Run("should return nil if the board doesn't exist", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(nil, nil)
+
+		stats, err := th.App.ComputeBoardStats(ctx, container, "board-1", model.BoardStatisticsOptions{})
+		require.NoError(t, err)
+		require.Nil(t, stats)
+	})
+}
+
+func TestStatisticsWeekStart(t *testing.T) {
+	sunday := time.Date(2021, time.October, 10, 23, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2021, time.October, 4, 0, 0, 0, 0, time.UTC), statisticsWeekStart(sunday))
+
+	monday := time.Date(2021, time.October, 11, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, monday, statisticsWeekStart(monday))
+}
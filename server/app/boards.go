@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// DuplicateBoard copies the board and all its blocks, including the
+// comments, with new IDs. The files referenced by the blocks are copied
+// under the new board, as files are stored per board. It returns the
+// new board, or nil if the board doesn't exist.
+func (a *App) DuplicateBoard(ctx context.Context, c store.Container, boardID string, asTemplate bool, userID string) (*model.Block, error) {
+	return a.copyBoard(ctx, c, c, boardID, userID, func(board *model.Block) {
+		isTemplate, _ := board.Fields["isTemplate"].(bool)
+		switch {
+		case asTemplate == isTemplate:
+			board.Title += " copy"
+		case asTemplate:
+			board.Title = "New board template"
+		}
+		board.Fields["isTemplate"] = asTemplate
+	})
+}
+
+// copyBoard copies the board of the src container and all its blocks
+// into the dst container, with new IDs. The update function is called
+// with the board before it is copied. It returns the new board, or nil
+// if the board doesn't exist.
+func (a *App) copyBoard(ctx context.Context, src, dst store.Container, boardID, userID string, update func(board *model.Block)) (*model.Block, error) {
+	// the blocks are read and copied in the same transaction, so that
+	// the copy doesn't mix the board before and after a concurrent change
+	tx, err := a.store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer a.rollbackTx(tx)
+
+	blocks, err := tx.GetBlocksWithRootID(ctx, src, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the copied cards are numbered again from the start of the sequence
+	// of the new board, in the order of their numbers in the board
+	sort.SliceStable(blocks, func(i, j int) bool {
+		first, _ := model.CardNumber(blocks[i])
+		second, _ := model.CardNumber(blocks[j])
+		return first < second
+	})
+
+	var board *model.Block
+	for i := range blocks {
+		if blocks[i].ID == boardID && blocks[i].Type == "board" {
+			board = &blocks[i]
+			break
+		}
+	}
+	if board == nil {
+		return nil, nil
+	}
+
+	if board.Fields == nil {
+		board.Fields = map[string]interface{}{}
+	}
+	update(board)
+
+	newBlocks, idMap := duplicateBlockTree(blocks, boardID)
+	newBoardID := idMap[boardID]
+
+	for _, block := range newBlocks {
+		fileID, ok := block.Fields["fileId"].(string)
+		if !ok || fileID == "" {
+			continue
+		}
+		srcPath := filepath.Join(src.WorkspaceID, boardID, fileID)
+		dstPath := filepath.Join(dst.WorkspaceID, newBoardID, fileID)
+		if err := a.filesBackend.CopyFile(srcPath, dstPath); err != nil {
+			// a missing file shouldn't prevent copying the board
+			a.logger.Warn("Unable to copy file of board",
+				mlog.String("boardID", boardID),
+				mlog.String("fileID", fileID),
+				mlog.Err(err),
+			)
+		}
+	}
+
+	if _, err := tx.InsertBlocks(ctx, dst, newBlocks, userID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	a.blocksInserted(ctx, dst, a.workspaceWebhooks(ctx, dst.WorkspaceID), nil, newBlocks, userID)
+
+	for i := range newBlocks {
+		if newBlocks[i].ID == newBoardID {
+			return &newBlocks[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetBoardMetadata returns the activity summary, the checklist progress
+// and the values of the computed properties of every card of the board,
+// the linked cards the user can't view being left out of the values.
+func (a *App) GetBoardMetadata(ctx context.Context, c store.Container, userID, boardID string) ([]model.CardMetadata, error) {
+	metadata, err := a.store.GetBoardMetadata(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	progress, err := a.store.GetChecklistProgress(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	cardProgress := make(map[string]model.ChecklistProgress, len(progress))
+	for _, p := range progress {
+		cardProgress[p.CardID] = p
+	}
+	for i := range metadata {
+		p := cardProgress[metadata[i].CardID]
+		metadata[i].CheckedCount = p.Checked
+		metadata[i].CheckboxCount = p.Total
+	}
+
+	board, err := a.store.GetBlock(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board != nil && board.Type == "board" {
+		computed, err := a.computeCardValues(ctx, c, userID, *board)
+		if err != nil {
+			return nil, err
+		}
+		for i := range metadata {
+			metadata[i].ComputedValues = computed[metadata[i].CardID]
+		}
+	}
+
+	return metadata, nil
+}
+
+// GetBoardPresence returns the IDs of the users viewing the board.
+func (a *App) GetBoardPresence(c store.Container, boardID string) []string {
+	return a.wsAdapter.GetBoardPresence(c.WorkspaceID, boardID)
+}
+
+// duplicateBlockTree copies the blocks of a tree with new IDs, remapping
+// the parent, root and block ordering references. The creation metadata
+// is set when the blocks are inserted. It returns the new blocks and
+// the map from old to new IDs.
+func duplicateBlockTree(blocks []model.Block, rootID string) ([]model.Block, map[string]string) {
+	idMap := make(map[string]string, len(blocks))
+	for _, block := range blocks {
+		idMap[block.ID] = utils.CreateGUID()
+	}
+
+	remap := func(id string) string {
+		if newID, ok := idMap[id]; ok {
+			return newID
+		}
+		return id
+	}
+
+	newBlocks := make([]model.Block, 0, len(blocks))
+	for _, block := range blocks {
+		newBlock := block
+		newBlock.ID = idMap[block.ID]
+		newBlock.RootID = idMap[rootID]
+		if block.ID != rootID {
+			newBlock.ParentID = remap(block.ParentID)
+		}
+
+		newBlock.Fields = make(map[string]interface{}, len(block.Fields))
+		for key, value := range block.Fields {
+			newBlock.Fields[key] = value
+		}
+
+		switch block.Type {
+		case "view":
+			if cardOrder, ok := block.Fields["cardOrder"].([]interface{}); ok {
+				newBlock.Fields["cardOrder"] = remapIDList(cardOrder, remap)
+			}
+		case "card":
+			delete(newBlock.Fields, model.CardNumberField)
+			if contentOrder, ok := block.Fields["contentOrder"].([]interface{}); ok {
+				newBlock.Fields["contentOrder"] = remapIDList(contentOrder, remap)
+			}
+		}
+
+		newBlocks = append(newBlocks, newBlock)
+	}
+
+	return newBlocks, idMap
+}
+
+// remapIDList maps the IDs of a list that can contain nested lists of
+// IDs, like the content order of cards.
+func remapIDList(ids []interface{}, remap func(string) string) []interface{} {
+	result := make([]interface{}, len(ids))
+	for i, item := range ids {
+		switch v := item.(type) {
+		case string:
+			result[i] = remap(v)
+		case []interface{}:
+			result[i] = remapIDList(v, remap)
+		default:
+			result[i] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/filestore/mocks"
+)
+
+func TestDuplicateBoard(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	boardBlocks := func(isTemplate bool) []model.Block {
+		return []model.Block{
+			{
+				ID:     "board-1",
+				RootID: "board-1",
+				Type:   "board",
+				Title:  "Board",
+				Fields: map[string]interface{}{"isTemplate": isTemplate},
+			},
+			{
+				ID:       "view-1",
+				ParentID: "board-1",
+				RootID:   "board-1",
+				Type:     "view",
+				Fields:   map[string]interface{}{"cardOrder": []interface{}{"card-1", "unknown-card"}},
+			},
+			{
+				ID:       "card-1",
+				ParentID: "board-1",
+				RootID:   "board-1",
+				Type:     "card",
+				Fields:   map[string]interface{}{"contentOrder": []interface{}{"image-1", []interface{}{"text-1"}}},
+			},
+			{
+				ID:       "image-1",
+				ParentID: "card-1",
+				RootID:   "board-1",
+				Type:     "image",
+				Fields:   map[string]interface{}{"fileId": "file-1.png"},
+			},
+			{
+				ID:       "text-1",
+				ParentID: "card-1",
+				RootID:   "board-1",
+				Type:     "text",
+			},
+		}
+	}
+
+	t.Run("should duplicate the board and its blocks", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		var inserted []model.Block
+		tx := th.expectTx()
+		th.Store.EXPECT().GetBlocksWithRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(boardBlocks(false), nil)
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-id-1")).
+			DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				inserted = blocks
+				return &model.BlocksUpsertResult{}, nil
+			})
+		mockedFileBackend.On("CopyFile", filepath.Join("0", "board-1", "file-1.png"), mock.Anything).Return(nil)
+
+		board, err := th.App.DuplicateBoard(ctx, container, "board-1", false, "user-id-1")
+		require.NoError(t, err)
+		require.NotNil(t, board)
+		require.Len(t, inserted, 5)
+		require.True(t, tx.committed)
+
+		newIDs := map[string]model.Block{}
+		for _, block := range inserted {
+			newIDs[block.Type] = block
+		}
+		require.NotEqual(t, "board-1", board.ID)
+		require.Equal(t, "Board copy", board.Title)
+		require.Equal(t, false, board.Fields["isTemplate"])
+
+		for _, block := range inserted {
+			require.Equal(t, board.ID, block.RootID)
+		}
+
+		view := newIDs["view"]
+		card := newIDs["card"]
+		require.Equal(t, board.ID, view.ParentID)
+		require.Equal(t, board.ID, card.ParentID)
+		require.Equal(t, card.ID, newIDs["image"].ParentID)
+		require.Equal(t, card.ID, newIDs["text"].ParentID)
+		require.Equal(t, []interface{}{card.ID, "unknown-card"}, view.Fields["cardOrder"])
+		require.Equal(t, []interface{}{newIDs["image"].ID, []interface{}{newIDs["text"].ID}}, card.Fields["contentOrder"])
+		require.Equal(t, "file-1.png", newIDs["image"].Fields["fileId"])
+
+		mockedFileBackend.AssertCalled(t, "CopyFile", filepath.Join("0", "board-1", "file-1.png"), filepath.Join("0", board.ID, "file-1.png"))
+	})
+
+	t.Run("should renumber the cards in the order of their numbers", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		mockedFileBackend.On("CopyFile", mock.Anything, mock.Anything).Return(nil)
+		th.App.filesBackend = mockedFileBackend
+
+		blocks := []model.Block{
+			{ID: "board-1", RootID: "board-1", Type: "board"},
+			{ID: "card-2", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "Second", Fields: map[string]interface{}{"number": float64(7)}},
+			{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "First", Fields: map[string]interface{}{"number": float64(3)}},
+		}
+
+		var inserted []model.Block
+		th.expectTx()
+		th.Store.EXPECT().GetBlocksWithRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(blocks, nil)
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-id-1")).
+			DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				inserted = blocks
+				return &model.BlocksUpsertResult{}, nil
+			})
+
+		_, err := th.App.DuplicateBoard(ctx, container, "board-1", false, "user-id-1")
+		require.NoError(t, err)
+		require.Len(t, inserted, 3)
+		require.Equal(t, "First", inserted[1].Title)
+		require.Equal(t, "Second", inserted[2].Title)
+		for _, block := range inserted {
+			require.NotContains(t, block.Fields, model.CardNumberField)
+		}
+	})
+
+	titleTests := []struct {
+		name       string
+		isTemplate bool
+		asTemplate bool
+		title      string
+	}{
+		{"board as board", false, false, "Board copy"},
+		{"template as template", true, true, "Board copy"},
+		{"board as template", false, true, "New board template"},
+		{"template as board", true, false, "Board"},
+	}
+
+	for _, tt := range titleTests {
+		t.Run("should set the title when duplicating "+tt.name, func(t *testing.T) {
+			mockedFileBackend := &mocks.FileBackend{}
+			mockedFileBackend.On("CopyFile", mock.Anything, mock.Anything).Return(nil)
+			th.App.filesBackend = mockedFileBackend
+
+			th.expectTx()
+			th.Store.EXPECT().GetBlocksWithRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(boardBlocks(tt.isTemplate), nil)
+			th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+			th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-id-1")).Return(&model.BlocksUpsertResult{}, nil)
+
+			board, err := th.App.DuplicateBoard(ctx, container, "board-1", tt.asTemplate, "user-id-1")
+			require.NoError(t, err)
+			require.Equal(t, tt.title, board.Title)
+			require.Equal(t, tt.asTemplate, board.Fields["isTemplate"])
+		})
+	}
+
+	t.Run("should return nil if the board doesn't exist", func(t *testing.T) {
+		th.expectTx()
+		th.Store.EXPECT().GetBlocksWithRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return([]model.Block{}, nil)
+
+		board, err := th.App.DuplicateBoard(ctx, container, "board-1", false, "user-id-1")
+		require.NoError(t, err)
+		require.Nil(t, board)
+	})
+
+	t.Run("should fail if the blocks can't be inserted", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		mockedFileBackend.On("CopyFile", mock.Anything, mock.Anything).Return(nil)
+		th.App.filesBackend = mockedFileBackend
+
+		tx := th.expectTx()
+		th.Store.EXPECT().GetBlocksWithRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(boardBlocks(false), nil)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-id-1")).Return(nil, blockError{"error"})
+
+		board, err := th.App.DuplicateBoard(ctx, container, "board-1", false, "user-id-1")
+		require.Error(t, err)
+		require.Nil(t, board)
+		require.False(t, tx.committed)
+		require.True(t, tx.rolledBack)
+	})
+}
@@ -0,0 +1,242 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+const (
+	icalDateFormat     = "20060102"
+	icalDateTimeFormat = "20060102T150405Z"
+
+	// icalLineLength is the maximum length in octets of the lines of an
+	// iCalendar file, the longer ones are folded
+	icalLineLength = 75
+)
+
+// GetCalendarFeed returns the calendar feed of the board, or nil if it
+// has none.
+func (a *App) GetCalendarFeed(c store.Container, boardID string) (*model.CalendarFeed, error) {
+	feed, err := a.store.GetCalendarFeed(c, boardID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return feed, err
+}
+
+// CreateCalendarFeed creates the calendar feed of the board with a new
+// token, revoking the previous one if any. It returns nil if the board
+// doesn't exist.
+func (a *App) CreateCalendarFeed(ctx context.Context, c store.Container, boardID, userID string) (*model.CalendarFeed, error) {
+	board, err := a.store.GetBlock(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil || board.Type != "board" {
+		return nil, nil
+	}
+
+	feed := model.CalendarFeed{
+		BoardID:   boardID,
+		Token:     utils.CreateGUID(),
+		CreatedBy: userID,
+		CreateAt:  utils.GetMillis(),
+	}
+	if err := a.store.UpsertCalendarFeed(c, feed); err != nil {
+		return nil, err
+	}
+
+	a.recordAuditEntry(model.AuditActionCreateCalendarFeed, userID, c.WorkspaceID, boardID, nil)
+	return &feed, nil
+}
+
+// RevokeCalendarFeed revokes the calendar feed of the board. It returns
+// sql.ErrNoRows if the board has none.
+func (a *App) RevokeCalendarFeed(c store.Container, boardID, userID string) error {
+	if err := a.store.DeleteCalendarFeed(c, boardID); err != nil {
+		return err
+	}
+
+	a.recordAuditEntry(model.AuditActionRevokeCalendarFeed, userID, c.WorkspaceID, boardID, nil)
+	return nil
+}
+
+// IsValidCalendarToken tells if the token is the one of the calendar
+// feed of the board.
+func (a *App) IsValidCalendarToken(c store.Container, boardID, token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	feed, err := a.GetCalendarFeed(c, boardID)
+	if err != nil || feed == nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(feed.Token), []byte(token)) == 1, nil
+}
+
+// BoardCalendar exports the dates of the cards of a board as an
+// iCalendar feed, with an event per date property of each card.
+type BoardCalendar struct {
+	container      store.Container
+	board          model.Block
+	dateProperties []csvProperty
+	permalink      func(cardID string) string
+	store          store.Store
+}
+
+// NewBoardCalendar prepares the calendar feed of the board. It returns
+// nil if the board doesn't exist.
+func (a *App) NewBoardCalendar(ctx context.Context, c store.Container, boardID string) (*BoardCalendar, error) {
+	board, err := a.store.GetBlock(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil || board.Type != "board" || board.DeleteAt != 0 {
+		return nil, nil
+	}
+
+	dateProperties := []csvProperty{}
+	for _, property := range csvProperties(*board, nil) {
+		if property.propertyType == "date" {
+			dateProperties = append(dateProperties, property)
+		}
+	}
+
+	return &BoardCalendar{
+		container:      c,
+		board:          *board,
+		dateProperties: dateProperties,
+		permalink:      a.cardPermalinker(ctx, c, boardID),
+		store:          a.store,
+	}, nil
+}
+
+// Write writes the calendar and then the events one card at a time, so
+// that they are sent as the cards are read from the store. The deleted
+// cards and the card templates are left out.
+func (e *BoardCalendar) Write(ctx context.Context, w io.Writer) error {
+	writer := &icalWriter{w: bufio.NewWriter(w)}
+
+	writer.line("BEGIN", "VCALENDAR")
+	writer.line("VERSION", "2.0")
+	writer.line("PRODID", "-//Mattermost//Focalboard//EN")
+	writer.line("CALSCALE", "GREGORIAN")
+	writer.line("METHOD", "PUBLISH")
+	writer.line("X-WR-CALNAME", icalText(e.board.Title))
+
+	err := e.store.StreamBlocksWithParentAndType(ctx, e.container, e.board.ID, "card", func(card model.Block) error {
+		if isTemplate, _ := card.Fields["isTemplate"].(bool); isTemplate {
+			return nil
+		}
+
+		values, _ := card.Fields["properties"].(map[string]interface{})
+		for _, property := range e.dateProperties {
+			date, ok := parseDateProperty(values[property.id])
+			if !ok {
+				continue
+			}
+			e.writeEvent(writer, card, property, date)
+		}
+		return writer.err
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.line("END", "VCALENDAR")
+	if writer.err != nil {
+		return writer.err
+	}
+	return writer.w.Flush()
+}
+
+func (e *BoardCalendar) writeEvent(writer *icalWriter, card model.Block, property csvProperty, date dateProperty) {
+	summary := card.Title
+	if len(e.dateProperties) > 1 {
+		summary = fmt.Sprintf("%s (%s)", card.Title, property.name)
+	}
+	link := e.permalink(card.ID)
+
+	writer.line("BEGIN", "VEVENT")
+	writer.line("UID", fmt.Sprintf("%s-%s@focalboard", card.ID, property.id))
+	writer.line("DTSTAMP", utils.TimeFromMillis(card.UpdateAt).UTC().Format(icalDateTimeFormat))
+
+	from, to := date.From, date.To
+	if from == 0 {
+		from = to
+	}
+	if date.IncludeTime {
+		writer.line("DTSTART", utils.TimeFromMillis(from).UTC().Format(icalDateTimeFormat))
+		if to > from {
+			writer.line("DTEND", utils.TimeFromMillis(to).UTC().Format(icalDateTimeFormat))
+		}
+	} else {
+		// the dates without time are stored at midnight UTC, and the end
+		// of the all-day events is the day after their last day
+		start := utils.TimeFromMillis(from).UTC()
+		end := start
+		if to > from {
+			end = utils.TimeFromMillis(to).UTC()
+		}
+		writer.line("DTSTART;VALUE=DATE", start.Format(icalDateFormat))
+		writer.line("DTEND;VALUE=DATE", end.AddDate(0, 0, 1).Format(icalDateFormat))
+	}
+
+	writer.line("SUMMARY", icalText(summary))
+	writer.line("DESCRIPTION", icalText(card.Title+"\n"+link))
+	writer.line("URL", link)
+	writer.line("END", "VEVENT")
+}
+
+// icalWriter writes the content lines of an iCalendar file, folding the
+// long ones, and keeps the first error.
+type icalWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (w *icalWriter) line(name, value string) {
+	if w.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	// the continuation lines start with a space, which counts in their
+	// length
+	limit := icalLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, w.err = w.w.WriteString(line[:cut] + "\r\n "); w.err != nil {
+			return
+		}
+		line = line[cut:]
+		limit = icalLineLength - 1
+	}
+	_, w.err = w.w.WriteString(line + "\r\n")
+}
+
+// icalText escapes a TEXT value of an iCalendar property.
+func icalText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
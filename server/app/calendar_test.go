@@ -0,0 +1,221 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardCalendar(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.App.config.ServerRoot = "http://localhost:8000"
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := &model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Title:  "Board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "due", "name": "Due", "type": "date"},
+				map[string]interface{}{"id": "estimate", "name": "Estimate", "type": "number"},
+			},
+		},
+	}
+	view := model.Block{ID: "view-1", ParentID: "board-1", RootID: "board-1", Type: "view"}
+	card := func(id, title string, properties map[string]interface{}) model.Block {
+		return model.Block{
+			ID:       id,
+			ParentID: "board-1",
+			RootID:   "board-1",
+			Type:     "card",
+			Title:    title,
+			UpdateAt: 1609462800000,
+			Fields:   map[string]interface{}{"properties": properties},
+		}
+	}
+	template := card("template-1", "Template", map[string]interface{}{"due": "1612137600000"})
+	template.Fields["isTemplate"] = true
+	cards := []model.Block{
+		card("card-1", "First, with a comma", map[string]interface{}{"due": `{"from":1612137600000,"to":1612224000000}`}),
+		card("card-2", "Second", map[string]interface{}{"due": `{"from":1612170000000,"includeTime":true}`}),
+		card("card-3", "No date", map[string]interface{}{"estimate": "3"}),
+		template,
+	}
+
+	streamCards := func(_ context.Context, _ st.Container, _, _ string, fn func(model.Block) error) error {
+		for _, card := range cards {
+			if err := fn(card); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	t.Run("should write an event per card with a date", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("view")).
+			Return([]model.Block{view}, nil)
+		th.Store.EXPECT().StreamBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card"), gomock.Any()).
+			DoAndReturn(streamCards)
+
+		calendar, err := th.App.NewBoardCalendar(ctx, container, "board-1")
+		require.NoError(t, err)
+		require.NotNil(t, calendar)
+
+		var buf bytes.Buffer
+		require.NoError(t, calendar.Write(ctx, &buf))
+		require.Equal(t, strings.Join([]string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"PRODID:-//Mattermost//Focalboard//EN",
+			"CALSCALE:GREGORIAN",
+			"METHOD:PUBLISH",
+			"X-WR-CALNAME:Board",
+			"BEGIN:VEVENT",
+			"UID:card-1-due@focalboard",
+			"DTSTAMP:20210101T010000Z",
+			"DTSTART;VALUE=DATE:20210201",
+			"DTEND;VALUE=DATE:20210203",
+			`SUMMARY:First\, with a comma`,
+			`DESCRIPTION:First\, with a comma\nhttp://localhost:8000/board-1/view-1/card`,
+			" -1",
+			"URL:http://localhost:8000/board-1/view-1/card-1",
+			"END:VEVENT",
+			"BEGIN:VEVENT",
+			"UID:card-2-due@focalboard",
+			"DTSTAMP:20210101T010000Z",
+			"DTSTART:20210201T090000Z",
+			"SUMMARY:Second",
+			`DESCRIPTION:Second\nhttp://localhost:8000/board-1/view-1/card-2`,
+			"URL:http://localhost:8000/board-1/view-1/card-2",
+			"END:VEVENT",
+			"END:VCALENDAR",
+			"",
+		}, "\r\n"), buf.String())
+	})
+
+	t.Run("should name the date property of the events if there are several", func(t *testing.T) {
+		board := *board
+		board.Fields = map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "start", "name": "Start", "type": "date"},
+				map[string]interface{}{"id": "due", "name": "Due", "type": "date"},
+			},
+		}
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(&board, nil)
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("view")).
+			Return([]model.Block{}, nil)
+		th.Store.EXPECT().StreamBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card"), gomock.Any()).
+			DoAndReturn(streamCards)
+
+		calendar, err := th.App.NewBoardCalendar(ctx, container, "board-1")
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, calendar.Write(ctx, &buf))
+		require.Contains(t, buf.String(), "SUMMARY:Second (Due)\r\n")
+		require.Contains(t, buf.String(), "URL:http://localhost:8000/board-1\r\n")
+		require.NotContains(t, buf.String(), "(Start)")
+	})
+
+	t.Run("should return nil if the board doesn't exist", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(nil, nil)
+
+		calendar, err := th.App.NewBoardCalendar(ctx, container, "board-1")
+		require.NoError(t, err)
+		require.Nil(t, calendar)
+	})
+}
+
+func TestICalWriter(t *testing.T) {
+	t.Run("should fold the long lines", func(t *testing.T) {
+		value := strings.Repeat("é", 100)
+
+		var buf bytes.Buffer
+		writer := &icalWriter{w: bufio.NewWriter(&buf)}
+		writer.line("SUMMARY", value)
+		require.NoError(t, writer.err)
+		require.NoError(t, writer.w.Flush())
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+		require.Greater(t, len(lines), 1)
+		for _, line := range lines {
+			require.LessOrEqual(t, len(line), icalLineLength)
+		}
+		require.Equal(t, "SUMMARY:"+value, strings.ReplaceAll(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n ", ""))
+	})
+
+	t.Run("should escape the text values", func(t *testing.T) {
+		require.Equal(t, `a\\b\;c\,d\ne\nf`, icalText("a\\b;c,d\ne\r\nf"))
+	})
+}
+
+func TestCalendarFeed(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	board := &model.Block{ID: "board-1", RootID: "board-1", Type: "board"}
+
+	t.Run("should create the feed of a board", func(t *testing.T) {
+		var stored model.CalendarFeed
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().UpsertCalendarFeed(gomock.Eq(container), gomock.Any()).DoAndReturn(func(_ st.Container, feed model.CalendarFeed) error {
+			stored = feed
+			return nil
+		})
+		expectAuditEntry(th, model.AuditActionCreateCalendarFeed, "user-id-1", "board-1")
+
+		feed, err := th.App.CreateCalendarFeed(ctx, container, "board-1", "user-id-1")
+		require.NoError(t, err)
+		require.NotNil(t, feed)
+		require.NotEmpty(t, feed.Token)
+		require.Equal(t, stored, *feed)
+
+		th.Store.EXPECT().GetCalendarFeed(gomock.Eq(container), gomock.Eq("board-1")).Return(feed, nil).Times(2)
+		valid, err := th.App.IsValidCalendarToken(container, "board-1", feed.Token)
+		require.NoError(t, err)
+		require.True(t, valid)
+
+		valid, err = th.App.IsValidCalendarToken(container, "board-1", "other-token")
+		require.NoError(t, err)
+		require.False(t, valid)
+	})
+
+	t.Run("should return nil if the board doesn't exist", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(nil, nil)
+
+		feed, err := th.App.CreateCalendarFeed(ctx, container, "board-1", "user-id-1")
+		require.NoError(t, err)
+		require.Nil(t, feed)
+	})
+
+	t.Run("should not accept tokens once the feed is revoked", func(t *testing.T) {
+		th.Store.EXPECT().DeleteCalendarFeed(gomock.Eq(container), gomock.Eq("board-1")).Return(nil)
+		expectAuditEntry(th, model.AuditActionRevokeCalendarFeed, "user-id-1", "board-1")
+		require.NoError(t, th.App.RevokeCalendarFeed(container, "board-1", "user-id-1"))
+
+		th.Store.EXPECT().GetCalendarFeed(gomock.Eq(container), gomock.Eq("board-1")).Return(nil, sql.ErrNoRows)
+		valid, err := th.App.IsValidCalendarToken(container, "board-1", "token")
+		require.NoError(t, err)
+		require.False(t, valid)
+	})
+}
@@ -0,0 +1,473 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// orderKeyDigits are the digits of the order keys, in the order of
+// their bytes so that the keys compare as strings. A key is the
+// fractional part of a number in base 62, without the trailing zeros, so
+// that there is always a key between two others.
+const orderKeyDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// cardOrderMaxKeyLength is the length of the order keys over which the
+// order keys of the cards of the board are rebalanced.
+const cardOrderMaxKeyLength = 12
+
+// cardOrderMigrationSetting is the system setting recording that the
+// legacy card orders of the views were converted to order keys.
+const cardOrderMigrationSetting = "CardOrderMigrationComplete"
+
+// ErrInvalidCardMove is returned when a card is moved next to a block
+// that isn't a card of its board.
+var ErrInvalidCardMove = errors.New("invalid card move")
+
+var errInvalidOrderKeys = errors.New("invalid order keys")
+
+// orderKeyBetween returns a key between the two keys, an empty key
+// standing for the start or the end.
+func orderKeyBetween(a, b string) (string, error) {
+	if b != "" && a >= b {
+		return "", fmt.Errorf("%w: %q isn't before %q", errInvalidOrderKeys, a, b)
+	}
+	for _, key := range []string{a, b} {
+		if strings.HasSuffix(key, "0") || strings.Trim(key, orderKeyDigits) != "" {
+			return "", fmt.Errorf("%w: %q", errInvalidOrderKeys, key)
+		}
+	}
+	return midpointKey(a, b), nil
+}
+
+func midpointKey(a, b string) string {
+	digitAt := func(key string, i int) byte {
+		if i < len(key) {
+			return key[i]
+		}
+		return '0'
+	}
+
+	if b != "" {
+		// the common prefix is kept
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			if n > len(a) {
+				return b[:n] + midpointKey("", b[n:])
+			}
+			return b[:n] + midpointKey(a[n:], b[n:])
+		}
+	}
+
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(orderKeyDigits, a[0])
+	}
+	digitB := len(orderKeyDigits)
+	if b != "" {
+		digitB = strings.IndexByte(orderKeyDigits, b[0])
+	}
+	if digitB-digitA > 1 {
+		return string(orderKeyDigits[(digitA+digitB+1)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return string(orderKeyDigits[digitA]) + midpointKey(rest, "")
+}
+
+// evenOrderKeys returns n increasing keys spread evenly, each of them
+// leaving room for about as many keys before the next one as there are
+// digits.
+func evenOrderKeys(n int) []string {
+	base := int64(len(orderKeyDigits))
+	length := 1
+	space := base
+	for space < int64(n+1)*base {
+		length++
+		space *= base
+	}
+	step := space / int64(n+1)
+
+	keys := make([]string, n)
+	for i := range keys {
+		value := int64(i+1) * step
+		digits := make([]byte, length)
+		for j := length - 1; j >= 0; j-- {
+			digits[j] = orderKeyDigits[value%base]
+			value /= base
+		}
+		keys[i] = strings.TrimRight(string(digits), "0")
+	}
+	return keys
+}
+
+func cardOrderKey(card model.Block) string {
+	key, _ := card.Fields[model.CardOrderField].(string)
+	return key
+}
+
+// sortCardsByOrder sorts the cards by their order keys, the cards without
+// a key coming last in the legacy order of the views, and then in the
+// order of their creation.
+func sortCardsByOrder(cards []model.Block, legacyOrder []string) {
+	positions := make(map[string]int, len(legacyOrder))
+	for i, id := range legacyOrder {
+		if _, ok := positions[id]; !ok {
+			positions[id] = i
+		}
+	}
+	position := func(card model.Block) int {
+		if i, ok := positions[card.ID]; ok {
+			return i
+		}
+		return len(legacyOrder)
+	}
+
+	sort.SliceStable(cards, func(i, j int) bool {
+		keyI, keyJ := cardOrderKey(cards[i]), cardOrderKey(cards[j])
+		switch {
+		case keyI != "" && keyJ != "":
+			return keyI < keyJ
+		case keyI != "" || keyJ != "":
+			return keyI != ""
+		}
+		if positionI, positionJ := position(cards[i]), position(cards[j]); positionI != positionJ {
+			return positionI < positionJ
+		}
+		if cards[i].CreateAt != cards[j].CreateAt {
+			return cards[i].CreateAt < cards[j].CreateAt
+		}
+		return cards[i].ID < cards[j].ID
+	})
+}
+
+// legacyCardOrder returns the card order of the first view of the board
+// that has one.
+func legacyCardOrder(views []model.Block) []string {
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].CreateAt != views[j].CreateAt {
+			return views[i].CreateAt < views[j].CreateAt
+		}
+		return views[i].ID < views[j].ID
+	})
+	for _, view := range views {
+		cardOrder, _ := view.Fields[model.ViewCardOrderField].([]interface{})
+		if len(cardOrder) == 0 {
+			continue
+		}
+		ids := make([]string, 0, len(cardOrder))
+		for _, id := range cardOrder {
+			ids = append(ids, fmt.Sprint(id))
+		}
+		return ids
+	}
+	return nil
+}
+
+// getOrderedCards returns the cards of the board but the moved one,
+// sorted by their order keys. If one of the cards has no key, or an
+// invalid one, the keys of the cards are rebalanced first.
+func (a *App) getOrderedCards(ctx context.Context, c store.Container, boardID, movedID, userID string) ([]model.Block, error) {
+	cards, err := a.store.GetBlocksWithParentAndType(ctx, c, boardID, "card")
+	if err != nil {
+		return nil, err
+	}
+	cards = withoutCard(cards, movedID)
+	for _, card := range cards {
+		key := cardOrderKey(card)
+		if key == "" || strings.HasSuffix(key, "0") || strings.Trim(key, orderKeyDigits) != "" {
+			return a.rebalanceCardOrder(ctx, c, boardID, movedID, userID)
+		}
+	}
+	sortCardsByOrder(cards, nil)
+	return cards, nil
+}
+
+func withoutCard(cards []model.Block, cardID string) []model.Block {
+	others := make([]model.Block, 0, len(cards))
+	for _, card := range cards {
+		if card.ID != cardID {
+			others = append(others, card)
+		}
+	}
+	return others
+}
+
+// rebalanceCardOrder gives evenly spread order keys to the cards of the
+// board in their current order, and returns them in that order. The
+// moved card is left out, as it gets its key from the move, the history
+// of a block keeping one version per instant.
+func (a *App) rebalanceCardOrder(ctx context.Context, c store.Container, boardID, movedID, userID string) ([]model.Block, error) {
+	cards, err := a.store.GetBlocksWithParentAndType(ctx, c, boardID, "card")
+	if err != nil {
+		return nil, err
+	}
+	views, err := a.store.GetBlocksWithParentAndType(ctx, c, boardID, "view")
+	if err != nil {
+		return nil, err
+	}
+	cards = withoutCard(cards, movedID)
+	sortCardsByOrder(cards, legacyCardOrder(views))
+
+	changed := []model.Block{}
+	for i, key := range evenOrderKeys(len(cards)) {
+		if cardOrderKey(cards[i]) == key {
+			continue
+		}
+		cards[i] = copyBlockFields(cards[i])
+		cards[i].Fields[model.CardOrderField] = key
+		changed = append(changed, cards[i])
+	}
+	if len(changed) == 0 {
+		return cards, nil
+	}
+
+	if _, err := a.store.InsertBlocks(ctx, c, changed, userID); err != nil {
+		return nil, err
+	}
+	a.wsAdapter.BroadcastBlockChanges(c.WorkspaceID, changed)
+	a.logger.Debug("Rebalanced the card order",
+		mlog.String("boardID", boardID),
+		mlog.Int("card_count", len(changed)),
+	)
+	return cards, nil
+}
+
+// MoveCard gives the card an order key between the keys of its new
+// neighbors, and returns the moved card. If the key gets too long, the
+// keys of the cards of the board are rebalanced by
+// RebalanceCardOrders. Unless disabled, the legacy card orders of the
+// views of the board are updated too.
+func (a *App) MoveCard(ctx context.Context, c store.Container, cardID string, move model.CardMove, userID string) (*model.Block, error) {
+	card, err := a.store.GetBlock(ctx, c, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, sql.ErrNoRows
+	}
+	if card.Type != "card" {
+		return nil, fmt.Errorf("%w: %s isn't a card", ErrInvalidCardMove, cardID)
+	}
+	if move.BeforeID == cardID || move.AfterID == cardID {
+		return nil, fmt.Errorf("%w: a card can't be moved next to itself", ErrInvalidCardMove)
+	}
+	if err := a.checkBlocksWritable(c, userID, []model.Block{*card}); err != nil {
+		return nil, err
+	}
+	if err := a.checkBlocksNotArchived(ctx, c, []model.Block{*card}); err != nil {
+		return nil, err
+	}
+
+	others, err := a.getOrderedCards(ctx, c, card.ParentID, cardID, userID)
+	if err != nil {
+		return nil, err
+	}
+	indexOf := func(id string) (int, error) {
+		for i := range others {
+			if others[i].ID == id {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("%w: %s isn't a card of the board", ErrInvalidCardMove, id)
+	}
+
+	before, after := -1, len(others)
+	switch {
+	case move.BeforeID != "" && move.AfterID != "":
+		if before, err = indexOf(move.BeforeID); err != nil {
+			return nil, err
+		}
+		if after, err = indexOf(move.AfterID); err != nil {
+			return nil, err
+		}
+		if before >= after {
+			return nil, fmt.Errorf("%w: %s isn't before %s", ErrInvalidCardMove, move.BeforeID, move.AfterID)
+		}
+	case move.BeforeID != "":
+		if before, err = indexOf(move.BeforeID); err != nil {
+			return nil, err
+		}
+		after = before + 1
+	case move.AfterID != "":
+		if after, err = indexOf(move.AfterID); err != nil {
+			return nil, err
+		}
+		before = after - 1
+	default:
+		before = len(others) - 1
+	}
+
+	beforeKey, afterKey := "", ""
+	if before >= 0 {
+		beforeKey = cardOrderKey(others[before])
+	}
+	if after < len(others) {
+		afterKey = cardOrderKey(others[after])
+	}
+	key, err := orderKeyBetween(beforeKey, afterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.PatchBlock(ctx, c, cardID, &model.BlockPatch{
+		UpdatedFields: map[string]interface{}{model.CardOrderField: key},
+	}, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) > cardOrderMaxKeyLength {
+		a.cardOrderRebalance.add(c, card.ParentID)
+	}
+
+	if !a.config.DisableLegacyCardOrder {
+		previousID := ""
+		if before >= 0 {
+			previousID = others[before].ID
+		}
+		if err := a.updateLegacyCardOrders(ctx, c, card.ParentID, cardID, previousID, userID); err != nil {
+			return nil, err
+		}
+	}
+	return result.After, nil
+}
+
+// updateLegacyCardOrders moves the card right after the previous card in
+// the legacy card orders of the views of the board, or first if there is
+// no previous card.
+func (a *App) updateLegacyCardOrders(ctx context.Context, c store.Container, boardID, cardID, previousID, userID string) error {
+	views, err := a.store.GetBlocksWithParentAndType(ctx, c, boardID, "view")
+	if err != nil {
+		return err
+	}
+
+	for _, view := range views {
+		cardOrder, ok := view.Fields[model.ViewCardOrderField].([]interface{})
+		if !ok {
+			continue
+		}
+
+		updated := make([]interface{}, 0, len(cardOrder)+1)
+		if previousID == "" {
+			updated = append(updated, cardID)
+		}
+		inserted := previousID == ""
+		for _, id := range cardOrder {
+			if id == cardID {
+				continue
+			}
+			updated = append(updated, id)
+			if id == previousID {
+				updated = append(updated, cardID)
+				inserted = true
+			}
+		}
+		if !inserted {
+			updated = append(updated, cardID)
+		}
+
+		// the views are updated even if another user is editing them, as
+		// their card order is only kept for the older clients
+		if _, err := a.PatchBlock(ctx, c, view.ID, &model.BlockPatch{
+			UpdatedFields: map[string]interface{}{model.ViewCardOrderField: updated},
+		}, userID, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cardOrderRebalanceQueue is the boards whose order keys got too long,
+// to be rebalanced by RebalanceCardOrders.
+type cardOrderRebalanceQueue struct {
+	mutex  sync.Mutex
+	boards map[string]store.Container
+}
+
+func newCardOrderRebalanceQueue() *cardOrderRebalanceQueue {
+	return &cardOrderRebalanceQueue{boards: map[string]store.Container{}}
+}
+
+func (q *cardOrderRebalanceQueue) add(c store.Container, boardID string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.boards[boardID] = c
+}
+
+func (q *cardOrderRebalanceQueue) take() map[string]store.Container {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	boards := q.boards
+	q.boards = map[string]store.Container{}
+	return boards
+}
+
+// RebalanceCardOrders rebalances the order keys of the cards of the
+// boards where a move made a key too long.
+func (a *App) RebalanceCardOrders(ctx context.Context) error {
+	for boardID, c := range a.cardOrderRebalance.take() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := a.rebalanceCardOrder(ctx, c, boardID, "", systemUserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateCardOrders converts the legacy card orders of the views of all
+// the boards to order keys, once.
+func (a *App) MigrateCardOrders(ctx context.Context) error {
+	settings, err := a.store.GetSystemSettings()
+	if err != nil {
+		return err
+	}
+	if settings[cardOrderMigrationSetting] == "true" {
+		return nil
+	}
+
+	workspaceIDs, err := a.store.GetBoardWorkspaceIDs()
+	if err != nil {
+		return err
+	}
+	boardCount := 0
+	for _, workspaceID := range workspaceIDs {
+		c := store.Container{
+			WorkspaceID: workspaceID,
+		}
+		boards, err := a.store.GetBlocksWithType(ctx, c, "board")
+		if err != nil {
+			return err
+		}
+		for _, board := range boards {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if _, err := a.getOrderedCards(ctx, c, board.ID, "", systemUserID); err != nil {
+				return err
+			}
+			boardCount++
+		}
+	}
+
+	a.logger.Info("Migrated the card orders", mlog.Int("board_count", boardCount))
+	return a.store.SetSystemSetting(cardOrderMigrationSetting, "true")
+}
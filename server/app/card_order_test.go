@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderKeyBetween(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected string
+	}{
+		{"", "", "V"},
+		{"V", "", "l"},
+		{"", "V", "G"},
+		{"1", "2", "1V"},
+		{"1", "1V", "1G"},
+		{"z", "", "zV"},
+		{"", "1", "0V"},
+		{"A", "A1", "A0V"},
+	}
+	for _, tc := range testCases {
+		key, err := orderKeyBetween(tc.a, tc.b)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, key, "between %q and %q", tc.a, tc.b)
+		require.Greater(t, key, tc.a)
+		if tc.b != "" {
+			require.Less(t, key, tc.b)
+		}
+	}
+
+	t.Run("should keep finding keys between close keys", func(t *testing.T) {
+		a, b := "V", "W"
+		for i := 0; i < 200; i++ {
+			key, err := orderKeyBetween(a, b)
+			require.NoError(t, err)
+			require.Greater(t, key, a)
+			require.Less(t, key, b)
+			if i%2 == 0 {
+				a = key
+			} else {
+				b = key
+			}
+		}
+	})
+
+	t.Run("should fail with invalid keys", func(t *testing.T) {
+		for _, keys := range [][2]string{{"b", "a"}, {"a", "a"}, {"a0", ""}, {"a-", ""}} {
+			_, err := orderKeyBetween(keys[0], keys[1])
+			require.ErrorIs(t, err, errInvalidOrderKeys)
+		}
+	})
+}
+
+func TestEvenOrderKeys(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 61, 62, 1000} {
+		keys := evenOrderKeys(n)
+		require.Len(t, keys, n)
+		previous := ""
+		for _, key := range keys {
+			require.Greater(t, key, previous)
+			require.NotEqual(t, '0', key[len(key)-1])
+			_, err := orderKeyBetween(previous, key)
+			require.NoError(t, err)
+			previous = key
+		}
+	}
+}
+
+func TestSortCardsByOrder(t *testing.T) {
+	cards := []model.Block{
+		{ID: "unordered", CreateAt: 1, Fields: map[string]interface{}{}},
+		{ID: "legacy-2", CreateAt: 2, Fields: map[string]interface{}{}},
+		{ID: "keyed-2", Fields: map[string]interface{}{model.CardOrderField: "b"}},
+		{ID: "legacy-1", CreateAt: 3, Fields: map[string]interface{}{}},
+		{ID: "keyed-1", Fields: map[string]interface{}{model.CardOrderField: "a"}},
+	}
+	sortCardsByOrder(cards, []string{"legacy-1", "deleted", "legacy-2"})
+
+	ids := []string{}
+	for _, card := range cards {
+		ids = append(ids, card.ID)
+	}
+	require.Equal(t, []string{"keyed-1", "keyed-2", "legacy-1", "legacy-2", "unordered"}, ids)
+}
+
+func TestMoveCard(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectSingleWorkspace()
+	th.expectOpenBoards()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows).AnyTimes()
+	th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).
+		Return(&model.Block{ID: "board-1", Type: "board"}, nil).AnyTimes()
+
+	// the patches modify the returned blocks
+	card := func(id, key string) model.Block {
+		fields := map[string]interface{}{}
+		if key != "" {
+			fields[model.CardOrderField] = key
+		}
+		return model.Block{ID: id, ParentID: "board-1", RootID: "board-1", Type: "card", Fields: fields}
+	}
+	view := func() model.Block {
+		return model.Block{ID: "view-1", ParentID: "board-1", RootID: "board-1", Type: "view", Fields: map[string]interface{}{
+			model.ViewCardOrderField: []interface{}{"card-1", "card-2", "card-3"},
+		}}
+	}
+	expectBlocks := func(blocks ...func() model.Block) {
+		for _, block := range blocks {
+			block := block
+			th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq(block().ID)).
+				DoAndReturn(func(context.Context, st.Container, string) (*model.Block, error) {
+					b := block()
+					return &b, nil
+				}).AnyTimes()
+			th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(container), gomock.Eq(block().ID)).Return("board-1", nil).AnyTimes()
+		}
+	}
+
+	t.Run("should give the card a key between its neighbors", func(t *testing.T) {
+		cards := []model.Block{card("card-1", "1"), card("card-2", "2"), card("card-3", "3")}
+		expectBlocks(func() model.Block { return card("card-3", "3") }, view)
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card")).Return(cards, nil)
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("view")).Return([]model.Block{view()}, nil)
+
+		th.Store.EXPECT().PatchBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-3"), gomock.Eq(&model.BlockPatch{
+			UpdatedFields: map[string]interface{}{model.CardOrderField: "1V"},
+		}), gomock.Eq("user-id")).Return(nil)
+		th.Store.EXPECT().PatchBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("view-1"), gomock.Eq(&model.BlockPatch{
+			UpdatedFields: map[string]interface{}{model.ViewCardOrderField: []interface{}{"card-1", "card-3", "card-2"}},
+		}), gomock.Eq("user-id")).Return(nil)
+
+		_, err := th.App.MoveCard(ctx, container, "card-3", model.CardMove{BeforeID: "card-1"}, "user-id")
+		require.NoError(t, err)
+	})
+
+	t.Run("should rebalance the keys when a card has none", func(t *testing.T) {
+		cards := []model.Block{card("card-1", ""), card("card-2", ""), card("card-4", "")}
+		expectBlocks(func() model.Block { return card("card-4", "") })
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card")).Return(cards, nil).Times(2)
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("view")).Return([]model.Block{view()}, nil).Times(2)
+
+		// the moved card is left out of the rebalance
+		keys := evenOrderKeys(2)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-id")).
+			DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				require.Len(t, blocks, 2)
+				require.Equal(t, "card-1", blocks[0].ID)
+				require.Equal(t, "card-2", blocks[1].ID)
+				for i, block := range blocks {
+					require.Equal(t, keys[i], block.Fields[model.CardOrderField])
+				}
+				return &model.BlocksUpsertResult{}, nil
+			})
+
+		key, err := orderKeyBetween("", keys[0])
+		require.NoError(t, err)
+		th.Store.EXPECT().PatchBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-4"), gomock.Eq(&model.BlockPatch{
+			UpdatedFields: map[string]interface{}{model.CardOrderField: key},
+		}), gomock.Eq("user-id")).Return(nil)
+		th.Store.EXPECT().PatchBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("view-1"), gomock.Eq(&model.BlockPatch{
+			UpdatedFields: map[string]interface{}{model.ViewCardOrderField: []interface{}{"card-4", "card-1", "card-2", "card-3"}},
+		}), gomock.Eq("user-id")).Return(nil)
+
+		_, err = th.App.MoveCard(ctx, container, "card-4", model.CardMove{AfterID: "card-1"}, "user-id")
+		require.NoError(t, err)
+	})
+
+	t.Run("should fail if a neighbor isn't a card of the board", func(t *testing.T) {
+		expectBlocks(func() model.Block { return card("card-5", "1") })
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card")).
+			Return([]model.Block{card("card-5", "1")}, nil)
+
+		_, err := th.App.MoveCard(ctx, container, "card-5", model.CardMove{BeforeID: "card-6"}, "user-id")
+		require.ErrorIs(t, err, ErrInvalidCardMove)
+	})
+
+	t.Run("should queue the rebalance when the key gets too long", func(t *testing.T) {
+		cards := []model.Block{card("card-7", "1"), card("card-8", "100000000001")}
+		expectBlocks(func() model.Block { return card("card-9", "2") })
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card")).
+			Return(append(cards, card("card-9", "2")), nil)
+		th.Store.EXPECT().PatchBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-9"), gomock.Any(), gomock.Eq("user-id")).Return(nil)
+		th.App.config.DisableLegacyCardOrder = true
+		defer func() { th.App.config.DisableLegacyCardOrder = false }()
+
+		_, err := th.App.MoveCard(ctx, container, "card-9", model.CardMove{BeforeID: "card-7", AfterID: "card-8"}, "user-id")
+		require.NoError(t, err)
+		require.Contains(t, th.App.cardOrderRebalance.take(), "board-1")
+	})
+}
@@ -0,0 +1,231 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// ErrInvalidCardTemplate is returned when the default card template of a
+// workspace isn't a card template of the workspace.
+var ErrInvalidCardTemplate = errors.New("the block isn't a card template of the workspace")
+
+// SetDefaultCardTemplate sets the card template applied to the new blank
+// cards of the workspace, or clears it if the template ID is empty.
+func (a *App) SetDefaultCardTemplate(ctx context.Context, workspaceID, templateID, userID string) (*model.WorkspaceSettings, error) {
+	if templateID != "" {
+		template, err := a.store.GetBlock(ctx, store.Container{WorkspaceID: workspaceID}, templateID)
+		if err != nil {
+			return nil, err
+		}
+		if template == nil || template.Type != "card" || !isTemplate(*template) {
+			return nil, ErrInvalidCardTemplate
+		}
+	}
+
+	workspace, err := a.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil {
+		workspace = &model.Workspace{ID: workspaceID}
+	}
+
+	workspace.Settings.DefaultCardTemplateID = templateID
+	workspace.ModifiedBy = userID
+
+	if err := a.store.UpsertWorkspaceSettings(ctx, *workspace); err != nil {
+		return nil, err
+	}
+
+	a.recordAuditEntry(model.AuditActionSetDefaultCardTemplate, userID, workspaceID, workspaceID, map[string]interface{}{
+		"templateId": templateID,
+	})
+	return &workspace.Settings, nil
+}
+
+// ApplyDefaultCardTemplate returns the blocks with the content of the
+// default card template of the workspace copied into the new blank cards
+// among them, so that the copies are inserted along with the cards. A new
+// card is blank unless it references a template, or its content is among
+// the blocks. The cards of the boards that set their own default card
+// template are left as they are.
+func (a *App) ApplyDefaultCardTemplate(ctx context.Context, c store.Container, blocks []model.Block) ([]model.Block, error) {
+	workspace, err := a.GetWorkspace(ctx, c.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil || workspace.Settings.DefaultCardTemplateID == "" {
+		return blocks, nil
+	}
+
+	cards, err := a.blankCards(ctx, c, blocks)
+	if err != nil || len(cards) == 0 {
+		return blocks, err
+	}
+
+	templateID := workspace.Settings.DefaultCardTemplateID
+	template, err := a.store.GetBlock(ctx, c, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil || template.Type != "card" || !isTemplate(*template) {
+		// the template was deleted since it was set as the default
+		a.logger.Warn("Default card template not found",
+			mlog.String("workspaceID", c.WorkspaceID),
+			mlog.String("templateID", templateID),
+		)
+		return blocks, nil
+	}
+
+	children, err := a.store.GetBlocksWithParent(ctx, c, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := append([]model.Block{}, blocks...)
+	for _, i := range cards {
+		board, err := a.blockFromBatchOrStore(ctx, c, blocks, blocks[i].ParentID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, a.copyCardTemplate(c, *template, children, board, &result[i])...)
+	}
+	return result, nil
+}
+
+// blankCards returns the indexes of the new blank cards among the blocks
+// whose board doesn't set its own default card template.
+func (a *App) blankCards(ctx context.Context, c store.Container, blocks []model.Block) ([]int, error) {
+	withContent := map[string]bool{}
+	for _, block := range blocks {
+		withContent[block.ParentID] = true
+	}
+
+	cards := []int{}
+	for i, block := range blocks {
+		if block.Type != "card" || isTemplate(block) || withContent[block.ID] {
+			continue
+		}
+		if templateID, _ := block.Fields[model.CardTemplateField].(string); templateID != "" {
+			continue
+		}
+
+		existing, err := a.store.GetBlock(ctx, c, block.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			continue
+		}
+
+		board, err := a.blockFromBatchOrStore(ctx, c, blocks, block.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if board == nil {
+			continue
+		}
+		if _, ok := board.Fields[model.BoardDefaultCardTemplateField]; ok {
+			continue
+		}
+		cards = append(cards, i)
+	}
+	return cards, nil
+}
+
+// blockFromBatchOrStore returns the block from the blocks being inserted,
+// or from the store if it isn't among them.
+func (a *App) blockFromBatchOrStore(ctx context.Context, c store.Container, blocks []model.Block, blockID string) (*model.Block, error) {
+	for i := range blocks {
+		if blocks[i].ID == blockID {
+			return &blocks[i], nil
+		}
+	}
+	return a.store.GetBlock(ctx, c, blockID)
+}
+
+// copyCardTemplate sets the icon, the content order and the values of the
+// properties of the template on the card, the values set on the card
+// being kept, and returns the copies of the content of the template with
+// new IDs. The values of the properties the board of the card doesn't
+// have are left out.
+func (a *App) copyCardTemplate(c store.Container, template model.Block, children []model.Block, board *model.Block, card *model.Block) []model.Block {
+	idMap := make(map[string]string, len(children))
+	for _, child := range children {
+		idMap[child.ID] = utils.CreateGUID()
+	}
+	remap := func(id string) string {
+		if newID, ok := idMap[id]; ok {
+			return newID
+		}
+		return id
+	}
+
+	fields := make(map[string]interface{}, len(card.Fields)+3)
+	for key, value := range card.Fields {
+		fields[key] = value
+	}
+	fields[model.CardTemplateField] = template.ID
+	if icon, _ := fields["icon"].(string); icon == "" {
+		if templateIcon, ok := template.Fields["icon"]; ok {
+			fields["icon"] = templateIcon
+		}
+	}
+	if contentOrder, ok := template.Fields["contentOrder"].([]interface{}); ok {
+		fields["contentOrder"] = remapIDList(contentOrder, remap)
+	}
+
+	boardProperties := boardPropertyTypes(*board)
+	properties := map[string]interface{}{}
+	templateProperties, _ := template.Fields["properties"].(map[string]interface{})
+	for id, value := range templateProperties {
+		if _, ok := boardProperties[id]; ok {
+			properties[id] = value
+		}
+	}
+	cardProperties, _ := card.Fields["properties"].(map[string]interface{})
+	for id, value := range cardProperties {
+		properties[id] = value
+	}
+	fields["properties"] = properties
+	card.Fields = fields
+
+	copies := make([]model.Block, 0, len(children))
+	for _, child := range children {
+		if child.Type == "comment" {
+			continue
+		}
+		newChild := child
+		newChild.ID = idMap[child.ID]
+		newChild.ParentID = card.ID
+		newChild.RootID = card.RootID
+		newChild.CreateAt = card.CreateAt
+		newChild.UpdateAt = card.UpdateAt
+		newChild.Fields = make(map[string]interface{}, len(child.Fields))
+		for key, value := range child.Fields {
+			newChild.Fields[key] = value
+		}
+
+		if fileID, ok := child.Fields["fileId"].(string); ok && fileID != "" && child.RootID != card.RootID {
+			srcPath := filepath.Join(c.WorkspaceID, child.RootID, fileID)
+			dstPath := filepath.Join(c.WorkspaceID, card.RootID, fileID)
+			if err := a.filesBackend.CopyFile(srcPath, dstPath); err != nil {
+				// a missing file shouldn't prevent creating the card
+				a.logger.Warn("Unable to copy file of card template",
+					mlog.String("templateID", template.ID),
+					mlog.String("fileID", fileID),
+					mlog.Err(err),
+				)
+			}
+		}
+		copies = append(copies, newChild)
+	}
+	return copies
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDefaultCardTemplate(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	workspaceID := "workspace-id"
+	userID := "user-id"
+	container := store.Container{WorkspaceID: workspaceID}
+
+	t.Run("should set a card template as the default", func(t *testing.T) {
+		template := &model.Block{ID: "template-1", Type: "card", Fields: map[string]interface{}{"isTemplate": true}}
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "template-1").Return(template, nil)
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), workspaceID).Return(&model.Workspace{ID: workspaceID}, nil)
+		th.Store.EXPECT().UpsertWorkspaceSettings(gomock.Any(), model.Workspace{
+			ID:         workspaceID,
+			Settings:   model.WorkspaceSettings{DefaultCardTemplateID: "template-1"},
+			ModifiedBy: userID,
+		}).Return(nil)
+		expectAuditEntry(th, model.AuditActionSetDefaultCardTemplate, userID, workspaceID)
+
+		settings, err := th.App.SetDefaultCardTemplate(ctx, workspaceID, "template-1", userID)
+		require.NoError(t, err)
+		require.Equal(t, "template-1", settings.DefaultCardTemplateID)
+	})
+
+	t.Run("should clear the default", func(t *testing.T) {
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), workspaceID).Return(&model.Workspace{
+			ID:       workspaceID,
+			Settings: model.WorkspaceSettings{DefaultCardTemplateID: "template-1"},
+		}, nil)
+		th.Store.EXPECT().UpsertWorkspaceSettings(gomock.Any(), gomock.Any()).Return(nil)
+		expectAuditEntry(th, model.AuditActionSetDefaultCardTemplate, userID, workspaceID)
+
+		settings, err := th.App.SetDefaultCardTemplate(ctx, workspaceID, "", userID)
+		require.NoError(t, err)
+		require.Empty(t, settings.DefaultCardTemplateID)
+	})
+
+	t.Run("should reject the blocks that aren't card templates", func(t *testing.T) {
+		card := &model.Block{ID: "card-1", Type: "card", Fields: map[string]interface{}{}}
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "card-1").Return(card, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "missing").Return(nil, nil)
+
+		_, err := th.App.SetDefaultCardTemplate(ctx, workspaceID, "card-1", userID)
+		require.ErrorIs(t, err, ErrInvalidCardTemplate)
+		_, err = th.App.SetDefaultCardTemplate(ctx, workspaceID, "missing", userID)
+		require.ErrorIs(t, err, ErrInvalidCardTemplate)
+	})
+}
+
+func TestApplyDefaultCardTemplate(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	workspaceID := "workspace-id"
+	container := store.Container{WorkspaceID: workspaceID}
+	workspace := &model.Workspace{
+		ID:       workspaceID,
+		Settings: model.WorkspaceSettings{DefaultCardTemplateID: "template-1"},
+	}
+
+	board := &model.Block{ID: "board-1", RootID: "board-1", Type: "board", Fields: map[string]interface{}{
+		"cardProperties": []interface{}{
+			map[string]interface{}{"id": "status", "type": "select"},
+			map[string]interface{}{"id": "owner", "type": "person"},
+		},
+	}}
+	template := &model.Block{ID: "template-1", RootID: "board-1", ParentID: "board-1", Type: "card", Fields: map[string]interface{}{
+		"isTemplate":   true,
+		"icon":         "🐛",
+		"contentOrder": []interface{}{"text-1", []interface{}{"checkbox-1"}},
+		"properties":   map[string]interface{}{"status": "open", "owner": "user-1", "other-board": "value"},
+	}}
+	children := []model.Block{
+		{ID: "text-1", RootID: "board-1", ParentID: "template-1", Type: "text", Title: "Steps to reproduce"},
+		{ID: "checkbox-1", RootID: "board-1", ParentID: "template-1", Type: "checkbox", Title: "Triaged"},
+		{ID: "comment-1", RootID: "board-1", ParentID: "template-1", Type: "comment", Title: "not copied"},
+	}
+
+	t.Run("should copy the template into a new blank card", func(t *testing.T) {
+		card := model.Block{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card", Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"owner": "user-2"},
+		}}
+
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), workspaceID).Return(workspace, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "card-1").Return(nil, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "board-1").Return(board, nil).Times(2)
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "template-1").Return(template, nil)
+		th.Store.EXPECT().GetBlocksWithParent(gomock.Any(), container, "template-1").Return(children, nil)
+
+		blocks, err := th.App.ApplyDefaultCardTemplate(ctx, container, []model.Block{card})
+		require.NoError(t, err)
+		require.Len(t, blocks, 3)
+
+		newCard := blocks[0]
+		require.Equal(t, "template-1", newCard.Fields[model.CardTemplateField])
+		require.Equal(t, "🐛", newCard.Fields["icon"])
+		require.Equal(t, map[string]interface{}{"status": "open", "owner": "user-2"}, newCard.Fields["properties"])
+
+		text, checkbox := blocks[1], blocks[2]
+		require.Equal(t, "Steps to reproduce", text.Title)
+		require.Equal(t, "Triaged", checkbox.Title)
+		for _, child := range []model.Block{text, checkbox} {
+			require.Equal(t, "card-1", child.ParentID)
+			require.Equal(t, "board-1", child.RootID)
+			require.NotEqual(t, "text-1", child.ID)
+			require.NotEqual(t, "checkbox-1", child.ID)
+		}
+		require.Equal(t, []interface{}{text.ID, []interface{}{checkbox.ID}}, newCard.Fields["contentOrder"])
+
+		// the blocks of the request aren't modified
+		require.Nil(t, card.Fields[model.CardTemplateField])
+	})
+
+	t.Run("should leave the cards that aren't blank", func(t *testing.T) {
+		fromTemplate := model.Block{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card", Fields: map[string]interface{}{
+			model.CardTemplateField: "template-2",
+		}}
+		withContent := model.Block{ID: "card-2", RootID: "board-1", ParentID: "board-1", Type: "card", Fields: map[string]interface{}{}}
+		content := model.Block{ID: "text-2", RootID: "board-1", ParentID: "card-2", Type: "text"}
+		existing := model.Block{ID: "card-3", RootID: "board-1", ParentID: "board-1", Type: "card", Fields: map[string]interface{}{}}
+
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), workspaceID).Return(workspace, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "card-3").Return(&existing, nil)
+
+		blocks := []model.Block{fromTemplate, withContent, content, existing}
+		result, err := th.App.ApplyDefaultCardTemplate(ctx, container, blocks)
+		require.NoError(t, err)
+		require.Equal(t, blocks, result)
+	})
+
+	t.Run("should leave the cards of the boards with their own default", func(t *testing.T) {
+		overriding := model.Block{ID: "board-2", RootID: "board-2", Type: "board", Fields: map[string]interface{}{
+			model.BoardDefaultCardTemplateField: "",
+		}}
+		card := model.Block{ID: "card-1", RootID: "board-2", ParentID: "board-2", Type: "card", Fields: map[string]interface{}{}}
+
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), workspaceID).Return(workspace, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "card-1").Return(nil, nil)
+
+		blocks := []model.Block{overriding, card}
+		result, err := th.App.ApplyDefaultCardTemplate(ctx, container, blocks)
+		require.NoError(t, err)
+		require.Equal(t, blocks, result)
+	})
+
+	t.Run("should do nothing without a default", func(t *testing.T) {
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), workspaceID).Return(&model.Workspace{ID: workspaceID}, nil)
+
+		blocks := []model.Block{{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card"}}
+		result, err := th.App.ApplyDefaultCardTemplate(ctx, container, blocks)
+		require.NoError(t, err)
+		require.Equal(t, blocks, result)
+	})
+}
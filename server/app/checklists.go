@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// broadcastChecklistProgress broadcasts the checklist progress of the
+// cards of the changed checkboxes among the blocks. The progress is only
+// a hint for the board views, so failing to get it is logged and
+// otherwise ignored.
+func (a *App) broadcastChecklistProgress(ctx context.Context, c store.Container, blocks []model.Block) {
+	// the checkboxes are keyed by board, then by their parent
+	parents := map[string]map[string]bool{}
+	for _, block := range blocks {
+		if block.Type != "checkbox" {
+			continue
+		}
+		if parents[block.RootID] == nil {
+			parents[block.RootID] = map[string]bool{}
+		}
+		parents[block.RootID][block.ParentID] = true
+	}
+
+	for boardID, parentIDs := range parents {
+		progress, err := a.store.GetChecklistProgress(ctx, c, boardID)
+		if err != nil {
+			a.logger.Error("Unable to get the checklist progress", mlog.String("boardID", boardID), mlog.Err(err))
+			continue
+		}
+		cardProgress := make(map[string]model.ChecklistProgress, len(progress))
+		for _, p := range progress {
+			cardProgress[p.CardID] = p
+		}
+
+		cardIDs := map[string]bool{}
+		for parentID := range parentIDs {
+			cardID, err := a.checkboxCardID(ctx, c, parentID, cardProgress)
+			if err != nil {
+				a.logger.Error("Unable to get the card of a checkbox", mlog.String("parentID", parentID), mlog.Err(err))
+				continue
+			}
+			if cardID == "" || cardIDs[cardID] {
+				continue
+			}
+			cardIDs[cardID] = true
+
+			p, ok := cardProgress[cardID]
+			if !ok {
+				// the card has no checkboxes left
+				p = model.ChecklistProgress{CardID: cardID}
+			}
+			a.wsAdapter.BroadcastCardProgress(c.WorkspaceID, boardID, p)
+		}
+	}
+}
+
+// checkboxCardID returns the ID of the card of a checkbox, from its
+// parent, which is either the card or one of its content blocks, or an
+// empty string if the checkbox isn't part of a card.
+func (a *App) checkboxCardID(ctx context.Context, c store.Container, parentID string, cardProgress map[string]model.ChecklistProgress) (string, error) {
+	if _, ok := cardProgress[parentID]; ok {
+		return parentID, nil
+	}
+
+	parent, err := a.store.GetBlock(ctx, c, parentID)
+	if err != nil || parent == nil {
+		return "", err
+	}
+	if parent.Type == "card" {
+		return parent.ID, nil
+	}
+	if parent.ParentID == "" {
+		return "", nil
+	}
+
+	card, err := a.store.GetBlock(ctx, c, parent.ParentID)
+	if err != nil || card == nil || card.Type != "card" {
+		return "", err
+	}
+	return card.ID, nil
+}
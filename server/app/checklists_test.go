@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBoardMetadata(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	th.Store.EXPECT().GetBoardMetadata(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return([]model.CardMetadata{
+		{CardID: "card-1", CommentCount: 2},
+		{CardID: "card-2"},
+	}, nil)
+	th.Store.EXPECT().GetChecklistProgress(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return([]model.ChecklistProgress{
+		{CardID: "card-2", Checked: 1, Total: 3},
+	}, nil)
+
+	th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(&model.Block{ID: "board-1", Type: "board"}, nil)
+
+	metadata, err := th.App.GetBoardMetadata(ctx, container, "user-id-1", "board-1")
+	require.NoError(t, err)
+	require.Equal(t, []model.CardMetadata{
+		{CardID: "card-1", CommentCount: 2},
+		{CardID: "card-2", CheckedCount: 1, CheckboxCount: 3},
+	}, metadata)
+}
+
+func TestCheckboxCardID(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	cardProgress := map[string]model.ChecklistProgress{"card-1": {CardID: "card-1", Checked: 1, Total: 1}}
+
+	t.Run("card with checkboxes", func(t *testing.T) {
+		cardID, err := th.App.checkboxCardID(ctx, container, "card-1", cardProgress)
+		require.NoError(t, err)
+		require.Equal(t, "card-1", cardID)
+	})
+
+	t.Run("card without checkboxes", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-2")).
+			Return(&model.Block{ID: "card-2", Type: "card"}, nil)
+
+		cardID, err := th.App.checkboxCardID(ctx, container, "card-2", cardProgress)
+		require.NoError(t, err)
+		require.Equal(t, "card-2", cardID)
+	})
+
+	t.Run("checkbox nested in a text block", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("text-1")).
+			Return(&model.Block{ID: "text-1", ParentID: "card-2", Type: "text"}, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-2")).
+			Return(&model.Block{ID: "card-2", Type: "card"}, nil)
+
+		cardID, err := th.App.checkboxCardID(ctx, container, "text-1", cardProgress)
+		require.NoError(t, err)
+		require.Equal(t, "card-2", cardID)
+	})
+
+	t.Run("checkbox outside of a card", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).
+			Return(&model.Block{ID: "board-1", Type: "board"}, nil)
+
+		cardID, err := th.App.checkboxCardID(ctx, container, "board-1", cardProgress)
+		require.NoError(t, err)
+		require.Empty(t, cardID)
+	})
+}
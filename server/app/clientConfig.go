@@ -8,5 +8,14 @@ func (a *App) GetClientConfig() *model.ClientConfig {
 	return &model.ClientConfig{
 		Telemetry:   a.config.Telemetry,
 		TelemetryID: a.config.TelemetryID,
+		MaxFileSize: a.config.MaxFileSize,
+
+		OIDCLogin:     a.IsOIDCEnabled(),
+		PasswordLogin: a.IsPasswordLoginEnabled(),
 	}
 }
+
+// IsAPIDocsEnabled returns whether the docs UI of the API is served.
+func (a *App) IsAPIDocsEnabled() bool {
+	return a.config.EnableAPIDocs
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// InvalidCodeBlockError is returned when a field of a code block has a
+// value of the wrong type, or a language that is too long.
+type InvalidCodeBlockError struct {
+	BlockID string
+	Field   string
+}
+
+func (e InvalidCodeBlockError) Error() string {
+	return fmt.Sprintf("code block %s has an invalid %s", e.BlockID, e.Field)
+}
+
+// validateCodeBlock checks the language and wrap setting of the code
+// block, and normalizes its language, to the one of model.CodeLanguages
+// known by the name, or to lowercase.
+func (a *App) validateCodeBlock(_ store.Container, block *model.Block, _ func(blockID string) (*model.Block, error)) error {
+	if value, ok := block.Fields[model.CodeLanguageField]; ok && value != nil {
+		language, ok := value.(string)
+		if !ok || len(language) > model.CodeLanguageMaxLength {
+			return InvalidCodeBlockError{BlockID: block.ID, Field: model.CodeLanguageField}
+		}
+		block.Fields[model.CodeLanguageField] = model.NormalizeCodeLanguage(language)
+	}
+	if value, ok := block.Fields[model.CodeWrapField]; ok && value != nil {
+		if _, ok := value.(bool); !ok {
+			return InvalidCodeBlockError{BlockID: block.ID, Field: model.CodeWrapField}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCodeBlock(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	notStored := func(string) (*model.Block, error) { return nil, nil }
+	code := func(fields map[string]interface{}) model.Block {
+		return model.Block{ID: "code-1", RootID: "board-1", Type: model.CodeBlockType, Title: "fmt.Println(1)", Fields: fields}
+	}
+
+	t.Run("should normalize the languages", func(t *testing.T) {
+		testCases := map[string]string{
+			"go":       "go",
+			"Golang":   "go",
+			" JS ":     "javascript",
+			"yml":      "yaml",
+			"C++":      "cpp",
+			"Fortran":  "fortran",
+			"":         "",
+			"COBOL-85": "cobol-85",
+		}
+		for language, expected := range testCases {
+			block := code(map[string]interface{}{model.CodeLanguageField: language, model.CodeWrapField: true})
+			require.NoError(t, th.App.validateBlockFields(container, []model.Block{block}, notStored))
+			require.Equal(t, expected, block.Fields[model.CodeLanguageField], language)
+		}
+	})
+
+	t.Run("should accept blocks without settings", func(t *testing.T) {
+		require.NoError(t, th.App.validateBlockFields(container, []model.Block{code(map[string]interface{}{})}, notStored))
+	})
+
+	t.Run("should reject invalid languages", func(t *testing.T) {
+		for _, language := range []interface{}{42, []interface{}{"go"}, string(make([]byte, model.CodeLanguageMaxLength+1))} {
+			block := code(map[string]interface{}{model.CodeLanguageField: language})
+			err := th.App.validateBlockFields(container, []model.Block{block}, notStored)
+			require.Equal(t, InvalidCodeBlockError{BlockID: "code-1", Field: model.CodeLanguageField}, err)
+		}
+	})
+
+	t.Run("should reject invalid wrap settings", func(t *testing.T) {
+		block := code(map[string]interface{}{model.CodeWrapField: "yes"})
+		err := th.App.validateBlockFields(container, []model.Block{block}, notStored)
+		require.Equal(t, InvalidCodeBlockError{BlockID: "code-1", Field: model.CodeWrapField}, err)
+	})
+}
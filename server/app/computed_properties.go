@@ -0,0 +1,329 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// InvalidComputedPropertyError is returned when the definition of a
+// computed property of a board is invalid, or the computed properties of
+// the board depend on each other in a cycle.
+type InvalidComputedPropertyError struct {
+	BlockID    string
+	PropertyID string
+	Reason     string
+}
+
+func (e InvalidComputedPropertyError) Error() string {
+	return fmt.Sprintf("invalid computed property %s of board %s: %s", e.PropertyID, e.BlockID, e.Reason)
+}
+
+// computedProperty is a computed card property of a board.
+type computedProperty struct {
+	id         string
+	definition model.ComputedProperty
+}
+
+// computedProperties returns the computed properties of the board, in
+// the order they can be evaluated in, the properties they depend on
+// first. It fails with an InvalidComputedPropertyError if a definition
+// is invalid or the properties depend on each other in a cycle.
+func computedProperties(board model.Block) ([]computedProperty, error) {
+	invalid := func(propertyID, format string, args ...interface{}) error {
+		return InvalidComputedPropertyError{BlockID: board.ID, PropertyID: propertyID, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	propertyTypes := map[string]string{}
+	for _, property := range csvProperties(board, nil) {
+		propertyTypes[property.id] = property.propertyType
+	}
+
+	byID := map[string]computedProperty{}
+	ids := []string{}
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		template, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if propertyType, _ := template["type"].(string); propertyType != model.PropertyTypeComputed {
+			continue
+		}
+
+		id, _ := template["id"].(string)
+		definition, err := model.ComputedPropertyFromTemplate(template)
+		if err != nil {
+			return nil, invalid(id, "%s", err)
+		}
+		relationID := definition.RelationPropertyID
+		if relationID != "" && propertyTypes[relationID] != model.PropertyTypeRelation {
+			return nil, invalid(id, "%s isn't a relation property of the board", relationID)
+		}
+		if relationID == "" {
+			// the card itself is aggregated, so its properties are known
+			for _, reference := range definition.References() {
+				if _, ok := propertyTypes[reference]; !ok && !isComputedCardField(reference) {
+					return nil, invalid(id, "%s isn't a property of the board", reference)
+				}
+			}
+		}
+
+		byID[id] = computedProperty{id: id, definition: *definition}
+		ids = append(ids, id)
+	}
+
+	// depth-first ordering, the properties being visited when one of
+	// their dependencies is reached again forming a cycle
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	ordered := make([]computedProperty, 0, len(ids))
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return invalid(id, "the computed properties depend on each other in a cycle")
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		for _, reference := range byID[id].definition.References() {
+			if _, ok := byID[reference]; ok {
+				if err := visit(reference); err != nil {
+					return err
+				}
+			}
+		}
+		state[id] = visited
+		ordered = append(ordered, byID[id])
+		return nil
+	}
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// isComputedCardField tells if the source of a computed property is a
+// field of the cards rather than one of their properties.
+func isComputedCardField(source string) bool {
+	return source == model.ComputedSourceCreateAt || source == model.ComputedSourceUpdateAt
+}
+
+// validateComputedProperties checks the definitions of the computed
+// properties of the boards among the blocks.
+func validateComputedProperties(blocks []model.Block) error {
+	for _, board := range blocks {
+		if board.Type != "board" || board.DeleteAt != 0 {
+			continue
+		}
+		if _, err := computedProperties(board); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// computedValues evaluates the computed properties of the cards of a
+// board, reading their value from their stored properties or from the
+// values already computed for the cards of the board.
+type computedValues struct {
+	cards  map[string]model.Block
+	values map[string]map[string]float64
+	now    int64
+}
+
+// value returns the value of the property of the card as a number.
+func (v *computedValues) value(card model.Block, propertyID string) (float64, bool) {
+	switch propertyID {
+	case model.ComputedSourceCreateAt:
+		return float64(card.CreateAt), true
+	case model.ComputedSourceUpdateAt:
+		return float64(card.UpdateAt), true
+	}
+	if computed, ok := v.values[card.ID]; ok {
+		if value, ok := computed[propertyID]; ok {
+			return value, true
+		}
+	}
+
+	properties, _ := card.Fields["properties"].(map[string]interface{})
+	value := properties[propertyID]
+	if number, ok := parseNumberProperty(value); ok {
+		return number, true
+	}
+	if date, ok := parseDateProperty(value); ok {
+		return float64(date.From), true
+	}
+	return 0, false
+}
+
+// matchesComputedFilter tells if the card passes the filter of a
+// computed property.
+func matchesComputedFilter(card model.Block, filter *model.ComputedPropertyFilter) bool {
+	if filter == nil {
+		return true
+	}
+	properties, _ := card.Fields["properties"].(map[string]interface{})
+	switch value := properties[filter.PropertyID].(type) {
+	case string:
+		return value == filter.Value
+	case []interface{}:
+		for _, item := range value {
+			if item == filter.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compute returns the value of the computed property for the card, and
+// false if it has none, like the average of no values.
+func (v *computedValues) compute(card model.Block, property computedProperty) (float64, bool) {
+	definition := property.definition
+	aggregated := []model.Block{card}
+	if definition.RelationPropertyID != "" {
+		properties, _ := card.Fields["properties"].(map[string]interface{})
+		targetIDs, _ := model.RelationIDs(properties[definition.RelationPropertyID])
+		aggregated = make([]model.Block, 0, len(targetIDs))
+		for _, targetID := range targetIDs {
+			if target, ok := v.cards[targetID]; ok {
+				aggregated = append(aggregated, target)
+			}
+		}
+	}
+
+	values := []float64{}
+	count := 0
+	for _, block := range aggregated {
+		if !matchesComputedFilter(block, definition.Filter) {
+			continue
+		}
+		if definition.SourcePropertyID == "" {
+			count++
+			continue
+		}
+		if value, ok := v.value(block, definition.SourcePropertyID); ok {
+			values = append(values, value)
+			count++
+		}
+	}
+
+	switch definition.Aggregation {
+	case model.ComputedAggregationCount:
+		return float64(count), true
+	case model.ComputedAggregationSum:
+		sum := 0.0
+		for _, value := range values {
+			sum += value
+		}
+		return sum, true
+	}
+
+	if len(values) == 0 {
+		return 0, false
+	}
+	result := values[0]
+	switch definition.Aggregation {
+	case model.ComputedAggregationAverage:
+		for _, value := range values[1:] {
+			result += value
+		}
+		result /= float64(len(values))
+	case model.ComputedAggregationMin:
+		for _, value := range values[1:] {
+			result = math.Min(result, value)
+		}
+	case model.ComputedAggregationMax:
+		for _, value := range values[1:] {
+			result = math.Max(result, value)
+		}
+	case model.ComputedAggregationDaysSince:
+		result = math.Floor(float64(v.now-int64(result)) / float64((24 * time.Hour).Milliseconds()))
+	}
+	return result, true
+}
+
+// computeCardValues returns the values of the computed properties of the
+// cards of the board, by card and property ID, or nil if the board has
+// none. The cards of the board are read at once, and so are the linked
+// cards of other boards, only the ones the user can view being
+// aggregated.
+func (a *App) computeCardValues(ctx context.Context, c store.Container, userID string, board model.Block) (map[string]map[string]float64, error) {
+	properties, err := computedProperties(board)
+	if err != nil || len(properties) == 0 {
+		return nil, err
+	}
+
+	cards, err := a.store.GetBlocksWithParentAndType(ctx, c, board.ID, "card")
+	if err != nil {
+		return nil, err
+	}
+
+	v := &computedValues{
+		cards:  make(map[string]model.Block, len(cards)),
+		values: make(map[string]map[string]float64, len(cards)),
+		now:    utils.GetMillis(),
+	}
+	for _, card := range cards {
+		v.cards[card.ID] = card
+	}
+
+	linkedIDs := []string{}
+	seen := map[string]bool{}
+	for _, property := range properties {
+		relationID := property.definition.RelationPropertyID
+		if relationID == "" {
+			continue
+		}
+		for _, card := range cards {
+			values, _ := card.Fields["properties"].(map[string]interface{})
+			targetIDs, _ := model.RelationIDs(values[relationID])
+			for _, targetID := range targetIDs {
+				if _, ok := v.cards[targetID]; !ok && !seen[targetID] {
+					seen[targetID] = true
+					linkedIDs = append(linkedIDs, targetID)
+				}
+			}
+		}
+	}
+	if len(linkedIDs) > 0 {
+		linked, err := a.store.GetBlocksByIDs(ctx, c, linkedIDs)
+		if err != nil {
+			return nil, err
+		}
+		if linked, err = a.FilterBlocksForUser(c, userID, linked); err != nil {
+			return nil, err
+		}
+		for _, card := range linked {
+			if card.Type == "card" {
+				v.cards[card.ID] = card
+			}
+		}
+	}
+
+	for _, property := range properties {
+		for _, card := range cards {
+			value, ok := v.compute(card, property)
+			if !ok {
+				continue
+			}
+			if v.values[card.ID] == nil {
+				v.values[card.ID] = map[string]float64{}
+			}
+			v.values[card.ID][property.id] = value
+		}
+	}
+	return v.values, nil
+}
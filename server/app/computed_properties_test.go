@@ -0,0 +1,173 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func computedBoard(cardProperties ...interface{}) model.Block {
+	return model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Fields: map[string]interface{}{
+			"cardProperties": append([]interface{}{
+				map[string]interface{}{"id": "related", "type": model.PropertyTypeRelation},
+				map[string]interface{}{"id": "estimate", "type": "number"},
+				map[string]interface{}{"id": "status", "type": "select"},
+			}, cardProperties...),
+		},
+	}
+}
+
+func computedTemplate(id string, computed map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"id": id, "type": model.PropertyTypeComputed, "computed": computed}
+}
+
+func TestComputedProperties(t *testing.T) {
+	t.Run("should order the properties by dependency", func(t *testing.T) {
+		board := computedBoard(
+			computedTemplate("double", map[string]interface{}{"sourcePropertyId": "total", "aggregation": "sum"}),
+			computedTemplate("total", map[string]interface{}{"relationPropertyId": "related", "sourcePropertyId": "estimate", "aggregation": "sum"}),
+		)
+
+		properties, err := computedProperties(board)
+		require.NoError(t, err)
+		require.Len(t, properties, 2)
+		require.Equal(t, "total", properties[0].id)
+		require.Equal(t, "double", properties[1].id)
+	})
+
+	testCases := []struct {
+		name      string
+		templates []interface{}
+	}{
+		{"unknown aggregation", []interface{}{
+			computedTemplate("total", map[string]interface{}{"sourcePropertyId": "estimate", "aggregation": "median"}),
+		}},
+		{"sum without source", []interface{}{
+			computedTemplate("total", map[string]interface{}{"relationPropertyId": "related", "aggregation": "sum"}),
+		}},
+		{"daysSince of linked cards", []interface{}{
+			computedTemplate("age", map[string]interface{}{"relationPropertyId": "related", "sourcePropertyId": "createAt", "aggregation": "daysSince"}),
+		}},
+		{"relation that isn't a relation property", []interface{}{
+			computedTemplate("total", map[string]interface{}{"relationPropertyId": "status", "sourcePropertyId": "estimate", "aggregation": "sum"}),
+		}},
+		{"unknown source of the card", []interface{}{
+			computedTemplate("total", map[string]interface{}{"sourcePropertyId": "unknown", "aggregation": "max"}),
+		}},
+		{"invalid definition", []interface{}{
+			map[string]interface{}{"id": "total", "type": model.PropertyTypeComputed, "computed": "sum"},
+		}},
+		{"self reference", []interface{}{
+			computedTemplate("total", map[string]interface{}{"relationPropertyId": "related", "sourcePropertyId": "total", "aggregation": "sum"}),
+		}},
+		{"cycle", []interface{}{
+			computedTemplate("a", map[string]interface{}{"relationPropertyId": "related", "sourcePropertyId": "b", "aggregation": "sum"}),
+			computedTemplate("b", map[string]interface{}{"sourcePropertyId": "c", "aggregation": "sum"}),
+			computedTemplate("c", map[string]interface{}{"aggregation": "count", "filter": map[string]interface{}{"propertyId": "a", "value": "1"}}),
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run("should reject "+tc.name, func(t *testing.T) {
+			board := computedBoard(tc.templates...)
+
+			err := validateComputedProperties([]model.Block{board})
+			var computedErr InvalidComputedPropertyError
+			require.ErrorAs(t, err, &computedErr)
+			require.Equal(t, "board-1", computedErr.BlockID)
+		})
+	}
+}
+
+func TestGetBoardMetadataComputedValues(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectOpenBoards()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := computedBoard(
+		computedTemplate("total", map[string]interface{}{"relationPropertyId": "related", "sourcePropertyId": "estimate", "aggregation": "sum"}),
+		computedTemplate("done", map[string]interface{}{"relationPropertyId": "related", "aggregation": "count", "filter": map[string]interface{}{"propertyId": "status", "value": "done"}}),
+		computedTemplate("average", map[string]interface{}{"relationPropertyId": "related", "sourcePropertyId": "estimate", "aggregation": "avg"}),
+		computedTemplate("age", map[string]interface{}{"sourcePropertyId": "createAt", "aggregation": "daysSince"}),
+		computedTemplate("double", map[string]interface{}{"relationPropertyId": "related", "sourcePropertyId": "total", "aggregation": "sum"}),
+	)
+	card := func(id, boardID string, properties map[string]interface{}) model.Block {
+		return model.Block{
+			ID:       id,
+			ParentID: boardID,
+			RootID:   boardID,
+			Type:     "card",
+			CreateAt: utils.GetMillis() - (3*24*time.Hour + time.Hour).Milliseconds(),
+			Fields:   map[string]interface{}{"properties": properties},
+		}
+	}
+	parent := card("parent", "board-1", map[string]interface{}{"related": []interface{}{"child-1", "child-2", "other-board"}})
+	grandparent := card("grandparent", "board-1", map[string]interface{}{"related": []interface{}{"parent"}})
+	child1 := card("child-1", "board-1", map[string]interface{}{"estimate": "3", "status": "done"})
+	child2 := card("child-2", "board-1", map[string]interface{}{"estimate": "5", "status": "todo"})
+	otherBoard := card("other-board", "board-2", map[string]interface{}{"estimate": "2", "status": "done"})
+
+	th.Store.EXPECT().GetBoardMetadata(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return([]model.CardMetadata{
+		{CardID: "child-1"}, {CardID: "child-2"}, {CardID: "grandparent"}, {CardID: "parent"},
+	}, nil)
+	th.Store.EXPECT().GetChecklistProgress(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return([]model.ChecklistProgress{}, nil)
+	th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(&board, nil)
+	th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card")).
+		Return([]model.Block{child1, child2, grandparent, parent}, nil)
+	th.Store.EXPECT().GetBlocksByIDs(gomock.Any(), gomock.Eq(container), gomock.Eq([]string{"other-board"})).Return([]model.Block{otherBoard}, nil)
+
+	metadata, err := th.App.GetBoardMetadata(ctx, container, "user-id-1", "board-1")
+	require.NoError(t, err)
+	require.Len(t, metadata, 4)
+
+	values := map[string]map[string]float64{}
+	for _, m := range metadata {
+		values[m.CardID] = m.ComputedValues
+	}
+	require.Equal(t, map[string]float64{"total": 10, "done": 2, "average": 10.0 / 3, "age": 3, "double": 0}, values["parent"])
+	require.Equal(t, map[string]float64{"total": 0, "done": 0, "age": 3, "double": 10}, values["grandparent"])
+	require.Equal(t, map[string]float64{"total": 0, "done": 0, "age": 3, "double": 0}, values["child-1"])
+}
+
+func TestComputedValuesOfRestrictedBoards(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := computedBoard(
+		computedTemplate("total", map[string]interface{}{"relationPropertyId": "related", "sourcePropertyId": "estimate", "aggregation": "sum"}),
+	)
+	parent := model.Block{ID: "parent", RootID: "board-1", Type: "card", Fields: map[string]interface{}{
+		"properties": map[string]interface{}{"related": []interface{}{"hidden"}},
+	}}
+	hidden := model.Block{ID: "hidden", RootID: "board-2", Type: "card", Fields: map[string]interface{}{
+		"properties": map[string]interface{}{"estimate": "8"},
+	}}
+
+	th.Store.EXPECT().GetUserByID(gomock.Eq("user-id-1")).Return(&model.User{ID: "user-id-1"}, nil)
+	th.Store.EXPECT().GetBoardRolesForUser(gomock.Eq(container), gomock.Eq("user-id-1")).Return(map[string]string{"board-2": ""}, nil)
+	th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card")).Return([]model.Block{parent}, nil)
+	th.Store.EXPECT().GetBlocksByIDs(gomock.Any(), gomock.Eq(container), gomock.Eq([]string{"hidden"})).Return([]model.Block{hidden}, nil)
+
+	values, err := th.App.computeCardValues(ctx, container, "user-id-1", board)
+	require.NoError(t, err)
+	require.Equal(t, map[string]float64{"total": 0}, values["parent"])
+}
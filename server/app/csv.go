@@ -0,0 +1,253 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+const (
+	csvDateFormat     = "2006-01-02"
+	csvDateTimeFormat = time.RFC3339
+)
+
+// csvProperty is a card property exported as a CSV column.
+type csvProperty struct {
+	id           string
+	name         string
+	propertyType string
+	options      map[string]string
+}
+
+// BoardCSVExport exports the cards of a board as CSV, with a column per
+// card property of the view.
+type BoardCSVExport struct {
+	container  store.Container
+	board      model.Block
+	view       *model.Block
+	properties []csvProperty
+	usernames  map[string]string
+	store      store.Store
+}
+
+// NewBoardCSVExport prepares the CSV export of the board, with the
+// visible properties of the view in their order, or all the properties
+// if viewID is empty. It returns nil if the board or the view don't
+// exist.
+func (a *App) NewBoardCSVExport(ctx context.Context, c store.Container, boardID, viewID string) (*BoardCSVExport, error) {
+	board, err := a.store.GetBlock(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil || board.Type != "board" {
+		return nil, nil
+	}
+
+	var view *model.Block
+	if viewID != "" {
+		view, err = a.store.GetBlock(ctx, c, viewID)
+		if err != nil {
+			return nil, err
+		}
+		if view == nil || view.Type != "view" || view.RootID != board.ID {
+			return nil, nil
+		}
+	}
+
+	usernames := map[string]string{}
+	users, err := a.store.GetUsersByWorkspace(c.WorkspaceID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	return &BoardCSVExport{
+		container:  c,
+		board:      *board,
+		view:       view,
+		properties: csvProperties(*board, view),
+		usernames:  usernames,
+		store:      a.store,
+	}, nil
+}
+
+// Filename returns the name of the exported file.
+func (e *BoardCSVExport) Filename() string {
+	title := e.board.Title
+	if e.view != nil && e.view.Title != "" {
+		title = e.view.Title
+	}
+	if title == "" {
+		title = "Untitled"
+	}
+	return strings.NewReplacer(`"`, "", "/", "_", `\`, "_").Replace(title) + ".csv"
+}
+
+// Write writes the header and then the cards one row at a time, so that
+// the rows are sent as they are read from the store.
+func (e *BoardCSVExport) Write(ctx context.Context, w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"Title"}
+	for _, property := range e.properties {
+		header = append(header, property.name)
+	}
+	header = append(header, "Created", "Updated")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	err := e.store.StreamBlocksWithParentAndType(ctx, e.container, e.board.ID, "card", func(card model.Block) error {
+		if isTemplate, _ := card.Fields["isTemplate"].(bool); isTemplate {
+			return nil
+		}
+
+		values, _ := card.Fields["properties"].(map[string]interface{})
+		row := []string{card.Title}
+		for _, property := range e.properties {
+			row = append(row, e.propertyValue(card, property, values[property.id]))
+		}
+		row = append(row, formatCSVTime(card.CreateAt, true), formatCSVTime(card.UpdateAt, true))
+
+		return writer.Write(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (e *BoardCSVExport) propertyValue(card model.Block, property csvProperty, value interface{}) string {
+	switch property.propertyType {
+	case "select":
+		if id, ok := value.(string); ok {
+			return property.options[id]
+		}
+	case "multiSelect":
+		ids, _ := value.([]interface{})
+		names := []string{}
+		for _, id := range ids {
+			if name, ok := property.options[fmt.Sprint(id)]; ok {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ";")
+	case "person":
+		if id, ok := value.(string); ok {
+			return e.username(id)
+		}
+	case "date":
+		date, ok := parseDateProperty(value)
+		if !ok {
+			return ""
+		}
+		if date.To != 0 && date.From != 0 {
+			return formatCSVTime(date.From, date.IncludeTime) + "/" + formatCSVTime(date.To, date.IncludeTime)
+		}
+		return formatCSVTime(date.From+date.To, date.IncludeTime)
+	case "createdTime":
+		return formatCSVTime(card.CreateAt, true)
+	case "updatedTime":
+		return formatCSVTime(card.UpdateAt, true)
+	case "createdBy":
+		return e.username(card.CreatedBy)
+	case "updatedBy":
+		return e.username(card.ModifiedBy)
+	case "checkbox":
+		if checked, ok := value.(string); ok {
+			return strconv.FormatBool(checked == "true")
+		}
+		return "false"
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// username returns the username of the user, or the ID for the users
+// that aren't members of the workspace.
+func (e *BoardCSVExport) username(userID string) string {
+	if username, ok := e.usernames[userID]; ok {
+		return username
+	}
+	return userID
+}
+
+// csvProperties returns the card properties of the board to export, in
+// the order of the visible properties of the view if there is one.
+func csvProperties(board model.Block, view *model.Block) []csvProperty {
+	byID := map[string]csvProperty{}
+	ordered := []csvProperty{}
+
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		template, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		property := csvProperty{
+			options: map[string]string{},
+		}
+		property.id, _ = template["id"].(string)
+		property.name, _ = template["name"].(string)
+		property.propertyType, _ = template["type"].(string)
+
+		options, _ := template["options"].([]interface{})
+		for _, item := range options {
+			if option, ok := item.(map[string]interface{}); ok {
+				id, _ := option["id"].(string)
+				value, _ := option["value"].(string)
+				property.options[id] = value
+			}
+		}
+
+		byID[property.id] = property
+		ordered = append(ordered, property)
+	}
+
+	if view == nil {
+		return ordered
+	}
+
+	visible := []csvProperty{}
+	visibleIDs, _ := view.Fields["visiblePropertyIds"].([]interface{})
+	for _, id := range visibleIDs {
+		if property, ok := byID[fmt.Sprint(id)]; ok {
+			visible = append(visible, property)
+		}
+	}
+	return visible
+}
+
+// formatCSVTime formats the timestamp in milliseconds as an ISO-8601
+// date, with the time if includeTime is true.
+func formatCSVTime(millis int64, includeTime bool) string {
+	if millis == 0 {
+		return ""
+	}
+	t := time.Unix(0, millis*int64(time.Millisecond)).UTC()
+	if includeTime {
+		return t.Format(csvDateTimeFormat)
+	}
+	return t.Format(csvDateFormat)
+}
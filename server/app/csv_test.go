@@ -0,0 +1,148 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardCSVExport(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := &model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Title:  "Board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+					map[string]interface{}{"id": "done", "value": "Done"},
+				}},
+				map[string]interface{}{"id": "tags", "name": "Tags", "type": "multiSelect", "options": []interface{}{
+					map[string]interface{}{"id": "a", "value": "A"},
+					map[string]interface{}{"id": "b", "value": "B"},
+				}},
+				map[string]interface{}{"id": "owner", "name": "Owner", "type": "person"},
+				map[string]interface{}{"id": "due", "name": "Due", "type": "date"},
+				map[string]interface{}{"id": "estimate", "name": "Estimate", "type": "number"},
+			},
+		},
+	}
+	view := &model.Block{
+		ID:       "view-1",
+		ParentID: "board-1",
+		RootID:   "board-1",
+		Type:     "view",
+		Title:    "Table",
+		Fields: map[string]interface{}{
+			"visiblePropertyIds": []interface{}{"due", "owner", "tags", "status"},
+		},
+	}
+	cards := []model.Block{
+		{
+			ID:       "card-1",
+			ParentID: "board-1",
+			RootID:   "board-1",
+			Type:     "card",
+			Title:    "First, with a comma",
+			CreateAt: 1609459200000,
+			UpdateAt: 1609462800000,
+			Fields: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"status":   "done",
+					"tags":     []interface{}{"a", "b"},
+					"owner":    "user-1",
+					"due":      `{"from":1612137600000,"to":1612224000000}`,
+					"estimate": "3",
+				},
+			},
+		},
+		{
+			ID:       "card-2",
+			ParentID: "board-1",
+			RootID:   "board-1",
+			Type:     "card",
+			Title:    "Second",
+			CreateAt: 1609459200000,
+			UpdateAt: 1609459200000,
+			Fields: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"owner": "unknown-user",
+					"due":   `{"from":1612137600000,"includeTime":true}`,
+				},
+			},
+		},
+	}
+
+	streamCards := func(_ context.Context, _ st.Container, _, _ string, fn func(model.Block) error) error {
+		for _, card := range cards {
+			if err := fn(card); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	t.Run("should export the visible properties of the view", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("view-1")).Return(view, nil)
+		th.Store.EXPECT().GetUsersByWorkspace(gomock.Eq("0")).Return([]*model.User{{ID: "user-1", Username: "alice"}}, nil)
+		th.Store.EXPECT().StreamBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card"), gomock.Any()).
+			DoAndReturn(streamCards)
+
+		export, err := th.App.NewBoardCSVExport(ctx, container, "board-1", "view-1")
+		require.NoError(t, err)
+		require.NotNil(t, export)
+		require.Equal(t, "Table.csv", export.Filename())
+
+		var buf bytes.Buffer
+		require.NoError(t, export.Write(ctx, &buf))
+		require.Equal(t, "Title,Due,Owner,Tags,Status,Created,Updated\n"+
+			"\"First, with a comma\",2021-02-01/2021-02-02,alice,A;B,Done,2021-01-01T00:00:00Z,2021-01-01T01:00:00Z\n"+
+			"Second,2021-02-01T00:00:00Z,unknown-user,,,2021-01-01T00:00:00Z,2021-01-01T00:00:00Z\n", buf.String())
+	})
+
+	t.Run("should export all the properties without a view", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().GetUsersByWorkspace(gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().StreamBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card"), gomock.Any()).
+			DoAndReturn(streamCards)
+
+		export, err := th.App.NewBoardCSVExport(ctx, container, "board-1", "")
+		require.NoError(t, err)
+		require.Equal(t, "Board.csv", export.Filename())
+
+		var buf bytes.Buffer
+		require.NoError(t, export.Write(ctx, &buf))
+		require.Contains(t, buf.String(), "Title,Status,Tags,Owner,Due,Estimate,Created,Updated\n")
+		require.Contains(t, buf.String(), ",user-1,2021-02-01/2021-02-02,3,")
+	})
+
+	t.Run("should return nil if the board or the view don't exist", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(nil, nil)
+
+		export, err := th.App.NewBoardCSVExport(ctx, container, "board-1", "")
+		require.NoError(t, err)
+		require.Nil(t, export)
+
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(&cards[0], nil)
+
+		export, err = th.App.NewBoardCSVExport(ctx, container, "board-1", "card-1")
+		require.NoError(t, err)
+		require.Nil(t, export)
+	})
+}
@@ -0,0 +1,393 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+const (
+	// digestNotificationLimit is the number of the latest notifications
+	// of a user read for the mentions of their digest
+	digestNotificationLimit = 200
+
+	// digestMaxSectionItems is the number of entries of a section of a
+	// board in a digest, the others are only counted
+	digestMaxSectionItems = 20
+)
+
+// ErrInvalidDigestFrequency is returned when setting a digest frequency
+// other than daily, weekly or never.
+var ErrInvalidDigestFrequency = errors.New("the digest frequency must be daily, weekly or never")
+
+// digestDoneOptionNames are the names of the options of the select
+// properties that mark the cards as completed.
+var digestDoneOptionNames = map[string]bool{
+	"done":      true,
+	"complete":  true,
+	"completed": true,
+}
+
+type digestItem struct {
+	Text      string
+	Permalink string
+}
+
+type digestSection struct {
+	Title string
+	Items []digestItem
+	More  int
+}
+
+func (s *digestSection) add(item digestItem) {
+	if len(s.Items) < digestMaxSectionItems {
+		s.Items = append(s.Items, item)
+	} else {
+		s.More++
+	}
+}
+
+type digestBoard struct {
+	Title     string
+	Permalink string
+	Sections  []digestSection
+}
+
+// digest is the activity of the boards a user watches since their
+// previous digest.
+type digest struct {
+	Username  string
+	Frequency string
+	Since     string
+	Boards    []digestBoard
+}
+
+// digestPeriod returns the time between two digests of the frequency, or
+// 0 if no digest is sent.
+func digestPeriod(frequency string) time.Duration {
+	switch frequency {
+	case model.DigestFrequencyDaily:
+		return 24 * time.Hour
+	case model.DigestFrequencyWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// UpdateDigestFrequency sets how often the user receives the digest.
+func (a *App) UpdateDigestFrequency(userID, frequency string) (*model.DigestSettings, error) {
+	if !model.IsValidDigestFrequency(frequency) {
+		return nil, ErrInvalidDigestFrequency
+	}
+
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Props == nil {
+		user.Props = map[string]interface{}{}
+	}
+	user.Props[model.UserPropDigestFrequency] = frequency
+	if err := a.store.UpdateUser(user); err != nil {
+		return nil, err
+	}
+	return &model.DigestSettings{Frequency: frequency}, nil
+}
+
+// SendDigests emails their digest to the users watching boards whose
+// digest is due. A digest is claimed before it's sent, so that running
+// the job again, or on another server, doesn't send it twice.
+func (a *App) SendDigests(ctx context.Context) error {
+	if a.emailSender == nil {
+		return nil
+	}
+
+	subscriptions, err := a.store.GetBoardSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	subscriberIDs := []string{}
+	bySubscriber := map[string][]model.Subscription{}
+	for _, subscription := range subscriptions {
+		if _, ok := bySubscriber[subscription.SubscriberID]; !ok {
+			subscriberIDs = append(subscriberIDs, subscription.SubscriberID)
+		}
+		bySubscriber[subscription.SubscriberID] = append(bySubscriber[subscription.SubscriberID], subscription)
+	}
+
+	now := time.Now()
+	for _, userID := range subscriberIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := a.sendDigest(ctx, userID, bySubscriber[userID], now); err != nil {
+			a.logger.Error("Unable to send the digest", mlog.String("userID", userID), mlog.Err(err))
+		}
+	}
+	return nil
+}
+
+func (a *App) sendDigest(ctx context.Context, userID string, subscriptions []model.Subscription, now time.Time) error {
+	user, err := a.store.GetUserByID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.DeleteAt != 0 || user.Email == "" {
+		return nil
+	}
+	period := digestPeriod(user.DigestFrequency())
+	if period == 0 {
+		return nil
+	}
+
+	lastDigestAt, err := a.store.GetLastDigestAt(ctx, userID)
+	if err != nil {
+		return err
+	}
+	nowMillis := utils.MillisFromTime(now)
+	periodMillis := int64(period / time.Millisecond)
+	if lastDigestAt > nowMillis-periodMillis {
+		return nil
+	}
+	// the first digest, or the first after a long pause, covers a
+	// single period
+	since := lastDigestAt
+	if since < nowMillis-2*periodMillis {
+		since = nowMillis - periodMillis
+	}
+
+	claimed, err := a.store.ClaimUserDigest(ctx, userID, lastDigestAt, nowMillis)
+	if err != nil || !claimed {
+		return err
+	}
+
+	d, err := a.buildDigest(ctx, user, subscriptions, since)
+	if err != nil {
+		return err
+	}
+	if len(d.Boards) == 0 {
+		return nil
+	}
+	return a.sendDigestEmail(user.Email, d)
+}
+
+// buildDigest returns the cards created and completed since the time on
+// the watched boards the user still has access to, and the mentions of
+// the user on them.
+func (a *App) buildDigest(ctx context.Context, user *model.User, subscriptions []model.Subscription, since int64) (*digest, error) {
+	d := &digest{
+		Username:  user.Username,
+		Frequency: user.DigestFrequency(),
+		Since:     utils.TimeFromMillis(since).UTC().Format("January 2, 2006"),
+		Boards:    []digestBoard{},
+	}
+
+	notifications, err := a.store.GetNotificationsForUser(user.ID, digestNotificationLimit)
+	if err != nil {
+		return nil, err
+	}
+	usernames := map[string]string{}
+
+	for _, subscription := range subscriptions {
+		c := store.Container{WorkspaceID: subscription.WorkspaceID}
+		if !a.canNotifySubscriber(ctx, c, user.ID, subscription.BlockID) {
+			continue
+		}
+		board, err := a.store.GetBlock(ctx, c, subscription.BlockID)
+		if err != nil {
+			return nil, err
+		}
+		if board == nil || board.Type != "board" {
+			continue
+		}
+		cards, err := a.store.GetBlocksWithParentAndType(ctx, c, board.ID, "card")
+		if err != nil {
+			return nil, err
+		}
+
+		permalink := a.cardPermalinker(ctx, c, board.ID)
+		done := digestDoneOptions(*board)
+		newCards := digestSection{Title: "New cards"}
+		completedCards := digestSection{Title: "Completed cards"}
+		mentions := digestSection{Title: "Mentions"}
+
+		titles := map[string]string{}
+		for _, card := range cards {
+			if isTemplate(card) {
+				continue
+			}
+			titles[card.ID] = digestCardTitle(card)
+			if card.CreateAt >= since {
+				newCards.add(digestItem{Text: titles[card.ID], Permalink: permalink(card.ID)})
+			}
+			if card.UpdateAt < since || !isDigestCardDone(card, done) {
+				continue
+			}
+			// the card is only counted if it wasn't already completed
+			previous, err := a.store.GetBlockHistory(ctx, c, card.ID, model.QueryBlockHistoryOptions{Limit: 1, Descending: true, Before: since})
+			if err != nil {
+				return nil, err
+			}
+			if len(previous) == 0 || !isDigestCardDone(previous[0], done) {
+				completedCards.add(digestItem{Text: titles[card.ID], Permalink: permalink(card.ID)})
+			}
+		}
+
+		for _, notification := range notifications {
+			if notification.Type != model.NotificationTypeMention || notification.CreateAt < since ||
+				notification.WorkspaceID != subscription.WorkspaceID || notification.BoardID != board.ID {
+				continue
+			}
+			title, ok := titles[notification.CardID]
+			if !ok {
+				continue
+			}
+			if _, ok := usernames[notification.AuthorID]; !ok {
+				usernames[notification.AuthorID] = ""
+				if author, err := a.store.GetUserByID(notification.AuthorID); err == nil && author != nil {
+					usernames[notification.AuthorID] = author.Username
+				}
+			}
+			text := "You were mentioned in " + title
+			if username := usernames[notification.AuthorID]; username != "" {
+				text = fmt.Sprintf("@%s mentioned you in %s", username, title)
+			}
+			mentions.add(digestItem{Text: text, Permalink: permalink(notification.CardID)})
+		}
+
+		item := digestBoard{
+			Title:     digestCardTitle(*board),
+			Permalink: a.boardPermalink(c, board.ID),
+		}
+		for _, section := range []digestSection{newCards, completedCards, mentions} {
+			if len(section.Items) > 0 {
+				item.Sections = append(item.Sections, section)
+			}
+		}
+		if len(item.Sections) > 0 {
+			d.Boards = append(d.Boards, item)
+		}
+	}
+
+	return d, nil
+}
+
+// digestDoneOptions returns the options of the select properties of the
+// board that mark the cards as completed, by property.
+func digestDoneOptions(board model.Block) map[string]map[string]bool {
+	done := map[string]map[string]bool{}
+	for _, property := range csvProperties(board, nil) {
+		if property.propertyType != "select" {
+			continue
+		}
+		for id, name := range property.options {
+			if digestDoneOptionNames[strings.ToLower(strings.TrimSpace(name))] {
+				if done[property.id] == nil {
+					done[property.id] = map[string]bool{}
+				}
+				done[property.id][id] = true
+			}
+		}
+	}
+	return done
+}
+
+func isDigestCardDone(card model.Block, done map[string]map[string]bool) bool {
+	values, _ := card.Fields["properties"].(map[string]interface{})
+	for propertyID, options := range done {
+		if value, ok := values[propertyID].(string); ok && options[value] {
+			return true
+		}
+	}
+	return false
+}
+
+func digestCardTitle(block model.Block) string {
+	if block.Title == "" {
+		return "Untitled"
+	}
+	return block.Title
+}
+
+// SendTestDigest emails a sample digest to the address, to verify the
+// SMTP settings.
+func (a *App) SendTestDigest(to string) error {
+	if a.emailSender == nil {
+		return ErrEmailNotConfigured
+	}
+
+	link := a.config.ServerRoot
+	return a.sendDigestEmail(to, &digest{
+		Username:  "admin",
+		Frequency: model.DigestFrequencyDaily,
+		Since:     time.Now().UTC().AddDate(0, 0, -1).Format("January 2, 2006"),
+		Boards: []digestBoard{{
+			Title:     "Sample board",
+			Permalink: link,
+			Sections: []digestSection{
+				{Title: "New cards", Items: []digestItem{{Text: "Sample card", Permalink: link}}},
+				{Title: "Completed cards", Items: []digestItem{{Text: "Another sample card", Permalink: link}}},
+			},
+		}},
+	})
+}
+
+func (a *App) sendDigestEmail(to string, d *digest) error {
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, d); err != nil {
+		return err
+	}
+	if err := digestHTMLTemplate.Execute(&html, d); err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("Your %s Focalboard digest", d.Frequency)
+	return a.emailSender.SendHTML(to, subject, text.String(), html.String())
+}
+
+var digestTextTemplate = texttemplate.Must(texttemplate.New("digest").Parse(`Hi {{.Username}},
+
+Here is what happened on the boards you watch since {{.Since}}.
+{{range .Boards}}
+{{.Title}} ({{.Permalink}})
+{{range .Sections}}
+{{.Title}}:
+{{range .Items}}- {{.Text}} ({{.Permalink}})
+{{end}}{{if .More}}- and {{.More}} more
+{{end}}{{end}}{{end}}
+You receive this digest {{.Frequency}} for the boards you watch.
+`))
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #3f4350;">
+<p>Hi {{.Username}},</p>
+<p>Here is what happened on the boards you watch since {{.Since}}.</p>
+{{range .Boards}}
+<h2><a href="{{.Permalink}}">{{.Title}}</a></h2>
+{{range .Sections}}
+<h3>{{.Title}}</h3>
+<ul>
+{{range .Items}}<li><a href="{{.Permalink}}">{{.Text}}</a></li>
+{{end}}{{if .More}}<li>and {{.More}} more</li>
+{{end}}</ul>
+{{end}}{{end}}
+<p style="color: #8d8f96;">You receive this digest {{.Frequency}} for the boards you watch.</p>
+</body>
+</html>
+`))
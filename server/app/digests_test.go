@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendDigests(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.ServerRoot = "http://localhost:8000"
+	sender := &testEmailSender{sent: make(chan sentEmail, 1)}
+	th.App.emailSender = sender
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	now := utils.GetMillis()
+	hour := int64(time.Hour / time.Millisecond)
+
+	user := func(frequency string) *model.User {
+		return &model.User{ID: "user-1", Username: "alice", Email: "alice@example.com", Props: map[string]interface{}{
+			model.UserPropDigestFrequency: frequency,
+		}}
+	}
+	board := &model.Block{ID: "board-1", RootID: "board-1", Type: "board", Title: "Roadmap", Fields: map[string]interface{}{
+		"cardProperties": []interface{}{
+			map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+				map[string]interface{}{"id": "todo", "value": "To do"},
+				map[string]interface{}{"id": "done", "value": "Done"},
+			}},
+		},
+	}}
+	card := func(id, title, status string, createAt int64) model.Block {
+		return model.Block{ID: id, ParentID: "board-1", RootID: "board-1", Type: "card", Title: title, CreateAt: createAt, UpdateAt: now,
+			Fields: map[string]interface{}{"properties": map[string]interface{}{"status": status}}}
+	}
+
+	th.Store.EXPECT().GetBoardSubscriptions(gomock.Any()).
+		Return([]model.Subscription{{BlockType: "board", BlockID: "board-1", WorkspaceID: "0", SubscriberID: "user-1"}}, nil).AnyTimes()
+
+	t.Run("should send the activity of the watched boards", func(t *testing.T) {
+		lastDigestAt := now - 25*hour
+		th.Store.EXPECT().GetUserByID(gomock.Eq("user-1")).Return(user(model.DigestFrequencyDaily), nil).Times(2)
+		th.Store.EXPECT().GetLastDigestAt(gomock.Any(), gomock.Eq("user-1")).Return(lastDigestAt, nil)
+		th.Store.EXPECT().ClaimUserDigest(gomock.Any(), gomock.Eq("user-1"), gomock.Eq(lastDigestAt), gomock.Any()).Return(true, nil)
+		th.Store.EXPECT().HasWorkspaceAccess(gomock.Any(), gomock.Eq("user-1"), gomock.Eq("0")).Return(true, nil)
+		th.Store.EXPECT().GetBoardRolesForUser(gomock.Eq(container), gomock.Eq("user-1")).Return(map[string]string{}, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("view")).
+			Return([]model.Block{{ID: "view-1"}}, nil)
+
+		template := card("template-1", "Template", "done", now)
+		template.Fields["isTemplate"] = true
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("card")).
+			Return([]model.Block{
+				card("card-1", "New card", "todo", now-hour),
+				card("card-2", "Finished card", "done", now-48*hour),
+				card("card-3", "Old finished card", "done", now-48*hour),
+				template,
+			}, nil)
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), gomock.Eq(container), gomock.Eq("card-2"), gomock.Eq(model.QueryBlockHistoryOptions{
+			Limit: 1, Descending: true, Before: lastDigestAt,
+		})).Return([]model.Block{card("card-2", "Finished card", "todo", now-48*hour)}, nil)
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), gomock.Eq(container), gomock.Eq("card-3"), gomock.Any()).
+			Return([]model.Block{card("card-3", "Old finished card", "done", now-48*hour)}, nil)
+
+		th.Store.EXPECT().GetNotificationsForUser(gomock.Eq("user-1"), gomock.Eq(digestNotificationLimit)).Return([]model.Notification{
+			{Type: model.NotificationTypeMention, WorkspaceID: "0", AuthorID: "author-id", BoardID: "board-1", CardID: "card-3", CreateAt: now - hour},
+			{Type: model.NotificationTypeMention, WorkspaceID: "0", AuthorID: "author-id", BoardID: "board-1", CardID: "card-1", CreateAt: now - 30*hour},
+			{Type: model.NotificationTypeDueDate, WorkspaceID: "0", BoardID: "board-1", CardID: "card-1", CreateAt: now - hour},
+		}, nil)
+		th.Store.EXPECT().GetUserByID(gomock.Eq("author-id")).Return(&model.User{ID: "author-id", Username: "bob"}, nil)
+
+		require.NoError(t, th.App.SendDigests(ctx))
+
+		var email sentEmail
+		select {
+		case email = <-sender.sent:
+		default:
+			require.Fail(t, "no digest sent")
+		}
+		require.Equal(t, "alice@example.com", email.to)
+		require.Equal(t, "Your daily Focalboard digest", email.subject)
+		require.Contains(t, email.body, "Roadmap (http://localhost:8000/board-1)")
+		require.Contains(t, email.body, "New cards:\n- New card (http://localhost:8000/board-1/view-1/card-1)")
+		require.Contains(t, email.body, "Completed cards:\n- Finished card (http://localhost:8000/board-1/view-1/card-2)")
+		require.Contains(t, email.body, "Mentions:\n- @bob mentioned you in Old finished card")
+		require.NotContains(t, email.body, "Template")
+		require.NotContains(t, email.body, "- Old finished card")
+		require.Contains(t, email.html, `<a href="http://localhost:8000/board-1/view-1/card-2">Finished card</a>`)
+	})
+
+	t.Run("should not send the digest before it's due", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(gomock.Eq("user-1")).Return(user(model.DigestFrequencyWeekly), nil)
+		th.Store.EXPECT().GetLastDigestAt(gomock.Any(), gomock.Eq("user-1")).Return(now-25*hour, nil)
+
+		require.NoError(t, th.App.SendDigests(ctx))
+		require.Empty(t, sender.sent)
+	})
+
+	t.Run("should not send the digest claimed by another server", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(gomock.Eq("user-1")).Return(user(""), nil)
+		th.Store.EXPECT().GetLastDigestAt(gomock.Any(), gomock.Eq("user-1")).Return(int64(0), nil)
+		th.Store.EXPECT().ClaimUserDigest(gomock.Any(), gomock.Eq("user-1"), gomock.Eq(int64(0)), gomock.Any()).Return(false, nil)
+
+		require.NoError(t, th.App.SendDigests(ctx))
+		require.Empty(t, sender.sent)
+	})
+
+	t.Run("should not send the digest to the users who opted out", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(gomock.Eq("user-1")).Return(user(model.DigestFrequencyNever), nil)
+
+		require.NoError(t, th.App.SendDigests(ctx))
+		require.Empty(t, sender.sent)
+	})
+}
+
+func TestUpdateDigestFrequency(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("should reject an invalid frequency", func(t *testing.T) {
+		_, err := th.App.UpdateDigestFrequency("user-1", "hourly")
+		require.ErrorIs(t, err, ErrInvalidDigestFrequency)
+	})
+
+	t.Run("should store the frequency in the props of the user", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(gomock.Eq("user-1")).Return(&model.User{ID: "user-1"}, nil)
+		th.Store.EXPECT().UpdateUser(gomock.Any()).DoAndReturn(func(user *model.User) error {
+			require.Equal(t, model.DigestFrequencyWeekly, user.DigestFrequency())
+			return nil
+		})
+
+		settings, err := th.App.UpdateDigestFrequency("user-1", model.DigestFrequencyWeekly)
+		require.NoError(t, err)
+		require.Equal(t, model.DigestFrequencyWeekly, settings.Frequency)
+	})
+}
+
+func TestSendTestDigest(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	err := th.App.SendTestDigest("admin@example.com")
+	require.ErrorIs(t, err, ErrEmailNotConfigured)
+
+	sender := &testEmailSender{sent: make(chan sentEmail, 1)}
+	th.App.emailSender = sender
+	require.NoError(t, th.App.SendTestDigest("admin@example.com"))
+
+	email := <-sender.sent
+	require.Equal(t, "admin@example.com", email.to)
+	require.Contains(t, email.html, "Sample board")
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+const (
+	// DefaultEmbedDuration is how long the embed URLs are valid for when
+	// no expiry is given, in milliseconds.
+	DefaultEmbedDuration = 30 * HoursPerDay * MinutesPerHour * SecondsPerMinute * 1000
+
+	// MaxEmbedDuration is how long the embed URLs can be valid for, in
+	// milliseconds.
+	MaxEmbedDuration = 365 * HoursPerDay * MinutesPerHour * SecondsPerMinute * 1000
+)
+
+var (
+	// ErrEmbedNotConfigured is returned when no embed signing key is
+	// configured.
+	ErrEmbedNotConfigured = errors.New("no embed signing key configured")
+
+	// ErrInvalidEmbedExpiry is returned when creating an embed URL that
+	// expires in the past or too far in the future.
+	ErrInvalidEmbedExpiry = errors.New("invalid embed expiry")
+)
+
+// CreateBoardEmbed creates a signed URL of the blocks of the board, valid
+// until expireAt, or for DefaultEmbedDuration if it's zero. It returns
+// nil if the board doesn't exist.
+func (a *App) CreateBoardEmbed(ctx context.Context, c store.Container, boardID string, expireAt int64, userID string) (*model.BoardEmbed, error) {
+	if a.config.EmbedSigningKey == "" {
+		return nil, ErrEmbedNotConfigured
+	}
+
+	now := utils.GetMillis()
+	if expireAt == 0 {
+		expireAt = now + DefaultEmbedDuration
+	}
+	if expireAt <= now || expireAt > now+MaxEmbedDuration {
+		return nil, ErrInvalidEmbedExpiry
+	}
+
+	board, err := a.store.GetBlock(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil || board.Type != "board" {
+		return nil, nil
+	}
+
+	query := url.Values{}
+	query.Set("expires", fmt.Sprint(expireAt))
+	query.Set("signature", auth.SignEmbed(a.config.EmbedSigningKey, c.WorkspaceID, boardID, expireAt))
+	embed := &model.BoardEmbed{
+		BoardID: boardID,
+		URL: fmt.Sprintf("%s/api/v1/workspaces/%s/boards/%s/embed?%s",
+			strings.TrimRight(a.config.ServerRoot, "/"), c.WorkspaceID, boardID, query.Encode()),
+		ExpireAt: expireAt,
+	}
+
+	a.recordAuditEntry(model.AuditActionCreateBoardEmbed, userID, c.WorkspaceID, boardID, map[string]interface{}{
+		"expireAt": expireAt,
+	})
+	return embed, nil
+}
+
+// IsValidEmbedSignature tells if the signature of the embed URL of the
+// board is valid with the current signing key and hasn't expired.
+func (a *App) IsValidEmbedSignature(c store.Container, boardID string, expireAt int64, signature string) bool {
+	if expireAt <= utils.GetMillis() {
+		return false
+	}
+	return auth.VerifyEmbedSignature(a.config.EmbedSigningKey, c.WorkspaceID, boardID, expireAt, signature)
+}
+
+// GetEmbeddedBoard returns the board and its blocks, or nil if the board
+// doesn't exist.
+func (a *App) GetEmbeddedBoard(ctx context.Context, c store.Container, boardID string) (*model.Block, []model.Block, error) {
+	blocks, err := a.store.GetBlocksWithRootID(ctx, c, boardID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range blocks {
+		if blocks[i].ID == boardID && blocks[i].Type == "board" {
+			return &blocks[i], blocks, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// GetEmbedFrameAncestors returns the sites allowed to embed the boards,
+// as the sources of a frame-ancestors directive. Any site can embed them
+// unless they are configured.
+func (a *App) GetEmbedFrameAncestors() string {
+	if len(a.config.EmbedFrameAncestors) == 0 {
+		return "*"
+	}
+	return strings.Join(a.config.EmbedFrameAncestors, " ")
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBoardEmbed(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.App.config.ServerRoot = "http://localhost:8000"
+	th.App.config.EmbedSigningKey = "signing-key"
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	board := &model.Block{ID: "board-1", RootID: "board-1", Type: "board"}
+
+	t.Run("should sign the URL of the board", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		expectAuditEntry(th, model.AuditActionCreateBoardEmbed, "user-id-1", "board-1")
+
+		now := utils.GetMillis()
+		embed, err := th.App.CreateBoardEmbed(ctx, container, "board-1", 0, "user-id-1")
+		require.NoError(t, err)
+		require.NotNil(t, embed)
+		require.InDelta(t, now+DefaultEmbedDuration, embed.ExpireAt, 1000)
+
+		embedURL, err := url.Parse(embed.URL)
+		require.NoError(t, err)
+		require.Equal(t, "/api/v1/workspaces/0/boards/board-1/embed", embedURL.Path)
+		require.Equal(t, strconv.FormatInt(embed.ExpireAt, 10), embedURL.Query().Get("expires"))
+
+		signature := embedURL.Query().Get("signature")
+		require.True(t, th.App.IsValidEmbedSignature(container, "board-1", embed.ExpireAt, signature))
+		require.False(t, th.App.IsValidEmbedSignature(container, "board-2", embed.ExpireAt, signature))
+		require.False(t, th.App.IsValidEmbedSignature(container, "board-1", embed.ExpireAt+1, signature))
+	})
+
+	t.Run("should reject the expiries in the past or too far", func(t *testing.T) {
+		now := utils.GetMillis()
+		_, err := th.App.CreateBoardEmbed(ctx, container, "board-1", now-1000, "user-id-1")
+		require.ErrorIs(t, err, ErrInvalidEmbedExpiry)
+
+		_, err = th.App.CreateBoardEmbed(ctx, container, "board-1", now+2*MaxEmbedDuration, "user-id-1")
+		require.ErrorIs(t, err, ErrInvalidEmbedExpiry)
+	})
+
+	t.Run("should not accept an expired signature", func(t *testing.T) {
+		expireAt := utils.GetMillis() - 1000
+		signature := auth.SignEmbed("signing-key", "0", "board-1", expireAt)
+		require.False(t, th.App.IsValidEmbedSignature(container, "board-1", expireAt, signature))
+	})
+
+	t.Run("should fail without a signing key", func(t *testing.T) {
+		th.App.config.EmbedSigningKey = ""
+		defer func() { th.App.config.EmbedSigningKey = "signing-key" }()
+
+		_, err := th.App.CreateBoardEmbed(ctx, container, "board-1", 0, "user-id-1")
+		require.ErrorIs(t, err, ErrEmbedNotConfigured)
+	})
+}
@@ -1,11 +1,21 @@
 package app
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net/http"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/store"
 	"github.com/mattermost/focalboard/server/utils"
 
 	"github.com/mattermost/mattermost-server/v6/shared/mlog"
@@ -13,7 +23,43 @@ import (
 	"github.com/mattermost/mattermost-server/v6/shared/filestore"
 )
 
+// fileSniffLength is the number of bytes used to detect the content
+// type of the uploaded images.
+const fileSniffLength = 512
+
+var (
+	// ErrFileTooLarge is returned when uploading a file larger than the
+	// configured maximum size.
+	ErrFileTooLarge = errors.New("the file is too large")
+
+	// ErrFileTypeNotAllowed is returned when uploading a file whose
+	// extension isn't allowed, or an image whose content isn't one.
+	ErrFileTypeNotAllowed = errors.New("the file type isn't allowed")
+)
+
+// imageFileExtensions are the extensions of the images whose content is
+// checked on upload.
+var imageFileExtensions = map[string]bool{
+	".bmp":  true,
+	".gif":  true,
+	".jpeg": true,
+	".jpg":  true,
+	".png":  true,
+	".webp": true,
+}
+
 func (a *App) SaveFile(reader io.Reader, workspaceID, rootID, filename string) (string, error) {
+	createdFilename, _, err := a.saveFile(reader, workspaceID, rootID, filename)
+	if err != nil {
+		return "", err
+	}
+
+	return createdFilename, nil
+}
+
+// saveFile stores the file and returns its name and size, even when
+// writing it failed so that the partial file can be removed.
+func (a *App) saveFile(reader io.Reader, workspaceID, rootID, filename string) (string, int64, error) {
 	// NOTE: File extension includes the dot
 	fileExtension := strings.ToLower(filepath.Ext(filename))
 	if fileExtension == ".jpeg" {
@@ -23,12 +69,157 @@ func (a *App) SaveFile(reader io.Reader, workspaceID, rootID, filename string) (
 	createdFilename := fmt.Sprintf(`%s%s`, utils.CreateGUID(), fileExtension)
 	filePath := filepath.Join(workspaceID, rootID, createdFilename)
 
-	_, appErr := a.filesBackend.WriteFile(reader, filePath)
+	written, appErr := a.filesBackend.WriteFile(reader, filePath)
 	if appErr != nil {
-		return "", fmt.Errorf("unable to store the file in the files storage: %w", appErr)
+		return createdFilename, written, fmt.Errorf("unable to store the file in the files storage: %w", appErr)
 	}
 
-	return createdFilename, nil
+	return createdFilename, written, nil
+}
+
+// UploadFile stores a file uploaded by a user and returns its ID, see
+// UploadFileInfo.
+func (a *App) UploadFile(reader io.Reader, workspaceID, rootID, filename string) (string, error) {
+	info, err := a.UploadFileInfo(reader, workspaceID, rootID, filename)
+	if err != nil {
+		return "", err
+	}
+	return info.ID, nil
+}
+
+// UploadFileInfo stores a file uploaded by a user, enforcing the
+// configured maximum size, allowed extensions and file storage quota of
+// the workspace. Images must have the content of an image. The size is
+// checked while the file is stored, so a file that is too large is never
+// read entirely. The stored file is recorded in the files of the
+// workspace, with its name and MIME type, and the images get a
+// thumbnail.
+func (a *App) UploadFileInfo(reader io.Reader, workspaceID, rootID, filename string) (*model.FileInfo, error) {
+	fileExtension := strings.ToLower(filepath.Ext(filename))
+	if !a.isFileExtensionAllowed(fileExtension) {
+		return nil, ErrFileTypeNotAllowed
+	}
+
+	head := make([]byte, fileSniffLength)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	if imageFileExtensions[fileExtension] && !strings.HasPrefix(http.DetectContentType(head), "image/") {
+		return nil, ErrFileTypeNotAllowed
+	}
+
+	remainingStorage, hasQuota, err := a.remainingFileStorage(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if hasQuota && remainingStorage <= 0 {
+		return nil, ErrQuotaExceeded
+	}
+
+	// the file is limited by the quota when it's lower than the size
+	maxSize := a.config.MaxFileSize
+	quotaLimited := hasQuota && (maxSize <= 0 || remainingStorage < maxSize)
+	if quotaLimited {
+		maxSize = remainingStorage
+	}
+
+	limiter := &fileSizeLimiter{
+		reader:    io.MultiReader(bytes.NewReader(head), reader),
+		remaining: maxSize,
+	}
+	var fileReader io.Reader = limiter
+	if maxSize <= 0 {
+		fileReader = limiter.reader
+	}
+
+	createdFilename, size, err := a.saveFile(fileReader, workspaceID, rootID, filename)
+	if limiter.exceeded {
+		a.removePartialFile(workspaceID, rootID, createdFilename)
+		if quotaLimited {
+			return nil, ErrQuotaExceeded
+		}
+		return nil, ErrFileTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info := model.FileInfo{
+		ID:          createdFilename,
+		WorkspaceID: workspaceID,
+		RootID:      rootID,
+		Size:        size,
+		CreateAt:    utils.GetMillis(),
+		Path:        filepath.Join(workspaceID, rootID, createdFilename),
+		Name:        filepath.Base(filename),
+		MimeType:    fileMimeType(fileExtension, head),
+	}
+	if err := a.store.InsertFileInfo(info); err != nil {
+		a.removePartialFile(workspaceID, rootID, createdFilename)
+		return nil, err
+	}
+	a.addWorkspaceUsage(workspaceID, 0, size)
+	a.queueThumbnail(info)
+
+	return &info, nil
+}
+
+// fileMimeType returns the MIME type of a file, from its extension or
+// else from the first bytes of its content.
+func fileMimeType(fileExtension string, head []byte) string {
+	if mimeType := mime.TypeByExtension(fileExtension); mimeType != "" {
+		return mimeType
+	}
+	return http.DetectContentType(head)
+}
+
+// removePartialFile removes a file whose upload failed.
+func (a *App) removePartialFile(workspaceID, rootID, filename string) {
+	if filename == "" {
+		return
+	}
+	filePath := filepath.Join(workspaceID, rootID, filename)
+	if err := a.filesBackend.RemoveFile(filePath); err != nil {
+		a.logger.Error("UploadFile: unable to remove the partial file", mlog.String("path", filePath), mlog.Err(err))
+	}
+}
+
+func (a *App) isFileExtensionAllowed(fileExtension string) bool {
+	if len(a.config.AllowedFileExtensions) == 0 {
+		return true
+	}
+
+	for _, allowed := range a.config.AllowedFileExtensions {
+		allowed = strings.ToLower(allowed)
+		if !strings.HasPrefix(allowed, ".") {
+			allowed = "." + allowed
+		}
+		if allowed == fileExtension {
+			return true
+		}
+	}
+	return false
+}
+
+// fileSizeLimiter is a reader that fails once more than the remaining
+// bytes are read.
+type fileSizeLimiter struct {
+	reader    io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (l *fileSizeLimiter) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		l.exceeded = true
+		return n, ErrFileTooLarge
+	}
+	return n, err
 }
 
 func (a *App) GetFileReader(workspaceID, rootID, filename string) (filestore.ReadCloseSeeker, error) {
@@ -67,3 +258,129 @@ func (a *App) GetFileReader(workspaceID, rootID, filename string) (filestore.Rea
 
 	return reader, nil
 }
+
+// GetFileInfo returns the record of the file of the root block, or nil
+// if it has none, like the files stored before the files were recorded.
+func (a *App) GetFileInfo(workspaceID, rootID, filename string) (*model.FileInfo, error) {
+	info, err := a.store.GetFileInfo(filename)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.WorkspaceID != workspaceID || info.RootID != rootID {
+		return nil, nil
+	}
+	return info, nil
+}
+
+func (a *App) FileExists(workspaceID, rootID, filename string) (bool, error) {
+	return a.filesBackend.FileExists(filepath.Join(workspaceID, rootID, filename))
+}
+
+// GetFileModTime returns the last modification time of the file, which
+// is used to answer conditional and range requests.
+func (a *App) GetFileModTime(workspaceID, rootID, filename string) (time.Time, error) {
+	return a.filesBackend.FileModTime(filepath.Join(workspaceID, rootID, filename))
+}
+
+func (a *App) DeleteFile(workspaceID, rootID, filename string) error {
+	filePath := filepath.Join(workspaceID, rootID, filename)
+	if err := a.filesBackend.RemoveFile(filePath); err != nil {
+		return fmt.Errorf("unable to remove the file from the files storage: %w", err)
+	}
+	a.removeThumbnail(filePath)
+	if err := a.store.DeleteFileInfo(filename); err != nil {
+		return err
+	}
+	a.forgetWorkspaceUsage(workspaceID)
+
+	return nil
+}
+
+// CleanupOrphanedFiles removes the files of the workspaces that aren't
+// referenced by any block and are older than the configured retention,
+// so that a file that was just uploaded isn't removed before its block
+// is inserted. With dryRun, the files are only counted.
+func (a *App) CleanupOrphanedFiles(ctx context.Context, dryRun bool) (*model.FileCleanupResult, error) {
+	retentionDays := a.config.FileRetentionDays
+	if retentionDays <= 0 {
+		retentionDays = config.DefaultFileRetentionDays
+	}
+	olderThan := time.Now().AddDate(0, 0, -retentionDays)
+
+	workspaceIDs, err := a.store.GetBoardWorkspaceIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.FileCleanupResult{DryRun: dryRun}
+	for _, workspaceID := range workspaceIDs {
+		if err := a.cleanupOrphanedFiles(ctx, workspaceID, olderThan, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (a *App) cleanupOrphanedFiles(ctx context.Context, workspaceID string, olderThan time.Time, result *model.FileCleanupResult) error {
+	fileIDs, err := a.store.GetReferencedFileIDs(ctx, store.Container{WorkspaceID: workspaceID})
+	if err != nil {
+		return err
+	}
+	referenced := make(map[string]bool, len(fileIDs))
+	for _, fileID := range fileIDs {
+		referenced[fileID] = true
+	}
+
+	// the files are stored in a directory per root block
+	rootPaths, err := a.filesBackend.ListDirectory(workspaceID)
+	if err != nil {
+		return err
+	}
+
+	for _, rootPath := range rootPaths {
+		filePaths, err := a.filesBackend.ListDirectory(rootPath)
+		if err != nil {
+			a.logger.Warn("CleanupOrphanedFiles: unable to list the files", mlog.String("path", rootPath), mlog.Err(err))
+			continue
+		}
+
+		for _, filePath := range filePaths {
+			// the thumbnails are removed along with their image
+			if isThumbnailPath(filePath) {
+				continue
+			}
+
+			result.FilesExamined++
+			if referenced[filepath.Base(filePath)] {
+				continue
+			}
+
+			modTime, err := a.filesBackend.FileModTime(filePath)
+			if err != nil {
+				a.logger.Warn("CleanupOrphanedFiles: unable to get the file time", mlog.String("path", filePath), mlog.Err(err))
+				continue
+			}
+			if modTime.After(olderThan) {
+				continue
+			}
+
+			if !result.DryRun {
+				if err := a.filesBackend.RemoveFile(filePath); err != nil {
+					return fmt.Errorf("unable to remove the file from the files storage: %w", err)
+				}
+				a.removeThumbnail(filePath)
+				if err := a.store.DeleteFileInfo(filepath.Base(filePath)); err != nil {
+					return err
+				}
+				a.forgetWorkspaceUsage(workspaceID)
+			}
+			result.FilesRemoved++
+		}
+	}
+
+	return nil
+}
@@ -1,9 +1,18 @@
 package app
 
 import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
 	"io"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
 
 	"github.com/mattermost/mattermost-server/v6/plugin/plugintest/mock"
 	"github.com/mattermost/mattermost-server/v6/shared/filestore"
@@ -248,3 +257,227 @@ func TestSaveFile(t *testing.T) {
 		assert.Equal(t, "unable to store the file in the files storage: Mocked File backend error", err.Error())
 	})
 }
+
+func TestDeleteFile(t *testing.T) {
+	th, _ := SetupTestHelper(t)
+
+	t.Run("should remove the file from the file backend", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		mockedFileBackend.On("RemoveFile", testFilePath).Return(nil)
+		th.Store.EXPECT().DeleteFileInfo(gomock.Eq(testFileName)).Return(nil)
+		err := th.App.DeleteFile("1", testRootID, testFileName)
+		assert.NoError(t, err)
+		mockedFileBackend.AssertExpectations(t)
+	})
+
+	t.Run("should return error when fileBackend.RemoveFile returns error", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		mockedFileBackend.On("RemoveFile", testFilePath).Return(&TestError{})
+		err := th.App.DeleteFile("1", testRootID, testFileName)
+		assert.Equal(t, "unable to remove the file from the files storage: Mocked File backend error", err.Error())
+	})
+}
+
+func TestCleanupOrphanedFiles(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	setup := func(t *testing.T) *mocks.FileBackend {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		old := time.Now().AddDate(0, 0, -30)
+		th.Store.EXPECT().GetBoardWorkspaceIDs().Return([]string{"1"}, nil)
+		th.Store.EXPECT().GetReferencedFileIDs(gomock.Any(), gomock.Eq(st.Container{WorkspaceID: "1"})).Return([]string{"used.png"}, nil)
+		mockedFileBackend.On("ListDirectory", "1").Return([]string{"1/root-1"}, nil)
+		mockedFileBackend.On("ListDirectory", "1/root-1").Return([]string{
+			"1/root-1/used.png",
+			"1/root-1/used.png.thumbnail.jpg",
+			"1/root-1/orphan.png",
+			"1/root-1/orphan.png.thumbnail.jpg",
+			"1/root-1/recent.png",
+		}, nil)
+		mockedFileBackend.On("FileModTime", "1/root-1/orphan.png").Return(old, nil)
+		mockedFileBackend.On("FileModTime", "1/root-1/recent.png").Return(time.Now(), nil)
+		return mockedFileBackend
+	}
+
+	t.Run("should remove the old unreferenced files with their thumbnail", func(t *testing.T) {
+		mockedFileBackend := setup(t)
+		mockedFileBackend.On("RemoveFile", "1/root-1/orphan.png").Return(nil)
+		mockedFileBackend.On("FileExists", "1/root-1/orphan.png.thumbnail.jpg").Return(true, nil)
+		mockedFileBackend.On("RemoveFile", "1/root-1/orphan.png.thumbnail.jpg").Return(nil)
+		th.Store.EXPECT().DeleteFileInfo(gomock.Eq("orphan.png")).Return(nil)
+
+		result, err := th.App.CleanupOrphanedFiles(ctx, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, result.FilesExamined)
+		assert.Equal(t, 1, result.FilesRemoved)
+		mockedFileBackend.AssertNumberOfCalls(t, "RemoveFile", 2)
+	})
+
+	t.Run("should only count the files with a dry run", func(t *testing.T) {
+		mockedFileBackend := setup(t)
+
+		result, err := th.App.CleanupOrphanedFiles(ctx, true)
+		assert.NoError(t, err)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 3, result.FilesExamined)
+		assert.Equal(t, 1, result.FilesRemoved)
+		mockedFileBackend.AssertNotCalled(t, "RemoveFile", mock.Anything)
+	})
+}
+
+func TestUploadFile(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	pngHeader := []byte("\x89PNG\r\n\x1a\n")
+	content := func(header []byte, size int) []byte {
+		data := make([]byte, size)
+		copy(data, header)
+		return data
+	}
+
+	setup := func(t *testing.T) (*mocks.FileBackend, *string) {
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		var writtenPath string
+		var writeErr error
+		written := map[string][]byte{}
+		writeFileFunc := func(reader io.Reader, path string) int64 {
+			writtenPath = path
+			var buf bytes.Buffer
+			n, err := io.Copy(&buf, reader)
+			written[path] = buf.Bytes()
+			writeErr = err
+			return n
+		}
+		writeFileErrorFunc := func(reader io.Reader, path string) error {
+			return writeErr
+		}
+		readerFunc := func(path string) filestore.ReadCloseSeeker {
+			return nopReadCloseSeeker{bytes.NewReader(written[path])}
+		}
+		mockedFileBackend.On("WriteFile", mock.Anything, mock.Anything).Return(writeFileFunc, writeFileErrorFunc)
+		mockedFileBackend.On("Reader", mock.Anything).Return(readerFunc, nil)
+		return mockedFileBackend, &writtenPath
+	}
+
+	th.App.config.MaxFileSize = 1024
+	defer func() { th.App.config.MaxFileSize = 0 }()
+
+	t.Run("should store a file exactly at the maximum size", func(t *testing.T) {
+		mockedFileBackend, _ := setup(t)
+		th.Store.EXPECT().InsertFileInfo(gomock.Any()).DoAndReturn(func(info model.FileInfo) error {
+			assert.Equal(t, "1", info.WorkspaceID)
+			assert.Equal(t, testRootID, info.RootID)
+			assert.EqualValues(t, 1024, info.Size)
+			return nil
+		})
+
+		fileID, err := th.App.UploadFile(bytes.NewReader(content(pngHeader, 1024)), "1", testRootID, "image.png")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasSuffix(fileID, ".png"))
+		mockedFileBackend.AssertNotCalled(t, "RemoveFile", mock.Anything)
+	})
+
+	t.Run("should reject and remove a file one byte over the maximum size", func(t *testing.T) {
+		mockedFileBackend, writtenPath := setup(t)
+		mockedFileBackend.On("RemoveFile", mock.Anything).Return(nil)
+
+		fileID, err := th.App.UploadFile(bytes.NewReader(content(pngHeader, 1025)), "1", testRootID, "image.png")
+		assert.ErrorIs(t, err, ErrFileTooLarge)
+		assert.Empty(t, fileID)
+		mockedFileBackend.AssertCalled(t, "RemoveFile", *writtenPath)
+	})
+
+	t.Run("should reject an image that isn't one", func(t *testing.T) {
+		setup(t)
+
+		_, err := th.App.UploadFile(bytes.NewReader(content([]byte("MZ\x90\x00"), 100)), "1", testRootID, "image.png")
+		assert.ErrorIs(t, err, ErrFileTypeNotAllowed)
+	})
+
+	t.Run("should reject the extensions that aren't allowed", func(t *testing.T) {
+		setup(t)
+		th.App.config.AllowedFileExtensions = []string{"png", ".PDF"}
+		defer func() { th.App.config.AllowedFileExtensions = nil }()
+
+		_, err := th.App.UploadFile(bytes.NewReader([]byte("text")), "1", testRootID, "notes.txt")
+		assert.ErrorIs(t, err, ErrFileTypeNotAllowed)
+
+		th.Store.EXPECT().InsertFileInfo(gomock.Any()).Return(nil)
+		_, err = th.App.UploadFile(bytes.NewReader([]byte("%PDF-1.4")), "1", testRootID, "doc.pdf")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject and remove a file over the file storage quota", func(t *testing.T) {
+		mockedFileBackend, writtenPath := setup(t)
+		mockedFileBackend.On("RemoveFile", mock.Anything).Return(nil)
+		th.App.config.MaxFileStoragePerWorkspace = 2048
+		defer func() { th.App.config.MaxFileStoragePerWorkspace = 0 }()
+		defer th.App.forgetWorkspaceUsage("1")
+		th.Store.EXPECT().GetWorkspaceUsage(gomock.Eq("1")).Return(&model.WorkspaceUsage{FileBytes: 1500}, nil)
+
+		fileID, err := th.App.UploadFile(bytes.NewReader(content(pngHeader, 1000)), "1", testRootID, "image.png")
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+		assert.Empty(t, fileID)
+		mockedFileBackend.AssertCalled(t, "RemoveFile", *writtenPath)
+
+		th.Store.EXPECT().InsertFileInfo(gomock.Any()).Return(nil)
+		_, err = th.App.UploadFile(bytes.NewReader(content(pngHeader, 548)), "1", testRootID, "image.png")
+		assert.NoError(t, err)
+
+		usage, err := th.App.GetWorkspaceUsage("1")
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2048, usage.FileBytes)
+
+		_, err = th.App.UploadFile(bytes.NewReader(content(pngHeader, 100)), "1", testRootID, "image.png")
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("should store the thumbnail of a small image", func(t *testing.T) {
+		mockedFileBackend, writtenPath := setup(t)
+		var buf bytes.Buffer
+		assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 10, 10))))
+		th.Store.EXPECT().InsertFileInfo(gomock.Any()).Return(nil)
+		th.Store.EXPECT().SetFileThumbnailPath(gomock.Any(), gomock.Any()).DoAndReturn(func(fileID, thumbnailPath string) error {
+			assert.Equal(t, "1/"+testRootID+"/"+fileID+thumbnailSuffix, thumbnailPath)
+			return nil
+		})
+
+		fileID, err := th.App.UploadFile(&buf, "1", testRootID, "image.png")
+		assert.NoError(t, err)
+		assert.Equal(t, "1/"+testRootID+"/"+fileID+thumbnailSuffix, *writtenPath)
+		mockedFileBackend.AssertNumberOfCalls(t, "WriteFile", 2)
+	})
+
+	t.Run("should queue the thumbnail of a large image", func(t *testing.T) {
+		mockedFileBackend, _ := setup(t)
+		th.App.config.MaxFileSize = 0
+		defer func() { th.App.config.MaxFileSize = 1024 }()
+		th.Store.EXPECT().InsertFileInfo(gomock.Any()).Return(nil)
+
+		_, err := th.App.UploadFile(bytes.NewReader(content(pngHeader, thumbnailSyncMaxSize+1)), "1", testRootID, "image.png")
+		assert.NoError(t, err)
+		mockedFileBackend.AssertNumberOfCalls(t, "WriteFile", 1)
+
+		queued := th.App.thumbnails.take()
+		assert.Len(t, queued, 1)
+		assert.Equal(t, "1/"+testRootID+"/"+queued[0].ID, queued[0].Path)
+	})
+}
+
+// nopReadCloseSeeker is a stored file read by the mocked files backend.
+type nopReadCloseSeeker struct {
+	*bytes.Reader
+}
+
+func (nopReadCloseSeeker) Close() error { return nil }
@@ -0,0 +1,608 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/github"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+const (
+	// gitHubWebhookSecretLength is the number of random bytes of the
+	// secret of the GitHub webhooks.
+	gitHubWebhookSecretLength = 32
+
+	// gitHubSyncOverlap is subtracted from the time of the last sync when
+	// listing the updated issues, for the clock skew with GitHub. The
+	// issues already synced are skipped.
+	gitHubSyncOverlap = time.Minute
+)
+
+// gitHubStateColors are the colors of the options of the states added to
+// the boards, the ones of GitHub.
+var gitHubStateColors = map[string]string{
+	model.GitHubStateOpen:   "propColorGreen",
+	model.GitHubStateClosed: "propColorPurple",
+}
+
+var (
+	// ErrIntegrationsNotConfigured is returned when no integrations
+	// encryption key is configured.
+	ErrIntegrationsNotConfigured = errors.New("no integrations encryption key configured")
+
+	// ErrInvalidGitHubSignature is returned when a GitHub webhook delivery
+	// isn't signed with the secret of the integration.
+	ErrInvalidGitHubSignature = errors.New("invalid GitHub webhook signature")
+)
+
+// InvalidGitHubIntegrationError is returned when the configuration of a
+// GitHub integration is invalid.
+type InvalidGitHubIntegrationError struct {
+	Reason string
+}
+
+func (e InvalidGitHubIntegrationError) Error() string {
+	return "invalid GitHub integration: " + e.Reason
+}
+
+// GitHubIntegrationConfig is the configuration of the GitHub integration
+// of a board. An empty token keeps the token of the current
+// configuration.
+type GitHubIntegrationConfig struct {
+	Repository     string
+	Token          string
+	LabelFilter    []string
+	CommentOnClose bool
+}
+
+// GetGitHubIntegration returns the GitHub integration of the board, or
+// nil if it has none.
+func (a *App) GetGitHubIntegration(c store.Container, boardID string) (*model.GitHubIntegration, error) {
+	integration, err := a.store.GetGitHubIntegration(c, boardID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return integration, err
+}
+
+// ConfigureGitHubIntegration configures the GitHub integration of the
+// board, adding the state and labels properties to the board if it lacks
+// them. The issues are synced from scratch on the next sync, and
+// switching to another repository drops the links to the issues of the
+// previous one. The returned integration has the secret of the webhook.
+// It returns nil if the board doesn't exist.
+func (a *App) ConfigureGitHubIntegration(ctx context.Context, c store.Container, boardID, userID string, config GitHubIntegrationConfig) (*model.GitHubIntegration, error) {
+	key := a.config.IntegrationsEncryptionKey
+	if key == "" {
+		return nil, ErrIntegrationsNotConfigured
+	}
+
+	repository := strings.TrimSpace(config.Repository)
+	if !github.IsValidRepository(repository) {
+		return nil, InvalidGitHubIntegrationError{Reason: "the repository must be an owner/name pair"}
+	}
+	labelFilter := []string{}
+	for _, label := range config.LabelFilter {
+		label = strings.TrimSpace(label)
+		if strings.Contains(label, ",") {
+			return nil, InvalidGitHubIntegrationError{Reason: "the labels can't contain commas"}
+		}
+		if label != "" {
+			labelFilter = append(labelFilter, label)
+		}
+	}
+
+	board, err := a.GetBoard(ctx, c, boardID)
+	if err != nil || board == nil {
+		return nil, err
+	}
+	current, err := a.GetGitHubIntegration(c, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tokenEncrypted, webhookSecret string
+	switch {
+	case config.Token != "":
+		if tokenEncrypted, err = auth.EncryptSecret(key, config.Token); err != nil {
+			return nil, fmt.Errorf("unable to encrypt the GitHub token: %w", err)
+		}
+	case current != nil:
+		tokenEncrypted = current.TokenEncrypted
+	default:
+		return nil, InvalidGitHubIntegrationError{Reason: "the token is required"}
+	}
+	if current != nil {
+		webhookSecret, err = auth.DecryptSecret(key, current.WebhookSecretEncrypted)
+		if err != nil && !errors.Is(err, auth.ErrInvalidSecret) {
+			return nil, err
+		}
+	}
+	if webhookSecret == "" {
+		secret := make([]byte, gitHubWebhookSecretLength)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("unable to generate the GitHub webhook secret: %w", err)
+		}
+		webhookSecret = hex.EncodeToString(secret)
+	}
+	webhookSecretEncrypted, err := auth.EncryptSecret(key, webhookSecret)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encrypt the GitHub webhook secret: %w", err)
+	}
+
+	var statePropertyID, labelsPropertyID string
+	createAt := utils.GetMillis()
+	if current != nil {
+		statePropertyID, labelsPropertyID = current.StatePropertyID, current.LabelsPropertyID
+		createAt = current.CreateAt
+	}
+	cardProperties, statePropertyID, labelsPropertyID := gitHubCardProperties(*board, statePropertyID, labelsPropertyID)
+	if cardProperties != nil {
+		patch := &model.BlockPatch{UpdatedFields: map[string]interface{}{"cardProperties": cardProperties}}
+		if _, err := a.PatchBlock(ctx, c, board.ID, patch, userID, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if current != nil && !strings.EqualFold(current.Repository, repository) {
+		if err := a.store.DeleteGitHubIntegration(c, boardID); err != nil {
+			return nil, err
+		}
+	}
+
+	integration := model.GitHubIntegration{
+		BoardID:                boardID,
+		WorkspaceID:            c.WorkspaceID,
+		Repository:             repository,
+		TokenEncrypted:         tokenEncrypted,
+		WebhookSecretEncrypted: webhookSecretEncrypted,
+		LabelFilter:            labelFilter,
+		CommentOnClose:         config.CommentOnClose,
+		StatePropertyID:        statePropertyID,
+		LabelsPropertyID:       labelsPropertyID,
+		CreatedBy:              userID,
+		CreateAt:               createAt,
+	}
+	if err := a.store.UpsertGitHubIntegration(c, integration); err != nil {
+		return nil, err
+	}
+
+	a.recordAuditEntry(model.AuditActionConfigureGitHubIntegration, userID, c.WorkspaceID, boardID, map[string]interface{}{
+		"repository":     repository,
+		"labelFilter":    labelFilter,
+		"commentOnClose": config.CommentOnClose,
+	})
+
+	integration.WebhookSecret = webhookSecret
+	return &integration, nil
+}
+
+// DeleteGitHubIntegration deletes the GitHub integration of the board,
+// leaving its cards. It returns sql.ErrNoRows if the board has none.
+func (a *App) DeleteGitHubIntegration(c store.Container, boardID, userID string) error {
+	if err := a.store.DeleteGitHubIntegration(c, boardID); err != nil {
+		return err
+	}
+
+	a.recordAuditEntry(model.AuditActionDeleteGitHubIntegration, userID, c.WorkspaceID, boardID, nil)
+	return nil
+}
+
+// SyncGitHubIntegrations syncs the GitHub integrations of all the boards,
+// but the ones whose rate limit is exhausted. The errors are recorded in
+// the integrations.
+func (a *App) SyncGitHubIntegrations(ctx context.Context) error {
+	if a.config.IntegrationsEncryptionKey == "" {
+		return nil
+	}
+
+	integrations, err := a.store.GetGitHubIntegrations()
+	if err != nil {
+		return err
+	}
+
+	now := utils.GetMillis()
+	for _, integration := range integrations {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if integration.RateLimitResetAt > now {
+			continue
+		}
+		if err := a.syncGitHubIntegration(ctx, integration); err != nil {
+			a.logger.Warn("Unable to sync the GitHub integration",
+				mlog.String("boardID", integration.BoardID),
+				mlog.String("repository", integration.Repository),
+				mlog.Err(err),
+			)
+		}
+	}
+	return nil
+}
+
+// SyncGitHubIntegration syncs the GitHub integration of the board now,
+// and returns it with the outcome of the sync. It returns sql.ErrNoRows
+// if the board has none, and a github.RateLimitError without calling
+// GitHub while its rate limit is exhausted.
+func (a *App) SyncGitHubIntegration(ctx context.Context, c store.Container, boardID string) (*model.GitHubIntegration, error) {
+	if a.config.IntegrationsEncryptionKey == "" {
+		return nil, ErrIntegrationsNotConfigured
+	}
+
+	integration, err := a.store.GetGitHubIntegration(c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if integration.RateLimitResetAt > utils.GetMillis() {
+		return nil, github.RateLimitError{ResetAt: utils.TimeFromMillis(integration.RateLimitResetAt)}
+	}
+
+	if err := a.syncGitHubIntegration(ctx, *integration); err != nil {
+		return nil, err
+	}
+	return a.store.GetGitHubIntegration(c, boardID)
+}
+
+// syncGitHubIntegration comments the issues whose cards were closed, and
+// then applies the issues updated since the last sync to their cards,
+// creating the cards of the new ones. The outcome is recorded in the
+// integration.
+func (a *App) syncGitHubIntegration(ctx context.Context, integration model.GitHubIntegration) error {
+	c := store.Container{WorkspaceID: integration.WorkspaceID}
+	startedAt := utils.GetMillis()
+
+	lastSyncAt, err := a.syncGitHubIssues(ctx, c, integration, startedAt)
+	var rateLimitResetAt int64
+	var lastError string
+	var rateLimitErr github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		rateLimitResetAt = utils.MillisFromTime(rateLimitErr.ResetAt)
+	}
+	if err != nil {
+		lastError = err.Error()
+	}
+
+	if storeErr := a.store.UpdateGitHubIntegrationSync(c, integration.BoardID, lastSyncAt, rateLimitResetAt, lastError); storeErr != nil {
+		return storeErr
+	}
+	return err
+}
+
+// syncGitHubIssues returns the time of the sync to record, which is the
+// one of the last sync if it failed, and the update time of the last
+// synced issue if more are left.
+func (a *App) syncGitHubIssues(ctx context.Context, c store.Container, integration model.GitHubIntegration, startedAt int64) (int64, error) {
+	board, err := a.GetBoard(ctx, c, integration.BoardID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && board == nil) {
+		return integration.LastSyncAt, errors.New("the board of the integration doesn't exist")
+	}
+	if err != nil {
+		return integration.LastSyncAt, err
+	}
+
+	token, err := auth.DecryptSecret(a.config.IntegrationsEncryptionKey, integration.TokenEncrypted)
+	if err != nil {
+		return integration.LastSyncAt, fmt.Errorf("unable to decrypt the GitHub token: %w", err)
+	}
+
+	links, err := a.gitHubIssueLinks(c, integration.BoardID)
+	if err != nil {
+		return integration.LastSyncAt, err
+	}
+	if err := a.commentOnClosedGitHubCards(ctx, c, integration, *board, token, links); err != nil {
+		return integration.LastSyncAt, err
+	}
+
+	var since time.Time
+	if integration.LastSyncAt > 0 {
+		since = utils.TimeFromMillis(integration.LastSyncAt).Add(-gitHubSyncOverlap)
+	}
+	issues, more, err := a.github.ListIssues(ctx, token, integration.Repository, integration.LabelFilter, since)
+	if err != nil {
+		return integration.LastSyncAt, err
+	}
+
+	for _, issue := range issues {
+		if err := a.applyGitHubIssue(ctx, c, integration, issue, links[issue.Number]); err != nil {
+			return integration.LastSyncAt, err
+		}
+	}
+
+	if more && len(issues) > 0 {
+		return utils.MillisFromTime(issues[len(issues)-1].UpdatedAt), nil
+	}
+	return startedAt, nil
+}
+
+// gitHubIssueLinks returns the issue links of the board, keyed by the
+// numbers of the issues.
+func (a *App) gitHubIssueLinks(c store.Container, boardID string) (map[int64]*model.GitHubIssueLink, error) {
+	links, err := a.store.GetGitHubIssueLinks(c, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]*model.GitHubIssueLink, len(links))
+	for i := range links {
+		result[links[i].IssueNumber] = &links[i]
+	}
+	return result, nil
+}
+
+// commentOnClosedGitHubCards records which cards of the open issues are
+// closed, commenting on the issues whose cards were closed since the
+// last sync if the integration is configured to.
+func (a *App) commentOnClosedGitHubCards(ctx context.Context, c store.Container, integration model.GitHubIntegration,
+	board model.Block, token string, links map[int64]*model.GitHubIssueLink) error {
+	closedOptionID := gitHubOptionID(board, integration.StatePropertyID, model.GitHubStateClosed)
+	if closedOptionID == "" {
+		return nil
+	}
+
+	cards, err := a.GetBlocks(ctx, c, board.ID, "card")
+	if err != nil {
+		return err
+	}
+	closedCards := map[string]bool{}
+	for _, card := range cards {
+		properties, _ := card.Fields["properties"].(map[string]interface{})
+		closedCards[card.ID] = properties[integration.StatePropertyID] == closedOptionID
+	}
+
+	for _, link := range links {
+		closed, found := closedCards[link.CardID]
+		if !found || link.State != model.GitHubStateOpen || closed == link.CardClosed {
+			continue
+		}
+
+		if closed && integration.CommentOnClose {
+			body := fmt.Sprintf("The card of this issue was closed on the board %q.", board.Title)
+			if err := a.github.CreateComment(ctx, token, integration.Repository, link.IssueNumber, body); err != nil {
+				return err
+			}
+		}
+
+		link.CardClosed = closed
+		if err := a.store.UpsertGitHubIssueLink(c, *link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyGitHubIssue creates or updates the card of the issue, on behalf of
+// the user who configured the integration. The state of the card is only
+// set when the state of the issue changes, so that the card can be closed
+// on the board. The issues whose card was deleted aren't synced anymore,
+// and the ones whose card is being edited are synced on their next
+// update.
+func (a *App) applyGitHubIssue(ctx context.Context, c store.Container, integration model.GitHubIntegration,
+	issue github.Issue, link *model.GitHubIssueLink) error {
+	updatedAt := utils.MillisFromTime(issue.UpdatedAt)
+	if link != nil && link.IssueUpdatedAt >= updatedAt {
+		return nil
+	}
+
+	labels := []interface{}{}
+	for _, label := range issue.Labels {
+		labels = append(labels, label.Name)
+	}
+	payload := model.InboundHookPayload{
+		Title:       issue.Title,
+		Description: issue.Body,
+		Properties: map[string]interface{}{
+			integration.LabelsPropertyID: labels,
+		},
+	}
+	stateChanged := link == nil || link.State != issue.State
+	if stateChanged {
+		payload.Properties[integration.StatePropertyID] = gitHubStateName(issue.State)
+	}
+	if link != nil {
+		payload.CardID = link.CardID
+	}
+
+	hook := model.InboundHook{
+		BoardID:         integration.BoardID,
+		WorkspaceID:     integration.WorkspaceID,
+		AllowNewOptions: true,
+		CreatedBy:       integration.CreatedBy,
+	}
+	card, err := a.ApplyInboundHook(ctx, hook, payload)
+	switch {
+	case link != nil && errors.Is(err, sql.ErrNoRows):
+		a.logger.Debug("Skipping the GitHub issue of a deleted card",
+			mlog.String("boardID", integration.BoardID),
+			mlog.Int64("issueNumber", issue.Number),
+		)
+		return nil
+	case errors.Is(err, ErrBlockLocked):
+		return nil
+	case err != nil:
+		return fmt.Errorf("unable to sync the issue #%d: %w", issue.Number, err)
+	}
+
+	newLink := model.GitHubIssueLink{
+		BoardID:        integration.BoardID,
+		IssueNumber:    issue.Number,
+		CardID:         card.ID,
+		State:          issue.State,
+		CardClosed:     issue.State == model.GitHubStateClosed,
+		IssueUpdatedAt: updatedAt,
+	}
+	if !stateChanged {
+		newLink.CardClosed = link.CardClosed
+	}
+	return a.store.UpsertGitHubIssueLink(c, newLink)
+}
+
+// HandleGitHubWebhook applies the issue of an "issues" event of the
+// repository of the GitHub integration of the board, whose signature is
+// checked with the secret of the integration. The other events are
+// ignored. It returns sql.ErrNoRows if the board has no integration.
+func (a *App) HandleGitHubWebhook(ctx context.Context, c store.Container, boardID, event string, payload []byte, signature string) error {
+	key := a.config.IntegrationsEncryptionKey
+	if key == "" {
+		return ErrIntegrationsNotConfigured
+	}
+
+	integration, err := a.store.GetGitHubIntegration(c, boardID)
+	if err != nil {
+		return err
+	}
+	secret, err := auth.DecryptSecret(key, integration.WebhookSecretEncrypted)
+	if err != nil && !errors.Is(err, auth.ErrInvalidSecret) {
+		return err
+	}
+	if !github.VerifySignature(secret, payload, signature) {
+		return ErrInvalidGitHubSignature
+	}
+	if event != "issues" {
+		return nil
+	}
+
+	var issuesEvent github.IssuesEvent
+	if err := json.Unmarshal(payload, &issuesEvent); err != nil {
+		return InvalidGitHubIntegrationError{Reason: "the event isn't an issues event"}
+	}
+	issue := issuesEvent.Issue
+	if !strings.EqualFold(issuesEvent.Repository.FullName, integration.Repository) ||
+		issuesEvent.Action == "deleted" || issuesEvent.Action == "transferred" ||
+		issue.IsPullRequest() || !issue.HasLabels(integration.LabelFilter) {
+		return nil
+	}
+
+	board, err := a.GetBoard(ctx, c, boardID)
+	if err != nil {
+		return err
+	}
+	if board == nil {
+		return sql.ErrNoRows
+	}
+
+	links, err := a.gitHubIssueLinks(c, boardID)
+	if err != nil {
+		return err
+	}
+	return a.applyGitHubIssue(ctx, c, *integration, issue, links[issue.Number])
+}
+
+// gitHubStateName returns the name of the option of the state.
+func gitHubStateName(state string) string {
+	if state == model.GitHubStateClosed {
+		return "Closed"
+	}
+	return "Open"
+}
+
+// gitHubOptionID returns the ID of the option of the state of the
+// property of the board, or an empty string if there is none.
+func gitHubOptionID(board model.Block, propertyID, state string) string {
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		if template, ok := item.(map[string]interface{}); ok && template["id"] == propertyID {
+			return gitHubTemplateOptionID(template, state)
+		}
+	}
+	return ""
+}
+
+// gitHubTemplateOptionID returns the ID of the option of the state of the
+// property template, or an empty string if there is none.
+func gitHubTemplateOptionID(template map[string]interface{}, state string) string {
+	options, _ := template["options"].([]interface{})
+	for _, item := range options {
+		option, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, _ := option["value"].(string); strings.EqualFold(strings.TrimSpace(value), gitHubStateName(state)) {
+			id, _ := option["id"].(string)
+			return id
+		}
+	}
+	return ""
+}
+
+// gitHubCardProperties returns the IDs of the state and labels properties
+// of the board, the ones with the IDs if they still exist, or the ones
+// with their names and types. Along with the IDs, it returns the card
+// properties of the board with the properties and the state options it
+// added, or nil if it added none.
+func gitHubCardProperties(board model.Block, statePropertyID, labelsPropertyID string) ([]interface{}, string, string) {
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	templates := make([]interface{}, len(cardProperties))
+	copy(templates, cardProperties)
+	changed := false
+
+	findTemplate := func(id, name, propertyType string) int {
+		for i, item := range templates {
+			template, ok := item.(map[string]interface{})
+			if ok && id != "" && template["id"] == id {
+				return i
+			}
+		}
+		for i, item := range templates {
+			template, ok := item.(map[string]interface{})
+			if !ok || template["type"] != propertyType {
+				continue
+			}
+			if templateName, _ := template["name"].(string); strings.EqualFold(strings.TrimSpace(templateName), name) {
+				return i
+			}
+		}
+
+		templates = append(templates, map[string]interface{}{
+			"id":      utils.CreateGUID(),
+			"name":    name,
+			"type":    propertyType,
+			"options": []interface{}{},
+		})
+		changed = true
+		return len(templates) - 1
+	}
+
+	stateIndex := findTemplate(statePropertyID, model.GitHubStatePropertyName, "select")
+	labelsIndex := findTemplate(labelsPropertyID, model.GitHubLabelsPropertyName, "multiSelect")
+
+	// the state template is copied, so that the board isn't changed until
+	// its patch is stored
+	stateTemplate := map[string]interface{}{}
+	for key, value := range templates[stateIndex].(map[string]interface{}) {
+		stateTemplate[key] = value
+	}
+	options, _ := stateTemplate["options"].([]interface{})
+	stateTemplate["options"] = append([]interface{}{}, options...)
+	for _, state := range []string{model.GitHubStateOpen, model.GitHubStateClosed} {
+		if gitHubTemplateOptionID(stateTemplate, state) != "" {
+			continue
+		}
+		stateTemplate["options"] = append(stateTemplate["options"].([]interface{}), map[string]interface{}{
+			"id":    utils.CreateGUID(),
+			"value": gitHubStateName(state),
+			"color": gitHubStateColors[state],
+		})
+		changed = true
+	}
+	templates[stateIndex] = stateTemplate
+
+	statePropertyID, _ = stateTemplate["id"].(string)
+	labelsPropertyID, _ = templates[labelsIndex].(map[string]interface{})["id"].(string)
+	if !changed {
+		return nil, statePropertyID, labelsPropertyID
+	}
+	return templates, statePropertyID, labelsPropertyID
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubCardProperties(t *testing.T) {
+	t.Run("should add the missing properties and options", func(t *testing.T) {
+		board := model.Block{ID: "board-1", Type: "board", Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "state", "name": "state", "type": "select", "options": []interface{}{
+					map[string]interface{}{"id": "open", "value": "Open"},
+				}},
+			},
+		}}
+
+		cardProperties, stateID, labelsID := gitHubCardProperties(board, "", "")
+		require.Equal(t, "state", stateID)
+		require.NotEmpty(t, labelsID)
+		require.Len(t, cardProperties, 2)
+
+		state := cardProperties[0].(map[string]interface{})
+		options := state["options"].([]interface{})
+		require.Len(t, options, 2)
+		require.Equal(t, "Closed", options[1].(map[string]interface{})["value"])
+		require.Equal(t, "propColorPurple", options[1].(map[string]interface{})["color"])
+		require.Equal(t, "multiSelect", cardProperties[1].(map[string]interface{})["type"])
+
+		// the board isn't changed
+		require.Len(t, board.Fields["cardProperties"].([]interface{}), 1)
+		require.Len(t, board.Fields["cardProperties"].([]interface{})[0].(map[string]interface{})["options"], 1)
+
+		patched := model.Block{Fields: map[string]interface{}{"cardProperties": cardProperties}}
+		require.Equal(t, options[1].(map[string]interface{})["id"], gitHubOptionID(patched, stateID, model.GitHubStateClosed))
+	})
+
+	t.Run("should keep the properties of the integration", func(t *testing.T) {
+		board := model.Block{ID: "board-1", Type: "board", Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+					map[string]interface{}{"id": "open", "value": "Open"},
+					map[string]interface{}{"id": "closed", "value": "Closed"},
+				}},
+				map[string]interface{}{"id": "tags", "name": "Tags", "type": "multiSelect"},
+			},
+		}}
+
+		cardProperties, stateID, labelsID := gitHubCardProperties(board, "status", "tags")
+		require.Nil(t, cardProperties)
+		require.Equal(t, "status", stateID)
+		require.Equal(t, "tags", labelsID)
+	})
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// guestInviteExpiry is how long a guest invite can be used after
+	// it's created.
+	guestInviteExpiry = 7 * 24 * time.Hour
+
+	guestInviteTokenLength = 32
+)
+
+// ErrInvalidGuestInvite is returned when a guest invite doesn't exist,
+// was already used or has expired.
+var ErrInvalidGuestInvite = errors.New("invalid or expired guest invite")
+
+// CreateGuestInvite creates a single-use invite for an external user to
+// sign up as a guest with the role on the board, and returns its token.
+// The user creating it must be able to manage the members of the board.
+func (a *App) CreateGuestInvite(ctx context.Context, c store.Container, actorID, boardID, role string) (string, *model.GuestInvite, error) {
+	if !model.IsValidGuestRole(role) {
+		return "", nil, ErrInvalidBoardRole
+	}
+
+	board, err := a.store.GetBlock(ctx, c, boardID)
+	if err != nil {
+		return "", nil, err
+	}
+	if board == nil || board.Type != "board" {
+		return "", nil, sql.ErrNoRows
+	}
+	if err = a.CheckBlockAccess(ctx, c, actorID, boardID, model.BoardRoleAdmin); err != nil {
+		return "", nil, err
+	}
+
+	secret := make([]byte, guestInviteTokenLength)
+	if _, err = rand.Read(secret); err != nil {
+		return "", nil, errors.Wrap(err, "unable to generate the guest invite token")
+	}
+	token := hex.EncodeToString(secret)
+
+	now := utils.GetMillis()
+	invite := model.GuestInvite{
+		TokenHash:   model.HashToken(token),
+		WorkspaceID: c.WorkspaceID,
+		BoardID:     boardID,
+		Role:        role,
+		CreatedBy:   actorID,
+		CreateAt:    now,
+		ExpireAt:    now + guestInviteExpiry.Milliseconds(),
+	}
+	if err = a.store.CreateGuestInvite(invite); err != nil {
+		return "", nil, errors.Wrap(err, "unable to store the guest invite")
+	}
+
+	a.recordAuditEntry(model.AuditActionCreateGuestInvite, actorID, c.WorkspaceID, boardID, map[string]interface{}{
+		"role": role,
+	})
+	return token, &invite, nil
+}
+
+// RegisterGuest creates a guest from the token of a guest invite, and
+// adds them to the board of the invite on behalf of its creator. The
+// invite can only be used once, and isn't used by invalid registrations.
+// The guests log in with their password, they can't register once the
+// password logins are disabled.
+func (a *App) RegisterGuest(token, username, email, password string) (*model.User, error) {
+	if !a.IsPasswordLoginEnabled() {
+		return nil, ErrPasswordLoginDisabled
+	}
+	if err := a.checkNewUser(username, email, password); err != nil {
+		return nil, err
+	}
+
+	invite, err := a.store.ConsumeGuestInvite(model.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidGuestInvite
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the guest invite")
+	}
+	if invite.IsExpired(utils.GetMillis()) {
+		return nil, ErrInvalidGuestInvite
+	}
+
+	user, err := a.createUser(username, email, password, true)
+	if err != nil {
+		return nil, err
+	}
+
+	c := store.Container{WorkspaceID: invite.WorkspaceID}
+	member := model.BoardMember{BoardID: invite.BoardID, UserID: user.ID, Role: invite.Role}
+	if _, err = a.UpsertBoardMember(c, invite.CreatedBy, member); err != nil {
+		return nil, errors.Wrap(err, "unable to add the guest to the board")
+	}
+
+	a.recordAuditEntry(model.AuditActionRegisterGuest, user.ID, invite.WorkspaceID, invite.BoardID, map[string]interface{}{
+		"invitedBy": invite.CreatedBy,
+	})
+	return user, nil
+}
+
+// IsUserGuest tells if the user is a guest, who only has access to the
+// boards they are a member of.
+func (a *App) IsUserGuest(userID string) (bool, error) {
+	return a.auth.IsGuest(userID)
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuestInvites(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("should not invite a guest as an admin", func(t *testing.T) {
+		_, _, err := th.App.CreateGuestInvite(ctx, container, "user-id-1", "board-id", model.BoardRoleAdmin)
+		require.ErrorIs(t, err, ErrInvalidBoardRole)
+	})
+
+	t.Run("should create an invite to a board", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-id")).Return(&model.Block{ID: "board-id", Type: "board"}, nil)
+		th.Store.EXPECT().GetUserByID(gomock.Eq("user-id-1")).Return(&model.User{ID: "user-id-1"}, nil)
+		th.Store.EXPECT().GetBoardRolesForUser(gomock.Eq(container), gomock.Eq("user-id-1")).Return(map[string]string{}, nil)
+
+		var stored model.GuestInvite
+		th.Store.EXPECT().CreateGuestInvite(gomock.Any()).DoAndReturn(func(invite model.GuestInvite) error {
+			stored = invite
+			return nil
+		})
+		expectAuditEntry(th, model.AuditActionCreateGuestInvite, "user-id-1", "board-id")
+
+		token, invite, err := th.App.CreateGuestInvite(ctx, container, "user-id-1", "board-id", model.BoardRoleEditor)
+		require.NoError(t, err)
+		require.Equal(t, model.HashToken(token), stored.TokenHash)
+		require.Equal(t, model.BoardRoleEditor, invite.Role)
+		require.Equal(t, "user-id-1", invite.CreatedBy)
+	})
+
+	t.Run("should reject an expired invite", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("guest")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetUserByEmail(gomock.Eq("guest@example.com")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().ConsumeGuestInvite(gomock.Eq(model.HashToken("token"))).Return(&model.GuestInvite{ExpireAt: 1}, nil)
+
+		_, err := th.App.RegisterGuest("token", "guest", "guest@example.com", "password")
+		require.ErrorIs(t, err, ErrInvalidGuestInvite)
+	})
+
+	t.Run("should register a guest and add them to the board", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("guest")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetUserByEmail(gomock.Eq("guest@example.com")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().ConsumeGuestInvite(gomock.Eq(model.HashToken("token"))).Return(&model.GuestInvite{
+			WorkspaceID: "0",
+			BoardID:     "board-id",
+			Role:        model.BoardRoleViewer,
+			CreatedBy:   "user-id-1",
+			ExpireAt:    utils.GetMillis() + 1000,
+		}, nil)
+		th.Store.EXPECT().GetRegisteredUserCount().Return(0, nil)
+
+		var created *model.User
+		th.Store.EXPECT().CreateUser(gomock.Any()).DoAndReturn(func(user *model.User) error {
+			created = user
+			return nil
+		})
+		th.Store.EXPECT().GetBoardMembers(gomock.Eq(container), gomock.Eq("board-id")).Return([]model.BoardMember{}, nil)
+		th.Store.EXPECT().GetUserByID(gomock.Eq("user-id-1")).Return(&model.User{ID: "user-id-1"}, nil)
+		th.Store.EXPECT().UpsertBoardMember(gomock.Eq(container), boardMemberMatcher{"user-id-1", model.BoardRoleAdmin}).Return(nil)
+		th.Store.EXPECT().UpsertBoardMember(gomock.Eq(container), gomock.Any()).Return(nil)
+		expectAuditEntry(th, model.AuditActionUpsertBoardMember, "user-id-1", "board-id")
+		th.Store.EXPECT().InsertAuditEntry(gomock.Any()).Return(nil)
+
+		user, err := th.App.RegisterGuest("token", "guest", "guest@example.com", "password")
+		require.NoError(t, err)
+		require.Equal(t, created, user)
+		require.True(t, user.IsGuest)
+		require.False(t, user.IsAdmin)
+	})
+}
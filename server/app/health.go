@@ -0,0 +1,194 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// healthCheckTimeout is the time the database has to answer the health
+// check.
+const healthCheckTimeout = time.Second
+
+// GetServerHealth checks the database is reachable and returns the
+// health of the server. The error of the check and the statistics of the
+// connection pool are only included for admins.
+func (a *App) GetServerHealth(ctx context.Context, isAdmin bool) *model.ServerHealth {
+	health := &model.ServerHealth{
+		Status:      model.HealthStatusOK,
+		Version:     model.CurrentVersion,
+		BuildNumber: model.BuildNumber,
+		BuildDate:   model.BuildDate,
+		BuildHash:   model.BuildHash,
+		Edition:     model.Edition,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := a.store.Ping(ctx)
+	health.Database.LatencyMs = time.Since(start).Milliseconds()
+	if err == nil {
+		health.Database.SchemaVersion, err = a.store.GetSchemaVersion(ctx)
+	}
+	if err != nil {
+		a.logger.Error("Database health check failed", mlog.Err(err))
+		health.Status = model.HealthStatusUnhealthy
+		if isAdmin {
+			health.Database.Error = err.Error()
+		}
+	}
+
+	if isAdmin {
+		stats := a.store.GetDBStats()
+		health.Database.Pool = &model.DatabasePoolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		}
+	}
+
+	return health
+}
+
+// readinessProbeDir is the directory of the files written by the
+// filestore check of the readiness, outside of the workspace directories.
+const readinessProbeDir = "readiness"
+
+// SetDraining marks the server as shutting down, for the readiness to tell
+// the load balancers to stop sending new requests.
+func (a *App) SetDraining() {
+	atomic.StoreInt32(&a.draining, 1)
+}
+
+// IsDraining tells if the server is shutting down.
+func (a *App) IsDraining() bool {
+	return atomic.LoadInt32(&a.draining) == 1
+}
+
+// GetLiveness returns the liveness of the server, which only depends on
+// the process answering.
+func (a *App) GetLiveness() *model.Liveness {
+	return &model.Liveness{Status: model.HealthStatusOK}
+}
+
+// GetReadiness checks the database is reachable, a file can be written to
+// the filestore and the migrations are all applied. The errors of the
+// checks are only logged, the readiness being public.
+func (a *App) GetReadiness(ctx context.Context) *model.Readiness {
+	readiness := &model.Readiness{
+		Status:   model.HealthStatusOK,
+		Draining: a.IsDraining(),
+		Dependencies: []model.DependencyHealth{
+			a.checkDependency(ctx, "database", a.store.Ping),
+			a.checkDependency(ctx, "filestore", a.checkFilestore),
+			a.checkDependency(ctx, "migrations", a.checkMigrations),
+		},
+	}
+
+	for _, dependency := range readiness.Dependencies {
+		if dependency.Status != model.HealthStatusOK {
+			readiness.Status = model.HealthStatusUnhealthy
+		}
+	}
+	if readiness.Draining {
+		readiness.Status = model.HealthStatusDraining
+	}
+	return readiness
+}
+
+// checkDependency runs the check of a dependency with the health check
+// timeout, timing it.
+func (a *App) checkDependency(ctx context.Context, name string, check func(context.Context) error) model.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	dependency := model.DependencyHealth{
+		Name:      name,
+		Status:    model.HealthStatusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		a.logger.Error("Readiness check failed", mlog.String("dependency", name), mlog.Err(err))
+		dependency.Status = model.HealthStatusUnhealthy
+	}
+	return dependency
+}
+
+// checkFilestore writes a file to the filestore and removes it. The
+// filestore doesn't take a context, the check is abandoned when the
+// context is done.
+func (a *App) checkFilestore(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		path := filepath.Join(readinessProbeDir, utils.CreateGUID())
+		if _, err := a.filesBackend.WriteFile(bytes.NewReader([]byte("ok")), path); err != nil {
+			done <- err
+			return
+		}
+		done <- a.filesBackend.RemoveFile(path)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// checkMigrations fails if some migrations aren't applied yet.
+func (a *App) checkMigrations(ctx context.Context) error {
+	pending, err := a.store.GetPendingMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending migrations", len(pending))
+	}
+	return nil
+}
+
+// RunDiagnostics checks the database is reachable, its schema is up to
+// date, and it has no orphaned blocks. The other checks are skipped when
+// the database isn't reachable.
+func (a *App) RunDiagnostics(ctx context.Context) []model.DiagnosticCheck {
+	if err := a.store.Ping(ctx); err != nil {
+		return []model.DiagnosticCheck{{Name: "database", Detail: err.Error()}}
+	}
+	checks := []model.DiagnosticCheck{{Name: "database", OK: true, Detail: "reachable"}}
+
+	schema := model.DiagnosticCheck{Name: "schema"}
+	if status, err := a.GetMigrationStatus(ctx); err != nil {
+		schema.Detail = err.Error()
+	} else if len(status.Pending) > 0 {
+		schema.Detail = fmt.Sprintf("version %d, %d pending migrations", status.SchemaVersion, len(status.Pending))
+	} else {
+		schema.OK = true
+		schema.Detail = fmt.Sprintf("version %d", status.SchemaVersion)
+	}
+	checks = append(checks, schema)
+
+	orphans := model.DiagnosticCheck{Name: "orphaned_blocks"}
+	if count, err := a.store.CountOrphanedBlocks(ctx); err != nil {
+		orphans.Detail = err.Error()
+	} else {
+		orphans.OK = count == 0
+		orphans.Detail = fmt.Sprintf("%d blocks without a root block", count)
+	}
+	return append(checks, orphans)
+}
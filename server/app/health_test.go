@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/mattermost-server/v6/shared/filestore/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetServerHealth(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("should report a healthy database", func(t *testing.T) {
+		th.Store.EXPECT().Ping(gomock.Any()).Return(nil)
+		th.Store.EXPECT().GetSchemaVersion(gomock.Any()).Return(int64(23), nil)
+
+		health := th.App.GetServerHealth(ctx, false)
+		require.Equal(t, model.HealthStatusOK, health.Status)
+		require.Equal(t, int64(23), health.Database.SchemaVersion)
+		require.Equal(t, model.CurrentVersion, health.Version)
+		require.Nil(t, health.Database.Pool)
+	})
+
+	t.Run("should report an unhealthy database without the error", func(t *testing.T) {
+		th.Store.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+
+		health := th.App.GetServerHealth(ctx, false)
+		require.Equal(t, model.HealthStatusUnhealthy, health.Status)
+		require.Empty(t, health.Database.Error)
+	})
+
+	t.Run("should include the error and the pool stats for admins", func(t *testing.T) {
+		th.Store.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+		th.Store.EXPECT().GetDBStats().Return(sql.DBStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2, WaitCount: 5})
+
+		health := th.App.GetServerHealth(ctx, true)
+		require.Equal(t, model.HealthStatusUnhealthy, health.Status)
+		require.Equal(t, "connection refused", health.Database.Error)
+		require.Equal(t, &model.DatabasePoolStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2, WaitCount: 5}, health.Database.Pool)
+	})
+
+	t.Run("should time out a slow database", func(t *testing.T) {
+		th.Store.EXPECT().Ping(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		th.Store.EXPECT().GetDBStats().Return(sql.DBStats{})
+
+		health := th.App.GetServerHealth(ctx, true)
+		require.Equal(t, model.HealthStatusUnhealthy, health.Status)
+		require.Equal(t, context.DeadlineExceeded.Error(), health.Database.Error)
+		require.GreaterOrEqual(t, health.Database.LatencyMs, healthCheckTimeout.Milliseconds())
+	})
+}
+
+func TestGetReadiness(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	filesBackend := &mocks.FileBackend{}
+	th.App.filesBackend = filesBackend
+	filesBackend.On("WriteFile", mock.Anything, mock.Anything).Return(int64(2), nil)
+	filesBackend.On("RemoveFile", mock.Anything).Return(nil)
+
+	statuses := func(readiness *model.Readiness) map[string]string {
+		result := map[string]string{}
+		for _, dependency := range readiness.Dependencies {
+			result[dependency.Name] = dependency.Status
+		}
+		return result
+	}
+
+	t.Run("should report a ready server", func(t *testing.T) {
+		th.Store.EXPECT().Ping(gomock.Any()).Return(nil)
+		th.Store.EXPECT().GetPendingMigrations(gomock.Any()).Return(nil, nil)
+
+		readiness := th.App.GetReadiness(ctx)
+		require.Equal(t, model.HealthStatusOK, readiness.Status)
+		require.False(t, readiness.Draining)
+		require.Equal(t, map[string]string{
+			"database":   model.HealthStatusOK,
+			"filestore":  model.HealthStatusOK,
+			"migrations": model.HealthStatusOK,
+		}, statuses(readiness))
+	})
+
+	t.Run("should report the pending migrations", func(t *testing.T) {
+		th.Store.EXPECT().Ping(gomock.Any()).Return(nil)
+		th.Store.EXPECT().GetPendingMigrations(gomock.Any()).Return([]model.Migration{{Version: 33}}, nil)
+
+		readiness := th.App.GetReadiness(ctx)
+		require.Equal(t, model.HealthStatusUnhealthy, readiness.Status)
+		require.Equal(t, model.HealthStatusOK, statuses(readiness)["database"])
+		require.Equal(t, model.HealthStatusUnhealthy, statuses(readiness)["migrations"])
+	})
+
+	t.Run("should report an unreachable database", func(t *testing.T) {
+		th.Store.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+		th.Store.EXPECT().GetPendingMigrations(gomock.Any()).Return(nil, errors.New("connection refused"))
+
+		readiness := th.App.GetReadiness(ctx)
+		require.Equal(t, model.HealthStatusUnhealthy, readiness.Status)
+		require.Equal(t, model.HealthStatusUnhealthy, statuses(readiness)["database"])
+		require.Equal(t, model.HealthStatusOK, statuses(readiness)["filestore"])
+	})
+
+	t.Run("should report a draining server", func(t *testing.T) {
+		th.Store.EXPECT().Ping(gomock.Any()).Return(nil)
+		th.Store.EXPECT().GetPendingMigrations(gomock.Any()).Return(nil, nil)
+
+		th.App.SetDraining()
+		readiness := th.App.GetReadiness(ctx)
+		require.Equal(t, model.HealthStatusDraining, readiness.Status)
+		require.True(t, readiness.Draining)
+	})
+}
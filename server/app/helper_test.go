@@ -3,11 +3,13 @@
 package app
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 
 	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/config"
 	"github.com/mattermost/focalboard/server/services/metrics"
 	"github.com/mattermost/focalboard/server/services/store/mockstore"
@@ -32,7 +34,7 @@ func SetupTestHelper(t *testing.T) (*TestHelper, func()) {
 	auth := auth.New(&cfg, store)
 	logger := mlog.CreateConsoleTestLogger(false, mlog.LvlDebug)
 	sessionToken := "TESTTOKEN"
-	wsserver := ws.NewServer(auth, sessionToken, false, logger)
+	wsserver := ws.NewServer(auth, sessionToken, false, logger, metrics.NoopInstrumentation{})
 	webhook := webhook.NewClient(&cfg, logger)
 	metricsService := metrics.NewMetrics(metrics.InstanceInfo{})
 
@@ -58,3 +60,52 @@ func SetupTestHelper(t *testing.T) (*TestHelper, func()) {
 		logger: logger,
 	}, tearDown
 }
+
+// testTx is a transaction of the mocked store, whose queries are
+// expected on the store itself.
+type testTx struct {
+	*mockstore.MockStore
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *testTx) Commit() error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *testTx) Rollback() error {
+	if tx.committed {
+		return sql.ErrTxDone
+	}
+	tx.rolledBack = true
+	return nil
+}
+
+// expectTx expects a transaction to be started, and returns it.
+func (th *TestHelper) expectTx() *testTx {
+	tx := &testTx{MockStore: th.Store}
+	th.Store.EXPECT().BeginTx(gomock.Any()).Return(tx, nil)
+	return tx
+}
+
+// expectOpenBoards expects the restricted boards of the users, and
+// whether they are guests, to be looked up, the workspace having none
+// and the users not being guests.
+func (th *TestHelper) expectOpenBoards() {
+	th.Store.EXPECT().GetBoardRolesForUser(gomock.Any(), gomock.Any()).Return(map[string]string{}, nil).AnyTimes()
+	th.Store.EXPECT().GetUserByID(gomock.Any()).Return(&model.User{}, nil).AnyTimes()
+}
+
+// expectSingleWorkspace expects the workspaces of the referenced and the
+// deleted blocks to be looked up, none of them being in another
+// workspace.
+func (th *TestHelper) expectSingleWorkspace() {
+	th.Store.EXPECT().GetBlockWorkspaceIDs(gomock.Any(), gomock.Any()).Return(map[string][]string{}, nil).AnyTimes()
+}
+
+// expectNoSubscriptions expects the subscriptions to the changed blocks
+// to be looked up, none of the blocks being watched.
+func (th *TestHelper) expectNoSubscriptions() {
+	th.Store.EXPECT().GetSubscriptions(gomock.Any(), gomock.Any(), gomock.Any()).Return([]model.Subscription{}, nil).AnyTimes()
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// ErrIdempotencyKeyReused is returned when the idempotency key of a
+// request was already used by the user for another request.
+var ErrIdempotencyKeyReused = errors.New("the idempotency key was used for another request")
+
+// ErrIdempotencyKeyInProgress is returned when the request of the
+// idempotency key is still handled after the retry waited for it.
+var ErrIdempotencyKeyInProgress = errors.New("the request of the idempotency key is still in progress")
+
+const (
+	// idempotencyKeyWaitTimeout is how long a retry waits for the request
+	// of its key to be handled
+	idempotencyKeyWaitTimeout = 10 * time.Second
+
+	idempotencyKeyPollInterval = 50 * time.Millisecond
+
+	// idempotencyKeyAbandonedAfter is how long after it's claimed a key
+	// without a response is taken over, as its request was interrupted
+	idempotencyKeyAbandonedAfter = time.Minute
+
+	// idempotencyKeyClaimAttempts is how many times a key that is deleted
+	// while it's claimed, like an expired one, is claimed again
+	idempotencyKeyClaimAttempts = 3
+)
+
+// ClaimIdempotencyKey claims the idempotency key of the user for the
+// request with the hash. If the key is claimed, it's returned with
+// claimed set, and the request is handled then its response stored with
+// SetIdempotencyKeyResponse, or the key released with
+// ReleaseIdempotencyKey. Otherwise the key of the earlier request is
+// returned with its response, after waiting for it to be handled if a
+// concurrent request holds the key. The keys are claimed by inserting
+// them, so that of the concurrent requests with a key only one does.
+func (a *App) ClaimIdempotencyKey(ctx context.Context, userID, key, requestHash string) (idempotencyKey *model.IdempotencyKey, claimed bool, err error) {
+	deadline := time.Now().Add(idempotencyKeyWaitTimeout)
+	attempts := 0
+
+	for {
+		now := utils.GetMillis()
+		claim := model.IdempotencyKey{
+			Key:         key,
+			UserID:      userID,
+			RequestHash: requestHash,
+			CreateAt:    now,
+			ExpireAt:    now + model.IdempotencyKeyTTL.Milliseconds(),
+		}
+		createErr := a.store.CreateIdempotencyKey(claim)
+		if createErr == nil {
+			return &claim, true, nil
+		}
+
+		existing, err := a.store.GetIdempotencyKey(userID, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			// the key was deleted after the insert failed, or the insert
+			// failed for another reason
+			attempts++
+			if attempts >= idempotencyKeyClaimAttempts {
+				return nil, false, createErr
+			}
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		abandoned := existing.IsPending() && now-existing.CreateAt > idempotencyKeyAbandonedAfter.Milliseconds()
+		if existing.IsExpired(now) || abandoned {
+			if err := a.store.DeleteIdempotencyKey(userID, key, existing.CreateAt); err != nil {
+				return nil, false, err
+			}
+			attempts++
+			if attempts >= idempotencyKeyClaimAttempts {
+				return nil, false, createErr
+			}
+			continue
+		}
+		if existing.RequestHash != requestHash {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+		if !existing.IsPending() {
+			return existing, false, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, false, ErrIdempotencyKeyInProgress
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-time.After(idempotencyKeyPollInterval):
+		}
+	}
+}
+
+// SetIdempotencyKeyResponse stores the response of the request of the
+// claimed idempotency key, for its retries.
+func (a *App) SetIdempotencyKeyResponse(key model.IdempotencyKey, statusCode int, response string) error {
+	return a.store.SetIdempotencyKeyResponse(key.UserID, key.Key, statusCode, response)
+}
+
+// ReleaseIdempotencyKey deletes the claimed idempotency key, so that the
+// retries of a request that failed are handled again.
+func (a *App) ReleaseIdempotencyKey(key model.IdempotencyKey) error {
+	return a.store.DeleteIdempotencyKey(key.UserID, key.Key, key.CreateAt)
+}
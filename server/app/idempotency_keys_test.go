@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	errDuplicate := errors.New("duplicate key")
+	stored := func(requestHash string, statusCode int, createAt, expireAt int64) *model.IdempotencyKey {
+		return &model.IdempotencyKey{
+			Key:         "key-1",
+			UserID:      "user-1",
+			RequestHash: requestHash,
+			StatusCode:  statusCode,
+			Response:    `{"id":"card-1"}`,
+			CreateAt:    createAt,
+			ExpireAt:    expireAt,
+		}
+	}
+
+	t.Run("should claim a new key", func(t *testing.T) {
+		th.Store.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(nil)
+
+		key, claimed, err := th.App.ClaimIdempotencyKey(ctx, "user-1", "key-1", "hash-1")
+		require.NoError(t, err)
+		require.True(t, claimed)
+		require.True(t, key.IsPending())
+		require.Equal(t, model.IdempotencyKeyTTL.Milliseconds(), key.ExpireAt-key.CreateAt)
+	})
+
+	t.Run("should return the response of the same request", func(t *testing.T) {
+		now := utils.GetMillis()
+		th.Store.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(errDuplicate)
+		th.Store.EXPECT().GetIdempotencyKey("user-1", "key-1").Return(stored("hash-1", 200, now, now+1000), nil)
+
+		key, claimed, err := th.App.ClaimIdempotencyKey(ctx, "user-1", "key-1", "hash-1")
+		require.NoError(t, err)
+		require.False(t, claimed)
+		require.Equal(t, 200, key.StatusCode)
+		require.Equal(t, `{"id":"card-1"}`, key.Response)
+	})
+
+	t.Run("should wait for the concurrent request", func(t *testing.T) {
+		now := utils.GetMillis()
+		th.Store.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(errDuplicate).Times(2)
+		gomock.InOrder(
+			th.Store.EXPECT().GetIdempotencyKey("user-1", "key-1").Return(stored("hash-1", 0, now, now+1000), nil),
+			th.Store.EXPECT().GetIdempotencyKey("user-1", "key-1").Return(stored("hash-1", 201, now, now+1000), nil),
+		)
+
+		key, claimed, err := th.App.ClaimIdempotencyKey(ctx, "user-1", "key-1", "hash-1")
+		require.NoError(t, err)
+		require.False(t, claimed)
+		require.Equal(t, 201, key.StatusCode)
+	})
+
+	t.Run("should reject the key of another request", func(t *testing.T) {
+		now := utils.GetMillis()
+		th.Store.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(errDuplicate)
+		th.Store.EXPECT().GetIdempotencyKey("user-1", "key-1").Return(stored("hash-2", 200, now, now+1000), nil)
+
+		_, _, err := th.App.ClaimIdempotencyKey(ctx, "user-1", "key-1", "hash-1")
+		require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	})
+
+	t.Run("should claim an expired or abandoned key again", func(t *testing.T) {
+		now := utils.GetMillis()
+		abandonedAt := now - 2*idempotencyKeyAbandonedAfter.Milliseconds()
+		gomock.InOrder(
+			th.Store.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(errDuplicate),
+			th.Store.EXPECT().GetIdempotencyKey("user-1", "key-1").Return(stored("hash-2", 200, 1000, 2000), nil),
+			th.Store.EXPECT().DeleteIdempotencyKey("user-1", "key-1", int64(1000)).Return(nil),
+			th.Store.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(errDuplicate),
+			th.Store.EXPECT().GetIdempotencyKey("user-1", "key-1").Return(stored("hash-1", 0, abandonedAt, now+1000), nil),
+			th.Store.EXPECT().DeleteIdempotencyKey("user-1", "key-1", abandonedAt).Return(nil),
+			th.Store.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(nil),
+		)
+
+		_, claimed, err := th.App.ClaimIdempotencyKey(ctx, "user-1", "key-1", "hash-1")
+		require.NoError(t, err)
+		require.True(t, claimed)
+	})
+
+	t.Run("should fail if the key can't be stored", func(t *testing.T) {
+		th.Store.EXPECT().CreateIdempotencyKey(gomock.Any()).Return(errDuplicate).Times(idempotencyKeyClaimAttempts)
+		th.Store.EXPECT().GetIdempotencyKey("user-1", "key-1").Return(nil, sql.ErrNoRows).Times(idempotencyKeyClaimAttempts)
+
+		_, _, err := th.App.ClaimIdempotencyKey(ctx, "user-1", "key-1", "hash-1")
+		require.ErrorIs(t, err, errDuplicate)
+	})
+}
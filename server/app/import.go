@@ -0,0 +1,186 @@
+package app
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/importer"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// ImportTrello converts the Trello board and inserts its blocks at
+// once, so either the whole board is imported or nothing is.
+func (a *App) ImportTrello(ctx context.Context, c store.Container, board importer.TrelloBoard, userID string) (*model.ImportSummary, error) {
+	blocks, summary := importer.ConvertTrello(board)
+
+	if _, err := a.InsertBlocks(ctx, c, blocks, userID); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// PreviewJiraImport reads the Jira export and returns its fields, with
+// a suggested mapping of them to the properties of the board.
+func (a *App) PreviewJiraImport(input io.Reader) (*model.JiraImportPreview, error) {
+	return importer.PreviewJira(input)
+}
+
+// ImportJira converts the issues of the Jira export with the mapping and
+// inserts the blocks at once. The values of the person properties are
+// the users of the workspace with their email address or username, the
+// other ones are skipped.
+func (a *App) ImportJira(ctx context.Context, c store.Container, input io.Reader, mapping model.JiraImportMapping, userID string) (*model.ImportSummary, error) {
+	blocks, summary, err := importer.ConvertJira(input, mapping, a.workspaceUserResolver(ctx, c))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := a.InsertBlocks(ctx, c, blocks, userID); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// ImportNotion converts the databases of the Notion export and inserts
+// their blocks at once. The images of the pages are uploaded as the
+// files of the boards, the ones whose type or size isn't allowed being
+// skipped, and are removed if the blocks can't be inserted.
+func (a *App) ImportNotion(ctx context.Context, c store.Container, archive *zip.Reader, userID string) (*model.ImportSummary, error) {
+	writtenFiles := []string{}
+	upload := func(rootID, filename string, reader io.Reader) (string, error) {
+		fileID, err := a.UploadFile(reader, c.WorkspaceID, rootID, filename)
+		switch {
+		case errors.Is(err, ErrFileTypeNotAllowed), errors.Is(err, ErrFileTooLarge):
+			return "", importer.SkippedFileError{Reason: err.Error()}
+		case err != nil:
+			return "", err
+		}
+		writtenFiles = append(writtenFiles, filepath.Join(c.WorkspaceID, rootID, fileID))
+		return fileID, nil
+	}
+
+	blocks, summary, err := importer.ConvertNotion(archive, upload)
+	if err != nil {
+		a.removeFiles(writtenFiles)
+		return nil, err
+	}
+
+	if _, err := a.InsertBlocks(ctx, c, blocks, userID); err != nil {
+		a.removeFiles(writtenFiles)
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// ImportAsana converts the tasks of the Asana project export and inserts
+// the blocks at once, the comments being attributed to the imported
+// user. The board of a previous import of the project is updated rather
+// than imported again.
+func (a *App) ImportAsana(ctx context.Context, c store.Container, export importer.AsanaExport, userID string) (*model.ImportSummary, error) {
+	previous, err := a.previousAsanaImport(ctx, c, export.Project().GID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, comments, summary := importer.ConvertAsana(export, previous, a.workspaceUserResolver(ctx, c))
+	if err := a.insertImportedBlocks(ctx, c, blocks, comments, userID); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// previousAsanaImport returns the blocks of the board imported from the
+// Asana project, if any.
+func (a *App) previousAsanaImport(ctx context.Context, c store.Container, projectGID string) ([]model.Block, error) {
+	if projectGID == "" {
+		return nil, nil
+	}
+
+	boards, err := a.store.GetBlocksWithType(ctx, c, "board")
+	if err != nil {
+		return nil, err
+	}
+	for _, board := range boards {
+		if gid, _ := board.Fields[importer.AsanaProjectGIDField].(string); gid == projectGID {
+			return a.store.GetBlocksWithRootID(ctx, c, board.ID)
+		}
+	}
+	return nil, nil
+}
+
+// insertImportedBlocks inserts the blocks of an import on behalf of the
+// user, and the imported comments on behalf of the imported user, in a
+// single transaction and with the checks of InsertBlocks.
+func (a *App) insertImportedBlocks(ctx context.Context, c store.Container, blocks, comments []model.Block, userID string) error {
+	all := append(append([]model.Block{}, blocks...), comments...)
+	if err := a.checkBlocksWritable(c, userID, all); err != nil {
+		return err
+	}
+	if err := a.checkBlocksNotArchived(ctx, c, all); err != nil {
+		return err
+	}
+	if err := a.validateBlocks(ctx, a.store, c, all, storedBlockGetter(ctx, a.store, c)); err != nil {
+		return err
+	}
+	if err := a.checkBlockQuota(ctx, c, all); err != nil {
+		return err
+	}
+
+	tx, err := a.store.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.rollbackTx(tx)
+
+	result, err := tx.InsertBlocks(ctx, c, blocks, userID)
+	if err != nil {
+		return err
+	}
+	commentsResult, err := tx.InsertBlocks(ctx, c, comments, model.ImportedUserID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	a.addWorkspaceUsage(c.WorkspaceID, int64(len(result.Inserted)+len(commentsResult.Inserted)), 0)
+
+	a.blocksInserted(ctx, c, a.workspaceWebhooks(ctx, c.WorkspaceID), nil, all, userID)
+	a.broadcastChecklistProgress(ctx, c, all)
+	return nil
+}
+
+// workspaceUserResolver returns a function returning the ID of the user
+// of the workspace with an email address or a username, or an empty ID
+// if there's none, reading each user once.
+func (a *App) workspaceUserResolver(ctx context.Context, c store.Container) func(string) string {
+	userIDs := map[string]string{}
+	return func(value string) string {
+		if id, ok := userIDs[value]; ok {
+			return id
+		}
+
+		var user *model.User
+		var err error
+		if strings.Contains(value, "@") {
+			user, err = a.store.GetUserByEmail(value)
+		} else {
+			user, err = a.store.GetUserByUsername(value)
+		}
+		id := ""
+		if err == nil && user != nil && a.DoesUserHaveWorkspaceAccess(ctx, user.ID, c.WorkspaceID) {
+			id = user.ID
+		}
+		userIDs[value] = id
+		return id
+	}
+}
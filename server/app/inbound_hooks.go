@@ -0,0 +1,501 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// inboundHookSecretLength is the number of random bytes of the token of
+// an inbound webhook.
+const inboundHookSecretLength = 32
+
+// inboundHookReadOnlyTypes are the types of the card properties that the
+// server computes, which the inbound webhooks can't set.
+var inboundHookReadOnlyTypes = map[string]bool{
+	"createdTime":              true,
+	"createdBy":                true,
+	"updatedTime":              true,
+	"updatedBy":                true,
+	model.PropertyTypeComputed: true,
+}
+
+// InvalidInboundHookPayloadError is returned when the payload posted to
+// the inbound webhook of a board doesn't match the board.
+type InvalidInboundHookPayloadError struct {
+	Reason string
+}
+
+func (e InvalidInboundHookPayloadError) Error() string {
+	return "invalid inbound hook payload: " + e.Reason
+}
+
+// GetInboundHook returns the inbound webhook of the board, or nil if it
+// has none.
+func (a *App) GetInboundHook(c store.Container, boardID string) (*model.InboundHook, error) {
+	hook, err := a.store.GetInboundHook(c, boardID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return hook, err
+}
+
+// CreateInboundHook creates the inbound webhook of the board with a new
+// token, revoking the previous one if any. The returned token is the
+// only copy of it, as only its hash is stored. It returns nil if the
+// board doesn't exist.
+func (a *App) CreateInboundHook(ctx context.Context, c store.Container, boardID, userID string, allowNewOptions bool) (*model.InboundHook, error) {
+	board, err := a.GetBoard(ctx, c, boardID)
+	if err != nil || board == nil {
+		return nil, err
+	}
+
+	secret := make([]byte, inboundHookSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("unable to generate the inbound hook token: %w", err)
+	}
+	token := hex.EncodeToString(secret)
+
+	hook := model.InboundHook{
+		BoardID:         boardID,
+		WorkspaceID:     c.WorkspaceID,
+		TokenHash:       model.HashToken(token),
+		AllowNewOptions: allowNewOptions,
+		CreatedBy:       userID,
+		CreateAt:        utils.GetMillis(),
+	}
+	if err := a.store.UpsertInboundHook(c, hook); err != nil {
+		return nil, err
+	}
+
+	a.recordAuditEntry(model.AuditActionCreateInboundHook, userID, c.WorkspaceID, boardID, map[string]interface{}{
+		"allowNewOptions": allowNewOptions,
+	})
+
+	hook.Token = token
+	return &hook, nil
+}
+
+// RevokeInboundHook revokes the inbound webhook of the board. It returns
+// sql.ErrNoRows if the board has none.
+func (a *App) RevokeInboundHook(c store.Container, boardID, userID string) error {
+	if err := a.store.DeleteInboundHook(c, boardID); err != nil {
+		return err
+	}
+
+	a.recordAuditEntry(model.AuditActionRevokeInboundHook, userID, c.WorkspaceID, boardID, nil)
+	return nil
+}
+
+// GetInboundHookByToken returns the inbound webhook with the token, or
+// nil if the token is invalid.
+func (a *App) GetInboundHookByToken(token string) (*model.InboundHook, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	hook, err := a.store.GetInboundHookByTokenHash(model.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return hook, err
+}
+
+// GetInboundHookDeliveries returns the last deliveries of the inbound
+// webhook of the board, the most recent first.
+func (a *App) GetInboundHookDeliveries(c store.Container, boardID string) ([]model.InboundHookDelivery, error) {
+	return a.store.GetInboundHookDeliveries(c, boardID, model.InboundHookDeliveriesLimit)
+}
+
+// RecordInboundHookDelivery stores a delivery of the inbound webhook,
+// keeping the last ones of its board. Failing to store it is logged, as
+// it doesn't change the outcome of the delivery.
+func (a *App) RecordInboundHookDelivery(hook model.InboundHook, payload, cardID string, statusCode int, deliveryErr string) {
+	delivery := model.InboundHookDelivery{
+		ID:         utils.CreateGUID(),
+		BoardID:    hook.BoardID,
+		CardID:     cardID,
+		Payload:    payload,
+		StatusCode: statusCode,
+		Error:      deliveryErr,
+		CreateAt:   utils.GetMillis(),
+	}
+	c := store.Container{WorkspaceID: hook.WorkspaceID}
+	if err := a.store.InsertInboundHookDelivery(c, delivery, model.InboundHookDeliveriesLimit); err != nil {
+		a.logger.Error("Unable to record the inbound hook delivery", mlog.String("boardID", hook.BoardID), mlog.Err(err))
+	}
+}
+
+// ApplyInboundHook creates a card of the board of the inbound webhook
+// from the payload, or updates the card of the payload, on behalf of the
+// creator of the hook. Unknown option names create new options if the
+// hook allows it. It fails with an InvalidInboundHookPayloadError if the
+// payload doesn't match the board, and returns sql.ErrNoRows if the
+// board or the card doesn't exist.
+func (a *App) ApplyInboundHook(ctx context.Context, hook model.InboundHook, payload model.InboundHookPayload) (*model.Block, error) {
+	c := store.Container{WorkspaceID: hook.WorkspaceID}
+	board, err := a.GetBoard(ctx, c, hook.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	var card *model.Block
+	if payload.CardID != "" {
+		card, err = a.GetCard(ctx, c, payload.CardID)
+		if err != nil {
+			return nil, err
+		}
+		if card == nil || card.RootID != board.ID {
+			return nil, sql.ErrNoRows
+		}
+		if payload.Title == "" && payload.Description == "" && len(payload.Properties) == 0 {
+			return nil, InvalidInboundHookPayloadError{Reason: "nothing to update"}
+		}
+	} else if strings.TrimSpace(payload.Title) == "" {
+		return nil, InvalidInboundHookPayloadError{Reason: "the title of a new card is required"}
+	}
+
+	properties, cardProperties, err := a.inboundHookProperties(ctx, c, *board, hook.AllowNewOptions, payload.Properties)
+	if err != nil {
+		return nil, err
+	}
+	if cardProperties != nil {
+		patch := &model.BlockPatch{UpdatedFields: map[string]interface{}{"cardProperties": cardProperties}}
+		if _, err := a.PatchBlock(ctx, c, board.ID, patch, hook.CreatedBy, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if card == nil {
+		return a.insertInboundHookCard(ctx, c, *board, hook.CreatedBy, payload, properties)
+	}
+	return a.updateInboundHookCard(ctx, c, *card, hook.CreatedBy, payload, properties)
+}
+
+func (a *App) insertInboundHookCard(ctx context.Context, c store.Container, board model.Block, userID string,
+	payload model.InboundHookPayload, properties map[string]interface{}) (*model.Block, error) {
+	now := utils.GetMillis()
+	card := model.Block{
+		ID:         utils.CreateGUID(),
+		ParentID:   board.ID,
+		RootID:     board.ID,
+		CreatedBy:  userID,
+		ModifiedBy: userID,
+		Type:       "card",
+		Title:      payload.Title,
+		Fields: map[string]interface{}{
+			"properties":   properties,
+			"contentOrder": []interface{}{},
+			"isTemplate":   false,
+		},
+		CreateAt: now,
+		UpdateAt: now,
+	}
+
+	blocks, err := a.ApplyDefaultCardTemplate(ctx, c, []model.Block{card})
+	if err != nil {
+		return nil, err
+	}
+	if payload.Description != "" {
+		text := inboundHookTextBlock(blocks[0], userID, payload.Description, now)
+		contentOrder, _ := blocks[0].Fields["contentOrder"].([]interface{})
+		blocks[0].Fields["contentOrder"] = append([]interface{}{text.ID}, contentOrder...)
+		blocks = append(blocks, text)
+	}
+	if _, err := a.InsertBlocks(ctx, c, blocks, userID); err != nil {
+		return nil, err
+	}
+	return &blocks[0], nil
+}
+
+// updateInboundHookCard patches the title and the properties of the
+// card, and replaces the text of its first text block with the
+// description, adding the block if the card has none.
+func (a *App) updateInboundHookCard(ctx context.Context, c store.Container, card model.Block, userID string,
+	payload model.InboundHookPayload, properties map[string]interface{}) (*model.Block, error) {
+	patch := &model.BlockPatch{UpdatedProperties: properties}
+	if payload.Title != "" {
+		patch.Title = &payload.Title
+	}
+
+	if payload.Description != "" {
+		texts, err := a.GetBlocks(ctx, c, card.ID, "text")
+		if err != nil {
+			return nil, err
+		}
+		textIDs := map[string]bool{}
+		for _, text := range texts {
+			textIDs[text.ID] = true
+		}
+
+		contentOrder, _ := card.Fields["contentOrder"].([]interface{})
+		firstTextID := ""
+		for _, id := range contentOrder {
+			if id, ok := id.(string); ok && textIDs[id] {
+				firstTextID = id
+				break
+			}
+		}
+
+		if firstTextID != "" {
+			textPatch := &model.BlockPatch{Title: &payload.Description}
+			if _, err := a.PatchBlock(ctx, c, firstTextID, textPatch, userID, false); err != nil {
+				return nil, err
+			}
+		} else {
+			text := inboundHookTextBlock(card, userID, payload.Description, utils.GetMillis())
+			if _, err := a.InsertBlocks(ctx, c, []model.Block{text}, userID); err != nil {
+				return nil, err
+			}
+			patch.UpdatedFields = map[string]interface{}{
+				"contentOrder": append([]interface{}{text.ID}, contentOrder...),
+			}
+		}
+	}
+
+	if patch.Title == nil && len(patch.UpdatedProperties) == 0 && len(patch.UpdatedFields) == 0 {
+		return a.GetCard(ctx, c, card.ID)
+	}
+	result, err := a.PatchBlock(ctx, c, card.ID, patch, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	return result.After, nil
+}
+
+// inboundHookTextBlock returns the text block of the description of the card.
+func inboundHookTextBlock(card model.Block, userID, description string, now int64) model.Block {
+	return model.Block{
+		ID:         utils.CreateGUID(),
+		ParentID:   card.ID,
+		RootID:     card.RootID,
+		CreatedBy:  userID,
+		ModifiedBy: userID,
+		Type:       "text",
+		Title:      description,
+		Fields:     map[string]interface{}{},
+		CreateAt:   now,
+		UpdateAt:   now,
+	}
+}
+
+// inboundHookProperties maps the property values of the payload, keyed
+// by the names of the properties, to the property values of a card of
+// the board. Along with the values, it returns the card properties of
+// the board with the options it added, or nil if it added none.
+func (a *App) inboundHookProperties(ctx context.Context, c store.Container, board model.Block, allowNewOptions bool,
+	values map[string]interface{}) (map[string]interface{}, []interface{}, error) {
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	templates := make([]interface{}, len(cardProperties))
+	copy(templates, cardProperties)
+	optionsAdded := false
+
+	findTemplate := func(name string) (int, map[string]interface{}) {
+		normalized := strings.TrimSpace(name)
+		for i, item := range templates {
+			template, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id, _ := template["id"].(string)
+			templateName, _ := template["name"].(string)
+			if id == name || strings.EqualFold(strings.TrimSpace(templateName), normalized) {
+				return i, template
+			}
+		}
+		return -1, nil
+	}
+
+	// findOption returns the ID of the option of the property with the
+	// name, adding the option if it's allowed
+	findOption := func(index int, propertyName string, value interface{}) (string, error) {
+		name, ok := value.(string)
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return "", InvalidInboundHookPayloadError{Reason: fmt.Sprintf("the values of the property %q must be option names", propertyName)}
+		}
+
+		template := templates[index].(map[string]interface{})
+		options, _ := template["options"].([]interface{})
+		for _, item := range options {
+			option, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id, _ := option["id"].(string)
+			optionValue, _ := option["value"].(string)
+			if id == name || strings.EqualFold(strings.TrimSpace(optionValue), name) {
+				return id, nil
+			}
+		}
+		if !allowNewOptions {
+			return "", InvalidInboundHookPayloadError{Reason: fmt.Sprintf("the property %q has no option %q", propertyName, name)}
+		}
+
+		// the template is copied, so that the board isn't changed until
+		// its patch is stored
+		templateCopy := map[string]interface{}{}
+		for key, value := range template {
+			templateCopy[key] = value
+		}
+		option := map[string]interface{}{
+			"id":    utils.CreateGUID(),
+			"value": name,
+			"color": "propColorDefault",
+		}
+		templateCopy["options"] = append(append([]interface{}{}, options...), option)
+		templates[index] = templateCopy
+		optionsAdded = true
+		return option["id"].(string), nil
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	properties := map[string]interface{}{}
+	for _, name := range names {
+		value := values[name]
+		index, template := findTemplate(name)
+		if template == nil {
+			return nil, nil, InvalidInboundHookPayloadError{Reason: fmt.Sprintf("the board has no property %q", name)}
+		}
+		if value == nil {
+			continue
+		}
+
+		id, _ := template["id"].(string)
+		propertyType, _ := template["type"].(string)
+		switch {
+		case inboundHookReadOnlyTypes[propertyType]:
+			return nil, nil, InvalidInboundHookPayloadError{Reason: fmt.Sprintf("the property %q is read-only", name)}
+		case propertyType == "select":
+			optionID, err := findOption(index, name, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			properties[id] = optionID
+		case propertyType == "multiSelect":
+			items, ok := value.([]interface{})
+			if !ok {
+				items = []interface{}{value}
+			}
+			optionIDs := []interface{}{}
+			for _, item := range items {
+				optionID, err := findOption(index, name, item)
+				if err != nil {
+					return nil, nil, err
+				}
+				optionIDs = append(optionIDs, optionID)
+			}
+			properties[id] = optionIDs
+		case propertyType == "person":
+			userID, err := a.inboundHookPerson(ctx, c, name, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			properties[id] = userID
+		case propertyType == "date":
+			date, err := inboundHookDate(name, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			properties[id] = date
+		default:
+			text, err := inboundHookValue(name, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			properties[id] = text
+		}
+	}
+
+	if !optionsAdded {
+		return properties, nil, nil
+	}
+	return properties, templates, nil
+}
+
+// inboundHookPerson returns the ID of the user of the workspace with the
+// username of the value.
+func (a *App) inboundHookPerson(ctx context.Context, c store.Container, propertyName string, value interface{}) (string, error) {
+	username, _ := value.(string)
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	if username == "" {
+		return "", InvalidInboundHookPayloadError{Reason: fmt.Sprintf("the value of the property %q must be a username", propertyName)}
+	}
+
+	user, err := a.store.GetUserByUsername(username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	if user == nil || user.DeleteAt != 0 || !a.DoesUserHaveWorkspaceAccess(ctx, user.ID, c.WorkspaceID) {
+		return "", InvalidInboundHookPayloadError{Reason: fmt.Sprintf("the workspace has no user %q", username)}
+	}
+	return user.ID, nil
+}
+
+// inboundHookDate returns the date property value of the value, a time
+// in milliseconds, or a date or a time in the RFC 3339 format.
+func inboundHookDate(propertyName string, value interface{}) (string, error) {
+	var millis int64
+	switch value := value.(type) {
+	case float64:
+		millis = int64(value)
+	case string:
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+		if err != nil {
+			date, err = time.Parse(time.RFC3339, strings.TrimSpace(value))
+		}
+		if err != nil {
+			return "", InvalidInboundHookPayloadError{Reason: fmt.Sprintf("the value of the property %q isn't a date", propertyName)}
+		}
+		millis = date.UnixNano() / int64(time.Millisecond)
+	default:
+		return "", InvalidInboundHookPayloadError{Reason: fmt.Sprintf("the value of the property %q isn't a date", propertyName)}
+	}
+
+	data, err := json.Marshal(map[string]int64{"from": millis})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// inboundHookValue returns the text property value of the value, a
+// string, a number or a boolean. False is the empty value of the
+// checkbox properties.
+func inboundHookValue(propertyName string, value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	case bool:
+		if value {
+			return "true", nil
+		}
+		return "", nil
+	default:
+		return "", InvalidInboundHookPayloadError{Reason: fmt.Sprintf("the value of the property %q must be a string, a number or a boolean", propertyName)}
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboundHookProperties(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	board := model.Block{ID: "board-1", RootID: "board-1", Type: "board", Fields: map[string]interface{}{
+		"cardProperties": []interface{}{
+			map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+				map[string]interface{}{"id": "done", "value": "Done"},
+			}},
+			map[string]interface{}{"id": "labels", "name": "Labels", "type": "multiSelect", "options": []interface{}{
+				map[string]interface{}{"id": "bug", "value": "Bug"},
+			}},
+			map[string]interface{}{"id": "owner", "name": "Owner", "type": "person"},
+			map[string]interface{}{"id": "due", "name": "Due date", "type": "date"},
+			map[string]interface{}{"id": "estimate", "name": "Estimate", "type": "number"},
+			map[string]interface{}{"id": "approved", "name": "Approved", "type": "checkbox"},
+			map[string]interface{}{"id": "created", "name": "Created", "type": "createdTime"},
+		},
+	}}
+
+	t.Run("should map the names to the IDs", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("sara")).Return(&model.User{ID: "sara-id", Username: "sara"}, nil)
+		th.Store.EXPECT().HasWorkspaceAccess(gomock.Any(), gomock.Eq("sara-id"), gomock.Eq("0")).Return(true, nil)
+
+		properties, cardProperties, err := th.App.inboundHookProperties(ctx, container, board, false, map[string]interface{}{
+			"status":   " done ",
+			"labels":   "Bug",
+			"Owner":    "@sara",
+			"Due date": "2030-01-02",
+			"estimate": 2.5,
+			"approved": true,
+			"Created":  nil,
+		})
+		require.NoError(t, err)
+		require.Nil(t, cardProperties)
+		require.Equal(t, map[string]interface{}{
+			"status":   "done",
+			"labels":   []interface{}{"bug"},
+			"owner":    "sara-id",
+			"due":      `{"from":1893542400000}`,
+			"estimate": "2.5",
+			"approved": "true",
+		}, properties)
+	})
+
+	t.Run("should reject the values that don't match the board", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("nobody")).Return(nil, sql.ErrNoRows)
+
+		for _, values := range []map[string]interface{}{
+			{"Priority": "High"},
+			{"Status": "Unknown"},
+			{"Labels": []interface{}{"Bug", 1.0}},
+			{"Owner": "nobody"},
+			{"Due date": "tomorrow"},
+			{"Estimate": map[string]interface{}{}},
+			{"Created": 1000.0},
+		} {
+			_, _, err := th.App.inboundHookProperties(ctx, container, board, false, values)
+			var payloadErr InvalidInboundHookPayloadError
+			require.ErrorAs(t, err, &payloadErr, values)
+		}
+	})
+
+	t.Run("should add the options if allowed", func(t *testing.T) {
+		properties, cardProperties, err := th.App.inboundHookProperties(ctx, container, board, true, map[string]interface{}{
+			"Status": "In progress",
+			"Labels": []interface{}{"bug", "Feature", "feature"},
+		})
+		require.NoError(t, err)
+		require.Len(t, cardProperties, 7)
+
+		statusOptions := cardProperties[0].(map[string]interface{})["options"].([]interface{})
+		require.Len(t, statusOptions, 2)
+		newStatus := statusOptions[1].(map[string]interface{})
+		require.Equal(t, "In progress", newStatus["value"])
+		require.Equal(t, newStatus["id"], properties["status"])
+
+		labelOptions := cardProperties[1].(map[string]interface{})["options"].([]interface{})
+		require.Len(t, labelOptions, 2)
+		newLabel := labelOptions[1].(map[string]interface{})["id"]
+		require.Equal(t, []interface{}{"bug", newLabel, newLabel}, properties["labels"])
+
+		// the board itself is left as is until it's patched
+		options := board.Fields["cardProperties"].([]interface{})[0].(map[string]interface{})["options"].([]interface{})
+		require.Len(t, options, 1)
+	})
+}
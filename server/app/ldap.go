@@ -0,0 +1,197 @@
+package app
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/ldap"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	loginMethodLDAP = "ldap"
+
+	// ldapAuthDataPrefix marks the auth data of the LDAP users, followed
+	// by the ID of their entry, apart from the identities of the single
+	// sign-on
+	ldapAuthDataPrefix = "ldap:"
+)
+
+var (
+	// ErrLDAPUnavailable is returned by the logins when the LDAP server
+	// can't be reached.
+	ErrLDAPUnavailable = ldap.ErrUnavailable
+
+	// ErrLDAPRegistrationDisabled is returned by the registrations when
+	// the users log in with the LDAP server, which they're registered in.
+	ErrLDAPRegistrationDisabled = errors.New("the users are registered in the directory, log in with its account")
+
+	// ErrLDAPPasswordChange is returned when the password of an LDAP user
+	// is changed, which can only be done in the directory.
+	ErrLDAPPasswordChange = errors.New("the password of a directory account can only be changed in the directory")
+
+	// ErrLDAPUsernameTaken is returned when the username of an entry is
+	// the one of a guest, or of a user linked to another identity.
+	ErrLDAPUsernameTaken = errors.New("the username of the directory account is taken by another user")
+
+	// ErrLDAPUserDeactivated is returned when the user of an entry is
+	// deactivated.
+	ErrLDAPUserDeactivated = errors.New("the user is deactivated")
+)
+
+// newLDAPClient returns the client of the LDAP server of the
+// configuration when it's the auth backend, or nil.
+func newLDAPClient(cfg *config.Configuration) *ldap.Client {
+	if cfg.AuthBackend != config.AuthBackendLDAP {
+		return nil
+	}
+	return ldap.NewClient(cfg.LDAP)
+}
+
+// IsLDAPEnabled tells if the users log in with the LDAP server.
+func (a *App) IsLDAPEnabled() bool {
+	return a.ldap != nil
+}
+
+// isLDAPUser tells if the user logs in with the LDAP server.
+func isLDAPUser(user *model.User) bool {
+	return strings.HasPrefix(user.AuthData, ldapAuthDataPrefix)
+}
+
+// authenticateWithLDAP checks the password with the LDAP server, and
+// returns the user of the entry, linking or provisioning it on the first
+// login. The guests, who are invited out of the directory, are checked
+// with their local password.
+func (a *App) authenticateWithLDAP(username, email, password, address string) (*model.User, error) {
+	if a.isLocalGuest(username, email) {
+		return a.authenticateWithPassword(username, email, password, address)
+	}
+
+	entry, err := a.ldap.Authenticate(username, email, password)
+	if errors.Is(err, ldap.ErrInvalidCredentials) {
+		a.loginFailed("", username, email, address)
+		return nil, errors.New("invalid username or password")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to authenticate with the LDAP server")
+	}
+
+	user, err := a.getLDAPUser(entry)
+	if err != nil {
+		a.loginFailed("", username, email, address)
+		return nil, err
+	}
+	return user, nil
+}
+
+// isLocalGuest tells if the username, or the email, is the one of a
+// guest with a local password.
+func (a *App) isLocalGuest(username, email string) bool {
+	var user *model.User
+	var err error
+	if username != "" {
+		user, err = a.store.GetUserByUsername(username)
+	} else {
+		user, err = a.store.GetUserByEmail(email)
+	}
+	return err == nil && user.IsGuest && !isLDAPUser(user)
+}
+
+// getLDAPUser returns the user of the entry, linking the local user with
+// its username, or provisioning a new user.
+func (a *App) getLDAPUser(entry *ldap.Entry) (*model.User, error) {
+	authData := ldapAuthDataPrefix + entry.ID
+	user, err := a.store.GetUserByAuthData(authData)
+	if err == nil {
+		if user.DeleteAt != 0 {
+			return nil, ErrLDAPUserDeactivated
+		}
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "unable to get the user of the entry")
+	}
+
+	username := strings.ToLower(strings.TrimSpace(entry.Username))
+	if username == "" {
+		return nil, errors.Errorf("the entry %q has no username", entry.DN)
+	}
+
+	user, err = a.store.GetUserByUsername(username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "unable to get the user of the username")
+	}
+	if err == nil && user != nil {
+		return a.linkLDAPUser(user, entry, authData)
+	}
+
+	return a.provisionLDAPUser(entry, username, authData)
+}
+
+// linkLDAPUser links the local user to the entry of its username, unless
+// it's a guest, or it's already linked.
+func (a *App) linkLDAPUser(user *model.User, entry *ldap.Entry, authData string) (*model.User, error) {
+	if user.IsGuest || user.AuthData != "" {
+		return nil, ErrLDAPUsernameTaken
+	}
+
+	if err := a.store.UpdateUserAuthData(user.ID, authData); err != nil {
+		return nil, errors.Wrap(err, "unable to link the user to the entry")
+	}
+	user.AuthData = authData
+
+	a.recordAuditEntry(model.AuditActionLinkLDAP, user.ID, "", user.ID, map[string]interface{}{
+		"dn": entry.DN,
+	})
+	return user, nil
+}
+
+// provisionLDAPUser creates the user of the entry, without a password,
+// with its mapped attributes. The email is dropped if another user has
+// it.
+func (a *App) provisionLDAPUser(entry *ldap.Entry, username, authData string) (*model.User, error) {
+	email := strings.TrimSpace(entry.Email)
+	if email != "" {
+		_, err := a.store.GetUserByEmail(email)
+		if err == nil {
+			email = ""
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(err, "unable to check the email")
+		}
+	}
+
+	// the first user administers the server
+	userCount, err := a.store.GetRegisteredUserCount()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to count the users")
+	}
+
+	props := map[string]interface{}{}
+	if entry.Nickname != "" {
+		props[model.UserPropNickname] = entry.Nickname
+	}
+
+	user := &model.User{
+		ID:          uuid.New().String(),
+		Username:    username,
+		Email:       email,
+		AuthService: a.config.AuthMode,
+		AuthData:    authData,
+		Props:       props,
+		IsAdmin:     userCount == 0,
+	}
+	if err = a.store.CreateUser(user); err != nil {
+		return nil, errors.Wrap(err, "unable to create the user of the entry")
+	}
+
+	a.recordAuditEntry(model.AuditActionProvisionUser, user.ID, "", user.ID, map[string]interface{}{
+		"username": user.Username,
+		"email":    user.Email,
+		"dn":       entry.DN,
+	})
+	return user, nil
+}
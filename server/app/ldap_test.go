@@ -0,0 +1,187 @@
+package app
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/ldap"
+	"github.com/mattermost/focalboard/server/services/ldap/ldaptest"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const testLDAPUserDN = "uid=alice,ou=people,dc=example,dc=com"
+
+func setupTestLDAP(t *testing.T, th *TestHelper) {
+	server := ldaptest.NewServer()
+	t.Cleanup(server.Close)
+	server.AddEntry(testLDAPUserDN, "alice password", map[string][]string{
+		"uid":  {"Alice"},
+		"mail": {"alice@example.com"},
+		"cn":   {"Alice Smith"},
+	})
+
+	th.App.ldap = ldap.NewClient(config.LDAPConfig{
+		Server:         server.Host(),
+		Port:           server.Port(),
+		UserDNTemplate: "uid={username},ou=people,dc=example,dc=com",
+	})
+	t.Cleanup(th.App.ldap.Close)
+}
+
+func TestLDAPLogin(t *testing.T) {
+	authData := ldapAuthDataPrefix + testLDAPUserDN
+
+	t.Run("should provision a user with the attributes of the entry", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+		setupTestLDAP(t, th)
+
+		var created *model.User
+		th.Store.EXPECT().GetUserByUsername("alice").Return(nil, sql.ErrNoRows).Times(2)
+		th.Store.EXPECT().GetUserByAuthData(authData).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetUserByEmail("alice@example.com").Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetRegisteredUserCount().Return(1, nil)
+		th.Store.EXPECT().CreateUser(gomock.Any()).DoAndReturn(func(user *model.User) error {
+			created = user
+			return nil
+		})
+		th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+		th.Store.EXPECT().InsertAuditEntry(gomock.Any()).Return(nil).Times(2)
+
+		token, err := th.App.Login("alice", "", "alice password", "", "")
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+
+		require.Equal(t, "alice", created.Username)
+		require.Equal(t, "alice@example.com", created.Email)
+		require.Equal(t, "Alice Smith", created.Props[model.UserPropNickname])
+		require.Equal(t, authData, created.AuthData)
+		require.Empty(t, created.Password)
+		require.False(t, created.IsAdmin)
+	})
+
+	t.Run("should log in the linked user", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+		setupTestLDAP(t, th)
+
+		user := &model.User{ID: "user-1", Username: "alice", AuthData: authData}
+		th.Store.EXPECT().GetUserByUsername("alice").Return(user, nil)
+		th.Store.EXPECT().GetUserByAuthData(authData).Return(user, nil)
+		th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+		expectAuditEntry(th, model.AuditActionLogin, "user-1", "user-1")
+
+		_, err := th.App.Login("alice", "", "alice password", "", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("should link the local user of the username", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+		setupTestLDAP(t, th)
+
+		user := &model.User{ID: "user-1", Username: "alice", Password: auth.HashPassword("local password")}
+		th.Store.EXPECT().GetUserByUsername("alice").Return(user, nil).Times(3)
+		th.Store.EXPECT().GetUserByAuthData(authData).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().UpdateUserAuthData("user-1", authData).Return(nil)
+		th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+		expectAuditEntry(th, model.AuditActionLoginFailed, "", "")
+		expectAuditEntry(th, model.AuditActionLinkLDAP, "user-1", "user-1")
+		expectAuditEntry(th, model.AuditActionLogin, "user-1", "user-1")
+
+		_, err := th.App.Login("alice", "", "local password", "", "")
+		require.Error(t, err, "the local password isn't checked")
+
+		_, err = th.App.Login("alice", "", "alice password", "", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("should not link the user of another identity", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+		setupTestLDAP(t, th)
+
+		user := &model.User{ID: "user-1", Username: "alice", AuthData: "subject-1"}
+		th.Store.EXPECT().GetUserByUsername("alice").Return(user, nil).Times(2)
+		th.Store.EXPECT().GetUserByAuthData(authData).Return(nil, sql.ErrNoRows)
+		expectAuditEntry(th, model.AuditActionLoginFailed, "", "")
+
+		_, err := th.App.Login("alice", "", "alice password", "", "")
+		require.ErrorIs(t, err, ErrLDAPUsernameTaken)
+	})
+
+	t.Run("should check the local password of a guest", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+		setupTestLDAP(t, th)
+
+		guest := &model.User{ID: "guest-1", Username: "alice", IsGuest: true, Password: auth.HashPassword("guest password")}
+		th.Store.EXPECT().GetUserByUsername("alice").Return(guest, nil).Times(2)
+		th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+		expectAuditEntry(th, model.AuditActionLogin, "guest-1", "guest-1")
+
+		_, err := th.App.Login("alice", "", "guest password", "", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject a deactivated user", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+		setupTestLDAP(t, th)
+
+		th.Store.EXPECT().GetUserByUsername("alice").Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetUserByAuthData(authData).Return(&model.User{ID: "user-1", DeleteAt: 1}, nil)
+		expectAuditEntry(th, model.AuditActionLoginFailed, "", "")
+
+		_, err := th.App.Login("alice", "", "alice password", "", "")
+		require.ErrorIs(t, err, ErrLDAPUserDeactivated)
+	})
+
+	t.Run("should reject a wrong password", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+		setupTestLDAP(t, th)
+
+		th.Store.EXPECT().GetUserByUsername("alice").Return(nil, sql.ErrNoRows)
+		expectAuditEntry(th, model.AuditActionLoginFailed, "", "")
+
+		_, err := th.App.Login("alice", "", "wrong password", "", "")
+		require.Error(t, err)
+	})
+
+	t.Run("should reject the registrations", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+		setupTestLDAP(t, th)
+
+		require.ErrorIs(t, th.App.RegisterUser("bob", "bob@example.com", "password"), ErrLDAPRegistrationDisabled)
+	})
+}
+
+func TestLDAPPasswordChange(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	user := &model.User{ID: "user-1", Username: "alice", AuthData: ldapAuthDataPrefix + testLDAPUserDN}
+	th.Store.EXPECT().GetUserByID("user-1").Return(user, nil).AnyTimes()
+	th.Store.EXPECT().GetUserByUsername("alice").Return(user, nil).AnyTimes()
+
+	require.ErrorIs(t, th.App.ChangePassword("user-1", "old password", "new password"), ErrLDAPPasswordChange)
+	require.ErrorIs(t, th.App.ResetUserPassword("admin-1", "user-1", "new password"), ErrLDAPPasswordChange)
+	require.ErrorIs(t, th.App.UpdateUserPassword("alice", "new password"), ErrLDAPPasswordChange)
+
+	t.Run("with a reset token", func(t *testing.T) {
+		tokenHash := model.HashToken("token")
+		th.Store.EXPECT().ConsumePasswordResetToken(tokenHash).Return(&model.PasswordResetToken{
+			TokenHash: tokenHash,
+			UserID:    "user-1",
+			ExpireAt:  utils.GetMillis() + 1000,
+		}, nil)
+		require.ErrorIs(t, th.App.CompletePasswordReset("token", "new password"), ErrLDAPPasswordChange)
+	})
+}
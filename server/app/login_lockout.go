@@ -0,0 +1,170 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/ratelimit"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// LoginLockedError is returned by Login when the username or the client
+// address is locked out after too many failed logins.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return "too many failed logins"
+}
+
+// newLoginLockout returns the lockout for the threshold, or nil if the
+// logins aren't locked out.
+func newLoginLockout(cfg *config.Configuration, threshold int, store store.Store) *ratelimit.Lockout {
+	if threshold <= 0 {
+		return nil
+	}
+
+	window := cfg.LoginLockoutWindow
+	if window <= 0 {
+		window = config.DefaultLoginLockoutWindow
+	}
+	duration := cfg.LoginLockoutDuration
+	if duration <= 0 {
+		duration = config.DefaultLoginLockoutDuration
+	}
+	maxDuration := cfg.LoginLockoutMaxDuration
+	if maxDuration <= 0 {
+		maxDuration = config.DefaultLoginLockoutMaxDuration
+	}
+
+	return ratelimit.NewLockout(
+		threshold,
+		time.Duration(window)*time.Second,
+		time.Duration(duration)*time.Second,
+		time.Duration(maxDuration)*time.Second,
+		store,
+	)
+}
+
+// loginLockoutUserKey returns the key of the failed logins of the user
+// with the ID, so that they're counted together whether the username or
+// the email is typed in, or else of the identifier of the unknown user.
+func loginLockoutUserKey(userID, username, email string) string {
+	if userID != "" {
+		return "user-id:" + userID
+	}
+	if username == "" {
+		username = email
+	}
+	if username == "" {
+		return ""
+	}
+	return "user:" + strings.ToLower(username)
+}
+
+// loginUserID returns the ID of the user logging in with the username,
+// or else with the email, or "" if there is none.
+func (a *App) loginUserID(username, email string) string {
+	var user *model.User
+	var err error
+	if username != "" {
+		user, err = a.store.GetUserByUsername(username)
+	} else if email != "" {
+		user, err = a.store.GetUserByEmail(email)
+	}
+	if err != nil || user == nil {
+		return ""
+	}
+	return user.ID
+}
+
+func loginLockoutIPKey(address string) string {
+	if address == "" {
+		return ""
+	}
+	return "ip:" + address
+}
+
+type loginLockoutKey struct {
+	lockout *ratelimit.Lockout
+	key     string
+}
+
+func (a *App) loginLockoutKeys(userID, username, email, address string) []loginLockoutKey {
+	keys := []loginLockoutKey{}
+	if a.userLoginLockout != nil && userID == "" {
+		userID = a.loginUserID(username, email)
+	}
+	if key := loginLockoutUserKey(userID, username, email); a.userLoginLockout != nil && key != "" {
+		keys = append(keys, loginLockoutKey{a.userLoginLockout, key})
+	}
+	if key := loginLockoutIPKey(address); a.ipLoginLockout != nil && key != "" {
+		keys = append(keys, loginLockoutKey{a.ipLoginLockout, key})
+	}
+	return keys
+}
+
+// checkLoginLockout returns a LoginLockedError if the username or the
+// client address is locked out. The lockout fails open if the failures
+// can't be read.
+func (a *App) checkLoginLockout(username, email, address string) error {
+	var retryAfter time.Duration
+	for _, k := range a.loginLockoutKeys("", username, email, address) {
+		lockedFor, err := k.lockout.LockedFor(k.key)
+		if err != nil {
+			a.logger.Error("Unable to get the failed logins", mlog.String("key", k.key), mlog.Err(err))
+			continue
+		}
+		if lockedFor > retryAfter {
+			retryAfter = lockedFor
+		}
+	}
+	if retryAfter == 0 {
+		return nil
+	}
+
+	a.recordAuditEntry(model.AuditActionLoginLocked, "", "", "", map[string]interface{}{
+		"username":   username,
+		"email":      email,
+		"address":    address,
+		"retryAfter": retryAfter.Milliseconds(),
+	})
+	return &LoginLockedError{RetryAfter: retryAfter}
+}
+
+// countLoginFailure counts a failed login against the user, with the ID
+// if it's known, and the client address.
+func (a *App) countLoginFailure(userID, username, email, address string) {
+	for _, k := range a.loginLockoutKeys(userID, username, email, address) {
+		lockedFor, err := k.lockout.Fail(k.key)
+		if err != nil {
+			a.logger.Error("Unable to store the failed login", mlog.String("key", k.key), mlog.Err(err))
+		}
+		if lockedFor > 0 {
+			a.logger.Warn("Too many failed logins, locking out", mlog.String("key", k.key), mlog.Duration("duration", lockedFor))
+		}
+	}
+}
+
+// clearLoginFailures forgets the failed logins of the user after a
+// successful login. The failures of the client address are kept, so that
+// logging in to an account doesn't allow guessing the others.
+func (a *App) clearLoginFailures(user *model.User) {
+	if a.userLoginLockout == nil {
+		return
+	}
+
+	for _, key := range []string{loginLockoutUserKey(user.ID, "", ""), loginLockoutUserKey("", user.Username, ""), loginLockoutUserKey("", "", user.Email)} {
+		if key == "" {
+			continue
+		}
+		if err := a.userLoginLockout.Clear(key); err != nil {
+			a.logger.Error("Unable to clear the failed logins", mlog.String("key", key), mlog.Err(err))
+		}
+	}
+}
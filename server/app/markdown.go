@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/render"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// BoardMarkdownExport exports a board as a Markdown document.
+type BoardMarkdownExport struct {
+	document render.Document
+}
+
+// NewBoardMarkdownExport renders the board, with its cards grouped as in
+// the view, or in the first view of the board if viewID is empty. The
+// dates are formatted in the locale of the workspace. It returns nil if
+// the board or the view don't exist.
+func (a *App) NewBoardMarkdownExport(ctx context.Context, c store.Container, boardID, viewID string) (*BoardMarkdownExport, error) {
+	board, err := a.store.GetBlock(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil || board.Type != "board" {
+		return nil, nil
+	}
+
+	blocks, err := a.store.GetSubTree3(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	views := []model.Block{}
+	cards := []model.Block{}
+	content := []model.Block{}
+	for _, block := range blocks {
+		switch {
+		case block.ID == boardID:
+		case block.ParentID == boardID && block.Type == "view":
+			views = append(views, block)
+		case block.ParentID == boardID && block.Type == "card":
+			cards = append(cards, block)
+		default:
+			content = append(content, block)
+		}
+	}
+	sortCardsByOrder(cards, legacyCardOrder(views))
+
+	var view *model.Block
+	for i := range views {
+		if views[i].ID == viewID {
+			view = &views[i]
+		}
+	}
+	if viewID != "" && view == nil {
+		return nil, nil
+	}
+	if viewID == "" && len(views) > 0 {
+		// legacyCardOrder sorted the views by creation
+		view = &views[0]
+	}
+
+	opts := render.Options{Usernames: map[string]string{}}
+	users, err := a.store.GetUsersByWorkspace(c.WorkspaceID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	for _, user := range users {
+		opts.Usernames[user.ID] = user.Username
+	}
+	workspace, err := a.GetWorkspace(ctx, c.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace != nil {
+		opts.Locale = workspace.Settings.Locale
+	}
+
+	return &BoardMarkdownExport{
+		document: render.NewDocument(*board, view, cards, content, opts),
+	}, nil
+}
+
+// Filename returns the name of the exported file.
+func (e *BoardMarkdownExport) Filename() string {
+	title := e.document.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	return strings.NewReplacer(`"`, "", "/", "_", `\`, "_").Replace(title) + ".md"
+}
+
+// Write writes the document.
+func (e *BoardMarkdownExport) Write(w io.Writer) error {
+	return render.WriteMarkdown(w, e.document)
+}
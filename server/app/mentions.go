@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// mentionRegexp matches the @username mentions, following the
+// Mattermost username rules.
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\w@])@([a-zA-Z0-9._-]+)`)
+
+// parseMentions returns the lowercase usernames mentioned in the text,
+// without duplicates.
+func parseMentions(text string) []string {
+	usernames := []string{}
+	found := map[string]bool{}
+	for _, match := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+		username := strings.ToLower(strings.TrimRight(match[1], "."))
+		if username == "" || found[username] {
+			continue
+		}
+		found[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// hasMentions returns true if the block is a text or a comment that
+// mentions users.
+func hasMentions(block *model.Block) bool {
+	if block.Type != "text" && block.Type != "comment" {
+		return false
+	}
+	return len(parseMentions(block.Title)) > 0
+}
+
+// notifyMentions records a notification for each user mentioned in the
+// block for the first time, and delivers it through the notifier if
+// there is one. The author is never notified.
+func (a *App) notifyMentions(ctx context.Context, c store.Container, block model.Block, authorID string) {
+	if !hasMentions(&block) {
+		return
+	}
+
+	notified, err := a.store.GetNotifiedUserIDs(block.ID)
+	if err != nil {
+		a.logger.Error("Unable to get the notified users", mlog.String("blockID", block.ID), mlog.Err(err))
+		return
+	}
+	alreadyNotified := map[string]bool{authorID: true}
+	for _, userID := range notified {
+		alreadyNotified[userID] = true
+	}
+
+	var permalink, authorUsername string
+	for _, username := range parseMentions(block.Title) {
+		user, err := a.store.GetUserByUsername(username)
+		if err != nil || user == nil {
+			// not a user, e.g. an email address or a typo
+			continue
+		}
+		if alreadyNotified[user.ID] {
+			continue
+		}
+		alreadyNotified[user.ID] = true
+
+		notification := model.Notification{
+			ID:          utils.CreateGUID(),
+			Type:        model.NotificationTypeMention,
+			WorkspaceID: c.WorkspaceID,
+			UserID:      user.ID,
+			AuthorID:    authorID,
+			BoardID:     block.RootID,
+			CardID:      block.ParentID,
+			BlockID:     block.ID,
+			CreateAt:    utils.GetMillis(),
+		}
+		if err := a.store.InsertNotification(notification); err != nil {
+			a.logger.Error("Unable to store the notification", mlog.String("blockID", block.ID), mlog.Err(err))
+			continue
+		}
+
+		if a.notifier == nil {
+			continue
+		}
+
+		if permalink == "" {
+			permalink = a.cardPermalink(ctx, c, block.RootID, block.ParentID)
+			if author, err := a.store.GetUserByID(authorID); err == nil && author != nil {
+				authorUsername = author.Username
+			}
+		}
+
+		err = a.notifier.NotifyMention(notify.Mention{
+			Notification:   notification,
+			AuthorUsername: authorUsername,
+			Message:        block.Title,
+			Permalink:      permalink,
+		})
+		if err != nil {
+			a.logger.Error("Unable to notify the mention", mlog.String("userID", user.ID), mlog.Err(err))
+		}
+	}
+}
+
+// cardPermalink returns the link to the card in the first view of the
+// board, or to the board if it has no views.
+func (a *App) cardPermalink(ctx context.Context, c store.Container, boardID, cardID string) string {
+	return a.cardPermalinker(ctx, c, boardID)(cardID)
+}
+
+// cardPermalinker returns a function returning the links to the cards of
+// the board, reading its views once.
+func (a *App) cardPermalinker(ctx context.Context, c store.Container, boardID string) func(cardID string) string {
+	link := a.config.ServerRoot
+	if c.WorkspaceID != "0" {
+		link += "/workspace/" + c.WorkspaceID
+	}
+
+	views, err := a.store.GetBlocksWithParentAndType(ctx, c, boardID, "view")
+	if err != nil || len(views) == 0 {
+		return func(string) string {
+			return fmt.Sprintf("%s/%s", link, boardID)
+		}
+	}
+	return func(cardID string) string {
+		return fmt.Sprintf("%s/%s/%s/%s", link, boardID, views[0].ID, cardID)
+	}
+}
+
+// CardPermalink returns the link to the card in the first view of its
+// board.
+func (a *App) CardPermalink(ctx context.Context, c store.Container, boardID, cardID string) string {
+	return a.cardPermalinker(ctx, c, boardID)(cardID)
+}
+
+func (a *App) GetNotifications(userID string, limit int) ([]model.Notification, error) {
+	return a.store.GetNotificationsForUser(userID, limit)
+}
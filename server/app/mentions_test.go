@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/notify"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+type testNotifier struct {
+	mentions  []notify.Mention
+	reminders []notify.DueDateReminder
+	changes   []notify.BlockChange
+	deletions []notify.WorkspaceDeletion
+}
+
+func (n *testNotifier) NotifyMention(mention notify.Mention) error {
+	n.mentions = append(n.mentions, mention)
+	return nil
+}
+
+func (n *testNotifier) NotifyDueDate(reminder notify.DueDateReminder) error {
+	n.reminders = append(n.reminders, reminder)
+	return nil
+}
+
+func (n *testNotifier) NotifyBlockChange(change notify.BlockChange) error {
+	n.changes = append(n.changes, change)
+	return nil
+}
+
+func (n *testNotifier) NotifyWorkspaceDeletion(deletion notify.WorkspaceDeletion) error {
+	n.deletions = append(n.deletions, deletion)
+	return nil
+}
+
+func TestParseMentions(t *testing.T) {
+	testCases := []struct {
+		text      string
+		usernames []string
+	}{
+		{"no mentions", []string{}},
+		{"@alice", []string{"alice"}},
+		{"hey @Alice and @bob.smith, @alice again.", []string{"alice", "bob.smith"}},
+		{"thanks @bob.", []string{"bob"}},
+		{"(@carol_1)", []string{"carol_1"}},
+		{"mail alice@example.com", []string{}},
+		{"@@dave", []string{}},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.usernames, parseMentions(tc.text), tc.text)
+	}
+}
+
+func TestNotifyMentions(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.ServerRoot = "http://localhost:8000"
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	comment := model.Block{
+		ID:       "comment-1",
+		ParentID: "card-1",
+		RootID:   "board-1",
+		Type:     "comment",
+		Title:    "@alice @bob @author @unknown please check",
+	}
+
+	t.Run("should not notify blocks that aren't text or comments", func(t *testing.T) {
+		card := comment
+		card.Type = "card"
+
+		th.App.notifyMentions(ctx, container, card, "author-id")
+	})
+
+	t.Run("should record the notifications of the mentioned users", func(t *testing.T) {
+		th.App.notifier = nil
+
+		th.Store.EXPECT().GetNotifiedUserIDs(gomock.Eq("comment-1")).Return([]string{"bob-id"}, nil)
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("alice")).Return(&model.User{ID: "alice-id", Username: "alice"}, nil)
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("bob")).Return(&model.User{ID: "bob-id", Username: "bob"}, nil)
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("author")).Return(&model.User{ID: "author-id", Username: "author"}, nil)
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("unknown")).Return(nil, sql.ErrNoRows)
+
+		var inserted []model.Notification
+		th.Store.EXPECT().InsertNotification(gomock.Any()).DoAndReturn(func(notification model.Notification) error {
+			inserted = append(inserted, notification)
+			return nil
+		})
+
+		th.App.notifyMentions(ctx, container, comment, "author-id")
+
+		require.Len(t, inserted, 1)
+		require.Equal(t, "alice-id", inserted[0].UserID)
+		require.Equal(t, "author-id", inserted[0].AuthorID)
+		require.Equal(t, "0", inserted[0].WorkspaceID)
+		require.Equal(t, "board-1", inserted[0].BoardID)
+		require.Equal(t, "card-1", inserted[0].CardID)
+		require.Equal(t, "comment-1", inserted[0].BlockID)
+	})
+
+	t.Run("should deliver the mentions with a permalink to the card", func(t *testing.T) {
+		notifier := &testNotifier{}
+		th.App.notifier = notifier
+		defer func() { th.App.notifier = nil }()
+
+		th.Store.EXPECT().GetNotifiedUserIDs(gomock.Eq("comment-1")).Return([]string{}, nil)
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("alice")).Return(&model.User{ID: "alice-id", Username: "alice"}, nil)
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("bob")).Return(&model.User{ID: "bob-id", Username: "bob"}, nil)
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("author")).Return(&model.User{ID: "author-id", Username: "author"}, nil)
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("unknown")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().InsertNotification(gomock.Any()).Return(nil).Times(2)
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("view")).
+			Return([]model.Block{{ID: "view-1"}}, nil)
+		th.Store.EXPECT().GetUserByID(gomock.Eq("author-id")).Return(&model.User{ID: "author-id", Username: "author"}, nil)
+
+		th.App.notifyMentions(ctx, container, comment, "author-id")
+
+		require.Len(t, notifier.mentions, 2)
+		require.Equal(t, "alice-id", notifier.mentions[0].UserID)
+		require.Equal(t, "bob-id", notifier.mentions[1].UserID)
+		for _, mention := range notifier.mentions {
+			require.Equal(t, "author", mention.AuthorUsername)
+			require.Equal(t, comment.Title, mention.Message)
+			require.Equal(t, "http://localhost:8000/board-1/view-1/card-1", mention.Permalink)
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package app
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/pkg/errors"
+)
+
+const (
+	mfaIssuer = "Focalboard"
+
+	mfaRecoveryCodeCount  = 10
+	mfaRecoveryCodeLength = 5
+)
+
+var (
+	// ErrMfaNotConfigured is returned when no MFA encryption key is
+	// configured.
+	ErrMfaNotConfigured = errors.New("no mfa encryption key configured")
+
+	// ErrMfaAlreadyActive is returned when activating MFA for a user that
+	// already has it.
+	ErrMfaAlreadyActive = errors.New("mfa is already active")
+
+	// ErrMfaNotActive is returned when confirming MFA that wasn't
+	// activated, or deactivating MFA that isn't active.
+	ErrMfaNotActive = errors.New("mfa is not active")
+
+	// ErrMfaRequired is returned by the logins of the users with MFA
+	// without a code.
+	ErrMfaRequired = errors.New("mfa token required")
+
+	// ErrInvalidMfaCode is returned when a TOTP or recovery code is wrong.
+	ErrInvalidMfaCode = errors.New("invalid mfa code")
+)
+
+// ActivateMfa generates a new TOTP secret for the user, and returns it with
+// its otpauth URL. The logins only require a code once the activation is
+// confirmed with ConfirmMfa.
+func (a *App) ActivateMfa(userID string) (secret string, url string, err error) {
+	if a.config.MfaEncryptionKey == "" {
+		return "", "", ErrMfaNotConfigured
+	}
+
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to get the user")
+	}
+	if user.MfaActive {
+		return "", "", ErrMfaAlreadyActive
+	}
+
+	secret, err = auth.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to generate the mfa secret")
+	}
+
+	encrypted, err := auth.EncryptMfaSecret(a.config.MfaEncryptionKey, secret)
+	if err != nil {
+		return "", "", errors.Wrap(err, "unable to encrypt the mfa secret")
+	}
+
+	if err = a.store.UpdateUserMfa(userID, encrypted, false); err != nil {
+		return "", "", errors.Wrap(err, "unable to store the mfa secret")
+	}
+
+	accountName := user.Email
+	if accountName == "" {
+		accountName = user.Username
+	}
+	return secret, auth.TOTPURL(mfaIssuer, accountName, secret), nil
+}
+
+// ConfirmMfa enables MFA for the user after checking a code of the secret
+// returned by ActivateMfa, and returns the one-time recovery codes.
+func (a *App) ConfirmMfa(userID, code string) ([]string, error) {
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the user")
+	}
+	if user.MfaActive {
+		return nil, ErrMfaAlreadyActive
+	}
+	if user.MfaSecret == "" {
+		return nil, ErrMfaNotActive
+	}
+
+	valid, err := a.validateTOTPCode(user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrInvalidMfaCode
+	}
+
+	recoveryCodes := make([]string, mfaRecoveryCodeCount)
+	codeHashes := make([]string, mfaRecoveryCodeCount)
+	for i := range recoveryCodes {
+		bytes := make([]byte, mfaRecoveryCodeLength)
+		if _, err = rand.Read(bytes); err != nil {
+			return nil, errors.Wrap(err, "unable to generate the recovery codes")
+		}
+		recoveryCodes[i] = hex.EncodeToString(bytes)
+		codeHashes[i] = model.HashToken(recoveryCodes[i])
+	}
+
+	if err = a.store.SetMfaRecoveryCodes(userID, codeHashes); err != nil {
+		return nil, errors.Wrap(err, "unable to store the recovery codes")
+	}
+	if err = a.store.UpdateUserMfa(userID, user.MfaSecret, true); err != nil {
+		return nil, errors.Wrap(err, "unable to enable mfa")
+	}
+
+	a.recordAuditEntry(model.AuditActionActivateMfa, userID, "", userID, nil)
+	return recoveryCodes, nil
+}
+
+// DeactivateMfa disables MFA for the user, after checking either a TOTP
+// code or a recovery code.
+func (a *App) DeactivateMfa(userID, code string) error {
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the user")
+	}
+	if !user.MfaActive {
+		return ErrMfaNotActive
+	}
+
+	valid, err := a.verifyMfaCode(user, code)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return ErrInvalidMfaCode
+	}
+
+	if err = a.store.UpdateUserMfa(userID, "", false); err != nil {
+		return errors.Wrap(err, "unable to disable mfa")
+	}
+	if err = a.store.DeleteMfaRecoveryCodes(userID); err != nil {
+		return errors.Wrap(err, "unable to delete the recovery codes")
+	}
+
+	a.recordAuditEntry(model.AuditActionDeactivateMfa, userID, "", userID, nil)
+	return nil
+}
+
+// verifyMfaCode returns true if the code is a valid TOTP code of the user,
+// or one of their recovery codes, which is then used up.
+func (a *App) verifyMfaCode(user *model.User, code string) (bool, error) {
+	valid, err := a.validateTOTPCode(user, code)
+	if err != nil || valid {
+		return valid, err
+	}
+
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	if len(code) != 2*mfaRecoveryCodeLength {
+		return false, nil
+	}
+
+	err = a.store.ConsumeMfaRecoveryCode(user.ID, model.HashToken(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "unable to use the recovery code")
+	}
+	return true, nil
+}
+
+func (a *App) validateTOTPCode(user *model.User, code string) (bool, error) {
+	if a.config.MfaEncryptionKey == "" {
+		return false, ErrMfaNotConfigured
+	}
+
+	secret, err := auth.DecryptMfaSecret(a.config.MfaEncryptionKey, user.MfaSecret)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to decrypt the mfa secret")
+	}
+	return auth.ValidateTOTPCode(secret, code, time.Now())
+}
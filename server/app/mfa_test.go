@@ -0,0 +1,126 @@
+package app
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/stretchr/testify/require"
+)
+
+const testMfaEncryptionKey = "test-encryption-key"
+
+func newMfaUser(t *testing.T, active bool) (*model.User, string) {
+	secret, err := auth.GenerateTOTPSecret()
+	require.NoError(t, err)
+	encrypted, err := auth.EncryptMfaSecret(testMfaEncryptionKey, secret)
+	require.NoError(t, err)
+
+	user := *mockUser
+	user.MfaSecret = encrypted
+	user.MfaActive = active
+	return &user, secret
+}
+
+func TestActivateMfa(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("no encryption key", func(t *testing.T) {
+		_, _, err := th.App.ActivateMfa(mockUser.ID)
+		require.ErrorIs(t, err, ErrMfaNotConfigured)
+	})
+
+	th.App.config.MfaEncryptionKey = testMfaEncryptionKey
+
+	t.Run("already active", func(t *testing.T) {
+		user, _ := newMfaUser(t, true)
+		th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+
+		_, _, err := th.App.ActivateMfa(user.ID)
+		require.ErrorIs(t, err, ErrMfaAlreadyActive)
+	})
+
+	t.Run("stores the encrypted secret", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(mockUser.ID).Return(mockUser, nil)
+		var stored string
+		th.Store.EXPECT().UpdateUserMfa(mockUser.ID, gomock.Any(), false).DoAndReturn(func(userID, mfaSecret string, mfaActive bool) error {
+			stored = mfaSecret
+			return nil
+		})
+
+		secret, url, err := th.App.ActivateMfa(mockUser.ID)
+		require.NoError(t, err)
+		require.Contains(t, url, "secret="+secret)
+		require.NotEqual(t, secret, stored)
+
+		decrypted, err := auth.DecryptMfaSecret(testMfaEncryptionKey, stored)
+		require.NoError(t, err)
+		require.Equal(t, secret, decrypted)
+	})
+}
+
+func TestConfirmMfa(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.App.config.MfaEncryptionKey = testMfaEncryptionKey
+
+	user, secret := newMfaUser(t, false)
+
+	t.Run("wrong code", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+
+		_, err := th.App.ConfirmMfa(user.ID, "abcdef")
+		require.ErrorIs(t, err, ErrInvalidMfaCode)
+	})
+
+	t.Run("valid code", func(t *testing.T) {
+		code, err := auth.TOTPCode(secret, time.Now())
+		require.NoError(t, err)
+
+		th.Store.EXPECT().GetUserByID(user.ID).Return(user, nil)
+		th.Store.EXPECT().SetMfaRecoveryCodes(user.ID, gomock.Len(10)).Return(nil)
+		th.Store.EXPECT().UpdateUserMfa(user.ID, user.MfaSecret, true).Return(nil)
+		expectAuditEntry(th, model.AuditActionActivateMfa, user.ID, user.ID)
+
+		recoveryCodes, err := th.App.ConfirmMfa(user.ID, code)
+		require.NoError(t, err)
+		require.Len(t, recoveryCodes, 10)
+	})
+}
+
+func TestLoginWithMfa(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.App.config.MfaEncryptionKey = testMfaEncryptionKey
+
+	user, secret := newMfaUser(t, true)
+	th.Store.EXPECT().GetUserByUsername(user.Username).Return(user, nil).AnyTimes()
+
+	t.Run("no mfa token", func(t *testing.T) {
+		_, err := th.App.Login(user.Username, "", "testPassword", "", "")
+		require.ErrorIs(t, err, ErrMfaRequired)
+	})
+
+	t.Run("wrong recovery code", func(t *testing.T) {
+		th.Store.EXPECT().ConsumeMfaRecoveryCode(user.ID, model.HashToken("0123456789")).Return(sql.ErrNoRows)
+		expectAuditEntry(th, model.AuditActionLoginFailed, user.ID, user.ID)
+
+		_, err := th.App.Login(user.Username, "", "testPassword", "01234-56789", "")
+		require.ErrorIs(t, err, ErrInvalidMfaCode)
+	})
+
+	t.Run("valid totp code", func(t *testing.T) {
+		code, err := auth.TOTPCode(secret, time.Now())
+		require.NoError(t, err)
+		th.Store.EXPECT().CreateSession(gomock.Any()).Return(nil)
+		expectAuditEntry(th, model.AuditActionLogin, user.ID, user.ID)
+
+		token, err := th.App.Login(user.Username, "", "testPassword", code, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+	})
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"context"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// GetMigrationStatus returns the migrations applied to the database and
+// the pending ones.
+func (a *App) GetMigrationStatus(ctx context.Context) (*model.MigrationStatus, error) {
+	version, err := a.store.GetSchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := a.store.GetAppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := a.store.GetPendingMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.MigrationStatus{
+		SchemaVersion: version,
+		Applied:       applied,
+		Pending:       pending,
+	}, nil
+}
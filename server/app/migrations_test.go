@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMigrationStatus(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("should return the applied and pending migrations", func(t *testing.T) {
+		applied := []model.Migration{{Version: 1, Name: "init", AppliedAt: 1000, DurationMs: 5}}
+		pending := []model.Migration{{Version: 2, Name: "next", SQL: "SELECT 1;"}}
+		th.Store.EXPECT().GetSchemaVersion(gomock.Any()).Return(int64(1), nil)
+		th.Store.EXPECT().GetAppliedMigrations(gomock.Any()).Return(applied, nil)
+		th.Store.EXPECT().GetPendingMigrations(gomock.Any()).Return(pending, nil)
+
+		status, err := th.App.GetMigrationStatus(ctx)
+		require.NoError(t, err)
+		require.Equal(t, &model.MigrationStatus{SchemaVersion: 1, Applied: applied, Pending: pending}, status)
+	})
+
+	t.Run("should fail if the schema version can't be read", func(t *testing.T) {
+		th.Store.EXPECT().GetSchemaVersion(gomock.Any()).Return(int64(0), errors.New("error"))
+
+		status, err := th.App.GetMigrationStatus(ctx)
+		require.Error(t, err)
+		require.Nil(t, status)
+	})
+}
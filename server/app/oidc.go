@@ -0,0 +1,278 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/oidc"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	loginMethodPassword = "password"
+	loginMethodOIDC     = "oidc"
+
+	// OIDCCallbackPath is the path the identity provider redirects the
+	// users back to after their login
+	OIDCCallbackPath = "/api/v1/oauth/callback"
+
+	// oidcUsernameMaxLength bounds the usernames of the provisioned users,
+	// with room for the suffix of a taken username
+	oidcUsernameMaxLength = 56
+
+	// oidcUsernameAttempts is the number of numbered usernames tried when
+	// the username of a provisioned user is taken
+	oidcUsernameAttempts = 10
+)
+
+var (
+	// ErrOIDCNotConfigured is returned by the single sign-on when no
+	// identity provider is configured.
+	ErrOIDCNotConfigured = errors.New("single sign-on is not configured")
+
+	// ErrPasswordLoginDisabled is returned by the password logins and
+	// registrations when the single sign-on is enforced.
+	ErrPasswordLoginDisabled = errors.New("password login is disabled, log in with single sign-on")
+
+	// ErrOIDCEmailNotVerified is returned when the email of an identity
+	// is the one of an existing user, but isn't verified by the identity
+	// provider, so that the user can't be linked to it.
+	ErrOIDCEmailNotVerified = errors.New("the email of the account is not verified by the identity provider")
+
+	// ErrOIDCEmailLinked is returned when the user with the email of an
+	// identity is already linked to another identity.
+	ErrOIDCEmailLinked = errors.New("the account of the email is linked to another identity")
+
+	// ErrOIDCUserDeactivated is returned when the user of an identity is
+	// deactivated.
+	ErrOIDCUserDeactivated = errors.New("the user is deactivated")
+
+	usernameInvalidChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+)
+
+// OIDCLogin is a login started at the identity provider. The client
+// keeps the state, the nonce and the code verifier until the provider
+// redirects it back, with the state, to the callback.
+type OIDCLogin struct {
+	URL          string
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// newOIDCClient returns the client of the identity provider of the
+// configuration, or nil if there is none.
+func newOIDCClient(cfg *config.Configuration) *oidc.Client {
+	if cfg.OIDCIssuerURL == "" || cfg.OIDCClientID == "" {
+		return nil
+	}
+	return oidc.NewClient(oidc.Config{
+		IssuerURL:    cfg.OIDCIssuerURL,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  strings.TrimSuffix(cfg.ServerRoot, "/") + OIDCCallbackPath,
+		Scopes:       cfg.OIDCScopes,
+	})
+}
+
+// IsOIDCEnabled tells if the users can log in with the single sign-on.
+func (a *App) IsOIDCEnabled() bool {
+	return a.oidc != nil
+}
+
+// IsPasswordLoginEnabled tells if the users can log in with their
+// password, which they can unless the single sign-on is enforced.
+func (a *App) IsPasswordLoginEnabled() bool {
+	return !a.config.DisablePasswordLogin || a.oidc == nil
+}
+
+// StartOIDCLogin starts a login at the identity provider, returning the
+// URL the user is redirected to.
+func (a *App) StartOIDCLogin(ctx context.Context) (*OIDCLogin, error) {
+	if a.oidc == nil {
+		return nil, ErrOIDCNotConfigured
+	}
+
+	login := &OIDCLogin{}
+	for _, value := range []*string{&login.State, &login.Nonce, &login.CodeVerifier} {
+		random, err := oidc.NewRandomValue()
+		if err != nil {
+			return nil, err
+		}
+		*value = random
+	}
+
+	authURL, err := a.oidc.AuthCodeURL(ctx, login.State, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the identity provider")
+	}
+	login.URL = authURL
+	return login, nil
+}
+
+// LoginWithOIDC completes a login at the identity provider with the code
+// it returned, creating a session of the user of the identity. The user
+// is provisioned on the first login, or linked when a user has the email
+// of the identity, only if the provider verified it.
+func (a *App) LoginWithOIDC(ctx context.Context, code, codeVerifier, nonce, address string) (string, error) {
+	if a.oidc == nil {
+		return "", ErrOIDCNotConfigured
+	}
+	a.metrics.IncrementLoginAttemptCount(1)
+
+	claims, err := a.oidc.Exchange(ctx, code, codeVerifier, nonce)
+	if err != nil {
+		a.loginFailed("", "", "", address)
+		return "", errors.Wrap(err, "unable to verify the login at the identity provider")
+	}
+
+	user, err := a.getOIDCUser(claims)
+	if err != nil {
+		a.loginFailed("", "", claims.Email, address)
+		return "", err
+	}
+
+	return a.createSession(user, address, loginMethodOIDC)
+}
+
+// getOIDCUser returns the user of the identity, linking the user with its
+// verified email, or provisioning a new user.
+func (a *App) getOIDCUser(claims *oidc.Claims) (*model.User, error) {
+	user, err := a.store.GetUserByAuthData(claims.Subject)
+	if err == nil {
+		if user.DeleteAt != 0 {
+			return nil, ErrOIDCUserDeactivated
+		}
+		return user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "unable to get the user of the identity")
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if email != "" {
+		user, err = a.store.GetUserByEmail(email)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Wrap(err, "unable to get the user of the email")
+		}
+		if err == nil && user != nil {
+			return a.linkOIDCUser(user, claims)
+		}
+	}
+
+	return a.provisionOIDCUser(claims)
+}
+
+// linkOIDCUser links the user to the identity with its email, if the
+// provider verified it.
+func (a *App) linkOIDCUser(user *model.User, claims *oidc.Claims) (*model.User, error) {
+	if !claims.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+	if user.AuthData != "" {
+		return nil, ErrOIDCEmailLinked
+	}
+
+	if err := a.store.UpdateUserAuthData(user.ID, claims.Subject); err != nil {
+		return nil, errors.Wrap(err, "unable to link the user to the identity")
+	}
+	user.AuthData = claims.Subject
+
+	a.recordAuditEntry(model.AuditActionLinkSingleSignOn, user.ID, "", user.ID, map[string]interface{}{
+		"email": user.Email,
+	})
+	return user, nil
+}
+
+// provisionOIDCUser creates the user of the identity, without a password.
+// The email is only kept if the provider verified it, and the username is
+// numbered if it's taken.
+func (a *App) provisionOIDCUser(claims *oidc.Claims) (*model.User, error) {
+	email := ""
+	if claims.EmailVerified {
+		email = strings.TrimSpace(claims.Email)
+	}
+
+	username, err := a.newOIDCUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// the first user administers the server
+	userCount, err := a.store.GetRegisteredUserCount()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to count the users")
+	}
+
+	user := &model.User{
+		ID:          uuid.New().String(),
+		Username:    username,
+		Email:       email,
+		AuthService: a.config.AuthMode,
+		AuthData:    claims.Subject,
+		Props:       map[string]interface{}{},
+		IsAdmin:     userCount == 0,
+	}
+	if err = a.store.CreateUser(user); err != nil {
+		return nil, errors.Wrap(err, "unable to create the user of the identity")
+	}
+
+	a.recordAuditEntry(model.AuditActionProvisionUser, user.ID, "", user.ID, map[string]interface{}{
+		"username": user.Username,
+		"email":    user.Email,
+	})
+	return user, nil
+}
+
+// newOIDCUsername returns a free username for the identity, from its
+// preferred username or its email.
+func (a *App) newOIDCUsername(claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+	base = strings.Trim(usernameInvalidChars.ReplaceAllString(strings.ToLower(base), "-"), "-.")
+	if len(base) > oidcUsernameMaxLength {
+		base = base[:oidcUsernameMaxLength]
+	}
+	if base == "" {
+		base = "user"
+	}
+
+	candidates := []string{base}
+	for i := 1; i < oidcUsernameAttempts; i++ {
+		candidates = append(candidates, fmt.Sprintf("%s%d", base, i))
+	}
+	candidates = append(candidates, base+"-"+utils.CreateGUID()[:8])
+
+	for _, username := range candidates {
+		_, err := a.store.GetUserByUsername(username)
+		if errors.Is(err, sql.ErrNoRows) {
+			return username, nil
+		}
+		if err != nil {
+			return "", errors.Wrap(err, "unable to check the username")
+		}
+	}
+	return "", errors.New("unable to find a free username")
+}
+
+// GetOIDCLoginRedirectURL returns the URL of the client the users are
+// sent to after their login at the identity provider, with the error of
+// the login if it failed.
+func (a *App) GetOIDCLoginRedirectURL(loginError string) string {
+	root := strings.TrimRight(a.config.ServerRoot, "/")
+	if loginError == "" {
+		return root + "/"
+	}
+	return root + "/login?sso_error=" + url.QueryEscape(loginError)
+}
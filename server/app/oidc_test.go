@@ -0,0 +1,111 @@
+package app
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/oidc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOIDCUser(t *testing.T) {
+	t.Run("should return the linked user", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		user := &model.User{ID: "user-1", AuthData: "subject-1"}
+		th.Store.EXPECT().GetUserByAuthData("subject-1").Return(user, nil)
+
+		got, err := th.App.getOIDCUser(&oidc.Claims{Subject: "subject-1", Email: "alice@example.com"})
+		require.NoError(t, err)
+		require.Equal(t, user, got)
+	})
+
+	t.Run("should reject a deactivated user", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		th.Store.EXPECT().GetUserByAuthData("subject-1").Return(&model.User{ID: "user-1", DeleteAt: 1}, nil)
+
+		_, err := th.App.getOIDCUser(&oidc.Claims{Subject: "subject-1"})
+		require.ErrorIs(t, err, ErrOIDCUserDeactivated)
+	})
+
+	t.Run("should link the user of a verified email", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		th.Store.EXPECT().GetUserByAuthData("subject-1").Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetUserByEmail("alice@example.com").Return(&model.User{ID: "user-1"}, nil)
+		th.Store.EXPECT().UpdateUserAuthData("user-1", "subject-1").Return(nil)
+		expectAuditEntry(th, model.AuditActionLinkSingleSignOn, "user-1", "user-1")
+
+		got, err := th.App.getOIDCUser(&oidc.Claims{Subject: "subject-1", Email: "alice@example.com", EmailVerified: true})
+		require.NoError(t, err)
+		require.Equal(t, "subject-1", got.AuthData)
+	})
+
+	t.Run("should not link the user of an unverified email", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		th.Store.EXPECT().GetUserByAuthData("subject-1").Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetUserByEmail("alice@example.com").Return(&model.User{ID: "user-1"}, nil)
+
+		_, err := th.App.getOIDCUser(&oidc.Claims{Subject: "subject-1", Email: "alice@example.com"})
+		require.ErrorIs(t, err, ErrOIDCEmailNotVerified)
+	})
+
+	t.Run("should not link the user of another identity", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		th.Store.EXPECT().GetUserByAuthData("subject-1").Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetUserByEmail("alice@example.com").Return(&model.User{ID: "user-1", AuthData: "subject-2"}, nil)
+
+		_, err := th.App.getOIDCUser(&oidc.Claims{Subject: "subject-1", Email: "alice@example.com", EmailVerified: true})
+		require.ErrorIs(t, err, ErrOIDCEmailLinked)
+	})
+
+	t.Run("should provision a user with a free username", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		th.Store.EXPECT().GetUserByAuthData("subject-1").Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetUserByEmail("alice@example.com").Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetUserByUsername("alice").Return(&model.User{ID: "user-2"}, nil)
+		th.Store.EXPECT().GetUserByUsername("alice1").Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().GetRegisteredUserCount().Return(2, nil)
+		th.Store.EXPECT().CreateUser(gomock.Any()).Return(nil)
+		th.Store.EXPECT().InsertAuditEntry(gomock.Any()).Return(nil)
+
+		got, err := th.App.getOIDCUser(&oidc.Claims{Subject: "subject-1", Email: "alice@example.com"})
+		require.NoError(t, err)
+		require.Equal(t, "alice1", got.Username)
+		require.Empty(t, got.Email)
+		require.Empty(t, got.Password)
+		require.Equal(t, "subject-1", got.AuthData)
+		require.False(t, got.IsAdmin)
+	})
+}
+
+func TestPasswordLoginDisabled(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	require.True(t, th.App.IsPasswordLoginEnabled())
+
+	th.App.config.DisablePasswordLogin = true
+	require.True(t, th.App.IsPasswordLoginEnabled(), "the password logins can't be disabled without single sign-on")
+
+	th.App.oidc = oidc.NewClient(oidc.Config{IssuerURL: "https://sso.example.com", ClientID: "focalboard"})
+	require.False(t, th.App.IsPasswordLoginEnabled())
+
+	_, err := th.App.Login("alice", "", "password", "", "")
+	require.ErrorIs(t, err, ErrPasswordLoginDisabled)
+	require.ErrorIs(t, th.App.RegisterUser("alice", "alice@example.com", "password"), ErrPasswordLoginDisabled)
+	_, err = th.App.RegisterGuest("token", "alice", "alice@example.com", "password")
+	require.ErrorIs(t, err, ErrPasswordLoginDisabled)
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+const (
+	// passwordResetTokenExpiry is how long a password reset token can be
+	// used after it's emailed.
+	passwordResetTokenExpiry = time.Hour
+
+	passwordResetTokenLength = 32
+)
+
+var (
+	// ErrEmailNotConfigured is returned when a feature needs to send an
+	// email and no email server is configured.
+	ErrEmailNotConfigured = errors.New("no email server configured")
+
+	// ErrInvalidPasswordResetToken is returned when a password reset token
+	// doesn't exist, was already used or has expired.
+	ErrInvalidPasswordResetToken = errors.New("invalid or expired password reset token")
+)
+
+// RequestPasswordReset emails a single-use password reset link to the user
+// with the email. Nothing is sent and no error is returned if there's no
+// such user, so that the response doesn't tell which emails are
+// registered.
+func (a *App) RequestPasswordReset(email string) error {
+	if a.emailSender == nil {
+		return ErrEmailNotConfigured
+	}
+
+	user, err := a.store.GetUserByEmail(email)
+	if errors.Is(err, sql.ErrNoRows) {
+		a.logger.Debug("Password reset requested for an unknown email")
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to get the user")
+	}
+	if isLDAPUser(user) {
+		a.logger.Debug("Password reset requested for an LDAP user", mlog.String("userID", user.ID))
+		return nil
+	}
+
+	secret := make([]byte, passwordResetTokenLength)
+	if _, err = rand.Read(secret); err != nil {
+		return errors.Wrap(err, "unable to generate the password reset token")
+	}
+	token := hex.EncodeToString(secret)
+
+	now := utils.GetMillis()
+	resetToken := model.PasswordResetToken{
+		TokenHash: model.HashToken(token),
+		UserID:    user.ID,
+		CreateAt:  now,
+		ExpireAt:  now + passwordResetTokenExpiry.Milliseconds(),
+	}
+	if err = a.store.CreatePasswordResetToken(resetToken); err != nil {
+		return errors.Wrap(err, "unable to store the password reset token")
+	}
+
+	a.recordAuditEntry(model.AuditActionRequestPasswordReset, user.ID, "", user.ID, nil)
+
+	// the email is sent in the background, so that the response time
+	// doesn't tell which emails are registered either
+	go a.sendPasswordResetEmail(user, token)
+	return nil
+}
+
+func (a *App) sendPasswordResetEmail(user *model.User, token string) {
+	link := fmt.Sprintf("%s/reset_password?token=%s", strings.TrimRight(a.config.ServerRoot, "/"), url.QueryEscape(token))
+	body := fmt.Sprintf("A password reset was requested for your Focalboard account %s.\n\n"+
+		"To choose a new password, open this link within an hour:\n%s\n\n"+
+		"If you didn't request it, you can ignore this email.\n", user.Username, link)
+
+	if err := a.emailSender.Send(user.Email, "Reset your Focalboard password", body); err != nil {
+		a.logger.Error("Unable to send the password reset email", mlog.String("userID", user.ID), mlog.Err(err))
+	}
+}
+
+// CompletePasswordReset sets the password of the user of the reset token,
+// and logs the user out of all their sessions. The token can only be used
+// once, and not for an LDAP user.
+func (a *App) CompletePasswordReset(token, newPassword string) error {
+	resetToken, err := a.store.ConsumePasswordResetToken(model.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidPasswordResetToken
+	}
+	if err != nil {
+		return errors.Wrap(err, "unable to get the password reset token")
+	}
+	if resetToken.IsExpired(utils.GetMillis()) {
+		return ErrInvalidPasswordResetToken
+	}
+
+	user, err := a.store.GetUserByID(resetToken.UserID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the user")
+	}
+	if isLDAPUser(user) {
+		return ErrLDAPPasswordChange
+	}
+
+	if err = a.store.UpdateUserPasswordByID(resetToken.UserID, auth.HashPassword(newPassword)); err != nil {
+		return errors.Wrap(err, "unable to update password")
+	}
+
+	if err = a.store.DeletePasswordResetTokensForUser(resetToken.UserID); err != nil {
+		a.logger.Error("Unable to delete the password reset tokens", mlog.String("userID", resetToken.UserID), mlog.Err(err))
+	}
+	if err = a.store.DeleteSessionsForUser(resetToken.UserID); err != nil {
+		return errors.Wrap(err, "unable to delete the sessions")
+	}
+
+	a.recordAuditEntry(model.AuditActionResetPassword, resetToken.UserID, "", resetToken.UserID, nil)
+	return nil
+}
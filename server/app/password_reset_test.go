@@ -0,0 +1,119 @@
+package app
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+type sentEmail struct {
+	to      string
+	subject string
+	body    string
+	html    string
+}
+
+// testEmailSender sends the emails to a channel.
+type testEmailSender struct {
+	sent chan sentEmail
+}
+
+func (s *testEmailSender) Send(to, subject, body string) error {
+	s.sent <- sentEmail{to: to, subject: subject, body: body}
+	return nil
+}
+
+func (s *testEmailSender) SendHTML(to, subject, textBody, htmlBody string) error {
+	s.sent <- sentEmail{to: to, subject: subject, body: textBody, html: htmlBody}
+	return nil
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("no email server", func(t *testing.T) {
+		err := th.App.RequestPasswordReset(mockUser.Email)
+		require.ErrorIs(t, err, ErrEmailNotConfigured)
+	})
+
+	sender := &testEmailSender{sent: make(chan sentEmail, 1)}
+	th.App.emailSender = sender
+	th.App.config.ServerRoot = "http://localhost:8000"
+
+	t.Run("unknown email", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByEmail("unknown@example.com").Return(nil, sql.ErrNoRows)
+
+		err := th.App.RequestPasswordReset("unknown@example.com")
+		require.NoError(t, err)
+		require.Empty(t, sender.sent)
+	})
+
+	t.Run("registered email", func(t *testing.T) {
+		var stored model.PasswordResetToken
+		th.Store.EXPECT().GetUserByEmail(mockUser.Email).Return(mockUser, nil)
+		th.Store.EXPECT().CreatePasswordResetToken(gomock.Any()).DoAndReturn(func(token model.PasswordResetToken) error {
+			stored = token
+			return nil
+		})
+		expectAuditEntry(th, model.AuditActionRequestPasswordReset, mockUser.ID, mockUser.ID)
+
+		err := th.App.RequestPasswordReset(mockUser.Email)
+		require.NoError(t, err)
+		require.Equal(t, mockUser.ID, stored.UserID)
+		require.InDelta(t, time.Hour.Milliseconds(), stored.ExpireAt-stored.CreateAt, 0)
+
+		select {
+		case email := <-sender.sent:
+			require.Equal(t, mockUser.Email, email.to)
+			require.Contains(t, email.body, "http://localhost:8000/reset_password?token=")
+			require.NotContains(t, email.body, stored.TokenHash)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "no email sent")
+		}
+	})
+}
+
+func TestCompletePasswordReset(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	tokenHash := model.HashToken("token")
+
+	t.Run("unknown or used token", func(t *testing.T) {
+		th.Store.EXPECT().ConsumePasswordResetToken(tokenHash).Return(nil, sql.ErrNoRows)
+
+		err := th.App.CompletePasswordReset("token", "newPassword")
+		require.ErrorIs(t, err, ErrInvalidPasswordResetToken)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		resetToken := &model.PasswordResetToken{TokenHash: tokenHash, UserID: mockUser.ID, ExpireAt: utils.GetMillis() - 1000}
+		th.Store.EXPECT().ConsumePasswordResetToken(tokenHash).Return(resetToken, nil)
+
+		err := th.App.CompletePasswordReset("token", "newPassword")
+		require.ErrorIs(t, err, ErrInvalidPasswordResetToken)
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		resetToken := &model.PasswordResetToken{TokenHash: tokenHash, UserID: mockUser.ID, ExpireAt: utils.GetMillis() + 1000}
+		th.Store.EXPECT().ConsumePasswordResetToken(tokenHash).Return(resetToken, nil)
+		th.Store.EXPECT().GetUserByID(mockUser.ID).Return(mockUser, nil)
+		th.Store.EXPECT().UpdateUserPasswordByID(mockUser.ID, gomock.Any()).DoAndReturn(func(userID, password string) error {
+			require.True(t, auth.ComparePassword(password, "newPassword"))
+			return nil
+		})
+		th.Store.EXPECT().DeletePasswordResetTokensForUser(mockUser.ID).Return(nil)
+		th.Store.EXPECT().DeleteSessionsForUser(mockUser.ID).Return(nil)
+		expectAuditEntry(th, model.AuditActionResetPassword, mockUser.ID, mockUser.ID)
+
+		err := th.App.CompletePasswordReset("token", "newPassword")
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// deletedBlockHistoryLimit is the number of most recent versions of a
+// deleted block searched for the board it lived on, the rows recorded by
+// the older versions of DeleteBlock lacking it.
+const deletedBlockHistoryLimit = 10
+
+// ResolveBlock returns the current location of the block, or a tombstone
+// if the block, its card or its board is deleted. The card of a comment
+// or a content block gives its board, the content blocks not moving with
+// their card in every client. The location is only set if the user, or
+// the viewer with the read token without a user, can view the board of
+// the block. It returns sql.ErrNoRows if the workspace never had the
+// block.
+func (a *App) ResolveBlock(ctx context.Context, c store.Container, blockID, userID, readToken string) (*model.ResolvedBlock, error) {
+	block, err := a.store.GetBlock(ctx, c, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return a.resolveDeletedBlock(ctx, c, blockID, "", blockID, userID, readToken)
+	}
+
+	boardID := block.BoardID()
+	cardID := ""
+	switch {
+	case block.Type == "card":
+		cardID = block.ID
+	case block.Type != "view" && block.ParentID != "" && block.ParentID != boardID:
+		cardID = block.ParentID
+	}
+	if cardID != "" && cardID != block.ID {
+		card, err := a.store.GetBlock(ctx, c, cardID)
+		if err != nil {
+			return nil, err
+		}
+		if card == nil {
+			return a.resolveDeletedBlock(ctx, c, blockID, block.Type, cardID, userID, readToken)
+		}
+		boardID = card.BoardID()
+	}
+	if boardID != block.ID {
+		board, err := a.store.GetBlock(ctx, c, boardID)
+		if err != nil {
+			return nil, err
+		}
+		if board == nil {
+			return a.resolveDeletedBlock(ctx, c, blockID, block.Type, boardID, userID, readToken)
+		}
+	}
+
+	resolved := &model.ResolvedBlock{BlockID: blockID}
+	if resolved.CanAccess, err = a.canViewBoard(ctx, c, userID, readToken, boardID); err != nil || !resolved.CanAccess {
+		return resolved, err
+	}
+	resolved.Type = block.Type
+	resolved.BoardID = boardID
+	resolved.CardID = cardID
+	if block.Type == "view" {
+		resolved.ViewID = block.ID
+	} else if resolved.ViewID, err = a.defaultViewID(ctx, c, boardID); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// resolveDeletedBlock returns the tombstone of the block, deleted itself
+// or with its card or board, the deleted block. The board the block
+// lived on is read from the history of the deleted block, and the type
+// of the block too if it's empty.
+func (a *App) resolveDeletedBlock(ctx context.Context, c store.Container, blockID, blockType, deletedID, userID, readToken string) (*model.ResolvedBlock, error) {
+	history, err := a.store.GetBlockHistory(ctx, c, deletedID, model.QueryBlockHistoryOptions{
+		Descending: true,
+		Limit:      deletedBlockHistoryLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var last *model.Block
+	for i := range history {
+		if history[i].Type != "" {
+			last = &history[i]
+			break
+		}
+	}
+	if last == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	resolved := &model.ResolvedBlock{BlockID: blockID, Deleted: true, DeleteAt: history[0].DeleteAt}
+	if resolved.DeleteAt == 0 {
+		resolved.DeleteAt = history[0].UpdateAt
+	}
+
+	boardID := last.BoardID()
+	if resolved.CanAccess, err = a.canViewBoard(ctx, c, userID, readToken, boardID); err != nil || !resolved.CanAccess {
+		return resolved, err
+	}
+	if blockType == "" {
+		blockType = last.Type
+	}
+	resolved.Type = blockType
+	resolved.BoardID = boardID
+	return resolved, nil
+}
+
+// canViewBoard tells if the user can view the board, or the viewer with
+// the read token if the user can't or there's none. The read tokens of
+// the other boards, and of the deleted boards, don't give access.
+func (a *App) canViewBoard(ctx context.Context, c store.Container, userID, readToken, boardID string) (bool, error) {
+	if userID != "" {
+		roles, err := a.getUserBoardRoles(c, userID)
+		if err != nil {
+			return false, err
+		}
+		if roles.check(model.BoardRoleViewer, boardID) == nil {
+			return true, nil
+		}
+	}
+	if readToken == "" {
+		return false, nil
+	}
+
+	valid, err := a.auth.IsValidReadToken(ctx, c, boardID, readToken)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, auth.ErrReadTokenExpired) {
+		return false, nil
+	}
+	return valid, err
+}
+
+// defaultViewID returns the ID of the view of the board the clients open
+// by default, the first by title, or an empty ID if the board has none.
+func (a *App) defaultViewID(ctx context.Context, c store.Container, boardID string) (string, error) {
+	views, err := a.store.GetBlocksWithParentAndType(ctx, c, boardID, "view")
+	if err != nil || len(views) == 0 {
+		return "", err
+	}
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Title != views[j].Title {
+			return views[i].Title < views[j].Title
+		}
+		return views[i].ID < views[j].ID
+	})
+	return views[0].ID, nil
+}
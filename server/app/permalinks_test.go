@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveBlock(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	roles := map[string]string{"private-board": ""}
+	th.Store.EXPECT().GetUserByID(gomock.Eq("user-id")).Return(&model.User{ID: "user-id"}, nil).AnyTimes()
+	history := model.QueryBlockHistoryOptions{Descending: true, Limit: deletedBlockHistoryLimit}
+
+	t.Run("should resolve a card to its board and the first view by title", func(t *testing.T) {
+		card := &model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"}
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "card-1").Return(card, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "board-1").Return(&model.Block{ID: "board-1", Type: "board"}, nil)
+		th.Store.EXPECT().GetBoardRolesForUser(container, "user-id").Return(roles, nil)
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), container, "board-1", "view").Return([]model.Block{
+			{ID: "view-2", Title: "Table"},
+			{ID: "view-3", Title: "Calendar"},
+			{ID: "view-1", Title: "Table"},
+		}, nil)
+
+		resolved, err := th.App.ResolveBlock(ctx, container, "card-1", "user-id", "")
+		require.NoError(t, err)
+		require.Equal(t, &model.ResolvedBlock{
+			BlockID:   "card-1",
+			CanAccess: true,
+			Type:      "card",
+			BoardID:   "board-1",
+			ViewID:    "view-3",
+			CardID:    "card-1",
+		}, resolved)
+	})
+
+	t.Run("should not locate the blocks of the boards the user can't view", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "private-board").Return(&model.Block{ID: "private-board", Type: "board"}, nil)
+		th.Store.EXPECT().GetBoardRolesForUser(container, "user-id").Return(roles, nil)
+
+		resolved, err := th.App.ResolveBlock(ctx, container, "private-board", "user-id", "")
+		require.NoError(t, err)
+		require.Equal(t, &model.ResolvedBlock{BlockID: "private-board"}, resolved)
+	})
+
+	t.Run("should resolve a card of a deleted board to a tombstone", func(t *testing.T) {
+		card := &model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"}
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "card-1").Return(card, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "board-1").Return(nil, nil)
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), container, "board-1", history).Return([]model.Block{
+			{ID: "board-1", RootID: "board-1", Type: "board", UpdateAt: 20, DeleteAt: 20},
+		}, nil)
+		th.Store.EXPECT().GetBoardRolesForUser(container, "user-id").Return(roles, nil)
+
+		resolved, err := th.App.ResolveBlock(ctx, container, "card-1", "user-id", "")
+		require.NoError(t, err)
+		require.Equal(t, &model.ResolvedBlock{
+			BlockID:   "card-1",
+			CanAccess: true,
+			Deleted:   true,
+			DeleteAt:  20,
+			Type:      "card",
+			BoardID:   "board-1",
+		}, resolved)
+	})
+
+	t.Run("should find the board of a block deleted by an older version", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "card-1").Return(nil, nil)
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), container, "card-1", history).Return([]model.Block{
+			{ID: "card-1", UpdateAt: 30},
+			{ID: "card-1", RootID: "board-1", Type: "card", UpdateAt: 10},
+		}, nil)
+		th.Store.EXPECT().GetBoardRolesForUser(container, "user-id").Return(roles, nil)
+
+		resolved, err := th.App.ResolveBlock(ctx, container, "card-1", "user-id", "")
+		require.NoError(t, err)
+		require.Equal(t, &model.ResolvedBlock{
+			BlockID:   "card-1",
+			CanAccess: true,
+			Deleted:   true,
+			DeleteAt:  30,
+			Type:      "card",
+			BoardID:   "board-1",
+		}, resolved)
+	})
+
+	t.Run("should not find a block without history", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), container, "unknown").Return(nil, nil)
+		th.Store.EXPECT().GetBlockHistory(gomock.Any(), container, "unknown", history).Return([]model.Block{}, nil)
+
+		_, err := th.App.ResolveBlock(ctx, container, "unknown", "user-id", "")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
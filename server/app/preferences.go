@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// InvalidPreferencesError is returned when a batch of preferences is
+// empty or too large, or has a preference out of the client namespace or
+// with a value that is too long.
+type InvalidPreferencesError struct {
+	Reason string
+}
+
+func (e InvalidPreferencesError) Error() string {
+	return "invalid preferences: " + e.Reason
+}
+
+// GetUserPreferences returns the preferences of the user, kept by
+// Mattermost in plugin mode.
+func (a *App) GetUserPreferences(ctx context.Context, userID string) ([]model.Preference, error) {
+	return a.store.GetPreferences(ctx, userID)
+}
+
+// UpdateUserPreferences sets the preferences of the user in the client
+// namespace, and returns all their preferences.
+func (a *App) UpdateUserPreferences(ctx context.Context, userID string, preferences []model.Preference) ([]model.Preference, error) {
+	if err := model.ValidatePreferences(preferences); err != nil {
+		return nil, InvalidPreferencesError{Reason: err.Error()}
+	}
+
+	if err := a.store.UpsertPreferences(ctx, userID, preferences); err != nil {
+		return nil, err
+	}
+	return a.store.GetPreferences(ctx, userID)
+}
+
+// DeleteUserPreferences removes the preferences of the user in the
+// client namespace, and returns their remaining preferences.
+func (a *App) DeleteUserPreferences(ctx context.Context, userID string, preferences []model.Preference) ([]model.Preference, error) {
+	if err := model.ValidatePreferences(preferences); err != nil {
+		return nil, InvalidPreferencesError{Reason: err.Error()}
+	}
+
+	if err := a.store.DeletePreferences(ctx, userID, preferences); err != nil {
+		return nil, err
+	}
+	return a.store.GetPreferences(ctx, userID)
+}
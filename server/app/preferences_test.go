@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateUserPreferences(t *testing.T) {
+	ctx := context.Background()
+	theme := model.Preference{Category: "client_display", Name: "theme", Value: "dark"}
+
+	t.Run("should set the preferences and return them all", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		stored := []model.Preference{
+			{UserID: "user-1", Category: "client_display", Name: "theme", Value: "dark"},
+			{UserID: "user-1", Category: "client_tours", Name: "onboarding", Value: "dismissed"},
+		}
+		th.Store.EXPECT().UpsertPreferences(ctx, "user-1", []model.Preference{theme}).Return(nil)
+		th.Store.EXPECT().GetPreferences(ctx, "user-1").Return(stored, nil)
+
+		preferences, err := th.App.UpdateUserPreferences(ctx, "user-1", []model.Preference{theme})
+		require.NoError(t, err)
+		require.Equal(t, stored, preferences)
+	})
+
+	t.Run("should reject the invalid preferences", func(t *testing.T) {
+		th, tearDown := SetupTestHelper(t)
+		defer tearDown()
+
+		testCases := map[string][]model.Preference{
+			"no preferences":       {},
+			"too many preferences": make([]model.Preference, model.PreferencesMaxBatchSize+1),
+			"server category":      {{Category: "server_digest", Name: "frequency"}},
+			"bare namespace":       {{Category: "client_", Name: "theme"}},
+			"long category":        {{Category: "client_" + strings.Repeat("a", 15), Name: "theme"}},
+			"uppercase name":       {{Category: "client_display", Name: "Theme"}},
+			"empty name":           {{Category: "client_display"}},
+			"long value":           {{Category: "client_display", Name: "theme", Value: strings.Repeat("a", model.PreferenceValueMaxLength+1)}},
+			"repeated preference":  {theme, theme},
+		}
+		for name, preferences := range testCases {
+			t.Run(name, func(t *testing.T) {
+				_, err := th.App.UpdateUserPreferences(ctx, "user-1", preferences)
+				require.ErrorAs(t, err, &InvalidPreferencesError{})
+			})
+		}
+	})
+}
+
+func TestDeleteUserPreferences(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	tour := model.Preference{Category: "client_tours", Name: "onboarding"}
+	th.Store.EXPECT().DeletePreferences(ctx, "user-1", []model.Preference{tour}).Return(nil)
+	th.Store.EXPECT().GetPreferences(ctx, "user-1").Return([]model.Preference{}, nil)
+
+	preferences, err := th.App.DeleteUserPreferences(ctx, "user-1", []model.Preference{tour})
+	require.NoError(t, err)
+	require.Empty(t, preferences)
+
+	_, err = th.App.DeleteUserPreferences(ctx, "user-1", []model.Preference{{Category: "display", Name: "theme"}})
+	require.ErrorAs(t, err, &InvalidPreferencesError{})
+}
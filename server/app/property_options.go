@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// propertyOptionColorMigrationSetting is the system setting recording
+// that the legacy colors of the options of the boards were normalized.
+const propertyOptionColorMigrationSetting = "PropertyOptionColorMigrationComplete"
+
+// InvalidPropertyOptionError is returned when an option of a card
+// property of a board has a color that isn't in the palette.
+type InvalidPropertyOptionError struct {
+	BlockID    string
+	PropertyID string
+	OptionID   string
+	Color      interface{}
+}
+
+func (e InvalidPropertyOptionError) Error() string {
+	return fmt.Sprintf("option %s of property %s of board %s has the unknown color %v, the valid colors are %s",
+		e.OptionID, e.PropertyID, e.BlockID, e.Color, strings.Join(model.PropertyOptionColors, ", "))
+}
+
+// normalizePropertyOptionColors normalizes the legacy colors of the
+// options of the card properties of the board to the ones of the
+// palette, and tells if it changed any. The unknown colors fail with an
+// InvalidPropertyOptionError, or are reset to the default color unless
+// strict. The options without a color are left as they are.
+func normalizePropertyOptionColors(board *model.Block, strict bool) (bool, error) {
+	changed := false
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		template, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		options, _ := template["options"].([]interface{})
+		for _, item := range options {
+			option, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			value, ok := option["color"]
+			if !ok {
+				continue
+			}
+
+			color, _ := value.(string)
+			normalized, known := model.NormalizePropertyOptionColor(color)
+			if _, isString := value.(string); !known || !isString {
+				if strict {
+					propertyID, _ := template["id"].(string)
+					optionID, _ := option["id"].(string)
+					return changed, InvalidPropertyOptionError{BlockID: board.ID, PropertyID: propertyID, OptionID: optionID, Color: value}
+				}
+				normalized = model.PropertyOptionColorDefault
+			}
+			if normalized != value {
+				option["color"] = normalized
+				changed = true
+			}
+		}
+	}
+	return changed, nil
+}
+
+// validateBoard checks that the options of the card properties of the
+// board have colors of the palette, and normalizes their legacy colors.
+func (a *App) validateBoard(_ store.Container, block *model.Block, _ func(blockID string) (*model.Block, error)) error {
+	_, err := normalizePropertyOptionColors(block, true)
+	return err
+}
+
+// MigratePropertyOptionColors normalizes the legacy colors of the options
+// of all the boards, once, the unknown colors being reset to the default
+// color so that the boards can be patched.
+func (a *App) MigratePropertyOptionColors(ctx context.Context) error {
+	settings, err := a.store.GetSystemSettings()
+	if err != nil {
+		return err
+	}
+	if settings[propertyOptionColorMigrationSetting] == "true" {
+		return nil
+	}
+
+	workspaceIDs, err := a.store.GetBoardWorkspaceIDs()
+	if err != nil {
+		return err
+	}
+	boardCount := 0
+	for _, workspaceID := range workspaceIDs {
+		c := store.Container{
+			WorkspaceID: workspaceID,
+		}
+		boards, err := a.store.GetBlocksWithType(ctx, c, "board")
+		if err != nil {
+			return err
+		}
+
+		changed := []model.Block{}
+		for i := range boards {
+			normalized, _ := normalizePropertyOptionColors(&boards[i], false)
+			if normalized {
+				changed = append(changed, boards[i])
+			}
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := a.store.InsertBlocks(ctx, c, changed, systemUserID); err != nil {
+			return err
+		}
+		a.wsAdapter.BroadcastBlockChanges(workspaceID, changed)
+		boardCount += len(changed)
+	}
+
+	a.logger.Info("Migrated the property option colors", mlog.Int("board_count", boardCount))
+	return a.store.SetSystemSetting(propertyOptionColorMigrationSetting, "true")
+}
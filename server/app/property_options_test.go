@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+// boardWithOptionColors returns a board with a select property whose
+// options have the colors.
+func boardWithOptionColors(colors ...interface{}) model.Block {
+	options := []interface{}{}
+	for i, color := range colors {
+		option := map[string]interface{}{"id": string(rune('a' + i)), "value": "option"}
+		if color != nil {
+			option["color"] = color
+		}
+		options = append(options, option)
+	}
+	return model.Block{
+		ID:   "board-1",
+		Type: "board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": options},
+			},
+		},
+	}
+}
+
+// optionColors returns the colors of the options of the select property
+// of the board.
+func optionColors(board model.Block) []interface{} {
+	colors := []interface{}{}
+	template := board.Fields["cardProperties"].([]interface{})[0].(map[string]interface{})
+	for _, item := range template["options"].([]interface{}) {
+		colors = append(colors, item.(map[string]interface{})["color"])
+	}
+	return colors
+}
+
+func TestValidateBoard(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	notStored := func(string) (*model.Block, error) { return nil, nil }
+
+	t.Run("should accept the colors of the palette", func(t *testing.T) {
+		board := boardWithOptionColors(model.PropertyOptionColorDefault, model.PropertyOptionColorRed, nil)
+		require.NoError(t, th.App.validateBlockFields(container, []model.Block{board}, notStored))
+		require.Equal(t, []interface{}{model.PropertyOptionColorDefault, model.PropertyOptionColorRed, nil}, optionColors(board))
+	})
+
+	t.Run("should normalize the legacy colors", func(t *testing.T) {
+		board := boardWithOptionColors("", "red", "Blue", "propcolorgreen", " propColorPink ")
+		require.NoError(t, th.App.validateBlockFields(container, []model.Block{board}, notStored))
+		require.Equal(t, []interface{}{
+			model.PropertyOptionColorDefault,
+			model.PropertyOptionColorRed,
+			model.PropertyOptionColorBlue,
+			model.PropertyOptionColorGreen,
+			model.PropertyOptionColorPink,
+		}, optionColors(board))
+	})
+
+	t.Run("should reject the unknown colors", func(t *testing.T) {
+		for _, color := range []interface{}{"#ff0000", "propColorMagenta", 42} {
+			board := boardWithOptionColors(model.PropertyOptionColorRed, color)
+			err := th.App.validateBlockFields(container, []model.Block{board}, notStored)
+			require.Equal(t, InvalidPropertyOptionError{BlockID: "board-1", PropertyID: "status", OptionID: "b", Color: color}, err)
+			require.Contains(t, err.Error(), "propColorDefault, propColorGray")
+		}
+	})
+
+	t.Run("should ignore the deleted boards", func(t *testing.T) {
+		board := boardWithOptionColors("#ff0000")
+		board.DeleteAt = 1
+		require.NoError(t, th.App.validateBlockFields(container, []model.Block{board}, notStored))
+	})
+}
+
+func TestMigratePropertyOptionColors(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("should normalize the colors of the boards once", func(t *testing.T) {
+		legacy := boardWithOptionColors("red", "#ff0000", model.PropertyOptionColorBlue)
+		current := boardWithOptionColors(model.PropertyOptionColorGray)
+		current.ID = "board-2"
+
+		th.Store.EXPECT().GetSystemSettings().Return(map[string]string{}, nil)
+		th.Store.EXPECT().GetBoardWorkspaceIDs().Return([]string{"0"}, nil)
+		th.Store.EXPECT().GetBlocksWithType(gomock.Any(), container, "board").Return([]model.Block{legacy, current}, nil)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), container, gomock.Any(), systemUserID).DoAndReturn(
+			func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				require.Len(t, blocks, 1)
+				require.Equal(t, "board-1", blocks[0].ID)
+				require.Equal(t, []interface{}{
+					model.PropertyOptionColorRed,
+					model.PropertyOptionColorDefault,
+					model.PropertyOptionColorBlue,
+				}, optionColors(blocks[0]))
+				return &model.BlocksUpsertResult{}, nil
+			})
+		th.Store.EXPECT().SetSystemSetting(propertyOptionColorMigrationSetting, "true").Return(nil)
+
+		require.NoError(t, th.App.MigratePropertyOptionColors(context.Background()))
+	})
+
+	t.Run("should skip a completed migration", func(t *testing.T) {
+		th.Store.EXPECT().GetSystemSettings().Return(map[string]string{propertyOptionColorMigrationSetting: "true"}, nil)
+
+		require.NoError(t, th.App.MigratePropertyOptionColors(context.Background()))
+	})
+}
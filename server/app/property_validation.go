@@ -0,0 +1,233 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// validateCardPropertiesField is the field of the boards that opts in the
+// enforcement of the validation rules of their card properties.
+const validateCardPropertiesField = "validateCardProperties"
+
+// InvalidPropertiesError is returned when the values of properties of a
+// card break the validation rules of its board.
+type InvalidPropertiesError struct {
+	BlockID     string
+	PropertyIDs []string
+}
+
+func (e InvalidPropertiesError) Error() string {
+	return fmt.Sprintf("properties %s of card %s are invalid", strings.Join(e.PropertyIDs, ", "), e.BlockID)
+}
+
+// propertyRule is the validation rule of a card property, read from the
+// "validation" field of its definition in the cardProperties of the board.
+type propertyRule struct {
+	id           string
+	propertyType string
+	required     bool
+	pattern      *regexp.Regexp
+	min          *float64
+	max          *float64
+	optionsOnly  bool
+	options      map[string]bool
+}
+
+// propertyRules returns the validation rules of the card properties of
+// the board, none unless the board opts in their enforcement. The
+// invalid rules are ignored, so that a mistake in the definition of a
+// board doesn't prevent editing its cards.
+func propertyRules(board *model.Block) []propertyRule {
+	if board == nil || board.Type != "board" {
+		return nil
+	}
+	if enabled, _ := board.Fields[validateCardPropertiesField].(bool); !enabled {
+		return nil
+	}
+
+	rules := []propertyRule{}
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		template, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		validation, ok := template["validation"].(map[string]interface{})
+		if !ok || template["type"] == model.PropertyTypeComputed {
+			continue
+		}
+
+		rule := propertyRule{options: map[string]bool{}}
+		rule.id, _ = template["id"].(string)
+		rule.propertyType, _ = template["type"].(string)
+		rule.required, _ = validation["required"].(bool)
+		rule.optionsOnly, _ = validation["optionsOnly"].(bool)
+		if pattern, ok := validation["pattern"].(string); ok && pattern != "" {
+			rule.pattern, _ = regexp.Compile(pattern)
+		}
+		if min, ok := validation["min"].(float64); ok {
+			rule.min = &min
+		}
+		if max, ok := validation["max"].(float64); ok {
+			rule.max = &max
+		}
+
+		options, _ := template["options"].([]interface{})
+		for _, item := range options {
+			if option, ok := item.(map[string]interface{}); ok {
+				id, _ := option["id"].(string)
+				rule.options[id] = true
+			}
+		}
+
+		if rule.id != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// isEmptyPropertyValue tells if the value of a card property is unset.
+func isEmptyPropertyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// allows tells if the value of the card property follows the rule. The
+// empty values are only invalid for the required properties.
+func (r propertyRule) allows(value interface{}) bool {
+	if isEmptyPropertyValue(value) {
+		return !r.required
+	}
+
+	switch r.propertyType {
+	case "text":
+		if r.pattern != nil {
+			text, ok := value.(string)
+			return ok && r.pattern.MatchString(text)
+		}
+	case "number":
+		if r.min != nil || r.max != nil {
+			number, ok := parseNumberProperty(value)
+			return ok && (r.min == nil || number >= *r.min) && (r.max == nil || number <= *r.max)
+		}
+	case "select":
+		if r.optionsOnly {
+			id, ok := value.(string)
+			return ok && r.options[id]
+		}
+	case "multiSelect":
+		if r.optionsOnly {
+			ids, ok := value.([]interface{})
+			if !ok {
+				return false
+			}
+			for _, id := range ids {
+				if id, ok := id.(string); !ok || !r.options[id] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// parseNumberProperty returns the value of a number property, which the
+// clients store as a string.
+func parseNumberProperty(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// boardRulesGetter returns a function returning the property rules of a
+// board, reading each board once with getBlock.
+func boardRulesGetter(getBlock func(blockID string) (*model.Block, error)) func(boardID string) ([]propertyRule, error) {
+	cache := map[string][]propertyRule{}
+	return func(boardID string) ([]propertyRule, error) {
+		if rules, ok := cache[boardID]; ok {
+			return rules, nil
+		}
+		board, err := getBlock(boardID)
+		if err != nil {
+			return nil, err
+		}
+		rules := propertyRules(board)
+		cache[boardID] = rules
+		return rules, nil
+	}
+}
+
+// validateCardProperties checks that the properties of the cards among
+// the blocks follow the validation rules of their board, when it opts in
+// their enforcement. The rules aren't enforced retroactively: a property
+// that breaks its rule is only rejected if the card is new, moves to the
+// board, or the value of the property changes from the version returned
+// by getStored.
+func (a *App) validateCardProperties(ctx context.Context, st store.Store, c store.Container, blocks []model.Block, getStored func(blockID string) (*model.Block, error)) error {
+	getRules := boardRulesGetter(batchBlockGetter(ctx, st, c, blocks))
+	for _, card := range blocks {
+		if card.Type != "card" || card.DeleteAt != 0 {
+			continue
+		}
+
+		rules, err := getRules(card.RootID)
+		if err != nil {
+			return err
+		}
+
+		values, _ := card.Fields["properties"].(map[string]interface{})
+		failing := []propertyRule{}
+		for _, rule := range rules {
+			if !rule.allows(values[rule.id]) {
+				failing = append(failing, rule)
+			}
+		}
+		if len(failing) == 0 {
+			continue
+		}
+
+		stored, err := getStored(card.ID)
+		if err != nil {
+			return err
+		}
+		moved := stored == nil || stored.RootID != card.RootID
+		var storedValues map[string]interface{}
+		if !moved {
+			storedValues, _ = stored.Fields["properties"].(map[string]interface{})
+		}
+
+		propertyIDs := []string{}
+		for _, rule := range failing {
+			if moved || !reflect.DeepEqual(storedValues[rule.id], values[rule.id]) {
+				propertyIDs = append(propertyIDs, rule.id)
+			}
+		}
+		if len(propertyIDs) > 0 {
+			sort.Strings(propertyIDs)
+			return InvalidPropertiesError{BlockID: card.ID, PropertyIDs: propertyIDs}
+		}
+	}
+
+	return nil
+}
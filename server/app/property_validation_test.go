@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPropertyRules(t *testing.T) {
+	property := func(propertyType string, validation map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"id":   "property",
+			"type": propertyType,
+			"options": []interface{}{
+				map[string]interface{}{"id": "option-1", "value": "One"},
+				map[string]interface{}{"id": "option-2", "value": "Two"},
+			},
+			"validation": validation,
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		property map[string]interface{}
+		value    interface{}
+		allowed  bool
+	}{
+		{"required text set", property("text", map[string]interface{}{"required": true}), "x", true},
+		{"required text empty", property("text", map[string]interface{}{"required": true}), "", false},
+		{"required text unset", property("text", map[string]interface{}{"required": true}), nil, false},
+		{"optional text unset", property("text", map[string]interface{}{"pattern": "^[A-Z]+-[0-9]+$"}), nil, true},
+		{"text matching the pattern", property("text", map[string]interface{}{"pattern": "^[A-Z]+-[0-9]+$"}), "FB-12", true},
+		{"text not matching the pattern", property("text", map[string]interface{}{"pattern": "^[A-Z]+-[0-9]+$"}), "fb-12", false},
+		{"text with an invalid pattern", property("text", map[string]interface{}{"pattern": "["}), "x", true},
+		{"number in range", property("number", map[string]interface{}{"min": 1.0, "max": 5.0}), "3", true},
+		{"number at the bounds", property("number", map[string]interface{}{"min": 1.0, "max": 5.0}), "5", true},
+		{"number below min", property("number", map[string]interface{}{"min": 1.0}), "0.5", false},
+		{"number above max", property("number", map[string]interface{}{"max": 5.0}), 6.0, false},
+		{"number that isn't a number", property("number", map[string]interface{}{"min": 1.0}), "many", false},
+		{"number without bounds", property("number", map[string]interface{}{}), "many", true},
+		{"required number unset", property("number", map[string]interface{}{"required": true}), nil, false},
+		{"select option", property("select", map[string]interface{}{"optionsOnly": true}), "option-1", true},
+		{"select unknown option", property("select", map[string]interface{}{"optionsOnly": true}), "option-3", false},
+		{"select unknown option allowed", property("select", map[string]interface{}{}), "option-3", true},
+		{"required select unset", property("select", map[string]interface{}{"required": true}), "", false},
+		{"multiSelect options", property("multiSelect", map[string]interface{}{"optionsOnly": true}), []interface{}{"option-1", "option-2"}, true},
+		{"multiSelect unknown option", property("multiSelect", map[string]interface{}{"optionsOnly": true}), []interface{}{"option-1", "option-3"}, false},
+		{"multiSelect that isn't a list", property("multiSelect", map[string]interface{}{"optionsOnly": true}), "option-1", false},
+		{"required multiSelect empty", property("multiSelect", map[string]interface{}{"required": true}), []interface{}{}, false},
+		{"required person set", property("person", map[string]interface{}{"required": true}), "user-1", true},
+		{"required date unset", property("date", map[string]interface{}{"required": true}), nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			board := &model.Block{ID: "board-1", Type: "board", Fields: map[string]interface{}{
+				"validateCardProperties": true,
+				"cardProperties":         []interface{}{tc.property},
+			}}
+			rules := propertyRules(board)
+			require.Len(t, rules, 1)
+			require.Equal(t, tc.allowed, rules[0].allows(tc.value))
+		})
+	}
+
+	t.Run("should have no rules unless the board opts in", func(t *testing.T) {
+		board := &model.Block{ID: "board-1", Type: "board", Fields: map[string]interface{}{
+			"cardProperties": []interface{}{property("text", map[string]interface{}{"required": true})},
+		}}
+		require.Empty(t, propertyRules(board))
+	})
+}
+
+func TestValidateCardProperties(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := &model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Fields: map[string]interface{}{
+			"validateCardProperties": true,
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "title", "type": "text", "validation": map[string]interface{}{"required": true}},
+				map[string]interface{}{"id": "points", "type": "number", "validation": map[string]interface{}{"max": 8.0}},
+			},
+		},
+	}
+	card := func(properties map[string]interface{}) model.Block {
+		return model.Block{
+			ID:     "card-1",
+			RootID: "board-1",
+			Type:   "card",
+			Fields: map[string]interface{}{"properties": properties},
+		}
+	}
+	notStored := func(string) (*model.Block, error) { return nil, nil }
+	storedAs := func(block model.Block) func(string) (*model.Block, error) {
+		return func(string) (*model.Block, error) { return &block, nil }
+	}
+
+	t.Run("should accept valid cards", func(t *testing.T) {
+		blocks := []model.Block{*board, card(map[string]interface{}{"title": "x", "points": "3"})}
+
+		err := th.App.validateCardProperties(ctx, th.Store, container, blocks, notStored)
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject new cards with the failing properties", func(t *testing.T) {
+		blocks := []model.Block{*board, card(map[string]interface{}{"points": "13"})}
+
+		err := th.App.validateCardProperties(ctx, th.Store, container, blocks, notStored)
+		var propertiesErr InvalidPropertiesError
+		require.ErrorAs(t, err, &propertiesErr)
+		require.Equal(t, "card-1", propertiesErr.BlockID)
+		require.Equal(t, []string{"points", "title"}, propertiesErr.PropertyIDs)
+	})
+
+	t.Run("should accept changes that don't touch the failing properties", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		stored := card(map[string]interface{}{"points": "13"})
+
+		err := th.App.validateCardProperties(ctx, th.Store, container, []model.Block{card(map[string]interface{}{"points": "13", "other": "y"})}, storedAs(stored))
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject changes of the failing properties", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		stored := card(map[string]interface{}{"title": "x", "points": "3"})
+
+		err := th.App.validateCardProperties(ctx, th.Store, container, []model.Block{card(map[string]interface{}{"title": "x", "points": "13"})}, storedAs(stored))
+		var propertiesErr InvalidPropertiesError
+		require.ErrorAs(t, err, &propertiesErr)
+		require.Equal(t, []string{"points"}, propertiesErr.PropertyIDs)
+	})
+
+	t.Run("should reject cards moved to the board", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		stored := card(map[string]interface{}{"points": "13"})
+		stored.RootID = "board-2"
+
+		err := th.App.validateCardProperties(ctx, th.Store, container, []model.Block{card(map[string]interface{}{"points": "13"})}, storedAs(stored))
+		var propertiesErr InvalidPropertiesError
+		require.ErrorAs(t, err, &propertiesErr)
+		require.Equal(t, []string{"points", "title"}, propertiesErr.PropertyIDs)
+	})
+
+	t.Run("should accept any card of the boards that don't opt in", func(t *testing.T) {
+		optOut := &model.Block{ID: "board-1", Type: "board", Fields: map[string]interface{}{"cardProperties": board.Fields["cardProperties"]}}
+		blocks := []model.Block{*optOut, card(map[string]interface{}{"points": "13"})}
+
+		err := th.App.validateCardProperties(ctx, th.Store, container, blocks, notStored)
+		require.NoError(t, err)
+	})
+}
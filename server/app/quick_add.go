@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/quickadd"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// ErrEmptyQuickAddTitle is returned when nothing is left for the title of
+// a quick-added card once its tokens are parsed.
+var ErrEmptyQuickAddTitle = errors.New("the card title is empty")
+
+// QuickAddCard parses the text into the title and the property values of
+// a new card of the board, and inserts it along with the content of the
+// default card template. The @tokens only match the users of the
+// workspace. It returns sql.ErrNoRows if the board doesn't exist.
+func (a *App) QuickAddCard(ctx context.Context, c store.Container, boardID, text, userID string) (*model.Block, error) {
+	board, err := a.store.GetBlock(ctx, c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil || board.Type != "board" {
+		return nil, sql.ErrNoRows
+	}
+
+	resolveUser := func(username string) (string, bool) {
+		user, err := a.store.GetUserByUsername(username)
+		if err != nil || user == nil || user.DeleteAt != 0 {
+			return "", false
+		}
+		if !a.DoesUserHaveWorkspaceAccess(ctx, user.ID, c.WorkspaceID) {
+			return "", false
+		}
+		return user.ID, true
+	}
+	parsed := quickadd.New(quickAddProperties(*board), resolveUser).Parse(text, time.Now().UTC())
+	if parsed.Title == "" {
+		return nil, ErrEmptyQuickAddTitle
+	}
+
+	now := utils.GetMillis()
+	card := model.Block{
+		ID:         utils.CreateGUID(),
+		ParentID:   board.ID,
+		RootID:     board.ID,
+		CreatedBy:  userID,
+		ModifiedBy: userID,
+		Type:       "card",
+		Title:      parsed.Title,
+		Fields: map[string]interface{}{
+			"properties":   parsed.Properties,
+			"contentOrder": []interface{}{},
+			"isTemplate":   false,
+		},
+		CreateAt: now,
+		UpdateAt: now,
+	}
+
+	blocks, err := a.ApplyDefaultCardTemplate(ctx, c, []model.Block{card})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := a.InsertBlocks(ctx, c, blocks, userID); err != nil {
+		return nil, err
+	}
+	return &blocks[0], nil
+}
+
+// quickAddProperties returns the card properties of the board, with their
+// options in their order.
+func quickAddProperties(board model.Block) []quickadd.Property {
+	properties := []quickadd.Property{}
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		template, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		property := quickadd.Property{}
+		property.ID, _ = template["id"].(string)
+		property.Name, _ = template["name"].(string)
+		property.Type, _ = template["type"].(string)
+
+		options, _ := template["options"].([]interface{})
+		for _, item := range options {
+			if option, ok := item.(map[string]interface{}); ok {
+				id, _ := option["id"].(string)
+				value, _ := option["value"].(string)
+				property.Options = append(property.Options, quickadd.Option{ID: id, Value: value})
+			}
+		}
+		properties = append(properties, property)
+	}
+	return properties
+}
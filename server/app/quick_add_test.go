@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickAddCard(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectSingleWorkspace()
+	th.expectOpenBoards()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	board := &model.Block{ID: "board-1", RootID: "board-1", Type: "board", Fields: map[string]interface{}{
+		"cardProperties": []interface{}{
+			map[string]interface{}{"id": "priority", "name": "Priority", "type": "select", "options": []interface{}{
+				map[string]interface{}{"id": "urgent", "value": "Urgent"},
+			}},
+			map[string]interface{}{"id": "owner", "name": "Owner", "type": "person"},
+			map[string]interface{}{"id": "due", "name": "Due", "type": "date"},
+		},
+	}}
+	th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil).AnyTimes()
+	th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("view-1")).Return(&model.Block{ID: "view-1", Type: "view"}, nil).AnyTimes()
+	th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Any()).Return(nil, nil).AnyTimes()
+	th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows).AnyTimes()
+
+	t.Run("should insert the parsed card", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("sara")).Return(&model.User{ID: "sara-id", Username: "sara"}, nil)
+		th.Store.EXPECT().HasWorkspaceAccess(gomock.Any(), gomock.Eq("sara-id"), gomock.Eq("0")).Return(true, nil)
+		th.Store.EXPECT().GetUserByUsername(gomock.Eq("outsider")).Return(&model.User{ID: "outsider-id", Username: "outsider"}, nil)
+		th.Store.EXPECT().HasWorkspaceAccess(gomock.Any(), gomock.Eq("outsider-id"), gomock.Eq("0")).Return(false, nil)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-id")).
+			DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				require.Len(t, blocks, 1)
+				require.Equal(t, "card", blocks[0].Type)
+				require.Equal(t, "board-1", blocks[0].ParentID)
+				require.Equal(t, "board-1", blocks[0].RootID)
+				return &model.BlocksUpsertResult{Inserted: []string{blocks[0].ID}}, nil
+			})
+
+		card, err := th.App.QuickAddCard(ctx, container, "board-1", "Fix login bug #urgent @outsider @sara due tomorrow", "user-id")
+		require.NoError(t, err)
+		require.NotEmpty(t, card.ID)
+		require.Equal(t, "Fix login bug @outsider", card.Title)
+		require.Equal(t, "user-id", card.CreatedBy)
+		properties := card.Fields["properties"].(map[string]interface{})
+		require.Equal(t, "urgent", properties["priority"])
+		require.Equal(t, "sara-id", properties["owner"])
+		require.Contains(t, properties["due"], `{"from":`)
+		require.Equal(t, false, card.Fields["isTemplate"])
+	})
+
+	t.Run("should fail without a title", func(t *testing.T) {
+		_, err := th.App.QuickAddCard(ctx, container, "board-1", "#urgent", "user-id")
+		require.ErrorIs(t, err, ErrEmptyQuickAddTitle)
+	})
+
+	t.Run("should fail if the block isn't a board", func(t *testing.T) {
+		_, err := th.App.QuickAddCard(ctx, container, "view-1", "Fix login bug", "user-id")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = th.App.QuickAddCard(ctx, container, "missing", "Fix login bug", "user-id")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
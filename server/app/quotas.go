@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// ErrQuotaExceeded is returned when inserting blocks or uploading a file
+// would exceed the configured quota of the workspace.
+var ErrQuotaExceeded = errors.New("the workspace quota is exceeded")
+
+// workspaceUsageCache is the usage of the workspaces whose quotas were
+// checked, so that their blocks and files aren't counted on every
+// insert. The usage is incremented by the inserts and the uploads of
+// this server, and counted again by RecomputeWorkspaceUsage to correct
+// the drift of the other writes, like the ones of the other servers.
+type workspaceUsageCache struct {
+	mutex sync.Mutex
+	usage map[string]*model.WorkspaceUsage
+}
+
+func newWorkspaceUsageCache() *workspaceUsageCache {
+	return &workspaceUsageCache{usage: map[string]*model.WorkspaceUsage{}}
+}
+
+// GetWorkspaceUsage returns the usage of the workspace, counting it if
+// it isn't cached yet.
+func (a *App) GetWorkspaceUsage(workspaceID string) (model.WorkspaceUsage, error) {
+	cache := a.workspaceUsage
+	cache.mutex.Lock()
+	usage, ok := cache.usage[workspaceID]
+	cache.mutex.Unlock()
+	if ok {
+		return *usage, nil
+	}
+
+	counted, err := a.store.GetWorkspaceUsage(workspaceID)
+	if err != nil {
+		return model.WorkspaceUsage{}, err
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	// keep the usage counted meanwhile by another request, which may
+	// have been incremented since
+	if usage, ok := cache.usage[workspaceID]; ok {
+		return *usage, nil
+	}
+	cache.usage[workspaceID] = counted
+	return *counted, nil
+}
+
+// addWorkspaceUsage increments the usage of the workspace, if it's
+// cached.
+func (a *App) addWorkspaceUsage(workspaceID string, blockCount, fileBytes int64) {
+	cache := a.workspaceUsage
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if usage, ok := cache.usage[workspaceID]; ok {
+		usage.BlockCount += blockCount
+		usage.FileBytes += fileBytes
+	}
+}
+
+// forgetWorkspaceUsage removes the usage of the workspace from the
+// cache, so that it's counted again when its quotas are next checked.
+func (a *App) forgetWorkspaceUsage(workspaceID string) {
+	cache := a.workspaceUsage
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	delete(cache.usage, workspaceID)
+}
+
+// RecomputeWorkspaceUsage counts the usage of the cached workspaces
+// again.
+func (a *App) RecomputeWorkspaceUsage() error {
+	cache := a.workspaceUsage
+	cache.mutex.Lock()
+	workspaceIDs := make([]string, 0, len(cache.usage))
+	for workspaceID := range cache.usage {
+		workspaceIDs = append(workspaceIDs, workspaceID)
+	}
+	cache.mutex.Unlock()
+
+	for _, workspaceID := range workspaceIDs {
+		counted, err := a.store.GetWorkspaceUsage(workspaceID)
+		if err != nil {
+			return err
+		}
+
+		cache.mutex.Lock()
+		cache.usage[workspaceID] = counted
+		cache.mutex.Unlock()
+	}
+
+	return nil
+}
+
+// checkBlockQuota returns ErrQuotaExceeded if inserting the blocks would
+// exceed the block quota of the workspace. Only the blocks that don't
+// exist yet count, and they're only looked up when the blocks could
+// exceed the quota.
+func (a *App) checkBlockQuota(ctx context.Context, c store.Container, blocks []model.Block) error {
+	maxBlocks := a.config.MaxBlocksPerWorkspace
+	if maxBlocks <= 0 {
+		return nil
+	}
+
+	usage, err := a.GetWorkspaceUsage(c.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if usage.BlockCount+int64(len(blocks)) <= maxBlocks {
+		return nil
+	}
+
+	var added int64
+	for i := range blocks {
+		if blocks[i].DeleteAt != 0 {
+			continue
+		}
+		existing, err := a.store.GetBlock(ctx, c, blocks[i].ID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			added++
+		}
+	}
+	if added > 0 && usage.BlockCount+added > maxBlocks {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// remainingFileStorage returns the bytes that can still be uploaded to
+// the workspace, and false if its file storage is unlimited.
+func (a *App) remainingFileStorage(workspaceID string) (int64, bool, error) {
+	maxBytes := a.config.MaxFileStoragePerWorkspace
+	if maxBytes <= 0 {
+		return 0, false, nil
+	}
+
+	usage, err := a.GetWorkspaceUsage(workspaceID)
+	if err != nil {
+		return 0, false, err
+	}
+	if usage.FileBytes >= maxBytes {
+		return 0, true, nil
+	}
+	return maxBytes - usage.FileBytes, true, nil
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockQuota(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectOpenBoards()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	th.App.config.MaxBlocksPerWorkspace = 3
+	defer func() { th.App.config.MaxBlocksPerWorkspace = 0 }()
+
+	t.Run("should count the usage once and increment it", func(t *testing.T) {
+		blocks := []model.Block{{ID: "block-1", Type: "board"}, {ID: "block-2", Type: "board"}}
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Any()).Return(nil, nil).Times(2)
+		th.Store.EXPECT().GetWorkspaceUsage(gomock.Eq("0")).Return(&model.WorkspaceUsage{BlockCount: 1}, nil)
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Eq(blocks), gomock.Eq("user-id-1")).
+			Return(&model.BlocksUpsertResult{Inserted: []string{"block-1", "block-2"}, Updated: []string{}}, nil)
+
+		_, err := th.App.InsertBlocks(ctx, container, blocks, "user-id-1")
+		require.NoError(t, err)
+
+		usage, err := th.App.GetWorkspaceUsage("0")
+		require.NoError(t, err)
+		require.EqualValues(t, 3, usage.BlockCount)
+	})
+
+	t.Run("should reject a new block over the quota", func(t *testing.T) {
+		blocks := []model.Block{{ID: "block-3", Type: "board"}}
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-3")).Return(nil, nil).Times(2)
+
+		result, err := th.App.InsertBlocks(ctx, container, blocks, "user-id-1")
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+		require.Nil(t, result)
+	})
+
+	t.Run("should update an existing block over the quota", func(t *testing.T) {
+		blocks := []model.Block{{ID: "block-1", Type: "board", Title: "Renamed"}}
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("block-1")).Return(&model.Block{ID: "block-1"}, nil).Times(2)
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Eq(blocks), gomock.Eq("user-id-1")).
+			Return(&model.BlocksUpsertResult{Inserted: []string{}, Updated: []string{"block-1"}}, nil)
+
+		_, err := th.App.InsertBlocks(ctx, container, blocks, "user-id-1")
+		require.NoError(t, err)
+	})
+
+	t.Run("should recompute the cached usage", func(t *testing.T) {
+		th.Store.EXPECT().GetWorkspaceUsage(gomock.Eq("0")).Return(&model.WorkspaceUsage{BlockCount: 2, FileBytes: 10}, nil)
+
+		require.NoError(t, th.App.RecomputeWorkspaceUsage())
+
+		usage, err := th.App.GetWorkspaceUsage("0")
+		require.NoError(t, err)
+		require.Equal(t, model.WorkspaceUsage{BlockCount: 2, FileBytes: 10}, usage)
+	})
+}
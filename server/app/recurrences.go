@@ -0,0 +1,213 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// InvalidRecurrenceError is returned when the fields of a recurrence
+// block don't define a schedule of a card template of its board.
+type InvalidRecurrenceError struct {
+	BlockID string
+	Reason  string
+}
+
+func (e InvalidRecurrenceError) Error() string {
+	return fmt.Sprintf("invalid recurrence %s: %s", e.BlockID, e.Reason)
+}
+
+// validateRecurrences checks that the recurrence blocks among the blocks
+// have a valid schedule, and refer to a card template and a select
+// option of their board, either already stored or inserted along with
+// them.
+func (a *App) validateRecurrences(ctx context.Context, st store.Store, c store.Container, blocks []model.Block) error {
+	getBlock := batchBlockGetter(ctx, st, c, blocks)
+	for _, block := range blocks {
+		if block.Type != model.RecurrenceBlockType || block.DeleteAt != 0 {
+			continue
+		}
+		invalid := func(format string, args ...interface{}) error {
+			return InvalidRecurrenceError{BlockID: block.ID, Reason: fmt.Sprintf(format, args...)}
+		}
+
+		recurrence, err := model.RecurrenceFromBlock(block)
+		if err != nil {
+			return invalid("%s", err)
+		}
+
+		board, err := getBlock(block.RootID)
+		if err != nil {
+			return err
+		}
+		if board == nil || board.Type != "board" || block.ParentID != board.ID {
+			return invalid("the recurrence isn't a child of a board")
+		}
+
+		template, err := getBlock(recurrence.TemplateID)
+		if err != nil {
+			return err
+		}
+		if template == nil || template.Type != "card" || template.RootID != board.ID || template.DeleteAt != 0 {
+			return invalid("%s isn't a card of the board", recurrence.TemplateID)
+		}
+		if isTemplate, _ := template.Fields["isTemplate"].(bool); !isTemplate {
+			return invalid("%s isn't a card template", recurrence.TemplateID)
+		}
+
+		if recurrence.PropertyID != "" && !hasSelectOption(*board, recurrence.PropertyID, recurrence.OptionID) {
+			return invalid("%s isn't an option of the select property %s", recurrence.OptionID, recurrence.PropertyID)
+		}
+	}
+
+	return nil
+}
+
+// hasSelectOption tells if the option is one of the select property of
+// the board.
+func hasSelectOption(board model.Block, propertyID, optionID string) bool {
+	for _, property := range csvProperties(board, nil) {
+		if property.id == propertyID && property.propertyType == "select" {
+			_, ok := property.options[optionID]
+			return ok
+		}
+	}
+	return false
+}
+
+// CreateRecurringCards copies the card templates of the recurrences
+// whose last occurrence up to now didn't create a card yet. The
+// occurrences are recorded along with the cards, so that a restart or
+// another server doesn't create them again, and only the latest missed
+// occurrence of each recurrence creates a card.
+func (a *App) CreateRecurringCards(ctx context.Context) error {
+	now := time.Now()
+
+	workspaceIDs, err := a.store.GetBoardWorkspaceIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, workspaceID := range workspaceIDs {
+		c := store.Container{
+			WorkspaceID: workspaceID,
+		}
+
+		recurrences, err := a.store.GetBlocksWithType(ctx, c, model.RecurrenceBlockType)
+		if err != nil {
+			return err
+		}
+
+		for _, block := range recurrences {
+			recurrence, err := model.RecurrenceFromBlock(block)
+			if err != nil {
+				a.logger.Warn("Skipping invalid recurrence", mlog.String("recurrenceID", block.ID), mlog.Err(err))
+				continue
+			}
+
+			lastRun, err := a.store.GetLastRecurrenceRun(ctx, block.ID)
+			if err != nil {
+				return err
+			}
+			// a new recurrence starts from its creation, rather than
+			// catching up with the occurrences before it
+			after := lastRun
+			if after < block.CreateAt {
+				after = block.CreateAt
+			}
+
+			runAt, ok := recurrence.Rule.Latest(utils.TimeFromMillis(after), now, recurrence.Location)
+			if !ok {
+				continue
+			}
+			if err := a.createRecurringCard(ctx, c, block, recurrence, utils.MillisFromTime(runAt)); err != nil {
+				a.logger.Error("Unable to create the recurring card",
+					mlog.String("recurrenceID", block.ID),
+					mlog.Err(err),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// createRecurringCard copies the card template of the recurrence and its
+// content into the board, and records the occurrence in the same
+// transaction.
+func (a *App) createRecurringCard(ctx context.Context, c store.Container, block model.Block, recurrence *model.Recurrence, runAt int64) error {
+	tx, err := a.store.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer a.rollbackTx(tx)
+
+	blocks, err := tx.GetSubTree3(ctx, c, recurrence.TemplateID)
+	if err != nil {
+		return err
+	}
+	var template *model.Block
+	for i := range blocks {
+		if blocks[i].ID == recurrence.TemplateID {
+			template = &blocks[i]
+		}
+	}
+	if template == nil || template.Type != "card" || template.RootID != block.RootID {
+		a.logger.Warn("Skipping recurrence without its card template", mlog.String("recurrenceID", block.ID))
+		return nil
+	}
+
+	newBlocks, idMap := duplicateBlockTree(blocks, template.ID)
+	cardID := idMap[template.ID]
+	for i := range newBlocks {
+		// the copies stay in the board of the template
+		newBlocks[i].RootID = block.RootID
+		if newBlocks[i].ID != cardID {
+			continue
+		}
+
+		card := &newBlocks[i]
+		card.Fields["isTemplate"] = false
+		card.Fields["createdFrom"] = block.ID
+		if recurrence.PropertyID != "" {
+			properties := map[string]interface{}{}
+			if values, ok := card.Fields["properties"].(map[string]interface{}); ok {
+				for key, value := range values {
+					properties[key] = value
+				}
+			}
+			properties[recurrence.PropertyID] = recurrence.OptionID
+			card.Fields["properties"] = properties
+		}
+	}
+
+	userID := block.CreatedBy
+	if _, err := tx.InsertBlocks(ctx, c, newBlocks, userID); err != nil {
+		return err
+	}
+	err = tx.InsertRecurrenceRun(ctx, model.RecurrenceRun{
+		RecurrenceID: block.ID,
+		RunAt:        runAt,
+		CardID:       cardID,
+		CreateAt:     utils.GetMillis(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	a.logger.Debug("Created recurring card",
+		mlog.String("recurrenceID", block.ID),
+		mlog.String("cardID", cardID),
+	)
+	a.blocksInserted(ctx, c, nil, nil, newBlocks, userID)
+	return nil
+}
@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRecurrences(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{
+					"id":   "status",
+					"name": "Status",
+					"type": "select",
+					"options": []interface{}{
+						map[string]interface{}{"id": "todo", "value": "To do"},
+					},
+				},
+			},
+		},
+	}
+	template := model.Block{ID: "template-1", RootID: "board-1", ParentID: "board-1", Type: "card", Fields: map[string]interface{}{"isTemplate": true}}
+	card := model.Block{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card"}
+	recurrence := func(fields map[string]interface{}) model.Block {
+		return model.Block{ID: "recurrence-1", RootID: "board-1", ParentID: "board-1", Type: model.RecurrenceBlockType, Fields: fields}
+	}
+
+	testCases := []struct {
+		name   string
+		fields map[string]interface{}
+		valid  bool
+	}{
+		{
+			name:   "weekly",
+			fields: map[string]interface{}{"templateId": "template-1", "rrule": "FREQ=WEEKLY;BYDAY=MO,TH;BYHOUR=9"},
+			valid:  true,
+		},
+		{
+			name: "monthly in a column",
+			fields: map[string]interface{}{
+				"templateId": "template-1",
+				"rrule":      "FREQ=MONTHLY;BYMONTHDAY=31",
+				"timezone":   "Europe/Paris",
+				"propertyId": "status",
+				"optionId":   "todo",
+			},
+			valid: true,
+		},
+		{
+			name:   "missing template",
+			fields: map[string]interface{}{"rrule": "FREQ=WEEKLY;BYDAY=MO"},
+		},
+		{
+			name:   "unknown template",
+			fields: map[string]interface{}{"templateId": "template-2", "rrule": "FREQ=WEEKLY;BYDAY=MO"},
+		},
+		{
+			name:   "card that isn't a template",
+			fields: map[string]interface{}{"templateId": "card-1", "rrule": "FREQ=WEEKLY;BYDAY=MO"},
+		},
+		{
+			name:   "daily rule",
+			fields: map[string]interface{}{"templateId": "template-1", "rrule": "FREQ=DAILY"},
+		},
+		{
+			name:   "weekly rule without days",
+			fields: map[string]interface{}{"templateId": "template-1", "rrule": "FREQ=WEEKLY"},
+		},
+		{
+			name:   "monthly rule on day 32",
+			fields: map[string]interface{}{"templateId": "template-1", "rrule": "FREQ=MONTHLY;BYMONTHDAY=32"},
+		},
+		{
+			name:   "invalid hour",
+			fields: map[string]interface{}{"templateId": "template-1", "rrule": "FREQ=WEEKLY;BYDAY=MO;BYHOUR=24"},
+		},
+		{
+			name:   "unsupported rule part",
+			fields: map[string]interface{}{"templateId": "template-1", "rrule": "FREQ=WEEKLY;BYDAY=MO;COUNT=3"},
+		},
+		{
+			name:   "invalid timezone",
+			fields: map[string]interface{}{"templateId": "template-1", "rrule": "FREQ=WEEKLY;BYDAY=MO", "timezone": "Nowhere"},
+		},
+		{
+			name: "unknown option",
+			fields: map[string]interface{}{
+				"templateId": "template-1",
+				"rrule":      "FREQ=WEEKLY;BYDAY=MO",
+				"propertyId": "status",
+				"optionId":   "done",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("template-2")).Return(nil, nil).AnyTimes()
+
+			// the board and the templates are inserted along
+			err := th.App.validateRecurrences(ctx, th.Store, container, []model.Block{board, template, card, recurrence(tc.fields)})
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			var recurrenceErr InvalidRecurrenceError
+			require.ErrorAs(t, err, &recurrenceErr)
+			require.Equal(t, "recurrence-1", recurrenceErr.BlockID)
+		})
+	}
+}
+
+func TestCreateRecurringCards(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	recurrence := model.Block{
+		ID:        "recurrence-1",
+		RootID:    "board-1",
+		ParentID:  "board-1",
+		Type:      model.RecurrenceBlockType,
+		CreatedBy: "user-1",
+		CreateAt:  utils.MillisFromTime(today.AddDate(0, 0, -3)),
+		Fields: map[string]interface{}{
+			"templateId": "template-1",
+			"rrule":      "FREQ=WEEKLY;BYDAY=SU,MO,TU,WE,TH,FR,SA",
+			"propertyId": "status",
+			"optionId":   "todo",
+		},
+	}
+	template := []model.Block{
+		{
+			ID:       "template-1",
+			RootID:   "board-1",
+			ParentID: "board-1",
+			Type:     "card",
+			Title:    "Weekly review",
+			Fields: map[string]interface{}{
+				"isTemplate":   true,
+				"contentOrder": []interface{}{"text-1"},
+				"properties":   map[string]interface{}{"priority": "high"},
+			},
+		},
+		{ID: "text-1", RootID: "board-1", ParentID: "template-1", Type: "text", Title: "Agenda"},
+	}
+
+	th.Store.EXPECT().GetBoardWorkspaceIDs().Return([]string{"0"}, nil).Times(2)
+	th.Store.EXPECT().GetBlocksWithType(gomock.Any(), gomock.Eq(container), gomock.Eq(model.RecurrenceBlockType)).
+		Return([]model.Block{recurrence}, nil).Times(2)
+
+	t.Run("should create the card of the latest occurrence", func(t *testing.T) {
+		tx := th.expectTx()
+		th.Store.EXPECT().GetLastRecurrenceRun(gomock.Any(), gomock.Eq("recurrence-1")).Return(int64(0), nil)
+		th.Store.EXPECT().GetSubTree3(gomock.Any(), gomock.Eq(container), gomock.Eq("template-1")).Return(template, nil)
+
+		var cardID string
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-1")).
+			DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				require.Len(t, blocks, 2)
+				card, text := blocks[0], blocks[1]
+				cardID = card.ID
+				require.NotEqual(t, "template-1", card.ID)
+				require.Equal(t, "board-1", card.RootID)
+				require.Equal(t, "board-1", card.ParentID)
+				require.Equal(t, "Weekly review", card.Title)
+				require.Equal(t, false, card.Fields["isTemplate"])
+				require.Equal(t, "recurrence-1", card.Fields["createdFrom"])
+				require.Equal(t, map[string]interface{}{"priority": "high", "status": "todo"}, card.Fields["properties"])
+				require.Equal(t, []interface{}{text.ID}, card.Fields["contentOrder"])
+				require.Equal(t, "board-1", text.RootID)
+				require.Equal(t, card.ID, text.ParentID)
+				return &model.BlocksUpsertResult{}, nil
+			})
+		th.Store.EXPECT().InsertRecurrenceRun(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, run model.RecurrenceRun) error {
+			require.Equal(t, "recurrence-1", run.RecurrenceID)
+			require.Equal(t, utils.MillisFromTime(today), run.RunAt)
+			require.Equal(t, cardID, run.CardID)
+			return nil
+		})
+
+		require.NoError(t, th.App.CreateRecurringCards(ctx))
+		require.True(t, tx.committed)
+		// the template is left untouched
+		require.Equal(t, true, template[0].Fields["isTemplate"])
+	})
+
+	t.Run("should not create the card of an occurrence twice", func(t *testing.T) {
+		th.Store.EXPECT().GetLastRecurrenceRun(gomock.Any(), gomock.Eq("recurrence-1")).Return(utils.MillisFromTime(today), nil)
+
+		require.NoError(t, th.App.CreateRecurringCards(ctx))
+	})
+}
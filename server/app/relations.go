@@ -0,0 +1,235 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// InvalidRelationError is returned when a relation property of a card
+// isn't a list of IDs, or links to a block that isn't a card of the
+// workspace.
+type InvalidRelationError struct {
+	BlockID    string
+	PropertyID string
+	TargetID   string
+}
+
+func (e InvalidRelationError) Error() string {
+	if e.TargetID == "" {
+		return fmt.Sprintf("relation property %s of card %s isn't a list of card IDs", e.PropertyID, e.BlockID)
+	}
+	return fmt.Sprintf("relation property %s of card %s links to %s, which isn't a card of the workspace", e.PropertyID, e.BlockID, e.TargetID)
+}
+
+// relationProperties returns the relation properties of the board.
+func relationProperties(board *model.Block) []csvProperty {
+	if board == nil || board.Type != "board" {
+		return nil
+	}
+
+	properties := []csvProperty{}
+	for _, property := range csvProperties(*board, nil) {
+		if property.propertyType == model.PropertyTypeRelation {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// patchesRelations tells if the patch can change the relation properties
+// of a card, or the board they're properties of.
+func patchesRelations(patch *model.BlockPatch) bool {
+	_, patchesProperties := patch.UpdatedFields["properties"]
+	return patchesProperties || len(patch.UpdatedProperties) > 0 || patch.RootID != nil || patch.Type != nil
+}
+
+// boardRelationsGetter returns a function returning the relation
+// properties of a board, reading each board once with getBlock.
+func boardRelationsGetter(getBlock func(blockID string) (*model.Block, error)) func(boardID string) ([]csvProperty, error) {
+	cache := map[string][]csvProperty{}
+	return func(boardID string) ([]csvProperty, error) {
+		if relations, ok := cache[boardID]; ok {
+			return relations, nil
+		}
+		board, err := getBlock(boardID)
+		if err != nil {
+			return nil, err
+		}
+		relations := relationProperties(board)
+		cache[boardID] = relations
+		return relations, nil
+	}
+}
+
+// batchBlockGetter returns a function returning a block, either among
+// the given blocks or read from the store.
+func batchBlockGetter(ctx context.Context, st store.Store, c store.Container, blocks []model.Block) func(blockID string) (*model.Block, error) {
+	batch := make(map[string]*model.Block, len(blocks))
+	for i := range blocks {
+		batch[blocks[i].ID] = &blocks[i]
+	}
+
+	return func(blockID string) (*model.Block, error) {
+		if block, ok := batch[blockID]; ok {
+			return block, nil
+		}
+		return st.GetBlock(ctx, c, blockID)
+	}
+}
+
+// copyBlockFields returns a copy of the block that patching the block
+// doesn't change, the patches replacing its fields in place.
+func copyBlockFields(block model.Block) model.Block {
+	fields := make(map[string]interface{}, len(block.Fields))
+	for key, value := range block.Fields {
+		fields[key] = value
+	}
+	block.Fields = fields
+	return block
+}
+
+// validateRelations checks that the relation properties of the cards
+// among the blocks link to cards of the workspace, either already stored
+// or inserted along with them. The boards and the linked cards are read
+// from the given store, which can be a transaction.
+func (a *App) validateRelations(ctx context.Context, st store.Store, c store.Container, blocks []model.Block) error {
+	getBlock := batchBlockGetter(ctx, st, c, blocks)
+	getRelations := boardRelationsGetter(getBlock)
+	validTargets := map[string]bool{}
+	for _, card := range blocks {
+		if card.Type != "card" || card.DeleteAt != 0 {
+			continue
+		}
+
+		relations, err := getRelations(card.RootID)
+		if err != nil {
+			return err
+		}
+
+		values, _ := card.Fields["properties"].(map[string]interface{})
+		for _, relation := range relations {
+			targetIDs, ok := model.RelationIDs(values[relation.id])
+			if !ok {
+				return InvalidRelationError{BlockID: card.ID, PropertyID: relation.id}
+			}
+
+			for _, targetID := range targetIDs {
+				valid, checked := validTargets[targetID]
+				if !checked {
+					target, err := getBlock(targetID)
+					if err != nil {
+						return err
+					}
+					valid = target != nil && target.Type == "card" && target.DeleteAt == 0
+					validTargets[targetID] = valid
+				}
+				if !valid {
+					return InvalidRelationError{BlockID: card.ID, PropertyID: relation.id, TargetID: targetID}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetCardBacklinks returns the relation properties of the other cards of
+// the workspace that link to the card, or nil if the card doesn't exist.
+func (a *App) GetCardBacklinks(ctx context.Context, c store.Container, cardID string) ([]model.CardBacklink, error) {
+	card, err := a.store.GetBlock(ctx, c, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil || card.Type != "card" {
+		return nil, nil
+	}
+
+	cards, err := a.store.GetCardBacklinks(ctx, c, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	getRelations := boardRelationsGetter(func(blockID string) (*model.Block, error) {
+		return a.store.GetBlock(ctx, c, blockID)
+	})
+	backlinks := []model.CardBacklink{}
+	for _, linking := range cards {
+		relations, err := getRelations(linking.RootID)
+		if err != nil {
+			return nil, err
+		}
+
+		linkingPropertyIDs := map[string]bool{}
+		for _, propertyID := range model.CardPropertiesLinkingTo(linking, cardID) {
+			linkingPropertyIDs[propertyID] = true
+		}
+		for _, relation := range relations {
+			if linkingPropertyIDs[relation.id] {
+				backlinks = append(backlinks, model.CardBacklink{
+					CardID:       linking.ID,
+					BoardID:      linking.RootID,
+					Title:        linking.Title,
+					PropertyID:   relation.id,
+					PropertyName: relation.name,
+				})
+			}
+		}
+	}
+
+	return backlinks, nil
+}
+
+// unlinkCard removes the ID of the card from the relation properties of
+// the cards that link to it, and returns the updated cards. The cards
+// are read and written with the given store, which should be the
+// transaction deleting the card.
+func (a *App) unlinkCard(ctx context.Context, tx store.Store, c store.Container, cardID, userID string) ([]model.Block, error) {
+	cards, err := tx.GetCardBacklinks(ctx, c, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	getRelations := boardRelationsGetter(func(blockID string) (*model.Block, error) {
+		return tx.GetBlock(ctx, c, blockID)
+	})
+	unlinked := []model.Block{}
+	for _, linking := range cards {
+		relations, err := getRelations(linking.RootID)
+		if err != nil {
+			return nil, err
+		}
+
+		values, _ := linking.Fields["properties"].(map[string]interface{})
+		changed := false
+		for _, relation := range relations {
+			targetIDs, ok := model.RelationIDs(values[relation.id])
+			if !ok {
+				continue
+			}
+
+			kept := make([]interface{}, 0, len(targetIDs))
+			for _, targetID := range targetIDs {
+				if targetID != cardID {
+					kept = append(kept, targetID)
+				}
+			}
+			if len(kept) != len(targetIDs) {
+				values[relation.id] = kept
+				changed = true
+			}
+		}
+		if changed {
+			unlinked = append(unlinked, linking)
+		}
+	}
+
+	if len(unlinked) > 0 {
+		if _, err := tx.InsertBlocks(ctx, c, unlinked, userID); err != nil {
+			return nil, err
+		}
+	}
+	return unlinked, nil
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRelations(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := &model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "related", "name": "Related", "type": model.PropertyTypeRelation},
+				map[string]interface{}{"id": "status", "name": "Status", "type": "select"},
+			},
+		},
+	}
+	card := func(id string, related interface{}) model.Block {
+		return model.Block{
+			ID:     id,
+			RootID: "board-1",
+			Type:   "card",
+			Fields: map[string]interface{}{"properties": map[string]interface{}{"related": related, "status": "x"}},
+		}
+	}
+
+	t.Run("should accept links to stored cards", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		target := card("card-2", nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-2")).Return(&target, nil)
+
+		err := th.App.validateRelations(ctx, th.Store, container, []model.Block{card("card-1", []interface{}{"card-2"})})
+		require.NoError(t, err)
+	})
+
+	t.Run("should accept links to cards inserted along", func(t *testing.T) {
+		blocks := []model.Block{*board, card("card-1", []interface{}{"card-2"}), card("card-2", []interface{}{"card-1"})}
+
+		err := th.App.validateRelations(ctx, th.Store, container, blocks)
+		require.NoError(t, err)
+	})
+
+	t.Run("should reject links to unknown blocks", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-2")).Return(nil, nil)
+
+		err := th.App.validateRelations(ctx, th.Store, container, []model.Block{card("card-1", []interface{}{"card-2"})})
+		require.Equal(t, InvalidRelationError{BlockID: "card-1", PropertyID: "related", TargetID: "card-2"}, err)
+	})
+
+	t.Run("should reject links to blocks that aren't cards", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil).Times(2)
+
+		err := th.App.validateRelations(ctx, th.Store, container, []model.Block{card("card-1", []interface{}{"board-1"})})
+		require.Equal(t, InvalidRelationError{BlockID: "card-1", PropertyID: "related", TargetID: "board-1"}, err)
+	})
+
+	t.Run("should reject values that aren't lists of IDs", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+
+		err := th.App.validateRelations(ctx, th.Store, container, []model.Block{card("card-1", "card-2")})
+		require.Equal(t, InvalidRelationError{BlockID: "card-1", PropertyID: "related"}, err)
+	})
+
+	t.Run("should not check the other blocks", func(t *testing.T) {
+		view := model.Block{ID: "view-1", RootID: "board-1", Type: "view"}
+
+		err := th.App.validateRelations(ctx, th.Store, container, []model.Block{view})
+		require.NoError(t, err)
+	})
+}
+
+func TestGetCardBacklinks(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := &model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "related", "name": "Related", "type": model.PropertyTypeRelation},
+				map[string]interface{}{"id": "blocked", "name": "Blocked by", "type": model.PropertyTypeRelation},
+				map[string]interface{}{"id": "text", "name": "Text", "type": "text"},
+			},
+		},
+	}
+	card := &model.Block{ID: "card-1", RootID: "board-1", Type: "card"}
+
+	t.Run("should return the relation properties linking to the card", func(t *testing.T) {
+		linking := model.Block{
+			ID:     "card-2",
+			RootID: "board-1",
+			Type:   "card",
+			Title:  "Linking",
+			Fields: map[string]interface{}{"properties": map[string]interface{}{
+				"related": []interface{}{"card-1"},
+				"blocked": []interface{}{"card-1", "card-3"},
+				"text":    []interface{}{"card-1"},
+			}},
+		}
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(card, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().GetCardBacklinks(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return([]model.Block{linking}, nil)
+
+		backlinks, err := th.App.GetCardBacklinks(ctx, container, "card-1")
+		require.NoError(t, err)
+		require.Equal(t, []model.CardBacklink{
+			{CardID: "card-2", BoardID: "board-1", Title: "Linking", PropertyID: "related", PropertyName: "Related"},
+			{CardID: "card-2", BoardID: "board-1", Title: "Linking", PropertyID: "blocked", PropertyName: "Blocked by"},
+		}, backlinks)
+	})
+
+	t.Run("should return nil if the card doesn't exist", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(nil, nil)
+
+		backlinks, err := th.App.GetCardBacklinks(ctx, container, "card-1")
+		require.NoError(t, err)
+		require.Nil(t, backlinks)
+	})
+}
+
+func TestDeleteLinkedCard(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectSingleWorkspace()
+	th.expectOpenBoards()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := &model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "related", "name": "Related", "type": model.PropertyTypeRelation},
+			},
+		},
+	}
+	card := &model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"}
+	linking := model.Block{
+		ID:     "card-2",
+		RootID: "board-1",
+		Type:   "card",
+		Fields: map[string]interface{}{"properties": map[string]interface{}{"related": []interface{}{"card-3", "card-1"}}},
+	}
+
+	tx := th.expectTx()
+	th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows).AnyTimes()
+	th.Store.EXPECT().GetParentID(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return("board-1", nil)
+	th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(card, nil)
+	th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil).Times(2)
+	th.Store.EXPECT().DeleteBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1"), gomock.Eq("user-id")).Return(nil)
+	th.Store.EXPECT().GetCardBacklinks(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return([]model.Block{linking}, nil)
+	th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(container), gomock.Any(), gomock.Eq("user-id")).
+		DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+			require.Len(t, blocks, 1)
+			require.Equal(t, "card-2", blocks[0].ID)
+			properties := blocks[0].Fields["properties"].(map[string]interface{})
+			require.Equal(t, []interface{}{"card-3"}, properties["related"])
+			return &model.BlocksUpsertResult{}, nil
+		})
+	th.Store.EXPECT().InsertAuditEntry(gomock.Any()).Return(nil)
+
+	require.NoError(t, th.App.DeleteBlock(ctx, container, "card-1", "user-id"))
+	require.True(t, tx.committed)
+}
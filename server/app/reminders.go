@@ -0,0 +1,239 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// dueDateReminderWindow is how long before their due date the
+// assignees of a card are reminded of it.
+const dueDateReminderWindow = 24 * time.Hour
+
+// dueDateProperties are the IDs of the due date and person properties
+// of a board.
+type dueDateProperties struct {
+	dueDateID string
+	personIDs []string
+}
+
+// SendDueDateReminders notifies the assignees of the cards that are due
+// within the next 24 hours. The due date is the date property with the
+// configured name and the assignees are the users of the person
+// properties. Each assignee is reminded once per due date.
+func (a *App) SendDueDateReminders(ctx context.Context) error {
+	propertyName := a.config.DueDatePropertyName
+	if propertyName == "" {
+		propertyName = config.DefaultDueDatePropertyName
+	}
+
+	now := utils.GetMillis()
+	until := now + dueDateReminderWindow.Milliseconds()
+
+	workspaceIDs, err := a.store.GetBoardWorkspaceIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, workspaceID := range workspaceIDs {
+		c := store.Container{
+			WorkspaceID: workspaceID,
+		}
+
+		boards, err := a.store.GetBlocksWithType(ctx, c, "board")
+		if err != nil {
+			return err
+		}
+
+		boardProperties := map[string]dueDateProperties{}
+		for _, board := range boards {
+			if isTemplate, _ := board.Fields["isTemplate"].(bool); isTemplate {
+				continue
+			}
+			if properties, ok := getDueDateProperties(board, propertyName); ok {
+				boardProperties[board.ID] = properties
+			}
+		}
+		if len(boardProperties) == 0 {
+			continue
+		}
+
+		cards, err := a.store.GetBlocksWithType(ctx, c, "card")
+		if err != nil {
+			return err
+		}
+
+		for _, card := range cards {
+			properties, ok := boardProperties[card.RootID]
+			if !ok {
+				continue
+			}
+
+			values, _ := card.Fields["properties"].(map[string]interface{})
+			dueAt := parseDateValue(values[properties.dueDateID])
+			if dueAt < now || dueAt > until {
+				continue
+			}
+
+			assigneeIDs := []string{}
+			for _, personID := range properties.personIDs {
+				if userID, _ := values[personID].(string); userID != "" {
+					assigneeIDs = append(assigneeIDs, userID)
+				}
+			}
+
+			a.sendDueDateReminders(ctx, c, card, dueAt, assigneeIDs)
+		}
+	}
+
+	return nil
+}
+
+// sendDueDateReminders reminds the assignees of the card that haven't
+// been reminded of its due date yet.
+func (a *App) sendDueDateReminders(ctx context.Context, c store.Container, card model.Block, dueAt int64, assigneeIDs []string) {
+	if len(assigneeIDs) == 0 {
+		return
+	}
+
+	sent, err := a.store.GetSentReminderUserIDs(card.ID, dueAt)
+	if err != nil {
+		a.logger.Error("Unable to get the sent reminders", mlog.String("cardID", card.ID), mlog.Err(err))
+		return
+	}
+	alreadySent := map[string]bool{}
+	for _, userID := range sent {
+		alreadySent[userID] = true
+	}
+
+	var permalink string
+	for _, userID := range assigneeIDs {
+		if alreadySent[userID] {
+			continue
+		}
+		alreadySent[userID] = true
+
+		now := utils.GetMillis()
+		err := a.store.InsertReminderSent(model.ReminderSent{
+			CardID:   card.ID,
+			UserID:   userID,
+			DueAt:    dueAt,
+			CreateAt: now,
+		})
+		if err != nil {
+			a.logger.Error("Unable to record the reminder", mlog.String("cardID", card.ID), mlog.Err(err))
+			continue
+		}
+
+		notification := model.Notification{
+			ID:          utils.CreateGUID(),
+			Type:        model.NotificationTypeDueDate,
+			WorkspaceID: c.WorkspaceID,
+			UserID:      userID,
+			BoardID:     card.RootID,
+			CardID:      card.ID,
+			BlockID:     card.ID,
+			CreateAt:    now,
+		}
+		if err := a.store.InsertNotification(notification); err != nil {
+			a.logger.Error("Unable to store the notification", mlog.String("cardID", card.ID), mlog.Err(err))
+			continue
+		}
+
+		if a.notifier == nil {
+			continue
+		}
+
+		if permalink == "" {
+			permalink = a.cardPermalink(ctx, c, card.RootID, card.ID)
+		}
+
+		err = a.notifier.NotifyDueDate(notify.DueDateReminder{
+			Notification: notification,
+			CardTitle:    card.Title,
+			DueAt:        dueAt,
+			Permalink:    permalink,
+		})
+		if err != nil {
+			a.logger.Error("Unable to send the reminder", mlog.String("userID", userID), mlog.Err(err))
+		}
+	}
+}
+
+// getDueDateProperties returns the IDs of the date property with the
+// given name and of the person properties of the board.
+func getDueDateProperties(board model.Block, propertyName string) (dueDateProperties, bool) {
+	properties := dueDateProperties{}
+
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		property, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := property["id"].(string)
+		name, _ := property["name"].(string)
+		switch property["type"] {
+		case "date":
+			if properties.dueDateID == "" && strings.EqualFold(strings.TrimSpace(name), propertyName) {
+				properties.dueDateID = id
+			}
+		case "person":
+			properties.personIDs = append(properties.personIDs, id)
+		}
+	}
+
+	return properties, properties.dueDateID != "" && len(properties.personIDs) > 0
+}
+
+// dateProperty is the value of a date property.
+type dateProperty struct {
+	From        int64 `json:"from"`
+	To          int64 `json:"to"`
+	IncludeTime bool  `json:"includeTime"`
+}
+
+// parseDateProperty parses the value of a date property, either a
+// timestamp or a JSON encoded date range.
+func parseDateProperty(value interface{}) (dateProperty, bool) {
+	var date dateProperty
+
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return date, false
+	}
+
+	if timestamp, err := strconv.ParseInt(str, 10, 64); err == nil {
+		date.From = timestamp
+		return date, true
+	}
+
+	if err := json.Unmarshal([]byte(str), &date); err != nil {
+		return date, false
+	}
+	return date, date.From != 0 || date.To != 0
+}
+
+// parseDateValue returns the due date of a date property value, the
+// end of the range for date ranges. It returns 0 if the value isn't a
+// date.
+func parseDateValue(value interface{}) int64 {
+	date, ok := parseDateProperty(value)
+	if !ok {
+		return 0
+	}
+	if date.To != 0 {
+		return date.To
+	}
+	return date.From
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDateValue(t *testing.T) {
+	require.Equal(t, int64(0), parseDateValue(nil))
+	require.Equal(t, int64(0), parseDateValue(""))
+	require.Equal(t, int64(0), parseDateValue("not a date"))
+	require.Equal(t, int64(1000), parseDateValue("1000"))
+	require.Equal(t, int64(1000), parseDateValue(`{"from":1000}`))
+	require.Equal(t, int64(2000), parseDateValue(`{"from":1000,"to":2000}`))
+}
+
+func TestSendDueDateReminders(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	th.App.config.ServerRoot = "http://localhost:8000"
+	th.App.config.DueDatePropertyName = "Due date"
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := model.Block{
+		ID:     "board-1",
+		RootID: "board-1",
+		Type:   "board",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "created", "name": "Created", "type": "date"},
+				map[string]interface{}{"id": "due", "name": "Due Date", "type": "date"},
+				map[string]interface{}{"id": "owner", "name": "Owner", "type": "person"},
+				map[string]interface{}{"id": "reviewer", "name": "Reviewer", "type": "person"},
+			},
+		},
+	}
+	template := board
+	template.ID = "template-1"
+	template.Fields = map[string]interface{}{
+		"isTemplate":     true,
+		"cardProperties": board.Fields["cardProperties"],
+	}
+
+	soon := utils.GetMillis() + time.Hour.Milliseconds()
+	later := utils.GetMillis() + 48*time.Hour.Milliseconds()
+	card := func(id, rootID string, due interface{}) model.Block {
+		return model.Block{
+			ID:       id,
+			ParentID: rootID,
+			RootID:   rootID,
+			Type:     "card",
+			Title:    "Card " + id,
+			Fields: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"created":  fmt.Sprint(soon),
+					"due":      due,
+					"owner":    "user-1",
+					"reviewer": "user-2",
+				},
+			},
+		}
+	}
+	cards := []model.Block{
+		card("card-soon", "board-1", fmt.Sprintf(`{"from":%d}`, soon)),
+		card("card-later", "board-1", fmt.Sprint(later)),
+		card("card-no-date", "board-1", nil),
+		card("card-template", "template-1", fmt.Sprint(soon)),
+	}
+
+	notifier := &testNotifier{}
+	th.App.notifier = notifier
+	defer func() { th.App.notifier = nil }()
+
+	th.Store.EXPECT().GetBoardWorkspaceIDs().Return([]string{"0"}, nil)
+	th.Store.EXPECT().GetBlocksWithType(gomock.Any(), gomock.Eq(container), gomock.Eq("board")).Return([]model.Block{board, template}, nil)
+	th.Store.EXPECT().GetBlocksWithType(gomock.Any(), gomock.Eq(container), gomock.Eq("card")).Return(cards, nil)
+	th.Store.EXPECT().GetSentReminderUserIDs(gomock.Eq("card-soon"), gomock.Eq(soon)).Return([]string{"user-2"}, nil)
+	th.Store.EXPECT().InsertReminderSent(gomock.Any()).DoAndReturn(func(reminder model.ReminderSent) error {
+		require.Equal(t, "card-soon", reminder.CardID)
+		require.Equal(t, "user-1", reminder.UserID)
+		require.Equal(t, soon, reminder.DueAt)
+		return nil
+	})
+	th.Store.EXPECT().InsertNotification(gomock.Any()).Return(nil)
+	th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("view")).
+		Return([]model.Block{{ID: "view-1"}}, nil)
+
+	err := th.App.SendDueDateReminders(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, notifier.reminders, 1)
+	reminder := notifier.reminders[0]
+	require.Equal(t, model.NotificationTypeDueDate, reminder.Type)
+	require.Equal(t, "user-1", reminder.UserID)
+	require.Equal(t, "card-soon", reminder.CardID)
+	require.Equal(t, "board-1", reminder.BoardID)
+	require.Equal(t, "Card card-soon", reminder.CardTitle)
+	require.Equal(t, soon, reminder.DueAt)
+	require.Equal(t, "http://localhost:8000/board-1/view-1/card-soon", reminder.Permalink)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
 )
 
 func (a *App) GetSharing(c store.Container, rootID string) (*model.Sharing, error) {
@@ -16,9 +17,57 @@ func (a *App) GetSharing(c store.Container, rootID string) (*model.Sharing, erro
 	if err != nil {
 		return nil, err
 	}
+
+	tokens, err := a.store.GetSharingTokens(c, rootID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) > 0 {
+		sharing.Tokens = tokens
+	}
+
 	return sharing, nil
 }
 
 func (a *App) UpsertSharing(c store.Container, sharing model.Sharing) error {
-	return a.store.UpsertSharing(c, sharing)
+	if err := a.store.UpsertSharing(c, sharing); err != nil {
+		return err
+	}
+
+	a.recordAuditEntry(model.AuditActionUpsertSharing, sharing.ModifiedBy, c.WorkspaceID, sharing.ID, map[string]interface{}{
+		"enabled": sharing.Enabled,
+	})
+	return nil
+}
+
+// RegenerateSharingToken creates a new access token for the root block,
+// revoking previousToken if it's not empty.
+func (a *App) RegenerateSharingToken(c store.Container, rootID string, expireAt int64, previousToken string, userID string) (*model.SharingToken, error) {
+	token := model.SharingToken{
+		Token:     utils.CreateGUID(),
+		RootID:    rootID,
+		CreatedBy: userID,
+		CreateAt:  utils.GetMillis(),
+		ExpireAt:  expireAt,
+	}
+
+	if err := a.store.RegenerateSharingToken(c, token, previousToken); err != nil {
+		return nil, err
+	}
+
+	// the tokens give access to the board, so they aren't recorded
+	a.recordAuditEntry(model.AuditActionRegenerateSharingToken, userID, c.WorkspaceID, rootID, map[string]interface{}{
+		"expireAt":        expireAt,
+		"revokedPrevious": previousToken != "",
+	})
+	return &token, nil
+}
+
+func (a *App) RevokeSharingToken(c store.Container, rootID string, token string, userID string) error {
+	if err := a.store.RevokeSharingToken(c, rootID, token); err != nil {
+		return err
+	}
+
+	a.recordAuditEntry(model.AuditActionRevokeSharingToken, userID, c.WorkspaceID, rootID, nil)
+	return nil
 }
@@ -30,6 +30,7 @@ func TestGetSharing(t *testing.T) {
 			UpdateAt:   time.Now().Unix(),
 		}
 		th.Store.EXPECT().GetSharing(gomock.Eq(container), gomock.Eq("test-id")).Return(want, nil)
+		th.Store.EXPECT().GetSharingTokens(gomock.Eq(container), gomock.Eq("test-id")).Return([]model.SharingToken{}, nil)
 
 		result, err := th.App.GetSharing(container, "test-id")
 		require.NoError(t, err)
@@ -38,6 +39,20 @@ func TestGetSharing(t *testing.T) {
 		require.NotNil(t, th.App)
 	})
 
+	t.Run("should include the sharing tokens", func(t *testing.T) {
+		sharing := &model.Sharing{
+			ID:      "test-id",
+			Enabled: true,
+		}
+		tokens := []model.SharingToken{{Token: "token-1", RootID: "test-id"}, {Token: "token-2", RootID: "test-id"}}
+		th.Store.EXPECT().GetSharing(gomock.Eq(container), gomock.Eq("test-id")).Return(sharing, nil)
+		th.Store.EXPECT().GetSharingTokens(gomock.Eq(container), gomock.Eq("test-id")).Return(tokens, nil)
+
+		result, err := th.App.GetSharing(container, "test-id")
+		require.NoError(t, err)
+		require.Equal(t, tokens, result.Tokens)
+	})
+
 	t.Run("should fail to get a sharing", func(t *testing.T) {
 		th.Store.EXPECT().GetSharing(gomock.Eq(container), gomock.Eq("test-id")).Return(
 			nil,
@@ -79,6 +94,7 @@ func TestUpsertSharing(t *testing.T) {
 
 	t.Run("should success to upsert sharing", func(t *testing.T) {
 		th.Store.EXPECT().UpsertSharing(gomock.Eq(container), gomock.Eq(sharing)).Return(nil)
+		expectAuditEntry(th, model.AuditActionUpsertSharing, "otherid", sharing.ID)
 		err := th.App.UpsertSharing(container, sharing)
 
 		require.NoError(t, err)
@@ -92,3 +108,40 @@ func TestUpsertSharing(t *testing.T) {
 		require.Equal(t, "sharing not found", err.Error())
 	})
 }
+
+func TestRegenerateSharingToken(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: utils.CreateGUID(),
+	}
+
+	t.Run("should create a new token", func(t *testing.T) {
+		var stored model.SharingToken
+		th.Store.EXPECT().RegenerateSharingToken(gomock.Eq(container), gomock.Any(), gomock.Eq("old-token")).
+			DoAndReturn(func(_ st.Container, token model.SharingToken, _ string) error {
+				stored = token
+				return nil
+			})
+		expectAuditEntry(th, model.AuditActionRegenerateSharingToken, "user-id", "root-id")
+
+		token, err := th.App.RegenerateSharingToken(container, "root-id", 1234, "old-token", "user-id")
+		require.NoError(t, err)
+		require.Equal(t, stored, *token)
+		require.NotEmpty(t, token.Token)
+		require.NotEqual(t, "old-token", token.Token)
+		require.Equal(t, "root-id", token.RootID)
+		require.Equal(t, "user-id", token.CreatedBy)
+		require.EqualValues(t, 1234, token.ExpireAt)
+		require.NotZero(t, token.CreateAt)
+	})
+
+	t.Run("should fail to create a token", func(t *testing.T) {
+		th.Store.EXPECT().RegenerateSharingToken(gomock.Eq(container), gomock.Any(), gomock.Eq("")).Return(errors.New("error"))
+
+		token, err := th.App.RegenerateSharingToken(container, "root-id", 0, "", "user-id")
+		require.Error(t, err)
+		require.Nil(t, token)
+	})
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// ErrInvalidSubscription is returned when subscribing to a block that
+// isn't a card or a board.
+var ErrInvalidSubscription = errors.New("only the cards and the boards can be watched")
+
+// CreateSubscription subscribes the user to the changes of the card or
+// the board. Subscribing again keeps the existing subscription.
+func (a *App) CreateSubscription(ctx context.Context, c store.Container, blockID, userID string) (*model.Subscription, error) {
+	block, err := a.store.GetBlock(ctx, c, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, sql.ErrNoRows
+	}
+	if block.Type != "card" && block.Type != "board" {
+		return nil, fmt.Errorf("%w: %s is a %s", ErrInvalidSubscription, blockID, block.Type)
+	}
+	if err := a.CheckBlockAccess(ctx, c, userID, blockID, model.BoardRoleViewer); err != nil {
+		return nil, err
+	}
+
+	subscription := model.Subscription{
+		BlockType:    block.Type,
+		BlockID:      blockID,
+		WorkspaceID:  c.WorkspaceID,
+		SubscriberID: userID,
+		CreateAt:     utils.GetMillis(),
+	}
+	if err := a.store.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// DeleteSubscription unsubscribes the user from the block.
+func (a *App) DeleteSubscription(ctx context.Context, c store.Container, blockID, userID string) error {
+	return a.store.DeleteSubscription(ctx, c, blockID, userID)
+}
+
+func (a *App) GetSubscriptionsForUser(ctx context.Context, c store.Container, userID string) ([]model.Subscription, error) {
+	return a.store.GetSubscriptionsForUser(ctx, c, userID)
+}
+
+// watchedBlockIDs returns the IDs of the blocks whose subscribers are
+// notified of a change of the block, the most specific first: the block
+// itself, its card and its board.
+func watchedBlockIDs(block model.Block) []string {
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, id := range []string{block.ID, block.ParentID, block.RootID} {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// notifySubscribers notifies the users watching the changed blocks, or
+// their cards or boards, other than the author of the change. The
+// subscriptions are looked up before returning, and the notifications
+// delivered in the background.
+func (a *App) notifySubscribers(ctx context.Context, c store.Container, blocks []model.Block, authorID string) {
+	blockIDs := []string{}
+	seen := map[string]bool{}
+	for i := range blocks {
+		for _, id := range watchedBlockIDs(blocks[i]) {
+			if !seen[id] {
+				seen[id] = true
+				blockIDs = append(blockIDs, id)
+			}
+		}
+	}
+	if len(blockIDs) == 0 {
+		return
+	}
+
+	subscriptions, err := a.store.GetSubscriptions(ctx, c, blockIDs)
+	if err != nil {
+		a.logger.Error("Unable to get the subscriptions", mlog.Err(err))
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	// the request context is done once the response is written
+	go a.deliverSubscriptionNotifications(context.Background(), c, blocks, subscriptions, authorID)
+}
+
+// deliverSubscriptionNotifications notifies each subscriber once of the
+// changed blocks, through their most specific subscription. A
+// subscriber already notified within the notification window isn't
+// notified again, so that consecutive edits collapse into one
+// notification.
+func (a *App) deliverSubscriptionNotifications(ctx context.Context, c store.Container, blocks []model.Block, subscriptions []model.Subscription, authorID string) {
+	byBlock := map[string][]model.Subscription{}
+	for _, subscription := range subscriptions {
+		byBlock[subscription.BlockID] = append(byBlock[subscription.BlockID], subscription)
+	}
+
+	window := a.config.SubscriptionNotificationWindow
+	if window <= 0 {
+		window = config.DefaultSubscriptionNotificationWindow
+	}
+
+	notified := map[string]bool{authorID: true}
+	var authorUsername string
+	for _, block := range blocks {
+		for _, watchedID := range watchedBlockIDs(block) {
+			for _, subscription := range byBlock[watchedID] {
+				if notified[subscription.SubscriberID] {
+					continue
+				}
+				notified[subscription.SubscriberID] = true
+
+				if !a.canNotifySubscriber(ctx, c, subscription.SubscriberID, block.RootID) {
+					continue
+				}
+
+				now := utils.GetMillis()
+				claimed, err := a.store.ClaimSubscriptionNotification(ctx, subscription.BlockID, subscription.SubscriberID, now, now-window*1000)
+				if err != nil {
+					a.logger.Error("Unable to throttle the subscription notification", mlog.String("blockID", subscription.BlockID), mlog.Err(err))
+					continue
+				}
+				if !claimed {
+					continue
+				}
+
+				cardID := ""
+				if subscription.BlockType == "card" {
+					cardID = subscription.BlockID
+				}
+				notification := model.Notification{
+					ID:          utils.CreateGUID(),
+					Type:        model.NotificationTypeSubscription,
+					WorkspaceID: c.WorkspaceID,
+					UserID:      subscription.SubscriberID,
+					AuthorID:    authorID,
+					BoardID:     block.RootID,
+					CardID:      cardID,
+					BlockID:     block.ID,
+					CreateAt:    now,
+				}
+				if err := a.store.InsertNotification(notification); err != nil {
+					a.logger.Error("Unable to store the notification", mlog.String("blockID", block.ID), mlog.Err(err))
+					continue
+				}
+
+				if a.notifier == nil {
+					continue
+				}
+
+				if authorUsername == "" {
+					if author, err := a.store.GetUserByID(authorID); err == nil && author != nil {
+						authorUsername = author.Username
+					}
+				}
+				permalink := a.boardPermalink(c, block.RootID)
+				if cardID != "" {
+					permalink = a.cardPermalink(ctx, c, block.RootID, cardID)
+				}
+				title := block.Title
+				if watched, err := a.store.GetBlock(ctx, c, subscription.BlockID); err == nil && watched != nil {
+					title = watched.Title
+				}
+
+				err = a.notifier.NotifyBlockChange(notify.BlockChange{
+					Notification:   notification,
+					AuthorUsername: authorUsername,
+					Title:          title,
+					Permalink:      permalink,
+				})
+				if err != nil {
+					a.logger.Error("Unable to notify the subscriber", mlog.String("userID", subscription.SubscriberID), mlog.Err(err))
+				}
+			}
+		}
+	}
+}
+
+// canNotifySubscriber returns true if the subscriber still has access to
+// the workspace and to the board.
+func (a *App) canNotifySubscriber(ctx context.Context, c store.Container, subscriberID, boardID string) bool {
+	if !a.DoesUserHaveWorkspaceAccess(ctx, subscriberID, c.WorkspaceID) {
+		return false
+	}
+	roles, err := a.getUserBoardRoles(c, subscriberID)
+	if err != nil {
+		a.logger.Error("Unable to get the board roles of the subscriber", mlog.String("userID", subscriberID), mlog.Err(err))
+		return false
+	}
+	return roles.check(model.BoardRoleViewer, boardID) == nil
+}
+
+// boardPermalink returns the link to the board.
+func (a *App) boardPermalink(c store.Container, boardID string) string {
+	link := a.config.ServerRoot
+	if c.WorkspaceID != "0" {
+		link += "/workspace/" + c.WorkspaceID
+	}
+	return fmt.Sprintf("%s/%s", link, boardID)
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSubscription(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("should only watch cards and boards", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("view-1")).
+			Return(&model.Block{ID: "view-1", RootID: "board-1", Type: "view"}, nil)
+
+		_, err := th.App.CreateSubscription(ctx, container, "view-1", "user-1")
+		require.ErrorIs(t, err, ErrInvalidSubscription)
+	})
+}
+
+func TestDeliverSubscriptionNotifications(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectOpenBoards()
+
+	th.App.config.ServerRoot = "http://localhost:8000"
+	th.App.config.SubscriptionNotificationWindow = 60
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+	comment := model.Block{ID: "comment-1", ParentID: "card-1", RootID: "board-1", Type: "comment"}
+	subscriptions := []model.Subscription{
+		{BlockType: "board", BlockID: "board-1", SubscriberID: "alice-id"},
+		{BlockType: "card", BlockID: "card-1", SubscriberID: "alice-id"},
+		{BlockType: "board", BlockID: "board-1", SubscriberID: "bob-id"},
+		{BlockType: "card", BlockID: "card-1", SubscriberID: "author-id"},
+	}
+
+	t.Run("should notify each subscriber once but the author", func(t *testing.T) {
+		notifier := &testNotifier{}
+		th.App.notifier = notifier
+		defer func() { th.App.notifier = nil }()
+
+		th.Store.EXPECT().HasWorkspaceAccess(gomock.Any(), gomock.Any(), gomock.Eq("0")).Return(true, nil).Times(2)
+		th.Store.EXPECT().ClaimSubscriptionNotification(gomock.Any(), gomock.Eq("card-1"), gomock.Eq("alice-id"), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, notifiedAt, notifiedBefore int64) (bool, error) {
+				require.Equal(t, int64(60*1000), notifiedAt-notifiedBefore)
+				return true, nil
+			})
+		th.Store.EXPECT().ClaimSubscriptionNotification(gomock.Any(), gomock.Eq("board-1"), gomock.Eq("bob-id"), gomock.Any(), gomock.Any()).
+			Return(true, nil)
+
+		var inserted []model.Notification
+		th.Store.EXPECT().InsertNotification(gomock.Any()).DoAndReturn(func(notification model.Notification) error {
+			inserted = append(inserted, notification)
+			return nil
+		}).Times(2)
+		th.Store.EXPECT().GetBlocksWithParentAndType(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq("view")).
+			Return([]model.Block{{ID: "view-1"}}, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).
+			Return(&model.Block{ID: "card-1", Title: "Card 1"}, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).
+			Return(&model.Block{ID: "board-1", Title: "Board 1"}, nil)
+
+		th.App.deliverSubscriptionNotifications(ctx, container, []model.Block{comment}, subscriptions, "author-id")
+
+		require.Len(t, inserted, 2)
+		require.Equal(t, "alice-id", inserted[0].UserID)
+		require.Equal(t, model.NotificationTypeSubscription, inserted[0].Type)
+		require.Equal(t, "card-1", inserted[0].CardID)
+		require.Equal(t, "comment-1", inserted[0].BlockID)
+		require.Equal(t, "bob-id", inserted[1].UserID)
+		require.Empty(t, inserted[1].CardID)
+
+		require.Len(t, notifier.changes, 2)
+		require.Equal(t, "Card 1", notifier.changes[0].Title)
+		require.Equal(t, "http://localhost:8000/board-1/view-1/card-1", notifier.changes[0].Permalink)
+		require.Equal(t, "Board 1", notifier.changes[1].Title)
+		require.Equal(t, "http://localhost:8000/board-1", notifier.changes[1].Permalink)
+	})
+
+	t.Run("should not notify again within the window", func(t *testing.T) {
+		th.Store.EXPECT().HasWorkspaceAccess(gomock.Any(), gomock.Any(), gomock.Eq("0")).Return(true, nil).Times(2)
+		th.Store.EXPECT().ClaimSubscriptionNotification(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(false, nil).Times(2)
+
+		th.App.deliverSubscriptionNotifications(ctx, container, []model.Block{comment}, subscriptions, "author-id")
+	})
+
+	t.Run("should not notify the subscribers who lost access", func(t *testing.T) {
+		th.Store.EXPECT().HasWorkspaceAccess(gomock.Any(), gomock.Eq("alice-id"), gomock.Eq("0")).Return(false, nil)
+		th.Store.EXPECT().HasWorkspaceAccess(gomock.Any(), gomock.Eq("bob-id"), gomock.Eq("0")).Return(false, nil)
+
+		th.App.deliverSubscriptionNotifications(ctx, container, []model.Block{comment}, subscriptions, "author-id")
+	})
+}
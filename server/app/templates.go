@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/services/store/sqlstore/initializations"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// globalTemplatesContainer is the container of the templates that are
+// available to every workspace.
+var globalTemplatesContainer = store.Container{
+	WorkspaceID: "0",
+}
+
+// GetGlobalTemplates returns the templates available to every workspace.
+func (a *App) GetGlobalTemplates(ctx context.Context) ([]model.BoardTemplate, error) {
+	return a.store.GetTemplateBoards(ctx, globalTemplatesContainer)
+}
+
+// CreateGlobalTemplate copies the board and all its blocks as a new
+// global template. It returns the template board, or nil if the board
+// doesn't exist.
+func (a *App) CreateGlobalTemplate(ctx context.Context, c store.Container, boardID string, userID string) (*model.Block, error) {
+	return a.copyBoard(ctx, c, globalTemplatesContainer, boardID, userID, func(board *model.Block) {
+		board.Fields["isTemplate"] = true
+	})
+}
+
+// InitTemplates imports the built-in templates that are missing from
+// the global templates. Templates are identified by the ID of their
+// board, so the ones already imported, even if they were deleted
+// later, are skipped.
+func (a *App) InitTemplates(ctx context.Context) error {
+	var archive model.Archive
+	if err := json.Unmarshal(initializations.MustAsset("templates.json"), &archive); err != nil {
+		return err
+	}
+
+	existingIDs := map[string]bool{}
+	boards, err := a.store.GetBlocksWithType(ctx, globalTemplatesContainer, "board")
+	if err != nil {
+		return err
+	}
+	deletedBlocks, err := a.store.GetDeletedBlocks(ctx, globalTemplatesContainer, 0)
+	if err != nil {
+		return err
+	}
+	for _, block := range append(boards, deletedBlocks...) {
+		existingIDs[block.ID] = true
+	}
+
+	newBlocks := []model.Block{}
+	for _, block := range archive.Blocks {
+		if existingIDs[block.RootID] {
+			continue
+		}
+		a.logger.Trace("insert template block",
+			mlog.String("blockID", block.ID),
+			mlog.String("block_type", block.Type),
+			mlog.String("block_title", block.Title),
+		)
+		newBlocks = append(newBlocks, block)
+	}
+
+	if len(newBlocks) == 0 {
+		return nil
+	}
+
+	a.logger.Debug("Inserting template blocks", mlog.Int("block_count", len(newBlocks)))
+	_, err = a.store.InsertBlocks(ctx, globalTemplatesContainer, newBlocks, "system")
+	return err
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/filestore/mocks"
+)
+
+func TestInitTemplates(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("should import all the templates", func(t *testing.T) {
+		var inserted []model.Block
+		th.Store.EXPECT().GetBlocksWithType(gomock.Any(), gomock.Eq(globalTemplatesContainer), gomock.Eq("board")).Return([]model.Block{}, nil)
+		th.Store.EXPECT().GetDeletedBlocks(gomock.Any(), gomock.Eq(globalTemplatesContainer), gomock.Eq(int64(0))).Return([]model.Block{}, nil)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(globalTemplatesContainer), gomock.Any(), gomock.Eq("system")).
+			DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				inserted = blocks
+				return &model.BlocksUpsertResult{}, nil
+			})
+
+		err := th.App.InitTemplates(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, inserted)
+	})
+
+	t.Run("should skip the existing and deleted templates", func(t *testing.T) {
+		var inserted []model.Block
+		th.Store.EXPECT().GetBlocksWithType(gomock.Any(), gomock.Eq(globalTemplatesContainer), gomock.Eq("board")).
+			Return([]model.Block{{ID: "2bb7dc3d-c36a-4e00-8e0f-a6d31ac053c7"}}, nil)
+		th.Store.EXPECT().GetDeletedBlocks(gomock.Any(), gomock.Eq(globalTemplatesContainer), gomock.Eq(int64(0))).
+			Return([]model.Block{{ID: "3fa520eb-30cd-4852-829a-ba3bc7e88e26"}}, nil)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(globalTemplatesContainer), gomock.Any(), gomock.Eq("system")).
+			DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				inserted = blocks
+				return &model.BlocksUpsertResult{}, nil
+			})
+
+		err := th.App.InitTemplates(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, inserted)
+		for _, block := range inserted {
+			require.NotEqual(t, "2bb7dc3d-c36a-4e00-8e0f-a6d31ac053c7", block.RootID)
+			require.NotEqual(t, "3fa520eb-30cd-4852-829a-ba3bc7e88e26", block.RootID)
+		}
+	})
+}
+
+func TestCreateGlobalTemplate(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectNoSubscriptions()
+
+	container := st.Container{
+		WorkspaceID: "workspace-1",
+	}
+
+	t.Run("should copy the board as a global template", func(t *testing.T) {
+		mockedFileBackend := &mocks.FileBackend{}
+		mockedFileBackend.On("CopyFile", mock.Anything, mock.Anything).Return(nil)
+		th.App.filesBackend = mockedFileBackend
+
+		blocks := []model.Block{
+			{ID: "board-1", RootID: "board-1", Type: "board", Title: "Board"},
+			{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card"},
+		}
+		var inserted []model.Block
+		th.expectTx()
+		th.Store.EXPECT().GetBlocksWithRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(blocks, nil)
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), gomock.Eq("0")).Return(nil, sql.ErrNoRows)
+		th.Store.EXPECT().InsertBlocks(gomock.Any(), gomock.Eq(globalTemplatesContainer), gomock.Any(), gomock.Eq("user-id-1")).
+			DoAndReturn(func(_ context.Context, _ st.Container, blocks []model.Block, _ string) (*model.BlocksUpsertResult, error) {
+				inserted = blocks
+				return &model.BlocksUpsertResult{}, nil
+			})
+
+		template, err := th.App.CreateGlobalTemplate(ctx, container, "board-1", "user-id-1")
+		require.NoError(t, err)
+		require.NotNil(t, template)
+		require.NotEqual(t, "board-1", template.ID)
+		require.Equal(t, "Board", template.Title)
+		require.Equal(t, true, template.Fields["isTemplate"])
+		require.Len(t, inserted, 2)
+	})
+
+	t.Run("should return nil if the board doesn't exist", func(t *testing.T) {
+		th.expectTx()
+		th.Store.EXPECT().GetBlocksWithRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return([]model.Block{}, nil)
+
+		template, err := th.App.CreateGlobalTemplate(ctx, container, "board-1", "user-id-1")
+		require.NoError(t, err)
+		require.Nil(t, template)
+	})
+}
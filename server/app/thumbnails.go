@@ -0,0 +1,161 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/thumbnail"
+
+	"github.com/mattermost/mattermost-server/v6/shared/filestore"
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+const (
+	// thumbnailSyncMaxSize is the size of the largest image whose
+	// thumbnail is generated during the upload, the thumbnails of the
+	// larger ones being generated by GenerateThumbnails.
+	thumbnailSyncMaxSize = 1024 * 1024
+
+	// thumbnailSuffix is appended to the path of an image to get the path
+	// of its thumbnail.
+	thumbnailSuffix = ".thumbnail.jpg"
+)
+
+// thumbnailFileExtensions are the extensions of the images that have a
+// thumbnail.
+var thumbnailFileExtensions = map[string]bool{
+	".gif":  true,
+	".jpeg": true,
+	".jpg":  true,
+	".png":  true,
+}
+
+// thumbnailQueue is the uploaded images whose thumbnail is to be
+// generated by GenerateThumbnails.
+type thumbnailQueue struct {
+	mutex sync.Mutex
+	files []model.FileInfo
+}
+
+func newThumbnailQueue() *thumbnailQueue {
+	return &thumbnailQueue{}
+}
+
+func (q *thumbnailQueue) add(info model.FileInfo) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.files = append(q.files, info)
+}
+
+func (q *thumbnailQueue) take() []model.FileInfo {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	files := q.files
+	q.files = nil
+	return files
+}
+
+// isThumbnailPath tells if the path of a stored file is the one of a
+// thumbnail.
+func isThumbnailPath(filePath string) bool {
+	return strings.HasSuffix(filePath, thumbnailSuffix)
+}
+
+// queueThumbnail generates the thumbnail of the uploaded image right
+// away if it's small, or queues it. A failure leaves the image without a
+// thumbnail, the original being served instead.
+func (a *App) queueThumbnail(info model.FileInfo) {
+	if !thumbnailFileExtensions[strings.ToLower(filepath.Ext(info.ID))] {
+		return
+	}
+	if info.Size > thumbnailSyncMaxSize {
+		a.thumbnails.add(info)
+		return
+	}
+	if err := a.generateThumbnail(info); err != nil {
+		a.logger.Warn("Unable to generate the thumbnail", mlog.String("path", info.Path), mlog.Err(err))
+	}
+}
+
+// GenerateThumbnails generates the thumbnails of the queued images.
+func (a *App) GenerateThumbnails(ctx context.Context) error {
+	for _, info := range a.thumbnails.take() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := a.generateThumbnail(info); err != nil {
+			a.logger.Warn("Unable to generate the thumbnail", mlog.String("path", info.Path), mlog.Err(err))
+		}
+	}
+	return nil
+}
+
+// generateThumbnail stores the thumbnail of the image next to it and
+// records its path.
+func (a *App) generateThumbnail(info model.FileInfo) error {
+	reader, err := a.filesBackend.Reader(info.Path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var buf bytes.Buffer
+	if err = thumbnail.Generate(reader, &buf, thumbnail.MaxEdge); err != nil {
+		return err
+	}
+
+	thumbnailPath := info.Path + thumbnailSuffix
+	if _, err := a.filesBackend.WriteFile(&buf, thumbnailPath); err != nil {
+		return fmt.Errorf("unable to store the thumbnail in the files storage: %w", err)
+	}
+	if err := a.store.SetFileThumbnailPath(info.ID, thumbnailPath); err != nil {
+		a.removeThumbnail(info.Path)
+		return err
+	}
+	return nil
+}
+
+// GetThumbnailReader returns the thumbnail of the image and the time it
+// was generated, or a nil reader if the image has no thumbnail yet.
+func (a *App) GetThumbnailReader(workspaceID, rootID, filename string) (filestore.ReadCloseSeeker, time.Time, error) {
+	info, err := a.GetFileInfo(workspaceID, rootID, filename)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if info == nil || info.ThumbnailPath == "" {
+		return nil, time.Time{}, nil
+	}
+
+	modTime, err := a.filesBackend.FileModTime(info.ThumbnailPath)
+	if err != nil {
+		// the thumbnail may have been removed from the files storage
+		a.logger.Warn("GetThumbnailReader: unable to get the thumbnail time", mlog.String("path", info.ThumbnailPath), mlog.Err(err))
+		return nil, time.Time{}, nil
+	}
+	reader, err := a.filesBackend.Reader(info.ThumbnailPath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return reader, modTime, nil
+}
+
+// removeThumbnail removes the thumbnail of an image, if it has one.
+func (a *App) removeThumbnail(imagePath string) {
+	if !thumbnailFileExtensions[strings.ToLower(filepath.Ext(imagePath))] {
+		return
+	}
+	thumbnailPath := imagePath + thumbnailSuffix
+	exists, err := a.filesBackend.FileExists(thumbnailPath)
+	if err == nil && exists {
+		err = a.filesBackend.RemoveFile(thumbnailPath)
+	}
+	if err != nil {
+		a.logger.Warn("Unable to remove the thumbnail", mlog.String("path", thumbnailPath), mlog.Err(err))
+	}
+}
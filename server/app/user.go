@@ -1,7 +1,132 @@
 package app
 
-import "github.com/mattermost/focalboard/server/model"
+import (
+	"context"
+	"database/sql"
 
-func (a *App) GetWorkspaceUsers(workspaceID string) ([]*model.User, error) {
-	return a.store.GetUsersByWorkspace(workspaceID)
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/pkg/errors"
+)
+
+// ErrCannotDeactivateSelf is returned when an admin deactivates their
+// own user, which would leave them unable to undo it.
+var ErrCannotDeactivateSelf = errors.New("admins can't deactivate their own user")
+
+// userSearchLimit is the maximum number of users returned by a search.
+const userSearchLimit = 20
+
+// SearchWorkspaceUsers returns the first users with access to the
+// workspace whose username, or nickname in plugin mode, starts with the
+// prefix. The deactivated users and the bots are left out.
+func (a *App) SearchWorkspaceUsers(ctx context.Context, workspaceID, prefix string) ([]model.UserSearchResult, error) {
+	return a.store.SearchUsersByWorkspace(ctx, workspaceID, prefix, userSearchLimit)
+}
+
+// GetWorkspaceUsers returns the users of the workspace. The guests are
+// only returned with the board they can view, if one is given.
+func (a *App) GetWorkspaceUsers(workspaceID, boardID string) ([]*model.User, error) {
+	users, err := a.store.GetUsersByWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles map[string]string
+	if boardID != "" {
+		if roles, err = a.auth.GetBoardRoles(store.Container{WorkspaceID: workspaceID}, boardID); err != nil {
+			return nil, err
+		}
+	}
+
+	listed := make([]*model.User, 0, len(users))
+	for _, user := range users {
+		if !user.IsGuest || model.BoardRoleAllows(roles[user.ID], model.BoardRoleViewer) {
+			listed = append(listed, user)
+		}
+	}
+	return listed, nil
+}
+
+// IsUserAdmin tells if the user is active and administers the server.
+func (a *App) IsUserAdmin(userID string) (bool, error) {
+	user, err := a.store.GetUserByID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.IsAdmin, nil
+}
+
+// GetUserByUsername returns the active user with the username.
+func (a *App) GetUserByUsername(username string) (*model.User, error) {
+	return a.store.GetUserByUsername(username)
+}
+
+// GetUsers returns a page of the users of the server, including the
+// deactivated ones.
+func (a *App) GetUsers(opts model.QueryUsersOptions) ([]*model.User, error) {
+	return a.store.GetUsers(opts)
+}
+
+// DeactivateUser deactivates the user and logs them out of all their
+// sessions. The deactivated users can't log in, their access tokens
+// don't authenticate, and they aren't listed among the users of the
+// workspaces.
+func (a *App) DeactivateUser(adminID, userID string) error {
+	if adminID == userID {
+		return ErrCannotDeactivateSelf
+	}
+
+	if err := a.store.UpdateUserActive(userID, false); err != nil {
+		return err
+	}
+	if err := a.store.DeleteSessionsForUser(userID); err != nil {
+		return errors.Wrap(err, "unable to delete the sessions")
+	}
+
+	a.recordAuditEntry(model.AuditActionDeactivateUser, adminID, "", userID, nil)
+	return nil
+}
+
+// ActivateUser activates a deactivated user again.
+func (a *App) ActivateUser(adminID, userID string) error {
+	if err := a.store.UpdateUserActive(userID, true); err != nil {
+		return err
+	}
+
+	a.recordAuditEntry(model.AuditActionActivateUser, adminID, "", userID, nil)
+	return nil
+}
+
+// ResetUserPassword sets the password of the user for an admin, and
+// logs the user out of all their sessions. The passwords of the LDAP
+// users can't be reset.
+func (a *App) ResetUserPassword(adminID, userID, password string) error {
+	passwordSettings := auth.PasswordSettings{
+		MinimumLength: 6,
+	}
+	if err := auth.IsPasswordValid(password, passwordSettings); err != nil {
+		return err
+	}
+
+	user, err := a.store.GetUserByID(userID)
+	if err != nil {
+		return errors.Wrap(err, "unable to get the user")
+	}
+	if isLDAPUser(user) {
+		return ErrLDAPPasswordChange
+	}
+
+	if err := a.store.UpdateUserPasswordByID(userID, auth.HashPassword(password)); err != nil {
+		return err
+	}
+	if err := a.store.DeleteSessionsForUser(userID); err != nil {
+		return errors.Wrap(err, "unable to delete the sessions")
+	}
+
+	a.recordAuditEntry(model.AuditActionAdminResetPassword, adminID, "", userID, nil)
+	return nil
 }
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeactivateUser(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("should deactivate the user and delete their sessions", func(t *testing.T) {
+		th.Store.EXPECT().UpdateUserActive(gomock.Eq("user-id-2"), gomock.Eq(false)).Return(nil)
+		th.Store.EXPECT().DeleteSessionsForUser(gomock.Eq("user-id-2")).Return(nil)
+		expectAuditEntry(th, model.AuditActionDeactivateUser, "admin-id", "user-id-2")
+
+		require.NoError(t, th.App.DeactivateUser("admin-id", "user-id-2"))
+	})
+
+	t.Run("should not deactivate the admin's own user", func(t *testing.T) {
+		err := th.App.DeactivateUser("admin-id", "admin-id")
+		require.ErrorIs(t, err, ErrCannotDeactivateSelf)
+	})
+}
+
+func TestResetUserPassword(t *testing.T) {
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	t.Run("should reject a short password", func(t *testing.T) {
+		require.Error(t, th.App.ResetUserPassword("admin-id", "user-id-2", "short"))
+	})
+
+	t.Run("should set the password and delete the sessions", func(t *testing.T) {
+		th.Store.EXPECT().GetUserByID("user-id-2").Return(&model.User{ID: "user-id-2"}, nil)
+		th.Store.EXPECT().UpdateUserPasswordByID(gomock.Eq("user-id-2"), gomock.Any()).Return(nil)
+		th.Store.EXPECT().DeleteSessionsForUser(gomock.Eq("user-id-2")).Return(nil)
+		expectAuditEntry(th, model.AuditActionAdminResetPassword, "admin-id", "user-id-2")
+
+		require.NoError(t, th.App.ResetUserPassword("admin-id", "user-id-2", "newPassword"))
+	})
+}
@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// viewFilterConditions are the conditions of the view filters applied by
+// the server, by type of the card properties they apply to. The filters
+// on the other properties, like the multiSelect ones, are left to the
+// clients.
+var viewFilterConditions = map[string]map[string]bool{
+	"select": {
+		model.FilterConditionIncludes:    true,
+		model.FilterConditionNotIncludes: true,
+		model.FilterConditionIsEmpty:     true,
+		model.FilterConditionIsNotEmpty:  true,
+	},
+	"person": {
+		model.FilterConditionIncludes:    true,
+		model.FilterConditionNotIncludes: true,
+		model.FilterConditionIsEmpty:     true,
+		model.FilterConditionIsNotEmpty:  true,
+	},
+	"date": {
+		model.FilterConditionIsBetween:  true,
+		model.FilterConditionIsEmpty:    true,
+		model.FilterConditionIsNotEmpty: true,
+	},
+}
+
+// GetViewBlocks returns the board of the view and its child blocks, with
+// only the cards meeting the filter of the view. It also tells if the
+// filter was applied completely: the filters the server can't apply fall
+// back to all the cards, for the client to filter them. It returns
+// sql.ErrNoRows if the view doesn't exist.
+func (a *App) GetViewBlocks(ctx context.Context, c store.Container, viewID string) ([]model.Block, bool, error) {
+	view, err := a.store.GetBlock(ctx, c, viewID)
+	if err != nil {
+		return nil, false, err
+	}
+	if view == nil || view.Type != "view" {
+		return nil, false, sql.ErrNoRows
+	}
+	board, err := a.store.GetBlock(ctx, c, view.RootID)
+	if err != nil {
+		return nil, false, err
+	}
+	if board == nil {
+		return nil, false, sql.ErrNoRows
+	}
+
+	filter, err := model.ViewFilterFromBlock(*view)
+	if err != nil {
+		a.logger.Debug("Unable to read the filter of the view", mlog.String("viewID", viewID), mlog.Err(err))
+	}
+	if filter != nil && !isSupportedFilter(*filter, boardPropertyTypes(*board)) {
+		filter = nil
+	}
+
+	blocks, err := a.store.GetFilteredBoardBlocks(ctx, c, board.ID, filter)
+	if err != nil {
+		return nil, false, err
+	}
+	if filter == nil {
+		return blocks, false, nil
+	}
+
+	// the database doesn't compare the date ranges, nor the values with
+	// the JSON encoding of SQLite, so the cards are filtered again
+	filtered := make([]model.Block, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Type == "card" && block.ParentID == board.ID && !isTemplate(block) && !filterGroupMatches(*filter, block) {
+			continue
+		}
+		filtered = append(filtered, block)
+	}
+	return filtered, true, nil
+}
+
+// boardPropertyTypes returns the types of the card properties of the
+// board by ID.
+func boardPropertyTypes(board model.Block) map[string]string {
+	types := map[string]string{}
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		template, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := template["id"].(string)
+		propertyType, _ := template["type"].(string)
+		types[id] = propertyType
+	}
+	return types
+}
+
+// isSupportedFilter tells if the server can apply all the clauses of the
+// filter, given the types of the card properties.
+func isSupportedFilter(group model.FilterGroup, types map[string]string) bool {
+	for _, clause := range group.Clauses {
+		if !viewFilterConditions[types[clause.PropertyID]][clause.Condition] {
+			return false
+		}
+		if clause.Condition == model.FilterConditionIsBetween {
+			for _, value := range clause.Values {
+				if _, err := strconv.ParseInt(value, 10, 64); value != "" && err != nil {
+					return false
+				}
+			}
+		}
+	}
+	for _, nested := range group.Groups {
+		if !isSupportedFilter(nested, types) {
+			return false
+		}
+	}
+	return true
+}
+
+// isTemplate tells if the card is a card template.
+func isTemplate(block model.Block) bool {
+	template, _ := block.Fields["isTemplate"].(bool)
+	return template
+}
+
+// filterGroupMatches tells if the card meets the filter.
+func filterGroupMatches(group model.FilterGroup, card model.Block) bool {
+	if len(group.Clauses) == 0 && len(group.Groups) == 0 {
+		return true
+	}
+
+	or := group.Operation == model.FilterOperationOr
+	for _, clause := range group.Clauses {
+		if filterClauseMatches(clause, card) == or {
+			return or
+		}
+	}
+	for _, nested := range group.Groups {
+		if filterGroupMatches(nested, card) == or {
+			return or
+		}
+	}
+	return !or
+}
+
+// filterClauseMatches tells if the card meets the clause. The date ranges
+// meet the isBetween clauses when they overlap the range of the clause.
+func filterClauseMatches(clause model.FilterClause, card model.Block) bool {
+	properties, _ := card.Fields["properties"].(map[string]interface{})
+	value, _ := properties[clause.PropertyID].(string)
+
+	switch clause.Condition {
+	case model.FilterConditionIncludes, model.FilterConditionNotIncludes:
+		if len(clause.Values) == 0 {
+			return true
+		}
+		included := false
+		for _, v := range clause.Values {
+			if v == value {
+				included = true
+				break
+			}
+		}
+		return included == (clause.Condition == model.FilterConditionIncludes)
+	case model.FilterConditionIsEmpty:
+		return value == ""
+	case model.FilterConditionIsNotEmpty:
+		return value != ""
+	case model.FilterConditionIsBetween:
+		date, ok := parseDateProperty(value)
+		if !ok {
+			return false
+		}
+		end := date.To
+		if end == 0 {
+			end = date.From
+		}
+		if len(clause.Values) > 0 && clause.Values[0] != "" {
+			if from, _ := strconv.ParseInt(clause.Values[0], 10, 64); end < from {
+				return false
+			}
+		}
+		if len(clause.Values) > 1 && clause.Values[1] != "" {
+			if to, _ := strconv.ParseInt(clause.Values[1], 10, 64); date.From > to {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
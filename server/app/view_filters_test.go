@@ -0,0 +1,117 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterClauseMatches(t *testing.T) {
+	card := model.Block{Type: "card", Fields: map[string]interface{}{
+		"properties": map[string]interface{}{
+			"status": "todo",
+			"due":    `{"from":1000,"to":2000}`,
+			"start":  "1500",
+		},
+	}}
+
+	testCases := []struct {
+		name    string
+		clause  model.FilterClause
+		matches bool
+	}{
+		{"includes", model.FilterClause{PropertyID: "status", Condition: model.FilterConditionIncludes, Values: []string{"done", "todo"}}, true},
+		{"doesn't include", model.FilterClause{PropertyID: "status", Condition: model.FilterConditionIncludes, Values: []string{"done"}}, false},
+		{"not includes", model.FilterClause{PropertyID: "status", Condition: model.FilterConditionNotIncludes, Values: []string{"todo"}}, false},
+		{"includes without values", model.FilterClause{PropertyID: "status", Condition: model.FilterConditionIncludes}, true},
+		{"is empty", model.FilterClause{PropertyID: "owner", Condition: model.FilterConditionIsEmpty}, true},
+		{"is not empty", model.FilterClause{PropertyID: "owner", Condition: model.FilterConditionIsNotEmpty}, false},
+		{"timestamp in range", model.FilterClause{PropertyID: "start", Condition: model.FilterConditionIsBetween, Values: []string{"1000", "1500"}}, true},
+		{"timestamp out of range", model.FilterClause{PropertyID: "start", Condition: model.FilterConditionIsBetween, Values: []string{"1600"}}, false},
+		{"overlapping date range", model.FilterClause{PropertyID: "due", Condition: model.FilterConditionIsBetween, Values: []string{"1800", "3000"}}, true},
+		{"date range before", model.FilterClause{PropertyID: "due", Condition: model.FilterConditionIsBetween, Values: []string{"2500", ""}}, false},
+		{"date range after", model.FilterClause{PropertyID: "due", Condition: model.FilterConditionIsBetween, Values: []string{"", "500"}}, false},
+		{"missing date", model.FilterClause{PropertyID: "end", Condition: model.FilterConditionIsBetween}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.matches, filterClauseMatches(tc.clause, card))
+		})
+	}
+}
+
+func TestGetViewBlocks(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	container := st.Container{
+		WorkspaceID: "0",
+	}
+
+	board := model.Block{ID: "board-1", RootID: "board-1", Type: "board", Fields: map[string]interface{}{
+		"cardProperties": []interface{}{
+			map[string]interface{}{"id": "status", "type": "select"},
+			map[string]interface{}{"id": "tags", "type": "multiSelect"},
+			map[string]interface{}{"id": "due", "type": "date"},
+		},
+	}}
+	view := func(filters ...interface{}) *model.Block {
+		return &model.Block{ID: "view-1", RootID: "board-1", ParentID: "board-1", Type: "view", Fields: map[string]interface{}{
+			"filter": map[string]interface{}{"operation": "and", "filters": filters},
+		}}
+	}
+	card := func(id, due string) model.Block {
+		return model.Block{ID: id, RootID: "board-1", ParentID: "board-1", Type: "card", Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"due": due},
+		}}
+	}
+	blockIDs := func(blocks []model.Block) []string {
+		ids := []string{}
+		for _, block := range blocks {
+			ids = append(ids, block.ID)
+		}
+		return ids
+	}
+
+	t.Run("filter the date ranges the database returns", func(t *testing.T) {
+		filter := &model.FilterGroup{Operation: model.FilterOperationAnd, Clauses: []model.FilterClause{
+			{PropertyID: "due", Condition: model.FilterConditionIsBetween, Values: []string{"1000", "2000"}},
+		}}
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("view-1")).
+			Return(view(map[string]interface{}{"propertyId": "due", "condition": "isBetween", "values": []interface{}{"1000", "2000"}}), nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(&board, nil)
+		th.Store.EXPECT().GetFilteredBoardBlocks(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Eq(filter)).
+			Return([]model.Block{board, card("in", "1500"), card("overlap", `{"from":1800,"to":2500}`), card("out", `{"from":2500,"to":3000}`)}, nil)
+
+		blocks, complete, err := th.App.GetViewBlocks(ctx, container, "view-1")
+		require.NoError(t, err)
+		require.True(t, complete)
+		require.Equal(t, []string{"board-1", "in", "overlap"}, blockIDs(blocks))
+	})
+
+	t.Run("return all the cards for the filters the server can't apply", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("view-1")).
+			Return(view(map[string]interface{}{"propertyId": "tags", "condition": "includes", "values": []interface{}{"a"}}), nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(&board, nil)
+		th.Store.EXPECT().GetFilteredBoardBlocks(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1"), gomock.Nil()).
+			Return([]model.Block{board, card("out", "")}, nil)
+
+		blocks, complete, err := th.App.GetViewBlocks(ctx, container, "view-1")
+		require.NoError(t, err)
+		require.False(t, complete)
+		require.Equal(t, []string{"board-1", "out"}, blockIDs(blocks))
+	})
+
+	t.Run("unknown view", func(t *testing.T) {
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(&board, nil)
+
+		_, _, err := th.App.GetViewBlocks(ctx, container, "board-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"context"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/services/webhook"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// workspaceWebhooks returns the webhooks configured in the settings of
+// the workspace. Errors are logged, as they shouldn't prevent the
+// changes from being saved.
+func (a *App) workspaceWebhooks(ctx context.Context, workspaceID string) []model.WorkspaceWebhook {
+	workspace, err := a.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		a.logger.Error("Unable to get the webhooks of the workspace", mlog.String("workspaceID", workspaceID), mlog.Err(err))
+		return nil
+	}
+	if workspace == nil {
+		return nil
+	}
+	return workspace.Settings.Webhooks
+}
+
+// notifyBlockChanged queues the change for delivery to the webhooks.
+func (a *App) notifyBlockChanged(c store.Container, webhooks []model.WorkspaceWebhook, before, after *model.Block, actorID string) {
+	a.webhookDispatcher.NotifyBlockChanged(webhooks, webhook.BlockChangedPayload{
+		WorkspaceID: c.WorkspaceID,
+		Before:      before,
+		After:       after,
+		ActorID:     actorID,
+	})
+}
+
+func (a *App) GetWebhookDeliveries(workspaceID string, limit int) ([]model.WebhookDelivery, error) {
+	return a.store.GetWebhookDeliveries(workspaceID, limit)
+}
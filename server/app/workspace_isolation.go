@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// WorkspaceMismatchError is returned when a block references, as its
+// parent or root, a block of another workspace, or when a block of
+// another workspace is deleted.
+type WorkspaceMismatchError struct {
+	BlockID     string
+	ReferenceID string
+}
+
+func (e WorkspaceMismatchError) Error() string {
+	if e.ReferenceID == "" {
+		return fmt.Sprintf("block %s is a block of another workspace", e.BlockID)
+	}
+	return fmt.Sprintf("block %s references %s, which is a block of another workspace", e.BlockID, e.ReferenceID)
+}
+
+// validateBlockWorkspaces checks that the parents and roots of the blocks
+// that aren't among them are blocks of the workspace of the container,
+// reading their workspaces from the given store in a single query. The
+// references to blocks that don't exist aren't checked.
+func (a *App) validateBlockWorkspaces(ctx context.Context, st store.Store, c store.Container, blocks []model.Block) error {
+	inserted := map[string]bool{}
+	for _, block := range blocks {
+		inserted[block.ID] = true
+	}
+	referenceIDs := []string{}
+	referenced := map[string]bool{}
+	for _, block := range blocks {
+		for _, id := range []string{block.ParentID, block.RootID} {
+			if id != "" && !inserted[id] && !referenced[id] {
+				referenced[id] = true
+				referenceIDs = append(referenceIDs, id)
+			}
+		}
+	}
+	if len(referenceIDs) == 0 {
+		return nil
+	}
+
+	workspaceIDs, err := st.GetBlockWorkspaceIDs(ctx, referenceIDs)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		for _, id := range []string{block.ParentID, block.RootID} {
+			if !inWorkspace(workspaceIDs, id, c.WorkspaceID) {
+				return WorkspaceMismatchError{BlockID: block.ID, ReferenceID: id}
+			}
+		}
+	}
+	return nil
+}
+
+// checkBlockWorkspace returns a WorkspaceMismatchError if the block only
+// exists in other workspaces than the one of the container.
+func (a *App) checkBlockWorkspace(ctx context.Context, c store.Container, blockID string) error {
+	workspaceIDs, err := a.store.GetBlockWorkspaceIDs(ctx, []string{blockID})
+	if err != nil {
+		return err
+	}
+	if !inWorkspace(workspaceIDs, blockID, c.WorkspaceID) {
+		return WorkspaceMismatchError{BlockID: blockID}
+	}
+	return nil
+}
+
+// inWorkspace tells if the block, among the blocks whose workspaces are
+// given, is a block of the workspace or doesn't exist.
+func inWorkspace(workspaceIDs map[string][]string, blockID, workspaceID string) bool {
+	blockWorkspaceIDs, ok := workspaceIDs[blockID]
+	if !ok {
+		return true
+	}
+	for _, id := range blockWorkspaceIDs {
+		if id == workspaceID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceIsolation(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+	th.expectOpenBoards()
+
+	container := st.Container{
+		WorkspaceID: "workspace-1",
+	}
+	otherBoardWorkspaces := map[string][]string{"other-board": {"workspace-2"}}
+
+	t.Run("should reject the insertion of a card into a board of another workspace", func(t *testing.T) {
+		// the blocks of the other workspace aren't found in the workspace
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("other-board")).Return(nil, nil)
+		th.Store.EXPECT().GetBlockWorkspaceIDs(gomock.Any(), gomock.Eq([]string{"other-board"})).Return(otherBoardWorkspaces, nil)
+
+		card := model.Block{ID: "card-2", ParentID: "other-board", RootID: "other-board", Type: "card"}
+		_, err := th.App.InsertBlocks(ctx, container, []model.Block{card}, "user-id-1")
+		require.Equal(t, WorkspaceMismatchError{BlockID: "card-2", ReferenceID: "other-board"}, err)
+	})
+
+	t.Run("should accept the blocks of boards in both workspaces", func(t *testing.T) {
+		th.Store.EXPECT().GetBlockWorkspaceIDs(gomock.Any(), gomock.Eq([]string{"board-1"})).
+			Return(map[string][]string{"board-1": {"workspace-1", "workspace-2"}}, nil)
+
+		card := model.Block{ID: "card-2", ParentID: "board-1", RootID: "board-1", Type: "card"}
+		require.NoError(t, th.App.validateBlockWorkspaces(ctx, th.Store, container, []model.Block{card}))
+	})
+
+	t.Run("should not look up the blocks inserted along", func(t *testing.T) {
+		blocks := []model.Block{
+			{ID: "board-2", RootID: "board-2", Type: "board"},
+			{ID: "card-2", ParentID: "board-2", RootID: "board-2", Type: "card"},
+		}
+		require.NoError(t, th.App.validateBlockWorkspaces(ctx, th.Store, container, blocks))
+	})
+
+	t.Run("should reject moving a card to a board of another workspace", func(t *testing.T) {
+		card := &model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"}
+		board := &model.Block{ID: "board-1", RootID: "board-1", Type: "board"}
+		th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return("board-1", nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("board-1")).Return(board, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("other-board")).Return(nil, nil)
+		th.Store.EXPECT().GetBlock(gomock.Any(), gomock.Eq(container), gomock.Eq("card-1")).Return(card, nil)
+		th.Store.EXPECT().GetBlockWorkspaceIDs(gomock.Any(), gomock.Eq([]string{"other-board"})).Return(otherBoardWorkspaces, nil)
+
+		otherBoardID := "other-board"
+		patch := &model.BlockPatch{ParentID: &otherBoardID, RootID: &otherBoardID}
+		_, err := th.App.PatchBlock(ctx, container, "card-1", patch, "user-id-1", false)
+		require.Equal(t, WorkspaceMismatchError{BlockID: "card-1", ReferenceID: "other-board"}, err)
+	})
+
+	t.Run("should reject the deletion of a block of another workspace", func(t *testing.T) {
+		th.Store.EXPECT().GetBlockWorkspaceIDs(gomock.Any(), gomock.Eq([]string{"other-board"})).Return(otherBoardWorkspaces, nil)
+
+		err := th.App.DeleteBlock(ctx, container, "other-board", "user-id-1")
+		require.Equal(t, WorkspaceMismatchError{BlockID: "other-board"}, err)
+	})
+}
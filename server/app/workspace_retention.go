@@ -0,0 +1,263 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/notify"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// workspaceArchivesDir is the directory of the files where the inactive
+// workspaces are archived before their deletion.
+const workspaceArchivesDir = "archives"
+
+// errNoWorkspaceMemberNotified is returned when no member of an inactive
+// workspace could be notified of its deletion, which is then not planned.
+var errNoWorkspaceMemberNotified = errors.New("no member of the workspace was notified")
+
+// GetWorkspaceRetentionPlan returns the workspaces affected by the next
+// run of the cleanup policy of the inactive workspaces, without changing
+// them, whether the policy is enabled or not.
+func (a *App) GetWorkspaceRetentionPlan(ctx context.Context) (*model.WorkspaceRetentionPlan, error) {
+	plan, _, err := a.planWorkspaceRetention(ctx, utils.GetMillis())
+	return plan, err
+}
+
+// ApplyWorkspaceRetention runs the cleanup policy of the inactive
+// workspaces, if it's enabled. The members of the workspaces whose blocks
+// haven't been updated for the configured number of days are notified of
+// their deletion, and the workspaces are archived to the files and
+// deleted once the notice ends. The notices of the workspaces updated
+// since are reset. Each step is recorded in the audit log. The root
+// workspace, with the templates and the boards of the standalone servers,
+// is never deleted.
+func (a *App) ApplyWorkspaceRetention(ctx context.Context) error {
+	if !a.config.WorkspaceRetentionEnabled {
+		return nil
+	}
+
+	now := utils.GetMillis()
+	plan, staleNotices, err := a.planWorkspaceRetention(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, notice := range staleNotices {
+		if err := a.store.DeleteWorkspaceRetentionNotice(ctx, notice.WorkspaceID); err != nil {
+			return err
+		}
+		a.recordAuditEntry(model.AuditActionResetWorkspaceRetention, systemUserID, notice.WorkspaceID, notice.WorkspaceID, map[string]interface{}{
+			"notifiedAt": notice.NotifiedAt,
+		})
+	}
+
+	for _, candidate := range plan.Workspaces {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		switch candidate.Action {
+		case model.WorkspaceRetentionActionNotify:
+			err = a.notifyInactiveWorkspace(ctx, candidate, now)
+		case model.WorkspaceRetentionActionDelete:
+			err = a.deleteInactiveWorkspace(ctx, candidate, now)
+		default:
+			continue
+		}
+		if err != nil {
+			// one workspace failing doesn't prevent the cleanup of the others
+			a.logger.Error("Unable to apply the retention policy to the workspace",
+				mlog.String("workspaceID", candidate.WorkspaceID),
+				mlog.String("action", candidate.Action),
+				mlog.Err(err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// planWorkspaceRetention returns the inactive workspaces and what the
+// policy does with them, with the notices of the workspaces that aren't
+// inactive anymore, to reset.
+func (a *App) planWorkspaceRetention(ctx context.Context, now int64) (*model.WorkspaceRetentionPlan, []model.WorkspaceRetentionNotice, error) {
+	inactivityDays := a.config.WorkspaceInactivityDays
+	if inactivityDays <= 0 {
+		inactivityDays = config.DefaultWorkspaceInactivityDays
+	}
+	noticeDays := a.config.WorkspaceRetentionNoticeDays
+	if noticeDays <= 0 {
+		noticeDays = config.DefaultWorkspaceRetentionNoticeDays
+	}
+	notice := (time.Duration(noticeDays) * 24 * time.Hour).Milliseconds()
+
+	inactiveSince := now - (time.Duration(inactivityDays) * 24 * time.Hour).Milliseconds()
+	inactive, err := a.store.GetInactiveWorkspaces(ctx, inactiveSince)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	notices, err := a.store.GetWorkspaceRetentionNotices(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	noticesByID := make(map[string]model.WorkspaceRetentionNotice, len(notices))
+	for _, n := range notices {
+		noticesByID[n.WorkspaceID] = n
+	}
+
+	plan := &model.WorkspaceRetentionPlan{
+		Enabled:        a.config.WorkspaceRetentionEnabled,
+		InactivityDays: inactivityDays,
+		NoticeDays:     noticeDays,
+		Workspaces:     []model.WorkspaceRetentionCandidate{},
+	}
+	for _, workspace := range inactive {
+		if workspace.WorkspaceID == "0" {
+			continue
+		}
+
+		candidate := model.WorkspaceRetentionCandidate{
+			WorkspaceID:    workspace.WorkspaceID,
+			LastActivityAt: workspace.LastActivityAt,
+			DeleteAt:       now + notice,
+			Action:         model.WorkspaceRetentionActionNotify,
+		}
+		// a notice computed from another activity is sent again
+		if n, ok := noticesByID[workspace.WorkspaceID]; ok && n.LastActivityAt == workspace.LastActivityAt {
+			candidate.NotifiedAt = n.NotifiedAt
+			candidate.DeleteAt = n.NotifiedAt + notice
+			candidate.Action = model.WorkspaceRetentionActionWait
+			if now >= candidate.DeleteAt {
+				candidate.Action = model.WorkspaceRetentionActionDelete
+			}
+		}
+		delete(noticesByID, workspace.WorkspaceID)
+		plan.Workspaces = append(plan.Workspaces, candidate)
+	}
+
+	staleNotices := []model.WorkspaceRetentionNotice{}
+	for _, n := range notices {
+		if _, ok := noticesByID[n.WorkspaceID]; ok {
+			staleNotices = append(staleNotices, n)
+		}
+	}
+
+	return plan, staleNotices, nil
+}
+
+// notifyInactiveWorkspace notifies the active members of the workspace
+// of its deletion, and records the notice, from which the notice period
+// starts. The notice isn't recorded if no member could be notified.
+func (a *App) notifyInactiveWorkspace(ctx context.Context, candidate model.WorkspaceRetentionCandidate, now int64) error {
+	members, err := a.store.GetUsersByWorkspace(candidate.WorkspaceID)
+	if err != nil {
+		return err
+	}
+
+	notified := 0
+	for _, member := range members {
+		if member.DeleteAt != 0 {
+			continue
+		}
+
+		notification := model.Notification{
+			ID:          utils.CreateGUID(),
+			Type:        model.NotificationTypeWorkspaceDeletion,
+			WorkspaceID: candidate.WorkspaceID,
+			UserID:      member.ID,
+			CreateAt:    now,
+		}
+		if err := a.store.InsertNotification(notification); err != nil {
+			a.logger.Error("Unable to store the notification", mlog.String("workspaceID", candidate.WorkspaceID), mlog.Err(err))
+			continue
+		}
+		notified++
+
+		if a.notifier == nil {
+			continue
+		}
+
+		err := a.notifier.NotifyWorkspaceDeletion(notify.WorkspaceDeletion{
+			Notification:   notification,
+			LastActivityAt: candidate.LastActivityAt,
+			DeleteAt:       candidate.DeleteAt,
+		})
+		if err != nil {
+			a.logger.Error("Unable to send the notice of the workspace deletion", mlog.String("userID", member.ID), mlog.Err(err))
+		}
+	}
+
+	// the notice period only starts once a member was told about it
+	if notified == 0 {
+		return errNoWorkspaceMemberNotified
+	}
+
+	err = a.store.UpsertWorkspaceRetentionNotice(ctx, model.WorkspaceRetentionNotice{
+		WorkspaceID:    candidate.WorkspaceID,
+		LastActivityAt: candidate.LastActivityAt,
+		NotifiedAt:     now,
+	})
+	if err != nil {
+		return err
+	}
+
+	a.recordAuditEntry(model.AuditActionNotifyInactiveWorkspace, systemUserID, candidate.WorkspaceID, candidate.WorkspaceID, map[string]interface{}{
+		"lastActivityAt": candidate.LastActivityAt,
+		"deleteAt":       candidate.DeleteAt,
+		"notifiedUsers":  notified,
+	})
+	return nil
+}
+
+// deleteInactiveWorkspace archives the workspace to the files, then
+// deletes it. The workspace isn't deleted if it can't be archived.
+func (a *App) deleteInactiveWorkspace(ctx context.Context, candidate model.WorkspaceRetentionCandidate, now int64) error {
+	filePath := filepath.Join(workspaceArchivesDir, fmt.Sprintf("workspace-%s-%s.zip", candidate.WorkspaceID, utils.TimeFromMillis(now).UTC().Format("20060102")))
+	written, err := a.writeWorkspaceArchive(ctx, candidate.WorkspaceID, filePath)
+	if err != nil {
+		return fmt.Errorf("unable to archive the workspace: %w", err)
+	}
+	a.recordAuditEntry(model.AuditActionArchiveInactiveWorkspace, systemUserID, candidate.WorkspaceID, candidate.WorkspaceID, map[string]interface{}{
+		"path": filePath,
+		"size": written,
+	})
+
+	if err := a.DeleteWorkspace(ctx, candidate.WorkspaceID); err != nil {
+		return err
+	}
+	a.recordAuditEntry(model.AuditActionDeleteInactiveWorkspace, systemUserID, candidate.WorkspaceID, candidate.WorkspaceID, map[string]interface{}{
+		"lastActivityAt": candidate.LastActivityAt,
+		"notifiedAt":     candidate.NotifiedAt,
+		"archivePath":    filePath,
+	})
+	return nil
+}
+
+// writeWorkspaceArchive writes the archive of every board of the
+// workspace to the file, streamed from the export, and returns its size.
+func (a *App) writeWorkspaceArchive(ctx context.Context, workspaceID, filePath string) (int64, error) {
+	reader, writer := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c := store.Container{WorkspaceID: workspaceID}
+		writer.CloseWithError(a.ExportWorkspaceArchive(ctx, c, systemUserID, writer))
+	}()
+
+	written, err := a.filesBackend.WriteFile(reader, filePath)
+	// closing the reader stops the export if the file couldn't be written
+	reader.Close()
+	<-done
+	return written, err
+}
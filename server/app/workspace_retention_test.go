@@ -0,0 +1,185 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	st "github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/filestore/mocks"
+)
+
+func TestWorkspaceRetention(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	day := (24 * time.Hour).Milliseconds()
+	now := utils.GetMillis()
+	inactive := []model.InactiveWorkspace{
+		{WorkspaceID: "0", LastActivityAt: now - 400*day},
+		{WorkspaceID: "new-workspace", LastActivityAt: now - 400*day},
+		{WorkspaceID: "noticed-workspace", LastActivityAt: now - 380*day},
+		{WorkspaceID: "expired-workspace", LastActivityAt: now - 500*day},
+		{WorkspaceID: "updated-workspace", LastActivityAt: now - 370*day},
+	}
+	notices := []model.WorkspaceRetentionNotice{
+		{WorkspaceID: "noticed-workspace", LastActivityAt: now - 380*day, NotifiedAt: now - 10*day},
+		{WorkspaceID: "expired-workspace", LastActivityAt: now - 500*day, NotifiedAt: now - 31*day},
+		{WorkspaceID: "updated-workspace", LastActivityAt: now - 400*day, NotifiedAt: now - 20*day},
+		{WorkspaceID: "active-workspace", LastActivityAt: now - 400*day, NotifiedAt: now - 20*day},
+	}
+	expectPlan := func() {
+		th.Store.EXPECT().GetInactiveWorkspaces(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, inactiveSince int64) ([]model.InactiveWorkspace, error) {
+				require.InDelta(t, now-365*day, inactiveSince, float64(time.Minute.Milliseconds()))
+				return inactive, nil
+			})
+		th.Store.EXPECT().GetWorkspaceRetentionNotices(gomock.Any()).Return(notices, nil)
+	}
+
+	t.Run("should plan the actions without applying them", func(t *testing.T) {
+		expectPlan()
+
+		plan, err := th.App.GetWorkspaceRetentionPlan(ctx)
+		require.NoError(t, err)
+		require.False(t, plan.Enabled)
+		require.Equal(t, 365, plan.InactivityDays)
+		require.Equal(t, 30, plan.NoticeDays)
+
+		actions := map[string]string{}
+		for _, candidate := range plan.Workspaces {
+			actions[candidate.WorkspaceID] = candidate.Action
+		}
+		require.Equal(t, map[string]string{
+			"new-workspace":     model.WorkspaceRetentionActionNotify,
+			"noticed-workspace": model.WorkspaceRetentionActionWait,
+			"expired-workspace": model.WorkspaceRetentionActionDelete,
+			"updated-workspace": model.WorkspaceRetentionActionNotify,
+		}, actions)
+		require.Equal(t, now-10*day+30*day, plan.Workspaces[1].DeleteAt)
+		require.Equal(t, now-10*day, plan.Workspaces[1].NotifiedAt)
+	})
+
+	t.Run("should do nothing when it's disabled", func(t *testing.T) {
+		require.NoError(t, th.App.ApplyWorkspaceRetention(ctx))
+	})
+
+	t.Run("should notify, reset, archive and delete the workspaces", func(t *testing.T) {
+		th.App.config.WorkspaceRetentionEnabled = true
+		defer func() { th.App.config.WorkspaceRetentionEnabled = false }()
+		notifier := &testNotifier{}
+		th.App.notifier = notifier
+		defer func() { th.App.notifier = nil }()
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		expectPlan()
+
+		th.Store.EXPECT().DeleteWorkspaceRetentionNotice(gomock.Any(), "active-workspace").Return(nil)
+		expectAuditEntry(th, model.AuditActionResetWorkspaceRetention, systemUserID, "active-workspace")
+
+		members := []*model.User{{ID: "user-1"}, {ID: "user-2", DeleteAt: 1}}
+		var notifications []model.Notification
+		for _, workspaceID := range []string{"new-workspace", "updated-workspace"} {
+			workspaceID := workspaceID
+			th.Store.EXPECT().GetUsersByWorkspace(workspaceID).Return(members, nil)
+			th.Store.EXPECT().InsertNotification(gomock.Any()).DoAndReturn(func(notification model.Notification) error {
+				notifications = append(notifications, notification)
+				return nil
+			})
+			th.Store.EXPECT().UpsertWorkspaceRetentionNotice(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, notice model.WorkspaceRetentionNotice) error {
+					require.Equal(t, workspaceID, notice.WorkspaceID)
+					require.GreaterOrEqual(t, notice.NotifiedAt, now)
+					return nil
+				})
+			expectAuditEntry(th, model.AuditActionNotifyInactiveWorkspace, systemUserID, workspaceID)
+		}
+
+		expired := st.Container{WorkspaceID: "expired-workspace"}
+		blocks := []model.Block{
+			{ID: "board-1", RootID: "board-1", Type: "board"},
+			{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"},
+		}
+		th.Store.EXPECT().StreamAllBlocks(gomock.Any(), expired, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ st.Container, fn func(model.Block) error) error {
+				for _, block := range blocks {
+					if err := fn(block); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		var writtenPath string
+		var written []byte
+		mockedFileBackend.On("WriteFile", mock.Anything, mock.Anything).Return(func(reader io.Reader, path string) int64 {
+			writtenPath = path
+			written, _ = ioutil.ReadAll(reader)
+			return int64(len(written))
+		}, nil)
+		expectAuditEntry(th, model.AuditActionArchiveInactiveWorkspace, systemUserID, "expired-workspace")
+		th.Store.EXPECT().DeleteWorkspace(gomock.Any(), "expired-workspace").Return(nil)
+		expectAuditEntry(th, model.AuditActionDeleteInactiveWorkspace, systemUserID, "expired-workspace")
+
+		require.NoError(t, th.App.ApplyWorkspaceRetention(ctx))
+
+		require.Len(t, notifications, 2)
+		for i, workspaceID := range []string{"new-workspace", "updated-workspace"} {
+			require.Equal(t, model.NotificationTypeWorkspaceDeletion, notifications[i].Type)
+			require.Equal(t, workspaceID, notifications[i].WorkspaceID)
+			require.Equal(t, "user-1", notifications[i].UserID)
+		}
+		require.Len(t, notifier.deletions, 2)
+		require.Equal(t, now-400*day, notifier.deletions[0].LastActivityAt)
+		require.GreaterOrEqual(t, notifier.deletions[0].DeleteAt, now+30*day)
+
+		require.True(t, strings.HasPrefix(writtenPath, "archives/workspace-expired-workspace-"))
+		archive, err := zip.NewReader(bytes.NewReader(written), int64(len(written)))
+		require.NoError(t, err)
+		require.Len(t, archive.File, 1)
+		reader, err := archive.File[0].Open()
+		require.NoError(t, err)
+		lines, err := ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		require.Len(t, strings.Split(strings.TrimSpace(string(lines)), "\n"), len(blocks))
+	})
+
+	t.Run("should not start the notice if no member was notified", func(t *testing.T) {
+		th.Store.EXPECT().GetUsersByWorkspace("new-workspace").Return([]*model.User{{ID: "user-1"}, {ID: "user-3"}}, nil)
+		th.Store.EXPECT().InsertNotification(gomock.Any()).Return(&TestError{}).Times(2)
+
+		// neither the notice nor its audit entry are recorded, so the
+		// next run notifies the members again
+		candidate := model.WorkspaceRetentionCandidate{WorkspaceID: "new-workspace", LastActivityAt: now - 400*day}
+		err := th.App.notifyInactiveWorkspace(ctx, candidate, now)
+		require.ErrorIs(t, err, errNoWorkspaceMemberNotified)
+	})
+
+	t.Run("should not delete a workspace that can't be archived", func(t *testing.T) {
+		th.App.config.WorkspaceRetentionEnabled = true
+		defer func() { th.App.config.WorkspaceRetentionEnabled = false }()
+		mockedFileBackend := &mocks.FileBackend{}
+		th.App.filesBackend = mockedFileBackend
+
+		th.Store.EXPECT().GetInactiveWorkspaces(gomock.Any(), gomock.Any()).Return(inactive[3:4], nil)
+		th.Store.EXPECT().GetWorkspaceRetentionNotices(gomock.Any()).Return(notices[1:2], nil)
+		th.Store.EXPECT().StreamAllBlocks(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		mockedFileBackend.On("WriteFile", mock.Anything, mock.Anything).Return(int64(0), &TestError{})
+
+		// the workspace isn't deleted, the failure being only logged
+		require.NoError(t, th.App.ApplyWorkspaceRetention(ctx))
+		mockedFileBackend.AssertExpectations(t)
+	})
+}
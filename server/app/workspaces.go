@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 
@@ -10,20 +11,20 @@ import (
 	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 )
 
-func (a *App) GetRootWorkspace() (*model.Workspace, error) {
+func (a *App) GetRootWorkspace(ctx context.Context) (*model.Workspace, error) {
 	workspaceID := "0"
-	workspace, _ := a.store.GetWorkspace(workspaceID)
+	workspace, _ := a.store.GetWorkspace(ctx, workspaceID)
 	if workspace == nil {
 		workspace = &model.Workspace{
 			ID:          workspaceID,
 			SignupToken: utils.CreateGUID(),
 		}
-		err := a.store.UpsertWorkspaceSignupToken(*workspace)
+		err := a.store.UpsertWorkspaceSignupToken(ctx, *workspace)
 		if err != nil {
 			a.logger.Fatal("Unable to initialize workspace", mlog.Err(err))
 			return nil, err
 		}
-		workspace, err = a.store.GetWorkspace(workspaceID)
+		workspace, err = a.store.GetWorkspace(ctx, workspaceID)
 		if err != nil {
 			a.logger.Fatal("Unable to get initialized workspace", mlog.Err(err))
 			return nil, err
@@ -35,8 +36,8 @@ func (a *App) GetRootWorkspace() (*model.Workspace, error) {
 	return workspace, nil
 }
 
-func (a *App) GetWorkspace(id string) (*model.Workspace, error) {
-	workspace, err := a.store.GetWorkspace(id)
+func (a *App) GetWorkspace(ctx context.Context, id string) (*model.Workspace, error) {
+	workspace, err := a.store.GetWorkspace(ctx, id)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, nil
 	}
@@ -46,22 +47,64 @@ func (a *App) GetWorkspace(id string) (*model.Workspace, error) {
 	return workspace, nil
 }
 
-func (a *App) DoesUserHaveWorkspaceAccess(userID string, workspaceID string) bool {
-	return a.auth.DoesUserHaveWorkspaceAccess(userID, workspaceID)
+func (a *App) DoesUserHaveWorkspaceAccess(ctx context.Context, userID string, workspaceID string) bool {
+	return a.auth.DoesUserHaveWorkspaceAccess(ctx, userID, workspaceID)
 }
 
-func (a *App) UpsertWorkspaceSettings(workspace model.Workspace) error {
-	return a.store.UpsertWorkspaceSettings(workspace)
+func (a *App) UpsertWorkspaceSettings(ctx context.Context, workspace model.Workspace) error {
+	return a.store.UpsertWorkspaceSettings(ctx, workspace)
 }
 
-func (a *App) UpsertWorkspaceSignupToken(workspace model.Workspace) error {
-	return a.store.UpsertWorkspaceSignupToken(workspace)
+// PatchWorkspaceSettings merges the patch into the current settings of
+// the workspace and returns the resulting settings.
+func (a *App) PatchWorkspaceSettings(ctx context.Context, workspaceID string, patch *model.WorkspaceSettingsPatch, userID string) (*model.WorkspaceSettings, error) {
+	workspace, err := a.GetWorkspace(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if workspace == nil {
+		workspace = &model.Workspace{ID: workspaceID}
+	}
+
+	workspace.Settings = patch.Patch(workspace.Settings)
+	workspace.ModifiedBy = userID
+
+	if err := a.store.UpsertWorkspaceSettings(ctx, *workspace); err != nil {
+		return nil, err
+	}
+
+	a.recordAuditEntry(model.AuditActionPatchWorkspaceSettings, userID, workspaceID, workspaceID, map[string]interface{}{
+		"patch": patch,
+	})
+	return &workspace.Settings, nil
+}
+
+func (a *App) UpsertWorkspaceSignupToken(ctx context.Context, workspace model.Workspace) error {
+	return a.store.UpsertWorkspaceSignupToken(ctx, workspace)
+}
+
+func (a *App) GetWorkspaces(ctx context.Context, modifiedSince int64, limit int) ([]*model.Workspace, error) {
+	return a.store.GetWorkspaces(ctx, modifiedSince, limit)
+}
+
+func (a *App) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	if err := a.store.DeleteWorkspace(ctx, workspaceID); err != nil {
+		return err
+	}
+	a.invalidateWorkspaceBlocks(workspaceID)
+	return nil
+}
+
+func (a *App) GetWorkspaceCount(ctx context.Context) (int64, error) {
+	return a.store.GetWorkspaceCount(ctx)
 }
 
-func (a *App) GetWorkspaceCount() (int64, error) {
-	return a.store.GetWorkspaceCount()
+// GetWorkspaceStats returns the statistics of the workspaces of the
+// server, until now.
+func (a *App) GetWorkspaceStats(ctx context.Context) (*model.WorkspaceStats, error) {
+	return a.store.GetWorkspaceStats(ctx, utils.GetMillis())
 }
 
-func (a *App) GetUserWorkspaces(userID string) ([]model.UserWorkspace, error) {
-	return a.store.GetUserWorkspaces(userID)
+func (a *App) GetUserWorkspaces(ctx context.Context, userID, cursor string, limit int) ([]model.UserWorkspace, bool, error) {
+	return a.store.GetUserWorkspaces(ctx, userID, cursor, limit)
 }
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchWorkspaceSettings(t *testing.T) {
+	ctx := context.Background()
+	th, tearDown := SetupTestHelper(t)
+	defer tearDown()
+
+	workspaceID := "workspace-id"
+	userID := "user-id"
+
+	t.Run("should merge the patch into the existing settings", func(t *testing.T) {
+		existing := &model.Workspace{
+			ID:          workspaceID,
+			SignupToken: "token",
+			Settings: model.WorkspaceSettings{
+				SignupAllowed: true,
+				Locale:        "en",
+			},
+		}
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), workspaceID).Return(existing, nil)
+
+		locale := "es"
+		cardLimit := 50
+		patch := &model.WorkspaceSettingsPatch{Locale: &locale, CardLimit: &cardLimit}
+
+		want := model.WorkspaceSettings{SignupAllowed: true, Locale: "es", CardLimit: 50}
+		th.Store.EXPECT().UpsertWorkspaceSettings(gomock.Any(), model.Workspace{
+			ID:          workspaceID,
+			SignupToken: "token",
+			Settings:    want,
+			ModifiedBy:  userID,
+		}).Return(nil)
+		expectAuditEntry(th, model.AuditActionPatchWorkspaceSettings, userID, workspaceID)
+
+		settings, err := th.App.PatchWorkspaceSettings(ctx, workspaceID, patch, userID)
+		require.NoError(t, err)
+		require.Equal(t, want, *settings)
+	})
+
+	t.Run("should create the settings of a new workspace", func(t *testing.T) {
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), workspaceID).Return(nil, sql.ErrNoRows)
+
+		signupAllowed := true
+		patch := &model.WorkspaceSettingsPatch{SignupAllowed: &signupAllowed}
+
+		th.Store.EXPECT().UpsertWorkspaceSettings(gomock.Any(), gomock.Any()).Return(nil)
+		expectAuditEntry(th, model.AuditActionPatchWorkspaceSettings, userID, workspaceID)
+
+		settings, err := th.App.PatchWorkspaceSettings(ctx, workspaceID, patch, userID)
+		require.NoError(t, err)
+		require.Equal(t, model.WorkspaceSettings{SignupAllowed: true}, *settings)
+	})
+
+	t.Run("should fail if the workspace can't be fetched", func(t *testing.T) {
+		th.Store.EXPECT().GetWorkspace(gomock.Any(), workspaceID).Return(nil, errors.New("database error"))
+
+		settings, err := th.App.PatchWorkspaceSettings(ctx, workspaceID, &model.WorkspaceSettingsPatch{}, userID)
+		require.Error(t, err)
+		require.Nil(t, settings)
+	})
+}
+
+func TestWorkspaceSettingsPatchValidation(t *testing.T) {
+	t.Run("should reject unknown keys", func(t *testing.T) {
+		_, err := model.WorkspaceSettingsPatchFromJSON([]byte(`{"lcoale": "en"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("should reject invalid values", func(t *testing.T) {
+		patch, err := model.WorkspaceSettingsPatchFromJSON([]byte(`{"cardLimit": -1}`))
+		require.NoError(t, err)
+		require.Error(t, patch.IsValid())
+
+		patch, err = model.WorkspaceSettingsPatchFromJSON([]byte(`{"locale": "not a locale"}`))
+		require.NoError(t, err)
+		require.Error(t, patch.IsValid())
+	})
+
+	t.Run("should accept valid values", func(t *testing.T) {
+		patch, err := model.WorkspaceSettingsPatchFromJSON([]byte(`{"locale": "pt-BR", "cardLimit": 0, "signupAllowed": false}`))
+		require.NoError(t, err)
+		require.NoError(t, patch.IsValid())
+	})
+}
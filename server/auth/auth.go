@@ -1,15 +1,25 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/config"
 	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
 	"github.com/pkg/errors"
 )
 
+// ErrReadTokenExpired is returned when a read token matches a sharing
+// token that has already expired.
+var ErrReadTokenExpired = errors.New("read token expired")
+
+// accessTokenLastUsedInterval is the minimum time, in milliseconds, between
+// two updates of the last used time of a personal access token.
+const accessTokenLastUsedInterval = 60 * 1000
+
 // Auth authenticates sessions.
 type Auth struct {
 	config *config.Configuration
@@ -22,24 +32,78 @@ func New(config *config.Configuration, store store.Store) *Auth {
 }
 
 // GetSession Get a user active session and refresh the session if needed.
+// The expiry of the session is extended on activity, at most once per
+// refresh interval.
 func (a *Auth) GetSession(token string) (*model.Session, error) {
 	if len(token) < 1 {
 		return nil, errors.New("no session token")
 	}
 
-	session, err := a.store.GetSession(token, a.config.SessionExpireTime)
+	if model.IsAccessToken(token) {
+		return a.getAccessTokenSession(token)
+	}
+
+	session, err := a.store.GetSession(token)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to get the session for the token")
 	}
-	if session.UpdateAt < (time.Now().Unix() - a.config.SessionRefreshTime) {
+
+	now := time.Now().Unix()
+	if session.UpdateAt < (now - a.sessionRefreshTime()) {
+		session.ExpireAt = a.SessionExpireAt(now)
 		_ = a.store.RefreshSession(session)
 	}
 	return session, nil
 }
 
+// getAccessTokenSession returns a session for the user owning the personal
+// access token.
+func (a *Auth) getAccessTokenSession(token string) (*model.Session, error) {
+	accessToken, err := a.store.GetAccessTokenByHash(model.HashToken(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the access token")
+	}
+
+	now := utils.GetMillis()
+	if accessToken.IsExpired(now) {
+		return nil, errors.New("access token expired")
+	}
+	if now-accessToken.LastUsedAt > accessTokenLastUsedInterval {
+		_ = a.store.UpdateAccessTokenLastUsed(accessToken.ID, now)
+	}
+
+	return &model.Session{
+		ID:          accessToken.ID,
+		Token:       token,
+		UserID:      accessToken.UserID,
+		AuthService: a.config.AuthMode,
+		Props:       map[string]interface{}{},
+		CreateAt:    accessToken.CreateAt / 1000,
+		UpdateAt:    now / 1000,
+		ExpireAt:    accessToken.ExpireAt / 1000,
+	}, nil
+}
+
+// SessionExpireAt returns the expiry, in seconds, of a session active at
+// the given time.
+func (a *Auth) SessionExpireAt(now int64) int64 {
+	expireTime := a.config.SessionExpireTime
+	if expireTime <= 0 {
+		expireTime = config.DefaultSessionExpireTime
+	}
+	return now + expireTime
+}
+
+func (a *Auth) sessionRefreshTime() int64 {
+	if a.config.SessionRefreshTime <= 0 {
+		return config.DefaultSessionRefreshTime
+	}
+	return a.config.SessionRefreshTime
+}
+
 // IsValidReadToken validates the read token for a block.
-func (a *Auth) IsValidReadToken(c store.Container, blockID string, readToken string) (bool, error) {
-	rootID, err := a.store.GetRootID(c, blockID)
+func (a *Auth) IsValidReadToken(ctx context.Context, c store.Container, blockID string, readToken string) (bool, error) {
+	rootID, err := a.store.GetRootID(ctx, c, blockID)
 	if err != nil {
 		return false, err
 	}
@@ -52,17 +116,70 @@ func (a *Auth) IsValidReadToken(c store.Container, blockID string, readToken str
 		return false, err
 	}
 
-	if sharing != nil && (sharing.ID == rootID && sharing.Enabled && sharing.Token == readToken) {
+	if sharing == nil || sharing.ID != rootID || !sharing.Enabled {
+		return false, nil
+	}
+
+	if sharing.Token == readToken {
+		return true, nil
+	}
+
+	tokens, err := a.store.GetSharingTokens(c, rootID)
+	if err != nil {
+		return false, err
+	}
+
+	now := utils.GetMillis()
+	for _, token := range tokens {
+		if token.Token != readToken {
+			continue
+		}
+		if token.IsExpired(now) {
+			return false, ErrReadTokenExpired
+		}
 		return true, nil
 	}
 
 	return false, nil
 }
 
-func (a *Auth) DoesUserHaveWorkspaceAccess(userID string, workspaceID string) bool {
-	hasAccess, err := a.store.HasWorkspaceAccess(userID, workspaceID)
+func (a *Auth) DoesUserHaveWorkspaceAccess(ctx context.Context, userID string, workspaceID string) bool {
+	hasAccess, err := a.store.HasWorkspaceAccess(ctx, userID, workspaceID)
 	if err != nil {
 		return false
 	}
 	return hasAccess
 }
+
+// GetBoardRoles returns the roles of the members of the board by user
+// ID, or nil if the board has no members and is open to the users of its
+// workspace.
+func (a *Auth) GetBoardRoles(c store.Container, boardID string) (map[string]string, error) {
+	members, err := a.store.GetBoardMembers(c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	roles := make(map[string]string, len(members))
+	for _, member := range members {
+		roles[member.UserID] = member.Role
+	}
+	return roles, nil
+}
+
+// IsGuest tells if the user is a guest, who only has access to the boards
+// they are a member of. The users that aren't stored, like the one of the
+// single user mode, aren't guests.
+func (a *Auth) IsGuest(userID string) (bool, error) {
+	user, err := a.store.GetUserByID(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.IsGuest, nil
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -57,8 +58,8 @@ func TestGetSession(t *testing.T) {
 		{"success, good token", "goodToken", 1000, false},
 	}
 
-	th.Store.EXPECT().GetSession("badToken", gomock.Any()).Return(nil, errors.New("Invalid Token"))
-	th.Store.EXPECT().GetSession("goodToken", gomock.Any()).Return(mockSession, nil)
+	th.Store.EXPECT().GetSession("badToken").Return(nil, errors.New("Invalid Token"))
+	th.Store.EXPECT().GetSession("goodToken").Return(mockSession, nil)
 	th.Store.EXPECT().RefreshSession(gomock.Any()).Return(nil)
 
 	for _, test := range testcases {
@@ -78,6 +79,76 @@ func TestGetSession(t *testing.T) {
 	}
 }
 
+func TestGetSessionRefresh(t *testing.T) {
+	th := setupTestHelper(t)
+	th.Auth.config.SessionExpireTime = 3600
+	th.Auth.config.SessionRefreshTime = 600
+
+	t.Run("recently refreshed session", func(t *testing.T) {
+		now := time.Now().Unix()
+		session := &model.Session{Token: "recentToken", UpdateAt: now - 60, ExpireAt: now + 3540}
+		th.Store.EXPECT().GetSession("recentToken").Return(session, nil)
+
+		got, err := th.Auth.GetSession("recentToken")
+		require.NoError(t, err)
+		require.Equal(t, now+3540, got.ExpireAt)
+	})
+
+	t.Run("session not refreshed within the refresh time", func(t *testing.T) {
+		now := time.Now().Unix()
+		session := &model.Session{Token: "staleToken", UpdateAt: now - 900, ExpireAt: now + 2700}
+		th.Store.EXPECT().GetSession("staleToken").Return(session, nil)
+		th.Store.EXPECT().RefreshSession(session).Return(nil)
+
+		got, err := th.Auth.GetSession("staleToken")
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, got.ExpireAt, now+3600)
+	})
+}
+
+func TestGetAccessTokenSession(t *testing.T) {
+	th := setupTestHelper(t)
+	th.Auth.config.AuthMode = "native"
+
+	token := model.AccessTokenPrefix + "secret"
+	tokenHash := model.HashToken(token)
+
+	t.Run("unknown token", func(t *testing.T) {
+		th.Store.EXPECT().GetAccessTokenByHash(tokenHash).Return(nil, sql.ErrNoRows)
+
+		_, err := th.Auth.GetSession(token)
+		require.Error(t, err)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		accessToken := &model.AccessToken{ID: "token-id", UserID: "user-id", ExpireAt: utils.GetMillis() - 1000}
+		th.Store.EXPECT().GetAccessTokenByHash(tokenHash).Return(accessToken, nil)
+
+		_, err := th.Auth.GetSession(token)
+		require.Error(t, err)
+	})
+
+	t.Run("recently used token", func(t *testing.T) {
+		accessToken := &model.AccessToken{ID: "token-id", UserID: "user-id", LastUsedAt: utils.GetMillis() - 1000}
+		th.Store.EXPECT().GetAccessTokenByHash(tokenHash).Return(accessToken, nil)
+
+		session, err := th.Auth.GetSession(token)
+		require.NoError(t, err)
+		require.Equal(t, "user-id", session.UserID)
+		require.Equal(t, "native", session.AuthService)
+	})
+
+	t.Run("token not used within a minute", func(t *testing.T) {
+		accessToken := &model.AccessToken{ID: "token-id", UserID: "user-id", LastUsedAt: utils.GetMillis() - 120*1000}
+		th.Store.EXPECT().GetAccessTokenByHash(tokenHash).Return(accessToken, nil)
+		th.Store.EXPECT().UpdateAccessTokenLastUsed("token-id", gomock.Any()).Return(nil)
+
+		session, err := th.Auth.GetSession(token)
+		require.NoError(t, err)
+		require.Equal(t, "user-id", session.UserID)
+	})
+}
+
 func TestIsValidReadToken(t *testing.T) {
 	th := setupTestHelper(t)
 
@@ -92,6 +163,11 @@ func TestIsValidReadToken(t *testing.T) {
 		Token:   validReadToken,
 	}
 
+	mockSharingTokens := []model.SharingToken{
+		{Token: "sharingToken", RootID: "testRootID", ExpireAt: utils.GetMillis() + 60*60*1000},
+		{Token: "expiredToken", RootID: "testRootID", ExpireAt: utils.GetMillis() - 1},
+	}
+
 	testcases := []struct {
 		title     string
 		container store.Container
@@ -105,19 +181,22 @@ func TestIsValidReadToken(t *testing.T) {
 		{"fail, sharing throws error", mockContainer, "goodBlockID2", "", true, false},
 		{"fail, bad readToken", mockContainer, validBlockID, "invalidReadToken", false, false},
 		{"success", mockContainer, validBlockID, validReadToken, false, true},
+		{"success, sharing token", mockContainer, validBlockID, "sharingToken", false, true},
+		{"fail, expired sharing token", mockContainer, validBlockID, "expiredToken", true, false},
 	}
 
-	th.Store.EXPECT().GetRootID(gomock.Eq(mockContainer), "badBlock").Return("", errors.New("invalid block"))
-	th.Store.EXPECT().GetRootID(gomock.Eq(mockContainer), "goodBlockID").Return("rootNotFound", nil)
-	th.Store.EXPECT().GetRootID(gomock.Eq(mockContainer), "goodBlockID2").Return("rootError", nil)
-	th.Store.EXPECT().GetRootID(gomock.Eq(mockContainer), validBlockID).Return("testRootID", nil).Times(2)
+	th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(mockContainer), "badBlock").Return("", errors.New("invalid block"))
+	th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(mockContainer), "goodBlockID").Return("rootNotFound", nil)
+	th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(mockContainer), "goodBlockID2").Return("rootError", nil)
+	th.Store.EXPECT().GetRootID(gomock.Any(), gomock.Eq(mockContainer), validBlockID).Return("testRootID", nil).Times(4)
 	th.Store.EXPECT().GetSharing(gomock.Eq(mockContainer), "rootNotFound").Return(nil, sql.ErrNoRows)
 	th.Store.EXPECT().GetSharing(gomock.Eq(mockContainer), "rootError").Return(nil, errors.New("another error"))
-	th.Store.EXPECT().GetSharing(gomock.Eq(mockContainer), "testRootID").Return(&mockSharing, nil).Times(2)
+	th.Store.EXPECT().GetSharing(gomock.Eq(mockContainer), "testRootID").Return(&mockSharing, nil).Times(4)
+	th.Store.EXPECT().GetSharingTokens(gomock.Eq(mockContainer), "testRootID").Return(mockSharingTokens, nil).Times(3)
 
 	for _, test := range testcases {
 		t.Run(test.title, func(t *testing.T) {
-			success, err := th.Auth.IsValidReadToken(test.container, test.blockID, test.readToken)
+			success, err := th.Auth.IsValidReadToken(context.Background(), test.container, test.blockID, test.readToken)
 			if test.isError {
 				require.Error(t, err)
 			} else {
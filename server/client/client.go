@@ -8,10 +8,13 @@ import (
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/mattermost/focalboard/server/api"
 	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/github"
 )
 
 const (
@@ -111,12 +114,16 @@ func (c *Client) DoAPIRequest(method, url, data, etag string) (*http.Response, e
 
 type requestOption func(r *http.Request)
 
-func (c *Client) doAPIRequestReader(method, url string, data io.Reader, _ /* etag */ string, opts ...requestOption) (*http.Response, error) {
+func (c *Client) doAPIRequestReader(method, url string, data io.Reader, etag string, opts ...requestOption) (*http.Response, error) {
 	rq, err := http.NewRequest(method, url, data)
 	if err != nil {
 		return nil, err
 	}
 
+	if etag != "" {
+		rq.Header.Set("If-None-Match", etag)
+	}
+
 	for _, opt := range opts {
 		opt(rq)
 	}
@@ -164,6 +171,50 @@ func (c *Client) GetSubtreeRoute(id string) string {
 	return fmt.Sprintf("%s/subtree", c.GetBlockRoute(id))
 }
 
+func (c *Client) GetUndeleteBlockRoute(id string) string {
+	return fmt.Sprintf("%s/undelete", c.GetBlockRoute(id))
+}
+
+func (c *Client) GetResolveBlockRoute(id string) string {
+	return fmt.Sprintf("/workspaces/0/resolve/%s", id)
+}
+
+// ResolveBlock returns the current location of the block, or its
+// tombstone if it's deleted.
+func (c *Client) ResolveBlock(id string) (*model.ResolvedBlock, *Response) {
+	r, err := c.DoAPIGet(c.GetResolveBlockRoute(id), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var resolved model.ResolvedBlock
+	if err := json.NewDecoder(r.Body).Decode(&resolved); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return &resolved, BuildResponse(r)
+}
+
+func (c *Client) GetBlockHistoryRoute(id string) string {
+	return fmt.Sprintf("%s/history", c.GetBlockRoute(id))
+}
+
+func (c *Client) GetRevertBlockRoute(id string, version int64) string {
+	return fmt.Sprintf("%s/revert/%d", c.GetBlockRoute(id), version)
+}
+
+func (c *Client) GetDuplicateBoardRoute(id string, asTemplate bool) string {
+	return fmt.Sprintf("%s/duplicate?asTemplate=%t", c.GetBlockRoute(id), asTemplate)
+}
+
+func (c *Client) GetSearchBlocksRoute(query string) string {
+	return fmt.Sprintf("%s/search?q=%s", c.GetBlocksRoute(), url.QueryEscape(query))
+}
+
+func (c *Client) GetDeletedBlocksRoute() string {
+	return fmt.Sprintf("%s/trash", c.GetBlocksRoute())
+}
+
 func (c *Client) GetBlocks() ([]model.Block, *Response) {
 	r, err := c.DoAPIGet(c.GetBlocksRoute(), "")
 	if err != nil {
@@ -174,14 +225,110 @@ func (c *Client) GetBlocks() ([]model.Block, *Response) {
 	return model.BlocksFromJSON(r.Body), BuildResponse(r)
 }
 
-func (c *Client) PatchBlock(blockID string, blockPatch *model.BlockPatch) (bool, *Response) {
-	r, err := c.DoAPIPatch(c.GetBlockRoute(blockID), toJSON(blockPatch))
+// GetBlocksWithETag gets the root blocks unless their entity tag is
+// still etag, in which case the response is 304 Not Modified and no
+// blocks are returned.
+func (c *Client) GetBlocksWithETag(etag string) ([]model.Block, *Response) {
+	r, err := c.DoAPIGet(c.GetBlocksRoute(), etag)
 	if err != nil {
-		return false, BuildErrorResponse(r, err)
+		return nil, BuildErrorResponse(r, err)
 	}
 	defer closeBody(r)
 
-	return true, BuildResponse(r)
+	if r.StatusCode == http.StatusNotModified {
+		return nil, BuildResponse(r)
+	}
+
+	return model.BlocksFromJSON(r.Body), BuildResponse(r)
+}
+
+func (c *Client) GetBlocksPageRoute(parentID string, limit int, after string) string {
+	query := url.Values{}
+	query.Set("parent_id", parentID)
+	query.Set("limit", strconv.Itoa(limit))
+	if after != "" {
+		query.Set("after", after)
+	}
+	return fmt.Sprintf("%s?%s", c.GetBlocksRoute(), query.Encode())
+}
+
+// GetBlocksPage gets a page of the child blocks of the parent, the root
+// blocks if it's empty, starting after the cursor of the previous page.
+func (c *Client) GetBlocksPage(parentID string, limit int, after string) (*model.BlocksPage, *Response) {
+	r, err := c.DoAPIGet(c.GetBlocksPageRoute(parentID, limit, after), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var page *model.BlocksPage
+	if err := json.NewDecoder(r.Body).Decode(&page); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return page, BuildResponse(r)
+}
+
+// GetBlocksForView gets the board of the view and its child blocks, with
+// only the cards meeting the filter of the view. The X-Server-Filter
+// header of the response tells if the cards still have to be filtered.
+func (c *Client) GetBlocksForView(viewID string) ([]model.Block, *Response) {
+	r, err := c.DoAPIGet(c.GetBlocksRoute()+"?view_id="+viewID, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.BlocksFromJSON(r.Body), BuildResponse(r)
+}
+
+// PatchBlock patches the block, and returns the block before and after
+// the patch.
+func (c *Client) PatchBlock(blockID string, blockPatch *model.BlockPatch) (*model.BlockPatchResult, *Response) {
+	return c.patchBlock(c.GetBlockRoute(blockID), blockPatch)
+}
+
+// PatchBlockVersion applies the patch to the block only if it still has
+// the version. Otherwise the response has the conflict status, and its
+// error the stored block.
+func (c *Client) PatchBlockVersion(blockID string, blockPatch *model.BlockPatch, version int64) (*model.BlockPatchResult, *Response) {
+	opt := func(r *http.Request) {
+		r.Header.Set("If-Match", strconv.FormatInt(version, 10))
+	}
+	return c.patchBlock(c.GetBlockRoute(blockID), blockPatch, opt)
+}
+
+func (c *Client) patchBlock(route string, blockPatch *model.BlockPatch, opts ...requestOption) (*model.BlockPatchResult, *Response) {
+	r, err := c.doAPIRequestReader(http.MethodPatch, c.APIURL+route, strings.NewReader(toJSON(blockPatch)), "", opts...)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var result model.BlockPatchResult
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &result, BuildResponse(r)
+}
+
+// PatchBlocks applies the patch to several cards at once, and returns
+// the patched cards.
+func (c *Client) PatchBlocks(blocksPatch *model.BlocksPatch) ([]model.Block, *Response) {
+	r, err := c.DoAPIPatch(c.GetBlocksRoute(), toJSON(blocksPatch))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.BlocksFromJSON(r.Body), BuildResponse(r)
+}
+
+// ForcePatchBlock patches the block even if another user holds its
+// editing lock.
+func (c *Client) ForcePatchBlock(blockID string, blockPatch *model.BlockPatch) (*model.BlockPatchResult, *Response) {
+	return c.patchBlock(c.GetBlockRoute(blockID)+"?force=true", blockPatch)
 }
 
 func (c *Client) InsertBlocks(blocks []model.Block) (bool, *Response) {
@@ -194,6 +341,22 @@ func (c *Client) InsertBlocks(blocks []model.Block) (bool, *Response) {
 	return true, BuildResponse(r)
 }
 
+// InsertBlocksIdempotent inserts the blocks with the idempotency key, so
+// that the retries of the request don't insert them again.
+func (c *Client) InsertBlocksIdempotent(blocks []model.Block, key string) (bool, *Response) {
+	opt := func(r *http.Request) {
+		r.Header.Set(model.IdempotencyKeyHeader, key)
+	}
+
+	r, err := c.doAPIRequestReader(http.MethodPost, c.APIURL+c.GetBlocksRoute(), strings.NewReader(toJSON(blocks)), "", opt)
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
 func (c *Client) DeleteBlock(blockID string) (bool, *Response) {
 	r, err := c.DoAPIDelete(c.GetBlockRoute(blockID))
 	if err != nil {
@@ -204,8 +367,18 @@ func (c *Client) DeleteBlock(blockID string) (bool, *Response) {
 	return true, BuildResponse(r)
 }
 
-func (c *Client) GetSubtree(blockID string) ([]model.Block, *Response) {
-	r, err := c.DoAPIGet(c.GetSubtreeRoute(blockID), "")
+func (c *Client) UndeleteBlock(blockID string) (bool, *Response) {
+	r, err := c.DoAPIPost(c.GetUndeleteBlockRoute(blockID), "")
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetDeletedBlocks() ([]model.Block, *Response) {
+	r, err := c.DoAPIGet(c.GetDeletedBlocksRoute(), "")
 	if err != nil {
 		return nil, BuildErrorResponse(r, err)
 	}
@@ -214,128 +387,1639 @@ func (c *Client) GetSubtree(blockID string) ([]model.Block, *Response) {
 	return model.BlocksFromJSON(r.Body), BuildResponse(r)
 }
 
-// Sharing
+func (c *Client) DuplicateBoard(boardID string, asTemplate bool) (*model.Block, *Response) {
+	r, err := c.DoAPIPost(c.GetDuplicateBoardRoute(boardID, asTemplate), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
 
-func (c *Client) GetSharingRoute(rootID string) string {
-	return fmt.Sprintf("/workspaces/0/sharing/%s", rootID)
+	var board model.Block
+	if err := json.NewDecoder(r.Body).Decode(&board); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &board, BuildResponse(r)
 }
 
-func (c *Client) GetSharing(rootID string) (*model.Sharing, *Response) {
-	r, err := c.DoAPIGet(c.GetSharingRoute(rootID), "")
+func (c *Client) GetImportTrelloRoute() string {
+	return "/workspaces/0/import/trello"
+}
+
+// ImportTrello imports a board from the JSON export of a Trello board.
+func (c *Client) ImportTrello(export string) (*model.ImportSummary, *Response) {
+	r, err := c.DoAPIPost(c.GetImportTrelloRoute(), export)
 	if err != nil {
 		return nil, BuildErrorResponse(r, err)
 	}
 	defer closeBody(r)
 
-	sharing := model.SharingFromJSON(r.Body)
-	return &sharing, BuildResponse(r)
+	var summary model.ImportSummary
+	if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &summary, BuildResponse(r)
 }
 
-func (c *Client) PostSharing(sharing model.Sharing) (bool, *Response) {
-	r, err := c.DoAPIPost(c.GetSharingRoute(sharing.ID), toJSON(sharing))
+func (c *Client) GetImportJiraRoute(mode string) string {
+	return "/workspaces/0/import/jira?mode=" + mode
+}
+
+// jiraImportRequest posts the Jira export, with the mapping if any, in
+// the import form.
+func (c *Client) jiraImportRequest(mode string, export io.Reader, mapping *model.JiraImportMapping) (*http.Response, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	if mapping != nil {
+		data, err := json.Marshal(mapping)
+		if err != nil {
+			return nil, err
+		}
+		if err = writer.WriteField(api.JiraImportMappingFormKey, string(data)); err != nil {
+			return nil, err
+		}
+	}
+	part, err := writer.CreateFormFile(api.UploadFormFileKey, "export")
 	if err != nil {
-		return false, BuildErrorResponse(r, err)
+		return nil, err
 	}
-	defer closeBody(r)
+	if _, err = io.Copy(part, export); err != nil {
+		return nil, err
+	}
+	writer.Close()
 
-	return true, BuildResponse(r)
+	opt := func(r *http.Request) {
+		r.Header.Add("Content-Type", writer.FormDataContentType())
+	}
+
+	return c.doAPIRequestReader(http.MethodPost, c.APIURL+c.GetImportJiraRoute(mode), body, "", opt)
 }
 
-func (c *Client) GetRegisterRoute() string {
-	return "/register"
+// PreviewImportJira returns the fields of the Jira export, with a
+// suggested mapping for ImportJira.
+func (c *Client) PreviewImportJira(export io.Reader) (*model.JiraImportPreview, *Response) {
+	r, err := c.jiraImportRequest("preview", export, nil)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var preview model.JiraImportPreview
+	if err := json.NewDecoder(r.Body).Decode(&preview); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &preview, BuildResponse(r)
 }
 
-func (c *Client) Register(request *api.RegisterRequest) (bool, *Response) {
-	r, err := c.DoAPIPost(c.GetRegisterRoute(), toJSON(&request))
+func (c *Client) ImportJira(export io.Reader, mapping model.JiraImportMapping) (*model.ImportSummary, *Response) {
+	r, err := c.jiraImportRequest("commit", export, &mapping)
 	if err != nil {
-		return false, BuildErrorResponse(r, err)
+		return nil, BuildErrorResponse(r, err)
 	}
 	defer closeBody(r)
 
-	return true, BuildResponse(r)
+	var summary model.ImportSummary
+	if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &summary, BuildResponse(r)
 }
 
-func (c *Client) GetLoginRoute() string {
-	return "/login"
+func (c *Client) GetImportNotionRoute() string {
+	return "/workspaces/0/import/notion"
 }
 
-func (c *Client) Login(request *api.LoginRequest) (*api.LoginResponse, *Response) {
-	r, err := c.DoAPIPost(c.GetLoginRoute(), toJSON(&request))
+func (c *Client) ImportNotion(export io.Reader) (*model.ImportSummary, *Response) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(api.UploadFormFileKey, "export.zip")
+	if err != nil {
+		return nil, &Response{Error: err}
+	}
+	if _, err = io.Copy(part, export); err != nil {
+		return nil, &Response{Error: err}
+	}
+	writer.Close()
+
+	opt := func(r *http.Request) {
+		r.Header.Add("Content-Type", writer.FormDataContentType())
+	}
+
+	r, err := c.doAPIRequestReader(http.MethodPost, c.APIURL+c.GetImportNotionRoute(), body, "", opt)
 	if err != nil {
 		return nil, BuildErrorResponse(r, err)
 	}
 	defer closeBody(r)
 
-	data, err := api.LoginResponseFromJSON(r.Body)
+	var summary model.ImportSummary
+	if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &summary, BuildResponse(r)
+}
+
+func (c *Client) GetImportAsanaRoute() string {
+	return "/workspaces/0/import/asana"
+}
+
+func (c *Client) ImportAsana(export string) (*model.ImportSummary, *Response) {
+	r, err := c.DoAPIPost(c.GetImportAsanaRoute(), export)
 	if err != nil {
 		return nil, BuildErrorResponse(r, err)
 	}
+	defer closeBody(r)
 
-	if data.Token != "" {
-		c.Token = data.Token
+	var summary model.ImportSummary
+	if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+		return nil, BuildErrorResponse(r, err)
 	}
 
-	return data, BuildResponse(r)
+	return &summary, BuildResponse(r)
 }
 
-func (c *Client) GetMeRoute() string {
-	return "/users/me"
+func (c *Client) GetBlockHistory(blockID string) ([]model.Block, *Response) {
+	r, err := c.DoAPIGet(c.GetBlockHistoryRoute(blockID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.BlocksFromJSON(r.Body), BuildResponse(r)
 }
 
-func (c *Client) GetMe() (*model.User, *Response) {
-	r, err := c.DoAPIGet(c.GetMeRoute(), "")
+// RevertBlock restores the version of the block from its history, and
+// returns the block before and after the revert.
+func (c *Client) RevertBlock(blockID string, version int64) (*model.BlockPatchResult, *Response) {
+	r, err := c.DoAPIPost(c.GetRevertBlockRoute(blockID, version), "")
 	if err != nil {
 		return nil, BuildErrorResponse(r, err)
 	}
 	defer closeBody(r)
 
-	me, err := model.UserFromJSON(r.Body)
+	var result model.BlockPatchResult
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &result, BuildResponse(r)
+}
+
+func (c *Client) SearchBlocks(query string) ([]model.BlockSearchResult, *Response) {
+	r, err := c.DoAPIGet(c.GetSearchBlocksRoute(query), "")
 	if err != nil {
 		return nil, BuildErrorResponse(r, err)
 	}
-	return me, BuildResponse(r)
+	defer closeBody(r)
+
+	var results []model.BlockSearchResult
+	if err := json.NewDecoder(r.Body).Decode(&results); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return results, BuildResponse(r)
 }
 
-func (c *Client) GetUserRoute(id string) string {
-	return fmt.Sprintf("/users/%s", id)
+func (c *Client) GetSubtree(blockID string) ([]model.Block, *Response) {
+	r, err := c.DoAPIGet(c.GetSubtreeRoute(blockID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.BlocksFromJSON(r.Body), BuildResponse(r)
 }
 
-func (c *Client) GetUser(id string) (*model.User, *Response) {
-	r, err := c.DoAPIGet(c.GetUserRoute(id), "")
+func (c *Client) GetBoardMetadataRoute(boardID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/metadata", boardID)
+}
+
+func (c *Client) GetBoardMetadata(boardID string) ([]model.CardMetadata, *Response) {
+	r, err := c.DoAPIGet(c.GetBoardMetadataRoute(boardID), "")
 	if err != nil {
 		return nil, BuildErrorResponse(r, err)
 	}
 	defer closeBody(r)
 
-	user, err := model.UserFromJSON(r.Body)
+	var metadata []model.CardMetadata
+	if err := json.NewDecoder(r.Body).Decode(&metadata); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return metadata, BuildResponse(r)
+}
+
+func (c *Client) GetBoardPresenceRoute(boardID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/presence", boardID)
+}
+
+func (c *Client) GetBoardPresence(boardID string) ([]string, *Response) {
+	r, err := c.DoAPIGet(c.GetBoardPresenceRoute(boardID), "")
 	if err != nil {
 		return nil, BuildErrorResponse(r, err)
 	}
-	return user, BuildResponse(r)
+	defer closeBody(r)
+
+	var viewers []string
+	if err := json.NewDecoder(r.Body).Decode(&viewers); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return viewers, BuildResponse(r)
 }
 
-func (c *Client) GetUserChangePasswordRoute(id string) string {
-	return fmt.Sprintf("/users/%s/changepassword", id)
+func (c *Client) GetExportBoardCSVRoute(boardID, viewID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/export/csv?viewID=%s", boardID, url.QueryEscape(viewID))
 }
 
-func (c *Client) UserChangePassword(id string, data *api.ChangePasswordRequest) (bool, *Response) {
-	r, err := c.DoAPIPost(c.GetUserChangePasswordRoute(id), toJSON(&data))
+func (c *Client) ExportBoardCSV(boardID, viewID string) (string, *Response) {
+	r, err := c.DoAPIGet(c.GetExportBoardCSVRoute(boardID, viewID), "")
 	if err != nil {
-		return false, BuildErrorResponse(r, err)
+		return "", BuildErrorResponse(r, err)
 	}
 	defer closeBody(r)
 
-	return true, BuildResponse(r)
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", BuildErrorResponse(r, err)
+	}
+
+	return string(data), BuildResponse(r)
 }
 
-func (c *Client) GetWorkspaceUploadFileRoute(workspaceID, rootID string) string {
-	return fmt.Sprintf("/workspaces/%s/%s/files", workspaceID, rootID)
+func (c *Client) GetExportBoardMarkdownRoute(boardID, viewID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/export/markdown?viewID=%s", boardID, url.QueryEscape(viewID))
 }
 
-func (c *Client) WorkspaceUploadFile(workspaceID, rootID string, data io.Reader) (*api.FileUploadResponse, *Response) {
+func (c *Client) ExportBoardMarkdown(boardID, viewID string) (string, *Response) {
+	r, err := c.DoAPIGet(c.GetExportBoardMarkdownRoute(boardID, viewID), "")
+	if err != nil {
+		return "", BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", BuildErrorResponse(r, err)
+	}
+
+	return string(data), BuildResponse(r)
+}
+
+func (c *Client) GetBoardStatisticsRoute(boardID string, opts model.BoardStatisticsOptions) string {
+	query := url.Values{}
+	if opts.SelectPropertyID != "" {
+		query.Set("selectPropertyID", opts.SelectPropertyID)
+	}
+	if opts.PersonPropertyID != "" {
+		query.Set("personPropertyID", opts.PersonPropertyID)
+	}
+	if opts.DoneOptionID != "" {
+		query.Set("doneOptionID", opts.DoneOptionID)
+	}
+	return fmt.Sprintf("/workspaces/0/boards/%s/statistics?%s", boardID, query.Encode())
+}
+
+func (c *Client) GetBoardStatistics(boardID string, opts model.BoardStatisticsOptions) (*model.BoardStatistics, *Response) {
+	r, err := c.DoAPIGet(c.GetBoardStatisticsRoute(boardID, opts), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var stats *model.BoardStatistics
+	if err := json.NewDecoder(r.Body).Decode(&stats); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return stats, BuildResponse(r)
+}
+
+func (c *Client) GetCardActivityRoute(cardID string, limit int, before int64) string {
+	query := url.Values{}
+	if limit != 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if before != 0 {
+		query.Set("before", strconv.FormatInt(before, 10))
+	}
+	return fmt.Sprintf("/workspaces/0/cards/%s/activity?%s", cardID, query.Encode())
+}
+
+func (c *Client) GetCardActivity(cardID string, limit int, before int64) (*model.CardActivityPage, *Response) {
+	r, err := c.DoAPIGet(c.GetCardActivityRoute(cardID, limit, before), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var page *model.CardActivityPage
+	if err := json.NewDecoder(r.Body).Decode(&page); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return page, BuildResponse(r)
+}
+
+func (c *Client) GetCardBacklinksRoute(cardID string) string {
+	return fmt.Sprintf("/workspaces/0/cards/%s/backlinks", cardID)
+}
+
+func (c *Client) GetCardBacklinks(cardID string) ([]model.CardBacklink, *Response) {
+	r, err := c.DoAPIGet(c.GetCardBacklinksRoute(cardID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var backlinks []model.CardBacklink
+	if err := json.NewDecoder(r.Body).Decode(&backlinks); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return backlinks, BuildResponse(r)
+}
+
+func (c *Client) GetMoveCardRoute(cardID string) string {
+	return fmt.Sprintf("/workspaces/0/cards/%s/move", cardID)
+}
+
+func (c *Client) MoveCard(cardID string, move model.CardMove) (*model.Block, *Response) {
+	r, err := c.DoAPIPatch(c.GetMoveCardRoute(cardID), toJSON(move))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var card model.Block
+	if err := json.NewDecoder(r.Body).Decode(&card); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &card, BuildResponse(r)
+}
+
+func (c *Client) GetCardByNumberRoute(boardID string, number int64) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/cards/by-number/%d", boardID, number)
+}
+
+func (c *Client) GetCardByNumber(boardID string, number int64) (*model.Block, *Response) {
+	r, err := c.DoAPIGet(c.GetCardByNumberRoute(boardID, number), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var card *model.Block
+	if err := json.NewDecoder(r.Body).Decode(&card); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return card, BuildResponse(r)
+}
+
+func (c *Client) GetQuickAddRoute(boardID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/cards/quick-add", boardID)
+}
+
+func (c *Client) QuickAddCard(boardID, text string) (*model.Block, *Response) {
+	return c.quickAddCard(boardID, text)
+}
+
+// QuickAddCardIdempotent creates the card from the text with the
+// idempotency key, so that the retries of the request don't create the
+// card again.
+func (c *Client) QuickAddCardIdempotent(boardID, text, key string) (*model.Block, *Response) {
+	opt := func(r *http.Request) {
+		r.Header.Set(model.IdempotencyKeyHeader, key)
+	}
+	return c.quickAddCard(boardID, text, opt)
+}
+
+func (c *Client) quickAddCard(boardID, text string, opts ...requestOption) (*model.Block, *Response) {
+	r, err := c.doAPIRequestReader(http.MethodPost, c.APIURL+c.GetQuickAddRoute(boardID), strings.NewReader(toJSON(api.QuickAddRequest{Text: text})), "", opts...)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var card model.Block
+	if err := json.NewDecoder(r.Body).Decode(&card); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &card, BuildResponse(r)
+}
+
+func (c *Client) GetWorkspaceArchiveRoute() string {
+	return "/workspaces/0/archive"
+}
+
+func (c *Client) ExportWorkspaceArchive() ([]byte, *Response) {
+	r, err := c.DoAPIGet(c.GetWorkspaceArchiveRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return data, BuildResponse(r)
+}
+
+func (c *Client) ImportWorkspaceArchive(archive []byte) (*model.ImportSummary, *Response) {
+	r, err := c.DoAPIPost(c.GetWorkspaceArchiveRoute(), string(archive))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var summary model.ImportSummary
+	if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &summary, BuildResponse(r)
+}
+
+// Sharing
+
+func (c *Client) GetSharingRoute(rootID string) string {
+	return fmt.Sprintf("/workspaces/0/sharing/%s", rootID)
+}
+
+func (c *Client) GetSharing(rootID string) (*model.Sharing, *Response) {
+	r, err := c.DoAPIGet(c.GetSharingRoute(rootID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	sharing := model.SharingFromJSON(r.Body)
+	return &sharing, BuildResponse(r)
+}
+
+func (c *Client) PostSharing(sharing model.Sharing) (bool, *Response) {
+	r, err := c.DoAPIPost(c.GetSharingRoute(sharing.ID), toJSON(sharing))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetSharingTokensRoute(rootID string) string {
+	return fmt.Sprintf("%s/tokens", c.GetSharingRoute(rootID))
+}
+
+func (c *Client) PostSharingToken(rootID string, request api.SharingTokenRequest) (*model.SharingToken, *Response) {
+	r, err := c.DoAPIPost(c.GetSharingTokensRoute(rootID), toJSON(request))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	token, err := model.SharingTokenFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return token, BuildResponse(r)
+}
+
+func (c *Client) DeleteSharingToken(rootID, token string) (bool, *Response) {
+	r, err := c.DoAPIDelete(fmt.Sprintf("%s/%s", c.GetSharingTokensRoute(rootID), token))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetCalendarFeedRoute(boardID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/calendar", boardID)
+}
+
+func (c *Client) GetCalendarFeed(boardID string) (*model.CalendarFeed, *Response) {
+	r, err := c.DoAPIGet(c.GetCalendarFeedRoute(boardID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var feed *model.CalendarFeed
+	if err := json.NewDecoder(r.Body).Decode(&feed); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return feed, BuildResponse(r)
+}
+
+func (c *Client) PostCalendarFeed(boardID string) (*model.CalendarFeed, *Response) {
+	r, err := c.DoAPIPost(c.GetCalendarFeedRoute(boardID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var feed *model.CalendarFeed
+	if err := json.NewDecoder(r.Body).Decode(&feed); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return feed, BuildResponse(r)
+}
+
+func (c *Client) DeleteCalendarFeed(boardID string) (bool, *Response) {
+	r, err := c.DoAPIDelete(c.GetCalendarFeedRoute(boardID))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetInboundHookRoute(boardID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/inbound-hook", boardID)
+}
+
+func (c *Client) GetInboundHook(boardID string) (*model.InboundHook, *Response) {
+	r, err := c.DoAPIGet(c.GetInboundHookRoute(boardID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var hook *model.InboundHook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return hook, BuildResponse(r)
+}
+
+func (c *Client) PostInboundHook(boardID string, request api.InboundHookRequest) (*model.InboundHook, *Response) {
+	r, err := c.DoAPIPost(c.GetInboundHookRoute(boardID), toJSON(request))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var hook *model.InboundHook
+	if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return hook, BuildResponse(r)
+}
+
+func (c *Client) DeleteInboundHook(boardID string) (bool, *Response) {
+	r, err := c.DoAPIDelete(c.GetInboundHookRoute(boardID))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetInboundHookDeliveries(boardID string) ([]model.InboundHookDelivery, *Response) {
+	r, err := c.DoAPIGet(c.GetInboundHookRoute(boardID)+"/deliveries", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var deliveries []model.InboundHookDelivery
+	if err := json.NewDecoder(r.Body).Decode(&deliveries); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return deliveries, BuildResponse(r)
+}
+
+func (c *Client) GetInboundHookDeliveryRoute(token string) string {
+	return fmt.Sprintf("/hooks/%s", url.PathEscape(token))
+}
+
+// PostInboundHookDelivery posts the payload to the inbound hook with the
+// token, as an integration would.
+func (c *Client) PostInboundHookDelivery(token string, payload string) (*model.Block, *Response) {
+	r, err := c.DoAPIPost(c.GetInboundHookDeliveryRoute(token), payload)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var card *model.Block
+	if err := json.NewDecoder(r.Body).Decode(&card); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return card, BuildResponse(r)
+}
+
+func (c *Client) GetGitHubIntegrationRoute(boardID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/github", boardID)
+}
+
+func (c *Client) GetGitHubIntegration(boardID string) (*model.GitHubIntegration, *Response) {
+	r, err := c.DoAPIGet(c.GetGitHubIntegrationRoute(boardID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var integration *model.GitHubIntegration
+	if err := json.NewDecoder(r.Body).Decode(&integration); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return integration, BuildResponse(r)
+}
+
+func (c *Client) PutGitHubIntegration(boardID string, request api.GitHubIntegrationRequest) (*model.GitHubIntegration, *Response) {
+	r, err := c.DoAPIPut(c.GetGitHubIntegrationRoute(boardID), toJSON(request))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var integration *model.GitHubIntegration
+	if err := json.NewDecoder(r.Body).Decode(&integration); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return integration, BuildResponse(r)
+}
+
+func (c *Client) DeleteGitHubIntegration(boardID string) (bool, *Response) {
+	r, err := c.DoAPIDelete(c.GetGitHubIntegrationRoute(boardID))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) PostGitHubSync(boardID string) (*model.GitHubIntegration, *Response) {
+	r, err := c.DoAPIPost(c.GetGitHubIntegrationRoute(boardID)+"/sync", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var integration *model.GitHubIntegration
+	if err := json.NewDecoder(r.Body).Decode(&integration); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return integration, BuildResponse(r)
+}
+
+// PostGitHubWebhook posts the payload of the event to the GitHub webhook
+// of the board with the signature, as GitHub would.
+func (c *Client) PostGitHubWebhook(boardID, event, signature, payload string) (bool, *Response) {
+	opt := func(r *http.Request) {
+		r.Header.Set(github.EventHeader, event)
+		r.Header.Set(github.SignatureHeader, signature)
+	}
+
+	r, err := c.doAPIRequestReader(http.MethodPost, c.APIURL+c.GetGitHubIntegrationRoute(boardID)+"/webhook", strings.NewReader(payload), "", opt)
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetBoardCalendarRoute(boardID, token string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/calendar.ics?token=%s", boardID, url.QueryEscape(token))
+}
+
+func (c *Client) GetBoardCalendar(boardID, token string) (string, *Response) {
+	r, err := c.DoAPIGet(c.GetBoardCalendarRoute(boardID, token), "")
+	if err != nil {
+		return "", BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", BuildErrorResponse(r, err)
+	}
+
+	return string(data), BuildResponse(r)
+}
+
+func (c *Client) GetBoardEmbedRoute(boardID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/embed", boardID)
+}
+
+func (c *Client) PostBoardEmbed(boardID string, request api.BoardEmbedRequest) (*model.BoardEmbed, *Response) {
+	r, err := c.DoAPIPost(c.GetBoardEmbedRoute(boardID), toJSON(request))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var embed *model.BoardEmbed
+	if err := json.NewDecoder(r.Body).Decode(&embed); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return embed, BuildResponse(r)
+}
+
+func (c *Client) GetBoardMembersRoute(boardID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/members", boardID)
+}
+
+func (c *Client) GetBoardMembers(boardID string) ([]model.BoardMember, *Response) {
+	r, err := c.DoAPIGet(c.GetBoardMembersRoute(boardID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var members []model.BoardMember
+	if err := json.NewDecoder(r.Body).Decode(&members); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return members, BuildResponse(r)
+}
+
+func (c *Client) AddBoardMember(boardID, userID, role string) (*model.BoardMember, *Response) {
+	r, err := c.DoAPIPost(c.GetBoardMembersRoute(boardID), toJSON(api.BoardMemberRequest{UserID: userID, Role: role}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var member *model.BoardMember
+	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return member, BuildResponse(r)
+}
+
+func (c *Client) UpdateBoardMember(boardID, userID, role string) (*model.BoardMember, *Response) {
+	r, err := c.DoAPIPut(fmt.Sprintf("%s/%s", c.GetBoardMembersRoute(boardID), userID), toJSON(api.BoardMemberRequest{Role: role}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var member *model.BoardMember
+	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return member, BuildResponse(r)
+}
+
+func (c *Client) DeleteBoardMember(boardID, userID string) (bool, *Response) {
+	r, err := c.DoAPIDelete(fmt.Sprintf("%s/%s", c.GetBoardMembersRoute(boardID), userID))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetGuestInvitesRoute(boardID string) string {
+	return fmt.Sprintf("/workspaces/0/boards/%s/guest_invites", boardID)
+}
+
+func (c *Client) CreateGuestInvite(boardID, role string) (*api.GuestInviteResponse, *Response) {
+	r, err := c.DoAPIPost(c.GetGuestInvitesRoute(boardID), toJSON(api.GuestInviteRequest{Role: role}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var invite *api.GuestInviteResponse
+	if err := json.NewDecoder(r.Body).Decode(&invite); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return invite, BuildResponse(r)
+}
+
+func (c *Client) GetWorkspaceUsersRoute() string {
+	return "/workspaces/0/users"
+}
+
+func (c *Client) GetSearchWorkspaceUsersRoute(query string) string {
+	return fmt.Sprintf("%s/search?q=%s", c.GetWorkspaceUsersRoute(), url.QueryEscape(query))
+}
+
+func (c *Client) SearchWorkspaceUsers(query string) ([]model.UserSearchResult, *Response) {
+	r, err := c.DoAPIGet(c.GetSearchWorkspaceUsersRoute(query), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var users []model.UserSearchResult
+	if err := json.NewDecoder(r.Body).Decode(&users); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return users, BuildResponse(r)
+}
+
+func (c *Client) GetWorkspaceUsers(boardID string) ([]*model.User, *Response) {
+	query := ""
+	if boardID != "" {
+		query = "?board_id=" + url.QueryEscape(boardID)
+	}
+
+	r, err := c.DoAPIGet(c.GetWorkspaceUsersRoute()+query, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var users []*model.User
+	if err := json.NewDecoder(r.Body).Decode(&users); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return users, BuildResponse(r)
+}
+
+func (c *Client) GetTemplatesRoute() string {
+	return "/templates"
+}
+
+func (c *Client) GetTemplates() ([]model.BoardTemplate, *Response) {
+	r, err := c.DoAPIGet(c.GetTemplatesRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var templates []model.BoardTemplate
+	if err := json.NewDecoder(r.Body).Decode(&templates); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return templates, BuildResponse(r)
+}
+
+func (c *Client) GetNotificationsRoute() string {
+	return "/notifications"
+}
+
+func (c *Client) GetNotifications() ([]model.Notification, *Response) {
+	r, err := c.DoAPIGet(c.GetNotificationsRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var notifications []model.Notification
+	if err := json.NewDecoder(r.Body).Decode(&notifications); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return notifications, BuildResponse(r)
+}
+
+func (c *Client) GetSubscriptionsRoute() string {
+	return "/workspaces/0/subscriptions"
+}
+
+func (c *Client) GetSubscriptionRoute(blockID string) string {
+	return fmt.Sprintf("%s/subscription", c.GetBlockRoute(blockID))
+}
+
+func (c *Client) GetSubscriptions() ([]model.Subscription, *Response) {
+	r, err := c.DoAPIGet(c.GetSubscriptionsRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var subscriptions []model.Subscription
+	if err := json.NewDecoder(r.Body).Decode(&subscriptions); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return subscriptions, BuildResponse(r)
+}
+
+func (c *Client) Subscribe(blockID string) (*model.Subscription, *Response) {
+	r, err := c.DoAPIPost(c.GetSubscriptionRoute(blockID), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var subscription model.Subscription
+	if err := json.NewDecoder(r.Body).Decode(&subscription); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &subscription, BuildResponse(r)
+}
+
+func (c *Client) Unsubscribe(blockID string) (bool, *Response) {
+	r, err := c.DoAPIDelete(c.GetSubscriptionRoute(blockID))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetPingRoute() string {
+	return "/ping"
+}
+
+func (c *Client) Ping() (*model.ServerHealth, *Response) {
+	r, err := c.DoAPIGet(c.GetPingRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var health model.ServerHealth
+	if err := json.NewDecoder(r.Body).Decode(&health); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &health, BuildResponse(r)
+}
+
+func (c *Client) GetHealthRoute() string {
+	return "/health"
+}
+
+func (c *Client) Health() (*model.Liveness, *Response) {
+	r, err := c.DoAPIGet(c.GetHealthRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var liveness model.Liveness
+	if err := json.NewDecoder(r.Body).Decode(&liveness); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &liveness, BuildResponse(r)
+}
+
+func (c *Client) GetReadinessRoute() string {
+	return "/readiness"
+}
+
+func (c *Client) Readiness() (*model.Readiness, *Response) {
+	r, err := c.DoAPIGet(c.GetReadinessRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var readiness model.Readiness
+	if err := json.NewDecoder(r.Body).Decode(&readiness); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &readiness, BuildResponse(r)
+}
+
+func (c *Client) GetRegisterRoute() string {
+	return "/register"
+}
+
+func (c *Client) Register(request *api.RegisterRequest) (bool, *Response) {
+	r, err := c.DoAPIPost(c.GetRegisterRoute(), toJSON(&request))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetRegisterGuestRoute() string {
+	return "/register/guest"
+}
+
+func (c *Client) RegisterGuest(request *api.RegisterRequest) (bool, *Response) {
+	r, err := c.DoAPIPost(c.GetRegisterGuestRoute(), toJSON(&request))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetLoginRoute() string {
+	return "/login"
+}
+
+func (c *Client) Login(request *api.LoginRequest) (*api.LoginResponse, *Response) {
+	r, err := c.DoAPIPost(c.GetLoginRoute(), toJSON(&request))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	data, err := api.LoginResponseFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	if data.Token != "" {
+		c.Token = data.Token
+	}
+
+	return data, BuildResponse(r)
+}
+
+func (c *Client) GetLogoutRoute() string {
+	return "/logout"
+}
+
+func (c *Client) Logout() (bool, *Response) {
+	r, err := c.DoAPIPost(c.GetLogoutRoute(), "")
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	c.Token = ""
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetMeRoute() string {
+	return "/users/me"
+}
+
+func (c *Client) GetMe() (*model.User, *Response) {
+	r, err := c.DoAPIGet(c.GetMeRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	me, err := model.UserFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return me, BuildResponse(r)
+}
+
+func (c *Client) GetClientConfigRoute() string {
+	return "/clientConfig"
+}
+
+// GetClientConfig returns the configuration of the server needed by the
+// clients.
+func (c *Client) GetClientConfig() (*model.ClientConfig, *Response) {
+	r, err := c.DoAPIGet(c.GetClientConfigRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var clientConfig *model.ClientConfig
+	if err := json.NewDecoder(r.Body).Decode(&clientConfig); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return clientConfig, BuildResponse(r)
+}
+
+func (c *Client) GetConstantsRoute() string {
+	return "/constants"
+}
+
+// GetConstants returns the enumerations shared by the server and the
+// clients.
+func (c *Client) GetConstants() (*model.Constants, *Response) {
+	r, err := c.DoAPIGet(c.GetConstantsRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var constants model.Constants
+	if err := json.NewDecoder(r.Body).Decode(&constants); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return &constants, BuildResponse(r)
+}
+
+func (c *Client) GetDigestSettingsRoute() string {
+	return fmt.Sprintf("%s/digest", c.GetMeRoute())
+}
+
+func (c *Client) UpdateDigestSettings(settings model.DigestSettings) (*model.DigestSettings, *Response) {
+	r, err := c.DoAPIPut(c.GetDigestSettingsRoute(), toJSON(settings))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var updated model.DigestSettings
+	if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &updated, BuildResponse(r)
+}
+
+func (c *Client) GetPreferencesRoute() string {
+	return fmt.Sprintf("%s/preferences", c.GetMeRoute())
+}
+
+func (c *Client) GetPreferences() ([]model.Preference, *Response) {
+	r, err := c.DoAPIGet(c.GetPreferencesRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return preferencesFromResponse(r)
+}
+
+func (c *Client) UpdatePreferences(preferences []model.Preference) ([]model.Preference, *Response) {
+	r, err := c.DoAPIPut(c.GetPreferencesRoute(), toJSON(preferences))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return preferencesFromResponse(r)
+}
+
+func (c *Client) DeletePreferences(preferences []model.Preference) ([]model.Preference, *Response) {
+	r, err := c.DoAPIPost(c.GetPreferencesRoute()+"/delete", toJSON(preferences))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return preferencesFromResponse(r)
+}
+
+func preferencesFromResponse(r *http.Response) ([]model.Preference, *Response) {
+	var preferences []model.Preference
+	if err := json.NewDecoder(r.Body).Decode(&preferences); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return preferences, BuildResponse(r)
+}
+
+func (c *Client) GetAccessTokensRoute() string {
+	return fmt.Sprintf("%s/tokens", c.GetMeRoute())
+}
+
+func (c *Client) CreateAccessToken(request api.AccessTokenRequest) (*model.AccessToken, *Response) {
+	r, err := c.DoAPIPost(c.GetAccessTokensRoute(), toJSON(request))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var token model.AccessToken
+	if err := json.NewDecoder(r.Body).Decode(&token); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &token, BuildResponse(r)
+}
+
+func (c *Client) GetAccessTokens() ([]model.AccessToken, *Response) {
+	r, err := c.DoAPIGet(c.GetAccessTokensRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var tokens []model.AccessToken
+	if err := json.NewDecoder(r.Body).Decode(&tokens); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return tokens, BuildResponse(r)
+}
+
+func (c *Client) DeleteAccessToken(tokenID string) (bool, *Response) {
+	r, err := c.DoAPIDelete(fmt.Sprintf("%s/%s", c.GetAccessTokensRoute(), tokenID))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetMfaRoute() string {
+	return "/users/me/mfa"
+}
+
+func (c *Client) ActivateMfa() (*api.MfaActivateResponse, *Response) {
+	r, err := c.DoAPIPost(c.GetMfaRoute()+"/activate", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var activation api.MfaActivateResponse
+	if err := json.NewDecoder(r.Body).Decode(&activation); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &activation, BuildResponse(r)
+}
+
+func (c *Client) ConfirmMfa(code string) ([]string, *Response) {
+	r, err := c.DoAPIPost(c.GetMfaRoute()+"/confirm", toJSON(&api.MfaCodeRequest{Code: code}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var confirmation api.MfaConfirmResponse
+	if err := json.NewDecoder(r.Body).Decode(&confirmation); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return confirmation.RecoveryCodes, BuildResponse(r)
+}
+
+func (c *Client) DeactivateMfa(code string) (bool, *Response) {
+	r, err := c.DoAPIPost(c.GetMfaRoute()+"/deactivate", toJSON(&api.MfaCodeRequest{Code: code}))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetPasswordResetRoute() string {
+	return "/users/password-reset"
+}
+
+func (c *Client) RequestPasswordReset(request *api.PasswordResetRequest) (bool, *Response) {
+	r, err := c.DoAPIPost(c.GetPasswordResetRoute(), toJSON(request))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) CompletePasswordReset(request *api.CompletePasswordResetRequest) (bool, *Response) {
+	r, err := c.DoAPIPost(c.GetPasswordResetRoute()+"/complete", toJSON(request))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetUserRoute(id string) string {
+	return fmt.Sprintf("/users/%s", id)
+}
+
+func (c *Client) GetUser(id string) (*model.User, *Response) {
+	r, err := c.DoAPIGet(c.GetUserRoute(id), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	user, err := model.UserFromJSON(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return user, BuildResponse(r)
+}
+
+func (c *Client) GetUserImageRoute(id string) string {
+	return fmt.Sprintf("/users/%s/image", id)
+}
+
+// SetUserImage uploads the image of the avatar of the user.
+func (c *Client) SetUserImage(id string, data io.Reader) (*api.UserImageResponse, *Response) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(api.UploadFormFileKey, "avatar")
+	if err != nil {
+		return nil, &Response{Error: err}
+	}
+	if _, err = io.Copy(part, data); err != nil {
+		return nil, &Response{Error: err}
+	}
+	writer.Close()
+
+	opt := func(r *http.Request) {
+		r.Header.Add("Content-Type", writer.FormDataContentType())
+	}
+
+	r, err := c.doAPIRequestReader(http.MethodPut, c.APIURL+c.GetUserImageRoute(id), body, "", opt)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var response api.UserImageResponse
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &response, BuildResponse(r)
+}
+
+// GetUserImage downloads the avatar of the user, passing the time it
+// was set if it's not zero.
+func (c *Client) GetUserImage(id string, updateAt int64) ([]byte, *Response) {
+	route := c.GetUserImageRoute(id)
+	if updateAt != 0 {
+		route += "?t=" + strconv.FormatInt(updateAt, 10)
+	}
+	r, err := c.doAPIRequestReader(http.MethodGet, c.APIURL+route, nil, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return data, BuildResponse(r)
+}
+
+func (c *Client) GetUserChangePasswordRoute(id string) string {
+	return fmt.Sprintf("/users/%s/changepassword", id)
+}
+
+func (c *Client) UserChangePassword(id string, data *api.ChangePasswordRequest) (bool, *Response) {
+	r, err := c.DoAPIPost(c.GetUserChangePasswordRoute(id), toJSON(&data))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetAdminUsersRoute() string {
+	return "/admin/users"
+}
+
+func (c *Client) AdminGetUsers(opts model.QueryUsersOptions) ([]*model.User, *Response) {
+	query := url.Values{}
+	if opts.Active != nil {
+		query.Set("active", strconv.FormatBool(*opts.Active))
+	}
+	if opts.Username != "" {
+		query.Set("username", opts.Username)
+	}
+	if opts.Page != 0 {
+		query.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.PerPage != 0 {
+		query.Set("per_page", strconv.Itoa(opts.PerPage))
+	}
+
+	route := c.GetAdminUsersRoute()
+	if len(query) > 0 {
+		route += "?" + query.Encode()
+	}
+	r, err := c.DoAPIGet(route, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var users []*model.User
+	if err := json.NewDecoder(r.Body).Decode(&users); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return users, BuildResponse(r)
+}
+
+func (c *Client) AdminDeactivateUser(id string) (bool, *Response) {
+	r, err := c.DoAPIPut(fmt.Sprintf("%s/%s/deactivate", c.GetAdminUsersRoute(), id), "")
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) AdminActivateUser(id string) (bool, *Response) {
+	r, err := c.DoAPIPut(fmt.Sprintf("%s/%s/activate", c.GetAdminUsersRoute(), id), "")
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) AdminResetUserPassword(id, password string) (bool, *Response) {
+	r, err := c.DoAPIPut(fmt.Sprintf("%s/%s/password", c.GetAdminUsersRoute(), id), toJSON(api.AdminSetPasswordData{Password: password}))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return true, BuildResponse(r)
+}
+
+func (c *Client) GetAdminStatisticsRoute() string {
+	return "/admin/statistics"
+}
+
+func (c *Client) AdminGetStatistics() (*model.WorkspaceStats, *Response) {
+	r, err := c.DoAPIGet(c.GetAdminStatisticsRoute(), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var stats model.WorkspaceStats
+	if err := json.NewDecoder(r.Body).Decode(&stats); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	return &stats, BuildResponse(r)
+}
+
+func (c *Client) GetWorkspaceSettingsRoute() string {
+	return "/workspaces/0/settings"
+}
+
+func (c *Client) PatchWorkspaceSettings(patch model.WorkspaceSettingsPatch) (*model.WorkspaceSettings, *Response) {
+	r, err := c.DoAPIPatch(c.GetWorkspaceSettingsRoute(), toJSON(patch))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var settings model.WorkspaceSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &settings, BuildResponse(r)
+}
+
+func (c *Client) GetBoardsRoute() string {
+	return "/workspaces/0/boards"
+}
+
+// GetBoards returns the boards of the workspace, the archived ones or
+// the others.
+func (c *Client) GetBoards(archived bool) ([]model.Block, *Response) {
+	route := c.GetBoardsRoute()
+	if archived {
+		route += "?archived=true"
+	}
+	r, err := c.DoAPIGet(route, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	return model.BlocksFromJSON(r.Body), BuildResponse(r)
+}
+
+func (c *Client) GetDefaultCardTemplateRoute() string {
+	return c.GetWorkspaceSettingsRoute() + "/default_card_template"
+}
+
+// SetDefaultCardTemplate sets the default card template of the
+// workspace, or clears it if the template ID is empty.
+func (c *Client) SetDefaultCardTemplate(templateID string) (*model.WorkspaceSettings, *Response) {
+	r, err := c.DoAPIPut(c.GetDefaultCardTemplateRoute(), toJSON(model.DefaultCardTemplateRequest{TemplateID: templateID}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var settings model.WorkspaceSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return &settings, BuildResponse(r)
+}
+
+func (c *Client) GetUserWorkspacesRoute(cursor string, limit int) string {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	return fmt.Sprintf("/workspaces?%s", query.Encode())
+}
+
+// GetUserWorkspaces gets a page of the workspaces of the current user,
+// starting after the ID of the last workspace of the previous page.
+func (c *Client) GetUserWorkspaces(cursor string, limit int) (*model.UserWorkspacesPage, *Response) {
+	r, err := c.DoAPIGet(c.GetUserWorkspacesRoute(cursor, limit), "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	var page *model.UserWorkspacesPage
+	if err := json.NewDecoder(r.Body).Decode(&page); err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return page, BuildResponse(r)
+}
+
+func (c *Client) GetWorkspaceUploadFileRoute(workspaceID, rootID string) string {
+	return fmt.Sprintf("/workspaces/%s/%s/files", workspaceID, rootID)
+}
+
+func (c *Client) GetFileRoute(workspaceID, rootID, fileID string) string {
+	return fmt.Sprintf("/files/workspaces/%s/%s/%s", workspaceID, rootID, fileID)
+}
+
+// GetFile downloads the file, or only the requested bytes if byteRange
+// is a range header value like "bytes=0-99".
+func (c *Client) GetFile(workspaceID, rootID, fileID, byteRange string) ([]byte, *Response) {
+	opt := func(r *http.Request) {
+		if byteRange != "" {
+			r.Header.Set("Range", byteRange)
+		}
+	}
+
+	r, err := c.doAPIRequestReader(http.MethodGet, c.URL+c.GetFileRoute(workspaceID, rootID, fileID), nil, "", opt)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return data, BuildResponse(r)
+}
+
+// GetFileThumbnail downloads the thumbnail of the image, or the image
+// while it has none.
+func (c *Client) GetFileThumbnail(workspaceID, rootID, fileID string) ([]byte, *Response) {
+	r, err := c.doAPIRequestReader(http.MethodGet, c.URL+c.GetFileRoute(workspaceID, rootID, fileID)+"?thumbnail=true", nil, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+
+	return data, BuildResponse(r)
+}
+
+func (c *Client) WorkspaceUploadFile(workspaceID, rootID string, data io.Reader) (*api.FileUploadResponse, *Response) {
+	return c.WorkspaceUploadNamedFile(workspaceID, rootID, "file", data)
+}
+
+// WorkspaceUploadNamedFile uploads the file with the name, whose
+// extension is the one of the stored file.
+func (c *Client) WorkspaceUploadNamedFile(workspaceID, rootID, filename string, data io.Reader) (*api.FileUploadResponse, *Response) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile(api.UploadFormFileKey, "file")
+	part, err := writer.CreateFormFile(api.UploadFormFileKey, filename)
 	if err != nil {
 		return nil, &Response{Error: err}
 	}
@@ -4,14 +4,17 @@ go 1.16
 
 require (
 	github.com/Masterminds/squirrel v1.5.0
+	github.com/andybalholm/brotli v1.0.3
 	github.com/go-sql-driver/mysql v1.6.0
 	github.com/golang-migrate/migrate/v4 v4.14.1
 	github.com/golang/mock v1.5.0
 	github.com/google/uuid v1.2.0
 	github.com/gorilla/mux v1.8.0
+	github.com/go-asn1-ber/asn1-ber v1.5.3
 	github.com/gorilla/websocket v1.4.2
 	github.com/lib/pq v1.10.2
 	github.com/magiconair/properties v1.8.5 // indirect
+	github.com/mattermost/ldap v0.0.0-20201202150706-ee0e6284187d
 	github.com/mattermost/logr/v2 v2.0.11 // indirect
 	github.com/mattermost/mattermost-server/v6 v6.0.0-20210824141503-6031b163d8e3
 	github.com/mattn/go-sqlite3 v2.0.3+incompatible
@@ -27,4 +30,5 @@ require (
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20210616213533-5ff15b29337e
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
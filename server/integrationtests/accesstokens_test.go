@@ -0,0 +1,90 @@
+package integrationtests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokens(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	success, resp := th.Client.Register(&api.RegisterRequest{
+		Username: fakeUsername,
+		Email:    fakeEmail,
+		Password: password,
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	_, resp = th.Client.Login(&api.LoginRequest{
+		Type:     "normal",
+		Username: fakeUsername,
+		Password: password,
+	})
+	require.NoError(t, resp.Error)
+
+	token, resp := th.Client.CreateAccessToken(api.AccessTokenRequest{Description: "automation"})
+	require.NoError(t, resp.Error)
+	require.True(t, strings.HasPrefix(token.Token, model.AccessTokenPrefix))
+	require.Equal(t, "automation", token.Description)
+
+	tokenClient := client.NewClient(th.Server.Config().ServerRoot, token.Token)
+
+	t.Run("the token authenticates the requests", func(t *testing.T) {
+		me, resp := tokenClient.GetMe()
+		require.NoError(t, resp.Error)
+		require.Equal(t, fakeUsername, me.Username)
+	})
+
+	t.Run("the token isn't listed", func(t *testing.T) {
+		tokens, resp := th.Client.GetAccessTokens()
+		require.NoError(t, resp.Error)
+		require.Len(t, tokens, 1)
+		require.Equal(t, token.ID, tokens[0].ID)
+		require.Empty(t, tokens[0].Token)
+		require.NotZero(t, tokens[0].LastUsedAt)
+	})
+
+	t.Run("a token can't be used to log out", func(t *testing.T) {
+		_, resp := tokenClient.Logout()
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("a revoked token can't be used anymore", func(t *testing.T) {
+		success, resp := th.Client.DeleteAccessToken(token.ID)
+		require.NoError(t, resp.Error)
+		require.True(t, success)
+
+		_, resp = tokenClient.GetMe()
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		_, resp = th.Client.DeleteAccessToken(token.ID)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("an expiry in the past is rejected", func(t *testing.T) {
+		_, resp := th.Client.CreateAccessToken(api.AccessTokenRequest{ExpireAt: utils.GetMillis() - 1000})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestAccessTokensSingleUser(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	_, resp := th.Client.CreateAccessToken(api.AccessTokenRequest{})
+	require.Error(t, resp.Error)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,53 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCardActivity(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	blocks := []model.Block{
+		{ID: boardID, RootID: boardID, Type: "board", CreateAt: now, UpdateAt: now},
+		{ID: cardID, RootID: boardID, ParentID: boardID, Type: "card", Title: "Card", CreateAt: now, UpdateAt: now},
+		{ID: utils.CreateGUID(), RootID: boardID, ParentID: cardID, Type: "comment", Title: "Comment", CreateAt: now, UpdateAt: now},
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Get the activity", func(t *testing.T) {
+		page, resp := th.Client.GetCardActivity(cardID, 0, 0)
+		require.NoError(t, resp.Error)
+		require.False(t, page.HasMore)
+		require.Len(t, page.Activity, 2)
+
+		blockTypes := []string{}
+		for _, activity := range page.Activity {
+			require.Equal(t, model.CardActivityCreated, activity.Action)
+			require.NotEmpty(t, activity.Changes)
+			blockTypes = append(blockTypes, activity.BlockType)
+		}
+		require.ElementsMatch(t, []string{"card", "comment"}, blockTypes)
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		_, resp := th.Client.GetCardActivity(cardID, model.CardActivityMaxPageSize+1, 0)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Unknown card", func(t *testing.T) {
+		_, resp := th.Client.GetCardActivity(utils.CreateGUID(), 0, 0)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,166 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminUsers(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	passwords := map[string]string{
+		"admin": utils.CreateGUID(),
+		"other": utils.CreateGUID(),
+	}
+	success, resp := th.Client.Register(&api.RegisterRequest{
+		Username: "admin",
+		Email:    "admin@example.com",
+		Password: passwords["admin"],
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	// the other user needs the sign-up token of the workspace
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "admin", Password: passwords["admin"]})
+	require.NoError(t, resp.Error)
+	r, err := th.Client.DoAPIGet("/workspaces/0", "")
+	require.NoError(t, err)
+	var workspace model.Workspace
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&workspace))
+	_ = r.Body.Close()
+
+	success, resp = th.Client.Register(&api.RegisterRequest{
+		Username: "other",
+		Email:    "other@example.com",
+		Password: passwords["other"],
+		Token:    workspace.SignupToken,
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	other := client.NewClient(th.Server.Config().ServerRoot, "")
+	_, resp = other.Login(&api.LoginRequest{Type: "normal", Username: "other", Password: passwords["other"]})
+	require.NoError(t, resp.Error)
+	otherUser, resp := other.GetMe()
+	require.NoError(t, resp.Error)
+
+	t.Run("the first registered user is the admin", func(t *testing.T) {
+		me, resp := th.Client.GetMe()
+		require.NoError(t, resp.Error)
+		require.True(t, me.IsAdmin)
+		require.False(t, otherUser.IsAdmin)
+	})
+
+	t.Run("the other users aren't admins", func(t *testing.T) {
+		_, resp := other.AdminGetUsers(model.QueryUsersOptions{})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, resp = other.AdminDeactivateUser(otherUser.ID)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, resp = other.AdminGetStatistics()
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("get the statistics of the workspaces", func(t *testing.T) {
+		stats, resp := th.Client.AdminGetStatistics()
+		require.NoError(t, resp.Error)
+		require.EqualValues(t, 1, stats.Total)
+		require.Len(t, stats.CreatedByDay, model.WorkspaceStatsDays)
+		require.EqualValues(t, 1, stats.CreatedByDay[model.WorkspaceStatsDays-1].Count)
+	})
+
+	t.Run("list the users", func(t *testing.T) {
+		users, resp := th.Client.AdminGetUsers(model.QueryUsersOptions{})
+		require.NoError(t, resp.Error)
+		require.Len(t, users, 2)
+		require.Equal(t, "admin", users[0].Username)
+		require.Equal(t, "other", users[1].Username)
+
+		users, resp = th.Client.AdminGetUsers(model.QueryUsersOptions{Username: "oth"})
+		require.NoError(t, resp.Error)
+		require.Len(t, users, 1)
+		require.Equal(t, otherUser.ID, users[0].ID)
+	})
+
+	t.Run("the admin can't deactivate themselves", func(t *testing.T) {
+		me, resp := th.Client.GetMe()
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.AdminDeactivateUser(me.ID)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("deactivate a user", func(t *testing.T) {
+		success, resp := th.Client.AdminDeactivateUser(otherUser.ID)
+		require.NoError(t, resp.Error)
+		require.True(t, success)
+
+		// the sessions of the user are deleted
+		_, resp = other.GetMe()
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		_, resp = other.Login(&api.LoginRequest{Type: "normal", Username: "other", Password: passwords["other"]})
+		require.Error(t, resp.Error)
+
+		r, err := th.Client.DoAPIGet("/workspaces/0/users", "")
+		require.NoError(t, err)
+		var users []*model.User
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&users))
+		_ = r.Body.Close()
+		for _, user := range users {
+			require.NotEqual(t, otherUser.ID, user.ID)
+		}
+
+		active := false
+		deactivated, resp := th.Client.AdminGetUsers(model.QueryUsersOptions{Active: &active})
+		require.NoError(t, resp.Error)
+		require.Len(t, deactivated, 1)
+		require.Equal(t, otherUser.ID, deactivated[0].ID)
+	})
+
+	t.Run("activate a user", func(t *testing.T) {
+		success, resp := th.Client.AdminActivateUser(otherUser.ID)
+		require.NoError(t, resp.Error)
+		require.True(t, success)
+
+		_, resp = other.Login(&api.LoginRequest{Type: "normal", Username: "other", Password: passwords["other"]})
+		require.NoError(t, resp.Error)
+	})
+
+	t.Run("reset the password of a user", func(t *testing.T) {
+		_, resp := th.Client.AdminResetUserPassword(otherUser.ID, "short")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		newPassword := utils.CreateGUID()
+		success, resp := th.Client.AdminResetUserPassword(otherUser.ID, newPassword)
+		require.NoError(t, resp.Error)
+		require.True(t, success)
+
+		_, resp = other.GetMe()
+		require.Error(t, resp.Error)
+
+		_, resp = other.Login(&api.LoginRequest{Type: "normal", Username: "other", Password: newPassword})
+		require.NoError(t, resp.Error)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, resp := th.Client.AdminActivateUser(utils.CreateGUID())
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
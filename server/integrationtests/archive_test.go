@@ -0,0 +1,114 @@
+package integrationtests
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceArchive(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	file, resp := th.Client.WorkspaceUploadFile("0", boardID, bytes.NewReader([]byte("image data")))
+	require.NoError(t, resp.Error)
+
+	now := utils.GetMillis()
+	cardID := utils.CreateGUID()
+	blocks := []model.Block{
+		{ID: boardID, RootID: boardID, Type: "board", Title: "Archived board", CreateAt: now, UpdateAt: now},
+		{ID: cardID, ParentID: boardID, RootID: boardID, Type: "card", CreateAt: now, UpdateAt: now},
+		{
+			ID:       utils.CreateGUID(),
+			ParentID: cardID,
+			RootID:   boardID,
+			Type:     "image",
+			Fields:   map[string]interface{}{"fileId": file.FileID},
+			CreateAt: now,
+			UpdateAt: now,
+		},
+	}
+	_, resp = th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Export and import the workspace", func(t *testing.T) {
+		data, resp := th.Client.ExportWorkspaceArchive()
+		require.NoError(t, resp.Error)
+
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		names := []string{}
+		for _, entry := range archive.File {
+			names = append(names, entry.Name)
+		}
+		require.Contains(t, names, "blocks.jsonl")
+		require.Contains(t, names, "files/"+file.FileID)
+
+		rootBlocks, resp := th.Client.GetBlocks()
+		require.NoError(t, resp.Error)
+		initialCount := len(rootBlocks)
+
+		summary, resp := th.Client.ImportWorkspaceArchive(data)
+		require.NoError(t, resp.Error)
+		// the board and the default templates of the workspace
+		require.Equal(t, initialCount, summary.BoardsCreated)
+		require.Empty(t, summary.Skipped)
+
+		rootBlocks, resp = th.Client.GetBlocks()
+		require.NoError(t, resp.Error)
+		require.Len(t, rootBlocks, initialCount*2)
+
+		var importedID string
+		for _, block := range rootBlocks {
+			if block.Title == "Archived board" && block.ID != boardID {
+				importedID = block.ID
+			}
+		}
+		require.NotEmpty(t, importedID)
+
+		subtree, resp := th.Client.GetSubtree(importedID)
+		require.NoError(t, resp.Error)
+		require.Len(t, subtree, 2)
+		var importedCardID string
+		for _, block := range subtree {
+			if block.Type == "card" {
+				importedCardID = block.ID
+			}
+		}
+		require.NotEmpty(t, importedCardID)
+
+		subtree, resp = th.Client.GetSubtree(importedCardID)
+		require.NoError(t, resp.Error)
+		require.Len(t, subtree, 2)
+		for _, block := range subtree {
+			if block.Type == "image" {
+				require.NotEqual(t, file.FileID, block.Fields["fileId"])
+				require.NotEmpty(t, block.Fields["fileId"])
+			}
+		}
+	})
+
+	t.Run("Import an invalid archive", func(t *testing.T) {
+		_, resp := th.Client.ImportWorkspaceArchive([]byte("not a zip"))
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		var buf bytes.Buffer
+		writer := zip.NewWriter(&buf)
+		entry, err := writer.Create("blocks.jsonl")
+		require.NoError(t, err)
+		_, err = entry.Write([]byte(`{"id":"orphan","parentId":"missing","rootId":"missing","type":"card"}` + "\n"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		_, resp = th.Client.ImportWorkspaceArchive(buf.Bytes())
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,102 @@
+package integrationtests
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func avatarImage(t *testing.T, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 640, 480))
+	for y := 0; y < 480; y++ {
+		for x := 0; x < 640; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func decodeAvatar(t *testing.T, data []byte) image.Image {
+	avatar, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 128, 128), avatar.Bounds())
+	return avatar
+}
+
+func TestUserImage(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	_, resp := th.Client.Register(&api.RegisterRequest{Username: "owner", Email: "owner@example.com", Password: password})
+	require.NoError(t, resp.Error)
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "owner", Password: password})
+	require.NoError(t, resp.Error)
+	owner, resp := th.Client.GetMe()
+	require.NoError(t, resp.Error)
+
+	memberClient, member := loginNewUser(t, th, "member")
+
+	t.Run("the users without an avatar get an identicon", func(t *testing.T) {
+		data, resp := memberClient.GetUserImage(owner.ID, 0)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		require.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+		decodeAvatar(t, data)
+
+		again, resp := th.Client.GetUserImage(owner.ID, 0)
+		require.NoError(t, resp.Error)
+		require.Equal(t, data, again)
+	})
+
+	t.Run("the users set their own avatar", func(t *testing.T) {
+		uploaded, resp := th.Client.SetUserImage(owner.ID, bytes.NewReader(avatarImage(t, color.RGBA{R: 255, A: 255})))
+		require.NoError(t, resp.Error)
+		require.NotZero(t, uploaded.UpdateAt)
+
+		me, resp := th.Client.GetMe()
+		require.NoError(t, resp.Error)
+		require.EqualValues(t, uploaded.UpdateAt, me.Props[model.UserPropAvatarUpdateAt])
+
+		data, resp := memberClient.GetUserImage(owner.ID, uploaded.UpdateAt)
+		require.NoError(t, resp.Error)
+		require.Contains(t, resp.Header.Get("Cache-Control"), "max-age=31536000")
+		r, g, b, _ := decodeAvatar(t, data).At(64, 64).RGBA()
+		require.Equal(t, []uint32{0xff, 0, 0}, []uint32{r >> 8, g >> 8, b >> 8})
+
+		_, resp = memberClient.SetUserImage(owner.ID, bytes.NewReader(avatarImage(t, color.Black)))
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("the images that can't be decoded are rejected", func(t *testing.T) {
+		_, resp := memberClient.SetUserImage(member.ID, bytes.NewReader([]byte("not an image")))
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("the avatar of a deactivated user is kept", func(t *testing.T) {
+		_, resp := memberClient.SetUserImage(member.ID, bytes.NewReader(avatarImage(t, color.RGBA{B: 255, A: 255})))
+		require.NoError(t, resp.Error)
+		avatar, resp := th.Client.GetUserImage(member.ID, 0)
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.AdminDeactivateUser(member.ID)
+		require.NoError(t, resp.Error)
+
+		data, resp := th.Client.GetUserImage(member.ID, 0)
+		require.NoError(t, resp.Error)
+		require.Equal(t, avatar, data)
+
+		_, resp = memberClient.SetUserImage(member.ID, bytes.NewReader(avatarImage(t, color.Black)))
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
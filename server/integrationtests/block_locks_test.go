@@ -0,0 +1,111 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockLocks(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	passwords := map[string]string{
+		"editor": utils.CreateGUID(),
+		"other":  utils.CreateGUID(),
+	}
+	success, resp := th.Client.Register(&api.RegisterRequest{
+		Username: "editor",
+		Email:    "editor@example.com",
+		Password: passwords["editor"],
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	// the other user needs the sign-up token of the workspace
+	editorLogin, resp := th.Client.Login(&api.LoginRequest{Type: "normal", Username: "editor", Password: passwords["editor"]})
+	require.NoError(t, resp.Error)
+	r, err := th.Client.DoAPIGet("/workspaces/0", "")
+	require.NoError(t, err)
+	var workspace model.Workspace
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&workspace))
+	_ = r.Body.Close()
+
+	success, resp = th.Client.Register(&api.RegisterRequest{
+		Username: "other",
+		Email:    "other@example.com",
+		Password: passwords["other"],
+		Token:    workspace.SignupToken,
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	other := client.NewClient(th.Server.Config().ServerRoot, "")
+	_, resp = other.Login(&api.LoginRequest{Type: "normal", Username: "other", Password: passwords["other"]})
+	require.NoError(t, resp.Error)
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	_, resp = th.Client.InsertBlocks([]model.Block{
+		{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board"},
+		{ID: cardID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card"},
+	})
+	require.NoError(t, resp.Error)
+
+	// the editor locks the card over the websocket
+	url := "ws" + strings.TrimPrefix(th.Server.Config().ServerRoot, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	commands := []map[string]interface{}{
+		{"action": "AUTH", "token": editorLogin.Token},
+		{"action": "SUBSCRIBE_WORKSPACE", "workspaceId": "0"},
+		{"action": "LOCK_BLOCK", "workspaceId": "0", "boardId": boardID, "blockId": cardID},
+	}
+	for _, command := range commands {
+		require.NoError(t, conn.WriteJSON(command))
+	}
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var lock map[string]interface{}
+	require.NoError(t, conn.ReadJSON(&lock))
+	require.Equal(t, "BLOCK_LOCKED", lock["action"])
+	require.Equal(t, cardID, lock["blockId"])
+	require.NotEmpty(t, lock["userId"])
+
+	title := "New title"
+	patch := &model.BlockPatch{Title: &title}
+
+	t.Run("the holder can patch the block", func(t *testing.T) {
+		_, resp := th.Client.PatchBlock(cardID, patch)
+		require.NoError(t, resp.Error)
+	})
+
+	t.Run("another user can't patch the block", func(t *testing.T) {
+		_, resp := other.PatchBlock(cardID, patch)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		require.Contains(t, resp.Error.Error(), `"errorCode":1005`)
+	})
+
+	t.Run("another user can force the patch", func(t *testing.T) {
+		_, resp := other.ForcePatchBlock(cardID, patch)
+		require.NoError(t, resp.Error)
+	})
+
+	t.Run("the lock is released when the connection closes", func(t *testing.T) {
+		require.NoError(t, conn.Close())
+		require.Eventually(t, func() bool {
+			_, resp := other.PatchBlock(cardID, patch)
+			return resp.Error == nil
+		}, 5*time.Second, 20*time.Millisecond)
+	})
+}
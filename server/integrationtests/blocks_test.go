@@ -1,8 +1,13 @@
 package integrationtests
 
 import (
+	"bytes"
+	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/mattermost/focalboard/server/api"
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/utils"
 
@@ -50,6 +55,164 @@ func TestGetBlocks(t *testing.T) {
 	require.Contains(t, blockIDs, blockID2)
 }
 
+func TestGetBlocksPage(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	newBlocks := []model.Block{{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board"}}
+	for i := 0; i < 5; i++ {
+		cardID := utils.CreateGUID()
+		newBlocks = append(newBlocks, model.Block{ID: cardID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card"})
+	}
+	_, resp := th.Client.InsertBlocks(newBlocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Page through the blocks", func(t *testing.T) {
+		ids := []string{}
+		after := ""
+		for pages := 0; pages < 3; pages++ {
+			page, resp := th.Client.GetBlocksPage(boardID, 2, after)
+			require.NoError(t, resp.Error)
+			require.LessOrEqual(t, len(page.Blocks), 2)
+			for _, block := range page.Blocks {
+				ids = append(ids, block.ID)
+			}
+			after = page.NextCursor
+		}
+		require.Empty(t, after)
+
+		expected := []string{}
+		for _, block := range newBlocks[1:] {
+			expected = append(expected, block.ID)
+		}
+		require.ElementsMatch(t, expected, ids)
+	})
+
+	t.Run("Reject an invalid cursor", func(t *testing.T) {
+		_, resp := th.Client.GetBlocksPage(boardID, 2, "not a cursor")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Reject an invalid limit", func(t *testing.T) {
+		_, resp := th.Client.GetBlocksPage(boardID, model.BlocksMaxPageSize+1, "")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestGetBlocksETag(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	_, resp := th.Client.GetBlocks()
+	require.NoError(t, resp.Error)
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+
+	t.Run("Unchanged blocks aren't sent again", func(t *testing.T) {
+		blocks, resp := th.Client.GetBlocksWithETag(etag)
+		require.NoError(t, resp.Error)
+		require.Equal(t, http.StatusNotModified, resp.StatusCode)
+		require.Nil(t, blocks)
+		require.Equal(t, etag, resp.Header.Get("ETag"))
+	})
+
+	t.Run("A block update changes the ETag", func(t *testing.T) {
+		blockID := utils.CreateGUID()
+		_, resp := th.Client.InsertBlocks([]model.Block{
+			{ID: blockID, RootID: blockID, CreateAt: 1, UpdateAt: 1, Type: "board"},
+		})
+		require.NoError(t, resp.Error)
+
+		blocks, resp := th.Client.GetBlocksWithETag(etag)
+		require.NoError(t, resp.Error)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NotEmpty(t, blocks)
+		newETag := resp.Header.Get("ETag")
+		require.NotEqual(t, etag, newETag)
+
+		time.Sleep(1 * time.Millisecond)
+		title := "New title"
+		_, resp = th.Client.PatchBlock(blockID, &model.BlockPatch{Title: &title})
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.GetBlocksWithETag(newETag)
+		require.NoError(t, resp.Error)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NotEqual(t, newETag, resp.Header.Get("ETag"))
+	})
+}
+
+func TestGetBlocksForView(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	viewID := utils.CreateGUID()
+	cardProperties := []interface{}{
+		map[string]interface{}{"id": "status", "name": "Status", "type": "select"},
+		map[string]interface{}{"id": "tags", "name": "Tags", "type": "multiSelect"},
+		map[string]interface{}{"id": "due", "name": "Due", "type": "date"},
+	}
+	card := func(id string, properties map[string]interface{}) model.Block {
+		return model.Block{ID: id, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card", Fields: map[string]interface{}{"properties": properties}}
+	}
+	view := model.Block{ID: viewID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "view", Fields: map[string]interface{}{
+		"filter": map[string]interface{}{"operation": "and", "filters": []interface{}{
+			map[string]interface{}{"propertyId": "status", "condition": "includes", "values": []interface{}{"todo"}},
+			map[string]interface{}{"operation": "or", "filters": []interface{}{
+				map[string]interface{}{"propertyId": "due", "condition": "isBetween", "values": []interface{}{"1000", "2000"}},
+			}},
+		}},
+	}}
+	_, resp := th.Client.InsertBlocks([]model.Block{
+		{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board", Fields: map[string]interface{}{"cardProperties": cardProperties}},
+		view,
+		card("todo-soon", map[string]interface{}{"status": "todo", "due": "1500"}),
+		card("todo-range", map[string]interface{}{"status": "todo", "due": `{"from":500,"to":1200}`}),
+		card("todo-later", map[string]interface{}{"status": "todo", "due": `{"from":2500,"to":3000}`}),
+		card("done-soon", map[string]interface{}{"status": "done", "due": "1500", "tags": []interface{}{"a"}}),
+	})
+	require.NoError(t, resp.Error)
+
+	blockIDs := func(blocks []model.Block) []string {
+		ids := []string{}
+		for _, block := range blocks {
+			ids = append(ids, block.ID)
+		}
+		return ids
+	}
+
+	t.Run("The server filters the cards of the view", func(t *testing.T) {
+		blocks, resp := th.Client.GetBlocksForView(viewID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "complete", resp.Header.Get("X-Server-Filter"))
+		require.ElementsMatch(t, []string{boardID, viewID, "todo-soon", "todo-range"}, blockIDs(blocks))
+	})
+
+	t.Run("The filters the server can't apply return all the cards", func(t *testing.T) {
+		view.Fields = map[string]interface{}{
+			"filter": map[string]interface{}{"operation": "and", "filters": []interface{}{
+				map[string]interface{}{"propertyId": "tags", "condition": "includes", "values": []interface{}{"a"}},
+			}},
+		}
+		_, resp := th.Client.InsertBlocks([]model.Block{view})
+		require.NoError(t, resp.Error)
+
+		blocks, resp := th.Client.GetBlocksForView(viewID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "partial", resp.Header.Get("X-Server-Filter"))
+		require.Len(t, blocks, 6)
+	})
+
+	t.Run("Unknown view", func(t *testing.T) {
+		_, resp := th.Client.GetBlocksForView(utils.CreateGUID())
+		require.Error(t, resp.Error)
+	})
+}
+
 func TestPostBlock(t *testing.T) {
 	th := SetupTestHelper().InitBasic()
 	defer th.TearDown()
@@ -177,8 +340,10 @@ func TestPatchBlock(t *testing.T) {
 			Title: &newTitle,
 		}
 
-		_, resp := th.Client.PatchBlock(blockID, blockPatch)
+		result, resp := th.Client.PatchBlock(blockID, blockPatch)
 		require.NoError(t, resp.Error)
+		require.Equal(t, "New title", result.Before.Title)
+		require.Equal(t, "Updated title", result.After.Title)
 
 		blocks, resp := th.Client.GetBlocks()
 		require.NoError(t, resp.Error)
@@ -245,6 +410,150 @@ func TestPatchBlock(t *testing.T) {
 	})
 }
 
+func TestRevertBlock(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	board := model.Block{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board", Fields: map[string]interface{}{
+		"cardProperties": []interface{}{
+			map[string]interface{}{"id": "status", "type": "select", "options": []interface{}{
+				map[string]interface{}{"id": "todo", "value": "To do"},
+				map[string]interface{}{"id": "done", "value": "Done"},
+			}},
+		},
+	}}
+	_, resp := th.Client.InsertBlocks([]model.Block{
+		board,
+		{ID: cardID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card", Title: "Card", Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"status": "done"},
+		}},
+	})
+	require.NoError(t, resp.Error)
+
+	// the versions are identified by their update time in milliseconds
+	time.Sleep(2 * time.Millisecond)
+	title := "Renamed"
+	_, resp = th.Client.PatchBlock(cardID, &model.BlockPatch{
+		Title:             &title,
+		UpdatedProperties: map[string]interface{}{"status": "todo"},
+	})
+	require.NoError(t, resp.Error)
+
+	t.Run("should restore a version as a new version", func(t *testing.T) {
+		// the done option is deleted since the version
+		_, resp := th.Client.PatchBlock(boardID, &model.BlockPatch{UpdatedFields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "status", "type": "select", "options": []interface{}{
+					map[string]interface{}{"id": "todo", "value": "To do"},
+				}},
+			},
+		}})
+		require.NoError(t, resp.Error)
+
+		history, resp := th.Client.GetBlockHistory(cardID)
+		require.NoError(t, resp.Error)
+		require.Len(t, history, 2)
+
+		result, resp := th.Client.RevertBlock(cardID, history[0].UpdateAt)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "Renamed", result.Before.Title)
+		require.Equal(t, "Card", result.After.Title)
+		require.Equal(t, map[string]interface{}{}, result.After.Fields["properties"])
+
+		history, resp = th.Client.GetBlockHistory(cardID)
+		require.NoError(t, resp.Error)
+		require.Len(t, history, 3)
+	})
+
+	t.Run("should fail for a version that isn't in the history", func(t *testing.T) {
+		_, resp := th.Client.RevertBlock(cardID, 12345)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestPatchBlocks(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardIDs := []string{utils.CreateGUID(), utils.CreateGUID()}
+	viewID := utils.CreateGUID()
+	blocks := []model.Block{
+		{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board"},
+		{ID: viewID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "view"},
+	}
+	for _, cardID := range cardIDs {
+		blocks = append(blocks, model.Block{
+			ID:       cardID,
+			RootID:   boardID,
+			ParentID: boardID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     "card",
+			Fields: map[string]interface{}{
+				"properties": map[string]interface{}{"status": "todo", "owner": "user-1"},
+			},
+		})
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	// avoid violating the block_history composite primary key constraint
+	// with a quick update of the cards
+	time.Sleep(1 * time.Second)
+
+	t.Run("Patch several cards", func(t *testing.T) {
+		icon := "icon"
+		patched, resp := th.Client.PatchBlocks(&model.BlocksPatch{
+			BlockIDs: []string{cardIDs[1], cardIDs[0]},
+			Patch: model.BlockPatch{
+				UpdatedFields:     map[string]interface{}{"icon": icon},
+				UpdatedProperties: map[string]interface{}{"status": "done"},
+			},
+		})
+		require.NoError(t, resp.Error)
+		require.Len(t, patched, 2)
+		require.Equal(t, cardIDs[1], patched[0].ID)
+		require.Equal(t, cardIDs[0], patched[1].ID)
+
+		blocks, resp := th.Client.GetBlocks()
+		require.NoError(t, resp.Error)
+		for _, block := range blocks {
+			if block.Type != "card" {
+				continue
+			}
+			require.Equal(t, icon, block.Fields["icon"])
+			require.Equal(t, map[string]interface{}{"status": "done", "owner": "user-1"}, block.Fields["properties"])
+		}
+	})
+
+	t.Run("Reject the patch if a block isn't a card", func(t *testing.T) {
+		title := "Title"
+		_, resp := th.Client.PatchBlocks(&model.BlocksPatch{
+			BlockIDs: []string{cardIDs[0], viewID},
+			Patch:    model.BlockPatch{Title: &title},
+		})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, resp = th.Client.PatchBlocks(&model.BlocksPatch{
+			BlockIDs: []string{cardIDs[0], utils.CreateGUID()},
+			Patch:    model.BlockPatch{Title: &title},
+		})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		blocks, resp := th.Client.GetBlocks()
+		require.NoError(t, resp.Error)
+		for _, block := range blocks {
+			require.NotEqual(t, title, block.Title)
+		}
+	})
+}
+
 func TestDeleteBlock(t *testing.T) {
 	th := SetupTestHelper().InitBasic()
 	defer th.TearDown()
@@ -286,6 +595,40 @@ func TestDeleteBlock(t *testing.T) {
 		require.NoError(t, resp.Error)
 		require.Len(t, blocks, initialCount)
 	})
+
+	t.Run("List the trash", func(t *testing.T) {
+		deletedBlocks, resp := th.Client.GetDeletedBlocks()
+		require.NoError(t, resp.Error)
+		require.Len(t, deletedBlocks, 1)
+		require.Equal(t, blockID, deletedBlocks[0].ID)
+	})
+
+	t.Run("Undelete a block", func(t *testing.T) {
+		_, resp := th.Client.UndeleteBlock(blockID)
+		require.NoError(t, resp.Error)
+
+		blocks, resp := th.Client.GetBlocks()
+		require.NoError(t, resp.Error)
+		require.Len(t, blocks, initialCount+1)
+
+		deletedBlocks, resp := th.Client.GetDeletedBlocks()
+		require.NoError(t, resp.Error)
+		require.Empty(t, deletedBlocks)
+	})
+
+	t.Run("Get the block history", func(t *testing.T) {
+		history, resp := th.Client.GetBlockHistory(blockID)
+		require.NoError(t, resp.Error)
+		require.Len(t, history, 3)
+		require.Equal(t, "New title", history[0].Title)
+		require.NotZero(t, history[1].DeleteAt)
+		require.Zero(t, history[2].DeleteAt)
+	})
+
+	t.Run("Undelete a block not in the trash", func(t *testing.T) {
+		_, resp := th.Client.UndeleteBlock(blockID)
+		require.Error(t, resp.Error)
+	})
 }
 
 func TestGetSubtree(t *testing.T) {
@@ -354,3 +697,404 @@ func TestGetSubtree(t *testing.T) {
 		require.Contains(t, blockIDs, childBlockID2)
 	})
 }
+
+func TestSearchBlocks(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	newBlocks := []model.Block{
+		{
+			ID:       boardID,
+			RootID:   boardID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     "board",
+		},
+		{
+			ID:       cardID,
+			RootID:   boardID,
+			ParentID: boardID,
+			CreateAt: 2,
+			UpdateAt: 2,
+			Type:     "card",
+			Title:    "Searchable card",
+		},
+	}
+	_, resp := th.Client.InsertBlocks(newBlocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Search cards", func(t *testing.T) {
+		results, resp := th.Client.SearchBlocks("searchable")
+		require.NoError(t, resp.Error)
+		require.Len(t, results, 1)
+		require.Equal(t, cardID, results[0].ID)
+		require.Equal(t, boardID, results[0].BoardID)
+	})
+
+	t.Run("Reject short queries", func(t *testing.T) {
+		_, resp := th.Client.SearchBlocks("  se ")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestDuplicateBoard(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	newBlocks := []model.Block{
+		{
+			ID:       boardID,
+			RootID:   boardID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     "board",
+			Title:    "Board",
+		},
+		{
+			ID:       cardID,
+			RootID:   boardID,
+			ParentID: boardID,
+			CreateAt: 2,
+			UpdateAt: 2,
+			Type:     "card",
+		},
+	}
+	_, resp := th.Client.InsertBlocks(newBlocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Duplicate a board", func(t *testing.T) {
+		board, resp := th.Client.DuplicateBoard(boardID, false)
+		require.NoError(t, resp.Error)
+		require.NotNil(t, board)
+		require.NotEqual(t, boardID, board.ID)
+		require.Equal(t, "Board copy", board.Title)
+
+		blocks, resp := th.Client.GetSubtree(board.ID)
+		require.NoError(t, resp.Error)
+		require.Len(t, blocks, 2)
+		for _, block := range blocks {
+			require.Equal(t, board.ID, block.RootID)
+			require.NotEqual(t, cardID, block.ID)
+		}
+	})
+
+	t.Run("Duplicate a board that doesn't exist", func(t *testing.T) {
+		_, resp := th.Client.DuplicateBoard(utils.CreateGUID(), false)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestGetBoardMetadata(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	newBlocks := []model.Block{
+		{
+			ID:       boardID,
+			RootID:   boardID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     "board",
+		},
+		{
+			ID:       cardID,
+			RootID:   boardID,
+			ParentID: boardID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     "card",
+		},
+		{
+			ID:       utils.CreateGUID(),
+			RootID:   boardID,
+			ParentID: cardID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     "comment",
+		},
+		{
+			ID:       utils.CreateGUID(),
+			RootID:   boardID,
+			ParentID: cardID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     "checkbox",
+			Fields:   map[string]interface{}{"value": true},
+		},
+		{
+			ID:       utils.CreateGUID(),
+			RootID:   boardID,
+			ParentID: cardID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     "checkbox",
+		},
+	}
+	_, resp := th.Client.InsertBlocks(newBlocks)
+	require.NoError(t, resp.Error)
+
+	metadata, resp := th.Client.GetBoardMetadata(boardID)
+	require.NoError(t, resp.Error)
+	require.Len(t, metadata, 1)
+	require.Equal(t, cardID, metadata[0].CardID)
+	require.EqualValues(t, 1, metadata[0].CommentCount)
+	require.NotZero(t, metadata[0].LastContentUpdateAt)
+	require.EqualValues(t, 1, metadata[0].CheckedCount)
+	require.EqualValues(t, 2, metadata[0].CheckboxCount)
+}
+
+func TestGetBoardMetadataComputedValues(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	parentID := utils.CreateGUID()
+	childID := utils.CreateGUID()
+	cardProperties := []interface{}{
+		map[string]interface{}{"id": "related", "name": "Related", "type": model.PropertyTypeRelation},
+		map[string]interface{}{"id": "estimate", "name": "Estimate", "type": "number"},
+		map[string]interface{}{"id": "total", "name": "Total", "type": model.PropertyTypeComputed, "computed": map[string]interface{}{
+			"relationPropertyId": "related", "sourcePropertyId": "estimate", "aggregation": "sum",
+		}},
+	}
+	newBlocks := []model.Block{
+		{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board", Fields: map[string]interface{}{"cardProperties": cardProperties}},
+		{ID: parentID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card", Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"related": []interface{}{childID}},
+		}},
+		{ID: childID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card", Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"estimate": "5"},
+		}},
+	}
+	_, resp := th.Client.InsertBlocks(newBlocks)
+	require.NoError(t, resp.Error)
+
+	metadata, resp := th.Client.GetBoardMetadata(boardID)
+	require.NoError(t, resp.Error)
+	require.Len(t, metadata, 2)
+	for _, m := range metadata {
+		if m.CardID == parentID {
+			require.Equal(t, map[string]float64{"total": 5}, m.ComputedValues)
+		}
+	}
+
+	t.Run("reject circular computed properties", func(t *testing.T) {
+		cyclic := append(cardProperties, map[string]interface{}{"id": "loop", "type": model.PropertyTypeComputed, "computed": map[string]interface{}{
+			"relationPropertyId": "related", "sourcePropertyId": "loop", "aggregation": "max",
+		}})
+		board := newBlocks[0]
+		board.Fields = map[string]interface{}{"cardProperties": cyclic}
+
+		_, resp := th.Client.InsertBlocks([]model.Block{board})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestAttachmentBlock(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	_, resp := th.Client.InsertBlocks([]model.Block{
+		{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board"},
+		{ID: cardID, ParentID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card"},
+	})
+	require.NoError(t, resp.Error)
+
+	data := []byte("%PDF-1.4 report")
+	uploaded, resp := th.Client.WorkspaceUploadNamedFile("0", boardID, "Quarterly report.pdf", bytes.NewReader(data))
+	require.NoError(t, resp.Error)
+	require.Equal(t, "Quarterly report.pdf", uploaded.Filename)
+	require.Equal(t, int64(len(data)), uploaded.Size)
+	require.Equal(t, "application/pdf", uploaded.MimeType)
+
+	attachment := func(fileID string) model.Block {
+		return model.Block{
+			ID:       utils.CreateGUID(),
+			ParentID: cardID,
+			RootID:   boardID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     model.AttachmentBlockType,
+			Fields: map[string]interface{}{
+				model.AttachmentFileIDField:   fileID,
+				model.AttachmentFilenameField: uploaded.Filename,
+				model.AttachmentSizeField:     uploaded.Size,
+				model.AttachmentMimeTypeField: uploaded.MimeType,
+			},
+		}
+	}
+
+	block := attachment(uploaded.FileID)
+	t.Run("Create an attachment", func(t *testing.T) {
+		_, resp := th.Client.InsertBlocks([]model.Block{block})
+		require.NoError(t, resp.Error)
+	})
+
+	t.Run("Reject an attachment of an unknown file", func(t *testing.T) {
+		_, resp := th.Client.InsertBlocks([]model.Block{attachment("unknown.pdf")})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Reject an attachment of a file of another board", func(t *testing.T) {
+		other, resp := th.Client.WorkspaceUploadNamedFile("0", utils.CreateGUID(), "other.pdf", bytes.NewReader(data))
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.InsertBlocks([]model.Block{attachment(other.FileID)})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Download the file", func(t *testing.T) {
+		downloaded, resp := th.Client.GetFile("0", boardID, uploaded.FileID, "")
+		require.NoError(t, resp.Error)
+		require.Equal(t, data, downloaded)
+		require.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+		require.Equal(t, `attachment; filename="Quarterly report.pdf"`, resp.Header.Get("Content-Disposition"))
+		require.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+	})
+
+	t.Run("Delete the attachment", func(t *testing.T) {
+		_, resp := th.Client.DeleteBlock(block.ID)
+		require.NoError(t, resp.Error)
+
+		// the file is removed by the cleanup of the orphaned files
+		_, resp = th.Client.GetFile("0", boardID, uploaded.FileID, "")
+		require.NoError(t, resp.Error)
+	})
+}
+
+func TestCodeBlock(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	code := model.Block{
+		ID:       utils.CreateGUID(),
+		ParentID: cardID,
+		RootID:   boardID,
+		CreateAt: 1,
+		UpdateAt: 1,
+		Type:     model.CodeBlockType,
+		Title:    "SELECT 1;",
+		Fields:   map[string]interface{}{model.CodeLanguageField: "PostgreSQL", model.CodeWrapField: true},
+	}
+	_, resp := th.Client.InsertBlocks([]model.Block{
+		{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board"},
+		{ID: cardID, ParentID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card"},
+		code,
+	})
+	require.NoError(t, resp.Error)
+
+	language := func() interface{} {
+		blocks, resp := th.Client.GetSubtree(cardID)
+		require.NoError(t, resp.Error)
+		for _, block := range blocks {
+			if block.ID == code.ID {
+				return block.Fields[model.CodeLanguageField]
+			}
+		}
+		return nil
+	}
+
+	t.Run("Normalize the language", func(t *testing.T) {
+		require.Equal(t, "postgresql", language())
+
+		_, resp := th.Client.PatchBlock(code.ID, &model.BlockPatch{UpdatedFields: map[string]interface{}{model.CodeLanguageField: "YML"}})
+		require.NoError(t, resp.Error)
+		require.Equal(t, "yaml", language())
+	})
+
+	t.Run("Reject an invalid wrap setting", func(t *testing.T) {
+		_, resp := th.Client.PatchBlock(code.ID, &model.BlockPatch{UpdatedFields: map[string]interface{}{model.CodeWrapField: "on"}})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestBlockTypeRules(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	_, resp := th.Client.InsertBlocks([]model.Block{{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board"}})
+	require.NoError(t, resp.Error)
+
+	t.Run("Reject a comment of a board", func(t *testing.T) {
+		comment := model.Block{ID: utils.CreateGUID(), ParentID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "comment"}
+		_, resp := th.Client.InsertBlocks([]model.Block{comment})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Contains(t, resp.Error.Error(), `"rule":"parentType"`)
+	})
+
+	t.Run("Reject an unknown type", func(t *testing.T) {
+		block := model.Block{ID: utils.CreateGUID(), ParentID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "poll"}
+		_, resp := th.Client.InsertBlocks([]model.Block{block})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Contains(t, resp.Error.Error(), `"rule":"knownType"`)
+	})
+
+	t.Run("Reject moving a view to a card", func(t *testing.T) {
+		viewID := utils.CreateGUID()
+		cardID := utils.CreateGUID()
+		_, resp := th.Client.InsertBlocks([]model.Block{
+			{ID: viewID, ParentID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "view"},
+			{ID: cardID, ParentID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card"},
+		})
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.PatchBlock(viewID, &model.BlockPatch{ParentID: &cardID})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestPatchBlockVersion(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	_, resp := th.Client.InsertBlocks([]model.Block{{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board"}})
+	require.NoError(t, resp.Error)
+
+	title := func(value string) *model.BlockPatch {
+		return &model.BlockPatch{Title: &value}
+	}
+
+	t.Run("Patch the expected version", func(t *testing.T) {
+		result, resp := th.Client.PatchBlockVersion(boardID, title("First"), 1)
+		require.NoError(t, resp.Error)
+		require.Equal(t, int64(1), result.Before.Version)
+		require.Equal(t, int64(2), result.After.Version)
+		require.Equal(t, "First", result.After.Title)
+	})
+
+	t.Run("Reject another version with the stored block", func(t *testing.T) {
+		_, resp := th.Client.PatchBlockVersion(boardID, title("Stale"), 1)
+		requireErrorCode(t, resp, http.StatusConflict, model.ErrorCodeConflict)
+
+		require.Contains(t, resp.Error.Error(), fmt.Sprintf(`"errorCode":%d`, api.ErrorVersionConflictCode))
+		require.Contains(t, resp.Error.Error(), `"title":"First"`)
+		require.Contains(t, resp.Error.Error(), `"version":2`)
+	})
+
+	t.Run("Patch without a version", func(t *testing.T) {
+		result, resp := th.Client.PatchBlock(boardID, title("Any"))
+		require.NoError(t, resp.Error)
+		require.True(t, result.After.Version > result.Before.Version)
+	})
+}
@@ -0,0 +1,72 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardArchive(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	_, resp := th.Client.InsertBlocks([]model.Block{
+		{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board", Fields: map[string]interface{}{}},
+		{ID: cardID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card", Fields: map[string]interface{}{}},
+	})
+	require.NoError(t, resp.Error)
+
+	boardIDs := func(archived bool) []string {
+		boards, resp := th.Client.GetBoards(archived)
+		require.NoError(t, resp.Error)
+		ids := []string{}
+		for _, board := range boards {
+			ids = append(ids, board.ID)
+		}
+		return ids
+	}
+	require.Contains(t, boardIDs(false), boardID)
+	require.NotContains(t, boardIDs(true), boardID)
+
+	_, resp = th.Client.PatchBlock(boardID, &model.BlockPatch{
+		UpdatedFields: map[string]interface{}{model.BoardArchivedField: true},
+	})
+	require.NoError(t, resp.Error)
+	require.NotContains(t, boardIDs(false), boardID)
+	require.Contains(t, boardIDs(true), boardID)
+
+	t.Run("the blocks of an archived board are read-only", func(t *testing.T) {
+		title := "Renamed"
+		_, resp := th.Client.PatchBlock(cardID, &model.BlockPatch{Title: &title})
+		requireErrorCode(t, resp, http.StatusConflict, model.ErrorCodeConflict)
+
+		_, resp = th.Client.InsertBlocks([]model.Block{
+			{ID: utils.CreateGUID(), RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card"},
+		})
+		requireErrorCode(t, resp, http.StatusConflict, model.ErrorCodeConflict)
+
+		_, resp = th.Client.DeleteBlock(cardID)
+		requireErrorCode(t, resp, http.StatusConflict, model.ErrorCodeConflict)
+
+		_, resp = th.Client.PatchBlock(boardID, &model.BlockPatch{Title: &title})
+		requireErrorCode(t, resp, http.StatusConflict, model.ErrorCodeConflict)
+	})
+
+	t.Run("an archived board can be unarchived", func(t *testing.T) {
+		_, resp := th.Client.PatchBlock(boardID, &model.BlockPatch{
+			DeletedFields: []string{model.BoardArchivedField},
+		})
+		require.NoError(t, resp.Error)
+		require.Contains(t, boardIDs(false), boardID)
+		require.NotContains(t, boardIDs(true), boardID)
+
+		title := "Renamed"
+		_, resp = th.Client.PatchBlock(cardID, &model.BlockPatch{Title: &title})
+		require.NoError(t, resp.Error)
+	})
+}
@@ -0,0 +1,159 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// loginNewUser registers a user in the root workspace, with its sign-up
+// token, and returns a client logged in as the user.
+func loginNewUser(t *testing.T, th *TestHelper, username string) (*client.Client, *model.User) {
+	r, err := th.Client.DoAPIGet("/workspaces/0", "")
+	require.NoError(t, err)
+	var workspace model.Workspace
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&workspace))
+	_ = r.Body.Close()
+
+	password := utils.CreateGUID()
+	_, resp := th.Client.Register(&api.RegisterRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: password,
+		Token:    workspace.SignupToken,
+	})
+	require.NoError(t, resp.Error)
+
+	userClient := client.NewClient(th.Server.Config().ServerRoot, "")
+	_, resp = userClient.Login(&api.LoginRequest{Type: "normal", Username: username, Password: password})
+	require.NoError(t, resp.Error)
+	user, resp := userClient.GetMe()
+	require.NoError(t, resp.Error)
+	return userClient, user
+}
+
+func TestBoardMembers(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	_, resp := th.Client.Register(&api.RegisterRequest{Username: "owner", Email: "owner@example.com", Password: password})
+	require.NoError(t, resp.Error)
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "owner", Password: password})
+	require.NoError(t, resp.Error)
+	owner, resp := th.Client.GetMe()
+	require.NoError(t, resp.Error)
+
+	viewer, viewerUser := loginNewUser(t, th, "viewer")
+	outsider, _ := loginNewUser(t, th, "outsider")
+
+	now := utils.GetMillis()
+	_, resp = th.Client.InsertBlocks([]model.Block{
+		{ID: "private-board", RootID: "private-board", Type: "board", Title: "Private", CreateAt: now, UpdateAt: now},
+		{ID: "private-card", RootID: "private-board", ParentID: "private-board", Type: "card", Title: "Card", CreateAt: now, UpdateAt: now},
+		{ID: "open-board", RootID: "open-board", Type: "board", Title: "Open", CreateAt: now, UpdateAt: now},
+	})
+	require.NoError(t, resp.Error)
+
+	t.Run("the boards without members are open", func(t *testing.T) {
+		members, resp := outsider.GetBoardMembers("private-board")
+		require.NoError(t, resp.Error)
+		require.Empty(t, members)
+
+		blocks, resp := outsider.GetBlocks()
+		require.NoError(t, resp.Error)
+		require.Contains(t, blockIDs(blocks), "private-board")
+	})
+
+	t.Run("adding a member makes the user an admin", func(t *testing.T) {
+		member, resp := th.Client.AddBoardMember("private-board", viewerUser.ID, model.BoardRoleViewer)
+		require.NoError(t, resp.Error)
+		require.Equal(t, model.BoardRoleViewer, member.Role)
+
+		members, resp := th.Client.GetBoardMembers("private-board")
+		require.NoError(t, resp.Error)
+		roles := map[string]string{}
+		for _, member := range members {
+			roles[member.UserID] = member.Role
+		}
+		require.Equal(t, map[string]string{owner.ID: model.BoardRoleAdmin, viewerUser.ID: model.BoardRoleViewer}, roles)
+	})
+
+	t.Run("the restricted board is hidden from the other users", func(t *testing.T) {
+		blocks, resp := outsider.GetBlocks()
+		require.NoError(t, resp.Error)
+		require.NotContains(t, blockIDs(blocks), "private-board")
+		require.Contains(t, blockIDs(blocks), "open-board")
+
+		_, resp = outsider.GetSubtree("private-board")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, resp = outsider.GetBoardMembers("private-board")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		title := "Renamed"
+		_, resp = outsider.PatchBlock("private-card", &model.BlockPatch{Title: &title})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("a viewer can read but not edit", func(t *testing.T) {
+		blocks, resp := viewer.GetSubtree("private-board")
+		require.NoError(t, resp.Error)
+		require.Len(t, blocks, 2)
+
+		title := "Renamed"
+		_, resp = viewer.PatchBlock("private-card", &model.BlockPatch{Title: &title})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, resp = viewer.DeleteBlock("private-card")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, resp = viewer.AddBoardMember("private-board", viewerUser.ID, model.BoardRoleAdmin)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("an editor can edit", func(t *testing.T) {
+		_, resp := th.Client.UpdateBoardMember("private-board", viewerUser.ID, model.BoardRoleEditor)
+		require.NoError(t, resp.Error)
+
+		title := "Renamed"
+		_, resp = viewer.PatchBlock("private-card", &model.BlockPatch{Title: &title})
+		require.NoError(t, resp.Error)
+	})
+
+	t.Run("the board keeps an admin", func(t *testing.T) {
+		_, resp := th.Client.UpdateBoardMember("private-board", owner.ID, model.BoardRoleEditor)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, resp = th.Client.DeleteBoardMember("private-board", owner.ID)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, resp = th.Client.AddBoardMember("private-board", viewerUser.ID, "owner")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("removing the members opens the board again", func(t *testing.T) {
+		_, resp := viewer.DeleteBoardMember("private-board", viewerUser.ID)
+		require.NoError(t, resp.Error)
+		_, resp = th.Client.DeleteBoardMember("private-board", owner.ID)
+		require.NoError(t, resp.Error)
+
+		blocks, resp := outsider.GetBlocks()
+		require.NoError(t, resp.Error)
+		require.Contains(t, blockIDs(blocks), "private-board")
+	})
+}
+
+func blockIDs(blocks []model.Block) []string {
+	ids := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		ids = append(ids, block.ID)
+	}
+	return ids
+}
@@ -0,0 +1,89 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBoardStatistics(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	card := func(properties map[string]interface{}) model.Block {
+		return model.Block{
+			ID:       utils.CreateGUID(),
+			ParentID: boardID,
+			RootID:   boardID,
+			Type:     "card",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields:   map[string]interface{}{"properties": properties},
+		}
+	}
+	blocks := []model.Block{
+		{
+			ID:       boardID,
+			RootID:   boardID,
+			Type:     "board",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields: map[string]interface{}{
+				"cardProperties": []interface{}{
+					map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+						map[string]interface{}{"id": "todo", "value": "To do"},
+						map[string]interface{}{"id": "done", "value": "Done"},
+					}},
+					map[string]interface{}{"id": "owner", "name": "Owner", "type": "person"},
+				},
+			},
+		},
+		card(map[string]interface{}{"status": "done", "owner": "user-1"}),
+		card(map[string]interface{}{"status": "done", "owner": "user-2"}),
+		card(map[string]interface{}{"status": "todo", "owner": "user-1"}),
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Get the statistics", func(t *testing.T) {
+		stats, resp := th.Client.GetBoardStatistics(boardID, model.BoardStatisticsOptions{DoneOptionID: "done"})
+		require.NoError(t, resp.Error)
+		require.EqualValues(t, 3, stats.CardCount)
+		require.Equal(t, "status", stats.SelectPropertyID)
+		require.Equal(t, []model.PropertyValueCount{
+			{Value: "done", Name: "Done", Count: 2},
+			{Value: "todo", Name: "To do", Count: 1},
+		}, stats.ByOption)
+		require.Equal(t, "owner", stats.PersonPropertyID)
+		require.Equal(t, []model.PropertyValueCount{
+			{Value: "user-1", Count: 2},
+			{Value: "user-2", Count: 1},
+		}, stats.ByPerson)
+
+		require.Len(t, stats.Throughput, model.BoardStatisticsWeeks)
+		lastWeek := stats.Throughput[model.BoardStatisticsWeeks-1]
+		require.EqualValues(t, 3, lastWeek.Created)
+		require.EqualValues(t, 2, lastWeek.Completed)
+	})
+
+	t.Run("Unknown property or option", func(t *testing.T) {
+		_, resp := th.Client.GetBoardStatistics(boardID, model.BoardStatisticsOptions{SelectPropertyID: "owner"})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, resp = th.Client.GetBoardStatistics(boardID, model.BoardStatisticsOptions{DoneOptionID: "unknown"})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Unknown board", func(t *testing.T) {
+		_, resp := th.Client.GetBoardStatistics(utils.CreateGUID(), model.BoardStatisticsOptions{})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
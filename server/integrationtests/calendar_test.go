@@ -0,0 +1,116 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardCalendar(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	card := func(title string, due string) model.Block {
+		return model.Block{
+			ID:       utils.CreateGUID(),
+			RootID:   boardID,
+			ParentID: boardID,
+			Type:     "card",
+			Title:    title,
+			CreateAt: now,
+			UpdateAt: now,
+			Fields:   map[string]interface{}{"properties": map[string]interface{}{"due": due}},
+		}
+	}
+	deleted := card("Deleted", "1612137600000")
+	deleted.DeleteAt = now
+	blocks := []model.Block{
+		{
+			ID:       boardID,
+			RootID:   boardID,
+			Type:     "board",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields: map[string]interface{}{
+				"cardProperties": []interface{}{
+					map[string]interface{}{"id": "due", "name": "Due", "type": "date"},
+				},
+			},
+		},
+		card("Release", `{"from":1612137600000,"to":1612224000000}`),
+		deleted,
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Get the feed before it is created", func(t *testing.T) {
+		_, resp := th.Client.GetCalendarFeed(boardID)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		_, resp = th.Client.GetBoardCalendar(boardID, "")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	feed, resp := th.Client.PostCalendarFeed(boardID)
+	require.NoError(t, resp.Error)
+	require.NotNil(t, feed)
+	require.Equal(t, boardID, feed.BoardID)
+
+	t.Run("Get the calendar of the board", func(t *testing.T) {
+		// the calendar apps don't send the CSRF header
+		r, err := http.Get(th.Server.Config().ServerRoot + "/api/v1" + th.Client.GetBoardCalendarRoute(boardID, feed.Token))
+		require.NoError(t, err)
+		r.Body.Close()
+		require.Equal(t, http.StatusOK, r.StatusCode)
+
+		calendar, resp := th.Client.GetBoardCalendar(boardID, feed.Token)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.NotEmpty(t, resp.Header.Get("Cache-Control"))
+		require.Contains(t, calendar, "BEGIN:VCALENDAR\r\n")
+		require.Contains(t, calendar, "SUMMARY:Release\r\n")
+		require.Contains(t, calendar, "DTSTART;VALUE=DATE:20210201\r\n")
+		require.Contains(t, calendar, "DTEND;VALUE=DATE:20210203\r\n")
+		require.NotContains(t, calendar, "Deleted")
+	})
+
+	t.Run("Get the calendar with another token", func(t *testing.T) {
+		_, resp := th.Client.GetBoardCalendar(boardID, utils.CreateGUID())
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Regenerate the token", func(t *testing.T) {
+		newFeed, resp := th.Client.PostCalendarFeed(boardID)
+		require.NoError(t, resp.Error)
+		require.NotEqual(t, feed.Token, newFeed.Token)
+
+		stored, resp := th.Client.GetCalendarFeed(boardID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, newFeed.Token, stored.Token)
+
+		_, resp = th.Client.GetBoardCalendar(boardID, feed.Token)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		feed = newFeed
+	})
+
+	t.Run("Revoke the feed", func(t *testing.T) {
+		_, resp := th.Client.DeleteCalendarFeed(boardID)
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.GetBoardCalendar(boardID, feed.Token)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		_, resp = th.Client.DeleteCalendarFeed(boardID)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Create the feed of an unknown board", func(t *testing.T) {
+		_, resp := th.Client.PostCalendarFeed(utils.CreateGUID())
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
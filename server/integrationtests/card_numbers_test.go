@@ -0,0 +1,72 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCardByNumber(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	card := func(title string) model.Block {
+		return model.Block{
+			ID:       utils.CreateGUID(),
+			RootID:   boardID,
+			ParentID: boardID,
+			Type:     "card",
+			Title:    title,
+			CreateAt: now,
+			UpdateAt: now,
+		}
+	}
+	blocks := []model.Block{
+		{ID: boardID, RootID: boardID, Type: "board", CreateAt: now, UpdateAt: now},
+		card("First"),
+		card("Second"),
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Get a card by its number", func(t *testing.T) {
+		card, resp := th.Client.GetCardByNumber(boardID, 2)
+		require.NoError(t, resp.Error)
+		require.NotNil(t, card)
+		require.Equal(t, blocks[2].ID, card.ID)
+		require.EqualValues(t, 2, card.Fields[model.CardNumberField])
+	})
+
+	t.Run("Number the cards of a duplicated board from the start", func(t *testing.T) {
+		board, resp := th.Client.DuplicateBoard(boardID, false)
+		require.NoError(t, resp.Error)
+		require.NotNil(t, board)
+
+		card, resp := th.Client.GetCardByNumber(board.ID, 1)
+		require.NoError(t, resp.Error)
+		require.NotNil(t, card)
+		require.Equal(t, "First", card.Title)
+
+		_, resp = th.Client.GetCardByNumber(board.ID, 3)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Get an unknown number", func(t *testing.T) {
+		card, resp := th.Client.GetCardByNumber(boardID, 3)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Nil(t, card)
+	})
+
+	t.Run("Get an invalid number", func(t *testing.T) {
+		card, resp := th.Client.GetCardByNumber(boardID, 0)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Nil(t, card)
+	})
+}
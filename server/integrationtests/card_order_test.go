@@ -0,0 +1,101 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveCard(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	viewID := utils.CreateGUID()
+	cardIDs := []string{utils.CreateGUID(), utils.CreateGUID(), utils.CreateGUID()}
+	blocks := []model.Block{
+		{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board", Fields: map[string]interface{}{}},
+		{ID: viewID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "view", Fields: map[string]interface{}{
+			model.ViewCardOrderField: []interface{}{cardIDs[0], cardIDs[1], cardIDs[2]},
+		}},
+	}
+	for _, cardID := range cardIDs {
+		blocks = append(blocks, model.Block{ID: cardID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card", Fields: map[string]interface{}{}})
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+	// the history keeps one version of a block per millisecond
+	time.Sleep(2 * time.Millisecond)
+
+	blocksWithType := func(blockType string) []model.Block {
+		blocks, resp := th.Client.GetSubtree(boardID)
+		require.NoError(t, resp.Error)
+		filtered := []model.Block{}
+		for _, block := range blocks {
+			if block.Type == blockType {
+				filtered = append(filtered, block)
+			}
+		}
+		return filtered
+	}
+	// the IDs of the cards sorted by their order keys
+	orderedCardIDs := func() []string {
+		keys := map[string]string{}
+		for _, card := range blocksWithType("card") {
+			key, _ := card.Fields[model.CardOrderField].(string)
+			require.NotEmpty(t, key)
+			keys[card.ID] = key
+		}
+		ids := append([]string{}, cardIDs...)
+		for i := range ids {
+			for j := i + 1; j < len(ids); j++ {
+				if keys[ids[j]] < keys[ids[i]] {
+					ids[i], ids[j] = ids[j], ids[i]
+				}
+			}
+		}
+		return ids
+	}
+	legacyCardOrder := func() []interface{} {
+		views := blocksWithType("view")
+		require.Len(t, views, 1)
+		return views[0].Fields[model.ViewCardOrderField].([]interface{})
+	}
+
+	t.Run("should move the card between its neighbors", func(t *testing.T) {
+		card, resp := th.Client.MoveCard(cardIDs[2], model.CardMove{BeforeID: cardIDs[0], AfterID: cardIDs[1]})
+		require.NoError(t, resp.Error)
+		require.NotEmpty(t, card.Fields[model.CardOrderField])
+
+		require.Equal(t, []string{cardIDs[0], cardIDs[2], cardIDs[1]}, orderedCardIDs())
+		require.Equal(t, []interface{}{cardIDs[0], cardIDs[2], cardIDs[1]}, legacyCardOrder())
+	})
+
+	t.Run("should move the card first or last", func(t *testing.T) {
+		time.Sleep(2 * time.Millisecond)
+		_, resp := th.Client.MoveCard(cardIDs[1], model.CardMove{AfterID: cardIDs[0]})
+		require.NoError(t, resp.Error)
+		time.Sleep(2 * time.Millisecond)
+		_, resp = th.Client.MoveCard(cardIDs[0], model.CardMove{})
+		require.NoError(t, resp.Error)
+
+		require.Equal(t, []string{cardIDs[1], cardIDs[2], cardIDs[0]}, orderedCardIDs())
+		require.Equal(t, []interface{}{cardIDs[1], cardIDs[2], cardIDs[0]}, legacyCardOrder())
+	})
+
+	t.Run("should fail if the neighbor isn't a card of the board", func(t *testing.T) {
+		_, resp := th.Client.MoveCard(cardIDs[0], model.CardMove{BeforeID: viewID})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, resp = th.Client.MoveCard(viewID, model.CardMove{})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, resp = th.Client.MoveCard(utils.CreateGUID(), model.CardMove{})
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
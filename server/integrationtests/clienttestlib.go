@@ -66,16 +66,19 @@ func getTestConfig() *config.Configuration {
 }
 
 func newTestServer(singleUserToken string) *server.Server {
+	return newTestServerWithConfig(getTestConfig(), singleUserToken)
+}
+
+func newTestServerWithConfig(cfg *config.Configuration, singleUserToken string) *server.Server {
 	logger, _ := mlog.NewLogger()
-	if err := logger.Configure("", getTestConfig().LoggingCfgJSON); err != nil {
+	if err := logger.Configure("", cfg.LoggingCfgJSON); err != nil {
 		panic(err)
 	}
-	cfg := getTestConfig()
 	db, err := server.NewStore(cfg, logger)
 	if err != nil {
 		panic(err)
 	}
-	srv, err := server.New(cfg, singleUserToken, db, logger, "", nil)
+	srv, err := server.New(cfg, singleUserToken, db, logger, "", nil, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -91,6 +94,16 @@ func SetupTestHelper() *TestHelper {
 	return th
 }
 
+// SetupTestHelperWithConfig returns a helper for a single user server
+// with the configuration, which should be based on getTestConfig.
+func SetupTestHelperWithConfig(cfg *config.Configuration) *TestHelper {
+	sessionToken := "TESTTOKEN"
+	th := &TestHelper{}
+	th.Server = newTestServerWithConfig(cfg, sessionToken)
+	th.Client = client.NewClient(th.Server.Config().ServerRoot, sessionToken)
+	return th
+}
+
 func SetupTestHelperWithoutToken() *TestHelper {
 	th := &TestHelper{}
 	th.Server = newTestServer("")
@@ -98,6 +111,16 @@ func SetupTestHelperWithoutToken() *TestHelper {
 	return th
 }
 
+// SetupTestHelperWithConfigWithoutToken returns a helper for a server
+// without a single user token, with the configuration, which should be
+// based on getTestConfig.
+func SetupTestHelperWithConfigWithoutToken(cfg *config.Configuration) *TestHelper {
+	th := &TestHelper{}
+	th.Server = newTestServerWithConfig(cfg, "")
+	th.Client = client.NewClient(th.Server.Config().ServerRoot, "")
+	return th
+}
+
 func (th *TestHelper) InitBasic() *TestHelper {
 	go func() {
 		if err := th.Server.Start(); err != nil {
@@ -0,0 +1,199 @@
+package integrationtests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// getWithEncoding gets the URL accepting the encoding, without the
+// transparent decompression of the HTTP client.
+func getWithEncoding(tb testing.TB, th *TestHelper, url, encoding string) (*http.Response, []byte) {
+	rq, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(tb, err)
+	for k, v := range th.Client.HTTPHeader {
+		rq.Header.Set(k, v)
+	}
+	rq.Header.Set("Authorization", "Bearer "+th.Client.Token)
+	if encoding != "" {
+		rq.Header.Set("Accept-Encoding", encoding)
+	}
+
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	rp, err := client.Do(rq)
+	require.NoError(tb, err)
+	defer rp.Body.Close()
+
+	data, err := ioutil.ReadAll(rp.Body)
+	require.NoError(tb, err)
+	return rp, data
+}
+
+func gunzip(tb testing.TB, data []byte) []byte {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(tb, err)
+	decompressed, err := ioutil.ReadAll(reader)
+	require.NoError(tb, err)
+	return decompressed
+}
+
+// insertBoardFixture inserts a board with blockCount cards, with the
+// properties and titles of a typical board.
+func insertBoardFixture(tb testing.TB, th *TestHelper, blockCount int) string {
+	boardID := utils.CreateGUID()
+	blocks := []model.Block{{
+		ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board", Title: "Roadmap",
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+					map[string]interface{}{"id": "todo", "value": "To do", "color": "propColorGray"},
+					map[string]interface{}{"id": "done", "value": "Done", "color": "propColorGreen"},
+				}},
+				map[string]interface{}{"id": "estimate", "name": "Estimate", "type": "number"},
+			},
+		},
+	}}
+	for i := 1; i < blockCount; i++ {
+		status := "todo"
+		if i%3 == 0 {
+			status = "done"
+		}
+		blocks = append(blocks, model.Block{
+			ID: utils.CreateGUID(), RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card",
+			Title: fmt.Sprintf("Card %d of the roadmap", i),
+			Fields: map[string]interface{}{
+				"icon":       "📋",
+				"properties": map[string]interface{}{"status": status, "estimate": fmt.Sprint(i % 13)},
+			},
+		})
+	}
+	for start := 0; start < len(blocks); start += 500 {
+		end := start + 500
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+		_, resp := th.Client.InsertBlocks(blocks[start:end])
+		require.NoError(tb, resp.Error)
+	}
+	return boardID
+}
+
+func TestCompression(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := insertBoardFixture(t, th, 200)
+	blocksURL := th.Client.APIURL + th.Client.GetBlocksRoute() + "?parent_id=" + boardID
+
+	t.Run("Compress the large responses", func(t *testing.T) {
+		plain, plainData := getWithEncoding(t, th, blocksURL, "")
+		require.Equal(t, http.StatusOK, plain.StatusCode)
+		require.Empty(t, plain.Header.Get("Content-Encoding"))
+
+		compressed, compressedData := getWithEncoding(t, th, blocksURL, "br;q=0, gzip")
+		require.Equal(t, http.StatusOK, compressed.StatusCode)
+		require.Equal(t, "gzip", compressed.Header.Get("Content-Encoding"))
+		require.Contains(t, compressed.Header.Values("Vary"), "Accept-Encoding")
+		require.Less(t, len(compressedData), len(plainData)/4)
+		require.Equal(t, plainData, gunzip(t, compressedData))
+	})
+
+	t.Run("Weaken the entity tag of the compressed responses", func(t *testing.T) {
+		plain, _ := getWithEncoding(t, th, blocksURL, "")
+		etag := plain.Header.Get("ETag")
+		require.NotEmpty(t, etag)
+		require.False(t, strings.HasPrefix(etag, "W/"))
+
+		compressed, _ := getWithEncoding(t, th, blocksURL, "gzip")
+		require.Equal(t, "gzip", compressed.Header.Get("Content-Encoding"))
+		require.Equal(t, "W/"+etag, compressed.Header.Get("ETag"))
+
+		// both tags validate the cached response
+		for _, tag := range []string{etag, "W/" + etag} {
+			rq, err := http.NewRequest(http.MethodGet, blocksURL, nil)
+			require.NoError(t, err)
+			for k, v := range th.Client.HTTPHeader {
+				rq.Header.Set(k, v)
+			}
+			rq.Header.Set("Authorization", "Bearer "+th.Client.Token)
+			rq.Header.Set("Accept-Encoding", "gzip")
+			rq.Header.Set("If-None-Match", tag)
+			rp, err := (&http.Client{Transport: &http.Transport{DisableCompression: true}}).Do(rq)
+			require.NoError(t, err)
+			rp.Body.Close()
+			require.Equal(t, http.StatusNotModified, rp.StatusCode)
+		}
+	})
+
+	t.Run("Don't compress the small responses", func(t *testing.T) {
+		rp, data := getWithEncoding(t, th, th.Client.APIURL+th.Client.GetBlocksRoute()+"?parent_id="+utils.CreateGUID(), "gzip")
+		require.Equal(t, http.StatusOK, rp.StatusCode)
+		require.Empty(t, rp.Header.Get("Content-Encoding"))
+		require.Equal(t, "[]", string(data))
+	})
+
+	t.Run("Don't compress the encodings the client refuses", func(t *testing.T) {
+		rp, _ := getWithEncoding(t, th, blocksURL, "gzip;q=0, deflate")
+		require.Empty(t, rp.Header.Get("Content-Encoding"))
+	})
+
+	t.Run("Don't compress the attachments again", func(t *testing.T) {
+		var archive bytes.Buffer
+		writer := gzip.NewWriter(&archive)
+		_, err := writer.Write(bytes.Repeat([]byte("attachment "), 1000))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		file, resp := th.Client.WorkspaceUploadFile("0", boardID, bytes.NewReader(archive.Bytes()))
+		require.NoError(t, resp.Error)
+
+		rp, data := getWithEncoding(t, th, th.Client.URL+th.Client.GetFileRoute("0", boardID, file.FileID), "gzip")
+		require.Equal(t, http.StatusOK, rp.StatusCode)
+		require.Empty(t, rp.Header.Get("Content-Encoding"))
+		require.Equal(t, archive.Bytes(), data)
+	})
+}
+
+// BenchmarkCompression reports the size of the blocks of a board of 2,000
+// blocks, compressed or not.
+func BenchmarkCompression(b *testing.B) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := insertBoardFixture(b, th, 2000)
+	blocksURL := th.Client.APIURL + th.Client.GetBlocksRoute() + "?parent_id=" + boardID
+
+	for _, encoding := range []string{"identity", "gzip"} {
+		b.Run(encoding, func(b *testing.B) {
+			var size int
+			for i := 0; i < b.N; i++ {
+				rp, data := getWithEncoding(b, th, blocksURL, encoding)
+				require.Equal(b, http.StatusOK, rp.StatusCode)
+				size = len(data)
+			}
+			b.ReportMetric(float64(size), "bytes/response")
+		})
+	}
+
+	b.Run("attachment", func(b *testing.B) {
+		data := bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 1024)
+		file, resp := th.Client.WorkspaceUploadFile("0", boardID, bytes.NewReader(data))
+		require.NoError(b, resp.Error)
+		fileURL := th.Client.URL + th.Client.GetFileRoute("0", boardID, file.FileID)
+
+		for i := 0; i < b.N; i++ {
+			rp, downloaded := getWithEncoding(b, th, fileURL, "gzip")
+			require.Empty(b, rp.Header.Get("Content-Encoding"))
+			require.Equal(b, data, downloaded)
+		}
+		b.ReportMetric(float64(len(data)), "bytes/response")
+	})
+}
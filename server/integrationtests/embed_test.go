@@ -0,0 +1,93 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardEmbed(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.EmbedSigningKey = "signing-key"
+	th := SetupTestHelperWithConfig(cfg).InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	blocks := []model.Block{
+		{ID: boardID, RootID: boardID, Type: "board", Title: "Roadmap", CreateAt: now, UpdateAt: now},
+		{ID: utils.CreateGUID(), RootID: boardID, ParentID: boardID, Type: "card", Title: "<b>Launch</b>", CreateAt: now, UpdateAt: now},
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	// the frames don't send the CSRF header
+	get := func(url string) (*http.Response, string) {
+		r, err := http.Get(url) //nolint:gosec
+		require.NoError(t, err)
+		defer r.Body.Close()
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		return r, string(body)
+	}
+
+	embed, resp := th.Client.PostBoardEmbed(boardID, api.BoardEmbedRequest{})
+	require.NoError(t, resp.Error)
+	require.Equal(t, boardID, embed.BoardID)
+	require.Greater(t, embed.ExpireAt, now)
+
+	t.Run("Get the blocks of the embedded board", func(t *testing.T) {
+		r, body := get(embed.URL)
+		require.Equal(t, http.StatusOK, r.StatusCode)
+		require.Empty(t, r.Header.Get("X-Frame-Options"))
+		require.Equal(t, "frame-ancestors *", r.Header.Get("Content-Security-Policy"))
+
+		var embedded []model.Block
+		require.NoError(t, json.Unmarshal([]byte(body), &embedded))
+		require.Len(t, embedded, 2)
+	})
+
+	t.Run("Get the page of the embedded board", func(t *testing.T) {
+		r, body := get(embed.URL + "&format=html")
+		require.Equal(t, http.StatusOK, r.StatusCode)
+		require.Equal(t, "text/html; charset=utf-8", r.Header.Get("Content-Type"))
+		require.Contains(t, body, "<h1>Roadmap</h1>")
+		require.Contains(t, body, "<li>&lt;b&gt;Launch&lt;/b&gt;</li>")
+		require.NotContains(t, body, "<b>Launch</b>")
+	})
+
+	t.Run("Get the board with an invalid signature", func(t *testing.T) {
+		r, _ := get(embed.URL + "x")
+		require.Equal(t, http.StatusNotFound, r.StatusCode)
+	})
+
+	t.Run("Create an embed URL with an expiry in the past", func(t *testing.T) {
+		_, resp := th.Client.PostBoardEmbed(boardID, api.BoardEmbedRequest{ExpireAt: now - 1000})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Create an embed URL of an unknown board", func(t *testing.T) {
+		_, resp := th.Client.PostBoardEmbed(utils.CreateGUID(), api.BoardEmbedRequest{})
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Rotate the signing key", func(t *testing.T) {
+		th.Server.Config().EmbedSigningKey = "new-signing-key"
+		r, _ := get(embed.URL)
+		require.Equal(t, http.StatusNotFound, r.StatusCode)
+	})
+}
+
+func TestBoardEmbedNotConfigured(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	_, resp := th.Client.PostBoardEmbed(utils.CreateGUID(), api.BoardEmbedRequest{})
+	require.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
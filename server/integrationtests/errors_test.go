@@ -0,0 +1,71 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func requireErrorCode(t *testing.T, resp *client.Response, statusCode int, code string) {
+	t.Helper()
+	require.Error(t, resp.Error)
+	require.Equal(t, statusCode, resp.StatusCode)
+	require.Contains(t, resp.Error.Error(), `"code":"`+code+`"`)
+}
+
+func TestErrorCodes(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		r, err := th.Client.DoAPIPost("/workspaces/0/blocks", "{not json")
+		require.Error(t, err)
+		require.Equal(t, http.StatusBadRequest, r.StatusCode)
+		requireErrorCode(t, client.BuildErrorResponse(r, err), http.StatusBadRequest, model.ErrorCodeBadRequest)
+	})
+
+	t.Run("invalid block", func(t *testing.T) {
+		blockID := utils.CreateGUID()
+		_, resp := th.Client.InsertBlocks([]model.Block{{ID: blockID, RootID: blockID, CreateAt: 1, UpdateAt: 1}})
+		requireErrorCode(t, resp, http.StatusBadRequest, model.ErrorCodeInvalidBlock)
+		require.Contains(t, resp.Error.Error(), `"details":{"blockId":"`+blockID+`"}`)
+	})
+
+	t.Run("block not found", func(t *testing.T) {
+		title := "New title"
+		_, resp := th.Client.PatchBlock(utils.CreateGUID(), &model.BlockPatch{Title: &title})
+		requireErrorCode(t, resp, http.StatusNotFound, model.ErrorCodeNotFound)
+
+		_, resp = th.Client.UndeleteBlock(utils.CreateGUID())
+		requireErrorCode(t, resp, http.StatusNotFound, model.ErrorCodeNotFound)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		_, resp := th.Client.GetUser(utils.CreateGUID())
+		requireErrorCode(t, resp, http.StatusNotFound, model.ErrorCodeNotFound)
+	})
+
+	t.Run("workspace mismatch", func(t *testing.T) {
+		r, err := th.Client.DoAPIGet("/workspaces/other-workspace/blocks", "")
+		requireErrorCode(t, client.BuildErrorResponse(r, err), http.StatusBadRequest, model.ErrorCodeWorkspaceMismatch)
+	})
+
+	t.Run("the older clients still get the error message", func(t *testing.T) {
+		_, resp := th.Client.InsertBlocks([]model.Block{{ID: "block-id"}})
+		require.Contains(t, resp.Error.Error(), `"error":"missing type for block id block-id"`)
+		require.Contains(t, resp.Error.Error(), `"errorCode":400`)
+	})
+}
+
+func TestForbiddenErrorCode(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	// the files can be read without a session with a read token only
+	_, resp := th.Client.GetFile("0", utils.CreateGUID(), "file.png", "")
+	requireErrorCode(t, resp, http.StatusForbidden, model.ErrorCodeForbidden)
+}
@@ -0,0 +1,177 @@
+package integrationtests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportBoardCSV(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	viewID := utils.CreateGUID()
+	now := utils.GetMillis()
+	blocks := []model.Block{
+		{
+			ID:       boardID,
+			RootID:   boardID,
+			Type:     "board",
+			Title:    "Board",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields: map[string]interface{}{
+				"cardProperties": []interface{}{
+					map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+						map[string]interface{}{"id": "done", "value": "Done"},
+					}},
+					map[string]interface{}{"id": "notes", "name": "Notes", "type": "text"},
+				},
+			},
+		},
+		{
+			ID:       viewID,
+			ParentID: boardID,
+			RootID:   boardID,
+			Type:     "view",
+			Title:    "View",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields: map[string]interface{}{
+				"visiblePropertyIds": []interface{}{"status"},
+			},
+		},
+		{
+			ID:       utils.CreateGUID(),
+			ParentID: boardID,
+			RootID:   boardID,
+			Type:     "card",
+			Title:    "Card",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields: map[string]interface{}{
+				"properties": map[string]interface{}{"status": "done", "notes": "Some notes"},
+			},
+		},
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Export a view", func(t *testing.T) {
+		data, resp := th.Client.ExportBoardCSV(boardID, viewID)
+		require.NoError(t, resp.Error)
+
+		lines := strings.Split(strings.TrimSpace(data), "\n")
+		require.Len(t, lines, 2)
+		require.Equal(t, "Title,Status,Created,Updated", lines[0])
+		require.True(t, strings.HasPrefix(lines[1], "Card,Done,"))
+	})
+
+	t.Run("Export all the properties", func(t *testing.T) {
+		data, resp := th.Client.ExportBoardCSV(boardID, "")
+		require.NoError(t, resp.Error)
+		require.True(t, strings.HasPrefix(data, "Title,Status,Notes,Created,Updated\n"))
+		require.Contains(t, data, "Card,Done,Some notes,")
+	})
+
+	t.Run("Unknown board or view", func(t *testing.T) {
+		_, resp := th.Client.ExportBoardCSV(utils.CreateGUID(), "")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		_, resp = th.Client.ExportBoardCSV(boardID, utils.CreateGUID())
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestExportBoardMarkdown(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	locale := "de"
+	_, resp := th.Client.PatchWorkspaceSettings(model.WorkspaceSettingsPatch{Locale: &locale})
+	require.NoError(t, resp.Error)
+
+	boardID := utils.CreateGUID()
+	viewID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	textID := utils.CreateGUID()
+	now := utils.GetMillis()
+	blocks := []model.Block{
+		{
+			ID:       boardID,
+			RootID:   boardID,
+			Type:     "board",
+			Title:    "Board",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields: map[string]interface{}{
+				"cardProperties": []interface{}{
+					map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+						map[string]interface{}{"id": "done", "value": "Done"},
+					}},
+					map[string]interface{}{"id": "due", "name": "Due", "type": "date"},
+				},
+			},
+		},
+		{
+			ID:       viewID,
+			ParentID: boardID,
+			RootID:   boardID,
+			Type:     "view",
+			Title:    "View",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields: map[string]interface{}{
+				"groupById":          "status",
+				"visiblePropertyIds": []interface{}{"due"},
+			},
+		},
+		{
+			ID:       cardID,
+			ParentID: boardID,
+			RootID:   boardID,
+			Type:     "card",
+			Title:    "Card",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields: map[string]interface{}{
+				"properties":   map[string]interface{}{"status": "done", "due": "1634169600000"},
+				"contentOrder": []interface{}{textID},
+			},
+		},
+		{
+			ID:       textID,
+			ParentID: cardID,
+			RootID:   boardID,
+			Type:     "text",
+			Title:    "Description",
+			CreateAt: now,
+			UpdateAt: now,
+		},
+	}
+	_, resp = th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Export the first view", func(t *testing.T) {
+		data, resp := th.Client.ExportBoardMarkdown(boardID, "")
+		require.NoError(t, resp.Error)
+		require.Equal(t, "# Board\n\n## Done\n\n- **Card** — Due: 14.10.2021\n\n  Description\n", data)
+	})
+
+	t.Run("Unknown board or view", func(t *testing.T) {
+		_, resp := th.Client.ExportBoardMarkdown(utils.CreateGUID(), "")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		_, resp = th.Client.ExportBoardMarkdown(boardID, utils.CreateGUID())
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,284 @@
+package integrationtests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitHub serves the issues and records the comments of a
+// repository, as the GitHub API would.
+type fakeGitHub struct {
+	mu          sync.Mutex
+	issues      []map[string]interface{}
+	comments    map[string][]string
+	rateLimited bool
+	calls       int
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+
+	if f.rateLimited {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/owner/repo/issues":
+		_ = json.NewEncoder(w).Encode(f.issues)
+	case r.Method == http.MethodPost:
+		var comment map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&comment)
+		f.comments[r.URL.Path] = append(f.comments[r.URL.Path], comment["body"])
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeGitHub) setIssues(issues ...map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.issues = issues
+}
+
+func gitHubIssue(number int, title, state string, updatedAt time.Time, labels ...string) map[string]interface{} {
+	issueLabels := []interface{}{}
+	for _, label := range labels {
+		issueLabels = append(issueLabels, map[string]interface{}{"name": label})
+	}
+	return map[string]interface{}{
+		"number":     number,
+		"title":      title,
+		"body":       title + " details",
+		"state":      state,
+		"labels":     issueLabels,
+		"updated_at": updatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func TestGitHubIntegrationNotConfigured(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	_, resp := th.Client.InsertBlocks([]model.Block{{ID: boardID, RootID: boardID, Type: "board", CreateAt: now, UpdateAt: now}})
+	require.NoError(t, resp.Error)
+
+	_, resp = th.Client.PutGitHubIntegration(boardID, api.GitHubIntegrationRequest{Repository: "owner/repo", Token: "secret-token"})
+	require.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
+
+func TestGitHubIntegration(t *testing.T) {
+	fake := &fakeGitHub{comments: map[string][]string{}}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	cfg := getTestConfig()
+	cfg.IntegrationsEncryptionKey = "encryption-key"
+	cfg.GitHubAPIURL = ts.URL
+	th := SetupTestHelperWithConfig(cfg).InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	_, resp := th.Client.InsertBlocks([]model.Block{{ID: boardID, RootID: boardID, Type: "board", Title: "Issues", CreateAt: now, UpdateAt: now}})
+	require.NoError(t, resp.Error)
+
+	getCards := func() map[string]model.Block {
+		blocks, resp := th.Client.GetSubtree(boardID)
+		require.NoError(t, resp.Error)
+		cards := map[string]model.Block{}
+		for _, block := range blocks {
+			if block.Type == "card" {
+				cards[block.Title] = block
+			}
+		}
+		return cards
+	}
+
+	t.Run("should reject an invalid configuration", func(t *testing.T) {
+		_, resp := th.Client.PutGitHubIntegration(boardID, api.GitHubIntegrationRequest{Repository: "owner/repo"})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, resp = th.Client.PutGitHubIntegration(boardID, api.GitHubIntegrationRequest{Repository: "repo", Token: "secret-token"})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	integration, resp := th.Client.PutGitHubIntegration(boardID, api.GitHubIntegrationRequest{
+		Repository:     "owner/repo",
+		Token:          "secret-token",
+		CommentOnClose: true,
+	})
+	require.NoError(t, resp.Error)
+	require.NotEmpty(t, integration.WebhookSecret)
+	require.NotEmpty(t, integration.StatePropertyID)
+	require.NotEmpty(t, integration.LabelsPropertyID)
+
+	stored, resp := th.Client.GetGitHubIntegration(boardID)
+	require.NoError(t, resp.Error)
+	require.Empty(t, stored.WebhookSecret)
+	require.Equal(t, "owner/repo", stored.Repository)
+
+	var closedOptionID string
+	t.Run("should add the state and labels properties to the board", func(t *testing.T) {
+		blocks, resp := th.Client.GetSubtree(boardID)
+		require.NoError(t, resp.Error)
+		cardProperties := blocks[0].Fields["cardProperties"].([]interface{})
+		require.Len(t, cardProperties, 2)
+
+		state := cardProperties[0].(map[string]interface{})
+		require.Equal(t, integration.StatePropertyID, state["id"])
+		require.Equal(t, "select", state["type"])
+		options := state["options"].([]interface{})
+		require.Len(t, options, 2)
+		require.Equal(t, "Closed", options[1].(map[string]interface{})["value"])
+		closedOptionID = options[1].(map[string]interface{})["id"].(string)
+
+		labels := cardProperties[1].(map[string]interface{})
+		require.Equal(t, integration.LabelsPropertyID, labels["id"])
+		require.Equal(t, "multiSelect", labels["type"])
+	})
+
+	updatedAt := time.Now().Add(-time.Hour)
+	t.Run("should create a card per issue", func(t *testing.T) {
+		fake.setIssues(
+			gitHubIssue(1, "First issue", "open", updatedAt, "bug"),
+			gitHubIssue(2, "Second issue", "closed", updatedAt),
+		)
+		synced, resp := th.Client.PostGitHubSync(boardID)
+		require.NoError(t, resp.Error)
+		require.NotZero(t, synced.LastSyncAt)
+		require.Empty(t, synced.LastError)
+
+		cards := getCards()
+		require.Len(t, cards, 2)
+		first := cards["First issue"]
+		properties := first.Fields["properties"].(map[string]interface{})
+		require.NotEqual(t, closedOptionID, properties[integration.StatePropertyID])
+		require.Len(t, properties[integration.LabelsPropertyID], 1)
+		require.Equal(t, closedOptionID, cards["Second issue"].Fields["properties"].(map[string]interface{})[integration.StatePropertyID])
+
+		contentOrder := first.Fields["contentOrder"].([]interface{})
+		require.Len(t, contentOrder, 1)
+		blocks, resp := th.Client.GetSubtree(first.ID)
+		require.NoError(t, resp.Error)
+		for _, block := range blocks {
+			if block.ID == contentOrder[0] {
+				require.Equal(t, "First issue details", block.Title)
+			}
+		}
+	})
+
+	t.Run("should update the card of an updated issue", func(t *testing.T) {
+		updatedAt = updatedAt.Add(time.Minute)
+		fake.setIssues(gitHubIssue(1, "First issue renamed", "open", updatedAt, "bug", "ui"))
+		_, resp := th.Client.PostGitHubSync(boardID)
+		require.NoError(t, resp.Error)
+
+		cards := getCards()
+		require.Len(t, cards, 2)
+		renamed, ok := cards["First issue renamed"]
+		require.True(t, ok)
+		require.Len(t, renamed.Fields["properties"].(map[string]interface{})[integration.LabelsPropertyID], 2)
+	})
+
+	t.Run("should comment on the issue of a closed card", func(t *testing.T) {
+		card := getCards()["First issue renamed"]
+		patch := &model.BlockPatch{UpdatedProperties: map[string]interface{}{integration.StatePropertyID: closedOptionID}}
+		_, resp := th.Client.PatchBlock(card.ID, patch)
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.PostGitHubSync(boardID)
+		require.NoError(t, resp.Error)
+		_, resp = th.Client.PostGitHubSync(boardID)
+		require.NoError(t, resp.Error)
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		require.Len(t, fake.comments["/repos/owner/repo/issues/1/comments"], 1)
+		require.Empty(t, fake.comments["/repos/owner/repo/issues/2/comments"])
+
+		// the open issue doesn't reopen the card
+		closed := getCards()["First issue renamed"]
+		require.Equal(t, closedOptionID, closed.Fields["properties"].(map[string]interface{})[integration.StatePropertyID])
+	})
+
+	t.Run("should apply the issue of a signed webhook delivery", func(t *testing.T) {
+		event := map[string]interface{}{
+			"action":     "opened",
+			"issue":      gitHubIssue(3, "Third issue", "open", time.Now()),
+			"repository": map[string]interface{}{"full_name": "owner/repo"},
+		}
+		payload, err := json.Marshal(event)
+		require.NoError(t, err)
+		mac := hmac.New(sha256.New, []byte(integration.WebhookSecret))
+		mac.Write(payload)
+		signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+		// GitHub has no session
+		gitHubClient := client.NewClient(th.Server.Config().ServerRoot, "")
+
+		_, resp := gitHubClient.PostGitHubWebhook(boardID, "issues", "sha256=00", string(payload))
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		_, resp = gitHubClient.PostGitHubWebhook(boardID, "ping", signature, string(payload))
+		require.NoError(t, resp.Error)
+		require.Len(t, getCards(), 2)
+
+		_, resp = gitHubClient.PostGitHubWebhook(boardID, "issues", signature, string(payload))
+		require.NoError(t, resp.Error)
+		require.Contains(t, getCards(), "Third issue")
+	})
+
+	t.Run("should respect the rate limit of GitHub", func(t *testing.T) {
+		fake.mu.Lock()
+		fake.rateLimited = true
+		fake.mu.Unlock()
+
+		_, resp := th.Client.PostGitHubSync(boardID)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		fake.mu.Lock()
+		calls := fake.calls
+		fake.mu.Unlock()
+
+		stored, resp := th.Client.GetGitHubIntegration(boardID)
+		require.NoError(t, resp.Error)
+		require.Greater(t, stored.RateLimitResetAt, utils.GetMillis())
+		require.NotEmpty(t, stored.LastError)
+
+		_, resp = th.Client.PostGitHubSync(boardID)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		fake.mu.Lock()
+		require.Equal(t, calls, fake.calls)
+		fake.mu.Unlock()
+	})
+
+	t.Run("should delete the integration", func(t *testing.T) {
+		_, resp := th.Client.DeleteGitHubIntegration(boardID)
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.GetGitHubIntegration(boardID)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Len(t, getCards(), 3)
+	})
+}
@@ -0,0 +1,128 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuests(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	_, resp := th.Client.Register(&api.RegisterRequest{Username: "owner", Email: "owner@example.com", Password: password})
+	require.NoError(t, resp.Error)
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "owner", Password: password})
+	require.NoError(t, resp.Error)
+
+	now := utils.GetMillis()
+	_, resp = th.Client.InsertBlocks([]model.Block{
+		{ID: "client-board", RootID: "client-board", Type: "board", Title: "Client", CreateAt: now, UpdateAt: now},
+		{ID: "client-card", RootID: "client-board", ParentID: "client-board", Type: "card", Title: "Card", CreateAt: now, UpdateAt: now},
+		{ID: "internal-board", RootID: "internal-board", Type: "board", Title: "Internal", CreateAt: now, UpdateAt: now},
+	})
+	require.NoError(t, resp.Error)
+
+	t.Run("the guests can't be invited as admins", func(t *testing.T) {
+		_, resp := th.Client.CreateGuestInvite("client-board", model.BoardRoleAdmin)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	invite, resp := th.Client.CreateGuestInvite("client-board", model.BoardRoleViewer)
+	require.NoError(t, resp.Error)
+	require.NotEmpty(t, invite.Token)
+
+	guestClient := client.NewClient(th.Server.Config().ServerRoot, "")
+	guestPassword := utils.CreateGUID()
+	registerRequest := &api.RegisterRequest{
+		Username: "guest",
+		Email:    "guest@example.com",
+		Password: guestPassword,
+		Token:    invite.Token,
+	}
+
+	t.Run("the guests sign up with the invite once", func(t *testing.T) {
+		_, resp := guestClient.RegisterGuest(&api.RegisterRequest{
+			Username: "guest",
+			Email:    "guest@example.com",
+			Password: guestPassword,
+			Token:    "invalid",
+		})
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		_, resp = guestClient.RegisterGuest(registerRequest)
+		require.NoError(t, resp.Error)
+
+		registerRequest.Username = "guest2"
+		registerRequest.Email = "guest2@example.com"
+		_, resp = guestClient.RegisterGuest(registerRequest)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	_, resp = guestClient.Login(&api.LoginRequest{Type: "normal", Username: "guest", Password: guestPassword})
+	require.NoError(t, resp.Error)
+	guest, resp := guestClient.GetMe()
+	require.NoError(t, resp.Error)
+	require.True(t, guest.IsGuest)
+
+	t.Run("the guests only see the boards they are a member of", func(t *testing.T) {
+		blocks, resp := guestClient.GetBlocks()
+		require.NoError(t, resp.Error)
+		require.Equal(t, []string{"client-board"}, blockIDs(blocks))
+
+		_, resp = guestClient.GetSubtree("internal-board")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		// the guest is a viewer of the board
+		_, resp = guestClient.InsertBlocks([]model.Block{
+			{ID: "guest-card", RootID: "client-board", ParentID: "client-board", Type: "card", CreateAt: now, UpdateAt: now},
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, resp = guestClient.InsertBlocks([]model.Block{
+			{ID: "guest-board", RootID: "guest-board", Type: "board", CreateAt: now, UpdateAt: now},
+		})
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("the guests don't have access to the workspace", func(t *testing.T) {
+		r, err := guestClient.DoAPIGet("/workspaces/0", "")
+		require.Error(t, err)
+		require.Equal(t, http.StatusForbidden, r.StatusCode)
+		_ = r.Body.Close()
+
+		_, resp := guestClient.GetTemplates()
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, resp = guestClient.AddBoardMember("internal-board", guest.ID, model.BoardRoleViewer)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("the guests are only listed with their boards", func(t *testing.T) {
+		users, resp := th.Client.GetWorkspaceUsers("")
+		require.NoError(t, resp.Error)
+		require.NotContains(t, userIDs(users), guest.ID)
+
+		users, resp = th.Client.GetWorkspaceUsers("internal-board")
+		require.NoError(t, resp.Error)
+		require.NotContains(t, userIDs(users), guest.ID)
+
+		users, resp = th.Client.GetWorkspaceUsers("client-board")
+		require.NoError(t, resp.Error)
+		require.Contains(t, userIDs(users), guest.ID)
+	})
+}
+
+func userIDs(users []*model.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
@@ -0,0 +1,71 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPing(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	t.Run("should report the health of the server", func(t *testing.T) {
+		health, resp := th.Client.Ping()
+		require.NoError(t, resp.Error)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, model.HealthStatusOK, health.Status)
+		require.Equal(t, model.CurrentVersion, health.Version)
+		require.Positive(t, health.Database.SchemaVersion)
+		require.Empty(t, health.Database.Error)
+		require.Nil(t, health.Database.Pool)
+	})
+
+	t.Run("should not require the CSRF header", func(t *testing.T) {
+		r, err := http.Get(th.Server.Config().ServerRoot + "/api/v1/ping")
+		require.NoError(t, err)
+		defer r.Body.Close()
+		require.Equal(t, http.StatusOK, r.StatusCode)
+
+		var health model.ServerHealth
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&health))
+		require.Equal(t, model.HealthStatusOK, health.Status)
+	})
+}
+
+func TestHealth(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	liveness, resp := th.Client.Health()
+	require.NoError(t, resp.Error)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, model.HealthStatusOK, liveness.Status)
+}
+
+func TestReadiness(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	t.Run("should report the dependencies of the server", func(t *testing.T) {
+		readiness, resp := th.Client.Readiness()
+		require.NoError(t, resp.Error)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, model.HealthStatusOK, readiness.Status)
+		require.False(t, readiness.Draining)
+		require.Len(t, readiness.Dependencies, 3)
+		for _, dependency := range readiness.Dependencies {
+			require.Equal(t, model.HealthStatusOK, dependency.Status, dependency.Name)
+		}
+	})
+
+	t.Run("should not require the CSRF header", func(t *testing.T) {
+		r, err := http.Get(th.Server.Config().ServerRoot + "/api/v1/readiness")
+		require.NoError(t, err)
+		defer r.Body.Close()
+		require.Equal(t, http.StatusOK, r.StatusCode)
+	})
+}
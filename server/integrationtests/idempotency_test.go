@@ -0,0 +1,128 @@
+package integrationtests
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	cfg := getTestConfig()
+	if cfg.DBConfigString == ":memory:" {
+		// every connection to an in-memory database gets its own, so the
+		// concurrent requests must share one
+		cfg.DBMaxOpenConns = 1
+	}
+	th := SetupTestHelperWithConfig(cfg).InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	_, resp := th.Client.InsertBlocks([]model.Block{{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board"}})
+	require.NoError(t, resp.Error)
+
+	countCards := func(t *testing.T, title string) int {
+		t.Helper()
+		blocks, resp := th.Client.GetSubtree(boardID)
+		require.NoError(t, resp.Error)
+		count := 0
+		for _, block := range blocks {
+			if block.Type == "card" && block.Title == title {
+				count++
+			}
+		}
+		return count
+	}
+
+	t.Run("Create the card once for the concurrent retries", func(t *testing.T) {
+		key := utils.CreateGUID()
+		cards := make([]*model.Block, 2)
+		responses := make([]*client.Response, 2)
+		var wg sync.WaitGroup
+		for i := range cards {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				cards[i], responses[i] = th.Client.QuickAddCardIdempotent(boardID, "Retried card", key)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, resp := range responses {
+			require.NoError(t, resp.Error)
+		}
+		require.Equal(t, cards[0].ID, cards[1].ID)
+		require.Equal(t, 1, countCards(t, "Retried card"))
+
+		// one of the responses is the stored one
+		replayed := responses[0].Header.Get("Idempotent-Replayed") + responses[1].Header.Get("Idempotent-Replayed")
+		require.Equal(t, "true", replayed)
+	})
+
+	t.Run("Return the stored response for a retry", func(t *testing.T) {
+		key := utils.CreateGUID()
+		card, resp := th.Client.QuickAddCardIdempotent(boardID, "Stored card", key)
+		require.NoError(t, resp.Error)
+		require.Empty(t, resp.Header.Get("Idempotent-Replayed"))
+
+		retried, resp := th.Client.QuickAddCardIdempotent(boardID, "Stored card", key)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+		require.Equal(t, card.ID, retried.ID)
+		require.Equal(t, 1, countCards(t, "Stored card"))
+	})
+
+	t.Run("Reject the key used for another request", func(t *testing.T) {
+		key := utils.CreateGUID()
+		_, resp := th.Client.QuickAddCardIdempotent(boardID, "First card", key)
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.QuickAddCardIdempotent(boardID, "Second card", key)
+		requireErrorCode(t, resp, http.StatusConflict, model.ErrorCodeConflict)
+		require.Equal(t, 0, countCards(t, "Second card"))
+
+		cardID := utils.CreateGUID()
+		_, resp = th.Client.InsertBlocksIdempotent([]model.Block{{ID: cardID, ParentID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card"}}, key)
+		requireErrorCode(t, resp, http.StatusConflict, model.ErrorCodeConflict)
+	})
+
+	t.Run("Create the cards again without a key", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, resp := th.Client.QuickAddCard(boardID, "Unkeyed card")
+			require.NoError(t, resp.Error)
+		}
+		require.Equal(t, 2, countCards(t, "Unkeyed card"))
+	})
+
+	t.Run("Insert the blocks once for the retries", func(t *testing.T) {
+		key := utils.CreateGUID()
+		cardID := utils.CreateGUID()
+		blocks := []model.Block{{ID: cardID, ParentID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card", Title: "Inserted card"}}
+		for i := 0; i < 2; i++ {
+			_, resp := th.Client.InsertBlocksIdempotent(blocks, key)
+			require.NoError(t, resp.Error)
+		}
+
+		blocks, resp := th.Client.GetSubtree(cardID)
+		require.NoError(t, resp.Error)
+		require.Len(t, blocks, 1)
+		require.Equal(t, int64(1), blocks[0].Version)
+	})
+
+	t.Run("Reject a body larger than the blocks", func(t *testing.T) {
+		cardID := utils.CreateGUID()
+		blocks := []model.Block{{
+			ID: cardID, ParentID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card",
+			Title: strings.Repeat("a", api.BlocksMaxBodySize),
+		}}
+		_, resp := th.Client.InsertBlocksIdempotent(blocks, utils.CreateGUID())
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+}
@@ -0,0 +1,275 @@
+package integrationtests
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportTrello(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	t.Run("Import a board", func(t *testing.T) {
+		blocks, resp := th.Client.GetBlocks()
+		require.NoError(t, resp.Error)
+		initialCount := len(blocks)
+
+		export := `{
+			"id": "trello-board",
+			"name": "Trello board",
+			"lists": [{"id": "list-1", "name": "To Do", "pos": 1}],
+			"cards": [
+				{"id": "card-1", "name": "Card", "desc": "Description", "idList": "list-1", "pos": 1},
+				{"id": "card-2", "name": "Archived", "closed": true, "idList": "list-1", "pos": 2}
+			],
+			"actions": [{"id": "action-1", "type": "commentCard", "data": {"text": "Comment", "card": {"id": "card-1"}}}]
+		}`
+
+		summary, resp := th.Client.ImportTrello(export)
+		require.NoError(t, resp.Error)
+		require.Equal(t, 1, summary.BoardsCreated)
+		require.Equal(t, 1, summary.CardsCreated)
+		require.Equal(t, 1, summary.CommentsCreated)
+		require.Len(t, summary.Skipped, 1)
+		require.Equal(t, "card-2", summary.Skipped[0].ID)
+
+		blocks, resp = th.Client.GetBlocks()
+		require.NoError(t, resp.Error)
+		require.Len(t, blocks, initialCount+1)
+
+		var boardID string
+		for _, block := range blocks {
+			if block.Title == "Trello board" {
+				boardID = block.ID
+			}
+		}
+		require.NotEmpty(t, boardID)
+
+		// board, view and card
+		subtree, resp := th.Client.GetSubtree(boardID)
+		require.NoError(t, resp.Error)
+		require.Len(t, subtree, 3)
+
+		metadata, resp := th.Client.GetBoardMetadata(boardID)
+		require.NoError(t, resp.Error)
+		require.Len(t, metadata, 1)
+		require.Equal(t, int64(1), metadata[0].CommentCount)
+	})
+
+	t.Run("Invalid export", func(t *testing.T) {
+		summary, resp := th.Client.ImportTrello("not json")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Nil(t, summary)
+
+		_, resp = th.Client.ImportTrello("{}")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestImportJira(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	export := "Summary,Issue key,Status,Assignee,Labels,Labels,Description,Project name\n" +
+		"First,PROJ-1,To Do,nobody,backend,api,h1. Details,Project\n" +
+		"Second,PROJ-2,Done,nobody,,,,Project\n"
+
+	preview, resp := th.Client.PreviewImportJira(strings.NewReader(export))
+	require.NoError(t, resp.Error)
+	require.Equal(t, "csv", preview.Format)
+	require.Equal(t, 2, preview.IssueCount)
+	require.Equal(t, "Project", preview.Mapping.BoardTitle)
+	require.Len(t, preview.Mapping.Fields, 4)
+
+	t.Run("Import the issues with the mapping", func(t *testing.T) {
+		mapping := preview.Mapping
+		mapping.BoardTitle = "Jira board"
+		summary, resp := th.Client.ImportJira(strings.NewReader(export), mapping)
+		require.NoError(t, resp.Error)
+		require.Equal(t, 1, summary.BoardsCreated)
+		require.Equal(t, 2, summary.CardsCreated)
+		// the assignee isn't a user of the workspace
+		require.Len(t, summary.Skipped, 2)
+
+		blocks, resp := th.Client.GetBlocks()
+		require.NoError(t, resp.Error)
+		var board model.Block
+		for _, block := range blocks {
+			if block.Title == "Jira board" {
+				board = block
+			}
+		}
+		require.NotEmpty(t, board.ID)
+		require.Len(t, board.Fields["cardProperties"], 4)
+
+		subtree, resp := th.Client.GetSubtree(board.ID)
+		require.NoError(t, resp.Error)
+		var first model.Block
+		for _, block := range subtree {
+			if block.Type == "card" && block.Title == "First" {
+				first = block
+			}
+		}
+		require.NotEmpty(t, first.ID)
+
+		content, resp := th.Client.GetSubtree(first.ID)
+		require.NoError(t, resp.Error)
+		texts := []string{}
+		for _, block := range content {
+			if block.Type == "text" {
+				texts = append(texts, block.Title)
+			}
+		}
+		require.Equal(t, []string{"# Details"}, texts)
+	})
+
+	t.Run("Invalid import", func(t *testing.T) {
+		_, resp := th.Client.PreviewImportJira(strings.NewReader("[1, "))
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		mapping := model.JiraImportMapping{Fields: []model.JiraFieldMapping{{FieldID: "Status", PropertyName: "Status", PropertyType: "checkbox"}}}
+		_, resp = th.Client.ImportJira(strings.NewReader(export), mapping)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestImportNotion(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"Projects 0123456789abcdef0123456789abcdef.csv":                                        "Name,Status\nLaunch,Doing\nPlan,Done\n",
+		"Projects 0123456789abcdef0123456789abcdef/Launch 0123456789abcdef0123456789abcdef.md": "# Launch\n\nStatus: Doing\n\nDetails\n\n![logo.png](Launch/logo.png)",
+		"Projects 0123456789abcdef0123456789abcdef/Launch/logo.png":                            "\x89PNG\r\n\x1a\nimage",
+	} {
+		fileWriter, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = fileWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	summary, resp := th.Client.ImportNotion(&buf)
+	require.NoError(t, resp.Error)
+	require.Equal(t, 1, summary.BoardsCreated)
+	require.Equal(t, 2, summary.CardsCreated)
+	require.Empty(t, summary.Skipped)
+
+	blocks, resp := th.Client.GetBlocks()
+	require.NoError(t, resp.Error)
+	var board model.Block
+	for _, block := range blocks {
+		if block.Title == "Projects" {
+			board = block
+		}
+	}
+	require.NotEmpty(t, board.ID)
+
+	subtree, resp := th.Client.GetSubtree(board.ID)
+	require.NoError(t, resp.Error)
+	var launch model.Block
+	for _, block := range subtree {
+		if block.Title == "Launch" {
+			launch = block
+		}
+	}
+	content, resp := th.Client.GetSubtree(launch.ID)
+	require.NoError(t, resp.Error)
+	var fileID string
+	for _, block := range content {
+		if block.Type == "image" {
+			fileID, _ = block.Fields["fileId"].(string)
+		}
+	}
+	require.NotEmpty(t, fileID)
+
+	t.Run("Invalid export", func(t *testing.T) {
+		_, resp := th.Client.ImportNotion(strings.NewReader("not a zip"))
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestImportAsana(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	export := `{"data": [
+		{
+			"gid": "task-1",
+			"name": "Task",
+			"notes": "Notes",
+			"memberships": [{"project": {"gid": "project-1", "name": "Asana project"}, "section": {"gid": "section-1", "name": "To Do"}}],
+			"subtasks": [{"gid": "subtask-1", "name": "Step"}],
+			"stories": [{"gid": "story-1", "type": "comment", "text": "Comment", "created_by": {"gid": "user-1", "name": "Some User"}}]
+		}
+	]}`
+
+	summary, resp := th.Client.ImportAsana(export)
+	require.NoError(t, resp.Error)
+	require.Equal(t, 1, summary.BoardsCreated)
+	require.Equal(t, 1, summary.CardsCreated)
+	require.Equal(t, 1, summary.CommentsCreated)
+
+	blocks, resp := th.Client.GetBlocks()
+	require.NoError(t, resp.Error)
+	var board model.Block
+	for _, block := range blocks {
+		if block.Title == "Asana project" {
+			board = block
+		}
+	}
+	require.NotEmpty(t, board.ID)
+
+	subtree, resp := th.Client.GetSubtree(board.ID)
+	require.NoError(t, resp.Error)
+	var card model.Block
+	for _, block := range subtree {
+		if block.Type == "card" {
+			card = block
+		}
+	}
+	require.Equal(t, "Task", card.Title)
+	content, resp := th.Client.GetSubtree(card.ID)
+	require.NoError(t, resp.Error)
+	// the card, the notes, the checkbox and the comment
+	require.Len(t, content, 4)
+	for _, block := range content {
+		if block.Type == "comment" {
+			require.Equal(t, model.ImportedUserID, block.CreatedBy)
+			require.Equal(t, "**Some User**: Comment", block.Title)
+		}
+	}
+
+	t.Run("Import the export again", func(t *testing.T) {
+		summary, resp := th.Client.ImportAsana(export)
+		require.NoError(t, resp.Error)
+		require.Equal(t, 0, summary.BoardsCreated)
+		require.Equal(t, 0, summary.CardsCreated)
+		require.Equal(t, 0, summary.CommentsCreated)
+
+		again, resp := th.Client.GetSubtree(board.ID)
+		require.NoError(t, resp.Error)
+		require.Len(t, again, len(subtree))
+		againContent, resp := th.Client.GetSubtree(card.ID)
+		require.NoError(t, resp.Error)
+		require.Len(t, againContent, len(content))
+	})
+
+	t.Run("Invalid export", func(t *testing.T) {
+		_, resp := th.Client.ImportAsana("not json")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, resp = th.Client.ImportAsana("{}")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
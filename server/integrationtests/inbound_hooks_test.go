@@ -0,0 +1,171 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInboundHooks(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	_, resp := th.Client.InsertBlocks([]model.Block{{ID: boardID, RootID: boardID, Type: "board", CreateAt: now, UpdateAt: now, Fields: map[string]interface{}{
+		"cardProperties": []interface{}{
+			map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+				map[string]interface{}{"id": "done", "value": "Done"},
+			}},
+			map[string]interface{}{"id": "labels", "name": "Labels", "type": "multiSelect", "options": []interface{}{
+				map[string]interface{}{"id": "bug", "value": "Bug"},
+			}},
+			map[string]interface{}{"id": "estimate", "name": "Estimate", "type": "number"},
+		},
+	}}})
+	require.NoError(t, resp.Error)
+
+	getBlock := func(rootID, blockID string) *model.Block {
+		blocks, resp := th.Client.GetSubtree(rootID)
+		require.NoError(t, resp.Error)
+		for i := range blocks {
+			if blocks[i].ID == blockID {
+				return &blocks[i]
+			}
+		}
+		return nil
+	}
+
+	_, resp = th.Client.GetInboundHook(boardID)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	hook, resp := th.Client.PostInboundHook(boardID, api.InboundHookRequest{})
+	require.NoError(t, resp.Error)
+	require.NotEmpty(t, hook.Token)
+
+	var cardID string
+	t.Run("should create the card from the payload", func(t *testing.T) {
+		card, resp := th.Client.PostInboundHookDelivery(hook.Token,
+			`{"title":"From the form","description":"The details","properties":{"status":"done","Labels":["bug"],"Estimate":3}}`)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "From the form", card.Title)
+		cardID = card.ID
+
+		stored := getBlock(boardID, card.ID)
+		require.NotNil(t, stored)
+		properties := stored.Fields["properties"].(map[string]interface{})
+		require.Equal(t, "done", properties["status"])
+		require.Equal(t, []interface{}{"bug"}, properties["labels"])
+		require.Equal(t, "3", properties["estimate"])
+
+		contentOrder := stored.Fields["contentOrder"].([]interface{})
+		require.Len(t, contentOrder, 1)
+		text := getBlock(card.ID, contentOrder[0].(string))
+		require.Equal(t, "text", text.Type)
+		require.Equal(t, "The details", text.Title)
+	})
+
+	t.Run("should update the card of the payload", func(t *testing.T) {
+		card, resp := th.Client.PostInboundHookDelivery(hook.Token,
+			`{"cardId":"`+cardID+`","description":"New details","properties":{"Estimate":5}}`)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "From the form", card.Title)
+
+		stored := getBlock(boardID, cardID)
+		properties := stored.Fields["properties"].(map[string]interface{})
+		require.Equal(t, "done", properties["status"])
+		require.Equal(t, "5", properties["estimate"])
+		contentOrder := stored.Fields["contentOrder"].([]interface{})
+		require.Len(t, contentOrder, 1)
+		require.Equal(t, "New details", getBlock(cardID, contentOrder[0].(string)).Title)
+	})
+
+	t.Run("should reject the payloads that don't match the board", func(t *testing.T) {
+		for _, payload := range []string{
+			`{"title":"Card","properties":{"status":"Unknown"}}`,
+			`{"title":"Card","properties":{"Priority":"High"}}`,
+			`{"properties":{"status":"done"}}`,
+			`{"title":"Card","unknown":true}`,
+			`{`,
+		} {
+			_, resp := th.Client.PostInboundHookDelivery(hook.Token, payload)
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode, payload)
+		}
+
+		_, resp := th.Client.PostInboundHookDelivery(hook.Token, `{"cardId":"missing","title":"Card"}`)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should record the deliveries", func(t *testing.T) {
+		deliveries, resp := th.Client.GetInboundHookDeliveries(boardID)
+		require.NoError(t, resp.Error)
+		require.Len(t, deliveries, 8)
+
+		// the deliveries of the same millisecond aren't ordered
+		statusCodes := map[int]int{}
+		for _, delivery := range deliveries {
+			statusCodes[delivery.StatusCode]++
+			switch delivery.StatusCode {
+			case http.StatusOK:
+				require.Equal(t, cardID, delivery.CardID)
+				require.Empty(t, delivery.Error)
+			case http.StatusBadRequest:
+				require.Empty(t, delivery.CardID)
+				require.NotEmpty(t, delivery.Error)
+			}
+		}
+		require.Equal(t, map[int]int{http.StatusOK: 2, http.StatusBadRequest: 5, http.StatusNotFound: 1}, statusCodes)
+	})
+
+	t.Run("should add the options if the hook allows it", func(t *testing.T) {
+		previous := hook.Token
+		hook, resp = th.Client.PostInboundHook(boardID, api.InboundHookRequest{AllowNewOptions: true})
+		require.NoError(t, resp.Error)
+		require.NotEqual(t, previous, hook.Token)
+
+		_, resp = th.Client.PostInboundHookDelivery(previous, `{"title":"Card"}`)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		card, resp := th.Client.PostInboundHookDelivery(hook.Token, `{"title":"Card","properties":{"Labels":["Bug","Feature","feature"]}}`)
+		require.NoError(t, resp.Error)
+
+		board := getBlock(boardID, boardID)
+		options := board.Fields["cardProperties"].([]interface{})[1].(map[string]interface{})["options"].([]interface{})
+		require.Len(t, options, 2)
+		option := options[1].(map[string]interface{})
+		require.Equal(t, "Feature", option["value"])
+		properties := getBlock(boardID, card.ID).Fields["properties"].(map[string]interface{})
+		require.Equal(t, []interface{}{"bug", option["id"], option["id"]}, properties["labels"])
+	})
+
+	t.Run("should not return the token", func(t *testing.T) {
+		stored, resp := th.Client.GetInboundHook(boardID)
+		require.NoError(t, resp.Error)
+		require.Empty(t, stored.Token)
+		require.True(t, stored.AllowNewOptions)
+		require.NotEmpty(t, stored.CreatedBy)
+	})
+
+	t.Run("should revoke the hook", func(t *testing.T) {
+		_, resp := th.Client.DeleteInboundHook(boardID)
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.PostInboundHookDelivery(hook.Token, `{"title":"Card"}`)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		_, resp = th.Client.DeleteInboundHook(boardID)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should rate limit the deliveries per token, even if invalid", func(t *testing.T) {
+		limited := false
+		for i := 0; i < 30 && !limited; i++ {
+			_, resp := th.Client.PostInboundHookDelivery(hook.Token, `{`)
+			limited = resp.StatusCode == http.StatusTooManyRequests
+		}
+		require.True(t, limited)
+	})
+}
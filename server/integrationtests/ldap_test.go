@@ -0,0 +1,114 @@
+package integrationtests
+
+import (
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/ldap/ldaptest"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLDAPLogin(t *testing.T) {
+	server := ldaptest.NewServer()
+	defer server.Close()
+	server.AddEntry("cn=focalboard,dc=example,dc=com", "bind secret", nil)
+	server.AddEntry("uid=alice,ou=people,dc=example,dc=com", "alice password", map[string][]string{
+		"objectClass": {"inetOrgPerson"},
+		"uid":         {"alice"},
+		"mail":        {"alice@example.com"},
+		"displayName": {"Alice Smith"},
+	})
+
+	cfg := getTestConfig()
+	cfg.AuthBackend = config.AuthBackendLDAP
+	cfg.LDAP = config.LDAPConfig{
+		Server:            server.Host(),
+		Port:              server.Port(),
+		BindDN:            "cn=focalboard,dc=example,dc=com",
+		BindPassword:      "bind secret",
+		BaseDN:            "dc=example,dc=com",
+		UserFilter:        "(objectClass=inetOrgPerson)",
+		NicknameAttribute: "displayName",
+	}
+	th := SetupTestHelperWithConfigWithoutToken(cfg).InitBasic()
+	defer th.TearDown()
+
+	t.Run("should reject the registrations", func(t *testing.T) {
+		_, resp := th.Client.Register(&api.RegisterRequest{
+			Username: fakeUsername,
+			Email:    fakeEmail,
+			Password: utils.CreateGUID(),
+		})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("should reject the bad credentials", func(t *testing.T) {
+		_, resp := th.Client.Login(&api.LoginRequest{Type: "normal", Username: "alice", Password: "wrong password"})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "bob", Password: "alice password"})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("should provision the user with the mapped attributes", func(t *testing.T) {
+		data, resp := th.Client.Login(&api.LoginRequest{Type: "normal", Email: "alice@example.com", Password: "alice password"})
+		require.NoError(t, resp.Error)
+
+		userClient := client.NewClient(th.Server.Config().ServerRoot, data.Token)
+		me, resp := userClient.GetMe()
+		require.NoError(t, resp.Error)
+		require.Equal(t, "alice", me.Username)
+		require.Equal(t, "alice@example.com", me.Email)
+		require.Equal(t, "Alice Smith", me.Props[model.UserPropNickname])
+		require.True(t, me.IsAdmin, "the first user administers the server")
+
+		t.Run("and log it in again", func(t *testing.T) {
+			data, resp := th.Client.Login(&api.LoginRequest{Type: "normal", Username: "alice", Password: "alice password"})
+			require.NoError(t, resp.Error)
+
+			again, resp := client.NewClient(th.Server.Config().ServerRoot, data.Token).GetMe()
+			require.NoError(t, resp.Error)
+			require.Equal(t, me.ID, again.ID)
+		})
+
+		t.Run("and refuse to change its password", func(t *testing.T) {
+			_, resp := userClient.UserChangePassword(me.ID, &api.ChangePasswordRequest{
+				OldPassword: "alice password",
+				NewPassword: "new password",
+			})
+			require.Error(t, resp.Error)
+			require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		})
+	})
+}
+
+func TestLDAPUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	cfg := getTestConfig()
+	cfg.AuthBackend = config.AuthBackendLDAP
+	cfg.LDAP = config.LDAPConfig{
+		Server:         "127.0.0.1",
+		Port:           port,
+		UserDNTemplate: "uid={username},ou=people,dc=example,dc=com",
+		Timeout:        1,
+	}
+	th := SetupTestHelperWithConfigWithoutToken(cfg).InitBasic()
+	defer th.TearDown()
+
+	_, resp := th.Client.Login(&api.LoginRequest{Type: "normal", Username: "alice", Password: "alice password"})
+	require.Error(t, resp.Error)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
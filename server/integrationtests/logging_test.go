@@ -0,0 +1,100 @@
+package integrationtests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+func TestRequestLogging(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	logs := &mlog.Buffer{}
+	require.NoError(t, mlog.AddWriterTarget(th.Server.Logger(), logs, true, mlog.LvlInfo))
+	readLogs := func() string {
+		require.NoError(t, th.Server.Logger().Flush())
+		return logs.String()
+	}
+
+	password := utils.CreateGUID()
+	success, resp := th.Client.Register(&api.RegisterRequest{
+		Username: fakeUsername,
+		Email:    fakeEmail,
+		Password: password,
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	login, resp := th.Client.Login(&api.LoginRequest{
+		Type:     "normal",
+		Username: fakeUsername,
+		Password: password,
+	})
+	require.NoError(t, resp.Error)
+	me, resp := th.Client.GetMe()
+	require.NoError(t, resp.Error)
+
+	get := func(url, requestID string) *http.Response {
+		rq, err := http.NewRequest(http.MethodGet, th.Client.APIURL+url, nil)
+		require.NoError(t, err)
+		for k, v := range th.Client.HTTPHeader {
+			rq.Header.Set(k, v)
+		}
+		rq.Header.Set("Authorization", "Bearer "+th.Client.Token)
+		if requestID != "" {
+			rq.Header.Set(api.RequestIDHeader, requestID)
+		}
+		rp, err := http.DefaultClient.Do(rq)
+		require.NoError(t, err)
+		rp.Body.Close()
+		return rp
+	}
+
+	t.Run("should return the request IDs", func(t *testing.T) {
+		rp := get(th.Client.GetMeRoute(), "")
+		require.Len(t, rp.Header.Get(api.RequestIDHeader), len(utils.CreateGUID()))
+
+		rp = get(th.Client.GetMeRoute(), "lb-1234.abcd")
+		require.Equal(t, "lb-1234.abcd", rp.Header.Get(api.RequestIDHeader))
+
+		rp = get(th.Client.GetMeRoute(), "forged\" id")
+		require.NotContains(t, rp.Header.Get(api.RequestIDHeader), "forged")
+	})
+
+	t.Run("should log the completed requests", func(t *testing.T) {
+		get(th.Client.GetBlocksRoute()+"?parent_id=board-1", "request-log-test")
+
+		var line string
+		for _, l := range strings.Split(readLogs(), "\n") {
+			if strings.Contains(l, `"requestID":"request-log-test"`) {
+				line = l
+			}
+		}
+		require.NotEmpty(t, line)
+		require.Contains(t, line, `"method":"GET"`)
+		require.Contains(t, line, `"route":"/api/v1/workspaces/{workspaceID}/blocks"`)
+		require.Contains(t, line, `"query":"parent_id=board-1"`)
+		require.Contains(t, line, `"status":200`)
+		require.Contains(t, line, `"duration"`)
+		require.Contains(t, line, `"userID":"`+me.ID+`"`)
+	})
+
+	t.Run("should redact the tokens and passwords", func(t *testing.T) {
+		readToken := utils.CreateGUID()
+		get(th.Client.GetBlocksRoute()+"?read_token="+readToken+"&password="+password, "redaction-test")
+
+		output := readLogs()
+		require.Contains(t, output, `read_token=REDACTED`)
+		require.Contains(t, output, `password=REDACTED`)
+		require.NotContains(t, output, readToken)
+		require.NotContains(t, output, password)
+		require.NotContains(t, output, login.Token)
+	})
+}
@@ -0,0 +1,144 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginLockout(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.LoginLockoutThreshold = 3
+	cfg.LoginLockoutIPThreshold = 6
+	cfg.LoginLockoutWindow = 300
+	cfg.LoginLockoutDuration = 60
+
+	th := SetupTestHelperWithConfigWithoutToken(cfg).InitBasic()
+	defer th.TearDown()
+
+	passwords := map[string]string{
+		"locked": utils.CreateGUID(),
+		"other":  utils.CreateGUID(),
+	}
+	success, resp := th.Client.Register(&api.RegisterRequest{
+		Username: "locked",
+		Email:    "locked@example.com",
+		Password: passwords["locked"],
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	// the other users need the sign-up token of the workspace
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "locked", Password: passwords["locked"]})
+	require.NoError(t, resp.Error)
+	r, err := th.Client.DoAPIGet("/workspaces/0", "")
+	require.NoError(t, err)
+	var workspace model.Workspace
+	require.NoError(t, json.NewDecoder(r.Body).Decode(&workspace))
+	_ = r.Body.Close()
+
+	success, resp = th.Client.Register(&api.RegisterRequest{
+		Username: "other",
+		Email:    "other@example.com",
+		Password: passwords["other"],
+		Token:    workspace.SignupToken,
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	login := func(username, password string) (*api.LoginResponse, int) {
+		data, resp := th.Client.Login(&api.LoginRequest{
+			Type:     "normal",
+			Username: username,
+			Password: password,
+		})
+		return data, resp.StatusCode
+	}
+
+	t.Run("a successful login clears the failures", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, status := login("locked", "wrong password")
+			require.Equal(t, http.StatusUnauthorized, status)
+		}
+		_, status := login("locked", passwords["locked"])
+		require.Equal(t, http.StatusOK, status)
+	})
+
+	t.Run("the username is locked out after the threshold", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, status := login("locked", "wrong password")
+			require.Equal(t, http.StatusUnauthorized, status, "attempt %d", i)
+		}
+
+		_, resp := th.Client.Login(&api.LoginRequest{
+			Type:     "normal",
+			Username: "locked",
+			Password: passwords["locked"],
+		})
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.NotEmpty(t, resp.Header.Get("Retry-After"))
+		require.Contains(t, resp.Error.Error(), `"errorCode":1003`)
+	})
+
+	t.Run("a locked username doesn't lock the other users of the address", func(t *testing.T) {
+		data, status := login("other", passwords["other"])
+		require.Equal(t, http.StatusOK, status)
+		require.NotEmpty(t, data.Token)
+	})
+
+	t.Run("the address is locked out after its threshold", func(t *testing.T) {
+		// the address has 5 failures, the next one locks it out
+		_, status := login("other", "wrong password")
+		require.Equal(t, http.StatusUnauthorized, status)
+
+		_, status = login("other", passwords["other"])
+		require.Equal(t, http.StatusTooManyRequests, status)
+	})
+}
+
+func TestLoginLockoutAcrossIdentifiers(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.LoginLockoutThreshold = 3
+	cfg.LoginLockoutIPThreshold = 10
+	cfg.LoginLockoutWindow = 300
+	cfg.LoginLockoutDuration = 60
+
+	th := SetupTestHelperWithConfigWithoutToken(cfg).InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	success, resp := th.Client.Register(&api.RegisterRequest{
+		Username: "locked",
+		Email:    "locked@example.com",
+		Password: password,
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	// the failures with the username and the email of the account are
+	// counted together
+	attempts := []api.LoginRequest{
+		{Type: "normal", Username: "locked", Password: "wrong password"},
+		{Type: "normal", Email: "locked@example.com", Password: "wrong password"},
+		{Type: "normal", Username: "locked", Password: "wrong password"},
+	}
+	for i, attempt := range attempts {
+		attempt := attempt
+		_, resp := th.Client.Login(&attempt)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode, "attempt %d", i)
+	}
+
+	for _, attempt := range []api.LoginRequest{
+		{Type: "normal", Username: "locked", Password: password},
+		{Type: "normal", Email: "locked@example.com", Password: password},
+	} {
+		attempt := attempt
+		_, resp := th.Client.Login(&attempt)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	}
+}
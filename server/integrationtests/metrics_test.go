@@ -0,0 +1,54 @@
+package integrationtests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.EnableMetrics = true
+	cfg.MetricsAuthToken = "metrics-token"
+
+	th := SetupTestHelperWithConfig(cfg).InitBasic()
+	defer th.TearDown()
+
+	_, resp := th.Client.GetBlocks()
+	require.NoError(t, resp.Error)
+
+	getMetrics := func(t *testing.T, token string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, th.Server.Config().ServerRoot+"/metrics", nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		r, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer r.Body.Close()
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		return r.StatusCode, string(body)
+	}
+
+	t.Run("without the token", func(t *testing.T) {
+		statusCode, _ := getMetrics(t, "")
+		require.Equal(t, http.StatusUnauthorized, statusCode)
+
+		statusCode, _ = getMetrics(t, "wrong-token")
+		require.Equal(t, http.StatusUnauthorized, statusCode)
+	})
+
+	t.Run("with the token", func(t *testing.T) {
+		statusCode, body := getMetrics(t, cfg.MetricsAuthToken)
+		require.Equal(t, http.StatusOK, statusCode)
+		require.Contains(t, body, `focalboard_api_requests_total{method="GET",route="/api/v1/workspaces/{workspaceID}/blocks",status="200"} 1`)
+		require.Contains(t, body, `focalboard_api_request_duration_seconds_count{method="GET",route="/api/v1/workspaces/{workspaceID}/blocks"} 1`)
+		require.Contains(t, body, `focalboard_db_query_duration_seconds_count{operation="select"}`)
+		require.Contains(t, body, "focalboard_websocket_connections 0")
+	})
+}
@@ -0,0 +1,119 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMfa(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.MfaEncryptionKey = "test-encryption-key"
+
+	th := SetupTestHelperWithConfigWithoutToken(cfg).InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	success, resp := th.Client.Register(&api.RegisterRequest{
+		Username: fakeUsername,
+		Email:    fakeEmail,
+		Password: password,
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	login := func(mfaToken string) *api.LoginResponse {
+		data, _ := th.Client.Login(&api.LoginRequest{
+			Type:     "normal",
+			Username: fakeUsername,
+			Password: password,
+			MfaToken: mfaToken,
+		})
+		return data
+	}
+	require.NotNil(t, login(""))
+
+	activation, resp := th.Client.ActivateMfa()
+	require.NoError(t, resp.Error)
+	require.NotEmpty(t, activation.Secret)
+	require.Contains(t, activation.URL, "otpauth://totp/")
+
+	t.Run("mfa isn't required before the confirmation", func(t *testing.T) {
+		require.NotNil(t, login(""))
+	})
+
+	t.Run("confirm with a wrong code", func(t *testing.T) {
+		_, resp := th.Client.ConfirmMfa("000000")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	code, err := auth.TOTPCode(activation.Secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, resp := th.Client.ConfirmMfa(code)
+	require.NoError(t, resp.Error)
+	require.Len(t, recoveryCodes, 10)
+
+	t.Run("login requires the mfa token", func(t *testing.T) {
+		data, resp := th.Client.Login(&api.LoginRequest{Type: "normal", Username: fakeUsername, Password: password})
+		require.Nil(t, data)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.Contains(t, resp.Error.Error(), `"errorCode":1004`)
+
+		data, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: fakeUsername, Password: password, MfaToken: "000000"})
+		require.Nil(t, data)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		require.NotContains(t, resp.Error.Error(), `"errorCode":1004`)
+	})
+
+	t.Run("login with a totp code", func(t *testing.T) {
+		code, err := auth.TOTPCode(activation.Secret, time.Now())
+		require.NoError(t, err)
+		require.NotNil(t, login(code))
+	})
+
+	t.Run("login with a recovery code", func(t *testing.T) {
+		require.NotNil(t, login(recoveryCodes[0]))
+
+		// the recovery codes can only be used once
+		require.Nil(t, login(recoveryCodes[0]))
+	})
+
+	t.Run("activate again", func(t *testing.T) {
+		_, resp := th.Client.ActivateMfa()
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		_, resp := th.Client.DeactivateMfa("000000")
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		success, resp := th.Client.DeactivateMfa(recoveryCodes[1])
+		require.NoError(t, resp.Error)
+		require.True(t, success)
+
+		require.NotNil(t, login(""))
+	})
+}
+
+func TestMfaNotConfigured(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	_, resp := th.Client.Register(&api.RegisterRequest{Username: fakeUsername, Email: fakeEmail, Password: password})
+	require.NoError(t, resp.Error)
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: fakeUsername, Password: password})
+	require.NoError(t, resp.Error)
+
+	_, resp = th.Client.ActivateMfa()
+	require.Error(t, resp.Error)
+	require.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
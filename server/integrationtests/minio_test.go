@@ -0,0 +1,48 @@
+//go:build minio
+// +build minio
+
+package integrationtests
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// The MinIO tests run against a MinIO container with a bucket, e.g.
+//
+//	docker run -d -p 9000:9000 -e MINIO_ROOT_USER=minioaccesskey \
+//	  -e MINIO_ROOT_PASSWORD=miniosecretkey minio/minio server /data
+//
+// and are enabled with the minio build tag:
+//
+//	go test -tags minio ./integrationtests/ -run MinIO
+func getMinIOTestConfig() *config.Configuration {
+	cfg := getTestConfig()
+	cfg.FilesDriver = "amazons3"
+	cfg.FilesS3Config = config.AmazonS3Config{
+		AccessKeyID:     getEnv("FB_MINIO_ACCESS_KEY", "minioaccesskey"),
+		SecretAccessKey: getEnv("FB_MINIO_SECRET_KEY", "miniosecretkey"),
+		Bucket:          getEnv("FB_MINIO_BUCKET", "focalboard-test"),
+		PathPrefix:      utils.CreateGUID(),
+		Region:          "us-east-1",
+		Endpoint:        getEnv("FB_MINIO_ENDPOINT", "localhost:9000"),
+	}
+	return cfg
+}
+
+func getEnv(name, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestMinIOUploadFile(t *testing.T) {
+	th := SetupTestHelperWithConfig(getMinIOTestConfig()).InitBasic()
+	defer th.TearDown()
+
+	testUploadAndDownloadFile(t, th)
+}
@@ -0,0 +1,18 @@
+package integrationtests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNotifications(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	t.Run("No notifications", func(t *testing.T) {
+		notifications, resp := th.Client.GetNotifications()
+		require.NoError(t, resp.Error)
+		require.Empty(t, notifications)
+	})
+}
@@ -0,0 +1,208 @@
+package integrationtests
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/services/auth"
+	"github.com/mattermost/focalboard/server/services/oidc/oidctest"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// oidcLogin logs in at the provider with a browser, and returns the URL
+// the server redirected it to after the login, and the session token of
+// its cookie.
+func oidcLogin(t *testing.T, th *TestHelper) (*url.URL, string) {
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	browser := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(r *http.Request, _ []*http.Request) error {
+			if !strings.HasPrefix(r.URL.Path, "/api/") && r.URL.Path != "/authorize" {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	response, err := browser.Get(th.Server.Config().ServerRoot + "/api/v1/oauth/login")
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusFound, response.StatusCode)
+
+	location, err := url.Parse(response.Header.Get("Location"))
+	require.NoError(t, err)
+	token := ""
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == auth.SessionCookieToken {
+			token = cookie.Value
+		}
+	}
+	return location, token
+}
+
+func TestOIDCLogin(t *testing.T) {
+	provider := oidctest.NewProvider("focalboard", "client secret")
+	defer provider.Close()
+
+	cfg := getTestConfig()
+	cfg.OIDCIssuerURL = provider.URL()
+	cfg.OIDCClientID = provider.ClientID
+	cfg.OIDCClientSecret = provider.ClientSecret
+	th := SetupTestHelperWithConfigWithoutToken(cfg).InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	success, resp := th.Client.Register(&api.RegisterRequest{
+		Username: fakeUsername,
+		Email:    fakeEmail,
+		Password: password,
+	})
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	t.Run("should provision a new user", func(t *testing.T) {
+		provider.SetUser(map[string]interface{}{
+			"sub":                "subject-1",
+			"email":              "alice@example.com",
+			"email_verified":     true,
+			"preferred_username": "Alice Smith",
+		})
+		location, token := oidcLogin(t, th)
+		require.Equal(t, "/", location.Path)
+		require.NotEmpty(t, token)
+
+		me, resp := client.NewClient(th.Server.Config().ServerRoot, token).GetMe()
+		require.NoError(t, resp.Error)
+		require.Equal(t, "alice-smith", me.Username)
+		require.Equal(t, "alice@example.com", me.Email)
+		require.False(t, me.IsAdmin)
+
+		t.Run("and log it in again", func(t *testing.T) {
+			_, token := oidcLogin(t, th)
+			again, resp := client.NewClient(th.Server.Config().ServerRoot, token).GetMe()
+			require.NoError(t, resp.Error)
+			require.Equal(t, me.ID, again.ID)
+		})
+	})
+
+	t.Run("should not link a user with an unverified email", func(t *testing.T) {
+		provider.SetUser(map[string]interface{}{
+			"sub":            "subject-2",
+			"email":          fakeEmail,
+			"email_verified": false,
+		})
+		location, token := oidcLogin(t, th)
+		require.Equal(t, "/login", location.Path)
+		require.Equal(t, "email_not_verified", location.Query().Get("sso_error"))
+		require.Empty(t, token)
+	})
+
+	t.Run("should link a user with a verified email", func(t *testing.T) {
+		provider.SetUser(map[string]interface{}{
+			"sub":            "subject-3",
+			"email":          fakeEmail,
+			"email_verified": "true",
+		})
+		_, token := oidcLogin(t, th)
+		me, resp := client.NewClient(th.Server.Config().ServerRoot, token).GetMe()
+		require.NoError(t, resp.Error)
+		require.Equal(t, fakeUsername, me.Username)
+
+		t.Run("and not link it to another identity", func(t *testing.T) {
+			provider.SetUser(map[string]interface{}{
+				"sub":            "subject-4",
+				"email":          fakeEmail,
+				"email_verified": true,
+			})
+			location, token := oidcLogin(t, th)
+			require.Equal(t, "email_linked", location.Query().Get("sso_error"))
+			require.Empty(t, token)
+		})
+
+		t.Run("and keep its password login", func(t *testing.T) {
+			data, resp := th.Client.Login(&api.LoginRequest{Type: "normal", Username: fakeUsername, Password: password})
+			require.NoError(t, resp.Error)
+			require.NotEmpty(t, data.Token)
+		})
+	})
+
+	t.Run("should reject a callback without the state of the login", func(t *testing.T) {
+		response, err := (&http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}).Get(th.Server.Config().ServerRoot + "/api/v1/oauth/callback?code=code&state=state")
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, http.StatusFound, response.StatusCode)
+		require.Contains(t, response.Header.Get("Location"), "sso_error=invalid_state")
+	})
+
+	t.Run("should tell the clients the logins", func(t *testing.T) {
+		clientConfig, resp := th.Client.GetClientConfig()
+		require.NoError(t, resp.Error)
+		require.True(t, clientConfig.OIDCLogin)
+		require.True(t, clientConfig.PasswordLogin)
+	})
+}
+
+func TestOIDCEnforced(t *testing.T) {
+	provider := oidctest.NewProvider("focalboard", "client secret")
+	defer provider.Close()
+
+	cfg := getTestConfig()
+	cfg.OIDCIssuerURL = provider.URL()
+	cfg.OIDCClientID = provider.ClientID
+	cfg.OIDCClientSecret = provider.ClientSecret
+	cfg.DisablePasswordLogin = true
+	th := SetupTestHelperWithConfigWithoutToken(cfg).InitBasic()
+	defer th.TearDown()
+
+	t.Run("should reject the password registrations and logins", func(t *testing.T) {
+		_, resp := th.Client.Register(&api.RegisterRequest{
+			Username: fakeUsername,
+			Email:    fakeEmail,
+			Password: utils.CreateGUID(),
+		})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: fakeUsername, Password: "password"})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("should log in the first user as the admin", func(t *testing.T) {
+		provider.SetUser(map[string]interface{}{"sub": "subject-1", "email": "alice@example.com"})
+		_, token := oidcLogin(t, th)
+		require.NotEmpty(t, token)
+
+		me, resp := client.NewClient(th.Server.Config().ServerRoot, token).GetMe()
+		require.NoError(t, resp.Error)
+		require.Equal(t, "alice", me.Username)
+		require.Empty(t, me.Email)
+		require.True(t, me.IsAdmin)
+	})
+
+	t.Run("should tell the clients the logins", func(t *testing.T) {
+		clientConfig, resp := th.Client.GetClientConfig()
+		require.NoError(t, resp.Error)
+		require.True(t, clientConfig.OIDCLogin)
+		require.False(t, clientConfig.PasswordLogin)
+	})
+}
+
+func TestOIDCNotConfigured(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	response, err := http.Get(th.Server.Config().ServerRoot + "/api/v1/oauth/login")
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusNotFound, response.StatusCode)
+}
@@ -0,0 +1,41 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordReset(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	t.Run("no email server", func(t *testing.T) {
+		success, resp := th.Client.RequestPasswordReset(&api.PasswordResetRequest{Email: fakeEmail})
+		require.Error(t, resp.Error)
+		require.False(t, success)
+		require.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		success, resp := th.Client.CompletePasswordReset(&api.CompletePasswordResetRequest{
+			Token:       "invalid",
+			NewPassword: "newPassword",
+		})
+		require.Error(t, resp.Error)
+		require.False(t, success)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("short password", func(t *testing.T) {
+		success, resp := th.Client.CompletePasswordReset(&api.CompletePasswordResetRequest{
+			Token:       "invalid",
+			NewPassword: "short",
+		})
+		require.Error(t, resp.Error)
+		require.False(t, success)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,131 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveBlock(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	_, resp := th.Client.Register(&api.RegisterRequest{Username: "owner", Email: "owner@example.com", Password: password})
+	require.NoError(t, resp.Error)
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "owner", Password: password})
+	require.NoError(t, resp.Error)
+
+	boardID := utils.CreateGUID()
+	otherBoardID := utils.CreateGUID()
+	tableViewID := utils.CreateGUID()
+	boardViewID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	commentID := utils.CreateGUID()
+	newBlock := func(id, parentID, rootID, blockType, title string) model.Block {
+		return model.Block{ID: id, ParentID: parentID, RootID: rootID, Type: blockType, Title: title, CreateAt: 1, UpdateAt: 1}
+	}
+	_, resp = th.Client.InsertBlocks([]model.Block{
+		newBlock(boardID, "", boardID, "board", "Board"),
+		newBlock(otherBoardID, "", otherBoardID, "board", "Other board"),
+		newBlock(tableViewID, boardID, boardID, "view", "Table view"),
+		newBlock(boardViewID, boardID, boardID, "view", "Board view"),
+		newBlock(cardID, boardID, boardID, "card", "Card"),
+		newBlock(commentID, cardID, boardID, "comment", "Comment"),
+	})
+	require.NoError(t, resp.Error)
+
+	t.Run("a card resolves to its board and the default view", func(t *testing.T) {
+		resolved, resp := th.Client.ResolveBlock(cardID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, &model.ResolvedBlock{
+			BlockID:   cardID,
+			CanAccess: true,
+			Type:      "card",
+			BoardID:   boardID,
+			ViewID:    boardViewID,
+			CardID:    cardID,
+		}, resolved)
+	})
+
+	t.Run("a view resolves to itself", func(t *testing.T) {
+		resolved, resp := th.Client.ResolveBlock(tableViewID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, boardID, resolved.BoardID)
+		require.Equal(t, tableViewID, resolved.ViewID)
+		require.Empty(t, resolved.CardID)
+	})
+
+	t.Run("a moved card and its comments resolve to the new board", func(t *testing.T) {
+		_, resp := th.Client.PatchBlock(cardID, &model.BlockPatch{ParentID: &otherBoardID, RootID: &otherBoardID})
+		require.NoError(t, resp.Error)
+
+		resolved, resp := th.Client.ResolveBlock(cardID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, otherBoardID, resolved.BoardID)
+		require.Empty(t, resolved.ViewID)
+
+		resolved, resp = th.Client.ResolveBlock(commentID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, &model.ResolvedBlock{
+			BlockID:   commentID,
+			CanAccess: true,
+			Type:      "comment",
+			BoardID:   otherBoardID,
+			CardID:    cardID,
+		}, resolved)
+	})
+
+	t.Run("the share-token viewers only resolve the blocks of their board", func(t *testing.T) {
+		token := utils.CreateGUID()
+		_, resp := th.Client.PostSharing(model.Sharing{ID: otherBoardID, Token: token, Enabled: true, UpdateAt: 1})
+		require.NoError(t, resp.Error)
+
+		viewer := client.NewClient(th.Server.Config().ServerRoot, "")
+		resolve := func(t *testing.T, blockID, readToken string) *model.ResolvedBlock {
+			r, err := viewer.DoAPIGet(viewer.GetResolveBlockRoute(blockID)+"?read_token="+readToken, "")
+			require.NoError(t, err)
+			defer r.Body.Close()
+			var resolved model.ResolvedBlock
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&resolved))
+			return &resolved
+		}
+
+		resolved := resolve(t, cardID, token)
+		require.True(t, resolved.CanAccess)
+		require.Equal(t, otherBoardID, resolved.BoardID)
+
+		resolved = resolve(t, tableViewID, token)
+		require.Equal(t, &model.ResolvedBlock{BlockID: tableViewID}, resolved)
+
+		_, err := viewer.DoAPIGet(viewer.GetResolveBlockRoute(cardID), "")
+		require.Error(t, err)
+	})
+
+	t.Run("a deleted card resolves to a tombstone", func(t *testing.T) {
+		_, resp := th.Client.DeleteBlock(cardID)
+		require.NoError(t, resp.Error)
+
+		for _, blockID := range []string{cardID, commentID} {
+			resolved, resp := th.Client.ResolveBlock(blockID)
+			require.NoError(t, resp.Error)
+			require.True(t, resolved.Deleted)
+			require.True(t, resolved.CanAccess)
+			require.NotZero(t, resolved.DeleteAt)
+			require.Equal(t, otherBoardID, resolved.BoardID)
+			require.Empty(t, resolved.ViewID)
+		}
+	})
+
+	t.Run("an unknown block isn't found", func(t *testing.T) {
+		_, resp := th.Client.ResolveBlock(utils.CreateGUID())
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
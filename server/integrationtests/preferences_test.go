@@ -0,0 +1,95 @@
+package integrationtests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferences(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	_, resp := th.Client.Register(&api.RegisterRequest{Username: "owner", Email: "owner@example.com", Password: password})
+	require.NoError(t, resp.Error)
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "owner", Password: password})
+	require.NoError(t, resp.Error)
+	owner, resp := th.Client.GetMe()
+	require.NoError(t, resp.Error)
+
+	memberClient, _ := loginNewUser(t, th, "member")
+
+	t.Run("a user without preferences gets none", func(t *testing.T) {
+		preferences, resp := th.Client.GetPreferences()
+		require.NoError(t, resp.Error)
+		require.Empty(t, preferences)
+	})
+
+	t.Run("set and replace preferences", func(t *testing.T) {
+		preferences, resp := th.Client.UpdatePreferences([]model.Preference{
+			{Category: "client_display", Name: "theme", Value: "dark"},
+			{Category: "client_tours", Name: "onboarding", Value: "dismissed"},
+		})
+		require.NoError(t, resp.Error)
+		require.Len(t, preferences, 2)
+
+		preferences, resp = th.Client.UpdatePreferences([]model.Preference{
+			{Category: "client_display", Name: "theme", Value: "light"},
+			{Category: "client_display", Name: "language", Value: "fr"},
+		})
+		require.NoError(t, resp.Error)
+		require.Equal(t, []model.Preference{
+			{UserID: owner.ID, Category: "client_display", Name: "language", Value: "fr"},
+			{UserID: owner.ID, Category: "client_display", Name: "theme", Value: "light"},
+			{UserID: owner.ID, Category: "client_tours", Name: "onboarding", Value: "dismissed"},
+		}, preferences)
+
+		fetched, resp := th.Client.GetPreferences()
+		require.NoError(t, resp.Error)
+		require.Equal(t, preferences, fetched)
+	})
+
+	t.Run("the preferences are kept per user", func(t *testing.T) {
+		preferences, resp := memberClient.GetPreferences()
+		require.NoError(t, resp.Error)
+		require.Empty(t, preferences)
+	})
+
+	t.Run("invalid preferences", func(t *testing.T) {
+		testCases := []struct {
+			name        string
+			preferences []model.Preference
+		}{
+			{"empty batch", []model.Preference{}},
+			{"reserved category", []model.Preference{{Category: "digest", Name: "theme"}}},
+			{"invalid name", []model.Preference{{Category: "client_display", Name: "Theme"}}},
+			{"value too long", []model.Preference{{Category: "client_display", Name: "theme", Value: strings.Repeat("a", model.PreferenceValueMaxLength+1)}}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, resp := th.Client.UpdatePreferences(tc.preferences)
+				require.Error(t, resp.Error)
+				require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			})
+		}
+	})
+
+	t.Run("delete preferences", func(t *testing.T) {
+		preferences, resp := th.Client.DeletePreferences([]model.Preference{
+			{Category: "client_display", Name: "theme"},
+			{Category: "client_display", Name: "unset"},
+		})
+		require.NoError(t, resp.Error)
+		require.Equal(t, []model.Preference{
+			{UserID: owner.ID, Category: "client_display", Name: "language", Value: "fr"},
+			{UserID: owner.ID, Category: "client_tours", Name: "onboarding", Value: "dismissed"},
+		}, preferences)
+	})
+}
@@ -0,0 +1,46 @@
+package integrationtests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardPresence(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := "board-id"
+
+	viewers, resp := th.Client.GetBoardPresence(boardID)
+	require.NoError(t, resp.Error)
+	require.Empty(t, viewers)
+
+	url := "ws" + strings.TrimPrefix(th.Server.Config().ServerRoot, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+
+	commands := []map[string]interface{}{
+		{"action": "AUTH", "token": "TESTTOKEN"},
+		{"action": "SUBSCRIBE_WORKSPACE", "workspaceId": "0"},
+		{"action": "SET_ACTIVE_BOARD", "workspaceId": "0", "boardId": boardID},
+	}
+	for _, command := range commands {
+		require.NoError(t, conn.WriteJSON(command))
+	}
+
+	require.Eventually(t, func() bool {
+		viewers, resp := th.Client.GetBoardPresence(boardID)
+		return resp.Error == nil && len(viewers) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// the viewer leaves the board when the connection is closed
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		viewers, resp := th.Client.GetBoardPresence(boardID)
+		return resp.Error == nil && len(viewers) == 0
+	}, 5*time.Second, 20*time.Millisecond)
+}
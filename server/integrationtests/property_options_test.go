@@ -0,0 +1,86 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConstants(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	constants, resp := th.Client.GetConstants()
+	require.NoError(t, resp.Error)
+	require.Equal(t, model.PropertyOptionColors, constants.PropertyOptionColors)
+	require.Contains(t, constants.PropertyTypes, "multiSelect")
+	require.Contains(t, constants.PropertyTypes, model.PropertyTypeComputed)
+	require.Contains(t, constants.BlockTypes, "card")
+	require.Contains(t, constants.ComputedAggregations, model.ComputedAggregationSum)
+	require.Contains(t, constants.CodeLanguages, "go")
+}
+
+func TestPropertyOptionColors(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardProperties := func(color interface{}) []interface{} {
+		return []interface{}{
+			map[string]interface{}{
+				"id":   "status",
+				"name": "Status",
+				"type": "select",
+				"options": []interface{}{
+					map[string]interface{}{"id": "done", "value": "Done", "color": color},
+				},
+			},
+		}
+	}
+	getColor := func(t *testing.T) interface{} {
+		blocks, resp := th.Client.GetBlocks()
+		require.NoError(t, resp.Error)
+		for _, block := range blocks {
+			if block.ID == boardID {
+				template := block.Fields["cardProperties"].([]interface{})[0].(map[string]interface{})
+				return template["options"].([]interface{})[0].(map[string]interface{})["color"]
+			}
+		}
+		require.Fail(t, "board not found")
+		return nil
+	}
+
+	board := model.Block{
+		ID:       boardID,
+		RootID:   boardID,
+		CreateAt: 1,
+		UpdateAt: 1,
+		Type:     "board",
+		Fields:   map[string]interface{}{"cardProperties": cardProperties("green")},
+	}
+	_, resp := th.Client.InsertBlocks([]model.Block{board})
+	require.NoError(t, resp.Error)
+
+	t.Run("the legacy colors are normalized", func(t *testing.T) {
+		require.Equal(t, model.PropertyOptionColorGreen, getColor(t))
+
+		_, resp := th.Client.PatchBlock(boardID, &model.BlockPatch{
+			UpdatedFields: map[string]interface{}{"cardProperties": cardProperties("Red")},
+		})
+		require.NoError(t, resp.Error)
+		require.Equal(t, model.PropertyOptionColorRed, getColor(t))
+	})
+
+	t.Run("the unknown colors are rejected", func(t *testing.T) {
+		_, resp := th.Client.PatchBlock(boardID, &model.BlockPatch{
+			UpdatedFields: map[string]interface{}{"cardProperties": cardProperties("#00ff00")},
+		})
+		requireErrorCode(t, resp, http.StatusBadRequest, model.ErrorCodeInvalidBlock)
+		require.Contains(t, resp.Error.Error(), `"optionId":"done"`)
+		require.Contains(t, resp.Error.Error(), `"validColors":["propColorDefault","propColorGray"`)
+		require.Equal(t, model.PropertyOptionColorRed, getColor(t))
+	})
+}
@@ -0,0 +1,72 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickAddCard(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	_, resp := th.Client.Register(&api.RegisterRequest{Username: "owner", Email: "owner@example.com", Password: password})
+	require.NoError(t, resp.Error)
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "owner", Password: password})
+	require.NoError(t, resp.Error)
+	_, sara := loginNewUser(t, th, "sara")
+
+	boardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	_, resp = th.Client.InsertBlocks([]model.Block{{ID: boardID, RootID: boardID, Type: "board", CreateAt: now, UpdateAt: now, Fields: map[string]interface{}{
+		"cardProperties": []interface{}{
+			map[string]interface{}{"id": "priority", "name": "Priority", "type": "select", "options": []interface{}{
+				map[string]interface{}{"id": "urgent", "value": "Urgent"},
+			}},
+			map[string]interface{}{"id": "labels", "name": "Labels", "type": "multiSelect", "options": []interface{}{
+				map[string]interface{}{"id": "bug", "value": "Bug"},
+			}},
+			map[string]interface{}{"id": "owner", "name": "Owner", "type": "person"},
+			map[string]interface{}{"id": "due", "name": "Due date", "type": "date"},
+		},
+	}}})
+	require.NoError(t, resp.Error)
+
+	t.Run("should create the card from the text", func(t *testing.T) {
+		card, resp := th.Client.QuickAddCard(boardID, "Fix login bug #urgent #bug #unknown @sara @nobody due 2030-01-02")
+		require.NoError(t, resp.Error)
+		require.Equal(t, "Fix login bug #unknown @nobody", card.Title)
+		require.Equal(t, boardID, card.ParentID)
+
+		blocks, resp := th.Client.GetSubtree(boardID)
+		require.NoError(t, resp.Error)
+		var stored *model.Block
+		for i := range blocks {
+			if blocks[i].ID == card.ID {
+				stored = &blocks[i]
+			}
+		}
+		require.NotNil(t, stored)
+		require.Equal(t, "card", stored.Type)
+		properties := stored.Fields["properties"].(map[string]interface{})
+		require.Equal(t, "urgent", properties["priority"])
+		require.Equal(t, []interface{}{"bug"}, properties["labels"])
+		require.Equal(t, sara.ID, properties["owner"])
+		require.Contains(t, properties["due"], `{"from":`)
+	})
+
+	t.Run("should fail without a title", func(t *testing.T) {
+		_, resp := th.Client.QuickAddCard(boardID, "#urgent @sara")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("should fail without a board", func(t *testing.T) {
+		_, resp := th.Client.QuickAddCard(utils.CreateGUID(), "Fix login bug")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
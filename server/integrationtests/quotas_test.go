@@ -0,0 +1,48 @@
+package integrationtests
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceQuotas(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	now := utils.GetMillis()
+	board := model.Block{ID: boardID, RootID: boardID, Type: "board", Title: "Board", CreateAt: now, UpdateAt: now}
+	_, resp := th.Client.InsertBlocks([]model.Block{board})
+	require.NoError(t, resp.Error)
+
+	t.Run("Insert a block over the block quota", func(t *testing.T) {
+		th.Server.Config().MaxBlocksPerWorkspace = 1
+		defer func() { th.Server.Config().MaxBlocksPerWorkspace = 0 }()
+
+		card := model.Block{ID: utils.CreateGUID(), ParentID: boardID, RootID: boardID, Type: "card", CreateAt: now, UpdateAt: now}
+		_, resp := th.Client.InsertBlocks([]model.Block{card})
+		requireErrorCode(t, resp, http.StatusForbidden, model.ErrorCodeQuotaExceeded)
+
+		// the blocks that already exist can still be updated
+		board.Title = "Renamed"
+		board.UpdateAt = utils.GetMillis()
+		_, resp = th.Client.InsertBlocks([]model.Block{board})
+		require.NoError(t, resp.Error)
+	})
+
+	t.Run("Upload a file over the file storage quota", func(t *testing.T) {
+		th.Server.Config().MaxFileStoragePerWorkspace = 1500
+		defer func() { th.Server.Config().MaxFileStoragePerWorkspace = 0 }()
+
+		_, resp := th.Client.WorkspaceUploadFile("0", boardID, bytes.NewReader(randomBytes(t, 1000)))
+		require.NoError(t, resp.Error)
+
+		_, resp = th.Client.WorkspaceUploadFile("0", boardID, bytes.NewReader(randomBytes(t, 1000)))
+		requireErrorCode(t, resp, http.StatusForbidden, model.ErrorCodeQuotaExceeded)
+	})
+}
@@ -0,0 +1,84 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/client"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.RateLimitPerSecond = 0.01
+	cfg.RateLimitBurst = 3
+	cfg.AdminRateLimitPerSecond = 0.01
+	cfg.AdminRateLimitBurst = 5
+
+	th := SetupTestHelperWithConfig(cfg).InitBasic()
+	defer th.TearDown()
+
+	t.Run("the single user token gets the admin burst", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			_, resp := th.Client.GetBlocks()
+			require.NoError(t, resp.Error, "request %d", i)
+		}
+
+		_, resp := th.Client.GetBlocks()
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.NotEmpty(t, resp.Header.Get("Retry-After"))
+		require.Contains(t, resp.Error.Error(), `"errorCode":1003`)
+		require.Contains(t, resp.Error.Error(), `"code":"rate_limited"`)
+	})
+
+	t.Run("the requests without a token are limited by address", func(t *testing.T) {
+		anonymous := client.NewClient(th.Server.Config().ServerRoot, "")
+		for i := 0; i < 3; i++ {
+			_, resp := anonymous.GetBlocks()
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode, "request %d", i)
+		}
+
+		_, resp := anonymous.GetBlocks()
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	})
+
+	t.Run("the requests with unknown tokens share the bucket of their address", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			faked := client.NewClient(th.Server.Config().ServerRoot, utils.CreateGUID())
+			_, resp := faked.GetBlocks()
+			require.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "request %d", i)
+		}
+	})
+}
+
+func TestRateLimitAdminSessions(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.RateLimitPerSecond = 0.01
+	cfg.RateLimitBurst = 3
+	cfg.AdminRateLimitPerSecond = 0.01
+	cfg.AdminRateLimitBurst = 5
+
+	th := SetupTestHelperWithConfigWithoutToken(cfg).InitBasic()
+	defer th.TearDown()
+
+	// the first registered user is the admin, registering and logging in
+	// being limited by address
+	password := utils.CreateGUID()
+	_, resp := th.Client.Register(&api.RegisterRequest{Username: "admin", Email: "admin@example.com", Password: password})
+	require.NoError(t, resp.Error)
+	_, resp = th.Client.Login(&api.LoginRequest{Type: "normal", Username: "admin", Password: password})
+	require.NoError(t, resp.Error)
+
+	// the first request of the session is limited by address too, before
+	// the session is resolved
+	for i := 0; i < 6; i++ {
+		_, resp := th.Client.GetBlocks()
+		require.NoError(t, resp.Error, "request %d", i)
+	}
+
+	_, resp = th.Client.GetBlocks()
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+}
@@ -0,0 +1,56 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurrenceBlocks(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	templateID := utils.CreateGUID()
+	now := utils.GetMillis()
+	recurrence := func(rule string) model.Block {
+		return model.Block{
+			ID:       utils.CreateGUID(),
+			RootID:   boardID,
+			ParentID: boardID,
+			Type:     model.RecurrenceBlockType,
+			CreateAt: now,
+			UpdateAt: now,
+			Fields:   map[string]interface{}{"templateId": templateID, "rrule": rule},
+		}
+	}
+
+	blocks := []model.Block{
+		{ID: boardID, RootID: boardID, Type: "board", CreateAt: now, UpdateAt: now},
+		{
+			ID:       templateID,
+			RootID:   boardID,
+			ParentID: boardID,
+			Type:     "card",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields:   map[string]interface{}{"isTemplate": true},
+		},
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Insert a recurrence", func(t *testing.T) {
+		_, resp := th.Client.InsertBlocks([]model.Block{recurrence("FREQ=WEEKLY;BYDAY=MO;BYHOUR=9")})
+		require.NoError(t, resp.Error)
+	})
+
+	t.Run("Insert a recurrence with an invalid rule", func(t *testing.T) {
+		_, resp := th.Client.InsertBlocks([]model.Block{recurrence("FREQ=HOURLY")})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,91 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardRelations(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	linkingID := utils.CreateGUID()
+	now := utils.GetMillis()
+	board := model.Block{
+		ID:       boardID,
+		RootID:   boardID,
+		Type:     "board",
+		CreateAt: now,
+		UpdateAt: now,
+		Fields: map[string]interface{}{
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "related", "name": "Related", "type": model.PropertyTypeRelation},
+			},
+		},
+	}
+	card := func(id string, related []interface{}) model.Block {
+		return model.Block{
+			ID:       id,
+			RootID:   boardID,
+			ParentID: boardID,
+			Type:     "card",
+			Title:    "Card",
+			CreateAt: now,
+			UpdateAt: now,
+			Fields:   map[string]interface{}{"properties": map[string]interface{}{"related": related}},
+		}
+	}
+
+	t.Run("Insert a card linking to an unknown card", func(t *testing.T) {
+		blocks := []model.Block{board, card(linkingID, []interface{}{utils.CreateGUID()})}
+		_, resp := th.Client.InsertBlocks(blocks)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	blocks := []model.Block{board, card(cardID, nil), card(linkingID, []interface{}{cardID})}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Get the backlinks", func(t *testing.T) {
+		backlinks, resp := th.Client.GetCardBacklinks(cardID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, []model.CardBacklink{
+			{CardID: linkingID, BoardID: boardID, Title: "Card", PropertyID: "related", PropertyName: "Related"},
+		}, backlinks)
+	})
+
+	t.Run("Unknown card", func(t *testing.T) {
+		_, resp := th.Client.GetCardBacklinks(utils.CreateGUID())
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	// avoid violating the block_history composite primary key constraint
+	// with a quick update of the cards
+	time.Sleep(1 * time.Second)
+
+	t.Run("Delete the linked card", func(t *testing.T) {
+		_, resp := th.Client.DeleteBlock(cardID)
+		require.NoError(t, resp.Error)
+
+		blocks, resp := th.Client.GetSubtree(boardID)
+		require.NoError(t, resp.Error)
+		var linking *model.Block
+		for i := range blocks {
+			if blocks[i].ID == linkingID {
+				linking = &blocks[i]
+			}
+		}
+		require.NotNil(t, linking)
+		properties := linking.Fields["properties"].(map[string]interface{})
+		require.Empty(t, properties["related"])
+	})
+}
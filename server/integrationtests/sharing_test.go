@@ -1,8 +1,11 @@
 package integrationtests
 
 import (
+	"net/http"
 	"testing"
 
+	"github.com/mattermost/focalboard/server/api"
+
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/utils"
 	"github.com/stretchr/testify/require"
@@ -44,3 +47,60 @@ func TestSharing(t *testing.T) {
 		require.Equal(t, sharing.Token, token)
 	})
 }
+
+func TestSharingTokens(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	rootID := utils.CreateGUID()
+	_, resp := th.Client.PostSharing(model.Sharing{ID: rootID, Token: utils.CreateGUID(), Enabled: true})
+	require.NoError(t, resp.Error)
+
+	var token *model.SharingToken
+	t.Run("create a token", func(t *testing.T) {
+		token, resp = th.Client.PostSharingToken(rootID, api.SharingTokenRequest{})
+		require.NoError(t, resp.Error)
+		require.NotEmpty(t, token.Token)
+		require.Equal(t, rootID, token.RootID)
+		require.Zero(t, token.ExpireAt)
+
+		sharing, resp := th.Client.GetSharing(rootID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, []model.SharingToken{*token}, sharing.Tokens)
+	})
+
+	t.Run("reject an expiry in the past", func(t *testing.T) {
+		_, resp := th.Client.PostSharingToken(rootID, api.SharingTokenRequest{ExpireAt: 1})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("regenerate a token", func(t *testing.T) {
+		expireAt := utils.GetMillis() + 60*60*1000
+		newToken, resp := th.Client.PostSharingToken(rootID, api.SharingTokenRequest{
+			ExpireAt: expireAt,
+			Replaces: token.Token,
+		})
+		require.NoError(t, resp.Error)
+		require.NotEqual(t, token.Token, newToken.Token)
+		require.Equal(t, expireAt, newToken.ExpireAt)
+
+		sharing, resp := th.Client.GetSharing(rootID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, []model.SharingToken{*newToken}, sharing.Tokens)
+		token = newToken
+	})
+
+	t.Run("revoke a token", func(t *testing.T) {
+		_, resp := th.Client.DeleteSharingToken(rootID, token.Token)
+		require.NoError(t, resp.Error)
+
+		sharing, resp := th.Client.GetSharing(rootID)
+		require.NoError(t, resp.Error)
+		require.Empty(t, sharing.Tokens)
+
+		_, resp = th.Client.DeleteSharingToken(rootID, token.Token)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
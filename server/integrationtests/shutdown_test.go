@@ -0,0 +1,94 @@
+package integrationtests
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer func() {
+		_ = th.Server.Logger().Shutdown()
+		os.RemoveAll(th.Server.Config().FilesPath)
+	}()
+
+	boardID := insertBoardFixture(t, th, 1)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	shutdownDone := make(chan error, 1)
+	go func() {
+		shutdownDone <- th.Server.ShutdownOnSignal(stop)
+	}()
+
+	// an upload whose body is sent slowly, still in flight when the
+	// server gets the signal
+	bodyReader, bodyWriter := io.Pipe()
+	writer := multipart.NewWriter(bodyWriter)
+	rq, err := http.NewRequest(http.MethodPost, th.Client.APIURL+th.Client.GetWorkspaceUploadFileRoute("0", boardID), bodyReader)
+	require.NoError(t, err)
+	for k, v := range th.Client.HTTPHeader {
+		rq.Header.Set(k, v)
+	}
+	rq.Header.Set("Authorization", "Bearer "+th.Client.Token)
+	rq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		rp, err := http.DefaultClient.Do(rq)
+		if err != nil {
+			t.Log(err)
+		}
+		responses <- rp
+	}()
+
+	part, err := writer.CreateFormFile(api.UploadFormFileKey, "file")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("the first half of the file, "))
+	require.NoError(t, err)
+	// lets the server start handling the request
+	time.Sleep(200 * time.Millisecond)
+
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(syscall.SIGTERM))
+
+	// the new connections are refused while the upload finishes
+	require.Eventually(t, func() bool {
+		rp, err := http.Get(th.Server.Config().ServerRoot + "/api/v1/health")
+		if err == nil {
+			rp.Body.Close()
+		}
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = part.Write([]byte("and the second half"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.NoError(t, bodyWriter.Close())
+
+	rp := <-responses
+	require.NotNil(t, rp)
+	defer rp.Body.Close()
+	require.Equal(t, http.StatusOK, rp.StatusCode)
+	file, err := api.FileUploadResponseFromJSON(rp.Body)
+	require.NoError(t, err)
+	require.NotEmpty(t, file.FileID)
+
+	select {
+	case err := <-shutdownDone:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "the server didn't shut down")
+	}
+}
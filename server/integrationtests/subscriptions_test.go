@@ -0,0 +1,69 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptions(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	viewID := utils.CreateGUID()
+	cardID := utils.CreateGUID()
+	blocks := []model.Block{
+		{ID: boardID, RootID: boardID, CreateAt: 1, UpdateAt: 1, Type: "board"},
+		{ID: viewID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "view"},
+		{ID: cardID, RootID: boardID, ParentID: boardID, CreateAt: 1, UpdateAt: 1, Type: "card"},
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("should watch the card and the board", func(t *testing.T) {
+		subscription, resp := th.Client.Subscribe(cardID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "card", subscription.BlockType)
+		require.Equal(t, cardID, subscription.BlockID)
+
+		_, resp = th.Client.Subscribe(cardID)
+		require.NoError(t, resp.Error)
+		_, resp = th.Client.Subscribe(boardID)
+		require.NoError(t, resp.Error)
+
+		subscriptions, resp := th.Client.GetSubscriptions()
+		require.NoError(t, resp.Error)
+		require.Len(t, subscriptions, 2)
+	})
+
+	t.Run("should not watch the other blocks", func(t *testing.T) {
+		_, resp := th.Client.Subscribe(viewID)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		_, resp = th.Client.Subscribe(utils.CreateGUID())
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should stop watching the board", func(t *testing.T) {
+		success, resp := th.Client.Unsubscribe(boardID)
+		require.NoError(t, resp.Error)
+		require.True(t, success)
+
+		_, resp = th.Client.Unsubscribe(boardID)
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("should stop watching the deleted card", func(t *testing.T) {
+		_, resp := th.Client.DeleteBlock(cardID)
+		require.NoError(t, resp.Error)
+
+		subscriptions, resp := th.Client.GetSubscriptions()
+		require.NoError(t, resp.Error)
+		require.Empty(t, subscriptions)
+	})
+}
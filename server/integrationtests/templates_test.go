@@ -0,0 +1,92 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTemplates(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	t.Run("Built-in templates are imported", func(t *testing.T) {
+		templates, resp := th.Client.GetTemplates()
+		require.NoError(t, resp.Error)
+		require.NotEmpty(t, templates)
+
+		for _, template := range templates {
+			require.NotEmpty(t, template.ID)
+			require.NotEmpty(t, template.Title)
+		}
+	})
+}
+
+func TestDefaultCardTemplate(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	boardID := utils.CreateGUID()
+	templateID := utils.CreateGUID()
+	contentID := utils.CreateGUID()
+	blocks := []model.Block{
+		{ID: boardID, RootID: boardID, Type: "board", CreateAt: 1, UpdateAt: 1, Fields: map[string]interface{}{}},
+		{ID: templateID, RootID: boardID, ParentID: boardID, Type: "card", CreateAt: 1, UpdateAt: 1, Fields: map[string]interface{}{
+			"isTemplate":   true,
+			"contentOrder": []interface{}{contentID},
+		}},
+		{ID: contentID, RootID: boardID, ParentID: templateID, Type: "text", Title: "Steps to reproduce", CreateAt: 1, UpdateAt: 1},
+	}
+	_, resp := th.Client.InsertBlocks(blocks)
+	require.NoError(t, resp.Error)
+
+	t.Run("Reject the blocks that aren't card templates", func(t *testing.T) {
+		_, resp := th.Client.SetDefaultCardTemplate(boardID)
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	settings, resp := th.Client.SetDefaultCardTemplate(templateID)
+	require.NoError(t, resp.Error)
+	require.Equal(t, templateID, settings.DefaultCardTemplateID)
+
+	t.Run("Copy the template into the new blank cards", func(t *testing.T) {
+		cardID := utils.CreateGUID()
+		_, resp := th.Client.InsertBlocks([]model.Block{
+			{ID: cardID, RootID: boardID, ParentID: boardID, Type: "card", CreateAt: 1, UpdateAt: 1, Fields: map[string]interface{}{}},
+		})
+		require.NoError(t, resp.Error)
+
+		subtree, resp := th.Client.GetSubtree(cardID)
+		require.NoError(t, resp.Error)
+		require.Len(t, subtree, 2)
+		for _, block := range subtree {
+			switch block.ID {
+			case cardID:
+				require.Equal(t, templateID, block.Fields[model.CardTemplateField])
+			default:
+				require.Equal(t, "Steps to reproduce", block.Title)
+				require.NotEqual(t, contentID, block.ID)
+			}
+		}
+	})
+
+	t.Run("Clear the default", func(t *testing.T) {
+		settings, resp := th.Client.SetDefaultCardTemplate("")
+		require.NoError(t, resp.Error)
+		require.Empty(t, settings.DefaultCardTemplateID)
+
+		cardID := utils.CreateGUID()
+		_, resp = th.Client.InsertBlocks([]model.Block{
+			{ID: cardID, RootID: boardID, ParentID: boardID, Type: "card", CreateAt: 1, UpdateAt: 1, Fields: map[string]interface{}{}},
+		})
+		require.NoError(t, resp.Error)
+
+		subtree, resp := th.Client.GetSubtree(cardID)
+		require.NoError(t, resp.Error)
+		require.Len(t, subtree, 1)
+	})
+}
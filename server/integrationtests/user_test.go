@@ -3,9 +3,14 @@ package integrationtests
 import (
 	"bytes"
 	"crypto/rand"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
 	"testing"
 
 	"github.com/mattermost/focalboard/server/api"
+	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/utils"
 	"github.com/stretchr/testify/require"
 )
@@ -77,6 +82,39 @@ func TestUserLogin(t *testing.T) {
 	})
 }
 
+func TestUserLogout(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	password := utils.CreateGUID()
+	registerRequest := &api.RegisterRequest{
+		Username: fakeUsername,
+		Email:    fakeEmail,
+		Password: password,
+	}
+	success, resp := th.Client.Register(registerRequest)
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	data, resp := th.Client.Login(&api.LoginRequest{
+		Type:     "normal",
+		Username: fakeUsername,
+		Password: password,
+	})
+	require.NoError(t, resp.Error)
+	token := data.Token
+
+	success, resp = th.Client.Logout()
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+
+	// the token of the session can't be used anymore
+	th.Client.Token = token
+	me, resp := th.Client.GetMe()
+	require.Error(t, resp.Error)
+	require.Nil(t, me)
+}
+
 func TestGetMe(t *testing.T) {
 	th := SetupTestHelperWithoutToken().InitBasic()
 	defer th.TearDown()
@@ -164,6 +202,88 @@ func TestGetUser(t *testing.T) {
 	})
 }
 
+func TestSearchWorkspaceUsers(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	// register
+	password := utils.CreateGUID()
+	registerRequest := &api.RegisterRequest{
+		Username: fakeUsername,
+		Email:    fakeEmail,
+		Password: password,
+	}
+	success, resp := th.Client.Register(registerRequest)
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+	// login
+	loginRequest := &api.LoginRequest{
+		Type:     "normal",
+		Username: fakeUsername,
+		Email:    fakeEmail,
+		Password: password,
+	}
+	data, resp := th.Client.Login(loginRequest)
+	require.NoError(t, resp.Error)
+	require.NotNil(t, data)
+
+	t.Run("matching prefix", func(t *testing.T) {
+		users, resp := th.Client.SearchWorkspaceUsers("FAKE")
+		require.NoError(t, resp.Error)
+		require.Len(t, users, 1)
+		require.Equal(t, fakeUsername, users[0].Username)
+		require.NotEmpty(t, users[0].ID)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		users, resp := th.Client.SearchWorkspaceUsers("username")
+		require.NoError(t, resp.Error)
+		require.Empty(t, users)
+	})
+}
+
+func TestUpdateDigestSettings(t *testing.T) {
+	th := SetupTestHelperWithoutToken().InitBasic()
+	defer th.TearDown()
+
+	// register
+	password := utils.CreateGUID()
+	registerRequest := &api.RegisterRequest{
+		Username: fakeUsername,
+		Email:    fakeEmail,
+		Password: password,
+	}
+	success, resp := th.Client.Register(registerRequest)
+	require.NoError(t, resp.Error)
+	require.True(t, success)
+	// login
+	loginRequest := &api.LoginRequest{
+		Type:     "normal",
+		Username: fakeUsername,
+		Email:    fakeEmail,
+		Password: password,
+	}
+	data, resp := th.Client.Login(loginRequest)
+	require.NoError(t, resp.Error)
+	require.NotNil(t, data)
+
+	t.Run("valid frequency", func(t *testing.T) {
+		settings, resp := th.Client.UpdateDigestSettings(model.DigestSettings{Frequency: model.DigestFrequencyWeekly})
+		require.NoError(t, resp.Error)
+		require.Equal(t, model.DigestFrequencyWeekly, settings.Frequency)
+
+		me, resp := th.Client.GetMe()
+		require.NoError(t, resp.Error)
+		require.Equal(t, model.DigestFrequencyWeekly, me.DigestFrequency())
+	})
+
+	t.Run("invalid frequency", func(t *testing.T) {
+		_, resp := th.Client.UpdateDigestSettings(model.DigestSettings{Frequency: "hourly"})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
 func TestUserChangePassword(t *testing.T) {
 	th := SetupTestHelperWithoutToken().InitBasic()
 	defer th.TearDown()
@@ -227,13 +347,72 @@ func TestWorkspaceUploadFile(t *testing.T) {
 		th := SetupTestHelper().InitBasic()
 		defer th.TearDown()
 
-		workspaceID := "0"
+		testUploadAndDownloadFile(t, th)
+	})
+
+	t.Run("maximum file size", func(t *testing.T) {
+		th := SetupTestHelper().InitBasic()
+		defer th.TearDown()
+		th.Server.Config().MaxFileSize = 1024
+
 		rootID := utils.CreateGUID()
-		data := randomBytes(t, 1024)
-		result, resp := th.Client.WorkspaceUploadFile(workspaceID, rootID, bytes.NewReader(data))
+		result, resp := th.Client.WorkspaceUploadFile("0", rootID, bytes.NewReader(randomBytes(t, 1024)))
 		require.NoError(t, resp.Error)
-		require.NotNil(t, result)
 		require.NotEmpty(t, result.FileID)
-		// TODO get the uploaded file
+
+		result, resp = th.Client.WorkspaceUploadFile("0", rootID, bytes.NewReader(randomBytes(t, 1025)))
+		require.Error(t, resp.Error)
+		require.Nil(t, result)
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		require.Contains(t, resp.Error.Error(), `"errorCode":1001`)
+		require.Contains(t, resp.Error.Error(), `"code":"payload_too_large"`)
+	})
+
+	t.Run("thumbnail", func(t *testing.T) {
+		th := SetupTestHelper().InitBasic()
+		defer th.TearDown()
+
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 800, 200))))
+		rootID := utils.CreateGUID()
+		result, resp := th.Client.WorkspaceUploadNamedFile("0", rootID, "image.png", &buf)
+		require.NoError(t, resp.Error)
+
+		data, resp := th.Client.GetFileThumbnail("0", rootID, result.FileID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
+		thumbnail, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, 400, thumbnail.Width)
+		require.Equal(t, 100, thumbnail.Height)
+
+		// the files without a thumbnail are returned as they are
+		original := randomBytes(t, 1024)
+		result, resp = th.Client.WorkspaceUploadFile("0", rootID, bytes.NewReader(original))
+		require.NoError(t, resp.Error)
+		data, resp = th.Client.GetFileThumbnail("0", rootID, result.FileID)
+		require.NoError(t, resp.Error)
+		require.Equal(t, original, data)
 	})
 }
+
+func testUploadAndDownloadFile(t *testing.T, th *TestHelper) {
+	workspaceID := "0"
+	rootID := utils.CreateGUID()
+	data := randomBytes(t, 1024)
+	result, resp := th.Client.WorkspaceUploadFile(workspaceID, rootID, bytes.NewReader(data))
+	require.NoError(t, resp.Error)
+	require.NotNil(t, result)
+	require.NotEmpty(t, result.FileID)
+
+	downloaded, resp := th.Client.GetFile(workspaceID, rootID, result.FileID, "")
+	require.NoError(t, resp.Error)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, data, downloaded)
+
+	partial, resp := th.Client.GetFile(workspaceID, rootID, result.FileID, "bytes=100-199")
+	require.NoError(t, resp.Error)
+	require.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	require.Equal(t, "bytes 100-199/1024", resp.Header.Get("Content-Range"))
+	require.Equal(t, data[100:200], partial)
+}
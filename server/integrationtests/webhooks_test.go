@@ -0,0 +1,114 @@
+package integrationtests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/webhook"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkspaceWebhooks(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	var mu sync.Mutex
+	payloads := []webhook.BlockChangedPayload{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(webhook.SignatureHeader) != webhook.Sign("secret", body) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var payload webhook.BlockChangedPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		payloads = append(payloads, payload)
+	}))
+	defer ts.Close()
+
+	t.Run("Reject invalid webhook URLs", func(t *testing.T) {
+		webhooks := []model.WorkspaceWebhook{{URL: "not a url"}}
+		_, resp := th.Client.PatchWorkspaceSettings(model.WorkspaceSettingsPatch{Webhooks: &webhooks})
+		require.Error(t, resp.Error)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	webhooks := []model.WorkspaceWebhook{{URL: ts.URL, Secret: "secret"}}
+	settings, resp := th.Client.PatchWorkspaceSettings(model.WorkspaceSettingsPatch{Webhooks: &webhooks})
+	require.NoError(t, resp.Error)
+	require.Equal(t, webhooks, settings.Webhooks)
+
+	blockID := utils.CreateGUID()
+	t.Run("Notify new blocks", func(t *testing.T) {
+		block := model.Block{
+			ID:       blockID,
+			RootID:   blockID,
+			CreateAt: 1,
+			UpdateAt: 1,
+			Type:     "board",
+			Title:    "New title",
+		}
+		_, resp := th.Client.InsertBlocks([]model.Block{block})
+		require.NoError(t, resp.Error)
+
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(payloads) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, "0", payloads[0].WorkspaceID)
+		require.Nil(t, payloads[0].Before)
+		require.Equal(t, blockID, payloads[0].After.ID)
+		require.NotEmpty(t, payloads[0].ActorID)
+	})
+
+	t.Run("Notify patched blocks", func(t *testing.T) {
+		title := "Patched title"
+		_, resp := th.Client.PatchBlock(blockID, &model.BlockPatch{Title: &title})
+		require.NoError(t, resp.Error)
+
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(payloads) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, "New title", payloads[1].Before.Title)
+		require.Equal(t, "Patched title", payloads[1].After.Title)
+	})
+
+	t.Run("Notify deleted blocks", func(t *testing.T) {
+		_, resp := th.Client.DeleteBlock(blockID)
+		require.NoError(t, resp.Error)
+
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(payloads) == 3
+		}, 5*time.Second, 10*time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, blockID, payloads[2].Before.ID)
+		require.Nil(t, payloads[2].After)
+	})
+}
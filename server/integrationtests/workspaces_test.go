@@ -0,0 +1,29 @@
+package integrationtests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserWorkspaces(t *testing.T) {
+	th := SetupTestHelper().InitBasic()
+	defer th.TearDown()
+
+	t.Run("Get a page of the workspaces", func(t *testing.T) {
+		page, resp := th.Client.GetUserWorkspaces("", model.UserWorkspacesMaxPageSize)
+		require.NoError(t, resp.Error)
+		require.NotNil(t, page)
+		require.False(t, page.HasMore)
+	})
+
+	t.Run("Reject an invalid limit", func(t *testing.T) {
+		for _, limit := range []int{0, model.UserWorkspacesMaxPageSize + 1} {
+			_, resp := th.Client.GetUserWorkspaces("", limit)
+			require.Error(t, resp.Error)
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		}
+	})
+}
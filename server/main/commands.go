@@ -0,0 +1,374 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/server"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// The exit codes of the commands
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// commandUsersPageSize is the number of users read at once by user list.
+const commandUsersPageSize = 100
+
+// commandUserID is the user the changes of the commands are made by.
+const commandUserID = "system"
+
+const commandsUsage = `Usage: focalboard-server <command> [flags]
+
+Commands:
+  export --workspace <id> --out <file.zip>      export a workspace archive
+  import --workspace <id> --in <file.zip>       import a workspace archive
+  user list [--active true|false] [--username <prefix>]
+  user create --username <name> --email <email> --password <password>
+  user reset-password --username <name> --password <password>
+  doctor                                        check the database
+
+Every command also accepts --dbtype and --dbconfig, and runs against the
+store of config.json without going through the HTTP server.
+`
+
+// commands are the subcommands of the server, by name.
+var commands = map[string]func(cmd *command, args []string) int{
+	"export": runExport,
+	"import": runImport,
+	"user":   runUser,
+	"doctor": runDoctor,
+}
+
+func isCommand(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// command is the environment of a subcommand.
+type command struct {
+	config *config.Configuration
+	logger *mlog.Logger
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// runCommandFromConfig runs the subcommand with a logger configured
+// like the server's, or logging to stderr.
+func runCommandFromConfig(cfg *config.Configuration, args []string) int {
+	logger, _ := mlog.NewLogger()
+	cfgJSON := cfg.LoggingCfgJSON
+	if cfg.LoggingCfgFile == "" && cfgJSON == "" {
+		cfgJSON = commandLoggingConfig()
+	}
+	if err := logger.Configure(cfg.LoggingCfgFile, cfgJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "Error in config file for logger: %s\n", err)
+		return exitFailure
+	}
+	defer func() { _ = logger.Shutdown() }()
+
+	return runCommand(cfg, logger, args, os.Stdout, os.Stderr)
+}
+
+// runCommand runs the subcommand of the arguments, starting with its
+// name, and returns the exit code of the process.
+func runCommand(cfg *config.Configuration, logger *mlog.Logger, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || !isCommand(args[0]) {
+		fmt.Fprint(stderr, commandsUsage)
+		return exitUsage
+	}
+
+	cmd := &command{config: cfg, logger: logger, stdout: stdout, stderr: stderr}
+	return commands[args[0]](cmd, args[1:])
+}
+
+// flagSet returns the flags of the subcommand, with the database flags
+// overriding the configuration.
+func (cmd *command) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(cmd.stderr)
+	flags.StringVar(&cmd.config.DBType, "dbtype", cmd.config.DBType, "Database type")
+	flags.StringVar(&cmd.config.DBConfigString, "dbconfig", cmd.config.DBConfigString, "Database config")
+	return flags
+}
+
+// parse parses the flags of the subcommand, and returns false with the
+// exit code if they're invalid or a required flag is missing.
+func (cmd *command) parse(flags *flag.FlagSet, args []string, required ...string) (int, bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(cmd.stderr, "unexpected arguments: %v\n", flags.Args())
+		return exitUsage, false
+	}
+	for _, name := range required {
+		if flags.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(cmd.stderr, "--%s is required\n", name)
+			flags.Usage()
+			return exitUsage, false
+		}
+	}
+	return exitOK, true
+}
+
+func (cmd *command) fail(format string, args ...interface{}) int {
+	fmt.Fprintf(cmd.stderr, format+"\n", args...)
+	return exitFailure
+}
+
+// withApp opens the store, migrating it, and runs the function with an
+// app working on it.
+func (cmd *command) withApp(run func(a *app.App) int) int {
+	db, err := server.NewStore(cmd.config, cmd.logger)
+	if err != nil {
+		return cmd.fail("Unable to open the database: %s", err)
+	}
+	defer func() { _ = db.Shutdown() }()
+
+	a, shutdown, err := server.NewCommandApp(cmd.config, db, cmd.logger)
+	if err != nil {
+		return cmd.fail("Unable to initialize the app: %s", err)
+	}
+	defer shutdown()
+
+	return run(a)
+}
+
+func runExport(cmd *command, args []string) int {
+	flags := cmd.flagSet("export")
+	workspaceID := flags.String("workspace", "0", "the ID of the workspace to export")
+	out := flags.String("out", "", "the archive file to write")
+	if code, ok := cmd.parse(flags, args, "out"); !ok {
+		return code
+	}
+
+	return cmd.withApp(func(a *app.App) int {
+		file, err := os.Create(*out)
+		if err != nil {
+			return cmd.fail("Unable to create the archive: %s", err)
+		}
+
+		err = a.ExportWorkspaceArchive(context.Background(), store.Container{WorkspaceID: *workspaceID}, commandUserID, file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(*out)
+			return cmd.fail("Unable to export the workspace: %s", err)
+		}
+
+		fmt.Fprintf(cmd.stdout, "Exported workspace %s to %s\n", *workspaceID, *out)
+		return exitOK
+	})
+}
+
+func runImport(cmd *command, args []string) int {
+	flags := cmd.flagSet("import")
+	workspaceID := flags.String("workspace", "0", "the ID of the workspace to import into")
+	in := flags.String("in", "", "the archive file to read")
+	if code, ok := cmd.parse(flags, args, "in"); !ok {
+		return code
+	}
+
+	return cmd.withApp(func(a *app.App) int {
+		archive, err := zip.OpenReader(*in)
+		if err != nil {
+			return cmd.fail("Unable to open the archive: %s", err)
+		}
+		defer archive.Close()
+
+		summary, err := a.ImportWorkspaceArchive(context.Background(), store.Container{WorkspaceID: *workspaceID}, &archive.Reader, commandUserID)
+		if err != nil {
+			return cmd.fail("Unable to import the archive: %s", err)
+		}
+
+		fmt.Fprintf(cmd.stdout, "Imported %d boards, %d cards and %d comments into workspace %s\n",
+			summary.BoardsCreated, summary.CardsCreated, summary.CommentsCreated, *workspaceID)
+		for _, skipped := range summary.Skipped {
+			fmt.Fprintf(cmd.stdout, "Skipped %s %s: %s\n", skipped.Type, skipped.ID, skipped.Reason)
+		}
+		return exitOK
+	})
+}
+
+func runUser(cmd *command, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(cmd.stderr, commandsUsage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return runUserList(cmd, args[1:])
+	case "create":
+		return runUserCreate(cmd, args[1:])
+	case "reset-password":
+		return runUserResetPassword(cmd, args[1:])
+	default:
+		fmt.Fprintf(cmd.stderr, "unknown user command %q\n", args[0])
+		return exitUsage
+	}
+}
+
+func runUserList(cmd *command, args []string) int {
+	flags := cmd.flagSet("user list")
+	active := flags.String("active", "", "only the active users when true, or the deactivated ones when false")
+	username := flags.String("username", "", "only the users whose username starts with this prefix")
+	if code, ok := cmd.parse(flags, args); !ok {
+		return code
+	}
+
+	opts := model.QueryUsersOptions{
+		Username: *username,
+		PerPage:  commandUsersPageSize,
+	}
+	if *active != "" {
+		value, err := strconv.ParseBool(*active)
+		if err != nil {
+			fmt.Fprintf(cmd.stderr, "invalid --active %q\n", *active)
+			return exitUsage
+		}
+		opts.Active = &value
+	}
+
+	return cmd.withApp(func(a *app.App) int {
+		writer := tabwriter.NewWriter(cmd.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tUSERNAME\tEMAIL\tACTIVE\tADMIN")
+		for {
+			users, err := a.GetUsers(opts)
+			if err != nil {
+				return cmd.fail("Unable to get the users: %s", err)
+			}
+			for _, user := range users {
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%t\t%t\n", user.ID, user.Username, user.Email, user.DeleteAt == 0, user.IsAdmin)
+			}
+			if len(users) < opts.PerPage {
+				break
+			}
+			opts.Page++
+		}
+		if err := writer.Flush(); err != nil {
+			return cmd.fail("Unable to write the users: %s", err)
+		}
+		return exitOK
+	})
+}
+
+func runUserCreate(cmd *command, args []string) int {
+	flags := cmd.flagSet("user create")
+	username := flags.String("username", "", "the username of the user")
+	email := flags.String("email", "", "the email of the user")
+	password := flags.String("password", "", "the password of the user")
+	if code, ok := cmd.parse(flags, args, "username", "email", "password"); !ok {
+		return code
+	}
+
+	return cmd.withApp(func(a *app.App) int {
+		if err := a.RegisterUser(*username, *email, *password); err != nil {
+			return cmd.fail("Unable to create the user: %s", err)
+		}
+
+		fmt.Fprintf(cmd.stdout, "Created user %s\n", *username)
+		return exitOK
+	})
+}
+
+func runUserResetPassword(cmd *command, args []string) int {
+	flags := cmd.flagSet("user reset-password")
+	username := flags.String("username", "", "the username of the user")
+	password := flags.String("password", "", "the new password of the user")
+	if code, ok := cmd.parse(flags, args, "username", "password"); !ok {
+		return code
+	}
+
+	return cmd.withApp(func(a *app.App) int {
+		user, err := a.GetUserByUsername(*username)
+		if err != nil {
+			return cmd.fail("Unable to get the user %s: %s", *username, err)
+		}
+
+		if err := a.ResetUserPassword(commandUserID, user.ID, *password); err != nil {
+			return cmd.fail("Unable to reset the password: %s", err)
+		}
+
+		fmt.Fprintf(cmd.stdout, "Reset the password of user %s\n", *username)
+		return exitOK
+	})
+}
+
+func runDoctor(cmd *command, args []string) int {
+	flags := cmd.flagSet("doctor")
+	if code, ok := cmd.parse(flags, args); !ok {
+		return code
+	}
+
+	// the pending migrations are reported, not applied
+	db, err := server.NewStoreWithoutMigrations(cmd.config, cmd.logger)
+	if err != nil {
+		fmt.Fprintf(cmd.stdout, "FAIL\tdatabase\t%s\n", err)
+		return exitFailure
+	}
+	defer func() { _ = db.Shutdown() }()
+
+	a, shutdown, err := server.NewCommandApp(cmd.config, db, cmd.logger)
+	if err != nil {
+		return cmd.fail("Unable to initialize the app: %s", err)
+	}
+	defer shutdown()
+
+	code := exitOK
+	for _, check := range a.RunDiagnostics(context.Background()) {
+		status := "ok"
+		if !check.OK {
+			status = "FAIL"
+			code = exitFailure
+		}
+		fmt.Fprintf(cmd.stdout, "%s\t%s\t%s\n", status, check.Name, check.Detail)
+	}
+	return code
+}
+
+// commandLoggingConfig logs the warnings and errors of the commands to
+// stderr, so that their output can be parsed.
+func commandLoggingConfig() string {
+	return `
+	{
+		"def": {
+			"type": "console",
+			"options": {
+				"out": "stderr"
+			},
+			"format": "plain",
+			"format_options": {
+				"delim": " ",
+				"min_level_len": 5,
+				"min_msg_len": 40
+			},
+			"levels": [
+				{"id": 3, "name": "warn"},
+				{"id": 2, "name": "error"},
+				{"id": 1, "name": "fatal", "stacktrace": true},
+				{"id": 0, "name": "panic", "stacktrace": true}
+			]
+		}
+	}`
+}
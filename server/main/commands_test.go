@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/server"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+func setupCommandTest(t *testing.T) (*config.Configuration, *mlog.Logger) {
+	dir := t.TempDir()
+	cfg := &config.Configuration{
+		DBType:         "sqlite3",
+		DBConfigString: filepath.Join(dir, "focalboard.db"),
+		DBTablePrefix:  "test_",
+		FilesDriver:    "local",
+		FilesPath:      filepath.Join(dir, "files"),
+		AuthMode:       "native",
+	}
+
+	logger, _ := mlog.NewLogger()
+	require.NoError(t, logger.Configure("", commandLoggingConfig()))
+	t.Cleanup(func() { _ = logger.Shutdown() })
+	return cfg, logger
+}
+
+func run(cfg *config.Configuration, logger *mlog.Logger, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := runCommand(cfg, logger, args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCommandUsage(t *testing.T) {
+	cfg, logger := setupCommandTest(t)
+
+	code, _, stderr := run(cfg, logger, "unknown")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "Usage:")
+
+	code, _, _ = run(cfg, logger, "user", "unknown")
+	require.Equal(t, exitUsage, code)
+
+	code, _, stderr = run(cfg, logger, "export")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, "--out is required")
+}
+
+func TestUserCommands(t *testing.T) {
+	cfg, logger := setupCommandTest(t)
+
+	code, stdout, _ := run(cfg, logger, "user", "create", "--username", "admin", "--email", "admin@example.com", "--password", "password1")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, "Created user admin")
+
+	code, _, _ = run(cfg, logger, "user", "create", "--username", "other", "--email", "other@example.com", "--password", "password2")
+	require.Equal(t, exitOK, code)
+
+	code, _, stderr := run(cfg, logger, "user", "create", "--username", "admin", "--email", "again@example.com", "--password", "password3")
+	require.Equal(t, exitFailure, code)
+	require.Contains(t, stderr, "already exists")
+
+	code, stdout, _ = run(cfg, logger, "user", "list")
+	require.Equal(t, exitOK, code)
+	require.Regexp(t, `admin\s+admin@example.com\s+true\s+true`, stdout)
+	require.Regexp(t, `other\s+other@example.com\s+true\s+false`, stdout)
+
+	code, stdout, _ = run(cfg, logger, "user", "list", "--username", "oth")
+	require.Equal(t, exitOK, code)
+	require.NotContains(t, stdout, "admin@example.com")
+
+	code, _, _ = run(cfg, logger, "user", "list", "--active", "maybe")
+	require.Equal(t, exitUsage, code)
+
+	code, _, _ = run(cfg, logger, "user", "reset-password", "--username", "other", "--password", "short")
+	require.Equal(t, exitFailure, code)
+
+	code, _, _ = run(cfg, logger, "user", "reset-password", "--username", "unknown", "--password", "password4")
+	require.Equal(t, exitFailure, code)
+
+	code, stdout, _ = run(cfg, logger, "user", "reset-password", "--username", "other", "--password", "password4")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, "Reset the password of user other")
+}
+
+func TestArchiveAndDoctorCommands(t *testing.T) {
+	cfg, logger := setupCommandTest(t)
+	ctx := context.Background()
+	container := store.Container{WorkspaceID: "0"}
+
+	db, err := server.NewStore(cfg, logger)
+	require.NoError(t, err)
+	for _, block := range []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board", Title: "Board", CreateAt: 1, UpdateAt: 1},
+		{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card", Title: "Card", CreateAt: 1, UpdateAt: 1},
+	} {
+		block := block
+		require.NoError(t, db.InsertBlock(ctx, container, &block, "user-1"))
+	}
+	require.NoError(t, db.Shutdown())
+
+	code, stdout, _ := run(cfg, logger, "doctor")
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, "ok\tdatabase")
+	require.Contains(t, stdout, "ok\tschema")
+	require.Contains(t, stdout, "ok\torphaned_blocks")
+
+	archivePath := filepath.Join(t.TempDir(), "workspace.zip")
+	code, stdout, _ = run(cfg, logger, "export", "--workspace", "0", "--out", archivePath)
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, "Exported workspace 0")
+
+	code, stdout, _ = run(cfg, logger, "import", "--workspace", "0", "--in", archivePath)
+	require.Equal(t, exitOK, code)
+	require.Contains(t, stdout, "Imported 1 boards, 1 cards")
+
+	code, _, _ = run(cfg, logger, "import", "--in", filepath.Join(t.TempDir(), "missing.zip"))
+	require.Equal(t, exitFailure, code)
+
+	// a card whose board is gone is reported
+	db, err = server.NewStore(cfg, logger)
+	require.NoError(t, err)
+	orphan := model.Block{ID: "card-2", RootID: "missing-board", ParentID: "missing-board", Type: "card", CreateAt: 1, UpdateAt: 1}
+	require.NoError(t, db.InsertBlock(ctx, container, &orphan, "user-1"))
+	require.NoError(t, db.Shutdown())
+
+	code, stdout, _ = run(cfg, logger, "doctor")
+	require.Equal(t, exitFailure, code)
+	require.Contains(t, stdout, "FAIL\torphaned_blocks\t1 blocks without a root block")
+
+	cfg.DBConfigString = filepath.Join(t.TempDir(), "missing", "focalboard.db")
+	code, stdout, _ = run(cfg, logger, "doctor")
+	require.Equal(t, exitFailure, code)
+	require.Contains(t, stdout, "FAIL\tdatabase")
+}
@@ -28,9 +28,11 @@ package main
 import (
 	"C"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -94,6 +96,11 @@ func main() {
 		return
 	}
 
+	// the subcommands work on the store, without starting the server
+	if len(os.Args) > 1 && isCommand(os.Args[1]) {
+		os.Exit(runCommandFromConfig(config, os.Args[1:]))
+	}
+
 	logger, _ := mlog.NewLogger()
 	cfgJSON := config.LoggingCfgJSON
 	if config.LoggingCfgFile == "" && cfgJSON == "" {
@@ -120,6 +127,8 @@ func main() {
 	pSingleUser := flag.Bool("single-user", false, "single user mode")
 	pDBType := flag.String("dbtype", "", "Database type")
 	pDBConfig := flag.String("dbconfig", "", "Database config")
+	pMigrateDryRun := flag.Bool("migrate-dry-run", false, "print the pending migrations without applying them, and exit")
+	pSendTestDigest := flag.String("send-test-digest", "", "email a sample digest to the address to verify the SMTP settings, and exit")
 	flag.Parse()
 
 	singleUser := false
@@ -160,12 +169,22 @@ func main() {
 		config.Port = *pPort
 	}
 
+	if pMigrateDryRun != nil && *pMigrateDryRun {
+		printPendingMigrations(config, logger)
+		return
+	}
+
+	if pSendTestDigest != nil && *pSendTestDigest != "" {
+		sendTestDigest(config, logger, *pSendTestDigest)
+		return
+	}
+
 	db, err := server.NewStore(config, logger)
 	if err != nil {
 		logger.Fatal("server.NewStore ERROR", mlog.Err(err))
 	}
 
-	server, err := server.New(config, singleUserToken, db, logger, "", nil)
+	server, err := server.New(config, singleUserToken, db, logger, "", nil, nil)
 	if err != nil {
 		logger.Fatal("server.New ERROR", mlog.Err(err))
 	}
@@ -176,12 +195,52 @@ func main() {
 
 	// Setting up signal capturing
 	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	// Waiting for SIGINT (pkill -2) or SIGTERM, then letting the requests
+	// and the jobs finish
+	if err := server.ShutdownOnSignal(stop); err != nil {
+		logger.Error("server.Shutdown ERROR", mlog.Err(err))
+	}
+}
+
+// printPendingMigrations prints the name and SQL of the migrations not
+// applied yet to the database.
+func printPendingMigrations(config *config.Configuration, logger *mlog.Logger) {
+	pending, err := server.GetPendingMigrations(config, logger)
+	if err != nil {
+		logger.Fatal("server.GetPendingMigrations ERROR", mlog.Err(err))
+	}
 
-	// Waiting for SIGINT (pkill -2)
-	<-stop
+	if len(pending) == 0 {
+		fmt.Println("No pending migrations")
+		return
+	}
+
+	for _, migration := range pending {
+		fmt.Printf("-- %06d_%s\n%s\n\n", migration.Version, migration.Name, strings.TrimSpace(migration.SQL))
+	}
+}
+
+// sendTestDigest emails a sample digest to the address through the
+// configured SMTP server.
+func sendTestDigest(config *config.Configuration, logger *mlog.Logger, to string) {
+	db, err := server.NewStore(config, logger)
+	if err != nil {
+		logger.Fatal("server.NewStore ERROR", mlog.Err(err))
+	}
+	defer func() { _ = db.Shutdown() }()
 
-	_ = server.Shutdown()
+	a, shutdown, err := server.NewCommandApp(config, db, logger)
+	if err != nil {
+		logger.Fatal("server.NewCommandApp ERROR", mlog.Err(err))
+	}
+	defer shutdown()
+
+	if err := a.SendTestDigest(to); err != nil {
+		logger.Fatal("Unable to send the test digest", mlog.String("to", to), mlog.Err(err))
+	}
+	fmt.Printf("Sent a test digest to %s\n", to)
 }
 
 // StartServer starts the server
@@ -245,7 +304,7 @@ func startServer(webPath string, filesPath string, port int, singleUserToken, db
 		logger.Fatal("server.NewStore ERROR", mlog.Err(err))
 	}
 
-	pServer, err = server.New(config, singleUserToken, db, logger, "", nil)
+	pServer, err = server.New(config, singleUserToken, db, logger, "", nil, nil)
 	if err != nil {
 		logger.Fatal("server.New ERROR", mlog.Err(err))
 	}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// AccessTokenPrefix starts the personal access tokens, to tell them apart
+// from the session tokens.
+const AccessTokenPrefix = "fbp_"
+
+// AccessToken is a personal access token of a user, used by the API
+// integrations instead of a session
+// swagger:model
+type AccessToken struct {
+	// ID of the token
+	// required: true
+	ID string `json:"id"`
+
+	// ID of the user owning the token
+	// required: true
+	UserID string `json:"userId"`
+
+	// Description of the token
+	// required: false
+	Description string `json:"description"`
+
+	// The token, only returned when the token is created
+	// required: false
+	Token string `json:"token,omitempty"`
+
+	// SHA-256 hash of the token, the only form in which the token is
+	// stored
+	TokenHash string `json:"-"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+
+	// Last time the token was used, updated at most once per minute
+	// required: false
+	LastUsedAt int64 `json:"lastUsedAt"`
+
+	// Expiry time in milliseconds, zero if the token never expires
+	// required: false
+	ExpireAt int64 `json:"expireAt"`
+}
+
+// IsExpired returns true if the token has an expiry time before now.
+func (t AccessToken) IsExpired(now int64) bool {
+	return t.ExpireAt != 0 && t.ExpireAt <= now
+}
+
+// IsAccessToken returns true if the token is a personal access token.
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// HashToken returns the SHA-256 hash under which a secret token, like a
+// personal access token, is stored.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
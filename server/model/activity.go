@@ -0,0 +1,97 @@
+package model
+
+const (
+	// CardActivityDefaultPageSize is the number of entries of a page of
+	// the activity of a card when no limit is given
+	CardActivityDefaultPageSize = 50
+
+	// CardActivityMaxPageSize is the maximum number of entries of a page
+	// of the activity of a card
+	CardActivityMaxPageSize = 200
+)
+
+const (
+	CardActivityCreated = "created"
+	CardActivityUpdated = "updated"
+	CardActivityDeleted = "deleted"
+)
+
+// BlockHistoryEntry is a version of a block from its history, along
+// with the version it replaced.
+type BlockHistoryEntry struct {
+	Block Block
+
+	// Previous is nil when the version created the block
+	Previous *Block
+}
+
+// BlockChange is a change of a field of a block between two versions
+// swagger:model
+type BlockChange struct {
+	// Name of the changed field, "title" or the key of the fields of the
+	// block, such as "properties"
+	// required: true
+	Field string `json:"field"`
+
+	// ID of the changed card property, when the field is "properties"
+	// required: false
+	PropertyID string `json:"propertyId,omitempty"`
+
+	// Name of the changed card property, if it's a property of the board
+	// required: false
+	PropertyName string `json:"propertyName,omitempty"`
+
+	// Value before the change, null if it wasn't set
+	// required: false
+	OldValue interface{} `json:"oldValue"`
+
+	// Value after the change, null if it was removed
+	// required: false
+	NewValue interface{} `json:"newValue"`
+}
+
+// CardActivity is an entry of the activity of a card, a version of the
+// card or of one of its children
+// swagger:model
+type CardActivity struct {
+	// ID of the changed block, the card or one of its children
+	// required: true
+	BlockID string `json:"blockId"`
+
+	// Type of the changed block, such as "card" or "comment"
+	// required: true
+	BlockType string `json:"blockType"`
+
+	// What happened to the block, "created", "updated" or "deleted"
+	// required: true
+	Action string `json:"action"`
+
+	// ID of the user who made the change
+	// required: true
+	ModifiedBy string `json:"modifiedBy"`
+
+	// Time of the change, in milliseconds since the epoch
+	// required: true
+	UpdateAt int64 `json:"updateAt"`
+
+	// Changed fields of the block, empty for deletions
+	// required: true
+	Changes []BlockChange `json:"changes"`
+}
+
+// CardActivityPage is a page of the activity of a card, the most
+// recent first
+// swagger:model
+type CardActivityPage struct {
+	// The activity in this page
+	// required: true
+	Activity []CardActivity `json:"activity"`
+
+	// Whether there is older activity after this page
+	// required: true
+	HasMore bool `json:"hasMore"`
+
+	// Cursor of the next page, to be passed as the before parameter
+	// required: true
+	Before int64 `json:"before"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// AttachmentBlockType is the type of the content blocks of the cards
+// holding an uploaded file that isn't shown as an image.
+const AttachmentBlockType = "attachment"
+
+// The fields of the attachment blocks, set from the uploaded file.
+const (
+	AttachmentFileIDField   = "fileId"
+	AttachmentFilenameField = "filename"
+	AttachmentSizeField     = "size"
+	AttachmentMimeTypeField = "mimeType"
+)
@@ -0,0 +1,88 @@
+package model
+
+const (
+	AuditActionDeleteBlock                = "deleteBlock"
+	AuditActionUpsertSharing              = "upsertSharing"
+	AuditActionRegenerateSharingToken     = "regenerateSharingToken"
+	AuditActionRevokeSharingToken         = "revokeSharingToken"
+	AuditActionCreateCalendarFeed         = "createCalendarFeed"
+	AuditActionRevokeCalendarFeed         = "revokeCalendarFeed"
+	AuditActionCreateInboundHook          = "createInboundHook"
+	AuditActionRevokeInboundHook          = "revokeInboundHook"
+	AuditActionConfigureGitHubIntegration = "configureGitHubIntegration"
+	AuditActionDeleteGitHubIntegration    = "deleteGitHubIntegration"
+	AuditActionCreateBoardEmbed           = "createBoardEmbed"
+	AuditActionPatchWorkspaceSettings     = "patchWorkspaceSettings"
+	AuditActionSetDefaultCardTemplate     = "setDefaultCardTemplate"
+	AuditActionLogin                      = "login"
+	AuditActionLoginFailed                = "loginFailed"
+	AuditActionLoginLocked                = "loginLocked"
+	AuditActionLogout                     = "logout"
+	AuditActionRequestPasswordReset       = "requestPasswordReset"
+	AuditActionResetPassword              = "resetPassword"
+	AuditActionCreateAccessToken          = "createAccessToken"
+	AuditActionRevokeAccessToken          = "revokeAccessToken"
+	AuditActionActivateMfa                = "activateMfa"
+	AuditActionDeactivateMfa              = "deactivateMfa"
+	AuditActionDeactivateUser             = "deactivateUser"
+	AuditActionActivateUser               = "activateUser"
+	AuditActionAdminResetPassword         = "adminResetPassword"
+	AuditActionUpsertBoardMember          = "upsertBoardMember"
+	AuditActionDeleteBoardMember          = "deleteBoardMember"
+	AuditActionCreateGuestInvite          = "createGuestInvite"
+	AuditActionRegisterGuest              = "registerGuest"
+	AuditActionProvisionUser              = "provisionUser"
+	AuditActionLinkSingleSignOn           = "linkSingleSignOn"
+	AuditActionLinkLDAP                   = "linkLDAP"
+	AuditActionUpdateAvatar               = "updateAvatar"
+	AuditActionNotifyInactiveWorkspace    = "notifyInactiveWorkspace"
+	AuditActionResetWorkspaceRetention    = "resetWorkspaceRetention"
+	AuditActionArchiveInactiveWorkspace   = "archiveInactiveWorkspace"
+	AuditActionDeleteInactiveWorkspace    = "deleteInactiveWorkspace"
+)
+
+// AuditEntry records a destructive or authentication event
+// swagger:model
+type AuditEntry struct {
+	// ID of the entry
+	// required: true
+	ID string `json:"id"`
+
+	// ID of the user who did the action, empty for the failed logins of
+	// unknown users
+	// required: false
+	ActorID string `json:"actorId"`
+
+	// ID of the workspace of the entity, empty for the authentication
+	// events
+	// required: false
+	WorkspaceID string `json:"workspaceId"`
+
+	// The action, e.g. deleteBlock or login
+	// required: true
+	Action string `json:"action"`
+
+	// ID of the entity of the action, e.g. the deleted block
+	// required: false
+	EntityID string `json:"entityId"`
+
+	// Details of the action
+	// required: false
+	Payload map[string]interface{} `json:"payload"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
+
+// QueryAuditEntriesOptions are the filters of the audit entries. The
+// time range is in milliseconds, and unbounded for zero values.
+type QueryAuditEntriesOptions struct {
+	ActorID     string
+	WorkspaceID string
+	Action      string
+	Since       int64
+	Until       int64
+	Page        int
+	PerPage     int
+}
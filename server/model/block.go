@@ -2,9 +2,14 @@ package model
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 )
 
+// ErrBlockVersionConflict is returned when a block patch expects another
+// version of the block than the stored one.
+var ErrBlockVersionConflict = errors.New("the block was modified since the expected version")
+
 // Block is the basic data unit
 // swagger:model
 type Block struct {
@@ -55,6 +60,10 @@ type Block struct {
 	// The deleted time. Set to indicate this block is deleted
 	// required: false
 	DeleteAt int64 `json:"deleteAt"`
+
+	// The version of this block, incremented by each of its updates
+	// required: false
+	Version int64 `json:"version"`
 }
 
 // BlockPatch is a patch for modify blocks
@@ -87,6 +96,168 @@ type BlockPatch struct {
 	// The block removed fields
 	// required: false
 	DeletedFields []string `json:"deletedFields"`
+
+	// The updated card properties, merged into the properties field so
+	// that the other properties are kept
+	// required: false
+	UpdatedProperties map[string]interface{} `json:"updatedProperties,omitempty"`
+
+	// The removed card properties
+	// required: false
+	DeletedProperties []string `json:"deletedProperties,omitempty"`
+
+	// ExpectedVersion is the version the block must have for the patch to
+	// be applied, from the If-Match header of the request
+	ExpectedVersion *int64 `json:"-"`
+}
+
+// BlocksPatchMaxBlocks is the most blocks patched by one BlocksPatch.
+const BlocksPatchMaxBlocks = 500
+
+// BoardArchivedField is the field of the boards telling if they're
+// archived: hidden from the board listings, and read-only until they're
+// unarchived.
+const BoardArchivedField = "isArchived"
+
+// IsUnarchivePatch tells if the patch only unarchives a board, by
+// setting its archived field to false or by deleting it.
+func (p *BlockPatch) IsUnarchivePatch() bool {
+	if p.ParentID != nil || p.RootID != nil || p.Schema != nil || p.Type != nil || p.Title != nil ||
+		len(p.UpdatedProperties) > 0 || len(p.DeletedProperties) > 0 {
+		return false
+	}
+
+	switch {
+	case len(p.UpdatedFields) == 1 && len(p.DeletedFields) == 0:
+		archived, ok := p.UpdatedFields[BoardArchivedField].(bool)
+		return ok && !archived
+	case len(p.UpdatedFields) == 0 && len(p.DeletedFields) == 1:
+		return p.DeletedFields[0] == BoardArchivedField
+	default:
+		return false
+	}
+}
+
+// BlocksPatch is a patch applied to several cards at once
+// swagger:model
+type BlocksPatch struct {
+	// IDs of the cards to patch
+	// required: true
+	BlockIDs []string `json:"blockIds"`
+
+	// The patch applied to each card
+	// required: true
+	Patch BlockPatch `json:"patch"`
+}
+
+// BlocksUpsertResult lists the blocks created and updated by an insert
+// swagger:model
+type BlocksUpsertResult struct {
+	// IDs of the blocks that didn't exist and were created
+	// required: true
+	Inserted []string `json:"inserted"`
+
+	// IDs of the blocks that already existed and were updated
+	// required: true
+	Updated []string `json:"updated"`
+}
+
+// BlockPatchResult is the block before and after a patch, so that the
+// patch can be undone
+// swagger:model
+type BlockPatchResult struct {
+	// The block before the patch
+	// required: true
+	Before *Block `json:"before"`
+
+	// The block after the patch
+	// required: true
+	After *Block `json:"after"`
+}
+
+// BlockSearchResult is a block matching a search query
+// swagger:model
+type BlockSearchResult struct {
+	Block
+
+	// ID of the board that contains the block
+	// required: true
+	BoardID string `json:"boardId"`
+}
+
+// CardMetadata is the activity summary of a card
+// swagger:model
+type CardMetadata struct {
+	// ID of the card
+	// required: true
+	CardID string `json:"cardId"`
+
+	// Number of comments of the card
+	// required: true
+	CommentCount int64 `json:"commentCount"`
+
+	// Last time a content block or comment of the card was updated,
+	// zero if the card has no content
+	// required: true
+	LastContentUpdateAt int64 `json:"lastContentUpdateAt"`
+
+	// ID of the user who last modified the content of the card
+	// required: false
+	LastContentModifiedBy string `json:"lastContentModifiedBy,omitempty"`
+
+	// Number of checked checkboxes of the card
+	// required: true
+	CheckedCount int64 `json:"checkedCount"`
+
+	// Number of checkboxes of the card
+	// required: true
+	CheckboxCount int64 `json:"checkboxCount"`
+
+	// Values of the computed properties of the card, by property ID,
+	// omitted when a value can't be computed
+	// required: false
+	ComputedValues map[string]float64 `json:"computedValues,omitempty"`
+}
+
+// ChecklistProgress is the number of checked checkboxes of a card, out
+// of all its checkboxes
+// swagger:model
+type ChecklistProgress struct {
+	// ID of the card
+	// required: true
+	CardID string `json:"cardId"`
+
+	// Number of checked checkboxes
+	// required: true
+	Checked int64 `json:"checked"`
+
+	// Number of checkboxes
+	// required: true
+	Total int64 `json:"total"`
+}
+
+// QueryBlockHistoryOptions are the query options that can be used to
+// filter the history of a block.
+type QueryBlockHistoryOptions struct {
+	Limit      uint64 // if non-zero then limit the number of returned records
+	Descending bool   // if true then the records are sorted by insert_at in descending order
+	Before     int64  // if non-zero then only the versions updated before this time
+}
+
+// QueryBlocksDigestOptions are the filters of the blocks summarized by
+// a digest, the same as the filters of the blocks API.
+type QueryBlocksDigestOptions struct {
+	ParentID  string // the parent of the blocks, when BlockType is empty an empty ParentID means the root blocks
+	BlockType string // if not empty then only the blocks of this type
+	All       bool   // if true then all the blocks, ignoring ParentID and BlockType
+}
+
+// BlocksDigest summarizes a set of blocks. It changes whenever one of
+// the blocks is inserted, updated or deleted, so it is used to tell if
+// a client already has the latest blocks.
+type BlocksDigest struct {
+	Count    int64
+	UpdateAt int64
 }
 
 // Archive is an import / export archive.
@@ -102,6 +273,15 @@ func BlocksFromJSON(data io.Reader) []Block {
 	return blocks
 }
 
+// BoardID returns the ID of the board of the block, which is its root,
+// or the block itself when it's a root.
+func (b Block) BoardID() string {
+	if b.RootID != "" {
+		return b.RootID
+	}
+	return b.ID
+}
+
 // LogClone implements the `mlog.LogCloner` interface to provide a subset of Block fields for logging.
 func (b Block) LogClone() interface{} {
 	return struct {
@@ -139,6 +319,9 @@ func (p *BlockPatch) Patch(block *Block) *Block {
 		block.Title = *p.Title
 	}
 
+	if block.Fields == nil && len(p.UpdatedFields) > 0 {
+		block.Fields = map[string]interface{}{}
+	}
 	for key, field := range p.UpdatedFields {
 		block.Fields[key] = field
 	}
@@ -147,5 +330,27 @@ func (p *BlockPatch) Patch(block *Block) *Block {
 		delete(block.Fields, key)
 	}
 
+	if len(p.UpdatedProperties) > 0 || len(p.DeletedProperties) > 0 {
+		if block.Fields == nil {
+			block.Fields = map[string]interface{}{}
+		}
+
+		// the properties are copied, as the map may be shared with
+		// another version of the block
+		properties := map[string]interface{}{}
+		if existing, ok := block.Fields["properties"].(map[string]interface{}); ok {
+			for key, value := range existing {
+				properties[key] = value
+			}
+		}
+		for key, value := range p.UpdatedProperties {
+			properties[key] = value
+		}
+		for _, key := range p.DeletedProperties {
+			delete(properties, key)
+		}
+		block.Fields["properties"] = properties
+	}
+
 	return block
 }
@@ -0,0 +1,91 @@
+package model
+
+// BlockType is the rules of the blocks of a type.
+type BlockType struct {
+	// ParentTypes are the types the parent of the blocks can have. The
+	// blocks without parent types are roots, without a parent
+	ParentTypes []string
+
+	// ChildTypes are the types of the blocks the blocks can be the parent
+	// of
+	ChildTypes []string
+
+	// RequiredFields are the fields the blocks must have
+	RequiredFields []string
+}
+
+// The rules of the block types, named by the errors of the blocks that
+// violate them.
+const (
+	BlockRuleKnownType     = "knownType"
+	BlockRuleRoot          = "root"
+	BlockRuleParentType    = "parentType"
+	BlockRuleChildType     = "childType"
+	BlockRuleRequiredField = "requiredField"
+)
+
+// cardContentTypes are the types of the content blocks of the cards.
+var cardContentTypes = []string{"text", "image", "divider", "checkbox", CodeBlockType, AttachmentBlockType}
+
+// BlockTypes are the known block types. A new block type is added with
+// its rules, the blocks of the other types being rejected unless the
+// experimental block types are enabled.
+var BlockTypes = map[string]BlockType{
+	"board": {
+		ChildTypes: []string{"view", "card", RecurrenceBlockType},
+	},
+	"view": {
+		ParentTypes: []string{"board"},
+	},
+	RecurrenceBlockType: {
+		ParentTypes: []string{"board"},
+	},
+	"card": {
+		ParentTypes: []string{"board"},
+		ChildTypes:  append([]string{"comment"}, cardContentTypes...),
+	},
+	"comment": {
+		ParentTypes: []string{"card"},
+	},
+	"text": {
+		ParentTypes: []string{"card"},
+	},
+	"image": {
+		ParentTypes:    []string{"card"},
+		RequiredFields: []string{"fileId"},
+	},
+	"divider": {
+		ParentTypes: []string{"card"},
+	},
+	"checkbox": {
+		ParentTypes: []string{"card"},
+	},
+	CodeBlockType: {
+		ParentTypes: []string{"card"},
+	},
+	AttachmentBlockType: {
+		ParentTypes:    []string{"card"},
+		RequiredFields: []string{AttachmentFileIDField},
+	},
+}
+
+// IsChildType tells if the blocks of the type can be the parent of the
+// blocks of the child type.
+func (t BlockType) IsChildType(childType string) bool {
+	return containsString(t.ChildTypes, childType)
+}
+
+// IsParentType tells if the blocks of the type can be the children of
+// the blocks of the parent type.
+func (t BlockType) IsParentType(parentType string) bool {
+	return containsString(t.ParentTypes, parentType)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// The sizes of the pages of the blocks API.
+const (
+	BlocksDefaultPageSize = 500
+	BlocksMaxPageSize     = 5000
+)
+
+var errInvalidBlocksCursor = errors.New("invalid blocks cursor")
+
+// BlocksCursor is the position of a block in the order of the pages of
+// blocks, the time it was first inserted, as formatted by the database,
+// then its ID. Updating a block doesn't change its position, and the
+// blocks inserted while paging come after the ones already inserted, so
+// the pages neither skip nor repeat blocks.
+type BlocksCursor struct {
+	InsertAt string
+	ID       string
+}
+
+// String returns the opaque form of the cursor exchanged with the
+// clients.
+func (c BlocksCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.InsertAt + "|" + c.ID))
+}
+
+// ParseBlocksCursor parses the opaque form of a cursor.
+func ParseBlocksCursor(cursor string) (*BlocksCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errInvalidBlocksCursor
+	}
+	parts := strings.SplitN(string(data), "|", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errInvalidBlocksCursor
+	}
+	return &BlocksCursor{InsertAt: parts[0], ID: parts[1]}, nil
+}
+
+// QueryBlocksPageOptions are the filters of a page of blocks, the same
+// as the filters of the blocks API.
+type QueryBlocksPageOptions struct {
+	ParentID  string        // the parent of the blocks, when BlockType is empty an empty ParentID means the root blocks
+	BlockType string        // if not empty then only the blocks of this type
+	All       bool          // if true then all the blocks, ignoring ParentID and BlockType
+	Limit     int           // the maximum number of blocks of the page
+	After     *BlocksCursor // if not nil then only the blocks after the cursor
+}
+
+// BlocksPage is a page of blocks, in the order they were inserted
+// swagger:model
+type BlocksPage struct {
+	// The blocks in this page
+	// required: true
+	Blocks []Block `json:"blocks"`
+
+	// Cursor of the next page, to be passed as the after parameter,
+	// empty for the last page
+	// required: true
+	NextCursor string `json:"nextCursor"`
+}
@@ -0,0 +1,54 @@
+package model
+
+// The roles of the members of a board. Each role grants the permissions
+// of the previous ones.
+const (
+	// BoardRoleViewer can read the blocks of the board
+	BoardRoleViewer = "viewer"
+
+	// BoardRoleEditor can also change the blocks of the board
+	BoardRoleEditor = "editor"
+
+	// BoardRoleAdmin can also manage the members of the board
+	BoardRoleAdmin = "admin"
+)
+
+var boardRoleRanks = map[string]int{
+	BoardRoleViewer: 1,
+	BoardRoleEditor: 2,
+	BoardRoleAdmin:  3,
+}
+
+// IsValidBoardRole tells if the role is one of the board roles.
+func IsValidBoardRole(role string) bool {
+	_, ok := boardRoleRanks[role]
+	return ok
+}
+
+// BoardRoleAllows tells if the role grants the permissions of the
+// required role.
+func BoardRoleAllows(role, required string) bool {
+	return IsValidBoardRole(role) && boardRoleRanks[role] >= boardRoleRanks[required]
+}
+
+// BoardMember is a user with a role on a board. A board with members is
+// restricted to them, the boards without members are open to the users
+// of their workspace.
+// swagger:model
+type BoardMember struct {
+	// ID of the board
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// ID of the user
+	// required: true
+	UserID string `json:"userId"`
+
+	// Role of the user on the board: viewer, editor or admin
+	// required: true
+	Role string `json:"role"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
@@ -0,0 +1,99 @@
+package model
+
+const (
+	// BoardStatisticsWeeks is the number of weeks, including the current
+	// one, of the throughput of the board statistics
+	BoardStatisticsWeeks = 12
+
+	// BoardStatisticsWeekMillis is the duration of a week of the
+	// throughput, in milliseconds
+	BoardStatisticsWeekMillis = 7 * 24 * 60 * 60 * 1000
+)
+
+// BoardStatisticsOptions are the properties the statistics of a board
+// are computed for.
+type BoardStatisticsOptions struct {
+	// SelectPropertyID is the select property the cards are grouped
+	// by, defaults to the first select property of the board
+	SelectPropertyID string
+
+	// PersonPropertyID is the person property the cards are grouped
+	// by, defaults to the first person property of the board
+	PersonPropertyID string
+
+	// DoneOptionID is the option of the select property of the
+	// completed cards. No card is completed if it's empty
+	DoneOptionID string
+}
+
+// BoardStatistics are the card counts of a board, to be charted
+// swagger:model
+type BoardStatistics struct {
+	// ID of the board
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// Number of cards of the board, not including the card templates
+	// required: true
+	CardCount int64 `json:"cardCount"`
+
+	// ID of the select property the cards are grouped by
+	// required: false
+	SelectPropertyID string `json:"selectPropertyId,omitempty"`
+
+	// Number of cards by option of the select property, the most
+	// common first. The cards without an option have an empty value
+	// required: true
+	ByOption []PropertyValueCount `json:"byOption"`
+
+	// ID of the person property the cards are grouped by
+	// required: false
+	PersonPropertyID string `json:"personPropertyId,omitempty"`
+
+	// Number of cards by user of the person property, the most common
+	// first. The unassigned cards have an empty value
+	// required: true
+	ByPerson []PropertyValueCount `json:"byPerson"`
+
+	// ID of the option of the completed cards
+	// required: false
+	DoneOptionID string `json:"doneOptionId,omitempty"`
+
+	// Number of cards created and completed by week, the oldest first
+	// required: true
+	Throughput []WeeklyThroughput `json:"throughput"`
+}
+
+// PropertyValueCount is the number of cards with a value of a property
+// swagger:model
+type PropertyValueCount struct {
+	// Value of the property, the ID of the option or user
+	// required: true
+	Value string `json:"value"`
+
+	// Name of the option, for select properties
+	// required: false
+	Name string `json:"name,omitempty"`
+
+	// Number of cards with the value
+	// required: true
+	Count int64 `json:"count"`
+}
+
+// WeeklyThroughput is the number of cards created and completed in a
+// week
+// swagger:model
+type WeeklyThroughput struct {
+	// Start of the week, in milliseconds since the epoch
+	// required: true
+	WeekStart int64 `json:"weekStart"`
+
+	// Number of cards created in the week
+	// required: true
+	Created int64 `json:"created"`
+
+	// Number of cards moved to the done option for the first time in
+	// the week
+	// required: true
+	Completed int64 `json:"completed"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import "encoding/json"
+
+// CardNumberField is the field of the cards holding their number, which
+// is unique within their board and increases with each new card.
+const CardNumberField = "number"
+
+// CardNumber returns the number of the card, and false if it has none.
+func CardNumber(block Block) (int64, bool) {
+	var number int64
+	switch v := block.Fields[CardNumberField].(type) {
+	case float64:
+		number = int64(v)
+		if float64(number) != v {
+			return 0, false
+		}
+	case int64:
+		number = v
+	case int:
+		number = int64(v)
+	case json.Number:
+		var err error
+		if number, err = v.Int64(); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	return number, number > 0
+}
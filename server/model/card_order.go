@@ -0,0 +1,26 @@
+package model
+
+// CardOrderField is the field of the cards with their fractional order
+// key. The cards of a board are sorted by their keys, and a card is moved
+// by giving it a key between the keys of its new neighbors, so that the
+// concurrent moves of different cards don't overwrite each other.
+const CardOrderField = "order"
+
+// ViewCardOrderField is the legacy field of the views with the IDs of
+// their cards in order, still written along with the order keys for the
+// older clients.
+const ViewCardOrderField = "cardOrder"
+
+// CardMove is the new position of a card among the cards of its board
+// swagger:model
+type CardMove struct {
+	// ID of the card that comes right before the moved card, empty to
+	// move the card first, or last if AfterID is empty too
+	// required: false
+	BeforeID string `json:"beforeId"`
+
+	// ID of the card that comes right after the moved card, empty to
+	// move the card right after BeforeID
+	// required: false
+	AfterID string `json:"afterId"`
+}
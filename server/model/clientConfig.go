@@ -1,6 +1,27 @@
 package model
 
+// ClientConfig is the configuration of the server needed by the clients
+// swagger:model
 type ClientConfig struct {
-	Telemetry   bool   `json:"telemetry"`
+	// Whether the telemetry is enabled
+	// required: true
+	Telemetry bool `json:"telemetry"`
+
+	// ID of the server in the telemetry
+	// required: true
 	TelemetryID string `json:"telemetryid"`
+
+	// Maximum size of the uploaded files, in bytes
+	// required: true
+	MaxFileSize int64 `json:"maxFileSize"`
+
+	// Whether the users can log in with the single sign-on, at
+	// /api/v1/oauth/login
+	// required: true
+	OIDCLogin bool `json:"oidcLogin"`
+
+	// Whether the users can log in with their password, which they can't
+	// once the single sign-on is enforced
+	// required: true
+	PasswordLogin bool `json:"passwordLogin"`
 }
@@ -0,0 +1,76 @@
+package model
+
+import "strings"
+
+// CodeBlockType is the type of the content blocks of the cards holding
+// code, whose title is the code.
+const CodeBlockType = "code"
+
+// The fields of the code blocks.
+const (
+	// CodeLanguageField is the language of the code, for its highlighting
+	CodeLanguageField = "language"
+
+	// CodeWrapField tells if the long lines of the code are wrapped
+	CodeWrapField = "wrap"
+)
+
+// CodeLanguageMaxLength is the length of the longest language of a code
+// block.
+const CodeLanguageMaxLength = 64
+
+// CodeLanguages are the languages highlighted by the clients, by the
+// names they are also known by, e.g. in the info strings of the fenced
+// code blocks of markdown.
+var CodeLanguages = map[string][]string{
+	"bash":       {"sh", "shell", "zsh"},
+	"c":          {"h"},
+	"cpp":        {"c++", "cc", "hpp"},
+	"csharp":     {"c#", "cs"},
+	"css":        {},
+	"diff":       {"patch"},
+	"dockerfile": {"docker"},
+	"go":         {"golang"},
+	"graphql":    {"gql"},
+	"html":       {"htm", "xhtml"},
+	"java":       {},
+	"javascript": {"js", "jsx", "node"},
+	"json":       {},
+	"kotlin":     {"kt"},
+	"markdown":   {"md"},
+	"objectivec": {"objective-c", "objc"},
+	"php":        {},
+	"plaintext":  {"text", "txt", "plain"},
+	"powershell": {"ps", "ps1"},
+	"python":     {"py"},
+	"ruby":       {"rb"},
+	"rust":       {"rs"},
+	"scala":      {},
+	"sql":        {},
+	"swift":      {},
+	"typescript": {"ts", "tsx"},
+	"xml":        {"svg"},
+	"yaml":       {"yml"},
+}
+
+var codeLanguageAliases = func() map[string]string {
+	aliases := map[string]string{}
+	for language, names := range CodeLanguages {
+		aliases[language] = language
+		for _, name := range names {
+			aliases[name] = language
+		}
+	}
+	return aliases
+}()
+
+// NormalizeCodeLanguage returns the language of CodeLanguages known by
+// the name, case insensitively, or the name in lowercase if the language
+// isn't known.
+func NormalizeCodeLanguage(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if language, ok := codeLanguageAliases[name]; ok {
+		return language
+	}
+	return name
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// PropertyTypeComputed is the type of the card properties whose value is
+// computed by the server from their definition, rather than stored in
+// the cards.
+const PropertyTypeComputed = "computed"
+
+// The aggregations of the computed properties.
+const (
+	ComputedAggregationSum       = "sum"
+	ComputedAggregationAverage   = "avg"
+	ComputedAggregationMin       = "min"
+	ComputedAggregationMax       = "max"
+	ComputedAggregationCount     = "count"
+	ComputedAggregationDaysSince = "daysSince"
+)
+
+// The sources of the computed properties that are fields of the cards,
+// rather than card properties.
+const (
+	ComputedSourceCreateAt = "createAt"
+	ComputedSourceUpdateAt = "updateAt"
+)
+
+// ComputedProperty is the definition of a computed card property, stored
+// in the "computed" field of the property in the cardProperties of the
+// board. The aggregation applies to the source property of the cards
+// linked by the relation property, or of the card itself without one.
+// swagger:model
+type ComputedProperty struct {
+	// ID of the relation property linking to the aggregated cards, empty
+	// to aggregate the card itself
+	// required: false
+	RelationPropertyID string `json:"relationPropertyId,omitempty"`
+
+	// ID of the aggregated property, or createAt or updateAt. Optional
+	// for the count aggregation
+	// required: false
+	SourcePropertyID string `json:"sourcePropertyId,omitempty"`
+
+	// Aggregation of the values: sum, avg, min, max, count or daysSince
+	// required: true
+	Aggregation string `json:"aggregation"`
+
+	// Only aggregate the cards whose property has the value
+	// required: false
+	Filter *ComputedPropertyFilter `json:"filter,omitempty"`
+}
+
+// ComputedPropertyFilter restricts the cards aggregated by a computed
+// property to those whose property has the value, or contains it for
+// the properties with several values.
+// swagger:model
+type ComputedPropertyFilter struct {
+	// ID of the property
+	// required: true
+	PropertyID string `json:"propertyId"`
+
+	// Value of the property
+	// required: true
+	Value string `json:"value"`
+}
+
+// ComputedPropertyFromTemplate reads the definition of a computed
+// property from its template in the cardProperties of a board.
+func ComputedPropertyFromTemplate(template map[string]interface{}) (*ComputedProperty, error) {
+	data, err := json.Marshal(template["computed"])
+	if err != nil {
+		return nil, err
+	}
+
+	var property ComputedProperty
+	if err := json.Unmarshal(data, &property); err != nil {
+		return nil, errors.New("invalid computed property definition")
+	}
+	if err := property.IsValid(); err != nil {
+		return nil, err
+	}
+	return &property, nil
+}
+
+// IsValid checks that the aggregation of the computed property is known
+// and has the source it needs.
+func (p ComputedProperty) IsValid() error {
+	switch p.Aggregation {
+	case ComputedAggregationSum, ComputedAggregationAverage, ComputedAggregationMin, ComputedAggregationMax:
+		if p.SourcePropertyID == "" {
+			return fmt.Errorf("the %s aggregation needs a source property", p.Aggregation)
+		}
+	case ComputedAggregationCount:
+	case ComputedAggregationDaysSince:
+		if p.SourcePropertyID == "" {
+			return errors.New("the daysSince aggregation needs a source property")
+		}
+		if p.RelationPropertyID != "" {
+			return errors.New("the daysSince aggregation only applies to the card itself")
+		}
+	default:
+		return fmt.Errorf("unknown aggregation %q", p.Aggregation)
+	}
+
+	if p.Filter != nil && p.Filter.PropertyID == "" {
+		return errors.New("the filter needs a property")
+	}
+	return nil
+}
+
+// References returns the IDs of the properties the computed property
+// reads from the aggregated cards.
+func (p ComputedProperty) References() []string {
+	references := []string{}
+	if p.SourcePropertyID != "" {
+		references = append(references, p.SourcePropertyID)
+	}
+	if p.Filter != nil {
+		references = append(references, p.Filter.PropertyID)
+	}
+	return references
+}
@@ -0,0 +1,39 @@
+package model
+
+// UserPropDigestFrequency is the prop of the users with how often they
+// receive the email digest of the boards they watch.
+const UserPropDigestFrequency = "focalboard_digestFrequency"
+
+const (
+	DigestFrequencyDaily  = "daily"
+	DigestFrequencyWeekly = "weekly"
+	DigestFrequencyNever  = "never"
+)
+
+// IsValidDigestFrequency returns true if the frequency is daily, weekly
+// or never.
+func IsValidDigestFrequency(frequency string) bool {
+	switch frequency {
+	case DigestFrequencyDaily, DigestFrequencyWeekly, DigestFrequencyNever:
+		return true
+	}
+	return false
+}
+
+// DigestFrequency returns how often the user receives the digest, daily
+// unless they chose otherwise.
+func (u *User) DigestFrequency() string {
+	frequency, _ := u.Props[UserPropDigestFrequency].(string)
+	if !IsValidDigestFrequency(frequency) {
+		return DigestFrequencyDaily
+	}
+	return frequency
+}
+
+// DigestSettings are the settings of the email digest of a user
+// swagger:model
+type DigestSettings struct {
+	// How often the digest is sent, daily, weekly or never
+	// required: true
+	Frequency string `json:"frequency"`
+}
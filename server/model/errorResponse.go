@@ -1,13 +1,76 @@
 package model
 
+// The codes of the error responses.
+const (
+	// ErrorCodeBadRequest is returned for a malformed or invalid request.
+	ErrorCodeBadRequest = "bad_request"
+
+	// ErrorCodeUnauthorized is returned when the request isn't
+	// authenticated, or the authentication method isn't permitted.
+	ErrorCodeUnauthorized = "unauthorized"
+
+	// ErrorCodeForbidden is returned when the user doesn't have access to
+	// the entity.
+	ErrorCodeForbidden = "forbidden"
+
+	// ErrorCodeNotFound is returned when the entity doesn't exist.
+	ErrorCodeNotFound = "not_found"
+
+	// ErrorCodeConflict is returned when the entity is in a state that
+	// doesn't allow the request, like a block locked by another user.
+	ErrorCodeConflict = "conflict"
+
+	// ErrorCodeInvalidBlock is returned when a block of the request is
+	// invalid. The ID of the block is in the blockId detail.
+	ErrorCodeInvalidBlock = "invalid_block"
+
+	// ErrorCodeWorkspaceMismatch is returned when the workspace of the
+	// request isn't one of the workspaces of the server, or when a block
+	// of the request references a block of another workspace.
+	ErrorCodeWorkspaceMismatch = "workspace_mismatch"
+
+	// ErrorCodeRateLimited is returned when the client sent too many
+	// requests or login attempts. The Retry-After header has the seconds
+	// to wait for.
+	ErrorCodeRateLimited = "rate_limited"
+
+	// ErrorCodePayloadTooLarge is returned when the body of the request,
+	// like an uploaded file, is too large.
+	ErrorCodePayloadTooLarge = "payload_too_large"
+
+	// ErrorCodeQuotaExceeded is returned when the request would exceed the
+	// block or file storage quota of the workspace.
+	ErrorCodeQuotaExceeded = "quota_exceeded"
+
+	// ErrorCodeNotImplemented is returned when the feature isn't
+	// configured on the server.
+	ErrorCodeNotImplemented = "not_implemented"
+
+	// ErrorCodeInternal is returned for an unexpected server error.
+	ErrorCodeInternal = "internal_error"
+)
+
 // ErrorResponse is an error response
 // swagger:model
 type ErrorResponse struct {
+	// The machine-readable code of the error
+	// required: true
+	Code string `json:"code"`
+
 	// The error message
 	// required: false
+	Message string `json:"message"`
+
+	// The details of the error, depending on its code
+	// required: false
+	Details map[string]interface{} `json:"details,omitempty"`
+
+	// The error message, same as message
+	// required: false
 	Error string `json:"error"`
 
-	// The error code
+	// The numeric error code, or the status code for the errors without
+	// one
 	// required: false
 	ErrorCode int `json:"errorCode"`
 }
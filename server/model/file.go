@@ -0,0 +1,48 @@
+package model
+
+// FileCleanupResult is the result of a cleanup of the orphaned files
+// swagger:model
+type FileCleanupResult struct {
+	// Number of files examined
+	// required: true
+	FilesExamined int `json:"filesExamined"`
+
+	// Number of files removed, or that would have been removed by a dry
+	// run
+	// required: true
+	FilesRemoved int `json:"filesRemoved"`
+
+	// Whether the files were left in place
+	// required: true
+	DryRun bool `json:"dryRun"`
+}
+
+// FileInfo records a file uploaded to a workspace, so that the storage
+// used by the workspace is known without listing the files storage.
+type FileInfo struct {
+	ID          string
+	WorkspaceID string
+	RootID      string
+	Size        int64
+	CreateAt    int64
+
+	// Path of the file in the files storage
+	Path string
+
+	// Path of the thumbnail of the image in the files storage, empty
+	// until the thumbnail is generated
+	ThumbnailPath string
+
+	// Name of the uploaded file
+	Name string
+
+	// MIME type of the file, detected from its name and content
+	MimeType string
+}
+
+// WorkspaceUsage is the number of blocks of a workspace that aren't
+// deleted, and the total size in bytes of its uploaded files.
+type WorkspaceUsage struct {
+	BlockCount int64
+	FileBytes  int64
+}
@@ -0,0 +1,93 @@
+package model
+
+// The names of the card properties of the boards with a GitHub
+// integration, and of their options.
+const (
+	GitHubStatePropertyName  = "State"
+	GitHubLabelsPropertyName = "Labels"
+
+	GitHubStateOpen   = "open"
+	GitHubStateClosed = "closed"
+)
+
+// GitHubIntegration is the GitHub integration of a board, which syncs
+// the issues of a repository into its cards
+// swagger:model
+type GitHubIntegration struct {
+	// ID of the board
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// ID of the workspace of the board
+	// required: true
+	WorkspaceID string `json:"workspaceId"`
+
+	// The repository, as "owner/name"
+	// required: true
+	Repository string `json:"repository"`
+
+	// The GitHub token, stored encrypted
+	TokenEncrypted string `json:"-"`
+
+	// The secret of the GitHub webhook of the repository, only returned
+	// when the integration is configured
+	// required: false
+	WebhookSecret string `json:"webhookSecret,omitempty"`
+
+	// The secret of the webhook, stored encrypted
+	WebhookSecretEncrypted string `json:"-"`
+
+	// Only the issues with all these labels are synced
+	// required: false
+	LabelFilter []string `json:"labelFilter"`
+
+	// Whether closing a card of an open issue comments on the issue
+	// required: true
+	CommentOnClose bool `json:"commentOnClose"`
+
+	// ID of the select property of the state of the issues
+	// required: true
+	StatePropertyID string `json:"statePropertyId"`
+
+	// ID of the multi-select property of the labels of the issues
+	// required: true
+	LabelsPropertyID string `json:"labelsPropertyId"`
+
+	// ID of the user who configured the integration, on whose behalf the
+	// cards are changed
+	// required: true
+	CreatedBy string `json:"createdBy"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+
+	// Time of the last sync, 0 before the first one
+	// required: true
+	LastSyncAt int64 `json:"lastSyncAt"`
+
+	// Time until which the rate limit of GitHub is exhausted
+	// required: false
+	RateLimitResetAt int64 `json:"rateLimitResetAt,omitempty"`
+
+	// Error of the last sync
+	// required: false
+	LastError string `json:"lastError,omitempty"`
+}
+
+// GitHubIssueLink links an issue of the repository of the GitHub
+// integration of a board to its card.
+type GitHubIssueLink struct {
+	BoardID     string
+	IssueNumber int64
+	CardID      string
+
+	// State is the state of the issue at the last sync
+	State string
+
+	// CardClosed tells if the card was closed at the last sync
+	CardClosed bool
+
+	// IssueUpdatedAt is the update time of the issue at the last sync
+	IssueUpdatedAt int64
+}
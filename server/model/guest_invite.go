@@ -0,0 +1,38 @@
+package model
+
+// GuestInvite is a single-use token given to an external user to sign up
+// as a guest, who becomes a member of the board. Only the hash of the
+// token is stored.
+type GuestInvite struct {
+	// SHA-256 hash of the token
+	TokenHash string `json:"-"`
+
+	// ID of the workspace of the board
+	WorkspaceID string `json:"workspaceId"`
+
+	// ID of the board the guest is invited to
+	BoardID string `json:"boardId"`
+
+	// Role of the guest on the board: viewer or editor
+	Role string `json:"role"`
+
+	// ID of the user who created the invite
+	CreatedBy string `json:"createdBy"`
+
+	// Created time in milliseconds
+	CreateAt int64 `json:"createAt"`
+
+	// Expiry time in milliseconds
+	ExpireAt int64 `json:"expireAt"`
+}
+
+// IsExpired returns true if the invite has expired at now.
+func (i GuestInvite) IsExpired(now int64) bool {
+	return i.ExpireAt <= now
+}
+
+// IsValidGuestRole tells if the role can be given to a guest. The guests
+// can't manage the members of a board.
+func IsValidGuestRole(role string) bool {
+	return role == BoardRoleViewer || role == BoardRoleEditor
+}
@@ -0,0 +1,150 @@
+package model
+
+const (
+	// HealthStatusOK is the status of a server whose database is reachable
+	HealthStatusOK = "ok"
+
+	// HealthStatusUnhealthy is the status of a server whose database
+	// check failed
+	HealthStatusUnhealthy = "unhealthy"
+
+	// HealthStatusDraining is the readiness status of a server shutting
+	// down, which doesn't accept new requests
+	HealthStatusDraining = "draining"
+)
+
+// ServerHealth is the health of the server and its database
+// swagger:model
+type ServerHealth struct {
+	// Status of the server, ok or unhealthy
+	// required: true
+	Status string `json:"status"`
+
+	// Health of the database
+	// required: true
+	Database DatabaseHealth `json:"database"`
+
+	// Version of the server
+	// required: true
+	Version string `json:"version"`
+
+	// Build number of the server
+	// required: true
+	BuildNumber string `json:"buildNumber"`
+
+	// Build date of the server
+	// required: true
+	BuildDate string `json:"buildDate"`
+
+	// Build hash of the server
+	// required: true
+	BuildHash string `json:"buildHash"`
+
+	// Edition of the server
+	// required: true
+	Edition string `json:"edition"`
+}
+
+// DatabaseHealth is the result of the database check
+// swagger:model
+type DatabaseHealth struct {
+	// Time taken by the check, in milliseconds
+	// required: true
+	LatencyMs int64 `json:"latencyMs"`
+
+	// Version of the last migration applied to the database
+	// required: true
+	SchemaVersion int64 `json:"schemaVersion"`
+
+	// Error of the check, if it failed, only returned to admins
+	// required: false
+	Error string `json:"error,omitempty"`
+
+	// Statistics of the connection pool, only returned to admins
+	// required: false
+	Pool *DatabasePoolStats `json:"pool,omitempty"`
+}
+
+// DatabasePoolStats are the statistics of the database connection pool
+// swagger:model
+type DatabasePoolStats struct {
+	// Maximum number of open connections, 0 for unlimited
+	// required: true
+	MaxOpenConnections int `json:"maxOpenConnections"`
+
+	// Number of open connections, in use or idle
+	// required: true
+	OpenConnections int `json:"openConnections"`
+
+	// Number of connections in use
+	// required: true
+	InUse int `json:"inUse"`
+
+	// Number of idle connections
+	// required: true
+	Idle int `json:"idle"`
+
+	// Number of times a connection was waited for
+	// required: true
+	WaitCount int64 `json:"waitCount"`
+
+	// Total time waited for connections, in milliseconds
+	// required: true
+	WaitDurationMs int64 `json:"waitDurationMs"`
+}
+
+// Liveness is the liveness of the server, which is alive as long as it
+// answers
+// swagger:model
+type Liveness struct {
+	// Status of the server, always ok
+	// required: true
+	Status string `json:"status"`
+}
+
+// Readiness is the readiness of the server to serve requests, with the
+// status of each of its dependencies
+// swagger:model
+type Readiness struct {
+	// Status of the server, ok, unhealthy or draining
+	// required: true
+	Status string `json:"status"`
+
+	// Whether the server is shutting down
+	// required: true
+	Draining bool `json:"draining"`
+
+	// Status of the dependencies of the server
+	// required: true
+	Dependencies []DependencyHealth `json:"dependencies"`
+}
+
+// DependencyHealth is the result of the check of a dependency of the
+// server
+// swagger:model
+type DependencyHealth struct {
+	// Name of the dependency, database, filestore or migrations
+	// required: true
+	Name string `json:"name"`
+
+	// Status of the dependency, ok or unhealthy
+	// required: true
+	Status string `json:"status"`
+
+	// Time taken by the check, in milliseconds
+	// required: true
+	LatencyMs int64 `json:"latencyMs"`
+}
+
+// DiagnosticCheck is the result of a check of the installation, run by
+// the doctor command
+type DiagnosticCheck struct {
+	// Name of the check
+	Name string `json:"name"`
+
+	// Whether the check passed
+	OK bool `json:"ok"`
+
+	// Details of the result of the check
+	Detail string `json:"detail"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// IdempotencyKeyHeader is the header of the requests creating blocks
+// that can be retried without creating the blocks again.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyKeyMaxLength is the length of the longest idempotency key.
+const IdempotencyKeyMaxLength = 255
+
+// IdempotencyKeyTTL is how long the response of a request with an
+// idempotency key is kept for its retries.
+const IdempotencyKeyTTL = 24 * time.Hour
+
+// IdempotencyKey is the response of a request with an idempotency key,
+// returned again for the retries of the request by the same user.
+type IdempotencyKey struct {
+	// The key of the Idempotency-Key header
+	Key string `json:"key"`
+
+	// ID of the user who sent the request
+	UserID string `json:"userId"`
+
+	// SHA-256 hash of the method, path and body of the request
+	RequestHash string `json:"requestHash"`
+
+	// Status code of the response, 0 while the request is handled
+	StatusCode int `json:"statusCode"`
+
+	// Body of the response
+	Response string `json:"response"`
+
+	// Created time in milliseconds
+	CreateAt int64 `json:"createAt"`
+
+	// Expiry time in milliseconds
+	ExpireAt int64 `json:"expireAt"`
+}
+
+// IsExpired returns true if the key has expired at now.
+func (k IdempotencyKey) IsExpired(now int64) bool {
+	return k.ExpireAt <= now
+}
+
+// IsPending returns true if the request of the key is still handled.
+func (k IdempotencyKey) IsPending() bool {
+	return k.StatusCode == 0
+}
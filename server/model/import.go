@@ -0,0 +1,126 @@
+package model
+
+// ImportedUserID is the ID of the synthetic user the imported comments
+// are attributed to, their text keeping the name of their author.
+const ImportedUserID = "imported"
+
+// ImportSummary is the result of an import
+// swagger:model
+type ImportSummary struct {
+	// Number of boards created
+	// required: true
+	BoardsCreated int `json:"boardsCreated"`
+
+	// Number of cards created
+	// required: true
+	CardsCreated int `json:"cardsCreated"`
+
+	// Number of comments created
+	// required: true
+	CommentsCreated int `json:"commentsCreated"`
+
+	// Items of the import that weren't imported
+	// required: true
+	Skipped []ImportSkippedItem `json:"skipped"`
+}
+
+// ImportSkippedItem is an item that wasn't imported
+// swagger:model
+type ImportSkippedItem struct {
+	// Type of the item, e.g. card or attachment
+	// required: true
+	Type string `json:"type"`
+
+	// ID of the item in the imported data
+	// required: true
+	ID string `json:"id"`
+
+	// Name of the item
+	// required: false
+	Name string `json:"name,omitempty"`
+
+	// Why the item wasn't imported
+	// required: true
+	Reason string `json:"reason"`
+}
+
+// Skip adds an item to the skipped items of the summary.
+func (s *ImportSummary) Skip(itemType, id, name, reason string) {
+	s.Skipped = append(s.Skipped, ImportSkippedItem{
+		Type:   itemType,
+		ID:     id,
+		Name:   name,
+		Reason: reason,
+	})
+}
+
+// JiraImportPreview describes the fields detected in a Jira export,
+// along with a mapping of them to the properties of the imported board
+// swagger:model
+type JiraImportPreview struct {
+	// Format of the export, json or csv
+	// required: true
+	Format string `json:"format"`
+
+	// Number of issues of the export
+	// required: true
+	IssueCount int `json:"issueCount"`
+
+	// The fields with a value in some issues
+	// required: true
+	Fields []JiraField `json:"fields"`
+
+	// The suggested mapping, to edit and post with the import
+	// required: true
+	Mapping JiraImportMapping `json:"mapping"`
+}
+
+// JiraField is a field of the issues of a Jira export
+// swagger:model
+type JiraField struct {
+	// ID of the field, its name for the CSV exports
+	// required: true
+	ID string `json:"id"`
+
+	// Name of the field
+	// required: true
+	Name string `json:"name"`
+
+	// The property type detected from the values
+	// required: true
+	Type string `json:"type"`
+
+	// Some values of the field
+	// required: true
+	Values []string `json:"values"`
+}
+
+// JiraImportMapping maps the fields of the issues of a Jira export to
+// the properties of the imported board
+// swagger:model
+type JiraImportMapping struct {
+	// Title of the board, the one of the project if empty
+	// required: false
+	BoardTitle string `json:"boardTitle"`
+
+	// The fields to import, the other ones are skipped
+	// required: true
+	Fields []JiraFieldMapping `json:"fields"`
+}
+
+// JiraFieldMapping maps a field of the issues to a property of the board
+// swagger:model
+type JiraFieldMapping struct {
+	// ID of the field
+	// required: true
+	FieldID string `json:"fieldId"`
+
+	// Name of the property
+	// required: true
+	PropertyName string `json:"propertyName"`
+
+	// Type of the property: text, number, date, select, multiSelect or
+	// person
+	// required: true
+	PropertyType string `json:"propertyType"`
+}
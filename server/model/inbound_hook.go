@@ -0,0 +1,95 @@
+package model
+
+// InboundHookDeliveriesLimit is the number of deliveries kept per inbound
+// hook, for debugging the integrations.
+const InboundHookDeliveriesLimit = 50
+
+// InboundHook is the inbound webhook of a board, through which the
+// integrations create and update its cards
+// swagger:model
+type InboundHook struct {
+	// ID of the board
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// ID of the workspace of the board
+	// required: true
+	WorkspaceID string `json:"workspaceId"`
+
+	// The token of the hook URL, only returned when the hook is created
+	// required: false
+	Token string `json:"token,omitempty"`
+
+	// SHA-256 hash of the token, the only form in which the token is
+	// stored
+	TokenHash string `json:"-"`
+
+	// Whether unknown option names create new options of the select
+	// properties of the board
+	// required: true
+	AllowNewOptions bool `json:"allowNewOptions"`
+
+	// ID of the user who created the hook, on whose behalf the cards
+	// are changed
+	// required: true
+	CreatedBy string `json:"createdBy"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
+
+// InboundHookPayload is the body posted to the inbound webhook of a
+// board. The properties are keyed by their name, and the option values
+// are the names of the options
+// swagger:model
+type InboundHookPayload struct {
+	// ID of the card to update, a new card is created if empty
+	// required: false
+	CardID string `json:"cardId,omitempty"`
+
+	// Title of the card, required for a new card
+	// required: false
+	Title string `json:"title,omitempty"`
+
+	// Description of the card, stored as its first text block
+	// required: false
+	Description string `json:"description,omitempty"`
+
+	// The property values of the card, keyed by the property names
+	// required: false
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// InboundHookDelivery is a request received by the inbound webhook of
+// a board
+// swagger:model
+type InboundHookDelivery struct {
+	// ID of the delivery
+	// required: true
+	ID string `json:"id"`
+
+	// ID of the board
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// ID of the created or updated card, empty if the delivery failed
+	// required: false
+	CardID string `json:"cardId,omitempty"`
+
+	// The body of the request
+	// required: true
+	Payload string `json:"payload"`
+
+	// HTTP status code of the response
+	// required: true
+	StatusCode int `json:"statusCode"`
+
+	// Error of the delivery
+	// required: false
+	Error string `json:"error,omitempty"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
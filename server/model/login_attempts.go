@@ -0,0 +1,19 @@
+package model
+
+// LoginAttempts records the recent failed logins for a username or a
+// client address, so that brute-force attempts can be locked out.
+type LoginAttempts struct {
+	// Key of the username or the client address
+	Key string `json:"key"`
+
+	// Number of failed logins, each of them within the lockout window of
+	// the previous one
+	Failures int `json:"failures"`
+
+	// Time of the last failed login in milliseconds
+	LastFailureAt int64 `json:"lastFailureAt"`
+
+	// Time in milliseconds until which the logins are rejected, zero if
+	// they aren't locked
+	LockedUntil int64 `json:"lockedUntil"`
+}
@@ -0,0 +1,42 @@
+package model
+
+// Migration is a migration of the database schema
+// swagger:model
+type Migration struct {
+	// Version of the migration
+	// required: true
+	Version int64 `json:"version"`
+
+	// Name of the migration
+	// required: true
+	Name string `json:"name"`
+
+	// Time the migration was applied, in milliseconds since the
+	// epoch, 0 if pending or applied before the migrations were logged
+	// required: false
+	AppliedAt int64 `json:"appliedAt,omitempty"`
+
+	// Time the migration took to apply, in milliseconds
+	// required: false
+	DurationMs int64 `json:"durationMs,omitempty"`
+
+	// SQL of the migration, only returned for the pending ones
+	// required: false
+	SQL string `json:"sql,omitempty"`
+}
+
+// MigrationStatus is the status of the migrations of the database schema
+// swagger:model
+type MigrationStatus struct {
+	// Version of the last migration applied
+	// required: true
+	SchemaVersion int64 `json:"schemaVersion"`
+
+	// Migrations applied, oldest first
+	// required: true
+	Applied []Migration `json:"applied"`
+
+	// Migrations not applied yet, in the order they will be
+	// required: true
+	Pending []Migration `json:"pending"`
+}
@@ -0,0 +1,80 @@
+package model
+
+// NotificationDefaultPageSize is the number of notifications returned
+// when no limit is requested.
+const NotificationDefaultPageSize = 50
+
+const (
+	// NotificationTypeMention is the type of the notifications of a
+	// user mentioned in a card
+	NotificationTypeMention = "mention"
+
+	// NotificationTypeDueDate is the type of the reminders of the cards
+	// assigned to a user that are about to be due
+	NotificationTypeDueDate = "dueDate"
+
+	// NotificationTypeSubscription is the type of the notifications of
+	// the changes of a card or a board that the user watches
+	NotificationTypeSubscription = "subscription"
+
+	// NotificationTypeWorkspaceDeletion is the type of the notices of the
+	// deletion of an inactive workspace sent to its members
+	NotificationTypeWorkspaceDeletion = "workspaceDeletion"
+)
+
+// Notification is a notification of a user being mentioned in a card,
+// of a card assigned to the user being about to be due, of a change of a
+// card or a board that the user watches, or of the deletion of an
+// inactive workspace of the user
+// swagger:model
+type Notification struct {
+	// ID of the notification
+	// required: true
+	ID string `json:"id"`
+
+	// Type of the notification, mention, dueDate, subscription or
+	// workspaceDeletion
+	// required: true
+	Type string `json:"type"`
+
+	// ID of the workspace of the card
+	// required: true
+	WorkspaceID string `json:"workspaceId"`
+
+	// ID of the notified user
+	// required: true
+	UserID string `json:"userId"`
+
+	// ID of the user who wrote the mention or changed the watched block,
+	// empty for reminders
+	// required: false
+	AuthorID string `json:"authorId"`
+
+	// ID of the board of the card, empty for the deletions of workspaces
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// ID of the card, empty for the changes of a watched board outside
+	// of its cards and for the deletions of workspaces
+	// required: true
+	CardID string `json:"cardId"`
+
+	// ID of the text or comment block with the mention, of the card for
+	// reminders, of the changed block for subscriptions, or empty for the
+	// deletions of workspaces
+	// required: true
+	BlockID string `json:"blockId"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
+
+// ReminderSent records a due date reminder sent to a user, so it isn't
+// sent again.
+type ReminderSent struct {
+	CardID   string
+	UserID   string
+	DueAt    int64
+	CreateAt int64
+}
@@ -0,0 +1,22 @@
+package model
+
+// PasswordResetToken is a single-use token emailed to a user to reset
+// their password. Only the hash of the token is stored.
+type PasswordResetToken struct {
+	// SHA-256 hash of the token
+	TokenHash string `json:"-"`
+
+	// ID of the user whose password is reset
+	UserID string `json:"userId"`
+
+	// Created time in milliseconds
+	CreateAt int64 `json:"createAt"`
+
+	// Expiry time in milliseconds
+	ExpireAt int64 `json:"expireAt"`
+}
+
+// IsExpired returns true if the token has expired at now.
+func (t PasswordResetToken) IsExpired(now int64) bool {
+	return t.ExpireAt <= now
+}
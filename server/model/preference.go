@@ -0,0 +1,94 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// PreferenceClientCategoryPrefix is the namespace of the categories
+	// of the preferences set by the clients, the other categories being
+	// reserved for the preferences set by the server.
+	PreferenceClientCategoryPrefix = "client_"
+
+	// PreferenceCategoryMaxLength is the length of the longest category,
+	// which fits in the categories of Mattermost once prefixed in plugin
+	// mode.
+	PreferenceCategoryMaxLength = 21
+
+	PreferenceNameMaxLength  = 32
+	PreferenceValueMaxLength = 2000
+
+	// PreferencesMaxBatchSize is the most preferences set or deleted at
+	// once.
+	PreferencesMaxBatchSize = 100
+)
+
+var preferenceKeyPattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Preference is a setting of a user, like the theme or the tours they
+// dismissed, that follows them across their devices
+// swagger:model
+type Preference struct {
+	// The user ID
+	// required: false
+	UserID string `json:"userId"`
+
+	// The category of the preference, starting with client_ for the
+	// preferences set by the clients
+	// required: true
+	Category string `json:"category"`
+
+	// The name of the preference in its category
+	// required: true
+	Name string `json:"name"`
+
+	// The value of the preference, of at most 2000 bytes
+	// required: false
+	Value string `json:"value"`
+}
+
+// IsValid checks that the category and the name of the preference are
+// lowercase identifiers of the client namespace, and that the value isn't
+// too long.
+func (p Preference) IsValid() error {
+	if !strings.HasPrefix(p.Category, PreferenceClientCategoryPrefix) || len(p.Category) == len(PreferenceClientCategoryPrefix) {
+		return fmt.Errorf("the category %q isn't in the %s namespace", p.Category, PreferenceClientCategoryPrefix)
+	}
+	if len(p.Category) > PreferenceCategoryMaxLength || !preferenceKeyPattern.MatchString(p.Category) {
+		return fmt.Errorf("the category must be at most %d lowercase letters, digits or underscores", PreferenceCategoryMaxLength)
+	}
+	if p.Name == "" || len(p.Name) > PreferenceNameMaxLength || !preferenceKeyPattern.MatchString(p.Name) {
+		return fmt.Errorf("the name must be at most %d lowercase letters, digits or underscores", PreferenceNameMaxLength)
+	}
+	if len(p.Value) > PreferenceValueMaxLength {
+		return fmt.Errorf("the value is longer than %d bytes", PreferenceValueMaxLength)
+	}
+	return nil
+}
+
+// ValidatePreferences checks the preferences of a batch, which mustn't
+// have the same preference twice.
+func ValidatePreferences(preferences []Preference) error {
+	if len(preferences) == 0 {
+		return errors.New("no preferences")
+	}
+	if len(preferences) > PreferencesMaxBatchSize {
+		return fmt.Errorf("at most %d preferences are set at once", PreferencesMaxBatchSize)
+	}
+
+	seen := make(map[[2]string]bool, len(preferences))
+	for _, preference := range preferences {
+		if err := preference.IsValid(); err != nil {
+			return err
+		}
+		key := [2]string{preference.Category, preference.Name}
+		if seen[key] {
+			return fmt.Errorf("the preference %s/%s is repeated", preference.Category, preference.Name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
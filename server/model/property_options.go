@@ -0,0 +1,147 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// The colors of the options of the select and multi-select card
+// properties, in the "color" field of the options.
+const (
+	PropertyOptionColorDefault = "propColorDefault"
+	PropertyOptionColorGray    = "propColorGray"
+	PropertyOptionColorBrown   = "propColorBrown"
+	PropertyOptionColorOrange  = "propColorOrange"
+	PropertyOptionColorYellow  = "propColorYellow"
+	PropertyOptionColorGreen   = "propColorGreen"
+	PropertyOptionColorBlue    = "propColorBlue"
+	PropertyOptionColorPurple  = "propColorPurple"
+	PropertyOptionColorPink    = "propColorPink"
+	PropertyOptionColorRed     = "propColorRed"
+)
+
+// propertyOptionColorPrefix is the prefix of the colors of the options,
+// which the legacy colors may lack.
+const propertyOptionColorPrefix = "propColor"
+
+// PropertyOptionColors are the colors of the options, in the order of
+// the palette of the clients.
+var PropertyOptionColors = []string{
+	PropertyOptionColorDefault,
+	PropertyOptionColorGray,
+	PropertyOptionColorBrown,
+	PropertyOptionColorOrange,
+	PropertyOptionColorYellow,
+	PropertyOptionColorGreen,
+	PropertyOptionColorBlue,
+	PropertyOptionColorPurple,
+	PropertyOptionColorPink,
+	PropertyOptionColorRed,
+}
+
+// PropertyTypes are the types of the card properties.
+var PropertyTypes = []string{
+	"text",
+	"number",
+	"select",
+	"multiSelect",
+	"date",
+	"person",
+	"file",
+	"checkbox",
+	"url",
+	"email",
+	"phone",
+	"createdTime",
+	"createdBy",
+	"updatedTime",
+	"updatedBy",
+	PropertyTypeRelation,
+	PropertyTypeComputed,
+}
+
+// propertyOptionColorNames are the colors of the options by their name
+// in lowercase, with and without the prefix.
+var propertyOptionColorNames = func() map[string]string {
+	names := map[string]string{}
+	for _, color := range PropertyOptionColors {
+		names[strings.ToLower(color)] = color
+		names[strings.ToLower(strings.TrimPrefix(color, propertyOptionColorPrefix))] = color
+	}
+	return names
+}()
+
+// IsValidPropertyOptionColor tells if the color is one of
+// PropertyOptionColors.
+func IsValidPropertyOptionColor(color string) bool {
+	return containsString(PropertyOptionColors, color)
+}
+
+// NormalizePropertyOptionColor returns the color of PropertyOptionColors
+// of a legacy color, known by its name case insensitively and with or
+// without the propColor prefix, the empty color being the default one.
+// It returns false if the color isn't known.
+func NormalizePropertyOptionColor(color string) (string, bool) {
+	name := strings.ToLower(strings.TrimSpace(color))
+	if name == "" {
+		return PropertyOptionColorDefault, true
+	}
+	normalized, ok := propertyOptionColorNames[name]
+	return normalized, ok
+}
+
+// Constants are the enumerations shared by the server and the clients
+// swagger:model
+type Constants struct {
+	// The types of the card properties
+	// required: true
+	PropertyTypes []string `json:"propertyTypes"`
+
+	// The colors of the options of the select and multi-select card
+	// properties, in the order of the palette
+	// required: true
+	PropertyOptionColors []string `json:"propertyOptionColors"`
+
+	// The known block types
+	// required: true
+	BlockTypes []string `json:"blockTypes"`
+
+	// The aggregations of the computed properties
+	// required: true
+	ComputedAggregations []string `json:"computedAggregations"`
+
+	// The languages of the code blocks highlighted by the clients
+	// required: true
+	CodeLanguages []string `json:"codeLanguages"`
+}
+
+// GetConstants returns the enumerations shared by the server and the
+// clients, the unordered ones sorted.
+func GetConstants() *Constants {
+	blockTypes := make([]string, 0, len(BlockTypes))
+	for blockType := range BlockTypes {
+		blockTypes = append(blockTypes, blockType)
+	}
+	sort.Strings(blockTypes)
+
+	codeLanguages := make([]string, 0, len(CodeLanguages))
+	for language := range CodeLanguages {
+		codeLanguages = append(codeLanguages, language)
+	}
+	sort.Strings(codeLanguages)
+
+	return &Constants{
+		PropertyTypes:        append([]string{}, PropertyTypes...),
+		PropertyOptionColors: append([]string{}, PropertyOptionColors...),
+		BlockTypes:           blockTypes,
+		ComputedAggregations: []string{
+			ComputedAggregationSum,
+			ComputedAggregationAverage,
+			ComputedAggregationMin,
+			ComputedAggregationMax,
+			ComputedAggregationCount,
+			ComputedAggregationDaysSince,
+		},
+		CodeLanguages: codeLanguages,
+	}
+}
@@ -0,0 +1,225 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecurrenceBlockType is the type of the blocks of a board that create a
+// card from one of its card templates on a schedule.
+const RecurrenceBlockType = "recurrence"
+
+const (
+	RecurrenceFrequencyWeekly  = "WEEKLY"
+	RecurrenceFrequencyMonthly = "MONTHLY"
+)
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RecurrenceRule is the schedule of a recurrence, parsed from a subset
+// of the iCalendar RRULE syntax: FREQ=WEEKLY with BYDAY, or FREQ=MONTHLY
+// with BYMONTHDAY, and an optional BYHOUR, e.g.
+// "FREQ=WEEKLY;BYDAY=MO,TH;BYHOUR=9". The cards of a monthly rule on a
+// day the month doesn't have are created on its last day.
+type RecurrenceRule struct {
+	Frequency string
+	Weekdays  []time.Weekday
+	MonthDay  int
+	Hour      int
+}
+
+// ParseRecurrenceRule parses the schedule of a recurrence.
+func ParseRecurrenceRule(rule string) (*RecurrenceRule, error) {
+	parts := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"), ";") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		name := strings.ToUpper(strings.TrimSpace(kv[0]))
+		if _, ok := parts[name]; ok {
+			return nil, fmt.Errorf("repeated rule part %s", name)
+		}
+		parts[name] = strings.ToUpper(strings.TrimSpace(kv[1]))
+	}
+
+	r := &RecurrenceRule{Frequency: parts["FREQ"]}
+	if hour, ok := parts["BYHOUR"]; ok {
+		var err error
+		if r.Hour, err = strconv.Atoi(hour); err != nil || r.Hour < 0 || r.Hour > 23 {
+			return nil, fmt.Errorf("invalid BYHOUR %q", hour)
+		}
+	}
+
+	switch r.Frequency {
+	case RecurrenceFrequencyWeekly:
+		if _, ok := parts["BYMONTHDAY"]; ok {
+			return nil, errors.New("BYMONTHDAY isn't supported by weekly rules")
+		}
+		seen := map[time.Weekday]bool{}
+		for _, day := range strings.Split(parts["BYDAY"], ",") {
+			weekday, ok := recurrenceWeekdays[day]
+			if !ok {
+				return nil, fmt.Errorf("invalid BYDAY %q", parts["BYDAY"])
+			}
+			if !seen[weekday] {
+				seen[weekday] = true
+				r.Weekdays = append(r.Weekdays, weekday)
+			}
+		}
+	case RecurrenceFrequencyMonthly:
+		if _, ok := parts["BYDAY"]; ok {
+			return nil, errors.New("BYDAY isn't supported by monthly rules")
+		}
+		var err error
+		if r.MonthDay, err = strconv.Atoi(parts["BYMONTHDAY"]); err != nil || r.MonthDay < 1 || r.MonthDay > 31 {
+			return nil, fmt.Errorf("invalid BYMONTHDAY %q", parts["BYMONTHDAY"])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported FREQ %q", r.Frequency)
+	}
+
+	for name := range parts {
+		switch name {
+		case "FREQ", "BYDAY", "BYMONTHDAY", "BYHOUR":
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", name)
+		}
+	}
+
+	return r, nil
+}
+
+// matches tells if the rule has an occurrence on the day.
+func (r *RecurrenceRule) matches(day time.Time) bool {
+	if r.Frequency == RecurrenceFrequencyWeekly {
+		for _, weekday := range r.Weekdays {
+			if day.Weekday() == weekday {
+				return true
+			}
+		}
+		return false
+	}
+
+	// the day after the last day of the month is the first of the next
+	lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	monthDay := r.MonthDay
+	if monthDay > lastDay {
+		monthDay = lastDay
+	}
+	return day.Day() == monthDay
+}
+
+// Next returns the first occurrence of the rule after the time, in the
+// location.
+func (r *RecurrenceRule) Next(after time.Time, loc *time.Location) time.Time {
+	start := after.In(loc)
+	// every rule has an occurrence within a month and a few days
+	for i := 0; i <= 62; i++ {
+		day := time.Date(start.Year(), start.Month(), start.Day()+i, r.Hour, 0, 0, 0, loc)
+		if day.After(after) && r.matches(day) {
+			return day
+		}
+	}
+	return time.Time{}
+}
+
+// Latest returns the last occurrence of the rule after the time and up
+// to now, in the location, and false if there is none. The occurrences
+// missed before it, e.g. while the server was down, are skipped.
+func (r *RecurrenceRule) Latest(after, now time.Time, loc *time.Location) (time.Time, bool) {
+	latest := time.Time{}
+	for next := r.Next(after, loc); !next.IsZero() && !next.After(now); next = r.Next(next, loc) {
+		latest = next
+	}
+	return latest, !latest.IsZero()
+}
+
+// Recurrence is the definition of a recurrence block. The card template
+// is copied into the board at each occurrence of the rule, in the time
+// zone of the recurrence, with the select property set to the option
+// of the column when they are set.
+type Recurrence struct {
+	TemplateID string
+	Rule       *RecurrenceRule
+	Location   *time.Location
+	PropertyID string
+	OptionID   string
+}
+
+// RecurrenceFromBlock parses the fields of a recurrence block:
+// templateId and rrule are required, and timezone, propertyId and
+// optionId are optional. The time zone defaults to UTC.
+func RecurrenceFromBlock(block Block) (*Recurrence, error) {
+	stringField := func(name string) (string, error) {
+		value, ok := block.Fields[name]
+		if !ok || value == nil {
+			return "", nil
+		}
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%s isn't a string", name)
+		}
+		return s, nil
+	}
+
+	recurrence := &Recurrence{}
+	var err error
+	if recurrence.TemplateID, err = stringField("templateId"); err != nil {
+		return nil, err
+	}
+	if recurrence.TemplateID == "" {
+		return nil, errors.New("templateId is required")
+	}
+
+	rule, err := stringField("rrule")
+	if err != nil {
+		return nil, err
+	}
+	if recurrence.Rule, err = ParseRecurrenceRule(rule); err != nil {
+		return nil, fmt.Errorf("invalid rrule: %w", err)
+	}
+
+	timezone, err := stringField("timezone")
+	if err != nil {
+		return nil, err
+	}
+	if recurrence.Location, err = time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", timezone)
+	}
+
+	if recurrence.PropertyID, err = stringField("propertyId"); err != nil {
+		return nil, err
+	}
+	if recurrence.OptionID, err = stringField("optionId"); err != nil {
+		return nil, err
+	}
+	if (recurrence.PropertyID == "") != (recurrence.OptionID == "") {
+		return nil, errors.New("propertyId and optionId are set together")
+	}
+
+	return recurrence, nil
+}
+
+// RecurrenceRun records a card created by a recurrence, for the
+// occurrence of its rule at RunAt, so that it isn't created again.
+type RecurrenceRun struct {
+	RecurrenceID string
+	RunAt        int64
+	CardID       string
+	CreateAt     int64
+}
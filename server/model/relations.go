@@ -0,0 +1,79 @@
+package model
+
+import "sort"
+
+// PropertyTypeRelation is the type of the card properties whose value is
+// a list of IDs of the cards the card links to.
+const PropertyTypeRelation = "relation"
+
+// RelationIDs returns the card IDs of the value of a relation property,
+// and false if the value isn't a list of IDs. A missing value is an
+// empty list.
+func RelationIDs(value interface{}) ([]string, bool) {
+	if value == nil {
+		return []string{}, true
+	}
+
+	var ids []string
+	switch v := value.(type) {
+	case []string:
+		ids = v
+	case []interface{}:
+		ids = make([]string, 0, len(v))
+		for _, item := range v {
+			id, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			ids = append(ids, id)
+		}
+	default:
+		return nil, false
+	}
+	return ids, true
+}
+
+// CardPropertiesLinkingTo returns the sorted IDs of the properties of the
+// card whose value is a list of IDs containing the target card ID.
+func CardPropertiesLinkingTo(card Block, targetID string) []string {
+	properties, _ := card.Fields["properties"].(map[string]interface{})
+	propertyIDs := []string{}
+	for propertyID, value := range properties {
+		ids, ok := RelationIDs(value)
+		if !ok {
+			continue
+		}
+		for _, id := range ids {
+			if id == targetID {
+				propertyIDs = append(propertyIDs, propertyID)
+				break
+			}
+		}
+	}
+	sort.Strings(propertyIDs)
+	return propertyIDs
+}
+
+// CardBacklink is a relation property of a card linking to another card
+// swagger:model
+type CardBacklink struct {
+	// ID of the linking card
+	// required: true
+	CardID string `json:"cardId"`
+
+	// ID of the board of the linking card
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// Title of the linking card
+	// required: true
+	Title string `json:"title"`
+
+	// ID of the relation property of the linking card
+	// required: true
+	PropertyID string `json:"propertyId"`
+
+	// Name of the relation property of the linking card
+	// required: true
+	PropertyName string `json:"propertyName"`
+}
@@ -0,0 +1,43 @@
+package model
+
+// ResolvedBlock is the current location of a block, from which the
+// clients build the links to the block that keep working when it moves
+// to another board. The location is only set if the caller can view the
+// board of the block
+// swagger:model
+type ResolvedBlock struct {
+	// The ID of the block
+	// required: true
+	BlockID string `json:"blockId"`
+
+	// Whether the caller can view the board of the block
+	// required: true
+	CanAccess bool `json:"canAccess"`
+
+	// Whether the block or its board is deleted, its board then being the
+	// one it lived on
+	// required: true
+	Deleted bool `json:"deleted"`
+
+	// The time in milliseconds the block or its board was deleted
+	// required: false
+	DeleteAt int64 `json:"deleteAt,omitempty"`
+
+	// The type of the block
+	// required: false
+	Type string `json:"type,omitempty"`
+
+	// The ID of the board of the block
+	// required: false
+	BoardID string `json:"boardId,omitempty"`
+
+	// The ID of the view of the board the clients open by default, empty
+	// for the deleted blocks and the boards without views
+	// required: false
+	ViewID string `json:"viewId,omitempty"`
+
+	// The ID of the card of the block: the block itself for a card, or
+	// the card it belongs to for a comment or a content block
+	// required: false
+	CardID string `json:"cardId,omitempty"`
+}
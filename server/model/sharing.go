@@ -27,6 +27,77 @@ type Sharing struct {
 	// Updated time
 	// required: true
 	UpdateAt int64 `json:"update_at,omitempty"`
+
+	// Additional access tokens, each of them with its own expiry
+	// required: false
+	Tokens []SharingToken `json:"tokens,omitempty"`
+}
+
+// SharingToken is an additional read-only access token for a shared
+// root block
+// swagger:model
+type SharingToken struct {
+	// The access token
+	// required: true
+	Token string `json:"token"`
+
+	// ID of the root block
+	// required: true
+	RootID string `json:"rootId"`
+
+	// ID of the user who created the token
+	// required: true
+	CreatedBy string `json:"createdBy"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+
+	// Expiry time in milliseconds, zero if the token never expires
+	// required: false
+	ExpireAt int64 `json:"expireAt"`
+}
+
+// IsExpired returns true if the token has an expiry time before now.
+func (t SharingToken) IsExpired(now int64) bool {
+	return t.ExpireAt != 0 && t.ExpireAt <= now
+}
+
+// CalendarFeed is the access token of the calendar feed of a board
+// swagger:model
+type CalendarFeed struct {
+	// ID of the board
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The access token of the feed
+	// required: true
+	Token string `json:"token"`
+
+	// ID of the user who created the token
+	// required: true
+	CreatedBy string `json:"createdBy"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
+
+// BoardEmbed is a signed URL giving read-only access to a board, to
+// embed it in other sites
+// swagger:model
+type BoardEmbed struct {
+	// ID of the board
+	// required: true
+	BoardID string `json:"boardId"`
+
+	// The signed URL of the blocks of the board
+	// required: true
+	URL string `json:"url"`
+
+	// Expiry time of the URL in milliseconds
+	// required: true
+	ExpireAt int64 `json:"expireAt"`
 }
 
 func SharingFromJSON(data io.Reader) Sharing {
@@ -34,3 +105,11 @@ func SharingFromJSON(data io.Reader) Sharing {
 	_ = json.NewDecoder(data).Decode(&sharing)
 	return sharing
 }
+
+func SharingTokenFromJSON(data io.Reader) (*SharingToken, error) {
+	var token SharingToken
+	if err := json.NewDecoder(data).Decode(&token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,31 @@
+package model
+
+// Subscription is a user watching a card or a board, to be notified of
+// its changes without being mentioned
+// swagger:model
+type Subscription struct {
+	// Type of the watched block, card or board
+	// required: true
+	BlockType string `json:"blockType"`
+
+	// ID of the watched block
+	// required: true
+	BlockID string `json:"blockId"`
+
+	// ID of the workspace of the block
+	// required: true
+	WorkspaceID string `json:"workspaceId"`
+
+	// ID of the watching user
+	// required: true
+	SubscriberID string `json:"subscriberId"`
+
+	// Time of the last notification of the subscriber, 0 if never
+	// notified
+	// required: true
+	NotifiedAt int64 `json:"notifiedAt"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
@@ -0,0 +1,55 @@
+package model
+
+// BoardTemplate is a summary of a global board template
+// swagger:model
+type BoardTemplate struct {
+	// ID of the template board
+	// required: true
+	ID string `json:"id"`
+
+	// Title of the template
+	// required: true
+	Title string `json:"title"`
+
+	// Icon of the template
+	// required: false
+	Icon string `json:"icon,omitempty"`
+
+	// Number of cards of the template
+	// required: true
+	CardCount int64 `json:"cardCount"`
+}
+
+// CreateTemplateRequest is the request to create a global template
+// from an existing board
+// swagger:model
+type CreateTemplateRequest struct {
+	// Workspace of the board
+	// required: true
+	WorkspaceID string `json:"workspaceId"`
+
+	// ID of the board to create the template from
+	// required: true
+	BoardID string `json:"boardId"`
+}
+
+const (
+	// CardTemplateField is the field of the cards holding the ID of the
+	// card template they were created from.
+	CardTemplateField = "templateId"
+
+	// BoardDefaultCardTemplateField is the field of the boards holding
+	// the ID of their own default card template, applied by the clients.
+	// A board with the field, even empty, overrides the default card
+	// template of the workspace.
+	BoardDefaultCardTemplateField = "defaultCardTemplateId"
+)
+
+// DefaultCardTemplateRequest is the request to set the default card
+// template of a workspace
+// swagger:model
+type DefaultCardTemplateRequest struct {
+	// ID of the card template, empty to clear the default
+	// required: true
+	TemplateID string `json:"templateId"`
+}
@@ -5,6 +5,15 @@ import (
 	"io"
 )
 
+// UserPropNickname is the prop of the users with their nickname, mapped
+// from the directory for the LDAP users.
+const UserPropNickname = "focalboard_nickname"
+
+// UserPropAvatarUpdateAt is the prop of the standalone users with the
+// time in milliseconds their avatar was last set, which busts the caches
+// of its URL.
+const UserPropAvatarUpdateAt = "focalboard_avatarUpdateAt"
+
 // User is a user
 // swagger:model
 type User struct {
@@ -26,6 +35,9 @@ type User struct {
 	// swagger:ignore
 	MfaSecret string `json:"-"`
 
+	// swagger:ignore
+	MfaActive bool `json:"-"`
+
 	// swagger:ignore
 	AuthService string `json:"-"`
 
@@ -47,6 +59,46 @@ type User struct {
 	// Deleted time, set to indicate user is deleted
 	// required: true
 	DeleteAt int64 `json:"delete_at"`
+
+	// Whether the user administers the server
+	// required: false
+	IsAdmin bool `json:"is_admin"`
+
+	// Whether the user is a guest, who only has access to the boards
+	// they are a member of
+	// required: false
+	IsGuest bool `json:"is_guest"`
+}
+
+// UserSearchResult is a user matching a search, with only the fields
+// needed to render them
+// swagger:model
+type UserSearchResult struct {
+	// The user ID
+	// required: true
+	ID string `json:"id"`
+
+	// The user name
+	// required: true
+	Username string `json:"username"`
+
+	// The nickname of the user, empty in standalone mode
+	// required: false
+	Nickname string `json:"nickname"`
+}
+
+// QueryUsersOptions are the filters of a list of users, including the
+// deactivated ones.
+type QueryUsersOptions struct {
+	// Only the active users when true, or the deactivated ones when
+	// false
+	Active *bool
+
+	// Only the users whose username starts with this prefix
+	Username string
+
+	Page    int
+	PerPage int
 }
 
 type Session struct {
@@ -57,6 +109,7 @@ type Session struct {
 	Props       map[string]interface{} `json:"props"`
 	CreateAt    int64                  `json:"create_at,omitempty"`
 	UpdateAt    int64                  `json:"update_at,omitempty"`
+	ExpireAt    int64                  `json:"expire_at,omitempty"`
 }
 
 func UserFromJSON(data io.Reader) (*User, error) {
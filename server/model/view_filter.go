@@ -0,0 +1,96 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The conditions of the clauses of the view filters.
+const (
+	FilterConditionIncludes    = "includes"
+	FilterConditionNotIncludes = "notIncludes"
+	FilterConditionIsEmpty     = "isEmpty"
+	FilterConditionIsNotEmpty  = "isNotEmpty"
+
+	// FilterConditionIsBetween matches the dates between the two values
+	// of the clause, timestamps in milliseconds, either of which can be
+	// empty for an open range
+	FilterConditionIsBetween = "isBetween"
+)
+
+// The operations of the groups of the view filters.
+const (
+	FilterOperationAnd = "and"
+	FilterOperationOr  = "or"
+)
+
+// FilterClause is a condition on a card property of a view filter.
+type FilterClause struct {
+	PropertyID string   `json:"propertyId"`
+	Condition  string   `json:"condition"`
+	Values     []string `json:"values"`
+}
+
+// FilterGroup is the filter of a view, its clauses and nested groups
+// being combined by its operation.
+type FilterGroup struct {
+	Operation string
+	Clauses   []FilterClause
+	Groups    []FilterGroup
+}
+
+// ViewFilterFromBlock returns the filter of the view, read from its
+// "filter" field, where the clauses and the nested groups are mixed in
+// the filters of each group. A view without a filter has an empty one.
+func ViewFilterFromBlock(view Block) (*FilterGroup, error) {
+	filter, ok := view.Fields["filter"]
+	if !ok || filter == nil {
+		return &FilterGroup{Operation: FilterOperationAnd}, nil
+	}
+	fields, ok := filter.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the filter of the view isn't an object")
+	}
+	return filterGroupFromFields(fields)
+}
+
+func filterGroupFromFields(fields map[string]interface{}) (*FilterGroup, error) {
+	group := &FilterGroup{Operation: FilterOperationAnd}
+	if operation, ok := fields["operation"].(string); ok && operation != "" {
+		group.Operation = operation
+	}
+	if group.Operation != FilterOperationAnd && group.Operation != FilterOperationOr {
+		return nil, fmt.Errorf("unknown filter operation %q", group.Operation)
+	}
+
+	filters, _ := fields["filters"].([]interface{})
+	for _, item := range filters {
+		itemFields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("a filter of the view isn't an object")
+		}
+
+		if _, isGroup := itemFields["filters"]; isGroup {
+			nested, err := filterGroupFromFields(itemFields)
+			if err != nil {
+				return nil, err
+			}
+			group.Groups = append(group.Groups, *nested)
+			continue
+		}
+
+		clause := FilterClause{}
+		clause.PropertyID, _ = itemFields["propertyId"].(string)
+		clause.Condition, _ = itemFields["condition"].(string)
+		values, _ := itemFields["values"].([]interface{})
+		for _, value := range values {
+			str, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("a value of the filter of property %s isn't a string", clause.PropertyID)
+			}
+			clause.Values = append(clause.Values, str)
+		}
+		group.Clauses = append(group.Clauses, clause)
+	}
+	return group, nil
+}
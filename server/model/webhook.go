@@ -0,0 +1,38 @@
+package model
+
+// WebhookDelivery is a webhook delivery that failed after all its
+// attempts
+// swagger:model
+type WebhookDelivery struct {
+	// ID of the delivery
+	// required: true
+	ID string `json:"id"`
+
+	// ID of the workspace of the changed block
+	// required: true
+	WorkspaceID string `json:"workspaceId"`
+
+	// URL of the webhook
+	// required: true
+	URL string `json:"url"`
+
+	// The JSON payload that was sent
+	// required: true
+	Payload string `json:"payload"`
+
+	// HTTP status code of the last attempt, zero if there was no response
+	// required: true
+	StatusCode int `json:"statusCode"`
+
+	// Error of the last attempt
+	// required: false
+	Error string `json:"error,omitempty"`
+
+	// Number of attempts made
+	// required: true
+	Attempts int `json:"attempts"`
+
+	// Created time
+	// required: true
+	CreateAt int64 `json:"createAt"`
+}
@@ -1,5 +1,13 @@
 package model
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+)
+
 // Workspace is information global to a workspace
 // swagger:model
 type Workspace struct {
@@ -17,7 +25,7 @@ type Workspace struct {
 
 	// Workspace settings
 	// required: false
-	Settings map[string]interface{} `json:"settings"`
+	Settings WorkspaceSettings `json:"settings"`
 
 	// ID of user who last modified this
 	// required: true
@@ -28,6 +36,225 @@ type Workspace struct {
 	UpdateAt int64 `json:"updateAt"`
 }
 
+var (
+	localeRegexp = regexp.MustCompile(`^[a-zA-Z]{2,3}([-_][a-zA-Z0-9]{2,8})*$`)
+
+	errInvalidCardLimit = errors.New("cardLimit must not be negative")
+	errInvalidLocale    = errors.New("locale is not a valid language tag")
+	errInvalidWebhook   = errors.New("webhook url must be an absolute http or https URL")
+)
+
+// WorkspaceSettings are the settings of a workspace
+// swagger:model
+type WorkspaceSettings struct {
+	// Whether new users can sign up to the workspace
+	// required: false
+	SignupAllowed bool `json:"signupAllowed,omitempty"`
+
+	// ID of the template used for new boards
+	// required: false
+	DefaultTemplateID string `json:"defaultTemplateId,omitempty"`
+
+	// ID of the card template applied to the new blank cards of the
+	// boards that don't set their own, set with the default card
+	// template endpoint
+	// required: false
+	DefaultCardTemplateID string `json:"defaultCardTemplateId,omitempty"`
+
+	// Maximum number of cards in the workspace, zero means no limit
+	// required: false
+	CardLimit int `json:"cardLimit,omitempty"`
+
+	// Locale of the workspace, e.g. "en" or "pt-BR"
+	// required: false
+	Locale string `json:"locale,omitempty"`
+
+	// Webhooks notified when a block of the workspace changes
+	// required: false
+	Webhooks []WorkspaceWebhook `json:"webhooks,omitempty"`
+
+	// unknown keys are kept so settings written by newer versions
+	// are not lost when saved by this one
+	extra map[string]json.RawMessage
+}
+
+// MarshalJSON encodes the settings along with any unknown keys.
+func (ws WorkspaceSettings) MarshalJSON() ([]byte, error) {
+	settings := make(map[string]json.RawMessage, len(ws.extra)+6)
+	for key, value := range ws.extra {
+		settings[key] = value
+	}
+
+	// the alias type avoids recursing into this method
+	type knownSettings WorkspaceSettings
+	known, err := json.Marshal(knownSettings(ws))
+	if err != nil {
+		return nil, err
+	}
+
+	var knownMap map[string]json.RawMessage
+	if err := json.Unmarshal(known, &knownMap); err != nil {
+		return nil, err
+	}
+	for key, value := range knownMap {
+		settings[key] = value
+	}
+
+	return json.Marshal(settings)
+}
+
+// UnmarshalJSON decodes the settings. Values that aren't a JSON
+// object decode to empty settings, and known keys holding a value of
+// the wrong type are kept as unknown keys, so stored settings always
+// load.
+func (ws *WorkspaceSettings) UnmarshalJSON(data []byte) error {
+	*ws = WorkspaceSettings{}
+
+	var settings map[string]json.RawMessage
+	if json.Unmarshal(data, &settings) != nil {
+		// not a JSON object, the settings are left empty
+		return nil
+	}
+
+	known := map[string]interface{}{
+		"signupAllowed":         &ws.SignupAllowed,
+		"defaultTemplateId":     &ws.DefaultTemplateID,
+		"defaultCardTemplateId": &ws.DefaultCardTemplateID,
+		"cardLimit":             &ws.CardLimit,
+		"locale":                &ws.Locale,
+		"webhooks":              &ws.Webhooks,
+	}
+
+	for key, value := range settings {
+		if field, ok := known[key]; ok {
+			if err := json.Unmarshal(value, field); err == nil {
+				continue
+			}
+		}
+
+		if ws.extra == nil {
+			ws.extra = map[string]json.RawMessage{}
+		}
+		ws.extra[key] = value
+	}
+
+	return nil
+}
+
+// WorkspaceWebhook is a URL notified when a block of the workspace
+// changes
+// swagger:model
+type WorkspaceWebhook struct {
+	// URL the changes are posted to
+	// required: true
+	URL string `json:"url"`
+
+	// Secret used to sign the payloads with HMAC-SHA256, the
+	// signature is sent in the X-Focalboard-Signature header
+	// required: false
+	Secret string `json:"secret,omitempty"`
+}
+
+// IsValid returns an error if the URL of the webhook is not valid.
+func (wh WorkspaceWebhook) IsValid() error {
+	u, err := url.Parse(wh.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errInvalidWebhook
+	}
+	return nil
+}
+
+// WorkspaceSettingsPatchFromJSON decodes a settings patch, failing on
+// keys that are not known. It is meant for user input, where unknown keys are
+// most likely typos.
+func WorkspaceSettingsPatchFromJSON(data []byte) (*WorkspaceSettingsPatch, error) {
+	var patch WorkspaceSettingsPatch
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patch); err != nil {
+		return nil, err
+	}
+	return &patch, nil
+}
+
+// WorkspaceSettingsPatch is a patch for modifying workspace settings
+// swagger:model
+type WorkspaceSettingsPatch struct {
+	// Whether new users can sign up to the workspace
+	// required: false
+	SignupAllowed *bool `json:"signupAllowed"`
+
+	// ID of the template used for new boards
+	// required: false
+	DefaultTemplateID *string `json:"defaultTemplateId"`
+
+	// Maximum number of cards in the workspace, zero means no limit
+	// required: false
+	CardLimit *int `json:"cardLimit"`
+
+	// Locale of the workspace, e.g. "en" or "pt-BR"
+	// required: false
+	Locale *string `json:"locale"`
+
+	// Webhooks notified when a block of the workspace changes
+	// required: false
+	Webhooks *[]WorkspaceWebhook `json:"webhooks"`
+}
+
+// IsValid returns an error describing the first invalid value of
+// the patch, if any.
+func (p *WorkspaceSettingsPatch) IsValid() error {
+	if p.CardLimit != nil && *p.CardLimit < 0 {
+		return errInvalidCardLimit
+	}
+
+	if p.Locale != nil && *p.Locale != "" && !localeRegexp.MatchString(*p.Locale) {
+		return errInvalidLocale
+	}
+
+	if p.Webhooks != nil {
+		for _, webhook := range *p.Webhooks {
+			if err := webhook.IsValid(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Patch returns a copy of the settings with the patch applied. Keys
+// not present in the patch are left untouched.
+func (p *WorkspaceSettingsPatch) Patch(settings WorkspaceSettings) WorkspaceSettings {
+	if p.SignupAllowed != nil {
+		settings.SignupAllowed = *p.SignupAllowed
+	}
+
+	if p.DefaultTemplateID != nil {
+		settings.DefaultTemplateID = *p.DefaultTemplateID
+	}
+
+	if p.CardLimit != nil {
+		settings.CardLimit = *p.CardLimit
+	}
+
+	if p.Locale != nil {
+		settings.Locale = *p.Locale
+	}
+
+	if p.Webhooks != nil {
+		settings.Webhooks = *p.Webhooks
+	}
+
+	return settings
+}
+
+// The sizes of the pages of the workspaces of a user.
+const (
+	UserWorkspacesDefaultPageSize = 100
+	UserWorkspacesMaxPageSize     = 1000
+)
+
 // UserWorkspace is a summary of a single association between
 // a user and a workspace
 // swagger:model
@@ -43,3 +270,16 @@ type UserWorkspace struct {
 	// Number of boards in the workspace
 	BoardCount int `json:"boardCount"`
 }
+
+// UserWorkspacesPage is a page of the workspaces a user belongs to
+// swagger:model
+type UserWorkspacesPage struct {
+	// The workspaces in this page
+	// required: true
+	Workspaces []UserWorkspace `json:"workspaces"`
+
+	// Whether there are more workspaces after this page. The ID of
+	// the last workspace is used as the cursor for the next page
+	// required: true
+	HasMore bool `json:"hasMore"`
+}
@@ -0,0 +1,81 @@
+package model
+
+const (
+	// WorkspaceRetentionActionNotify is the action of the inactive
+	// workspaces whose members are notified of their deletion
+	WorkspaceRetentionActionNotify = "notify"
+
+	// WorkspaceRetentionActionWait is the action of the inactive
+	// workspaces whose members were notified, until the notice ends
+	WorkspaceRetentionActionWait = "wait"
+
+	// WorkspaceRetentionActionDelete is the action of the inactive
+	// workspaces that are archived and deleted, once the notice ended
+	WorkspaceRetentionActionDelete = "delete"
+)
+
+// InactiveWorkspace is a workspace whose blocks haven't been updated
+// since a time.
+type InactiveWorkspace struct {
+	WorkspaceID    string
+	LastActivityAt int64
+}
+
+// WorkspaceRetentionNotice records the members of an inactive workspace
+// being notified of its deletion, at the last activity it was computed
+// from, the notice being reset if the workspace is updated again.
+type WorkspaceRetentionNotice struct {
+	WorkspaceID    string
+	LastActivityAt int64
+	NotifiedAt     int64
+}
+
+// WorkspaceRetentionPlan is the list of the workspaces affected by the
+// next run of the cleanup policy of the inactive workspaces
+// swagger:model
+type WorkspaceRetentionPlan struct {
+	// Whether the policy is enabled, the workspaces being only affected
+	// once it is
+	// required: true
+	Enabled bool `json:"enabled"`
+
+	// The number of days without activity after which the members of a
+	// workspace are notified of its deletion
+	// required: true
+	InactivityDays int `json:"inactivityDays"`
+
+	// The number of days between the notice and the deletion
+	// required: true
+	NoticeDays int `json:"noticeDays"`
+
+	// The affected workspaces
+	// required: true
+	Workspaces []WorkspaceRetentionCandidate `json:"workspaces"`
+}
+
+// WorkspaceRetentionCandidate is an inactive workspace and what the next
+// run of the cleanup policy does with it
+// swagger:model
+type WorkspaceRetentionCandidate struct {
+	// The ID of the workspace
+	// required: true
+	WorkspaceID string `json:"workspaceId"`
+
+	// The time in milliseconds the blocks of the workspace were last
+	// updated
+	// required: true
+	LastActivityAt int64 `json:"lastActivityAt"`
+
+	// The time in milliseconds the members were notified, 0 if they
+	// weren't yet
+	// required: false
+	NotifiedAt int64 `json:"notifiedAt"`
+
+	// The time in milliseconds from which the workspace is deleted
+	// required: true
+	DeleteAt int64 `json:"deleteAt"`
+
+	// The action of the next run, notify, wait or delete
+	// required: true
+	Action string `json:"action"`
+}
@@ -0,0 +1,44 @@
+package model
+
+const (
+	// WorkspaceStatsDays is the number of days, including the current
+	// one, of the workspace creations of the workspace statistics
+	WorkspaceStatsDays = 90
+
+	// WorkspaceStatsDayMillis is the duration of a day of the workspace
+	// statistics, in milliseconds
+	WorkspaceStatsDayMillis = 24 * 60 * 60 * 1000
+)
+
+// WorkspaceStats are the workspace counts of the server, for the admin
+// dashboard
+// swagger:model
+type WorkspaceStats struct {
+	// Number of workspaces
+	// required: true
+	Total int64 `json:"total"`
+
+	// Number of workspaces with blocks updated in the last 7 days
+	// required: true
+	Active7Days int64 `json:"active7Days"`
+
+	// Number of workspaces with blocks updated in the last 30 days
+	// required: true
+	Active30Days int64 `json:"active30Days"`
+
+	// Number of workspaces created by day, in UTC, the oldest first
+	// required: true
+	CreatedByDay []DailyCount `json:"createdByDay"`
+}
+
+// DailyCount is a count of a day
+// swagger:model
+type DailyCount struct {
+	// Start of the day, in milliseconds since the epoch
+	// required: true
+	DayStart int64 `json:"dayStart"`
+
+	// Count of the day
+	// required: true
+	Count int64 `json:"count"`
+}
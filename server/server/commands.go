@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"os"
+
+	"github.com/mattermost/focalboard/server/app"
+	"github.com/mattermost/focalboard/server/auth"
+	appModel "github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/metrics"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/services/store/sqlstore"
+	"github.com/mattermost/focalboard/server/services/webhook"
+	"github.com/mattermost/focalboard/server/ws"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// NewCommandApp returns an app working directly on the store, for the
+// command-line tools that run without the HTTP server, e.g. while it's
+// down. The changes aren't broadcast to any client, and the returned
+// function waits for their webhook deliveries.
+func NewCommandApp(cfg *config.Configuration, db store.Store, logger *mlog.Logger) (*app.App, func(), error) {
+	filesBackend, err := newFilesBackend(cfg, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authenticator := auth.New(cfg, db)
+	webhookDispatcher := webhook.NewDispatcher(db, logger)
+	metricsService := metrics.NewMetrics(metrics.InstanceInfo{
+		Version:        appModel.CurrentVersion,
+		BuildNum:       appModel.BuildNumber,
+		Edition:        appModel.Edition,
+		InstallationID: os.Getenv("MM_CLOUD_INSTALLATION_ID"),
+	})
+
+	// the websocket server is never started, it has no client to
+	// broadcast the changes to
+	wsAdapter := ws.NewServer(authenticator, "", cfg.AuthMode == MattermostAuthMod, logger, metrics.NoopInstrumentation{})
+
+	appServices := app.Services{
+		Auth:              authenticator,
+		Store:             db,
+		FilesBackend:      filesBackend,
+		Webhook:           webhook.NewClient(cfg, logger),
+		WebhookDispatcher: webhookDispatcher,
+		EmailSender:       newEmailSender(cfg),
+		Metrics:           metricsService,
+		Logger:            logger,
+	}
+	waitForDeliveries := func() {
+		webhookDispatcher.Shutdown(context.Background())
+	}
+	return app.New(cfg, wsAdapter, appServices), waitForDeliveries, nil
+}
+
+// NewStoreWithoutMigrations opens the configured database without
+// applying its pending migrations, for the command-line tools that
+// inspect it.
+func NewStoreWithoutMigrations(config *config.Configuration, logger *mlog.Logger) (store.Store, error) {
+	sqlDB, err := openDatabase(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlstore.NewWithoutMigrations(config.DBType, config.DBConfigString, config.DBTablePrefix, logger, sqlDB, false), nil
+}
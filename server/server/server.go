@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net"
@@ -21,7 +22,9 @@ import (
 	appModel "github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/audit"
 	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/email"
 	"github.com/mattermost/focalboard/server/services/metrics"
+	"github.com/mattermost/focalboard/server/services/notify"
 	"github.com/mattermost/focalboard/server/services/scheduler"
 	"github.com/mattermost/focalboard/server/services/store"
 	"github.com/mattermost/focalboard/server/services/store/mattermostauthlayer"
@@ -39,10 +42,45 @@ import (
 )
 
 const (
-	cleanupSessionTaskFrequency = 10 * time.Minute
-	updateMetricsTaskFrequency  = 15 * time.Minute
-
-	minSessionExpiryTime = int64(60 * 60 * 24 * 31) // 31 days
+	cleanupSessionTaskFrequency     = 10 * time.Minute
+	updateMetricsTaskFrequency      = 15 * time.Minute
+	purgeTrashTaskFrequency         = 1 * time.Hour
+	dueDateReminderTaskFrequency    = 15 * time.Minute
+	cleanupFilesTaskFrequency       = 24 * time.Hour
+	recurringCardsTaskFrequency     = 1 * time.Minute
+	workspaceUsageTaskFrequency     = 1 * time.Hour
+	cardOrderTaskFrequency          = 1 * time.Minute
+	digestTaskFrequency             = 1 * time.Hour
+	gitHubSyncTaskFrequency         = 5 * time.Minute
+	thumbnailTaskFrequency          = 10 * time.Second
+	idempotencyKeysTaskFrequency    = 1 * time.Hour
+	workspaceRetentionTaskFrequency = 24 * time.Hour
+
+	// dueDateReminderLock is the cluster lock held by the server that
+	// sends the due date reminders
+	dueDateReminderLock = "dueDateReminders"
+
+	// cleanupFilesLock is the cluster lock held by the server that
+	// removes the orphaned files
+	cleanupFilesLock = "cleanupFiles"
+
+	// recurringCardsLock is the cluster lock held by the server that
+	// creates the recurring cards
+	recurringCardsLock = "recurringCards"
+
+	// digestLock is the cluster lock held by the server that sends the
+	// email digests
+	digestLock = "digests"
+
+	// gitHubSyncLock is the cluster lock held by the server that syncs
+	// the GitHub integrations of the boards
+	gitHubSyncLock = "gitHubSync"
+
+	// workspaceRetentionLock is the cluster lock held by the server that
+	// cleans up the inactive workspaces
+	workspaceRetentionLock = "workspaceRetention"
+
+	defaultTrashRetentionDays = 30
 
 	MattermostAuthMod = "mattermost"
 )
@@ -56,48 +94,47 @@ type Server struct {
 	telemetry              *telemetry.Service
 	logger                 *mlog.Logger
 	cleanUpSessionsTask    *scheduler.ScheduledTask
+	purgeTrashTask         *scheduler.ScheduledTask
+	dueDateReminderTask    *scheduler.ScheduledTask
+	cleanupFilesTask       *scheduler.ScheduledTask
+	recurringCardsTask     *scheduler.ScheduledTask
+	workspaceUsageTask     *scheduler.ScheduledTask
+	cardOrderTask          *scheduler.ScheduledTask
+	thumbnailTask          *scheduler.ScheduledTask
+	digestTask             *scheduler.ScheduledTask
+	gitHubSyncTask         *scheduler.ScheduledTask
+	idempotencyKeysTask    *scheduler.ScheduledTask
+	workspaceRetentionTask *scheduler.ScheduledTask
 	metricsServer          *metrics.Service
 	metricsService         *metrics.Metrics
 	metricsUpdaterTask     *scheduler.ScheduledTask
 	auditService           *audit.Audit
+	webhookDispatcher      *webhook.Dispatcher
+	app                    *app.App
+	instanceID             string
 	servicesStartStopMutex sync.Mutex
 
 	localRouter     *mux.Router
 	localModeServer *http.Server
 	api             *api.API
+
+	// jobsContext is passed to the background jobs, and is canceled on
+	// shutdown so that their queries don't outlive the server.
+	jobsContext context.Context
+	cancelJobs  context.CancelFunc
 }
 
 func New(cfg *config.Configuration, singleUserToken string, db store.Store,
-	logger *mlog.Logger, serverID string, wsAdapter ws.Adapter) (*Server, error) {
+	logger *mlog.Logger, serverID string, wsAdapter ws.Adapter, notifier notify.Notifier) (*Server, error) {
 	authenticator := auth.New(cfg, db)
 
-	// if no ws adapter is provided, we spin up a websocket server
-	if wsAdapter == nil {
-		wsAdapter = ws.NewServer(authenticator, singleUserToken, cfg.AuthMode == MattermostAuthMod, logger)
-	}
-
-	filesBackendSettings := filestore.FileBackendSettings{}
-	filesBackendSettings.DriverName = cfg.FilesDriver
-	filesBackendSettings.Directory = cfg.FilesPath
-	filesBackendSettings.AmazonS3AccessKeyId = cfg.FilesS3Config.AccessKeyID
-	filesBackendSettings.AmazonS3SecretAccessKey = cfg.FilesS3Config.SecretAccessKey
-	filesBackendSettings.AmazonS3Bucket = cfg.FilesS3Config.Bucket
-	filesBackendSettings.AmazonS3PathPrefix = cfg.FilesS3Config.PathPrefix
-	filesBackendSettings.AmazonS3Region = cfg.FilesS3Config.Region
-	filesBackendSettings.AmazonS3Endpoint = cfg.FilesS3Config.Endpoint
-	filesBackendSettings.AmazonS3SSL = cfg.FilesS3Config.SSL
-	filesBackendSettings.AmazonS3SignV2 = cfg.FilesS3Config.SignV2
-	filesBackendSettings.AmazonS3SSE = cfg.FilesS3Config.SSE
-	filesBackendSettings.AmazonS3Trace = cfg.FilesS3Config.Trace
-
-	filesBackend, appErr := filestore.NewFileBackend(filesBackendSettings)
-	if appErr != nil {
-		logger.Error("Unable to initialize the files storage", mlog.Err(appErr))
-
-		return nil, errors.New("unable to initialize the files storage")
+	filesBackend, err := newFilesBackend(cfg, logger)
+	if err != nil {
+		return nil, err
 	}
 
 	webhookClient := webhook.NewClient(cfg, logger)
+	webhookDispatcher := webhook.NewDispatcher(db, logger)
 
 	// Init metrics
 	instanceInfo := metrics.InstanceInfo{
@@ -108,6 +145,27 @@ func New(cfg *config.Configuration, singleUserToken string, db store.Store,
 	}
 	metricsService := metrics.NewMetrics(instanceInfo)
 
+	// the operations are only instrumented when the metrics are exported
+	var instrumentation metrics.Instrumentation = metrics.NoopInstrumentation{}
+	if cfg.EnableMetrics || cfg.PrometheusAddress != "" {
+		instrumentation = metricsService
+	}
+	if sqlStore, ok := db.(*sqlstore.SQLStore); ok {
+		sqlStore.SetInstrumentation(instrumentation)
+	}
+
+	corsPolicy := api.NewCORSPolicy(cfg)
+
+	// if no ws adapter is provided, we spin up a websocket server
+	if wsAdapter == nil {
+		wsServer := ws.NewServer(authenticator, singleUserToken, cfg.AuthMode == MattermostAuthMod, logger, instrumentation)
+		wsServer.SetReplayBufferSize(cfg.WebsocketReplayBufferSize)
+		if corsPolicy != nil {
+			wsServer.SetCheckOrigin(corsPolicy.CheckOrigin)
+		}
+		wsAdapter = wsServer
+	}
+
 	// Init audit
 	auditService, errAudit := audit.NewAudit()
 	if errAudit != nil {
@@ -118,23 +176,31 @@ func New(cfg *config.Configuration, singleUserToken string, db store.Store,
 	}
 
 	appServices := app.Services{
-		Auth:         authenticator,
-		Store:        db,
-		FilesBackend: filesBackend,
-		Webhook:      webhookClient,
-		Metrics:      metricsService,
-		Logger:       logger,
+		Auth:              authenticator,
+		Store:             db,
+		FilesBackend:      filesBackend,
+		Webhook:           webhookClient,
+		WebhookDispatcher: webhookDispatcher,
+		Notifier:          notifier,
+		EmailSender:       newEmailSender(cfg),
+		Metrics:           metricsService,
+		Logger:            logger,
 	}
 	app := app.New(cfg, wsAdapter, appServices)
 
-	focalboardAPI := api.NewAPI(app, singleUserToken, cfg.AuthMode, logger, auditService)
+	if err := app.InitTemplates(context.Background()); err != nil {
+		logger.Error("Unable to initialize the templates", mlog.Err(err))
+		return nil, err
+	}
+
+	focalboardAPI := api.NewAPI(app, singleUserToken, cfg.AuthMode, logger, auditService, api.NewRateLimiter(cfg), api.NewCompressor(cfg), corsPolicy, instrumentation)
 
 	// Local router for admin APIs
 	localRouter := mux.NewRouter()
 	focalboardAPI.RegisterAdminRoutes(localRouter)
 
 	// Init workspace
-	if _, err := app.GetRootWorkspace(); err != nil {
+	if _, err := app.GetRootWorkspace(context.Background()); err != nil {
 		logger.Error("Unable to get root workspace", mlog.Err(err))
 		return nil, err
 	}
@@ -145,6 +211,9 @@ func New(cfg *config.Configuration, singleUserToken string, db store.Store,
 		webServer.AddRoutes(routedService)
 	}
 	webServer.AddRoutes(focalboardAPI)
+	if cfg.EnableMetrics {
+		webServer.Router().Handle("/metrics", metrics.NewHandler(metricsService, cfg.MetricsAuthToken, logger)).Methods("GET")
+	}
 
 	settings, err := db.GetSystemSettings()
 	if err != nil {
@@ -159,7 +228,10 @@ func New(cfg *config.Configuration, singleUserToken string, db store.Store,
 			return nil, err
 		}
 	}
+	jobsContext, cancelJobs := context.WithCancel(context.Background())
+
 	telemetryOpts := telemetryOptions{
+		ctx:         jobsContext,
 		app:         app,
 		cfg:         cfg,
 		telemetryID: telemetryID,
@@ -170,18 +242,23 @@ func New(cfg *config.Configuration, singleUserToken string, db store.Store,
 	telemetryService := initTelemetry(telemetryOpts)
 
 	server := Server{
-		config:         cfg,
-		wsAdapter:      wsAdapter,
-		webServer:      webServer,
-		store:          db,
-		filesBackend:   filesBackend,
-		telemetry:      telemetryService,
-		metricsServer:  metrics.NewMetricsServer(cfg.PrometheusAddress, metricsService, logger),
-		metricsService: metricsService,
-		auditService:   auditService,
-		logger:         logger,
-		localRouter:    localRouter,
-		api:            focalboardAPI,
+		config:            cfg,
+		wsAdapter:         wsAdapter,
+		webServer:         webServer,
+		store:             db,
+		filesBackend:      filesBackend,
+		telemetry:         telemetryService,
+		metricsServer:     metrics.NewMetricsServer(cfg.PrometheusAddress, metricsService, logger),
+		metricsService:    metricsService,
+		auditService:      auditService,
+		webhookDispatcher: webhookDispatcher,
+		app:               app,
+		instanceID:        uuid.New().String(),
+		logger:            logger,
+		localRouter:       localRouter,
+		api:               focalboardAPI,
+		jobsContext:       jobsContext,
+		cancelJobs:        cancelJobs,
 	}
 
 	server.initHandlers()
@@ -189,24 +266,64 @@ func New(cfg *config.Configuration, singleUserToken string, db store.Store,
 	return &server, nil
 }
 
+// newEmailSender returns the sender of the emails, or nil if no SMTP
+// server is configured and no email is sent.
+func newEmailSender(cfg *config.Configuration) email.Sender {
+	if smtpSender := email.NewSMTPSender(cfg.SMTP); smtpSender != nil {
+		return smtpSender
+	}
+	return nil
+}
+
+func newFilesBackend(cfg *config.Configuration, logger *mlog.Logger) (filestore.FileBackend, error) {
+	filesBackendSettings := filestore.FileBackendSettings{}
+	filesBackendSettings.DriverName = cfg.FilesDriver
+	filesBackendSettings.Directory = cfg.FilesPath
+	filesBackendSettings.AmazonS3AccessKeyId = cfg.FilesS3Config.AccessKeyID
+	filesBackendSettings.AmazonS3SecretAccessKey = cfg.FilesS3Config.SecretAccessKey
+	filesBackendSettings.AmazonS3Bucket = cfg.FilesS3Config.Bucket
+	filesBackendSettings.AmazonS3PathPrefix = cfg.FilesS3Config.PathPrefix
+	filesBackendSettings.AmazonS3Region = cfg.FilesS3Config.Region
+	filesBackendSettings.AmazonS3Endpoint = cfg.FilesS3Config.Endpoint
+	filesBackendSettings.AmazonS3SSL = cfg.FilesS3Config.SSL
+	filesBackendSettings.AmazonS3SignV2 = cfg.FilesS3Config.SignV2
+	filesBackendSettings.AmazonS3SSE = cfg.FilesS3Config.SSE
+	filesBackendSettings.AmazonS3Trace = cfg.FilesS3Config.Trace
+
+	filesBackend, appErr := filestore.NewFileBackend(filesBackendSettings)
+	if appErr != nil {
+		logger.Error("Unable to initialize the files storage", mlog.Err(appErr))
+
+		return nil, errors.New("unable to initialize the files storage")
+	}
+
+	if err := filesBackend.TestConnection(); err != nil {
+		// the server still starts, uploads and downloads fail until
+		// the storage is reachable
+		logger.Error("Unable to connect to the files storage", mlog.String("driver", cfg.FilesDriver), mlog.Err(err))
+	}
+
+	return filesBackend, nil
+}
+
 func NewStore(config *config.Configuration, logger *mlog.Logger) (store.Store, error) {
-	sqlDB, err := sql.Open(config.DBType, config.DBConfigString)
+	sqlDB, err := openDatabase(config, logger)
 	if err != nil {
-		logger.Error("connectDatabase failed", mlog.Err(err))
 		return nil, err
 	}
 
-	err = sqlDB.Ping()
+	sqlStore, err := sqlstore.New(config.DBType, config.DBConfigString, config.DBTablePrefix, logger, sqlDB, false)
 	if err != nil {
-		logger.Error(`Database Ping failed`, mlog.Err(err))
 		return nil, err
 	}
 
-	var db store.Store
-	db, err = sqlstore.New(config.DBType, config.DBConfigString, config.DBTablePrefix, logger, sqlDB, false)
+	replicas, err := openReplicas(config)
 	if err != nil {
 		return nil, err
 	}
+	sqlStore.SetReplicas(replicas, time.Duration(config.DBReplicaForcePrimaryWindow)*time.Second)
+
+	var db store.Store = sqlStore
 	if config.AuthMode == MattermostAuthMod {
 		layeredStore, err2 := mattermostauthlayer.New(config.DBType, db.(*sqlstore.SQLStore).DBHandle(), db, logger)
 		if err2 != nil {
@@ -217,6 +334,67 @@ func NewStore(config *config.Configuration, logger *mlog.Logger) (store.Store, e
 	return db, nil
 }
 
+// GetPendingMigrations returns the migrations not applied yet to the
+// configured database, without applying them.
+func GetPendingMigrations(config *config.Configuration, logger *mlog.Logger) ([]appModel.Migration, error) {
+	db, err := NewStoreWithoutMigrations(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Shutdown() }()
+	return db.GetPendingMigrations(context.Background())
+}
+
+func openDatabase(config *config.Configuration, logger *mlog.Logger) (*sql.DB, error) {
+	sqlDB, err := sql.Open(config.DBType, config.DBConfigString)
+	if err != nil {
+		logger.Error("connectDatabase failed", mlog.Err(err))
+		return nil, err
+	}
+
+	configurePool(sqlDB, config)
+
+	err = sqlDB.Ping()
+	if err != nil {
+		logger.Error(`Database Ping failed`, mlog.Err(err))
+		return nil, err
+	}
+
+	return sqlDB, nil
+}
+
+// openReplicas opens the read replicas of the database. They aren't
+// pinged, as the store only reads from the replicas that answer its
+// health checks.
+func openReplicas(config *config.Configuration) ([]*sql.DB, error) {
+	replicas := make([]*sql.DB, 0, len(config.DBReplicaConfigStrings))
+	for _, connectionString := range config.DBReplicaConfigStrings {
+		replica, err := sql.Open(config.DBType, connectionString)
+		if err != nil {
+			for _, opened := range replicas {
+				_ = opened.Close()
+			}
+			return nil, err
+		}
+		configurePool(replica, config)
+		replicas = append(replicas, replica)
+	}
+	return replicas, nil
+}
+
+func configurePool(sqlDB *sql.DB, config *config.Configuration) {
+	// zero keeps the database/sql defaults
+	if config.DBMaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(config.DBMaxOpenConns)
+	}
+	if config.DBMaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(config.DBMaxIdleConns)
+	}
+	if config.DBConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(config.DBConnMaxLifetime) * time.Second)
+	}
+}
+
 func (s *Server) Start() error {
 	s.logger.Info("Server.Start")
 
@@ -232,20 +410,205 @@ func (s *Server) Start() error {
 	}
 
 	if s.config.AuthMode != MattermostAuthMod {
+		// the sessions created before the sessions had an expiry expire
+		// one session lifetime from now
+		if err := s.store.SetLegacySessionsExpireAt(s.app.SessionExpireAt(time.Now().Unix())); err != nil {
+			s.logger.Error("Unable to set the expiry of the legacy sessions", mlog.Err(err))
+		}
+
 		s.cleanUpSessionsTask = scheduler.CreateRecurringTask("cleanUpSessions", func() {
-			secondsAgo := minSessionExpiryTime
-			if secondsAgo < s.config.SessionExpireTime {
-				secondsAgo = s.config.SessionExpireTime
+			if err := s.store.DeleteExpiredSessions(); err != nil {
+				s.logger.Error("Unable to clean up the sessions", mlog.Err(err))
 			}
 
-			if err := s.store.CleanUpSessions(secondsAgo); err != nil {
-				s.logger.Error("Unable to clean up the sessions", mlog.Err(err))
+			lockoutWindow := s.config.LoginLockoutWindow
+			if lockoutWindow <= 0 {
+				lockoutWindow = config.DefaultLoginLockoutWindow
+			}
+			staleBefore := time.Now().Add(-time.Duration(lockoutWindow) * time.Second)
+			if err := s.store.DeleteStaleLoginAttempts(staleBefore.UnixNano() / int64(time.Millisecond)); err != nil {
+				s.logger.Error("Unable to clean up the failed logins", mlog.Err(err))
+			}
+
+			if err := s.store.DeleteExpiredPasswordResetTokens(time.Now().UnixNano() / int64(time.Millisecond)); err != nil {
+				s.logger.Error("Unable to clean up the password reset tokens", mlog.Err(err))
+			}
+
+			if err := s.store.DeleteExpiredGuestInvites(time.Now().UnixNano() / int64(time.Millisecond)); err != nil {
+				s.logger.Error("Unable to clean up the guest invites", mlog.Err(err))
 			}
 		}, cleanupSessionTaskFrequency)
 	}
 
+	s.purgeTrashTask = scheduler.CreateRecurringTask("purgeTrash", func() {
+		retentionDays := s.config.TrashRetentionDays
+		if retentionDays <= 0 {
+			retentionDays = defaultTrashRetentionDays
+		}
+
+		deletedBefore := utils.MillisFromTime(time.Now().AddDate(0, 0, -retentionDays))
+		count, err := s.store.PurgeDeletedBlocks(s.jobsContext, deletedBefore)
+		if err != nil {
+			s.logger.Error("Unable to purge the trash", mlog.Err(err))
+			return
+		}
+		if count > 0 {
+			s.logger.Info("Purged blocks from the trash", mlog.Int64("block_count", count))
+		}
+	}, purgeTrashTaskFrequency)
+
+	s.idempotencyKeysTask = scheduler.CreateRecurringTask("cleanUpIdempotencyKeys", func() {
+		if err := s.store.DeleteExpiredIdempotencyKeys(utils.MillisFromTime(time.Now())); err != nil {
+			s.logger.Error("Unable to clean up the idempotency keys", mlog.Err(err))
+		}
+	}, idempotencyKeysTaskFrequency)
+
+	s.dueDateReminderTask = scheduler.CreateRecurringTask("sendDueDateReminders", func() {
+		// only one server of the cluster sends the reminders, the lock
+		// outlives the task period so that it's renewed before expiring
+		expireAt := utils.MillisFromTime(time.Now().Add(2 * dueDateReminderTaskFrequency))
+		acquired, err := s.store.AcquireClusterLock(dueDateReminderLock, s.instanceID, expireAt)
+		if err != nil {
+			s.logger.Error("Unable to acquire the due date reminders lock", mlog.Err(err))
+			return
+		}
+		if !acquired {
+			return
+		}
+
+		if err := s.app.SendDueDateReminders(s.jobsContext); err != nil {
+			s.logger.Error("Unable to send the due date reminders", mlog.Err(err))
+		}
+	}, dueDateReminderTaskFrequency)
+
+	s.cleanupFilesTask = scheduler.CreateRecurringTask("cleanupOrphanedFiles", func() {
+		expireAt := utils.MillisFromTime(time.Now().Add(2 * cleanupFilesTaskFrequency))
+		acquired, err := s.store.AcquireClusterLock(cleanupFilesLock, s.instanceID, expireAt)
+		if err != nil {
+			s.logger.Error("Unable to acquire the files cleanup lock", mlog.Err(err))
+			return
+		}
+		if !acquired {
+			return
+		}
+
+		result, err := s.app.CleanupOrphanedFiles(s.jobsContext, false)
+		if err != nil {
+			s.logger.Error("Unable to clean up the orphaned files", mlog.Err(err))
+			return
+		}
+		if result.FilesRemoved > 0 {
+			s.logger.Info("Removed orphaned files", mlog.Int("file_count", result.FilesRemoved))
+		}
+	}, cleanupFilesTaskFrequency)
+
+	s.recurringCardsTask = scheduler.CreateRecurringTask("createRecurringCards", func() {
+		expireAt := utils.MillisFromTime(time.Now().Add(2 * recurringCardsTaskFrequency))
+		acquired, err := s.store.AcquireClusterLock(recurringCardsLock, s.instanceID, expireAt)
+		if err != nil {
+			s.logger.Error("Unable to acquire the recurring cards lock", mlog.Err(err))
+			return
+		}
+		if !acquired {
+			return
+		}
+
+		if err := s.app.CreateRecurringCards(s.jobsContext); err != nil {
+			s.logger.Error("Unable to create the recurring cards", mlog.Err(err))
+		}
+	}, recurringCardsTaskFrequency)
+
+	// every server caches the usage of the workspaces, so the task runs
+	// without a cluster lock
+	s.workspaceUsageTask = scheduler.CreateRecurringTask("recomputeWorkspaceUsage", func() {
+		if err := s.app.RecomputeWorkspaceUsage(); err != nil {
+			s.logger.Error("Unable to recompute the workspace usage", mlog.Err(err))
+		}
+	}, workspaceUsageTaskFrequency)
+
+	// the legacy option colors are normalized before the boards are
+	// patched
+	if err := s.app.MigratePropertyOptionColors(s.jobsContext); err != nil {
+		s.logger.Error("Unable to migrate the property option colors", mlog.Err(err))
+	}
+
+	// the card orders are converted before the cards are moved
+	if err := s.app.MigrateCardOrders(s.jobsContext); err != nil {
+		s.logger.Error("Unable to migrate the card orders", mlog.Err(err))
+	}
+
+	// every server queues the boards whose order keys got too long, so
+	// the task runs without a cluster lock
+	s.cardOrderTask = scheduler.CreateRecurringTask("rebalanceCardOrders", func() {
+		if err := s.app.RebalanceCardOrders(s.jobsContext); err != nil {
+			s.logger.Error("Unable to rebalance the card orders", mlog.Err(err))
+		}
+	}, cardOrderTaskFrequency)
+
+	// every server queues the large images it received, so the task runs
+	// without a cluster lock
+	s.thumbnailTask = scheduler.CreateRecurringTask("generateThumbnails", func() {
+		if err := s.app.GenerateThumbnails(s.jobsContext); err != nil {
+			s.logger.Error("Unable to generate the thumbnails", mlog.Err(err))
+		}
+	}, thumbnailTaskFrequency)
+
+	if s.config.SMTP.Server != "" {
+		s.digestTask = scheduler.CreateRecurringTask("sendDigests", func() {
+			expireAt := utils.MillisFromTime(time.Now().Add(2 * digestTaskFrequency))
+			acquired, err := s.store.AcquireClusterLock(digestLock, s.instanceID, expireAt)
+			if err != nil {
+				s.logger.Error("Unable to acquire the digests lock", mlog.Err(err))
+				return
+			}
+			if !acquired {
+				return
+			}
+
+			if err := s.app.SendDigests(s.jobsContext); err != nil {
+				s.logger.Error("Unable to send the digests", mlog.Err(err))
+			}
+		}, digestTaskFrequency)
+	}
+
+	if s.config.IntegrationsEncryptionKey != "" {
+		s.gitHubSyncTask = scheduler.CreateRecurringTask("syncGitHubIntegrations", func() {
+			expireAt := utils.MillisFromTime(time.Now().Add(2 * gitHubSyncTaskFrequency))
+			acquired, err := s.store.AcquireClusterLock(gitHubSyncLock, s.instanceID, expireAt)
+			if err != nil {
+				s.logger.Error("Unable to acquire the GitHub sync lock", mlog.Err(err))
+				return
+			}
+			if !acquired {
+				return
+			}
+
+			if err := s.app.SyncGitHubIntegrations(s.jobsContext); err != nil {
+				s.logger.Error("Unable to sync the GitHub integrations", mlog.Err(err))
+			}
+		}, gitHubSyncTaskFrequency)
+	}
+
+	if s.config.WorkspaceRetentionEnabled {
+		s.workspaceRetentionTask = scheduler.CreateRecurringTask("cleanupInactiveWorkspaces", func() {
+			expireAt := utils.MillisFromTime(time.Now().Add(2 * workspaceRetentionTaskFrequency))
+			acquired, err := s.store.AcquireClusterLock(workspaceRetentionLock, s.instanceID, expireAt)
+			if err != nil {
+				s.logger.Error("Unable to acquire the workspace retention lock", mlog.Err(err))
+				return
+			}
+			if !acquired {
+				return
+			}
+
+			if err := s.app.ApplyWorkspaceRetention(s.jobsContext); err != nil {
+				s.logger.Error("Unable to clean up the inactive workspaces", mlog.Err(err))
+			}
+		}, workspaceRetentionTaskFrequency)
+	}
+
 	metricsUpdater := func() {
-		blockCounts, err := s.store.GetBlockCountsByType()
+		blockCounts, err := s.store.GetBlockCountsByType(s.jobsContext)
 		if err != nil {
 			s.logger.Error("Error updating metrics", mlog.String("group", "blocks"), mlog.Err(err))
 			return
@@ -254,7 +617,7 @@ func (s *Server) Start() error {
 		for blockType, count := range blockCounts {
 			s.metricsService.ObserveBlockCount(blockType, count)
 		}
-		workspaceCount, err := s.store.GetWorkspaceCount()
+		workspaceCount, err := s.store.GetWorkspaceCount(s.jobsContext)
 		if err != nil {
 			s.logger.Error("Error updating metrics", mlog.String("group", "workspaces"), mlog.Err(err))
 			return
@@ -289,7 +652,24 @@ func (s *Server) Start() error {
 }
 
 func (s *Server) Shutdown() error {
-	if err := s.webServer.Shutdown(); err != nil {
+	// fail the readiness checks while the requests are finishing
+	s.app.SetDraining()
+
+	// the requests and the running jobs have the grace period to finish,
+	// the queries of the jobs still running after it are aborted
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownGracePeriod())
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		s.cancelJobs()
+	}()
+
+	// the websocket clients reconnect to another server
+	if wsServer, ok := s.wsAdapter.(*ws.Server); ok {
+		wsServer.Shutdown()
+	}
+
+	if err := s.webServer.Shutdown(ctx); err != nil {
 		return err
 	}
 
@@ -302,10 +682,56 @@ func (s *Server) Shutdown() error {
 		s.cleanUpSessionsTask.Cancel()
 	}
 
+	if s.purgeTrashTask != nil {
+		s.purgeTrashTask.Cancel()
+	}
+
+	if s.dueDateReminderTask != nil {
+		s.dueDateReminderTask.Cancel()
+	}
+
+	if s.cleanupFilesTask != nil {
+		s.cleanupFilesTask.Cancel()
+	}
+
+	if s.recurringCardsTask != nil {
+		s.recurringCardsTask.Cancel()
+	}
+
+	if s.workspaceUsageTask != nil {
+		s.workspaceUsageTask.Cancel()
+	}
+
+	if s.cardOrderTask != nil {
+		s.cardOrderTask.Cancel()
+	}
+
+	if s.thumbnailTask != nil {
+		s.thumbnailTask.Cancel()
+	}
+
+	if s.digestTask != nil {
+		s.digestTask.Cancel()
+	}
+
+	if s.gitHubSyncTask != nil {
+		s.gitHubSyncTask.Cancel()
+	}
+
+	if s.idempotencyKeysTask != nil {
+		s.idempotencyKeysTask.Cancel()
+	}
+
+	if s.workspaceRetentionTask != nil {
+		s.workspaceRetentionTask.Cancel()
+	}
+
 	if s.metricsUpdaterTask != nil {
 		s.metricsUpdaterTask.Cancel()
 	}
 
+	s.webhookDispatcher.Shutdown(ctx)
+
 	if err := s.telemetry.Shutdown(); err != nil {
 		s.logger.Warn("Error occurred when shutting down telemetry", mlog.Err(err))
 	}
@@ -319,6 +745,21 @@ func (s *Server) Shutdown() error {
 	return s.store.Shutdown()
 }
 
+func (s *Server) shutdownGracePeriod() time.Duration {
+	if s.config.ShutdownGracePeriod <= 0 {
+		return config.DefaultShutdownGracePeriod * time.Second
+	}
+	return time.Duration(s.config.ShutdownGracePeriod) * time.Second
+}
+
+// ShutdownOnSignal waits for a signal of the channel, then shuts the
+// server down gracefully.
+func (s *Server) ShutdownOnSignal(signals <-chan os.Signal) error {
+	sig := <-signals
+	s.logger.Info("Shutting down", mlog.Stringer("signal", sig))
+	return s.Shutdown()
+}
+
 func (s *Server) Config() *config.Configuration {
 	return s.config
 }
@@ -327,6 +768,12 @@ func (s *Server) Logger() *mlog.Logger {
 	return s.logger
 }
 
+// App returns the app of the server, for the plugin to act on the boards
+// outside of the API.
+func (s *Server) App() *app.App {
+	return s.app
+}
+
 // Local server
 
 func (s *Server) startLocalModeServer() error {
@@ -372,6 +819,7 @@ func (s *Server) GetRootRouter() *mux.Router {
 }
 
 type telemetryOptions struct {
+	ctx         context.Context
 	app         *app.App
 	cfg         *config.Configuration
 	telemetryID string
@@ -381,7 +829,7 @@ type telemetryOptions struct {
 }
 
 func initTelemetry(opts telemetryOptions) *telemetry.Service {
-	telemetryService := telemetry.New(opts.telemetryID, opts.logger)
+	telemetryService := telemetry.New(opts.telemetryID, opts.cfg, opts.logger)
 
 	telemetryService.RegisterTracker("server", func() (telemetry.Tracker, error) {
 		return map[string]interface{}{
@@ -428,7 +876,7 @@ func initTelemetry(opts telemetryOptions) *telemetry.Service {
 		return m, nil
 	})
 	telemetryService.RegisterTracker("blocks", func() (telemetry.Tracker, error) {
-		blockCounts, err := opts.app.GetBlockCountsByType()
+		blockCounts, err := opts.app.GetBlockCountsByType(opts.ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -439,7 +887,7 @@ func initTelemetry(opts telemetryOptions) *telemetry.Service {
 		return m, nil
 	})
 	telemetryService.RegisterTracker("workspaces", func() (telemetry.Tracker, error) {
-		count, err := opts.app.GetWorkspaceCount()
+		count, err := opts.app.GetWorkspaceCount(opts.ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// SignEmbed returns the signature of the embed URL of the board in the
+// workspace, valid until expireAt, computed with the server-side signing
+// key. Changing the key invalidates the signatures computed before.
+func SignEmbed(signingKey, workspaceID, boardID string, expireAt int64) string {
+	mac := hmac.New(sha256.New, []byte(signingKey))
+	fmt.Fprintf(mac, "%s:%s:%d", workspaceID, boardID, expireAt)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyEmbedSignature tells if the signature is the one of the embed
+// URL of the board with the signing key. It doesn't check the expiry.
+func VerifyEmbedSignature(signingKey, workspaceID, boardID string, expireAt int64, signature string) bool {
+	if signingKey == "" || signature == "" {
+		return false
+	}
+	expected := SignEmbed(signingKey, workspaceID, boardID, expireAt)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
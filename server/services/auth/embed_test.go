@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedSignature(t *testing.T) {
+	signature := SignEmbed("key", "0", "board-1", 1000)
+	require.NotEmpty(t, signature)
+	require.True(t, VerifyEmbedSignature("key", "0", "board-1", 1000, signature))
+
+	t.Run("should reject the signatures of other URLs", func(t *testing.T) {
+		require.False(t, VerifyEmbedSignature("key", "0", "board-2", 1000, signature))
+		require.False(t, VerifyEmbedSignature("key", "1", "board-1", 1000, signature))
+		require.False(t, VerifyEmbedSignature("key", "0", "board-1", 2000, signature))
+		require.False(t, VerifyEmbedSignature("key", "0", "board-1", 1000, ""))
+	})
+
+	t.Run("should reject the signatures of a previous key", func(t *testing.T) {
+		require.False(t, VerifyEmbedSignature("new-key", "0", "board-1", 1000, signature))
+	})
+
+	t.Run("should reject all the signatures without a key", func(t *testing.T) {
+		require.False(t, VerifyEmbedSignature("", "0", "board-1", 1000, SignEmbed("", "0", "board-1", 1000)))
+	})
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// TOTPDigits is the number of digits of the TOTP codes.
+	TOTPDigits = 6
+
+	// TOTPPeriod is the time step of the TOTP codes.
+	TOTPPeriod = 30 * time.Second
+
+	// totpSkewSteps is how many steps before and after the current one are
+	// also accepted, for the clocks of the devices that are off.
+	totpSkewSteps = 1
+
+	totpSecretLength = 20
+)
+
+var (
+	ErrInvalidMfaSecret = errors.New("invalid mfa secret")
+
+	totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// GenerateTOTPSecret returns a random base32 encoded TOTP secret.
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPURL returns the otpauth URL of the secret, that the authenticator
+// apps read from a QR code.
+func TOTPURL(issuer, accountName, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", TOTPDigits))
+	params.Set("period", fmt.Sprintf("%d", int(TOTPPeriod.Seconds())))
+
+	label := url.PathEscape(issuer + ":" + accountName)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPCode returns the TOTP code of the secret at the given time, as
+// defined by RFC 6238.
+func TOTPCode(secret string, at time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return totpCode(key, at.Unix()/int64(TOTPPeriod.Seconds())), nil
+}
+
+// ValidateTOTPCode returns true if the code is the TOTP code of the secret
+// at the given time, or one step before or after it.
+func ValidateTOTPCode(secret, code string, at time.Time) (bool, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return false, err
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != TOTPDigits {
+		return false, nil
+	}
+
+	step := at.Unix() / int64(TOTPPeriod.Seconds())
+	valid := false
+	for i := int64(-totpSkewSteps); i <= totpSkewSteps; i++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step+i)), []byte(code)) == 1 {
+			valid = true
+		}
+	}
+	return valid, nil
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidMfaSecret
+	}
+	return key, nil
+}
+
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// dynamic truncation, from RFC 4226
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%mod)
+}
+
+// EncryptMfaSecret encrypts the secret with AES-GCM, using a key derived
+// from the server-side encryption key.
+func EncryptMfaSecret(encryptionKey, secret string) (string, error) {
+	return EncryptSecret(encryptionKey, secret)
+}
+
+// DecryptMfaSecret decrypts a secret encrypted with EncryptMfaSecret.
+func DecryptMfaSecret(encryptionKey, encrypted string) (string, error) {
+	secret, err := DecryptSecret(encryptionKey, encrypted)
+	if errors.Is(err, ErrInvalidSecret) {
+		return "", ErrInvalidMfaSecret
+	}
+	return secret, err
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 secret of the test vectors of RFC 6238.
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	// the last 6 digits of the test vectors of RFC 6238
+	testCases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range testCases {
+		code, err := TOTPCode(rfc6238Secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, expected, code, "time %d", unix)
+	}
+
+	t.Run("invalid secret", func(t *testing.T) {
+		_, err := TOTPCode("not base32!", time.Now())
+		require.ErrorIs(t, err, ErrInvalidMfaSecret)
+	})
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1600000000, 0)
+	code, err := TOTPCode(secret, now)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		at       time.Time
+		code     string
+		expected bool
+	}{
+		{"current step", now, code, true},
+		{"one step behind", now.Add(-TOTPPeriod), code, true},
+		{"one step ahead", now.Add(TOTPPeriod), code, true},
+		{"two steps behind", now.Add(-2 * TOTPPeriod), code, false},
+		{"two steps ahead", now.Add(2 * TOTPPeriod), code, false},
+		{"wrong code", now, "000000", code == "000000"},
+		{"short code", now, code[:5], false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			valid, err := ValidateTOTPCode(secret, tc.code, tc.at)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, valid)
+		})
+	}
+}
+
+func TestTOTPURL(t *testing.T) {
+	url := TOTPURL("Focalboard", "user@example.com", "SECRET")
+	require.Equal(t, "otpauth://totp/Focalboard:user@example.com?algorithm=SHA1&digits=6&issuer=Focalboard&period=30&secret=SECRET", url)
+}
+
+func TestEncryptMfaSecret(t *testing.T) {
+	encrypted, err := EncryptMfaSecret("key", "SECRET")
+	require.NoError(t, err)
+	require.NotContains(t, encrypted, "SECRET")
+
+	secret, err := DecryptMfaSecret("key", encrypted)
+	require.NoError(t, err)
+	require.Equal(t, "SECRET", secret)
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := DecryptMfaSecret("other key", encrypted)
+		require.ErrorIs(t, err, ErrInvalidMfaSecret)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		_, err := EncryptMfaSecret("", "SECRET")
+		require.Error(t, err)
+	})
+}
@@ -7,10 +7,72 @@ import (
 )
 
 const (
-	DefaultServerRoot = "http://localhost:8000"
-	DefaultPort       = 8000
+	DefaultServerRoot          = "http://localhost:8000"
+	DefaultPort                = 8000
+	DefaultDueDatePropertyName = "Due date"
+	DefaultFileRetentionDays   = 7
+	DefaultMaxFileSize         = 100 * 1024 * 1024
+
+	// DefaultWorkspaceInactivityDays is the number of days without
+	// activity after which a workspace is deleted, once its members were
+	// notified DefaultWorkspaceRetentionNoticeDays days before
+	DefaultWorkspaceInactivityDays      = 365
+	DefaultWorkspaceRetentionNoticeDays = 30
+
+	DefaultSessionExpireTime  = 60 * 60 * 24 * 30 // 30 days session lifetime
+	DefaultSessionRefreshTime = 60 * 60 * 5       // 5 hours session refresh
+
+	DefaultRateLimitPerSecond      = 10
+	DefaultRateLimitBurst          = 50
+	DefaultAdminRateLimitPerSecond = 50
+	DefaultAdminRateLimitBurst     = 250
+
+	// DefaultCompressionLevel is the level of the compression of the
+	// API responses, from 1 for the fastest to 9 for the smallest
+	DefaultCompressionLevel = 6
+
+	DefaultLoginLockoutThreshold   = 10
+	DefaultLoginLockoutIPThreshold = 50
+	DefaultLoginLockoutWindow      = 5 * 60  // 5 minutes between the failures
+	DefaultLoginLockoutDuration    = 60      // 1 minute first lockout
+	DefaultLoginLockoutMaxDuration = 60 * 60 // 1 hour longest lockout
+
+	DefaultWebsocketReplayBufferSize = 1000
+
+	DefaultBlockCacheMaxBytes = 64 * 1024 * 1024
+
+	// DefaultSubscriptionNotificationWindow is the time, in seconds, over
+	// which the changes of a watched block collapse into one notification
+	DefaultSubscriptionNotificationWindow = 5 * 60
+
+	DefaultTelemetrySink      = "remote"
+	DefaultTelemetryLocalPath = "./telemetry.jsonl"
+
+	// DefaultShutdownGracePeriod is the time, in seconds, the requests and
+	// the background jobs have to finish on shutdown
+	DefaultShutdownGracePeriod = 30
+
+	DefaultDBMaxOpenConns    = 100
+	DefaultDBMaxIdleConns    = 20
+	DefaultDBConnMaxLifetime = 60 * 60 // 1 hour connection lifetime
+
+	DefaultDBReplicaForcePrimaryWindow = 5 // 5 seconds of reads from the primary after a write
+
+	// DefaultCORSMaxAge is the time, in seconds, the browsers cache the
+	// answers of the preflight requests
+	DefaultCORSMaxAge = 10 * 60
 )
 
+// DefaultCORSAllowedHeaders are the headers the cross-origin requests
+// can send.
+var DefaultCORSAllowedHeaders = []string{
+	"Content-Type",
+	"Authorization",
+	"X-Requested-With",
+	"If-Match",
+	"Idempotency-Key",
+}
+
 type AmazonS3Config struct {
 	AccessKeyID     string
 	SecretAccessKey string
@@ -24,6 +86,48 @@ type AmazonS3Config struct {
 	Trace           bool
 }
 
+// SMTPConfig is the SMTP server used to send the emails.
+// ConnectionSecurity is empty for a plain connection, "STARTTLS" or "TLS".
+type SMTPConfig struct {
+	Server             string
+	Port               int
+	Username           string
+	Password           string
+	FromAddress        string
+	ConnectionSecurity string
+}
+
+// The backends the users log in with their password against.
+const (
+	AuthBackendPassword = "password"
+	AuthBackendLDAP     = "ldap"
+)
+
+// LDAPConfig is the LDAP server the users log in with when the auth
+// backend is "ldap". The DN of a user is built from UserDNTemplate,
+// replacing {username}, or else searched under BaseDN with UserFilter,
+// binding as BindDN first unless it's empty. The attributes are mapped
+// to the users provisioned on their first login, identified by
+// IDAttribute, or by their DN when it's empty.
+// ConnectionSecurity is empty for a plain connection, "STARTTLS" or "TLS".
+type LDAPConfig struct {
+	Server                      string
+	Port                        int
+	ConnectionSecurity          string
+	SkipCertificateVerification bool
+	BindDN                      string
+	BindPassword                string
+	BaseDN                      string
+	UserDNTemplate              string
+	UserFilter                  string
+	IDAttribute                 string
+	UsernameAttribute           string
+	EmailAttribute              string
+	NicknameAttribute           string
+	MaxConnections              int
+	Timeout                     int
+}
+
 // Configuration is the app configuration stored in a json file.
 type Configuration struct {
 	ServerRoot              string         `json:"serverRoot" mapstructure:"serverRoot"`
@@ -31,6 +135,9 @@ type Configuration struct {
 	DBType                  string         `json:"dbtype" mapstructure:"dbtype"`
 	DBConfigString          string         `json:"dbconfig" mapstructure:"dbconfig"`
 	DBTablePrefix           string         `json:"dbtableprefix" mapstructure:"dbtableprefix"`
+	DBMaxOpenConns          int            `json:"dbmaxopenconns" mapstructure:"dbmaxopenconns"`
+	DBMaxIdleConns          int            `json:"dbmaxidleconns" mapstructure:"dbmaxidleconns"`
+	DBConnMaxLifetime       int64          `json:"dbconnmaxlifetime" mapstructure:"dbconnmaxlifetime"`
 	UseSSL                  bool           `json:"useSSL" mapstructure:"useSSL"`
 	SecureCookie            bool           `json:"secureCookie" mapstructure:"secureCookie"`
 	WebPath                 string         `json:"webpath" mapstructure:"webpath"`
@@ -40,6 +147,8 @@ type Configuration struct {
 	Telemetry               bool           `json:"telemetry" mapstructure:"telemetry"`
 	TelemetryID             string         `json:"telemetryid" mapstructure:"telemetryid"`
 	PrometheusAddress       string         `json:"prometheus_address" mapstructure:"prometheus_address"`
+	EnableMetrics           bool           `json:"enable_metrics" mapstructure:"enable_metrics"`
+	MetricsAuthToken        string         `json:"metrics_auth_token" mapstructure:"metrics_auth_token"`
 	WebhookUpdate           []string       `json:"webhook_update" mapstructure:"webhook_update"`
 	Secret                  string         `json:"secret" mapstructure:"secret"`
 	SessionExpireTime       int64          `json:"session_expire_time" mapstructure:"session_expire_time"`
@@ -47,6 +156,97 @@ type Configuration struct {
 	LocalOnly               bool           `json:"localonly" mapstructure:"localonly"`
 	EnableLocalMode         bool           `json:"enableLocalMode" mapstructure:"enableLocalMode"`
 	LocalModeSocketLocation string         `json:"localModeSocketLocation" mapstructure:"localModeSocketLocation"`
+	TrashRetentionDays      int            `json:"trash_retention_days" mapstructure:"trash_retention_days"`
+	DueDatePropertyName     string         `json:"due_date_property_name" mapstructure:"due_date_property_name"`
+	FileRetentionDays       int            `json:"file_retention_days" mapstructure:"file_retention_days"`
+	MaxFileSize             int64          `json:"maxfilesize" mapstructure:"maxfilesize"`
+	AllowedFileExtensions   []string       `json:"allowed_file_extensions" mapstructure:"allowed_file_extensions"`
+	RateLimitPerSecond      float64        `json:"rate_limit_per_second" mapstructure:"rate_limit_per_second"`
+	RateLimitBurst          int            `json:"rate_limit_burst" mapstructure:"rate_limit_burst"`
+	AdminRateLimitPerSecond float64        `json:"admin_rate_limit_per_second" mapstructure:"admin_rate_limit_per_second"`
+	AdminRateLimitBurst     int            `json:"admin_rate_limit_burst" mapstructure:"admin_rate_limit_burst"`
+	CompressionLevel        int            `json:"compression_level" mapstructure:"compression_level"`
+	LoginLockoutThreshold   int            `json:"login_lockout_threshold" mapstructure:"login_lockout_threshold"`
+	LoginLockoutIPThreshold int            `json:"login_lockout_ip_threshold" mapstructure:"login_lockout_ip_threshold"`
+	LoginLockoutWindow      int64          `json:"login_lockout_window" mapstructure:"login_lockout_window"`
+	LoginLockoutDuration    int64          `json:"login_lockout_duration" mapstructure:"login_lockout_duration"`
+	LoginLockoutMaxDuration int64          `json:"login_lockout_max_duration" mapstructure:"login_lockout_max_duration"`
+	SMTP                    SMTPConfig     `json:"smtp" mapstructure:"smtp"`
+	MfaEncryptionKey        string         `json:"mfa_encryption_key" mapstructure:"mfa_encryption_key"`
+	EmbedSigningKey         string         `json:"embed_signing_key" mapstructure:"embed_signing_key"`
+	EmbedFrameAncestors     []string       `json:"embed_frame_ancestors" mapstructure:"embed_frame_ancestors"`
+
+	WebsocketReplayBufferSize int `json:"websocket_replay_buffer_size" mapstructure:"websocket_replay_buffer_size"`
+
+	// the origins allowed to call the API and to open the websocket
+	// from another site, exactly or by a wildcard subdomain pattern like
+	// https://*.example.com, the CORS being disabled when empty
+	CORSAllowedOrigins []string `json:"cors_allowed_origins" mapstructure:"cors_allowed_origins"`
+	CORSAllowedHeaders []string `json:"cors_allowed_headers" mapstructure:"cors_allowed_headers"`
+	CORSMaxAge         int      `json:"cors_max_age" mapstructure:"cors_max_age"`
+
+	// the OpenID Connect provider of the single sign-on, disabled
+	// without an issuer and a client, and the password logins, which can
+	// be disabled once the single sign-on is enforced
+	OIDCIssuerURL        string   `json:"oidc_issuer_url" mapstructure:"oidc_issuer_url"`
+	OIDCClientID         string   `json:"oidc_client_id" mapstructure:"oidc_client_id"`
+	OIDCClientSecret     string   `json:"oidc_client_secret" mapstructure:"oidc_client_secret"`
+	OIDCScopes           []string `json:"oidc_scopes" mapstructure:"oidc_scopes"`
+	DisablePasswordLogin bool     `json:"disable_password_login" mapstructure:"disable_password_login"`
+
+	// the backend the passwords of the logins are checked against, the
+	// local users or the LDAP server
+	AuthBackend string     `json:"auth_backend" mapstructure:"auth_backend"`
+	LDAP        LDAPConfig `json:"ldap" mapstructure:"ldap"`
+
+	// the quotas of each workspace, unlimited when 0
+	MaxBlocksPerWorkspace      int64 `json:"max_blocks_per_workspace" mapstructure:"max_blocks_per_workspace"`
+	MaxFileStoragePerWorkspace int64 `json:"max_file_storage_per_workspace" mapstructure:"max_file_storage_per_workspace"`
+
+	// the cleanup of the workspaces without activity for the number of
+	// days, archived to the files and deleted the number of days after
+	// their members are notified, disabled by default
+	WorkspaceRetentionEnabled    bool `json:"workspace_retention_enabled" mapstructure:"workspace_retention_enabled"`
+	WorkspaceInactivityDays      int  `json:"workspace_inactivity_days" mapstructure:"workspace_inactivity_days"`
+	WorkspaceRetentionNoticeDays int  `json:"workspace_retention_notice_days" mapstructure:"workspace_retention_notice_days"`
+
+	// the cache of the blocks of the boards, disabled by default
+	EnableBlockCache   bool  `json:"enable_block_cache" mapstructure:"enable_block_cache"`
+	BlockCacheMaxBytes int64 `json:"block_cache_max_bytes" mapstructure:"block_cache_max_bytes"`
+
+	// accepts the blocks of the types that aren't known by the server,
+	// without checking them, for the development of new block types
+	EnableExperimentalBlockTypes bool `json:"enable_experimental_block_types" mapstructure:"enable_experimental_block_types"`
+
+	// stops writing the legacy card orders of the views when the cards
+	// are moved, once no older clients are left
+	DisableLegacyCardOrder bool `json:"disable_legacy_card_order" mapstructure:"disable_legacy_card_order"`
+
+	SubscriptionNotificationWindow int64 `json:"subscription_notification_window" mapstructure:"subscription_notification_window"`
+
+	// the key encrypting the tokens of the integrations of the boards,
+	// which are disabled without it, and the API of GitHub, for GitHub
+	// Enterprise
+	IntegrationsEncryptionKey string `json:"integrations_encryption_key" mapstructure:"integrations_encryption_key"`
+	GitHubAPIURL              string `json:"github_api_url" mapstructure:"github_api_url"`
+
+	DBReplicaConfigStrings      []string `json:"dbreplicaconfigs" mapstructure:"dbreplicaconfigs"`
+	DBReplicaForcePrimaryWindow int64    `json:"dbreplica_force_primary_window" mapstructure:"dbreplica_force_primary_window"`
+
+	// where the telemetry is sent, remote, local or disabled, and the
+	// categories of the telemetry that are not sent
+	TelemetrySink          string `json:"telemetry_sink" mapstructure:"telemetry_sink"`
+	TelemetryLocalPath     string `json:"telemetry_local_path" mapstructure:"telemetry_local_path"`
+	TelemetryDisableUsage  bool   `json:"telemetry_disable_usage" mapstructure:"telemetry_disable_usage"`
+	TelemetryDisableErrors bool   `json:"telemetry_disable_errors" mapstructure:"telemetry_disable_errors"`
+
+	// the time, in seconds, the requests and the background jobs have to
+	// finish on shutdown before they're aborted
+	ShutdownGracePeriod int `json:"shutdown_grace_period" mapstructure:"shutdown_grace_period"`
+
+	// the docs UI of the API at /api/v1/docs, disabled by default, the
+	// OpenAPI spec being always served
+	EnableAPIDocs bool `json:"enable_api_docs" mapstructure:"enable_api_docs"`
 
 	AuthMode string `json:"authMode" mapstructure:"authMode"`
 
@@ -69,18 +269,67 @@ func ReadConfigFile() (*Configuration, error) {
 	viper.SetDefault("DBType", "sqlite3")
 	viper.SetDefault("DBConfigString", "./focalboard.db")
 	viper.SetDefault("DBTablePrefix", "")
+	viper.SetDefault("DBMaxOpenConns", DefaultDBMaxOpenConns)
+	viper.SetDefault("DBMaxIdleConns", DefaultDBMaxIdleConns)
+	viper.SetDefault("DBConnMaxLifetime", DefaultDBConnMaxLifetime)
+	viper.SetDefault("DBReplicaConfigStrings", nil)
+	viper.SetDefault("DBReplicaForcePrimaryWindow", DefaultDBReplicaForcePrimaryWindow)
 	viper.SetDefault("SecureCookie", false)
 	viper.SetDefault("WebPath", "./pack")
 	viper.SetDefault("FilesPath", "./files")
 	viper.SetDefault("FilesDriver", "local")
 	viper.SetDefault("Telemetry", true)
 	viper.SetDefault("TelemetryID", "")
+	viper.SetDefault("TelemetrySink", DefaultTelemetrySink)
+	viper.SetDefault("TelemetryLocalPath", DefaultTelemetryLocalPath)
+	viper.SetDefault("TelemetryDisableUsage", false)
+	viper.SetDefault("TelemetryDisableErrors", false)
+	viper.SetDefault("EnableMetrics", false)
+	viper.SetDefault("MetricsAuthToken", "")
 	viper.SetDefault("WebhookUpdate", nil)
-	viper.SetDefault("SessionExpireTime", 60*60*24*30) // 30 days session lifetime
-	viper.SetDefault("SessionRefreshTime", 60*60*5)    // 5 minutes session refresh
+	viper.SetDefault("SessionExpireTime", DefaultSessionExpireTime)
+	viper.SetDefault("SessionRefreshTime", DefaultSessionRefreshTime)
 	viper.SetDefault("LocalOnly", false)
 	viper.SetDefault("EnableLocalMode", false)
 	viper.SetDefault("LocalModeSocketLocation", "/var/tmp/focalboard_local.socket")
+	viper.SetDefault("TrashRetentionDays", 30)
+	viper.SetDefault("DueDatePropertyName", DefaultDueDatePropertyName)
+	viper.SetDefault("FileRetentionDays", DefaultFileRetentionDays)
+	viper.SetDefault("MaxFileSize", DefaultMaxFileSize)
+	viper.SetDefault("AllowedFileExtensions", nil)
+	viper.SetDefault("MaxBlocksPerWorkspace", 0)
+	viper.SetDefault("MaxFileStoragePerWorkspace", 0)
+	viper.SetDefault("WorkspaceRetentionEnabled", false)
+	viper.SetDefault("WorkspaceInactivityDays", DefaultWorkspaceInactivityDays)
+	viper.SetDefault("WorkspaceRetentionNoticeDays", DefaultWorkspaceRetentionNoticeDays)
+	viper.SetDefault("RateLimitPerSecond", DefaultRateLimitPerSecond)
+	viper.SetDefault("RateLimitBurst", DefaultRateLimitBurst)
+	viper.SetDefault("AdminRateLimitPerSecond", DefaultAdminRateLimitPerSecond)
+	viper.SetDefault("AdminRateLimitBurst", DefaultAdminRateLimitBurst)
+	viper.SetDefault("CompressionLevel", DefaultCompressionLevel)
+	viper.SetDefault("EnableBlockCache", false)
+	viper.SetDefault("BlockCacheMaxBytes", DefaultBlockCacheMaxBytes)
+	viper.SetDefault("DisableLegacyCardOrder", false)
+	viper.SetDefault("EnableExperimentalBlockTypes", false)
+	viper.SetDefault("SubscriptionNotificationWindow", DefaultSubscriptionNotificationWindow)
+	viper.SetDefault("ShutdownGracePeriod", DefaultShutdownGracePeriod)
+	viper.SetDefault("EnableAPIDocs", false)
+	viper.SetDefault("LoginLockoutThreshold", DefaultLoginLockoutThreshold)
+	viper.SetDefault("LoginLockoutIPThreshold", DefaultLoginLockoutIPThreshold)
+	viper.SetDefault("LoginLockoutWindow", DefaultLoginLockoutWindow)
+	viper.SetDefault("LoginLockoutDuration", DefaultLoginLockoutDuration)
+	viper.SetDefault("LoginLockoutMaxDuration", DefaultLoginLockoutMaxDuration)
+	viper.SetDefault("WebsocketReplayBufferSize", DefaultWebsocketReplayBufferSize)
+	viper.SetDefault("EmbedFrameAncestors", nil)
+	viper.SetDefault("CORSAllowedOrigins", nil)
+	viper.SetDefault("CORSAllowedHeaders", DefaultCORSAllowedHeaders)
+	viper.SetDefault("CORSMaxAge", DefaultCORSMaxAge)
+	viper.SetDefault("OIDCIssuerURL", "")
+	viper.SetDefault("OIDCClientID", "")
+	viper.SetDefault("OIDCClientSecret", "")
+	viper.SetDefault("OIDCScopes", nil)
+	viper.SetDefault("DisablePasswordLogin", false)
+	viper.SetDefault("AuthBackend", AuthBackendPassword)
 
 	viper.SetDefault("AuthMode", "native")
 
@@ -104,5 +353,29 @@ func ReadConfigFile() (*Configuration, error) {
 
 func removeSecurityData(config Configuration) Configuration {
 	clean := config
+	if clean.FilesS3Config.SecretAccessKey != "" {
+		clean.FilesS3Config.SecretAccessKey = "********"
+	}
+	if clean.MetricsAuthToken != "" {
+		clean.MetricsAuthToken = "********"
+	}
+	if clean.SMTP.Password != "" {
+		clean.SMTP.Password = "********"
+	}
+	if clean.MfaEncryptionKey != "" {
+		clean.MfaEncryptionKey = "********"
+	}
+	if clean.LDAP.BindPassword != "" {
+		clean.LDAP.BindPassword = "********"
+	}
+	if clean.OIDCClientSecret != "" {
+		clean.OIDCClientSecret = "********"
+	}
+	if clean.EmbedSigningKey != "" {
+		clean.EmbedSigningKey = "********"
+	}
+	if clean.IntegrationsEncryptionKey != "" {
+		clean.IntegrationsEncryptionKey = "********"
+	}
 	return clean
 }
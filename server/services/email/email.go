@@ -0,0 +1,212 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package email
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/config"
+)
+
+const (
+	ConnectionSecurityNone     = ""
+	ConnectionSecurityStartTLS = "STARTTLS"
+	ConnectionSecurityTLS      = "TLS"
+
+	defaultSMTPPort = 25
+	dialTimeout     = 10 * time.Second
+)
+
+// Sender sends emails.
+type Sender interface {
+	// Send sends a plain text email
+	Send(to, subject, body string) error
+
+	// SendHTML sends an HTML email, along with its plain text version
+	// for the clients that don't display HTML
+	SendHTML(to, subject, textBody, htmlBody string) error
+}
+
+// SMTPSender sends the emails through an SMTP server.
+type SMTPSender struct {
+	config config.SMTPConfig
+}
+
+// NewSMTPSender returns a sender for the SMTP server, or nil if no server
+// is configured.
+func NewSMTPSender(cfg config.SMTPConfig) *SMTPSender {
+	if cfg.Server == "" {
+		return nil
+	}
+	if cfg.Port == 0 {
+		cfg.Port = defaultSMTPPort
+	}
+	return &SMTPSender{config: cfg}
+}
+
+// Send sends a plain text email to a single recipient.
+func (s *SMTPSender) Send(to, subject, body string) error {
+	return s.send(to, func(from, recipient *mail.Address) ([]byte, error) {
+		return buildMessage(from, recipient, subject, body, time.Now()), nil
+	})
+}
+
+// SendHTML sends an HTML email to a single recipient.
+func (s *SMTPSender) SendHTML(to, subject, textBody, htmlBody string) error {
+	return s.send(to, func(from, recipient *mail.Address) ([]byte, error) {
+		return buildHTMLMessage(from, recipient, subject, textBody, htmlBody, time.Now())
+	})
+}
+
+func (s *SMTPSender) send(to string, build func(from, recipient *mail.Address) ([]byte, error)) error {
+	from, err := mail.ParseAddress(s.config.FromAddress)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+	message, err := build(from, recipient)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.config.Username != "" {
+		auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Server)
+		if err = client.Auth(auth); err != nil {
+			return fmt.Errorf("unable to authenticate to the SMTP server: %w", err)
+		}
+	}
+
+	if err = client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err = client.Rcpt(recipient.Address); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(message); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (s *SMTPSender) connect() (*smtp.Client, error) {
+	address := net.JoinHostPort(s.config.Server, strconv.Itoa(s.config.Port))
+	tlsConfig := &tls.Config{ServerName: s.config.Server, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	switch strings.ToUpper(s.config.ConnectionSecurity) {
+	case ConnectionSecurityTLS:
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", address, tlsConfig)
+	case ConnectionSecurityNone, ConnectionSecurityStartTLS:
+		conn, err = net.DialTimeout("tcp", address, dialTimeout)
+	default:
+		return nil, errors.New("unknown SMTP connection security " + s.config.ConnectionSecurity)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to the SMTP server: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, s.config.Server)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if strings.ToUpper(s.config.ConnectionSecurity) == ConnectionSecurityStartTLS {
+		if err = client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("unable to start TLS with the SMTP server: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+// buildMessage returns the message of a plain text email. The subject is
+// encoded, so that a line break can't inject a header.
+func buildMessage(from, to *mail.Address, subject, body string, date time.Time) []byte {
+	var message bytes.Buffer
+	writeHeaders(&message, from, to, subject, date)
+	message.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(crlf(body))
+	message.WriteString("\r\n")
+	return message.Bytes()
+}
+
+// buildHTMLMessage returns the message of an HTML email, with the plain
+// text version first as the clients display the last part they support.
+func buildHTMLMessage(from, to *mail.Address, subject, textBody, htmlBody string, date time.Time) ([]byte, error) {
+	var parts bytes.Buffer
+	writer := multipart.NewWriter(&parts)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", textBody},
+		{"text/html", htmlBody},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType+"; charset=\"utf-8\"")
+		header.Set("Content-Transfer-Encoding", "8bit")
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := partWriter.Write([]byte(crlf(part.body))); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	writeHeaders(&message, from, to, subject, date)
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n", writer.Boundary())
+	message.WriteString("\r\n")
+	message.Write(parts.Bytes())
+	return message.Bytes(), nil
+}
+
+func writeHeaders(message *bytes.Buffer, from, to *mail.Address, subject string, date time.Time) {
+	fmt.Fprintf(message, "From: %s\r\n", from.String())
+	fmt.Fprintf(message, "To: %s\r\n", to.String())
+	fmt.Fprintf(message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(message, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+}
+
+// crlf returns the text with its line breaks as CRLF.
+func crlf(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n")
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package email
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedEmail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startTestSMTPServer accepts a single plain SMTP session and sends the
+// email it received to the returned channel.
+func startTestSMTPServer(t *testing.T) (string, int, <-chan receivedEmail) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	received := make(chan receivedEmail, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		_ = text.PrintfLine("220 localhost ESMTP")
+
+		var email receivedEmail
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				_ = text.PrintfLine("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				email.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				_ = text.PrintfLine("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				email.to = append(email.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				_ = text.PrintfLine("250 OK")
+			case command == "DATA":
+				_ = text.PrintfLine("354 Go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				email.data = string(data)
+				_ = text.PrintfLine("250 OK")
+			case command == "QUIT":
+				_ = text.PrintfLine("221 Bye")
+				received <- email
+				return
+			default:
+				_ = text.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return host, portNumber, received
+}
+
+func TestNewSMTPSender(t *testing.T) {
+	require.Nil(t, NewSMTPSender(config.SMTPConfig{}))
+
+	sender := NewSMTPSender(config.SMTPConfig{Server: "smtp.example.com"})
+	require.NotNil(t, sender)
+	require.Equal(t, defaultSMTPPort, sender.config.Port)
+}
+
+func TestSMTPSenderSend(t *testing.T) {
+	host, port, received := startTestSMTPServer(t)
+	sender := NewSMTPSender(config.SMTPConfig{
+		Server:      host,
+		Port:        port,
+		FromAddress: "Focalboard <noreply@example.com>",
+	})
+
+	err := sender.Send("user@example.com", "Reset your password", "Hello\n.\nBye")
+	require.NoError(t, err)
+
+	select {
+	case email := <-received:
+		require.Equal(t, "noreply@example.com", email.from)
+		require.Equal(t, []string{"user@example.com"}, email.to)
+
+		message, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(email.data)))
+		require.NoError(t, err)
+		require.Equal(t, "Reset your password", message.Header.Get("Subject"))
+		require.Equal(t, "<user@example.com>", message.Header.Get("To"))
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no email received")
+	}
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		err := sender.Send("not an address", "subject", "body")
+		require.Error(t, err)
+	})
+}
+
+func TestBuildMessage(t *testing.T) {
+	from := &mail.Address{Address: "noreply@example.com"}
+	to := &mail.Address{Address: "user@example.com"}
+
+	message := string(buildMessage(from, to, "Subject\r\nBcc: other@example.com", "line 1\nline 2", time.Unix(0, 0)))
+
+	require.NotContains(t, message, "\r\nBcc:")
+	require.Contains(t, message, "\r\n\r\nline 1\r\nline 2\r\n")
+}
+
+func TestBuildHTMLMessage(t *testing.T) {
+	from := &mail.Address{Address: "noreply@example.com"}
+	to := &mail.Address{Address: "user@example.com"}
+
+	data, err := buildHTMLMessage(from, to, "Digest", "line 1\nline 2", "<p>line 1</p>", time.Unix(0, 0))
+	require.NoError(t, err)
+
+	message, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(string(data))))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for _, expected := range []struct{ contentType, body string }{
+		{"text/plain; charset=\"utf-8\"", "line 1\r\nline 2"},
+		{"text/html; charset=\"utf-8\"", "<p>line 1</p>"},
+	} {
+		part, err := reader.NextPart()
+		require.NoError(t, err)
+		require.Equal(t, expected.contentType, part.Header.Get("Content-Type"))
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		require.Equal(t, expected.body, string(body))
+	}
+	_, err = reader.NextPart()
+	require.ErrorIs(t, err, io.EOF)
+}
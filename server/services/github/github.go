@@ -0,0 +1,280 @@
+// Package github is a client of the parts of the GitHub REST API that
+// the issue sync of the boards uses, and the verification of the GitHub
+// webhooks.
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the URL of the API of github.com.
+const DefaultAPIURL = "https://api.github.com"
+
+const (
+	// SignatureHeader is the header of the HMAC-SHA256 signature of the
+	// webhook payloads, as "sha256=" followed by the hex encoded signature
+	SignatureHeader = "X-Hub-Signature-256"
+
+	// EventHeader is the header of the name of the webhook events
+	EventHeader = "X-GitHub-Event"
+
+	defaultRequestTimeout = 10 * time.Second
+
+	// issuesPageSize is the number of issues per page, the most GitHub
+	// returns
+	issuesPageSize = 100
+
+	// maxIssuesPages bounds the pages read by a single listing, the rest
+	// being read on the next one
+	maxIssuesPages = 10
+
+	// maxErrorBodySize is the size of the error responses read for the
+	// messages of the errors
+	maxErrorBodySize = 4096
+)
+
+var (
+	// ErrUnauthorized is returned when GitHub rejects the token.
+	ErrUnauthorized = errors.New("the GitHub token is invalid or lacks access to the repository")
+
+	// ErrNotFound is returned when the repository or the issue doesn't
+	// exist, or the token can't see it.
+	ErrNotFound = errors.New("the GitHub repository or issue wasn't found")
+
+	repositoryRegexp = regexp.MustCompile(`^[A-Za-z0-9-]+/[A-Za-z0-9_.-]+$`)
+	nextLinkRegexp   = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+)
+
+// RateLimitError is returned when the rate limit of the token is
+// exhausted, until ResetAt.
+type RateLimitError struct {
+	ResetAt time.Time
+}
+
+func (e RateLimitError) Error() string {
+	return fmt.Sprintf("the GitHub rate limit is exceeded until %s", e.ResetAt.UTC().Format(time.RFC3339))
+}
+
+// Label is a label of an issue.
+type Label struct {
+	Name string `json:"name"`
+}
+
+// Issue is an issue of a repository. The pull requests, which the issues
+// API returns as well, have a PullRequest.
+type Issue struct {
+	Number      int64            `json:"number"`
+	Title       string           `json:"title"`
+	Body        string           `json:"body"`
+	State       string           `json:"state"`
+	HTMLURL     string           `json:"html_url"`
+	Labels      []Label          `json:"labels"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	PullRequest *json.RawMessage `json:"pull_request,omitempty"`
+}
+
+// IsPullRequest tells if the issue is a pull request.
+func (i Issue) IsPullRequest() bool {
+	return i.PullRequest != nil
+}
+
+// HasLabels tells if the issue has all the labels, compared without
+// case.
+func (i Issue) HasLabels(labels []string) bool {
+	for _, label := range labels {
+		found := false
+		for _, issueLabel := range i.Labels {
+			if strings.EqualFold(issueLabel.Name, label) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// IssuesEvent is the payload of the "issues" webhook events.
+type IssuesEvent struct {
+	Action     string `json:"action"`
+	Issue      Issue  `json:"issue"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// IsValidRepository tells if the repository is an "owner/name" pair.
+func IsValidRepository(repository string) bool {
+	return repositoryRegexp.MatchString(repository) &&
+		!strings.HasSuffix(repository, "/.") && !strings.HasSuffix(repository, "/..")
+}
+
+// VerifySignature tells if the signature header is the signature of the
+// payload with the secret of the webhook.
+func VerifySignature(secret string, payload []byte, signature string) bool {
+	if secret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// Client calls the GitHub API with the tokens of the integrations.
+type Client struct {
+	apiURL     string
+	httpClient *http.Client
+}
+
+// NewClient returns a client of the API at the URL, the one of
+// github.com if it's empty.
+func NewClient(apiURL string) *Client {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	return &Client{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		httpClient: &http.Client{Timeout: defaultRequestTimeout},
+	}
+}
+
+// ListIssues returns the issues of the repository updated since the
+// time, all of them if it's zero, with all the labels, open and closed,
+// the pull requests excluded. It reads a bounded number of pages, and
+// tells if more issues are left.
+func (c *Client) ListIssues(ctx context.Context, token, repository string, labels []string, since time.Time) ([]Issue, bool, error) {
+	query := url.Values{}
+	query.Set("state", "all")
+	query.Set("sort", "updated")
+	query.Set("direction", "asc")
+	query.Set("per_page", strconv.Itoa(issuesPageSize))
+	if len(labels) > 0 {
+		query.Set("labels", strings.Join(labels, ","))
+	}
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339))
+	}
+	pageURL := c.apiURL + "/repos/" + repository + "/issues?" + query.Encode()
+
+	issues := []Issue{}
+	for page := 0; page < maxIssuesPages && pageURL != ""; page++ {
+		response, err := c.do(ctx, http.MethodGet, pageURL, token, nil)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var pageIssues []Issue
+		err = json.NewDecoder(response.Body).Decode(&pageIssues)
+		response.Body.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to decode the GitHub issues: %w", err)
+		}
+		for _, issue := range pageIssues {
+			if !issue.IsPullRequest() {
+				issues = append(issues, issue)
+			}
+		}
+
+		pageURL = ""
+		if match := nextLinkRegexp.FindStringSubmatch(response.Header.Get("Link")); match != nil {
+			pageURL = match[1]
+		}
+	}
+
+	return issues, pageURL != "", nil
+}
+
+// CreateComment comments the issue of the repository.
+func (c *Client) CreateComment(ctx context.Context, token, repository string, number int64, body string) error {
+	data, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+
+	commentURL := fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.apiURL, repository, number)
+	response, err := c.do(ctx, http.MethodPost, commentURL, token, data)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+// do sends the request, and turns the error responses into errors, the
+// exhausted rate limits into a RateLimitError.
+func (c *Client) do(ctx context.Context, method, requestURL, token string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vnd.github.v3+json")
+	request.Header.Set("Authorization", "token "+token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode < http.StatusBadRequest {
+		return response, nil
+	}
+	defer response.Body.Close()
+
+	if resetAt, limited := rateLimitReset(response); limited {
+		return nil, RateLimitError{ResetAt: resetAt}
+	}
+	switch response.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrUnauthorized
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	}
+
+	message, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+	return nil, fmt.Errorf("GitHub returned the status %d: %s", response.StatusCode, strings.TrimSpace(string(message)))
+}
+
+// rateLimitReset tells if the error response is due to an exhausted rate
+// limit, the primary one telling when it's reset, and the secondary ones
+// how long to wait.
+func rateLimitReset(response *http.Response) (time.Time, bool) {
+	if response.StatusCode != http.StatusForbidden && response.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+
+	if retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+		return time.Now().Add(time.Duration(retryAfter) * time.Second), true
+	}
+	if response.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err == nil {
+			return time.Unix(reset, 0), true
+		}
+		return time.Now().Add(time.Minute), true
+	}
+	return time.Time{}, response.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListIssues(t *testing.T) {
+	t.Run("should read the pages and skip the pull requests", func(t *testing.T) {
+		var ts *httptest.Server
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/repos/owner/repo/issues", r.URL.Path)
+			require.Equal(t, "token secret-token", r.Header.Get("Authorization"))
+			if r.URL.Query().Get("page") == "" {
+				require.Equal(t, "all", r.URL.Query().Get("state"))
+				require.Equal(t, "bug,ui", r.URL.Query().Get("labels"))
+				require.Equal(t, "2021-06-01T10:00:00Z", r.URL.Query().Get("since"))
+				w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/issues?page=2>; rel="next"`, ts.URL))
+				_, _ = w.Write([]byte(`[{"number":1,"title":"First","state":"open","labels":[{"name":"bug"}]},{"number":2,"pull_request":{}}]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[{"number":3,"title":"Third","state":"closed"}]`))
+		}))
+		defer ts.Close()
+
+		since := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+		issues, more, err := NewClient(ts.URL).ListIssues(context.Background(), "secret-token", "owner/repo", []string{"bug", "ui"}, since)
+		require.NoError(t, err)
+		require.False(t, more)
+		require.Len(t, issues, 2)
+		require.Equal(t, int64(1), issues[0].Number)
+		require.Equal(t, "bug", issues[0].Labels[0].Name)
+		require.Equal(t, int64(3), issues[1].Number)
+		require.Equal(t, "closed", issues[1].State)
+	})
+
+	t.Run("should return the reset of an exhausted rate limit", func(t *testing.T) {
+		reset := time.Now().Add(time.Hour).Unix()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer ts.Close()
+
+		_, _, err := NewClient(ts.URL).ListIssues(context.Background(), "secret-token", "owner/repo", nil, time.Time{})
+		var rateLimitErr RateLimitError
+		require.ErrorAs(t, err, &rateLimitErr)
+		require.Equal(t, reset, rateLimitErr.ResetAt.Unix())
+	})
+
+	t.Run("should wait for the secondary rate limits", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer ts.Close()
+
+		_, _, err := NewClient(ts.URL).ListIssues(context.Background(), "secret-token", "owner/repo", nil, time.Time{})
+		var rateLimitErr RateLimitError
+		require.ErrorAs(t, err, &rateLimitErr)
+		require.WithinDuration(t, time.Now().Add(time.Minute), rateLimitErr.ResetAt, 5*time.Second)
+	})
+
+	t.Run("should reject an invalid token", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+
+		_, _, err := NewClient(ts.URL).ListIssues(context.Background(), "secret-token", "owner/repo", nil, time.Time{})
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestCreateComment(t *testing.T) {
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/repos/owner/repo/issues/7/comments", r.URL.Path)
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	err := NewClient(ts.URL).CreateComment(context.Background(), "secret-token", "owner/repo", 7, "Closed on the board")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"body":"Closed on the board"}`, string(body))
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"action":"opened"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	require.True(t, VerifySignature("secret", payload, signature))
+	require.False(t, VerifySignature("other", payload, signature))
+	require.False(t, VerifySignature("secret", []byte(`{}`), signature))
+	require.False(t, VerifySignature("secret", payload, "sha1=abc"))
+	require.False(t, VerifySignature("", payload, signature))
+}
+
+func TestIsValidRepository(t *testing.T) {
+	require.True(t, IsValidRepository("mattermost/focalboard"))
+	require.False(t, IsValidRepository("focalboard"))
+	require.False(t, IsValidRepository("mattermost/focalboard/issues"))
+	require.False(t, IsValidRepository("../repos"))
+	require.False(t, IsValidRepository("mattermost/.."))
+}
@@ -0,0 +1,559 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// The fields of the blocks imported from Asana holding the gid of the
+// Asana object they were imported from, which keys them when the same
+// export is imported again.
+const (
+	AsanaProjectGIDField = "asanaProjectGid"
+	AsanaGIDField        = "asanaGid"
+)
+
+const (
+	asanaDefaultBoardTitle = "Asana import"
+
+	// the suffix of the keys of the description blocks, which have the
+	// gid of their task
+	asanaNotesKeySuffix = "/notes"
+)
+
+// asanaColors maps the colors of the Asana options to the option colors.
+var asanaColors = map[string]string{
+	"red":           "propColorRed",
+	"orange":        "propColorOrange",
+	"yellow-orange": "propColorOrange",
+	"yellow":        "propColorYellow",
+	"yellow-green":  "propColorGreen",
+	"green":         "propColorGreen",
+	"blue-green":    "propColorGreen",
+	"aqua":          "propColorBlue",
+	"blue":          "propColorBlue",
+	"indigo":        "propColorPurple",
+	"purple":        "propColorPurple",
+	"magenta":       "propColorPink",
+	"hot-pink":      "propColorPink",
+	"pink":          "propColorPink",
+	"cool-gray":     "propColorGray",
+}
+
+// AsanaExport is the JSON export of the tasks of an Asana project.
+type AsanaExport struct {
+	Data []AsanaTask `json:"data"`
+}
+
+type AsanaTask struct {
+	GID          string             `json:"gid"`
+	Name         string             `json:"name"`
+	Notes        string             `json:"notes"`
+	Completed    bool               `json:"completed"`
+	Assignee     *AsanaUser         `json:"assignee"`
+	DueOn        string             `json:"due_on"`
+	DueAt        string             `json:"due_at"`
+	Parent       *AsanaObject       `json:"parent"`
+	Projects     []AsanaObject      `json:"projects"`
+	Memberships  []AsanaMembership  `json:"memberships"`
+	CustomFields []AsanaCustomField `json:"custom_fields"`
+	Subtasks     []AsanaTask        `json:"subtasks"`
+	Stories      []AsanaStory       `json:"stories"`
+}
+
+// AsanaObject is a reference to an Asana object, like a project or a
+// section.
+type AsanaObject struct {
+	GID  string `json:"gid"`
+	Name string `json:"name"`
+}
+
+type AsanaUser struct {
+	GID   string `json:"gid"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type AsanaMembership struct {
+	Project AsanaObject `json:"project"`
+	Section AsanaObject `json:"section"`
+}
+
+type AsanaCustomField struct {
+	GID             string            `json:"gid"`
+	Name            string            `json:"name"`
+	Type            string            `json:"type"`
+	ResourceSubtype string            `json:"resource_subtype"`
+	DisplayValue    *string           `json:"display_value"`
+	TextValue       *string           `json:"text_value"`
+	NumberValue     *float64          `json:"number_value"`
+	EnumValue       *AsanaEnumOption  `json:"enum_value"`
+	MultiEnumValues []AsanaEnumOption `json:"multi_enum_values"`
+	DateValue       *struct {
+		Date     string `json:"date"`
+		DateTime string `json:"date_time"`
+	} `json:"date_value"`
+	PeopleValue []AsanaUser `json:"people_value"`
+}
+
+type AsanaEnumOption struct {
+	GID   string `json:"gid"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type AsanaStory struct {
+	GID             string     `json:"gid"`
+	Type            string     `json:"type"`
+	ResourceSubtype string     `json:"resource_subtype"`
+	Text            string     `json:"text"`
+	CreatedBy       *AsanaUser `json:"created_by"`
+}
+
+// FieldType returns the property type of the custom field, the text for
+// the types that have no property type.
+func (f AsanaCustomField) FieldType() string {
+	fieldType := f.ResourceSubtype
+	if fieldType == "" {
+		fieldType = f.Type
+	}
+	switch fieldType {
+	case "number", "date":
+		return fieldType
+	case "enum":
+		return "select"
+	case "multi_enum":
+		return "multiSelect"
+	case "people":
+		return "person"
+	}
+	return "text"
+}
+
+// IsComment tells if the story is a comment, the other stories being the
+// changes of the task.
+func (s AsanaStory) IsComment() bool {
+	return s.Type == "comment" || s.ResourceSubtype == "comment_added"
+}
+
+// Project returns the project of the tasks of the export, the first
+// one of the first task.
+func (e AsanaExport) Project() AsanaObject {
+	for _, task := range e.Data {
+		for _, membership := range task.Memberships {
+			if membership.Project.GID != "" {
+				return membership.Project
+			}
+		}
+		if len(task.Projects) > 0 {
+			return task.Projects[0]
+		}
+	}
+	return AsanaObject{}
+}
+
+// asanaProperty is a card property of the imported board, with its
+// options by value.
+type asanaProperty struct {
+	template  map[string]interface{}
+	optionIDs map[string]string
+}
+
+// asanaConverter converts the tasks of an export, reusing the IDs of the
+// blocks of a previous import of the export.
+type asanaConverter struct {
+	resolveUser func(string) string
+	summary     *model.ImportSummary
+	now         int64
+	boardID     string
+
+	// the IDs of the previously imported blocks by gid, and the previous
+	// properties of the board by name
+	previousIDs        map[string]string
+	previousProperties map[string]map[string]interface{}
+
+	properties []*asanaProperty
+	byName     map[string]*asanaProperty
+}
+
+// ConvertAsana converts the tasks of an Asana project export to the
+// cards of a board, where the sections are the options of a select
+// property, the subtasks are checkboxes and the comments are comment
+// blocks, whose text starts with the name of their author. The custom
+// fields are properties of their type, or text properties. The assignees
+// and the people fields are resolved to users with the function, using
+// their email address.
+//
+// The previous blocks are the ones of the board of a previous import of
+// the project, whose blocks are updated rather than created again: the
+// returned blocks have their IDs. The comments are returned apart, as
+// they're attributed to the imported user.
+func ConvertAsana(export AsanaExport, previous []model.Block, resolveUser func(string) string) ([]model.Block, []model.Block, *model.ImportSummary) {
+	c := &asanaConverter{
+		resolveUser:        resolveUser,
+		summary:            &model.ImportSummary{Skipped: []model.ImportSkippedItem{}},
+		now:                utils.GetMillis(),
+		previousIDs:        map[string]string{},
+		previousProperties: map[string]map[string]interface{}{},
+		byName:             map[string]*asanaProperty{},
+	}
+
+	var previousBoard *model.Block
+	hasView := false
+	for i, block := range previous {
+		switch {
+		case block.Type == "board":
+			previousBoard = &previous[i]
+		case block.Type == "view":
+			hasView = true
+		}
+		if gid, _ := block.Fields[AsanaGIDField].(string); gid != "" {
+			c.previousIDs[gid] = block.ID
+		}
+	}
+	previousCardProperties := []interface{}{}
+	if previousBoard != nil {
+		c.boardID = previousBoard.ID
+		previousCardProperties, _ = previousBoard.Fields["cardProperties"].([]interface{})
+		for _, item := range previousCardProperties {
+			if template, ok := item.(map[string]interface{}); ok {
+				if name, ok := template["name"].(string); ok {
+					c.previousProperties[name] = template
+				}
+			}
+		}
+	} else {
+		c.boardID = utils.CreateGUID()
+		c.summary.BoardsCreated = 1
+	}
+
+	project := export.Project()
+	sectionProperty := c.property("Section", "select")
+	completedProperty := c.property("Completed", "checkbox")
+	assigneeProperty := c.property("Assignee", "person")
+	dueProperty := c.property("Due date", "date")
+
+	cards := []model.Block{}
+	content := []model.Block{}
+	comments := []model.Block{}
+	cardOrder := []interface{}{}
+	for _, task := range export.Data {
+		// the subtasks can be listed along with the tasks
+		if task.Parent != nil && task.Parent.GID != "" {
+			continue
+		}
+
+		properties := map[string]interface{}{}
+		for _, membership := range task.Memberships {
+			if membership.Section.Name != "" && (project.GID == "" || membership.Project.GID == project.GID) {
+				properties[sectionProperty.id()] = sectionProperty.optionID(membership.Section.Name, "")
+				break
+			}
+		}
+		if task.Completed {
+			properties[completedProperty.id()] = "true"
+		}
+		if task.Assignee != nil {
+			if userID := c.user(task.GID, "Assignee", *task.Assignee); userID != "" {
+				properties[assigneeProperty.id()] = userID
+			}
+		}
+		if date, ok := asanaDate(task.DueOn, task.DueAt); ok {
+			properties[dueProperty.id()] = date
+		}
+		for _, field := range task.CustomFields {
+			property := c.property(field.Name, field.FieldType())
+			if property.template["type"] != field.FieldType() {
+				c.summary.Skip("value", task.GID, field.Name, "the board has another property with the name of the field")
+				continue
+			}
+			if value := c.customFieldValue(task.GID, property, field); value != nil {
+				properties[property.id()] = value
+			}
+		}
+
+		card := c.newBlock("card", c.boardID, task.GID, task.Name, map[string]interface{}{
+			"icon":         "",
+			"isTemplate":   false,
+			"properties":   properties,
+			"contentOrder": []interface{}{},
+		})
+		cardOrder = append(cardOrder, card.ID)
+
+		contentOrder := []interface{}{}
+		if notes := strings.TrimSpace(task.Notes); notes != "" {
+			text := c.newBlock("text", card.ID, task.GID+asanaNotesKeySuffix, notes, map[string]interface{}{})
+			contentOrder = append(contentOrder, text.ID)
+			content = append(content, text)
+		}
+		for _, subtask := range task.Subtasks {
+			checkbox := c.newBlock("checkbox", card.ID, subtask.GID, subtask.Name, map[string]interface{}{"value": subtask.Completed})
+			contentOrder = append(contentOrder, checkbox.ID)
+			content = append(content, checkbox)
+		}
+		card.Fields["contentOrder"] = contentOrder
+
+		// the comments aren't part of the content order
+		for _, story := range task.Stories {
+			if !story.IsComment() || strings.TrimSpace(story.Text) == "" {
+				continue
+			}
+			text := story.Text
+			if story.CreatedBy != nil && story.CreatedBy.Name != "" {
+				text = fmt.Sprintf("**%s**: %s", story.CreatedBy.Name, text)
+			}
+			comments = append(comments, c.newBlock("comment", card.ID, story.GID, text, map[string]interface{}{}))
+			if _, ok := c.previousIDs[story.GID]; !ok {
+				c.summary.CommentsCreated++
+			}
+		}
+
+		if _, ok := c.previousIDs[task.GID]; !ok {
+			c.summary.CardsCreated++
+		}
+		cards = append(cards, card)
+	}
+
+	// the properties added to the board since the previous import are
+	// kept
+	cardProperties := []interface{}{}
+	for _, property := range c.properties {
+		cardProperties = append(cardProperties, property.template)
+	}
+	for _, item := range previousCardProperties {
+		template, _ := item.(map[string]interface{})
+		name, _ := template["name"].(string)
+		if _, ok := c.byName[name]; !ok {
+			cardProperties = append(cardProperties, item)
+		}
+	}
+
+	title := project.Name
+	if title == "" {
+		title = asanaDefaultBoardTitle
+	}
+	boardFields := map[string]interface{}{
+		"icon":               "",
+		"description":        "",
+		"showDescription":    false,
+		"isTemplate":         false,
+		"columnCalculations": map[string]interface{}{},
+	}
+	if previousBoard != nil {
+		for key, value := range previousBoard.Fields {
+			boardFields[key] = value
+		}
+	}
+	boardFields["cardProperties"] = cardProperties
+	boardFields[AsanaProjectGIDField] = project.GID
+	board := model.Block{
+		ID:       c.boardID,
+		RootID:   c.boardID,
+		Schema:   1,
+		Type:     "board",
+		Title:    title,
+		Fields:   boardFields,
+		CreateAt: c.now,
+		UpdateAt: c.now,
+	}
+
+	blocks := []model.Block{board}
+	if !hasView {
+		visibleOptionIDs := []interface{}{}
+		for _, option := range sectionProperty.template["options"].([]interface{}) {
+			visibleOptionIDs = append(visibleOptionIDs, option.(map[string]interface{})["id"])
+		}
+		visiblePropertyIDs := []interface{}{}
+		for _, property := range c.properties[1:] {
+			visiblePropertyIDs = append(visiblePropertyIDs, property.id())
+		}
+		blocks = append(blocks, c.newBlock("view", c.boardID, "", "Board View", map[string]interface{}{
+			"viewType":           "board",
+			"groupById":          sectionProperty.id(),
+			"sortOptions":        []interface{}{},
+			"visiblePropertyIds": visiblePropertyIDs,
+			"visibleOptionIds":   visibleOptionIDs,
+			"hiddenOptionIds":    []interface{}{},
+			"collapsedOptionIds": []interface{}{},
+			"filter":             map[string]interface{}{"operation": "and", "filters": []interface{}{}},
+			"cardOrder":          cardOrder,
+			"columnWidths":       map[string]interface{}{},
+			"columnCalculations": map[string]interface{}{},
+			"kanbanCalculations": map[string]interface{}{},
+			"defaultTemplateId":  "",
+		}))
+	}
+	blocks = append(blocks, cards...)
+	return append(blocks, content...), comments, c.summary
+}
+
+// newBlock returns a block of the board keyed by the gid, with the ID of
+// the block of the previous import with the gid, if any.
+func (c *asanaConverter) newBlock(blockType, parentID, gid, title string, fields map[string]interface{}) model.Block {
+	id, ok := c.previousIDs[gid]
+	if !ok || gid == "" {
+		id = utils.CreateGUID()
+	}
+	if gid != "" {
+		fields[AsanaGIDField] = gid
+	}
+	return model.Block{
+		ID:       id,
+		ParentID: parentID,
+		RootID:   c.boardID,
+		Schema:   1,
+		Type:     blockType,
+		Title:    title,
+		Fields:   fields,
+		CreateAt: c.now,
+		UpdateAt: c.now,
+	}
+}
+
+// property returns the property with the name, adding it with the type
+// if it's new. A property of the previous import with the same name and
+// type keeps its ID and options.
+func (c *asanaConverter) property(name, propertyType string) *asanaProperty {
+	if property, ok := c.byName[name]; ok {
+		return property
+	}
+
+	property := &asanaProperty{
+		template: map[string]interface{}{
+			"id":      utils.CreateGUID(),
+			"name":    name,
+			"type":    propertyType,
+			"options": []interface{}{},
+		},
+		optionIDs: map[string]string{},
+	}
+	if previous, ok := c.previousProperties[name]; ok && previous["type"] == propertyType {
+		property.template["id"] = previous["id"]
+		options, _ := previous["options"].([]interface{})
+		property.template["options"] = append([]interface{}{}, options...)
+		for _, item := range options {
+			option, _ := item.(map[string]interface{})
+			if value, ok := option["value"].(string); ok {
+				property.optionIDs[value], _ = option["id"].(string)
+			}
+		}
+	}
+	c.properties = append(c.properties, property)
+	c.byName[name] = property
+	return property
+}
+
+func (p *asanaProperty) id() string {
+	return p.template["id"].(string)
+}
+
+// optionID returns the ID of the option of the property for the value,
+// adding the option with the Asana color if it's new.
+func (p *asanaProperty) optionID(value, asanaColor string) string {
+	if id, ok := p.optionIDs[value]; ok {
+		return id
+	}
+
+	options := p.template["options"].([]interface{})
+	color, ok := asanaColors[asanaColor]
+	if !ok {
+		color = listColors[len(options)%len(listColors)]
+	}
+	id := utils.CreateGUID()
+	p.template["options"] = append(options, map[string]interface{}{
+		"id":    id,
+		"value": value,
+		"color": color,
+	})
+	p.optionIDs[value] = id
+	return id
+}
+
+// user returns the ID of the user of the workspace with the email
+// address of the Asana user, or skips the value of the property.
+func (c *asanaConverter) user(taskGID, propertyName string, user AsanaUser) string {
+	userID := ""
+	if user.Email != "" {
+		userID = c.resolveUser(user.Email)
+	}
+	if userID == "" {
+		c.summary.Skip("value", taskGID, propertyName, fmt.Sprintf("no user of the workspace matches %q", user.Name))
+	}
+	return userID
+}
+
+// customFieldValue returns the value of the property of the custom
+// field, or nil if it has none.
+func (c *asanaConverter) customFieldValue(taskGID string, property *asanaProperty, field AsanaCustomField) interface{} {
+	switch field.FieldType() {
+	case "number":
+		if field.NumberValue != nil {
+			return strconv.FormatFloat(*field.NumberValue, 'f', -1, 64)
+		}
+		return nil
+	case "date":
+		if field.DateValue != nil {
+			if date, ok := asanaDate(field.DateValue.Date, field.DateValue.DateTime); ok {
+				return date
+			}
+		}
+		return nil
+	case "select":
+		if field.EnumValue != nil {
+			return property.optionID(field.EnumValue.Name, field.EnumValue.Color)
+		}
+		return nil
+	case "multiSelect":
+		if len(field.MultiEnumValues) == 0 {
+			return nil
+		}
+		ids := []interface{}{}
+		for _, option := range field.MultiEnumValues {
+			ids = append(ids, property.optionID(option.Name, option.Color))
+		}
+		return ids
+	case "person":
+		// the person properties have a single user
+		for _, user := range field.PeopleValue {
+			if userID := c.user(taskGID, field.Name, user); userID != "" {
+				return userID
+			}
+		}
+		return nil
+	}
+
+	text := field.TextValue
+	if text == nil {
+		text = field.DisplayValue
+	}
+	if text == nil || *text == "" {
+		return nil
+	}
+	return *text
+}
+
+// asanaDate returns the value of a date property for the date or the
+// time of a date, the time if both are set.
+func asanaDate(date, dateTime string) (string, bool) {
+	var value time.Time
+	var err error
+	switch {
+	case dateTime != "":
+		value, err = time.Parse(time.RFC3339, dateTime)
+	case date != "":
+		value, err = time.Parse("2006-01-02", date)
+	default:
+		return "", false
+	}
+	if err != nil {
+		return "", false
+	}
+	data, _ := json.Marshal(map[string]int64{"from": utils.MillisFromTime(value)})
+	return string(data), true
+}
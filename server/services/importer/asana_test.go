@@ -0,0 +1,202 @@
+package importer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+const asanaExport = `{
+	"data": [
+		{
+			"gid": "task-1",
+			"name": "First",
+			"notes": "Some notes",
+			"completed": true,
+			"assignee": {"gid": "user-1", "name": "Some User", "email": "user@example.com"},
+			"due_on": "2021-10-14",
+			"memberships": [{"project": {"gid": "project-1", "name": "Project"}, "section": {"gid": "section-1", "name": "To Do"}}],
+			"custom_fields": [
+				{"gid": "field-1", "name": "Priority", "resource_subtype": "enum", "enum_value": {"gid": "option-1", "name": "High", "color": "red"}},
+				{"gid": "field-2", "name": "Estimate", "resource_subtype": "number", "number_value": 2.5},
+				{"gid": "field-3", "name": "Formula", "resource_subtype": "formula", "display_value": "42"}
+			],
+			"subtasks": [
+				{"gid": "subtask-1", "name": "Step", "completed": true}
+			],
+			"stories": [
+				{"gid": "story-1", "type": "comment", "text": "Looks good", "created_by": {"gid": "user-2", "name": "Someone Else"}},
+				{"gid": "story-2", "type": "system", "text": "changed the due date"}
+			]
+		},
+		{
+			"gid": "task-2",
+			"name": "Second",
+			"assignee": {"gid": "user-2", "name": "Someone Else", "email": "else@example.com"},
+			"memberships": [{"project": {"gid": "project-1", "name": "Project"}, "section": {"gid": "section-2", "name": "Done"}}],
+			"custom_fields": [
+				{"gid": "field-1", "name": "Priority", "resource_subtype": "enum", "enum_value": null}
+			]
+		},
+		{
+			"gid": "subtask-1",
+			"name": "Step",
+			"parent": {"gid": "task-1", "name": "First"}
+		}
+	]
+}`
+
+func TestConvertAsana(t *testing.T) {
+	var export AsanaExport
+	require.NoError(t, json.Unmarshal([]byte(asanaExport), &export))
+	resolveUser := func(email string) string {
+		if email == "user@example.com" {
+			return "user-id"
+		}
+		return ""
+	}
+
+	blocks, comments, summary := ConvertAsana(export, nil, resolveUser)
+	require.Equal(t, 1, summary.BoardsCreated)
+	require.Equal(t, 2, summary.CardsCreated)
+	require.Equal(t, 1, summary.CommentsCreated)
+	// the assignee of the second task isn't a user of the workspace
+	require.Len(t, summary.Skipped, 1)
+	require.Equal(t, "task-2", summary.Skipped[0].ID)
+
+	byType := map[string][]model.Block{}
+	cards := map[string]model.Block{}
+	content := map[string]model.Block{}
+	for _, block := range blocks {
+		byType[block.Type] = append(byType[block.Type], block)
+		content[block.ID] = block
+		if block.Type == "card" {
+			cards[block.Title] = block
+		}
+	}
+	require.Len(t, byType["board"], 1)
+	require.Len(t, byType["view"], 1)
+	board := byType["board"][0]
+	require.Equal(t, "Project", board.Title)
+	require.Equal(t, "project-1", board.Fields[AsanaProjectGIDField])
+
+	types := map[string]string{}
+	properties := map[string]map[string]interface{}{}
+	for _, item := range board.Fields["cardProperties"].([]interface{}) {
+		property := item.(map[string]interface{})
+		types[property["name"].(string)] = property["type"].(string)
+		properties[property["name"].(string)] = property
+	}
+	require.Equal(t, map[string]string{
+		"Section":   "select",
+		"Completed": "checkbox",
+		"Assignee":  "person",
+		"Due date":  "date",
+		"Priority":  "select",
+		"Estimate":  "number",
+		"Formula":   "text",
+	}, types)
+	require.Equal(t, properties["Section"]["id"], byType["view"][0].Fields["groupById"])
+
+	propertyID := func(name string) string {
+		return properties[name]["id"].(string)
+	}
+	sections := optionIDsByValue(properties["Section"])
+	priorities := optionIDsByValue(properties["Priority"])
+
+	require.Len(t, cards, 2)
+	first := cards["First"]
+	require.Equal(t, "task-1", first.Fields[AsanaGIDField])
+	require.Equal(t, map[string]interface{}{
+		propertyID("Section"):   sections["To Do"],
+		propertyID("Completed"): "true",
+		propertyID("Assignee"):  "user-id",
+		propertyID("Due date"):  `{"from":1634169600000}`,
+		propertyID("Priority"):  priorities["High"],
+		propertyID("Estimate"):  "2.5",
+		propertyID("Formula"):   "42",
+	}, first.Fields["properties"])
+	require.Equal(t, "propColorRed", properties["Priority"]["options"].([]interface{})[0].(map[string]interface{})["color"])
+	require.Equal(t, map[string]interface{}{
+		propertyID("Section"): sections["Done"],
+	}, cards["Second"].Fields["properties"])
+
+	contentOrder := first.Fields["contentOrder"].([]interface{})
+	require.Len(t, contentOrder, 2)
+	require.Equal(t, "Some notes", content[contentOrder[0].(string)].Title)
+	checkbox := content[contentOrder[1].(string)]
+	require.Equal(t, "checkbox", checkbox.Type)
+	require.Equal(t, "Step", checkbox.Title)
+	require.Equal(t, true, checkbox.Fields["value"])
+
+	require.Len(t, comments, 1)
+	require.Equal(t, "comment", comments[0].Type)
+	require.Equal(t, first.ID, comments[0].ParentID)
+	require.Equal(t, "**Someone Else**: Looks good", comments[0].Title)
+
+	t.Run("import again", func(t *testing.T) {
+		// a property added to the board since the import is kept
+		previous := append([]model.Block{}, blocks...)
+		previous = append(previous, comments...)
+		previousBoard := previous[0]
+		previousBoard.Fields = map[string]interface{}{}
+		for key, value := range board.Fields {
+			previousBoard.Fields[key] = value
+		}
+		added := map[string]interface{}{"id": "added", "name": "Added", "type": "text", "options": []interface{}{}}
+		previousBoard.Fields["cardProperties"] = append(append([]interface{}{}, board.Fields["cardProperties"].([]interface{})...), added)
+		previous[0] = previousBoard
+
+		again, againComments, summary := ConvertAsana(export, previous, resolveUser)
+		require.Equal(t, 0, summary.BoardsCreated)
+		require.Equal(t, 0, summary.CardsCreated)
+		require.Equal(t, 0, summary.CommentsCreated)
+
+		ids := []string{}
+		for _, block := range again {
+			require.NotEqual(t, "view", block.Type)
+			ids = append(ids, block.ID)
+		}
+		for _, block := range againComments {
+			ids = append(ids, block.ID)
+		}
+		previousIDs := []string{}
+		for _, block := range previous {
+			if block.Type != "view" {
+				previousIDs = append(previousIDs, block.ID)
+			}
+		}
+		require.ElementsMatch(t, previousIDs, ids)
+
+		againProperties := again[0].Fields["cardProperties"].([]interface{})
+		require.Len(t, againProperties, 8)
+		require.Contains(t, againProperties, added)
+		for _, block := range again {
+			if block.Type == "card" && block.Title == "First" {
+				require.Equal(t, first.Fields["properties"], block.Fields["properties"])
+			}
+		}
+	})
+}
+
+func TestAsanaCustomFieldType(t *testing.T) {
+	testCases := []struct {
+		field    AsanaCustomField
+		expected string
+	}{
+		{AsanaCustomField{ResourceSubtype: "number"}, "number"},
+		{AsanaCustomField{ResourceSubtype: "date"}, "date"},
+		{AsanaCustomField{ResourceSubtype: "enum"}, "select"},
+		{AsanaCustomField{ResourceSubtype: "multi_enum"}, "multiSelect"},
+		{AsanaCustomField{ResourceSubtype: "people"}, "person"},
+		{AsanaCustomField{Type: "enum"}, "select"},
+		{AsanaCustomField{ResourceSubtype: "formula"}, "text"},
+		{AsanaCustomField{ResourceSubtype: "text"}, "text"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, tc.field.FieldType())
+	}
+}
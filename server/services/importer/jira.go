@@ -0,0 +1,938 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// The formats of the Jira exports.
+const (
+	JiraFormatJSON = "json"
+	JiraFormatCSV  = "csv"
+)
+
+const (
+	// jiraPreviewValues is the number of values of each field returned
+	// by the previews
+	jiraPreviewValues = 10
+
+	// jiraMaxMappedFields is the number of fields an import can map
+	jiraMaxMappedFields = 100
+
+	jiraDefaultBoardTitle = "Jira import"
+)
+
+// jiraPropertyTypes are the property types the fields can be mapped to.
+var jiraPropertyTypes = map[string]bool{
+	"text":        true,
+	"number":      true,
+	"date":        true,
+	"select":      true,
+	"multiSelect": true,
+	"person":      true,
+}
+
+// jiraDateLayouts are the layouts of the dates of the JSON and the CSV
+// exports.
+var jiraDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04:05.000-0700",
+	time.RFC3339,
+	"02/Jan/06 3:04 PM",
+	"02/Jan/06",
+}
+
+// jiraJSONSkippedFields are the fields of the JSON exports that are
+// imported as the title or the content of the cards, or that can't be
+// mapped to properties.
+var jiraJSONSkippedFields = map[string]bool{
+	"summary":                  true,
+	"description":              true,
+	"comment":                  true,
+	"attachment":               true,
+	"project":                  true,
+	"worklog":                  true,
+	"issuelinks":               true,
+	"subtasks":                 true,
+	"watches":                  true,
+	"votes":                    true,
+	"progress":                 true,
+	"aggregateprogress":        true,
+	"timetracking":             true,
+	"created":                  true,
+	"updated":                  true,
+	"lastViewed":               true,
+	"statuscategorychangedate": true,
+	"thumbnail":                true,
+}
+
+// jiraCSVSkippedColumns are the columns of the CSV exports that are
+// imported as the title or the content of the cards, or that can't be
+// mapped to properties.
+var jiraCSVSkippedColumns = map[string]bool{
+	"Summary":                 true,
+	"Description":             true,
+	"Comment":                 true,
+	"Attachment":              true,
+	"Issue id":                true,
+	"Parent id":               true,
+	"Project key":             true,
+	"Project name":            true,
+	"Project type":            true,
+	"Project lead":            true,
+	"Project description":     true,
+	"Project url":             true,
+	"Created":                 true,
+	"Updated":                 true,
+	"Last Viewed":             true,
+	"Watchers":                true,
+	"Status Category Changed": true,
+}
+
+// jiraCSVPersonColumns and jiraCSVSelectColumns are the columns of the
+// CSV exports whose type can't be told from their values.
+var (
+	jiraCSVPersonColumns = map[string]bool{"Assignee": true, "Reporter": true, "Creator": true}
+	jiraCSVSelectColumns = map[string]bool{"Status": true, "Priority": true, "Issue Type": true, "Resolution": true, "Status Category": true}
+)
+
+// jiraFieldNames are the names of the system fields of the JSON exports,
+// when the export has none.
+var jiraFieldNames = map[string]string{
+	"key":         "Key",
+	"status":      "Status",
+	"assignee":    "Assignee",
+	"reporter":    "Reporter",
+	"creator":     "Creator",
+	"priority":    "Priority",
+	"issuetype":   "Issue Type",
+	"labels":      "Labels",
+	"duedate":     "Due date",
+	"resolution":  "Resolution",
+	"components":  "Components",
+	"fixVersions": "Fix versions",
+	"environment": "Environment",
+}
+
+// jiraDefaultFields are the names of the fields of the suggested
+// mappings, in their order, with the names of their properties.
+var jiraDefaultFields = []struct {
+	names        []string
+	propertyName string
+}{
+	{[]string{"key", "issue key"}, "Key"},
+	{[]string{"status"}, "Status"},
+	{[]string{"assignee"}, "Assignee"},
+	{[]string{"priority"}, "Priority"},
+	{[]string{"issue type"}, "Type"},
+	{[]string{"labels"}, "Labels"},
+	{[]string{"due date"}, "Due date"},
+	{[]string{"story points", "story point estimate", "custom field (story points)", "custom field (story point estimate)"}, "Story points"},
+}
+
+// InvalidJiraImportError is returned when a Jira export can't be read,
+// or when the mapping of its fields is invalid.
+type InvalidJiraImportError struct {
+	Reason string
+}
+
+func (e InvalidJiraImportError) Error() string {
+	return "invalid Jira import: " + e.Reason
+}
+
+// JiraIssue is an issue of a Jira export, with its rich text converted
+// to markdown.
+type JiraIssue struct {
+	Key         string
+	Summary     string
+	Description string
+	Project     string
+	Fields      map[string]JiraValue
+	Comments    []JiraComment
+	Attachments []JiraAttachment
+}
+
+// JiraValue is the value of a field of an issue, as strings, with the
+// property type that suits it. The users are their email address, or
+// their username or display name if the export has no email address.
+type JiraValue struct {
+	Type   string
+	Values []string
+}
+
+type JiraComment struct {
+	Author string
+	Body   string
+}
+
+type JiraAttachment struct {
+	Name string
+	URL  string
+}
+
+// ReadJira reads the issues of a Jira export, a JSON export of the
+// issues search or a CSV export, calling the function for each of them
+// so that the export isn't held in memory. Along with the format, it
+// returns the names of the fields of the JSON exports that have them.
+func ReadJira(input io.Reader, fn func(JiraIssue) error) (string, map[string]string, error) {
+	reader := bufio.NewReader(input)
+	for {
+		r, _, err := reader.ReadRune()
+		if errors.Is(err, io.EOF) {
+			return "", nil, InvalidJiraImportError{Reason: "the export is empty"}
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if r == '\uFEFF' || unicode.IsSpace(r) {
+			continue
+		}
+		if err := reader.UnreadRune(); err != nil {
+			return "", nil, err
+		}
+		if r == '{' || r == '[' {
+			names, err := readJiraJSON(reader, fn)
+			return JiraFormatJSON, names, err
+		}
+		return JiraFormatCSV, nil, readJiraCSV(reader, fn)
+	}
+}
+
+type jiraJSONIssue struct {
+	Key    string                     `json:"key"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+type jiraJSONComments struct {
+	Comments []struct {
+		Author struct {
+			DisplayName string `json:"displayName"`
+		} `json:"author"`
+		Body json.RawMessage `json:"body"`
+	} `json:"comments"`
+}
+
+type jiraJSONAttachment struct {
+	Filename string `json:"filename"`
+	Content  string `json:"content"`
+}
+
+// readJiraJSON reads a JSON export, either the result of an issues
+// search, whose names can follow the issues, or an array of issues.
+func readJiraJSON(input io.Reader, fn func(JiraIssue) error) (map[string]string, error) {
+	decoder := json.NewDecoder(input)
+	invalid := func(err error) error {
+		return InvalidJiraImportError{Reason: fmt.Sprintf("the JSON export can't be read: %s", err)}
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, invalid(err)
+	}
+	if token == json.Delim('[') {
+		return nil, readJiraJSONIssues(decoder, fn)
+	}
+
+	names := map[string]string{}
+	foundIssues := false
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, invalid(err)
+		}
+		switch token {
+		case "issues":
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, invalid(err)
+			}
+			if token != json.Delim('[') {
+				return nil, InvalidJiraImportError{Reason: "the issues of the export aren't an array"}
+			}
+			if err := readJiraJSONIssues(decoder, fn); err != nil {
+				return nil, err
+			}
+			foundIssues = true
+		case "names":
+			if err := decoder.Decode(&names); err != nil {
+				return nil, invalid(err)
+			}
+		default:
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return nil, invalid(err)
+			}
+		}
+	}
+	if !foundIssues {
+		return nil, InvalidJiraImportError{Reason: "the export has no issues"}
+	}
+	return names, nil
+}
+
+// readJiraJSONIssues reads the issues of an array whose opening bracket
+// was read.
+func readJiraJSONIssues(decoder *json.Decoder, fn func(JiraIssue) error) error {
+	for decoder.More() {
+		var raw jiraJSONIssue
+		if err := decoder.Decode(&raw); err != nil {
+			return InvalidJiraImportError{Reason: fmt.Sprintf("an issue of the export can't be read: %s", err)}
+		}
+		if err := fn(convertJiraJSONIssue(raw)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return InvalidJiraImportError{Reason: fmt.Sprintf("the JSON export can't be read: %s", err)}
+	}
+	return nil
+}
+
+func convertJiraJSONIssue(raw jiraJSONIssue) JiraIssue {
+	issue := JiraIssue{
+		Key:    raw.Key,
+		Fields: map[string]JiraValue{},
+	}
+	if raw.Key != "" {
+		issue.Fields["key"] = JiraValue{Type: "text", Values: []string{raw.Key}}
+	}
+
+	_ = json.Unmarshal(raw.Fields["summary"], &issue.Summary)
+	if description, ok := raw.Fields["description"]; ok {
+		issue.Description = jiraTextToMarkdown(description)
+	}
+
+	var project struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw.Fields["project"], &project); err == nil {
+		issue.Project = project.Name
+	}
+
+	var comments jiraJSONComments
+	if err := json.Unmarshal(raw.Fields["comment"], &comments); err == nil {
+		for _, comment := range comments.Comments {
+			if body := jiraTextToMarkdown(comment.Body); body != "" {
+				issue.Comments = append(issue.Comments, JiraComment{Author: comment.Author.DisplayName, Body: body})
+			}
+		}
+	}
+
+	var attachments []jiraJSONAttachment
+	if err := json.Unmarshal(raw.Fields["attachment"], &attachments); err == nil {
+		for _, attachment := range attachments {
+			issue.Attachments = append(issue.Attachments, JiraAttachment{Name: attachment.Filename, URL: attachment.Content})
+		}
+	}
+
+	for id, value := range raw.Fields {
+		if jiraJSONSkippedFields[id] {
+			continue
+		}
+		if value, ok := jiraJSONValue(value); ok {
+			issue.Fields[id] = value
+		}
+	}
+	return issue
+}
+
+// jiraJSONValue returns the value of a field of a JSON export, telling
+// its type from its structure.
+func jiraJSONValue(raw json.RawMessage) (JiraValue, bool) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return JiraValue{}, false
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if value["type"] == "doc" {
+			text := jiraTextToMarkdown(raw)
+			return JiraValue{Type: "text", Values: []string{text}}, text != ""
+		}
+		text, propertyType := jiraJSONScalar(value)
+		return JiraValue{Type: propertyType, Values: []string{text}}, text != ""
+	case []interface{}:
+		result := JiraValue{Type: "multiSelect"}
+		for _, item := range value {
+			if text, _ := jiraJSONScalar(item); text != "" {
+				result.Values = append(result.Values, text)
+			}
+		}
+		return result, len(result.Values) > 0
+	default:
+		text, propertyType := jiraJSONScalar(value)
+		return JiraValue{Type: propertyType, Values: []string{text}}, text != ""
+	}
+}
+
+// jiraJSONScalar returns the text of a single value, with the property
+// type that suits it.
+func jiraJSONScalar(value interface{}) (string, string) {
+	switch value := value.(type) {
+	case string:
+		if _, ok := parseJiraDate(value); ok {
+			return value, "date"
+		}
+		return value, "text"
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), "number"
+	case bool:
+		if value {
+			return "true", "text"
+		}
+		return "", "text"
+	case map[string]interface{}:
+		// the users have an account ID on Jira Cloud, and a key on Jira
+		// Server
+		_, hasDisplayName := value["displayName"]
+		_, hasAccountID := value["accountId"]
+		_, hasEmail := value["emailAddress"]
+		if hasDisplayName && (hasAccountID || hasEmail || value["key"] != nil) {
+			for _, key := range []string{"emailAddress", "name", "displayName"} {
+				if text, _ := value[key].(string); text != "" {
+					return text, "person"
+				}
+			}
+			return "", "person"
+		}
+		for _, key := range []string{"name", "value"} {
+			if text, ok := value[key].(string); ok {
+				return text, "select"
+			}
+		}
+	}
+	return "", "text"
+}
+
+// readJiraCSV reads a CSV export, whose multiple values, like the labels,
+// are in columns with the same name.
+func readJiraCSV(input io.Reader, fn func(JiraIssue) error) error {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return InvalidJiraImportError{Reason: fmt.Sprintf("the CSV export can't be read: %s", err)}
+	}
+	columns := make([]string, len(header))
+	columnCounts := map[string]int{}
+	for i, name := range header {
+		columns[i] = strings.TrimSpace(name)
+		columnCounts[columns[i]]++
+	}
+	if columnCounts["Summary"] == 0 {
+		return InvalidJiraImportError{Reason: "the CSV export has no Summary column"}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return InvalidJiraImportError{Reason: fmt.Sprintf("the CSV export can't be read: %s", err)}
+		}
+
+		issue := JiraIssue{Fields: map[string]JiraValue{}}
+		values := map[string][]string{}
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if i >= len(columns) || value == "" {
+				continue
+			}
+			switch column := columns[i]; column {
+			case "Summary":
+				issue.Summary = value
+			case "Issue key":
+				issue.Key = value
+				values[column] = append(values[column], value)
+			case "Description":
+				issue.Description = wikiToMarkdown(value)
+			case "Project name":
+				issue.Project = value
+			case "Comment":
+				// the comments are "date;author;body"
+				parts := strings.SplitN(value, ";", 3)
+				if len(parts) == 3 {
+					issue.Comments = append(issue.Comments, JiraComment{Body: wikiToMarkdown(parts[2])})
+				} else {
+					issue.Comments = append(issue.Comments, JiraComment{Body: wikiToMarkdown(value)})
+				}
+			case "Attachment":
+				// the attachments are "date;author;name;url"
+				parts := strings.SplitN(value, ";", 4)
+				if len(parts) == 4 {
+					issue.Attachments = append(issue.Attachments, JiraAttachment{Name: parts[2], URL: parts[3]})
+				}
+			default:
+				if !jiraCSVSkippedColumns[column] {
+					values[column] = append(values[column], value)
+				}
+			}
+		}
+
+		for column, columnValues := range values {
+			issue.Fields[column] = JiraValue{
+				Type:   jiraCSVType(column, columnValues, columnCounts[column] > 1),
+				Values: columnValues,
+			}
+		}
+		if err := fn(issue); err != nil {
+			return err
+		}
+	}
+}
+
+// jiraCSVType returns the property type that suits the values of a
+// column of a CSV export.
+func jiraCSVType(column string, values []string, repeated bool) string {
+	switch {
+	case jiraCSVPersonColumns[column]:
+		return "person"
+	case repeated:
+		return "multiSelect"
+	case jiraCSVSelectColumns[column]:
+		return "select"
+	}
+
+	isNumber, isDate := true, true
+	for _, value := range values {
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			isNumber = false
+		}
+		if _, ok := parseJiraDate(value); !ok {
+			isDate = false
+		}
+	}
+	switch {
+	case isNumber:
+		return "number"
+	case isDate:
+		return "date"
+	}
+	return "text"
+}
+
+func parseJiraDate(value string) (time.Time, bool) {
+	for _, layout := range jiraDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// PreviewJira reads a Jira export and returns its fields, with the
+// property types detected from their values, and a suggested mapping of
+// the common ones.
+func PreviewJira(input io.Reader) (*model.JiraImportPreview, error) {
+	type fieldValues struct {
+		propertyType string
+		values       []string
+		seen         map[string]bool
+	}
+	fields := map[string]*fieldValues{}
+	issueCount := 0
+	project := ""
+
+	format, names, err := ReadJira(input, func(issue JiraIssue) error {
+		issueCount++
+		if project == "" {
+			project = issue.Project
+		}
+		for id, value := range issue.Fields {
+			field, ok := fields[id]
+			if !ok {
+				field = &fieldValues{propertyType: value.Type, seen: map[string]bool{}}
+				fields[id] = field
+			}
+			if field.propertyType != value.Type {
+				field.propertyType = jiraMergedType(field.propertyType, value.Type)
+			}
+			for _, text := range value.Values {
+				if len(field.values) < jiraPreviewValues && !field.seen[text] {
+					field.seen[text] = true
+					field.values = append(field.values, text)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if issueCount == 0 {
+		return nil, InvalidJiraImportError{Reason: "the export has no issues"}
+	}
+
+	preview := &model.JiraImportPreview{
+		Format:     format,
+		IssueCount: issueCount,
+		Fields:     []model.JiraField{},
+		Mapping: model.JiraImportMapping{
+			BoardTitle: project,
+			Fields:     []model.JiraFieldMapping{},
+		},
+	}
+	for id, field := range fields {
+		name := names[id]
+		if name == "" {
+			name = jiraFieldNames[id]
+		}
+		if name == "" {
+			name = id
+		}
+		preview.Fields = append(preview.Fields, model.JiraField{
+			ID:     id,
+			Name:   name,
+			Type:   field.propertyType,
+			Values: field.values,
+		})
+	}
+	sort.Slice(preview.Fields, func(i, j int) bool {
+		if preview.Fields[i].Name != preview.Fields[j].Name {
+			return preview.Fields[i].Name < preview.Fields[j].Name
+		}
+		return preview.Fields[i].ID < preview.Fields[j].ID
+	})
+
+	for _, defaultField := range jiraDefaultFields {
+		for _, field := range preview.Fields {
+			if !containsFold(defaultField.names, field.Name) {
+				continue
+			}
+			preview.Mapping.Fields = append(preview.Mapping.Fields, model.JiraFieldMapping{
+				FieldID:      field.ID,
+				PropertyName: defaultField.propertyName,
+				PropertyType: field.Type,
+			})
+			break
+		}
+	}
+
+	return preview, nil
+}
+
+// jiraMergedType returns the type that suits the values of two types,
+// the text if none does.
+func jiraMergedType(a, b string) string {
+	if (a == "select" && b == "multiSelect") || (a == "multiSelect" && b == "select") {
+		return "multiSelect"
+	}
+	return "text"
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// JiraConverter converts the issues of a Jira export to the cards of a
+// board, with the properties of a mapping of the fields. The options of
+// the select properties are the values of the issues.
+type JiraConverter struct {
+	mapping     model.JiraImportMapping
+	resolveUser func(string) string
+	summary     *model.ImportSummary
+	now         int64
+	project     string
+
+	boardID   string
+	templates []map[string]interface{}
+	optionIDs []map[string]string
+	blocks    []model.Block
+	cardOrder []interface{}
+}
+
+// NewJiraConverter returns a converter of the issues with the mapping,
+// which resolves the users of the person properties with the function,
+// an empty ID dropping the value.
+func NewJiraConverter(mapping model.JiraImportMapping, resolveUser func(string) string) (*JiraConverter, error) {
+	if len(mapping.Fields) > jiraMaxMappedFields {
+		return nil, InvalidJiraImportError{Reason: fmt.Sprintf("at most %d fields can be imported", jiraMaxMappedFields)}
+	}
+
+	converter := &JiraConverter{
+		mapping:     mapping,
+		resolveUser: resolveUser,
+		summary:     &model.ImportSummary{Skipped: []model.ImportSkippedItem{}},
+		now:         utils.GetMillis(),
+		boardID:     utils.CreateGUID(),
+	}
+	fieldIDs := map[string]bool{}
+	for _, field := range mapping.Fields {
+		name := strings.TrimSpace(field.PropertyName)
+		switch {
+		case field.FieldID == "":
+			return nil, InvalidJiraImportError{Reason: "a mapped field has no ID"}
+		case fieldIDs[field.FieldID]:
+			return nil, InvalidJiraImportError{Reason: fmt.Sprintf("the field %q is mapped twice", field.FieldID)}
+		case name == "":
+			return nil, InvalidJiraImportError{Reason: fmt.Sprintf("the property of the field %q has no name", field.FieldID)}
+		case !jiraPropertyTypes[field.PropertyType]:
+			return nil, InvalidJiraImportError{Reason: fmt.Sprintf("the property type %q of the field %q isn't supported", field.PropertyType, field.FieldID)}
+		}
+		fieldIDs[field.FieldID] = true
+
+		template := map[string]interface{}{
+			"id":      utils.CreateGUID(),
+			"name":    name,
+			"type":    field.PropertyType,
+			"options": []interface{}{},
+		}
+		converter.templates = append(converter.templates, template)
+		converter.optionIDs = append(converter.optionIDs, map[string]string{})
+	}
+	return converter, nil
+}
+
+// ConvertJira reads a Jira export and converts its issues with the
+// mapping.
+func ConvertJira(input io.Reader, mapping model.JiraImportMapping, resolveUser func(string) string) ([]model.Block, *model.ImportSummary, error) {
+	converter, err := NewJiraConverter(mapping, resolveUser)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, _, err := ReadJira(input, func(issue JiraIssue) error {
+		converter.Add(issue)
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	blocks, summary := converter.Blocks()
+	return blocks, summary, nil
+}
+
+func (c *JiraConverter) newBlock(blockType, parentID, title string, fields map[string]interface{}) model.Block {
+	return model.Block{
+		ID:       utils.CreateGUID(),
+		ParentID: parentID,
+		RootID:   c.boardID,
+		Schema:   1,
+		Type:     blockType,
+		Title:    title,
+		Fields:   fields,
+		CreateAt: c.now,
+		UpdateAt: c.now,
+	}
+}
+
+// Add converts the issue to a card, with its description as a text block
+// followed by the links to its attachments, and its comments.
+func (c *JiraConverter) Add(issue JiraIssue) {
+	if c.project == "" {
+		c.project = issue.Project
+	}
+
+	title := issue.Summary
+	if title == "" {
+		title = issue.Key
+	}
+
+	properties := map[string]interface{}{}
+	for i, field := range c.mapping.Fields {
+		value, ok := issue.Fields[field.FieldID]
+		if !ok || len(value.Values) == 0 {
+			continue
+		}
+		if propertyValue := c.propertyValue(i, issue, value); propertyValue != nil {
+			properties[c.templates[i]["id"].(string)] = propertyValue
+		}
+	}
+
+	card := c.newBlock("card", c.boardID, title, map[string]interface{}{
+		"icon":         "",
+		"isTemplate":   false,
+		"properties":   properties,
+		"contentOrder": []interface{}{},
+	})
+	c.summary.CardsCreated++
+	c.cardOrder = append(c.cardOrder, card.ID)
+
+	description := issue.Description
+	for _, attachment := range issue.Attachments {
+		c.summary.Skip("attachment", issue.Key, attachment.Name, "attachments aren't imported, they are linked in the card description")
+		if attachment.URL == "" {
+			continue
+		}
+		name := attachment.Name
+		if name == "" {
+			name = attachment.URL
+		}
+		if description != "" {
+			description += "\n\n"
+		}
+		description += fmt.Sprintf("[%s](%s)", name, attachment.URL)
+	}
+
+	content := []model.Block{}
+	if description != "" {
+		text := c.newBlock("text", card.ID, description, map[string]interface{}{})
+		card.Fields["contentOrder"] = []interface{}{text.ID}
+		content = append(content, text)
+	}
+
+	// the comments aren't part of the content order
+	for _, comment := range issue.Comments {
+		body := comment.Body
+		if comment.Author != "" {
+			body = fmt.Sprintf("**%s**: %s", comment.Author, body)
+		}
+		content = append(content, c.newBlock("comment", card.ID, body, map[string]interface{}{}))
+		c.summary.CommentsCreated++
+	}
+
+	c.blocks = append(c.blocks, card)
+	c.blocks = append(c.blocks, content...)
+}
+
+// propertyValue returns the value of the property of the mapped field
+// with the index for the value, or nil if it doesn't suit the property.
+func (c *JiraConverter) propertyValue(index int, issue JiraIssue, value JiraValue) interface{} {
+	field := c.mapping.Fields[index]
+	skip := func(reason string) interface{} {
+		c.summary.Skip("value", issue.Key, field.PropertyName, reason)
+		return nil
+	}
+
+	switch field.PropertyType {
+	case "number":
+		number, err := strconv.ParseFloat(value.Values[0], 64)
+		if err != nil {
+			return skip(fmt.Sprintf("%q isn't a number", value.Values[0]))
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	case "date":
+		date, ok := parseJiraDate(value.Values[0])
+		if !ok {
+			return skip(fmt.Sprintf("%q isn't a date", value.Values[0]))
+		}
+		data, _ := json.Marshal(map[string]int64{"from": utils.MillisFromTime(date)})
+		return string(data)
+	case "person":
+		userID := c.resolveUser(value.Values[0])
+		if userID == "" {
+			return skip(fmt.Sprintf("no user of the workspace matches %q", value.Values[0]))
+		}
+		return userID
+	case "select":
+		return c.optionID(index, value.Values[0])
+	case "multiSelect":
+		optionIDs := []interface{}{}
+		for _, text := range value.Values {
+			optionIDs = append(optionIDs, c.optionID(index, text))
+		}
+		return optionIDs
+	default:
+		return strings.Join(value.Values, ", ")
+	}
+}
+
+// optionID returns the ID of the option of the select property with the
+// index for the value, adding the option if it's new.
+func (c *JiraConverter) optionID(index int, value string) string {
+	key := strings.ToLower(value)
+	if id, ok := c.optionIDs[index][key]; ok {
+		return id
+	}
+
+	template := c.templates[index]
+	options := template["options"].([]interface{})
+	id := utils.CreateGUID()
+	template["options"] = append(options, map[string]interface{}{
+		"id":    id,
+		"value": value,
+		"color": listColors[len(options)%len(listColors)],
+	})
+	c.optionIDs[index][key] = id
+	return id
+}
+
+// Blocks returns the board with a board view, grouped by the first
+// select property, followed by the cards and their content.
+func (c *JiraConverter) Blocks() ([]model.Block, *model.ImportSummary) {
+	title := strings.TrimSpace(c.mapping.BoardTitle)
+	if title == "" {
+		title = c.project
+	}
+	if title == "" {
+		title = jiraDefaultBoardTitle
+	}
+
+	cardProperties := []interface{}{}
+	for _, template := range c.templates {
+		cardProperties = append(cardProperties, template)
+	}
+	board := model.Block{
+		ID:       c.boardID,
+		RootID:   c.boardID,
+		Schema:   1,
+		Type:     "board",
+		Title:    title,
+		CreateAt: c.now,
+		UpdateAt: c.now,
+		Fields: map[string]interface{}{
+			"icon":               "",
+			"description":        "",
+			"showDescription":    false,
+			"isTemplate":         false,
+			"columnCalculations": map[string]interface{}{},
+			"cardProperties":     cardProperties,
+		},
+	}
+	c.summary.BoardsCreated = 1
+
+	groupByID := ""
+	visibleOptionIDs := []interface{}{}
+	visiblePropertyIDs := []interface{}{}
+	for _, template := range c.templates {
+		id := template["id"].(string)
+		if groupByID == "" && template["type"] == "select" {
+			groupByID = id
+			for _, option := range template["options"].([]interface{}) {
+				visibleOptionIDs = append(visibleOptionIDs, option.(map[string]interface{})["id"])
+			}
+			continue
+		}
+		visiblePropertyIDs = append(visiblePropertyIDs, id)
+	}
+
+	view := c.newBlock("view", c.boardID, "Board View", map[string]interface{}{
+		"viewType":           "board",
+		"groupById":          groupByID,
+		"sortOptions":        []interface{}{},
+		"visiblePropertyIds": visiblePropertyIDs,
+		"visibleOptionIds":   visibleOptionIDs,
+		"hiddenOptionIds":    []interface{}{},
+		"collapsedOptionIds": []interface{}{},
+		"filter":             map[string]interface{}{"operation": "and", "filters": []interface{}{}},
+		"cardOrder":          c.cardOrder,
+		"columnWidths":       map[string]interface{}{},
+		"columnCalculations": map[string]interface{}{},
+		"kanbanCalculations": map[string]interface{}{},
+		"defaultTemplateId":  "",
+	})
+
+	return append([]model.Block{board, view}, c.blocks...), c.summary
+}
@@ -0,0 +1,286 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+const jiraJSONExport = `{
+	"startAt": 0,
+	"total": 2,
+	"issues": [
+		{
+			"key": "PROJ-1",
+			"fields": {
+				"summary": "First",
+				"project": {"key": "PROJ", "name": "Project"},
+				"status": {"name": "In Progress"},
+				"assignee": {"accountId": "account-1", "displayName": "Some User", "emailAddress": "user@example.com"},
+				"labels": ["backend", "api"],
+				"duedate": "2021-06-01",
+				"customfield_10016": 3,
+				"description": {
+					"type": "doc", "version": 1,
+					"content": [
+						{"type": "paragraph", "content": [
+							{"type": "text", "text": "Some "},
+							{"type": "text", "text": "bold", "marks": [{"type": "strong"}]},
+							{"type": "text", "text": " text"}
+						]},
+						{"type": "bulletList", "content": [
+							{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "item"}]}]}
+						]}
+					]
+				},
+				"comment": {"comments": [
+					{"author": {"displayName": "Some User"}, "body": {"type": "doc", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Looks good"}]}]}}
+				]},
+				"attachment": [{"filename": "spec.pdf", "content": "https://jira.example.com/spec.pdf"}]
+			}
+		},
+		{
+			"key": "PROJ-2",
+			"fields": {
+				"summary": "Second",
+				"status": {"name": "Done"},
+				"assignee": {"accountId": "account-2", "displayName": "Someone Else"},
+				"labels": [],
+				"customfield_10016": null
+			}
+		}
+	],
+	"names": {"customfield_10016": "Story point estimate"}
+}`
+
+const jiraCSVExport = "Summary,Issue key,Issue id,Status,Assignee,Labels,Labels,Custom field (Story Points),Description,Comment\n" +
+	"First,PROJ-1,10001,In Progress,user,backend,api,3,*Some* text,01/Jun/21 10:00 AM;account-1;Looks good\n" +
+	"Second,PROJ-2,10002,Done,unknown,,,,,\n"
+
+func TestPreviewJira(t *testing.T) {
+	t.Run("JSON export", func(t *testing.T) {
+		preview, err := PreviewJira(strings.NewReader(jiraJSONExport))
+		require.NoError(t, err)
+		require.Equal(t, JiraFormatJSON, preview.Format)
+		require.Equal(t, 2, preview.IssueCount)
+		require.Equal(t, "Project", preview.Mapping.BoardTitle)
+
+		fields := map[string]model.JiraField{}
+		for _, field := range preview.Fields {
+			fields[field.ID] = field
+		}
+		require.Equal(t, "select", fields["status"].Type)
+		require.ElementsMatch(t, []string{"In Progress", "Done"}, fields["status"].Values)
+		require.Equal(t, "person", fields["assignee"].Type)
+		require.Contains(t, fields["assignee"].Values, "user@example.com")
+		require.Equal(t, "multiSelect", fields["labels"].Type)
+		require.Equal(t, "date", fields["duedate"].Type)
+		require.Equal(t, "number", fields["customfield_10016"].Type)
+		require.Equal(t, "Story point estimate", fields["customfield_10016"].Name)
+		require.NotContains(t, fields, "summary")
+		require.NotContains(t, fields, "description")
+
+		require.Equal(t, []model.JiraFieldMapping{
+			{FieldID: "key", PropertyName: "Key", PropertyType: "text"},
+			{FieldID: "status", PropertyName: "Status", PropertyType: "select"},
+			{FieldID: "assignee", PropertyName: "Assignee", PropertyType: "person"},
+			{FieldID: "labels", PropertyName: "Labels", PropertyType: "multiSelect"},
+			{FieldID: "duedate", PropertyName: "Due date", PropertyType: "date"},
+			{FieldID: "customfield_10016", PropertyName: "Story points", PropertyType: "number"},
+		}, preview.Mapping.Fields)
+	})
+
+	t.Run("CSV export", func(t *testing.T) {
+		preview, err := PreviewJira(strings.NewReader("\uFEFF" + jiraCSVExport))
+		require.NoError(t, err)
+		require.Equal(t, JiraFormatCSV, preview.Format)
+		require.Equal(t, 2, preview.IssueCount)
+
+		types := map[string]string{}
+		for _, field := range preview.Fields {
+			types[field.ID] = field.Type
+		}
+		require.Equal(t, map[string]string{
+			"Issue key":                   "text",
+			"Status":                      "select",
+			"Assignee":                    "person",
+			"Labels":                      "multiSelect",
+			"Custom field (Story Points)": "number",
+		}, types)
+		require.Len(t, preview.Mapping.Fields, 5)
+	})
+
+	t.Run("invalid exports", func(t *testing.T) {
+		for _, input := range []string{"", "  ", `{"issues": {}}`, `{"total": 0}`, `[]`, `[{"key": `, "Key,Status\nPROJ-1,Done\n"} {
+			_, err := PreviewJira(strings.NewReader(input))
+			var invalid InvalidJiraImportError
+			require.ErrorAs(t, err, &invalid, input)
+		}
+	})
+}
+
+func TestConvertJira(t *testing.T) {
+	mapping := model.JiraImportMapping{
+		Fields: []model.JiraFieldMapping{
+			{FieldID: "status", PropertyName: "Status", PropertyType: "select"},
+			{FieldID: "assignee", PropertyName: "Assignee", PropertyType: "person"},
+			{FieldID: "labels", PropertyName: "Labels", PropertyType: "multiSelect"},
+			{FieldID: "duedate", PropertyName: "Due date", PropertyType: "date"},
+			{FieldID: "customfield_10016", PropertyName: "Story points", PropertyType: "number"},
+		},
+	}
+	resolveUser := func(value string) string {
+		if value == "user@example.com" {
+			return "user-id"
+		}
+		return ""
+	}
+
+	blocks, summary, err := ConvertJira(strings.NewReader(jiraJSONExport), mapping, resolveUser)
+	require.NoError(t, err)
+	require.Equal(t, 1, summary.BoardsCreated)
+	require.Equal(t, 2, summary.CardsCreated)
+	require.Equal(t, 1, summary.CommentsCreated)
+	require.ElementsMatch(t, []string{"PROJ-1", "PROJ-2"}, skippedIDs(summary))
+
+	byType := map[string][]model.Block{}
+	for _, block := range blocks {
+		byType[block.Type] = append(byType[block.Type], block)
+	}
+	require.Len(t, byType["board"], 1)
+	board := byType["board"][0]
+	require.Equal(t, "Project", board.Title)
+	for _, block := range blocks {
+		require.Equal(t, board.ID, block.RootID)
+	}
+
+	cardProperties := board.Fields["cardProperties"].([]interface{})
+	require.Len(t, cardProperties, 5)
+	status := cardProperties[0].(map[string]interface{})
+	labels := cardProperties[2].(map[string]interface{})
+	statusOptions := optionIDsByValue(status)
+	require.Len(t, statusOptions, 2)
+	labelOptions := optionIDsByValue(labels)
+	require.Len(t, labelOptions, 2)
+
+	require.Len(t, byType["view"], 1)
+	require.Equal(t, status["id"], byType["view"][0].Fields["groupById"])
+
+	require.Len(t, byType["card"], 2)
+	first, second := byType["card"][0], byType["card"][1]
+	require.Equal(t, "First", first.Title)
+	propertyID := func(i int) string {
+		return cardProperties[i].(map[string]interface{})["id"].(string)
+	}
+	require.Equal(t, map[string]interface{}{
+		propertyID(0): statusOptions["In Progress"],
+		propertyID(1): "user-id",
+		propertyID(2): []interface{}{labelOptions["backend"], labelOptions["api"]},
+		propertyID(3): `{"from":1622505600000}`,
+		propertyID(4): "3",
+	}, first.Fields["properties"])
+
+	// the assignee of the second issue isn't a user of the workspace
+	require.Equal(t, map[string]interface{}{propertyID(0): statusOptions["Done"]}, second.Fields["properties"])
+
+	contentOrder := first.Fields["contentOrder"].([]interface{})
+	require.Len(t, contentOrder, 1)
+	require.Len(t, byType["text"], 1)
+	require.Equal(t, contentOrder[0], byType["text"][0].ID)
+	require.Equal(t, "Some **bold** text\n\n- item\n\n[spec.pdf](https://jira.example.com/spec.pdf)", byType["text"][0].Title)
+	require.Empty(t, second.Fields["contentOrder"])
+
+	require.Len(t, byType["comment"], 1)
+	require.Equal(t, first.ID, byType["comment"][0].ParentID)
+	require.Equal(t, "**Some User**: Looks good", byType["comment"][0].Title)
+
+	t.Run("CSV export", func(t *testing.T) {
+		mapping := model.JiraImportMapping{
+			BoardTitle: "Imported",
+			Fields: []model.JiraFieldMapping{
+				{FieldID: "Labels", PropertyName: "Labels", PropertyType: "multiSelect"},
+				{FieldID: "Custom field (Story Points)", PropertyName: "Points", PropertyType: "number"},
+			},
+		}
+		blocks, summary, err := ConvertJira(strings.NewReader(jiraCSVExport), mapping, resolveUser)
+		require.NoError(t, err)
+		require.Equal(t, 2, summary.CardsCreated)
+		require.Equal(t, 1, summary.CommentsCreated)
+		require.Equal(t, "Imported", blocks[0].Title)
+		// there's no select property to group by
+		require.Equal(t, "", blocks[1].Fields["groupById"])
+
+		for _, block := range blocks {
+			switch {
+			case block.Type == "text":
+				require.Equal(t, "**Some** text", block.Title)
+			case block.Type == "comment":
+				require.Equal(t, "Looks good", block.Title)
+			case block.Type == "card" && block.Title == "First":
+				require.Len(t, block.Fields["properties"], 2)
+			}
+		}
+	})
+
+	t.Run("invalid mappings", func(t *testing.T) {
+		for _, fields := range [][]model.JiraFieldMapping{
+			{{FieldID: "", PropertyName: "Status", PropertyType: "select"}},
+			{{FieldID: "status", PropertyName: " ", PropertyType: "select"}},
+			{{FieldID: "status", PropertyName: "Status", PropertyType: "checkbox"}},
+			{
+				{FieldID: "status", PropertyName: "Status", PropertyType: "select"},
+				{FieldID: "status", PropertyName: "State", PropertyType: "text"},
+			},
+		} {
+			_, _, err := ConvertJira(strings.NewReader(jiraJSONExport), model.JiraImportMapping{Fields: fields}, resolveUser)
+			var invalid InvalidJiraImportError
+			require.ErrorAs(t, err, &invalid)
+		}
+	})
+}
+
+func TestJiraTextToMarkdown(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{
+			name:     "wiki markup",
+			value:    `"h2. Title\n* one\n** two\n# first\nbq. quoted\n{{mono}} and [link|https://example.com]"`,
+			expected: "## Title\n- one\n  - two\n1. first\n> quoted\n`mono` and [link](https://example.com)",
+		},
+		{
+			name:     "wiki code block",
+			value:    `"{code:go}\nfmt.Println(\"*a*\")\n{code}"`,
+			expected: "```go\nfmt.Println(\"*a*\")\n```",
+		},
+		{
+			name: "ADF document",
+			value: `{"type": "doc", "content": [
+				{"type": "heading", "attrs": {"level": 3}, "content": [{"type": "text", "text": "Title"}]},
+				{"type": "orderedList", "content": [
+					{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "one", "marks": [{"type": "link", "attrs": {"href": "https://example.com"}}]}]}]},
+					{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "two", "marks": [{"type": "code"}]}]}]}
+				]},
+				{"type": "codeBlock", "attrs": {"language": "sql"}, "content": [{"type": "text", "text": "SELECT 1"}]},
+				{"type": "blockquote", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "quoted"}]}]},
+				{"type": "mediaSingle", "content": [{"type": "media", "attrs": {"id": "media-1"}}]}
+			]}`,
+			expected: "### Title\n\n1. [one](https://example.com)\n2. `two`\n\n```sql\nSELECT 1\n```\n\n> quoted",
+		},
+		{
+			name:     "not rich text",
+			value:    `{"type": "paragraph"}`,
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, jiraTextToMarkdown([]byte(tc.value)))
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// adfNode is a node of an Atlassian Document Format document, the format
+// of the rich text fields of Jira Cloud.
+type adfNode struct {
+	Type    string                 `json:"type"`
+	Text    string                 `json:"text"`
+	Attrs   map[string]interface{} `json:"attrs"`
+	Marks   []adfMark              `json:"marks"`
+	Content []adfNode              `json:"content"`
+}
+
+type adfMark struct {
+	Type  string                 `json:"type"`
+	Attrs map[string]interface{} `json:"attrs"`
+}
+
+// jiraTextToMarkdown converts a rich text value of a Jira export to
+// markdown, an ADF document for the JSON exports of Jira Cloud, or wiki
+// markup for the CSV exports and Jira Server.
+func jiraTextToMarkdown(value json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(value, &text); err == nil {
+		return wikiToMarkdown(text)
+	}
+
+	var doc adfNode
+	if err := json.Unmarshal(value, &doc); err != nil || doc.Type != "doc" {
+		return ""
+	}
+	return adfToMarkdown(doc)
+}
+
+// adfToMarkdown converts an ADF document to markdown. The media, which
+// aren't part of the exports, are left out.
+func adfToMarkdown(doc adfNode) string {
+	var b strings.Builder
+	writeADFBlocks(&b, doc.Content, "")
+	return strings.TrimSpace(b.String())
+}
+
+// writeADFBlocks writes the block nodes, separated by blank lines, with
+// the prefix before each of their lines, for the quotes and the lists.
+func writeADFBlocks(b *strings.Builder, nodes []adfNode, prefix string) {
+	for i, node := range nodes {
+		if i > 0 {
+			b.WriteString(strings.TrimRight(prefix, " ") + "\n")
+		}
+		writeADFBlock(b, node, prefix)
+	}
+}
+
+func writeADFBlock(b *strings.Builder, node adfNode, prefix string) {
+	switch node.Type {
+	case "paragraph":
+		writePrefixed(b, adfInline(node.Content), prefix)
+	case "heading":
+		level := 1
+		if l, ok := node.Attrs["level"].(float64); ok && l >= 1 && l <= 6 {
+			level = int(l)
+		}
+		writePrefixed(b, strings.Repeat("#", level)+" "+adfInline(node.Content), prefix)
+	case "bulletList", "orderedList":
+		for i, item := range node.Content {
+			marker := "- "
+			if node.Type == "orderedList" {
+				marker = fmt.Sprintf("%d. ", i+1)
+			}
+			var itemText strings.Builder
+			writeADFBlocks(&itemText, item.Content, "")
+			lines := strings.Split(strings.TrimRight(itemText.String(), "\n"), "\n")
+			for j, line := range lines {
+				if j == 0 {
+					b.WriteString(prefix + marker + line + "\n")
+				} else {
+					b.WriteString(prefix + strings.Repeat(" ", len(marker)) + line + "\n")
+				}
+			}
+		}
+	case "codeBlock":
+		language, _ := node.Attrs["language"].(string)
+		writePrefixed(b, "```"+language+"\n"+adfPlainText(node.Content)+"\n```", prefix)
+	case "blockquote", "panel":
+		writeADFBlocks(b, node.Content, prefix+"> ")
+	case "rule":
+		writePrefixed(b, "---", prefix)
+	case "table":
+		for i, row := range node.Content {
+			cells := []string{}
+			for _, cell := range row.Content {
+				var cellText strings.Builder
+				writeADFBlocks(&cellText, cell.Content, "")
+				cells = append(cells, strings.ReplaceAll(strings.TrimSpace(cellText.String()), "\n", " "))
+			}
+			writePrefixed(b, "| "+strings.Join(cells, " | ")+" |", prefix)
+			if i == 0 {
+				writePrefixed(b, strings.Repeat("| --- ", len(cells))+"|", prefix)
+			}
+		}
+	case "mediaSingle", "mediaGroup", "media":
+	default:
+		// the unknown containers, like the expands, are flattened
+		if len(node.Content) > 0 {
+			if node.Content[0].Type == "text" {
+				writePrefixed(b, adfInline(node.Content), prefix)
+			} else {
+				writeADFBlocks(b, node.Content, prefix)
+			}
+		}
+	}
+}
+
+func writePrefixed(b *strings.Builder, text, prefix string) {
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString(prefix + line + "\n")
+	}
+}
+
+// adfInline returns the markdown of the inline nodes.
+func adfInline(nodes []adfNode) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		switch node.Type {
+		case "text":
+			b.WriteString(adfMarkedText(node))
+		case "hardBreak":
+			b.WriteString("  \n")
+		case "mention", "emoji", "status":
+			if text, ok := node.Attrs["text"].(string); ok {
+				b.WriteString(text)
+			} else if shortName, ok := node.Attrs["shortName"].(string); ok {
+				b.WriteString(shortName)
+			}
+		case "inlineCard", "blockCard":
+			if url, ok := node.Attrs["url"].(string); ok {
+				b.WriteString(url)
+			}
+		default:
+			b.WriteString(adfInline(node.Content))
+		}
+	}
+	return b.String()
+}
+
+// adfMarkedText returns the markdown of a text node with its marks.
+func adfMarkedText(node adfNode) string {
+	text := node.Text
+	href := ""
+	for _, mark := range node.Marks {
+		switch mark.Type {
+		case "code":
+			text = "`" + text + "`"
+		case "strong":
+			text = "**" + text + "**"
+		case "em":
+			text = "_" + text + "_"
+		case "strike":
+			text = "~~" + text + "~~"
+		case "link":
+			href, _ = mark.Attrs["href"].(string)
+		}
+	}
+	if href != "" {
+		text = "[" + text + "](" + href + ")"
+	}
+	return text
+}
+
+// adfPlainText returns the text of the nodes without their marks, for
+// the code blocks.
+func adfPlainText(nodes []adfNode) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		b.WriteString(node.Text)
+		b.WriteString(adfPlainText(node.Content))
+	}
+	return b.String()
+}
+
+var (
+	wikiHeadingRegexp = regexp.MustCompile(`^h([1-6])\.\s+`)
+	wikiBulletRegexp  = regexp.MustCompile(`^(\*+|-)\s+`)
+	wikiNumberRegexp  = regexp.MustCompile(`^(#+)\s+`)
+	wikiCodeRegexp    = regexp.MustCompile(`^\{(code|noformat)(?::([^}|]*))?[^}]*\}(.*)$`)
+	wikiBoldRegexp    = regexp.MustCompile(`(^|[\s(])\*([^*\s][^*]*?)\*`)
+	wikiMonoRegexp    = regexp.MustCompile(`\{\{(.+?)\}\}`)
+	wikiLinkRegexp    = regexp.MustCompile(`\[([^|\]]+)\|([^\]]+)\]`)
+	wikiURLRegexp     = regexp.MustCompile(`\[(https?://[^\]|]+)\]`)
+	wikiQuoteRegexp   = regexp.MustCompile(`^bq\.\s+`)
+)
+
+// wikiToMarkdown converts Jira wiki markup to markdown: the headings,
+// lists, quotes, code blocks, bold, monospaced text and links. The rest
+// is kept as is.
+func wikiToMarkdown(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	result := make([]string, 0, len(lines))
+	inCode := false
+	for _, line := range lines {
+		if match := wikiCodeRegexp.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			if inCode {
+				result = append(result, "```")
+				inCode = false
+				continue
+			}
+			result = append(result, "```"+strings.TrimSpace(match[2]))
+			inCode = true
+			if rest := strings.TrimSpace(match[3]); rest != "" {
+				result = append(result, rest)
+			}
+			continue
+		}
+		if inCode {
+			result = append(result, line)
+			continue
+		}
+
+		switch {
+		case wikiHeadingRegexp.MatchString(line):
+			level := wikiHeadingRegexp.FindStringSubmatch(line)[1]
+			line = strings.Repeat("#", int(level[0]-'0')) + " " + wikiHeadingRegexp.ReplaceAllString(line, "")
+		case wikiBulletRegexp.MatchString(line):
+			depth := len(strings.TrimSpace(wikiBulletRegexp.FindStringSubmatch(line)[1]))
+			line = strings.Repeat("  ", depth-1) + "- " + wikiBulletRegexp.ReplaceAllString(line, "")
+		case wikiNumberRegexp.MatchString(line):
+			depth := len(wikiNumberRegexp.FindStringSubmatch(line)[1])
+			line = strings.Repeat("   ", depth-1) + "1. " + wikiNumberRegexp.ReplaceAllString(line, "")
+		case wikiQuoteRegexp.MatchString(line):
+			line = "> " + wikiQuoteRegexp.ReplaceAllString(line, "")
+		}
+
+		line = wikiBoldRegexp.ReplaceAllString(line, "$1**$2**")
+		line = wikiMonoRegexp.ReplaceAllString(line, "`$1`")
+		line = wikiLinkRegexp.ReplaceAllString(line, "[$1]($2)")
+		line = wikiURLRegexp.ReplaceAllString(line, "<$1>")
+		result = append(result, line)
+	}
+	if inCode {
+		result = append(result, "```")
+	}
+	return strings.TrimSpace(strings.Join(result, "\n"))
+}
+
+// markdownPart is a fenced code block of a markdown text, or the text
+// between them.
+type markdownPart struct {
+	code     bool
+	language string
+	text     string
+}
+
+var markdownFenceRegexp = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+
+// splitFencedCode splits the markdown into its fenced code blocks, whose
+// language is the first word of the info string, and the text between
+// them. A code block that isn't closed runs to the end of the text.
+func splitFencedCode(markdown string) []markdownPart {
+	parts := []markdownPart{}
+	lines := []string{}
+	var current markdownPart
+	fence := ""
+	flush := func() {
+		current.text = strings.Join(lines, "\n")
+		if current.code || strings.TrimSpace(current.text) != "" {
+			parts = append(parts, current)
+		}
+		lines = []string{}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		if fence == "" {
+			if match := markdownFenceRegexp.FindStringSubmatch(line); match != nil {
+				flush()
+				fence = match[1]
+				current = markdownPart{code: true, language: match[2]}
+				continue
+			}
+			lines = append(lines, line)
+			continue
+		}
+
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			flush()
+			fence = ""
+			current = markdownPart{}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return parts
+}
+
+// codeBlockFields returns the fields of an imported code block.
+func codeBlockFields(language string) map[string]interface{} {
+	return map[string]interface{}{
+		model.CodeLanguageField: model.NormalizeCodeLanguage(language),
+		model.CodeWrapField:     false,
+	}
+}
@@ -0,0 +1,804 @@
+package importer
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+const (
+	// notionSampleRows is the number of rows of a database sampled to
+	// infer the types of its columns
+	notionSampleRows = 100
+
+	// notionMaxSelectOptions is the number of distinct values up to which
+	// a column is a select property
+	notionMaxSelectOptions = 25
+
+	// notionMaxOptionLength is the length of the values up to which a
+	// column can be a select property
+	notionMaxOptionLength = 50
+
+	// notionMinUniqueSample is the number of sampled values from which a
+	// column whose values are all different is a text property
+	notionMinUniqueSample = 10
+
+	// notionMisfitRatio is the inverse of the share of the sampled values
+	// of a column that can not fit its type
+	notionMisfitRatio = 4
+
+	// notionMaxPageSize is the size of the markdown pages read from the
+	// export, the rest being left out
+	notionMaxPageSize = 10 * 1024 * 1024
+
+	notionSubPagesPropertyName = "Sub-pages"
+)
+
+// notionDateLayouts are the layouts of the dates of the Notion exports,
+// which are shown as in the user's locale.
+var notionDateLayouts = []string{
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	"2006-01-02",
+}
+
+var (
+	// notionIDRegexp matches the ID Notion appends to the names of the
+	// exported files
+	notionIDRegexp = regexp.MustCompile(`\s+[0-9a-f]{32}$`)
+
+	notionImageRegexp = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	notionEmailRegexp = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// InvalidNotionExportError is returned when a Notion export can't be
+// read, or has no database.
+type InvalidNotionExportError struct {
+	Reason string
+}
+
+func (e InvalidNotionExportError) Error() string {
+	return "invalid Notion export: " + e.Reason
+}
+
+// SkippedFileError is returned by the NotionFileUploader when a file
+// isn't allowed, for the import to go on without it.
+type SkippedFileError struct {
+	Reason string
+}
+
+func (e SkippedFileError) Error() string {
+	return e.Reason
+}
+
+// NotionFileUploader stores a file of the export for the board with the
+// root ID and returns its file ID.
+type NotionFileUploader func(rootID, filename string, reader io.Reader) (string, error)
+
+// notionColumn is a column of a database, with the card property it's
+// imported as.
+type notionColumn struct {
+	name      string
+	property  map[string]interface{}
+	optionIDs map[string]string
+}
+
+// notionConverter converts the databases of a Notion export to boards.
+type notionConverter struct {
+	files   map[string]*zip.File
+	upload  NotionFileUploader
+	summary *model.ImportSummary
+	now     int64
+
+	// the pages imported as cards, so that they're imported once
+	importedPages map[string]bool
+
+	// the IDs of the uploaded files by board and name, so that the images
+	// shown more than once are uploaded once
+	fileIDs map[string]string
+}
+
+// ConvertNotion converts the databases of a Notion export, a zip of a
+// CSV file per database and of a markdown file per page, to boards. Each
+// row of a database is a card, with the content of its page, and with
+// the pages nested in the page as cards linked by a relation property.
+// The images of the pages are stored with the uploader. The properties
+// are inferred from samples of the values of the columns, the values
+// that don't fit them being skipped.
+func ConvertNotion(archive *zip.Reader, upload NotionFileUploader) ([]model.Block, *model.ImportSummary, error) {
+	c := &notionConverter{
+		files:         map[string]*zip.File{},
+		upload:        upload,
+		summary:       &model.ImportSummary{Skipped: []model.ImportSkippedItem{}},
+		now:           utils.GetMillis(),
+		importedPages: map[string]bool{},
+		fileIDs:       map[string]string{},
+	}
+
+	databases := []string{}
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		c.files[file.Name] = file
+		if strings.EqualFold(path.Ext(file.Name), ".csv") {
+			databases = append(databases, file.Name)
+		}
+	}
+
+	// the recent exports have a CSV of the rows of the view along with
+	// the one of all the rows, whose name ends with _all
+	databasePaths := map[string]string{}
+	for _, name := range databases {
+		base := strings.TrimSuffix(strings.TrimSuffix(name, path.Ext(name)), "_all")
+		if existing, ok := databasePaths[base]; !ok || !strings.HasSuffix(strings.TrimSuffix(existing, path.Ext(existing)), "_all") {
+			databasePaths[base] = name
+		}
+	}
+	if len(databasePaths) == 0 {
+		return nil, nil, InvalidNotionExportError{Reason: "the export has no database"}
+	}
+	bases := make([]string, 0, len(databasePaths))
+	for base := range databasePaths {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	blocks := []model.Block{}
+	for _, base := range bases {
+		databaseBlocks, err := c.convertDatabase(databasePaths[base], base)
+		if err != nil {
+			return nil, nil, err
+		}
+		blocks = append(blocks, databaseBlocks...)
+	}
+
+	skippedPages := []string{}
+	for name := range c.files {
+		if strings.EqualFold(path.Ext(name), ".md") && !c.importedPages[name] {
+			skippedPages = append(skippedPages, name)
+		}
+	}
+	sort.Strings(skippedPages)
+	for _, name := range skippedPages {
+		c.summary.Skip("page", name, notionTitle(name), "the pages outside of a database aren't imported")
+	}
+
+	return blocks, c.summary, nil
+}
+
+// notionTitle returns the title of an exported file, its name without
+// the extension and ID.
+func notionTitle(name string) string {
+	title := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	title = strings.TrimSuffix(title, "_all")
+	return notionIDRegexp.ReplaceAllString(title, "")
+}
+
+// notionTitleKey returns the key matching a title with the name of the
+// file of its page, whose unsupported characters Notion removes.
+func notionTitleKey(title string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, title)
+}
+
+// openCSV returns a reader of the CSV file, without its byte order mark.
+func (c *notionConverter) openCSV(name string) (*csv.Reader, io.Closer, error) {
+	file, err := c.files[name].Open()
+	if err != nil {
+		return nil, nil, InvalidNotionExportError{Reason: fmt.Sprintf("%s can't be read: %s", name, err)}
+	}
+	reader := bufio.NewReader(file)
+	if r, _, err := reader.ReadRune(); err == nil && r != '\uFEFF' {
+		_ = reader.UnreadRune()
+	}
+
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	return csvReader, file, nil
+}
+
+// inferColumns reads the header of the database and infers the types of
+// its columns from the values of the first rows.
+func (c *notionConverter) inferColumns(name string) ([]*notionColumn, error) {
+	reader, closer, err := c.openCSV(name)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, InvalidNotionExportError{Reason: fmt.Sprintf("the header of %s can't be read: %s", name, err)}
+	}
+
+	samples := make([][]string, len(header))
+	for i := 0; i < notionSampleRows; i++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, InvalidNotionExportError{Reason: fmt.Sprintf("%s can't be read: %s", name, err)}
+		}
+		for j, value := range record {
+			if value = strings.TrimSpace(value); j < len(samples) && value != "" {
+				samples[j] = append(samples[j], value)
+			}
+		}
+	}
+
+	// the first column is the title of the rows
+	columns := make([]*notionColumn, 0, len(header))
+	for i, columnName := range header {
+		if i == 0 {
+			continue
+		}
+		columnName = strings.TrimSpace(columnName)
+		propertyType := notionColumnType(samples[i])
+		column := &notionColumn{
+			name: columnName,
+			property: map[string]interface{}{
+				"id":      utils.CreateGUID(),
+				"name":    columnName,
+				"type":    propertyType,
+				"options": []interface{}{},
+			},
+			optionIDs: map[string]string{},
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// notionColumnType returns the property type that suits the sampled
+// values of a column. The columns whose few distinct values are repeated
+// are selects, multi-selects when the values are lists.
+func notionColumnType(values []string) string {
+	if len(values) == 0 {
+		return "text"
+	}
+
+	// a few values that don't fit the type don't prevent it, the rows
+	// being reported by the import
+	allMatch := func(fn func(string) bool) bool {
+		misfits := 0
+		for _, value := range values {
+			if !fn(value) {
+				misfits++
+			}
+		}
+		return misfits <= len(values)/notionMisfitRatio
+	}
+
+	switch {
+	case allMatch(func(value string) bool { _, _, ok := parseNotionDate(value); return ok }):
+		return "date"
+	case allMatch(func(value string) bool { return value == "Yes" || value == "No" }):
+		return "checkbox"
+	case allMatch(func(value string) bool { _, err := strconv.ParseFloat(value, 64); return err == nil }):
+		return "number"
+	case allMatch(func(value string) bool {
+		return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+	}):
+		return "url"
+	case allMatch(notionEmailRegexp.MatchString):
+		return "email"
+	}
+
+	distinct := map[string]bool{}
+	itemCount := 0
+	isList := false
+	for _, value := range values {
+		if len(value) > notionMaxOptionLength*2 || strings.Contains(value, "\n") {
+			return "text"
+		}
+		items := splitNotionList(value)
+		if len(items) > 1 {
+			isList = true
+		}
+		for _, item := range items {
+			if len(item) > notionMaxOptionLength {
+				return "text"
+			}
+			distinct[item] = true
+		}
+		itemCount += len(items)
+	}
+
+	// the values of a larger sample that are all different aren't options
+	if len(distinct) > notionMaxSelectOptions || (len(values) >= notionMinUniqueSample && len(distinct) == itemCount) {
+		return "text"
+	}
+	if isList {
+		return "multiSelect"
+	}
+	return "select"
+}
+
+func splitNotionList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseNotionDate returns the start and end of a date of a Notion export,
+// the end being zero unless the date is a range.
+func parseNotionDate(value string) (time.Time, time.Time, bool) {
+	parse := func(value string) (time.Time, bool) {
+		for _, layout := range notionDateLayouts {
+			if date, err := time.Parse(layout, strings.TrimSpace(value)); err == nil {
+				return date, true
+			}
+		}
+		return time.Time{}, false
+	}
+
+	parts := strings.SplitN(value, "→", 2)
+	from, ok := parse(parts[0])
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	if len(parts) == 1 {
+		return from, time.Time{}, true
+	}
+	to, ok := parse(parts[1])
+	return from, to, ok
+}
+
+// convertDatabase converts the database of the CSV file to a board, with
+// the pages of its rows in the directory with the base name.
+func (c *notionConverter) convertDatabase(name, base string) ([]model.Block, error) {
+	columns, err := c.inferColumns(name)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, closer, err := c.openCSV(name)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	if _, err = reader.Read(); err != nil {
+		return nil, InvalidNotionExportError{Reason: fmt.Sprintf("%s can't be read: %s", name, err)}
+	}
+
+	boardID := utils.CreateGUID()
+	pages := c.pagesIn(base)
+	subPages := map[string]interface{}{
+		"id":      utils.CreateGUID(),
+		"name":    notionSubPagesPropertyName,
+		"type":    model.PropertyTypeRelation,
+		"options": []interface{}{},
+	}
+	hasSubPages := false
+
+	cards := []model.Block{}
+	content := []model.Block{}
+	cardOrder := []interface{}{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, InvalidNotionExportError{Reason: fmt.Sprintf("%s can't be read: %s", name, err)}
+		}
+		rowID := fmt.Sprintf("%s:%d", name, line)
+
+		title := strings.TrimSpace(record[0])
+		properties := map[string]interface{}{}
+		for i, column := range columns {
+			if i+1 >= len(record) {
+				break
+			}
+			value := strings.TrimSpace(record[i+1])
+			if value == "" {
+				continue
+			}
+			propertyValue, ok := column.value(value)
+			if !ok {
+				c.summary.Skip("row", rowID, title, fmt.Sprintf("the %s value %q isn't a %s", column.name, value, column.property["type"]))
+				continue
+			}
+			if propertyValue != nil {
+				properties[column.property["id"].(string)] = propertyValue
+			}
+		}
+
+		card := c.newBlock("card", boardID, boardID, title, map[string]interface{}{
+			"icon":         "",
+			"isTemplate":   false,
+			"properties":   properties,
+			"contentOrder": []interface{}{},
+		})
+		cardOrder = append(cardOrder, card.ID)
+		c.summary.CardsCreated++
+
+		if page := pages.take(title); page != "" {
+			pageBlocks, err := c.convertPage(&card, page, columns)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, pageBlocks...)
+
+			subIDs, subBlocks, err := c.convertSubPages(boardID, page, subPages["id"].(string))
+			if err != nil {
+				return nil, err
+			}
+			if len(subIDs) > 0 {
+				hasSubPages = true
+				properties[subPages["id"].(string)] = subIDs
+				content = append(content, subBlocks...)
+			}
+		}
+		cards = append(cards, card)
+	}
+
+	cardProperties := []interface{}{}
+	for _, column := range columns {
+		cardProperties = append(cardProperties, column.property)
+	}
+	if hasSubPages {
+		cardProperties = append(cardProperties, subPages)
+	}
+
+	board := c.newBlock("board", "", boardID, notionTitle(name), map[string]interface{}{
+		"icon":               "",
+		"description":        "",
+		"showDescription":    false,
+		"isTemplate":         false,
+		"columnCalculations": map[string]interface{}{},
+		"cardProperties":     cardProperties,
+	})
+	board.ID = boardID
+	c.summary.BoardsCreated++
+
+	groupByID := ""
+	visibleOptionIDs := []interface{}{}
+	visiblePropertyIDs := []interface{}{}
+	for _, column := range columns {
+		id := column.property["id"].(string)
+		if groupByID == "" && column.property["type"] == "select" {
+			groupByID = id
+			for _, option := range column.property["options"].([]interface{}) {
+				visibleOptionIDs = append(visibleOptionIDs, option.(map[string]interface{})["id"])
+			}
+			continue
+		}
+		visiblePropertyIDs = append(visiblePropertyIDs, id)
+	}
+
+	view := c.newBlock("view", boardID, boardID, "Board View", map[string]interface{}{
+		"viewType":           "board",
+		"groupById":          groupByID,
+		"sortOptions":        []interface{}{},
+		"visiblePropertyIds": visiblePropertyIDs,
+		"visibleOptionIds":   visibleOptionIDs,
+		"hiddenOptionIds":    []interface{}{},
+		"collapsedOptionIds": []interface{}{},
+		"filter":             map[string]interface{}{"operation": "and", "filters": []interface{}{}},
+		"cardOrder":          cardOrder,
+		"columnWidths":       map[string]interface{}{},
+		"columnCalculations": map[string]interface{}{},
+		"kanbanCalculations": map[string]interface{}{},
+		"defaultTemplateId":  "",
+	})
+
+	blocks := []model.Block{board, view}
+	blocks = append(blocks, cards...)
+	return append(blocks, content...), nil
+}
+
+func (c *notionConverter) newBlock(blockType, parentID, rootID, title string, fields map[string]interface{}) model.Block {
+	return model.Block{
+		ID:       utils.CreateGUID(),
+		ParentID: parentID,
+		RootID:   rootID,
+		Schema:   1,
+		Type:     blockType,
+		Title:    title,
+		Fields:   fields,
+		CreateAt: c.now,
+		UpdateAt: c.now,
+	}
+}
+
+// value returns the value of the property of the column for a value of
+// the database, or false if it doesn't fit the property.
+func (column *notionColumn) value(value string) (interface{}, bool) {
+	switch column.property["type"] {
+	case "date":
+		from, to, ok := parseNotionDate(value)
+		if !ok {
+			return nil, false
+		}
+		date := map[string]int64{"from": utils.MillisFromTime(from)}
+		if !to.IsZero() {
+			date["to"] = utils.MillisFromTime(to)
+		}
+		data, _ := json.Marshal(date)
+		return string(data), true
+	case "checkbox":
+		switch value {
+		case "Yes":
+			return "true", true
+		case "No":
+			return nil, true
+		}
+		return nil, false
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, false
+		}
+		return value, true
+	case "select":
+		return column.optionID(value), true
+	case "multiSelect":
+		ids := []interface{}{}
+		for _, item := range splitNotionList(value) {
+			ids = append(ids, column.optionID(item))
+		}
+		return ids, true
+	}
+	return value, true
+}
+
+// optionID returns the ID of the option of the select property for the
+// value, adding the option if it's new.
+func (column *notionColumn) optionID(value string) string {
+	if id, ok := column.optionIDs[value]; ok {
+		return id
+	}
+
+	options := column.property["options"].([]interface{})
+	id := utils.CreateGUID()
+	column.property["options"] = append(options, map[string]interface{}{
+		"id":    id,
+		"value": value,
+		"color": listColors[len(options)%len(listColors)],
+	})
+	column.optionIDs[value] = id
+	return id
+}
+
+// notionPages are the pages of a directory by the key of their title,
+// in the order of their names for the rows with the same title.
+type notionPages map[string][]string
+
+func (c *notionConverter) pagesIn(dir string) notionPages {
+	names := []string{}
+	for name := range c.files {
+		if path.Dir(name) == dir && strings.EqualFold(path.Ext(name), ".md") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	pages := notionPages{}
+	for _, name := range names {
+		key := notionTitleKey(notionTitle(name))
+		pages[key] = append(pages[key], name)
+	}
+	return pages
+}
+
+// take returns the first page with the title, if any, and removes it.
+func (p notionPages) take(title string) string {
+	key := notionTitleKey(title)
+	names := p[key]
+	if len(names) == 0 {
+		return ""
+	}
+	p[key] = names[1:]
+	return names[0]
+}
+
+// convertSubPages converts the pages nested in the page to cards of the
+// board, each linking to its own nested pages with the relation
+// property, and returns the IDs of the cards of the direct children.
+func (c *notionConverter) convertSubPages(boardID, page, relationID string) ([]interface{}, []model.Block, error) {
+	dir := strings.TrimSuffix(page, path.Ext(page))
+	names := []string{}
+	for _, pages := range c.pagesIn(dir) {
+		names = append(names, pages...)
+	}
+	sort.Strings(names)
+
+	ids := []interface{}{}
+	blocks := []model.Block{}
+	for _, name := range names {
+		properties := map[string]interface{}{}
+		card := c.newBlock("card", boardID, boardID, notionTitle(name), map[string]interface{}{
+			"icon":         "",
+			"isTemplate":   false,
+			"properties":   properties,
+			"contentOrder": []interface{}{},
+		})
+		c.summary.CardsCreated++
+
+		content, err := c.convertPage(&card, name, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		subIDs, subBlocks, err := c.convertSubPages(boardID, name, relationID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(subIDs) > 0 {
+			properties[relationID] = subIDs
+		}
+
+		ids = append(ids, card.ID)
+		blocks = append(blocks, card)
+		blocks = append(blocks, content...)
+		blocks = append(blocks, subBlocks...)
+	}
+	return ids, blocks, nil
+}
+
+// convertPage converts the markdown of the page to the content of the
+// card: text blocks, code blocks for the fenced code, and image blocks
+// for the images of the export. The title and the properties that start
+// the page are left out.
+func (c *notionConverter) convertPage(card *model.Block, name string, columns []*notionColumn) ([]model.Block, error) {
+	c.importedPages[name] = true
+
+	file, err := c.files[name].Open()
+	if err != nil {
+		return nil, InvalidNotionExportError{Reason: fmt.Sprintf("%s can't be read: %s", name, err)}
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(file, notionMaxPageSize))
+	if err != nil {
+		return nil, InvalidNotionExportError{Reason: fmt.Sprintf("%s can't be read: %s", name, err)}
+	}
+
+	markdown := notionPageContent(string(data), columns)
+	blocks := []model.Block{}
+	contentOrder := []interface{}{}
+	addText := func(text string) {
+		if text = strings.TrimSpace(text); text != "" {
+			block := c.newBlock("text", card.ID, card.RootID, text, map[string]interface{}{})
+			blocks = append(blocks, block)
+			contentOrder = append(contentOrder, block.ID)
+		}
+	}
+
+	for _, part := range splitFencedCode(markdown) {
+		if part.code {
+			code := c.newBlock(model.CodeBlockType, card.ID, card.RootID, part.text, codeBlockFields(part.language))
+			blocks = append(blocks, code)
+			contentOrder = append(contentOrder, code.ID)
+			continue
+		}
+
+		last := 0
+		text := part.text
+		for _, match := range notionImageRegexp.FindAllStringSubmatchIndex(text, -1) {
+			target := text[match[4]:match[5]]
+			imagePath, ok := c.exportPath(name, target)
+			if !ok {
+				continue
+			}
+
+			fileID, err := c.uploadFile(card.RootID, imagePath)
+			if err != nil {
+				return nil, err
+			}
+			if fileID == "" {
+				continue
+			}
+
+			addText(text[last:match[0]])
+			image := c.newBlock("image", card.ID, card.RootID, "", map[string]interface{}{"fileId": fileID})
+			blocks = append(blocks, image)
+			contentOrder = append(contentOrder, image.ID)
+			last = match[1]
+		}
+		addText(text[last:])
+	}
+
+	card.Fields["contentOrder"] = contentOrder
+	return blocks, nil
+}
+
+// exportPath returns the path in the export of a relative reference of
+// a page, if it's in the export.
+func (c *notionConverter) exportPath(page, target string) (string, bool) {
+	if strings.Contains(target, "://") || strings.HasPrefix(target, "data:") || strings.HasPrefix(target, "/") {
+		return "", false
+	}
+	unescaped, err := url.PathUnescape(target)
+	if err != nil {
+		return "", false
+	}
+	name := path.Join(path.Dir(page), unescaped)
+	_, ok := c.files[name]
+	return name, ok
+}
+
+// uploadFile stores the file of the export, and returns its file ID, or
+// an empty ID if the file is skipped.
+func (c *notionConverter) uploadFile(rootID, name string) (string, error) {
+	key := rootID + "/" + name
+	if fileID, ok := c.fileIDs[key]; ok {
+		return fileID, nil
+	}
+
+	file, err := c.files[name].Open()
+	if err != nil {
+		return "", InvalidNotionExportError{Reason: fmt.Sprintf("%s can't be read: %s", name, err)}
+	}
+	defer file.Close()
+
+	fileID, err := c.upload(rootID, path.Base(name), file)
+	var skipped SkippedFileError
+	if errors.As(err, &skipped) {
+		c.summary.Skip("file", name, path.Base(name), skipped.Reason)
+		c.fileIDs[key] = ""
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	c.fileIDs[key] = fileID
+	return fileID, nil
+}
+
+// notionPageContent returns the markdown of the page without its title
+// and the properties that follow it.
+func notionPageContent(markdown string, columns []*notionColumn) string {
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "# ") {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	names := map[string]bool{}
+	for _, column := range columns {
+		names[column.name] = true
+	}
+	i := 0
+	for i < len(lines) {
+		parts := strings.SplitN(lines[i], ":", 2)
+		if len(parts) != 2 || !names[strings.TrimSpace(parts[0])] {
+			break
+		}
+		i++
+	}
+	if i > 0 {
+		lines = lines[i:]
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+const notionID = " 0123456789abcdef0123456789abcdef"
+
+func notionArchive(t *testing.T, files map[string]string) *zip.Reader {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		fileWriter, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = fileWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	return archive
+}
+
+func TestConvertNotion(t *testing.T) {
+	archive := notionArchive(t, map[string]string{
+		"Tasks" + notionID + ".csv": "\uFEFFName,Status,Tags,Due,Done,Estimate\n" +
+			"First,To Do,\"backend, api\",\"October 14, 2021\",Yes,3\n" +
+			"Second,Done,api,\"October 14, 2021 → October 20, 2021\",No,2\n" +
+			"Third,To Do,,not a date,No,1\n",
+		"Tasks" + notionID + "_all.csv": "Name,Status,Tags,Due,Done,Estimate\n" +
+			"First,To Do,\"backend, api\",\"October 14, 2021\",Yes,3\n" +
+			"Second,Done,api,\"October 14, 2021 → October 20, 2021\",No,2\n" +
+			"Third,To Do,,not a date,No,1\n" +
+			"Fourth,Done,,,,\n" +
+			"Fifth,Done,,2021/10/14,,\n",
+		"Tasks" + notionID + "/First" + notionID + ".md": "# First\n\nStatus: To Do\nTags: backend, api\n\n" +
+			"Some **text**\n\n![diagram.png](First%20" + notionID[1:] + "/diagram.png)\n\nMore text\n\n![script.exe](First%20" + notionID[1:] + "/script.exe)",
+		"Tasks" + notionID + "/First" + notionID + "/diagram.png":                                         "png",
+		"Tasks" + notionID + "/First" + notionID + "/script.exe":                                          "exe",
+		"Tasks" + notionID + "/First" + notionID + "/Child" + notionID + ".md":                            "# Child\n\nChild content\n\n```Python\nprint(1)\n\n![diagram.png](diagram.png)\n```",
+		"Tasks" + notionID + "/First" + notionID + "/Child" + notionID + "/Grandchild" + notionID + ".md": "# Grandchild",
+		"Notes" + notionID + ".md": "# Notes",
+	})
+
+	uploaded := map[string]string{}
+	upload := func(rootID, filename string, reader io.Reader) (string, error) {
+		if filename == "script.exe" {
+			return "", SkippedFileError{Reason: "the file type isn't allowed"}
+		}
+		data, err := ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		uploaded[filename] = string(data)
+		return "file-" + filename, nil
+	}
+
+	blocks, summary, err := ConvertNotion(archive, upload)
+	require.NoError(t, err)
+	require.Equal(t, 1, summary.BoardsCreated)
+	require.Equal(t, 7, summary.CardsCreated)
+	require.Equal(t, map[string]string{"diagram.png": "png"}, uploaded)
+
+	skipped := map[string]string{}
+	for _, item := range summary.Skipped {
+		skipped[item.Type+" "+item.Name] = item.ID
+	}
+	require.Equal(t, map[string]string{
+		"row Third":       "Tasks" + notionID + "_all.csv:4",
+		"file script.exe": "Tasks" + notionID + "/First" + notionID + "/script.exe",
+		"page Notes":      "Notes" + notionID + ".md",
+	}, skipped)
+
+	byType := map[string][]model.Block{}
+	cards := map[string]model.Block{}
+	for _, block := range blocks {
+		byType[block.Type] = append(byType[block.Type], block)
+		if block.Type == "card" {
+			cards[block.Title] = block
+		}
+	}
+	require.Len(t, byType["board"], 1)
+	board := byType["board"][0]
+	require.Equal(t, "Tasks", board.Title)
+	for _, block := range blocks {
+		require.Equal(t, board.ID, block.RootID)
+	}
+
+	types := map[string]string{}
+	properties := map[string]map[string]interface{}{}
+	for _, item := range board.Fields["cardProperties"].([]interface{}) {
+		property := item.(map[string]interface{})
+		types[property["name"].(string)] = property["type"].(string)
+		properties[property["name"].(string)] = property
+	}
+	require.Equal(t, map[string]string{
+		"Status":    "select",
+		"Tags":      "multiSelect",
+		"Due":       "date",
+		"Done":      "checkbox",
+		"Estimate":  "number",
+		"Sub-pages": "relation",
+	}, types)
+	require.Equal(t, properties["Status"]["id"], byType["view"][0].Fields["groupById"])
+
+	statusOptions := optionIDsByValue(properties["Status"])
+	tagOptions := optionIDsByValue(properties["Tags"])
+	propertyID := func(name string) string {
+		return properties[name]["id"].(string)
+	}
+
+	require.Len(t, cards, 7)
+	first := cards["First"]
+	require.Equal(t, map[string]interface{}{
+		propertyID("Status"):    statusOptions["To Do"],
+		propertyID("Tags"):      []interface{}{tagOptions["backend"], tagOptions["api"]},
+		propertyID("Due"):       `{"from":1634169600000}`,
+		propertyID("Done"):      "true",
+		propertyID("Estimate"):  "3",
+		propertyID("Sub-pages"): []interface{}{cards["Child"].ID},
+	}, first.Fields["properties"])
+	require.Equal(t, `{"from":1634169600000,"to":1634688000000}`, cards["Second"].Fields["properties"].(map[string]interface{})[propertyID("Due")])
+	require.NotContains(t, cards["Third"].Fields["properties"], propertyID("Due"))
+	require.Equal(t, map[string]interface{}{propertyID("Sub-pages"): []interface{}{cards["Grandchild"].ID}}, cards["Child"].Fields["properties"])
+
+	content := map[string]model.Block{}
+	for _, block := range blocks {
+		content[block.ID] = block
+	}
+	contentOrder := first.Fields["contentOrder"].([]interface{})
+	require.Len(t, contentOrder, 3)
+	require.Equal(t, "Some **text**", content[contentOrder[0].(string)].Title)
+	image := content[contentOrder[1].(string)]
+	require.Equal(t, "image", image.Type)
+	require.Equal(t, "file-diagram.png", image.Fields["fileId"])
+	require.Equal(t, "More text\n\n![script.exe](First%20"+notionID[1:]+"/script.exe)", content[contentOrder[2].(string)].Title)
+
+	childContent := cards["Child"].Fields["contentOrder"].([]interface{})
+	require.Len(t, childContent, 2)
+	require.Equal(t, "Child content", content[childContent[0].(string)].Title)
+	// the images of the code are code
+	code := content[childContent[1].(string)]
+	require.Equal(t, model.CodeBlockType, code.Type)
+	require.Equal(t, "print(1)\n\n![diagram.png](diagram.png)", code.Title)
+	require.Equal(t, map[string]interface{}{model.CodeLanguageField: "python", model.CodeWrapField: false}, code.Fields)
+	require.Empty(t, cards["Fourth"].Fields["contentOrder"])
+
+	t.Run("no database", func(t *testing.T) {
+		_, _, err := ConvertNotion(notionArchive(t, map[string]string{"Notes.md": "# Notes"}), upload)
+		var invalid InvalidNotionExportError
+		require.ErrorAs(t, err, &invalid)
+	})
+}
+
+func TestNotionColumnType(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   []string
+		expected string
+	}{
+		{"empty", nil, "text"},
+		{"dates", []string{"October 14, 2021", "October 14, 2021 3:04 PM", "2021/10/14"}, "date"},
+		{"checkboxes", []string{"Yes", "No"}, "checkbox"},
+		{"dates with a misfit", []string{"2021/10/14", "2021/10/15", "2021/10/16", "soon"}, "date"},
+		{"numbers", []string{"1", "2.5", "-3"}, "number"},
+		{"urls", []string{"https://example.com", "http://example.com/a"}, "url"},
+		{"emails", []string{"a@example.com"}, "email"},
+		{"selects", []string{"To Do", "Done", "To Do"}, "select"},
+		{"multi-selects", []string{"a, b", "b"}, "multiSelect"},
+		{"long values", []string{"Done", "a value that is much longer than the values of the options of a select property, a sentence"}, "text"},
+		{"unique values", []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}, "text"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, notionColumnType(tc.values))
+		})
+	}
+}
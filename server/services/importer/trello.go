@@ -0,0 +1,341 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+)
+
+// TrelloBoard is the subset of a Trello board JSON export used by the
+// import.
+type TrelloBoard struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Desc       string            `json:"desc"`
+	Lists      []TrelloList      `json:"lists"`
+	Labels     []TrelloLabel     `json:"labels"`
+	Cards      []TrelloCard      `json:"cards"`
+	Checklists []TrelloChecklist `json:"checklists"`
+	Actions    []TrelloAction    `json:"actions"`
+}
+
+type TrelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+type TrelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type TrelloCard struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Desc        string             `json:"desc"`
+	Closed      bool               `json:"closed"`
+	IDList      string             `json:"idList"`
+	IDLabels    []string           `json:"idLabels"`
+	Pos         float64            `json:"pos"`
+	URL         string             `json:"url"`
+	Attachments []TrelloAttachment `json:"attachments"`
+}
+
+type TrelloAttachment struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type TrelloChecklist struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	IDCard     string            `json:"idCard"`
+	Pos        float64           `json:"pos"`
+	CheckItems []TrelloCheckItem `json:"checkItems"`
+}
+
+type TrelloCheckItem struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+type TrelloAction struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Text string `json:"text"`
+		Card *struct {
+			ID string `json:"id"`
+		} `json:"card"`
+	} `json:"data"`
+}
+
+// trelloLabelColors maps the Trello label colors to the option colors.
+var trelloLabelColors = map[string]string{
+	"green":  "propColorGreen",
+	"lime":   "propColorGreen",
+	"yellow": "propColorYellow",
+	"orange": "propColorOrange",
+	"red":    "propColorRed",
+	"purple": "propColorPurple",
+	"blue":   "propColorBlue",
+	"sky":    "propColorBlue",
+	"pink":   "propColorPink",
+	"black":  "propColorGray",
+}
+
+// listColors are the colors given to the options of the lists, in turn.
+var listColors = []string{
+	"propColorGray",
+	"propColorBrown",
+	"propColorOrange",
+	"propColorYellow",
+	"propColorGreen",
+	"propColorBlue",
+	"propColorPurple",
+	"propColorPink",
+	"propColorRed",
+}
+
+// ConvertTrello converts a Trello board to a board with a board view,
+// where the lists are the options of a select property and the labels
+// the options of a multi-select property. The description, checklists
+// and comments of the cards are imported as their content. Archived
+// cards and the attachments aren't imported, the attachments are
+// linked in the description of their card instead.
+func ConvertTrello(input TrelloBoard) ([]model.Block, *model.ImportSummary) {
+	summary := &model.ImportSummary{
+		Skipped: []model.ImportSkippedItem{},
+	}
+	now := utils.GetMillis()
+
+	newBlock := func(blockType, parentID, rootID, title string, fields map[string]interface{}) model.Block {
+		id := utils.CreateGUID()
+		if rootID == "" {
+			rootID = id
+		}
+		return model.Block{
+			ID:       id,
+			ParentID: parentID,
+			RootID:   rootID,
+			Schema:   1,
+			Type:     blockType,
+			Title:    title,
+			Fields:   fields,
+			CreateAt: now,
+			UpdateAt: now,
+		}
+	}
+
+	// Lists, as the options of the select property
+	lists := append([]TrelloList{}, input.Lists...)
+	sort.SliceStable(lists, func(i, j int) bool { return lists[i].Pos < lists[j].Pos })
+
+	listOptionIDs := map[string]string{}
+	listOptions := []interface{}{}
+	visibleOptionIDs := []interface{}{}
+	for i, list := range lists {
+		optionID := utils.CreateGUID()
+		listOptionIDs[list.ID] = optionID
+		listOptions = append(listOptions, map[string]interface{}{
+			"id":    optionID,
+			"value": list.Name,
+			"color": listColors[i%len(listColors)],
+		})
+		visibleOptionIDs = append(visibleOptionIDs, optionID)
+	}
+	listProperty := map[string]interface{}{
+		"id":      utils.CreateGUID(),
+		"name":    "List",
+		"type":    "select",
+		"options": listOptions,
+	}
+
+	// Labels, as the options of the multi-select property
+	labelOptionIDs := map[string]string{}
+	labelOptions := []interface{}{}
+	for _, label := range input.Labels {
+		name := label.Name
+		if name == "" {
+			name = label.Color
+		}
+		color, ok := trelloLabelColors[label.Color]
+		if !ok {
+			color = "propColorDefault"
+		}
+		optionID := utils.CreateGUID()
+		labelOptionIDs[label.ID] = optionID
+		labelOptions = append(labelOptions, map[string]interface{}{
+			"id":    optionID,
+			"value": name,
+			"color": color,
+		})
+	}
+	labelProperty := map[string]interface{}{
+		"id":      utils.CreateGUID(),
+		"name":    "Labels",
+		"type":    "multiSelect",
+		"options": labelOptions,
+	}
+	listPropertyID := listProperty["id"].(string)
+	labelPropertyID := labelProperty["id"].(string)
+
+	board := newBlock("board", "", "", input.Name, map[string]interface{}{
+		"icon":               "",
+		"description":        input.Desc,
+		"showDescription":    input.Desc != "",
+		"isTemplate":         false,
+		"columnCalculations": map[string]interface{}{},
+		"cardProperties":     []interface{}{listProperty, labelProperty},
+	})
+	summary.BoardsCreated = 1
+
+	view := newBlock("view", board.ID, board.ID, "Board View", map[string]interface{}{
+		"viewType":           "board",
+		"groupById":          listPropertyID,
+		"sortOptions":        []interface{}{},
+		"visiblePropertyIds": []interface{}{labelPropertyID},
+		"visibleOptionIds":   visibleOptionIDs,
+		"hiddenOptionIds":    []interface{}{},
+		"collapsedOptionIds": []interface{}{},
+		"filter":             map[string]interface{}{"operation": "and", "filters": []interface{}{}},
+		"cardOrder":          []interface{}{},
+		"columnWidths":       map[string]interface{}{},
+		"columnCalculations": map[string]interface{}{},
+		"kanbanCalculations": map[string]interface{}{},
+		"defaultTemplateId":  "",
+	})
+
+	// Cards
+	cards := append([]TrelloCard{}, input.Cards...)
+	sort.SliceStable(cards, func(i, j int) bool { return cards[i].Pos < cards[j].Pos })
+
+	cardBlocks := map[string]*model.Block{}
+	cardOrder := []interface{}{}
+	content := map[string][]model.Block{}
+	for _, card := range cards {
+		if card.Closed {
+			summary.Skip("card", card.ID, card.Name, "the card is archived")
+			continue
+		}
+
+		properties := map[string]interface{}{}
+		if optionID, ok := listOptionIDs[card.IDList]; ok {
+			properties[listPropertyID] = optionID
+		}
+		labels := []interface{}{}
+		for _, labelID := range card.IDLabels {
+			if optionID, ok := labelOptionIDs[labelID]; ok {
+				labels = append(labels, optionID)
+			}
+		}
+		if len(labels) > 0 {
+			properties[labelPropertyID] = labels
+		}
+
+		cardBlock := newBlock("card", board.ID, board.ID, card.Name, map[string]interface{}{
+			"icon":         "",
+			"isTemplate":   false,
+			"properties":   properties,
+			"contentOrder": []interface{}{},
+		})
+		cardBlocks[card.ID] = &cardBlock
+		cardOrder = append(cardOrder, cardBlock.ID)
+		summary.CardsCreated++
+
+		description := card.Desc
+		for _, attachment := range card.Attachments {
+			summary.Skip("attachment", attachment.ID, attachment.Name, "attachments aren't imported, they are linked in the card description")
+			if attachment.URL == "" {
+				continue
+			}
+			name := attachment.Name
+			if name == "" {
+				name = attachment.URL
+			}
+			if description != "" {
+				description += "\n\n"
+			}
+			description += fmt.Sprintf("[%s](%s)", name, attachment.URL)
+		}
+		// the fenced code of the description is in code blocks
+		for _, part := range splitFencedCode(description) {
+			if part.code {
+				content[card.ID] = append(content[card.ID], newBlock(model.CodeBlockType, cardBlock.ID, board.ID, part.text, codeBlockFields(part.language)))
+				continue
+			}
+			content[card.ID] = append(content[card.ID], newBlock("text", cardBlock.ID, board.ID, strings.TrimSpace(part.text), map[string]interface{}{}))
+		}
+	}
+	view.Fields["cardOrder"] = cardOrder
+
+	// Checklists, as a text block with the name followed by a checkbox
+	// block per item
+	checklists := append([]TrelloChecklist{}, input.Checklists...)
+	sort.SliceStable(checklists, func(i, j int) bool { return checklists[i].Pos < checklists[j].Pos })
+	for _, checklist := range checklists {
+		cardBlock, ok := cardBlocks[checklist.IDCard]
+		if !ok {
+			summary.Skip("checklist", checklist.ID, checklist.Name, "the card of the checklist wasn't imported")
+			continue
+		}
+
+		if strings.TrimSpace(checklist.Name) != "" {
+			content[checklist.IDCard] = append(content[checklist.IDCard], newBlock("text", cardBlock.ID, board.ID, checklist.Name, map[string]interface{}{}))
+		}
+
+		items := append([]TrelloCheckItem{}, checklist.CheckItems...)
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+		for _, item := range items {
+			content[checklist.IDCard] = append(content[checklist.IDCard], newBlock("checkbox", cardBlock.ID, board.ID, item.Name, map[string]interface{}{
+				"value": item.State == "complete",
+			}))
+		}
+	}
+
+	blocks := []model.Block{board, view}
+	for _, card := range cards {
+		cardBlock, ok := cardBlocks[card.ID]
+		if !ok {
+			continue
+		}
+
+		contentOrder := []interface{}{}
+		for _, block := range content[card.ID] {
+			contentOrder = append(contentOrder, block.ID)
+		}
+		cardBlock.Fields["contentOrder"] = contentOrder
+
+		blocks = append(blocks, *cardBlock)
+		blocks = append(blocks, content[card.ID]...)
+	}
+
+	// Comments, which aren't part of the content order
+	for _, action := range input.Actions {
+		if action.Type != "commentCard" {
+			continue
+		}
+		if action.Data.Card == nil {
+			summary.Skip("comment", action.ID, "", "the comment has no card")
+			continue
+		}
+		cardBlock, ok := cardBlocks[action.Data.Card.ID]
+		if !ok {
+			summary.Skip("comment", action.ID, "", "the card of the comment wasn't imported")
+			continue
+		}
+		blocks = append(blocks, newBlock("comment", cardBlock.ID, board.ID, action.Data.Text, map[string]interface{}{}))
+		summary.CommentsCreated++
+	}
+
+	return blocks, summary
+}
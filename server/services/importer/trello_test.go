@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+const trelloExport = `{
+	"id": "trello-board",
+	"name": "Roadmap",
+	"desc": "Our roadmap",
+	"lists": [
+		{"id": "list-done", "name": "Done", "pos": 2},
+		{"id": "list-todo", "name": "To Do", "pos": 1}
+	],
+	"labels": [
+		{"id": "label-bug", "name": "Bug", "color": "red"},
+		{"id": "label-green", "name": "", "color": "green"}
+	],
+	"cards": [
+		{
+			"id": "card-2", "name": "Second", "idList": "list-done", "pos": 2,
+			"idLabels": ["label-green"],
+			"attachments": [{"id": "attachment-1", "name": "spec.pdf", "url": "https://trello.com/spec.pdf"}]
+		},
+		{
+			"id": "card-1", "name": "First", "desc": "Details\n\n~~~JS title=\"build.js\"\nrun()\n~~~", "idList": "list-todo", "pos": 1,
+			"idLabels": ["label-bug", "unknown-label"]
+		},
+		{"id": "card-archived", "name": "Archived", "closed": true, "idList": "list-todo", "pos": 3}
+	],
+	"checklists": [
+		{
+			"id": "checklist-1", "name": "Steps", "idCard": "card-1", "pos": 1,
+			"checkItems": [
+				{"id": "item-2", "name": "Ship", "state": "incomplete", "pos": 2},
+				{"id": "item-1", "name": "Build", "state": "complete", "pos": 1}
+			]
+		},
+		{"id": "checklist-archived", "name": "Old", "idCard": "card-archived", "pos": 2}
+	],
+	"actions": [
+		{"id": "action-1", "type": "commentCard", "data": {"text": "Looks good", "card": {"id": "card-1"}}},
+		{"id": "action-2", "type": "commentCard", "data": {"text": "Old comment", "card": {"id": "card-archived"}}},
+		{"id": "action-3", "type": "updateCard", "data": {"card": {"id": "card-1"}}}
+	]
+}`
+
+func TestConvertTrello(t *testing.T) {
+	var input TrelloBoard
+	require.NoError(t, json.Unmarshal([]byte(trelloExport), &input))
+
+	blocks, summary := ConvertTrello(input)
+
+	require.Equal(t, 1, summary.BoardsCreated)
+	require.Equal(t, 2, summary.CardsCreated)
+	require.Equal(t, 1, summary.CommentsCreated)
+	require.ElementsMatch(t, []string{"card-archived", "attachment-1", "checklist-archived", "action-2"}, skippedIDs(summary))
+
+	byType := map[string][]model.Block{}
+	for _, block := range blocks {
+		byType[block.Type] = append(byType[block.Type], block)
+	}
+
+	require.Len(t, byType["board"], 1)
+	board := byType["board"][0]
+	require.Equal(t, "Roadmap", board.Title)
+	require.Equal(t, "Our roadmap", board.Fields["description"])
+	for _, block := range blocks {
+		require.Equal(t, board.ID, block.RootID)
+		require.NotEmpty(t, block.ID)
+	}
+
+	cardProperties := board.Fields["cardProperties"].([]interface{})
+	require.Len(t, cardProperties, 2)
+	listProperty := cardProperties[0].(map[string]interface{})
+	labelProperty := cardProperties[1].(map[string]interface{})
+	require.Equal(t, "select", listProperty["type"])
+	require.Equal(t, "multiSelect", labelProperty["type"])
+
+	listOptions := optionIDsByValue(listProperty)
+	labelOptions := optionIDsByValue(labelProperty)
+	require.Len(t, listOptions, 2)
+	require.Contains(t, labelOptions, "Bug")
+	require.Contains(t, labelOptions, "green")
+
+	require.Len(t, byType["view"], 1)
+	view := byType["view"][0]
+	require.Equal(t, listProperty["id"], view.Fields["groupById"])
+
+	require.Len(t, byType["card"], 2)
+	first, second := byType["card"][0], byType["card"][1]
+	require.Equal(t, "First", first.Title)
+	require.Equal(t, "Second", second.Title)
+	require.Equal(t, []interface{}{first.ID, second.ID}, view.Fields["cardOrder"])
+
+	firstProperties := first.Fields["properties"].(map[string]interface{})
+	require.Equal(t, listOptions["To Do"], firstProperties[listProperty["id"].(string)])
+	require.Equal(t, []interface{}{labelOptions["Bug"]}, firstProperties[labelProperty["id"].(string)])
+
+	contents := map[string]model.Block{}
+	for _, block := range blocks {
+		if block.ParentID == first.ID && block.Type != "comment" {
+			contents[block.ID] = block
+		}
+	}
+	contentOrder := first.Fields["contentOrder"].([]interface{})
+	require.Len(t, contentOrder, 5)
+	require.Equal(t, "Details", contents[contentOrder[0].(string)].Title)
+	code := contents[contentOrder[1].(string)]
+	require.Equal(t, model.CodeBlockType, code.Type)
+	require.Equal(t, "run()", code.Title)
+	require.Equal(t, "javascript", code.Fields[model.CodeLanguageField])
+	require.Equal(t, "Steps", contents[contentOrder[2].(string)].Title)
+	build := contents[contentOrder[3].(string)]
+	require.Equal(t, "checkbox", build.Type)
+	require.Equal(t, "Build", build.Title)
+	require.Equal(t, true, build.Fields["value"])
+	require.Equal(t, false, contents[contentOrder[4].(string)].Fields["value"])
+
+	secondContentOrder := second.Fields["contentOrder"].([]interface{})
+	require.Len(t, secondContentOrder, 1)
+	for _, block := range byType["text"] {
+		if block.ID == secondContentOrder[0] {
+			require.Equal(t, "[spec.pdf](https://trello.com/spec.pdf)", block.Title)
+		}
+	}
+
+	require.Len(t, byType["comment"], 1)
+	require.Equal(t, first.ID, byType["comment"][0].ParentID)
+	require.Equal(t, "Looks good", byType["comment"][0].Title)
+}
+
+func skippedIDs(summary *model.ImportSummary) []string {
+	ids := []string{}
+	for _, item := range summary.Skipped {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func optionIDsByValue(property map[string]interface{}) map[string]string {
+	ids := map[string]string{}
+	for _, item := range property["options"].([]interface{}) {
+		option := item.(map[string]interface{})
+		ids[option["value"].(string)] = option["id"].(string)
+	}
+	return ids
+}
@@ -0,0 +1,393 @@
+// Package ldap authenticates the users against an LDAP server, binding
+// with their password, and returns the attributes of their entry.
+package ldap
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/config"
+
+	goldap "github.com/mattermost/ldap"
+)
+
+const (
+	ConnectionSecurityNone     = ""
+	ConnectionSecurityStartTLS = "STARTTLS"
+	ConnectionSecurityTLS      = "TLS"
+
+	DefaultUsernameAttribute = "uid"
+	DefaultEmailAttribute    = "mail"
+	DefaultNicknameAttribute = "cn"
+	DefaultMaxConnections    = 10
+
+	defaultPort     = 389
+	defaultTLSPort  = 636
+	defaultTimeout  = 10 * time.Second
+	defaultFilter   = "(objectClass=*)"
+	usernameKeyword = "{username}"
+)
+
+var (
+	// ErrInvalidCredentials is returned when the user doesn't exist in the
+	// directory, or the password is wrong.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+
+	// ErrUnavailable is returned when the server can't be reached, or
+	// doesn't answer in time.
+	ErrUnavailable = errors.New("the LDAP server is unavailable")
+)
+
+// Entry is the entry of an authenticated user, with its mapped
+// attributes.
+type Entry struct {
+	DN string
+
+	// ID identifies the user in the directory, with the ID attribute or
+	// else the DN
+	ID string
+
+	Username string
+	Email    string
+	Nickname string
+}
+
+// Client authenticates the users against an LDAP server, reusing its
+// connections. It's safe for concurrent use.
+type Client struct {
+	config    config.LDAPConfig
+	address   string
+	tlsConfig *tls.Config
+	timeout   time.Duration
+
+	// idle are the open connections waiting to be reused, and slots
+	// bounds the number of connections in use
+	idle  chan *goldap.Conn
+	slots chan struct{}
+}
+
+// NewClient returns a client of the LDAP server, or nil if no server is
+// configured.
+func NewClient(cfg config.LDAPConfig) *Client {
+	if cfg.Server == "" {
+		return nil
+	}
+	if cfg.Port == 0 {
+		cfg.Port = defaultPort
+		if cfg.ConnectionSecurity == ConnectionSecurityTLS {
+			cfg.Port = defaultTLSPort
+		}
+	}
+	if cfg.UsernameAttribute == "" {
+		cfg.UsernameAttribute = DefaultUsernameAttribute
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = DefaultEmailAttribute
+	}
+	if cfg.NicknameAttribute == "" {
+		cfg.NicknameAttribute = DefaultNicknameAttribute
+	}
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = defaultFilter
+	} else if !strings.HasPrefix(cfg.UserFilter, "(") {
+		cfg.UserFilter = "(" + cfg.UserFilter + ")"
+	}
+	if cfg.MaxConnections <= 0 {
+		cfg.MaxConnections = DefaultMaxConnections
+	}
+
+	timeout := defaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	return &Client{
+		config:  cfg,
+		address: net.JoinHostPort(cfg.Server, strconv.Itoa(cfg.Port)),
+		tlsConfig: &tls.Config{
+			ServerName:         cfg.Server,
+			InsecureSkipVerify: cfg.SkipCertificateVerification, //nolint:gosec
+		},
+		timeout: timeout,
+		idle:    make(chan *goldap.Conn, cfg.MaxConnections),
+		slots:   make(chan struct{}, cfg.MaxConnections),
+	}
+}
+
+// Authenticate binds as the user of the username, or of the email when
+// the username is empty, with the password, and returns the entry of the
+// user. It returns ErrInvalidCredentials if the bind is refused, and
+// ErrUnavailable if the server can't be reached.
+func (c *Client) Authenticate(username, email, password string) (*Entry, error) {
+	// a bind without a password is an unauthenticated bind, which the
+	// servers accept for any DN
+	if password == "" || (username == "" && email == "") {
+		return nil, ErrInvalidCredentials
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+	case <-time.After(c.timeout):
+		return nil, fmt.Errorf("%w: all the %d connections are in use", ErrUnavailable, c.config.MaxConnections)
+	}
+	defer func() { <-c.slots }()
+
+	conn, reused, err := c.getConn()
+	if err != nil {
+		return nil, err
+	}
+	entry, err := c.authenticate(conn, username, email, password)
+	if reused && errors.Is(err, ErrUnavailable) {
+		// the server may have closed the idle connection
+		conn.Close()
+		if conn, err = c.dial(); err != nil {
+			return nil, err
+		}
+		entry, err = c.authenticate(conn, username, email, password)
+	}
+	c.putConn(conn, err)
+	return entry, err
+}
+
+// Close closes the idle connections.
+func (c *Client) Close() {
+	for {
+		select {
+		case conn := <-c.idle:
+			conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// authenticate authenticates the user on the connection, returning
+// ErrUnavailable if the connection was closed.
+func (c *Client) authenticate(conn *goldap.Conn, username, email, password string) (*Entry, error) {
+	entry, err := c.bindUser(conn, username, email, password)
+	if err != nil && conn.IsClosing() && !errors.Is(err, ErrUnavailable) {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	return entry, err
+}
+
+func (c *Client) bindUser(conn *goldap.Conn, username, email, password string) (*Entry, error) {
+	var entry *goldap.Entry
+	var dn string
+	if c.config.UserDNTemplate != "" && username != "" {
+		dn = strings.ReplaceAll(c.config.UserDNTemplate, usernameKeyword, EscapeDN(username))
+	} else {
+		var err error
+		if entry, err = c.searchUser(conn, username, email); err != nil {
+			return nil, err
+		}
+		dn = entry.DN
+	}
+
+	if err := conn.Bind(dn, password); err != nil {
+		return nil, convertError(err)
+	}
+
+	if entry == nil {
+		var err error
+		if entry, err = c.readUser(conn, dn); err != nil {
+			return nil, err
+		}
+	}
+	return c.newEntry(entry, username)
+}
+
+// searchUser returns the entry of the username or the email under the
+// base DN, binding as the search user first.
+func (c *Client) searchUser(conn *goldap.Conn, username, email string) (*goldap.Entry, error) {
+	if c.config.BaseDN == "" {
+		return nil, errors.New("no base DN to search the users under")
+	}
+
+	var err error
+	if c.config.BindDN != "" {
+		err = conn.Bind(c.config.BindDN, c.config.BindPassword)
+	} else {
+		// the connection may be bound as the last user
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to bind as the search user: %w", convertError(err))
+	}
+
+	attribute, value := c.config.UsernameAttribute, username
+	if username == "" {
+		attribute, value = c.config.EmailAttribute, email
+	}
+	filter := fmt.Sprintf("(&%s(%s=%s))", c.config.UserFilter, attribute, goldap.EscapeFilter(value))
+
+	result, err := conn.Search(goldap.NewSearchRequest(
+		c.config.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases,
+		0, int(c.timeout.Seconds()), false, filter, c.attributes(), nil,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("unable to search the user: %w", convertError(err))
+	}
+	switch len(result.Entries) {
+	case 0:
+		return nil, ErrInvalidCredentials
+	case 1:
+		return result.Entries[0], nil
+	}
+	return nil, fmt.Errorf("%d entries match the user %q", len(result.Entries), value)
+}
+
+// readUser returns the entry of the DN, as the user bound to it.
+func (c *Client) readUser(conn *goldap.Conn, dn string) (*goldap.Entry, error) {
+	result, err := conn.Search(goldap.NewSearchRequest(
+		dn, goldap.ScopeBaseObject, goldap.NeverDerefAliases,
+		0, int(c.timeout.Seconds()), false, c.config.UserFilter, c.attributes(), nil,
+	))
+	if err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("unable to read the user: %w", convertError(err))
+	}
+	if len(result.Entries) != 1 {
+		// the user doesn't match the filter
+		return nil, ErrInvalidCredentials
+	}
+	return result.Entries[0], nil
+}
+
+func (c *Client) attributes() []string {
+	attributes := []string{c.config.UsernameAttribute, c.config.EmailAttribute, c.config.NicknameAttribute}
+	if c.config.IDAttribute != "" {
+		attributes = append(attributes, c.config.IDAttribute)
+	}
+	return attributes
+}
+
+// newEntry maps the attributes of the entry, the username defaulting to
+// the one the user logged in with.
+func (c *Client) newEntry(entry *goldap.Entry, username string) (*Entry, error) {
+	mapped := &Entry{
+		DN:       entry.DN,
+		ID:       strings.ToLower(entry.DN),
+		Username: attributeValue(entry, c.config.UsernameAttribute),
+		Email:    attributeValue(entry, c.config.EmailAttribute),
+		Nickname: attributeValue(entry, c.config.NicknameAttribute),
+	}
+	if c.config.IDAttribute != "" {
+		mapped.ID = attributeValue(entry, c.config.IDAttribute)
+		if mapped.ID == "" {
+			return nil, fmt.Errorf("the entry %q has no %s attribute", entry.DN, c.config.IDAttribute)
+		}
+	}
+	if mapped.Username == "" {
+		mapped.Username = username
+	}
+	return mapped, nil
+}
+
+// attributeValue returns the first value of the attribute, whose name
+// the servers may return in another case.
+func attributeValue(entry *goldap.Entry, name string) string {
+	for _, attribute := range entry.Attributes {
+		if strings.EqualFold(attribute.Name, name) && len(attribute.Values) > 0 {
+			return attribute.Values[0]
+		}
+	}
+	return ""
+}
+
+// getConn returns an idle connection, or a new one, telling if it's
+// reused.
+func (c *Client) getConn() (*goldap.Conn, bool, error) {
+	for {
+		select {
+		case conn := <-c.idle:
+			if conn.IsClosing() {
+				continue
+			}
+			return conn, true, nil
+		default:
+			conn, err := c.dial()
+			return conn, false, err
+		}
+	}
+}
+
+// putConn keeps the connection to be reused, unless it failed.
+func (c *Client) putConn(conn *goldap.Conn, err error) {
+	if errors.Is(err, ErrUnavailable) || conn.IsClosing() {
+		conn.Close()
+		return
+	}
+	select {
+	case c.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+func (c *Client) dial() (*goldap.Conn, error) {
+	dialer := &net.Dialer{Timeout: c.timeout}
+
+	var netConn net.Conn
+	var err error
+	isTLS := c.config.ConnectionSecurity == ConnectionSecurityTLS
+	if isTLS {
+		netConn, err = tls.DialWithDialer(dialer, "tcp", c.address, c.tlsConfig)
+	} else {
+		netConn, err = dialer.Dial("tcp", c.address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+
+	conn := goldap.NewConn(netConn, isTLS)
+	conn.Start()
+	conn.SetTimeout(c.timeout)
+
+	if c.config.ConnectionSecurity == ConnectionSecurityStartTLS {
+		if err := conn.StartTLS(c.tlsConfig); err != nil {
+			conn.Close()
+			return nil, convertError(err)
+		}
+	}
+	return conn, nil
+}
+
+// convertError returns ErrInvalidCredentials for the refused binds, and
+// ErrUnavailable for the network errors.
+func convertError(err error) error {
+	switch {
+	case goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials):
+		return ErrInvalidCredentials
+	case goldap.IsErrorWithCode(err, goldap.ErrorNetwork):
+		return fmt.Errorf("%w: %s", ErrUnavailable, err)
+	}
+	return err
+}
+
+// EscapeDN escapes the special characters of a value of a DN, as
+// described in RFC 4514.
+func EscapeDN(value string) string {
+	var builder strings.Builder
+	for i, r := range value {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, r),
+			r == '#' && i == 0,
+			r == ' ' && (i == 0 || i == len(value)-1):
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r == 0:
+			builder.WriteString(`\00`)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,215 @@
+package ldap
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/ldap/ldaptest"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBaseDN     = "ou=people,dc=example,dc=com"
+	testBindDN     = "cn=focalboard,dc=example,dc=com"
+	testBindSecret = "bind secret"
+)
+
+func newTestServer(server *ldaptest.Server) *ldaptest.Server {
+	server.AddEntry(testBindDN, testBindSecret, nil)
+	server.AddEntry("uid=alice,"+testBaseDN, "alice password", map[string][]string{
+		"objectClass": {"person"},
+		"uid":         {"alice"},
+		"mail":        {"alice@example.com"},
+		"cn":          {"Alice Smith"},
+		"displayName": {"Alice"},
+		"employeeID":  {"E-1"},
+	})
+	server.AddEntry("uid=bob,"+testBaseDN, "bob password", map[string][]string{
+		"objectClass": {"device"},
+		"uid":         {"bob"},
+	})
+	return server
+}
+
+func newTestConfig(server *ldaptest.Server) config.LDAPConfig {
+	return config.LDAPConfig{
+		Server:     server.Host(),
+		Port:       server.Port(),
+		BaseDN:     testBaseDN,
+		BindDN:     testBindDN,
+		Timeout:    5,
+		UserFilter: "(objectClass=person)",
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	require.Nil(t, NewClient(config.LDAPConfig{}))
+
+	client := NewClient(config.LDAPConfig{Server: "ldap.example.com", ConnectionSecurity: ConnectionSecurityTLS, UserFilter: "objectClass=person"})
+	require.Equal(t, "ldap.example.com:636", client.address)
+	require.Equal(t, "(objectClass=person)", client.config.UserFilter)
+	require.Equal(t, DefaultMaxConnections, cap(client.idle))
+}
+
+func TestAuthenticate(t *testing.T) {
+	server := newTestServer(ldaptest.NewServer())
+	defer server.Close()
+
+	t.Run("with a search", func(t *testing.T) {
+		cfg := newTestConfig(server)
+		cfg.BindPassword = testBindSecret
+		client := NewClient(cfg)
+		defer client.Close()
+
+		entry, err := client.Authenticate("alice", "", "alice password")
+		require.NoError(t, err)
+		require.Equal(t, &Entry{
+			DN:       "uid=alice," + testBaseDN,
+			ID:       "uid=alice," + testBaseDN,
+			Username: "alice",
+			Email:    "alice@example.com",
+			Nickname: "Alice Smith",
+		}, entry)
+
+		entry, err = client.Authenticate("", "alice@example.com", "alice password")
+		require.NoError(t, err)
+		require.Equal(t, "alice", entry.Username)
+
+		testCases := []struct {
+			name     string
+			username string
+			password string
+		}{
+			{"a wrong password", "alice", "bob password"},
+			{"an empty password", "alice", ""},
+			{"an unknown user", "carol", "alice password"},
+			{"a user out of the filter", "bob", "bob password"},
+			{"an injected filter", "*", "alice password"},
+		}
+		for _, tc := range testCases {
+			t.Run("should reject "+tc.name, func(t *testing.T) {
+				_, err := client.Authenticate(tc.username, "", tc.password)
+				require.ErrorIs(t, err, ErrInvalidCredentials)
+			})
+		}
+	})
+
+	t.Run("should fail with a wrong search password", func(t *testing.T) {
+		cfg := newTestConfig(server)
+		cfg.BindPassword = "wrong secret"
+		client := NewClient(cfg)
+		defer client.Close()
+
+		_, err := client.Authenticate("alice", "", "alice password")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to bind as the search user")
+	})
+
+	t.Run("with a DN template", func(t *testing.T) {
+		cfg := newTestConfig(server)
+		cfg.BindDN = ""
+		cfg.BaseDN = ""
+		cfg.UserDNTemplate = "uid={username}," + testBaseDN
+		cfg.IDAttribute = "employeeID"
+		cfg.UsernameAttribute = "uid"
+		cfg.NicknameAttribute = "displayName"
+		client := NewClient(cfg)
+		defer client.Close()
+
+		entry, err := client.Authenticate("alice", "", "alice password")
+		require.NoError(t, err)
+		require.Equal(t, "E-1", entry.ID)
+		require.Equal(t, "Alice", entry.Nickname)
+
+		_, err = client.Authenticate("alice", "", "wrong password")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+
+		_, err = client.Authenticate("alice,ou=admins", "", "alice password")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+
+		_, err = client.Authenticate("bob", "", "bob password")
+		require.ErrorIs(t, err, ErrInvalidCredentials, "bob doesn't match the filter")
+
+		_, err = client.Authenticate("", "alice@example.com", "alice password")
+		require.Error(t, err, "the emails can't be searched without a base DN")
+	})
+
+	t.Run("should reuse the connections", func(t *testing.T) {
+		cfg := newTestConfig(server)
+		cfg.BindPassword = testBindSecret
+		client := NewClient(cfg)
+		defer client.Close()
+
+		before := server.Connections()
+		for i := 0; i < 3; i++ {
+			_, err := client.Authenticate("alice", "", "alice password")
+			require.NoError(t, err)
+		}
+		_, err := client.Authenticate("alice", "", "wrong password")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+		require.Equal(t, before+1, server.Connections())
+
+		t.Run("and reconnect when they're closed", func(t *testing.T) {
+			server.CloseConnections()
+			_, err := client.Authenticate("alice", "", "alice password")
+			require.NoError(t, err)
+			require.Equal(t, before+2, server.Connections())
+		})
+	})
+}
+
+func TestAuthenticateUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	client := NewClient(config.LDAPConfig{Server: "127.0.0.1", Port: port, BaseDN: testBaseDN, Timeout: 1})
+	_, err = client.Authenticate("alice", "", "alice password")
+	require.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestAuthenticateTLS(t *testing.T) {
+	t.Run("with TLS", func(t *testing.T) {
+		server := newTestServer(ldaptest.NewTLSServer())
+		defer server.Close()
+
+		cfg := newTestConfig(server)
+		cfg.BindPassword = testBindSecret
+		cfg.ConnectionSecurity = ConnectionSecurityTLS
+
+		_, err := NewClient(cfg).Authenticate("alice", "", "alice password")
+		require.ErrorIs(t, err, ErrUnavailable, "the certificate isn't trusted")
+
+		cfg.SkipCertificateVerification = true
+		entry, err := NewClient(cfg).Authenticate("alice", "", "alice password")
+		require.NoError(t, err)
+		require.Equal(t, "alice", entry.Username)
+	})
+
+	t.Run("with StartTLS", func(t *testing.T) {
+		server := newTestServer(ldaptest.NewServer())
+		defer server.Close()
+
+		cfg := newTestConfig(server)
+		cfg.BindPassword = testBindSecret
+		cfg.ConnectionSecurity = ConnectionSecurityStartTLS
+
+		_, err := NewClient(cfg).Authenticate("alice", "", "alice password")
+		require.ErrorIs(t, err, ErrUnavailable, "the certificate isn't trusted")
+
+		cfg.SkipCertificateVerification = true
+		entry, err := NewClient(cfg).Authenticate("alice", "", "alice password")
+		require.NoError(t, err)
+		require.Equal(t, "alice", entry.Username)
+	})
+}
+
+func TestEscapeDN(t *testing.T) {
+	require.Equal(t, "alice", EscapeDN("alice"))
+	require.Equal(t, `alice\,ou\=admins`, EscapeDN("alice,ou=admins"))
+	require.Equal(t, `\#alice\ `, EscapeDN("#alice "))
+	require.Equal(t, `\ a\+b\\c\<\>\;\"`, EscapeDN(` a+b\c<>;"`))
+	require.Equal(t, `a\00`, EscapeDN("a\x00"))
+}
@@ -0,0 +1,412 @@
+// Package ldaptest runs a fake LDAP server for the tests, answering the
+// simple binds, the searches with equality, presence and boolean filters,
+// and the StartTLS requests.
+package ldaptest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+const (
+	applicationBindRequest       = 0
+	applicationBindResponse      = 1
+	applicationUnbindRequest     = 2
+	applicationSearchRequest     = 3
+	applicationSearchResultEntry = 4
+	applicationSearchResultDone  = 5
+	applicationExtendedRequest   = 23
+	applicationExtendedResponse  = 24
+
+	filterAnd      = 0
+	filterOr       = 1
+	filterNot      = 2
+	filterEquality = 3
+	filterPresent  = 7
+
+	scopeBaseObject = 0
+	scopeSingle     = 1
+
+	resultSuccess            = 0
+	resultProtocolError      = 2
+	resultNoSuchObject       = 32
+	resultInvalidCredentials = 49
+	resultUnwillingToPerform = 53
+
+	startTLSOID = "1.3.6.1.4.1.1466.20037"
+)
+
+type entry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// Server is a fake LDAP server listening on the loopback.
+type Server struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+
+	mu          sync.Mutex
+	entries     map[string]*entry
+	conns       map[net.Conn]struct{}
+	connections int
+
+	wg sync.WaitGroup
+}
+
+// NewServer starts a server, accepting plain connections that can be
+// upgraded with StartTLS.
+func NewServer() *Server {
+	return newServer(false)
+}
+
+// NewTLSServer starts a server accepting the TLS connections.
+func NewTLSServer() *Server {
+	return newServer(true)
+}
+
+func newServer(useTLS bool) *Server {
+	s := &Server{
+		tlsConfig: &tls.Config{Certificates: []tls.Certificate{newCertificate()}},
+		entries:   map[string]*entry{},
+		conns:     map[net.Conn]struct{}{},
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	if useTLS {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+	s.listener = listener
+
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Host returns the host of the server.
+func (s *Server) Host() string {
+	return s.listener.Addr().(*net.TCPAddr).IP.String()
+}
+
+// Port returns the port of the server.
+func (s *Server) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// AddEntry adds the entry of the DN, which can be bound with the
+// password unless it's empty.
+func (s *Server) AddEntry(dn, password string, attributes map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[normalizeDN(dn)] = &entry{dn: dn, password: password, attributes: attributes}
+}
+
+// Connections returns the number of connections the server accepted.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// CloseConnections closes the open connections, like a server dropping
+// its idle clients.
+func (s *Server) CloseConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+}
+
+// Close stops the server, and closes its connections.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.CloseConnections()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.connections++
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID, _ := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+
+		var responses []*ber.Packet
+		switch request.Tag {
+		case applicationBindRequest:
+			responses = []*ber.Packet{s.bind(messageID, request)}
+		case applicationSearchRequest:
+			responses = s.search(messageID, request)
+		case applicationExtendedRequest:
+			if len(request.Children) == 0 || request.Children[0].Data.String() != startTLSOID {
+				responses = []*ber.Packet{newResult(messageID, applicationExtendedResponse, resultProtocolError, "unsupported extended request")}
+				break
+			}
+			if err = write(conn, newResult(messageID, applicationExtendedResponse, resultSuccess, "")); err != nil {
+				return
+			}
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err = tlsConn.Handshake(); err != nil {
+				return
+			}
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.conns[tlsConn] = struct{}{}
+			s.mu.Unlock()
+			conn = tlsConn
+			continue
+		case applicationUnbindRequest:
+			return
+		default:
+			responses = []*ber.Packet{newResult(messageID, applicationExtendedResponse, resultProtocolError, "unsupported request")}
+		}
+
+		for _, response := range responses {
+			if err = write(conn, response); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) bind(messageID int64, request *ber.Packet) *ber.Packet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(request.Children) < 3 || request.Children[2].Tag != 0 {
+		return newResult(messageID, applicationBindResponse, resultUnwillingToPerform, "only the simple binds are supported")
+	}
+	dn, _ := request.Children[1].Value.(string)
+	password := request.Children[2].Data.String()
+
+	if dn == "" && password == "" {
+		return newResult(messageID, applicationBindResponse, resultSuccess, "")
+	}
+	e, ok := s.entries[normalizeDN(dn)]
+	if !ok || e.password == "" || e.password != password {
+		return newResult(messageID, applicationBindResponse, resultInvalidCredentials, "invalid credentials")
+	}
+	return newResult(messageID, applicationBindResponse, resultSuccess, "")
+}
+
+func (s *Server) search(messageID int64, request *ber.Packet) []*ber.Packet {
+	if len(request.Children) < 8 {
+		return []*ber.Packet{newResult(messageID, applicationSearchResultDone, resultProtocolError, "invalid search request")}
+	}
+	baseDN, _ := request.Children[0].Value.(string)
+	scope, _ := request.Children[1].Value.(int64)
+	filter := request.Children[6]
+	var attributes []string
+	for _, attribute := range request.Children[7].Children {
+		name, _ := attribute.Value.(string)
+		attributes = append(attributes, strings.ToLower(name))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	base := normalizeDN(baseDN)
+	if _, ok := s.entries[base]; !ok && scope == scopeBaseObject {
+		return []*ber.Packet{newResult(messageID, applicationSearchResultDone, resultNoSuchObject, "no such object")}
+	}
+
+	var responses []*ber.Packet
+	for dn, e := range s.entries {
+		if !inScope(dn, base, scope) || !matches(e, filter) {
+			continue
+		}
+		responses = append(responses, newEntry(messageID, e, attributes))
+	}
+	return append(responses, newResult(messageID, applicationSearchResultDone, resultSuccess, ""))
+}
+
+func inScope(dn, base string, scope int64) bool {
+	switch scope {
+	case scopeBaseObject:
+		return dn == base
+	case scopeSingle:
+		parts := strings.SplitN(dn, ",", 2)
+		return len(parts) == 2 && parts[1] == base
+	}
+	return dn == base || strings.HasSuffix(dn, ","+base)
+}
+
+func matches(e *entry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case filterAnd:
+		for _, child := range filter.Children {
+			if !matches(e, child) {
+				return false
+			}
+		}
+		return true
+	case filterOr:
+		for _, child := range filter.Children {
+			if matches(e, child) {
+				return true
+			}
+		}
+		return false
+	case filterNot:
+		return len(filter.Children) == 1 && !matches(e, filter.Children[0])
+	case filterEquality:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		name, _ := filter.Children[0].Value.(string)
+		value := filter.Children[1].Data.String()
+		for _, candidate := range e.attribute(name) {
+			if strings.EqualFold(candidate, value) {
+				return true
+			}
+		}
+		return false
+	case filterPresent:
+		return len(e.attribute(filter.Data.String())) > 0
+	}
+	return false
+}
+
+// attribute returns the values of the attribute, objectClass being
+// present in all the entries.
+func (e *entry) attribute(name string) []string {
+	for attribute, values := range e.attributes {
+		if strings.EqualFold(attribute, name) {
+			return values
+		}
+	}
+	if strings.EqualFold(name, "objectClass") {
+		return []string{"top"}
+	}
+	return nil
+}
+
+func newEntry(messageID int64, e *entry, attributes []string) *ber.Packet {
+	packet := newMessage(messageID)
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, applicationSearchResultEntry, nil, "Search Result Entry")
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "Object Name"))
+
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range e.attributes {
+		if len(attributes) > 0 && !contains(attributes, strings.ToLower(name)) {
+			continue
+		}
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		list.AppendChild(attribute)
+	}
+	response.AppendChild(list)
+
+	packet.AppendChild(response)
+	return packet
+}
+
+func newResult(messageID int64, tag ber.Tag, code int64, message string) *ber.Packet {
+	packet := newMessage(messageID)
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	packet.AppendChild(response)
+	return packet
+}
+
+func newMessage(messageID int64) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	return packet
+}
+
+func write(conn net.Conn, packet *ber.Packet) error {
+	_, err := conn.Write(packet.Bytes())
+	return err
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDN(dn string) string {
+	parts := strings.Split(dn, ",")
+	for i, part := range parts {
+		parts[i] = strings.ToLower(strings.TrimSpace(part))
+	}
+	return strings.Join(parts, ",")
+}
+
+// newCertificate returns a self-signed certificate of the loopback.
+func newCertificate() tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ldaptest"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
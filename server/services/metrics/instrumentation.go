@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"time"
+)
+
+// Instrumentation is used by the API, the websocket server and the store
+// to report their operations. It's implemented by Metrics, and by
+// NoopInstrumentation for the builds that don't export their metrics,
+// like the plugin.
+type Instrumentation interface {
+	ObserveAPIRequest(route, method string, statusCode int, elapsed time.Duration)
+	IncrementWebSocketConnections()
+	DecrementWebSocketConnections()
+	ObserveQueryDuration(operation string, elapsed time.Duration)
+}
+
+// NoopInstrumentation is an Instrumentation that ignores the operations.
+type NoopInstrumentation struct{}
+
+func (NoopInstrumentation) ObserveAPIRequest(string, string, int, time.Duration) {}
+func (NoopInstrumentation) IncrementWebSocketConnections()                       {}
+func (NoopInstrumentation) DecrementWebSocketConnections()                       {}
+func (NoopInstrumentation) ObserveQueryDuration(string, time.Duration)           {}
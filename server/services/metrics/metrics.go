@@ -2,6 +2,8 @@ package metrics
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
@@ -12,6 +14,9 @@ const (
 	MetricsSubsystemBlocks     = "blocks"
 	MetricsSubsystemWorkspaces = "workspaces"
 	MetricsSubsystemSystem     = "system"
+	MetricsSubsystemAPI        = "api"
+	MetricsSubsystemWebSocket  = "websocket"
+	MetricsSubsystemDB         = "db"
 
 	MetricsCloudInstallationLabel = "installationId"
 )
@@ -30,8 +35,9 @@ type Metrics struct {
 	instance  *prometheus.GaugeVec
 	startTime prometheus.Gauge
 
-	loginCount     prometheus.Counter
-	loginFailCount prometheus.Counter
+	loginAttemptCount prometheus.Counter
+	loginCount        prometheus.Counter
+	loginFailCount    prometheus.Counter
 
 	blocksInsertedCount prometheus.Counter
 	blocksPatchedCount  prometheus.Counter
@@ -41,6 +47,17 @@ type Metrics struct {
 	workspaceCount prometheus.Gauge
 
 	blockLastActivity prometheus.Gauge
+
+	apiRequestCount    *prometheus.CounterVec
+	apiRequestDuration *prometheus.HistogramVec
+
+	webSocketConnections prometheus.Gauge
+
+	dbQueryDuration *prometheus.SummaryVec
+
+	blockCacheHitCount  prometheus.Counter
+	blockCacheMissCount prometheus.Counter
+	blockCacheBytes     prometheus.Gauge
 }
 
 // NewMetrics Factory method to create a new metrics collector.
@@ -59,6 +76,15 @@ func NewMetrics(info InstanceInfo) *Metrics {
 		additionalLabels[MetricsCloudInstallationLabel] = os.Getenv("MM_CLOUD_INSTALLATION_ID")
 	}
 
+	m.loginAttemptCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemSystem,
+		Name:        "login_attempts_total",
+		Help:        "Total number of login attempts.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.loginAttemptCount)
+
 	m.loginCount = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace:   MetricsNamespace,
 		Subsystem:   MetricsSubsystemSystem,
@@ -151,9 +177,80 @@ func NewMetrics(info InstanceInfo) *Metrics {
 	})
 	m.registry.MustRegister(m.blockLastActivity)
 
+	m.apiRequestCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemAPI,
+		Name:        "requests_total",
+		Help:        "Total number of API requests.",
+		ConstLabels: additionalLabels,
+	}, []string{"route", "method", "status"})
+	m.registry.MustRegister(m.apiRequestCount)
+
+	m.apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemAPI,
+		Name:        "request_duration_seconds",
+		Help:        "Duration of the API requests.",
+		Buckets:     prometheus.DefBuckets,
+		ConstLabels: additionalLabels,
+	}, []string{"route", "method"})
+	m.registry.MustRegister(m.apiRequestDuration)
+
+	m.webSocketConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemWebSocket,
+		Name:        "connections",
+		Help:        "Number of open websocket connections.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.webSocketConnections)
+
+	m.dbQueryDuration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemDB,
+		Name:        "query_duration_seconds",
+		Help:        "Duration of the database queries.",
+		Objectives:  map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		ConstLabels: additionalLabels,
+	}, []string{"operation"})
+	m.registry.MustRegister(m.dbQueryDuration)
+
+	m.blockCacheHitCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemBlocks,
+		Name:        "cache_hits_total",
+		Help:        "Total number of subtrees read from the block cache.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.blockCacheHitCount)
+
+	m.blockCacheMissCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemBlocks,
+		Name:        "cache_misses_total",
+		Help:        "Total number of subtrees missing from the block cache.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.blockCacheMissCount)
+
+	m.blockCacheBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemBlocks,
+		Name:        "cache_bytes",
+		Help:        "Size of the subtrees held by the block cache.",
+		ConstLabels: additionalLabels,
+	})
+	m.registry.MustRegister(m.blockCacheBytes)
+
 	return m
 }
 
+func (m *Metrics) IncrementLoginAttemptCount(num int) {
+	if m != nil {
+		m.loginAttemptCount.Add(float64(num))
+	}
+}
+
 func (m *Metrics) IncrementLoginCount(num int) {
 	if m != nil {
 		m.loginCount.Add(float64(num))
@@ -198,3 +295,46 @@ func (m *Metrics) ObserveWorkspaceCount(count int64) {
 		m.workspaceCount.Set(float64(count))
 	}
 }
+
+func (m *Metrics) ObserveAPIRequest(route, method string, statusCode int, elapsed time.Duration) {
+	if m != nil {
+		m.apiRequestCount.WithLabelValues(route, method, strconv.Itoa(statusCode)).Inc()
+		m.apiRequestDuration.WithLabelValues(route, method).Observe(elapsed.Seconds())
+	}
+}
+
+func (m *Metrics) IncrementWebSocketConnections() {
+	if m != nil {
+		m.webSocketConnections.Inc()
+	}
+}
+
+func (m *Metrics) DecrementWebSocketConnections() {
+	if m != nil {
+		m.webSocketConnections.Dec()
+	}
+}
+
+func (m *Metrics) ObserveQueryDuration(operation string, elapsed time.Duration) {
+	if m != nil {
+		m.dbQueryDuration.WithLabelValues(operation).Observe(elapsed.Seconds())
+	}
+}
+
+func (m *Metrics) IncrementBlockCacheHits() {
+	if m != nil {
+		m.blockCacheHitCount.Inc()
+	}
+}
+
+func (m *Metrics) IncrementBlockCacheMisses() {
+	if m != nil {
+		m.blockCacheMissCount.Inc()
+	}
+}
+
+func (m *Metrics) ObserveBlockCacheBytes(size int64) {
+	if m != nil {
+		m.blockCacheBytes.Set(float64(size))
+	}
+}
@@ -1,7 +1,9 @@
 package metrics
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -26,6 +28,29 @@ func NewMetricsServer(address string, metricsService *Metrics, logger *mlog.Logg
 	}
 }
 
+// NewHandler returns a handler exporting the metrics. When token isn't
+// empty, the requests must send it as a bearer token.
+func NewHandler(metricsService *Metrics, token string, logger *mlog.Logger) http.Handler {
+	handler := promhttp.HandlerFor(metricsService.registry, promhttp.HandlerOpts{
+		ErrorLog: logger.StdLogger(mlog.LvlError),
+	})
+	if token == "" {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if !strings.HasPrefix(strings.ToLower(authHeader), "bearer ") ||
+			subtle.ConstantTimeCompare([]byte(authHeader[7:]), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
 // Run will start the prometheus server.
 func (h *Service) Run() error {
 	return errors.Wrap(h.Server.ListenAndServe(), "prometheus ListenAndServe")
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// Mention is a user mentioned in a card or a comment.
+type Mention struct {
+	model.Notification
+
+	// Username of the author of the mention
+	AuthorUsername string
+
+	// Text of the block containing the mention
+	Message string
+
+	// Link to the card containing the mention
+	Permalink string
+}
+
+// DueDateReminder is a reminder of a card assigned to a user that is
+// about to be due.
+type DueDateReminder struct {
+	model.Notification
+
+	// Title of the card
+	CardTitle string
+
+	// Due date of the card, in milliseconds
+	DueAt int64
+
+	// Link to the card
+	Permalink string
+}
+
+// BlockChange is a change of a card or a board that a user watches.
+type BlockChange struct {
+	model.Notification
+
+	// Username of the author of the change
+	AuthorUsername string
+
+	// Title of the watched card or board
+	Title string
+
+	// Link to the card, or to the board
+	Permalink string
+}
+
+// WorkspaceDeletion is a notice of the deletion of an inactive workspace
+// sent to its members.
+type WorkspaceDeletion struct {
+	model.Notification
+
+	// Time the blocks of the workspace were last updated, in milliseconds
+	LastActivityAt int64
+
+	// Time from which the workspace is deleted, in milliseconds
+	DeleteAt int64
+}
+
+// Notifier delivers the notifications to the users, e.g. as direct
+// messages when running as a Mattermost plugin.
+type Notifier interface {
+	NotifyMention(mention Mention) error
+	NotifyDueDate(reminder DueDateReminder) error
+	NotifyBlockChange(change BlockChange) error
+	NotifyWorkspaceDeletion(deletion WorkspaceDeletion) error
+}
@@ -0,0 +1,173 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/oidc/oidctest"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirectURL = "http://boards.example.com/api/v1/oauth/callback"
+
+func newTestClient(provider *oidctest.Provider) *Client {
+	return NewClient(Config{
+		IssuerURL:    provider.URL(),
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		RedirectURL:  testRedirectURL,
+	})
+}
+
+func TestLogin(t *testing.T) {
+	provider := oidctest.NewProvider("focalboard", "client secret")
+	defer provider.Close()
+	provider.SetUser(map[string]interface{}{"sub": "user-1", "email": "alice@example.com", "email_verified": "true"})
+	client := newTestClient(provider)
+
+	codeVerifier, err := NewRandomValue()
+	require.NoError(t, err)
+	authURL, err := client.AuthCodeURL(context.Background(), "the state", "the nonce", codeVerifier)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(authURL, provider.URL()+"/authorize?"))
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, "openid profile email", parsed.Query().Get("scope"))
+	require.Equal(t, testRedirectURL, parsed.Query().Get("redirect_uri"))
+	require.Equal(t, CodeChallenge(codeVerifier), parsed.Query().Get("code_challenge"))
+
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	response, err := noRedirect.Get(authURL)
+	require.NoError(t, err)
+	response.Body.Close()
+	callback, err := url.Parse(response.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "the state", callback.Query().Get("state"))
+	code := callback.Query().Get("code")
+
+	t.Run("should reject the code with another verifier", func(t *testing.T) {
+		_, err := client.Exchange(context.Background(), code, "another verifier", "the nonce")
+		require.Error(t, err)
+	})
+
+	response, err = noRedirect.Get(authURL)
+	require.NoError(t, err)
+	response.Body.Close()
+	callback, err = url.Parse(response.Header.Get("Location"))
+	require.NoError(t, err)
+	code = callback.Query().Get("code")
+
+	claims, err := client.Exchange(context.Background(), code, codeVerifier, "the nonce")
+	require.NoError(t, err)
+	require.Equal(t, "user-1", claims.Subject)
+	require.Equal(t, "alice@example.com", claims.Email)
+	require.True(t, bool(claims.EmailVerified))
+
+	t.Run("should use the code once", func(t *testing.T) {
+		_, err := client.Exchange(context.Background(), code, codeVerifier, "the nonce")
+		require.Error(t, err)
+	})
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider := oidctest.NewProvider("focalboard", "client secret")
+	defer provider.Close()
+	client := newTestClient(provider)
+	ctx := context.Background()
+
+	claims, err := client.VerifyIDToken(ctx, provider.SignToken(map[string]interface{}{
+		"sub":   "user-1",
+		"nonce": "the nonce",
+		"aud":   []string{"focalboard", "another"},
+		"azp":   "focalboard",
+	}), "the nonce")
+	require.NoError(t, err)
+	require.Equal(t, "user-1", claims.Subject)
+	require.False(t, bool(claims.EmailVerified))
+
+	testCases := []struct {
+		name   string
+		claims map[string]interface{}
+		nonce  string
+	}{
+		{"another issuer", map[string]interface{}{"iss": "https://evil.example.com"}, "the nonce"},
+		{"another audience", map[string]interface{}{"aud": "another"}, "the nonce"},
+		{"another authorized party", map[string]interface{}{"aud": []string{"focalboard", "another"}, "azp": "another"}, "the nonce"},
+		{"expired", map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}, "the nonce"},
+		{"another nonce", map[string]interface{}{}, "another nonce"},
+		{"no nonce", map[string]interface{}{"nonce": nil}, ""},
+		{"no subject", map[string]interface{}{"sub": nil}, "the nonce"},
+	}
+	for _, tc := range testCases {
+		t.Run("should reject a token of "+tc.name, func(t *testing.T) {
+			tokenClaims := map[string]interface{}{"sub": "user-1", "nonce": "the nonce"}
+			for name, value := range tc.claims {
+				tokenClaims[name] = value
+			}
+			_, err := client.VerifyIDToken(ctx, provider.SignToken(tokenClaims), tc.nonce)
+			require.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+
+	valid := map[string]interface{}{
+		"iss":   provider.URL(),
+		"aud":   "focalboard",
+		"sub":   "user-1",
+		"nonce": "the nonce",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+
+	t.Run("should reject an unsigned token", func(t *testing.T) {
+		token := provider.SignRaw(map[string]interface{}{"alg": "none", "kid": "test-key"}, valid)
+		parts := strings.Split(token, ".")
+		_, err := client.VerifyIDToken(ctx, parts[0]+"."+parts[1]+".", "the nonce")
+		require.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("should reject a token of an unsupported algorithm", func(t *testing.T) {
+		token := provider.SignRaw(map[string]interface{}{"alg": "HS256", "kid": "test-key"}, valid)
+		_, err := client.VerifyIDToken(ctx, token, "the nonce")
+		require.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("should reject a token of an unknown key", func(t *testing.T) {
+		token := provider.SignRaw(map[string]interface{}{"alg": "RS256", "kid": "another-key"}, valid)
+		_, err := client.VerifyIDToken(ctx, token, "the nonce")
+		require.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("should reject a tampered token", func(t *testing.T) {
+		parts := strings.Split(provider.SignToken(map[string]interface{}{"sub": "user-1", "nonce": "the nonce"}), ".")
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin","nonce":"the nonce"}`))
+		_, err := client.VerifyIDToken(ctx, strings.Join(parts, "."), "the nonce")
+		require.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("should reject a token signed by another provider", func(t *testing.T) {
+		another := oidctest.NewProvider("focalboard", "client secret")
+		defer another.Close()
+		token := another.SignRaw(map[string]interface{}{"alg": "RS256", "kid": "test-key"}, valid)
+		_, err := client.VerifyIDToken(ctx, token, "the nonce")
+		require.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+}
+
+func TestDiscovery(t *testing.T) {
+	provider := oidctest.NewProvider("focalboard", "client secret")
+	defer provider.Close()
+
+	client := NewClient(Config{IssuerURL: provider.URL() + "/another", ClientID: "focalboard"})
+	_, err := client.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	require.Error(t, err)
+
+	client = NewClient(Config{IssuerURL: provider.URL() + "/", ClientID: "focalboard", Scopes: []string{"openid"}})
+	authURL, err := client.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	require.NoError(t, err)
+	require.Contains(t, authURL, "scope=openid&")
+}
@@ -0,0 +1,338 @@
+// Package quickadd parses a line of text, like "Fix login bug #urgent
+// @sara due friday", into the title and the property values of a card.
+package quickadd
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The types of the card properties the tokens are matched with
+const (
+	PropertyTypeSelect      = "select"
+	PropertyTypeMultiSelect = "multiSelect"
+	PropertyTypePerson      = "person"
+	PropertyTypeDate        = "date"
+)
+
+// trailingPunctuation is trimmed from the tokens and from the due date,
+// so that "#urgent," still matches the urgent option.
+const trailingPunctuation = ".,;:!?"
+
+// Option is an option of a select or multi-select property.
+type Option struct {
+	ID    string
+	Value string
+}
+
+// Property is a card property of the board the tokens are matched with.
+type Property struct {
+	ID      string
+	Name    string
+	Type    string
+	Options []Option
+}
+
+// UserResolver returns the ID of the user with the username, and false
+// if there is none.
+type UserResolver func(username string) (string, bool)
+
+// Card is a card parsed from a line of text.
+type Card struct {
+	// Title is the text without the matched tokens
+	Title string
+
+	// Properties are the values of the matched properties by ID, in the
+	// format of the card blocks: the option ID of a select property, the
+	// option IDs of a multi-select property, the user ID of a person
+	// property and the JSON encoded range of a date property
+	Properties map[string]interface{}
+
+	// DueDate is the start of the day the card is due, zero if the text
+	// has no due date, or the board no date property
+	DueDate time.Time
+}
+
+// Parser parses the lines of text into cards of a board.
+type Parser struct {
+	properties  []Property
+	resolveUser UserResolver
+}
+
+// New returns a parser matching the tokens with the card properties of
+// a board, in their order. The users are resolved with resolveUser, and
+// no user is matched if it's nil.
+func New(properties []Property, resolveUser UserResolver) *Parser {
+	return &Parser{properties: properties, resolveUser: resolveUser}
+}
+
+// Parse parses the text into a card:
+//   - a #token sets the first select property with a matching option,
+//     or adds the option to the first such multi-select property
+//   - an @token sets the first unset person property to the user
+//   - a trailing "due <date>" sets the due date property, the date being
+//     relative to now and in its location
+//   - the other words are the title
+//
+// The options are matched ignoring the case, the spaces, the dashes and
+// the underscores, so that #in-progress matches "In Progress". The
+// tokens that match nothing stay in the title.
+func (p *Parser) Parse(text string, now time.Time) Card {
+	card := Card{Properties: map[string]interface{}{}}
+	words := strings.Fields(text)
+
+	if dateProperty := p.dueDateProperty(); dateProperty != nil {
+		for i := len(words) - 2; i >= 0; i-- {
+			if !strings.EqualFold(words[i], "due") {
+				continue
+			}
+			if date, ok := parseDate(words[i+1:], now); ok {
+				card.DueDate = date
+				card.Properties[dateProperty.ID] = dateValue(date)
+				words = words[:i]
+			}
+			break
+		}
+	}
+
+	title := []string{}
+	for _, word := range words {
+		var matched bool
+		switch {
+		case strings.HasPrefix(word, "#"):
+			matched = p.matchOption(card.Properties, strings.TrimRight(word[1:], trailingPunctuation))
+		case strings.HasPrefix(word, "@"):
+			matched = p.matchPerson(card.Properties, strings.TrimRight(word[1:], trailingPunctuation))
+		}
+		if !matched {
+			title = append(title, word)
+		}
+	}
+	card.Title = strings.Join(title, " ")
+
+	return card
+}
+
+// dueDateProperty returns the date property named "Due" or "Due date",
+// or else the first date property.
+func (p *Parser) dueDateProperty() *Property {
+	var first *Property
+	for i := range p.properties {
+		property := &p.properties[i]
+		if property.Type != PropertyTypeDate {
+			continue
+		}
+		switch normalize(property.Name) {
+		case "due", "duedate":
+			return property
+		}
+		if first == nil {
+			first = property
+		}
+	}
+	return first
+}
+
+func (p *Parser) matchOption(values map[string]interface{}, name string) bool {
+	name = normalize(name)
+	if name == "" {
+		return false
+	}
+
+	for _, property := range p.properties {
+		if property.Type != PropertyTypeSelect && property.Type != PropertyTypeMultiSelect {
+			continue
+		}
+		if _, ok := values[property.ID]; ok && property.Type == PropertyTypeSelect {
+			continue
+		}
+
+		for _, option := range property.Options {
+			if normalize(option.Value) != name {
+				continue
+			}
+			if property.Type == PropertyTypeSelect {
+				values[property.ID] = option.ID
+				return true
+			}
+
+			optionIDs, _ := values[property.ID].([]interface{})
+			for _, id := range optionIDs {
+				if id == option.ID {
+					return true
+				}
+			}
+			values[property.ID] = append(optionIDs, option.ID)
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) matchPerson(values map[string]interface{}, username string) bool {
+	if username == "" || p.resolveUser == nil {
+		return false
+	}
+
+	for _, property := range p.properties {
+		if property.Type != PropertyTypePerson {
+			continue
+		}
+		if _, ok := values[property.ID]; ok {
+			continue
+		}
+
+		userID, ok := p.resolveUser(username)
+		if !ok {
+			return false
+		}
+		values[property.ID] = userID
+		return true
+	}
+	return false
+}
+
+// normalize returns the name in lower case without its spaces, dashes
+// and underscores.
+func normalize(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// dateValue returns the value of a date property starting on the day.
+func dateValue(date time.Time) string {
+	value, _ := json.Marshal(map[string]int64{"from": date.UnixNano() / int64(time.Millisecond)})
+	return string(value)
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+// parseDate parses the words as a day relative to now, and returns the
+// start of the day in the location of now. The supported dates are
+// "today", "tomorrow", a weekday, "next <weekday>", "next week",
+// "in <n> days" or "in <n> weeks", an ISO date like 2022-03-04, and a
+// month and a day like "march 4", "mar 4th" or "4 march", optionally
+// followed by a year. A weekday or a date without a year is the next
+// one, today included.
+func parseDate(words []string, now time.Time) (time.Time, bool) {
+	normalized := make([]string, len(words))
+	for i, word := range words {
+		normalized[i] = strings.ToLower(strings.TrimRight(word, trailingPunctuation))
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch len(normalized) {
+	case 1:
+		switch word := normalized[0]; word {
+		case "today":
+			return today, true
+		case "tomorrow":
+			return today.AddDate(0, 0, 1), true
+		default:
+			if weekday, ok := weekdays[word]; ok {
+				return nextWeekday(today, weekday), true
+			}
+			if date, err := time.ParseInLocation("2006-01-02", word, now.Location()); err == nil {
+				return date, true
+			}
+		}
+	case 2:
+		if normalized[0] == "next" {
+			if normalized[1] == "week" {
+				return nextWeekday(today.AddDate(0, 0, 1), time.Monday), true
+			}
+			if weekday, ok := weekdays[normalized[1]]; ok {
+				return nextWeekday(today, weekday).AddDate(0, 0, 7), true
+			}
+			return time.Time{}, false
+		}
+	case 3:
+		if normalized[0] == "in" {
+			n, err := strconv.Atoi(normalized[1])
+			if err != nil || n < 0 {
+				return time.Time{}, false
+			}
+			switch normalized[2] {
+			case "day", "days":
+				return today.AddDate(0, 0, n), true
+			case "week", "weeks":
+				return today.AddDate(0, 0, 7*n), true
+			}
+			return time.Time{}, false
+		}
+	}
+
+	return parseMonthDay(normalized, today)
+}
+
+// nextWeekday returns the next day of the week from the day, included.
+func nextWeekday(day time.Time, weekday time.Weekday) time.Time {
+	return day.AddDate(0, 0, (int(weekday)-int(day.Weekday())+7)%7)
+}
+
+// parseMonthDay parses a month and a day in either order, optionally
+// followed by a year.
+func parseMonthDay(words []string, today time.Time) (time.Time, bool) {
+	if len(words) != 2 && len(words) != 3 {
+		return time.Time{}, false
+	}
+
+	month, ok := months[words[0]]
+	dayWord := words[1]
+	if !ok {
+		month, ok = months[words[1]]
+		dayWord = words[0]
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+	day, err := strconv.Atoi(strings.TrimRight(dayWord, "stndrh"))
+	if err != nil || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+
+	year := today.Year()
+	if len(words) == 3 {
+		year, err = strconv.Atoi(words[2])
+		if err != nil || year < 1000 || year > 9999 {
+			return time.Time{}, false
+		}
+	}
+
+	date := time.Date(year, month, day, 0, 0, 0, 0, today.Location())
+	if date.Day() != day {
+		// the month has no such day
+		return time.Time{}, false
+	}
+	if len(words) == 2 && date.Before(today) {
+		date = time.Date(year+1, month, day, 0, 0, 0, 0, today.Location())
+		if date.Day() != day {
+			return time.Time{}, false
+		}
+	}
+	return date, true
+}
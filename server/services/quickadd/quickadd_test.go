@@ -0,0 +1,362 @@
+package quickadd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testNow is a Wednesday.
+var testNow = time.Date(2022, time.March, 2, 15, 30, 0, 0, time.UTC)
+
+func testDay(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+var testProperties = []Property{
+	{ID: "priority", Name: "Priority", Type: PropertyTypeSelect, Options: []Option{
+		{ID: "urgent", Value: "Urgent"},
+		{ID: "low", Value: "Low"},
+	}},
+	{ID: "status", Name: "Status", Type: PropertyTypeSelect, Options: []Option{
+		{ID: "in-progress", Value: "In Progress"},
+		{ID: "status-urgent", Value: "urgent"},
+	}},
+	{ID: "labels", Name: "Labels", Type: PropertyTypeMultiSelect, Options: []Option{
+		{ID: "bug", Value: "Bug"},
+		{ID: "ui", Value: "UI"},
+		{ID: "low-label", Value: "low"},
+	}},
+	{ID: "owner", Name: "Owner", Type: PropertyTypePerson},
+	{ID: "reviewer", Name: "Reviewer", Type: PropertyTypePerson},
+	{ID: "created", Name: "Created", Type: PropertyTypeDate},
+	{ID: "due", Name: "Due date", Type: PropertyTypeDate},
+}
+
+func testResolveUser(username string) (string, bool) {
+	switch username {
+	case "sara", "bob":
+		return username + "-id", true
+	}
+	return "", false
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name       string
+		text       string
+		title      string
+		properties map[string]interface{}
+		due        time.Time
+	}{
+		{
+			name:       "plain title",
+			text:       "Fix login bug",
+			title:      "Fix login bug",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:  "every token",
+			text:  "Fix login bug #urgent @sara due friday",
+			title: "Fix login bug",
+			properties: map[string]interface{}{
+				"priority": "urgent",
+				"owner":    "sara-id",
+				"due":      `{"from":1646352000000}`,
+			},
+			due: testDay(2022, time.March, 4),
+		},
+		{
+			name:       "tokens anywhere",
+			text:       "#bug Fix @bob the #ui login",
+			title:      "Fix the login",
+			properties: map[string]interface{}{"labels": []interface{}{"bug", "ui"}, "owner": "bob-id"},
+		},
+		{
+			name:       "extra spaces",
+			text:       "  Fix   login  ",
+			title:      "Fix login",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:       "empty text",
+			text:       "",
+			title:      "",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:       "only tokens",
+			text:       "#urgent @sara",
+			title:      "",
+			properties: map[string]interface{}{"priority": "urgent", "owner": "sara-id"},
+		},
+		{
+			name:       "option case, spaces and dashes",
+			text:       "Task #in-progress #URGENT",
+			title:      "Task",
+			properties: map[string]interface{}{"status": "in-progress", "priority": "urgent"},
+		},
+		{
+			name:       "option with underscores",
+			text:       "Task #in_progress",
+			title:      "Task",
+			properties: map[string]interface{}{"status": "in-progress"},
+		},
+		{
+			name:       "trailing punctuation",
+			text:       "Task #urgent, @sara.",
+			title:      "Task",
+			properties: map[string]interface{}{"priority": "urgent", "owner": "sara-id"},
+		},
+		{
+			name:       "second option of a select goes to the next select",
+			text:       "Task #urgent #urgent",
+			title:      "Task",
+			properties: map[string]interface{}{"priority": "urgent", "status": "status-urgent"},
+		},
+		{
+			name:       "select already set",
+			text:       "Task #urgent #low",
+			title:      "Task",
+			properties: map[string]interface{}{"priority": "urgent", "labels": []interface{}{"low-label"}},
+		},
+		{
+			name:       "third option of a select stays in the title",
+			text:       "Task #urgent #urgent #urgent",
+			title:      "Task #urgent",
+			properties: map[string]interface{}{"priority": "urgent", "status": "status-urgent"},
+		},
+		{
+			name:       "repeated multi-select option",
+			text:       "Task #bug #bug",
+			title:      "Task",
+			properties: map[string]interface{}{"labels": []interface{}{"bug"}},
+		},
+		{
+			name:       "unknown option",
+			text:       "Task #unknown #",
+			title:      "Task #unknown #",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:       "hash inside a word",
+			text:       "Fix issue#12",
+			title:      "Fix issue#12",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:       "users fill the person properties in order",
+			text:       "Review @sara @bob",
+			title:      "Review",
+			properties: map[string]interface{}{"owner": "sara-id", "reviewer": "bob-id"},
+		},
+		{
+			name:       "more users than person properties",
+			text:       "Review @sara @bob @sara",
+			title:      "Review @sara",
+			properties: map[string]interface{}{"owner": "sara-id", "reviewer": "bob-id"},
+		},
+		{
+			name:       "unknown user",
+			text:       "Ask @carol @",
+			title:      "Ask @carol @",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:       "email address",
+			text:       "Mail sara@example.com",
+			title:      "Mail sara@example.com",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:       "due without a date",
+			text:       "Pay the dues due",
+			title:      "Pay the dues due",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:       "due not followed by a date",
+			text:       "Fix the due date picker",
+			title:      "Fix the due date picker",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:       "only the last due is the date",
+			text:       "Due tomorrow follow up due today",
+			title:      "Due tomorrow follow up",
+			properties: map[string]interface{}{"due": `{"from":1646179200000}`},
+			due:        testDay(2022, time.March, 2),
+		},
+		{
+			name:       "due date not trailing",
+			text:       "Send due friday report",
+			title:      "Send due friday report",
+			properties: map[string]interface{}{},
+		},
+		{
+			name:       "due date case and punctuation",
+			text:       "Ship DUE Tomorrow!",
+			title:      "Ship",
+			properties: map[string]interface{}{"due": `{"from":1646265600000}`},
+			due:        testDay(2022, time.March, 3),
+		},
+		{
+			name:       "tokens before the due date",
+			text:       "Ship #bug due 2022-04-01",
+			title:      "Ship",
+			properties: map[string]interface{}{"labels": []interface{}{"bug"}, "due": `{"from":1648771200000}`},
+			due:        testDay(2022, time.April, 1),
+		},
+	}
+
+	parser := New(testProperties, testResolveUser)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			card := parser.Parse(tc.text, testNow)
+			require.Equal(t, tc.title, card.Title)
+			require.Equal(t, tc.properties, card.Properties)
+			require.Equal(t, tc.due, card.DueDate)
+		})
+	}
+}
+
+func TestParseWithoutProperties(t *testing.T) {
+	testCases := []struct {
+		name       string
+		properties []Property
+		resolver   UserResolver
+		text       string
+		title      string
+		values     map[string]interface{}
+	}{
+		{
+			name:   "no date property",
+			text:   "Ship due friday",
+			title:  "Ship due friday",
+			values: map[string]interface{}{},
+		},
+		{
+			name:   "no person property",
+			text:   "Ask @sara",
+			title:  "Ask @sara",
+			values: map[string]interface{}{},
+		},
+		{
+			name:       "no user resolver",
+			properties: []Property{{ID: "owner", Type: PropertyTypePerson}},
+			text:       "Ask @sara",
+			title:      "Ask @sara",
+			values:     map[string]interface{}{},
+		},
+		{
+			name:       "first date property without a due date property",
+			properties: []Property{{ID: "text", Type: "text"}, {ID: "deadline", Name: "Deadline", Type: PropertyTypeDate}, {ID: "other", Type: PropertyTypeDate}},
+			text:       "Ship due today",
+			title:      "Ship",
+			values:     map[string]interface{}{"deadline": `{"from":1646179200000}`},
+		},
+		{
+			name:       "due property",
+			properties: []Property{{ID: "other", Type: PropertyTypeDate}, {ID: "due", Name: " due ", Type: PropertyTypeDate}},
+			text:       "Ship due today",
+			title:      "Ship",
+			values:     map[string]interface{}{"due": `{"from":1646179200000}`},
+		},
+		{
+			name:       "options of other property types",
+			properties: []Property{{ID: "text", Type: "text", Options: []Option{{ID: "urgent", Value: "urgent"}}}},
+			text:       "Task #urgent",
+			title:      "Task #urgent",
+			values:     map[string]interface{}{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := tc.resolver
+			if tc.properties == nil {
+				resolver = testResolveUser
+			}
+			card := New(tc.properties, resolver).Parse(tc.text, testNow)
+			require.Equal(t, tc.title, card.Title)
+			require.Equal(t, tc.values, card.Properties)
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected time.Time
+	}{
+		{"today", testDay(2022, time.March, 2)},
+		{"tomorrow", testDay(2022, time.March, 3)},
+		{"wednesday", testDay(2022, time.March, 2)},
+		{"thursday", testDay(2022, time.March, 3)},
+		{"fri", testDay(2022, time.March, 4)},
+		{"Monday", testDay(2022, time.March, 7)},
+		{"tue", testDay(2022, time.March, 8)},
+		{"next friday", testDay(2022, time.March, 11)},
+		{"next wednesday", testDay(2022, time.March, 9)},
+		{"next week", testDay(2022, time.March, 7)},
+		{"in 0 days", testDay(2022, time.March, 2)},
+		{"in 1 day", testDay(2022, time.March, 3)},
+		{"in 30 days", testDay(2022, time.April, 1)},
+		{"in 2 weeks", testDay(2022, time.March, 16)},
+		{"2022-12-25", testDay(2022, time.December, 25)},
+		{"2021-01-01", testDay(2021, time.January, 1)},
+		{"march 10", testDay(2022, time.March, 10)},
+		{"Mar 2", testDay(2022, time.March, 2)},
+		{"march 1", testDay(2023, time.March, 1)},
+		{"10 march", testDay(2022, time.March, 10)},
+		{"apr 1st", testDay(2022, time.April, 1)},
+		{"june 22nd", testDay(2022, time.June, 22)},
+		{"jul 3rd", testDay(2022, time.July, 3)},
+		{"Sept 4th", testDay(2022, time.September, 4)},
+		{"jan 5 2024", testDay(2024, time.January, 5)},
+		{"jan 5, 2020", testDay(2020, time.January, 5)},
+		{"feb 29 2024", testDay(2024, time.February, 29)},
+		{"friday.", testDay(2022, time.March, 4)},
+	}
+	for _, tc := range testCases {
+		date, ok := parseDate(strings.Fields(tc.text), testNow)
+		require.True(t, ok, tc.text)
+		require.Equal(t, tc.expected, date, tc.text)
+	}
+
+	t.Run("should keep the location of now", func(t *testing.T) {
+		location := time.FixedZone("UTC-5", -5*60*60)
+		date, ok := parseDate([]string{"tomorrow"}, time.Date(2022, time.March, 2, 22, 0, 0, 0, location))
+		require.True(t, ok)
+		require.Equal(t, time.Date(2022, time.March, 3, 0, 0, 0, 0, location), date)
+	})
+
+	t.Run("should fail with invalid dates", func(t *testing.T) {
+		for _, text := range []string{
+			"",
+			"someday",
+			"next",
+			"next month",
+			"next someday",
+			"in days",
+			"in -1 days",
+			"in 2 months",
+			"2022-13-01",
+			"2022-02-30",
+			"feb 30",
+			"feb 29 2023",
+			"march",
+			"march 0",
+			"march 32",
+			"march 4 22",
+			"4 march march",
+			"friday at noon",
+			"march 4 2022 10am",
+		} {
+			_, ok := parseDate(strings.Fields(text), testNow)
+			require.False(t, ok, text)
+		}
+	})
+}
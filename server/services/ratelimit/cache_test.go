@@ -0,0 +1,56 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(ttl time.Duration, maxEntries int) (*Cache, *testClock) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	cache := NewCache(ttl, maxEntries)
+	cache.now = clock.Now
+	return cache, clock
+}
+
+func TestCache(t *testing.T) {
+	t.Run("should return the values until they expire", func(t *testing.T) {
+		cache, clock := newTestCache(time.Minute, 0)
+		_, ok := cache.Get("key")
+		require.False(t, ok)
+
+		cache.Add("key", "value")
+		clock.Advance(59 * time.Second)
+		value, ok := cache.Get("key")
+		require.True(t, ok)
+		require.Equal(t, "value", value)
+
+		clock.Advance(time.Second)
+		_, ok = cache.Get("key")
+		require.False(t, ok)
+		require.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("should evict the least recently used entries", func(t *testing.T) {
+		cache, _ := newTestCache(time.Minute, 3)
+		for i := 0; i < 3; i++ {
+			cache.Add(fmt.Sprintf("key-%d", i), i)
+		}
+		_, ok := cache.Get("key-0")
+		require.True(t, ok)
+
+		cache.Add("key-3", 3)
+		require.Equal(t, 3, cache.Len())
+		_, ok = cache.Get("key-1")
+		require.False(t, ok)
+		for _, key := range []string{"key-0", "key-2", "key-3"} {
+			_, ok := cache.Get(key)
+			require.True(t, ok, key)
+		}
+	})
+}
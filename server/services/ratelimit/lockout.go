@@ -0,0 +1,169 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package ratelimit
+
+import (
+	"container/list"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// LockoutStore persists the failed logins, so that a restart doesn't reset
+// a lockout.
+type LockoutStore interface {
+	GetLoginAttempts(key string) (*model.LoginAttempts, error)
+	UpsertLoginAttempts(attempts model.LoginAttempts) error
+	DeleteLoginAttempts(key string) error
+}
+
+// Lockout locks out a key, like a username or a client address, after
+// threshold failed logins each within window of the previous one, or of
+// the end of the previous lockout. The lockout lasts baseLockout, and
+// doubles with every failure after the threshold up to maxLockout. The
+// failures are kept in memory for up to maxEntries keys, and written
+// through to the store.
+type Lockout struct {
+	threshold   int
+	window      time.Duration
+	baseLockout time.Duration
+	maxLockout  time.Duration
+	maxEntries  int
+	store       LockoutStore
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	// now returns the current time, and is replaced in the tests.
+	now func() time.Time
+}
+
+// NewLockout returns a lockout for the given threshold and durations,
+// storing the failures in store.
+func NewLockout(threshold int, window, baseLockout, maxLockout time.Duration, store LockoutStore) *Lockout {
+	if threshold < 1 {
+		threshold = 1
+	}
+	if maxLockout < baseLockout {
+		maxLockout = baseLockout
+	}
+
+	return &Lockout{
+		threshold:   threshold,
+		window:      window,
+		baseLockout: baseLockout,
+		maxLockout:  maxLockout,
+		maxEntries:  DefaultMaxBuckets,
+		store:       store,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+		now:         time.Now,
+	}
+}
+
+// LockedFor returns how long the key is still locked out, zero if it
+// isn't.
+func (l *Lockout) LockedFor(key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	attempts, err := l.getAttempts(key)
+	if err != nil {
+		return 0, err
+	}
+
+	lockedFor := time.Duration(attempts.LockedUntil-toMillis(l.now())) * time.Millisecond
+	if lockedFor < 0 {
+		return 0, nil
+	}
+	return lockedFor, nil
+}
+
+// Fail records a failed login for the key, and returns how long the key is
+// locked out for after it, zero if it isn't.
+func (l *Lockout) Fail(key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	attempts, err := l.getAttempts(key)
+	if err != nil {
+		return 0, err
+	}
+
+	// the failures are counted together while each of them is within the
+	// window of the previous one, or of the end of the lockout
+	now := toMillis(l.now())
+	last := attempts.LastFailureAt
+	if attempts.LockedUntil > last {
+		last = attempts.LockedUntil
+	}
+	if now-last > l.window.Milliseconds() {
+		attempts.Failures = 0
+	}
+	attempts.Failures++
+	attempts.LastFailureAt = now
+
+	var lockedFor time.Duration
+	if attempts.Failures >= l.threshold {
+		lockedFor = l.lockoutDuration(attempts.Failures - l.threshold)
+		attempts.LockedUntil = now + lockedFor.Milliseconds()
+	}
+
+	return lockedFor, l.store.UpsertLoginAttempts(*attempts)
+}
+
+// Clear forgets the failed logins of the key.
+func (l *Lockout) Clear(key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		l.lru.Remove(element)
+		delete(l.entries, key)
+	}
+	return l.store.DeleteLoginAttempts(key)
+}
+
+// lockoutDuration returns the lockout after the given number of failures
+// past the threshold.
+func (l *Lockout) lockoutDuration(extraFailures int) time.Duration {
+	lockout := l.baseLockout
+	for i := 0; i < extraFailures && lockout < l.maxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > l.maxLockout {
+		return l.maxLockout
+	}
+	return lockout
+}
+
+func (l *Lockout) getAttempts(key string) (*model.LoginAttempts, error) {
+	if element, ok := l.entries[key]; ok {
+		l.lru.MoveToFront(element)
+		return element.Value.(*model.LoginAttempts), nil
+	}
+
+	attempts, err := l.store.GetLoginAttempts(key)
+	if errors.Is(err, sql.ErrNoRows) {
+		attempts = &model.LoginAttempts{Key: key}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if l.lru.Len() >= l.maxEntries {
+		oldest := l.lru.Back()
+		l.lru.Remove(oldest)
+		delete(l.entries, oldest.Value.(*model.LoginAttempts).Key)
+	}
+	l.entries[key] = l.lru.PushFront(attempts)
+	return attempts, nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
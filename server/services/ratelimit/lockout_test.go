@@ -0,0 +1,119 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package ratelimit
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+type testLockoutStore struct {
+	attempts map[string]model.LoginAttempts
+}
+
+func (s *testLockoutStore) GetLoginAttempts(key string) (*model.LoginAttempts, error) {
+	attempts, ok := s.attempts[key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &attempts, nil
+}
+
+func (s *testLockoutStore) UpsertLoginAttempts(attempts model.LoginAttempts) error {
+	s.attempts[attempts.Key] = attempts
+	return nil
+}
+
+func (s *testLockoutStore) DeleteLoginAttempts(key string) error {
+	delete(s.attempts, key)
+	return nil
+}
+
+func newTestLockout(store *testLockoutStore, clock *testClock) *Lockout {
+	lockout := NewLockout(3, 5*time.Minute, time.Minute, 10*time.Minute, store)
+	lockout.now = clock.Now
+	return lockout
+}
+
+func requireLockedFor(t *testing.T, lockout *Lockout, key string, expected time.Duration) {
+	lockedFor, err := lockout.LockedFor(key)
+	require.NoError(t, err)
+	require.Equal(t, expected, lockedFor)
+}
+
+func TestLockout(t *testing.T) {
+	store := &testLockoutStore{attempts: map[string]model.LoginAttempts{}}
+	clock := &testClock{now: time.Unix(1000, 0)}
+	lockout := newTestLockout(store, clock)
+
+	t.Run("should lock out after the threshold", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			lockedFor, err := lockout.Fail("key")
+			require.NoError(t, err)
+			require.Zero(t, lockedFor)
+		}
+		requireLockedFor(t, lockout, "key", 0)
+
+		lockedFor, err := lockout.Fail("key")
+		require.NoError(t, err)
+		require.Equal(t, time.Minute, lockedFor)
+		requireLockedFor(t, lockout, "key", time.Minute)
+		requireLockedFor(t, lockout, "other", 0)
+
+		clock.Advance(20 * time.Second)
+		requireLockedFor(t, lockout, "key", 40*time.Second)
+	})
+
+	t.Run("should back off exponentially", func(t *testing.T) {
+		clock.Advance(time.Minute)
+		requireLockedFor(t, lockout, "key", 0)
+
+		lockedFor, err := lockout.Fail("key")
+		require.NoError(t, err)
+		require.Equal(t, 2*time.Minute, lockedFor)
+
+		clock.Advance(2 * time.Minute)
+		lockedFor, err = lockout.Fail("key")
+		require.NoError(t, err)
+		require.Equal(t, 4*time.Minute, lockedFor)
+
+		clock.Advance(4 * time.Minute)
+		lockedFor, err = lockout.Fail("key")
+		require.NoError(t, err)
+		require.Equal(t, 8*time.Minute, lockedFor)
+
+		// the lockout never lasts more than the maximum
+		clock.Advance(8 * time.Minute)
+		lockedFor, err = lockout.Fail("key")
+		require.NoError(t, err)
+		require.Equal(t, 10*time.Minute, lockedFor)
+	})
+
+	t.Run("should keep the lockout after a restart", func(t *testing.T) {
+		restarted := newTestLockout(store, clock)
+		requireLockedFor(t, restarted, "key", 10*time.Minute)
+	})
+
+	t.Run("should forget the failures after the window", func(t *testing.T) {
+		clock.Advance(10*time.Minute + 5*time.Minute + time.Second)
+		lockedFor, err := lockout.Fail("key")
+		require.NoError(t, err)
+		require.Zero(t, lockedFor)
+	})
+
+	t.Run("should clear the failures", func(t *testing.T) {
+		_, err := lockout.Fail("key")
+		require.NoError(t, err)
+		require.NoError(t, lockout.Clear("key"))
+		require.NotContains(t, store.attempts, "key")
+
+		lockedFor, err := lockout.Fail("key")
+		require.NoError(t, err)
+		require.Zero(t, lockedFor)
+	})
+}
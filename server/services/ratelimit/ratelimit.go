@@ -0,0 +1,109 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package ratelimit
+
+import (
+	"container/list"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBuckets is the number of buckets kept by a limiter when no
+// size is given.
+const DefaultMaxBuckets = 10000
+
+// Limiter is a token bucket rate limiter with a bucket per key. Each
+// bucket holds up to burst tokens and is refilled at rate tokens per
+// second. The buckets are kept in a LRU list, so that the memory used is
+// bounded by the number of buckets; a key whose bucket was evicted gets a
+// full bucket again.
+type Limiter struct {
+	rate       float64
+	burst      float64
+	maxBuckets int
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	lru     *list.List
+
+	// now returns the current time, and is replaced in the tests.
+	now func() time.Time
+}
+
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// New returns a limiter allowing rate requests per second per key, with
+// bursts of up to burst requests, and keeping at most maxBuckets buckets.
+func New(rate float64, burst int, maxBuckets int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	if maxBuckets <= 0 {
+		maxBuckets = DefaultMaxBuckets
+	}
+
+	return &Limiter{
+		rate:       rate,
+		burst:      float64(burst),
+		maxBuckets: maxBuckets,
+		buckets:    map[string]*list.Element{},
+		lru:        list.New(),
+		now:        time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of the key. When the bucket is
+// empty, the request isn't allowed and the time until the next token is
+// available is returned.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.getBucket(key, now)
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	if l.rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	retryAfter := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, retryAfter
+}
+
+// Len returns the number of buckets kept by the limiter.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lru.Len()
+}
+
+func (l *Limiter) getBucket(key string, now time.Time) *bucket {
+	if element, ok := l.buckets[key]; ok {
+		l.lru.MoveToFront(element)
+		return element.Value.(*bucket)
+	}
+
+	if l.lru.Len() >= l.maxBuckets {
+		oldest := l.lru.Back()
+		l.lru.Remove(oldest)
+		delete(l.buckets, oldest.Value.(*bucket).key)
+	}
+
+	b := &bucket{key: key, tokens: l.burst, last: now}
+	l.buckets[key] = l.lru.PushFront(b)
+	return b
+}
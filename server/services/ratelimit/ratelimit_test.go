@@ -0,0 +1,113 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestLimiter(rate float64, burst int, maxBuckets int) (*Limiter, *testClock) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	limiter := New(rate, burst, maxBuckets)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func TestLimiterBurst(t *testing.T) {
+	limiter, clock := newTestLimiter(2, 5, 0)
+
+	t.Run("should allow a burst up to the bucket size", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			allowed, _ := limiter.Allow("key")
+			require.True(t, allowed, "request %d", i)
+		}
+
+		allowed, retryAfter := limiter.Allow("key")
+		require.False(t, allowed)
+		require.Equal(t, 500*time.Millisecond, retryAfter)
+	})
+
+	t.Run("should refill the bucket at the rate", func(t *testing.T) {
+		clock.Advance(500 * time.Millisecond)
+		allowed, _ := limiter.Allow("key")
+		require.True(t, allowed)
+		allowed, _ = limiter.Allow("key")
+		require.False(t, allowed)
+
+		// the bucket never holds more than the burst
+		clock.Advance(time.Hour)
+		for i := 0; i < 5; i++ {
+			allowed, _ := limiter.Allow("key")
+			require.True(t, allowed, "request %d", i)
+		}
+		allowed, _ = limiter.Allow("key")
+		require.False(t, allowed)
+	})
+
+	t.Run("should limit the keys separately", func(t *testing.T) {
+		allowed, _ := limiter.Allow("key")
+		require.False(t, allowed)
+		allowed, _ = limiter.Allow("other")
+		require.True(t, allowed)
+	})
+}
+
+func TestLimiterSustainedRate(t *testing.T) {
+	limiter, clock := newTestLimiter(10, 10, 0)
+
+	// a client sending 20 requests per second for 10 seconds gets the
+	// burst, then the rate
+	allowedCount := 0
+	for i := 0; i < 200; i++ {
+		if allowed, _ := limiter.Allow("key"); allowed {
+			allowedCount++
+		}
+		clock.Advance(50 * time.Millisecond)
+	}
+	require.InDelta(t, 10+10*10, allowedCount, 1)
+}
+
+func TestLimiterMaxBuckets(t *testing.T) {
+	limiter, _ := newTestLimiter(1, 1, 3)
+
+	for i := 0; i < 10; i++ {
+		allowed, _ := limiter.Allow(fmt.Sprintf("key-%d", i))
+		require.True(t, allowed)
+	}
+	require.Equal(t, 3, limiter.Len())
+
+	t.Run("should keep the recently used buckets", func(t *testing.T) {
+		allowed, _ := limiter.Allow("key-9")
+		require.False(t, allowed)
+	})
+
+	t.Run("should evict the least recently used buckets", func(t *testing.T) {
+		allowed, _ := limiter.Allow("key-0")
+		require.True(t, allowed)
+		require.Equal(t, 3, limiter.Len())
+
+		// key-7 was the least recently used, so it was evicted and gets a
+		// full bucket again
+		allowed, _ = limiter.Allow("key-7")
+		require.True(t, allowed)
+		allowed, _ = limiter.Allow("key-9")
+		require.False(t, allowed)
+	})
+}
@@ -0,0 +1,333 @@
+// Package render renders boards as documents, independently of their
+// output format.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// The types of the content of the cards that are rendered.
+const (
+	ContentText     = "text"
+	ContentCheckbox = "checkbox"
+	ContentCode     = model.CodeBlockType
+)
+
+// Document is a board as a document, with its cards in sections.
+type Document struct {
+	Title       string
+	Icon        string
+	Description string
+	Sections    []Section
+}
+
+// Section is a group of cards, of the option of the property the view
+// groups the cards by. The title is empty if the cards aren't grouped.
+type Section struct {
+	Title string
+	Cards []Card
+}
+
+// Card is a card with the values of its key properties, formatted, and
+// its text, checkbox and code content.
+type Card struct {
+	Title      string
+	Icon       string
+	Properties []Property
+	Content    []Content
+}
+
+// Property is the formatted value of a property of a card.
+type Property struct {
+	Name  string
+	Value string
+}
+
+// Content is a text, checkbox or code block of a card.
+type Content struct {
+	Type     string
+	Text     string
+	Checked  bool
+	Language string
+}
+
+// Options are the options of the rendering of a board.
+type Options struct {
+	// Locale of the dates, e.g. "en" or "pt-BR"
+	Locale string
+
+	// Usernames of the users of the person properties, by ID
+	Usernames map[string]string
+}
+
+// property is a card property of the board.
+type property struct {
+	id           string
+	name         string
+	propertyType string
+	optionIDs    []string
+	options      map[string]string
+}
+
+// NewDocument returns the document of the board, whose cards are grouped
+// by the select property the view groups them by, keeping their order,
+// and show the visible properties of the view, or all of them if the
+// view is nil. The content blocks are the ones of the cards, in any
+// order. The templates are left out.
+func NewDocument(board model.Block, view *model.Block, cards, content []model.Block, opts Options) Document {
+	properties := boardProperties(board)
+	byID := map[string]property{}
+	for _, p := range properties {
+		byID[p.id] = p
+	}
+
+	var groupBy *property
+	keyProperties := properties
+	if view != nil {
+		if p, ok := byID[stringField(view.Fields, "groupById")]; ok && p.propertyType == "select" {
+			groupBy = &p
+		}
+		keyProperties = []property{}
+		visibleIDs, _ := view.Fields["visiblePropertyIds"].([]interface{})
+		for _, id := range visibleIDs {
+			if p, ok := byID[fmt.Sprint(id)]; ok {
+				keyProperties = append(keyProperties, p)
+			}
+		}
+	}
+
+	contentByID := map[string]model.Block{}
+	for _, block := range content {
+		contentByID[block.ID] = block
+	}
+
+	formatter := &formatter{opts: opts}
+	sections := map[string]*Section{}
+	for _, card := range cards {
+		if isTemplate, _ := card.Fields["isTemplate"].(bool); isTemplate {
+			continue
+		}
+
+		values, _ := card.Fields["properties"].(map[string]interface{})
+		rendered := Card{
+			Title:   card.Title,
+			Icon:    stringField(card.Fields, "icon"),
+			Content: cardContent(card, contentByID),
+		}
+		for _, p := range keyProperties {
+			if groupBy != nil && p.id == groupBy.id {
+				continue
+			}
+			if value := formatter.value(card, p, values[p.id]); value != "" {
+				rendered.Properties = append(rendered.Properties, Property{Name: p.name, Value: value})
+			}
+		}
+
+		group := ""
+		if groupBy != nil {
+			group, _ = values[groupBy.id].(string)
+			if _, ok := groupBy.options[group]; !ok {
+				group = ""
+			}
+		}
+		section, ok := sections[group]
+		if !ok {
+			section = &Section{}
+			sections[group] = section
+		}
+		section.Cards = append(section.Cards, rendered)
+	}
+
+	doc := Document{
+		Title:       board.Title,
+		Icon:        stringField(board.Fields, "icon"),
+		Description: stringField(board.Fields, "description"),
+		Sections:    []Section{},
+	}
+	if groupBy == nil {
+		if section, ok := sections[""]; ok {
+			doc.Sections = append(doc.Sections, *section)
+		}
+		return doc
+	}
+
+	// the cards without a value come first, as in the board view
+	if section, ok := sections[""]; ok {
+		section.Title = "No " + groupBy.name
+		doc.Sections = append(doc.Sections, *section)
+	}
+	for _, id := range groupBy.optionIDs {
+		if section, ok := sections[id]; ok {
+			section.Title = groupBy.options[id]
+			doc.Sections = append(doc.Sections, *section)
+		}
+	}
+	return doc
+}
+
+// cardContent returns the text, checkbox and code content of the card, in
+// its content order, where the blocks shown side by side are nested lists.
+func cardContent(card model.Block, contentByID map[string]model.Block) []Content {
+	ids := []string{}
+	contentOrder, _ := card.Fields["contentOrder"].([]interface{})
+	for _, item := range contentOrder {
+		if row, ok := item.([]interface{}); ok {
+			for _, id := range row {
+				ids = append(ids, fmt.Sprint(id))
+			}
+			continue
+		}
+		ids = append(ids, fmt.Sprint(item))
+	}
+
+	content := []Content{}
+	for _, id := range ids {
+		block, ok := contentByID[id]
+		if !ok || block.ParentID != card.ID {
+			continue
+		}
+		switch block.Type {
+		case ContentText:
+			if text := strings.TrimSpace(block.Title); text != "" {
+				content = append(content, Content{Type: ContentText, Text: text})
+			}
+		case ContentCheckbox:
+			checked, _ := block.Fields["value"].(bool)
+			content = append(content, Content{Type: ContentCheckbox, Text: block.Title, Checked: checked})
+		case ContentCode:
+			language, _ := block.Fields[model.CodeLanguageField].(string)
+			content = append(content, Content{Type: ContentCode, Text: block.Title, Language: language})
+		}
+	}
+	return content
+}
+
+// boardProperties returns the card properties of the board, in their
+// order.
+func boardProperties(board model.Block) []property {
+	properties := []property{}
+	cardProperties, _ := board.Fields["cardProperties"].([]interface{})
+	for _, item := range cardProperties {
+		template, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		p := property{
+			id:           stringField(template, "id"),
+			name:         stringField(template, "name"),
+			propertyType: stringField(template, "type"),
+			options:      map[string]string{},
+		}
+		options, _ := template["options"].([]interface{})
+		for _, item := range options {
+			if option, ok := item.(map[string]interface{}); ok {
+				id := stringField(option, "id")
+				p.optionIDs = append(p.optionIDs, id)
+				p.options[id] = stringField(option, "value")
+			}
+		}
+		properties = append(properties, p)
+	}
+	return properties
+}
+
+// formatter formats the values of the properties.
+type formatter struct {
+	opts Options
+}
+
+// value returns the formatted value of the property of the card, or an
+// empty string if it has none.
+func (f *formatter) value(card model.Block, p property, value interface{}) string {
+	switch p.propertyType {
+	case "select":
+		id, _ := value.(string)
+		return p.options[id]
+	case "multiSelect":
+		ids, _ := value.([]interface{})
+		names := []string{}
+		for _, id := range ids {
+			if name, ok := p.options[fmt.Sprint(id)]; ok {
+				names = append(names, name)
+			}
+		}
+		return strings.Join(names, ", ")
+	case "person":
+		id, _ := value.(string)
+		return f.username(id)
+	case "createdBy":
+		return f.username(card.CreatedBy)
+	case "updatedBy":
+		return f.username(card.ModifiedBy)
+	case "date":
+		return f.date(value)
+	case "createdTime":
+		return FormatTime(card.CreateAt, true, f.opts.Locale)
+	case "updatedTime":
+		return FormatTime(card.UpdateAt, true, f.opts.Locale)
+	case "checkbox":
+		if value == "true" {
+			return "Yes"
+		}
+		return "No"
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// username returns the username of the user, or the ID for the users
+// that aren't members of the workspace.
+func (f *formatter) username(userID string) string {
+	if username, ok := f.opts.Usernames[userID]; ok {
+		return username
+	}
+	return userID
+}
+
+// date formats the value of a date property, a timestamp or a JSON
+// encoded date range.
+func (f *formatter) date(value interface{}) string {
+	str, _ := value.(string)
+	if str == "" {
+		return ""
+	}
+
+	var date struct {
+		From        int64 `json:"from"`
+		To          int64 `json:"to"`
+		IncludeTime bool  `json:"includeTime"`
+	}
+	if timestamp, err := strconv.ParseInt(str, 10, 64); err == nil {
+		date.From = timestamp
+	} else if err := json.Unmarshal([]byte(str), &date); err != nil {
+		return ""
+	}
+
+	from := FormatTime(date.From, date.IncludeTime, f.opts.Locale)
+	to := FormatTime(date.To, date.IncludeTime, f.opts.Locale)
+	switch {
+	case from != "" && to != "":
+		return from + " – " + to
+	case from != "":
+		return from
+	}
+	return to
+}
+
+func stringField(fields map[string]interface{}, name string) string {
+	value, _ := fields[name].(string)
+	return value
+}
@@ -0,0 +1,71 @@
+package render
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	defaultDateLayout = "2006-01-02"
+	defaultTimeLayout = "15:04"
+)
+
+// dateLayouts are the layouts of the dates by locale, and by language
+// for the locales that aren't listed.
+var dateLayouts = map[string]string{
+	"en":    "January 2, 2006",
+	"en-gb": "2 January 2006",
+	"ca":    "02/01/2006",
+	"de":    "02.01.2006",
+	"es":    "02/01/2006",
+	"fr":    "02/01/2006",
+	"it":    "02/01/2006",
+	"nl":    "02-01-2006",
+	"pl":    "02.01.2006",
+	"pt":    "02/01/2006",
+	"ru":    "02.01.2006",
+	"sv":    "2006-01-02",
+	"tr":    "02.01.2006",
+	"ja":    "2006/01/02",
+	"ko":    "2006. 01. 02.",
+	"zh":    "2006/01/02",
+}
+
+// timeLayouts are the layouts of the times of the day by locale, and by
+// language, the 24-hour clock being the default.
+var timeLayouts = map[string]string{
+	"en":    "3:04 PM",
+	"en-gb": "15:04",
+}
+
+// FormatTime formats the timestamp in milliseconds as a date of the
+// locale, in UTC, with the time of the day if includeTime is true. It
+// returns an empty string for a zero timestamp.
+func FormatTime(millis int64, includeTime bool, locale string) string {
+	if millis == 0 {
+		return ""
+	}
+	t := time.Unix(0, millis*int64(time.Millisecond)).UTC()
+	layout := localeLayout(dateLayouts, locale, defaultDateLayout)
+	if includeTime {
+		layout += " " + localeLayout(timeLayouts, locale, defaultTimeLayout)
+	}
+	return t.Format(layout)
+}
+
+// localeLayout returns the layout of the locale, or of its language.
+func localeLayout(layouts map[string]string, locale, defaultLayout string) string {
+	tag := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if layout, ok := layouts[tag]; ok {
+		return layout
+	}
+	if i := strings.Index(tag, "-"); i > 0 {
+		if layout, ok := layouts[tag[:i]]; ok {
+			return layout
+		}
+	}
+	if tag == "" {
+		return layouts["en"]
+	}
+	return defaultLayout
+}
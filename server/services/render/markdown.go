@@ -0,0 +1,136 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownEscaper escapes the characters of the titles and the values
+// that Markdown would take as formatting.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+)
+
+// markdownWriter writes the document, keeping the first error.
+type markdownWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (m *markdownWriter) printf(format string, args ...interface{}) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
+
+// lines writes the text indented with the prefix, leaving the blank
+// lines empty.
+func (m *markdownWriter) lines(prefix, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			m.printf("\n")
+			continue
+		}
+		m.printf("%s%s\n", prefix, line)
+	}
+}
+
+// WriteMarkdown writes the document as Markdown: the sections are H2
+// headings and the cards are bullets with their properties, whose
+// content is nested. The text of the descriptions is Markdown already.
+func WriteMarkdown(w io.Writer, doc Document) error {
+	m := &markdownWriter{w: w}
+
+	m.printf("# %s\n", withIcon(doc.Icon, doc.Title))
+	if description := strings.TrimSpace(doc.Description); description != "" {
+		m.printf("\n")
+		m.lines("", description)
+	}
+
+	for _, section := range doc.Sections {
+		if section.Title != "" {
+			m.printf("\n## %s\n", markdownEscaper.Replace(section.Title))
+		}
+		m.printf("\n")
+		for i, card := range section.Cards {
+			if i > 0 {
+				m.printf("\n")
+			}
+			writeMarkdownCard(m, card)
+		}
+	}
+
+	return m.err
+}
+
+func writeMarkdownCard(m *markdownWriter, card Card) {
+	title := card.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	m.printf("- **%s**", withIcon(card.Icon, title))
+	for i, property := range card.Properties {
+		separator := " · "
+		if i == 0 {
+			separator = " — "
+		}
+		m.printf("%s%s: %s", separator, markdownEscaper.Replace(property.Name), markdownEscaper.Replace(property.Value))
+	}
+	m.printf("\n")
+
+	// the consecutive checkboxes form a checklist
+	inChecklist := false
+	for _, content := range card.Content {
+		switch content.Type {
+		case ContentText:
+			m.printf("\n")
+			m.lines("  ", content.Text)
+			inChecklist = false
+		case ContentCheckbox:
+			if !inChecklist {
+				m.printf("\n")
+			}
+			check := " "
+			if content.Checked {
+				check = "x"
+			}
+			m.printf("  - [%s] %s\n", check, markdownEscaper.Replace(content.Text))
+			inChecklist = true
+		case ContentCode:
+			m.printf("\n")
+			writeMarkdownCode(m, "  ", content)
+			inChecklist = false
+		}
+	}
+}
+
+// writeMarkdownCode writes the code as a fenced code block, whose fence
+// is longer than the runs of backticks of the code.
+func writeMarkdownCode(m *markdownWriter, prefix string, content Content) {
+	fence := "```"
+	for strings.Contains(content.Text, fence) {
+		fence += "`"
+	}
+	m.printf("%s%s%s\n", prefix, fence, content.Language)
+	for _, line := range strings.Split(strings.TrimRight(content.Text, "\n"), "\n") {
+		m.printf("%s%s\n", prefix, strings.TrimRight(line, "\r"))
+	}
+	m.printf("%s%s\n", prefix, fence)
+}
+
+func withIcon(icon, title string) string {
+	title = markdownEscaper.Replace(title)
+	if icon == "" {
+		return title
+	}
+	return icon + " " + title
+}
@@ -0,0 +1,170 @@
+package render
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// requireGolden compares the output to the golden file of the test data,
+// or updates the file with -update.
+func requireGolden(t *testing.T, name string, output []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, ioutil.WriteFile(path, output, 0600))
+	}
+	expected, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(output))
+}
+
+func testBoard() (model.Block, model.Block, []model.Block, []model.Block) {
+	board := model.Block{
+		ID:    "board",
+		Type:  "board",
+		Title: "Roadmap",
+		Fields: map[string]interface{}{
+			"icon":        "🗺️",
+			"description": "The plan for the *next* release",
+			"cardProperties": []interface{}{
+				map[string]interface{}{"id": "status", "name": "Status", "type": "select", "options": []interface{}{
+					map[string]interface{}{"id": "todo", "value": "To Do"},
+					map[string]interface{}{"id": "doing", "value": "In Progress"},
+					map[string]interface{}{"id": "done", "value": "Done"},
+				}},
+				map[string]interface{}{"id": "assignee", "name": "Assignee", "type": "person"},
+				map[string]interface{}{"id": "due", "name": "Due date", "type": "date"},
+				map[string]interface{}{"id": "tags", "name": "Tags", "type": "multiSelect", "options": []interface{}{
+					map[string]interface{}{"id": "api", "value": "api"},
+					map[string]interface{}{"id": "ui", "value": "ui"},
+				}},
+				map[string]interface{}{"id": "estimate", "name": "Estimate", "type": "number"},
+			},
+		},
+	}
+	view := model.Block{
+		ID:       "view",
+		ParentID: "board",
+		Type:     "view",
+		Fields: map[string]interface{}{
+			"groupById":          "status",
+			"visiblePropertyIds": []interface{}{"assignee", "due", "tags"},
+		},
+	}
+	cards := []model.Block{
+		{
+			ID:       "card-1",
+			ParentID: "board",
+			Type:     "card",
+			Title:    "Design the API",
+			Fields: map[string]interface{}{
+				"icon": "📐",
+				"properties": map[string]interface{}{
+					"status":   "doing",
+					"assignee": "user-1",
+					"due":      `{"from":1634169600000,"to":1634688000000}`,
+					"tags":     []interface{}{"api", "ui"},
+					"estimate": "3",
+				},
+				"contentOrder": []interface{}{"text-1", []interface{}{"checkbox-1", "checkbox-2"}, "text-2", "code-1", "image-1"},
+			},
+		},
+		{
+			ID:       "card-2",
+			ParentID: "board",
+			Type:     "card",
+			Title:    "Release [notes]",
+			Fields: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"status":   "done",
+					"assignee": "unknown-user",
+					"due":      `{"from":1634223600000,"includeTime":true}`,
+				},
+			},
+		},
+		{
+			ID:       "card-3",
+			ParentID: "board",
+			Type:     "card",
+			Title:    "Triage",
+			Fields:   map[string]interface{}{"properties": map[string]interface{}{}},
+		},
+		{
+			ID:       "template",
+			ParentID: "board",
+			Type:     "card",
+			Title:    "Template",
+			Fields:   map[string]interface{}{"isTemplate": true, "properties": map[string]interface{}{"status": "todo"}},
+		},
+	}
+	content := []model.Block{
+		{ID: "text-1", ParentID: "card-1", Type: "text", Title: "Some **text**\n\nA second paragraph"},
+		{ID: "checkbox-1", ParentID: "card-1", Type: "checkbox", Title: "Draft", Fields: map[string]interface{}{"value": true}},
+		{ID: "checkbox-2", ParentID: "card-1", Type: "checkbox", Title: "Review", Fields: map[string]interface{}{}},
+		{ID: "text-2", ParentID: "card-1", Type: "text", Title: "More text"},
+		{
+			ID:       "code-1",
+			ParentID: "card-1",
+			Type:     "code",
+			Title:    "func main() {\n\tfmt.Println(\"```\")\n}",
+			Fields:   map[string]interface{}{"language": "go"},
+		},
+		{ID: "image-1", ParentID: "card-1", Type: "image", Fields: map[string]interface{}{"fileId": "file"}},
+	}
+	return board, view, cards, content
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	board, view, cards, content := testBoard()
+	usernames := map[string]string{"user-1": "alice"}
+
+	testCases := []struct {
+		name   string
+		golden string
+		view   *model.Block
+		locale string
+	}{
+		{"grouped by the view", "grouped.md", &view, "en"},
+		{"without a view", "ungrouped.md", nil, "de"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := NewDocument(board, tc.view, cards, content, Options{Locale: tc.locale, Usernames: usernames})
+			var buf bytes.Buffer
+			require.NoError(t, WriteMarkdown(&buf, doc))
+			requireGolden(t, tc.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	testCases := []struct {
+		locale      string
+		includeTime bool
+		expected    string
+	}{
+		{"", false, "October 14, 2021"},
+		{"en", true, "October 14, 2021 3:04 PM"},
+		{"en-GB", false, "14 October 2021"},
+		{"en_GB", true, "14 October 2021 15:04"},
+		{"pt-BR", false, "14/10/2021"},
+		{"de", true, "14.10.2021 15:04"},
+		{"ja", false, "2021/10/14"},
+		{"xx", false, "2021-10-14"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.locale, func(t *testing.T) {
+			require.Equal(t, tc.expected, FormatTime(1634223840000, tc.includeTime, tc.locale))
+		})
+	}
+	require.Empty(t, FormatTime(0, true, "en"))
+}
@@ -0,0 +1,19 @@
+package store
+
+import "context"
+
+type sessionIDContextKey struct{}
+
+// WithSessionID returns a context for the queries of the session, so
+// that its reads following its writes can be routed to the primary
+// database.
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDContextKey{}, sessionID)
+}
+
+// SessionIDFromContext returns the ID of the session of the queries, or
+// an empty string if they aren't run for a session.
+func SessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionIDContextKey{}).(string)
+	return sessionID
+}
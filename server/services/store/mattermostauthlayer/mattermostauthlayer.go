@@ -1,8 +1,10 @@
 package mattermostauthlayer
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"strings"
 	"time"
 
@@ -19,6 +21,18 @@ const (
 	postgresDBType = "postgres"
 )
 
+// likeEscapeChar escapes the wildcards of the LIKE patterns.
+const likeEscapeChar = "!"
+
+var likePatternEscaper = strings.NewReplacer(
+	likeEscapeChar, likeEscapeChar+likeEscapeChar,
+	"%", likeEscapeChar+"%",
+	"_", likeEscapeChar+"_",
+)
+
+// mmGuestRole is the role of the guest accounts of Mattermost.
+const mmGuestRole = "system_guest"
+
 type NotSupportedError struct {
 	msg string
 }
@@ -52,6 +66,37 @@ func (s *MattermostAuthLayer) Shutdown() error {
 	return s.Store.Shutdown()
 }
 
+// mattermostAuthLayerTx is the layer over a store bound to a
+// transaction. The users and channels are still read from the
+// Mattermost tables outside of the transaction.
+type mattermostAuthLayerTx struct {
+	*MattermostAuthLayer
+	tx store.Tx
+}
+
+func (s *MattermostAuthLayer) BeginTx(ctx context.Context) (store.Tx, error) {
+	tx, err := s.Store.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	layer := &MattermostAuthLayer{
+		Store:  tx,
+		dbType: s.dbType,
+		mmDB:   s.mmDB,
+		logger: s.logger,
+	}
+	return &mattermostAuthLayerTx{MattermostAuthLayer: layer, tx: tx}, nil
+}
+
+func (t *mattermostAuthLayerTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *mattermostAuthLayerTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
 func (s *MattermostAuthLayer) GetRegisteredUserCount() (int, error) {
 	query := s.getQueryBuilder().
 		Select("count(*)").
@@ -71,7 +116,7 @@ func (s *MattermostAuthLayer) GetRegisteredUserCount() (int, error) {
 func (s *MattermostAuthLayer) getUserByCondition(condition sq.Eq) (*model.User, error) {
 	query := s.getQueryBuilder().
 		Select("id", "username", "email", "password", "MFASecret as mfa_secret", "AuthService as auth_service", "COALESCE(AuthData, '') as auth_data",
-			"props", "CreateAt as create_at", "UpdateAt as update_at", "DeleteAt as delete_at").
+			"props", "CreateAt as create_at", "UpdateAt as update_at", "DeleteAt as delete_at", "Roles as roles").
 		From("Users").
 		Where(sq.Eq{"deleteAt": 0}).
 		Where(condition)
@@ -79,11 +124,13 @@ func (s *MattermostAuthLayer) getUserByCondition(condition sq.Eq) (*model.User,
 	user := model.User{}
 
 	var propsBytes []byte
+	var roles string
 	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.MfaSecret, &user.AuthService,
-		&user.AuthData, &propsBytes, &user.CreateAt, &user.UpdateAt, &user.DeleteAt)
+		&user.AuthData, &propsBytes, &user.CreateAt, &user.UpdateAt, &user.DeleteAt, &roles)
 	if err != nil {
 		return nil, err
 	}
+	user.IsGuest = isMattermostGuest(roles)
 
 	err = json.Unmarshal(propsBytes, &user.Props)
 	if err != nil {
@@ -105,6 +152,10 @@ func (s *MattermostAuthLayer) GetUserByUsername(username string) (*model.User, e
 	return s.getUserByCondition(sq.Eq{"username": username})
 }
 
+func (s *MattermostAuthLayer) GetUserByAuthData(authData string) (*model.User, error) {
+	return nil, NotSupportedError{"no single sign-on from focalboard, the users log in using mattermost"}
+}
+
 func (s *MattermostAuthLayer) CreateUser(user *model.User) error {
 	return NotSupportedError{"no user creation allowed from focalboard, create it using mattermost"}
 }
@@ -121,6 +172,22 @@ func (s *MattermostAuthLayer) UpdateUserPasswordByID(userID, password string) er
 	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
 }
 
+func (s *MattermostAuthLayer) UpdateUserMfa(userID, mfaSecret string, mfaActive bool) error {
+	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
+}
+
+func (s *MattermostAuthLayer) UpdateUserAuthData(userID, authData string) error {
+	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
+}
+
+func (s *MattermostAuthLayer) UpdateUserActive(userID string, active bool) error {
+	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
+}
+
+func (s *MattermostAuthLayer) GetUsers(opts model.QueryUsersOptions) ([]*model.User, error) {
+	return nil, NotSupportedError{"no user listing from focalboard, list them using mattermost"}
+}
+
 // GetActiveUserCount returns the number of users with active sessions within N seconds ago.
 func (s *MattermostAuthLayer) GetActiveUserCount(updatedSecondsAgo int64) (int, error) {
 	query := s.getQueryBuilder().
@@ -139,7 +206,7 @@ func (s *MattermostAuthLayer) GetActiveUserCount(updatedSecondsAgo int64) (int,
 	return count, nil
 }
 
-func (s *MattermostAuthLayer) GetSession(token string, expireTime int64) (*model.Session, error) {
+func (s *MattermostAuthLayer) GetSession(token string) (*model.Session, error) {
 	return nil, NotSupportedError{"sessions not used when using mattermost"}
 }
 
@@ -159,11 +226,59 @@ func (s *MattermostAuthLayer) DeleteSession(sessionID string) error {
 	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
 }
 
-func (s *MattermostAuthLayer) CleanUpSessions(expireTime int64) error {
+func (s *MattermostAuthLayer) DeleteSessionsForUser(userID string) error {
 	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
 }
 
-func (s *MattermostAuthLayer) GetWorkspace(id string) (*model.Workspace, error) {
+func (s *MattermostAuthLayer) DeleteExpiredSessions() error {
+	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
+}
+
+func (s *MattermostAuthLayer) SetLegacySessionsExpireAt(expireAt int64) error {
+	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
+}
+
+func (s *MattermostAuthLayer) CreateAccessToken(token model.AccessToken) error {
+	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
+}
+
+func (s *MattermostAuthLayer) GetAccessTokenByHash(tokenHash string) (*model.AccessToken, error) {
+	return nil, NotSupportedError{"access tokens not used when using mattermost"}
+}
+
+func (s *MattermostAuthLayer) GetAccessTokensForUser(userID string) ([]model.AccessToken, error) {
+	return nil, NotSupportedError{"access tokens not used when using mattermost"}
+}
+
+func (s *MattermostAuthLayer) UpdateAccessTokenLastUsed(tokenID string, lastUsedAt int64) error {
+	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
+}
+
+func (s *MattermostAuthLayer) DeleteAccessToken(userID string, tokenID string) error {
+	return NotSupportedError{"no update allowed from focalboard, update it using mattermost"}
+}
+
+func (s *MattermostAuthLayer) GetWorkspace(ctx context.Context, id string) (*model.Workspace, error) {
+	workspace, err := s.getWorkspaceFromChannel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// settings are stored in the focalboard workspaces table
+	fbWorkspace, err := s.Store.GetWorkspace(ctx, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if fbWorkspace != nil {
+		workspace.Settings = fbWorkspace.Settings
+		workspace.ModifiedBy = fbWorkspace.ModifiedBy
+		workspace.UpdateAt = fbWorkspace.UpdateAt
+	}
+
+	return workspace, nil
+}
+
+func (s *MattermostAuthLayer) getWorkspaceFromChannel(ctx context.Context, id string) (*model.Workspace, error) {
 	if id == "0" {
 		workspace := model.Workspace{
 			ID:    id,
@@ -178,7 +293,7 @@ func (s *MattermostAuthLayer) GetWorkspace(id string) (*model.Workspace, error)
 		From("Channels").
 		Where(sq.Eq{"ID": id})
 
-	row := query.QueryRow()
+	row := query.QueryRowContext(ctx)
 	var displayName string
 	var channelType string
 	err := row.Scan(&displayName, &channelType)
@@ -197,7 +312,7 @@ func (s *MattermostAuthLayer) GetWorkspace(id string) (*model.Workspace, error)
 		Where(sq.Eq{"ChannelID": id})
 
 	var sb strings.Builder
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -219,14 +334,26 @@ func (s *MattermostAuthLayer) GetWorkspace(id string) (*model.Workspace, error)
 	return &model.Workspace{ID: id, Title: sb.String()}, nil
 }
 
-func (s *MattermostAuthLayer) HasWorkspaceAccess(userID string, workspaceID string) (bool, error) {
+func (s *MattermostAuthLayer) HasWorkspaceAccess(ctx context.Context, userID string, workspaceID string) (bool, error) {
 	query := s.getQueryBuilder().
 		Select("count(*)").
 		From("ChannelMembers").
-		Where(sq.Eq{"ChannelID": workspaceID}).
-		Where(sq.Eq{"UserID": userID})
+		Join("Users ON Users.ID = ChannelMembers.UserID").
+		Where(sq.Eq{"ChannelMembers.ChannelID": workspaceID}).
+		Where(sq.Eq{"ChannelMembers.UserID": userID}).
+		Where(sq.Eq{"Users.DeleteAt": 0})
+
+	// the root workspace is not backed by a channel, any active
+	// user has access to it
+	if workspaceID == "0" {
+		query = s.getQueryBuilder().
+			Select("count(*)").
+			From("Users").
+			Where(sq.Eq{"ID": userID}).
+			Where(sq.Eq{"DeleteAt": 0})
+	}
 
-	row := query.QueryRow()
+	row := query.QueryRowContext(ctx)
 
 	var count int
 	err := row.Scan(&count)
@@ -249,7 +376,7 @@ func (s *MattermostAuthLayer) getQueryBuilder() sq.StatementBuilderType {
 func (s *MattermostAuthLayer) GetUsersByWorkspace(workspaceID string) ([]*model.User, error) {
 	query := s.getQueryBuilder().
 		Select("id", "username", "email", "password", "MFASecret as mfa_secret", "AuthService as auth_service", "COALESCE(AuthData, '') as auth_data",
-			"props", "CreateAt as create_at", "UpdateAt as update_at", "DeleteAt as delete_at").
+			"props", "CreateAt as create_at", "UpdateAt as update_at", "DeleteAt as delete_at", "Roles as roles").
 		From("Users").
 		Join("ChannelMembers ON ChannelMembers.UserID = Users.ID").
 		Where(sq.Eq{"deleteAt": 0}).
@@ -269,12 +396,53 @@ func (s *MattermostAuthLayer) GetUsersByWorkspace(workspaceID string) ([]*model.
 	return users, nil
 }
 
+// SearchUsersByWorkspace returns the active users who are members of the
+// channel of the workspace, or any active user for the root workspace,
+// whose username or nickname starts with the prefix. The bots are left
+// out.
+func (s *MattermostAuthLayer) SearchUsersByWorkspace(ctx context.Context, workspaceID, prefix string, limit int) ([]model.UserSearchResult, error) {
+	pattern := likePatternEscaper.Replace(strings.ToLower(prefix)) + "%"
+	query := s.getQueryBuilder().
+		Select("Users.Id", "Users.Username", "COALESCE(Users.Nickname, '')").
+		From("Users").
+		LeftJoin("Bots ON Bots.UserId = Users.Id").
+		Where(sq.Eq{"Users.DeleteAt": 0}).
+		Where(sq.Eq{"Bots.UserId": nil}).
+		Where(sq.Or{
+			sq.Expr("LOWER(Users.Username) LIKE ? ESCAPE '"+likeEscapeChar+"'", pattern),
+			sq.Expr("LOWER(Users.Nickname) LIKE ? ESCAPE '"+likeEscapeChar+"'", pattern),
+		}).
+		OrderBy("Users.Username", "Users.Id").
+		Limit(uint64(limit))
+	if workspaceID != "0" {
+		// the root workspace is not backed by a channel
+		query = query.Join("ChannelMembers ON ChannelMembers.UserId = Users.Id AND ChannelMembers.ChannelId = ?", workspaceID)
+	}
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	results := []model.UserSearchResult{}
+	for rows.Next() {
+		var result model.UserSearchResult
+		if err := rows.Scan(&result.ID, &result.Username, &result.Nickname); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
 func (s *MattermostAuthLayer) usersFromRows(rows *sql.Rows) ([]*model.User, error) {
 	users := []*model.User{}
 
 	for rows.Next() {
 		var user model.User
 		var propsBytes []byte
+		var roles string
 
 		err := rows.Scan(
 			&user.ID,
@@ -288,10 +456,12 @@ func (s *MattermostAuthLayer) usersFromRows(rows *sql.Rows) ([]*model.User, erro
 			&user.CreateAt,
 			&user.UpdateAt,
 			&user.DeleteAt,
+			&roles,
 		)
 		if err != nil {
 			return nil, err
 		}
+		user.IsGuest = isMattermostGuest(roles)
 
 		err = json.Unmarshal(propsBytes, &user.Props)
 		if err != nil {
@@ -304,6 +474,17 @@ func (s *MattermostAuthLayer) usersFromRows(rows *sql.Rows) ([]*model.User, erro
 	return users, nil
 }
 
+// isMattermostGuest tells if the space separated roles of a Mattermost
+// user are those of a guest.
+func isMattermostGuest(roles string) bool {
+	for _, role := range strings.Fields(roles) {
+		if role == mmGuestRole {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *MattermostAuthLayer) CloseRows(rows *sql.Rows) {
 	if err := rows.Close(); err != nil {
 		s.logger.Error("error closing MattermostAuthLayer row set", mlog.Err(err))
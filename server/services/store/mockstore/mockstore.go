@@ -5,6 +5,8 @@
 package mockstore
 
 import (
+	context "context"
+	sql "database/sql"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,522 +37,4625 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
-// CleanUpSessions mocks base method.
-func (m *MockStore) CleanUpSessions(expireTime int64) error {
+// AcquireClusterLock mocks base method.
+func (m *MockStore) AcquireClusterLock(name, owner string, expireAt int64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CleanUpSessions", expireTime)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "AcquireClusterLock", name, owner, expireAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// CleanUpSessions indicates an expected call of CleanUpSessions.
-func (mr *MockStoreMockRecorder) CleanUpSessions(expireTime interface{}) *gomock.Call {
+// AcquireClusterLock indicates an expected call of AcquireClusterLock.
+func (mr *MockStoreMockRecorder) AcquireClusterLock(name, owner, expireAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanUpSessions", reflect.TypeOf((*MockStore)(nil).CleanUpSessions), expireTime)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireClusterLock", reflect.TypeOf((*MockStore)(nil).AcquireClusterLock), name, owner, expireAt)
 }
 
-// CreateSession mocks base method.
-func (m *MockStore) CreateSession(session *model.Session) error {
+// AddWorkspaceMember mocks base method.
+func (m *MockStore) AddWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", session)
+	ret := m.ctrl.Call(m, "AddWorkspaceMember", ctx, workspaceID, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CreateSession indicates an expected call of CreateSession.
-func (mr *MockStoreMockRecorder) CreateSession(session interface{}) *gomock.Call {
+// AddWorkspaceMember indicates an expected call of AddWorkspaceMember.
+func (mr *MockStoreMockRecorder) AddWorkspaceMember(ctx, workspaceID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), session)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWorkspaceMember", reflect.TypeOf((*MockStore)(nil).AddWorkspaceMember), ctx, workspaceID, userID)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(user *model.User) error {
+// BeginTx mocks base method.
+func (m *MockStore) BeginTx(ctx context.Context) (store.Tx, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", user)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "BeginTx", ctx)
+	ret0, _ := ret[0].(store.Tx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(user interface{}) *gomock.Call {
+// BeginTx indicates an expected call of BeginTx.
+func (mr *MockStoreMockRecorder) BeginTx(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTx", reflect.TypeOf((*MockStore)(nil).BeginTx), ctx)
 }
 
-// DeleteBlock mocks base method.
-func (m *MockStore) DeleteBlock(c store.Container, blockID, modifiedBy string) error {
+// ClaimSubscriptionNotification mocks base method.
+func (m *MockStore) ClaimSubscriptionNotification(ctx context.Context, blockID, subscriberID string, notifiedAt, notifiedBefore int64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteBlock", c, blockID, modifiedBy)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ClaimSubscriptionNotification", ctx, blockID, subscriberID, notifiedAt, notifiedBefore)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteBlock indicates an expected call of DeleteBlock.
-func (mr *MockStoreMockRecorder) DeleteBlock(c, blockID, modifiedBy interface{}) *gomock.Call {
+// ClaimSubscriptionNotification indicates an expected call of ClaimSubscriptionNotification.
+func (mr *MockStoreMockRecorder) ClaimSubscriptionNotification(ctx, blockID, subscriberID, notifiedAt, notifiedBefore interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlock", reflect.TypeOf((*MockStore)(nil).DeleteBlock), c, blockID, modifiedBy)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimSubscriptionNotification", reflect.TypeOf((*MockStore)(nil).ClaimSubscriptionNotification), ctx, blockID, subscriberID, notifiedAt, notifiedBefore)
 }
 
-// DeleteSession mocks base method.
-func (m *MockStore) DeleteSession(sessionID string) error {
+// ClaimUserDigest mocks base method.
+func (m *MockStore) ClaimUserDigest(ctx context.Context, userID string, lastDigestAt, digestAt int64) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteSession", sessionID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ClaimUserDigest", ctx, userID, lastDigestAt, digestAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteSession indicates an expected call of DeleteSession.
-func (mr *MockStoreMockRecorder) DeleteSession(sessionID interface{}) *gomock.Call {
+// ClaimUserDigest indicates an expected call of ClaimUserDigest.
+func (mr *MockStoreMockRecorder) ClaimUserDigest(ctx, userID, lastDigestAt, digestAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockStore)(nil).DeleteSession), sessionID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimUserDigest", reflect.TypeOf((*MockStore)(nil).ClaimUserDigest), ctx, userID, lastDigestAt, digestAt)
 }
 
-// GetActiveUserCount mocks base method.
-func (m *MockStore) GetActiveUserCount(updatedSecondsAgo int64) (int, error) {
+// ConsumeGuestInvite mocks base method.
+func (m *MockStore) ConsumeGuestInvite(tokenHash string) (*model.GuestInvite, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActiveUserCount", updatedSecondsAgo)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "ConsumeGuestInvite", tokenHash)
+	ret0, _ := ret[0].(*model.GuestInvite)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetActiveUserCount indicates an expected call of GetActiveUserCount.
-func (mr *MockStoreMockRecorder) GetActiveUserCount(updatedSecondsAgo interface{}) *gomock.Call {
+// ConsumeGuestInvite indicates an expected call of ConsumeGuestInvite.
+func (mr *MockStoreMockRecorder) ConsumeGuestInvite(tokenHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveUserCount", reflect.TypeOf((*MockStore)(nil).GetActiveUserCount), updatedSecondsAgo)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeGuestInvite", reflect.TypeOf((*MockStore)(nil).ConsumeGuestInvite), tokenHash)
 }
 
-// GetAllBlocks mocks base method.
-func (m *MockStore) GetAllBlocks(c store.Container) ([]model.Block, error) {
+// ConsumeMfaRecoveryCode mocks base method.
+func (m *MockStore) ConsumeMfaRecoveryCode(userID, codeHash string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllBlocks", c)
-	ret0, _ := ret[0].([]model.Block)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ConsumeMfaRecoveryCode", userID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetAllBlocks indicates an expected call of GetAllBlocks.
-func (mr *MockStoreMockRecorder) GetAllBlocks(c interface{}) *gomock.Call {
+// ConsumeMfaRecoveryCode indicates an expected call of ConsumeMfaRecoveryCode.
+func (mr *MockStoreMockRecorder) ConsumeMfaRecoveryCode(userID, codeHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllBlocks", reflect.TypeOf((*MockStore)(nil).GetAllBlocks), c)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeMfaRecoveryCode", reflect.TypeOf((*MockStore)(nil).ConsumeMfaRecoveryCode), userID, codeHash)
 }
 
-// GetBlock mocks base method.
-func (m *MockStore) GetBlock(c store.Container, blockID string) (*model.Block, error) {
+// ConsumePasswordResetToken mocks base method.
+func (m *MockStore) ConsumePasswordResetToken(tokenHash string) (*model.PasswordResetToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBlock", c, blockID)
-	ret0, _ := ret[0].(*model.Block)
+	ret := m.ctrl.Call(m, "ConsumePasswordResetToken", tokenHash)
+	ret0, _ := ret[0].(*model.PasswordResetToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBlock indicates an expected call of GetBlock.
-func (mr *MockStoreMockRecorder) GetBlock(c, blockID interface{}) *gomock.Call {
+// ConsumePasswordResetToken indicates an expected call of ConsumePasswordResetToken.
+func (mr *MockStoreMockRecorder) ConsumePasswordResetToken(tokenHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlock", reflect.TypeOf((*MockStore)(nil).GetBlock), c, blockID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumePasswordResetToken", reflect.TypeOf((*MockStore)(nil).ConsumePasswordResetToken), tokenHash)
 }
 
-// GetBlockCountsByType mocks base method.
-func (m *MockStore) GetBlockCountsByType() (map[string]int64, error) {
+// CountCardsByProperty mocks base method.
+func (m *MockStore) CountCardsByProperty(ctx context.Context, c store.Container, boardID, propertyID string) ([]model.PropertyValueCount, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBlockCountsByType")
-	ret0, _ := ret[0].(map[string]int64)
+	ret := m.ctrl.Call(m, "CountCardsByProperty", ctx, c, boardID, propertyID)
+	ret0, _ := ret[0].([]model.PropertyValueCount)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBlockCountsByType indicates an expected call of GetBlockCountsByType.
-func (mr *MockStoreMockRecorder) GetBlockCountsByType() *gomock.Call {
+// CountCardsByProperty indicates an expected call of CountCardsByProperty.
+func (mr *MockStoreMockRecorder) CountCardsByProperty(ctx, c, boardID, propertyID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockCountsByType", reflect.TypeOf((*MockStore)(nil).GetBlockCountsByType))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCardsByProperty", reflect.TypeOf((*MockStore)(nil).CountCardsByProperty), ctx, c, boardID, propertyID)
 }
 
-// GetBlocksWithParent mocks base method.
-func (m *MockStore) GetBlocksWithParent(c store.Container, parentID string) ([]model.Block, error) {
+// CountCardsCompletedByWeek mocks base method.
+func (m *MockStore) CountCardsCompletedByWeek(ctx context.Context, c store.Container, boardID, propertyID, optionID string, since int64) (map[int64]int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBlocksWithParent", c, parentID)
-	ret0, _ := ret[0].([]model.Block)
+	ret := m.ctrl.Call(m, "CountCardsCompletedByWeek", ctx, c, boardID, propertyID, optionID, since)
+	ret0, _ := ret[0].(map[int64]int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBlocksWithParent indicates an expected call of GetBlocksWithParent.
-func (mr *MockStoreMockRecorder) GetBlocksWithParent(c, parentID interface{}) *gomock.Call {
+// CountCardsCompletedByWeek indicates an expected call of CountCardsCompletedByWeek.
+func (mr *MockStoreMockRecorder) CountCardsCompletedByWeek(ctx, c, boardID, propertyID, optionID, since interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithParent", reflect.TypeOf((*MockStore)(nil).GetBlocksWithParent), c, parentID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCardsCompletedByWeek", reflect.TypeOf((*MockStore)(nil).CountCardsCompletedByWeek), ctx, c, boardID, propertyID, optionID, since)
 }
 
-// GetBlocksWithParentAndType mocks base method.
-func (m *MockStore) GetBlocksWithParentAndType(c store.Container, parentID, blockType string) ([]model.Block, error) {
+// CountCardsCreatedByWeek mocks base method.
+func (m *MockStore) CountCardsCreatedByWeek(ctx context.Context, c store.Container, boardID string, since int64) (map[int64]int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBlocksWithParentAndType", c, parentID, blockType)
-	ret0, _ := ret[0].([]model.Block)
+	ret := m.ctrl.Call(m, "CountCardsCreatedByWeek", ctx, c, boardID, since)
+	ret0, _ := ret[0].(map[int64]int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBlocksWithParentAndType indicates an expected call of GetBlocksWithParentAndType.
-func (mr *MockStoreMockRecorder) GetBlocksWithParentAndType(c, parentID, blockType interface{}) *gomock.Call {
+// CountCardsCreatedByWeek indicates an expected call of CountCardsCreatedByWeek.
+func (mr *MockStoreMockRecorder) CountCardsCreatedByWeek(ctx, c, boardID, since interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithParentAndType", reflect.TypeOf((*MockStore)(nil).GetBlocksWithParentAndType), c, parentID, blockType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCardsCreatedByWeek", reflect.TypeOf((*MockStore)(nil).CountCardsCreatedByWeek), ctx, c, boardID, since)
 }
 
-// GetBlocksWithRootID mocks base method.
-func (m *MockStore) GetBlocksWithRootID(c store.Container, rootID string) ([]model.Block, error) {
+// CountOrphanedBlocks mocks base method.
+func (m *MockStore) CountOrphanedBlocks(ctx context.Context) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBlocksWithRootID", c, rootID)
-	ret0, _ := ret[0].([]model.Block)
+	ret := m.ctrl.Call(m, "CountOrphanedBlocks", ctx)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBlocksWithRootID indicates an expected call of GetBlocksWithRootID.
-func (mr *MockStoreMockRecorder) GetBlocksWithRootID(c, rootID interface{}) *gomock.Call {
+// CountOrphanedBlocks indicates an expected call of CountOrphanedBlocks.
+func (mr *MockStoreMockRecorder) CountOrphanedBlocks(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithRootID", reflect.TypeOf((*MockStore)(nil).GetBlocksWithRootID), c, rootID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOrphanedBlocks", reflect.TypeOf((*MockStore)(nil).CountOrphanedBlocks), ctx)
 }
 
-// GetBlocksWithType mocks base method.
-func (m *MockStore) GetBlocksWithType(c store.Container, blockType string) ([]model.Block, error) {
+// CreateAccessToken mocks base method.
+func (m *MockStore) CreateAccessToken(token model.AccessToken) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBlocksWithType", c, blockType)
-	ret0, _ := ret[0].([]model.Block)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateAccessToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetBlocksWithType indicates an expected call of GetBlocksWithType.
-func (mr *MockStoreMockRecorder) GetBlocksWithType(c, blockType interface{}) *gomock.Call {
+// CreateAccessToken indicates an expected call of CreateAccessToken.
+func (mr *MockStoreMockRecorder) CreateAccessToken(token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithType", reflect.TypeOf((*MockStore)(nil).GetBlocksWithType), c, blockType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockStore)(nil).CreateAccessToken), token)
 }
 
-// GetParentID mocks base method.
-func (m *MockStore) GetParentID(c store.Container, blockID string) (string, error) {
+// CreateGuestInvite mocks base method.
+func (m *MockStore) CreateGuestInvite(invite model.GuestInvite) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetParentID", c, blockID)
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateGuestInvite", invite)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetParentID indicates an expected call of GetParentID.
-func (mr *MockStoreMockRecorder) GetParentID(c, blockID interface{}) *gomock.Call {
+// CreateGuestInvite indicates an expected call of CreateGuestInvite.
+func (mr *MockStoreMockRecorder) CreateGuestInvite(invite interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParentID", reflect.TypeOf((*MockStore)(nil).GetParentID), c, blockID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGuestInvite", reflect.TypeOf((*MockStore)(nil).CreateGuestInvite), invite)
 }
 
-// GetRegisteredUserCount mocks base method.
-func (m *MockStore) GetRegisteredUserCount() (int, error) {
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(key model.IdempotencyKey) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRegisteredUserCount")
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", key)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetRegisteredUserCount indicates an expected call of GetRegisteredUserCount.
-func (mr *MockStoreMockRecorder) GetRegisteredUserCount() *gomock.Call {
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegisteredUserCount", reflect.TypeOf((*MockStore)(nil).GetRegisteredUserCount))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), key)
 }
 
-// GetRootID mocks base method.
-func (m *MockStore) GetRootID(c store.Container, blockID string) (string, error) {
+// CreatePasswordResetToken mocks base method.
+func (m *MockStore) CreatePasswordResetToken(token model.PasswordResetToken) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRootID", c, blockID)
-	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetRootID indicates an expected call of GetRootID.
-func (mr *MockStoreMockRecorder) GetRootID(c, blockID interface{}) *gomock.Call {
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockStoreMockRecorder) CreatePasswordResetToken(token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRootID", reflect.TypeOf((*MockStore)(nil).GetRootID), c, blockID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockStore)(nil).CreatePasswordResetToken), token)
 }
 
-// GetSession mocks base method.
-func (m *MockStore) GetSession(token string, expireTime int64) (*model.Session, error) {
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(session *model.Session) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", token, expireTime)
-	ret0, _ := ret[0].(*model.Session)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockStoreMockRecorder) GetSession(token, expireTime interface{}) *gomock.Call {
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(session interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), token, expireTime)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), session)
 }
 
-// GetSharing mocks base method.
-func (m *MockStore) GetSharing(c store.Container, rootID string) (*model.Sharing, error) {
+// CreateSubscription mocks base method.
+func (m *MockStore) CreateSubscription(ctx context.Context, subscription model.Subscription) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSharing", c, rootID)
-	ret0, _ := ret[0].(*model.Sharing)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetSharing indicates an expected call of GetSharing.
-func (mr *MockStoreMockRecorder) GetSharing(c, rootID interface{}) *gomock.Call {
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockStoreMockRecorder) CreateSubscription(ctx, subscription interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharing", reflect.TypeOf((*MockStore)(nil).GetSharing), c, rootID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockStore)(nil).CreateSubscription), ctx, subscription)
 }
 
-// GetSubTree2 mocks base method.
-func (m *MockStore) GetSubTree2(c store.Container, blockID string) ([]model.Block, error) {
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(user *model.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSubTree2", c, blockID)
-	ret0, _ := ret[0].([]model.Block)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateUser", user)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetSubTree2 indicates an expected call of GetSubTree2.
-func (mr *MockStoreMockRecorder) GetSubTree2(c, blockID interface{}) *gomock.Call {
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubTree2", reflect.TypeOf((*MockStore)(nil).GetSubTree2), c, blockID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), user)
 }
 
-// GetSubTree3 mocks base method.
-func (m *MockStore) GetSubTree3(c store.Container, blockID string) ([]model.Block, error) {
+// DeleteAccessToken mocks base method.
+func (m *MockStore) DeleteAccessToken(userID, tokenID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSubTree3", c, blockID)
-	ret0, _ := ret[0].([]model.Block)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteAccessToken", userID, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetSubTree3 indicates an expected call of GetSubTree3.
-func (mr *MockStoreMockRecorder) GetSubTree3(c, blockID interface{}) *gomock.Call {
+// DeleteAccessToken indicates an expected call of DeleteAccessToken.
+func (mr *MockStoreMockRecorder) DeleteAccessToken(userID, tokenID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubTree3", reflect.TypeOf((*MockStore)(nil).GetSubTree3), c, blockID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccessToken", reflect.TypeOf((*MockStore)(nil).DeleteAccessToken), userID, tokenID)
 }
 
-// GetSystemSettings mocks base method.
-func (m *MockStore) GetSystemSettings() (map[string]string, error) {
+// DeleteBlock mocks base method.
+func (m *MockStore) DeleteBlock(ctx context.Context, c store.Container, blockID, modifiedBy string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSystemSettings")
-	ret0, _ := ret[0].(map[string]string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteBlock", ctx, c, blockID, modifiedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetSystemSettings indicates an expected call of GetSystemSettings.
-func (mr *MockStoreMockRecorder) GetSystemSettings() *gomock.Call {
+// DeleteBlock indicates an expected call of DeleteBlock.
+func (mr *MockStoreMockRecorder) DeleteBlock(ctx, c, blockID, modifiedBy interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemSettings", reflect.TypeOf((*MockStore)(nil).GetSystemSettings))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlock", reflect.TypeOf((*MockStore)(nil).DeleteBlock), ctx, c, blockID, modifiedBy)
 }
 
-// GetUserByEmail mocks base method.
-func (m *MockStore) GetUserByEmail(email string) (*model.User, error) {
+// DeleteBoardMember mocks base method.
+func (m *MockStore) DeleteBoardMember(c store.Container, boardID, userID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByEmail", email)
-	ret0, _ := ret[0].(*model.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteBoardMember", c, boardID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetUserByEmail indicates an expected call of GetUserByEmail.
-func (mr *MockStoreMockRecorder) GetUserByEmail(email interface{}) *gomock.Call {
+// DeleteBoardMember indicates an expected call of DeleteBoardMember.
+func (mr *MockStoreMockRecorder) DeleteBoardMember(c, boardID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBoardMember", reflect.TypeOf((*MockStore)(nil).DeleteBoardMember), c, boardID, userID)
 }
 
-// GetUserByID mocks base method.
-func (m *MockStore) GetUserByID(userID string) (*model.User, error) {
+// DeleteCalendarFeed mocks base method.
+func (m *MockStore) DeleteCalendarFeed(c store.Container, boardID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", userID)
-	ret0, _ := ret[0].(*model.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteCalendarFeed", c, boardID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetUserByID indicates an expected call of GetUserByID.
-func (mr *MockStoreMockRecorder) GetUserByID(userID interface{}) *gomock.Call {
+// DeleteCalendarFeed indicates an expected call of DeleteCalendarFeed.
+func (mr *MockStoreMockRecorder) DeleteCalendarFeed(c, boardID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStore)(nil).GetUserByID), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCalendarFeed", reflect.TypeOf((*MockStore)(nil).DeleteCalendarFeed), c, boardID)
 }
 
-// GetUserByUsername mocks base method.
-func (m *MockStore) GetUserByUsername(username string) (*model.User, error) {
+// DeleteExpiredGuestInvites mocks base method.
+func (m *MockStore) DeleteExpiredGuestInvites(now int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUsername", username)
-	ret0, _ := ret[0].(*model.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteExpiredGuestInvites", now)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetUserByUsername indicates an expected call of GetUserByUsername.
-func (mr *MockStoreMockRecorder) GetUserByUsername(username interface{}) *gomock.Call {
+// DeleteExpiredGuestInvites indicates an expected call of DeleteExpiredGuestInvites.
+func (mr *MockStoreMockRecorder) DeleteExpiredGuestInvites(now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStore)(nil).GetUserByUsername), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredGuestInvites", reflect.TypeOf((*MockStore)(nil).DeleteExpiredGuestInvites), now)
 }
 
-// GetUserWorkspaces mocks base method.
-func (m *MockStore) GetUserWorkspaces(userID string) ([]model.UserWorkspace, error) {
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockStore) DeleteExpiredIdempotencyKeys(now int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserWorkspaces", userID)
-	ret0, _ := ret[0].([]model.UserWorkspace)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", now)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetUserWorkspaces indicates an expected call of GetUserWorkspaces.
-func (mr *MockStoreMockRecorder) GetUserWorkspaces(userID interface{}) *gomock.Call {
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockStoreMockRecorder) DeleteExpiredIdempotencyKeys(now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWorkspaces", reflect.TypeOf((*MockStore)(nil).GetUserWorkspaces), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).DeleteExpiredIdempotencyKeys), now)
 }
 
-// GetUsersByWorkspace mocks base method.
-func (m *MockStore) GetUsersByWorkspace(workspaceID string) ([]*model.User, error) {
+// DeleteExpiredPasswordResetTokens mocks base method.
+func (m *MockStore) DeleteExpiredPasswordResetTokens(now int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUsersByWorkspace", workspaceID)
-	ret0, _ := ret[0].([]*model.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteExpiredPasswordResetTokens", now)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetUsersByWorkspace indicates an expected call of GetUsersByWorkspace.
-func (mr *MockStoreMockRecorder) GetUsersByWorkspace(workspaceID interface{}) *gomock.Call {
+// DeleteExpiredPasswordResetTokens indicates an expected call of DeleteExpiredPasswordResetTokens.
+func (mr *MockStoreMockRecorder) DeleteExpiredPasswordResetTokens(now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByWorkspace", reflect.TypeOf((*MockStore)(nil).GetUsersByWorkspace), workspaceID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredPasswordResetTokens", reflect.TypeOf((*MockStore)(nil).DeleteExpiredPasswordResetTokens), now)
 }
 
-// GetWorkspace mocks base method.
-func (m *MockStore) GetWorkspace(ID string) (*model.Workspace, error) {
+// DeleteExpiredSessions mocks base method.
+func (m *MockStore) DeleteExpiredSessions() error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWorkspace", ID)
-	ret0, _ := ret[0].(*model.Workspace)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteExpiredSessions")
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetWorkspace indicates an expected call of GetWorkspace.
-func (mr *MockStoreMockRecorder) GetWorkspace(ID interface{}) *gomock.Call {
+// DeleteExpiredSessions indicates an expected call of DeleteExpiredSessions.
+func (mr *MockStoreMockRecorder) DeleteExpiredSessions() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspace", reflect.TypeOf((*MockStore)(nil).GetWorkspace), ID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSessions", reflect.TypeOf((*MockStore)(nil).DeleteExpiredSessions))
 }
 
-// GetWorkspaceCount mocks base method.
-func (m *MockStore) GetWorkspaceCount() (int64, error) {
+// DeleteFileInfo mocks base method.
+func (m *MockStore) DeleteFileInfo(fileID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWorkspaceCount")
-	ret0, _ := ret[0].(int64)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteFileInfo", fileID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetWorkspaceCount indicates an expected call of GetWorkspaceCount.
-func (mr *MockStoreMockRecorder) GetWorkspaceCount() *gomock.Call {
+// DeleteFileInfo indicates an expected call of DeleteFileInfo.
+func (mr *MockStoreMockRecorder) DeleteFileInfo(fileID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceCount", reflect.TypeOf((*MockStore)(nil).GetWorkspaceCount))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileInfo", reflect.TypeOf((*MockStore)(nil).DeleteFileInfo), fileID)
 }
 
-// HasWorkspaceAccess mocks base method.
-func (m *MockStore) HasWorkspaceAccess(userID, workspaceID string) (bool, error) {
+// DeleteGitHubIntegration mocks base method.
+func (m *MockStore) DeleteGitHubIntegration(c store.Container, boardID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HasWorkspaceAccess", userID, workspaceID)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "DeleteGitHubIntegration", c, boardID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// HasWorkspaceAccess indicates an expected call of HasWorkspaceAccess.
-func (mr *MockStoreMockRecorder) HasWorkspaceAccess(userID, workspaceID interface{}) *gomock.Call {
+// DeleteGitHubIntegration indicates an expected call of DeleteGitHubIntegration.
+func (mr *MockStoreMockRecorder) DeleteGitHubIntegration(c, boardID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasWorkspaceAccess", reflect.TypeOf((*MockStore)(nil).HasWorkspaceAccess), userID, workspaceID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGitHubIntegration", reflect.TypeOf((*MockStore)(nil).DeleteGitHubIntegration), c, boardID)
 }
 
-// InsertBlock mocks base method.
-func (m *MockStore) InsertBlock(c store.Container, block *model.Block, userID string) error {
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStore) DeleteIdempotencyKey(userID, key string, createAt int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertBlock", c, block, userID)
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", userID, key, createAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// InsertBlock indicates an expected call of InsertBlock.
-func (mr *MockStoreMockRecorder) InsertBlock(c, block, userID interface{}) *gomock.Call {
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKey(userID, key, createAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlock", reflect.TypeOf((*MockStore)(nil).InsertBlock), c, block, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKey), userID, key, createAt)
 }
 
-// PatchBlock mocks base method.
-func (m *MockStore) PatchBlock(c store.Container, blockID string, blockPatch *model.BlockPatch, userID string) error {
+// DeleteInboundHook mocks base method.
+func (m *MockStore) DeleteInboundHook(c store.Container, boardID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PatchBlock", c, blockID, blockPatch, userID)
+	ret := m.ctrl.Call(m, "DeleteInboundHook", c, boardID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// PatchBlock indicates an expected call of PatchBlock.
-func (mr *MockStoreMockRecorder) PatchBlock(c, blockID, blockPatch, userID interface{}) *gomock.Call {
+// DeleteInboundHook indicates an expected call of DeleteInboundHook.
+func (mr *MockStoreMockRecorder) DeleteInboundHook(c, boardID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchBlock", reflect.TypeOf((*MockStore)(nil).PatchBlock), c, blockID, blockPatch, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInboundHook", reflect.TypeOf((*MockStore)(nil).DeleteInboundHook), c, boardID)
 }
 
-// RefreshSession mocks base method.
-func (m *MockStore) RefreshSession(session *model.Session) error {
+// DeleteLoginAttempts mocks base method.
+func (m *MockStore) DeleteLoginAttempts(key string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RefreshSession", session)
+	ret := m.ctrl.Call(m, "DeleteLoginAttempts", key)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// RefreshSession indicates an expected call of RefreshSession.
-func (mr *MockStoreMockRecorder) RefreshSession(session interface{}) *gomock.Call {
+// DeleteLoginAttempts indicates an expected call of DeleteLoginAttempts.
+func (mr *MockStoreMockRecorder) DeleteLoginAttempts(key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockStore)(nil).RefreshSession), session)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttempts", reflect.TypeOf((*MockStore)(nil).DeleteLoginAttempts), key)
 }
 
-// SetSystemSetting mocks base method.
-func (m *MockStore) SetSystemSetting(key, value string) error {
+// DeleteMfaRecoveryCodes mocks base method.
+func (m *MockStore) DeleteMfaRecoveryCodes(userID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetSystemSetting", key, value)
+	ret := m.ctrl.Call(m, "DeleteMfaRecoveryCodes", userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SetSystemSetting indicates an expected call of SetSystemSetting.
-func (mr *MockStoreMockRecorder) SetSystemSetting(key, value interface{}) *gomock.Call {
+// DeleteMfaRecoveryCodes indicates an expected call of DeleteMfaRecoveryCodes.
+func (mr *MockStoreMockRecorder) DeleteMfaRecoveryCodes(userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSystemSetting", reflect.TypeOf((*MockStore)(nil).SetSystemSetting), key, value)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMfaRecoveryCodes", reflect.TypeOf((*MockStore)(nil).DeleteMfaRecoveryCodes), userID)
 }
 
-// Shutdown mocks base method.
-func (m *MockStore) Shutdown() error {
+// DeletePasswordResetTokensForUser mocks base method.
+func (m *MockStore) DeletePasswordResetTokensForUser(userID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Shutdown")
+	ret := m.ctrl.Call(m, "DeletePasswordResetTokensForUser", userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Shutdown indicates an expected call of Shutdown.
-func (mr *MockStoreMockRecorder) Shutdown() *gomock.Call {
+// DeletePasswordResetTokensForUser indicates an expected call of DeletePasswordResetTokensForUser.
+func (mr *MockStoreMockRecorder) DeletePasswordResetTokensForUser(userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockStore)(nil).Shutdown))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResetTokensForUser", reflect.TypeOf((*MockStore)(nil).DeletePasswordResetTokensForUser), userID)
 }
 
-// UpdateSession mocks base method.
-func (m *MockStore) UpdateSession(session *model.Session) error {
+// DeletePreferences mocks base method.
+func (m *MockStore) DeletePreferences(ctx context.Context, userID string, preferences []model.Preference) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSession", session)
+	ret := m.ctrl.Call(m, "DeletePreferences", ctx, userID, preferences)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateSession indicates an expected call of UpdateSession.
+// DeletePreferences indicates an expected call of DeletePreferences.
+func (mr *MockStoreMockRecorder) DeletePreferences(ctx, userID, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePreferences", reflect.TypeOf((*MockStore)(nil).DeletePreferences), ctx, userID, preferences)
+}
+
+// DeleteSession mocks base method.
+func (m *MockStore) DeleteSession(sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockStoreMockRecorder) DeleteSession(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockStore)(nil).DeleteSession), sessionID)
+}
+
+// DeleteSessionsForUser mocks base method.
+func (m *MockStore) DeleteSessionsForUser(userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessionsForUser", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSessionsForUser indicates an expected call of DeleteSessionsForUser.
+func (mr *MockStoreMockRecorder) DeleteSessionsForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessionsForUser", reflect.TypeOf((*MockStore)(nil).DeleteSessionsForUser), userID)
+}
+
+// DeleteStaleLoginAttempts mocks base method.
+func (m *MockStore) DeleteStaleLoginAttempts(before int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleLoginAttempts", before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStaleLoginAttempts indicates an expected call of DeleteStaleLoginAttempts.
+func (mr *MockStoreMockRecorder) DeleteStaleLoginAttempts(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleLoginAttempts", reflect.TypeOf((*MockStore)(nil).DeleteStaleLoginAttempts), before)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockStore) DeleteSubscription(ctx context.Context, c store.Container, blockID, subscriberID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, c, blockID, subscriberID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockStoreMockRecorder) DeleteSubscription(ctx, c, blockID, subscriberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockStore)(nil).DeleteSubscription), ctx, c, blockID, subscriberID)
+}
+
+// DeleteWorkspace mocks base method.
+func (m *MockStore) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspace", ctx, workspaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspace indicates an expected call of DeleteWorkspace.
+func (mr *MockStoreMockRecorder) DeleteWorkspace(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspace", reflect.TypeOf((*MockStore)(nil).DeleteWorkspace), ctx, workspaceID)
+}
+
+// DeleteWorkspaceRetentionNotice mocks base method.
+func (m *MockStore) DeleteWorkspaceRetentionNotice(ctx context.Context, workspaceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspaceRetentionNotice", ctx, workspaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspaceRetentionNotice indicates an expected call of DeleteWorkspaceRetentionNotice.
+func (mr *MockStoreMockRecorder) DeleteWorkspaceRetentionNotice(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspaceRetentionNotice", reflect.TypeOf((*MockStore)(nil).DeleteWorkspaceRetentionNotice), ctx, workspaceID)
+}
+
+// GetAccessTokenByHash mocks base method.
+func (m *MockStore) GetAccessTokenByHash(tokenHash string) (*model.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokenByHash", tokenHash)
+	ret0, _ := ret[0].(*model.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokenByHash indicates an expected call of GetAccessTokenByHash.
+func (mr *MockStoreMockRecorder) GetAccessTokenByHash(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokenByHash", reflect.TypeOf((*MockStore)(nil).GetAccessTokenByHash), tokenHash)
+}
+
+// GetAccessTokensForUser mocks base method.
+func (m *MockStore) GetAccessTokensForUser(userID string) ([]model.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokensForUser", userID)
+	ret0, _ := ret[0].([]model.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokensForUser indicates an expected call of GetAccessTokensForUser.
+func (mr *MockStoreMockRecorder) GetAccessTokensForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokensForUser", reflect.TypeOf((*MockStore)(nil).GetAccessTokensForUser), userID)
+}
+
+// GetActiveUserCount mocks base method.
+func (m *MockStore) GetActiveUserCount(updatedSecondsAgo int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveUserCount", updatedSecondsAgo)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveUserCount indicates an expected call of GetActiveUserCount.
+func (mr *MockStoreMockRecorder) GetActiveUserCount(updatedSecondsAgo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveUserCount", reflect.TypeOf((*MockStore)(nil).GetActiveUserCount), updatedSecondsAgo)
+}
+
+// GetAllBlocks mocks base method.
+func (m *MockStore) GetAllBlocks(ctx context.Context, c store.Container) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllBlocks", ctx, c)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllBlocks indicates an expected call of GetAllBlocks.
+func (mr *MockStoreMockRecorder) GetAllBlocks(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllBlocks", reflect.TypeOf((*MockStore)(nil).GetAllBlocks), ctx, c)
+}
+
+// GetAppliedMigrations mocks base method.
+func (m *MockStore) GetAppliedMigrations(ctx context.Context) ([]model.Migration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppliedMigrations", ctx)
+	ret0, _ := ret[0].([]model.Migration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppliedMigrations indicates an expected call of GetAppliedMigrations.
+func (mr *MockStoreMockRecorder) GetAppliedMigrations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppliedMigrations", reflect.TypeOf((*MockStore)(nil).GetAppliedMigrations), ctx)
+}
+
+// GetAuditEntries mocks base method.
+func (m *MockStore) GetAuditEntries(opts model.QueryAuditEntriesOptions) ([]model.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries", opts)
+	ret0, _ := ret[0].([]model.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries.
+func (mr *MockStoreMockRecorder) GetAuditEntries(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*MockStore)(nil).GetAuditEntries), opts)
+}
+
+// GetBlock mocks base method.
+func (m *MockStore) GetBlock(ctx context.Context, c store.Container, blockID string) (*model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlock", ctx, c, blockID)
+	ret0, _ := ret[0].(*model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlock indicates an expected call of GetBlock.
+func (mr *MockStoreMockRecorder) GetBlock(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlock", reflect.TypeOf((*MockStore)(nil).GetBlock), ctx, c, blockID)
+}
+
+// GetBlockCountsByType mocks base method.
+func (m *MockStore) GetBlockCountsByType(ctx context.Context) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockCountsByType", ctx)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockCountsByType indicates an expected call of GetBlockCountsByType.
+func (mr *MockStoreMockRecorder) GetBlockCountsByType(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockCountsByType", reflect.TypeOf((*MockStore)(nil).GetBlockCountsByType), ctx)
+}
+
+// GetBlockHistory mocks base method.
+func (m *MockStore) GetBlockHistory(ctx context.Context, c store.Container, blockID string, opts model.QueryBlockHistoryOptions) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockHistory", ctx, c, blockID, opts)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockHistory indicates an expected call of GetBlockHistory.
+func (mr *MockStoreMockRecorder) GetBlockHistory(ctx, c, blockID, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockHistory", reflect.TypeOf((*MockStore)(nil).GetBlockHistory), ctx, c, blockID, opts)
+}
+
+// GetBlockWorkspaceIDs mocks base method.
+func (m *MockStore) GetBlockWorkspaceIDs(ctx context.Context, blockIDs []string) (map[string][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockWorkspaceIDs", ctx, blockIDs)
+	ret0, _ := ret[0].(map[string][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockWorkspaceIDs indicates an expected call of GetBlockWorkspaceIDs.
+func (mr *MockStoreMockRecorder) GetBlockWorkspaceIDs(ctx, blockIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockWorkspaceIDs", reflect.TypeOf((*MockStore)(nil).GetBlockWorkspaceIDs), ctx, blockIDs)
+}
+
+// GetBlocksByIDs mocks base method.
+func (m *MockStore) GetBlocksByIDs(ctx context.Context, c store.Container, blockIDs []string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksByIDs", ctx, c, blockIDs)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksByIDs indicates an expected call of GetBlocksByIDs.
+func (mr *MockStoreMockRecorder) GetBlocksByIDs(ctx, c, blockIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksByIDs", reflect.TypeOf((*MockStore)(nil).GetBlocksByIDs), ctx, c, blockIDs)
+}
+
+// GetBlocksDigest mocks base method.
+func (m *MockStore) GetBlocksDigest(ctx context.Context, c store.Container, opts model.QueryBlocksDigestOptions) (*model.BlocksDigest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksDigest", ctx, c, opts)
+	ret0, _ := ret[0].(*model.BlocksDigest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksDigest indicates an expected call of GetBlocksDigest.
+func (mr *MockStoreMockRecorder) GetBlocksDigest(ctx, c, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksDigest", reflect.TypeOf((*MockStore)(nil).GetBlocksDigest), ctx, c, opts)
+}
+
+// GetBlocksPage mocks base method.
+func (m *MockStore) GetBlocksPage(ctx context.Context, c store.Container, opts model.QueryBlocksPageOptions) (*model.BlocksPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksPage", ctx, c, opts)
+	ret0, _ := ret[0].(*model.BlocksPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksPage indicates an expected call of GetBlocksPage.
+func (mr *MockStoreMockRecorder) GetBlocksPage(ctx, c, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksPage", reflect.TypeOf((*MockStore)(nil).GetBlocksPage), ctx, c, opts)
+}
+
+// GetBlocksWithParent mocks base method.
+func (m *MockStore) GetBlocksWithParent(ctx context.Context, c store.Container, parentID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksWithParent", ctx, c, parentID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksWithParent indicates an expected call of GetBlocksWithParent.
+func (mr *MockStoreMockRecorder) GetBlocksWithParent(ctx, c, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithParent", reflect.TypeOf((*MockStore)(nil).GetBlocksWithParent), ctx, c, parentID)
+}
+
+// GetBlocksWithParentAndType mocks base method.
+func (m *MockStore) GetBlocksWithParentAndType(ctx context.Context, c store.Container, parentID, blockType string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksWithParentAndType", ctx, c, parentID, blockType)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksWithParentAndType indicates an expected call of GetBlocksWithParentAndType.
+func (mr *MockStoreMockRecorder) GetBlocksWithParentAndType(ctx, c, parentID, blockType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithParentAndType", reflect.TypeOf((*MockStore)(nil).GetBlocksWithParentAndType), ctx, c, parentID, blockType)
+}
+
+// GetBlocksWithRootID mocks base method.
+func (m *MockStore) GetBlocksWithRootID(ctx context.Context, c store.Container, rootID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksWithRootID", ctx, c, rootID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksWithRootID indicates an expected call of GetBlocksWithRootID.
+func (mr *MockStoreMockRecorder) GetBlocksWithRootID(ctx, c, rootID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithRootID", reflect.TypeOf((*MockStore)(nil).GetBlocksWithRootID), ctx, c, rootID)
+}
+
+// GetBlocksWithType mocks base method.
+func (m *MockStore) GetBlocksWithType(ctx context.Context, c store.Container, blockType string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksWithType", ctx, c, blockType)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksWithType indicates an expected call of GetBlocksWithType.
+func (mr *MockStoreMockRecorder) GetBlocksWithType(ctx, c, blockType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithType", reflect.TypeOf((*MockStore)(nil).GetBlocksWithType), ctx, c, blockType)
+}
+
+// GetBoardMembers mocks base method.
+func (m *MockStore) GetBoardMembers(c store.Container, boardID string) ([]model.BoardMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardMembers", c, boardID)
+	ret0, _ := ret[0].([]model.BoardMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardMembers indicates an expected call of GetBoardMembers.
+func (mr *MockStoreMockRecorder) GetBoardMembers(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardMembers", reflect.TypeOf((*MockStore)(nil).GetBoardMembers), c, boardID)
+}
+
+// GetBoardMetadata mocks base method.
+func (m *MockStore) GetBoardMetadata(ctx context.Context, c store.Container, boardID string) ([]model.CardMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardMetadata", ctx, c, boardID)
+	ret0, _ := ret[0].([]model.CardMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardMetadata indicates an expected call of GetBoardMetadata.
+func (mr *MockStoreMockRecorder) GetBoardMetadata(ctx, c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardMetadata", reflect.TypeOf((*MockStore)(nil).GetBoardMetadata), ctx, c, boardID)
+}
+
+// GetBoardRolesForUser mocks base method.
+func (m *MockStore) GetBoardRolesForUser(c store.Container, userID string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardRolesForUser", c, userID)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardRolesForUser indicates an expected call of GetBoardRolesForUser.
+func (mr *MockStoreMockRecorder) GetBoardRolesForUser(c, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardRolesForUser", reflect.TypeOf((*MockStore)(nil).GetBoardRolesForUser), c, userID)
+}
+
+// GetBoardSubscriptions mocks base method.
+func (m *MockStore) GetBoardSubscriptions(ctx context.Context) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardSubscriptions", ctx)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardSubscriptions indicates an expected call of GetBoardSubscriptions.
+func (mr *MockStoreMockRecorder) GetBoardSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardSubscriptions", reflect.TypeOf((*MockStore)(nil).GetBoardSubscriptions), ctx)
+}
+
+// GetBoardWorkspaceIDs mocks base method.
+func (m *MockStore) GetBoardWorkspaceIDs() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardWorkspaceIDs")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardWorkspaceIDs indicates an expected call of GetBoardWorkspaceIDs.
+func (mr *MockStoreMockRecorder) GetBoardWorkspaceIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardWorkspaceIDs", reflect.TypeOf((*MockStore)(nil).GetBoardWorkspaceIDs))
+}
+
+// GetCalendarFeed mocks base method.
+func (m *MockStore) GetCalendarFeed(c store.Container, boardID string) (*model.CalendarFeed, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalendarFeed", c, boardID)
+	ret0, _ := ret[0].(*model.CalendarFeed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCalendarFeed indicates an expected call of GetCalendarFeed.
+func (mr *MockStoreMockRecorder) GetCalendarFeed(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalendarFeed", reflect.TypeOf((*MockStore)(nil).GetCalendarFeed), c, boardID)
+}
+
+// GetCardActivity mocks base method.
+func (m *MockStore) GetCardActivity(ctx context.Context, c store.Container, cardID string, limit int, before int64) ([]model.BlockHistoryEntry, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardActivity", ctx, c, cardID, limit, before)
+	ret0, _ := ret[0].([]model.BlockHistoryEntry)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCardActivity indicates an expected call of GetCardActivity.
+func (mr *MockStoreMockRecorder) GetCardActivity(ctx, c, cardID, limit, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardActivity", reflect.TypeOf((*MockStore)(nil).GetCardActivity), ctx, c, cardID, limit, before)
+}
+
+// GetCardBacklinks mocks base method.
+func (m *MockStore) GetCardBacklinks(ctx context.Context, c store.Container, cardID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardBacklinks", ctx, c, cardID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardBacklinks indicates an expected call of GetCardBacklinks.
+func (mr *MockStoreMockRecorder) GetCardBacklinks(ctx, c, cardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardBacklinks", reflect.TypeOf((*MockStore)(nil).GetCardBacklinks), ctx, c, cardID)
+}
+
+// GetCardByNumber mocks base method.
+func (m *MockStore) GetCardByNumber(ctx context.Context, c store.Container, boardID string, number int64) (*model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardByNumber", ctx, c, boardID, number)
+	ret0, _ := ret[0].(*model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardByNumber indicates an expected call of GetCardByNumber.
+func (mr *MockStoreMockRecorder) GetCardByNumber(ctx, c, boardID, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardByNumber", reflect.TypeOf((*MockStore)(nil).GetCardByNumber), ctx, c, boardID, number)
+}
+
+// GetChecklistProgress mocks base method.
+func (m *MockStore) GetChecklistProgress(ctx context.Context, c store.Container, boardID string) ([]model.ChecklistProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChecklistProgress", ctx, c, boardID)
+	ret0, _ := ret[0].([]model.ChecklistProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChecklistProgress indicates an expected call of GetChecklistProgress.
+func (mr *MockStoreMockRecorder) GetChecklistProgress(ctx, c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChecklistProgress", reflect.TypeOf((*MockStore)(nil).GetChecklistProgress), ctx, c, boardID)
+}
+
+// GetDBStats mocks base method.
+func (m *MockStore) GetDBStats() sql.DBStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDBStats")
+	ret0, _ := ret[0].(sql.DBStats)
+	return ret0
+}
+
+// GetDBStats indicates an expected call of GetDBStats.
+func (mr *MockStoreMockRecorder) GetDBStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDBStats", reflect.TypeOf((*MockStore)(nil).GetDBStats))
+}
+
+// GetDeletedBlocks mocks base method.
+func (m *MockStore) GetDeletedBlocks(ctx context.Context, c store.Container, since int64) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedBlocks", ctx, c, since)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedBlocks indicates an expected call of GetDeletedBlocks.
+func (mr *MockStoreMockRecorder) GetDeletedBlocks(ctx, c, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedBlocks", reflect.TypeOf((*MockStore)(nil).GetDeletedBlocks), ctx, c, since)
+}
+
+// GetFileInfo mocks base method.
+func (m *MockStore) GetFileInfo(fileID string) (*model.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileInfo", fileID)
+	ret0, _ := ret[0].(*model.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileInfo indicates an expected call of GetFileInfo.
+func (mr *MockStoreMockRecorder) GetFileInfo(fileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileInfo", reflect.TypeOf((*MockStore)(nil).GetFileInfo), fileID)
+}
+
+// GetFilteredBoardBlocks mocks base method.
+func (m *MockStore) GetFilteredBoardBlocks(ctx context.Context, c store.Container, boardID string, filter *model.FilterGroup) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilteredBoardBlocks", ctx, c, boardID, filter)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilteredBoardBlocks indicates an expected call of GetFilteredBoardBlocks.
+func (mr *MockStoreMockRecorder) GetFilteredBoardBlocks(ctx, c, boardID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilteredBoardBlocks", reflect.TypeOf((*MockStore)(nil).GetFilteredBoardBlocks), ctx, c, boardID, filter)
+}
+
+// GetGitHubIntegration mocks base method.
+func (m *MockStore) GetGitHubIntegration(c store.Container, boardID string) (*model.GitHubIntegration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitHubIntegration", c, boardID)
+	ret0, _ := ret[0].(*model.GitHubIntegration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitHubIntegration indicates an expected call of GetGitHubIntegration.
+func (mr *MockStoreMockRecorder) GetGitHubIntegration(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitHubIntegration", reflect.TypeOf((*MockStore)(nil).GetGitHubIntegration), c, boardID)
+}
+
+// GetGitHubIntegrations mocks base method.
+func (m *MockStore) GetGitHubIntegrations() ([]model.GitHubIntegration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitHubIntegrations")
+	ret0, _ := ret[0].([]model.GitHubIntegration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitHubIntegrations indicates an expected call of GetGitHubIntegrations.
+func (mr *MockStoreMockRecorder) GetGitHubIntegrations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitHubIntegrations", reflect.TypeOf((*MockStore)(nil).GetGitHubIntegrations))
+}
+
+// GetGitHubIssueLinks mocks base method.
+func (m *MockStore) GetGitHubIssueLinks(c store.Container, boardID string) ([]model.GitHubIssueLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitHubIssueLinks", c, boardID)
+	ret0, _ := ret[0].([]model.GitHubIssueLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitHubIssueLinks indicates an expected call of GetGitHubIssueLinks.
+func (mr *MockStoreMockRecorder) GetGitHubIssueLinks(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitHubIssueLinks", reflect.TypeOf((*MockStore)(nil).GetGitHubIssueLinks), c, boardID)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(userID, key string) (*model.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", userID, key)
+	ret0, _ := ret[0].(*model.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), userID, key)
+}
+
+// GetInactiveWorkspaces mocks base method.
+func (m *MockStore) GetInactiveWorkspaces(ctx context.Context, inactiveSince int64) ([]model.InactiveWorkspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInactiveWorkspaces", ctx, inactiveSince)
+	ret0, _ := ret[0].([]model.InactiveWorkspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInactiveWorkspaces indicates an expected call of GetInactiveWorkspaces.
+func (mr *MockStoreMockRecorder) GetInactiveWorkspaces(ctx, inactiveSince interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInactiveWorkspaces", reflect.TypeOf((*MockStore)(nil).GetInactiveWorkspaces), ctx, inactiveSince)
+}
+
+// GetInboundHook mocks base method.
+func (m *MockStore) GetInboundHook(c store.Container, boardID string) (*model.InboundHook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInboundHook", c, boardID)
+	ret0, _ := ret[0].(*model.InboundHook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInboundHook indicates an expected call of GetInboundHook.
+func (mr *MockStoreMockRecorder) GetInboundHook(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInboundHook", reflect.TypeOf((*MockStore)(nil).GetInboundHook), c, boardID)
+}
+
+// GetInboundHookByTokenHash mocks base method.
+func (m *MockStore) GetInboundHookByTokenHash(tokenHash string) (*model.InboundHook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInboundHookByTokenHash", tokenHash)
+	ret0, _ := ret[0].(*model.InboundHook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInboundHookByTokenHash indicates an expected call of GetInboundHookByTokenHash.
+func (mr *MockStoreMockRecorder) GetInboundHookByTokenHash(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInboundHookByTokenHash", reflect.TypeOf((*MockStore)(nil).GetInboundHookByTokenHash), tokenHash)
+}
+
+// GetInboundHookDeliveries mocks base method.
+func (m *MockStore) GetInboundHookDeliveries(c store.Container, boardID string, limit int) ([]model.InboundHookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInboundHookDeliveries", c, boardID, limit)
+	ret0, _ := ret[0].([]model.InboundHookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInboundHookDeliveries indicates an expected call of GetInboundHookDeliveries.
+func (mr *MockStoreMockRecorder) GetInboundHookDeliveries(c, boardID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInboundHookDeliveries", reflect.TypeOf((*MockStore)(nil).GetInboundHookDeliveries), c, boardID, limit)
+}
+
+// GetLastDigestAt mocks base method.
+func (m *MockStore) GetLastDigestAt(ctx context.Context, userID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastDigestAt", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastDigestAt indicates an expected call of GetLastDigestAt.
+func (mr *MockStoreMockRecorder) GetLastDigestAt(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastDigestAt", reflect.TypeOf((*MockStore)(nil).GetLastDigestAt), ctx, userID)
+}
+
+// GetLastRecurrenceRun mocks base method.
+func (m *MockStore) GetLastRecurrenceRun(ctx context.Context, recurrenceID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastRecurrenceRun", ctx, recurrenceID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastRecurrenceRun indicates an expected call of GetLastRecurrenceRun.
+func (mr *MockStoreMockRecorder) GetLastRecurrenceRun(ctx, recurrenceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastRecurrenceRun", reflect.TypeOf((*MockStore)(nil).GetLastRecurrenceRun), ctx, recurrenceID)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockStore) GetLoginAttempts(key string) (*model.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", key)
+	ret0, _ := ret[0].(*model.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockStoreMockRecorder) GetLoginAttempts(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockStore)(nil).GetLoginAttempts), key)
+}
+
+// GetNotificationsForUser mocks base method.
+func (m *MockStore) GetNotificationsForUser(userID string, limit int) ([]model.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationsForUser", userID, limit)
+	ret0, _ := ret[0].([]model.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationsForUser indicates an expected call of GetNotificationsForUser.
+func (mr *MockStoreMockRecorder) GetNotificationsForUser(userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationsForUser", reflect.TypeOf((*MockStore)(nil).GetNotificationsForUser), userID, limit)
+}
+
+// GetNotifiedUserIDs mocks base method.
+func (m *MockStore) GetNotifiedUserIDs(blockID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifiedUserIDs", blockID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifiedUserIDs indicates an expected call of GetNotifiedUserIDs.
+func (mr *MockStoreMockRecorder) GetNotifiedUserIDs(blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifiedUserIDs", reflect.TypeOf((*MockStore)(nil).GetNotifiedUserIDs), blockID)
+}
+
+// GetParentID mocks base method.
+func (m *MockStore) GetParentID(ctx context.Context, c store.Container, blockID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParentID", ctx, c, blockID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParentID indicates an expected call of GetParentID.
+func (mr *MockStoreMockRecorder) GetParentID(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParentID", reflect.TypeOf((*MockStore)(nil).GetParentID), ctx, c, blockID)
+}
+
+// GetPendingMigrations mocks base method.
+func (m *MockStore) GetPendingMigrations(ctx context.Context) ([]model.Migration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingMigrations", ctx)
+	ret0, _ := ret[0].([]model.Migration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingMigrations indicates an expected call of GetPendingMigrations.
+func (mr *MockStoreMockRecorder) GetPendingMigrations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingMigrations", reflect.TypeOf((*MockStore)(nil).GetPendingMigrations), ctx)
+}
+
+// GetPreferences mocks base method.
+func (m *MockStore) GetPreferences(ctx context.Context, userID string) ([]model.Preference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, userID)
+	ret0, _ := ret[0].([]model.Preference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockStoreMockRecorder) GetPreferences(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockStore)(nil).GetPreferences), ctx, userID)
+}
+
+// GetReferencedFileIDs mocks base method.
+func (m *MockStore) GetReferencedFileIDs(ctx context.Context, c store.Container) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferencedFileIDs", ctx, c)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferencedFileIDs indicates an expected call of GetReferencedFileIDs.
+func (mr *MockStoreMockRecorder) GetReferencedFileIDs(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferencedFileIDs", reflect.TypeOf((*MockStore)(nil).GetReferencedFileIDs), ctx, c)
+}
+
+// GetRegisteredUserCount mocks base method.
+func (m *MockStore) GetRegisteredUserCount() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRegisteredUserCount")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRegisteredUserCount indicates an expected call of GetRegisteredUserCount.
+func (mr *MockStoreMockRecorder) GetRegisteredUserCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegisteredUserCount", reflect.TypeOf((*MockStore)(nil).GetRegisteredUserCount))
+}
+
+// GetRootID mocks base method.
+func (m *MockStore) GetRootID(ctx context.Context, c store.Container, blockID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRootID", ctx, c, blockID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRootID indicates an expected call of GetRootID.
+func (mr *MockStoreMockRecorder) GetRootID(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRootID", reflect.TypeOf((*MockStore)(nil).GetRootID), ctx, c, blockID)
+}
+
+// GetSchemaVersion mocks base method.
+func (m *MockStore) GetSchemaVersion(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemaVersion", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchemaVersion indicates an expected call of GetSchemaVersion.
+func (mr *MockStoreMockRecorder) GetSchemaVersion(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemaVersion", reflect.TypeOf((*MockStore)(nil).GetSchemaVersion), ctx)
+}
+
+// GetSentReminderUserIDs mocks base method.
+func (m *MockStore) GetSentReminderUserIDs(cardID string, dueAt int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSentReminderUserIDs", cardID, dueAt)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSentReminderUserIDs indicates an expected call of GetSentReminderUserIDs.
+func (mr *MockStoreMockRecorder) GetSentReminderUserIDs(cardID, dueAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSentReminderUserIDs", reflect.TypeOf((*MockStore)(nil).GetSentReminderUserIDs), cardID, dueAt)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(token string) (*model.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", token)
+	ret0, _ := ret[0].(*model.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), token)
+}
+
+// GetSharing mocks base method.
+func (m *MockStore) GetSharing(c store.Container, rootID string) (*model.Sharing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharing", c, rootID)
+	ret0, _ := ret[0].(*model.Sharing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharing indicates an expected call of GetSharing.
+func (mr *MockStoreMockRecorder) GetSharing(c, rootID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharing", reflect.TypeOf((*MockStore)(nil).GetSharing), c, rootID)
+}
+
+// GetSharingTokens mocks base method.
+func (m *MockStore) GetSharingTokens(c store.Container, rootID string) ([]model.SharingToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharingTokens", c, rootID)
+	ret0, _ := ret[0].([]model.SharingToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharingTokens indicates an expected call of GetSharingTokens.
+func (mr *MockStoreMockRecorder) GetSharingTokens(c, rootID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharingTokens", reflect.TypeOf((*MockStore)(nil).GetSharingTokens), c, rootID)
+}
+
+// GetSubTree2 mocks base method.
+func (m *MockStore) GetSubTree2(ctx context.Context, c store.Container, blockID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubTree2", ctx, c, blockID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubTree2 indicates an expected call of GetSubTree2.
+func (mr *MockStoreMockRecorder) GetSubTree2(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubTree2", reflect.TypeOf((*MockStore)(nil).GetSubTree2), ctx, c, blockID)
+}
+
+// GetSubTree3 mocks base method.
+func (m *MockStore) GetSubTree3(ctx context.Context, c store.Container, blockID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubTree3", ctx, c, blockID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubTree3 indicates an expected call of GetSubTree3.
+func (mr *MockStoreMockRecorder) GetSubTree3(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubTree3", reflect.TypeOf((*MockStore)(nil).GetSubTree3), ctx, c, blockID)
+}
+
+// GetSubscriptions mocks base method.
+func (m *MockStore) GetSubscriptions(ctx context.Context, c store.Container, blockIDs []string) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptions", ctx, c, blockIDs)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptions indicates an expected call of GetSubscriptions.
+func (mr *MockStoreMockRecorder) GetSubscriptions(ctx, c, blockIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptions", reflect.TypeOf((*MockStore)(nil).GetSubscriptions), ctx, c, blockIDs)
+}
+
+// GetSubscriptionsForUser mocks base method.
+func (m *MockStore) GetSubscriptionsForUser(ctx context.Context, c store.Container, subscriberID string) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionsForUser", ctx, c, subscriberID)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionsForUser indicates an expected call of GetSubscriptionsForUser.
+func (mr *MockStoreMockRecorder) GetSubscriptionsForUser(ctx, c, subscriberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionsForUser", reflect.TypeOf((*MockStore)(nil).GetSubscriptionsForUser), ctx, c, subscriberID)
+}
+
+// GetSystemSettings mocks base method.
+func (m *MockStore) GetSystemSettings() (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemSettings")
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemSettings indicates an expected call of GetSystemSettings.
+func (mr *MockStoreMockRecorder) GetSystemSettings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemSettings", reflect.TypeOf((*MockStore)(nil).GetSystemSettings))
+}
+
+// GetTemplateBoards mocks base method.
+func (m *MockStore) GetTemplateBoards(ctx context.Context, c store.Container) ([]model.BoardTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateBoards", ctx, c)
+	ret0, _ := ret[0].([]model.BoardTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateBoards indicates an expected call of GetTemplateBoards.
+func (mr *MockStoreMockRecorder) GetTemplateBoards(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateBoards", reflect.TypeOf((*MockStore)(nil).GetTemplateBoards), ctx, c)
+}
+
+// GetUserByAuthData mocks base method.
+func (m *MockStore) GetUserByAuthData(authData string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByAuthData", authData)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByAuthData indicates an expected call of GetUserByAuthData.
+func (mr *MockStoreMockRecorder) GetUserByAuthData(authData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByAuthData", reflect.TypeOf((*MockStore)(nil).GetUserByAuthData), authData)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStore) GetUserByEmail(email string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", email)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStoreMockRecorder) GetUserByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), email)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStore) GetUserByID(userID string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", userID)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStoreMockRecorder) GetUserByID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStore)(nil).GetUserByID), userID)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockStore) GetUserByUsername(username string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", username)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockStoreMockRecorder) GetUserByUsername(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockStore)(nil).GetUserByUsername), username)
+}
+
+// GetUserWorkspaces mocks base method.
+func (m *MockStore) GetUserWorkspaces(ctx context.Context, userID, cursor string, limit int) ([]model.UserWorkspace, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserWorkspaces", ctx, userID, cursor, limit)
+	ret0, _ := ret[0].([]model.UserWorkspace)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserWorkspaces indicates an expected call of GetUserWorkspaces.
+func (mr *MockStoreMockRecorder) GetUserWorkspaces(ctx, userID, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWorkspaces", reflect.TypeOf((*MockStore)(nil).GetUserWorkspaces), ctx, userID, cursor, limit)
+}
+
+// GetUsers mocks base method.
+func (m *MockStore) GetUsers(opts model.QueryUsersOptions) ([]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", opts)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockStoreMockRecorder) GetUsers(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockStore)(nil).GetUsers), opts)
+}
+
+// GetUsersByWorkspace mocks base method.
+func (m *MockStore) GetUsersByWorkspace(workspaceID string) ([]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByWorkspace", workspaceID)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByWorkspace indicates an expected call of GetUsersByWorkspace.
+func (mr *MockStoreMockRecorder) GetUsersByWorkspace(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByWorkspace", reflect.TypeOf((*MockStore)(nil).GetUsersByWorkspace), workspaceID)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockStore) GetWebhookDeliveries(workspaceID string, limit int) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", workspaceID, limit)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockStoreMockRecorder) GetWebhookDeliveries(workspaceID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockStore)(nil).GetWebhookDeliveries), workspaceID, limit)
+}
+
+// GetWorkspace mocks base method.
+func (m *MockStore) GetWorkspace(ctx context.Context, ID string) (*model.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspace", ctx, ID)
+	ret0, _ := ret[0].(*model.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspace indicates an expected call of GetWorkspace.
+func (mr *MockStoreMockRecorder) GetWorkspace(ctx, ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspace", reflect.TypeOf((*MockStore)(nil).GetWorkspace), ctx, ID)
+}
+
+// GetWorkspaceCount mocks base method.
+func (m *MockStore) GetWorkspaceCount(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceCount", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceCount indicates an expected call of GetWorkspaceCount.
+func (mr *MockStoreMockRecorder) GetWorkspaceCount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceCount", reflect.TypeOf((*MockStore)(nil).GetWorkspaceCount), ctx)
+}
+
+// GetWorkspaceRetentionNotices mocks base method.
+func (m *MockStore) GetWorkspaceRetentionNotices(ctx context.Context) ([]model.WorkspaceRetentionNotice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceRetentionNotices", ctx)
+	ret0, _ := ret[0].([]model.WorkspaceRetentionNotice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceRetentionNotices indicates an expected call of GetWorkspaceRetentionNotices.
+func (mr *MockStoreMockRecorder) GetWorkspaceRetentionNotices(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceRetentionNotices", reflect.TypeOf((*MockStore)(nil).GetWorkspaceRetentionNotices), ctx)
+}
+
+// GetWorkspaceStats mocks base method.
+func (m *MockStore) GetWorkspaceStats(ctx context.Context, now int64) (*model.WorkspaceStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceStats", ctx, now)
+	ret0, _ := ret[0].(*model.WorkspaceStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceStats indicates an expected call of GetWorkspaceStats.
+func (mr *MockStoreMockRecorder) GetWorkspaceStats(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceStats", reflect.TypeOf((*MockStore)(nil).GetWorkspaceStats), ctx, now)
+}
+
+// GetWorkspaceUsage mocks base method.
+func (m *MockStore) GetWorkspaceUsage(workspaceID string) (*model.WorkspaceUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceUsage", workspaceID)
+	ret0, _ := ret[0].(*model.WorkspaceUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceUsage indicates an expected call of GetWorkspaceUsage.
+func (mr *MockStoreMockRecorder) GetWorkspaceUsage(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceUsage", reflect.TypeOf((*MockStore)(nil).GetWorkspaceUsage), workspaceID)
+}
+
+// GetWorkspaces mocks base method.
+func (m *MockStore) GetWorkspaces(ctx context.Context, modifiedSince int64, limit int) ([]*model.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaces", ctx, modifiedSince, limit)
+	ret0, _ := ret[0].([]*model.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaces indicates an expected call of GetWorkspaces.
+func (mr *MockStoreMockRecorder) GetWorkspaces(ctx, modifiedSince, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaces", reflect.TypeOf((*MockStore)(nil).GetWorkspaces), ctx, modifiedSince, limit)
+}
+
+// HasWorkspaceAccess mocks base method.
+func (m *MockStore) HasWorkspaceAccess(ctx context.Context, userID, workspaceID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasWorkspaceAccess", ctx, userID, workspaceID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasWorkspaceAccess indicates an expected call of HasWorkspaceAccess.
+func (mr *MockStoreMockRecorder) HasWorkspaceAccess(ctx, userID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasWorkspaceAccess", reflect.TypeOf((*MockStore)(nil).HasWorkspaceAccess), ctx, userID, workspaceID)
+}
+
+// InsertAuditEntry mocks base method.
+func (m *MockStore) InsertAuditEntry(entry model.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAuditEntry", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertAuditEntry indicates an expected call of InsertAuditEntry.
+func (mr *MockStoreMockRecorder) InsertAuditEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuditEntry", reflect.TypeOf((*MockStore)(nil).InsertAuditEntry), entry)
+}
+
+// InsertBlock mocks base method.
+func (m *MockStore) InsertBlock(ctx context.Context, c store.Container, block *model.Block, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBlock", ctx, c, block, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertBlock indicates an expected call of InsertBlock.
+func (mr *MockStoreMockRecorder) InsertBlock(ctx, c, block, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlock", reflect.TypeOf((*MockStore)(nil).InsertBlock), ctx, c, block, userID)
+}
+
+// InsertBlocks mocks base method.
+func (m *MockStore) InsertBlocks(ctx context.Context, c store.Container, blocks []model.Block, userID string) (*model.BlocksUpsertResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBlocks", ctx, c, blocks, userID)
+	ret0, _ := ret[0].(*model.BlocksUpsertResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertBlocks indicates an expected call of InsertBlocks.
+func (mr *MockStoreMockRecorder) InsertBlocks(ctx, c, blocks, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlocks", reflect.TypeOf((*MockStore)(nil).InsertBlocks), ctx, c, blocks, userID)
+}
+
+// InsertFileInfo mocks base method.
+func (m *MockStore) InsertFileInfo(info model.FileInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertFileInfo", info)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertFileInfo indicates an expected call of InsertFileInfo.
+func (mr *MockStoreMockRecorder) InsertFileInfo(info interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertFileInfo", reflect.TypeOf((*MockStore)(nil).InsertFileInfo), info)
+}
+
+// InsertInboundHookDelivery mocks base method.
+func (m *MockStore) InsertInboundHookDelivery(c store.Container, delivery model.InboundHookDelivery, keep int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertInboundHookDelivery", c, delivery, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertInboundHookDelivery indicates an expected call of InsertInboundHookDelivery.
+func (mr *MockStoreMockRecorder) InsertInboundHookDelivery(c, delivery, keep interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertInboundHookDelivery", reflect.TypeOf((*MockStore)(nil).InsertInboundHookDelivery), c, delivery, keep)
+}
+
+// InsertNotification mocks base method.
+func (m *MockStore) InsertNotification(notification model.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertNotification", notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertNotification indicates an expected call of InsertNotification.
+func (mr *MockStoreMockRecorder) InsertNotification(notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNotification", reflect.TypeOf((*MockStore)(nil).InsertNotification), notification)
+}
+
+// InsertRecurrenceRun mocks base method.
+func (m *MockStore) InsertRecurrenceRun(ctx context.Context, run model.RecurrenceRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRecurrenceRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertRecurrenceRun indicates an expected call of InsertRecurrenceRun.
+func (mr *MockStoreMockRecorder) InsertRecurrenceRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRecurrenceRun", reflect.TypeOf((*MockStore)(nil).InsertRecurrenceRun), ctx, run)
+}
+
+// InsertReminderSent mocks base method.
+func (m *MockStore) InsertReminderSent(reminder model.ReminderSent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReminderSent", reminder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertReminderSent indicates an expected call of InsertReminderSent.
+func (mr *MockStoreMockRecorder) InsertReminderSent(reminder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReminderSent", reflect.TypeOf((*MockStore)(nil).InsertReminderSent), reminder)
+}
+
+// InsertWebhookDelivery mocks base method.
+func (m *MockStore) InsertWebhookDelivery(delivery model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWebhookDelivery", delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWebhookDelivery indicates an expected call of InsertWebhookDelivery.
+func (mr *MockStoreMockRecorder) InsertWebhookDelivery(delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWebhookDelivery", reflect.TypeOf((*MockStore)(nil).InsertWebhookDelivery), delivery)
+}
+
+// PatchBlock mocks base method.
+func (m *MockStore) PatchBlock(ctx context.Context, c store.Container, blockID string, blockPatch *model.BlockPatch, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchBlock", ctx, c, blockID, blockPatch, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchBlock indicates an expected call of PatchBlock.
+func (mr *MockStoreMockRecorder) PatchBlock(ctx, c, blockID, blockPatch, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchBlock", reflect.TypeOf((*MockStore)(nil).PatchBlock), ctx, c, blockID, blockPatch, userID)
+}
+
+// Ping mocks base method.
+func (m *MockStore) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), ctx)
+}
+
+// PurgeDeletedBlocks mocks base method.
+func (m *MockStore) PurgeDeletedBlocks(ctx context.Context, deletedBefore int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedBlocks", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedBlocks indicates an expected call of PurgeDeletedBlocks.
+func (mr *MockStoreMockRecorder) PurgeDeletedBlocks(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedBlocks", reflect.TypeOf((*MockStore)(nil).PurgeDeletedBlocks), ctx, deletedBefore)
+}
+
+// RefreshSession mocks base method.
+func (m *MockStore) RefreshSession(session *model.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshSession indicates an expected call of RefreshSession.
+func (mr *MockStoreMockRecorder) RefreshSession(session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockStore)(nil).RefreshSession), session)
+}
+
+// RegenerateSharingToken mocks base method.
+func (m *MockStore) RegenerateSharingToken(c store.Container, token model.SharingToken, previousToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateSharingToken", c, token, previousToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegenerateSharingToken indicates an expected call of RegenerateSharingToken.
+func (mr *MockStoreMockRecorder) RegenerateSharingToken(c, token, previousToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateSharingToken", reflect.TypeOf((*MockStore)(nil).RegenerateSharingToken), c, token, previousToken)
+}
+
+// RemoveWorkspaceMember mocks base method.
+func (m *MockStore) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWorkspaceMember", ctx, workspaceID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWorkspaceMember indicates an expected call of RemoveWorkspaceMember.
+func (mr *MockStoreMockRecorder) RemoveWorkspaceMember(ctx, workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWorkspaceMember", reflect.TypeOf((*MockStore)(nil).RemoveWorkspaceMember), ctx, workspaceID, userID)
+}
+
+// RestoreBlock mocks base method.
+func (m *MockStore) RestoreBlock(ctx context.Context, c store.Container, blockID, modifiedBy string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreBlock", ctx, c, blockID, modifiedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreBlock indicates an expected call of RestoreBlock.
+func (mr *MockStoreMockRecorder) RestoreBlock(ctx, c, blockID, modifiedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreBlock", reflect.TypeOf((*MockStore)(nil).RestoreBlock), ctx, c, blockID, modifiedBy)
+}
+
+// RevokeSharingToken mocks base method.
+func (m *MockStore) RevokeSharingToken(c store.Container, rootID, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSharingToken", c, rootID, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSharingToken indicates an expected call of RevokeSharingToken.
+func (mr *MockStoreMockRecorder) RevokeSharingToken(c, rootID, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSharingToken", reflect.TypeOf((*MockStore)(nil).RevokeSharingToken), c, rootID, token)
+}
+
+// SearchBlocks mocks base method.
+func (m *MockStore) SearchBlocks(ctx context.Context, c store.Container, query string, limit int) ([]model.BlockSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchBlocks", ctx, c, query, limit)
+	ret0, _ := ret[0].([]model.BlockSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchBlocks indicates an expected call of SearchBlocks.
+func (mr *MockStoreMockRecorder) SearchBlocks(ctx, c, query, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchBlocks", reflect.TypeOf((*MockStore)(nil).SearchBlocks), ctx, c, query, limit)
+}
+
+// SearchUsersByWorkspace mocks base method.
+func (m *MockStore) SearchUsersByWorkspace(ctx context.Context, workspaceID, prefix string, limit int) ([]model.UserSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsersByWorkspace", ctx, workspaceID, prefix, limit)
+	ret0, _ := ret[0].([]model.UserSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsersByWorkspace indicates an expected call of SearchUsersByWorkspace.
+func (mr *MockStoreMockRecorder) SearchUsersByWorkspace(ctx, workspaceID, prefix, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsersByWorkspace", reflect.TypeOf((*MockStore)(nil).SearchUsersByWorkspace), ctx, workspaceID, prefix, limit)
+}
+
+// SetFileThumbnailPath mocks base method.
+func (m *MockStore) SetFileThumbnailPath(fileID, thumbnailPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFileThumbnailPath", fileID, thumbnailPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFileThumbnailPath indicates an expected call of SetFileThumbnailPath.
+func (mr *MockStoreMockRecorder) SetFileThumbnailPath(fileID, thumbnailPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFileThumbnailPath", reflect.TypeOf((*MockStore)(nil).SetFileThumbnailPath), fileID, thumbnailPath)
+}
+
+// SetIdempotencyKeyResponse mocks base method.
+func (m *MockStore) SetIdempotencyKeyResponse(userID, key string, statusCode int, response string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetIdempotencyKeyResponse", userID, key, statusCode, response)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetIdempotencyKeyResponse indicates an expected call of SetIdempotencyKeyResponse.
+func (mr *MockStoreMockRecorder) SetIdempotencyKeyResponse(userID, key, statusCode, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIdempotencyKeyResponse", reflect.TypeOf((*MockStore)(nil).SetIdempotencyKeyResponse), userID, key, statusCode, response)
+}
+
+// SetLegacySessionsExpireAt mocks base method.
+func (m *MockStore) SetLegacySessionsExpireAt(expireAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLegacySessionsExpireAt", expireAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLegacySessionsExpireAt indicates an expected call of SetLegacySessionsExpireAt.
+func (mr *MockStoreMockRecorder) SetLegacySessionsExpireAt(expireAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLegacySessionsExpireAt", reflect.TypeOf((*MockStore)(nil).SetLegacySessionsExpireAt), expireAt)
+}
+
+// SetMfaRecoveryCodes mocks base method.
+func (m *MockStore) SetMfaRecoveryCodes(userID string, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMfaRecoveryCodes", userID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMfaRecoveryCodes indicates an expected call of SetMfaRecoveryCodes.
+func (mr *MockStoreMockRecorder) SetMfaRecoveryCodes(userID, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMfaRecoveryCodes", reflect.TypeOf((*MockStore)(nil).SetMfaRecoveryCodes), userID, codeHashes)
+}
+
+// SetSystemSetting mocks base method.
+func (m *MockStore) SetSystemSetting(key, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSystemSetting", key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSystemSetting indicates an expected call of SetSystemSetting.
+func (mr *MockStoreMockRecorder) SetSystemSetting(key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSystemSetting", reflect.TypeOf((*MockStore)(nil).SetSystemSetting), key, value)
+}
+
+// SharesWorkspace mocks base method.
+func (m *MockStore) SharesWorkspace(ctx context.Context, userID, otherUserID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SharesWorkspace", ctx, userID, otherUserID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SharesWorkspace indicates an expected call of SharesWorkspace.
+func (mr *MockStoreMockRecorder) SharesWorkspace(ctx, userID, otherUserID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SharesWorkspace", reflect.TypeOf((*MockStore)(nil).SharesWorkspace), ctx, userID, otherUserID)
+}
+
+// Shutdown mocks base method.
+func (m *MockStore) Shutdown() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockStoreMockRecorder) Shutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockStore)(nil).Shutdown))
+}
+
+// StreamAllBlocks mocks base method.
+func (m *MockStore) StreamAllBlocks(ctx context.Context, c store.Container, fn func(model.Block) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamAllBlocks", ctx, c, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamAllBlocks indicates an expected call of StreamAllBlocks.
+func (mr *MockStoreMockRecorder) StreamAllBlocks(ctx, c, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamAllBlocks", reflect.TypeOf((*MockStore)(nil).StreamAllBlocks), ctx, c, fn)
+}
+
+// StreamBlocksWithParentAndType mocks base method.
+func (m *MockStore) StreamBlocksWithParentAndType(ctx context.Context, c store.Container, parentID, blockType string, fn func(model.Block) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamBlocksWithParentAndType", ctx, c, parentID, blockType, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamBlocksWithParentAndType indicates an expected call of StreamBlocksWithParentAndType.
+func (mr *MockStoreMockRecorder) StreamBlocksWithParentAndType(ctx, c, parentID, blockType, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamBlocksWithParentAndType", reflect.TypeOf((*MockStore)(nil).StreamBlocksWithParentAndType), ctx, c, parentID, blockType, fn)
+}
+
+// UpdateAccessTokenLastUsed mocks base method.
+func (m *MockStore) UpdateAccessTokenLastUsed(tokenID string, lastUsedAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccessTokenLastUsed", tokenID, lastUsedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccessTokenLastUsed indicates an expected call of UpdateAccessTokenLastUsed.
+func (mr *MockStoreMockRecorder) UpdateAccessTokenLastUsed(tokenID, lastUsedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccessTokenLastUsed", reflect.TypeOf((*MockStore)(nil).UpdateAccessTokenLastUsed), tokenID, lastUsedAt)
+}
+
+// UpdateGitHubIntegrationSync mocks base method.
+func (m *MockStore) UpdateGitHubIntegrationSync(c store.Container, boardID string, lastSyncAt, rateLimitResetAt int64, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGitHubIntegrationSync", c, boardID, lastSyncAt, rateLimitResetAt, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGitHubIntegrationSync indicates an expected call of UpdateGitHubIntegrationSync.
+func (mr *MockStoreMockRecorder) UpdateGitHubIntegrationSync(c, boardID, lastSyncAt, rateLimitResetAt, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGitHubIntegrationSync", reflect.TypeOf((*MockStore)(nil).UpdateGitHubIntegrationSync), c, boardID, lastSyncAt, rateLimitResetAt, lastError)
+}
+
+// UpdateSession mocks base method.
+func (m *MockStore) UpdateSession(session *model.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSession indicates an expected call of UpdateSession.
 func (mr *MockStoreMockRecorder) UpdateSession(session interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSession", reflect.TypeOf((*MockStore)(nil).UpdateSession), session)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSession", reflect.TypeOf((*MockStore)(nil).UpdateSession), session)
+}
+
+// UpdateUser mocks base method.
+func (m *MockStore) UpdateUser(user *model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockStoreMockRecorder) UpdateUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), user)
+}
+
+// UpdateUserActive mocks base method.
+func (m *MockStore) UpdateUserActive(userID string, active bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserActive", userID, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserActive indicates an expected call of UpdateUserActive.
+func (mr *MockStoreMockRecorder) UpdateUserActive(userID, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserActive", reflect.TypeOf((*MockStore)(nil).UpdateUserActive), userID, active)
+}
+
+// UpdateUserAuthData mocks base method.
+func (m *MockStore) UpdateUserAuthData(userID, authData string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserAuthData", userID, authData)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserAuthData indicates an expected call of UpdateUserAuthData.
+func (mr *MockStoreMockRecorder) UpdateUserAuthData(userID, authData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserAuthData", reflect.TypeOf((*MockStore)(nil).UpdateUserAuthData), userID, authData)
+}
+
+// UpdateUserMfa mocks base method.
+func (m *MockStore) UpdateUserMfa(userID, mfaSecret string, mfaActive bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserMfa", userID, mfaSecret, mfaActive)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserMfa indicates an expected call of UpdateUserMfa.
+func (mr *MockStoreMockRecorder) UpdateUserMfa(userID, mfaSecret, mfaActive interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserMfa", reflect.TypeOf((*MockStore)(nil).UpdateUserMfa), userID, mfaSecret, mfaActive)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockStore) UpdateUserPassword(username, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", username, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockStoreMockRecorder) UpdateUserPassword(username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), username, password)
+}
+
+// UpdateUserPasswordByID mocks base method.
+func (m *MockStore) UpdateUserPasswordByID(userID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPasswordByID", userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPasswordByID indicates an expected call of UpdateUserPasswordByID.
+func (mr *MockStoreMockRecorder) UpdateUserPasswordByID(userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPasswordByID", reflect.TypeOf((*MockStore)(nil).UpdateUserPasswordByID), userID, password)
+}
+
+// UpsertBoardMember mocks base method.
+func (m *MockStore) UpsertBoardMember(c store.Container, member model.BoardMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertBoardMember", c, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertBoardMember indicates an expected call of UpsertBoardMember.
+func (mr *MockStoreMockRecorder) UpsertBoardMember(c, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertBoardMember", reflect.TypeOf((*MockStore)(nil).UpsertBoardMember), c, member)
+}
+
+// UpsertCalendarFeed mocks base method.
+func (m *MockStore) UpsertCalendarFeed(c store.Container, feed model.CalendarFeed) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCalendarFeed", c, feed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertCalendarFeed indicates an expected call of UpsertCalendarFeed.
+func (mr *MockStoreMockRecorder) UpsertCalendarFeed(c, feed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCalendarFeed", reflect.TypeOf((*MockStore)(nil).UpsertCalendarFeed), c, feed)
+}
+
+// UpsertGitHubIntegration mocks base method.
+func (m *MockStore) UpsertGitHubIntegration(c store.Container, integration model.GitHubIntegration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertGitHubIntegration", c, integration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertGitHubIntegration indicates an expected call of UpsertGitHubIntegration.
+func (mr *MockStoreMockRecorder) UpsertGitHubIntegration(c, integration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertGitHubIntegration", reflect.TypeOf((*MockStore)(nil).UpsertGitHubIntegration), c, integration)
+}
+
+// UpsertGitHubIssueLink mocks base method.
+func (m *MockStore) UpsertGitHubIssueLink(c store.Container, link model.GitHubIssueLink) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertGitHubIssueLink", c, link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertGitHubIssueLink indicates an expected call of UpsertGitHubIssueLink.
+func (mr *MockStoreMockRecorder) UpsertGitHubIssueLink(c, link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertGitHubIssueLink", reflect.TypeOf((*MockStore)(nil).UpsertGitHubIssueLink), c, link)
+}
+
+// UpsertInboundHook mocks base method.
+func (m *MockStore) UpsertInboundHook(c store.Container, hook model.InboundHook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertInboundHook", c, hook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertInboundHook indicates an expected call of UpsertInboundHook.
+func (mr *MockStoreMockRecorder) UpsertInboundHook(c, hook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertInboundHook", reflect.TypeOf((*MockStore)(nil).UpsertInboundHook), c, hook)
+}
+
+// UpsertLoginAttempts mocks base method.
+func (m *MockStore) UpsertLoginAttempts(attempts model.LoginAttempts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertLoginAttempts", attempts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertLoginAttempts indicates an expected call of UpsertLoginAttempts.
+func (mr *MockStoreMockRecorder) UpsertLoginAttempts(attempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertLoginAttempts", reflect.TypeOf((*MockStore)(nil).UpsertLoginAttempts), attempts)
+}
+
+// UpsertPreferences mocks base method.
+func (m *MockStore) UpsertPreferences(ctx context.Context, userID string, preferences []model.Preference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertPreferences", ctx, userID, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertPreferences indicates an expected call of UpsertPreferences.
+func (mr *MockStoreMockRecorder) UpsertPreferences(ctx, userID, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertPreferences", reflect.TypeOf((*MockStore)(nil).UpsertPreferences), ctx, userID, preferences)
+}
+
+// UpsertSharing mocks base method.
+func (m *MockStore) UpsertSharing(c store.Container, sharing model.Sharing) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertSharing", c, sharing)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertSharing indicates an expected call of UpsertSharing.
+func (mr *MockStoreMockRecorder) UpsertSharing(c, sharing interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertSharing", reflect.TypeOf((*MockStore)(nil).UpsertSharing), c, sharing)
+}
+
+// UpsertWorkspaceRetentionNotice mocks base method.
+func (m *MockStore) UpsertWorkspaceRetentionNotice(ctx context.Context, notice model.WorkspaceRetentionNotice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceRetentionNotice", ctx, notice)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWorkspaceRetentionNotice indicates an expected call of UpsertWorkspaceRetentionNotice.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceRetentionNotice(ctx, notice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceRetentionNotice", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceRetentionNotice), ctx, notice)
+}
+
+// UpsertWorkspaceSettings mocks base method.
+func (m *MockStore) UpsertWorkspaceSettings(ctx context.Context, workspace model.Workspace) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceSettings", ctx, workspace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWorkspaceSettings indicates an expected call of UpsertWorkspaceSettings.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceSettings(ctx, workspace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceSettings", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceSettings), ctx, workspace)
+}
+
+// UpsertWorkspaceSignupToken mocks base method.
+func (m *MockStore) UpsertWorkspaceSignupToken(ctx context.Context, workspace model.Workspace) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceSignupToken", ctx, workspace)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWorkspaceSignupToken indicates an expected call of UpsertWorkspaceSignupToken.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceSignupToken(ctx, workspace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceSignupToken", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceSignupToken), ctx, workspace)
+}
+
+// MockTx is a mock of Tx interface.
+type MockTx struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxMockRecorder
+}
+
+// MockTxMockRecorder is the mock recorder for MockTx.
+type MockTxMockRecorder struct {
+	mock *MockTx
+}
+
+// NewMockTx creates a new mock instance.
+func NewMockTx(ctrl *gomock.Controller) *MockTx {
+	mock := &MockTx{ctrl: ctrl}
+	mock.recorder = &MockTxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTx) EXPECT() *MockTxMockRecorder {
+	return m.recorder
+}
+
+// AcquireClusterLock mocks base method.
+func (m *MockTx) AcquireClusterLock(name, owner string, expireAt int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireClusterLock", name, owner, expireAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireClusterLock indicates an expected call of AcquireClusterLock.
+func (mr *MockTxMockRecorder) AcquireClusterLock(name, owner, expireAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireClusterLock", reflect.TypeOf((*MockTx)(nil).AcquireClusterLock), name, owner, expireAt)
+}
+
+// AddWorkspaceMember mocks base method.
+func (m *MockTx) AddWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWorkspaceMember", ctx, workspaceID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddWorkspaceMember indicates an expected call of AddWorkspaceMember.
+func (mr *MockTxMockRecorder) AddWorkspaceMember(ctx, workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWorkspaceMember", reflect.TypeOf((*MockTx)(nil).AddWorkspaceMember), ctx, workspaceID, userID)
+}
+
+// BeginTx mocks base method.
+func (m *MockTx) BeginTx(ctx context.Context) (store.Tx, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginTx", ctx)
+	ret0, _ := ret[0].(store.Tx)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginTx indicates an expected call of BeginTx.
+func (mr *MockTxMockRecorder) BeginTx(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTx", reflect.TypeOf((*MockTx)(nil).BeginTx), ctx)
+}
+
+// ClaimSubscriptionNotification mocks base method.
+func (m *MockTx) ClaimSubscriptionNotification(ctx context.Context, blockID, subscriberID string, notifiedAt, notifiedBefore int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimSubscriptionNotification", ctx, blockID, subscriberID, notifiedAt, notifiedBefore)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimSubscriptionNotification indicates an expected call of ClaimSubscriptionNotification.
+func (mr *MockTxMockRecorder) ClaimSubscriptionNotification(ctx, blockID, subscriberID, notifiedAt, notifiedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimSubscriptionNotification", reflect.TypeOf((*MockTx)(nil).ClaimSubscriptionNotification), ctx, blockID, subscriberID, notifiedAt, notifiedBefore)
+}
+
+// ClaimUserDigest mocks base method.
+func (m *MockTx) ClaimUserDigest(ctx context.Context, userID string, lastDigestAt, digestAt int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimUserDigest", ctx, userID, lastDigestAt, digestAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimUserDigest indicates an expected call of ClaimUserDigest.
+func (mr *MockTxMockRecorder) ClaimUserDigest(ctx, userID, lastDigestAt, digestAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimUserDigest", reflect.TypeOf((*MockTx)(nil).ClaimUserDigest), ctx, userID, lastDigestAt, digestAt)
+}
+
+// Commit mocks base method.
+func (m *MockTx) Commit() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Commit")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Commit indicates an expected call of Commit.
+func (mr *MockTxMockRecorder) Commit() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockTx)(nil).Commit))
+}
+
+// ConsumeGuestInvite mocks base method.
+func (m *MockTx) ConsumeGuestInvite(tokenHash string) (*model.GuestInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeGuestInvite", tokenHash)
+	ret0, _ := ret[0].(*model.GuestInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeGuestInvite indicates an expected call of ConsumeGuestInvite.
+func (mr *MockTxMockRecorder) ConsumeGuestInvite(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeGuestInvite", reflect.TypeOf((*MockTx)(nil).ConsumeGuestInvite), tokenHash)
+}
+
+// ConsumeMfaRecoveryCode mocks base method.
+func (m *MockTx) ConsumeMfaRecoveryCode(userID, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeMfaRecoveryCode", userID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeMfaRecoveryCode indicates an expected call of ConsumeMfaRecoveryCode.
+func (mr *MockTxMockRecorder) ConsumeMfaRecoveryCode(userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeMfaRecoveryCode", reflect.TypeOf((*MockTx)(nil).ConsumeMfaRecoveryCode), userID, codeHash)
+}
+
+// ConsumePasswordResetToken mocks base method.
+func (m *MockTx) ConsumePasswordResetToken(tokenHash string) (*model.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumePasswordResetToken", tokenHash)
+	ret0, _ := ret[0].(*model.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumePasswordResetToken indicates an expected call of ConsumePasswordResetToken.
+func (mr *MockTxMockRecorder) ConsumePasswordResetToken(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumePasswordResetToken", reflect.TypeOf((*MockTx)(nil).ConsumePasswordResetToken), tokenHash)
+}
+
+// CountCardsByProperty mocks base method.
+func (m *MockTx) CountCardsByProperty(ctx context.Context, c store.Container, boardID, propertyID string) ([]model.PropertyValueCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCardsByProperty", ctx, c, boardID, propertyID)
+	ret0, _ := ret[0].([]model.PropertyValueCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCardsByProperty indicates an expected call of CountCardsByProperty.
+func (mr *MockTxMockRecorder) CountCardsByProperty(ctx, c, boardID, propertyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCardsByProperty", reflect.TypeOf((*MockTx)(nil).CountCardsByProperty), ctx, c, boardID, propertyID)
+}
+
+// CountCardsCompletedByWeek mocks base method.
+func (m *MockTx) CountCardsCompletedByWeek(ctx context.Context, c store.Container, boardID, propertyID, optionID string, since int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCardsCompletedByWeek", ctx, c, boardID, propertyID, optionID, since)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCardsCompletedByWeek indicates an expected call of CountCardsCompletedByWeek.
+func (mr *MockTxMockRecorder) CountCardsCompletedByWeek(ctx, c, boardID, propertyID, optionID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCardsCompletedByWeek", reflect.TypeOf((*MockTx)(nil).CountCardsCompletedByWeek), ctx, c, boardID, propertyID, optionID, since)
+}
+
+// CountCardsCreatedByWeek mocks base method.
+func (m *MockTx) CountCardsCreatedByWeek(ctx context.Context, c store.Container, boardID string, since int64) (map[int64]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCardsCreatedByWeek", ctx, c, boardID, since)
+	ret0, _ := ret[0].(map[int64]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCardsCreatedByWeek indicates an expected call of CountCardsCreatedByWeek.
+func (mr *MockTxMockRecorder) CountCardsCreatedByWeek(ctx, c, boardID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCardsCreatedByWeek", reflect.TypeOf((*MockTx)(nil).CountCardsCreatedByWeek), ctx, c, boardID, since)
+}
+
+// CountOrphanedBlocks mocks base method.
+func (m *MockTx) CountOrphanedBlocks(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOrphanedBlocks", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOrphanedBlocks indicates an expected call of CountOrphanedBlocks.
+func (mr *MockTxMockRecorder) CountOrphanedBlocks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOrphanedBlocks", reflect.TypeOf((*MockTx)(nil).CountOrphanedBlocks), ctx)
+}
+
+// CreateAccessToken mocks base method.
+func (m *MockTx) CreateAccessToken(token model.AccessToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccessToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAccessToken indicates an expected call of CreateAccessToken.
+func (mr *MockTxMockRecorder) CreateAccessToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockTx)(nil).CreateAccessToken), token)
+}
+
+// CreateGuestInvite mocks base method.
+func (m *MockTx) CreateGuestInvite(invite model.GuestInvite) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGuestInvite", invite)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateGuestInvite indicates an expected call of CreateGuestInvite.
+func (mr *MockTxMockRecorder) CreateGuestInvite(invite interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGuestInvite", reflect.TypeOf((*MockTx)(nil).CreateGuestInvite), invite)
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockTx) CreateIdempotencyKey(key model.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockTxMockRecorder) CreateIdempotencyKey(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockTx)(nil).CreateIdempotencyKey), key)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockTx) CreatePasswordResetToken(token model.PasswordResetToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockTxMockRecorder) CreatePasswordResetToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockTx)(nil).CreatePasswordResetToken), token)
+}
+
+// CreateSession mocks base method.
+func (m *MockTx) CreateSession(session *model.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockTxMockRecorder) CreateSession(session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockTx)(nil).CreateSession), session)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockTx) CreateSubscription(ctx context.Context, subscription model.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockTxMockRecorder) CreateSubscription(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockTx)(nil).CreateSubscription), ctx, subscription)
+}
+
+// CreateUser mocks base method.
+func (m *MockTx) CreateUser(user *model.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockTxMockRecorder) CreateUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockTx)(nil).CreateUser), user)
+}
+
+// DeleteAccessToken mocks base method.
+func (m *MockTx) DeleteAccessToken(userID, tokenID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccessToken", userID, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccessToken indicates an expected call of DeleteAccessToken.
+func (mr *MockTxMockRecorder) DeleteAccessToken(userID, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccessToken", reflect.TypeOf((*MockTx)(nil).DeleteAccessToken), userID, tokenID)
+}
+
+// DeleteBlock mocks base method.
+func (m *MockTx) DeleteBlock(ctx context.Context, c store.Container, blockID, modifiedBy string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlock", ctx, c, blockID, modifiedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlock indicates an expected call of DeleteBlock.
+func (mr *MockTxMockRecorder) DeleteBlock(ctx, c, blockID, modifiedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlock", reflect.TypeOf((*MockTx)(nil).DeleteBlock), ctx, c, blockID, modifiedBy)
+}
+
+// DeleteBoardMember mocks base method.
+func (m *MockTx) DeleteBoardMember(c store.Container, boardID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBoardMember", c, boardID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBoardMember indicates an expected call of DeleteBoardMember.
+func (mr *MockTxMockRecorder) DeleteBoardMember(c, boardID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBoardMember", reflect.TypeOf((*MockTx)(nil).DeleteBoardMember), c, boardID, userID)
+}
+
+// DeleteCalendarFeed mocks base method.
+func (m *MockTx) DeleteCalendarFeed(c store.Container, boardID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCalendarFeed", c, boardID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCalendarFeed indicates an expected call of DeleteCalendarFeed.
+func (mr *MockTxMockRecorder) DeleteCalendarFeed(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCalendarFeed", reflect.TypeOf((*MockTx)(nil).DeleteCalendarFeed), c, boardID)
+}
+
+// DeleteExpiredGuestInvites mocks base method.
+func (m *MockTx) DeleteExpiredGuestInvites(now int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredGuestInvites", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredGuestInvites indicates an expected call of DeleteExpiredGuestInvites.
+func (mr *MockTxMockRecorder) DeleteExpiredGuestInvites(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredGuestInvites", reflect.TypeOf((*MockTx)(nil).DeleteExpiredGuestInvites), now)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockTx) DeleteExpiredIdempotencyKeys(now int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockTxMockRecorder) DeleteExpiredIdempotencyKeys(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockTx)(nil).DeleteExpiredIdempotencyKeys), now)
+}
+
+// DeleteExpiredPasswordResetTokens mocks base method.
+func (m *MockTx) DeleteExpiredPasswordResetTokens(now int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredPasswordResetTokens", now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredPasswordResetTokens indicates an expected call of DeleteExpiredPasswordResetTokens.
+func (mr *MockTxMockRecorder) DeleteExpiredPasswordResetTokens(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredPasswordResetTokens", reflect.TypeOf((*MockTx)(nil).DeleteExpiredPasswordResetTokens), now)
+}
+
+// DeleteExpiredSessions mocks base method.
+func (m *MockTx) DeleteExpiredSessions() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSessions")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredSessions indicates an expected call of DeleteExpiredSessions.
+func (mr *MockTxMockRecorder) DeleteExpiredSessions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSessions", reflect.TypeOf((*MockTx)(nil).DeleteExpiredSessions))
+}
+
+// DeleteFileInfo mocks base method.
+func (m *MockTx) DeleteFileInfo(fileID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFileInfo", fileID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFileInfo indicates an expected call of DeleteFileInfo.
+func (mr *MockTxMockRecorder) DeleteFileInfo(fileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFileInfo", reflect.TypeOf((*MockTx)(nil).DeleteFileInfo), fileID)
+}
+
+// DeleteGitHubIntegration mocks base method.
+func (m *MockTx) DeleteGitHubIntegration(c store.Container, boardID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGitHubIntegration", c, boardID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGitHubIntegration indicates an expected call of DeleteGitHubIntegration.
+func (mr *MockTxMockRecorder) DeleteGitHubIntegration(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGitHubIntegration", reflect.TypeOf((*MockTx)(nil).DeleteGitHubIntegration), c, boardID)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockTx) DeleteIdempotencyKey(userID, key string, createAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", userID, key, createAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockTxMockRecorder) DeleteIdempotencyKey(userID, key, createAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockTx)(nil).DeleteIdempotencyKey), userID, key, createAt)
+}
+
+// DeleteInboundHook mocks base method.
+func (m *MockTx) DeleteInboundHook(c store.Container, boardID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteInboundHook", c, boardID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteInboundHook indicates an expected call of DeleteInboundHook.
+func (mr *MockTxMockRecorder) DeleteInboundHook(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteInboundHook", reflect.TypeOf((*MockTx)(nil).DeleteInboundHook), c, boardID)
+}
+
+// DeleteLoginAttempts mocks base method.
+func (m *MockTx) DeleteLoginAttempts(key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginAttempts", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginAttempts indicates an expected call of DeleteLoginAttempts.
+func (mr *MockTxMockRecorder) DeleteLoginAttempts(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttempts", reflect.TypeOf((*MockTx)(nil).DeleteLoginAttempts), key)
+}
+
+// DeleteMfaRecoveryCodes mocks base method.
+func (m *MockTx) DeleteMfaRecoveryCodes(userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMfaRecoveryCodes", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMfaRecoveryCodes indicates an expected call of DeleteMfaRecoveryCodes.
+func (mr *MockTxMockRecorder) DeleteMfaRecoveryCodes(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMfaRecoveryCodes", reflect.TypeOf((*MockTx)(nil).DeleteMfaRecoveryCodes), userID)
+}
+
+// DeletePasswordResetTokensForUser mocks base method.
+func (m *MockTx) DeletePasswordResetTokensForUser(userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasswordResetTokensForUser", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasswordResetTokensForUser indicates an expected call of DeletePasswordResetTokensForUser.
+func (mr *MockTxMockRecorder) DeletePasswordResetTokensForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResetTokensForUser", reflect.TypeOf((*MockTx)(nil).DeletePasswordResetTokensForUser), userID)
+}
+
+// DeletePreferences mocks base method.
+func (m *MockTx) DeletePreferences(ctx context.Context, userID string, preferences []model.Preference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePreferences", ctx, userID, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePreferences indicates an expected call of DeletePreferences.
+func (mr *MockTxMockRecorder) DeletePreferences(ctx, userID, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePreferences", reflect.TypeOf((*MockTx)(nil).DeletePreferences), ctx, userID, preferences)
+}
+
+// DeleteSession mocks base method.
+func (m *MockTx) DeleteSession(sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockTxMockRecorder) DeleteSession(sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockTx)(nil).DeleteSession), sessionID)
+}
+
+// DeleteSessionsForUser mocks base method.
+func (m *MockTx) DeleteSessionsForUser(userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessionsForUser", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSessionsForUser indicates an expected call of DeleteSessionsForUser.
+func (mr *MockTxMockRecorder) DeleteSessionsForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessionsForUser", reflect.TypeOf((*MockTx)(nil).DeleteSessionsForUser), userID)
+}
+
+// DeleteStaleLoginAttempts mocks base method.
+func (m *MockTx) DeleteStaleLoginAttempts(before int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleLoginAttempts", before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteStaleLoginAttempts indicates an expected call of DeleteStaleLoginAttempts.
+func (mr *MockTxMockRecorder) DeleteStaleLoginAttempts(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleLoginAttempts", reflect.TypeOf((*MockTx)(nil).DeleteStaleLoginAttempts), before)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockTx) DeleteSubscription(ctx context.Context, c store.Container, blockID, subscriberID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, c, blockID, subscriberID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockTxMockRecorder) DeleteSubscription(ctx, c, blockID, subscriberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockTx)(nil).DeleteSubscription), ctx, c, blockID, subscriberID)
+}
+
+// DeleteWorkspace mocks base method.
+func (m *MockTx) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspace", ctx, workspaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspace indicates an expected call of DeleteWorkspace.
+func (mr *MockTxMockRecorder) DeleteWorkspace(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspace", reflect.TypeOf((*MockTx)(nil).DeleteWorkspace), ctx, workspaceID)
+}
+
+// DeleteWorkspaceRetentionNotice mocks base method.
+func (m *MockTx) DeleteWorkspaceRetentionNotice(ctx context.Context, workspaceID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspaceRetentionNotice", ctx, workspaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspaceRetentionNotice indicates an expected call of DeleteWorkspaceRetentionNotice.
+func (mr *MockTxMockRecorder) DeleteWorkspaceRetentionNotice(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspaceRetentionNotice", reflect.TypeOf((*MockTx)(nil).DeleteWorkspaceRetentionNotice), ctx, workspaceID)
+}
+
+// GetAccessTokenByHash mocks base method.
+func (m *MockTx) GetAccessTokenByHash(tokenHash string) (*model.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokenByHash", tokenHash)
+	ret0, _ := ret[0].(*model.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokenByHash indicates an expected call of GetAccessTokenByHash.
+func (mr *MockTxMockRecorder) GetAccessTokenByHash(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokenByHash", reflect.TypeOf((*MockTx)(nil).GetAccessTokenByHash), tokenHash)
+}
+
+// GetAccessTokensForUser mocks base method.
+func (m *MockTx) GetAccessTokensForUser(userID string) ([]model.AccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccessTokensForUser", userID)
+	ret0, _ := ret[0].([]model.AccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccessTokensForUser indicates an expected call of GetAccessTokensForUser.
+func (mr *MockTxMockRecorder) GetAccessTokensForUser(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccessTokensForUser", reflect.TypeOf((*MockTx)(nil).GetAccessTokensForUser), userID)
+}
+
+// GetActiveUserCount mocks base method.
+func (m *MockTx) GetActiveUserCount(updatedSecondsAgo int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveUserCount", updatedSecondsAgo)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveUserCount indicates an expected call of GetActiveUserCount.
+func (mr *MockTxMockRecorder) GetActiveUserCount(updatedSecondsAgo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveUserCount", reflect.TypeOf((*MockTx)(nil).GetActiveUserCount), updatedSecondsAgo)
+}
+
+// GetAllBlocks mocks base method.
+func (m *MockTx) GetAllBlocks(ctx context.Context, c store.Container) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllBlocks", ctx, c)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllBlocks indicates an expected call of GetAllBlocks.
+func (mr *MockTxMockRecorder) GetAllBlocks(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllBlocks", reflect.TypeOf((*MockTx)(nil).GetAllBlocks), ctx, c)
+}
+
+// GetAppliedMigrations mocks base method.
+func (m *MockTx) GetAppliedMigrations(ctx context.Context) ([]model.Migration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppliedMigrations", ctx)
+	ret0, _ := ret[0].([]model.Migration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppliedMigrations indicates an expected call of GetAppliedMigrations.
+func (mr *MockTxMockRecorder) GetAppliedMigrations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppliedMigrations", reflect.TypeOf((*MockTx)(nil).GetAppliedMigrations), ctx)
+}
+
+// GetAuditEntries mocks base method.
+func (m *MockTx) GetAuditEntries(opts model.QueryAuditEntriesOptions) ([]model.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEntries", opts)
+	ret0, _ := ret[0].([]model.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEntries indicates an expected call of GetAuditEntries.
+func (mr *MockTxMockRecorder) GetAuditEntries(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEntries", reflect.TypeOf((*MockTx)(nil).GetAuditEntries), opts)
+}
+
+// GetBlock mocks base method.
+func (m *MockTx) GetBlock(ctx context.Context, c store.Container, blockID string) (*model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlock", ctx, c, blockID)
+	ret0, _ := ret[0].(*model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlock indicates an expected call of GetBlock.
+func (mr *MockTxMockRecorder) GetBlock(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlock", reflect.TypeOf((*MockTx)(nil).GetBlock), ctx, c, blockID)
+}
+
+// GetBlockCountsByType mocks base method.
+func (m *MockTx) GetBlockCountsByType(ctx context.Context) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockCountsByType", ctx)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockCountsByType indicates an expected call of GetBlockCountsByType.
+func (mr *MockTxMockRecorder) GetBlockCountsByType(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockCountsByType", reflect.TypeOf((*MockTx)(nil).GetBlockCountsByType), ctx)
+}
+
+// GetBlockHistory mocks base method.
+func (m *MockTx) GetBlockHistory(ctx context.Context, c store.Container, blockID string, opts model.QueryBlockHistoryOptions) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockHistory", ctx, c, blockID, opts)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockHistory indicates an expected call of GetBlockHistory.
+func (mr *MockTxMockRecorder) GetBlockHistory(ctx, c, blockID, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockHistory", reflect.TypeOf((*MockTx)(nil).GetBlockHistory), ctx, c, blockID, opts)
+}
+
+// GetBlockWorkspaceIDs mocks base method.
+func (m *MockTx) GetBlockWorkspaceIDs(ctx context.Context, blockIDs []string) (map[string][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockWorkspaceIDs", ctx, blockIDs)
+	ret0, _ := ret[0].(map[string][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockWorkspaceIDs indicates an expected call of GetBlockWorkspaceIDs.
+func (mr *MockTxMockRecorder) GetBlockWorkspaceIDs(ctx, blockIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockWorkspaceIDs", reflect.TypeOf((*MockTx)(nil).GetBlockWorkspaceIDs), ctx, blockIDs)
+}
+
+// GetBlocksByIDs mocks base method.
+func (m *MockTx) GetBlocksByIDs(ctx context.Context, c store.Container, blockIDs []string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksByIDs", ctx, c, blockIDs)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksByIDs indicates an expected call of GetBlocksByIDs.
+func (mr *MockTxMockRecorder) GetBlocksByIDs(ctx, c, blockIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksByIDs", reflect.TypeOf((*MockTx)(nil).GetBlocksByIDs), ctx, c, blockIDs)
+}
+
+// GetBlocksDigest mocks base method.
+func (m *MockTx) GetBlocksDigest(ctx context.Context, c store.Container, opts model.QueryBlocksDigestOptions) (*model.BlocksDigest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksDigest", ctx, c, opts)
+	ret0, _ := ret[0].(*model.BlocksDigest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksDigest indicates an expected call of GetBlocksDigest.
+func (mr *MockTxMockRecorder) GetBlocksDigest(ctx, c, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksDigest", reflect.TypeOf((*MockTx)(nil).GetBlocksDigest), ctx, c, opts)
+}
+
+// GetBlocksPage mocks base method.
+func (m *MockTx) GetBlocksPage(ctx context.Context, c store.Container, opts model.QueryBlocksPageOptions) (*model.BlocksPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksPage", ctx, c, opts)
+	ret0, _ := ret[0].(*model.BlocksPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksPage indicates an expected call of GetBlocksPage.
+func (mr *MockTxMockRecorder) GetBlocksPage(ctx, c, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksPage", reflect.TypeOf((*MockTx)(nil).GetBlocksPage), ctx, c, opts)
+}
+
+// GetBlocksWithParent mocks base method.
+func (m *MockTx) GetBlocksWithParent(ctx context.Context, c store.Container, parentID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksWithParent", ctx, c, parentID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksWithParent indicates an expected call of GetBlocksWithParent.
+func (mr *MockTxMockRecorder) GetBlocksWithParent(ctx, c, parentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithParent", reflect.TypeOf((*MockTx)(nil).GetBlocksWithParent), ctx, c, parentID)
+}
+
+// GetBlocksWithParentAndType mocks base method.
+func (m *MockTx) GetBlocksWithParentAndType(ctx context.Context, c store.Container, parentID, blockType string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksWithParentAndType", ctx, c, parentID, blockType)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksWithParentAndType indicates an expected call of GetBlocksWithParentAndType.
+func (mr *MockTxMockRecorder) GetBlocksWithParentAndType(ctx, c, parentID, blockType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithParentAndType", reflect.TypeOf((*MockTx)(nil).GetBlocksWithParentAndType), ctx, c, parentID, blockType)
+}
+
+// GetBlocksWithRootID mocks base method.
+func (m *MockTx) GetBlocksWithRootID(ctx context.Context, c store.Container, rootID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksWithRootID", ctx, c, rootID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksWithRootID indicates an expected call of GetBlocksWithRootID.
+func (mr *MockTxMockRecorder) GetBlocksWithRootID(ctx, c, rootID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithRootID", reflect.TypeOf((*MockTx)(nil).GetBlocksWithRootID), ctx, c, rootID)
+}
+
+// GetBlocksWithType mocks base method.
+func (m *MockTx) GetBlocksWithType(ctx context.Context, c store.Container, blockType string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksWithType", ctx, c, blockType)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksWithType indicates an expected call of GetBlocksWithType.
+func (mr *MockTxMockRecorder) GetBlocksWithType(ctx, c, blockType interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksWithType", reflect.TypeOf((*MockTx)(nil).GetBlocksWithType), ctx, c, blockType)
+}
+
+// GetBoardMembers mocks base method.
+func (m *MockTx) GetBoardMembers(c store.Container, boardID string) ([]model.BoardMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardMembers", c, boardID)
+	ret0, _ := ret[0].([]model.BoardMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardMembers indicates an expected call of GetBoardMembers.
+func (mr *MockTxMockRecorder) GetBoardMembers(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardMembers", reflect.TypeOf((*MockTx)(nil).GetBoardMembers), c, boardID)
+}
+
+// GetBoardMetadata mocks base method.
+func (m *MockTx) GetBoardMetadata(ctx context.Context, c store.Container, boardID string) ([]model.CardMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardMetadata", ctx, c, boardID)
+	ret0, _ := ret[0].([]model.CardMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardMetadata indicates an expected call of GetBoardMetadata.
+func (mr *MockTxMockRecorder) GetBoardMetadata(ctx, c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardMetadata", reflect.TypeOf((*MockTx)(nil).GetBoardMetadata), ctx, c, boardID)
+}
+
+// GetBoardRolesForUser mocks base method.
+func (m *MockTx) GetBoardRolesForUser(c store.Container, userID string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardRolesForUser", c, userID)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardRolesForUser indicates an expected call of GetBoardRolesForUser.
+func (mr *MockTxMockRecorder) GetBoardRolesForUser(c, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardRolesForUser", reflect.TypeOf((*MockTx)(nil).GetBoardRolesForUser), c, userID)
+}
+
+// GetBoardSubscriptions mocks base method.
+func (m *MockTx) GetBoardSubscriptions(ctx context.Context) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardSubscriptions", ctx)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardSubscriptions indicates an expected call of GetBoardSubscriptions.
+func (mr *MockTxMockRecorder) GetBoardSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardSubscriptions", reflect.TypeOf((*MockTx)(nil).GetBoardSubscriptions), ctx)
+}
+
+// GetBoardWorkspaceIDs mocks base method.
+func (m *MockTx) GetBoardWorkspaceIDs() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBoardWorkspaceIDs")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBoardWorkspaceIDs indicates an expected call of GetBoardWorkspaceIDs.
+func (mr *MockTxMockRecorder) GetBoardWorkspaceIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBoardWorkspaceIDs", reflect.TypeOf((*MockTx)(nil).GetBoardWorkspaceIDs))
+}
+
+// GetCalendarFeed mocks base method.
+func (m *MockTx) GetCalendarFeed(c store.Container, boardID string) (*model.CalendarFeed, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCalendarFeed", c, boardID)
+	ret0, _ := ret[0].(*model.CalendarFeed)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCalendarFeed indicates an expected call of GetCalendarFeed.
+func (mr *MockTxMockRecorder) GetCalendarFeed(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCalendarFeed", reflect.TypeOf((*MockTx)(nil).GetCalendarFeed), c, boardID)
+}
+
+// GetCardActivity mocks base method.
+func (m *MockTx) GetCardActivity(ctx context.Context, c store.Container, cardID string, limit int, before int64) ([]model.BlockHistoryEntry, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardActivity", ctx, c, cardID, limit, before)
+	ret0, _ := ret[0].([]model.BlockHistoryEntry)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCardActivity indicates an expected call of GetCardActivity.
+func (mr *MockTxMockRecorder) GetCardActivity(ctx, c, cardID, limit, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardActivity", reflect.TypeOf((*MockTx)(nil).GetCardActivity), ctx, c, cardID, limit, before)
+}
+
+// GetCardBacklinks mocks base method.
+func (m *MockTx) GetCardBacklinks(ctx context.Context, c store.Container, cardID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardBacklinks", ctx, c, cardID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardBacklinks indicates an expected call of GetCardBacklinks.
+func (mr *MockTxMockRecorder) GetCardBacklinks(ctx, c, cardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardBacklinks", reflect.TypeOf((*MockTx)(nil).GetCardBacklinks), ctx, c, cardID)
+}
+
+// GetCardByNumber mocks base method.
+func (m *MockTx) GetCardByNumber(ctx context.Context, c store.Container, boardID string, number int64) (*model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCardByNumber", ctx, c, boardID, number)
+	ret0, _ := ret[0].(*model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCardByNumber indicates an expected call of GetCardByNumber.
+func (mr *MockTxMockRecorder) GetCardByNumber(ctx, c, boardID, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCardByNumber", reflect.TypeOf((*MockTx)(nil).GetCardByNumber), ctx, c, boardID, number)
+}
+
+// GetChecklistProgress mocks base method.
+func (m *MockTx) GetChecklistProgress(ctx context.Context, c store.Container, boardID string) ([]model.ChecklistProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChecklistProgress", ctx, c, boardID)
+	ret0, _ := ret[0].([]model.ChecklistProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChecklistProgress indicates an expected call of GetChecklistProgress.
+func (mr *MockTxMockRecorder) GetChecklistProgress(ctx, c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChecklistProgress", reflect.TypeOf((*MockTx)(nil).GetChecklistProgress), ctx, c, boardID)
+}
+
+// GetDBStats mocks base method.
+func (m *MockTx) GetDBStats() sql.DBStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDBStats")
+	ret0, _ := ret[0].(sql.DBStats)
+	return ret0
+}
+
+// GetDBStats indicates an expected call of GetDBStats.
+func (mr *MockTxMockRecorder) GetDBStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDBStats", reflect.TypeOf((*MockTx)(nil).GetDBStats))
+}
+
+// GetDeletedBlocks mocks base method.
+func (m *MockTx) GetDeletedBlocks(ctx context.Context, c store.Container, since int64) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedBlocks", ctx, c, since)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedBlocks indicates an expected call of GetDeletedBlocks.
+func (mr *MockTxMockRecorder) GetDeletedBlocks(ctx, c, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedBlocks", reflect.TypeOf((*MockTx)(nil).GetDeletedBlocks), ctx, c, since)
+}
+
+// GetFileInfo mocks base method.
+func (m *MockTx) GetFileInfo(fileID string) (*model.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileInfo", fileID)
+	ret0, _ := ret[0].(*model.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileInfo indicates an expected call of GetFileInfo.
+func (mr *MockTxMockRecorder) GetFileInfo(fileID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileInfo", reflect.TypeOf((*MockTx)(nil).GetFileInfo), fileID)
+}
+
+// GetFilteredBoardBlocks mocks base method.
+func (m *MockTx) GetFilteredBoardBlocks(ctx context.Context, c store.Container, boardID string, filter *model.FilterGroup) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilteredBoardBlocks", ctx, c, boardID, filter)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilteredBoardBlocks indicates an expected call of GetFilteredBoardBlocks.
+func (mr *MockTxMockRecorder) GetFilteredBoardBlocks(ctx, c, boardID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilteredBoardBlocks", reflect.TypeOf((*MockTx)(nil).GetFilteredBoardBlocks), ctx, c, boardID, filter)
+}
+
+// GetGitHubIntegration mocks base method.
+func (m *MockTx) GetGitHubIntegration(c store.Container, boardID string) (*model.GitHubIntegration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitHubIntegration", c, boardID)
+	ret0, _ := ret[0].(*model.GitHubIntegration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitHubIntegration indicates an expected call of GetGitHubIntegration.
+func (mr *MockTxMockRecorder) GetGitHubIntegration(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitHubIntegration", reflect.TypeOf((*MockTx)(nil).GetGitHubIntegration), c, boardID)
+}
+
+// GetGitHubIntegrations mocks base method.
+func (m *MockTx) GetGitHubIntegrations() ([]model.GitHubIntegration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitHubIntegrations")
+	ret0, _ := ret[0].([]model.GitHubIntegration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitHubIntegrations indicates an expected call of GetGitHubIntegrations.
+func (mr *MockTxMockRecorder) GetGitHubIntegrations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitHubIntegrations", reflect.TypeOf((*MockTx)(nil).GetGitHubIntegrations))
+}
+
+// GetGitHubIssueLinks mocks base method.
+func (m *MockTx) GetGitHubIssueLinks(c store.Container, boardID string) ([]model.GitHubIssueLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGitHubIssueLinks", c, boardID)
+	ret0, _ := ret[0].([]model.GitHubIssueLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGitHubIssueLinks indicates an expected call of GetGitHubIssueLinks.
+func (mr *MockTxMockRecorder) GetGitHubIssueLinks(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGitHubIssueLinks", reflect.TypeOf((*MockTx)(nil).GetGitHubIssueLinks), c, boardID)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockTx) GetIdempotencyKey(userID, key string) (*model.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", userID, key)
+	ret0, _ := ret[0].(*model.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockTxMockRecorder) GetIdempotencyKey(userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockTx)(nil).GetIdempotencyKey), userID, key)
+}
+
+// GetInactiveWorkspaces mocks base method.
+func (m *MockTx) GetInactiveWorkspaces(ctx context.Context, inactiveSince int64) ([]model.InactiveWorkspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInactiveWorkspaces", ctx, inactiveSince)
+	ret0, _ := ret[0].([]model.InactiveWorkspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInactiveWorkspaces indicates an expected call of GetInactiveWorkspaces.
+func (mr *MockTxMockRecorder) GetInactiveWorkspaces(ctx, inactiveSince interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInactiveWorkspaces", reflect.TypeOf((*MockTx)(nil).GetInactiveWorkspaces), ctx, inactiveSince)
+}
+
+// GetInboundHook mocks base method.
+func (m *MockTx) GetInboundHook(c store.Container, boardID string) (*model.InboundHook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInboundHook", c, boardID)
+	ret0, _ := ret[0].(*model.InboundHook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInboundHook indicates an expected call of GetInboundHook.
+func (mr *MockTxMockRecorder) GetInboundHook(c, boardID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInboundHook", reflect.TypeOf((*MockTx)(nil).GetInboundHook), c, boardID)
+}
+
+// GetInboundHookByTokenHash mocks base method.
+func (m *MockTx) GetInboundHookByTokenHash(tokenHash string) (*model.InboundHook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInboundHookByTokenHash", tokenHash)
+	ret0, _ := ret[0].(*model.InboundHook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInboundHookByTokenHash indicates an expected call of GetInboundHookByTokenHash.
+func (mr *MockTxMockRecorder) GetInboundHookByTokenHash(tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInboundHookByTokenHash", reflect.TypeOf((*MockTx)(nil).GetInboundHookByTokenHash), tokenHash)
+}
+
+// GetInboundHookDeliveries mocks base method.
+func (m *MockTx) GetInboundHookDeliveries(c store.Container, boardID string, limit int) ([]model.InboundHookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInboundHookDeliveries", c, boardID, limit)
+	ret0, _ := ret[0].([]model.InboundHookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInboundHookDeliveries indicates an expected call of GetInboundHookDeliveries.
+func (mr *MockTxMockRecorder) GetInboundHookDeliveries(c, boardID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInboundHookDeliveries", reflect.TypeOf((*MockTx)(nil).GetInboundHookDeliveries), c, boardID, limit)
+}
+
+// GetLastDigestAt mocks base method.
+func (m *MockTx) GetLastDigestAt(ctx context.Context, userID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastDigestAt", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastDigestAt indicates an expected call of GetLastDigestAt.
+func (mr *MockTxMockRecorder) GetLastDigestAt(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastDigestAt", reflect.TypeOf((*MockTx)(nil).GetLastDigestAt), ctx, userID)
+}
+
+// GetLastRecurrenceRun mocks base method.
+func (m *MockTx) GetLastRecurrenceRun(ctx context.Context, recurrenceID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastRecurrenceRun", ctx, recurrenceID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastRecurrenceRun indicates an expected call of GetLastRecurrenceRun.
+func (mr *MockTxMockRecorder) GetLastRecurrenceRun(ctx, recurrenceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastRecurrenceRun", reflect.TypeOf((*MockTx)(nil).GetLastRecurrenceRun), ctx, recurrenceID)
+}
+
+// GetLoginAttempts mocks base method.
+func (m *MockTx) GetLoginAttempts(key string) (*model.LoginAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginAttempts", key)
+	ret0, _ := ret[0].(*model.LoginAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginAttempts indicates an expected call of GetLoginAttempts.
+func (mr *MockTxMockRecorder) GetLoginAttempts(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginAttempts", reflect.TypeOf((*MockTx)(nil).GetLoginAttempts), key)
+}
+
+// GetNotificationsForUser mocks base method.
+func (m *MockTx) GetNotificationsForUser(userID string, limit int) ([]model.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationsForUser", userID, limit)
+	ret0, _ := ret[0].([]model.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationsForUser indicates an expected call of GetNotificationsForUser.
+func (mr *MockTxMockRecorder) GetNotificationsForUser(userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationsForUser", reflect.TypeOf((*MockTx)(nil).GetNotificationsForUser), userID, limit)
+}
+
+// GetNotifiedUserIDs mocks base method.
+func (m *MockTx) GetNotifiedUserIDs(blockID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifiedUserIDs", blockID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifiedUserIDs indicates an expected call of GetNotifiedUserIDs.
+func (mr *MockTxMockRecorder) GetNotifiedUserIDs(blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifiedUserIDs", reflect.TypeOf((*MockTx)(nil).GetNotifiedUserIDs), blockID)
+}
+
+// GetParentID mocks base method.
+func (m *MockTx) GetParentID(ctx context.Context, c store.Container, blockID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParentID", ctx, c, blockID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParentID indicates an expected call of GetParentID.
+func (mr *MockTxMockRecorder) GetParentID(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParentID", reflect.TypeOf((*MockTx)(nil).GetParentID), ctx, c, blockID)
+}
+
+// GetPendingMigrations mocks base method.
+func (m *MockTx) GetPendingMigrations(ctx context.Context) ([]model.Migration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingMigrations", ctx)
+	ret0, _ := ret[0].([]model.Migration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingMigrations indicates an expected call of GetPendingMigrations.
+func (mr *MockTxMockRecorder) GetPendingMigrations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingMigrations", reflect.TypeOf((*MockTx)(nil).GetPendingMigrations), ctx)
+}
+
+// GetPreferences mocks base method.
+func (m *MockTx) GetPreferences(ctx context.Context, userID string) ([]model.Preference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, userID)
+	ret0, _ := ret[0].([]model.Preference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockTxMockRecorder) GetPreferences(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockTx)(nil).GetPreferences), ctx, userID)
+}
+
+// GetReferencedFileIDs mocks base method.
+func (m *MockTx) GetReferencedFileIDs(ctx context.Context, c store.Container) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReferencedFileIDs", ctx, c)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReferencedFileIDs indicates an expected call of GetReferencedFileIDs.
+func (mr *MockTxMockRecorder) GetReferencedFileIDs(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReferencedFileIDs", reflect.TypeOf((*MockTx)(nil).GetReferencedFileIDs), ctx, c)
+}
+
+// GetRegisteredUserCount mocks base method.
+func (m *MockTx) GetRegisteredUserCount() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRegisteredUserCount")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRegisteredUserCount indicates an expected call of GetRegisteredUserCount.
+func (mr *MockTxMockRecorder) GetRegisteredUserCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegisteredUserCount", reflect.TypeOf((*MockTx)(nil).GetRegisteredUserCount))
+}
+
+// GetRootID mocks base method.
+func (m *MockTx) GetRootID(ctx context.Context, c store.Container, blockID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRootID", ctx, c, blockID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRootID indicates an expected call of GetRootID.
+func (mr *MockTxMockRecorder) GetRootID(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRootID", reflect.TypeOf((*MockTx)(nil).GetRootID), ctx, c, blockID)
+}
+
+// GetSchemaVersion mocks base method.
+func (m *MockTx) GetSchemaVersion(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemaVersion", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchemaVersion indicates an expected call of GetSchemaVersion.
+func (mr *MockTxMockRecorder) GetSchemaVersion(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemaVersion", reflect.TypeOf((*MockTx)(nil).GetSchemaVersion), ctx)
+}
+
+// GetSentReminderUserIDs mocks base method.
+func (m *MockTx) GetSentReminderUserIDs(cardID string, dueAt int64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSentReminderUserIDs", cardID, dueAt)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSentReminderUserIDs indicates an expected call of GetSentReminderUserIDs.
+func (mr *MockTxMockRecorder) GetSentReminderUserIDs(cardID, dueAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSentReminderUserIDs", reflect.TypeOf((*MockTx)(nil).GetSentReminderUserIDs), cardID, dueAt)
+}
+
+// GetSession mocks base method.
+func (m *MockTx) GetSession(token string) (*model.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", token)
+	ret0, _ := ret[0].(*model.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockTxMockRecorder) GetSession(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockTx)(nil).GetSession), token)
+}
+
+// GetSharing mocks base method.
+func (m *MockTx) GetSharing(c store.Container, rootID string) (*model.Sharing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharing", c, rootID)
+	ret0, _ := ret[0].(*model.Sharing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharing indicates an expected call of GetSharing.
+func (mr *MockTxMockRecorder) GetSharing(c, rootID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharing", reflect.TypeOf((*MockTx)(nil).GetSharing), c, rootID)
+}
+
+// GetSharingTokens mocks base method.
+func (m *MockTx) GetSharingTokens(c store.Container, rootID string) ([]model.SharingToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSharingTokens", c, rootID)
+	ret0, _ := ret[0].([]model.SharingToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSharingTokens indicates an expected call of GetSharingTokens.
+func (mr *MockTxMockRecorder) GetSharingTokens(c, rootID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSharingTokens", reflect.TypeOf((*MockTx)(nil).GetSharingTokens), c, rootID)
+}
+
+// GetSubTree2 mocks base method.
+func (m *MockTx) GetSubTree2(ctx context.Context, c store.Container, blockID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubTree2", ctx, c, blockID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubTree2 indicates an expected call of GetSubTree2.
+func (mr *MockTxMockRecorder) GetSubTree2(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubTree2", reflect.TypeOf((*MockTx)(nil).GetSubTree2), ctx, c, blockID)
+}
+
+// GetSubTree3 mocks base method.
+func (m *MockTx) GetSubTree3(ctx context.Context, c store.Container, blockID string) ([]model.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubTree3", ctx, c, blockID)
+	ret0, _ := ret[0].([]model.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubTree3 indicates an expected call of GetSubTree3.
+func (mr *MockTxMockRecorder) GetSubTree3(ctx, c, blockID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubTree3", reflect.TypeOf((*MockTx)(nil).GetSubTree3), ctx, c, blockID)
+}
+
+// GetSubscriptions mocks base method.
+func (m *MockTx) GetSubscriptions(ctx context.Context, c store.Container, blockIDs []string) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptions", ctx, c, blockIDs)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptions indicates an expected call of GetSubscriptions.
+func (mr *MockTxMockRecorder) GetSubscriptions(ctx, c, blockIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptions", reflect.TypeOf((*MockTx)(nil).GetSubscriptions), ctx, c, blockIDs)
+}
+
+// GetSubscriptionsForUser mocks base method.
+func (m *MockTx) GetSubscriptionsForUser(ctx context.Context, c store.Container, subscriberID string) ([]model.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionsForUser", ctx, c, subscriberID)
+	ret0, _ := ret[0].([]model.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionsForUser indicates an expected call of GetSubscriptionsForUser.
+func (mr *MockTxMockRecorder) GetSubscriptionsForUser(ctx, c, subscriberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionsForUser", reflect.TypeOf((*MockTx)(nil).GetSubscriptionsForUser), ctx, c, subscriberID)
+}
+
+// GetSystemSettings mocks base method.
+func (m *MockTx) GetSystemSettings() (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemSettings")
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemSettings indicates an expected call of GetSystemSettings.
+func (mr *MockTxMockRecorder) GetSystemSettings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemSettings", reflect.TypeOf((*MockTx)(nil).GetSystemSettings))
+}
+
+// GetTemplateBoards mocks base method.
+func (m *MockTx) GetTemplateBoards(ctx context.Context, c store.Container) ([]model.BoardTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateBoards", ctx, c)
+	ret0, _ := ret[0].([]model.BoardTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateBoards indicates an expected call of GetTemplateBoards.
+func (mr *MockTxMockRecorder) GetTemplateBoards(ctx, c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateBoards", reflect.TypeOf((*MockTx)(nil).GetTemplateBoards), ctx, c)
+}
+
+// GetUserByAuthData mocks base method.
+func (m *MockTx) GetUserByAuthData(authData string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByAuthData", authData)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByAuthData indicates an expected call of GetUserByAuthData.
+func (mr *MockTxMockRecorder) GetUserByAuthData(authData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByAuthData", reflect.TypeOf((*MockTx)(nil).GetUserByAuthData), authData)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockTx) GetUserByEmail(email string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", email)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockTxMockRecorder) GetUserByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockTx)(nil).GetUserByEmail), email)
+}
+
+// GetUserByID mocks base method.
+func (m *MockTx) GetUserByID(userID string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", userID)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockTxMockRecorder) GetUserByID(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockTx)(nil).GetUserByID), userID)
+}
+
+// GetUserByUsername mocks base method.
+func (m *MockTx) GetUserByUsername(username string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByUsername", username)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByUsername indicates an expected call of GetUserByUsername.
+func (mr *MockTxMockRecorder) GetUserByUsername(username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockTx)(nil).GetUserByUsername), username)
+}
+
+// GetUserWorkspaces mocks base method.
+func (m *MockTx) GetUserWorkspaces(ctx context.Context, userID, cursor string, limit int) ([]model.UserWorkspace, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserWorkspaces", ctx, userID, cursor, limit)
+	ret0, _ := ret[0].([]model.UserWorkspace)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserWorkspaces indicates an expected call of GetUserWorkspaces.
+func (mr *MockTxMockRecorder) GetUserWorkspaces(ctx, userID, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserWorkspaces", reflect.TypeOf((*MockTx)(nil).GetUserWorkspaces), ctx, userID, cursor, limit)
+}
+
+// GetUsers mocks base method.
+func (m *MockTx) GetUsers(opts model.QueryUsersOptions) ([]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", opts)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockTxMockRecorder) GetUsers(opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockTx)(nil).GetUsers), opts)
+}
+
+// GetUsersByWorkspace mocks base method.
+func (m *MockTx) GetUsersByWorkspace(workspaceID string) ([]*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByWorkspace", workspaceID)
+	ret0, _ := ret[0].([]*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByWorkspace indicates an expected call of GetUsersByWorkspace.
+func (mr *MockTxMockRecorder) GetUsersByWorkspace(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByWorkspace", reflect.TypeOf((*MockTx)(nil).GetUsersByWorkspace), workspaceID)
+}
+
+// GetWebhookDeliveries mocks base method.
+func (m *MockTx) GetWebhookDeliveries(workspaceID string, limit int) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookDeliveries", workspaceID, limit)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookDeliveries indicates an expected call of GetWebhookDeliveries.
+func (mr *MockTxMockRecorder) GetWebhookDeliveries(workspaceID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookDeliveries", reflect.TypeOf((*MockTx)(nil).GetWebhookDeliveries), workspaceID, limit)
+}
+
+// GetWorkspace mocks base method.
+func (m *MockTx) GetWorkspace(ctx context.Context, ID string) (*model.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspace", ctx, ID)
+	ret0, _ := ret[0].(*model.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspace indicates an expected call of GetWorkspace.
+func (mr *MockTxMockRecorder) GetWorkspace(ctx, ID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspace", reflect.TypeOf((*MockTx)(nil).GetWorkspace), ctx, ID)
+}
+
+// GetWorkspaceCount mocks base method.
+func (m *MockTx) GetWorkspaceCount(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceCount", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceCount indicates an expected call of GetWorkspaceCount.
+func (mr *MockTxMockRecorder) GetWorkspaceCount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceCount", reflect.TypeOf((*MockTx)(nil).GetWorkspaceCount), ctx)
+}
+
+// GetWorkspaceRetentionNotices mocks base method.
+func (m *MockTx) GetWorkspaceRetentionNotices(ctx context.Context) ([]model.WorkspaceRetentionNotice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceRetentionNotices", ctx)
+	ret0, _ := ret[0].([]model.WorkspaceRetentionNotice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceRetentionNotices indicates an expected call of GetWorkspaceRetentionNotices.
+func (mr *MockTxMockRecorder) GetWorkspaceRetentionNotices(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceRetentionNotices", reflect.TypeOf((*MockTx)(nil).GetWorkspaceRetentionNotices), ctx)
+}
+
+// GetWorkspaceStats mocks base method.
+func (m *MockTx) GetWorkspaceStats(ctx context.Context, now int64) (*model.WorkspaceStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceStats", ctx, now)
+	ret0, _ := ret[0].(*model.WorkspaceStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceStats indicates an expected call of GetWorkspaceStats.
+func (mr *MockTxMockRecorder) GetWorkspaceStats(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceStats", reflect.TypeOf((*MockTx)(nil).GetWorkspaceStats), ctx, now)
+}
+
+// GetWorkspaceUsage mocks base method.
+func (m *MockTx) GetWorkspaceUsage(workspaceID string) (*model.WorkspaceUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceUsage", workspaceID)
+	ret0, _ := ret[0].(*model.WorkspaceUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceUsage indicates an expected call of GetWorkspaceUsage.
+func (mr *MockTxMockRecorder) GetWorkspaceUsage(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceUsage", reflect.TypeOf((*MockTx)(nil).GetWorkspaceUsage), workspaceID)
+}
+
+// GetWorkspaces mocks base method.
+func (m *MockTx) GetWorkspaces(ctx context.Context, modifiedSince int64, limit int) ([]*model.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaces", ctx, modifiedSince, limit)
+	ret0, _ := ret[0].([]*model.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaces indicates an expected call of GetWorkspaces.
+func (mr *MockTxMockRecorder) GetWorkspaces(ctx, modifiedSince, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaces", reflect.TypeOf((*MockTx)(nil).GetWorkspaces), ctx, modifiedSince, limit)
+}
+
+// HasWorkspaceAccess mocks base method.
+func (m *MockTx) HasWorkspaceAccess(ctx context.Context, userID, workspaceID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasWorkspaceAccess", ctx, userID, workspaceID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasWorkspaceAccess indicates an expected call of HasWorkspaceAccess.
+func (mr *MockTxMockRecorder) HasWorkspaceAccess(ctx, userID, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasWorkspaceAccess", reflect.TypeOf((*MockTx)(nil).HasWorkspaceAccess), ctx, userID, workspaceID)
+}
+
+// InsertAuditEntry mocks base method.
+func (m *MockTx) InsertAuditEntry(entry model.AuditEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAuditEntry", entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertAuditEntry indicates an expected call of InsertAuditEntry.
+func (mr *MockTxMockRecorder) InsertAuditEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuditEntry", reflect.TypeOf((*MockTx)(nil).InsertAuditEntry), entry)
+}
+
+// InsertBlock mocks base method.
+func (m *MockTx) InsertBlock(ctx context.Context, c store.Container, block *model.Block, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBlock", ctx, c, block, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertBlock indicates an expected call of InsertBlock.
+func (mr *MockTxMockRecorder) InsertBlock(ctx, c, block, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlock", reflect.TypeOf((*MockTx)(nil).InsertBlock), ctx, c, block, userID)
+}
+
+// InsertBlocks mocks base method.
+func (m *MockTx) InsertBlocks(ctx context.Context, c store.Container, blocks []model.Block, userID string) (*model.BlocksUpsertResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBlocks", ctx, c, blocks, userID)
+	ret0, _ := ret[0].(*model.BlocksUpsertResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertBlocks indicates an expected call of InsertBlocks.
+func (mr *MockTxMockRecorder) InsertBlocks(ctx, c, blocks, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlocks", reflect.TypeOf((*MockTx)(nil).InsertBlocks), ctx, c, blocks, userID)
+}
+
+// InsertFileInfo mocks base method.
+func (m *MockTx) InsertFileInfo(info model.FileInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertFileInfo", info)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertFileInfo indicates an expected call of InsertFileInfo.
+func (mr *MockTxMockRecorder) InsertFileInfo(info interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertFileInfo", reflect.TypeOf((*MockTx)(nil).InsertFileInfo), info)
+}
+
+// InsertInboundHookDelivery mocks base method.
+func (m *MockTx) InsertInboundHookDelivery(c store.Container, delivery model.InboundHookDelivery, keep int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertInboundHookDelivery", c, delivery, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertInboundHookDelivery indicates an expected call of InsertInboundHookDelivery.
+func (mr *MockTxMockRecorder) InsertInboundHookDelivery(c, delivery, keep interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertInboundHookDelivery", reflect.TypeOf((*MockTx)(nil).InsertInboundHookDelivery), c, delivery, keep)
+}
+
+// InsertNotification mocks base method.
+func (m *MockTx) InsertNotification(notification model.Notification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertNotification", notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertNotification indicates an expected call of InsertNotification.
+func (mr *MockTxMockRecorder) InsertNotification(notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNotification", reflect.TypeOf((*MockTx)(nil).InsertNotification), notification)
+}
+
+// InsertRecurrenceRun mocks base method.
+func (m *MockTx) InsertRecurrenceRun(ctx context.Context, run model.RecurrenceRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRecurrenceRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertRecurrenceRun indicates an expected call of InsertRecurrenceRun.
+func (mr *MockTxMockRecorder) InsertRecurrenceRun(ctx, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRecurrenceRun", reflect.TypeOf((*MockTx)(nil).InsertRecurrenceRun), ctx, run)
+}
+
+// InsertReminderSent mocks base method.
+func (m *MockTx) InsertReminderSent(reminder model.ReminderSent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReminderSent", reminder)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertReminderSent indicates an expected call of InsertReminderSent.
+func (mr *MockTxMockRecorder) InsertReminderSent(reminder interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReminderSent", reflect.TypeOf((*MockTx)(nil).InsertReminderSent), reminder)
+}
+
+// InsertWebhookDelivery mocks base method.
+func (m *MockTx) InsertWebhookDelivery(delivery model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWebhookDelivery", delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWebhookDelivery indicates an expected call of InsertWebhookDelivery.
+func (mr *MockTxMockRecorder) InsertWebhookDelivery(delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWebhookDelivery", reflect.TypeOf((*MockTx)(nil).InsertWebhookDelivery), delivery)
+}
+
+// PatchBlock mocks base method.
+func (m *MockTx) PatchBlock(ctx context.Context, c store.Container, blockID string, blockPatch *model.BlockPatch, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchBlock", ctx, c, blockID, blockPatch, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchBlock indicates an expected call of PatchBlock.
+func (mr *MockTxMockRecorder) PatchBlock(ctx, c, blockID, blockPatch, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchBlock", reflect.TypeOf((*MockTx)(nil).PatchBlock), ctx, c, blockID, blockPatch, userID)
+}
+
+// Ping mocks base method.
+func (m *MockTx) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockTxMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockTx)(nil).Ping), ctx)
+}
+
+// PurgeDeletedBlocks mocks base method.
+func (m *MockTx) PurgeDeletedBlocks(ctx context.Context, deletedBefore int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedBlocks", ctx, deletedBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedBlocks indicates an expected call of PurgeDeletedBlocks.
+func (mr *MockTxMockRecorder) PurgeDeletedBlocks(ctx, deletedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedBlocks", reflect.TypeOf((*MockTx)(nil).PurgeDeletedBlocks), ctx, deletedBefore)
+}
+
+// RefreshSession mocks base method.
+func (m *MockTx) RefreshSession(session *model.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshSession indicates an expected call of RefreshSession.
+func (mr *MockTxMockRecorder) RefreshSession(session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockTx)(nil).RefreshSession), session)
+}
+
+// RegenerateSharingToken mocks base method.
+func (m *MockTx) RegenerateSharingToken(c store.Container, token model.SharingToken, previousToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegenerateSharingToken", c, token, previousToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegenerateSharingToken indicates an expected call of RegenerateSharingToken.
+func (mr *MockTxMockRecorder) RegenerateSharingToken(c, token, previousToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenerateSharingToken", reflect.TypeOf((*MockTx)(nil).RegenerateSharingToken), c, token, previousToken)
+}
+
+// RemoveWorkspaceMember mocks base method.
+func (m *MockTx) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWorkspaceMember", ctx, workspaceID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWorkspaceMember indicates an expected call of RemoveWorkspaceMember.
+func (mr *MockTxMockRecorder) RemoveWorkspaceMember(ctx, workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWorkspaceMember", reflect.TypeOf((*MockTx)(nil).RemoveWorkspaceMember), ctx, workspaceID, userID)
+}
+
+// RestoreBlock mocks base method.
+func (m *MockTx) RestoreBlock(ctx context.Context, c store.Container, blockID, modifiedBy string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreBlock", ctx, c, blockID, modifiedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreBlock indicates an expected call of RestoreBlock.
+func (mr *MockTxMockRecorder) RestoreBlock(ctx, c, blockID, modifiedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreBlock", reflect.TypeOf((*MockTx)(nil).RestoreBlock), ctx, c, blockID, modifiedBy)
+}
+
+// RevokeSharingToken mocks base method.
+func (m *MockTx) RevokeSharingToken(c store.Container, rootID, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSharingToken", c, rootID, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSharingToken indicates an expected call of RevokeSharingToken.
+func (mr *MockTxMockRecorder) RevokeSharingToken(c, rootID, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSharingToken", reflect.TypeOf((*MockTx)(nil).RevokeSharingToken), c, rootID, token)
+}
+
+// Rollback mocks base method.
+func (m *MockTx) Rollback() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockTxMockRecorder) Rollback() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockTx)(nil).Rollback))
+}
+
+// SearchBlocks mocks base method.
+func (m *MockTx) SearchBlocks(ctx context.Context, c store.Container, query string, limit int) ([]model.BlockSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchBlocks", ctx, c, query, limit)
+	ret0, _ := ret[0].([]model.BlockSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchBlocks indicates an expected call of SearchBlocks.
+func (mr *MockTxMockRecorder) SearchBlocks(ctx, c, query, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchBlocks", reflect.TypeOf((*MockTx)(nil).SearchBlocks), ctx, c, query, limit)
+}
+
+// SearchUsersByWorkspace mocks base method.
+func (m *MockTx) SearchUsersByWorkspace(ctx context.Context, workspaceID, prefix string, limit int) ([]model.UserSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsersByWorkspace", ctx, workspaceID, prefix, limit)
+	ret0, _ := ret[0].([]model.UserSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsersByWorkspace indicates an expected call of SearchUsersByWorkspace.
+func (mr *MockTxMockRecorder) SearchUsersByWorkspace(ctx, workspaceID, prefix, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsersByWorkspace", reflect.TypeOf((*MockTx)(nil).SearchUsersByWorkspace), ctx, workspaceID, prefix, limit)
+}
+
+// SetFileThumbnailPath mocks base method.
+func (m *MockTx) SetFileThumbnailPath(fileID, thumbnailPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFileThumbnailPath", fileID, thumbnailPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetFileThumbnailPath indicates an expected call of SetFileThumbnailPath.
+func (mr *MockTxMockRecorder) SetFileThumbnailPath(fileID, thumbnailPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFileThumbnailPath", reflect.TypeOf((*MockTx)(nil).SetFileThumbnailPath), fileID, thumbnailPath)
+}
+
+// SetIdempotencyKeyResponse mocks base method.
+func (m *MockTx) SetIdempotencyKeyResponse(userID, key string, statusCode int, response string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetIdempotencyKeyResponse", userID, key, statusCode, response)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetIdempotencyKeyResponse indicates an expected call of SetIdempotencyKeyResponse.
+func (mr *MockTxMockRecorder) SetIdempotencyKeyResponse(userID, key, statusCode, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetIdempotencyKeyResponse", reflect.TypeOf((*MockTx)(nil).SetIdempotencyKeyResponse), userID, key, statusCode, response)
+}
+
+// SetLegacySessionsExpireAt mocks base method.
+func (m *MockTx) SetLegacySessionsExpireAt(expireAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLegacySessionsExpireAt", expireAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLegacySessionsExpireAt indicates an expected call of SetLegacySessionsExpireAt.
+func (mr *MockTxMockRecorder) SetLegacySessionsExpireAt(expireAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLegacySessionsExpireAt", reflect.TypeOf((*MockTx)(nil).SetLegacySessionsExpireAt), expireAt)
+}
+
+// SetMfaRecoveryCodes mocks base method.
+func (m *MockTx) SetMfaRecoveryCodes(userID string, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMfaRecoveryCodes", userID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMfaRecoveryCodes indicates an expected call of SetMfaRecoveryCodes.
+func (mr *MockTxMockRecorder) SetMfaRecoveryCodes(userID, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMfaRecoveryCodes", reflect.TypeOf((*MockTx)(nil).SetMfaRecoveryCodes), userID, codeHashes)
+}
+
+// SetSystemSetting mocks base method.
+func (m *MockTx) SetSystemSetting(key, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSystemSetting", key, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSystemSetting indicates an expected call of SetSystemSetting.
+func (mr *MockTxMockRecorder) SetSystemSetting(key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSystemSetting", reflect.TypeOf((*MockTx)(nil).SetSystemSetting), key, value)
+}
+
+// SharesWorkspace mocks base method.
+func (m *MockTx) SharesWorkspace(ctx context.Context, userID, otherUserID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SharesWorkspace", ctx, userID, otherUserID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SharesWorkspace indicates an expected call of SharesWorkspace.
+func (mr *MockTxMockRecorder) SharesWorkspace(ctx, userID, otherUserID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SharesWorkspace", reflect.TypeOf((*MockTx)(nil).SharesWorkspace), ctx, userID, otherUserID)
+}
+
+// Shutdown mocks base method.
+func (m *MockTx) Shutdown() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockTxMockRecorder) Shutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockTx)(nil).Shutdown))
+}
+
+// StreamAllBlocks mocks base method.
+func (m *MockTx) StreamAllBlocks(ctx context.Context, c store.Container, fn func(model.Block) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamAllBlocks", ctx, c, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamAllBlocks indicates an expected call of StreamAllBlocks.
+func (mr *MockTxMockRecorder) StreamAllBlocks(ctx, c, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamAllBlocks", reflect.TypeOf((*MockTx)(nil).StreamAllBlocks), ctx, c, fn)
+}
+
+// StreamBlocksWithParentAndType mocks base method.
+func (m *MockTx) StreamBlocksWithParentAndType(ctx context.Context, c store.Container, parentID, blockType string, fn func(model.Block) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamBlocksWithParentAndType", ctx, c, parentID, blockType, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamBlocksWithParentAndType indicates an expected call of StreamBlocksWithParentAndType.
+func (mr *MockTxMockRecorder) StreamBlocksWithParentAndType(ctx, c, parentID, blockType, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamBlocksWithParentAndType", reflect.TypeOf((*MockTx)(nil).StreamBlocksWithParentAndType), ctx, c, parentID, blockType, fn)
+}
+
+// UpdateAccessTokenLastUsed mocks base method.
+func (m *MockTx) UpdateAccessTokenLastUsed(tokenID string, lastUsedAt int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAccessTokenLastUsed", tokenID, lastUsedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAccessTokenLastUsed indicates an expected call of UpdateAccessTokenLastUsed.
+func (mr *MockTxMockRecorder) UpdateAccessTokenLastUsed(tokenID, lastUsedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccessTokenLastUsed", reflect.TypeOf((*MockTx)(nil).UpdateAccessTokenLastUsed), tokenID, lastUsedAt)
+}
+
+// UpdateGitHubIntegrationSync mocks base method.
+func (m *MockTx) UpdateGitHubIntegrationSync(c store.Container, boardID string, lastSyncAt, rateLimitResetAt int64, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGitHubIntegrationSync", c, boardID, lastSyncAt, rateLimitResetAt, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGitHubIntegrationSync indicates an expected call of UpdateGitHubIntegrationSync.
+func (mr *MockTxMockRecorder) UpdateGitHubIntegrationSync(c, boardID, lastSyncAt, rateLimitResetAt, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGitHubIntegrationSync", reflect.TypeOf((*MockTx)(nil).UpdateGitHubIntegrationSync), c, boardID, lastSyncAt, rateLimitResetAt, lastError)
+}
+
+// UpdateSession mocks base method.
+func (m *MockTx) UpdateSession(session *model.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSession indicates an expected call of UpdateSession.
+func (mr *MockTxMockRecorder) UpdateSession(session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSession", reflect.TypeOf((*MockTx)(nil).UpdateSession), session)
 }
 
 // UpdateUser mocks base method.
-func (m *MockStore) UpdateUser(user *model.User) error {
+func (m *MockTx) UpdateUser(user *model.User) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUser", user)
 	ret0, _ := ret[0].(error)
@@ -558,13 +4663,55 @@ func (m *MockStore) UpdateUser(user *model.User) error {
 }
 
 // UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockStoreMockRecorder) UpdateUser(user interface{}) *gomock.Call {
+func (mr *MockTxMockRecorder) UpdateUser(user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockTx)(nil).UpdateUser), user)
+}
+
+// UpdateUserActive mocks base method.
+func (m *MockTx) UpdateUserActive(userID string, active bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserActive", userID, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserActive indicates an expected call of UpdateUserActive.
+func (mr *MockTxMockRecorder) UpdateUserActive(userID, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserActive", reflect.TypeOf((*MockTx)(nil).UpdateUserActive), userID, active)
+}
+
+// UpdateUserAuthData mocks base method.
+func (m *MockTx) UpdateUserAuthData(userID, authData string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserAuthData", userID, authData)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserAuthData indicates an expected call of UpdateUserAuthData.
+func (mr *MockTxMockRecorder) UpdateUserAuthData(userID, authData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserAuthData", reflect.TypeOf((*MockTx)(nil).UpdateUserAuthData), userID, authData)
+}
+
+// UpdateUserMfa mocks base method.
+func (m *MockTx) UpdateUserMfa(userID, mfaSecret string, mfaActive bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserMfa", userID, mfaSecret, mfaActive)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserMfa indicates an expected call of UpdateUserMfa.
+func (mr *MockTxMockRecorder) UpdateUserMfa(userID, mfaSecret, mfaActive interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserMfa", reflect.TypeOf((*MockTx)(nil).UpdateUserMfa), userID, mfaSecret, mfaActive)
 }
 
 // UpdateUserPassword mocks base method.
-func (m *MockStore) UpdateUserPassword(username, password string) error {
+func (m *MockTx) UpdateUserPassword(username, password string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserPassword", username, password)
 	ret0, _ := ret[0].(error)
@@ -572,13 +4719,13 @@ func (m *MockStore) UpdateUserPassword(username, password string) error {
 }
 
 // UpdateUserPassword indicates an expected call of UpdateUserPassword.
-func (mr *MockStoreMockRecorder) UpdateUserPassword(username, password interface{}) *gomock.Call {
+func (mr *MockTxMockRecorder) UpdateUserPassword(username, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockTx)(nil).UpdateUserPassword), username, password)
 }
 
 // UpdateUserPasswordByID mocks base method.
-func (m *MockStore) UpdateUserPasswordByID(userID, password string) error {
+func (m *MockTx) UpdateUserPasswordByID(userID, password string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateUserPasswordByID", userID, password)
 	ret0, _ := ret[0].(error)
@@ -586,13 +4733,111 @@ func (m *MockStore) UpdateUserPasswordByID(userID, password string) error {
 }
 
 // UpdateUserPasswordByID indicates an expected call of UpdateUserPasswordByID.
-func (mr *MockStoreMockRecorder) UpdateUserPasswordByID(userID, password interface{}) *gomock.Call {
+func (mr *MockTxMockRecorder) UpdateUserPasswordByID(userID, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPasswordByID", reflect.TypeOf((*MockStore)(nil).UpdateUserPasswordByID), userID, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPasswordByID", reflect.TypeOf((*MockTx)(nil).UpdateUserPasswordByID), userID, password)
+}
+
+// UpsertBoardMember mocks base method.
+func (m *MockTx) UpsertBoardMember(c store.Container, member model.BoardMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertBoardMember", c, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertBoardMember indicates an expected call of UpsertBoardMember.
+func (mr *MockTxMockRecorder) UpsertBoardMember(c, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertBoardMember", reflect.TypeOf((*MockTx)(nil).UpsertBoardMember), c, member)
+}
+
+// UpsertCalendarFeed mocks base method.
+func (m *MockTx) UpsertCalendarFeed(c store.Container, feed model.CalendarFeed) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertCalendarFeed", c, feed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertCalendarFeed indicates an expected call of UpsertCalendarFeed.
+func (mr *MockTxMockRecorder) UpsertCalendarFeed(c, feed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertCalendarFeed", reflect.TypeOf((*MockTx)(nil).UpsertCalendarFeed), c, feed)
+}
+
+// UpsertGitHubIntegration mocks base method.
+func (m *MockTx) UpsertGitHubIntegration(c store.Container, integration model.GitHubIntegration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertGitHubIntegration", c, integration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertGitHubIntegration indicates an expected call of UpsertGitHubIntegration.
+func (mr *MockTxMockRecorder) UpsertGitHubIntegration(c, integration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertGitHubIntegration", reflect.TypeOf((*MockTx)(nil).UpsertGitHubIntegration), c, integration)
+}
+
+// UpsertGitHubIssueLink mocks base method.
+func (m *MockTx) UpsertGitHubIssueLink(c store.Container, link model.GitHubIssueLink) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertGitHubIssueLink", c, link)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertGitHubIssueLink indicates an expected call of UpsertGitHubIssueLink.
+func (mr *MockTxMockRecorder) UpsertGitHubIssueLink(c, link interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertGitHubIssueLink", reflect.TypeOf((*MockTx)(nil).UpsertGitHubIssueLink), c, link)
+}
+
+// UpsertInboundHook mocks base method.
+func (m *MockTx) UpsertInboundHook(c store.Container, hook model.InboundHook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertInboundHook", c, hook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertInboundHook indicates an expected call of UpsertInboundHook.
+func (mr *MockTxMockRecorder) UpsertInboundHook(c, hook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertInboundHook", reflect.TypeOf((*MockTx)(nil).UpsertInboundHook), c, hook)
+}
+
+// UpsertLoginAttempts mocks base method.
+func (m *MockTx) UpsertLoginAttempts(attempts model.LoginAttempts) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertLoginAttempts", attempts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertLoginAttempts indicates an expected call of UpsertLoginAttempts.
+func (mr *MockTxMockRecorder) UpsertLoginAttempts(attempts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertLoginAttempts", reflect.TypeOf((*MockTx)(nil).UpsertLoginAttempts), attempts)
+}
+
+// UpsertPreferences mocks base method.
+func (m *MockTx) UpsertPreferences(ctx context.Context, userID string, preferences []model.Preference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertPreferences", ctx, userID, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertPreferences indicates an expected call of UpsertPreferences.
+func (mr *MockTxMockRecorder) UpsertPreferences(ctx, userID, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertPreferences", reflect.TypeOf((*MockTx)(nil).UpsertPreferences), ctx, userID, preferences)
 }
 
 // UpsertSharing mocks base method.
-func (m *MockStore) UpsertSharing(c store.Container, sharing model.Sharing) error {
+func (m *MockTx) UpsertSharing(c store.Container, sharing model.Sharing) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpsertSharing", c, sharing)
 	ret0, _ := ret[0].(error)
@@ -600,35 +4845,49 @@ func (m *MockStore) UpsertSharing(c store.Container, sharing model.Sharing) erro
 }
 
 // UpsertSharing indicates an expected call of UpsertSharing.
-func (mr *MockStoreMockRecorder) UpsertSharing(c, sharing interface{}) *gomock.Call {
+func (mr *MockTxMockRecorder) UpsertSharing(c, sharing interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertSharing", reflect.TypeOf((*MockStore)(nil).UpsertSharing), c, sharing)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertSharing", reflect.TypeOf((*MockTx)(nil).UpsertSharing), c, sharing)
+}
+
+// UpsertWorkspaceRetentionNotice mocks base method.
+func (m *MockTx) UpsertWorkspaceRetentionNotice(ctx context.Context, notice model.WorkspaceRetentionNotice) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceRetentionNotice", ctx, notice)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWorkspaceRetentionNotice indicates an expected call of UpsertWorkspaceRetentionNotice.
+func (mr *MockTxMockRecorder) UpsertWorkspaceRetentionNotice(ctx, notice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceRetentionNotice", reflect.TypeOf((*MockTx)(nil).UpsertWorkspaceRetentionNotice), ctx, notice)
 }
 
 // UpsertWorkspaceSettings mocks base method.
-func (m *MockStore) UpsertWorkspaceSettings(workspace model.Workspace) error {
+func (m *MockTx) UpsertWorkspaceSettings(ctx context.Context, workspace model.Workspace) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpsertWorkspaceSettings", workspace)
+	ret := m.ctrl.Call(m, "UpsertWorkspaceSettings", ctx, workspace)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpsertWorkspaceSettings indicates an expected call of UpsertWorkspaceSettings.
-func (mr *MockStoreMockRecorder) UpsertWorkspaceSettings(workspace interface{}) *gomock.Call {
+func (mr *MockTxMockRecorder) UpsertWorkspaceSettings(ctx, workspace interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceSettings", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceSettings), workspace)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceSettings", reflect.TypeOf((*MockTx)(nil).UpsertWorkspaceSettings), ctx, workspace)
 }
 
 // UpsertWorkspaceSignupToken mocks base method.
-func (m *MockStore) UpsertWorkspaceSignupToken(workspace model.Workspace) error {
+func (m *MockTx) UpsertWorkspaceSignupToken(ctx context.Context, workspace model.Workspace) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpsertWorkspaceSignupToken", workspace)
+	ret := m.ctrl.Call(m, "UpsertWorkspaceSignupToken", ctx, workspace)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpsertWorkspaceSignupToken indicates an expected call of UpsertWorkspaceSignupToken.
-func (mr *MockStoreMockRecorder) UpsertWorkspaceSignupToken(workspace interface{}) *gomock.Call {
+func (mr *MockTxMockRecorder) UpsertWorkspaceSignupToken(ctx, workspace interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceSignupToken", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceSignupToken), workspace)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceSignupToken", reflect.TypeOf((*MockTx)(nil).UpsertWorkspaceSignupToken), ctx, workspace)
 }
@@ -0,0 +1,148 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+)
+
+func accessTokenFields() []string {
+	return []string{
+		"id",
+		"token_hash",
+		"user_id",
+		"COALESCE(description, '')",
+		"create_at",
+		"COALESCE(last_used_at, 0)",
+		"COALESCE(expire_at, 0)",
+	}
+}
+
+func (s *SQLStore) accessTokensFromRows(rows *sql.Rows) ([]model.AccessToken, error) {
+	tokens := []model.AccessToken{}
+	for rows.Next() {
+		var token model.AccessToken
+		err := rows.Scan(
+			&token.ID,
+			&token.TokenHash,
+			&token.UserID,
+			&token.Description,
+			&token.CreateAt,
+			&token.LastUsedAt,
+			&token.ExpireAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// CreateAccessToken stores a personal access token. Only the hash of the
+// token is stored.
+func (s *SQLStore) CreateAccessToken(token model.AccessToken) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"access_tokens").
+		Columns(
+			"id",
+			"token_hash",
+			"user_id",
+			"description",
+			"create_at",
+			"last_used_at",
+			"expire_at",
+		).
+		Values(
+			token.ID,
+			token.TokenHash,
+			token.UserID,
+			token.Description,
+			token.CreateAt,
+			token.LastUsedAt,
+			token.ExpireAt,
+		)
+
+	_, err := query.Exec()
+	return err
+}
+
+// GetAccessTokenByHash returns the personal access token with the hash.
+func (s *SQLStore) GetAccessTokenByHash(tokenHash string) (*model.AccessToken, error) {
+	query := s.getQueryBuilder().
+		Select(accessTokenFields()...).
+		From(s.tablePrefix+"access_tokens").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		// the tokens of the deactivated users don't authenticate
+		Where("user_id NOT IN (SELECT id FROM " + s.tablePrefix + "users WHERE delete_at <> 0)")
+
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	tokens, err := s.accessTokensFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &tokens[0], nil
+}
+
+// GetAccessTokensForUser returns the personal access tokens of the user,
+// the most recent first.
+func (s *SQLStore) GetAccessTokensForUser(userID string) ([]model.AccessToken, error) {
+	query := s.getQueryBuilder().
+		Select(accessTokenFields()...).
+		From(s.tablePrefix+"access_tokens").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("create_at DESC", "id")
+
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.accessTokensFromRows(rows)
+}
+
+// UpdateAccessTokenLastUsed sets the last time the token was used.
+func (s *SQLStore) UpdateAccessTokenLastUsed(tokenID string, lastUsedAt int64) error {
+	query := s.getQueryBuilder().
+		Update(s.tablePrefix+"access_tokens").
+		Set("last_used_at", lastUsedAt).
+		Where(sq.Eq{"id": tokenID})
+
+	_, err := query.Exec()
+	return err
+}
+
+// DeleteAccessToken revokes a personal access token of the user.
+func (s *SQLStore) DeleteAccessToken(userID string, tokenID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "access_tokens").
+		Where(sq.Eq{"id": tokenID}).
+		Where(sq.Eq{"user_id": userID})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
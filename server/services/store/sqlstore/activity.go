@@ -0,0 +1,166 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// historyColumns returns the columns of the versions of the blocks in the
+// history table, coalesced as the rows recorded by older versions of
+// DeleteBlock only have the modification metadata.
+func (s *SQLStore) historyColumns(table string) []string {
+	return []string{
+		table + ".id",
+		"COALESCE(" + table + ".parent_id, '')",
+		"COALESCE(" + table + ".root_id, '')",
+		"COALESCE(" + table + ".created_by, '')",
+		"COALESCE(" + table + ".modified_by, '')",
+		"COALESCE(" + table + "." + s.escapeField("schema") + ", 0)",
+		"COALESCE(" + table + ".type, '')",
+		"COALESCE(" + table + ".title, '')",
+		"COALESCE(" + table + ".fields, '{}')",
+		"COALESCE(" + table + ".create_at, 0)",
+		"COALESCE(" + table + ".update_at, 0)",
+		"COALESCE(" + table + ".delete_at, 0)",
+	}
+}
+
+// GetCardActivity returns a page of the versions of the card and of its
+// children, the most recent first, each one along with the version it
+// replaced. Only the versions recorded before the given time, in
+// milliseconds, are returned, unless it's zero, and the boolean result
+// reports whether there are older versions after the page.
+func (s *SQLStore) GetCardActivity(ctx context.Context, c store.Container, cardID string, limit int, before int64) ([]model.BlockHistoryEntry, bool, error) {
+	if limit <= 0 {
+		limit = model.CardActivityDefaultPageSize
+	}
+
+	historyTable := s.tablePrefix + "blocks_history"
+
+	// the previous version of a block is the one inserted right before
+	previousInsertAt := sq.Select("MAX(earlier.insert_at)").
+		From(historyTable + " AS earlier").
+		Where("earlier.id = history.id").
+		Where("COALESCE(earlier.workspace_id, '0') = COALESCE(history.workspace_id, '0')").
+		Where("earlier.insert_at < history.insert_at")
+	previousInsertAtSQL, _, err := previousInsertAt.ToSql()
+	if err != nil {
+		return nil, false, err
+	}
+
+	query := s.getReadQueryBuilder(ctx).
+		Select(s.historyColumns("history")...).
+		Column("previous.id").
+		Columns(s.historyColumns("previous")[1:]...).
+		From(historyTable+" AS history").
+		LeftJoin(
+			historyTable+" AS previous ON previous.id = history.id"+
+				" AND COALESCE(previous.workspace_id, '0') = COALESCE(history.workspace_id, '0')"+
+				" AND previous.insert_at = ("+previousInsertAtSQL+")",
+		).
+		Where(sq.Eq{"COALESCE(history.workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Or{
+			sq.Eq{"history.id": cardID},
+			sq.Eq{"history.parent_id": cardID},
+		}).
+		OrderBy("history.update_at DESC", "history.insert_at DESC").
+		// fetch an extra row to know if there is a next page
+		Limit(uint64(limit) + 1)
+
+	if before != 0 {
+		query = query.Where(sq.Lt{"history.update_at": before})
+	}
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetCardActivity ERROR`, mlog.Err(err))
+		return nil, false, err
+	}
+	defer s.CloseRows(rows)
+
+	entries := []model.BlockHistoryEntry{}
+	for rows.Next() {
+		entry, err := s.historyEntryFromRow(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if len(entries) <= limit {
+		return entries, false, nil
+	}
+
+	// the next page starts strictly before the last version of this
+	// one, so the versions recorded at the same time as the first one
+	// of the next page are left for it
+	next := entries[limit]
+	entries = entries[:limit]
+	for len(entries) > 1 && entries[len(entries)-1].Block.UpdateAt == next.Block.UpdateAt {
+		entries = entries[:len(entries)-1]
+	}
+	return entries, true, nil
+}
+
+func (s *SQLStore) historyEntryFromRow(rows *sql.Rows) (model.BlockHistoryEntry, error) {
+	var entry model.BlockHistoryEntry
+	var previous model.Block
+	var previousID sql.NullString
+	var fieldsJSON, previousFieldsJSON string
+
+	err := rows.Scan(
+		&entry.Block.ID,
+		&entry.Block.ParentID,
+		&entry.Block.RootID,
+		&entry.Block.CreatedBy,
+		&entry.Block.ModifiedBy,
+		&entry.Block.Schema,
+		&entry.Block.Type,
+		&entry.Block.Title,
+		&fieldsJSON,
+		&entry.Block.CreateAt,
+		&entry.Block.UpdateAt,
+		&entry.Block.DeleteAt,
+		&previousID,
+		&previous.ParentID,
+		&previous.RootID,
+		&previous.CreatedBy,
+		&previous.ModifiedBy,
+		&previous.Schema,
+		&previous.Type,
+		&previous.Title,
+		&previousFieldsJSON,
+		&previous.CreateAt,
+		&previous.UpdateAt,
+		&previous.DeleteAt)
+	if err != nil {
+		s.logger.Error(`ERROR historyEntryFromRow`, mlog.Err(err))
+		return entry, err
+	}
+
+	if err := json.Unmarshal([]byte(fieldsJSON), &entry.Block.Fields); err != nil {
+		s.logger.Error(`ERROR historyEntryFromRow fields`, mlog.Err(err))
+		return entry, err
+	}
+
+	if previousID.Valid {
+		previous.ID = previousID.String
+		if err := json.Unmarshal([]byte(previousFieldsJSON), &previous.Fields); err != nil {
+			s.logger.Error(`ERROR historyEntryFromRow previous fields`, mlog.Err(err))
+			return entry, err
+		}
+		entry.Previous = &previous
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,115 @@
+package sqlstore
+
+import (
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// InsertAuditEntry stores an audit entry.
+func (s *SQLStore) InsertAuditEntry(entry model.AuditEntry) error {
+	payload, err := json.Marshal(entry.Payload)
+	if err != nil {
+		return err
+	}
+
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"audit_entries").
+		Columns(
+			"id",
+			"actor_id",
+			"workspace_id",
+			"action",
+			"entity_id",
+			"payload",
+			"create_at",
+		).
+		Values(
+			entry.ID,
+			entry.ActorID,
+			entry.WorkspaceID,
+			entry.Action,
+			entry.EntityID,
+			string(payload),
+			entry.CreateAt,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR InsertAuditEntry", mlog.String("action", entry.Action), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetAuditEntries returns a page of the audit entries matching the
+// options, the most recent first.
+func (s *SQLStore) GetAuditEntries(opts model.QueryAuditEntriesOptions) ([]model.AuditEntry, error) {
+	query := s.getQueryBuilder().
+		Select(
+			"id",
+			"COALESCE(actor_id, '')",
+			"COALESCE(workspace_id, '')",
+			"action",
+			"COALESCE(entity_id, '')",
+			"COALESCE(payload, '')",
+			"create_at",
+		).
+		From(s.tablePrefix+"audit_entries").
+		OrderBy("create_at DESC", "id")
+
+	if opts.ActorID != "" {
+		query = query.Where(sq.Eq{"actor_id": opts.ActorID})
+	}
+	if opts.WorkspaceID != "" {
+		query = query.Where(sq.Eq{"workspace_id": opts.WorkspaceID})
+	}
+	if opts.Action != "" {
+		query = query.Where(sq.Eq{"action": opts.Action})
+	}
+	if opts.Since > 0 {
+		query = query.Where(sq.GtOrEq{"create_at": opts.Since})
+	}
+	if opts.Until > 0 {
+		query = query.Where(sq.LtOrEq{"create_at": opts.Until})
+	}
+	if opts.PerPage > 0 {
+		query = query.Limit(uint64(opts.PerPage)).Offset(uint64(opts.Page * opts.PerPage))
+	}
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetAuditEntries", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	entries := []model.AuditEntry{}
+	for rows.Next() {
+		var entry model.AuditEntry
+		var payload string
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.WorkspaceID,
+			&entry.Action,
+			&entry.EntityID,
+			&payload,
+			&entry.CreateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if payload != "" {
+			if err := json.Unmarshal([]byte(payload), &entry.Payload); err != nil {
+				s.logger.Error("ERROR GetAuditEntries payload", mlog.String("id", entry.ID), mlog.Err(err))
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
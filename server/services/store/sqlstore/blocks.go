@@ -4,8 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/mattermost/focalboard/server/utils"
 
@@ -18,6 +19,16 @@ import (
 	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 )
 
+const (
+	// insertChunkSize is the maximum number of rows written by a
+	// single multi-row insert
+	insertChunkSize = 100
+
+	// sqliteMaxVariables is the default limit of bound parameters
+	// per statement of the bundled SQLite
+	sqliteMaxVariables = 999
+)
+
 type RootIDNilError struct{}
 
 func (re RootIDNilError) Error() string {
@@ -32,8 +43,14 @@ func (be BlockNotFoundErr) Error() string {
 	return fmt.Sprintf("block not found (block id: %s", be.blockID)
 }
 
-func (s *SQLStore) GetBlocksWithParentAndType(c store.Container, parentID string, blockType string) ([]model.Block, error) {
-	query := s.getQueryBuilder().
+// Unwrap returns sql.ErrNoRows, so that a missing block is handled like
+// any other missing row.
+func (be BlockNotFoundErr) Unwrap() error {
+	return sql.ErrNoRows
+}
+
+func (s *SQLStore) GetBlocksWithParentAndType(ctx context.Context, c store.Container, parentID string, blockType string) ([]model.Block, error) {
+	query := s.getReadQueryBuilder(ctx).
 		Select(
 			"id",
 			"parent_id",
@@ -47,13 +64,15 @@ func (s *SQLStore) GetBlocksWithParentAndType(c store.Container, parentID string
 			"create_at",
 			"update_at",
 			"delete_at",
+			"version",
 		).
 		From(s.tablePrefix + "blocks").
 		Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0}).
 		Where(sq.Eq{"parent_id": parentID}).
 		Where(sq.Eq{"type": blockType})
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error(`getBlocksWithParentAndType ERROR`, mlog.Err(err))
 
@@ -64,7 +83,11 @@ func (s *SQLStore) GetBlocksWithParentAndType(c store.Container, parentID string
 	return s.blocksFromRows(rows)
 }
 
-func (s *SQLStore) GetBlocksWithParent(c store.Container, parentID string) ([]model.Block, error) {
+// StreamBlocksWithParentAndType calls fn for each block of the type
+// with the parent, in creation order, reading the blocks one at a time
+// so they aren't all held in memory. It stops at the first error
+// returned by fn.
+func (s *SQLStore) StreamBlocksWithParentAndType(ctx context.Context, c store.Container, parentID string, blockType string, fn func(block model.Block) error) error {
 	query := s.getQueryBuilder().
 		Select(
 			"id",
@@ -79,12 +102,91 @@ func (s *SQLStore) GetBlocksWithParent(c store.Container, parentID string) ([]mo
 			"create_at",
 			"update_at",
 			"delete_at",
+			"version",
 		).
 		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0}).
 		Where(sq.Eq{"parent_id": parentID}).
-		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID})
+		Where(sq.Eq{"type": blockType}).
+		OrderBy("create_at", "id")
+
+	return s.streamBlocks(ctx, query, fn)
+}
+
+// StreamAllBlocks calls fn for each block of the container, reading
+// the blocks one at a time. It stops at the first error returned by fn.
+func (s *SQLStore) StreamAllBlocks(ctx context.Context, c store.Container, fn func(block model.Block) error) error {
+	query := s.getQueryBuilder().
+		Select(
+			"id",
+			"parent_id",
+			"root_id",
+			"created_by",
+			"modified_by",
+			s.escapeField("schema"),
+			"type",
+			"title",
+			"COALESCE(fields, '{}')",
+			"create_at",
+			"update_at",
+			"delete_at",
+			"version",
+		).
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0}).
+		OrderBy("root_id", "create_at", "id")
 
-	rows, err := query.Query()
+	return s.streamBlocks(ctx, query, fn)
+}
+
+func (s *SQLStore) streamBlocks(ctx context.Context, query sq.SelectBuilder, fn func(block model.Block) error) error {
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`streamBlocks ERROR`, mlog.Err(err))
+
+		return err
+	}
+	defer s.CloseRows(rows)
+
+	for rows.Next() {
+		block, err := s.blockFromRow(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(block); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (s *SQLStore) GetBlocksWithParent(ctx context.Context, c store.Container, parentID string) ([]model.Block, error) {
+	query := s.getReadQueryBuilder(ctx).
+		Select(
+			"id",
+			"parent_id",
+			"root_id",
+			"created_by",
+			"modified_by",
+			s.escapeField("schema"),
+			"type",
+			"title",
+			"COALESCE(fields, '{}')",
+			"create_at",
+			"update_at",
+			"delete_at",
+			"version",
+		).
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"parent_id": parentID}).
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
+
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error(`getBlocksWithParent ERROR`, mlog.Err(err))
 
@@ -95,8 +197,8 @@ func (s *SQLStore) GetBlocksWithParent(c store.Container, parentID string) ([]mo
 	return s.blocksFromRows(rows)
 }
 
-func (s *SQLStore) GetBlocksWithRootID(c store.Container, rootID string) ([]model.Block, error) {
-	query := s.getQueryBuilder().
+func (s *SQLStore) GetBlocksWithRootID(ctx context.Context, c store.Container, rootID string) ([]model.Block, error) {
+	query := s.getReadQueryBuilder(ctx).
 		Select(
 			"id",
 			"parent_id",
@@ -110,12 +212,14 @@ func (s *SQLStore) GetBlocksWithRootID(c store.Container, rootID string) ([]mode
 			"create_at",
 			"update_at",
 			"delete_at",
+			"version",
 		).
 		From(s.tablePrefix + "blocks").
 		Where(sq.Eq{"root_id": rootID}).
-		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID})
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error(`GetBlocksWithRootID ERROR`, mlog.Err(err))
 
@@ -126,8 +230,8 @@ func (s *SQLStore) GetBlocksWithRootID(c store.Container, rootID string) ([]mode
 	return s.blocksFromRows(rows)
 }
 
-func (s *SQLStore) GetBlocksWithType(c store.Container, blockType string) ([]model.Block, error) {
-	query := s.getQueryBuilder().
+func (s *SQLStore) GetBlocksWithType(ctx context.Context, c store.Container, blockType string) ([]model.Block, error) {
+	query := s.getReadQueryBuilder(ctx).
 		Select(
 			"id",
 			"parent_id",
@@ -141,12 +245,14 @@ func (s *SQLStore) GetBlocksWithType(c store.Container, blockType string) ([]mod
 			"create_at",
 			"update_at",
 			"delete_at",
+			"version",
 		).
 		From(s.tablePrefix + "blocks").
 		Where(sq.Eq{"type": blockType}).
-		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID})
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error(`getBlocksWithParentAndType ERROR`, mlog.Err(err))
 
@@ -157,8 +263,131 @@ func (s *SQLStore) GetBlocksWithType(c store.Container, blockType string) ([]mod
 	return s.blocksFromRows(rows)
 }
 
+// GetBlocksDigest returns the number of blocks matching the options
+// and their latest update time.
+func (s *SQLStore) GetBlocksDigest(ctx context.Context, c store.Container, opts model.QueryBlocksDigestOptions) (*model.BlocksDigest, error) {
+	query := s.getQueryBuilder().
+		Select("COUNT(*)", "COALESCE(MAX(update_at), 0)").
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
+
+	if !opts.All {
+		if opts.BlockType != "" {
+			query = query.Where(sq.Eq{"type": opts.BlockType})
+		}
+		if opts.ParentID != "" || opts.BlockType == "" {
+			query = query.Where(sq.Eq{"parent_id": opts.ParentID})
+		}
+	}
+
+	var digest model.BlocksDigest
+	if err := query.QueryRowContext(ctx).Scan(&digest.Count, &digest.UpdateAt); err != nil {
+		s.logger.Error(`GetBlocksDigest ERROR`, mlog.Err(err))
+
+		return nil, err
+	}
+
+	return &digest, nil
+}
+
+// insertAtText returns the SQL expression of the insertion time of the
+// blocks as fixed width text, which sorts like the time itself.
+func (s *SQLStore) insertAtText() string {
+	switch s.dbType {
+	case postgresDBType:
+		return "to_char(insert_at AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS.US')"
+	case mysqlDBType:
+		return "DATE_FORMAT(insert_at, '%Y-%m-%d %H:%i:%s.%f')"
+	default:
+		return "STRFTIME('%Y-%m-%d %H:%M:%f', insert_at)"
+	}
+}
+
+// GetBlocksPage returns a page of the blocks matching the options, in
+// the order they were first inserted, then by ID. The next cursor of
+// the page is empty if it's the last one. A non positive limit falls
+// back to model.BlocksDefaultPageSize.
+func (s *SQLStore) GetBlocksPage(ctx context.Context, c store.Container, opts model.QueryBlocksPageOptions) (*model.BlocksPage, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = model.BlocksDefaultPageSize
+	}
+
+	insertAt := s.insertAtText()
+	query := s.getReadQueryBuilder(ctx).
+		Select(
+			"id",
+			"parent_id",
+			"root_id",
+			"created_by",
+			"modified_by",
+			s.escapeField("schema"),
+			"type",
+			"title",
+			"COALESCE(fields, '{}')",
+			"create_at",
+			"update_at",
+			"delete_at",
+			"version",
+			insertAt,
+		).
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0}).
+		OrderBy(insertAt, "id").
+		// fetch an extra row to know if there is a next page
+		Limit(uint64(limit) + 1)
+
+	if !opts.All {
+		if opts.BlockType != "" {
+			query = query.Where(sq.Eq{"type": opts.BlockType})
+		}
+		if opts.ParentID != "" || opts.BlockType == "" {
+			query = query.Where(sq.Eq{"parent_id": opts.ParentID})
+		}
+	}
+	if opts.After != nil {
+		query = query.Where(sq.Or{
+			sq.Expr(insertAt+" > ?", opts.After.InsertAt),
+			sq.And{
+				sq.Expr(insertAt+" = ?", opts.After.InsertAt),
+				sq.Gt{"id": opts.After.ID},
+			},
+		})
+	}
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetBlocksPage ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	page := &model.BlocksPage{Blocks: []model.Block{}}
+	var cursor model.BlocksCursor
+	for rows.Next() {
+		if len(page.Blocks) == limit {
+			page.NextCursor = cursor.String()
+			break
+		}
+
+		block, err := s.blockFromRow(rows, &cursor.InsertAt)
+		if err != nil {
+			return nil, err
+		}
+		cursor.ID = block.ID
+		page.Blocks = append(page.Blocks, block)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
 // GetSubTree2 returns blocks within 2 levels of the given blockID.
-func (s *SQLStore) GetSubTree2(c store.Container, blockID string) ([]model.Block, error) {
+func (s *SQLStore) GetSubTree2(ctx context.Context, c store.Container, blockID string) ([]model.Block, error) {
 	query := s.getQueryBuilder().
 		Select(
 			"id",
@@ -173,12 +402,14 @@ func (s *SQLStore) GetSubTree2(c store.Container, blockID string) ([]model.Block
 			"create_at",
 			"update_at",
 			"delete_at",
+			"version",
 		).
 		From(s.tablePrefix + "blocks").
 		Where(sq.Or{sq.Eq{"id": blockID}, sq.Eq{"parent_id": blockID}}).
-		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID})
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error(`getSubTree ERROR`, mlog.Err(err))
 
@@ -190,7 +421,7 @@ func (s *SQLStore) GetSubTree2(c store.Container, blockID string) ([]model.Block
 }
 
 // GetSubTree3 returns blocks within 3 levels of the given blockID.
-func (s *SQLStore) GetSubTree3(c store.Container, blockID string) ([]model.Block, error) {
+func (s *SQLStore) GetSubTree3(ctx context.Context, c store.Container, blockID string) ([]model.Block, error) {
 	// This first subquery returns repeated blocks
 	query := s.getQueryBuilder().Select(
 		"l3.id",
@@ -205,12 +436,16 @@ func (s *SQLStore) GetSubTree3(c store.Container, blockID string) ([]model.Block
 		"l3.create_at",
 		"l3.update_at",
 		"l3.delete_at",
+		"l3.version",
 	).
 		From(s.tablePrefix + "blocks as l1").
 		Join(s.tablePrefix + "blocks as l2 on l2.parent_id = l1.id or l2.id = l1.id").
 		Join(s.tablePrefix + "blocks as l3 on l3.parent_id = l2.id or l3.id = l2.id").
 		Where(sq.Eq{"l1.id": blockID}).
-		Where(sq.Eq{"COALESCE(l3.workspace_id, '0')": c.WorkspaceID})
+		Where(sq.Eq{"COALESCE(l3.workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"l1.delete_at": 0}).
+		Where(sq.Eq{"l2.delete_at": 0}).
+		Where(sq.Eq{"l3.delete_at": 0})
 
 	if s.dbType == postgresDBType {
 		query = query.Options("DISTINCT ON (l3.id)")
@@ -218,7 +453,7 @@ func (s *SQLStore) GetSubTree3(c store.Container, blockID string) ([]model.Block
 		query = query.Distinct()
 	}
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error(`getSubTree3 ERROR`, mlog.Err(err))
 
@@ -229,8 +464,8 @@ func (s *SQLStore) GetSubTree3(c store.Container, blockID string) ([]model.Block
 	return s.blocksFromRows(rows)
 }
 
-func (s *SQLStore) GetAllBlocks(c store.Container) ([]model.Block, error) {
-	query := s.getQueryBuilder().
+func (s *SQLStore) GetAllBlocks(ctx context.Context, c store.Container) ([]model.Block, error) {
+	query := s.getReadQueryBuilder(ctx).
 		Select(
 			"id",
 			"parent_id",
@@ -244,11 +479,13 @@ func (s *SQLStore) GetAllBlocks(c store.Container) ([]model.Block, error) {
 			"create_at",
 			"update_at",
 			"delete_at",
+			"version",
 		).
 		From(s.tablePrefix + "blocks").
-		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID})
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error(`getAllBlocks ERROR`, mlog.Err(err))
 
@@ -259,59 +496,123 @@ func (s *SQLStore) GetAllBlocks(c store.Container) ([]model.Block, error) {
 	return s.blocksFromRows(rows)
 }
 
+// GetBlockHistory returns the previous versions of the block, including
+// the deletions, ordered by the time they were recorded.
+func (s *SQLStore) GetBlockHistory(ctx context.Context, c store.Container, blockID string, opts model.QueryBlockHistoryOptions) ([]model.Block, error) {
+	var order string
+	if opts.Descending {
+		order = " DESC "
+	}
+
+	// rows recorded by older versions of DeleteBlock only have the
+	// modification metadata, so every column is coalesced
+	query := s.getQueryBuilder().
+		Select(
+			"id",
+			"COALESCE(parent_id, '')",
+			"COALESCE(root_id, '')",
+			"COALESCE(created_by, '')",
+			"modified_by",
+			"COALESCE("+s.escapeField("schema")+", 0)",
+			"COALESCE(type, '')",
+			"COALESCE(title, '')",
+			"COALESCE(fields, '{}')",
+			"COALESCE(create_at, 0)",
+			"COALESCE(update_at, 0)",
+			"COALESCE(delete_at, 0)",
+			"COALESCE(version, 0)",
+		).
+		From(s.tablePrefix + "blocks_history").
+		Where(sq.Eq{"id": blockID}).
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		OrderBy("insert_at"+order, "update_at"+order)
+
+	if opts.Before != 0 {
+		query = query.Where(sq.Lt{"update_at": opts.Before})
+	}
+	if opts.Limit != 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetBlockHistory ERROR`, mlog.Err(err))
+
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.blocksFromRows(rows)
+}
+
 func (s *SQLStore) blocksFromRows(rows *sql.Rows) ([]model.Block, error) {
 	results := []model.Block{}
 
 	for rows.Next() {
-		var block model.Block
-		var fieldsJSON string
-		var modifiedBy sql.NullString
-
-		err := rows.Scan(
-			&block.ID,
-			&block.ParentID,
-			&block.RootID,
-			&block.CreatedBy,
-			&modifiedBy,
-			&block.Schema,
-			&block.Type,
-			&block.Title,
-			&fieldsJSON,
-			&block.CreateAt,
-			&block.UpdateAt,
-			&block.DeleteAt)
+		block, err := s.blockFromRow(rows)
 		if err != nil {
-			// handle this error
-			s.logger.Error(`ERROR blocksFromRows`, mlog.Err(err))
-
 			return nil, err
 		}
 
-		if modifiedBy.Valid {
-			block.ModifiedBy = modifiedBy.String
-		}
+		results = append(results, block)
+	}
 
-		err = json.Unmarshal([]byte(fieldsJSON), &block.Fields)
-		if err != nil {
-			// handle this error
-			s.logger.Error(`ERROR blocksFromRows fields`, mlog.Err(err))
+	return results, nil
+}
 
-			return nil, err
-		}
+// blockFromRow scans the block of the row, and the extra columns
+// selected after the block columns into extra.
+func (s *SQLStore) blockFromRow(rows *sql.Rows, extra ...interface{}) (model.Block, error) {
+	var block model.Block
+	var fieldsJSON string
+	var modifiedBy sql.NullString
+
+	dest := []interface{}{
+		&block.ID,
+		&block.ParentID,
+		&block.RootID,
+		&block.CreatedBy,
+		&modifiedBy,
+		&block.Schema,
+		&block.Type,
+		&block.Title,
+		&fieldsJSON,
+		&block.CreateAt,
+		&block.UpdateAt,
+		&block.DeleteAt,
+		&block.Version,
+	}
+	err := rows.Scan(append(dest, extra...)...)
+	if err != nil {
+		// handle this error
+		s.logger.Error(`ERROR blocksFromRows`, mlog.Err(err))
 
-		results = append(results, block)
+		return block, err
 	}
 
-	return results, nil
+	if modifiedBy.Valid {
+		block.ModifiedBy = modifiedBy.String
+	}
+
+	err = json.Unmarshal([]byte(fieldsJSON), &block.Fields)
+	if err != nil {
+		// handle this error
+		s.logger.Error(`ERROR blocksFromRows fields`, mlog.Err(err))
+
+		return block, err
+	}
+
+	return block, nil
 }
 
-func (s *SQLStore) GetRootID(c store.Container, blockID string) (string, error) {
+func (s *SQLStore) GetRootID(ctx context.Context, c store.Container, blockID string) (string, error) {
 	query := s.getQueryBuilder().Select("root_id").
 		From(s.tablePrefix + "blocks").
 		Where(sq.Eq{"id": blockID}).
-		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID})
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
 
-	row := query.QueryRow()
+	row := query.QueryRowContext(ctx)
 
 	var rootID string
 
@@ -323,13 +624,14 @@ func (s *SQLStore) GetRootID(c store.Container, blockID string) (string, error)
 	return rootID, nil
 }
 
-func (s *SQLStore) GetParentID(c store.Container, blockID string) (string, error) {
+func (s *SQLStore) GetParentID(ctx context.Context, c store.Container, blockID string) (string, error) {
 	query := s.getQueryBuilder().Select("parent_id").
 		From(s.tablePrefix + "blocks").
 		Where(sq.Eq{"id": blockID}).
-		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID})
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
 
-	row := query.QueryRow()
+	row := query.QueryRowContext(ctx)
 
 	var parentID string
 
@@ -341,134 +643,268 @@ func (s *SQLStore) GetParentID(c store.Container, blockID string) (string, error
 	return parentID, nil
 }
 
-func (s *SQLStore) InsertBlock(c store.Container, block *model.Block, userID string) error {
-	if block.RootID == "" {
-		return RootIDNilError{}
+func (s *SQLStore) InsertBlock(ctx context.Context, c store.Container, block *model.Block, userID string) error {
+	blocks := []model.Block{*block}
+	if _, err := s.InsertBlocks(ctx, c, blocks, userID); err != nil {
+		return err
 	}
 
-	fieldsJSON, err := json.Marshal(block.Fields)
-	if err != nil {
-		return err
+	*block = blocks[0]
+	return nil
+}
+
+// InsertBlocks inserts or updates the blocks in a single transaction,
+// or in the transaction of the store, so either all of them are applied
+// or none is. The blocks are
+// updated in place with their creation and modification metadata.
+func (s *SQLStore) InsertBlocks(ctx context.Context, c store.Container, blocks []model.Block, userID string) (*model.BlocksUpsertResult, error) {
+	result := &model.BlocksUpsertResult{
+		Inserted: []string{},
+		Updated:  []string{},
 	}
 
-	existingBlock, err := s.GetBlock(c, block.ID)
-	if err != nil {
-		return err
+	// a block can only be written once per statement, so for
+	// repeated IDs only the last occurrence is kept
+	lastIndexByID := map[string]int{}
+	for i := range blocks {
+		if blocks[i].RootID == "" {
+			return nil, RootIDNilError{}
+		}
+		lastIndexByID[blocks[i].ID] = i
 	}
 
-	ctx := context.Background()
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
+	uniqueBlocks := make([]*model.Block, 0, len(lastIndexByID))
+	for i := range blocks {
+		if lastIndexByID[blocks[i].ID] == i {
+			uniqueBlocks = append(uniqueBlocks, &blocks[i])
+		}
 	}
 
-	insertQuery := s.getQueryBuilder().Insert("").
-		Columns(
-			"workspace_id",
-			"id",
-			"parent_id",
-			"root_id",
-			"created_by",
-			"modified_by",
-			s.escapeField("schema"),
-			"type",
-			"title",
-			"fields",
-			"create_at",
-			"update_at",
-			"delete_at",
-		)
+	if len(uniqueBlocks) == 0 {
+		return result, nil
+	}
 
-	insertQueryValues := map[string]interface{}{
-		"workspace_id":          c.WorkspaceID,
-		"id":                    block.ID,
-		"parent_id":             block.ParentID,
-		"root_id":               block.RootID,
-		s.escapeField("schema"): block.Schema,
-		"type":                  block.Type,
-		"title":                 block.Title,
-		"fields":                fieldsJSON,
-		"delete_at":             block.DeleteAt,
-		"created_by":            block.CreatedBy,
-		"modified_by":           block.ModifiedBy,
-		"create_at":             block.CreateAt,
-		"update_at":             block.UpdateAt,
-	}
-
-	block.UpdateAt = utils.GetMillis()
-	block.ModifiedBy = userID
-
-	if existingBlock != nil {
-		// block with ID exists, so this is an update operation
-		query := s.getQueryBuilder().Update(s.tablePrefix+"blocks").
-			Where(sq.Eq{"id": block.ID}).
-			Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID}).
-			Set("parent_id", block.ParentID).
-			Set("root_id", block.RootID).
-			Set("modified_by", block.ModifiedBy).
-			Set(s.escapeField("schema"), block.Schema).
-			Set("type", block.Type).
-			Set("title", block.Title).
-			Set("fields", fieldsJSON).
-			Set("update_at", block.UpdateAt).
-			Set("delete_at", block.DeleteAt)
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		existingBlocks, err := s.getExistingBlocks(ctx, tx, c, uniqueBlocks)
+		if err != nil {
+			return err
+		}
 
-		q, args, err2 := query.ToSql()
-		if err2 != nil {
-			s.logger.Error("InsertBlock error converting update query object to SQL", mlog.Err(err2))
-			return err2
+		now := utils.GetMillis()
+		for _, block := range uniqueBlocks {
+			if existing, ok := existingBlocks[block.ID]; ok {
+				block.Version = existing.Version + 1
+				result.Updated = append(result.Updated, block.ID)
+			} else {
+				block.CreatedBy = userID
+				block.CreateAt = now
+				block.Version = 1
+				result.Inserted = append(result.Inserted, block.ID)
+			}
+			block.ModifiedBy = userID
+			block.UpdateAt = now
 		}
 
-		if _, err2 := tx.Exec(q, args...); err2 != nil {
-			s.logger.Error(`InsertBlock error occurred while updating existing block`, mlog.String("blockID", block.ID), mlog.Err(err2))
-			if rollbackErr := tx.Rollback(); rollbackErr != nil {
-				s.logger.Warn("Transaction rollback error", mlog.Err(rollbackErr))
+		if err := s.assignCardNumbers(ctx, tx, c, uniqueBlocks, existingBlocks); err != nil {
+			return err
+		}
+
+		chunkSize := s.insertChunkSize(len(blockInsertColumns))
+		for start := 0; start < len(uniqueBlocks); start += chunkSize {
+			end := start + chunkSize
+			if end > len(uniqueBlocks) {
+				end = len(uniqueBlocks)
+			}
+			chunk := uniqueBlocks[start:end]
+
+			query, err := s.blocksInsertQuery(c, s.tablePrefix+"blocks", chunk, existingBlocks)
+			if err != nil {
+				return err
+			}
+			query = s.blocksUpsertSuffix(query)
+
+			if _, err := sq.ExecContextWith(ctx, tx, query); err != nil {
+				s.logger.Error("InsertBlocks error upserting blocks", mlog.Int("chunk_start", start), mlog.Err(err))
+				return err
+			}
+
+			// writing block history
+			historyQuery, err := s.blocksInsertQuery(c, s.tablePrefix+"blocks_history", chunk, existingBlocks)
+			if err != nil {
+				return err
+			}
+
+			if _, err := sq.ExecContextWith(ctx, tx, historyQuery); err != nil {
+				s.logger.Error("InsertBlocks error writing block history", mlog.Int("chunk_start", start), mlog.Err(err))
+				return err
 			}
-			return err2
 		}
-	} else {
-		block.CreatedBy = userID
-		block.CreateAt = utils.GetMillis()
-		block.ModifiedBy = userID
-		block.UpdateAt = utils.GetMillis()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// duplicated blocks get the same metadata as the one written
+	for i := range blocks {
+		blocks[i] = blocks[lastIndexByID[blocks[i].ID]]
+	}
+
+	return result, nil
+}
+
+var blockInsertColumns = []string{
+	"workspace_id",
+	"id",
+	"parent_id",
+	"root_id",
+	"created_by",
+	"modified_by",
+	"schema",
+	"type",
+	"title",
+	"fields",
+	"create_at",
+	"update_at",
+	"delete_at",
+	"version",
+}
+
+// insertChunkSize returns how many rows a multi-row insert can hold
+// without going over the bound parameter limit of the database.
+func (s *SQLStore) insertChunkSize(columns int) int {
+	if s.dbType == sqliteDBType && columns*insertChunkSize > sqliteMaxVariables {
+		return sqliteMaxVariables / columns
+	}
+	return insertChunkSize
+}
 
-		insertQueryValues["created_by"] = block.CreatedBy
-		insertQueryValues["create_at"] = block.CreateAt
-		insertQueryValues["update_at"] = block.UpdateAt
-		insertQueryValues["modified_by"] = block.ModifiedBy
+// blocksInsertQuery builds a multi-row insert for the blocks. The
+// creation metadata of existing blocks is taken from the stored ones,
+// as it can't be changed by an update.
+func (s *SQLStore) blocksInsertQuery(c store.Container, table string, blocks []*model.Block, existingBlocks map[string]model.Block) (sq.InsertBuilder, error) {
+	columns := make([]string, len(blockInsertColumns))
+	for i, column := range blockInsertColumns {
+		columns[i] = s.escapeField(column)
+	}
 
-		query := insertQuery.SetMap(insertQueryValues)
-		_, err = sq.ExecContextWith(ctx, tx, query.Into(s.tablePrefix+"blocks"))
+	builder := s.getQueryBuilder()
+	if s.dbType == sqliteDBType {
+		// SQLite resolves numbered parameters with a linear search,
+		// which makes preparing statements with many rows slow
+		builder = builder.PlaceholderFormat(sq.Question)
+	}
+
+	query := builder.
+		Insert(table).
+		Columns(columns...)
+
+	for _, block := range blocks {
+		fieldsJSON, err := json.Marshal(block.Fields)
 		if err != nil {
-			if rollbackErr := tx.Rollback(); rollbackErr != nil {
-				s.logger.Warn("Transaction rollback error", mlog.Err(rollbackErr))
-			}
+			return query, err
+		}
 
-			return err
+		createdBy, createAt := block.CreatedBy, block.CreateAt
+		if existing, ok := existingBlocks[block.ID]; ok {
+			createdBy, createAt = existing.CreatedBy, existing.CreateAt
 		}
+
+		query = query.Values(
+			c.WorkspaceID,
+			block.ID,
+			block.ParentID,
+			block.RootID,
+			createdBy,
+			block.ModifiedBy,
+			block.Schema,
+			block.Type,
+			block.Title,
+			fieldsJSON,
+			createAt,
+			block.UpdateAt,
+			block.DeleteAt,
+			block.Version,
+		)
 	}
 
-	// writing block history
-	query := insertQuery.SetMap(insertQueryValues)
+	return query, nil
+}
 
-	_, err = sq.ExecContextWith(ctx, tx, query.Into(s.tablePrefix+"blocks_history"))
-	if err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			s.logger.Warn("Transaction rollback error", mlog.Err(rollbackErr))
+func (s *SQLStore) blocksUpsertSuffix(query sq.InsertBuilder) sq.InsertBuilder {
+	// creation metadata is left untouched when updating
+	updatedColumns := []string{"parent_id", "root_id", "modified_by", s.escapeField("schema"), "type", "title", "fields", "update_at", "delete_at"}
+
+	assignments := make([]string, len(updatedColumns))
+	for i, column := range updatedColumns {
+		if s.dbType == mysqlDBType {
+			assignments[i] = column + " = VALUES(" + column + ")"
+		} else {
+			assignments[i] = column + " = EXCLUDED." + column
 		}
-		return err
 	}
 
-	err = tx.Commit()
-	if err != nil {
-		return err
+	// the version is incremented by the statement itself, so that
+	// concurrent updates of a block don't get the same version
+	if s.dbType == mysqlDBType {
+		assignments = append(assignments, "version = version + 1")
+		return query.Suffix("ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", "))
 	}
+	assignments = append(assignments, "version = "+s.tablePrefix+"blocks.version + 1")
+	return query.Suffix("ON CONFLICT (workspace_id, id) DO UPDATE SET " + strings.Join(assignments, ", "))
+}
 
-	return nil
+// getExistingBlocks returns the creation metadata and the version of
+// the blocks that are already stored, indexed by ID.
+func (s *SQLStore) getExistingBlocks(ctx context.Context, tx *sql.Tx, c store.Container, blocks []*model.Block) (map[string]model.Block, error) {
+	existing := map[string]model.Block{}
+
+	for start := 0; start < len(blocks); start += insertChunkSize {
+		end := start + insertChunkSize
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+
+		ids := make([]string, 0, end-start)
+		for _, block := range blocks[start:end] {
+			ids = append(ids, block.ID)
+		}
+
+		query := s.getQueryBuilder().
+			Select("id", "COALESCE(created_by, '')", "COALESCE(create_at, 0)", "version").
+			From(s.tablePrefix + "blocks").
+			Where(sq.Eq{"id": ids}).
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID})
+
+		rows, err := sq.QueryContextWith(ctx, tx, query)
+		if err != nil {
+			s.logger.Error("getExistingBlocks ERROR", mlog.Err(err))
+			return nil, err
+		}
+
+		for rows.Next() {
+			var block model.Block
+			if err := rows.Scan(&block.ID, &block.CreatedBy, &block.CreateAt, &block.Version); err != nil {
+				s.CloseRows(rows)
+				return nil, err
+			}
+			existing[block.ID] = block
+		}
+		s.CloseRows(rows)
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return existing, nil
 }
 
-func (s *SQLStore) PatchBlock(c store.Container, blockID string, blockPatch *model.BlockPatch, userID string) error {
-	existingBlock, err := s.GetBlock(c, blockID)
+// PatchBlock applies the patch to the stored block. If the patch has an
+// expected version, the block is only updated while it still has that
+// version, and model.ErrBlockVersionConflict is returned otherwise.
+func (s *SQLStore) PatchBlock(ctx context.Context, c store.Container, blockID string, blockPatch *model.BlockPatch, userID string) error {
+	existingBlock, err := s.GetBlock(ctx, c, blockID)
 	if err != nil {
 		return err
 	}
@@ -476,73 +912,302 @@ func (s *SQLStore) PatchBlock(c store.Container, blockID string, blockPatch *mod
 		return BlockNotFoundErr{blockID}
 	}
 
+	if blockPatch.ExpectedVersion == nil {
+		block := blockPatch.Patch(existingBlock)
+		return s.InsertBlock(ctx, c, block, userID)
+	}
+	if existingBlock.Version != *blockPatch.ExpectedVersion {
+		return model.ErrBlockVersionConflict
+	}
 	block := blockPatch.Patch(existingBlock)
-	return s.InsertBlock(c, block, userID)
+	return s.updateBlockVersion(ctx, c, block, *blockPatch.ExpectedVersion, userID)
 }
 
-func (s *SQLStore) DeleteBlock(c store.Container, blockID string, modifiedBy string) error {
-	ctx := context.Background()
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
+// updateBlockVersion updates the stored block if it has the expected
+// version. The version is compared and incremented by the same
+// statement, so that of two concurrent updates expecting the same
+// version only one is applied.
+func (s *SQLStore) updateBlockVersion(ctx context.Context, c store.Container, block *model.Block, expectedVersion int64, userID string) error {
+	if block.RootID == "" {
+		return RootIDNilError{}
 	}
 
-	now := time.Now().Unix()
-	insertQuery := s.getQueryBuilder().Insert(s.tablePrefix+"blocks_history").
-		Columns(
-			"workspace_id",
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		block.ModifiedBy = userID
+		block.UpdateAt = utils.GetMillis()
+		block.Version = expectedVersion + 1
+
+		existingBlocks := map[string]model.Block{block.ID: *block}
+		if err := s.assignCardNumbers(ctx, tx, c, []*model.Block{block}, existingBlocks); err != nil {
+			return err
+		}
+
+		fieldsJSON, err := json.Marshal(block.Fields)
+		if err != nil {
+			return err
+		}
+
+		updateQuery := s.getQueryBuilder().
+			Update(s.tablePrefix+"blocks").
+			Set("parent_id", block.ParentID).
+			Set("root_id", block.RootID).
+			Set("modified_by", block.ModifiedBy).
+			Set(s.escapeField("schema"), block.Schema).
+			Set("type", block.Type).
+			Set("title", block.Title).
+			Set("fields", fieldsJSON).
+			Set("update_at", block.UpdateAt).
+			Set("version", sq.Expr("version + 1")).
+			Where(sq.Eq{"id": block.ID}).
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID}).
+			Where(sq.Eq{"delete_at": 0}).
+			Where(sq.Eq{"version": expectedVersion})
+
+		result, err := sq.ExecContextWith(ctx, tx, updateQuery)
+		if err != nil {
+			s.logger.Error("updateBlockVersion error updating block", mlog.String("blockID", block.ID), mlog.Err(err))
+			return err
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return model.ErrBlockVersionConflict
+		}
+
+		historyQuery, err := s.blocksInsertQuery(c, s.tablePrefix+"blocks_history", []*model.Block{block}, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := sq.ExecContextWith(ctx, tx, historyQuery); err != nil {
+			s.logger.Error("updateBlockVersion error writing block history", mlog.String("blockID", block.ID), mlog.Err(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteBlock moves the block to the trash by setting its delete_at
+// timestamp. Deleting a block that doesn't exist or is already in the
+// trash is a no-op.
+func (s *SQLStore) DeleteBlock(ctx context.Context, c store.Container, blockID string, modifiedBy string) error {
+	err := s.setBlockDeleteAt(ctx, c, blockID, modifiedBy, true)
+	if errors.Is(err, BlockNotFoundErr{blockID}) {
+		return nil
+	}
+	return err
+}
+
+// RestoreBlock takes the block out of the trash.
+func (s *SQLStore) RestoreBlock(ctx context.Context, c store.Container, blockID string, modifiedBy string) error {
+	return s.setBlockDeleteAt(ctx, c, blockID, modifiedBy, false)
+}
+
+// setBlockDeleteAt flags the block as deleted or restores it, and
+// records the change in the history table within the same transaction.
+func (s *SQLStore) setBlockDeleteAt(ctx context.Context, c store.Container, blockID string, modifiedBy string, deleted bool) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		selectQuery := s.getQueryBuilder().
+			Select(
+				"id",
+				"parent_id",
+				"root_id",
+				"created_by",
+				"modified_by",
+				s.escapeField("schema"),
+				"type",
+				"title",
+				"COALESCE(fields, '{}')",
+				"create_at",
+				"update_at",
+				"delete_at",
+				"version",
+			).
+			From(s.tablePrefix + "blocks").
+			Where(sq.Eq{"id": blockID}).
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID})
+
+		if deleted {
+			selectQuery = selectQuery.Where(sq.Eq{"delete_at": 0})
+		} else {
+			selectQuery = selectQuery.Where(sq.Gt{"delete_at": 0})
+		}
+
+		rows, err := sq.QueryContextWith(ctx, tx, selectQuery)
+		if err != nil {
+			s.logger.Error("setBlockDeleteAt ERROR", mlog.Err(err))
+			return err
+		}
+		blocks, err := s.blocksFromRows(rows)
+		s.CloseRows(rows)
+		if err != nil {
+			return err
+		}
+		if len(blocks) == 0 {
+			return BlockNotFoundErr{blockID}
+		}
+
+		block := blocks[0]
+		now := utils.GetMillis()
+		block.ModifiedBy = modifiedBy
+		block.UpdateAt = now
+		block.DeleteAt = 0
+		if deleted {
+			block.DeleteAt = now
+		}
+		block.Version++
+
+		updateQuery := s.getQueryBuilder().
+			Update(s.tablePrefix+"blocks").
+			Set("modified_by", block.ModifiedBy).
+			Set("update_at", block.UpdateAt).
+			Set("delete_at", block.DeleteAt).
+			Set("version", sq.Expr("version + 1")).
+			Where(sq.Eq{"id": blockID}).
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID})
+
+		if _, err := sq.ExecContextWith(ctx, tx, updateQuery); err != nil {
+			return err
+		}
+
+		historyQuery, err := s.blocksInsertQuery(c, s.tablePrefix+"blocks_history", []*model.Block{&block}, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := sq.ExecContextWith(ctx, tx, historyQuery); err != nil {
+			return err
+		}
+
+		if deleted {
+			// the users stop watching the deleted block, and the cards of
+			// a deleted board
+			subscriptionsQuery := s.getQueryBuilder().
+				Delete(s.tablePrefix+"subscriptions").
+				Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+				Where(sq.Or{
+					sq.Eq{"block_id": blockID},
+					sq.Expr("block_id IN (SELECT id FROM "+s.tablePrefix+"blocks WHERE root_id = ? OR parent_id = ?)", blockID, blockID),
+				})
+			if _, err := sq.ExecContextWith(ctx, tx, subscriptionsQuery); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetDeletedBlocks returns the blocks of the workspace that were moved
+// to the trash after the given timestamp, most recently deleted first.
+func (s *SQLStore) GetDeletedBlocks(ctx context.Context, c store.Container, since int64) ([]model.Block, error) {
+	query := s.getQueryBuilder().
+		Select(
 			"id",
+			"parent_id",
+			"root_id",
+			"created_by",
 			"modified_by",
+			s.escapeField("schema"),
+			"type",
+			"title",
+			"COALESCE(fields, '{}')",
+			"create_at",
 			"update_at",
 			"delete_at",
+			"version",
 		).
-		Values(
-			c.WorkspaceID,
-			blockID,
-			modifiedBy,
-			now,
-			now,
-		)
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Gt{"delete_at": since}).
+		Where(sq.Gt{"delete_at": 0}).
+		OrderBy("delete_at DESC", "id")
 
-	_, err = sq.ExecContextWith(ctx, tx, insertQuery)
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			s.logger.Warn("Transaction rollback error", mlog.Err(rollbackErr))
-		}
-		return err
+		s.logger.Error(`GetDeletedBlocks ERROR`, mlog.Err(err))
+
+		return nil, err
 	}
+	defer s.CloseRows(rows)
 
-	deleteQuery := s.getQueryBuilder().
-		Delete(s.tablePrefix + "blocks").
-		Where(sq.Eq{"id": blockID}).
-		Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID})
+	return s.blocksFromRows(rows)
+}
 
-	_, err = sq.ExecContextWith(ctx, tx, deleteQuery)
+// PurgeDeletedBlocks permanently removes the blocks of all workspaces
+// that were moved to the trash before the given timestamp, and returns
+// the number of removed blocks.
+// GetReferencedFileIDs returns the IDs of the files referenced by the
+// fileId field of the blocks of the container, including the blocks in
+// the trash as they can still be restored.
+func (s *SQLStore) GetReferencedFileIDs(ctx context.Context, c store.Container) ([]string, error) {
+	query := s.getQueryBuilder().
+		Select("COALESCE(fields, '{}')").
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID})
+
+	if s.dbType == postgresDBType {
+		query = query.Where("fields ->> 'fileId' <> ''")
+	} else {
+		// the serialized fields are scanned, and the matches are
+		// verified against the decoded fields
+		query = query.Where(sq.Like{"fields": `%"fileId"%`})
+	}
+
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			s.logger.Warn("Transaction rollback error", mlog.Err(rollbackErr))
+		s.logger.Error(`GetReferencedFileIDs ERROR`, mlog.Err(err))
+
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	fileIDs := []string{}
+	for rows.Next() {
+		var fieldsJSON string
+		if err := rows.Scan(&fieldsJSON); err != nil {
+			return nil, err
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(fieldsJSON), &fields); err != nil {
+			s.logger.Error("GetReferencedFileIDs: invalid fields", mlog.Err(err))
+			continue
+		}
+		if fileID, ok := fields["fileId"].(string); ok && fileID != "" {
+			fileIDs = append(fileIDs, fileID)
 		}
-		return err
 	}
 
-	err = tx.Commit()
+	return fileIDs, rows.Err()
+}
+
+func (s *SQLStore) PurgeDeletedBlocks(ctx context.Context, deletedBefore int64) (int64, error) {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "blocks").
+		Where(sq.Gt{"delete_at": 0}).
+		Where(sq.Lt{"delete_at": deletedBefore})
+
+	result, err := query.ExecContext(ctx)
 	if err != nil {
-		return err
+		s.logger.Error(`PurgeDeletedBlocks ERROR`, mlog.Err(err))
+		return 0, err
 	}
 
-	return nil
+	return result.RowsAffected()
 }
 
-func (s *SQLStore) GetBlockCountsByType() (map[string]int64, error) {
+func (s *SQLStore) GetBlockCountsByType(ctx context.Context) (map[string]int64, error) {
 	query := s.getQueryBuilder().
 		Select(
 			"type",
 			"COUNT(*) AS count",
 		).
 		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"delete_at": 0}).
 		GroupBy("type")
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error(`GetBlockCountsByType ERROR`, mlog.Err(err))
 
@@ -566,7 +1231,7 @@ func (s *SQLStore) GetBlockCountsByType() (map[string]int64, error) {
 	return m, nil
 }
 
-func (s *SQLStore) GetBlock(c store.Container, blockID string) (*model.Block, error) {
+func (s *SQLStore) GetBlock(ctx context.Context, c store.Container, blockID string) (*model.Block, error) {
 	query := s.getQueryBuilder().
 		Select(
 			"id",
@@ -581,12 +1246,14 @@ func (s *SQLStore) GetBlock(c store.Container, blockID string) (*model.Block, er
 			"create_at",
 			"update_at",
 			"delete_at",
+			"version",
 		).
 		From(s.tablePrefix + "blocks").
 		Where(sq.Eq{"id": blockID}).
-		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID})
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error(`GetBlock ERROR`, mlog.Err(err))
 		return nil, err
@@ -603,3 +1270,73 @@ func (s *SQLStore) GetBlock(c store.Container, blockID string) (*model.Block, er
 
 	return &blocks[0], nil
 }
+
+// GetBlocksByIDs returns the blocks of the workspace with the IDs that
+// exist and aren't deleted, in no particular order.
+func (s *SQLStore) GetBlocksByIDs(ctx context.Context, c store.Container, blockIDs []string) ([]model.Block, error) {
+	if len(blockIDs) == 0 {
+		return []model.Block{}, nil
+	}
+
+	query := s.getReadQueryBuilder(ctx).
+		Select(
+			"id",
+			"parent_id",
+			"root_id",
+			"created_by",
+			"modified_by",
+			s.escapeField("schema"),
+			"type",
+			"title",
+			"COALESCE(fields, '{}')",
+			"create_at",
+			"update_at",
+			"delete_at",
+			"version",
+		).
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"id": blockIDs}).
+		Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0})
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetBlocksByIDs ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.blocksFromRows(rows)
+}
+
+// GetBlockWorkspaceIDs returns the workspaces of the blocks with the IDs,
+// deleted or not, by block ID. The IDs of the blocks that don't exist
+// are left out, and a block ID can be in several workspaces.
+func (s *SQLStore) GetBlockWorkspaceIDs(ctx context.Context, blockIDs []string) (map[string][]string, error) {
+	workspaceIDs := map[string][]string{}
+	if len(blockIDs) == 0 {
+		return workspaceIDs, nil
+	}
+
+	query := s.getQueryBuilder().
+		Select("id", "COALESCE(workspace_id, '0')").
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"id": blockIDs}).
+		OrderBy("id", "workspace_id")
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetBlockWorkspaceIDs ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	for rows.Next() {
+		var blockID, workspaceID string
+		if err := rows.Scan(&blockID, &workspaceID); err != nil {
+			return nil, err
+		}
+		workspaceIDs[blockID] = append(workspaceIDs[blockID], workspaceID)
+	}
+	return workspaceIDs, rows.Err()
+}
@@ -0,0 +1,101 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func generateBlocks(n int) []model.Block {
+	blocks := make([]model.Block, 0, n)
+	for i := 0; i < n; i++ {
+		blocks = append(blocks, model.Block{
+			ID:       fmt.Sprintf("block-%d", i),
+			RootID:   "block-0",
+			ParentID: "block-0",
+			Type:     "card",
+			Title:    fmt.Sprintf("Card %d", i),
+			Fields:   map[string]interface{}{"index": i},
+		})
+	}
+	return blocks
+}
+
+func TestInsertBlocksRollback(t *testing.T) {
+	ctx := context.Background()
+	s, tearDown := SetupTests(t)
+	defer tearDown()
+	sqlStore := s.(*SQLStore)
+
+	if sqlStore.dbType != sqliteDBType {
+		t.Skip("the failing trigger is only defined for SQLite")
+	}
+
+	container := store.Container{WorkspaceID: "workspace-id-1"}
+
+	// make the insert of one block in the middle of the batch fail
+	_, err := sqlStore.db.Exec(`CREATE TRIGGER fail_block_3000 BEFORE INSERT ON ` + sqlStore.tablePrefix + `blocks
+		WHEN NEW.id = 'block-3000'
+		BEGIN SELECT RAISE(ABORT, 'constraint violation'); END`)
+	require.NoError(t, err)
+
+	_, err = sqlStore.InsertBlocks(ctx, container, generateBlocks(5000), "user-id-1")
+	require.Error(t, err)
+
+	blocks, err := sqlStore.GetAllBlocks(ctx, container)
+	require.NoError(t, err)
+	require.Empty(t, blocks)
+
+	var historyCount int
+	err = sqlStore.getQueryBuilder().
+		Select("COUNT(*)").
+		From(sqlStore.tablePrefix + "blocks_history").
+		Where("workspace_id = ?", container.WorkspaceID).
+		QueryRow().
+		Scan(&historyCount)
+	require.NoError(t, err)
+	require.Zero(t, historyCount)
+}
+
+func BenchmarkInsertBlocks(b *testing.B) {
+	ctx := context.Background()
+	container := store.Container{WorkspaceID: "workspace-id-1"}
+
+	b.Run("one by one", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s, tearDown := setupTests(b)
+			blocks := generateBlocks(5000)
+			b.StartTimer()
+
+			for j := range blocks {
+				if err := s.InsertBlock(ctx, container, &blocks[j], "user-id-1"); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.StopTimer()
+			tearDown()
+		}
+	})
+
+	b.Run("batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s, tearDown := setupTests(b)
+			blocks := generateBlocks(5000)
+			b.StartTimer()
+
+			if _, err := s.InsertBlocks(ctx, container, blocks, "user-id-1"); err != nil {
+				b.Fatal(err)
+			}
+
+			b.StopTimer()
+			tearDown()
+		}
+	})
+}
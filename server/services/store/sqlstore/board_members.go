@@ -0,0 +1,106 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GetBoardMembers returns the members of the board, in the order they
+// were added. A board without members is open to the workspace.
+func (s *SQLStore) GetBoardMembers(c store.Container, boardID string) ([]model.BoardMember, error) {
+	query := s.getQueryBuilder().
+		Select("board_id", "user_id", "role", "create_at").
+		From(s.tablePrefix + "board_members").
+		Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+		Where(sq.Eq{"board_id": boardID}).
+		OrderBy("create_at", "user_id")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetBoardMembers", mlog.String("boardID", boardID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	members := []model.BoardMember{}
+	for rows.Next() {
+		var member model.BoardMember
+		if err := rows.Scan(&member.BoardID, &member.UserID, &member.Role, &member.CreateAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetBoardRolesForUser returns the role of the user on each board of the
+// workspace that has members, or an empty role if the user isn't one of
+// them. The boards without members aren't returned.
+func (s *SQLStore) GetBoardRolesForUser(c store.Container, userID string) (map[string]string, error) {
+	query := s.getQueryBuilder().
+		Select("board_id").
+		Column(sq.Expr("MAX(CASE WHEN user_id = ? THEN role ELSE '' END)", userID)).
+		From(s.tablePrefix + "board_members").
+		Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+		GroupBy("board_id")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetBoardRolesForUser", mlog.String("userID", userID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	roles := map[string]string{}
+	for rows.Next() {
+		var boardID, role string
+		if err := rows.Scan(&boardID, &role); err != nil {
+			return nil, err
+		}
+		roles[boardID] = role
+	}
+
+	return roles, rows.Err()
+}
+
+// UpsertBoardMember adds the member to the board, or changes their role
+// if they're already a member.
+func (s *SQLStore) UpsertBoardMember(c store.Container, member model.BoardMember) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"board_members").
+		Columns("workspace_id", "board_id", "user_id", "role", "create_at").
+		Values(c.WorkspaceID, member.BoardID, member.UserID, member.Role, member.CreateAt)
+	if s.dbType == mysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE role = ?", member.Role)
+	} else {
+		query = query.Suffix("ON CONFLICT (workspace_id, board_id, user_id) DO UPDATE SET role = EXCLUDED.role")
+	}
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR UpsertBoardMember", mlog.String("boardID", member.BoardID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteBoardMember removes the user from the members of the board.
+// Removing a user who isn't a member isn't an error.
+func (s *SQLStore) DeleteBoardMember(c store.Container, boardID, userID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "board_members").
+		Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+		Where(sq.Eq{"board_id": boardID}).
+		Where(sq.Eq{"user_id": userID})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR DeleteBoardMember", mlog.String("boardID", boardID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GetBoardMetadata returns the comment count and the last content
+// update of every card of the board. The content of a card are its
+// child blocks, including the comments.
+func (s *SQLStore) GetBoardMetadata(ctx context.Context, c store.Container, boardID string) ([]model.CardMetadata, error) {
+	blocksTable := s.tablePrefix + "blocks"
+
+	query := s.getQueryBuilder().
+		Select(
+			"cards.id",
+			"COUNT(CASE WHEN content.type = 'comment' THEN 1 END)",
+			"COALESCE(MAX(content.update_at), 0)",
+		).
+		Column(sq.Expr(
+			"COALESCE((SELECT latest.modified_by FROM "+blocksTable+" AS latest"+
+				" WHERE latest.parent_id = cards.id AND latest.delete_at = 0 AND COALESCE(latest.workspace_id, '0') = ?"+
+				" ORDER BY latest.update_at DESC, latest.id LIMIT 1), '')",
+			c.WorkspaceID,
+		)).
+		From(blocksTable+" AS cards").
+		LeftJoin(
+			blocksTable+" AS content ON content.parent_id = cards.id AND content.delete_at = 0 AND COALESCE(content.workspace_id, '0') = ?",
+			c.WorkspaceID,
+		).
+		Where(sq.Eq{"COALESCE(cards.workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"cards.root_id": boardID}).
+		Where(sq.Eq{"cards.type": "card"}).
+		Where(sq.Eq{"cards.delete_at": 0}).
+		GroupBy("cards.id").
+		OrderBy("cards.id")
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetBoardMetadata ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	results := []model.CardMetadata{}
+	for rows.Next() {
+		var metadata model.CardMetadata
+		err := rows.Scan(
+			&metadata.CardID,
+			&metadata.CommentCount,
+			&metadata.LastContentUpdateAt,
+			&metadata.LastContentModifiedBy,
+		)
+		if err != nil {
+			s.logger.Error("ERROR GetBoardMetadata scan", mlog.Err(err))
+			return nil, err
+		}
+		results = append(results, metadata)
+	}
+
+	return results, nil
+}
+
+// checkedFilter returns the SQL condition of the checkboxes of the table
+// being checked.
+func (s *SQLStore) checkedFilter(table string) (string, error) {
+	switch s.dbType {
+	case mysqlDBType, sqliteDBType:
+		// the bundled sqlite driver is built without the JSON1
+		// extension, so we match against the serialized fields
+		return fmt.Sprintf("%s.fields LIKE '%%\"value\":true%%'", table), nil
+	case postgresDBType:
+		return fmt.Sprintf("%s.fields ->> 'value' = 'true'", table), nil
+	default:
+		return "", errUnsupportedDatabaseError
+	}
+}
+
+// GetChecklistProgress returns the number of checked checkboxes, and of
+// all the checkboxes, of the cards of the board that have any. The
+// checkboxes are either children of the cards, or nested in another
+// content block of the cards.
+func (s *SQLStore) GetChecklistProgress(ctx context.Context, c store.Container, boardID string) ([]model.ChecklistProgress, error) {
+	blocksTable := s.tablePrefix + "blocks"
+	checked, err := s.checkedFilter("checkboxes")
+	if err != nil {
+		return nil, fmt.Errorf("GetChecklistProgress - %w", err)
+	}
+
+	query := s.getReadQueryBuilder(ctx).
+		Select(
+			"cards.id",
+			"COUNT(CASE WHEN "+checked+" THEN 1 END)",
+			"COUNT(*)",
+		).
+		From(blocksTable+" AS checkboxes").
+		Join(
+			blocksTable+" AS parents ON parents.id = checkboxes.parent_id AND parents.delete_at = 0 AND COALESCE(parents.workspace_id, '0') = ?",
+			c.WorkspaceID,
+		).
+		Join(
+			blocksTable+" AS cards ON cards.id = CASE WHEN parents.type = 'card' THEN parents.id ELSE parents.parent_id END"+
+				" AND cards.type = 'card' AND cards.delete_at = 0 AND COALESCE(cards.workspace_id, '0') = ?",
+			c.WorkspaceID,
+		).
+		Where(sq.Eq{"COALESCE(checkboxes.workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"checkboxes.root_id": boardID}).
+		Where(sq.Eq{"checkboxes.type": "checkbox"}).
+		Where(sq.Eq{"checkboxes.delete_at": 0}).
+		GroupBy("cards.id").
+		OrderBy("cards.id")
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetChecklistProgress ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	results := []model.ChecklistProgress{}
+	for rows.Next() {
+		var progress model.ChecklistProgress
+		if err := rows.Scan(&progress.CardID, &progress.Checked, &progress.Total); err != nil {
+			s.logger.Error("ERROR GetChecklistProgress scan", mlog.Err(err))
+			return nil, err
+		}
+		results = append(results, progress)
+	}
+
+	return results, nil
+}
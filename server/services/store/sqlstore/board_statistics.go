@@ -0,0 +1,195 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// cardPropertyValue returns the SQL expression of the value of a card
+// property in the fields of the blocks of the table, or an empty string
+// if the card has no value.
+func (s *SQLStore) cardPropertyValue(table, propertyID string) (sq.Sqlizer, error) {
+	if propertyID == "" {
+		return sq.Expr("''"), nil
+	}
+
+	switch s.dbType {
+	case postgresDBType:
+		return sq.Expr("COALESCE("+table+".fields -> 'properties' ->> ?, '')", propertyID), nil
+	case mysqlDBType:
+		return sq.Expr(
+			"COALESCE(JSON_UNQUOTE(JSON_EXTRACT("+table+".fields, ?)), '')",
+			fmt.Sprintf(`$.properties."%s"`, propertyID),
+		), nil
+	case sqliteDBType:
+		// the bundled sqlite driver is built without the JSON1
+		// extension, so the value is cut from the serialized fields,
+		// where the IDs of the properties are only used as keys. The
+		// fields are stored as a blob, which doesn't compare equal to
+		// text
+		prefix := fmt.Sprintf(`"%s":"`, propertyID)
+		start := "instr(" + table + ".fields, ?) + ?"
+		return sq.Expr(
+			"CAST(CASE WHEN instr("+table+".fields, ?) > 0"+
+				" THEN substr("+table+".fields, "+start+", instr(substr("+table+".fields, "+start+"), '\"') - 1)"+
+				" ELSE '' END AS TEXT)",
+			prefix, prefix, len(prefix), prefix, len(prefix),
+		), nil
+	default:
+		return nil, errUnsupportedDatabaseError
+	}
+}
+
+// weekOf returns the SQL expression of the index of the week of the
+// timestamp column, from the start of the first week.
+func (s *SQLStore) weekOf(column string, start int64) sq.Sqlizer {
+	if s.dbType == mysqlDBType {
+		return sq.Expr("("+column+" - ?) DIV ?", start, model.BoardStatisticsWeekMillis)
+	}
+	return sq.Expr("("+column+" - ?) / ?", start, model.BoardStatisticsWeekMillis)
+}
+
+// notTemplate negates the template filter, for the blocks without the
+// isTemplate field, for which it's null, to pass it.
+func notTemplate(templateFilter string) string {
+	return "NOT COALESCE(" + templateFilter + ", FALSE)"
+}
+
+// CountCardsByProperty returns the number of cards of the board by value
+// of the property, the most common first. An empty property ID counts
+// all the cards under an empty value.
+func (s *SQLStore) CountCardsByProperty(ctx context.Context, c store.Container, boardID, propertyID string) ([]model.PropertyValueCount, error) {
+	blocksTable := s.tablePrefix + "blocks"
+	value, err := s.cardPropertyValue("cards", propertyID)
+	if err != nil {
+		return nil, err
+	}
+	templateFilter, err := s.templateBoardFilter("cards", true)
+	if err != nil {
+		return nil, err
+	}
+
+	values := s.getReadQueryBuilder(ctx).
+		Select().
+		Column(sq.Alias(value, "value")).
+		From(blocksTable + " AS cards").
+		Where(sq.Eq{"COALESCE(cards.workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"cards.root_id": boardID}).
+		Where(sq.Eq{"cards.type": "card"}).
+		Where(sq.Eq{"cards.delete_at": 0}).
+		Where(notTemplate(templateFilter))
+
+	query := s.getReadQueryBuilder(ctx).
+		Select("card_values.value", "COUNT(*) AS card_count").
+		FromSelect(values, "card_values").
+		GroupBy("card_values.value").
+		OrderBy("card_count DESC", "card_values.value")
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`CountCardsByProperty ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	counts := []model.PropertyValueCount{}
+	for rows.Next() {
+		var count model.PropertyValueCount
+		if err := rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// CountCardsCreatedByWeek returns the number of cards of the board
+// created since the start of the first week, by index of the week.
+func (s *SQLStore) CountCardsCreatedByWeek(ctx context.Context, c store.Container, boardID string, since int64) (map[int64]int64, error) {
+	blocksTable := s.tablePrefix + "blocks"
+	templateFilter, err := s.templateBoardFilter("cards", true)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.getReadQueryBuilder(ctx).
+		Select().
+		Column(sq.Alias(s.weekOf("cards.create_at", since), "week")).
+		Column("COUNT(*)").
+		From(blocksTable + " AS cards").
+		Where(sq.Eq{"COALESCE(cards.workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"cards.root_id": boardID}).
+		Where(sq.Eq{"cards.type": "card"}).
+		Where(sq.Eq{"cards.delete_at": 0}).
+		Where(sq.GtOrEq{"cards.create_at": since}).
+		Where(notTemplate(templateFilter)).
+		GroupBy("week")
+
+	return s.weeklyCounts(ctx, "CountCardsCreatedByWeek", query)
+}
+
+// CountCardsCompletedByWeek returns the number of cards of the board
+// whose select property was first set to the done option since the
+// start of the first week, by index of the week. The history of the
+// cards is used, so that the cards moved out of the option after are
+// still counted.
+func (s *SQLStore) CountCardsCompletedByWeek(ctx context.Context, c store.Container, boardID, propertyID, optionID string, since int64) (map[int64]int64, error) {
+	blocksTable := s.tablePrefix + "blocks"
+	value, err := s.cardPropertyValue("history", propertyID)
+	if err != nil {
+		return nil, err
+	}
+	templateFilter, err := s.templateBoardFilter("history", true)
+	if err != nil {
+		return nil, err
+	}
+
+	completions := s.getReadQueryBuilder(ctx).
+		Select("history.id", "MIN(history.update_at) AS completed_at").
+		From(s.tablePrefix + "blocks_history AS history").
+		Where(sq.Eq{"COALESCE(history.workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"history.root_id": boardID}).
+		Where(sq.Eq{"history.type": "card"}).
+		Where(sq.Eq{"history.delete_at": 0}).
+		Where(notTemplate(templateFilter)).
+		Where(sq.Expr("? = ?", value, optionID)).
+		GroupBy("history.id")
+
+	query := s.getReadQueryBuilder(ctx).
+		Select().
+		Column(sq.Alias(s.weekOf("completions.completed_at", since), "week")).
+		Column("COUNT(*)").
+		FromSelect(completions, "completions").
+		Join(blocksTable + " AS cards ON cards.id = completions.id AND cards.delete_at = 0").
+		Where(sq.GtOrEq{"completions.completed_at": since}).
+		GroupBy("week")
+
+	return s.weeklyCounts(ctx, "CountCardsCompletedByWeek", query)
+}
+
+func (s *SQLStore) weeklyCounts(ctx context.Context, name string, query sq.SelectBuilder) (map[int64]int64, error) {
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(name+" ERROR", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	counts := map[int64]int64{}
+	for rows.Next() {
+		var week, count int64
+		if err := rows.Scan(&week, &count); err != nil {
+			return nil, err
+		}
+		counts[week] = count
+	}
+
+	return counts, rows.Err()
+}
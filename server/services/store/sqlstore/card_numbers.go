@@ -0,0 +1,270 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// assignCardNumbers sets the number of the new cards among the blocks,
+// from the sequence of their board, in the order of the blocks. The new
+// cards that already have a number, like the imported ones, keep it,
+// and the sequence continues after it. The updated cards keep their
+// stored number when they don't have one. The card templates aren't
+// numbered.
+func (s *SQLStore) assignCardNumbers(ctx context.Context, tx *sql.Tx, c store.Container, blocks []*model.Block, existingBlocks map[string]model.Block) error {
+	type boardCards struct {
+		unnumbered []*model.Block
+		maxNumber  int64
+	}
+	boards := map[string]*boardCards{}
+	boardIDs := []string{}
+	updatedIDs := []string{}
+
+	for _, block := range blocks {
+		if block.Type != "card" || block.DeleteAt != 0 {
+			continue
+		}
+		if isTemplate, _ := block.Fields["isTemplate"].(bool); isTemplate {
+			continue
+		}
+
+		number, hasNumber := model.CardNumber(*block)
+		if _, ok := existingBlocks[block.ID]; ok {
+			if !hasNumber {
+				updatedIDs = append(updatedIDs, block.ID)
+			}
+			continue
+		}
+
+		cards, ok := boards[block.RootID]
+		if !ok {
+			cards = &boardCards{}
+			boards[block.RootID] = cards
+			boardIDs = append(boardIDs, block.RootID)
+		}
+		if hasNumber {
+			if number > cards.maxNumber {
+				cards.maxNumber = number
+			}
+		} else {
+			cards.unnumbered = append(cards.unnumbered, block)
+		}
+	}
+
+	for _, boardID := range boardIDs {
+		cards := boards[boardID]
+		last, err := s.advanceBoardSequence(ctx, tx, boardID, cards.maxNumber, int64(len(cards.unnumbered)))
+		if err != nil {
+			return err
+		}
+		next := last - int64(len(cards.unnumbered)) + 1
+		for i, card := range cards.unnumbered {
+			if card.Fields == nil {
+				card.Fields = map[string]interface{}{}
+			}
+			card.Fields[model.CardNumberField] = next + int64(i)
+		}
+	}
+
+	if len(updatedIDs) == 0 {
+		return nil
+	}
+	numbers, err := s.getCardNumbers(ctx, tx, c, updatedIDs)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		if number, ok := numbers[block.ID]; ok {
+			if block.Fields == nil {
+				block.Fields = map[string]interface{}{}
+			}
+			block.Fields[model.CardNumberField] = number
+		}
+	}
+	return nil
+}
+
+// advanceBoardSequence raises the last number of the sequence of the
+// board to at least atLeast, then advances it by count, and returns it.
+func (s *SQLStore) advanceBoardSequence(ctx context.Context, tx *sql.Tx, boardID string, atLeast, count int64) (int64, error) {
+	table := s.tablePrefix + "board_sequences"
+
+	if s.dbType == postgresDBType {
+		query := s.getQueryBuilder().
+			Insert(table).
+			Columns("board_id", "last_number").
+			Values(boardID, atLeast+count).
+			Suffix("ON CONFLICT (board_id) DO UPDATE SET last_number = GREATEST("+table+".last_number, ?) + ? RETURNING last_number", atLeast, count)
+
+		var last int64
+		if err := query.RunWith(tx).QueryRowContext(ctx).Scan(&last); err != nil {
+			s.logger.Error("ERROR advanceBoardSequence", mlog.String("boardID", boardID), mlog.Err(err))
+			return 0, err
+		}
+		return last, nil
+	}
+
+	// MySQL locks the row until the end of the transaction, and SQLite
+	// only runs one writing transaction at a time
+	query := s.getQueryBuilder().
+		Select("last_number").
+		From(table).
+		Where(sq.Eq{"board_id": boardID})
+	if s.dbType == mysqlDBType {
+		query = query.Suffix("FOR UPDATE")
+	}
+
+	var current int64
+	err := query.RunWith(tx).QueryRowContext(ctx).Scan(&current)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		insert := s.getQueryBuilder().
+			Insert(table).
+			Columns("board_id", "last_number").
+			Values(boardID, atLeast+count)
+		if _, err := sq.ExecContextWith(ctx, tx, insert); err != nil {
+			s.logger.Error("ERROR advanceBoardSequence", mlog.String("boardID", boardID), mlog.Err(err))
+			return 0, err
+		}
+		return atLeast + count, nil
+	case err != nil:
+		s.logger.Error("ERROR advanceBoardSequence", mlog.String("boardID", boardID), mlog.Err(err))
+		return 0, err
+	}
+
+	last := current
+	if atLeast > last {
+		last = atLeast
+	}
+	last += count
+
+	update := s.getQueryBuilder().
+		Update(table).
+		Set("last_number", last).
+		Where(sq.Eq{"board_id": boardID})
+	if _, err := sq.ExecContextWith(ctx, tx, update); err != nil {
+		s.logger.Error("ERROR advanceBoardSequence", mlog.String("boardID", boardID), mlog.Err(err))
+		return 0, err
+	}
+	return last, nil
+}
+
+// getCardNumbers returns the stored numbers of the cards, indexed by ID.
+func (s *SQLStore) getCardNumbers(ctx context.Context, tx *sql.Tx, c store.Container, cardIDs []string) (map[string]int64, error) {
+	numbers := map[string]int64{}
+	for start := 0; start < len(cardIDs); start += insertChunkSize {
+		end := start + insertChunkSize
+		if end > len(cardIDs) {
+			end = len(cardIDs)
+		}
+
+		query := s.getQueryBuilder().
+			Select("id", "COALESCE(fields, '{}')").
+			From(s.tablePrefix + "blocks").
+			Where(sq.Eq{"id": cardIDs[start:end]}).
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID})
+
+		rows, err := sq.QueryContextWith(ctx, tx, query)
+		if err != nil {
+			s.logger.Error("getCardNumbers ERROR", mlog.Err(err))
+			return nil, err
+		}
+
+		for rows.Next() {
+			var block model.Block
+			var fieldsJSON string
+			if err := rows.Scan(&block.ID, &fieldsJSON); err != nil {
+				s.CloseRows(rows)
+				return nil, err
+			}
+			if err := json.Unmarshal([]byte(fieldsJSON), &block.Fields); err != nil {
+				s.CloseRows(rows)
+				return nil, err
+			}
+			if number, ok := model.CardNumber(block); ok {
+				numbers[block.ID] = number
+			}
+		}
+		s.CloseRows(rows)
+
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return numbers, nil
+}
+
+// GetCardByNumber returns the card of the board with the number, or nil
+// if there is none.
+func (s *SQLStore) GetCardByNumber(ctx context.Context, c store.Container, boardID string, number int64) (*model.Block, error) {
+	var filter sq.Sqlizer
+	switch s.dbType {
+	case postgresDBType:
+		filter = sq.Expr("fields ->> ? = ?", model.CardNumberField, fmt.Sprint(number))
+	case mysqlDBType:
+		filter = sq.Expr("JSON_EXTRACT(fields, ?) = ?", "$."+model.CardNumberField, number)
+	case sqliteDBType:
+		// the bundled sqlite driver is built without the JSON1
+		// extension, so we match against the serialized fields, where
+		// the number is followed by the next field or the end
+		value := fmt.Sprintf(`"%s":%d`, model.CardNumberField, number)
+		filter = sq.Or{
+			sq.Expr("fields LIKE ?", "%"+value+",%"),
+			sq.Expr("fields LIKE ?", "%"+value+"}%"),
+		}
+	default:
+		return nil, errUnsupportedDatabaseError
+	}
+
+	query := s.getReadQueryBuilder(ctx).
+		Select(
+			"id",
+			"parent_id",
+			"root_id",
+			"created_by",
+			"modified_by",
+			s.escapeField("schema"),
+			"type",
+			"title",
+			"COALESCE(fields, '{}')",
+			"create_at",
+			"update_at",
+			"delete_at",
+			"version",
+		).
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"root_id": boardID}).
+		Where(sq.Eq{"type": "card"}).
+		Where(sq.Eq{"delete_at": 0}).
+		Where(filter)
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetCardByNumber ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	blocks, err := s.blocksFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range blocks {
+		if cardNumber, ok := model.CardNumber(blocks[i]); ok && cardNumber == number {
+			return &blocks[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,63 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// AcquireClusterLock takes or renews the named lock for the owner until
+// expireAt. It returns false if the lock is held by another owner and
+// hasn't expired yet.
+func (s *SQLStore) AcquireClusterLock(name, owner string, expireAt int64) (bool, error) {
+	result, err := s.getQueryBuilder().
+		Update(s.tablePrefix+"cluster_locks").
+		Set("owner", owner).
+		Set("expire_at", expireAt).
+		Where(sq.Eq{"name": name}).
+		Where(sq.Or{
+			sq.Eq{"owner": owner},
+			sq.Lt{"expire_at": utils.GetMillis()},
+		}).
+		Exec()
+	if err != nil {
+		s.logger.Error("ERROR AcquireClusterLock", mlog.String("name", name), mlog.Err(err))
+		return false, err
+	}
+
+	if count, err := result.RowsAffected(); err == nil && count > 0 {
+		return true, nil
+	}
+
+	// the lock doesn't exist yet, or is held by another owner
+	_, err = s.getQueryBuilder().
+		Insert(s.tablePrefix+"cluster_locks").
+		Columns("name", "owner", "expire_at").
+		Values(name, owner, expireAt).
+		Exec()
+	if err == nil {
+		return true, nil
+	}
+
+	// the insert fails if the lock already exists, so check who holds it
+	var currentOwner string
+	err = s.getQueryBuilder().
+		Select("owner").
+		From(s.tablePrefix + "cluster_locks").
+		Where(sq.Eq{"name": name}).
+		QueryRow().
+		Scan(&currentOwner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		s.logger.Error("ERROR AcquireClusterLock", mlog.String("name", name), mlog.Err(err))
+		return false, err
+	}
+
+	return currentOwner == owner, nil
+}
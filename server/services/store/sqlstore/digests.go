@@ -0,0 +1,69 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GetLastDigestAt returns the time the last digest was sent to the user,
+// or 0 if none was.
+func (s *SQLStore) GetLastDigestAt(ctx context.Context, userID string) (int64, error) {
+	query := s.getQueryBuilder().
+		Select("last_digest_at").
+		From(s.tablePrefix + "digests").
+		Where(sq.Eq{"user_id": userID})
+
+	var lastDigestAt int64
+	err := query.QueryRowContext(ctx).Scan(&lastDigestAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		s.logger.Error("ERROR GetLastDigestAt", mlog.String("userID", userID), mlog.Err(err))
+		return 0, err
+	}
+	return lastDigestAt, nil
+}
+
+// ClaimUserDigest records that the digest is sent to the user at
+// digestAt, unless another digest was sent since lastDigestAt. It returns
+// false when the digest was already claimed, so that a digest is only
+// sent once even if the job runs again or on another server.
+func (s *SQLStore) ClaimUserDigest(ctx context.Context, userID string, lastDigestAt, digestAt int64) (bool, error) {
+	var result sql.Result
+	var err error
+	if lastDigestAt == 0 {
+		query := s.getQueryBuilder().
+			Insert(s.tablePrefix+"digests").
+			Columns("user_id", "last_digest_at").
+			Values(userID, digestAt)
+		if s.dbType == mysqlDBType {
+			query = query.Suffix("ON DUPLICATE KEY UPDATE user_id = user_id")
+		} else {
+			query = query.Suffix("ON CONFLICT (user_id) DO NOTHING")
+		}
+		result, err = query.ExecContext(ctx)
+	} else {
+		query := s.getQueryBuilder().
+			Update(s.tablePrefix+"digests").
+			Set("last_digest_at", digestAt).
+			Where(sq.Eq{"user_id": userID}).
+			Where(sq.Eq{"last_digest_at": lastDigestAt})
+		result, err = query.ExecContext(ctx)
+	}
+	if err != nil {
+		s.logger.Error("ERROR ClaimUserDigest", mlog.String("userID", userID), mlog.Err(err))
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,145 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// InsertFileInfo records a file uploaded to a workspace.
+func (s *SQLStore) InsertFileInfo(info model.FileInfo) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"files").
+		Columns(
+			"id",
+			"workspace_id",
+			"root_id",
+			"size",
+			"create_at",
+			"path",
+			"thumbnail_path",
+			"name",
+			"mime_type",
+		).
+		Values(
+			info.ID,
+			info.WorkspaceID,
+			info.RootID,
+			info.Size,
+			info.CreateAt,
+			info.Path,
+			info.ThumbnailPath,
+			info.Name,
+			info.MimeType,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR InsertFileInfo", mlog.String("fileID", info.ID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteFileInfo removes the record of a file, once the file is removed
+// from the files storage. Removing a file without a record isn't an
+// error.
+func (s *SQLStore) DeleteFileInfo(fileID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "files").
+		Where(sq.Eq{"id": fileID})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR DeleteFileInfo", mlog.String("fileID", fileID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetFileInfo returns the record of a file, or sql.ErrNoRows if the file
+// has none.
+func (s *SQLStore) GetFileInfo(fileID string) (*model.FileInfo, error) {
+	query := s.getQueryBuilder().
+		Select(
+			"id",
+			"workspace_id",
+			"root_id",
+			"size",
+			"create_at",
+			"COALESCE(path, '')",
+			"COALESCE(thumbnail_path, '')",
+			"COALESCE(name, '')",
+			"COALESCE(mime_type, '')",
+		).
+		From(s.tablePrefix + "files").
+		Where(sq.Eq{"id": fileID})
+
+	var info model.FileInfo
+	err := query.QueryRow().Scan(
+		&info.ID,
+		&info.WorkspaceID,
+		&info.RootID,
+		&info.Size,
+		&info.CreateAt,
+		&info.Path,
+		&info.ThumbnailPath,
+		&info.Name,
+		&info.MimeType,
+	)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.Error("ERROR GetFileInfo", mlog.String("fileID", fileID), mlog.Err(err))
+		}
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// SetFileThumbnailPath records the path of the generated thumbnail of a
+// file.
+func (s *SQLStore) SetFileThumbnailPath(fileID, thumbnailPath string) error {
+	query := s.getQueryBuilder().
+		Update(s.tablePrefix+"files").
+		Set("thumbnail_path", thumbnailPath).
+		Where(sq.Eq{"id": fileID})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR SetFileThumbnailPath", mlog.String("fileID", fileID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetWorkspaceUsage counts the blocks of the workspace that aren't
+// deleted, and sums the sizes of its recorded files.
+func (s *SQLStore) GetWorkspaceUsage(workspaceID string) (*model.WorkspaceUsage, error) {
+	usage := &model.WorkspaceUsage{}
+
+	blocksQuery := s.getQueryBuilder().
+		Select("COUNT(*)").
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"COALESCE(workspace_id, '0')": workspaceID}).
+		Where(sq.Eq{"delete_at": 0})
+	if err := blocksQuery.QueryRow().Scan(&usage.BlockCount); err != nil {
+		s.logger.Error("ERROR GetWorkspaceUsage blocks", mlog.String("workspaceID", workspaceID), mlog.Err(err))
+		return nil, err
+	}
+
+	filesQuery := s.getQueryBuilder().
+		Select("COALESCE(SUM(size), 0)").
+		From(s.tablePrefix + "files").
+		Where(sq.Eq{"workspace_id": workspaceID})
+	if err := filesQuery.QueryRow().Scan(&usage.FileBytes); err != nil {
+		s.logger.Error("ERROR GetWorkspaceUsage files", mlog.String("workspaceID", workspaceID), mlog.Err(err))
+		return nil, err
+	}
+
+	return usage, nil
+}
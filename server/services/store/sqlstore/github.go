@@ -0,0 +1,302 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+func (s *SQLStore) gitHubIntegrationColumns() []string {
+	return []string{
+		"id",
+		"workspace_id",
+		"repository",
+		"COALESCE(token_encrypted, '')",
+		"COALESCE(webhook_secret_encrypted, '')",
+		"COALESCE(label_filter, '')",
+		"COALESCE(comment_on_close, false)",
+		"COALESCE(state_property_id, '')",
+		"COALESCE(labels_property_id, '')",
+		"COALESCE(created_by, '')",
+		"COALESCE(create_at, 0)",
+		"COALESCE(last_sync_at, 0)",
+		"COALESCE(rate_limit_reset_at, 0)",
+		"COALESCE(last_error, '')",
+	}
+}
+
+func (s *SQLStore) scanGitHubIntegration(row sq.RowScanner) (*model.GitHubIntegration, error) {
+	var integration model.GitHubIntegration
+	var labelFilter string
+	err := row.Scan(
+		&integration.BoardID,
+		&integration.WorkspaceID,
+		&integration.Repository,
+		&integration.TokenEncrypted,
+		&integration.WebhookSecretEncrypted,
+		&labelFilter,
+		&integration.CommentOnClose,
+		&integration.StatePropertyID,
+		&integration.LabelsPropertyID,
+		&integration.CreatedBy,
+		&integration.CreateAt,
+		&integration.LastSyncAt,
+		&integration.RateLimitResetAt,
+		&integration.LastError,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	integration.LabelFilter = []string{}
+	if labelFilter != "" {
+		integration.LabelFilter = strings.Split(labelFilter, ",")
+	}
+	return &integration, nil
+}
+
+// GetGitHubIntegration returns the GitHub integration of the board. It
+// returns sql.ErrNoRows if the board has none.
+func (s *SQLStore) GetGitHubIntegration(c store.Container, boardID string) (*model.GitHubIntegration, error) {
+	query := s.getQueryBuilder().
+		Select(s.gitHubIntegrationColumns()...).
+		From(s.tablePrefix + "github_integrations").
+		Where(sq.Eq{"id": boardID}).
+		Where(sq.Eq{"workspace_id": c.WorkspaceID})
+
+	return s.scanGitHubIntegration(query.QueryRow())
+}
+
+// GetGitHubIntegrations returns the GitHub integrations of all the
+// boards, for the poller.
+func (s *SQLStore) GetGitHubIntegrations() ([]model.GitHubIntegration, error) {
+	query := s.getQueryBuilder().
+		Select(s.gitHubIntegrationColumns()...).
+		From(s.tablePrefix + "github_integrations").
+		OrderBy("id")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetGitHubIntegrations", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	integrations := []model.GitHubIntegration{}
+	for rows.Next() {
+		integration, err := s.scanGitHubIntegration(rows)
+		if err != nil {
+			return nil, err
+		}
+		integrations = append(integrations, *integration)
+	}
+
+	return integrations, rows.Err()
+}
+
+// UpsertGitHubIntegration stores the GitHub integration of the board,
+// replacing its previous configuration if any.
+func (s *SQLStore) UpsertGitHubIntegration(c store.Container, integration model.GitHubIntegration) error {
+	labelFilter := strings.Join(integration.LabelFilter, ",")
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"github_integrations").
+		Columns(
+			"id",
+			"workspace_id",
+			"repository",
+			"token_encrypted",
+			"webhook_secret_encrypted",
+			"label_filter",
+			"comment_on_close",
+			"state_property_id",
+			"labels_property_id",
+			"created_by",
+			"create_at",
+			"last_sync_at",
+			"rate_limit_reset_at",
+			"last_error",
+		).
+		Values(
+			integration.BoardID,
+			c.WorkspaceID,
+			integration.Repository,
+			integration.TokenEncrypted,
+			integration.WebhookSecretEncrypted,
+			labelFilter,
+			integration.CommentOnClose,
+			integration.StatePropertyID,
+			integration.LabelsPropertyID,
+			integration.CreatedBy,
+			integration.CreateAt,
+			integration.LastSyncAt,
+			integration.RateLimitResetAt,
+			integration.LastError,
+		)
+	if s.dbType == mysqlDBType {
+		query = query.Suffix(`ON DUPLICATE KEY UPDATE workspace_id = ?, repository = ?, token_encrypted = ?, webhook_secret_encrypted = ?,
+			label_filter = ?, comment_on_close = ?, state_property_id = ?, labels_property_id = ?, created_by = ?, create_at = ?,
+			last_sync_at = ?, rate_limit_reset_at = ?, last_error = ?`,
+			c.WorkspaceID, integration.Repository, integration.TokenEncrypted, integration.WebhookSecretEncrypted,
+			labelFilter, integration.CommentOnClose, integration.StatePropertyID, integration.LabelsPropertyID,
+			integration.CreatedBy, integration.CreateAt, integration.LastSyncAt, integration.RateLimitResetAt, integration.LastError)
+	} else {
+		query = query.Suffix(
+			`ON CONFLICT (id)
+			 DO UPDATE SET workspace_id = EXCLUDED.workspace_id, repository = EXCLUDED.repository,
+			 token_encrypted = EXCLUDED.token_encrypted, webhook_secret_encrypted = EXCLUDED.webhook_secret_encrypted,
+			 label_filter = EXCLUDED.label_filter, comment_on_close = EXCLUDED.comment_on_close,
+			 state_property_id = EXCLUDED.state_property_id, labels_property_id = EXCLUDED.labels_property_id,
+			 created_by = EXCLUDED.created_by, create_at = EXCLUDED.create_at, last_sync_at = EXCLUDED.last_sync_at,
+			 rate_limit_reset_at = EXCLUDED.rate_limit_reset_at, last_error = EXCLUDED.last_error`,
+		)
+	}
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR UpsertGitHubIntegration", mlog.String("boardID", integration.BoardID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// UpdateGitHubIntegrationSync stores the outcome of a sync of the GitHub
+// integration of the board.
+func (s *SQLStore) UpdateGitHubIntegrationSync(c store.Container, boardID string, lastSyncAt, rateLimitResetAt int64, lastError string) error {
+	query := s.getQueryBuilder().
+		Update(s.tablePrefix+"github_integrations").
+		Set("last_sync_at", lastSyncAt).
+		Set("rate_limit_reset_at", rateLimitResetAt).
+		Set("last_error", lastError).
+		Where(sq.Eq{"id": boardID}).
+		Where(sq.Eq{"workspace_id": c.WorkspaceID})
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR UpdateGitHubIntegrationSync", mlog.String("boardID", boardID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteGitHubIntegration deletes the GitHub integration of the board
+// and its issue links. It returns sql.ErrNoRows if the board has none.
+func (s *SQLStore) DeleteGitHubIntegration(c store.Container, boardID string) error {
+	ctx := context.Background()
+	integrationQuery := s.getQueryBuilder().
+		Delete(s.tablePrefix + "github_integrations").
+		Where(sq.Eq{"id": boardID}).
+		Where(sq.Eq{"workspace_id": c.WorkspaceID})
+
+	linksQuery := s.getQueryBuilder().
+		Delete(s.tablePrefix + "github_issue_links").
+		Where(sq.Eq{"board_id": boardID}).
+		Where(sq.Eq{"workspace_id": c.WorkspaceID})
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := sq.ExecContextWith(ctx, tx, integrationQuery)
+		if err != nil {
+			return err
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+
+		_, err = sq.ExecContextWith(ctx, tx, linksQuery)
+		return err
+	})
+}
+
+// GetGitHubIssueLinks returns the issue links of the GitHub integration
+// of the board.
+func (s *SQLStore) GetGitHubIssueLinks(c store.Container, boardID string) ([]model.GitHubIssueLink, error) {
+	query := s.getQueryBuilder().
+		Select(
+			"board_id",
+			"issue_number",
+			"card_id",
+			"COALESCE(state, '')",
+			"COALESCE(card_closed, false)",
+			"COALESCE(issue_updated_at, 0)",
+		).
+		From(s.tablePrefix + "github_issue_links").
+		Where(sq.Eq{"board_id": boardID}).
+		Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+		OrderBy("issue_number")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetGitHubIssueLinks", mlog.String("boardID", boardID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	links := []model.GitHubIssueLink{}
+	for rows.Next() {
+		var link model.GitHubIssueLink
+		err := rows.Scan(
+			&link.BoardID,
+			&link.IssueNumber,
+			&link.CardID,
+			&link.State,
+			&link.CardClosed,
+			&link.IssueUpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// UpsertGitHubIssueLink stores the link of an issue to its card.
+func (s *SQLStore) UpsertGitHubIssueLink(c store.Container, link model.GitHubIssueLink) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"github_issue_links").
+		Columns(
+			"board_id",
+			"workspace_id",
+			"issue_number",
+			"card_id",
+			"state",
+			"card_closed",
+			"issue_updated_at",
+		).
+		Values(
+			link.BoardID,
+			c.WorkspaceID,
+			link.IssueNumber,
+			link.CardID,
+			link.State,
+			link.CardClosed,
+			link.IssueUpdatedAt,
+		)
+	if s.dbType == mysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE workspace_id = ?, card_id = ?, state = ?, card_closed = ?, issue_updated_at = ?",
+			c.WorkspaceID, link.CardID, link.State, link.CardClosed, link.IssueUpdatedAt)
+	} else {
+		query = query.Suffix(
+			`ON CONFLICT (board_id, issue_number)
+			 DO UPDATE SET workspace_id = EXCLUDED.workspace_id, card_id = EXCLUDED.card_id, state = EXCLUDED.state,
+			 card_closed = EXCLUDED.card_closed, issue_updated_at = EXCLUDED.issue_updated_at`,
+		)
+	}
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR UpsertGitHubIssueLink", mlog.String("boardID", link.BoardID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+)
+
+func guestInviteFields() []string {
+	return []string{
+		"token_hash",
+		"workspace_id",
+		"board_id",
+		"role",
+		"created_by",
+		"create_at",
+		"expire_at",
+	}
+}
+
+// CreateGuestInvite stores a guest invite.
+func (s *SQLStore) CreateGuestInvite(invite model.GuestInvite) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"guest_invites").
+		Columns(guestInviteFields()...).
+		Values(invite.TokenHash, invite.WorkspaceID, invite.BoardID, invite.Role, invite.CreatedBy, invite.CreateAt, invite.ExpireAt)
+
+	_, err := query.Exec()
+	return err
+}
+
+// ConsumeGuestInvite deletes the guest invite with the hash and returns
+// it, or returns sql.ErrNoRows if it doesn't exist or was already
+// consumed.
+func (s *SQLStore) ConsumeGuestInvite(tokenHash string) (*model.GuestInvite, error) {
+	query := s.getQueryBuilder().
+		Select(guestInviteFields()...).
+		From(s.tablePrefix + "guest_invites").
+		Where(sq.Eq{"token_hash": tokenHash})
+
+	var invite model.GuestInvite
+	err := query.QueryRow().Scan(
+		&invite.TokenHash,
+		&invite.WorkspaceID,
+		&invite.BoardID,
+		&invite.Role,
+		&invite.CreatedBy,
+		&invite.CreateAt,
+		&invite.ExpireAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	deleteQuery := s.getQueryBuilder().
+		Delete(s.tablePrefix + "guest_invites").
+		Where(sq.Eq{"token_hash": tokenHash})
+
+	result, err := deleteQuery.Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	// the invite is only consumed by the request that deleted it
+	count, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &invite, nil
+}
+
+// DeleteExpiredGuestInvites deletes the guest invites that have expired
+// at now, in milliseconds.
+func (s *SQLStore) DeleteExpiredGuestInvites(now int64) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "guest_invites").
+		Where(sq.LtOrEq{"expire_at": now})
+
+	_, err := query.Exec()
+	return err
+}
@@ -0,0 +1,95 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// Ping checks the database is reachable by running a trivial query.
+func (s *SQLStore) Ping(ctx context.Context) error {
+	var one int
+	return s.getQueryBuilder().Select("1").QueryRowContext(ctx).Scan(&one)
+}
+
+// GetSchemaVersion returns the version of the last migration applied to
+// the database.
+func (s *SQLStore) GetSchemaVersion(ctx context.Context) (int64, error) {
+	query := s.getQueryBuilder().
+		Select("version").
+		From(s.tablePrefix + "schema_migrations")
+
+	var version int64
+	if err := query.QueryRowContext(ctx).Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// getAppliedSchemaVersion returns the schema version like
+// GetSchemaVersion, or 0 if no migration was applied yet.
+func (s *SQLStore) getAppliedSchemaVersion(ctx context.Context) (int64, error) {
+	exists, err := s.tableExists(ctx, s.tablePrefix+"schema_migrations")
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	version, err := s.GetSchemaVersion(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return version, err
+}
+
+func (s *SQLStore) tableExists(ctx context.Context, table string) (bool, error) {
+	var query sq.SelectBuilder
+	switch s.dbType {
+	case sqliteDBType:
+		query = s.getQueryBuilder().
+			Select("COUNT(*)").
+			From("sqlite_master").
+			Where(sq.Eq{"type": "table", "name": table})
+	case mysqlDBType:
+		query = s.getQueryBuilder().
+			Select("COUNT(*)").
+			From("information_schema.tables").
+			Where(sq.Eq{"table_name": table}).
+			Where("table_schema = DATABASE()")
+	default:
+		query = s.getQueryBuilder().
+			Select("COUNT(*)").
+			From("information_schema.tables").
+			Where(sq.Eq{"table_name": table}).
+			Where("table_schema = current_schema()")
+	}
+
+	var count int
+	if err := query.QueryRowContext(ctx).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetDBStats returns the statistics of the database connection pool.
+func (s *SQLStore) GetDBStats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// CountOrphanedBlocks counts the blocks that aren't deleted, but whose
+// root block doesn't exist in their workspace, or is deleted.
+func (s *SQLStore) CountOrphanedBlocks(ctx context.Context) (int64, error) {
+	query := s.getQueryBuilder().
+		Select("COUNT(*)").
+		From(s.tablePrefix + "blocks AS b").
+		Where(sq.Eq{"b.delete_at": 0}).
+		Where("NOT EXISTS (SELECT 1 FROM " + s.tablePrefix + "blocks AS r" +
+			" WHERE r.id = b.root_id AND COALESCE(r.workspace_id, '0') = COALESCE(b.workspace_id, '0') AND r.delete_at = 0)")
+
+	var count int64
+	if err := query.QueryRowContext(ctx).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,94 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+)
+
+func idempotencyKeyFields() []string {
+	return []string{
+		"idempotency_key",
+		"user_id",
+		"request_hash",
+		"status_code",
+		"COALESCE(response, '')",
+		"create_at",
+		"expire_at",
+	}
+}
+
+// CreateIdempotencyKey stores the idempotency key of a request. It fails
+// if the user already has the key, as the table is keyed by both.
+func (s *SQLStore) CreateIdempotencyKey(key model.IdempotencyKey) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"idempotency_keys").
+		Columns("idempotency_key", "user_id", "request_hash", "status_code", "response", "create_at", "expire_at").
+		Values(key.Key, key.UserID, key.RequestHash, key.StatusCode, key.Response, key.CreateAt, key.ExpireAt)
+
+	_, err := query.Exec()
+	return err
+}
+
+// GetIdempotencyKey returns the idempotency key of the user, or
+// sql.ErrNoRows if the user doesn't have it.
+func (s *SQLStore) GetIdempotencyKey(userID, key string) (*model.IdempotencyKey, error) {
+	query := s.getQueryBuilder().
+		Select(idempotencyKeyFields()...).
+		From(s.tablePrefix + "idempotency_keys").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"idempotency_key": key})
+
+	var idempotencyKey model.IdempotencyKey
+	err := query.QueryRow().Scan(
+		&idempotencyKey.Key,
+		&idempotencyKey.UserID,
+		&idempotencyKey.RequestHash,
+		&idempotencyKey.StatusCode,
+		&idempotencyKey.Response,
+		&idempotencyKey.CreateAt,
+		&idempotencyKey.ExpireAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &idempotencyKey, nil
+}
+
+// SetIdempotencyKeyResponse stores the response of the request of the
+// idempotency key of the user.
+func (s *SQLStore) SetIdempotencyKeyResponse(userID, key string, statusCode int, response string) error {
+	query := s.getQueryBuilder().
+		Update(s.tablePrefix+"idempotency_keys").
+		Set("status_code", statusCode).
+		Set("response", response).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"idempotency_key": key})
+
+	_, err := query.Exec()
+	return err
+}
+
+// DeleteIdempotencyKey deletes the idempotency key of the user, if it
+// was created at createAt, so that a key replacing it isn't deleted.
+func (s *SQLStore) DeleteIdempotencyKey(userID, key string, createAt int64) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "idempotency_keys").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"idempotency_key": key}).
+		Where(sq.Eq{"create_at": createAt})
+
+	_, err := query.Exec()
+	return err
+}
+
+// DeleteExpiredIdempotencyKeys deletes the idempotency keys that have
+// expired at now, in milliseconds.
+func (s *SQLStore) DeleteExpiredIdempotencyKeys(now int64) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "idempotency_keys").
+		Where(sq.LtOrEq{"expire_at": now})
+
+	_, err := query.Exec()
+	return err
+}
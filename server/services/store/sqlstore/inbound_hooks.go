@@ -0,0 +1,254 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+func (s *SQLStore) inboundHookColumns() []string {
+	return []string{
+		"id",
+		"workspace_id",
+		"token_hash",
+		"COALESCE(allow_new_options, false)",
+		"COALESCE(created_by, '')",
+		"COALESCE(create_at, 0)",
+	}
+}
+
+func (s *SQLStore) scanInboundHook(row sq.RowScanner) (*model.InboundHook, error) {
+	var hook model.InboundHook
+	err := row.Scan(
+		&hook.BoardID,
+		&hook.WorkspaceID,
+		&hook.TokenHash,
+		&hook.AllowNewOptions,
+		&hook.CreatedBy,
+		&hook.CreateAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// GetInboundHook returns the inbound webhook of the board. It returns
+// sql.ErrNoRows if the board has none.
+func (s *SQLStore) GetInboundHook(c store.Container, boardID string) (*model.InboundHook, error) {
+	query := s.getQueryBuilder().
+		Select(s.inboundHookColumns()...).
+		From(s.tablePrefix + "inbound_hooks").
+		Where(sq.Eq{"id": boardID}).
+		Where(sq.Eq{"workspace_id": c.WorkspaceID})
+
+	return s.scanInboundHook(query.QueryRow())
+}
+
+// GetInboundHookByTokenHash returns the inbound webhook with the token
+// hash. It returns sql.ErrNoRows if there is none.
+func (s *SQLStore) GetInboundHookByTokenHash(tokenHash string) (*model.InboundHook, error) {
+	query := s.getQueryBuilder().
+		Select(s.inboundHookColumns()...).
+		From(s.tablePrefix + "inbound_hooks").
+		Where(sq.Eq{"token_hash": tokenHash})
+
+	return s.scanInboundHook(query.QueryRow())
+}
+
+// UpsertInboundHook stores the inbound webhook of the board, replacing
+// its previous token if any.
+func (s *SQLStore) UpsertInboundHook(c store.Container, hook model.InboundHook) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"inbound_hooks").
+		Columns(
+			"id",
+			"workspace_id",
+			"token_hash",
+			"allow_new_options",
+			"created_by",
+			"create_at",
+		).
+		Values(
+			hook.BoardID,
+			c.WorkspaceID,
+			hook.TokenHash,
+			hook.AllowNewOptions,
+			hook.CreatedBy,
+			hook.CreateAt,
+		)
+	if s.dbType == mysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE workspace_id = ?, token_hash = ?, allow_new_options = ?, created_by = ?, create_at = ?",
+			c.WorkspaceID, hook.TokenHash, hook.AllowNewOptions, hook.CreatedBy, hook.CreateAt)
+	} else {
+		query = query.Suffix(
+			`ON CONFLICT (id)
+			 DO UPDATE SET workspace_id = EXCLUDED.workspace_id, token_hash = EXCLUDED.token_hash, allow_new_options = EXCLUDED.allow_new_options,
+			 created_by = EXCLUDED.created_by, create_at = EXCLUDED.create_at`,
+		)
+	}
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR UpsertInboundHook", mlog.String("boardID", hook.BoardID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// DeleteInboundHook revokes the inbound webhook of the board. It returns
+// sql.ErrNoRows if the board has none.
+func (s *SQLStore) DeleteInboundHook(c store.Container, boardID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "inbound_hooks").
+		Where(sq.Eq{"id": boardID}).
+		Where(sq.Eq{"workspace_id": c.WorkspaceID})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// InsertInboundHookDelivery stores a delivery of the inbound webhook of
+// a board, and deletes the deliveries of the board older than the last
+// keep ones.
+func (s *SQLStore) InsertInboundHookDelivery(c store.Container, delivery model.InboundHookDelivery, keep int) error {
+	ctx := context.Background()
+	insertQuery := s.getQueryBuilder().
+		Insert(s.tablePrefix+"inbound_hook_deliveries").
+		Columns(
+			"id",
+			"board_id",
+			"workspace_id",
+			"card_id",
+			"payload",
+			"status_code",
+			"error",
+			"create_at",
+		).
+		Values(
+			delivery.ID,
+			delivery.BoardID,
+			c.WorkspaceID,
+			delivery.CardID,
+			delivery.Payload,
+			delivery.StatusCode,
+			delivery.Error,
+			delivery.CreateAt,
+		)
+
+	// the oldest delivery kept, as MySQL doesn't support limits in the
+	// subqueries of a delete
+	oldestQuery := s.getQueryBuilder().
+		Select("create_at").
+		From(s.tablePrefix + "inbound_hook_deliveries").
+		Where(sq.Eq{"board_id": delivery.BoardID}).
+		Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+		OrderBy("create_at DESC", "id").
+		Limit(1).
+		Offset(uint64(keep - 1))
+
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := sq.ExecContextWith(ctx, tx, insertQuery); err != nil {
+			return err
+		}
+
+		rows, err := sq.QueryContextWith(ctx, tx, oldestQuery)
+		if err != nil {
+			return err
+		}
+		var oldest int64
+		found := rows.Next()
+		if found {
+			err = rows.Scan(&oldest)
+		}
+		s.CloseRows(rows)
+		if err != nil {
+			return err
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+
+		deleteQuery := s.getQueryBuilder().
+			Delete(s.tablePrefix + "inbound_hook_deliveries").
+			Where(sq.Eq{"board_id": delivery.BoardID}).
+			Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+			Where(sq.Lt{"create_at": oldest})
+
+		_, err = sq.ExecContextWith(ctx, tx, deleteQuery)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("ERROR InsertInboundHookDelivery", mlog.String("boardID", delivery.BoardID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetInboundHookDeliveries returns up to limit deliveries of the inbound
+// webhook of the board, the most recent first.
+func (s *SQLStore) GetInboundHookDeliveries(c store.Container, boardID string, limit int) ([]model.InboundHookDelivery, error) {
+	query := s.getQueryBuilder().
+		Select(
+			"id",
+			"board_id",
+			"COALESCE(card_id, '')",
+			"COALESCE(payload, '')",
+			"COALESCE(status_code, 0)",
+			"COALESCE(error, '')",
+			"COALESCE(create_at, 0)",
+		).
+		From(s.tablePrefix + "inbound_hook_deliveries").
+		Where(sq.Eq{"board_id": boardID}).
+		Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+		OrderBy("create_at DESC", "id").
+		Limit(uint64(limit))
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetInboundHookDeliveries", mlog.String("boardID", boardID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	deliveries := []model.InboundHookDelivery{}
+	for rows.Next() {
+		var delivery model.InboundHookDelivery
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.BoardID,
+			&delivery.CardID,
+			&delivery.Payload,
+			&delivery.StatusCode,
+			&delivery.Error,
+			&delivery.CreateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,71 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/metrics"
+)
+
+// queryRunner runs the queries against the database or a transaction.
+type queryRunner interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// instrumentedRunner runs the queries of the query builder and reports
+// their duration by operation.
+type instrumentedRunner struct {
+	db              queryRunner
+	instrumentation metrics.Instrumentation
+}
+
+func (r *instrumentedRunner) Exec(query string, args ...interface{}) (sql.Result, error) {
+	defer r.observe(query, time.Now())
+	return r.db.Exec(query, args...)
+}
+
+func (r *instrumentedRunner) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	defer r.observe(query, time.Now())
+	return r.db.Query(query, args...)
+}
+
+func (r *instrumentedRunner) QueryRow(query string, args ...interface{}) *sql.Row {
+	defer r.observe(query, time.Now())
+	return r.db.QueryRow(query, args...)
+}
+
+func (r *instrumentedRunner) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer r.observe(query, time.Now())
+	return r.db.ExecContext(ctx, query, args...)
+}
+
+func (r *instrumentedRunner) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer r.observe(query, time.Now())
+	return r.db.QueryContext(ctx, query, args...)
+}
+
+func (r *instrumentedRunner) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer r.observe(query, time.Now())
+	return r.db.QueryRowContext(ctx, query, args...)
+}
+
+func (r *instrumentedRunner) observe(query string, start time.Time) {
+	r.instrumentation.ObserveQueryDuration(queryOperation(query), time.Since(start))
+}
+
+// queryOperation returns the operation of the query, e.g. select, which
+// is used to label its duration.
+func queryOperation(query string) string {
+	query = strings.TrimSpace(query)
+	if i := strings.IndexAny(query, " \t\n"); i > 0 {
+		query = query[:i]
+	}
+	return strings.ToLower(query)
+}
@@ -0,0 +1,65 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// GetLoginAttempts returns the failed logins recorded for the key, or
+// sql.ErrNoRows if there are none.
+func (s *SQLStore) GetLoginAttempts(key string) (*model.LoginAttempts, error) {
+	query := s.getQueryBuilder().
+		Select("id", "failures", "last_failure_at", "locked_until").
+		From(s.tablePrefix + "login_attempts").
+		Where(sq.Eq{"id": key})
+
+	var attempts model.LoginAttempts
+	err := query.QueryRow().Scan(&attempts.Key, &attempts.Failures, &attempts.LastFailureAt, &attempts.LockedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempts, nil
+}
+
+// UpsertLoginAttempts stores the failed logins of a key.
+func (s *SQLStore) UpsertLoginAttempts(attempts model.LoginAttempts) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"login_attempts").
+		Columns("id", "failures", "last_failure_at", "locked_until").
+		Values(attempts.Key, attempts.Failures, attempts.LastFailureAt, attempts.LockedUntil)
+	if s.dbType == mysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE failures = ?, last_failure_at = ?, locked_until = ?",
+			attempts.Failures, attempts.LastFailureAt, attempts.LockedUntil)
+	} else {
+		query = query.Suffix(
+			`ON CONFLICT (id)
+			 DO UPDATE SET failures = EXCLUDED.failures, last_failure_at = EXCLUDED.last_failure_at, locked_until = EXCLUDED.locked_until`,
+		)
+	}
+
+	_, err := query.Exec()
+	return err
+}
+
+// DeleteLoginAttempts forgets the failed logins of a key.
+func (s *SQLStore) DeleteLoginAttempts(key string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "login_attempts").
+		Where(sq.Eq{"id": key})
+
+	_, err := query.Exec()
+	return err
+}
+
+// DeleteStaleLoginAttempts deletes the failed logins that are neither
+// recent nor locked at the given time, in milliseconds.
+func (s *SQLStore) DeleteStaleLoginAttempts(before int64) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "login_attempts").
+		Where(sq.Lt{"last_failure_at": before}).
+		Where(sq.Lt{"locked_until": before})
+
+	_, err := query.Exec()
+	return err
+}
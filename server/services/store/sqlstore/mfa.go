@@ -0,0 +1,63 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// SetMfaRecoveryCodes replaces the MFA recovery codes of the user with the
+// given hashes.
+func (s *SQLStore) SetMfaRecoveryCodes(userID string, codeHashes []string) error {
+	if err := s.DeleteMfaRecoveryCodes(userID); err != nil {
+		return err
+	}
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"mfa_recovery_codes").
+		Columns("user_id", "code_hash")
+	for _, codeHash := range codeHashes {
+		query = query.Values(userID, codeHash)
+	}
+
+	_, err := query.Exec()
+	return err
+}
+
+// ConsumeMfaRecoveryCode deletes the MFA recovery code of the user with
+// the hash, or returns sql.ErrNoRows if it doesn't exist or was already
+// used.
+func (s *SQLStore) ConsumeMfaRecoveryCode(userID, codeHash string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix+"mfa_recovery_codes").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Eq{"code_hash": codeHash})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteMfaRecoveryCodes deletes all the MFA recovery codes of the user.
+func (s *SQLStore) DeleteMfaRecoveryCodes(userID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "mfa_recovery_codes").
+		Where(sq.Eq{"user_id": userID})
+
+	_, err := query.Exec()
+	return err
+}
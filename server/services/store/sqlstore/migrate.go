@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"io/ioutil"
 	"os"
 	"text/template"
+	"time"
 
 	mysqldriver "github.com/go-sql-driver/mysql"
 	"github.com/golang-migrate/migrate/v4"
@@ -20,7 +22,11 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file" // fileystem driver
 	bindata "github.com/golang-migrate/migrate/v4/source/go_bindata"
 	_ "github.com/lib/pq" // postgres driver
+	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/store/sqlstore/migrations"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 )
 
 type PrefixedMigration struct {
@@ -120,6 +126,58 @@ func (s *SQLStore) getMigrationConnection() (*sql.DB, error) {
 	return db, nil
 }
 
+// newPrefixedMigration returns the source of the migrations, whose
+// templates are executed for the database of the store.
+func (s *SQLStore) newPrefixedMigration() (*PrefixedMigration, error) {
+	bresource := bindata.Resource(migrations.AssetNames(), migrations.Asset)
+
+	d, err := bindata.WithInstance(bresource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrefixedMigration{
+		Bindata:  d.(*bindata.Bindata),
+		prefix:   s.tablePrefix,
+		plugin:   s.isPlugin,
+		postgres: s.dbType == postgresDBType,
+		sqlite:   s.dbType == sqliteDBType,
+		mysql:    s.dbType == mysqlDBType,
+	}, nil
+}
+
+// readMigrations returns the migrations of the source after the given
+// version, with their SQL if withSQL is set.
+func readMigrations(src *PrefixedMigration, after uint, withSQL bool) ([]model.Migration, error) {
+	result := []model.Migration{}
+	version, err := src.First()
+	for err == nil {
+		if version > after {
+			r, name, readErr := src.ReadUp(version)
+			if readErr != nil {
+				return nil, readErr
+			}
+			data, readErr := ioutil.ReadAll(r)
+			r.Close()
+			if readErr != nil {
+				return nil, readErr
+			}
+
+			migration := model.Migration{Version: int64(version), Name: name}
+			if withSQL {
+				migration.SQL = string(data)
+			}
+			result = append(result, migration)
+		}
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (s *SQLStore) Migrate() error {
 	var driver database.Driver
 	var err error
@@ -152,30 +210,145 @@ func (s *SQLStore) Migrate() error {
 		}
 	}
 
-	bresource := bindata.Resource(migrations.AssetNames(), migrations.Asset)
-
-	d, err := bindata.WithInstance(bresource)
+	prefixedData, err := s.newPrefixedMigration()
 	if err != nil {
 		return err
 	}
 
-	prefixedData := &PrefixedMigration{
-		Bindata:  d.(*bindata.Bindata),
-		prefix:   s.tablePrefix,
-		plugin:   s.isPlugin,
-		postgres: s.dbType == postgresDBType,
-		sqlite:   s.dbType == sqliteDBType,
-		mysql:    s.dbType == mysqlDBType,
+	m, err := migrate.NewWithInstance("prefixed-migration", prefixedData, s.dbType, driver)
+	if err != nil {
+		return err
 	}
 
-	m, err := migrate.NewWithInstance("prefixed-migration", prefixedData, s.dbType, driver)
+	current, _, err := driver.Version()
 	if err != nil {
 		return err
 	}
-	err = m.Up()
-	if err != nil && !errors.Is(err, migrate.ErrNoChange) && !errors.Is(err, os.ErrNotExist) {
+	if current == database.NilVersion {
+		current = 0
+	}
+	pending, err := readMigrations(prefixedData, uint(current), false)
+	if err != nil {
 		return err
 	}
 
+	// the migrations are applied one by one to log how long each takes
+	applied := []model.Migration{}
+	defer func() { s.logMigrations(applied) }()
+	for _, migration := range pending {
+		start := time.Now()
+		err = m.Migrate(uint(migration.Version))
+		if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+
+		migration.AppliedAt = utils.GetMillis()
+		migration.DurationMs = time.Since(start).Milliseconds()
+		applied = append(applied, migration)
+		s.logger.Info("Applied migration",
+			mlog.Int64("version", migration.Version),
+			mlog.String("name", migration.Name),
+			mlog.Int64("duration_ms", migration.DurationMs),
+		)
+	}
+
 	return nil
 }
+
+// logMigrations records the applied migrations in the migrations log.
+// Failing to do so doesn't fail the migration, as the log is only used
+// for diagnosis.
+func (s *SQLStore) logMigrations(applied []model.Migration) {
+	if len(applied) == 0 {
+		return
+	}
+
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"migrations_log").
+		Columns("version", "name", "applied_at", "duration_ms")
+	for _, migration := range applied {
+		query = query.Values(migration.Version, migration.Name, migration.AppliedAt, migration.DurationMs)
+	}
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Warn("Unable to log the applied migrations", mlog.Err(err))
+	}
+}
+
+// GetPendingMigrations returns the migrations not applied to the
+// database yet, with their SQL.
+func (s *SQLStore) GetPendingMigrations(ctx context.Context) ([]model.Migration, error) {
+	current, err := s.getAppliedSchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := s.newPrefixedMigration()
+	if err != nil {
+		return nil, err
+	}
+
+	return readMigrations(src, uint(current), true)
+}
+
+// GetAppliedMigrations returns the migrations applied to the database,
+// with the time they were applied and took if they were logged.
+func (s *SQLStore) GetAppliedMigrations(ctx context.Context) ([]model.Migration, error) {
+	current, err := s.getAppliedSchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		return []model.Migration{}, nil
+	}
+
+	src, err := s.newPrefixedMigration()
+	if err != nil {
+		return nil, err
+	}
+	all, err := readMigrations(src, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	logged, err := s.getLoggedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []model.Migration{}
+	for _, migration := range all {
+		if migration.Version > current {
+			break
+		}
+		if entry, ok := logged[migration.Version]; ok {
+			migration.AppliedAt = entry.AppliedAt
+			migration.DurationMs = entry.DurationMs
+		}
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+func (s *SQLStore) getLoggedMigrations(ctx context.Context) (map[int64]model.Migration, error) {
+	rows, err := s.getQueryBuilder().
+		Select("version", "name", "applied_at", "duration_ms").
+		From(s.tablePrefix + "migrations_log").
+		QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	logged := map[int64]model.Migration{}
+	for rows.Next() {
+		var migration model.Migration
+		if err := rows.Scan(&migration.Version, &migration.Name, &migration.AppliedAt, &migration.DurationMs); err != nil {
+			return nil, err
+		}
+		logged[migration.Version] = migration
+	}
+
+	return logged, rows.Err()
+}
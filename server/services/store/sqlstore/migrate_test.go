@@ -1,10 +1,14 @@
 package sqlstore
 
 import (
+	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 )
 
 func TestGetMySQLMigrationConnection(t *testing.T) {
@@ -33,3 +37,45 @@ func TestGetMySQLMigrationConnection(t *testing.T) {
 		})
 	}
 }
+
+func TestMigrationsLog(t *testing.T) {
+	ctx := context.Background()
+	logger := mlog.CreateConsoleTestLogger(false, mlog.LvlDebug)
+	defer func() { _ = logger.Shutdown() }()
+
+	sqlDB, err := sql.Open(sqliteDBType, ":memory:")
+	require.NoError(t, err)
+	store := NewWithoutMigrations(sqliteDBType, ":memory:", "test_", logger, sqlDB, false)
+	defer func() { _ = store.Shutdown() }()
+
+	pending, err := store.GetPendingMigrations(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, pending)
+	require.Equal(t, int64(1), pending[0].Version)
+	require.Equal(t, "init", pending[0].Name)
+	require.Contains(t, pending[0].SQL, "CREATE TABLE IF NOT EXISTS test_blocks")
+
+	applied, err := store.GetAppliedMigrations(ctx)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	require.NoError(t, store.Migrate())
+
+	version, err := store.GetSchemaVersion(ctx)
+	require.NoError(t, err)
+	require.Equal(t, pending[len(pending)-1].Version, version)
+
+	newPending, err := store.GetPendingMigrations(ctx)
+	require.NoError(t, err)
+	require.Empty(t, newPending)
+
+	applied, err = store.GetAppliedMigrations(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(pending))
+	for i, migration := range applied {
+		require.Equal(t, pending[i].Version, migration.Version)
+		require.Equal(t, pending[i].Name, migration.Name)
+		require.Positive(t, migration.AppliedAt)
+		require.Empty(t, migration.SQL)
+	}
+}
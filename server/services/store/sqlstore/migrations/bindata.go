@@ -223,6 +223,564 @@ func _000011_match_collation_up_sql() ([]byte, error) {
 	)
 }
 
+var __000012_workspace_members_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x29\x00\xd6\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x77\x6f\x72\x6b\x73\x70\x61\x63\x65\x5f\x6d\x65\x6d\x62\x65\x72\x73\x3b\x0a\x03\x00\xac\x7a\x48\x49\x29\x00\x00\x00")
+
+func _000012_workspace_members_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000012_workspace_members_down_sql,
+		"000012_workspace_members.down.sql",
+	)
+}
+
+var __000012_workspace_members_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\xcd\x41\x4b\xc3\x30\x18\x87\xf1\x73\xf3\x29\xfe\xc7\x16\xca\x98\x28\x22\x78\xca\xea\x3b\x0d\xce\x29\xe9\xab\xb8\xd3\xe8\xb6\xb7\x50\x34\x3a\x93\x16\x95\x90\xef\x2e\x05\x61\xbd\xfe\x0e\xcf\x53\x59\xd2\x4c\x60\xbd\x58\x11\xcc\x12\xeb\x47\x06\xbd\x9a\x9a\x6b\xc4\x38\x3b\x7a\x69\xbb\x9f\x94\xbe\x3f\xfd\x5b\x38\x36\x7b\xd9\x3a\x71\x3b\xf1\x01\xb9\xca\x4e\xd8\x1d\xf0\xa2\x6d\x75\xa7\x6d\x7e\x7e\x59\x94\x2a\x1b\x82\xf8\xa9\x9e\xcd\xe7\x23\xef\xbd\x34\xbd\x6c\x9b\x1e\x0b\x73\x6b\xd6\x5c\xaa\xec\xc9\x9a\x07\x6d\x37\xb8\xa7\x0d\xf2\x69\xb1\xc4\x7f\xa4\x50\x05\x62\xec\x5a\xcc\xdc\x6f\xf8\x7a\x4f\xe9\x86\x96\xfa\x79\xc5\x18\x7f\xba\x62\xb2\xa8\x89\x31\xf4\xed\x95\xdb\x5d\xc4\x28\x1f\x87\x94\xae\xd5\xdf\x00\xfa\x9b\x07\xda\xd9\x00\x00\x00")
+
+func _000012_workspace_members_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000012_workspace_members_up_sql,
+		"000012_workspace_members.up.sql",
+	)
+}
+
+var __000013_workspaces_update_at_index_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xaa\xae\xce\x4c\x53\xd0\xcb\xad\x2c\x2e\xcc\xa9\xad\xe5\x72\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xc8\x4c\xa9\x88\xaf\xae\xd6\x2b\x28\x4a\x4d\xcb\xac\xa8\xad\x2d\xcf\x2f\xca\x2e\x2e\x48\x4c\x4e\x2d\x8e\x2f\x2d\x48\x49\x2c\x49\x8d\x4f\x2c\x51\xf0\xf7\x53\xc0\xaa\xc4\x9a\xab\xba\x3a\x35\xa7\x38\x95\x64\x13\xad\xb9\xaa\xab\x53\xf3\x52\x6a\x6b\xb9\x00\x03\x00\xc5\x85\xed\x62\x98\x00\x00\x00")
+
+func _000013_workspaces_update_at_index_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000013_workspaces_update_at_index_down_sql,
+		"000013_workspaces_update_at_index.down.sql",
+	)
+}
+
+var __000013_workspaces_update_at_index_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x5a\x00\xa5\xff\x43\x52\x45\x41\x54\x45\x20\x49\x4e\x44\x45\x58\x20\x69\x64\x78\x5f\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x77\x6f\x72\x6b\x73\x70\x61\x63\x65\x73\x5f\x75\x70\x64\x61\x74\x65\x5f\x61\x74\x20\x4f\x4e\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x77\x6f\x72\x6b\x73\x70\x61\x63\x65\x73\x28\x75\x70\x64\x61\x74\x65\x5f\x61\x74\x2c\x20\x69\x64\x29\x3b\x0a\x03\x00\xa4\xb6\x8c\x39\x5a\x00\x00\x00")
+
+func _000013_workspaces_update_at_index_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000013_workspaces_update_at_index_up_sql,
+		"000013_workspaces_update_at_index.up.sql",
+	)
+}
+
+var __000014_sharing_tokens_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x26\x00\xd9\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x73\x68\x61\x72\x69\x6e\x67\x5f\x74\x6f\x6b\x65\x6e\x73\x3b\x0a\x03\x00\xda\xf1\xfa\x98\x26\x00\x00\x00")
+
+func _000014_sharing_tokens_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000014_sharing_tokens_down_sql,
+		"000014_sharing_tokens.down.sql",
+	)
+}
+
+var __000014_sharing_tokens_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x51\x4b\xc3\x30\x14\x85\x9f\x9b\x5f\x71\x1f\x5b\x18\x63\xa2\x88\xb0\xa7\xac\xbb\xd3\xe0\xec\x24\xbd\xca\xf6\x14\xba\x25\xd5\x30\xd7\xd5\xb4\x62\x47\xc8\x7f\x97\x0e\x11\x44\xfb\x76\xf9\x0e\x5c\xce\xf9\x52\x89\x9c\x10\x88\xcf\x96\x08\x62\x01\xd9\x8a\x00\xd7\x22\xa7\x1c\xbc\x1f\xd7\xce\x94\xb6\x0b\xa1\x79\x2d\x9c\xad\x5e\x54\x7b\xdc\x9b\xaa\x81\x98\x45\xe7\x0b\x9e\xb9\x4c\xef\xb8\x8c\x2f\x26\x93\x64\xc4\x22\xab\x7f\xc8\xe5\x75\x0f\x3e\x8f\x6e\xdf\xd4\xc5\xce\xa8\x3f\xd1\xce\x99\xa2\x35\x5a\x6d\x4f\xff\x06\xaa\x68\x61\x26\x6e\x45\x46\x23\x16\x99\xae\xb6\xee\x37\x7a\x94\xe2\x81\xcb\x0d\xdc\xe3\x06\xe2\x73\x99\x84\x25\xe0\xbd\x2d\x61\x7c\x38\x35\xef\x6f\x21\xcc\x71\xc1\x9f\x96\x04\xfd\x6f\x9e\x12\x4a\xc8\x91\xe0\xa3\x2d\x6f\x0e\xdb\x2b\xef\x4d\xa5\x43\x98\x32\xf6\x6d\x40\x64\x73\x5c\x83\xd5\x9d\x1a\xdc\xdd\x8f\x58\x65\xc3\x5e\x62\xab\x93\x29\xfb\x1a\x00\xd2\x35\x38\xec\x52\x01\x00\x00")
+
+func _000014_sharing_tokens_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000014_sharing_tokens_up_sql,
+		"000014_sharing_tokens.up.sql",
+	)
+}
+
+var __000015_webhook_deliveries_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x2a\x00\xd5\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x77\x65\x62\x68\x6f\x6f\x6b\x5f\x64\x65\x6c\x69\x76\x65\x72\x69\x65\x73\x3b\x0a\x03\x00\x1e\x11\x6c\x8b\x2a\x00\x00\x00")
+
+func _000015_webhook_deliveries_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000015_webhook_deliveries_down_sql,
+		"000015_webhook_deliveries.down.sql",
+	)
+}
+
+var __000015_webhook_deliveries_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\xc1\x6a\x32\x31\x14\x85\xd7\xe6\x29\xee\x72\x06\xc4\xcd\xff\x53\x0a\xae\xa2\xc6\x36\xd4\x8e\x25\xa6\x45\x57\x21\x9a\x2b\x0d\xce\x34\xd3\x24\x53\x95\x90\x77\x2f\x52\x2d\x2d\x05\x97\xf7\x7c\x77\x71\xbe\x33\x16\x8c\x4a\x06\x92\x8e\x66\x0c\xf8\x14\xaa\xb9\x04\xb6\xe4\x0b\xb9\x80\x94\x06\xad\xc7\xad\x3d\xe4\xbc\xc7\xf5\xab\x73\x3b\x65\xb0\xb6\x1f\xe8\x2d\x06\x28\x48\xcf\x1a\x78\xa1\x62\x7c\x4f\x45\xf1\xef\xa6\xec\x93\xde\xde\xf9\x5d\x68\xf5\x06\xd5\x1f\xd4\xf9\x1a\x24\x5b\xca\x3e\xe9\xb5\xfa\x58\x3b\x6d\x2e\x67\x88\x3a\x76\x41\x6d\x9c\x41\xe0\xd5\x29\x41\xef\x9d\xbf\x60\x1d\x23\x36\x6d\x0c\x67\xb6\xf1\xa8\x23\x2a\x1d\x61\xc4\xef\xbe\xa2\x27\xc1\x1f\xa9\x58\xc1\x03\x5b\x41\x61\x4d\x49\x4a\x48\xc9\x6e\x61\xd0\x1c\xc3\x7b\x9d\xf3\x84\x4d\xe9\xf3\x4c\xc2\xa9\x0d\x1d\x4b\x26\x60\xc1\x24\x74\x71\x7b\xdb\xac\xff\xa7\x84\x6f\x26\xe7\x21\x21\xe7\x25\x78\x35\x61\x4b\xb0\xe6\xa0\xae\xfa\xab\x5f\xae\xf3\xea\xfa\x5a\xc5\xcf\xef\x3e\x7c\x4b\x94\x43\xf2\x39\x00\xe8\x71\xc7\x6c\x81\x01\x00\x00")
+
+func _000015_webhook_deliveries_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000015_webhook_deliveries_up_sql,
+		"000015_webhook_deliveries.up.sql",
+	)
+}
+
+var __000016_notifications_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x25\x00\xda\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x6e\x6f\x74\x69\x66\x69\x63\x61\x74\x69\x6f\x6e\x73\x3b\x0a\x03\x00\xe2\xc5\x6f\x1b\x25\x00\x00\x00")
+
+func _000016_notifications_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000016_notifications_down_sql,
+		"000016_notifications.down.sql",
+	)
+}
+
+var __000016_notifications_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x90\x41\x4b\xc3\x30\x18\x86\xcf\xcd\xaf\xf8\x8e\x2d\x94\x5d\x14\x11\x7a\xca\xba\x4c\x83\xb3\x93\x34\xca\x76\x2a\x69\x93\x62\x68\xd7\xcc\x36\xc5\x49\xc8\x7f\x17\x61\xee\xd2\x4e\xf0\xfa\xe4\x25\xef\xf7\x3e\x29\x23\x98\x13\xe0\x78\xb9\x21\x40\xd7\x90\x6d\x39\x90\x1d\xcd\x79\x0e\xce\x2d\x8e\xbd\xaa\xf5\xc9\xfb\xce\x58\x5d\xeb\x4a\x58\x6d\xba\x01\x42\x14\x68\x09\x6f\x98\xa5\x8f\x98\x85\x37\x77\x51\x8c\x82\x4f\xd3\x37\xc3\x51\x54\xaa\x98\x3c\x8d\x83\xea\xa7\x54\x8c\xf6\xdd\xcc\xf0\xd2\x88\x5e\x4e\x71\x35\x4b\xcb\xd6\x54\xcd\x4c\xb8\x57\xc2\xaa\x42\x58\x58\xd2\x07\x9a\xf1\x18\x05\x2f\x8c\x3e\x63\xb6\x87\x27\xb2\x87\x50\xcb\x08\x45\xe0\x9c\xae\x61\x71\xf8\x1a\x3e\x5a\xef\x57\x64\x8d\x5f\x37\x1c\x7e\x3e\xc7\x29\x27\x0c\x72\xc2\x61\xb4\xf5\xfd\xa1\xbc\x75\x4e\x75\xd2\xfb\x04\xa1\xb3\x2e\x9a\xad\xc8\x0e\xb4\x3c\x15\xd7\x24\x15\xbf\xab\xb7\xd9\x55\x91\xe1\x39\x13\xc3\xe5\xe0\x28\xf9\x47\xc5\x65\xfd\x5f\x1d\x65\x6b\xaa\xa6\xd0\x32\x4a\xd0\xf7\x00\xd5\x7e\xab\x3f\xec\x01\x00\x00")
+
+func _000016_notifications_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000016_notifications_up_sql,
+		"000016_notifications.up.sql",
+	)
+}
+
+var __000017_due_date_reminders_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xa8\xae\xd6\x2b\x28\x4a\x4d\xcb\xac\xa8\xad\x4d\xce\x29\x2d\x2e\x49\x2d\x8a\xcf\xc9\x4f\xce\x2e\xb6\xe6\xc2\xae\xa8\x28\x35\x37\x33\x2f\x25\xb5\xa8\x38\xbe\x38\x35\xaf\xc4\x9a\x8b\xcb\xd1\x27\xc4\x35\x08\x53\x5d\x5e\x7e\x49\x66\x5a\x66\x72\x62\x49\x66\x7e\x5e\x31\xc4\x2c\x67\x7f\x9f\x50\x5f\x3f\x85\x92\xca\x82\x54\x6b\x2e\xc0\x00\xef\x50\x43\x98\x83\x00\x00\x00")
+
+func _000017_due_date_reminders_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000017_due_date_reminders_down_sql,
+		"000017_due_date_reminders.down.sql",
+	)
+}
+
+var __000017_due_date_reminders_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x90\x5f\x4b\xf3\x30\x14\xc6\xaf\x97\x4f\x71\xee\xb6\x42\x19\x7b\x5f\x45\x04\xaf\xb2\x2e\xd3\x62\xb7\x49\x96\x89\xbb\x2a\xb5\x3d\x85\xe0\x9a\xce\x24\xc5\x8d\x90\xef\x2e\xc1\xfa\x1f\x44\xbc\x7d\x38\xfc\xce\xf3\x7b\x68\x26\x18\x07\x41\xa7\x19\x03\xe7\xc6\x7b\x8d\xb5\x3c\x78\xaf\x5a\x2b\x6b\x59\x16\x56\xb6\xca\x10\x3a\x9b\x41\xb2\xca\x36\x8b\x25\xd8\xe3\x1e\xe1\x96\xf2\xe4\x8a\xf2\xd1\xff\x49\x04\x33\x36\xa7\x9b\x4c\xc0\xb0\x41\x15\xae\x87\x17\x84\x24\x9c\x51\xc1\x7a\x6a\x3a\x87\xe5\x4a\x00\xbb\x4b\xd7\x62\xfd\xf1\x87\xc6\x46\xaa\x0a\xb5\xc9\x0d\x2a\x0b\x23\x32\x28\x0b\x5d\xe5\xb2\x7a\xe3\x9f\x9c\x45\x31\x19\x74\x06\xf5\xf7\xb4\xea\x30\x2f\x2c\x4c\xd3\xcb\x74\x29\x62\x32\x28\x35\x16\xf6\x73\x74\xc3\xd3\x05\xe5\x5b\xb8\x66\x5b\x18\xf5\xec\x18\x7a\x5c\x0c\x2f\x84\x88\x44\xe0\x9c\xac\x61\xdc\x1c\xcd\xe3\xce\xfb\x57\xa1\xd0\x80\x26\x61\x9d\x35\x13\xd0\xd9\xfa\xbc\xb9\x3f\x75\x0e\x55\xe5\xfd\xaf\x1d\xcb\x5d\x67\x2c\xea\x7c\xd7\x96\x0f\x26\x28\xaa\xa2\x79\xdf\xef\xdf\x64\x12\x54\xda\x27\x85\xfa\x8b\x1e\x1e\xf6\x52\xff\xa0\x13\x38\x7f\xac\xfe\x3c\x00\x6f\xea\x6a\x02\xf3\x01\x00\x00")
+
+func _000017_due_date_reminders_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000017_due_date_reminders_up_sql,
+		"000017_due_date_reminders.up.sql",
+	)
+}
+
+var __000018_audit_entries_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x25\x00\xda\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x61\x75\x64\x69\x74\x5f\x65\x6e\x74\x72\x69\x65\x73\x3b\x0a\x03\x00\xf8\x22\x78\x47\x25\x00\x00\x00")
+
+func _000018_audit_entries_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000018_audit_entries_down_sql,
+		"000018_audit_entries.down.sql",
+	)
+}
+
+var __000018_audit_entries_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x91\x5d\x4b\xc3\x30\x18\x85\xaf\x97\x5f\xf1\x5e\xb6\x50\x86\xe0\x07\xc2\xae\xb2\x2e\xd3\xe0\xec\x24\x8d\xd2\x5d\x95\xd8\xa4\x10\xdc\xda\x9a\x66\xb8\x12\xf2\xdf\xa5\xa0\xd5\x51\x36\xd0\xdb\x93\xc3\x79\xc2\xf3\xc6\x8c\x60\x4e\x80\xe3\xf9\x8a\x00\x5d\x42\xb2\xe6\x40\x32\x9a\xf2\x14\x9c\x9b\x36\x46\x95\xfa\xe0\xbd\xd8\x4b\x6d\x73\x55\x59\xa3\x55\x0b\x01\x9a\x68\x09\x2f\x98\xc5\xf7\x98\x05\x97\x37\x61\x84\x26\xa2\xb0\xb5\xc9\x47\xf1\x47\x6d\xde\xda\x46\x14\x6a\xfc\x24\x0a\xab\xeb\x6a\x08\xaf\x2f\xfa\x50\x55\x56\xdb\x6e\x5c\x6e\x44\xb7\xad\x85\x04\x4e\x32\x1e\xa1\x49\x61\x94\xb0\x2a\x17\x16\xe6\xf4\x8e\x26\x7d\xf4\xc4\xe8\x23\x66\x1b\x78\x20\x1b\x08\xb4\x0c\x51\x08\xce\xe9\x12\xa6\xbb\xae\x7d\xdf\x7a\xbf\x20\x4b\xfc\xbc\xe2\xd0\xc3\x70\xcc\x09\x83\x94\x70\xd8\xdb\xf2\x76\xf7\x7a\xe5\x9c\xaa\xa4\xf7\x33\x84\xbe\x7c\xd0\x64\x41\x32\xd0\xf2\x90\x9f\xb2\x90\xff\xfc\x61\x9d\x9c\x74\x15\x0c\xad\x70\xf6\x87\xed\xc1\xe6\xb9\xe9\xef\x52\x04\xff\x83\x1c\xdd\xe6\x1c\xe8\x77\xf1\x18\xf6\x39\x00\x0d\x5e\xec\xca\x3e\x02\x00\x00")
+
+func _000018_audit_entries_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000018_audit_entries_up_sql,
+		"000018_audit_entries.up.sql",
+	)
+}
+
+var __000019_sessions_expire_at_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x37\x00\xc8\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x73\x65\x73\x73\x69\x6f\x6e\x73\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x65\x78\x70\x69\x72\x65\x5f\x61\x74\x3b\x0a\x03\x00\x06\xde\x82\xb7\x37\x00\x00\x00")
+
+func _000019_sessions_expire_at_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000019_sessions_expire_at_down_sql,
+		"000019_sessions_expire_at.down.sql",
+	)
+}
+
+var __000019_sessions_expire_at_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x47\x00\xb8\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x73\x65\x73\x73\x69\x6f\x6e\x73\x0a\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x65\x78\x70\x69\x72\x65\x5f\x61\x74\x20\x42\x49\x47\x49\x4e\x54\x20\x44\x45\x46\x41\x55\x4c\x54\x20\x30\x3b\x0a\x03\x00\x53\x21\x6c\x96\x47\x00\x00\x00")
+
+func _000019_sessions_expire_at_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000019_sessions_expire_at_up_sql,
+		"000019_sessions_expire_at.up.sql",
+	)
+}
+
+var __000020_access_tokens_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x25\x00\xda\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x61\x63\x63\x65\x73\x73\x5f\x74\x6f\x6b\x65\x6e\x73\x3b\x0a\x03\x00\xee\x7e\xbe\xd8\x25\x00\x00\x00")
+
+func _000020_access_tokens_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000020_access_tokens_down_sql,
+		"000020_access_tokens.down.sql",
+	)
+}
+
+var __000020_access_tokens_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x90\x41\x4f\xc2\x30\x18\x86\xcf\xf4\x57\x7c\xc7\x2d\x21\x1c\x14\x88\x09\xa7\x02\x45\x1b\x71\x68\xd7\x19\x38\x35\x73\xed\x42\x23\x8c\xd9\x76\xc9\x4c\xd3\xff\x6e\x66\x04\x25\x06\xe3\xb5\xcf\xdb\xf7\xcd\xf7\xcc\x18\xc1\x9c\x00\xc7\xd3\x25\x01\xba\x80\x64\xc5\x81\xac\x69\xca\x53\xf0\x7e\x50\x1b\x55\xea\x36\x84\xbc\x28\x94\xb5\xc2\x1d\x5e\x55\x65\x21\x42\x3d\x2d\xe1\x19\xb3\xd9\x1d\x66\xd1\xf5\x38\xee\xa3\xde\x27\x12\xdb\xdc\x6e\x4f\x60\x3c\xec\x40\x63\x95\x11\xbf\xe2\x52\xd9\xc2\xe8\xda\xe9\x43\x75\x22\x57\xa3\x51\xf7\xa1\x30\x2a\x77\x4a\xe4\x0e\xa6\xf4\x96\x26\xbc\x8f\x7a\xbb\xdc\x3a\xd1\x58\x25\xcf\x5e\x55\x5b\x6b\x73\x1e\x7c\x64\xf4\x01\xb3\x0d\xdc\x93\x0d\x44\x5a\xc6\x28\x06\xef\x75\x09\x83\xfd\xbb\x7d\xdb\x85\x30\x27\x0b\x9c\x2d\x39\x74\x7b\x78\xc6\x09\x83\x94\x70\x68\x5c\x79\xb3\x7f\x19\x7a\xaf\x2a\x19\xc2\x04\xa1\x2f\x29\x59\x42\x9f\x32\x02\x34\x99\x93\x35\x68\xd9\x8a\x4b\x46\xc4\x8f\xeb\x57\xc9\x45\x71\xd1\x77\x2c\x9e\x1c\x47\xfe\xd1\x7e\x54\xf8\x57\x75\x63\x95\x11\x5a\xc6\x13\xf4\x31\x00\x26\x74\x9d\x43\xd2\x01\x00\x00")
+
+func _000020_access_tokens_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000020_access_tokens_up_sql,
+		"000020_access_tokens.up.sql",
+	)
+}
+
+var __000021_login_attempts_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x26\x00\xd9\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x6c\x6f\x67\x69\x6e\x5f\x61\x74\x74\x65\x6d\x70\x74\x73\x3b\x0a\x03\x00\x9b\xf7\x71\xb5\x26\x00\x00\x00")
+
+func _000021_login_attempts_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000021_login_attempts_down_sql,
+		"000021_login_attempts.down.sql",
+	)
+}
+
+var __000021_login_attempts_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x44\xcd\x41\x4b\xc3\x30\x18\x87\xf1\x73\xf3\x29\xfe\xc7\x16\xc6\x0e\xe2\x40\xf0\x94\xd5\x77\x33\x38\xab\xa4\xaf\xe2\x4e\xa5\xda\x44\x82\x69\x37\x97\x14\x94\x90\xef\x2e\x03\x61\xd7\xdf\x73\x78\x6a\x4d\x92\x09\x2c\xd7\x3b\x82\xda\xa0\x79\x62\xd0\x9b\x6a\xb9\x45\x4a\xcb\xe3\xc9\x58\xf7\x93\xb3\x3f\x7c\xba\xa9\xeb\x63\x34\xe3\x31\x06\x94\xa2\x70\x03\x5e\xa5\xae\xef\xa5\x2e\xaf\x56\xab\x6a\x21\x0a\xdb\x3b\x3f\x9f\x4c\x80\x6a\x98\xb6\xa4\x17\xa2\xf0\x7d\x88\xdd\xbf\x77\x7d\xc4\x5a\x6d\x55\xc3\xe7\x70\xf8\xf8\x32\x43\x37\x4f\xd1\xf9\x8b\x3e\x6b\xf5\x28\xf5\x1e\x0f\xb4\x47\xe9\x86\x4a\x54\x48\xc9\x59\x2c\xc7\xdf\xf0\xed\x73\xbe\xa3\x8d\x7c\xd9\x31\xce\x57\x59\x33\x69\xb4\xc4\x98\xa3\xbd\x19\xdf\xaf\x53\x32\xd3\x90\xf3\xad\xf8\x1b\x00\x6e\x2a\x74\xa1\xd2\x00\x00\x00")
+
+func _000021_login_attempts_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000021_login_attempts_up_sql,
+		"000021_login_attempts.up.sql",
+	)
+}
+
+var __000022_password_reset_tokens_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x2d\x00\xd2\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x70\x61\x73\x73\x77\x6f\x72\x64\x5f\x72\x65\x73\x65\x74\x5f\x74\x6f\x6b\x65\x6e\x73\x3b\x0a\x03\x00\xfd\x2f\xdd\xfc\x2d\x00\x00\x00")
+
+func _000022_password_reset_tokens_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000022_password_reset_tokens_down_sql,
+		"000022_password_reset_tokens.down.sql",
+	)
+}
+
+var __000022_password_reset_tokens_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x90\x4f\x4b\x03\x31\x10\x47\xcf\x9b\x4f\x31\xc7\x5d\x28\xbd\x58\x8a\xd0\x53\xba\x9d\x6a\xb0\x6e\x25\x1b\xa5\x3d\x85\xd5\xcc\xd2\xa0\x6d\xd7\x24\x8b\x2b\x21\xdf\x5d\x2a\xfe\xa1\xa7\xde\x86\x77\x79\xf3\x7e\xa5\x44\xae\x10\x14\x9f\xaf\x10\xc4\x12\xaa\xb5\x02\xdc\x88\x5a\xd5\x10\xe3\xb8\x73\xd4\xda\x21\xa5\xae\xf1\xfe\xe3\xe8\x8c\x76\xe4\x29\xe8\x70\x7c\xa5\x83\x87\x9c\x65\xdf\x97\xde\x35\x7e\x07\x4f\x5c\x96\xb7\x5c\xe6\xd3\x49\x31\x62\x59\xef\xc9\x69\x6b\xfe\xe8\xd5\xf4\x44\x5f\x1c\x35\x81\x74\x13\x60\x2e\x6e\x44\xa5\x46\x2c\xa3\xa1\xb3\xee\x1c\x3d\x48\x71\xcf\xe5\x16\xee\x70\x0b\xf9\xbf\xa1\x60\x05\xc4\x68\x5b\x18\xef\x3f\xfd\xfb\x5b\x4a\x0b\x5c\xf2\xc7\x95\x82\x93\x80\x97\x0a\x25\xd4\xa8\xa0\x0f\xed\xf5\xfe\x79\x12\x23\x1d\x4c\x4a\x33\xc6\x7e\x12\x45\xb5\xc0\x0d\x58\x33\xe8\x4b\x61\xfa\xf7\xf9\x75\x75\x71\x84\xbc\xf7\xe4\xb4\x35\xc5\x8c\x7d\x0d\x00\x1b\xb0\xe5\x09\x4b\x01\x00\x00")
+
+func _000022_password_reset_tokens_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000022_password_reset_tokens_up_sql,
+		"000022_password_reset_tokens.up.sql",
+	)
+}
+
+var __000023_user_mfa_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x60\x00\x9f\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x6d\x66\x61\x5f\x72\x65\x63\x6f\x76\x65\x72\x79\x5f\x63\x6f\x64\x65\x73\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x75\x73\x65\x72\x73\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x6d\x66\x61\x5f\x61\x63\x74\x69\x76\x65\x3b\x0a\x03\x00\xc8\x3d\xac\x3d\x60\x00\x00\x00")
+
+func _000023_user_mfa_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000023_user_mfa_down_sql,
+		"000023_user_mfa.down.sql",
+	)
+}
+
+var __000023_user_mfa_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x4c\xcf\xc1\x6a\xc2\x40\x10\x06\xe0\xb3\xfb\x14\xff\x31\x01\xf1\x52\x91\x82\xa7\x31\x99\xd0\xd0\x35\x29\x9b\xb5\xd4\x53\x48\x93\x0d\x06\x9a\xda\x66\x55\x2a\xcb\xbe\x7b\x59\xb0\xd2\xeb\x3f\x33\xdf\xcc\x90\xd4\xac\xa0\x69\x23\x19\xce\x2d\xbe\x26\xd3\x0f\x3f\xde\x9f\xad\x99\xac\xa0\x34\x45\x52\xca\xdd\xb6\xc0\xd8\x37\x75\xd3\x9e\x86\x8b\xc1\xa6\x2c\x25\x53\x81\x94\x33\xda\x49\x8d\x8c\x64\xc5\x6b\x21\x12\xc5\xa4\xf9\x66\xe5\x19\x8a\x52\x83\xdf\xf2\x4a\x57\xff\xe5\x00\x4d\xa6\x3d\x5e\xcc\x74\xad\xdb\x63\x67\x2c\x22\x31\x0b\xfb\xea\xa1\xc3\x2b\xa9\xe4\x89\x54\xf4\xb0\x8a\xe7\x62\x16\xca\xf5\xa1\xb1\x87\x7b\xbe\x5a\x86\xfc\x45\xe5\x5b\x52\x7b\x3c\xf3\x1e\xd1\x6d\x74\x8e\x7b\x77\x2c\x62\x38\x37\xf4\x58\x8c\x57\xfb\xfd\xe1\xfd\xdf\xa5\x81\xa0\x24\x3c\x5c\xb1\xc6\xf9\xd4\x3f\x8e\xef\x4b\xe7\xcc\x67\xe7\xfd\x5a\xfc\x0e\x00\x80\xe3\x5c\x8e\x0b\x01\x00\x00")
+
+func _000023_user_mfa_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000023_user_mfa_up_sql,
+		"000023_user_mfa.up.sql",
+	)
+}
+
+var __000024_migrations_log_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x26\x00\xd9\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x6d\x69\x67\x72\x61\x74\x69\x6f\x6e\x73\x5f\x6c\x6f\x67\x3b\x0a\x03\x00\x3b\x23\xa6\x19\x26\x00\x00\x00")
+
+func _000024_migrations_log_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000024_migrations_log_down_sql,
+		"000024_migrations_log.down.sql",
+	)
+}
+
+var __000024_migrations_log_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x44\xcd\x51\x4b\x85\x30\x18\xc6\xf1\x6b\xf7\x29\xde\x4b\x85\xc3\xe1\x04\x5d\x04\x5d\xed\xd8\x6b\x8d\xcc\x62\xbe\x45\x5e\xc9\xc2\x29\x03\x37\xcd\x69\x14\x63\xdf\x3d\xa2\x03\xde\x3e\x3c\xfc\xfe\xb9\x44\x4e\x08\xc4\xcf\x25\x82\x28\xa0\x7a\x26\xc0\x77\x51\x53\x0d\x21\x1c\xe7\x45\xf7\xe6\x3b\x46\x6b\x86\x45\xad\x66\x72\xbe\x1d\xa7\x01\x52\x96\x7c\xe9\xc5\x9b\xc9\xc1\x59\xdc\x8b\x8a\x0e\x2c\x71\xca\x6a\x78\xe3\x32\x7f\xe0\x32\xbd\x3a\x9d\xb2\x03\x4b\xd4\x3c\x8f\x46\x77\xad\x5a\xf7\x5f\xb7\xfd\x4b\xad\xf5\xfb\xf8\x22\xc5\x13\x97\x0d\x3c\x62\x03\xe9\x85\xce\x58\x06\x21\x98\x1e\x8e\xf6\xc7\x7f\x8e\x31\xde\x61\xc1\x5f\x4b\x82\xbf\x02\xcf\x09\x25\xd4\x48\xb0\xad\xfd\x8d\xfd\xb8\x0e\x41\xbb\x2e\xc6\x5b\xf6\x3b\x00\x14\xa3\x1f\x48\xd1\x00\x00\x00")
+
+func _000024_migrations_log_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000024_migrations_log_up_sql,
+		"000024_migrations_log.up.sql",
+	)
+}
+
+var __000025_recurrence_runs_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x27\x00\xd8\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x72\x65\x63\x75\x72\x72\x65\x6e\x63\x65\x5f\x72\x75\x6e\x73\x3b\x0a\x03\x00\x29\xce\x66\x84\x27\x00\x00\x00")
+
+func _000025_recurrence_runs_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000025_recurrence_runs_down_sql,
+		"000025_recurrence_runs.down.sql",
+	)
+}
+
+var __000025_recurrence_runs_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\xcd\xc1\x4a\xc4\x30\x14\x85\xe1\x75\xf3\x14\x67\xd9\x42\x99\x8d\x22\x82\xab\x4c\xbd\xa3\xc1\x71\x94\xf4\x2a\xce\x6a\xa8\x69\x0a\x05\x1b\xf4\xb6\x01\x25\xe4\xdd\x45\xdc\x58\xdc\x1e\x7e\xbe\xd3\x58\xd2\x4c\x60\xbd\xdd\x13\xcc\x0e\x87\x07\x06\xbd\x98\x96\x5b\xa4\xb4\x79\x17\x3f\x8c\x9f\x39\x8b\x77\x51\xc4\x07\xe7\x4f\x12\xc3\x8c\x52\x15\x7f\xa6\xb1\xc7\xb3\xb6\xcd\xad\xb6\xe5\xd9\x45\x55\xab\x42\x62\x38\x75\x0b\xb6\xe6\xc6\x1c\xb8\x56\x85\xeb\xa4\xff\x5f\x39\xf1\xdd\xe2\x57\xe1\xa3\x35\xf7\xda\x1e\x71\x47\x47\x94\xab\x87\x1a\xbf\x68\xa5\x2a\xa4\x34\x0e\xd8\x4c\x5f\xf3\xc7\x5b\xce\xd7\xb4\xd3\x4f\x7b\xc6\x0f\xac\x1b\x26\x8b\x96\x18\x71\x19\x2e\xa7\xd7\xf3\x94\x7c\xe8\x73\xbe\x52\xdf\x03\x00\xfe\x71\x44\x49\xe7\x00\x00\x00")
+
+func _000025_recurrence_runs_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000025_recurrence_runs_up_sql,
+		"000025_recurrence_runs.up.sql",
+	)
+}
+
+var __000026_board_sequences_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x27\x00\xd8\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x62\x6f\x61\x72\x64\x5f\x73\x65\x71\x75\x65\x6e\x63\x65\x73\x3b\x0a\x03\x00\x44\x26\x3b\x38\x27\x00\x00\x00")
+
+func _000026_board_sequences_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000026_board_sequences_down_sql,
+		"000026_board_sequences.down.sql",
+	)
+}
+
+var __000026_board_sequences_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x34\xcc\xc1\xaa\x82\x40\x14\x80\xe1\xb5\xf3\x14\x67\xa9\x20\x6e\xee\xe5\x72\xa1\xd5\x68\xc7\x1a\x32\x8b\xf1\x14\xb9\x12\xcd\x11\x04\xb5\x74\x14\x8a\x61\xde\x3d\x22\xda\xfe\xf0\x7f\x91\x44\x4e\x08\xc4\xc3\x04\x41\xc4\x90\x1e\x08\xf0\x22\x32\xca\xc0\x98\xe0\x3e\xa9\xa6\x7d\x58\x5b\xdd\xca\xa9\x2e\xb4\x1a\x17\x35\x5c\x95\x06\x97\x39\x9f\xd4\xd6\x70\xe6\x32\xda\x72\xe9\xfe\xfc\x79\x3e\x73\xba\x52\xcf\xc5\xb0\xf4\x95\x9a\x20\x14\x1b\x91\x92\xcf\x9c\xa3\x14\x7b\x2e\x73\xd8\x61\x0e\xee\x77\xf4\x98\x07\xc6\xb4\x0d\x04\xfd\x53\x8f\x9d\xb5\x6b\x8c\xf9\x29\x21\x78\x6b\x3c\x22\x94\x90\x21\xc1\x32\x37\xff\x7d\xf5\x6b\x8c\x1a\x6a\x6b\x57\xec\x35\x00\xa0\x92\x77\xdd\xb1\x00\x00\x00")
+
+func _000026_board_sequences_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000026_board_sequences_up_sql,
+		"000026_board_sequences.up.sql",
+	)
+}
+
+var __000027_calendar_feeds_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x26\x00\xd9\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x63\x61\x6c\x65\x6e\x64\x61\x72\x5f\x66\x65\x65\x64\x73\x3b\x0a\x03\x00\x8d\x16\x85\xfa\x26\x00\x00\x00")
+
+func _000027_calendar_feeds_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000027_calendar_feeds_down_sql,
+		"000027_calendar_feeds.down.sql",
+	)
+}
+
+var __000027_calendar_feeds_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\xcc\xc1\x4b\xc3\x30\x14\xc7\xf1\x73\xf3\x57\xbc\x63\x0b\x63\x4c\x14\x11\x3c\x65\xf5\x4d\x83\x73\x4a\xfa\x14\x77\x2a\x59\xf3\x02\x61\x5b\x37\xd3\x88\x8e\x90\xff\x5d\x8a\xe0\xc1\x5d\x7f\xdf\x0f\xbf\x5a\xa3\x24\x04\x92\xf3\x25\x82\x5a\xc0\xea\x99\x00\xdf\x55\x43\x0d\xa4\x34\x3d\x06\x76\xfe\x3b\xe7\xce\xec\xb8\xb7\x26\xb4\x8e\xd9\x0e\x50\x8a\xc2\x5b\x78\x93\xba\x7e\x90\xba\xbc\xbc\xae\x26\xa2\xf8\x3a\x84\xed\x70\x34\x1d\xb7\x67\x29\x1e\xb6\xdc\xff\x6d\x17\xb3\xd9\xe8\xbb\xc0\x26\xb2\x6d\x37\xa7\x7f\xfa\x37\xb4\x26\xc2\x5c\xdd\xab\x15\x4d\x44\xf1\xa2\xd5\x93\xd4\x6b\x78\xc4\x35\x94\xde\x56\xa2\x82\x94\xbc\x83\xe9\xfe\x34\x7c\xec\x72\xbe\xc3\x85\x7c\x5d\x12\x8c\x2f\xb2\x26\xd4\xd0\x20\xc1\x67\x74\x37\xfb\xcd\x55\x4a\xdc\xdb\x9c\x6f\xc5\xcf\x00\x2a\xa7\xe7\xa7\xeb\x00\x00\x00")
+
+func _000027_calendar_feeds_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000027_calendar_feeds_up_sql,
+		"000027_calendar_feeds.up.sql",
+	)
+}
+
+var __000028_files_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x1d\x00\xe2\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x66\x69\x6c\x65\x73\x3b\x0a\x03\x00\x34\xe2\x83\x6c\x1d\x00\x00\x00")
+
+func _000028_files_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000028_files_down_sql,
+		"000028_files.down.sql",
+	)
+}
+
+var __000028_files_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\x8f\x51\x4b\xc3\x30\x14\x46\x9f\x9b\x5f\xf1\x3d\xb6\x30\xc6\x44\x11\x61\x4f\x59\x77\xa7\xc1\xd9\x49\x7a\x95\xed\xa9\xd4\x35\x85\xe0\x66\x67\x13\x71\x1a\xf2\xdf\x65\x20\x38\xb7\xd7\x73\x2e\xdc\xef\xe4\x9a\x24\x13\x58\x4e\xe6\x04\x35\x43\xb1\x60\xd0\x52\x95\x5c\x22\x84\xe1\xae\x37\xad\xdd\xc7\xd8\xda\x8d\x71\x48\x45\x62\x1b\x3c\x4b\x9d\xdf\x49\x9d\x5e\x8c\x46\xd9\x40\x24\x9f\x5d\xff\xea\x76\xf5\xda\x54\x47\xee\xf2\xfa\xa0\xfa\xae\xf3\xe7\xd4\xd9\x6f\x83\x89\xba\x55\x05\x0f\x44\xb2\xee\x4d\xed\x4d\x55\xfb\x3f\xf4\xa8\xd5\x83\xd4\x2b\xdc\xd3\x0a\xa9\x6d\x32\x91\x21\x04\xdb\x62\xb8\xfd\x72\xef\x9b\x18\xa7\x34\x93\x4f\x73\xc6\xe1\x93\xcc\x99\x34\x4a\x62\x7c\xf8\xf6\x66\xfb\x72\x15\x82\x79\x6b\x62\x1c\x0b\xf1\x5b\xa6\x8a\x29\x2d\x61\x9b\x7d\x75\xda\x53\xfd\x9b\xbe\x28\xce\x82\xd3\xe3\x83\x6c\x2c\x7e\x06\x00\x00\x1c\xbd\x29\x2c\x01\x00\x00")
+
+func _000028_files_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000028_files_up_sql,
+		"000028_files.up.sql",
+	)
+}
+
+var __000029_user_is_admin_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x33\x00\xcc\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x75\x73\x65\x72\x73\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x69\x73\x5f\x61\x64\x6d\x69\x6e\x3b\x0a\x03\x00\x08\x98\xe4\x25\x33\x00\x00\x00")
+
+func _000029_user_is_admin_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000029_user_is_admin_down_sql,
+		"000029_user_is_admin.down.sql",
+	)
+}
+
+var __000029_user_is_admin_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\x8f\x51\x4b\xc3\x30\x14\x85\x9f\xcd\xaf\x38\x8f\x1b\x6c\x82\xcf\xc3\x87\x6c\xbd\xc5\x42\xda\x6a\x9a\x22\x3e\x95\xb8\xdc\x6a\xa0\xd3\x91\xa4\xa2\x8c\xfd\x77\x69\x45\x04\xf7\x76\x0f\xf7\xe3\xe3\x1c\xa9\x0c\x69\x18\xb9\x55\x84\xd3\xe9\xfa\x18\xb8\xf7\x9f\xe7\xf3\x18\x39\x44\x21\xb3\x0c\xbb\x5a\xb5\x65\x05\x1f\x3b\xeb\x0e\xfe\x0d\xdb\xba\x56\x24\x2b\x64\x94\xcb\x56\x19\xe4\x52\x35\xb4\x11\x62\xbd\x46\x7a\x65\xf4\x3e\xc4\x84\xc0\x2f\x3e\x26\x0e\xec\x30\x99\xe0\xe3\xfc\x9c\x0d\xab\xf9\x74\x1c\xfc\x07\x3b\x24\xfb\x3c\x30\x06\x4e\x11\xe5\x57\xf3\xa0\x26\x4f\xe4\x81\xf7\x09\x7d\x78\x3f\xcc\xec\x78\x74\x36\xfd\xb2\xa2\xbd\xcf\xa4\xb9\x2c\x8b\x86\xcc\x5f\xcb\x5b\x18\xdd\x12\x1e\xef\x48\x13\xbc\x43\x51\x61\x21\xae\x1a\x52\xb4\x33\x53\xce\x75\x5d\x62\xf1\x2f\x5f\x28\x6b\x9d\x91\xc6\xf6\x09\xfb\xc0\x36\x71\x67\xd3\x6a\x82\x55\x51\x16\x06\x37\x4b\xc8\xe6\x67\x6f\x37\x46\x0e\x62\xb9\x11\xdf\x03\x00\xd8\x12\x00\xcc\x4e\x01\x00\x00")
+
+func _000029_user_is_admin_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000029_user_is_admin_up_sql,
+		"000029_user_is_admin.up.sql",
+	)
+}
+
+var __000030_board_members_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x25\x00\xda\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x62\x6f\x61\x72\x64\x5f\x6d\x65\x6d\x62\x65\x72\x73\x3b\x0a\x03\x00\xb3\x77\xcf\x33\x25\x00\x00\x00")
+
+func _000030_board_members_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000030_board_members_down_sql,
+		"000030_board_members.down.sql",
+	)
+}
+
+var __000030_board_members_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\xcd\xcd\x4a\xc3\x40\x14\x47\xf1\x75\xe6\x29\xfe\xcb\x04\x42\xa9\x28\x22\xb8\x9a\xc6\x5b\x1d\xac\x55\x26\x57\xb1\xab\x90\x34\x37\x10\x6c\x4c\x9d\xa4\xa8\x0c\xf3\xee\x52\x3f\xb0\xd0\xed\xe1\xc0\x2f\xb3\xa4\x99\xc0\x7a\xb6\x20\x98\x39\x96\xf7\x0c\x7a\x36\x39\xe7\xf0\x7e\xb2\x75\xd2\xb4\x1f\x21\x54\x7d\xe9\xea\xa2\x93\xae\x12\x37\x20\x56\xd1\x7b\xef\x5e\x86\x6d\xb9\x96\xa2\xad\xf1\xa4\x6d\x76\xa3\x6d\x7c\x7a\x9e\xa4\x2a\xfa\x79\x8f\xf2\x6e\x10\x77\x58\x4f\xa6\xd3\xfd\xed\xfa\x8d\xfc\xb7\xef\x73\xed\xa4\x1c\xa5\x28\x47\xcc\xcc\xb5\x59\x72\xaa\xa2\x07\x6b\xee\xb4\x5d\xe1\x96\x56\x88\x0f\xed\x14\x7f\x5c\x8a\x5f\x21\x51\x09\xbc\x6f\x1b\x4c\xba\xcf\xe1\x6d\x13\xc2\x15\xcd\xf5\xe3\x82\xb1\x27\x74\xc6\x64\x91\x13\x63\x37\x36\x17\x5d\x75\xe6\xbd\xbc\xd6\x21\x5c\xaa\xaf\x01\x00\xbc\xe6\x07\xcf\x09\x01\x00\x00")
+
+func _000030_board_members_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000030_board_members_up_sql,
+		"000030_board_members.up.sql",
+	)
+}
+
+var __000031_guests_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x59\x00\xa6\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x67\x75\x65\x73\x74\x5f\x69\x6e\x76\x69\x74\x65\x73\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x75\x73\x65\x72\x73\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x69\x73\x5f\x67\x75\x65\x73\x74\x3b\x0a\x03\x00\x2e\x54\x2f\x82\x59\x00\x00\x00")
+
+func _000031_guests_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000031_guests_down_sql,
+		"000031_guests.down.sql",
+	)
+}
+
+var __000031_guests_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\x90\x51\x6b\xfa\x30\x14\x47\x9f\x9b\x4f\x71\x1f\x15\x44\xfc\xf3\x17\x19\xf8\x14\xdb\x74\x2b\x8b\xed\x48\xe3\x98\x4f\xa1\xb5\xe9\x0c\x6a\xdb\x25\xe9\xa6\x84\x7c\xf7\xa1\x6c\x2a\xec\xf5\xfc\x0e\x07\xee\xc5\x94\x13\x06\x1c\x2f\x28\x01\xe7\xc6\x9d\x96\xb5\x3a\x7a\xdf\x1b\xa9\x0d\xc2\x51\x04\x61\x46\x57\xcb\x14\x94\x11\xef\xbd\x34\x16\x16\x59\x46\x09\x4e\x21\x22\x31\x5e\x51\x0e\x31\xa6\x39\x99\x23\x14\x32\x82\x39\xf9\x29\x25\x31\xa4\x19\x07\xf2\x96\xe4\x3c\xbf\xef\x5e\x1a\x42\x35\x9f\xca\x4a\x03\x03\x14\xd8\x76\x27\x1b\xb1\x2d\xcc\x16\x5e\x31\x0b\x9f\x30\x1b\xcc\xa6\x43\x78\x61\xc9\x12\xb3\x35\x3c\x93\xf5\x08\x05\x5f\xad\xde\x99\xae\xd8\x48\xa1\xaa\xab\xf6\x7f\x36\x1c\xa1\xa0\x6c\x0b\x5d\xfd\xc5\xba\xdd\xcb\x2b\xfa\x77\x31\x37\x5a\x16\x56\x56\xa2\x3c\xdd\x86\xc9\xe4\xb6\x88\xc2\xc2\x22\x79\x4c\x52\x3e\x42\x81\x3c\x76\x4a\xdf\x21\x34\x04\xe7\x54\x0d\xe3\xc3\xc9\x7c\xec\xbd\xff\xbd\xfe\x9c\xc1\xe1\xf9\x85\x39\xe1\xd0\xdb\xfa\xe1\x50\x4e\x9d\x93\x4d\xe5\xfd\x1c\x7d\x0f\x00\x24\x79\x8f\x7b\x5d\x01\x00\x00")
+
+func _000031_guests_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000031_guests_up_sql,
+		"000031_guests.up.sql",
+	)
+}
+
+var __000032_workspace_stats_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xaa\xae\xce\x4c\x53\xd0\xcb\xad\x2c\x2e\xcc\xa9\xad\xe5\x72\x09\xf2\x0f\x50\xf0\xf4\x73\x71\x8d\x50\xc8\x4c\xa9\x88\xaf\xae\xd6\x2b\x28\x4a\x4d\xcb\xac\xa8\xad\x4d\xca\xc9\x4f\xce\x2e\x8e\x2f\x2d\x48\x49\x2c\x49\x8d\x4f\x2c\x89\x2f\xcf\x2f\xca\x2e\x2e\x48\x4c\x4e\x8d\xcf\x4c\x51\xf0\xf7\x53\xc0\x50\x6b\xcd\x55\x5d\x9d\x9a\x53\x9c\x4a\xbe\xb1\x60\x13\xf2\x52\x6a\x6b\xb9\xb8\x1c\x7d\x42\x5c\x83\x14\x42\x1c\x9d\x7c\x5c\x91\x6d\x82\xab\x2e\x86\xd8\xe1\xec\xef\x13\xea\xeb\xa7\x90\x5c\x94\x9a\x58\x92\x1a\x9f\x58\x62\xcd\x05\x18\x00\xef\x57\x23\xba\xe0\x00\x00\x00")
+
+func _000032_workspace_stats_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000032_workspace_stats_down_sql,
+		"000032_workspace_stats.down.sql",
+	)
+}
+
+var __000032_workspace_stats_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x8e\xc1\x6a\x84\x30\x10\x40\xef\xf9\x8a\xb9\xb5\x85\xda\x1f\x90\x1e\xa2\x09\x45\xb0\xb1\xd8\x08\xbd\x05\x6b\x62\x3b\xb8\x9b\x88\xc9\xa2\x20\xfe\xfb\xb2\x2b\xa8\x97\xbd\x85\xcc\x9b\xf7\x86\xe6\x92\x97\x20\x69\x92\x73\x98\xe7\xb7\x7e\x30\x2d\x4e\xcb\x32\xba\xa1\xf3\x7d\xdd\x18\x4f\x28\x63\x90\x16\x79\xf5\x29\xa0\x19\x4c\x1d\x8c\xaa\x03\x24\xd9\x47\x26\x64\x4c\x48\x14\x41\xf8\x37\xeb\x04\x9d\x85\x80\x67\x03\xae\xbd\x7f\x9a\x09\x7d\x40\xfb\x07\xbb\x0d\xd0\xdb\xa7\x00\x9d\x75\xa3\x25\xd5\x17\xa3\xf2\x41\x16\xbe\xb9\x3c\xf4\xde\xe1\xd2\xeb\xf5\x1d\x13\x92\x96\xfc\xb6\x98\x09\xc6\x7f\x00\xf5\xa4\x0e\x8a\xdf\x93\x6b\x3a\xaf\x36\x5c\x6d\x4e\x85\x1a\x0a\x71\xcc\xad\xec\xf3\xc6\xbe\xee\x97\x2a\xd4\x2f\x31\xb9\x0e\x00\x73\xa1\xee\xef\x1e\x01\x00\x00")
+
+func _000032_workspace_stats_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000032_workspace_stats_up_sql,
+		"000032_workspace_stats.up.sql",
+	)
+}
+
+var __000033_subscriptions_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x25\x00\xda\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x73\x75\x62\x73\x63\x72\x69\x70\x74\x69\x6f\x6e\x73\x3b\x0a\x03\x00\xba\xdf\x6d\x6e\x25\x00\x00\x00")
+
+func _000033_subscriptions_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000033_subscriptions_down_sql,
+		"000033_subscriptions.down.sql",
+	)
+}
+
+var __000033_subscriptions_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x90\x5f\x6b\xf2\x30\x1c\x85\xaf\x9b\x4f\x71\x2e\x5b\x28\xa2\xbc\x2f\x63\xe0\x55\xac\x71\x0b\x73\x75\xa4\xd9\xd0\xab\xd2\x3f\x29\x04\xb5\xed\xda\xc8\x94\x90\xef\x3e\x1c\x6e\xd8\x8b\xde\x9e\x27\x39\xbf\xc3\x13\x09\x46\x25\x83\xa4\x8b\x35\x03\x5f\x21\xde\x48\xb0\x2d\x4f\x64\x02\x6b\x27\x6d\xa7\x2a\x7d\x76\xae\x3f\xe5\x7d\xd1\xe9\xd6\xe8\xa6\xee\xe1\x13\xef\xab\xe9\xf6\x7d\x9b\x15\x2a\xd5\x25\x3e\xa8\x88\x9e\xa9\xf0\xff\x3d\x04\x21\xf1\xf2\x43\x53\xec\xc7\x62\x73\x69\xd5\x1f\x98\x4d\xaf\xe0\xd6\x9d\xab\xee\xfe\xd3\x6c\xfa\x03\x8b\x4e\x65\x46\xa5\x99\xc1\x82\x3f\xf1\x58\x86\xc4\xab\x1b\xa3\x2b\xad\xca\x41\xf8\x26\xf8\x2b\x15\x3b\xbc\xb0\x1d\xfc\xdf\x05\x21\x06\xdd\x01\x09\x60\xad\xae\x30\x39\x5e\xfa\xcf\x83\x73\x4b\xb6\xa2\xef\x6b\x89\xeb\x18\x1a\x49\x26\x90\x30\x89\x93\xa9\x1e\x8f\xf9\x7f\x6b\x55\x5d\x3a\x37\x27\xe4\xa6\x88\xc7\x4b\xb6\x85\x2e\xcf\xe9\x98\x98\x74\x70\x0e\x9b\x78\x54\xa1\x3f\x78\x19\xe2\x5e\x67\x30\x27\xdf\x03\x00\xf7\x85\xa7\xe3\x95\x01\x00\x00")
+
+func _000033_subscriptions_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000033_subscriptions_up_sql,
+		"000033_subscriptions.up.sql",
+	)
+}
+
+var __000034_digests_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x1f\x00\xe0\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x64\x69\x67\x65\x73\x74\x73\x3b\x0a\x03\x00\x31\x6f\x36\xdc\x1f\x00\x00\x00")
+
+func _000034_digests_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000034_digests_down_sql,
+		"000034_digests.down.sql",
+	)
+}
+
+var __000034_digests_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x2c\xcc\x41\x8b\x82\x40\x14\xc0\xf1\xb3\xf3\x29\xde\x51\x41\xc4\x85\x3d\x2c\xec\x69\x74\x9f\xdb\x90\x59\x8c\xaf\xc8\x93\x18\x8e\x31\xa0\x51\xce\x08\xc5\x30\xdf\x3d\xa2\xee\xff\xff\x2f\x97\xc8\x09\x81\x78\x56\x22\x88\x02\xaa\x2d\x01\x1e\x45\x4d\x35\x38\x97\x5c\x67\x35\xe8\xbb\xf7\xbd\x3e\x2b\x63\x0d\x84\x2c\x58\x8c\x9a\x5b\xdd\xc3\x81\xcb\x7c\xc5\x65\xf8\x95\xa6\x51\xcc\x82\xb1\x33\xb6\x7d\x67\x6d\x67\x21\x13\xff\xa2\xa2\x98\x05\x3b\x29\x36\x5c\x36\xb0\xc6\x06\xc2\xcf\x1b\xb1\x08\x9c\xd3\x03\x24\xd3\xc3\xdc\x46\xef\xff\xb0\xe0\xfb\x92\xe0\x05\xf2\x9c\x50\x42\x8d\x04\x8b\x1d\x7e\xa6\xd3\xb7\x73\xea\xd2\x7b\xff\xcb\x9e\x03\x00\x92\x09\xaf\x50\xab\x00\x00\x00")
+
+func _000034_digests_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000034_digests_up_sql,
+		"000034_digests.up.sql",
+	)
+}
+
+var __000035_inbound_hooks_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x54\x00\xab\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x69\x6e\x62\x6f\x75\x6e\x64\x5f\x68\x6f\x6f\x6b\x5f\x64\x65\x6c\x69\x76\x65\x72\x69\x65\x73\x3b\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x69\x6e\x62\x6f\x75\x6e\x64\x5f\x68\x6f\x6f\x6b\x73\x3b\x0a\x03\x00\x83\x25\x6f\x56\x54\x00\x00\x00")
+
+func _000035_inbound_hooks_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000035_inbound_hooks_down_sql,
+		"000035_inbound_hooks.down.sql",
+	)
+}
+
+var __000035_inbound_hooks_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\xd1\xcf\x6e\xe2\x30\x10\x06\xf0\x73\xfc\x14\x73\x4c\xa4\x88\xcb\x22\xb4\x12\x27\x03\x66\x37\x2a\x4d\xda\x60\x2a\x38\x59\x4e\x6c\x84\x45\x88\x53\xdb\x14\x50\x94\x77\xaf\x10\xe5\x8f\x5a\x50\xcb\xa1\xe7\xcf\x33\xd6\xfc\xbe\x7e\x4a\x30\x25\x40\x71\x6f\x44\x20\x1a\x42\x9c\x50\x20\xd3\x68\x4c\xc7\x50\xd7\xad\xca\xc8\xb9\xda\x36\x8d\x2a\x33\xbd\x2e\x05\x5b\x68\xbd\xb4\xe0\x23\x4f\x09\x78\xc1\x69\xff\x3f\x4e\xfd\x3f\x9d\x20\x44\xde\x46\x9b\xa5\xad\x78\x2e\xd9\x97\xc8\xe9\xa5\x2c\xd9\x82\xdb\xc5\x29\xe8\xb4\xf7\x33\xbc\x28\xf4\x86\x95\x72\xc3\x74\xe5\x94\x2e\x2d\xf4\x92\x64\x44\x70\x1c\x22\x2f\x37\x92\x3b\x29\x58\xb6\xfb\xb4\xed\x10\x30\xee\xa0\x17\xfd\x8b\x62\x1a\x22\xef\x29\x8d\x1e\x71\x3a\x83\x07\x32\x03\x5f\x89\x00\x05\x50\xd7\x6a\x0e\xad\xd5\xce\xbe\x16\x4d\x33\x20\x43\x3c\x19\x51\xd8\x6f\xc1\x7d\x4a\x52\x18\x13\x0a\x6b\x37\xff\xbb\xca\xda\x75\x2d\x4b\xd1\x34\x5d\x84\x3e\x28\x26\x71\xf4\x3c\x21\x10\xc5\x03\x32\x05\x25\xb6\xec\x96\x03\xbb\x38\x2c\x89\x6f\x72\xf9\xe7\x67\xc1\xf9\x97\x3b\xc0\x99\x90\x85\x7a\x93\x46\xc9\xeb\xf4\x99\xe6\x46\xb0\x7b\x1a\xc9\xaf\x0e\x54\x7c\x57\x68\x2e\x80\x92\xe9\x5e\xd5\x3a\xee\xd6\x96\xe5\x5a\x48\x38\x38\x4b\x63\xb4\x39\xc6\xbf\xde\xc3\xb7\x05\x5c\xb8\xb0\x93\x41\x12\xff\x80\xd1\x3f\x3e\x0f\xe1\x74\x46\xd0\x45\xef\x03\x00\x78\x9b\xf1\x96\x0c\x03\x00\x00")
+
+func _000035_inbound_hooks_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000035_inbound_hooks_up_sql,
+		"000035_inbound_hooks.up.sql",
+	)
+}
+
+var __000036_github_integrations_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x55\x00\xaa\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x67\x69\x74\x68\x75\x62\x5f\x69\x73\x73\x75\x65\x5f\x6c\x69\x6e\x6b\x73\x3b\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x67\x69\x74\x68\x75\x62\x5f\x69\x6e\x74\x65\x67\x72\x61\x74\x69\x6f\x6e\x73\x3b\x0a\x03\x00\xd2\x8c\xe7\x37\x55\x00\x00\x00")
+
+func _000036_github_integrations_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000036_github_integrations_down_sql,
+		"000036_github_integrations.down.sql",
+	)
+}
+
+var __000036_github_integrations_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x91\x4f\x6b\xdb\x40\x10\xc5\xcf\xd6\xa7\x98\xa3\x0d\x26\xd0\x3f\x29\x85\x9e\x64\x77\xd3\x8a\xba\x76\x91\xb7\x25\x39\x2d\xab\xdd\x71\xb2\x58\xda\x55\x67\x47\xb4\x42\xe8\xbb\x17\x29\xc4\x75\x50\x28\xb8\xd7\xf7\x66\x1e\x6f\xe6\xb7\xce\x45\x2a\x05\xc8\x74\xb5\x11\x90\xdd\xc0\x76\x27\x41\xdc\x66\x7b\xb9\x87\xae\xbb\xaa\x09\x0f\xee\x77\xdf\xdf\x3b\x7e\x68\x0a\xe5\x3c\xe3\x3d\x69\x76\xc1\x47\x98\x27\x33\x67\xe1\x47\x9a\xaf\x3f\xa7\xf9\xfc\xcd\xbb\xc5\x32\x99\xfd\x0a\x74\x8c\xb5\x36\xa8\x26\x16\x61\x1d\xa2\xe3\x40\xed\xc9\x78\x7d\x7d\x3d\x2c\x71\x38\xa2\x57\xe8\x0d\xb5\x35\xa3\x05\x29\x6e\xe5\x90\x85\xc5\x43\x08\x47\x15\xd1\x10\xf2\xd4\x2f\x75\x81\xa5\x3a\xb8\x92\x91\x9e\x34\x13\xaa\x0a\x3d\xab\xe0\x95\x29\x43\x44\x58\xed\x76\x1b\x91\x6e\x97\xc9\x2c\xb2\x66\x54\x35\x85\x1a\x89\xdb\x69\xbf\x31\x2e\xfe\x63\xc0\x10\x6a\x46\xab\x8a\xf6\x45\x43\x69\x86\x55\xf6\x29\xdb\x0e\x3d\x4a\x1d\x59\xc5\xd6\x9b\x67\x2a\x0d\x0d\x4a\x57\x39\x56\x84\x11\x79\xba\x82\x44\xe1\x74\xcc\xb7\x3c\xfb\x9a\xe6\x77\xf0\x45\xdc\xc1\xdc\xd9\x45\xb2\x80\xae\x73\x07\xb8\xaa\xda\xf8\xb3\xec\xfb\x8f\xe2\x26\xfd\xbe\x91\x30\x74\x4c\xd7\x52\xe4\xb0\x17\x12\x1a\x3e\xbc\xaf\x8a\xb7\x5d\x87\xde\xf6\xfd\x87\x24\xb9\x8c\x70\x8c\xcd\xd0\xd1\x1f\x47\xc0\x45\xd0\x64\xd5\x25\x98\x1f\x03\x7c\x53\x15\x48\x7f\x8f\x33\x2f\xc6\x8c\x48\x4e\xda\xab\x51\x1b\x27\x47\x76\xf6\x0c\xde\x63\x6a\x53\xdb\x91\xc0\xf9\xdb\x9e\x3d\xe9\xa9\xee\x12\xce\x6b\xfc\xe7\xe3\xfe\x0c\x00\xc7\x16\xc6\x49\x1a\x03\x00\x00")
+
+func _000036_github_integrations_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000036_github_integrations_up_sql,
+		"000036_github_integrations.up.sql",
+	)
+}
+
+var __000037_file_thumbnails_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x69\x00\x96\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x66\x69\x6c\x65\x73\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x74\x68\x75\x6d\x62\x6e\x61\x69\x6c\x5f\x70\x61\x74\x68\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x66\x69\x6c\x65\x73\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x70\x61\x74\x68\x3b\x0a\x03\x00\x0b\x2c\x8f\xe2\x69\x00\x00\x00")
+
+func _000037_file_thumbnails_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000037_file_thumbnails_down_sql,
+		"000037_file_thumbnails.down.sql",
+	)
+}
+
+var __000037_file_thumbnails_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\xa8\xae\xd6\x2b\x28\x4a\x4d\xcb\xac\xa8\xad\x4d\xcb\xcc\x49\x2d\xe6\x72\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x48\x2c\xc9\x50\x08\x73\x0c\x72\xf6\x70\x0c\xd2\x30\x35\x34\xd2\xb4\xe6\xe2\x22\x56\x6f\x49\x46\x69\x6e\x52\x5e\x62\x66\x4e\x3c\x16\x53\x00\x03\x00\xde\x9d\x9e\x5e\x81\x00\x00\x00")
+
+func _000037_file_thumbnails_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000037_file_thumbnails_up_sql,
+		"000037_file_thumbnails.up.sql",
+	)
+}
+
+var __000038_file_names_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x64\x00\x9b\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x66\x69\x6c\x65\x73\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x6d\x69\x6d\x65\x5f\x74\x79\x70\x65\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x66\x69\x6c\x65\x73\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x6e\x61\x6d\x65\x3b\x0a\x03\x00\x4a\x61\x1e\xbe\x64\x00\x00\x00")
+
+func _000038_file_names_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000038_file_names_down_sql,
+		"000038_file_names.down.sql",
+	)
+}
+
+var __000038_file_names_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x7c\x00\x83\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x66\x69\x6c\x65\x73\x0a\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x6e\x61\x6d\x65\x20\x56\x41\x52\x43\x48\x41\x52\x28\x35\x31\x32\x29\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x66\x69\x6c\x65\x73\x0a\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x6d\x69\x6d\x65\x5f\x74\x79\x70\x65\x20\x56\x41\x52\x43\x48\x41\x52\x28\x32\x35\x35\x29\x3b\x0a\x03\x00\xf8\x92\x84\xb5\x7c\x00\x00\x00")
+
+func _000038_file_names_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000038_file_names_up_sql,
+		"000038_file_names.up.sql",
+	)
+}
+
+var __000039_block_versions_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x6f\x00\x90\xff\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x62\x6c\x6f\x63\x6b\x73\x5f\x68\x69\x73\x74\x6f\x72\x79\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x76\x65\x72\x73\x69\x6f\x6e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x62\x6c\x6f\x63\x6b\x73\x0a\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x76\x65\x72\x73\x69\x6f\x6e\x3b\x0a\x03\x00\xba\x88\xb8\x20\x6f\x00\x00\x00")
+
+func _000039_block_versions_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000039_block_versions_down_sql,
+		"000039_block_versions.down.sql",
+	)
+}
+
+var __000039_block_versions_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\xa8\xae\xd6\x2b\x28\x4a\x4d\xcb\xac\xa8\xad\x4d\xca\xc9\x4f\xce\x2e\xe6\x72\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x4b\x2d\x2a\xce\xcc\xcf\x53\x70\xf2\x74\xf7\xf4\x0b\x51\xf0\xf3\x0f\x51\xf0\x0b\xf5\xf1\x51\x70\x71\x75\x73\x0c\xf5\x09\x51\x30\xb4\xe6\xe2\xc2\x6b\x58\x7c\x46\x66\x71\x49\x7e\x51\x25\x29\x86\x1a\x58\x73\x01\x06\x00\xea\xd6\x6a\x05\xa1\x00\x00\x00")
+
+func _000039_block_versions_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000039_block_versions_up_sql,
+		"000039_block_versions.up.sql",
+	)
+}
+
+var __000040_idempotency_keys_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x28\x00\xd7\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x69\x64\x65\x6d\x70\x6f\x74\x65\x6e\x63\x79\x5f\x6b\x65\x79\x73\x3b\x0a\x03\x00\x15\xaa\x62\xd4\x28\x00\x00\x00")
+
+func _000040_idempotency_keys_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000040_idempotency_keys_down_sql,
+		"000040_idempotency_keys.down.sql",
+	)
+}
+
+var __000040_idempotency_keys_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x90\x51\x6b\xf2\x30\x18\x85\xaf\xcd\xaf\x78\x2f\x5b\x10\xf1\xfb\xd0\x31\xf0\x2a\x6a\xdc\xc2\xb4\x8e\x18\x87\x5e\x85\xce\xbe\x62\x98\xb6\xb5\x49\xa1\x25\xe4\xbf\x8f\x6e\xa5\x43\x2f\xbc\x0b\xcf\x39\x24\x4f\xce\x4c\x30\x2a\x19\x48\x3a\x5d\x32\xe0\x0b\x88\xd6\x12\xd8\x8e\x6f\xe4\x06\x9c\x1b\xe4\x05\x1e\x75\xe5\xbd\x4e\xf0\x92\x67\x16\xd3\x43\xad\xbe\xb0\x36\x10\x90\xde\x1d\x83\x0f\x2a\x66\xaf\x54\x04\xff\xc7\xe3\xb0\x4f\x7a\xa5\xc1\x42\xe9\xa4\xc3\xff\x86\xc3\x06\x17\x78\x2d\xd1\x58\x75\x8a\xcd\xa9\xcb\x9e\x46\x4d\x64\x6c\x6c\x4b\xa3\x0e\x59\x82\xc0\x23\xf9\x53\x36\x79\x96\x1a\x04\xe7\xf4\x11\x06\x97\xda\x5c\xcf\xde\xaf\xd8\x9c\x6f\x57\x92\xed\xa4\x73\x78\x36\xe8\x7d\x7b\x4e\x13\xef\xfb\xa4\x77\x28\x30\xb6\xa8\x62\x0b\x53\xfe\xf2\x7b\x11\x56\xb9\x2e\x6e\xd1\xbb\xe0\x2b\x2a\xf6\xf0\xc6\xf6\x10\xb4\xb2\x7d\xb8\xfb\x54\x48\xc2\xdb\xb7\xe7\x6c\x41\xb7\x4b\x09\x8d\x36\x9d\x49\x26\x60\xc3\x24\x94\xf6\xf8\x7c\xf9\x1c\xb5\x0a\x13\x42\xda\x55\x79\x34\x67\x3b\xd0\x49\xa5\x1e\x6c\xa9\xfe\xe4\xd6\xd1\xa3\xd1\x83\xae\x18\x4e\xc8\xf7\x00\x65\xdd\x16\xe5\xb8\x01\x00\x00")
+
+func _000040_idempotency_keys_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000040_idempotency_keys_up_sql,
+		"000040_idempotency_keys.up.sql",
+	)
+}
+
+var __000041_preferences_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x23\x00\xdc\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x70\x72\x65\x66\x65\x72\x65\x6e\x63\x65\x73\x3b\x0a\x03\x00\xdd\x84\xbc\xc5\x23\x00\x00\x00")
+
+func _000041_preferences_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000041_preferences_down_sql,
+		"000041_preferences.down.sql",
+	)
+}
+
+var __000041_preferences_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x5c\xcd\xcd\x4a\xc3\x40\x14\xc5\xf1\x75\xe6\x29\xce\x32\x81\x50\xea\xc7\x42\x70\x35\x8d\xb7\x3a\x58\xab\x4c\xae\xd2\xae\xca\xd8\xdc\x48\xa0\x89\x35\x1f\x62\x19\xe6\xdd\x65\x40\x5d\xb8\x3a\xf0\x5b\x9c\x7f\x61\x49\x33\x81\xf5\x62\x45\x30\x4b\xac\x1f\x19\xb4\x31\x25\x97\xf0\x7e\x76\xec\xa5\x6e\xbe\x42\x88\x2b\xbd\x74\x7b\x19\x90\xaa\x64\x1a\xa4\xdf\x35\x15\x5e\xb4\x2d\xee\xb4\x4d\xcf\xe6\xf3\x2c\x57\xc9\xde\x8d\xf2\xf6\xde\x9f\xfe\xfc\xe2\x3c\x72\xe7\x5a\xf9\x47\x9f\xee\x30\x09\x98\x36\x9c\xab\x64\x3a\x56\x6e\x94\x9d\x1b\xb1\x30\xb7\x66\x1d\xe9\xc9\x9a\x07\x6d\xb7\xb8\xa7\x2d\xd2\x9f\x5a\x8e\xdf\xff\x1c\xf1\x32\x53\x19\xbc\x6f\x6a\xcc\xda\xd3\xf0\x71\x08\xe1\x86\x96\xfa\x79\xc5\x88\x1d\x5d\x30\x59\x94\xc4\x98\xc6\xfa\xaa\x7d\xbd\xf4\x5e\xba\x2a\x84\x6b\xf5\x3d\x00\xd1\x46\xa8\x00\xf1\x00\x00\x00")
+
+func _000041_preferences_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000041_preferences_up_sql,
+		"000041_preferences.up.sql",
+	)
+}
+
+var __000042_workspace_retention_notices_down_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x33\x00\xcc\xff\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x7b\x7b\x2e\x70\x72\x65\x66\x69\x78\x7d\x7d\x77\x6f\x72\x6b\x73\x70\x61\x63\x65\x5f\x72\x65\x74\x65\x6e\x74\x69\x6f\x6e\x5f\x6e\x6f\x74\x69\x63\x65\x73\x3b\x0a\x03\x00\x31\x86\x46\x08\x33\x00\x00\x00")
+
+func _000042_workspace_retention_notices_down_sql() ([]byte, error) {
+	return bindata_read(
+		__000042_workspace_retention_notices_down_sql,
+		"000042_workspace_retention_notices.down.sql",
+	)
+}
+
+var __000042_workspace_retention_notices_up_sql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x54\xcd\x4d\x4b\xc3\x40\x10\xc6\xf1\x73\xf6\x53\xcc\x31\x81\xd2\x8b\x22\x82\xa7\x6d\x9c\xea\x62\x8c\xb2\x99\x8a\x3d\x85\x98\x6c\x60\xb0\xdd\xd4\xec\xf8\x52\x96\xfd\xee\x52\x2f\xea\xf5\x79\xe0\xf7\x2f\x2d\x6a\x42\x20\xbd\xaa\x10\xcc\x1a\xea\x07\x02\x7c\x36\x0d\x35\x10\xe3\xf2\x30\xbb\x91\xbf\x52\xfa\x9c\xe6\xd7\x70\xe8\x7a\xd7\xce\x4e\x9c\x17\x9e\x7c\xeb\x27\xe1\xde\x05\xc8\x55\xf6\x7b\xf3\x00\x4f\xda\x96\xb7\xda\xe6\x67\x17\xc5\x8f\x56\x6f\xaa\x6a\xa1\xb2\x5d\x17\xa4\xed\x7a\xe1\x0f\x96\x63\xdb\x09\xac\xcc\x8d\xa9\x69\xa1\xb2\x13\x34\xb2\x1b\xfe\x8d\x8f\xd6\xdc\x6b\xbb\x85\x3b\xdc\x42\xfe\xd7\x2f\x54\x01\x31\xf2\x08\xcb\xfd\x31\xbc\xed\x52\xba\xc6\xb5\xde\x54\x04\xa7\xa8\x2e\x09\x2d\x34\x48\xf0\x2e\xe3\xe5\xfe\xe5\x3c\x46\xe7\x87\x94\xae\xd4\xf7\x00\xab\x02\x79\x16\xe8\x00\x00\x00")
+
+func _000042_workspace_retention_notices_up_sql() ([]byte, error) {
+	return bindata_read(
+		__000042_workspace_retention_notices_up_sql,
+		"000042_workspace_retention_notices.up.sql",
+	)
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -267,6 +825,68 @@ var _bindata = map[string]func() ([]byte, error){
 	"000010_blocks_created_by.up.sql": _000010_blocks_created_by_up_sql,
 	"000011_match_collation.down.sql": _000011_match_collation_down_sql,
 	"000011_match_collation.up.sql": _000011_match_collation_up_sql,
+	"000012_workspace_members.down.sql": _000012_workspace_members_down_sql,
+	"000012_workspace_members.up.sql": _000012_workspace_members_up_sql,
+	"000013_workspaces_update_at_index.down.sql": _000013_workspaces_update_at_index_down_sql,
+	"000013_workspaces_update_at_index.up.sql": _000013_workspaces_update_at_index_up_sql,
+	"000014_sharing_tokens.down.sql": _000014_sharing_tokens_down_sql,
+	"000014_sharing_tokens.up.sql": _000014_sharing_tokens_up_sql,
+	"000015_webhook_deliveries.down.sql": _000015_webhook_deliveries_down_sql,
+	"000015_webhook_deliveries.up.sql": _000015_webhook_deliveries_up_sql,
+	"000016_notifications.down.sql": _000016_notifications_down_sql,
+	"000016_notifications.up.sql": _000016_notifications_up_sql,
+	"000017_due_date_reminders.down.sql": _000017_due_date_reminders_down_sql,
+	"000017_due_date_reminders.up.sql": _000017_due_date_reminders_up_sql,
+	"000018_audit_entries.down.sql": _000018_audit_entries_down_sql,
+	"000018_audit_entries.up.sql": _000018_audit_entries_up_sql,
+	"000019_sessions_expire_at.down.sql": _000019_sessions_expire_at_down_sql,
+	"000019_sessions_expire_at.up.sql": _000019_sessions_expire_at_up_sql,
+	"000020_access_tokens.down.sql": _000020_access_tokens_down_sql,
+	"000020_access_tokens.up.sql": _000020_access_tokens_up_sql,
+	"000021_login_attempts.down.sql": _000021_login_attempts_down_sql,
+	"000021_login_attempts.up.sql": _000021_login_attempts_up_sql,
+	"000022_password_reset_tokens.down.sql": _000022_password_reset_tokens_down_sql,
+	"000022_password_reset_tokens.up.sql": _000022_password_reset_tokens_up_sql,
+	"000023_user_mfa.down.sql": _000023_user_mfa_down_sql,
+	"000023_user_mfa.up.sql": _000023_user_mfa_up_sql,
+	"000024_migrations_log.down.sql": _000024_migrations_log_down_sql,
+	"000024_migrations_log.up.sql": _000024_migrations_log_up_sql,
+	"000025_recurrence_runs.down.sql": _000025_recurrence_runs_down_sql,
+	"000025_recurrence_runs.up.sql": _000025_recurrence_runs_up_sql,
+	"000026_board_sequences.down.sql": _000026_board_sequences_down_sql,
+	"000026_board_sequences.up.sql": _000026_board_sequences_up_sql,
+	"000027_calendar_feeds.down.sql": _000027_calendar_feeds_down_sql,
+	"000027_calendar_feeds.up.sql": _000027_calendar_feeds_up_sql,
+	"000028_files.down.sql": _000028_files_down_sql,
+	"000028_files.up.sql": _000028_files_up_sql,
+	"000029_user_is_admin.down.sql": _000029_user_is_admin_down_sql,
+	"000029_user_is_admin.up.sql": _000029_user_is_admin_up_sql,
+	"000030_board_members.down.sql": _000030_board_members_down_sql,
+	"000030_board_members.up.sql": _000030_board_members_up_sql,
+	"000031_guests.down.sql": _000031_guests_down_sql,
+	"000031_guests.up.sql": _000031_guests_up_sql,
+	"000032_workspace_stats.down.sql": _000032_workspace_stats_down_sql,
+	"000032_workspace_stats.up.sql": _000032_workspace_stats_up_sql,
+	"000033_subscriptions.down.sql": _000033_subscriptions_down_sql,
+	"000033_subscriptions.up.sql": _000033_subscriptions_up_sql,
+	"000034_digests.down.sql": _000034_digests_down_sql,
+	"000034_digests.up.sql": _000034_digests_up_sql,
+	"000035_inbound_hooks.down.sql": _000035_inbound_hooks_down_sql,
+	"000035_inbound_hooks.up.sql": _000035_inbound_hooks_up_sql,
+	"000036_github_integrations.down.sql": _000036_github_integrations_down_sql,
+	"000036_github_integrations.up.sql": _000036_github_integrations_up_sql,
+	"000037_file_thumbnails.down.sql": _000037_file_thumbnails_down_sql,
+	"000037_file_thumbnails.up.sql": _000037_file_thumbnails_up_sql,
+	"000038_file_names.down.sql": _000038_file_names_down_sql,
+	"000038_file_names.up.sql": _000038_file_names_up_sql,
+	"000039_block_versions.down.sql": _000039_block_versions_down_sql,
+	"000039_block_versions.up.sql": _000039_block_versions_up_sql,
+	"000040_idempotency_keys.down.sql": _000040_idempotency_keys_down_sql,
+	"000040_idempotency_keys.up.sql": _000040_idempotency_keys_up_sql,
+	"000041_preferences.down.sql": _000041_preferences_down_sql,
+	"000041_preferences.up.sql": _000041_preferences_up_sql,
+	"000042_workspace_retention_notices.down.sql": _000042_workspace_retention_notices_down_sql,
+	"000042_workspace_retention_notices.up.sql": _000042_workspace_retention_notices_up_sql,
 }
 // AssetDir returns the file names below a certain
 // directory embedded in the file by go-bindata.
@@ -352,4 +972,128 @@ var _bintree = &_bintree_t{nil, map[string]*_bintree_t{
 	}},
 	"000011_match_collation.up.sql": &_bintree_t{_000011_match_collation_up_sql, map[string]*_bintree_t{
 	}},
+	"000012_workspace_members.down.sql": &_bintree_t{_000012_workspace_members_down_sql, map[string]*_bintree_t{
+	}},
+	"000012_workspace_members.up.sql": &_bintree_t{_000012_workspace_members_up_sql, map[string]*_bintree_t{
+	}},
+	"000013_workspaces_update_at_index.down.sql": &_bintree_t{_000013_workspaces_update_at_index_down_sql, map[string]*_bintree_t{
+	}},
+	"000013_workspaces_update_at_index.up.sql": &_bintree_t{_000013_workspaces_update_at_index_up_sql, map[string]*_bintree_t{
+	}},
+	"000014_sharing_tokens.down.sql": &_bintree_t{_000014_sharing_tokens_down_sql, map[string]*_bintree_t{
+	}},
+	"000014_sharing_tokens.up.sql": &_bintree_t{_000014_sharing_tokens_up_sql, map[string]*_bintree_t{
+	}},
+	"000015_webhook_deliveries.down.sql": &_bintree_t{_000015_webhook_deliveries_down_sql, map[string]*_bintree_t{
+	}},
+	"000015_webhook_deliveries.up.sql": &_bintree_t{_000015_webhook_deliveries_up_sql, map[string]*_bintree_t{
+	}},
+	"000016_notifications.down.sql": &_bintree_t{_000016_notifications_down_sql, map[string]*_bintree_t{
+	}},
+	"000016_notifications.up.sql": &_bintree_t{_000016_notifications_up_sql, map[string]*_bintree_t{
+	}},
+	"000017_due_date_reminders.down.sql": &_bintree_t{_000017_due_date_reminders_down_sql, map[string]*_bintree_t{
+	}},
+	"000017_due_date_reminders.up.sql": &_bintree_t{_000017_due_date_reminders_up_sql, map[string]*_bintree_t{
+	}},
+	"000018_audit_entries.down.sql": &_bintree_t{_000018_audit_entries_down_sql, map[string]*_bintree_t{
+	}},
+	"000018_audit_entries.up.sql": &_bintree_t{_000018_audit_entries_up_sql, map[string]*_bintree_t{
+	}},
+	"000019_sessions_expire_at.down.sql": &_bintree_t{_000019_sessions_expire_at_down_sql, map[string]*_bintree_t{
+	}},
+	"000019_sessions_expire_at.up.sql": &_bintree_t{_000019_sessions_expire_at_up_sql, map[string]*_bintree_t{
+	}},
+	"000020_access_tokens.down.sql": &_bintree_t{_000020_access_tokens_down_sql, map[string]*_bintree_t{
+	}},
+	"000020_access_tokens.up.sql": &_bintree_t{_000020_access_tokens_up_sql, map[string]*_bintree_t{
+	}},
+	"000021_login_attempts.down.sql": &_bintree_t{_000021_login_attempts_down_sql, map[string]*_bintree_t{
+	}},
+	"000021_login_attempts.up.sql": &_bintree_t{_000021_login_attempts_up_sql, map[string]*_bintree_t{
+	}},
+	"000022_password_reset_tokens.down.sql": &_bintree_t{_000022_password_reset_tokens_down_sql, map[string]*_bintree_t{
+	}},
+	"000022_password_reset_tokens.up.sql": &_bintree_t{_000022_password_reset_tokens_up_sql, map[string]*_bintree_t{
+	}},
+	"000023_user_mfa.down.sql": &_bintree_t{_000023_user_mfa_down_sql, map[string]*_bintree_t{
+	}},
+	"000023_user_mfa.up.sql": &_bintree_t{_000023_user_mfa_up_sql, map[string]*_bintree_t{
+	}},
+	"000024_migrations_log.down.sql": &_bintree_t{_000024_migrations_log_down_sql, map[string]*_bintree_t{
+	}},
+	"000024_migrations_log.up.sql": &_bintree_t{_000024_migrations_log_up_sql, map[string]*_bintree_t{
+	}},
+	"000025_recurrence_runs.down.sql": &_bintree_t{_000025_recurrence_runs_down_sql, map[string]*_bintree_t{
+	}},
+	"000025_recurrence_runs.up.sql": &_bintree_t{_000025_recurrence_runs_up_sql, map[string]*_bintree_t{
+	}},
+	"000026_board_sequences.down.sql": &_bintree_t{_000026_board_sequences_down_sql, map[string]*_bintree_t{
+	}},
+	"000026_board_sequences.up.sql": &_bintree_t{_000026_board_sequences_up_sql, map[string]*_bintree_t{
+	}},
+	"000027_calendar_feeds.down.sql": &_bintree_t{_000027_calendar_feeds_down_sql, map[string]*_bintree_t{
+	}},
+	"000027_calendar_feeds.up.sql": &_bintree_t{_000027_calendar_feeds_up_sql, map[string]*_bintree_t{
+	}},
+	"000028_files.down.sql": &_bintree_t{_000028_files_down_sql, map[string]*_bintree_t{
+	}},
+	"000028_files.up.sql": &_bintree_t{_000028_files_up_sql, map[string]*_bintree_t{
+	}},
+	"000029_user_is_admin.down.sql": &_bintree_t{_000029_user_is_admin_down_sql, map[string]*_bintree_t{
+	}},
+	"000029_user_is_admin.up.sql": &_bintree_t{_000029_user_is_admin_up_sql, map[string]*_bintree_t{
+	}},
+	"000030_board_members.down.sql": &_bintree_t{_000030_board_members_down_sql, map[string]*_bintree_t{
+	}},
+	"000030_board_members.up.sql": &_bintree_t{_000030_board_members_up_sql, map[string]*_bintree_t{
+	}},
+	"000031_guests.down.sql": &_bintree_t{_000031_guests_down_sql, map[string]*_bintree_t{
+	}},
+	"000031_guests.up.sql": &_bintree_t{_000031_guests_up_sql, map[string]*_bintree_t{
+	}},
+	"000032_workspace_stats.down.sql": &_bintree_t{_000032_workspace_stats_down_sql, map[string]*_bintree_t{
+	}},
+	"000032_workspace_stats.up.sql": &_bintree_t{_000032_workspace_stats_up_sql, map[string]*_bintree_t{
+	}},
+	"000033_subscriptions.down.sql": &_bintree_t{_000033_subscriptions_down_sql, map[string]*_bintree_t{
+	}},
+	"000033_subscriptions.up.sql": &_bintree_t{_000033_subscriptions_up_sql, map[string]*_bintree_t{
+	}},
+	"000034_digests.down.sql": &_bintree_t{_000034_digests_down_sql, map[string]*_bintree_t{
+	}},
+	"000034_digests.up.sql": &_bintree_t{_000034_digests_up_sql, map[string]*_bintree_t{
+	}},
+	"000035_inbound_hooks.down.sql": &_bintree_t{_000035_inbound_hooks_down_sql, map[string]*_bintree_t{
+	}},
+	"000035_inbound_hooks.up.sql": &_bintree_t{_000035_inbound_hooks_up_sql, map[string]*_bintree_t{
+	}},
+	"000036_github_integrations.down.sql": &_bintree_t{_000036_github_integrations_down_sql, map[string]*_bintree_t{
+	}},
+	"000036_github_integrations.up.sql": &_bintree_t{_000036_github_integrations_up_sql, map[string]*_bintree_t{
+	}},
+	"000037_file_thumbnails.down.sql": &_bintree_t{_000037_file_thumbnails_down_sql, map[string]*_bintree_t{
+	}},
+	"000037_file_thumbnails.up.sql": &_bintree_t{_000037_file_thumbnails_up_sql, map[string]*_bintree_t{
+	}},
+	"000038_file_names.down.sql": &_bintree_t{_000038_file_names_down_sql, map[string]*_bintree_t{
+	}},
+	"000038_file_names.up.sql": &_bintree_t{_000038_file_names_up_sql, map[string]*_bintree_t{
+	}},
+	"000039_block_versions.down.sql": &_bintree_t{_000039_block_versions_down_sql, map[string]*_bintree_t{
+	}},
+	"000039_block_versions.up.sql": &_bintree_t{_000039_block_versions_up_sql, map[string]*_bintree_t{
+	}},
+	"000040_idempotency_keys.down.sql": &_bintree_t{_000040_idempotency_keys_down_sql, map[string]*_bintree_t{
+	}},
+	"000040_idempotency_keys.up.sql": &_bintree_t{_000040_idempotency_keys_up_sql, map[string]*_bintree_t{
+	}},
+	"000041_preferences.down.sql": &_bintree_t{_000041_preferences_down_sql, map[string]*_bintree_t{
+	}},
+	"000041_preferences.up.sql": &_bintree_t{_000041_preferences_up_sql, map[string]*_bintree_t{
+	}},
+	"000042_workspace_retention_notices.down.sql": &_bintree_t{_000042_workspace_retention_notices_down_sql, map[string]*_bintree_t{
+	}},
+	"000042_workspace_retention_notices.up.sql": &_bintree_t{_000042_workspace_retention_notices_up_sql, map[string]*_bintree_t{
+	}},
 }}
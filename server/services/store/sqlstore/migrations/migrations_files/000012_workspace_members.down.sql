@@ -0,0 +1 @@
+DROP TABLE {{.prefix}}workspace_members;
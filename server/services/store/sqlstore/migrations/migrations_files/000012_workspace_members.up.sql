@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}workspace_members (
+	workspace_id VARCHAR(36),
+	user_id VARCHAR(100),
+	create_at BIGINT,
+	PRIMARY KEY (workspace_id, user_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
@@ -0,0 +1,5 @@
+{{if .mysql}}
+DROP INDEX idx_{{.prefix}}workspaces_update_at ON {{.prefix}}workspaces;
+{{else}}
+DROP INDEX idx_{{.prefix}}workspaces_update_at;
+{{end}}
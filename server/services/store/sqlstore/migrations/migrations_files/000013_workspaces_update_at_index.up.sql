@@ -0,0 +1 @@
+CREATE INDEX idx_{{.prefix}}workspaces_update_at ON {{.prefix}}workspaces(update_at, id);
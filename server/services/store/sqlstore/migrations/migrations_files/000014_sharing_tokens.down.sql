@@ -0,0 +1 @@
+DROP TABLE {{.prefix}}sharing_tokens;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}sharing_tokens (
+	token VARCHAR(100),
+	id VARCHAR(36),
+	workspace_id VARCHAR(36),
+	created_by VARCHAR(36),
+	create_at BIGINT,
+	expire_at BIGINT,
+	PRIMARY KEY (token)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE INDEX idx_{{.prefix}}sharing_tokens_id ON {{.prefix}}sharing_tokens(id);
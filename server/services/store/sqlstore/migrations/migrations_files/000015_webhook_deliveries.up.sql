@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}webhook_deliveries (
+	id VARCHAR(36),
+	workspace_id VARCHAR(36),
+	url TEXT,
+	payload TEXT,
+	status_code INT,
+	error TEXT,
+	attempts INT,
+	create_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE INDEX idx_{{.prefix}}webhook_deliveries_workspace_id ON {{.prefix}}webhook_deliveries(workspace_id, create_at);
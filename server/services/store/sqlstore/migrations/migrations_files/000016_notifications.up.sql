@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}notifications (
+	id VARCHAR(36),
+	workspace_id VARCHAR(36),
+	user_id VARCHAR(36),
+	author_id VARCHAR(36),
+	board_id VARCHAR(36),
+	card_id VARCHAR(36),
+	block_id VARCHAR(36),
+	create_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE INDEX idx_{{.prefix}}notifications_user_id ON {{.prefix}}notifications(user_id, create_at);
+CREATE INDEX idx_{{.prefix}}notifications_block_id ON {{.prefix}}notifications(block_id);
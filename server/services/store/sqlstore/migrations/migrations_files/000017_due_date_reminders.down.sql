@@ -0,0 +1,5 @@
+DROP TABLE {{.prefix}}cluster_locks;
+DROP TABLE {{.prefix}}reminders_sent;
+
+ALTER TABLE {{.prefix}}notifications
+DROP COLUMN type;
@@ -0,0 +1,17 @@
+ALTER TABLE {{.prefix}}notifications
+ADD COLUMN type VARCHAR(20) DEFAULT 'mention';
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}reminders_sent (
+	card_id VARCHAR(36),
+	user_id VARCHAR(36),
+	due_at BIGINT,
+	create_at BIGINT,
+	PRIMARY KEY (card_id, user_id, due_at)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}cluster_locks (
+	name VARCHAR(100),
+	owner VARCHAR(36),
+	expire_at BIGINT,
+	PRIMARY KEY (name)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}audit_entries (
+	id VARCHAR(36),
+	actor_id VARCHAR(36),
+	workspace_id VARCHAR(36),
+	action VARCHAR(50),
+	entity_id VARCHAR(36),
+	payload TEXT,
+	create_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE INDEX idx_{{.prefix}}audit_entries_create_at ON {{.prefix}}audit_entries(create_at);
+CREATE INDEX idx_{{.prefix}}audit_entries_actor_id ON {{.prefix}}audit_entries(actor_id, create_at);
+CREATE INDEX idx_{{.prefix}}audit_entries_workspace_id ON {{.prefix}}audit_entries(workspace_id, create_at);
@@ -0,0 +1,2 @@
+ALTER TABLE {{.prefix}}sessions
+DROP COLUMN expire_at;
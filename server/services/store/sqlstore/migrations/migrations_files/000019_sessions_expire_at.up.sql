@@ -0,0 +1,2 @@
+ALTER TABLE {{.prefix}}sessions
+ADD COLUMN expire_at BIGINT DEFAULT 0;
@@ -0,0 +1 @@
+DROP TABLE {{.prefix}}access_tokens;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}access_tokens (
+	id VARCHAR(36),
+	token_hash VARCHAR(64),
+	user_id VARCHAR(36),
+	description VARCHAR(255),
+	create_at BIGINT,
+	last_used_at BIGINT,
+	expire_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE UNIQUE INDEX idx_{{.prefix}}access_tokens_token_hash ON {{.prefix}}access_tokens(token_hash);
+CREATE INDEX idx_{{.prefix}}access_tokens_user_id ON {{.prefix}}access_tokens(user_id);
@@ -0,0 +1 @@
+DROP TABLE {{.prefix}}login_attempts;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}login_attempts (
+	id VARCHAR(255),
+	failures INTEGER,
+	last_failure_at BIGINT,
+	locked_until BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
@@ -0,0 +1 @@
+DROP TABLE {{.prefix}}password_reset_tokens;
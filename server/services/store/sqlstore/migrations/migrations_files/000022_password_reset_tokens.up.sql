@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}password_reset_tokens (
+	token_hash VARCHAR(64),
+	user_id VARCHAR(36),
+	create_at BIGINT,
+	expire_at BIGINT,
+	PRIMARY KEY (token_hash)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE INDEX idx_{{.prefix}}password_reset_tokens_user_id ON {{.prefix}}password_reset_tokens(user_id);
@@ -0,0 +1,4 @@
+DROP TABLE {{.prefix}}mfa_recovery_codes;
+
+ALTER TABLE {{.prefix}}users
+DROP COLUMN mfa_active;
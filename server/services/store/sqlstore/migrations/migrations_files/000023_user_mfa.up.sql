@@ -0,0 +1,8 @@
+ALTER TABLE {{.prefix}}users
+ADD COLUMN mfa_active BOOLEAN DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}mfa_recovery_codes (
+	user_id VARCHAR(36),
+	code_hash VARCHAR(64),
+	PRIMARY KEY (user_id, code_hash)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
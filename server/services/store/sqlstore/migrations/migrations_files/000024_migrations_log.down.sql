@@ -0,0 +1 @@
+DROP TABLE {{.prefix}}migrations_log;
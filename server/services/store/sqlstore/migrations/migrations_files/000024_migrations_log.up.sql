@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}migrations_log (
+	version BIGINT,
+	name VARCHAR(100),
+	applied_at BIGINT,
+	duration_ms BIGINT,
+	PRIMARY KEY (version)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
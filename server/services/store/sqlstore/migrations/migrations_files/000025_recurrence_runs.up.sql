@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}recurrence_runs (
+	recurrence_id VARCHAR(36),
+	run_at BIGINT,
+	card_id VARCHAR(36),
+	create_at BIGINT,
+	PRIMARY KEY (recurrence_id, run_at)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
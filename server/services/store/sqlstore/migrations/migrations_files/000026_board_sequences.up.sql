@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}board_sequences (
+	board_id VARCHAR(36),
+	last_number BIGINT,
+	PRIMARY KEY (board_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
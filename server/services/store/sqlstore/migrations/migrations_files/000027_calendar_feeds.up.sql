@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}calendar_feeds (
+	id VARCHAR(36),
+	workspace_id VARCHAR(36),
+	token VARCHAR(100),
+	created_by VARCHAR(36),
+	create_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}files (
+	id VARCHAR(100),
+	workspace_id VARCHAR(36),
+	root_id VARCHAR(36),
+	size BIGINT,
+	create_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE INDEX idx_{{.prefix}}files_workspace_id ON {{.prefix}}files(workspace_id);
@@ -0,0 +1,2 @@
+ALTER TABLE {{.prefix}}users
+DROP COLUMN is_admin;
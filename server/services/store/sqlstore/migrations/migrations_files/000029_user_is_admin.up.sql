@@ -0,0 +1,8 @@
+ALTER TABLE {{.prefix}}users
+ADD COLUMN is_admin BOOLEAN DEFAULT FALSE;
+
+-- the first registered user is the admin, the derived table lets MySQL
+-- select from the updated table
+UPDATE {{.prefix}}users SET is_admin = TRUE WHERE id IN (
+	SELECT id FROM (SELECT id FROM {{.prefix}}users ORDER BY create_at, id LIMIT 1) AS first_user
+);
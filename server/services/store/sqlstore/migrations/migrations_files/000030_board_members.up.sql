@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}board_members (
+	workspace_id VARCHAR(36),
+	board_id VARCHAR(36),
+	user_id VARCHAR(100),
+	role VARCHAR(16),
+	create_at BIGINT,
+	PRIMARY KEY (workspace_id, board_id, user_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
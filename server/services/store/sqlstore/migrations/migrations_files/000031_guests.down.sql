@@ -0,0 +1,4 @@
+DROP TABLE {{.prefix}}guest_invites;
+
+ALTER TABLE {{.prefix}}users
+DROP COLUMN is_guest;
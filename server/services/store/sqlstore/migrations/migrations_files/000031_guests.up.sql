@@ -0,0 +1,12 @@
+ALTER TABLE {{.prefix}}users
+ADD COLUMN is_guest BOOLEAN DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}guest_invites (
+	token_hash VARCHAR(64) PRIMARY KEY,
+	workspace_id VARCHAR(36),
+	board_id VARCHAR(36),
+	role VARCHAR(16),
+	created_by VARCHAR(100),
+	create_at BIGINT,
+	expire_at BIGINT
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
@@ -0,0 +1,8 @@
+{{if .mysql}}
+DROP INDEX idx_{{.prefix}}blocks_update_at_workspace_id ON {{.prefix}}blocks;
+{{else}}
+DROP INDEX idx_{{.prefix}}blocks_update_at_workspace_id;
+{{end}}
+
+ALTER TABLE {{.prefix}}workspaces
+DROP COLUMN create_at;
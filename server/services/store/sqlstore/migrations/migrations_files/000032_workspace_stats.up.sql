@@ -0,0 +1,7 @@
+ALTER TABLE {{.prefix}}workspaces
+ADD COLUMN create_at BIGINT;
+
+-- the creation time of the existing workspaces isn't known
+UPDATE {{.prefix}}workspaces SET create_at = update_at;
+
+CREATE INDEX idx_{{.prefix}}blocks_update_at_workspace_id ON {{.prefix}}blocks(update_at, workspace_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}subscriptions (
+	workspace_id VARCHAR(36),
+	block_id VARCHAR(36),
+	block_type VARCHAR(10),
+	subscriber_id VARCHAR(100),
+	create_at BIGINT,
+	notified_at BIGINT,
+	PRIMARY KEY (block_id, subscriber_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE INDEX idx_{{.prefix}}subscriptions_subscriber_id ON {{.prefix}}subscriptions(subscriber_id, workspace_id);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}digests (
+	user_id VARCHAR(100),
+	last_digest_at BIGINT,
+	PRIMARY KEY (user_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
@@ -0,0 +1,2 @@
+DROP TABLE {{.prefix}}inbound_hook_deliveries;
+DROP TABLE {{.prefix}}inbound_hooks;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}inbound_hooks (
+	id VARCHAR(36),
+	workspace_id VARCHAR(36),
+	token_hash VARCHAR(64),
+	allow_new_options BOOLEAN,
+	created_by VARCHAR(36),
+	create_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE UNIQUE INDEX idx_{{.prefix}}inbound_hooks_token_hash ON {{.prefix}}inbound_hooks(token_hash);
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}inbound_hook_deliveries (
+	id VARCHAR(36),
+	board_id VARCHAR(36),
+	workspace_id VARCHAR(36),
+	card_id VARCHAR(36),
+	payload TEXT,
+	status_code INT,
+	error TEXT,
+	create_at BIGINT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE INDEX idx_{{.prefix}}inbound_hook_deliveries_board_id ON {{.prefix}}inbound_hook_deliveries(board_id, create_at);
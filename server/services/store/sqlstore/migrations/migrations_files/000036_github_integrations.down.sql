@@ -0,0 +1,2 @@
+DROP TABLE {{.prefix}}github_issue_links;
+DROP TABLE {{.prefix}}github_integrations;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}github_integrations (
+	id VARCHAR(36),
+	workspace_id VARCHAR(36),
+	repository VARCHAR(255),
+	token_encrypted TEXT,
+	webhook_secret_encrypted TEXT,
+	label_filter TEXT,
+	comment_on_close BOOLEAN,
+	state_property_id VARCHAR(36),
+	labels_property_id VARCHAR(36),
+	created_by VARCHAR(36),
+	create_at BIGINT,
+	last_sync_at BIGINT,
+	rate_limit_reset_at BIGINT,
+	last_error TEXT,
+	PRIMARY KEY (id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE TABLE IF NOT EXISTS {{.prefix}}github_issue_links (
+	board_id VARCHAR(36),
+	workspace_id VARCHAR(36),
+	issue_number BIGINT,
+	card_id VARCHAR(36),
+	state VARCHAR(16),
+	card_closed BOOLEAN,
+	issue_updated_at BIGINT,
+	PRIMARY KEY (board_id, issue_number)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
@@ -0,0 +1,5 @@
+ALTER TABLE {{.prefix}}files
+DROP COLUMN thumbnail_path;
+
+ALTER TABLE {{.prefix}}files
+DROP COLUMN path;
@@ -0,0 +1,5 @@
+ALTER TABLE {{.prefix}}files
+ADD COLUMN path VARCHAR(512);
+
+ALTER TABLE {{.prefix}}files
+ADD COLUMN thumbnail_path VARCHAR(512);
@@ -0,0 +1,5 @@
+ALTER TABLE {{.prefix}}files
+DROP COLUMN mime_type;
+
+ALTER TABLE {{.prefix}}files
+DROP COLUMN name;
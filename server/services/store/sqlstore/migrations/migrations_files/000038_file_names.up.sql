@@ -0,0 +1,5 @@
+ALTER TABLE {{.prefix}}files
+ADD COLUMN name VARCHAR(512);
+
+ALTER TABLE {{.prefix}}files
+ADD COLUMN mime_type VARCHAR(255);
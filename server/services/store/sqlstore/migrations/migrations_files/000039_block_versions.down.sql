@@ -0,0 +1,5 @@
+ALTER TABLE {{.prefix}}blocks_history
+DROP COLUMN version;
+
+ALTER TABLE {{.prefix}}blocks
+DROP COLUMN version;
@@ -0,0 +1,5 @@
+ALTER TABLE {{.prefix}}blocks
+ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE {{.prefix}}blocks_history
+ADD COLUMN version BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+DROP TABLE {{.prefix}}idempotency_keys;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}idempotency_keys (
+	idempotency_key VARCHAR(255),
+	user_id VARCHAR(100),
+	request_hash VARCHAR(64),
+	status_code INT,
+	response {{if .mysql}}MEDIUMTEXT{{else}}TEXT{{end}},
+	create_at BIGINT,
+	expire_at BIGINT,
+	PRIMARY KEY (user_id, idempotency_key)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
+
+CREATE INDEX idx_{{.prefix}}idempotency_keys_expire_at ON {{.prefix}}idempotency_keys(expire_at);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}preferences (
+	user_id VARCHAR(100),
+	category VARCHAR(32),
+	name VARCHAR(32),
+	value TEXT,
+	update_at BIGINT,
+	PRIMARY KEY (user_id, category, name)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS {{.prefix}}workspace_retention_notices (
+	workspace_id VARCHAR(36) NOT NULL,
+	last_activity_at BIGINT,
+	notified_at BIGINT,
+	PRIMARY KEY (workspace_id)
+) {{if .mysql}}DEFAULT CHARACTER SET utf8mb4{{end}};
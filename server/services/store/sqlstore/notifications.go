@@ -0,0 +1,120 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// InsertNotification stores a mention notification.
+func (s *SQLStore) InsertNotification(notification model.Notification) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"notifications").
+		Columns(
+			"id",
+			"type",
+			"workspace_id",
+			"user_id",
+			"author_id",
+			"board_id",
+			"card_id",
+			"block_id",
+			"create_at",
+		).
+		Values(
+			notification.ID,
+			notification.Type,
+			notification.WorkspaceID,
+			notification.UserID,
+			notification.AuthorID,
+			notification.BoardID,
+			notification.CardID,
+			notification.BlockID,
+			notification.CreateAt,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR InsertNotification", mlog.String("blockID", notification.BlockID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetNotifiedUserIDs returns the IDs of the users already notified of
+// a mention in the block.
+func (s *SQLStore) GetNotifiedUserIDs(blockID string) ([]string, error) {
+	query := s.getQueryBuilder().
+		Select("DISTINCT user_id").
+		From(s.tablePrefix + "notifications").
+		Where(sq.Eq{"block_id": blockID})
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetNotifiedUserIDs", mlog.String("blockID", blockID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	userIDs := []string{}
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// GetNotificationsForUser returns up to limit notifications of the
+// user, the most recent first.
+func (s *SQLStore) GetNotificationsForUser(userID string, limit int) ([]model.Notification, error) {
+	query := s.getQueryBuilder().
+		Select(
+			"id",
+			"type",
+			"workspace_id",
+			"user_id",
+			"author_id",
+			"board_id",
+			"card_id",
+			"block_id",
+			"create_at",
+		).
+		From(s.tablePrefix + "notifications").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("create_at DESC", "id").
+		Limit(uint64(limit))
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetNotificationsForUser", mlog.String("userID", userID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	notifications := []model.Notification{}
+	for rows.Next() {
+		var notification model.Notification
+		err := rows.Scan(
+			&notification.ID,
+			&notification.Type,
+			&notification.WorkspaceID,
+			&notification.UserID,
+			&notification.AuthorID,
+			&notification.BoardID,
+			&notification.CardID,
+			&notification.BlockID,
+			&notification.CreateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
@@ -0,0 +1,77 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// CreatePasswordResetToken stores a password reset token.
+func (s *SQLStore) CreatePasswordResetToken(token model.PasswordResetToken) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"password_reset_tokens").
+		Columns("token_hash", "user_id", "create_at", "expire_at").
+		Values(token.TokenHash, token.UserID, token.CreateAt, token.ExpireAt)
+
+	_, err := query.Exec()
+	return err
+}
+
+// ConsumePasswordResetToken deletes the password reset token with the hash
+// and returns it, or returns sql.ErrNoRows if it doesn't exist or was
+// already consumed.
+func (s *SQLStore) ConsumePasswordResetToken(tokenHash string) (*model.PasswordResetToken, error) {
+	query := s.getQueryBuilder().
+		Select("token_hash", "user_id", "create_at", "expire_at").
+		From(s.tablePrefix + "password_reset_tokens").
+		Where(sq.Eq{"token_hash": tokenHash})
+
+	var token model.PasswordResetToken
+	err := query.QueryRow().Scan(&token.TokenHash, &token.UserID, &token.CreateAt, &token.ExpireAt)
+	if err != nil {
+		return nil, err
+	}
+
+	deleteQuery := s.getQueryBuilder().
+		Delete(s.tablePrefix + "password_reset_tokens").
+		Where(sq.Eq{"token_hash": tokenHash})
+
+	result, err := deleteQuery.Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	// the token is only consumed by the request that deleted it
+	count, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return &token, nil
+}
+
+// DeletePasswordResetTokensForUser deletes all the password reset tokens
+// of the user.
+func (s *SQLStore) DeletePasswordResetTokensForUser(userID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "password_reset_tokens").
+		Where(sq.Eq{"user_id": userID})
+
+	_, err := query.Exec()
+	return err
+}
+
+// DeleteExpiredPasswordResetTokens deletes the password reset tokens that
+// have expired at now, in milliseconds.
+func (s *SQLStore) DeleteExpiredPasswordResetTokens(now int64) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "password_reset_tokens").
+		Where(sq.LtOrEq{"expire_at": now})
+
+	_, err := query.Exec()
+	return err
+}
@@ -0,0 +1,86 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GetPreferences returns the preferences of the user, ordered by their
+// category and name.
+func (s *SQLStore) GetPreferences(ctx context.Context, userID string) ([]model.Preference, error) {
+	query := s.getQueryBuilder().
+		Select("user_id", "category", "name", "COALESCE(value, '')").
+		From(s.tablePrefix + "preferences").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("category", "name")
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR GetPreferences", mlog.String("userID", userID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	preferences := []model.Preference{}
+	for rows.Next() {
+		var preference model.Preference
+		if err := rows.Scan(&preference.UserID, &preference.Category, &preference.Name, &preference.Value); err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, preference)
+	}
+	return preferences, rows.Err()
+}
+
+// UpsertPreferences sets the preferences of the user together, replacing
+// the values of the ones that are already set.
+func (s *SQLStore) UpsertPreferences(ctx context.Context, userID string, preferences []model.Preference) error {
+	now := utils.GetMillis()
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, preference := range preferences {
+			query := s.getQueryBuilder().
+				Insert(s.tablePrefix+"preferences").
+				Columns("user_id", "category", "name", "value", "update_at").
+				Values(userID, preference.Category, preference.Name, preference.Value, now)
+			if s.dbType == mysqlDBType {
+				query = query.Suffix("ON DUPLICATE KEY UPDATE value = ?, update_at = ?", preference.Value, now)
+			} else {
+				query = query.Suffix("ON CONFLICT (user_id, category, name) DO UPDATE SET value = EXCLUDED.value, update_at = EXCLUDED.update_at")
+			}
+
+			if _, err := sq.ExecContextWith(ctx, tx, query); err != nil {
+				s.logger.Error("ERROR UpsertPreferences", mlog.String("userID", userID), mlog.Err(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeletePreferences removes the preferences of the user with the
+// categories and names of the given ones. Removing a preference that
+// isn't set isn't an error.
+func (s *SQLStore) DeletePreferences(ctx context.Context, userID string, preferences []model.Preference) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, preference := range preferences {
+			query := s.getQueryBuilder().
+				Delete(s.tablePrefix + "preferences").
+				Where(sq.Eq{"user_id": userID}).
+				Where(sq.Eq{"category": preference.Category}).
+				Where(sq.Eq{"name": preference.Name})
+
+			if _, err := sq.ExecContextWith(ctx, tx, query); err != nil {
+				s.logger.Error("ERROR DeletePreferences", mlog.String("userID", userID), mlog.Err(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
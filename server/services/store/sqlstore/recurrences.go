@@ -0,0 +1,54 @@
+package sqlstore
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GetLastRecurrenceRun returns the time of the last occurrence of the
+// recurrence that created a card, or zero if none did.
+func (s *SQLStore) GetLastRecurrenceRun(ctx context.Context, recurrenceID string) (int64, error) {
+	query := s.getQueryBuilder().
+		Select("COALESCE(MAX(run_at), 0)").
+		From(s.tablePrefix + "recurrence_runs").
+		Where(sq.Eq{"recurrence_id": recurrenceID})
+
+	var runAt int64
+	if err := query.QueryRowContext(ctx).Scan(&runAt); err != nil {
+		s.logger.Error("ERROR GetLastRecurrenceRun", mlog.String("recurrenceID", recurrenceID), mlog.Err(err))
+		return 0, err
+	}
+
+	return runAt, nil
+}
+
+// InsertRecurrenceRun records the card created for an occurrence of a
+// recurrence. It fails if a card was already recorded for the
+// occurrence.
+func (s *SQLStore) InsertRecurrenceRun(ctx context.Context, run model.RecurrenceRun) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"recurrence_runs").
+		Columns(
+			"recurrence_id",
+			"run_at",
+			"card_id",
+			"create_at",
+		).
+		Values(
+			run.RecurrenceID,
+			run.RunAt,
+			run.CardID,
+			run.CreateAt,
+		)
+
+	if _, err := query.ExecContext(ctx); err != nil {
+		s.logger.Error("ERROR InsertRecurrenceRun", mlog.String("recurrenceID", run.RecurrenceID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
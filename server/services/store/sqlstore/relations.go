@@ -0,0 +1,69 @@
+package sqlstore
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GetCardBacklinks returns the other cards of the workspace with a
+// property whose value is a list of IDs containing the ID of the card.
+// The type of the properties isn't checked, as it's only known from the
+// boards of the cards.
+func (s *SQLStore) GetCardBacklinks(ctx context.Context, c store.Container, cardID string) ([]model.Block, error) {
+	pattern := "%" + likePatternEscaper.Replace(`"`+cardID+`"`) + "%"
+	fields := "fields"
+	if s.dbType == postgresDBType {
+		fields = "CAST(fields AS TEXT)"
+	}
+
+	// the serialized fields are scanned, and the matches are verified
+	// against the decoded properties
+	query := s.getReadQueryBuilder(ctx).
+		Select(
+			"id",
+			"parent_id",
+			"root_id",
+			"created_by",
+			"modified_by",
+			s.escapeField("schema"),
+			"type",
+			"title",
+			"COALESCE(fields, '{}')",
+			"create_at",
+			"update_at",
+			"delete_at",
+			"version",
+		).
+		From(s.tablePrefix+"blocks").
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0}).
+		Where(sq.Eq{"type": "card"}).
+		Where(sq.NotEq{"id": cardID}).
+		Where(fields+" LIKE ? ESCAPE '"+likeEscapeChar+"'", pattern).
+		OrderBy("id")
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetCardBacklinks ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	blocks, err := s.blocksFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	backlinks := []model.Block{}
+	for _, block := range blocks {
+		if len(model.CardPropertiesLinkingTo(block, cardID)) > 0 {
+			backlinks = append(backlinks, block)
+		}
+	}
+	return backlinks, nil
+}
@@ -0,0 +1,89 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GetBoardWorkspaceIDs returns the IDs of the workspaces that have at
+// least one board.
+func (s *SQLStore) GetBoardWorkspaceIDs() ([]string, error) {
+	query := s.getQueryBuilder().
+		Select("DISTINCT COALESCE(workspace_id, '0')").
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"type": "board"}).
+		Where(sq.Eq{"delete_at": 0})
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetBoardWorkspaceIDs", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	workspaceIDs := []string{}
+	for rows.Next() {
+		var workspaceID string
+		if err := rows.Scan(&workspaceID); err != nil {
+			return nil, err
+		}
+		workspaceIDs = append(workspaceIDs, workspaceID)
+	}
+
+	return workspaceIDs, rows.Err()
+}
+
+// InsertReminderSent records a due date reminder sent to a user.
+func (s *SQLStore) InsertReminderSent(reminder model.ReminderSent) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"reminders_sent").
+		Columns(
+			"card_id",
+			"user_id",
+			"due_at",
+			"create_at",
+		).
+		Values(
+			reminder.CardID,
+			reminder.UserID,
+			reminder.DueAt,
+			reminder.CreateAt,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR InsertReminderSent", mlog.String("cardID", reminder.CardID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetSentReminderUserIDs returns the IDs of the users already reminded
+// of the card being due at dueAt.
+func (s *SQLStore) GetSentReminderUserIDs(cardID string, dueAt int64) ([]string, error) {
+	query := s.getQueryBuilder().
+		Select("user_id").
+		From(s.tablePrefix + "reminders_sent").
+		Where(sq.Eq{"card_id": cardID}).
+		Where(sq.Eq{"due_at": dueAt})
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetSentReminderUserIDs", mlog.String("cardID", cardID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	userIDs := []string{}
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
@@ -0,0 +1,182 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+const (
+	replicaHealthCheckInterval = 10 * time.Second
+	replicaHealthCheckTimeout  = time.Second
+)
+
+// replica is a read replica of the database, which is only used while
+// it answers the health checks.
+type replica struct {
+	db      *sql.DB
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+// replicaRouter routes the reads to the replicas round-robin, unless the
+// session running them wrote to the primary within the force primary
+// window, so that it reads its own writes.
+type replicaRouter struct {
+	replicas           []*replica
+	next               uint32
+	forcePrimaryWindow time.Duration
+	logger             *mlog.Logger
+
+	mutex      sync.Mutex
+	lastWrites map[string]time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// SetReplicas sets the read replicas of the database, to which the
+// reads that can tolerate the replication lag are routed. The reads of a
+// session within forcePrimaryWindow of its last write stay on the
+// primary. As the store is created before the replicas are opened, it's
+// set once they are.
+func (s *SQLStore) SetReplicas(dbs []*sql.DB, forcePrimaryWindow time.Duration) {
+	if len(dbs) == 0 {
+		return
+	}
+
+	router := &replicaRouter{
+		forcePrimaryWindow: forcePrimaryWindow,
+		logger:             s.logger,
+		lastWrites:         map[string]time.Time{},
+		done:               make(chan struct{}),
+	}
+	for _, db := range dbs {
+		router.replicas = append(router.replicas, &replica{db: db})
+	}
+	router.checkHealth()
+
+	router.wg.Add(1)
+	go router.monitor()
+
+	s.replicas = router
+}
+
+// monitor checks the health of the replicas and forgets the writes out
+// of the force primary window, until the router is closed.
+func (r *replicaRouter) monitor() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(replicaHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.checkHealth()
+			r.pruneWrites()
+		}
+	}
+}
+
+func (r *replicaRouter) checkHealth() {
+	for i, replica := range r.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), replicaHealthCheckTimeout)
+		err := replica.db.PingContext(ctx)
+		cancel()
+
+		var healthy int32
+		if err == nil {
+			healthy = 1
+		}
+		if previous := atomic.SwapInt32(&replica.healthy, healthy); previous != healthy {
+			if err != nil {
+				r.logger.Warn("Database replica is unhealthy, reading from the primary", mlog.Int("replica", i), mlog.Err(err))
+			} else {
+				r.logger.Info("Database replica is healthy", mlog.Int("replica", i))
+			}
+		}
+	}
+}
+
+// pick returns the replica the read should run on, or nil if it should
+// run on the primary.
+func (r *replicaRouter) pick(ctx context.Context) *sql.DB {
+	if sessionID := store.SessionIDFromContext(ctx); sessionID != "" && r.wroteRecently(sessionID) {
+		return nil
+	}
+
+	start := atomic.AddUint32(&r.next, 1)
+	for i := 0; i < len(r.replicas); i++ {
+		replica := r.replicas[(int(start)+i)%len(r.replicas)]
+		if replica.isHealthy() {
+			return replica.db
+		}
+	}
+	return nil
+}
+
+func (r *replicaRouter) recordWrite(ctx context.Context) {
+	sessionID := store.SessionIDFromContext(ctx)
+	if sessionID == "" || r.forcePrimaryWindow <= 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lastWrites[sessionID] = time.Now()
+}
+
+func (r *replicaRouter) wroteRecently(sessionID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	lastWrite, ok := r.lastWrites[sessionID]
+	return ok && time.Since(lastWrite) < r.forcePrimaryWindow
+}
+
+func (r *replicaRouter) pruneWrites() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for sessionID, lastWrite := range r.lastWrites {
+		if time.Since(lastWrite) >= r.forcePrimaryWindow {
+			delete(r.lastWrites, sessionID)
+		}
+	}
+}
+
+// close stops the health checks and closes the replicas.
+func (r *replicaRouter) close() error {
+	close(r.done)
+	r.wg.Wait()
+
+	var firstErr error
+	for _, replica := range r.replicas {
+		if err := replica.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// writeTracker records the writes run with a context on the primary,
+// for the reads of the same session to stay on it.
+type writeTracker struct {
+	queryRunner
+	router *replicaRouter
+}
+
+func (w *writeTracker) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	w.router.recordWrite(ctx)
+	return w.queryRunner.ExecContext(ctx, query, args...)
+}
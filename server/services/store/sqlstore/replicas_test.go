@@ -0,0 +1,100 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicas(t *testing.T) {
+	container := store.Container{WorkspaceID: "0"}
+
+	// the replica is another database, so the reads routed to it don't
+	// see the writes to the primary
+	setupReplicas := func(t *testing.T, forcePrimaryWindow time.Duration) (*SQLStore, *SQLStore, func()) {
+		primary, tearDownPrimary := setupTests(t)
+		replica, tearDownReplica := setupTests(t)
+		primary.SetReplicas([]*sql.DB{replica.db}, forcePrimaryWindow)
+
+		err := primary.InsertBlock(context.Background(), container, &model.Block{ID: "board-1", RootID: "board-1", Type: "board"}, "user-id-1")
+		require.NoError(t, err)
+
+		return primary, replica, func() {
+			tearDownPrimary()
+			tearDownReplica()
+		}
+	}
+
+	countBoards := func(t *testing.T, s *SQLStore, sessionID string) int {
+		ctx := context.Background()
+		if sessionID != "" {
+			ctx = store.WithSessionID(ctx, sessionID)
+		}
+		blocks, err := s.GetBlocksWithType(ctx, container, "board")
+		require.NoError(t, err)
+		return len(blocks)
+	}
+
+	t.Run("should read from the replica", func(t *testing.T) {
+		primary, _, tearDown := setupReplicas(t, time.Minute)
+		defer tearDown()
+
+		require.Equal(t, 0, countBoards(t, primary, ""))
+
+		block, err := primary.GetBlock(context.Background(), container, "board-1")
+		require.NoError(t, err)
+		require.NotNil(t, block, "the reads that aren't routed should stay on the primary")
+	})
+
+	t.Run("should read from the primary after a write of the session", func(t *testing.T) {
+		primary, _, tearDown := setupReplicas(t, time.Minute)
+		defer tearDown()
+
+		ctx := store.WithSessionID(context.Background(), "session-1")
+		err := primary.InsertBlock(ctx, container, &model.Block{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card"}, "user-id-1")
+		require.NoError(t, err)
+
+		require.Equal(t, 1, countBoards(t, primary, "session-1"))
+		require.Equal(t, 0, countBoards(t, primary, "session-2"))
+	})
+
+	t.Run("should read from the replica after the force primary window", func(t *testing.T) {
+		primary, _, tearDown := setupReplicas(t, time.Millisecond)
+		defer tearDown()
+
+		ctx := store.WithSessionID(context.Background(), "session-1")
+		err := primary.InsertBlock(ctx, container, &model.Block{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card"}, "user-id-1")
+		require.NoError(t, err)
+
+		time.Sleep(10 * time.Millisecond)
+		require.Equal(t, 0, countBoards(t, primary, "session-1"))
+	})
+
+	t.Run("should read from the primary in a transaction", func(t *testing.T) {
+		primary, _, tearDown := setupReplicas(t, time.Minute)
+		defer tearDown()
+
+		tx, err := primary.BeginTx(context.Background())
+		require.NoError(t, err)
+		defer func() { _ = tx.Rollback() }()
+
+		blocks, err := tx.GetBlocksWithType(context.Background(), container, "board")
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+	})
+
+	t.Run("should fall back to the primary when the replica is unhealthy", func(t *testing.T) {
+		primary, replica, tearDown := setupReplicas(t, time.Minute)
+		defer tearDown()
+
+		require.NoError(t, replica.db.Close())
+		primary.replicas.checkHealth()
+
+		require.Equal(t, 1, countBoards(t, primary, ""))
+	})
+}
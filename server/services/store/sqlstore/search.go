@@ -0,0 +1,130 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// likeEscapeChar is the escape character used in LIKE patterns. A
+// character with no special meaning in string literals is used, so
+// the same ESCAPE clause works with every database.
+const likeEscapeChar = "!"
+
+var likePatternEscaper = strings.NewReplacer(
+	likeEscapeChar, likeEscapeChar+likeEscapeChar,
+	"%", likeEscapeChar+"%",
+	"_", likeEscapeChar+"_",
+)
+
+// SearchBlocks returns up to limit cards of the workspace whose title
+// or property values contain the query, ignoring case. Select
+// properties are matched by their stored option ID, not their label.
+func (s *SQLStore) SearchBlocks(ctx context.Context, c store.Container, query string, limit int) ([]model.BlockSearchResult, error) {
+	pattern := "%" + likePatternEscaper.Replace(query) + "%"
+
+	builder := s.getReadQueryBuilder(ctx).
+		Select(
+			"id",
+			"parent_id",
+			"root_id",
+			"created_by",
+			"modified_by",
+			s.escapeField("schema"),
+			"type",
+			"title",
+			"COALESCE(fields, '{}')",
+			"create_at",
+			"update_at",
+			"delete_at",
+			"version",
+		).
+		From(s.tablePrefix + "blocks").
+		Where(sq.Eq{"coalesce(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0}).
+		Where(sq.Eq{"type": "card"})
+
+	if s.dbType == postgresDBType {
+		builder = builder.
+			Where(sq.Or{
+				sq.Expr("title ILIKE ? ESCAPE '"+likeEscapeChar+"'", pattern),
+				sq.Expr("EXISTS (SELECT 1 FROM json_each_text(fields -> 'properties') AS p WHERE p.value ILIKE ? ESCAPE '"+likeEscapeChar+"')", pattern),
+			}).
+			OrderByClause("ts_rank(to_tsvector('simple', COALESCE(title, '')), plainto_tsquery('simple', ?)) DESC", query).
+			OrderBy("update_at DESC", "id").
+			Limit(uint64(limit))
+	} else {
+		// without JSON functions the serialized fields are scanned, and
+		// the matches are verified against the decoded properties
+		builder = builder.
+			Where(sq.Or{
+				sq.Expr("title LIKE ? ESCAPE '"+likeEscapeChar+"'", pattern),
+				sq.Expr("fields LIKE ? ESCAPE '"+likeEscapeChar+"'", pattern),
+			}).
+			OrderBy("update_at DESC", "id")
+	}
+
+	rows, err := builder.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`SearchBlocks ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	blocks, err := s.blocksFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	lowerQuery := strings.ToLower(query)
+	results := []model.BlockSearchResult{}
+	for _, block := range blocks {
+		if len(results) == limit {
+			break
+		}
+		if s.dbType != postgresDBType && !blockMatchesQuery(block, lowerQuery) {
+			continue
+		}
+		results = append(results, model.BlockSearchResult{Block: block, BoardID: block.RootID})
+	}
+
+	return results, nil
+}
+
+// blockMatchesQuery checks if the title or any of the property values
+// of the block contain the lower cased query.
+func blockMatchesQuery(block model.Block, lowerQuery string) bool {
+	if strings.Contains(strings.ToLower(block.Title), lowerQuery) {
+		return true
+	}
+
+	properties, ok := block.Fields["properties"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	for _, value := range properties {
+		var text string
+		switch v := value.(type) {
+		case string:
+			text = v
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			text = string(data)
+		}
+		if strings.Contains(strings.ToLower(text), lowerQuery) {
+			return true
+		}
+	}
+
+	return false
+}
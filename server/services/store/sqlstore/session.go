@@ -26,18 +26,28 @@ func (s *SQLStore) GetActiveUserCount(updatedSecondsAgo int64) (int, error) {
 	return count, nil
 }
 
-func (s *SQLStore) GetSession(token string, expireTime int64) (*model.Session, error) {
+// GetSession returns the session for the token, if it hasn't expired.
+func (s *SQLStore) GetSession(token string) (*model.Session, error) {
 	query := s.getQueryBuilder().
-		Select("id", "token", "user_id", "auth_service", "props").
+		Select("id", "token", "user_id", "auth_service", "props", "create_at", "update_at", "expire_at").
 		From(s.tablePrefix + "sessions").
 		Where(sq.Eq{"token": token}).
-		Where(sq.Gt{"update_at": time.Now().Unix() - expireTime})
+		Where(sq.Gt{"expire_at": time.Now().Unix()})
 
 	row := query.QueryRow()
 	session := model.Session{}
 
 	var propsBytes []byte
-	err := row.Scan(&session.ID, &session.Token, &session.UserID, &session.AuthService, &propsBytes)
+	err := row.Scan(
+		&session.ID,
+		&session.Token,
+		&session.UserID,
+		&session.AuthService,
+		&propsBytes,
+		&session.CreateAt,
+		&session.UpdateAt,
+		&session.ExpireAt,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -50,6 +60,7 @@ func (s *SQLStore) GetSession(token string, expireTime int64) (*model.Session, e
 	return &session, nil
 }
 
+// CreateSession inserts the session, which expires at its ExpireAt time.
 func (s *SQLStore) CreateSession(session *model.Session) error {
 	now := time.Now().Unix()
 
@@ -59,22 +70,36 @@ func (s *SQLStore) CreateSession(session *model.Session) error {
 	}
 
 	query := s.getQueryBuilder().Insert(s.tablePrefix+"sessions").
-		Columns("id", "token", "user_id", "auth_service", "props", "create_at", "update_at").
-		Values(session.ID, session.Token, session.UserID, session.AuthService, propsBytes, now, now)
+		Columns("id", "token", "user_id", "auth_service", "props", "create_at", "update_at", "expire_at").
+		Values(session.ID, session.Token, session.UserID, session.AuthService, propsBytes, now, now, session.ExpireAt)
 
 	_, err = query.Exec()
-	return err
+	if err != nil {
+		return err
+	}
+
+	session.CreateAt = now
+	session.UpdateAt = now
+	return nil
 }
 
+// RefreshSession marks the session as active now, and extends it to its
+// ExpireAt time.
 func (s *SQLStore) RefreshSession(session *model.Session) error {
 	now := time.Now().Unix()
 
 	query := s.getQueryBuilder().Update(s.tablePrefix+"sessions").
 		Where(sq.Eq{"token": session.Token}).
-		Set("update_at", now)
+		Set("update_at", now).
+		Set("expire_at", session.ExpireAt)
 
 	_, err := query.Exec()
-	return err
+	if err != nil {
+		return err
+	}
+
+	session.UpdateAt = now
+	return nil
 }
 
 func (s *SQLStore) UpdateSession(session *model.Session) error {
@@ -91,7 +116,12 @@ func (s *SQLStore) UpdateSession(session *model.Session) error {
 		Set("props", propsBytes)
 
 	_, err = query.Exec()
-	return err
+	if err != nil {
+		return err
+	}
+
+	session.UpdateAt = now
+	return nil
 }
 
 func (s *SQLStore) DeleteSession(sessionID string) error {
@@ -102,9 +132,30 @@ func (s *SQLStore) DeleteSession(sessionID string) error {
 	return err
 }
 
-func (s *SQLStore) CleanUpSessions(expireTime int64) error {
+// DeleteSessionsForUser deletes all the sessions of the user.
+func (s *SQLStore) DeleteSessionsForUser(userID string) error {
+	query := s.getQueryBuilder().Delete(s.tablePrefix + "sessions").
+		Where(sq.Eq{"user_id": userID})
+
+	_, err := query.Exec()
+	return err
+}
+
+// DeleteExpiredSessions deletes the sessions that have expired.
+func (s *SQLStore) DeleteExpiredSessions() error {
 	query := s.getQueryBuilder().Delete(s.tablePrefix + "sessions").
-		Where(sq.Lt{"update_at": time.Now().Unix() - expireTime})
+		Where(sq.LtOrEq{"expire_at": time.Now().Unix()})
+
+	_, err := query.Exec()
+	return err
+}
+
+// SetLegacySessionsExpireAt sets the expiry of the sessions created
+// before the sessions had one.
+func (s *SQLStore) SetLegacySessionsExpireAt(expireAt int64) error {
+	query := s.getQueryBuilder().Update(s.tablePrefix+"sessions").
+		Set("expire_at", expireAt).
+		Where(sq.Or{sq.Eq{"expire_at": 0}, sq.Eq{"expire_at": nil}})
 
 	_, err := query.Exec()
 	return err
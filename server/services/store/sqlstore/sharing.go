@@ -1,12 +1,16 @@
 package sqlstore
 
 import (
+	"context"
+	"database/sql"
 	"time"
 
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/store"
 
 	sq "github.com/Masterminds/squirrel"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 )
 
 func (s *SQLStore) UpsertSharing(c store.Container, sharing model.Sharing) error {
@@ -20,6 +24,7 @@ func (s *SQLStore) UpsertSharing(c store.Container, sharing model.Sharing) error
 			"token",
 			"modified_by",
 			"update_at",
+			"workspace_id",
 		).
 		Values(
 			sharing.ID,
@@ -27,6 +32,7 @@ func (s *SQLStore) UpsertSharing(c store.Container, sharing model.Sharing) error
 			sharing.Token,
 			sharing.ModifiedBy,
 			now,
+			c.WorkspaceID,
 		)
 	if s.dbType == mysqlDBType {
 		query = query.Suffix("ON DUPLICATE KEY UPDATE enabled = ?, token = ?, modified_by = ?, update_at = ?",
@@ -69,3 +75,197 @@ func (s *SQLStore) GetSharing(c store.Container, rootID string) (*model.Sharing,
 
 	return &sharing, nil
 }
+
+// GetSharingTokens returns the additional access tokens of the root
+// block, including the expired ones.
+func (s *SQLStore) GetSharingTokens(c store.Container, rootID string) ([]model.SharingToken, error) {
+	query := s.getQueryBuilder().
+		Select(
+			"token",
+			"id",
+			"COALESCE(created_by, '')",
+			"COALESCE(create_at, 0)",
+			"COALESCE(expire_at, 0)",
+		).
+		From(s.tablePrefix + "sharing_tokens").
+		Where(sq.Eq{"id": rootID}).
+		Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID}).
+		OrderBy("create_at", "token")
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetSharingTokens", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	tokens := []model.SharingToken{}
+	for rows.Next() {
+		var token model.SharingToken
+		err := rows.Scan(
+			&token.Token,
+			&token.RootID,
+			&token.CreatedBy,
+			&token.CreateAt,
+			&token.ExpireAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RegenerateSharingToken stores a new access token for the root block.
+// If previousToken is not empty, that token is revoked in the same
+// transaction, so the new one replaces it.
+func (s *SQLStore) RegenerateSharingToken(c store.Container, token model.SharingToken, previousToken string) error {
+	ctx := context.Background()
+	insertQuery := s.getQueryBuilder().
+		Insert(s.tablePrefix+"sharing_tokens").
+		Columns(
+			"token",
+			"id",
+			"workspace_id",
+			"created_by",
+			"create_at",
+			"expire_at",
+		).
+		Values(
+			token.Token,
+			token.RootID,
+			c.WorkspaceID,
+			token.CreatedBy,
+			token.CreateAt,
+			token.ExpireAt,
+		)
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if previousToken != "" {
+			deleteQuery := s.getQueryBuilder().
+				Delete(s.tablePrefix + "sharing_tokens").
+				Where(sq.Eq{"token": previousToken}).
+				Where(sq.Eq{"id": token.RootID}).
+				Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID})
+
+			if _, err := sq.ExecContextWith(ctx, tx, deleteQuery); err != nil {
+				return err
+			}
+		}
+
+		_, err := sq.ExecContextWith(ctx, tx, insertQuery)
+		return err
+	})
+}
+
+// RevokeSharingToken deletes an access token of the root block. It
+// returns sql.ErrNoRows if the token doesn't exist.
+func (s *SQLStore) RevokeSharingToken(c store.Container, rootID string, token string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "sharing_tokens").
+		Where(sq.Eq{"token": token}).
+		Where(sq.Eq{"id": rootID}).
+		Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetCalendarFeed returns the calendar feed of the board. It returns
+// sql.ErrNoRows if the board has none.
+func (s *SQLStore) GetCalendarFeed(c store.Container, boardID string) (*model.CalendarFeed, error) {
+	query := s.getQueryBuilder().
+		Select(
+			"id",
+			"token",
+			"COALESCE(created_by, '')",
+			"COALESCE(create_at, 0)",
+		).
+		From(s.tablePrefix + "calendar_feeds").
+		Where(sq.Eq{"id": boardID}).
+		Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID})
+
+	var feed model.CalendarFeed
+	err := query.QueryRow().Scan(
+		&feed.BoardID,
+		&feed.Token,
+		&feed.CreatedBy,
+		&feed.CreateAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+// UpsertCalendarFeed stores the calendar feed of the board, replacing
+// its previous token if any.
+func (s *SQLStore) UpsertCalendarFeed(c store.Container, feed model.CalendarFeed) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"calendar_feeds").
+		Columns(
+			"id",
+			"workspace_id",
+			"token",
+			"created_by",
+			"create_at",
+		).
+		Values(
+			feed.BoardID,
+			c.WorkspaceID,
+			feed.Token,
+			feed.CreatedBy,
+			feed.CreateAt,
+		)
+	if s.dbType == mysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE workspace_id = ?, token = ?, created_by = ?, create_at = ?",
+			c.WorkspaceID, feed.Token, feed.CreatedBy, feed.CreateAt)
+	} else {
+		query = query.Suffix(
+			`ON CONFLICT (id)
+			 DO UPDATE SET workspace_id = EXCLUDED.workspace_id, token = EXCLUDED.token, created_by = EXCLUDED.created_by, create_at = EXCLUDED.create_at`,
+		)
+	}
+
+	_, err := query.Exec()
+	return err
+}
+
+// DeleteCalendarFeed revokes the calendar feed of the board. It returns
+// sql.ErrNoRows if the board has none.
+func (s *SQLStore) DeleteCalendarFeed(c store.Container, boardID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "calendar_feeds").
+		Where(sq.Eq{"id": boardID}).
+		Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -1,9 +1,11 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/services/metrics"
 
 	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 )
@@ -22,20 +24,20 @@ type SQLStore struct {
 	connectionString string
 	isPlugin         bool
 	logger           *mlog.Logger
+	instrumentation  metrics.Instrumentation
+
+	// tx is the transaction the store is bound to, if any
+	tx *sql.Tx
+
+	// replicas routes the reads to the read replicas, if any
+	replicas *replicaRouter
 }
 
-// New creates a new SQL implementation of the store.
+// New creates a new SQL implementation of the store, and migrates the
+// database to the latest schema.
 func New(dbType, connectionString, tablePrefix string, logger *mlog.Logger, db *sql.DB, isPlugin bool) (*SQLStore, error) {
 	logger.Info("connectDatabase", mlog.String("dbType", dbType), mlog.String("connStr", connectionString))
-	store := &SQLStore{
-		// TODO: add replica DB support too.
-		db:               db,
-		dbType:           dbType,
-		tablePrefix:      tablePrefix,
-		connectionString: connectionString,
-		logger:           logger,
-		isPlugin:         isPlugin,
-	}
+	store := NewWithoutMigrations(dbType, connectionString, tablePrefix, logger, db, isPlugin)
 
 	err := store.Migrate()
 	if err != nil {
@@ -44,18 +46,37 @@ func New(dbType, connectionString, tablePrefix string, logger *mlog.Logger, db *
 		return nil, err
 	}
 
-	err = store.InitializeTemplates()
-	if err != nil {
-		logger.Error(`InitializeTemplates failed`, mlog.Err(err))
+	return store, nil
+}
 
-		return nil, err
+// NewWithoutMigrations creates a new SQL implementation of the store
+// without migrating the database, to inspect the pending migrations.
+func NewWithoutMigrations(dbType, connectionString, tablePrefix string, logger *mlog.Logger, db *sql.DB, isPlugin bool) *SQLStore {
+	return &SQLStore{
+		db:               db,
+		dbType:           dbType,
+		tablePrefix:      tablePrefix,
+		connectionString: connectionString,
+		logger:           logger,
+		isPlugin:         isPlugin,
+		instrumentation:  metrics.NoopInstrumentation{},
 	}
+}
 
-	return store, nil
+// SetInstrumentation sets the instrumentation reporting the duration of
+// the queries run by the query builder. As the store is created before
+// the server, it's set once the metrics are.
+func (s *SQLStore) SetInstrumentation(instrumentation metrics.Instrumentation) {
+	s.instrumentation = instrumentation
 }
 
 // Shutdown close the connection with the store.
 func (s *SQLStore) Shutdown() error {
+	if s.replicas != nil {
+		if err := s.replicas.close(); err != nil {
+			s.logger.Warn("Unable to close the database replicas", mlog.Err(err))
+		}
+	}
 	return s.db.Close()
 }
 
@@ -67,12 +88,39 @@ func (s *SQLStore) DBHandle() *sql.DB {
 }
 
 func (s *SQLStore) getQueryBuilder() sq.StatementBuilderType {
+	var db queryRunner = s.db
+	if s.tx != nil {
+		db = s.tx
+	}
+	if s.replicas != nil {
+		db = &writeTracker{queryRunner: db, router: s.replicas}
+	}
+
+	return s.newQueryBuilder(db)
+}
+
+// getReadQueryBuilder returns a query builder for reads that tolerate
+// the replication lag, which run on a replica unless the store is bound
+// to a transaction or there is no healthy replica.
+func (s *SQLStore) getReadQueryBuilder(ctx context.Context) sq.StatementBuilderType {
+	if s.tx != nil || s.replicas == nil {
+		return s.getQueryBuilder()
+	}
+
+	db := s.replicas.pick(ctx)
+	if db == nil {
+		return s.getQueryBuilder()
+	}
+	return s.newQueryBuilder(db)
+}
+
+func (s *SQLStore) newQueryBuilder(db queryRunner) sq.StatementBuilderType {
 	builder := sq.StatementBuilder
 	if s.dbType == postgresDBType || s.dbType == sqliteDBType {
 		builder = builder.PlaceholderFormat(sq.Dollar)
 	}
 
-	return builder.RunWith(s.db)
+	return builder.RunWith(&instrumentedRunner{db: db, instrumentation: s.instrumentation})
 }
 
 func (s *SQLStore) escapeField(fieldName string) string { //nolint:unparam
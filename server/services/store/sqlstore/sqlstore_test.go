@@ -13,6 +13,10 @@ import (
 )
 
 func SetupTests(t *testing.T) (store.Store, func()) {
+	return setupTests(t)
+}
+
+func setupTests(t testing.TB) (*SQLStore, func()) {
 	dbType := os.Getenv("FB_STORE_TEST_DB_TYPE")
 	if dbType == "" {
 		dbType = sqliteDBType
@@ -48,4 +52,25 @@ func TestBlocksStore(t *testing.T) {
 	t.Run("UserStore", func(t *testing.T) { storetests.StoreTestUserStore(t, SetupTests) })
 	t.Run("SessionStore", func(t *testing.T) { storetests.StoreTestSessionStore(t, SetupTests) })
 	t.Run("WorkspaceStore", func(t *testing.T) { storetests.StoreTestWorkspaceStore(t, SetupTests) })
+	t.Run("AuditStore", func(t *testing.T) { storetests.StoreTestAuditStore(t, SetupTests) })
+	t.Run("AccessTokenStore", func(t *testing.T) { storetests.StoreTestAccessTokenStore(t, SetupTests) })
+	t.Run("LoginAttemptsStore", func(t *testing.T) { storetests.StoreTestLoginAttemptsStore(t, SetupTests) })
+	t.Run("PasswordResetStore", func(t *testing.T) { storetests.StoreTestPasswordResetStore(t, SetupTests) })
+	t.Run("MfaStore", func(t *testing.T) { storetests.StoreTestMfaStore(t, SetupTests) })
+	t.Run("Transactions", func(t *testing.T) { storetests.StoreTestTransactions(t, SetupTests) })
+	t.Run("Health", func(t *testing.T) { storetests.StoreTestHealth(t, SetupTests) })
+	t.Run("BoardStatistics", func(t *testing.T) { storetests.StoreTestBoardStatistics(t, SetupTests) })
+	t.Run("Activity", func(t *testing.T) { storetests.StoreTestActivity(t, SetupTests) })
+	t.Run("Relations", func(t *testing.T) { storetests.StoreTestRelations(t, SetupTests) })
+	t.Run("RecurrenceStore", func(t *testing.T) { storetests.StoreTestRecurrenceStore(t, SetupTests) })
+	t.Run("CardNumbers", func(t *testing.T) { storetests.StoreTestCardNumbers(t, SetupTests) })
+	t.Run("Files", func(t *testing.T) { storetests.StoreTestFiles(t, SetupTests) })
+	t.Run("BoardMembers", func(t *testing.T) { storetests.StoreTestBoardMembers(t, SetupTests) })
+	t.Run("GuestInvites", func(t *testing.T) { storetests.StoreTestGuestInvites(t, SetupTests) })
+	t.Run("Subscriptions", func(t *testing.T) { storetests.StoreTestSubscriptions(t, SetupTests) })
+	t.Run("Digests", func(t *testing.T) { storetests.StoreTestDigests(t, SetupTests) })
+	t.Run("InboundHooks", func(t *testing.T) { storetests.StoreTestInboundHooks(t, SetupTests) })
+	t.Run("GitHubIntegrations", func(t *testing.T) { storetests.StoreTestGitHubIntegrations(t, SetupTests) })
+	t.Run("IdempotencyKeys", func(t *testing.T) { storetests.StoreTestIdempotencyKeys(t, SetupTests) })
+	t.Run("Preferences", func(t *testing.T) { storetests.StoreTestPreferences(t, SetupTests) })
 }
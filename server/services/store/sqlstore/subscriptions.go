@@ -0,0 +1,167 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+func subscriptionFields() []string {
+	return []string{
+		"block_type",
+		"block_id",
+		"workspace_id",
+		"subscriber_id",
+		"COALESCE(notified_at, 0)",
+		"create_at",
+	}
+}
+
+// CreateSubscription subscribes the user to the block, keeping the
+// existing subscription if there is one.
+func (s *SQLStore) CreateSubscription(ctx context.Context, subscription model.Subscription) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"subscriptions").
+		Columns("block_type", "block_id", "workspace_id", "subscriber_id", "notified_at", "create_at").
+		Values(
+			subscription.BlockType,
+			subscription.BlockID,
+			subscription.WorkspaceID,
+			subscription.SubscriberID,
+			subscription.NotifiedAt,
+			subscription.CreateAt,
+		)
+	if s.dbType == mysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE subscriber_id = subscriber_id")
+	} else {
+		query = query.Suffix("ON CONFLICT (block_id, subscriber_id) DO NOTHING")
+	}
+
+	if _, err := query.ExecContext(ctx); err != nil {
+		s.logger.Error("ERROR CreateSubscription", mlog.String("blockID", subscription.BlockID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteSubscription unsubscribes the user from the block. It returns
+// sql.ErrNoRows if the user isn't subscribed to it.
+func (s *SQLStore) DeleteSubscription(ctx context.Context, c store.Container, blockID, subscriberID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "subscriptions").
+		Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+		Where(sq.Eq{"block_id": blockID}).
+		Where(sq.Eq{"subscriber_id": subscriberID})
+
+	result, err := query.ExecContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR DeleteSubscription", mlog.String("blockID", blockID), mlog.Err(err))
+		return err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetSubscriptions returns the subscriptions to the blocks.
+func (s *SQLStore) GetSubscriptions(ctx context.Context, c store.Container, blockIDs []string) ([]model.Subscription, error) {
+	if len(blockIDs) == 0 {
+		return []model.Subscription{}, nil
+	}
+
+	query := s.getQueryBuilder().
+		Select(subscriptionFields()...).
+		From(s.tablePrefix + "subscriptions").
+		Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+		Where(sq.Eq{"block_id": blockIDs}).
+		OrderBy("create_at", "subscriber_id")
+
+	return s.querySubscriptions(ctx, query)
+}
+
+// GetSubscriptionsForUser returns the subscriptions of the user in the
+// workspace, the oldest first.
+func (s *SQLStore) GetSubscriptionsForUser(ctx context.Context, c store.Container, subscriberID string) ([]model.Subscription, error) {
+	query := s.getQueryBuilder().
+		Select(subscriptionFields()...).
+		From(s.tablePrefix + "subscriptions").
+		Where(sq.Eq{"workspace_id": c.WorkspaceID}).
+		Where(sq.Eq{"subscriber_id": subscriberID}).
+		OrderBy("create_at", "block_id")
+
+	return s.querySubscriptions(ctx, query)
+}
+
+func (s *SQLStore) querySubscriptions(ctx context.Context, query sq.SelectBuilder) ([]model.Subscription, error) {
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR querySubscriptions", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	subscriptions := []model.Subscription{}
+	for rows.Next() {
+		var subscription model.Subscription
+		err := rows.Scan(
+			&subscription.BlockType,
+			&subscription.BlockID,
+			&subscription.WorkspaceID,
+			&subscription.SubscriberID,
+			&subscription.NotifiedAt,
+			&subscription.CreateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// ClaimSubscriptionNotification records that the subscriber is notified
+// of a change of the block, unless they were already notified since
+// notifiedBefore. It returns false when the notification is throttled,
+// so that only one server of the cluster sends it.
+func (s *SQLStore) ClaimSubscriptionNotification(ctx context.Context, blockID, subscriberID string, notifiedAt, notifiedBefore int64) (bool, error) {
+	query := s.getQueryBuilder().
+		Update(s.tablePrefix+"subscriptions").
+		Set("notified_at", notifiedAt).
+		Where(sq.Eq{"block_id": blockID}).
+		Where(sq.Eq{"subscriber_id": subscriberID}).
+		Where(sq.Lt{"COALESCE(notified_at, 0)": notifiedBefore})
+
+	result, err := query.ExecContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR ClaimSubscriptionNotification", mlog.String("blockID", blockID), mlog.Err(err))
+		return false, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetBoardSubscriptions returns the subscriptions to the boards of every
+// workspace, by subscriber.
+func (s *SQLStore) GetBoardSubscriptions(ctx context.Context) ([]model.Subscription, error) {
+	query := s.getQueryBuilder().
+		Select(subscriptionFields()...).
+		From(s.tablePrefix + "subscriptions").
+		Where(sq.Eq{"block_type": "board"}).
+		OrderBy("subscriber_id", "workspace_id", "create_at")
+
+	return s.querySubscriptions(ctx, query)
+}
@@ -0,0 +1,65 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GetTemplateBoards returns the template boards of the workspace,
+// ordered by title, along with their card count.
+func (s *SQLStore) GetTemplateBoards(ctx context.Context, c store.Container) ([]model.BoardTemplate, error) {
+	blocksTable := s.tablePrefix + "blocks"
+	templateFilter, err := s.templateBoardFilter("b", true)
+	if err != nil {
+		return nil, fmt.Errorf("GetTemplateBoards - %w", err)
+	}
+
+	query := s.getQueryBuilder().
+		Select("b.id", "b.title", "COALESCE(b.fields, '{}')").
+		Column(sq.Expr(
+			"(SELECT COUNT(*) FROM "+blocksTable+" AS cards WHERE cards.root_id = b.id AND cards.type = 'card' AND cards.delete_at = 0 AND COALESCE(cards.workspace_id, '0') = ?)",
+			c.WorkspaceID,
+		)).
+		From(blocksTable + " AS b").
+		Where(sq.Eq{"COALESCE(b.workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"b.type": "board"}).
+		Where(sq.Eq{"b.delete_at": 0}).
+		Where(templateFilter).
+		OrderBy("b.title", "b.id")
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetTemplateBoards ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	templates := []model.BoardTemplate{}
+	for rows.Next() {
+		var template model.BoardTemplate
+		var fieldsJSON string
+
+		if err := rows.Scan(&template.ID, &template.Title, &fieldsJSON, &template.CardCount); err != nil {
+			s.logger.Error("ERROR GetTemplateBoards scan", mlog.Err(err))
+			return nil, err
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(fieldsJSON), &fields); err != nil {
+			s.logger.Error("ERROR GetTemplateBoards fields", mlog.Err(err))
+			return nil, err
+		}
+		template.Icon, _ = fields["icon"].(string)
+
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,80 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+var errNestedTransaction = errors.New("the store is already bound to a transaction")
+
+// sqlStoreTx is a store bound to a transaction.
+type sqlStoreTx struct {
+	*SQLStore
+}
+
+// BeginTx starts a transaction and returns a store bound to it, so that
+// its methods are applied together when it's committed, or not at all.
+func (s *SQLStore) BeginTx(ctx context.Context) (store.Tx, error) {
+	if s.tx != nil {
+		return nil, errNestedTransaction
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	txStore := *s
+	txStore.tx = tx
+	return &sqlStoreTx{SQLStore: &txStore}, nil
+}
+
+func (t *sqlStoreTx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *sqlStoreTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Shutdown rolls back the transaction, as the database is shared with
+// the store that started it.
+func (t *sqlStoreTx) Shutdown() error {
+	err := t.tx.Rollback()
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
+
+// inTx runs fn in the transaction the store is bound to. If it isn't
+// bound to one, fn runs in a new transaction, which is committed if fn
+// succeeds and rolled back otherwise.
+func (s *SQLStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if s.replicas != nil {
+		s.replicas.recordWrite(ctx)
+	}
+
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.logger.Warn("Transaction rollback error", mlog.Err(rollbackErr))
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,10 +1,12 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/mattermost/focalboard/server/model"
@@ -20,6 +22,12 @@ func (unf UserNotFoundError) Error() string {
 	return fmt.Sprintf("user not found (%s)", unf.id)
 }
 
+// Unwrap returns sql.ErrNoRows, so that a missing user is handled like
+// any other missing row.
+func (unf UserNotFoundError) Unwrap() error {
+	return sql.ErrNoRows
+}
+
 func (s *SQLStore) GetRegisteredUserCount() (int, error) {
 	query := s.getQueryBuilder().
 		Select("count(*)").
@@ -49,21 +57,28 @@ func (s *SQLStore) getUserByCondition(condition sq.Eq) (*model.User, error) {
 	return users[0], nil
 }
 
+func userFields() []string {
+	return []string{
+		"id",
+		"username",
+		"email",
+		"password",
+		"mfa_secret",
+		"mfa_active",
+		"auth_service",
+		"auth_data",
+		"props",
+		"create_at",
+		"update_at",
+		"delete_at",
+		"COALESCE(is_admin, FALSE)",
+		"COALESCE(is_guest, FALSE)",
+	}
+}
+
 func (s *SQLStore) getUsersByCondition(condition sq.Eq) ([]*model.User, error) {
 	query := s.getQueryBuilder().
-		Select(
-			"id",
-			"username",
-			"email",
-			"password",
-			"mfa_secret",
-			"auth_service",
-			"auth_data",
-			"props",
-			"create_at",
-			"update_at",
-			"delete_at",
-		).
+		Select(userFields()...).
 		From(s.tablePrefix + "users").
 		Where(sq.Eq{"delete_at": 0}).
 		Where(condition)
@@ -98,6 +113,33 @@ func (s *SQLStore) GetUserByUsername(username string) (*model.User, error) {
 	return s.getUserByCondition(sq.Eq{"username": username})
 }
 
+// GetUserByAuthData returns the user with the identity of the single
+// sign-on, including a deactivated one, so that the deactivated users
+// aren't provisioned again.
+func (s *SQLStore) GetUserByAuthData(authData string) (*model.User, error) {
+	query := s.getQueryBuilder().
+		Select(userFields()...).
+		From(s.tablePrefix + "users").
+		Where(sq.Eq{"auth_data": authData}).
+		Where(sq.NotEq{"auth_data": ""}).
+		OrderBy("delete_at", "create_at").
+		Limit(1)
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	users, err := s.usersFromRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return users[0], nil
+}
+
 func (s *SQLStore) CreateUser(user *model.User) error {
 	now := time.Now().Unix()
 
@@ -107,8 +149,8 @@ func (s *SQLStore) CreateUser(user *model.User) error {
 	}
 
 	query := s.getQueryBuilder().Insert(s.tablePrefix+"users").
-		Columns("id", "username", "email", "password", "mfa_secret", "auth_service", "auth_data", "props", "create_at", "update_at", "delete_at").
-		Values(user.ID, user.Username, user.Email, user.Password, user.MfaSecret, user.AuthService, user.AuthData, propsBytes, now, now, 0)
+		Columns("id", "username", "email", "password", "mfa_secret", "auth_service", "auth_data", "props", "create_at", "update_at", "delete_at", "is_admin", "is_guest").
+		Values(user.ID, user.Username, user.Email, user.Password, user.MfaSecret, user.AuthService, user.AuthData, propsBytes, now, now, 0, user.IsAdmin, user.IsGuest)
 
 	_, err = query.Exec()
 	return err
@@ -196,10 +238,163 @@ func (s *SQLStore) UpdateUserPasswordByID(userID, password string) error {
 	return nil
 }
 
+// UpdateUserMfa sets the encrypted MFA secret of the user, and whether
+// the logins require a code for it.
+func (s *SQLStore) UpdateUserMfa(userID, mfaSecret string, mfaActive bool) error {
+	now := time.Now().Unix()
+
+	query := s.getQueryBuilder().Update(s.tablePrefix+"users").
+		Set("mfa_secret", mfaSecret).
+		Set("mfa_active", mfaActive).
+		Set("update_at", now).
+		Where(sq.Eq{"id": userID})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowCount < 1 {
+		return UserNotFoundError{userID}
+	}
+
+	return nil
+}
+
+// UpdateUserAuthData sets the identity of the single sign-on of the
+// user, linking the user to it.
+func (s *SQLStore) UpdateUserAuthData(userID, authData string) error {
+	now := time.Now().Unix()
+
+	query := s.getQueryBuilder().Update(s.tablePrefix+"users").
+		Set("auth_data", authData).
+		Set("update_at", now).
+		Where(sq.Eq{"id": userID})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowCount < 1 {
+		return UserNotFoundError{userID}
+	}
+
+	return nil
+}
+
+// UpdateUserActive deactivates the user, or activates it again. The
+// deactivated users are deleted users, so that they can't log in and
+// aren't listed.
+func (s *SQLStore) UpdateUserActive(userID string, active bool) error {
+	now := time.Now().Unix()
+	deleteAt := now
+	if active {
+		deleteAt = 0
+	}
+
+	query := s.getQueryBuilder().Update(s.tablePrefix+"users").
+		Set("delete_at", deleteAt).
+		Set("update_at", now).
+		Where(sq.Eq{"id": userID})
+
+	result, err := query.Exec()
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowCount < 1 {
+		return UserNotFoundError{userID}
+	}
+
+	return nil
+}
+
+// GetUsers returns a page of the users matching the options, including
+// the deactivated ones, sorted by username.
+func (s *SQLStore) GetUsers(opts model.QueryUsersOptions) ([]*model.User, error) {
+	query := s.getQueryBuilder().
+		Select(userFields()...).
+		From(s.tablePrefix+"users").
+		OrderBy("username", "id")
+
+	if opts.Active != nil {
+		if *opts.Active {
+			query = query.Where(sq.Eq{"delete_at": 0})
+		} else {
+			query = query.Where(sq.NotEq{"delete_at": 0})
+		}
+	}
+	if opts.Username != "" {
+		pattern := likePatternEscaper.Replace(opts.Username) + "%"
+		query = query.Where("username LIKE ? ESCAPE '"+likeEscapeChar+"'", pattern)
+	}
+	if opts.PerPage > 0 {
+		query = query.Limit(uint64(opts.PerPage)).Offset(uint64(opts.Page * opts.PerPage))
+	}
+
+	rows, err := query.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.usersFromRows(rows)
+}
+
 func (s *SQLStore) GetUsersByWorkspace(workspaceID string) ([]*model.User, error) {
 	return s.getUsersByCondition(nil)
 }
 
+// SearchUsersByWorkspace returns the active users with access to the
+// workspace whose username starts with the prefix, ignoring the case.
+func (s *SQLStore) SearchUsersByWorkspace(ctx context.Context, workspaceID, prefix string, limit int) ([]model.UserSearchResult, error) {
+	pattern := likePatternEscaper.Replace(strings.ToLower(prefix)) + "%"
+	query := s.getQueryBuilder().
+		Select("u.id", "u.username").
+		From(s.tablePrefix+"users AS u").
+		Where(sq.Eq{"u.delete_at": 0}).
+		Where("LOWER(u.username) LIKE ? ESCAPE '"+likeEscapeChar+"'", pattern).
+		Where(s.guestBoardCondition("u", workspaceID)).
+		OrderBy("u.username", "u.id").
+		Limit(uint64(limit))
+	if workspaceID != "0" {
+		// every active user has access to the root workspace
+		query = query.Join(s.tablePrefix+"workspace_members AS wm ON wm.user_id = u.id AND wm.workspace_id = ?", workspaceID)
+	}
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	results := []model.UserSearchResult{}
+	for rows.Next() {
+		var result model.UserSearchResult
+		if err := rows.Scan(&result.ID, &result.Username); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
 func (s *SQLStore) usersFromRows(rows *sql.Rows) ([]*model.User, error) {
 	users := []*model.User{}
 
@@ -213,12 +408,15 @@ func (s *SQLStore) usersFromRows(rows *sql.Rows) ([]*model.User, error) {
 			&user.Email,
 			&user.Password,
 			&user.MfaSecret,
+			&user.MfaActive,
 			&user.AuthService,
 			&user.AuthData,
 			&propsBytes,
 			&user.CreateAt,
 			&user.UpdateAt,
 			&user.DeleteAt,
+			&user.IsAdmin,
+			&user.IsGuest,
 		)
 		if err != nil {
 			return nil, err
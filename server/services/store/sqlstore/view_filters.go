@@ -0,0 +1,190 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// integerText returns the SQL condition of the text expression being a
+// non-negative integer, like the timestamps of the date properties.
+func (s *SQLStore) integerText(value sq.Sqlizer) (sq.Sqlizer, error) {
+	switch s.dbType {
+	case postgresDBType:
+		return sq.Expr("? ~ '^[0-9]+$'", value), nil
+	case mysqlDBType:
+		return sq.Expr("? REGEXP '^[0-9]+$'", value), nil
+	case sqliteDBType:
+		return sq.Expr("(? <> '' AND ? NOT GLOB '*[^0-9]*')", value, value), nil
+	default:
+		return nil, errUnsupportedDatabaseError
+	}
+}
+
+// castInteger returns the SQL expression of the text expression as an
+// integer.
+func (s *SQLStore) castInteger(value sq.Sqlizer) sq.Sqlizer {
+	switch s.dbType {
+	case mysqlDBType:
+		return sq.Expr("CAST(? AS SIGNED)", value)
+	case sqliteDBType:
+		return sq.Expr("CAST(? AS INTEGER)", value)
+	default:
+		return sq.Expr("CAST(? AS BIGINT)", value)
+	}
+}
+
+// filterClausePredicate returns the SQL condition of the cards of the
+// table meeting the clause of a view filter, on a property with a single
+// value. The dates that aren't a timestamp, like the date ranges, aren't
+// compared by the database and always meet the isBetween clauses, for
+// the caller to check them.
+func (s *SQLStore) filterClausePredicate(table string, clause model.FilterClause) (sq.Sqlizer, error) {
+	value, err := s.cardPropertyValue(table, clause.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch clause.Condition {
+	case model.FilterConditionIncludes, model.FilterConditionNotIncludes:
+		// like the clients, the clauses without values are ignored
+		if len(clause.Values) == 0 {
+			return sq.Expr("1 = 1"), nil
+		}
+		operator := "IN"
+		if clause.Condition == model.FilterConditionNotIncludes {
+			operator = "NOT IN"
+		}
+		args := []interface{}{value}
+		for _, v := range clause.Values {
+			args = append(args, v)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(clause.Values)), ", ")
+		return sq.Expr("? "+operator+" ("+placeholders+")", args...), nil
+	case model.FilterConditionIsEmpty:
+		return sq.Expr("? = ''", value), nil
+	case model.FilterConditionIsNotEmpty:
+		return sq.Expr("? <> ''", value), nil
+	case model.FilterConditionIsBetween:
+		isInteger, err := s.integerText(value)
+		if err != nil {
+			return nil, err
+		}
+		between := sq.And{isInteger}
+		if len(clause.Values) > 0 && clause.Values[0] != "" {
+			from, err := strconv.ParseInt(clause.Values[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid start of the date range of property %s", clause.PropertyID)
+			}
+			between = append(between, sq.Expr("? >= ?", s.castInteger(value), from))
+		}
+		if len(clause.Values) > 1 && clause.Values[1] != "" {
+			to, err := strconv.ParseInt(clause.Values[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid end of the date range of property %s", clause.PropertyID)
+			}
+			between = append(between, sq.Expr("? <= ?", s.castInteger(value), to))
+		}
+		return sq.Or{
+			between,
+			sq.And{sq.Expr("? <> ''", value), sq.Expr("NOT (?)", isInteger)},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported filter condition %q", clause.Condition)
+	}
+}
+
+// filterGroupPredicate returns the SQL condition of the cards of the
+// table meeting the view filter. Like the clients, the groups without
+// any filter are always met.
+func (s *SQLStore) filterGroupPredicate(table string, group model.FilterGroup) (sq.Sqlizer, error) {
+	if len(group.Clauses) == 0 && len(group.Groups) == 0 {
+		return sq.Expr("1 = 1"), nil
+	}
+
+	predicates := []sq.Sqlizer{}
+	for _, clause := range group.Clauses {
+		predicate, err := s.filterClausePredicate(table, clause)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+	for _, nested := range group.Groups {
+		predicate, err := s.filterGroupPredicate(table, nested)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+	}
+
+	if group.Operation == model.FilterOperationOr {
+		return sq.Or(predicates), nil
+	}
+	return sq.And(predicates), nil
+}
+
+// GetFilteredBoardBlocks returns the board, its child blocks that aren't
+// cards, its card templates and the cards meeting the view filter, or
+// all its cards without one.
+func (s *SQLStore) GetFilteredBoardBlocks(ctx context.Context, c store.Container, boardID string, filter *model.FilterGroup) ([]model.Block, error) {
+	table := "blocks"
+	cards := sq.Sqlizer(sq.Expr("1 = 1"))
+	if filter != nil {
+		var err error
+		if cards, err = s.filterGroupPredicate(table, *filter); err != nil {
+			return nil, err
+		}
+	}
+	templateFilter, err := s.templateBoardFilter(table, true)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.getReadQueryBuilder(ctx).
+		Select(
+			"id",
+			"parent_id",
+			"root_id",
+			"created_by",
+			"modified_by",
+			s.escapeField("schema"),
+			"type",
+			"title",
+			"COALESCE(fields, '{}')",
+			"create_at",
+			"update_at",
+			"delete_at",
+			"version",
+		).
+		From(s.tablePrefix + "blocks AS " + table).
+		Where(sq.Eq{"COALESCE(workspace_id, '0')": c.WorkspaceID}).
+		Where(sq.Eq{"delete_at": 0}).
+		Where(sq.Or{
+			sq.Eq{"id": boardID},
+			sq.And{
+				sq.Eq{"parent_id": boardID},
+				sq.Or{
+					sq.NotEq{"type": "card"},
+					sq.Expr("COALESCE(" + templateFilter + ", FALSE)"),
+					cards,
+				},
+			},
+		})
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error(`GetFilteredBoardBlocks ERROR`, mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.blocksFromRows(rows)
+}
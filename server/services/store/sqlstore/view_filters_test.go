@@ -0,0 +1,138 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterClausePredicate(t *testing.T) {
+	status := model.FilterClause{PropertyID: "status", Condition: model.FilterConditionIncludes, Values: []string{"todo", "done"}}
+	due := model.FilterClause{PropertyID: "due", Condition: model.FilterConditionIsBetween, Values: []string{"1000", "2000"}}
+
+	testCases := []struct {
+		dbType string
+		clause model.FilterClause
+		sql    string
+		args   []interface{}
+	}{
+		{
+			postgresDBType, status,
+			"COALESCE(cards.fields -> 'properties' ->> ?, '') IN (?, ?)",
+			[]interface{}{"status", "todo", "done"},
+		},
+		{
+			mysqlDBType, status,
+			"COALESCE(JSON_UNQUOTE(JSON_EXTRACT(cards.fields, ?)), '') IN (?, ?)",
+			[]interface{}{`$.properties."status"`, "todo", "done"},
+		},
+		{
+			sqliteDBType, status,
+			`CAST(CASE WHEN instr(cards.fields, ?) > 0 THEN substr(cards.fields, instr(cards.fields, ?) + ?, instr(substr(cards.fields, instr(cards.fields, ?) + ?), '"') - 1) ELSE '' END AS TEXT) IN (?, ?)`,
+			[]interface{}{`"status":"`, `"status":"`, 10, `"status":"`, 10, "todo", "done"},
+		},
+		{
+			postgresDBType, model.FilterClause{PropertyID: "owner", Condition: model.FilterConditionNotIncludes, Values: []string{"user-1"}},
+			"COALESCE(cards.fields -> 'properties' ->> ?, '') NOT IN (?)",
+			[]interface{}{"owner", "user-1"},
+		},
+		{
+			postgresDBType, model.FilterClause{PropertyID: "owner", Condition: model.FilterConditionIncludes},
+			"1 = 1",
+			nil,
+		},
+		{
+			mysqlDBType, model.FilterClause{PropertyID: "owner", Condition: model.FilterConditionIsEmpty},
+			"COALESCE(JSON_UNQUOTE(JSON_EXTRACT(cards.fields, ?)), '') = ''",
+			[]interface{}{`$.properties."owner"`},
+		},
+		{
+			postgresDBType, due,
+			"((COALESCE(cards.fields -> 'properties' ->> ?, '') ~ '^[0-9]+$'" +
+				" AND CAST(COALESCE(cards.fields -> 'properties' ->> ?, '') AS BIGINT) >= ?" +
+				" AND CAST(COALESCE(cards.fields -> 'properties' ->> ?, '') AS BIGINT) <= ?)" +
+				" OR (COALESCE(cards.fields -> 'properties' ->> ?, '') <> ''" +
+				" AND NOT (COALESCE(cards.fields -> 'properties' ->> ?, '') ~ '^[0-9]+$')))",
+			[]interface{}{"due", "due", int64(1000), "due", int64(2000), "due", "due"},
+		},
+		{
+			mysqlDBType, model.FilterClause{PropertyID: "due", Condition: model.FilterConditionIsBetween, Values: []string{"1000"}},
+			"((COALESCE(JSON_UNQUOTE(JSON_EXTRACT(cards.fields, ?)), '') REGEXP '^[0-9]+$'" +
+				" AND CAST(COALESCE(JSON_UNQUOTE(JSON_EXTRACT(cards.fields, ?)), '') AS SIGNED) >= ?)" +
+				" OR (COALESCE(JSON_UNQUOTE(JSON_EXTRACT(cards.fields, ?)), '') <> ''" +
+				" AND NOT (COALESCE(JSON_UNQUOTE(JSON_EXTRACT(cards.fields, ?)), '') REGEXP '^[0-9]+$')))",
+			[]interface{}{`$.properties."due"`, `$.properties."due"`, int64(1000), `$.properties."due"`, `$.properties."due"`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.dbType+" "+tc.clause.Condition, func(t *testing.T) {
+			s := &SQLStore{dbType: tc.dbType}
+			predicate, err := s.filterClausePredicate("cards", tc.clause)
+			require.NoError(t, err)
+
+			sql, args, err := predicate.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, tc.sql, sql)
+			require.Equal(t, tc.args, args)
+		})
+	}
+
+	t.Run("sqlite isBetween", func(t *testing.T) {
+		s := &SQLStore{dbType: sqliteDBType}
+		predicate, err := s.filterClausePredicate("cards", model.FilterClause{PropertyID: "due", Condition: model.FilterConditionIsBetween, Values: []string{"", "2000"}})
+		require.NoError(t, err)
+
+		sql, _, err := predicate.ToSql()
+		require.NoError(t, err)
+		require.Contains(t, sql, "NOT GLOB '*[^0-9]*'")
+		require.Contains(t, sql, "AS INTEGER) <= ?")
+		require.NotContains(t, sql, ">= ?")
+	})
+
+	t.Run("unsupported condition", func(t *testing.T) {
+		s := &SQLStore{dbType: postgresDBType}
+		_, err := s.filterClausePredicate("cards", model.FilterClause{PropertyID: "due", Condition: "isWeekend"})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid date range", func(t *testing.T) {
+		s := &SQLStore{dbType: postgresDBType}
+		_, err := s.filterClausePredicate("cards", model.FilterClause{PropertyID: "due", Condition: model.FilterConditionIsBetween, Values: []string{"yesterday"}})
+		require.Error(t, err)
+	})
+}
+
+func TestFilterGroupPredicate(t *testing.T) {
+	s := &SQLStore{dbType: postgresDBType}
+
+	group := model.FilterGroup{
+		Operation: model.FilterOperationOr,
+		Clauses:   []model.FilterClause{{PropertyID: "status", Condition: model.FilterConditionIsEmpty}},
+		Groups: []model.FilterGroup{{
+			Operation: model.FilterOperationAnd,
+			Clauses: []model.FilterClause{
+				{PropertyID: "status", Condition: model.FilterConditionIncludes, Values: []string{"done"}},
+				{PropertyID: "owner", Condition: model.FilterConditionIsNotEmpty},
+			},
+		}},
+	}
+	predicate, err := s.filterGroupPredicate("cards", group)
+	require.NoError(t, err)
+
+	sql, args, err := predicate.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "(COALESCE(cards.fields -> 'properties' ->> ?, '') = ''"+
+		" OR (COALESCE(cards.fields -> 'properties' ->> ?, '') IN (?)"+
+		" AND COALESCE(cards.fields -> 'properties' ->> ?, '') <> ''))", sql)
+	require.Equal(t, []interface{}{"status", "status", "done", "owner"}, args)
+
+	t.Run("empty group", func(t *testing.T) {
+		predicate, err := s.filterGroupPredicate("cards", model.FilterGroup{Operation: model.FilterOperationOr})
+		require.NoError(t, err)
+		sql, _, err := predicate.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, "1 = 1", sql)
+	})
+}
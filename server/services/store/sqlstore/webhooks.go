@@ -0,0 +1,89 @@
+package sqlstore
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// InsertWebhookDelivery stores a failed webhook delivery.
+func (s *SQLStore) InsertWebhookDelivery(delivery model.WebhookDelivery) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"webhook_deliveries").
+		Columns(
+			"id",
+			"workspace_id",
+			"url",
+			"payload",
+			"status_code",
+			"error",
+			"attempts",
+			"create_at",
+		).
+		Values(
+			delivery.ID,
+			delivery.WorkspaceID,
+			delivery.URL,
+			delivery.Payload,
+			delivery.StatusCode,
+			delivery.Error,
+			delivery.Attempts,
+			delivery.CreateAt,
+		)
+
+	if _, err := query.Exec(); err != nil {
+		s.logger.Error("ERROR InsertWebhookDelivery", mlog.String("workspaceID", delivery.WorkspaceID), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries returns up to limit failed webhook deliveries
+// of the workspace, the most recent first.
+func (s *SQLStore) GetWebhookDeliveries(workspaceID string, limit int) ([]model.WebhookDelivery, error) {
+	query := s.getQueryBuilder().
+		Select(
+			"id",
+			"workspace_id",
+			"COALESCE(url, '')",
+			"COALESCE(payload, '')",
+			"COALESCE(status_code, 0)",
+			"COALESCE(error, '')",
+			"COALESCE(attempts, 0)",
+			"COALESCE(create_at, 0)",
+		).
+		From(s.tablePrefix + "webhook_deliveries").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		OrderBy("create_at DESC", "id").
+		Limit(uint64(limit))
+
+	rows, err := query.Query()
+	if err != nil {
+		s.logger.Error("ERROR GetWebhookDeliveries", mlog.String("workspaceID", workspaceID), mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	deliveries := []model.WebhookDelivery{}
+	for rows.Next() {
+		var delivery model.WebhookDelivery
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.WorkspaceID,
+			&delivery.URL,
+			&delivery.Payload,
+			&delivery.StatusCode,
+			&delivery.Error,
+			&delivery.Attempts,
+			&delivery.CreateAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,99 @@
+package sqlstore
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/focalboard/server/model"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// GetInactiveWorkspaces returns the workspaces whose blocks were last
+// updated before the time, in milliseconds, ordered by their ID. The
+// workspaces without blocks aren't returned.
+func (s *SQLStore) GetInactiveWorkspaces(ctx context.Context, inactiveSince int64) ([]model.InactiveWorkspace, error) {
+	query := s.getQueryBuilder().
+		Select("COALESCE(workspace_id, '0') AS id", "MAX(update_at)").
+		From(s.tablePrefix+"blocks").
+		GroupBy("COALESCE(workspace_id, '0')").
+		Having("MAX(update_at) < ?", inactiveSince).
+		OrderBy("id")
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR GetInactiveWorkspaces", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	workspaces := []model.InactiveWorkspace{}
+	for rows.Next() {
+		var workspace model.InactiveWorkspace
+		if err := rows.Scan(&workspace.WorkspaceID, &workspace.LastActivityAt); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	return workspaces, rows.Err()
+}
+
+// GetWorkspaceRetentionNotices returns the notices of the deletion of the
+// inactive workspaces sent to their members.
+func (s *SQLStore) GetWorkspaceRetentionNotices(ctx context.Context) ([]model.WorkspaceRetentionNotice, error) {
+	query := s.getQueryBuilder().
+		Select("workspace_id", "last_activity_at", "notified_at").
+		From(s.tablePrefix + "workspace_retention_notices").
+		OrderBy("workspace_id")
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR GetWorkspaceRetentionNotices", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	notices := []model.WorkspaceRetentionNotice{}
+	for rows.Next() {
+		var notice model.WorkspaceRetentionNotice
+		if err := rows.Scan(&notice.WorkspaceID, &notice.LastActivityAt, &notice.NotifiedAt); err != nil {
+			return nil, err
+		}
+		notices = append(notices, notice)
+	}
+	return notices, rows.Err()
+}
+
+// UpsertWorkspaceRetentionNotice records the notice of the deletion of
+// the workspace, replacing the previous one.
+func (s *SQLStore) UpsertWorkspaceRetentionNotice(ctx context.Context, notice model.WorkspaceRetentionNotice) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"workspace_retention_notices").
+		Columns("workspace_id", "last_activity_at", "notified_at").
+		Values(notice.WorkspaceID, notice.LastActivityAt, notice.NotifiedAt)
+	if s.dbType == mysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE last_activity_at = ?, notified_at = ?", notice.LastActivityAt, notice.NotifiedAt)
+	} else {
+		query = query.Suffix("ON CONFLICT (workspace_id) DO UPDATE SET last_activity_at = EXCLUDED.last_activity_at, notified_at = EXCLUDED.notified_at")
+	}
+
+	if _, err := query.ExecContext(ctx); err != nil {
+		s.logger.Error("ERROR UpsertWorkspaceRetentionNotice", mlog.String("workspaceID", notice.WorkspaceID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteWorkspaceRetentionNotice removes the notice of the deletion of
+// the workspace. Removing a notice that doesn't exist isn't an error.
+func (s *SQLStore) DeleteWorkspaceRetentionNotice(ctx context.Context, workspaceID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "workspace_retention_notices").
+		Where(sq.Eq{"workspace_id": workspaceID})
+
+	if _, err := query.ExecContext(ctx); err != nil {
+		s.logger.Error("ERROR DeleteWorkspaceRetentionNotice", mlog.String("workspaceID", workspaceID), mlog.Err(err))
+		return err
+	}
+	return nil
+}
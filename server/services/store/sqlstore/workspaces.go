@@ -1,6 +1,7 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -19,7 +20,7 @@ var (
 	errUnsupportedDatabaseError = errors.New("method is unsupported on current database. Supported databases are - MySQL and PostgreSQL")
 )
 
-func (s *SQLStore) UpsertWorkspaceSignupToken(workspace model.Workspace) error {
+func (s *SQLStore) UpsertWorkspaceSignupToken(ctx context.Context, workspace model.Workspace) error {
 	now := time.Now().Unix()
 
 	query := s.getQueryBuilder().
@@ -29,12 +30,14 @@ func (s *SQLStore) UpsertWorkspaceSignupToken(workspace model.Workspace) error {
 			"signup_token",
 			"modified_by",
 			"update_at",
+			"create_at",
 		).
 		Values(
 			workspace.ID,
 			workspace.SignupToken,
 			workspace.ModifiedBy,
 			now,
+			now,
 		)
 	if s.dbType == mysqlDBType {
 		query = query.Suffix("ON DUPLICATE KEY UPDATE signup_token = ?, modified_by = ?, update_at = ?",
@@ -46,11 +49,11 @@ func (s *SQLStore) UpsertWorkspaceSignupToken(workspace model.Workspace) error {
 		)
 	}
 
-	_, err := query.Exec()
+	_, err := query.ExecContext(ctx)
 	return err
 }
 
-func (s *SQLStore) UpsertWorkspaceSettings(workspace model.Workspace) error {
+func (s *SQLStore) UpsertWorkspaceSettings(ctx context.Context, workspace model.Workspace) error {
 	now := time.Now().Unix()
 	signupToken := utils.CreateGUID()
 
@@ -67,6 +70,7 @@ func (s *SQLStore) UpsertWorkspaceSettings(workspace model.Workspace) error {
 			"settings",
 			"modified_by",
 			"update_at",
+			"create_at",
 		).
 		Values(
 			workspace.ID,
@@ -74,6 +78,7 @@ func (s *SQLStore) UpsertWorkspaceSettings(workspace model.Workspace) error {
 			settingsJSON,
 			workspace.ModifiedBy,
 			now,
+			now,
 		)
 	if s.dbType == mysqlDBType {
 		query = query.Suffix("ON DUPLICATE KEY UPDATE settings = ?, modified_by = ?, update_at = ?", settingsJSON, workspace.ModifiedBy, now)
@@ -84,14 +89,14 @@ func (s *SQLStore) UpsertWorkspaceSettings(workspace model.Workspace) error {
 		)
 	}
 
-	_, err = query.Exec()
+	_, err = query.ExecContext(ctx)
 	return err
 }
 
-func (s *SQLStore) GetWorkspace(id string) (*model.Workspace, error) {
+func (s *SQLStore) GetWorkspace(ctx context.Context, id string) (*model.Workspace, error) {
 	var settingsJSON string
 
-	query := s.getQueryBuilder().
+	query := s.getReadQueryBuilder(ctx).
 		Select(
 			"id",
 			"signup_token",
@@ -101,7 +106,7 @@ func (s *SQLStore) GetWorkspace(id string) (*model.Workspace, error) {
 		).
 		From(s.tablePrefix + "workspaces").
 		Where(sq.Eq{"id": id})
-	row := query.QueryRow()
+	row := query.QueryRowContext(ctx)
 	workspace := model.Workspace{}
 
 	err := row.Scan(
@@ -124,18 +129,284 @@ func (s *SQLStore) GetWorkspace(id string) (*model.Workspace, error) {
 	return &workspace, nil
 }
 
-func (s *SQLStore) HasWorkspaceAccess(userID string, workspaceID string) (bool, error) {
-	return true, nil
+// GetWorkspaces returns the workspaces modified after modifiedSince,
+// ordered by update_at and id. Workspaces modified exactly at
+// modifiedSince are excluded. A non positive limit returns all of them.
+func (s *SQLStore) GetWorkspaces(ctx context.Context, modifiedSince int64, limit int) ([]*model.Workspace, error) {
+	query := s.getQueryBuilder().
+		Select(
+			"id",
+			"signup_token",
+			"COALESCE(settings, '{}')",
+			"modified_by",
+			"update_at",
+		).
+		From(s.tablePrefix + "workspaces").
+		Where(sq.Gt{"update_at": modifiedSince}).
+		OrderBy("update_at", "id")
+
+	if limit > 0 {
+		query = query.Limit(uint64(limit))
+	}
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR GetWorkspaces", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
+
+	return s.workspacesFromRows(rows)
 }
 
-func (s *SQLStore) GetWorkspaceCount() (int64, error) {
+func (s *SQLStore) workspacesFromRows(rows *sql.Rows) ([]*model.Workspace, error) {
+	workspaces := []*model.Workspace{}
+
+	for rows.Next() {
+		var workspace model.Workspace
+		var settingsJSON string
+
+		err := rows.Scan(
+			&workspace.ID,
+			&workspace.SignupToken,
+			&settingsJSON,
+			&workspace.ModifiedBy,
+			&workspace.UpdateAt,
+		)
+		if err != nil {
+			s.logger.Error("ERROR workspacesFromRows", mlog.Err(err))
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(settingsJSON), &workspace.Settings)
+		if err != nil {
+			s.logger.Error(`ERROR workspacesFromRows settings json.Unmarshal`, mlog.Err(err))
+			return nil, err
+		}
+
+		workspaces = append(workspaces, &workspace)
+	}
+
+	return workspaces, nil
+}
+
+// HasWorkspaceAccess returns true if the user is an active member of
+// the workspace. The standalone guests only have access to the
+// workspaces where they are a member of a board. A denied access is
+// reported as false with no error.
+func (s *SQLStore) HasWorkspaceAccess(ctx context.Context, userID string, workspaceID string) (bool, error) {
+	var query sq.SelectBuilder
+
+	switch {
+	case workspaceID == "0":
+		// every active user has access to the root workspace
+		query = s.activeUserQuery(userID, workspaceID)
+	case s.isPlugin:
+		// workspaces are backed by channels in plugin mode
+		query = s.getQueryBuilder().
+			Select("COUNT(*)").
+			From("ChannelMembers").
+			Join("Users ON Users.Id = ChannelMembers.UserId").
+			Where(sq.Eq{"ChannelMembers.ChannelId": workspaceID}).
+			Where(sq.Eq{"ChannelMembers.UserId": userID}).
+			Where(sq.Eq{"Users.DeleteAt": 0})
+	default:
+		query = s.getQueryBuilder().
+			Select("COUNT(*)").
+			From(s.tablePrefix + "workspace_members AS wm").
+			Join(s.tablePrefix + "users AS u ON u.id = wm.user_id").
+			Where(sq.Eq{"wm.workspace_id": workspaceID}).
+			Where(sq.Eq{"wm.user_id": userID}).
+			Where(sq.Eq{"u.delete_at": 0}).
+			Where(s.guestBoardCondition("u", workspaceID))
+	}
+
+	var count int
+	if err := query.QueryRowContext(ctx).Scan(&count); err != nil {
+		s.logger.Error("ERROR HasWorkspaceAccess", mlog.String("workspaceID", workspaceID), mlog.Err(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *SQLStore) activeUserQuery(userID, workspaceID string) sq.SelectBuilder {
+	if s.isPlugin {
+		return s.getQueryBuilder().
+			Select("COUNT(*)").
+			From("Users").
+			Where(sq.Eq{"Id": userID}).
+			Where(sq.Eq{"DeleteAt": 0})
+	}
+
+	return s.getQueryBuilder().
+		Select("COUNT(*)").
+		From(s.tablePrefix + "users AS u").
+		Where(sq.Eq{"u.id": userID}).
+		Where(sq.Eq{"u.delete_at": 0}).
+		Where(s.guestBoardCondition("u", workspaceID))
+}
+
+// guestBoardCondition returns the SQL condition that matches the users of
+// the table who aren't guests, or who are a member of a board of the
+// workspace.
+func (s *SQLStore) guestBoardCondition(usersTable, workspaceID string) sq.Sqlizer {
+	return sq.Expr(
+		"(NOT COALESCE("+usersTable+".is_guest, FALSE) OR EXISTS (SELECT 1 FROM "+s.tablePrefix+"board_members AS bm "+
+			"WHERE bm.user_id = "+usersTable+".id AND bm.workspace_id = ?))",
+		workspaceID,
+	)
+}
+
+// SharesWorkspace returns true if the user is active and has access to
+// a workspace that the other user, active or not, has access to.
+func (s *SQLStore) SharesWorkspace(ctx context.Context, userID, otherUserID string) (bool, error) {
+	var query sq.SelectBuilder
+
+	if s.isPlugin {
+		// every active user has access to the root workspace
+		query = s.activeUserQuery(userID, "0")
+	} else {
+		// a workspace member is a guest of the workspace when it's a
+		// member of one of its boards
+		memberCondition := func(usersTable string) string {
+			return "(NOT COALESCE(" + usersTable + ".is_guest, FALSE) OR EXISTS (SELECT 1 FROM " + s.tablePrefix + "board_members AS bm " +
+				"WHERE bm.user_id = " + usersTable + ".id AND bm.workspace_id = wm.workspace_id))"
+		}
+
+		query = s.getQueryBuilder().
+			Select("COUNT(*)").
+			From(s.tablePrefix+"users AS u").
+			Join(s.tablePrefix+"users AS other ON other.id = ?", otherUserID).
+			Where(sq.Eq{"u.id": userID}).
+			Where(sq.Eq{"u.delete_at": 0}).
+			Where(sq.Or{
+				sq.And{s.guestBoardCondition("u", "0"), s.guestBoardCondition("other", "0")},
+				sq.Expr("EXISTS (SELECT 1 FROM " + s.tablePrefix + "workspace_members AS wm " +
+					"JOIN " + s.tablePrefix + "workspace_members AS other_wm ON other_wm.workspace_id = wm.workspace_id " +
+					"WHERE wm.user_id = u.id AND other_wm.user_id = other.id " +
+					"AND " + memberCondition("u") + " AND " + memberCondition("other") + ")"),
+			})
+	}
+
+	var count int
+	if err := query.QueryRowContext(ctx).Scan(&count); err != nil {
+		s.logger.Error("ERROR SharesWorkspace", mlog.String("userID", userID), mlog.Err(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// AddWorkspaceMember grants a user access to a standalone workspace.
+func (s *SQLStore) AddWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	query := s.getQueryBuilder().
+		Insert(s.tablePrefix+"workspace_members").
+		Columns("workspace_id", "user_id", "create_at").
+		Values(workspaceID, userID, utils.GetMillis())
+	if s.dbType == mysqlDBType {
+		query = query.Suffix("ON DUPLICATE KEY UPDATE user_id = user_id")
+	} else {
+		query = query.Suffix("ON CONFLICT (workspace_id, user_id) DO NOTHING")
+	}
+
+	_, err := query.ExecContext(ctx)
+	return err
+}
+
+// RemoveWorkspaceMember revokes a user's access to a standalone workspace.
+func (s *SQLStore) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	query := s.getQueryBuilder().
+		Delete(s.tablePrefix + "workspace_members").
+		Where(sq.Eq{"workspace_id": workspaceID}).
+		Where(sq.Eq{"user_id": userID})
+
+	_, err := query.ExecContext(ctx)
+	return err
+}
+
+// DeleteWorkspace removes a workspace along with all its blocks,
+// block history, sharing entries and tokens, calendar feeds, and
+// members. Everything
+// is deleted in a single transaction, so a failure leaves the
+// workspace untouched.
+func (s *SQLStore) DeleteWorkspace(ctx context.Context, workspaceID string) error {
+	queries := []sq.DeleteBuilder{
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "blocks").
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "blocks_history").
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "sharing").
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "sharing_tokens").
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "calendar_feeds").
+			Where(sq.Eq{"COALESCE(workspace_id, '0')": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "github_integrations").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "github_issue_links").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "inbound_hooks").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "inbound_hook_deliveries").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "files").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "board_members").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "guest_invites").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "notifications").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "subscriptions").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "webhook_deliveries").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "workspace_members").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "workspace_retention_notices").
+			Where(sq.Eq{"workspace_id": workspaceID}),
+		s.getQueryBuilder().
+			Delete(s.tablePrefix + "workspaces").
+			Where(sq.Eq{"id": workspaceID}),
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, query := range queries {
+			if _, err := sq.ExecContextWith(ctx, tx, query); err != nil {
+				s.logger.Error("ERROR DeleteWorkspace", mlog.String("workspaceID", workspaceID), mlog.Err(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLStore) GetWorkspaceCount(ctx context.Context) (int64, error) {
 	query := s.getQueryBuilder().
 		Select(
 			"COUNT(*) AS count",
 		).
 		From(s.tablePrefix + "workspaces")
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error("ERROR GetWorkspaceCount", mlog.Err(err))
 		return 0, err
@@ -143,8 +414,9 @@ func (s *SQLStore) GetWorkspaceCount() (int64, error) {
 	defer s.CloseRows(rows)
 
 	var count int64
-
-	rows.Next()
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
 	err = rows.Scan(&count)
 	if err != nil {
 		s.logger.Error("Failed to fetch workspace count", mlog.Err(err))
@@ -153,41 +425,270 @@ func (s *SQLStore) GetWorkspaceCount() (int64, error) {
 	return count, nil
 }
 
-func (s *SQLStore) GetUserWorkspaces(userID string) ([]model.UserWorkspace, error) {
-	var query sq.SelectBuilder
+// GetWorkspaceStats returns the number of workspaces, of the active ones
+// and of the ones created by day until the time, in milliseconds. The
+// days are the UTC ones.
+func (s *SQLStore) GetWorkspaceStats(ctx context.Context, now int64) (*model.WorkspaceStats, error) {
+	total, err := s.GetWorkspaceCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := &model.WorkspaceStats{Total: total}
+
+	stats.Active7Days, err = s.countActiveWorkspaces(ctx, now-7*model.WorkspaceStatsDayMillis)
+	if err != nil {
+		return nil, err
+	}
+	stats.Active30Days, err = s.countActiveWorkspaces(ctx, now-30*model.WorkspaceStatsDayMillis)
+	if err != nil {
+		return nil, err
+	}
+
+	// the times of the workspaces are in seconds
+	const daySeconds = model.WorkspaceStatsDayMillis / 1000
+	firstDay := now - now%model.WorkspaceStatsDayMillis - (model.WorkspaceStatsDays-1)*model.WorkspaceStatsDayMillis
+	dayOf := sq.Expr("(create_at - ?) / ?", firstDay/1000, daySeconds)
+	if s.dbType == mysqlDBType {
+		dayOf = sq.Expr("(create_at - ?) DIV ?", firstDay/1000, daySeconds)
+	}
+	query := s.getReadQueryBuilder(ctx).
+		Select().
+		Column(sq.Alias(dayOf, "day")).
+		Column("COUNT(*)").
+		From(s.tablePrefix + "workspaces").
+		Where(sq.GtOrEq{"create_at": firstDay / 1000}).
+		GroupBy("day")
 
-	var nonTemplateFilter string
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR GetWorkspaceStats", mlog.Err(err))
+		return nil, err
+	}
+	defer s.CloseRows(rows)
 
+	created := map[int64]int64{}
+	for rows.Next() {
+		var day, count int64
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, err
+		}
+		created[day] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for day := int64(0); day < model.WorkspaceStatsDays; day++ {
+		stats.CreatedByDay = append(stats.CreatedByDay, model.DailyCount{
+			DayStart: firstDay + day*model.WorkspaceStatsDayMillis,
+			Count:    created[day],
+		})
+	}
+	return stats, nil
+}
+
+// countActiveWorkspaces returns the number of workspaces with blocks
+// updated since the time, in milliseconds. The index of the blocks on
+// their update time and workspace covers the query.
+func (s *SQLStore) countActiveWorkspaces(ctx context.Context, since int64) (int64, error) {
+	query := s.getReadQueryBuilder(ctx).
+		Select("COUNT(DISTINCT COALESCE(workspace_id, '0'))").
+		From(s.tablePrefix + "blocks").
+		Where(sq.GtOrEq{"update_at": since})
+
+	var count int64
+	if err := query.QueryRowContext(ctx).Scan(&count); err != nil {
+		s.logger.Error("ERROR countActiveWorkspaces", mlog.Err(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// templateBoardFilter returns the SQL condition that matches blocks
+// whose template flag in their fields is equal to isTemplate.
+func (s *SQLStore) templateBoardFilter(table string, isTemplate bool) (string, error) {
+	switch s.dbType {
+	case mysqlDBType, sqliteDBType:
+		// the bundled sqlite driver is built without the JSON1
+		// extension, so we match against the serialized fields
+		return fmt.Sprintf("%s.fields LIKE '%%\"isTemplate\":%t%%'", table, isTemplate), nil
+	case postgresDBType:
+		return fmt.Sprintf("%s.fields ->> 'isTemplate' = '%t'", table, isTemplate), nil
+	default:
+		return "", errUnsupportedDatabaseError
+	}
+}
+
+// nonArchivedBoardFilter returns the SQL condition that matches the
+// blocks that aren't archived boards, whose fields don't have the
+// archived flag set.
+func (s *SQLStore) nonArchivedBoardFilter(table string) (string, error) {
 	switch s.dbType {
-	case mysqlDBType:
-		nonTemplateFilter = "focalboard_blocks.fields LIKE %\"isTemplate\":false%"
+	case mysqlDBType, sqliteDBType:
+		return fmt.Sprintf("COALESCE(%s.fields, '') NOT LIKE '%%\"%s\":true%%'", table, model.BoardArchivedField), nil
 	case postgresDBType:
-		nonTemplateFilter = "focalboard_blocks.fields ->> 'isTemplate' = 'false'"
+		return fmt.Sprintf("COALESCE(%s.fields ->> '%s', 'false') <> 'true'", table, model.BoardArchivedField), nil
 	default:
-		return nil, fmt.Errorf("GetUserWorkspaces - %w", errUnsupportedDatabaseError)
+		return "", errUnsupportedDatabaseError
 	}
+}
 
-	query = s.getQueryBuilder().
-		Select("Channels.ID", "Channels.DisplayName", "COUNT(focalboard_blocks.id)").
+// GetUserWorkspaces returns a page of the workspaces the user belongs
+// to, ordered by ID. Only workspaces with an ID greater than the
+// cursor are returned, and the boolean result reports whether there
+// are more workspaces after the page. A non positive limit falls back
+// to model.UserWorkspacesDefaultPageSize. The standalone guests only
+// get the workspaces where they are a member of a board.
+func (s *SQLStore) GetUserWorkspaces(ctx context.Context, userID, cursor string, limit int) ([]model.UserWorkspace, bool, error) {
+	if limit <= 0 {
+		limit = model.UserWorkspacesDefaultPageSize
+	}
+
+	blocksTable := s.tablePrefix + "blocks"
+	nonTemplateFilter, err := s.templateBoardFilter(blocksTable, false)
+	if err != nil {
+		return nil, false, fmt.Errorf("GetUserWorkspaces - %w", err)
+	}
+	nonArchivedFilter, err := s.nonArchivedBoardFilter(blocksTable)
+	if err != nil {
+		return nil, false, fmt.Errorf("GetUserWorkspaces - %w", err)
+	}
+	// the archived boards aren't counted
+	boardFilter := nonTemplateFilter + " AND " + nonArchivedFilter
+
+	// standalone installations don't have the Channels and
+	// ChannelMembers tables, so in that case the workspaces are
+	// enumerated from our own tables
+	if !s.isPlugin {
+		user, err := s.GetUserByID(userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, false, fmt.Errorf("GetUserWorkspaces - %w", err)
+		}
+		if user != nil && user.IsGuest {
+			return s.getGuestUserWorkspaces(ctx, userID, cursor, limit, nonArchivedFilter)
+		}
+		return s.getStandaloneUserWorkspaces(ctx, userID, cursor, limit, boardFilter)
+	}
+
+	query := s.getReadQueryBuilder(ctx).
+		Select("Channels.ID", "Channels.DisplayName", "COUNT("+blocksTable+".id)").
 		From("ChannelMembers").
 		// select channels without a corresponding workspace
 		LeftJoin(
-			"focalboard_blocks ON focalboard_blocks.workspace_id = ChannelMembers.ChannelId AND "+
-				"focalboard_blocks.type = 'board' AND "+
-				nonTemplateFilter,
+			blocksTable+" ON "+blocksTable+".workspace_id = ChannelMembers.ChannelId AND "+
+				blocksTable+".type = 'board' AND "+
+				blocksTable+".delete_at = 0 AND "+
+				boardFilter,
 		).
 		Join("Channels ON ChannelMembers.ChannelId = Channels.Id").
 		Where(sq.Eq{"ChannelMembers.UserId": userID}).
-		GroupBy("Channels.Id", "Channels.DisplayName")
+		GroupBy("Channels.Id", "Channels.DisplayName").
+		OrderBy("Channels.Id").
+		// fetch an extra row to know if there is a next page
+		Limit(uint64(limit) + 1)
+
+	if cursor != "" {
+		query = query.Where(sq.Gt{"Channels.Id": cursor})
+	}
 
-	rows, err := query.Query()
+	rows, err := query.QueryContext(ctx)
 	if err != nil {
 		s.logger.Error("ERROR GetUserWorkspaces", mlog.Err(err))
-		return nil, err
+		return nil, false, err
+	}
+
+	defer s.CloseRows(rows)
+	return s.userWorkspacesPageFromRows(rows, limit)
+}
+
+// getStandaloneUserWorkspaces returns the workspaces registered in
+// the workspaces table plus any other workspace where the user has
+// created boards, along with the number of their boards matching the
+// filter.
+func (s *SQLStore) getStandaloneUserWorkspaces(ctx context.Context, userID, cursor string, limit int, boardFilter string) ([]model.UserWorkspace, bool, error) {
+	blocksTable := s.tablePrefix + "blocks"
+
+	workspaceIDs := s.getReadQueryBuilder(ctx).
+		Select("id AS workspace_id").
+		From(s.tablePrefix+"workspaces").
+		Suffix(
+			"UNION SELECT DISTINCT COALESCE(workspace_id, '0') FROM "+blocksTable+" WHERE type = 'board' AND created_by = ?",
+			userID,
+		)
+
+	query := s.getReadQueryBuilder(ctx).
+		Select("w.workspace_id", "''", "COUNT("+blocksTable+".id)").
+		FromSelect(workspaceIDs, "w").
+		LeftJoin(
+			blocksTable + " ON COALESCE(" + blocksTable + ".workspace_id, '0') = w.workspace_id AND " +
+				blocksTable + ".type = 'board' AND " +
+				blocksTable + ".delete_at = 0 AND " +
+				boardFilter,
+		).
+		GroupBy("w.workspace_id").
+		OrderBy("w.workspace_id").
+		Limit(uint64(limit) + 1)
+
+	if cursor != "" {
+		query = query.Where(sq.Gt{"w.workspace_id": cursor})
+	}
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR getStandaloneUserWorkspaces", mlog.Err(err))
+		return nil, false, err
 	}
 
 	defer s.CloseRows(rows)
-	return s.userWorkspacesFromRows(rows)
+	return s.userWorkspacesPageFromRows(rows, limit)
+}
+
+// getGuestUserWorkspaces returns the workspaces where the guest is a
+// member of at least one board matching the filter, along with the
+// number of these boards.
+func (s *SQLStore) getGuestUserWorkspaces(ctx context.Context, userID, cursor string, limit int, boardFilter string) ([]model.UserWorkspace, bool, error) {
+	blocksTable := s.tablePrefix + "blocks"
+
+	query := s.getReadQueryBuilder(ctx).
+		Select("bm.workspace_id", "''", "COUNT("+blocksTable+".id)").
+		From(s.tablePrefix+"board_members AS bm").
+		Join(
+			blocksTable+" ON "+blocksTable+".id = bm.board_id AND "+
+				"COALESCE("+blocksTable+".workspace_id, '0') = bm.workspace_id AND "+
+				blocksTable+".type = 'board' AND "+
+				blocksTable+".delete_at = 0 AND "+
+				boardFilter,
+		).
+		Where(sq.Eq{"bm.user_id": userID}).
+		GroupBy("bm.workspace_id").
+		OrderBy("bm.workspace_id").
+		Limit(uint64(limit) + 1)
+
+	if cursor != "" {
+		query = query.Where(sq.Gt{"bm.workspace_id": cursor})
+	}
+
+	rows, err := query.QueryContext(ctx)
+	if err != nil {
+		s.logger.Error("ERROR getGuestUserWorkspaces", mlog.Err(err))
+		return nil, false, err
+	}
+
+	defer s.CloseRows(rows)
+	return s.userWorkspacesPageFromRows(rows, limit)
+}
+
+func (s *SQLStore) userWorkspacesPageFromRows(rows *sql.Rows, limit int) ([]model.UserWorkspace, bool, error) {
+	userWorkspaces, err := s.userWorkspacesFromRows(rows)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(userWorkspaces) > limit {
+		return userWorkspaces[:limit], true, nil
+	}
+
+	return userWorkspaces, false, nil
 }
 
 func (s *SQLStore) userWorkspacesFromRows(rows *sql.Rows) ([]model.UserWorkspace, error) {
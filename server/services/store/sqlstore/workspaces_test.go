@@ -0,0 +1,224 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// setupPluginTests creates a store in plugin mode along with minimal
+// versions of the Mattermost tables it depends on.
+func setupPluginTests(t *testing.T) (*SQLStore, func()) {
+	logger := mlog.CreateConsoleTestLogger(false, mlog.LvlDebug)
+
+	sqlDB, err := sql.Open(sqliteDBType, ":memory:")
+	require.NoError(t, err)
+	// every connection to an in-memory database gets its own
+	// database, so a single connection is used
+	sqlDB.SetMaxOpenConns(1)
+
+	store, err := New(sqliteDBType, ":memory:", "test_", logger, sqlDB, true)
+	require.NoError(t, err)
+
+	_, err = sqlDB.Exec(`CREATE TABLE Channels (Id VARCHAR(26) PRIMARY KEY, DisplayName VARCHAR(64))`)
+	require.NoError(t, err)
+	_, err = sqlDB.Exec(`CREATE TABLE ChannelMembers (ChannelId VARCHAR(26), UserId VARCHAR(26), PRIMARY KEY (ChannelId, UserId))`)
+	require.NoError(t, err)
+
+	tearDown := func() {
+		defer func() { _ = logger.Shutdown() }()
+		require.NoError(t, store.Shutdown())
+	}
+
+	return store, tearDown
+}
+
+func TestGetUserWorkspacesPagination(t *testing.T) {
+	ctx := context.Background()
+	store, tearDown := setupPluginTests(t)
+	defer tearDown()
+
+	userID := "user-id-1"
+	memberships := 500
+
+	expectedIDs := []string{}
+	for i := 0; i < memberships; i++ {
+		channelID := fmt.Sprintf("channel-%03d", i)
+		expectedIDs = append(expectedIDs, channelID)
+
+		_, err := store.db.Exec(`INSERT INTO Channels (Id, DisplayName) VALUES ($1, $2)`, channelID, "Channel "+channelID)
+		require.NoError(t, err)
+		_, err = store.db.Exec(`INSERT INTO ChannelMembers (ChannelId, UserId) VALUES ($1, $2)`, channelID, userID)
+		require.NoError(t, err)
+	}
+
+	// a channel the user is not a member of
+	_, err := store.db.Exec(`INSERT INTO Channels (Id, DisplayName) VALUES ('foreign-channel', 'Foreign')`)
+	require.NoError(t, err)
+	_, err = store.db.Exec(`INSERT INTO ChannelMembers (ChannelId, UserId) VALUES ('foreign-channel', 'another-user-id')`)
+	require.NoError(t, err)
+
+	t.Run("Default page size", func(t *testing.T) {
+		userWorkspaces, hasMore, err := store.GetUserWorkspaces(ctx, userID, "", 0)
+		require.NoError(t, err)
+		require.True(t, hasMore)
+		require.Len(t, userWorkspaces, model.UserWorkspacesDefaultPageSize)
+	})
+
+	t.Run("Pages are stable and cover every membership", func(t *testing.T) {
+		gotIDs := []string{}
+		cursor := ""
+		pages := 0
+		for {
+			userWorkspaces, hasMore, err := store.GetUserWorkspaces(ctx, userID, cursor, 75)
+			require.NoError(t, err)
+			pages++
+
+			for _, userWorkspace := range userWorkspaces {
+				gotIDs = append(gotIDs, userWorkspace.ID)
+			}
+
+			if !hasMore {
+				break
+			}
+			cursor = userWorkspaces[len(userWorkspaces)-1].ID
+		}
+
+		require.Equal(t, 7, pages)
+		require.Equal(t, expectedIDs, gotIDs)
+	})
+
+	t.Run("Requesting the same page twice returns the same result", func(t *testing.T) {
+		first, _, err := store.GetUserWorkspaces(ctx, userID, "channel-199", 50)
+		require.NoError(t, err)
+		second, _, err := store.GetUserWorkspaces(ctx, userID, "channel-199", 50)
+		require.NoError(t, err)
+		require.Equal(t, first, second)
+		require.Equal(t, "channel-200", first[0].ID)
+	})
+}
+
+func TestHasWorkspaceAccessDeactivatedUser(t *testing.T) {
+	ctx := context.Background()
+	store, tearDown := SetupTests(t)
+	defer tearDown()
+	sqlStore := store.(*SQLStore)
+
+	workspaceID := "workspace-id-1"
+	user := &model.User{
+		ID:       "deactivated-user-id",
+		Username: "deactivated",
+		Email:    "deactivated@example.com",
+	}
+	require.NoError(t, sqlStore.CreateUser(user))
+	require.NoError(t, sqlStore.AddWorkspaceMember(ctx, workspaceID, user.ID))
+
+	hasAccess, err := sqlStore.HasWorkspaceAccess(ctx, user.ID, workspaceID)
+	require.NoError(t, err)
+	require.True(t, hasAccess)
+
+	// the store API doesn't allow deactivating users, so the
+	// column is updated directly
+	_, err = sqlStore.getQueryBuilder().
+		Update(sqlStore.tablePrefix+"users").
+		Set("delete_at", time.Now().Unix()).
+		Where("id = ?", user.ID).
+		Exec()
+	require.NoError(t, err)
+
+	t.Run("Deactivated member", func(t *testing.T) {
+		hasAccess, err := sqlStore.HasWorkspaceAccess(ctx, user.ID, workspaceID)
+		require.NoError(t, err)
+		require.False(t, hasAccess)
+	})
+
+	t.Run("Deactivated user in the root workspace", func(t *testing.T) {
+		hasAccess, err := sqlStore.HasWorkspaceAccess(ctx, user.ID, "0")
+		require.NoError(t, err)
+		require.False(t, hasAccess)
+	})
+}
+
+func TestDeleteWorkspaceRollback(t *testing.T) {
+	ctx := context.Background()
+	s, tearDown := SetupTests(t)
+	defer tearDown()
+	sqlStore := s.(*SQLStore)
+
+	container := store.Container{WorkspaceID: "workspace-id-1"}
+	block := model.Block{ID: "board-id", RootID: "board-id", Type: "board"}
+	require.NoError(t, sqlStore.InsertBlock(ctx, container, &block, "user-id-1"))
+
+	// make one of the cascading deletes fail
+	_, err := sqlStore.db.Exec("DROP TABLE " + sqlStore.tablePrefix + "workspace_members")
+	require.NoError(t, err)
+
+	err = sqlStore.DeleteWorkspace(ctx, container.WorkspaceID)
+	require.Error(t, err)
+
+	blocks, err := sqlStore.GetAllBlocks(ctx, container)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+}
+
+func TestGetWorkspacesModifiedSince(t *testing.T) {
+	ctx := context.Background()
+	s, tearDown := SetupTests(t)
+	defer tearDown()
+	sqlStore := s.(*SQLStore)
+
+	updateAts := map[string]int64{
+		"workspace-1": 100,
+		"workspace-3": 200,
+		"workspace-2": 200,
+		"workspace-4": 300,
+	}
+	for id, updateAt := range updateAts {
+		require.NoError(t, sqlStore.UpsertWorkspaceSignupToken(ctx, model.Workspace{ID: id, SignupToken: "token"}))
+
+		_, err := sqlStore.getQueryBuilder().
+			Update(sqlStore.tablePrefix+"workspaces").
+			Set("update_at", updateAt).
+			Where("id = ?", id).
+			Exec()
+		require.NoError(t, err)
+	}
+
+	workspaceIDs := func(workspaces []*model.Workspace) []string {
+		ids := []string{}
+		for _, workspace := range workspaces {
+			ids = append(ids, workspace.ID)
+		}
+		return ids
+	}
+
+	testCases := []struct {
+		name          string
+		modifiedSince int64
+		limit         int
+		expected      []string
+	}{
+		{"all workspaces", 0, 0, []string{"workspace-1", "workspace-2", "workspace-3", "workspace-4"}},
+		{"just before a timestamp", 99, 0, []string{"workspace-1", "workspace-2", "workspace-3", "workspace-4"}},
+		{"exactly equal is excluded", 100, 0, []string{"workspace-2", "workspace-3", "workspace-4"}},
+		{"ties are ordered by id", 199, 2, []string{"workspace-2", "workspace-3"}},
+		{"exactly equal to the last one", 300, 0, []string{}},
+		{"limit", 0, 1, []string{"workspace-1"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			workspaces, err := sqlStore.GetWorkspaces(ctx, tc.modifiedSince, tc.limit)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, workspaceIDs(workspaces))
+		})
+	}
+}
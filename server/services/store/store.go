@@ -1,7 +1,12 @@
 //go:generate mockgen --build_flags=--mod=mod -destination=mockstore/mockstore.go -package mockstore . Store
 package store
 
-import "github.com/mattermost/focalboard/server/model"
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mattermost/focalboard/server/model"
+)
 
 // Conainer represents a container in a store
 // Using a struct to make extending this easier in the future.
@@ -11,23 +16,56 @@ type Container struct {
 
 // Store represents the abstraction of the data storage.
 type Store interface {
-	GetBlocksWithParentAndType(c Container, parentID string, blockType string) ([]model.Block, error)
-	GetBlocksWithParent(c Container, parentID string) ([]model.Block, error)
-	GetBlocksWithRootID(c Container, rootID string) ([]model.Block, error)
-	GetBlocksWithType(c Container, blockType string) ([]model.Block, error)
-	GetSubTree2(c Container, blockID string) ([]model.Block, error)
-	GetSubTree3(c Container, blockID string) ([]model.Block, error)
-	GetAllBlocks(c Container) ([]model.Block, error)
-	GetRootID(c Container, blockID string) (string, error)
-	GetParentID(c Container, blockID string) (string, error)
-	InsertBlock(c Container, block *model.Block, userID string) error
-	DeleteBlock(c Container, blockID string, modifiedBy string) error
-	GetBlockCountsByType() (map[string]int64, error)
-	GetBlock(c Container, blockID string) (*model.Block, error)
-	PatchBlock(c Container, blockID string, blockPatch *model.BlockPatch, userID string) error
+	GetBlocksWithParentAndType(ctx context.Context, c Container, parentID string, blockType string) ([]model.Block, error)
+	StreamBlocksWithParentAndType(ctx context.Context, c Container, parentID string, blockType string, fn func(block model.Block) error) error
+	StreamAllBlocks(ctx context.Context, c Container, fn func(block model.Block) error) error
+	GetBlocksWithParent(ctx context.Context, c Container, parentID string) ([]model.Block, error)
+	GetBlocksWithRootID(ctx context.Context, c Container, rootID string) ([]model.Block, error)
+	GetBlocksWithType(ctx context.Context, c Container, blockType string) ([]model.Block, error)
+	GetSubTree2(ctx context.Context, c Container, blockID string) ([]model.Block, error)
+	GetSubTree3(ctx context.Context, c Container, blockID string) ([]model.Block, error)
+	GetAllBlocks(ctx context.Context, c Container) ([]model.Block, error)
+	GetBlocksDigest(ctx context.Context, c Container, opts model.QueryBlocksDigestOptions) (*model.BlocksDigest, error)
+	GetBlocksPage(ctx context.Context, c Container, opts model.QueryBlocksPageOptions) (*model.BlocksPage, error)
+	GetRootID(ctx context.Context, c Container, blockID string) (string, error)
+	GetParentID(ctx context.Context, c Container, blockID string) (string, error)
+	InsertBlock(ctx context.Context, c Container, block *model.Block, userID string) error
+	InsertBlocks(ctx context.Context, c Container, blocks []model.Block, userID string) (*model.BlocksUpsertResult, error)
+	DeleteBlock(ctx context.Context, c Container, blockID string, modifiedBy string) error
+	RestoreBlock(ctx context.Context, c Container, blockID string, modifiedBy string) error
+	GetDeletedBlocks(ctx context.Context, c Container, since int64) ([]model.Block, error)
+	GetReferencedFileIDs(ctx context.Context, c Container) ([]string, error)
+	PurgeDeletedBlocks(ctx context.Context, deletedBefore int64) (int64, error)
+	GetBlockCountsByType(ctx context.Context) (map[string]int64, error)
+	GetBlock(ctx context.Context, c Container, blockID string) (*model.Block, error)
+	GetBlocksByIDs(ctx context.Context, c Container, blockIDs []string) ([]model.Block, error)
+	GetBlockWorkspaceIDs(ctx context.Context, blockIDs []string) (map[string][]string, error)
+	GetFilteredBoardBlocks(ctx context.Context, c Container, boardID string, filter *model.FilterGroup) ([]model.Block, error)
+	GetBlockHistory(ctx context.Context, c Container, blockID string, opts model.QueryBlockHistoryOptions) ([]model.Block, error)
+	GetCardActivity(ctx context.Context, c Container, cardID string, limit int, before int64) ([]model.BlockHistoryEntry, bool, error)
+	GetCardBacklinks(ctx context.Context, c Container, cardID string) ([]model.Block, error)
+	GetCardByNumber(ctx context.Context, c Container, boardID string, number int64) (*model.Block, error)
+	SearchBlocks(ctx context.Context, c Container, query string, limit int) ([]model.BlockSearchResult, error)
+	PatchBlock(ctx context.Context, c Container, blockID string, blockPatch *model.BlockPatch, userID string) error
+	GetTemplateBoards(ctx context.Context, c Container) ([]model.BoardTemplate, error)
+	GetBoardMetadata(ctx context.Context, c Container, boardID string) ([]model.CardMetadata, error)
+	GetChecklistProgress(ctx context.Context, c Container, boardID string) ([]model.ChecklistProgress, error)
+	CountCardsByProperty(ctx context.Context, c Container, boardID, propertyID string) ([]model.PropertyValueCount, error)
+	CountCardsCreatedByWeek(ctx context.Context, c Container, boardID string, since int64) (map[int64]int64, error)
+	CountCardsCompletedByWeek(ctx context.Context, c Container, boardID, propertyID, optionID string, since int64) (map[int64]int64, error)
+
+	// BeginTx starts a transaction, and returns a store bound to it
+	BeginTx(ctx context.Context) (Tx, error)
 
 	Shutdown() error
 
+	Ping(ctx context.Context) error
+	GetSchemaVersion(ctx context.Context) (int64, error)
+	GetPendingMigrations(ctx context.Context) ([]model.Migration, error)
+	GetAppliedMigrations(ctx context.Context) ([]model.Migration, error)
+	GetDBStats() sql.DBStats
+	CountOrphanedBlocks(ctx context.Context) (int64, error)
+
 	GetSystemSettings() (map[string]string, error)
 	SetSystemSetting(key, value string) error
 
@@ -35,27 +73,147 @@ type Store interface {
 	GetUserByID(userID string) (*model.User, error)
 	GetUserByEmail(email string) (*model.User, error)
 	GetUserByUsername(username string) (*model.User, error)
+	GetUserByAuthData(authData string) (*model.User, error)
 	CreateUser(user *model.User) error
 	UpdateUser(user *model.User) error
 	UpdateUserPassword(username, password string) error
 	UpdateUserPasswordByID(userID, password string) error
+	UpdateUserMfa(userID, mfaSecret string, mfaActive bool) error
+	UpdateUserAuthData(userID, authData string) error
+	UpdateUserActive(userID string, active bool) error
+	GetUsers(opts model.QueryUsersOptions) ([]*model.User, error)
 	GetUsersByWorkspace(workspaceID string) ([]*model.User, error)
+	SearchUsersByWorkspace(ctx context.Context, workspaceID, prefix string, limit int) ([]model.UserSearchResult, error)
 
 	GetActiveUserCount(updatedSecondsAgo int64) (int, error)
-	GetSession(token string, expireTime int64) (*model.Session, error)
+	GetSession(token string) (*model.Session, error)
 	CreateSession(session *model.Session) error
 	RefreshSession(session *model.Session) error
 	UpdateSession(session *model.Session) error
 	DeleteSession(sessionID string) error
-	CleanUpSessions(expireTime int64) error
+	DeleteSessionsForUser(userID string) error
+	DeleteExpiredSessions() error
+	SetLegacySessionsExpireAt(expireAt int64) error
+
+	CreateAccessToken(token model.AccessToken) error
+	GetAccessTokenByHash(tokenHash string) (*model.AccessToken, error)
+	GetAccessTokensForUser(userID string) ([]model.AccessToken, error)
+	UpdateAccessTokenLastUsed(tokenID string, lastUsedAt int64) error
+	DeleteAccessToken(userID string, tokenID string) error
+
+	GetLoginAttempts(key string) (*model.LoginAttempts, error)
+	UpsertLoginAttempts(attempts model.LoginAttempts) error
+	DeleteLoginAttempts(key string) error
+	DeleteStaleLoginAttempts(before int64) error
+
+	CreatePasswordResetToken(token model.PasswordResetToken) error
+	ConsumePasswordResetToken(tokenHash string) (*model.PasswordResetToken, error)
+	DeletePasswordResetTokensForUser(userID string) error
+	DeleteExpiredPasswordResetTokens(now int64) error
+
+	CreateGuestInvite(invite model.GuestInvite) error
+	ConsumeGuestInvite(tokenHash string) (*model.GuestInvite, error)
+	DeleteExpiredGuestInvites(now int64) error
+
+	CreateIdempotencyKey(key model.IdempotencyKey) error
+	GetIdempotencyKey(userID, key string) (*model.IdempotencyKey, error)
+	SetIdempotencyKeyResponse(userID, key string, statusCode int, response string) error
+	DeleteIdempotencyKey(userID, key string, createAt int64) error
+	DeleteExpiredIdempotencyKeys(now int64) error
+
+	SetMfaRecoveryCodes(userID string, codeHashes []string) error
+	ConsumeMfaRecoveryCode(userID, codeHash string) error
+	DeleteMfaRecoveryCodes(userID string) error
 
 	UpsertSharing(c Container, sharing model.Sharing) error
 	GetSharing(c Container, rootID string) (*model.Sharing, error)
+	GetSharingTokens(c Container, rootID string) ([]model.SharingToken, error)
+	RegenerateSharingToken(c Container, token model.SharingToken, previousToken string) error
+	RevokeSharingToken(c Container, rootID string, token string) error
+	GetCalendarFeed(c Container, boardID string) (*model.CalendarFeed, error)
+	UpsertCalendarFeed(c Container, feed model.CalendarFeed) error
+	DeleteCalendarFeed(c Container, boardID string) error
+
+	InsertWebhookDelivery(delivery model.WebhookDelivery) error
+	GetWebhookDeliveries(workspaceID string, limit int) ([]model.WebhookDelivery, error)
+
+	GetInboundHook(c Container, boardID string) (*model.InboundHook, error)
+	GetInboundHookByTokenHash(tokenHash string) (*model.InboundHook, error)
+	UpsertInboundHook(c Container, hook model.InboundHook) error
+	DeleteInboundHook(c Container, boardID string) error
+	InsertInboundHookDelivery(c Container, delivery model.InboundHookDelivery, keep int) error
+	GetInboundHookDeliveries(c Container, boardID string, limit int) ([]model.InboundHookDelivery, error)
+
+	GetGitHubIntegration(c Container, boardID string) (*model.GitHubIntegration, error)
+	GetGitHubIntegrations() ([]model.GitHubIntegration, error)
+	UpsertGitHubIntegration(c Container, integration model.GitHubIntegration) error
+	UpdateGitHubIntegrationSync(c Container, boardID string, lastSyncAt, rateLimitResetAt int64, lastError string) error
+	DeleteGitHubIntegration(c Container, boardID string) error
+	GetGitHubIssueLinks(c Container, boardID string) ([]model.GitHubIssueLink, error)
+	UpsertGitHubIssueLink(c Container, link model.GitHubIssueLink) error
+
+	InsertNotification(notification model.Notification) error
+	GetNotifiedUserIDs(blockID string) ([]string, error)
+	GetNotificationsForUser(userID string, limit int) ([]model.Notification, error)
+
+	CreateSubscription(ctx context.Context, subscription model.Subscription) error
+	DeleteSubscription(ctx context.Context, c Container, blockID, subscriberID string) error
+	GetSubscriptions(ctx context.Context, c Container, blockIDs []string) ([]model.Subscription, error)
+	GetSubscriptionsForUser(ctx context.Context, c Container, subscriberID string) ([]model.Subscription, error)
+	ClaimSubscriptionNotification(ctx context.Context, blockID, subscriberID string, notifiedAt, notifiedBefore int64) (bool, error)
+	GetBoardSubscriptions(ctx context.Context) ([]model.Subscription, error)
+	GetLastDigestAt(ctx context.Context, userID string) (int64, error)
+	ClaimUserDigest(ctx context.Context, userID string, lastDigestAt, digestAt int64) (bool, error)
+
+	GetPreferences(ctx context.Context, userID string) ([]model.Preference, error)
+	UpsertPreferences(ctx context.Context, userID string, preferences []model.Preference) error
+	DeletePreferences(ctx context.Context, userID string, preferences []model.Preference) error
+
+	InsertAuditEntry(entry model.AuditEntry) error
+	GetAuditEntries(opts model.QueryAuditEntriesOptions) ([]model.AuditEntry, error)
+
+	InsertFileInfo(info model.FileInfo) error
+	DeleteFileInfo(fileID string) error
+	GetFileInfo(fileID string) (*model.FileInfo, error)
+	SetFileThumbnailPath(fileID, thumbnailPath string) error
+	GetWorkspaceUsage(workspaceID string) (*model.WorkspaceUsage, error)
+
+	GetBoardMembers(c Container, boardID string) ([]model.BoardMember, error)
+	GetBoardRolesForUser(c Container, userID string) (map[string]string, error)
+	UpsertBoardMember(c Container, member model.BoardMember) error
+	DeleteBoardMember(c Container, boardID, userID string) error
+
+	GetBoardWorkspaceIDs() ([]string, error)
+	InsertReminderSent(reminder model.ReminderSent) error
+	GetSentReminderUserIDs(cardID string, dueAt int64) ([]string, error)
+	GetLastRecurrenceRun(ctx context.Context, recurrenceID string) (int64, error)
+	InsertRecurrenceRun(ctx context.Context, run model.RecurrenceRun) error
+
+	AcquireClusterLock(name, owner string, expireAt int64) (bool, error)
+
+	UpsertWorkspaceSignupToken(ctx context.Context, workspace model.Workspace) error
+	UpsertWorkspaceSettings(ctx context.Context, workspace model.Workspace) error
+	GetWorkspace(ctx context.Context, ID string) (*model.Workspace, error)
+	HasWorkspaceAccess(ctx context.Context, userID string, workspaceID string) (bool, error)
+	SharesWorkspace(ctx context.Context, userID, otherUserID string) (bool, error)
+	AddWorkspaceMember(ctx context.Context, workspaceID, userID string) error
+	RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error
+	DeleteWorkspace(ctx context.Context, workspaceID string) error
+	GetWorkspaces(ctx context.Context, modifiedSince int64, limit int) ([]*model.Workspace, error)
+	GetWorkspaceCount(ctx context.Context) (int64, error)
+	GetWorkspaceStats(ctx context.Context, now int64) (*model.WorkspaceStats, error)
+	GetUserWorkspaces(ctx context.Context, userID, cursor string, limit int) ([]model.UserWorkspace, bool, error)
+
+	GetInactiveWorkspaces(ctx context.Context, inactiveSince int64) ([]model.InactiveWorkspace, error)
+	GetWorkspaceRetentionNotices(ctx context.Context) ([]model.WorkspaceRetentionNotice, error)
+	UpsertWorkspaceRetentionNotice(ctx context.Context, notice model.WorkspaceRetentionNotice) error
+	DeleteWorkspaceRetentionNotice(ctx context.Context, workspaceID string) error
+}
 
-	UpsertWorkspaceSignupToken(workspace model.Workspace) error
-	UpsertWorkspaceSettings(workspace model.Workspace) error
-	GetWorkspace(ID string) (*model.Workspace, error)
-	HasWorkspaceAccess(userID string, workspaceID string) (bool, error)
-	GetWorkspaceCount() (int64, error)
-	GetUserWorkspaces(userID string) ([]model.UserWorkspace, error)
+// Tx is a store bound to a transaction. Its writes are applied together
+// when it's committed, and discarded when it's rolled back.
+type Tx interface {
+	Store
+	Commit() error
+	Rollback() error
 }
@@ -0,0 +1,112 @@
+package storetests
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestAccessTokenStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("CreateAndGetAccessTokens", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCreateAndGetAccessTokens(t, store)
+	})
+
+	t.Run("UpdateAccessTokenLastUsed", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testUpdateAccessTokenLastUsed(t, store)
+	})
+
+	t.Run("DeleteAccessToken", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteAccessToken(t, store)
+	})
+}
+
+func createTestAccessTokens(t *testing.T, store store.Store) []model.AccessToken {
+	tokens := []model.AccessToken{
+		{
+			ID:          "token-1",
+			UserID:      "user-1",
+			Description: "first",
+			TokenHash:   model.HashToken("fbp_first"),
+			CreateAt:    1000,
+		},
+		{
+			ID:          "token-2",
+			UserID:      "user-1",
+			Description: "second",
+			TokenHash:   model.HashToken("fbp_second"),
+			CreateAt:    2000,
+			ExpireAt:    5000,
+		},
+		{
+			ID:        "token-3",
+			UserID:    "user-2",
+			TokenHash: model.HashToken("fbp_third"),
+			CreateAt:  3000,
+		},
+	}
+	for _, token := range tokens {
+		require.NoError(t, store.CreateAccessToken(token))
+	}
+	return tokens
+}
+
+func testCreateAndGetAccessTokens(t *testing.T, store store.Store) {
+	tokens := createTestAccessTokens(t, store)
+
+	t.Run("GetAccessTokenByHash", func(t *testing.T) {
+		got, err := store.GetAccessTokenByHash(model.HashToken("fbp_second"))
+		require.NoError(t, err)
+		require.Equal(t, tokens[1], *got)
+
+		_, err = store.GetAccessTokenByHash(model.HashToken("fbp_unknown"))
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("GetAccessTokensForUser", func(t *testing.T) {
+		got, err := store.GetAccessTokensForUser("user-1")
+		require.NoError(t, err)
+		require.Equal(t, []model.AccessToken{tokens[1], tokens[0]}, got)
+
+		got, err = store.GetAccessTokensForUser("user-3")
+		require.NoError(t, err)
+		require.Empty(t, got)
+	})
+}
+
+func testUpdateAccessTokenLastUsed(t *testing.T, store store.Store) {
+	createTestAccessTokens(t, store)
+
+	require.NoError(t, store.UpdateAccessTokenLastUsed("token-1", 4000))
+
+	got, err := store.GetAccessTokenByHash(model.HashToken("fbp_first"))
+	require.NoError(t, err)
+	require.Equal(t, int64(4000), got.LastUsedAt)
+}
+
+func testDeleteAccessToken(t *testing.T, store store.Store) {
+	createTestAccessTokens(t, store)
+
+	t.Run("the tokens of another user can't be deleted", func(t *testing.T) {
+		err := store.DeleteAccessToken("user-2", "token-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("delete a token", func(t *testing.T) {
+		require.NoError(t, store.DeleteAccessToken("user-1", "token-1"))
+
+		_, err := store.GetAccessTokenByHash(model.HashToken("fbp_first"))
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		err = store.DeleteAccessToken("user-1", "token-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
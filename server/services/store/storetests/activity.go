@@ -0,0 +1,97 @@
+package storetests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestActivity(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container := store.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("GetCardActivity", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetCardActivity(t, store, container)
+	})
+}
+
+func testGetCardActivity(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	insert := func(block model.Block) {
+		err := store.InsertBlock(ctx, container, &block, testUserID)
+		require.NoError(t, err)
+		// keep the versions apart, for their order to be deterministic
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	insert(model.Block{ID: "board-1", RootID: "board-1", Type: "board"})
+	insert(model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "Card"})
+	insert(model.Block{ID: "comment-1", ParentID: "card-1", RootID: "board-1", Type: "comment", Title: "Comment"})
+	insert(model.Block{ID: "card-2", ParentID: "board-1", RootID: "board-1", Type: "card"})
+	insert(model.Block{ID: "comment-2", ParentID: "card-2", RootID: "board-1", Type: "comment"})
+
+	// avoid violating the block_history composite primary key constraint
+	// with a quick update of the card
+	time.Sleep(1 * time.Second)
+	insert(model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "Renamed"})
+
+	t.Run("should return the versions of the card and its children", func(t *testing.T) {
+		entries, hasMore, err := store.GetCardActivity(ctx, container, "card-1", 10, 0)
+		require.NoError(t, err)
+		require.False(t, hasMore)
+		require.Len(t, entries, 3)
+
+		require.Equal(t, "card-1", entries[0].Block.ID)
+		require.Equal(t, "Renamed", entries[0].Block.Title)
+		require.NotNil(t, entries[0].Previous)
+		require.Equal(t, "Card", entries[0].Previous.Title)
+
+		require.Equal(t, "comment-1", entries[1].Block.ID)
+		require.Nil(t, entries[1].Previous)
+
+		require.Equal(t, "card-1", entries[2].Block.ID)
+		require.Equal(t, "Card", entries[2].Block.Title)
+		require.Nil(t, entries[2].Previous)
+	})
+
+	t.Run("should return the versions in pages", func(t *testing.T) {
+		entries, hasMore, err := store.GetCardActivity(ctx, container, "card-1", 2, 0)
+		require.NoError(t, err)
+		require.True(t, hasMore)
+		require.Len(t, entries, 2)
+		require.Equal(t, "comment-1", entries[1].Block.ID)
+
+		entries, hasMore, err = store.GetCardActivity(ctx, container, "card-1", 2, entries[1].Block.UpdateAt)
+		require.NoError(t, err)
+		require.False(t, hasMore)
+		require.Len(t, entries, 1)
+		require.Equal(t, "Card", entries[0].Block.Title)
+	})
+
+	t.Run("should include the deletions", func(t *testing.T) {
+		err := store.DeleteBlock(ctx, container, "comment-1", testUserID)
+		require.NoError(t, err)
+
+		entries, _, err := store.GetCardActivity(ctx, container, "card-1", 1, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, "comment-1", entries[0].Block.ID)
+		require.NotZero(t, entries[0].Block.DeleteAt)
+		require.NotNil(t, entries[0].Previous)
+		require.Equal(t, "Comment", entries[0].Previous.Title)
+	})
+
+	t.Run("should return no versions for an unknown card", func(t *testing.T) {
+		entries, hasMore, err := store.GetCardActivity(ctx, container, "card-3", 10, 0)
+		require.NoError(t, err)
+		require.False(t, hasMore)
+		require.Empty(t, entries)
+	})
+}
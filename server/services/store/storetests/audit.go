@@ -0,0 +1,95 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestAuditStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("InsertAndGetAuditEntries", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testInsertAndGetAuditEntries(t, store)
+	})
+}
+
+func testInsertAndGetAuditEntries(t *testing.T, store store.Store) {
+	entries := []model.AuditEntry{
+		{
+			ID:          "entry-1",
+			ActorID:     "user-1",
+			WorkspaceID: "workspace-1",
+			Action:      model.AuditActionDeleteBlock,
+			EntityID:    "block-1",
+			Payload:     map[string]interface{}{"title": "Board"},
+			CreateAt:    1000,
+		},
+		{
+			ID:       "entry-2",
+			ActorID:  "user-1",
+			Action:   model.AuditActionLogin,
+			EntityID: "user-1",
+			CreateAt: 2000,
+		},
+		{
+			ID:          "entry-3",
+			ActorID:     "user-2",
+			WorkspaceID: "workspace-1",
+			Action:      model.AuditActionDeleteBlock,
+			EntityID:    "block-2",
+			CreateAt:    3000,
+		},
+		{
+			ID:       "entry-4",
+			Action:   model.AuditActionLoginFailed,
+			Payload:  map[string]interface{}{"username": "unknown"},
+			CreateAt: 4000,
+		},
+	}
+	for _, entry := range entries {
+		require.NoError(t, store.InsertAuditEntry(entry))
+	}
+
+	ids := func(entries []model.AuditEntry) []string {
+		result := []string{}
+		for _, entry := range entries {
+			result = append(result, entry.ID)
+		}
+		return result
+	}
+
+	t.Run("all the entries, the most recent first", func(t *testing.T) {
+		got, err := store.GetAuditEntries(model.QueryAuditEntriesOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"entry-4", "entry-3", "entry-2", "entry-1"}, ids(got))
+		require.Equal(t, entries[0], got[3])
+		require.Equal(t, "unknown", got[0].Payload["username"])
+	})
+
+	t.Run("filtered entries", func(t *testing.T) {
+		got, err := store.GetAuditEntries(model.QueryAuditEntriesOptions{ActorID: "user-1"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"entry-2", "entry-1"}, ids(got))
+
+		got, err = store.GetAuditEntries(model.QueryAuditEntriesOptions{WorkspaceID: "workspace-1", Action: model.AuditActionDeleteBlock})
+		require.NoError(t, err)
+		require.Equal(t, []string{"entry-3", "entry-1"}, ids(got))
+
+		got, err = store.GetAuditEntries(model.QueryAuditEntriesOptions{Since: 2000, Until: 3000})
+		require.NoError(t, err)
+		require.Equal(t, []string{"entry-3", "entry-2"}, ids(got))
+	})
+
+	t.Run("paginated entries", func(t *testing.T) {
+		got, err := store.GetAuditEntries(model.QueryAuditEntriesOptions{Page: 0, PerPage: 3})
+		require.NoError(t, err)
+		require.Equal(t, []string{"entry-4", "entry-3", "entry-2"}, ids(got))
+
+		got, err = store.GetAuditEntries(model.QueryAuditEntriesOptions{Page: 1, PerPage: 3})
+		require.NoError(t, err)
+		require.Equal(t, []string{"entry-1"}, ids(got))
+	})
+}
@@ -1,6 +1,9 @@
 package storetests
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -8,6 +11,7 @@ import (
 
 	"github.com/mattermost/focalboard/server/model"
 	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
 	"github.com/stretchr/testify/require"
 )
 
@@ -25,6 +29,11 @@ func StoreTestBlocksStore(t *testing.T, setup func(t *testing.T) (store.Store, f
 		defer tearDown()
 		testInsertBlock(t, store, container)
 	})
+	t.Run("InsertBlocks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testInsertBlocks(t, store, container)
+	})
 	t.Run("PatchBlock", func(t *testing.T) {
 		store, tearDown := setup(t)
 		defer tearDown()
@@ -35,6 +44,46 @@ func StoreTestBlocksStore(t *testing.T, setup func(t *testing.T) (store.Store, f
 		defer tearDown()
 		testDeleteBlock(t, store, container)
 	})
+	t.Run("RestoreBlock", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testRestoreBlock(t, store, container)
+	})
+	t.Run("GetDeletedBlocks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetDeletedBlocks(t, store, container)
+	})
+	t.Run("PurgeDeletedBlocks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testPurgeDeletedBlocks(t, store, container)
+	})
+	t.Run("GetBlockHistory", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBlockHistory(t, store, container)
+	})
+	t.Run("SearchBlocks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testSearchBlocks(t, store, container)
+	})
+	t.Run("GetTemplateBoards", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetTemplateBoards(t, store, container)
+	})
+	t.Run("GetBoardMetadata", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBoardMetadata(t, store, container)
+	})
+	t.Run("GetChecklistProgress", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetChecklistProgress(t, store, container)
+	})
 	t.Run("GetSubTree2", func(t *testing.T) {
 		store, tearDown := setup(t)
 		defer tearDown()
@@ -60,12 +109,68 @@ func StoreTestBlocksStore(t *testing.T, setup func(t *testing.T) (store.Store, f
 		defer tearDown()
 		testGetBlock(t, store, container)
 	})
+	t.Run("GetBlocksByIDs", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBlocksByIDs(t, store, container)
+	})
+	t.Run("GetBlockWorkspaceIDs", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBlockWorkspaceIDs(t, store, container)
+	})
+	t.Run("BlockVersions", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testBlockVersions(t, store, container)
+	})
+	t.Run("GetBlocksPage", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBlocksPage(t, store, container)
+	})
+	t.Run("GetFilteredBoardBlocks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetFilteredBoardBlocks(t, store, container)
+	})
+	t.Run("GetBoardWorkspaceIDs", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBoardWorkspaceIDs(t, store, container)
+	})
+	t.Run("StreamBlocksWithParentAndType", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testStreamBlocksWithParentAndType(t, store, container)
+	})
+	t.Run("StreamAllBlocks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testStreamAllBlocks(t, store, container)
+	})
+	t.Run("GetReferencedFileIDs", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetReferencedFileIDs(t, store, container)
+	})
+	t.Run("GetBlocksDigest", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBlocksDigest(t, store, container)
+	})
+	t.Run("CanceledContext", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCanceledContext(t, store, container)
+	})
 }
 
 func testInsertBlock(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
 	userID := testUserID
 
-	blocks, errBlocks := store.GetAllBlocks(container)
+	blocks, errBlocks := store.GetAllBlocks(ctx, container)
 	require.NoError(t, errBlocks)
 	initialCount := len(blocks)
 
@@ -76,10 +181,10 @@ func testInsertBlock(t *testing.T, store store.Store, container store.Container)
 			ModifiedBy: userID,
 		}
 
-		err := store.InsertBlock(container, &block, "user-id-1")
+		err := store.InsertBlock(ctx, container, &block, "user-id-1")
 		require.NoError(t, err)
 
-		blocks, err := store.GetAllBlocks(container)
+		blocks, err := store.GetAllBlocks(ctx, container)
 		require.NoError(t, err)
 		require.Len(t, blocks, initialCount+1)
 	})
@@ -91,10 +196,10 @@ func testInsertBlock(t *testing.T, store store.Store, container store.Container)
 			ModifiedBy: userID,
 		}
 
-		err := store.InsertBlock(container, &block, "user-id-1")
+		err := store.InsertBlock(ctx, container, &block, "user-id-1")
 		require.Error(t, err)
 
-		blocks, err := store.GetAllBlocks(container)
+		blocks, err := store.GetAllBlocks(ctx, container)
 		require.NoError(t, err)
 		require.Len(t, blocks, initialCount+1)
 	})
@@ -107,10 +212,10 @@ func testInsertBlock(t *testing.T, store store.Store, container store.Container)
 			Fields:     map[string]interface{}{"no-serialiable-value": t.Run},
 		}
 
-		err := store.InsertBlock(container, &block, "user-id-1")
+		err := store.InsertBlock(ctx, container, &block, "user-id-1")
 		require.Error(t, err)
 
-		blocks, err := store.GetAllBlocks(container)
+		blocks, err := store.GetAllBlocks(ctx, container)
 		require.NoError(t, err)
 		require.Len(t, blocks, initialCount+1)
 	})
@@ -120,7 +225,7 @@ func testInsertBlock(t *testing.T, store store.Store, container store.Container)
 			RootID: "root-id",
 		}
 
-		err := store.InsertBlock(container, &block, "user-id-2")
+		err := store.InsertBlock(ctx, container, &block, "user-id-2")
 		require.NoError(t, err)
 		require.Equal(t, "user-id-2", block.CreatedBy)
 	})
@@ -133,7 +238,7 @@ func testInsertBlock(t *testing.T, store store.Store, container store.Container)
 		}
 
 		// inserting
-		err := store.InsertBlock(container, &block, "user-id-2")
+		err := store.InsertBlock(ctx, container, &block, "user-id-2")
 		require.NoError(t, err)
 
 		// created by populated from user id for new blocks
@@ -150,7 +255,7 @@ func testInsertBlock(t *testing.T, store store.Store, container store.Container)
 			CreatedBy: "user-id-3",
 			Title:     "New Title",
 		}
-		err = store.InsertBlock(container, &newBlock, "user-id-4")
+		err = store.InsertBlock(ctx, container, &newBlock, "user-id-4")
 		require.NoError(t, err)
 		// created by is not altered for existing blocks
 		require.Equal(t, "user-id-3", newBlock.CreatedBy)
@@ -175,10 +280,10 @@ func testInsertBlock(t *testing.T, store store.Store, container store.Container)
 		}
 
 		// inserting
-		err := store.InsertBlock(container, &block, "user-id-1")
+		err := store.InsertBlock(ctx, container, &block, "user-id-1")
 		require.NoError(t, err)
 
-		retrievedBlock, err := store.GetBlock(container, "id-10")
+		retrievedBlock, err := store.GetBlock(ctx, container, "id-10")
 		assert.NoError(t, err)
 		assert.NotNil(t, retrievedBlock)
 		assert.Equal(t, "user-id-1", retrievedBlock.CreatedBy)
@@ -189,6 +294,7 @@ func testInsertBlock(t *testing.T, store store.Store, container store.Container)
 }
 
 func testPatchBlock(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
 	userID := testUserID
 
 	block := model.Block{
@@ -199,18 +305,18 @@ func testPatchBlock(t *testing.T, store store.Store, container store.Container)
 		Fields:     map[string]interface{}{"test": "test value", "test2": "test value 2"},
 	}
 
-	err := store.InsertBlock(container, &block, "user-id-1")
+	err := store.InsertBlock(ctx, container, &block, "user-id-1")
 	require.NoError(t, err)
 
-	blocks, errBlocks := store.GetAllBlocks(container)
+	blocks, errBlocks := store.GetAllBlocks(ctx, container)
 	require.NoError(t, errBlocks)
 	initialCount := len(blocks)
 
 	t.Run("not existing block", func(t *testing.T) {
-		err := store.PatchBlock(container, "invalid-block-id", &model.BlockPatch{}, "user-id-1")
+		err := store.PatchBlock(ctx, container, "invalid-block-id", &model.BlockPatch{}, "user-id-1")
 		require.Error(t, err)
 
-		blocks, err := store.GetAllBlocks(container)
+		blocks, err := store.GetAllBlocks(ctx, container)
 		require.NoError(t, err)
 		require.Len(t, blocks, initialCount)
 	})
@@ -221,10 +327,10 @@ func testPatchBlock(t *testing.T, store store.Store, container store.Container)
 			RootID: &wrongRootID,
 		}
 
-		err := store.PatchBlock(container, "id-test", &blockPatch, "user-id-1")
+		err := store.PatchBlock(ctx, container, "id-test", &blockPatch, "user-id-1")
 		require.Error(t, err)
 
-		blocks, err := store.GetAllBlocks(container)
+		blocks, err := store.GetAllBlocks(ctx, container)
 		require.NoError(t, err)
 		require.Len(t, blocks, initialCount)
 	})
@@ -234,10 +340,10 @@ func testPatchBlock(t *testing.T, store store.Store, container store.Container)
 			UpdatedFields: map[string]interface{}{"no-serialiable-value": t.Run},
 		}
 
-		err := store.PatchBlock(container, "id-test", &blockPatch, "user-id-1")
+		err := store.PatchBlock(ctx, container, "id-test", &blockPatch, "user-id-1")
 		require.Error(t, err)
 
-		blocks, err := store.GetAllBlocks(container)
+		blocks, err := store.GetAllBlocks(ctx, container)
 		require.NoError(t, err)
 		require.Len(t, blocks, initialCount)
 	})
@@ -252,10 +358,10 @@ func testPatchBlock(t *testing.T, store store.Store, container store.Container)
 		time.Sleep(1 * time.Millisecond)
 
 		// inserting
-		err := store.PatchBlock(container, "id-test", &blockPatch, "user-id-2")
+		err := store.PatchBlock(ctx, container, "id-test", &blockPatch, "user-id-2")
 		require.NoError(t, err)
 
-		retrievedBlock, err := store.GetBlock(container, "id-test")
+		retrievedBlock, err := store.GetBlock(ctx, container, "id-test")
 		require.NoError(t, err)
 
 		// created by populated from user id for new blocks
@@ -272,10 +378,10 @@ func testPatchBlock(t *testing.T, store store.Store, container store.Container)
 		time.Sleep(1 * time.Millisecond)
 
 		// inserting
-		err := store.PatchBlock(container, "id-test", &blockPatch, "user-id-2")
+		err := store.PatchBlock(ctx, container, "id-test", &blockPatch, "user-id-2")
 		require.NoError(t, err)
 
-		retrievedBlock, err := store.GetBlock(container, "id-test")
+		retrievedBlock, err := store.GetBlock(ctx, container, "id-test")
 		require.NoError(t, err)
 
 		// created by populated from user id for new blocks
@@ -294,10 +400,10 @@ func testPatchBlock(t *testing.T, store store.Store, container store.Container)
 		time.Sleep(1 * time.Millisecond)
 
 		// inserting
-		err := store.PatchBlock(container, "id-test", &blockPatch, "user-id-2")
+		err := store.PatchBlock(ctx, container, "id-test", &blockPatch, "user-id-2")
 		require.NoError(t, err)
 
-		retrievedBlock, err := store.GetBlock(container, "id-test")
+		retrievedBlock, err := store.GetBlock(ctx, container, "id-test")
 		require.NoError(t, err)
 
 		// created by populated from user id for new blocks
@@ -349,19 +455,20 @@ var (
 )
 
 func testGetSubTree2(t *testing.T, store store.Store, container store.Container) {
-	blocks, err := store.GetAllBlocks(container)
+	ctx := context.Background()
+	blocks, err := store.GetAllBlocks(ctx, container)
 	require.NoError(t, err)
 	initialCount := len(blocks)
 
 	InsertBlocks(t, store, container, subtreeSampleBlocks, "user-id-1")
 	defer DeleteBlocks(t, store, container, subtreeSampleBlocks, "test")
 
-	blocks, err = store.GetAllBlocks(container)
+	blocks, err = store.GetAllBlocks(ctx, container)
 	require.NoError(t, err)
 	require.Len(t, blocks, initialCount+6)
 
 	t.Run("from root id", func(t *testing.T) {
-		blocks, err = store.GetSubTree2(container, "parent")
+		blocks, err = store.GetSubTree2(ctx, container, "parent")
 		require.NoError(t, err)
 		require.Len(t, blocks, 3)
 		require.True(t, ContainsBlockWithID(blocks, "parent"))
@@ -370,7 +477,7 @@ func testGetSubTree2(t *testing.T, store store.Store, container store.Container)
 	})
 
 	t.Run("from child id", func(t *testing.T) {
-		blocks, err = store.GetSubTree2(container, "child1")
+		blocks, err = store.GetSubTree2(ctx, container, "child1")
 		require.NoError(t, err)
 		require.Len(t, blocks, 2)
 		require.True(t, ContainsBlockWithID(blocks, "child1"))
@@ -378,26 +485,27 @@ func testGetSubTree2(t *testing.T, store store.Store, container store.Container)
 	})
 
 	t.Run("from not existing id", func(t *testing.T) {
-		blocks, err = store.GetSubTree2(container, "not-exists")
+		blocks, err = store.GetSubTree2(ctx, container, "not-exists")
 		require.NoError(t, err)
 		require.Len(t, blocks, 0)
 	})
 }
 
 func testGetSubTree3(t *testing.T, store store.Store, container store.Container) {
-	blocks, err := store.GetAllBlocks(container)
+	ctx := context.Background()
+	blocks, err := store.GetAllBlocks(ctx, container)
 	require.NoError(t, err)
 	initialCount := len(blocks)
 
 	InsertBlocks(t, store, container, subtreeSampleBlocks, "user-id-1")
 	defer DeleteBlocks(t, store, container, subtreeSampleBlocks, "test")
 
-	blocks, err = store.GetAllBlocks(container)
+	blocks, err = store.GetAllBlocks(ctx, container)
 	require.NoError(t, err)
 	require.Len(t, blocks, initialCount+6)
 
 	t.Run("from root id", func(t *testing.T) {
-		blocks, err = store.GetSubTree3(container, "parent")
+		blocks, err = store.GetSubTree3(ctx, container, "parent")
 		require.NoError(t, err)
 		require.Len(t, blocks, 5)
 		require.True(t, ContainsBlockWithID(blocks, "parent"))
@@ -408,7 +516,7 @@ func testGetSubTree3(t *testing.T, store store.Store, container store.Container)
 	})
 
 	t.Run("from child id", func(t *testing.T) {
-		blocks, err = store.GetSubTree3(container, "child1")
+		blocks, err = store.GetSubTree3(ctx, container, "child1")
 		require.NoError(t, err)
 		require.Len(t, blocks, 3)
 		require.True(t, ContainsBlockWithID(blocks, "child1"))
@@ -417,63 +525,65 @@ func testGetSubTree3(t *testing.T, store store.Store, container store.Container)
 	})
 
 	t.Run("from not existing id", func(t *testing.T) {
-		blocks, err = store.GetSubTree3(container, "not-exists")
+		blocks, err = store.GetSubTree3(ctx, container, "not-exists")
 		require.NoError(t, err)
 		require.Len(t, blocks, 0)
 	})
 }
 
 func testGetParents(t *testing.T, store store.Store, container store.Container) {
-	blocks, err := store.GetAllBlocks(container)
+	ctx := context.Background()
+	blocks, err := store.GetAllBlocks(ctx, container)
 	require.NoError(t, err)
 	initialCount := len(blocks)
 
 	InsertBlocks(t, store, container, subtreeSampleBlocks, "user-id-1")
 	defer DeleteBlocks(t, store, container, subtreeSampleBlocks, "test")
 
-	blocks, err = store.GetAllBlocks(container)
+	blocks, err = store.GetAllBlocks(ctx, container)
 	require.NoError(t, err)
 	require.Len(t, blocks, initialCount+6)
 
 	t.Run("root from root id", func(t *testing.T) {
-		rootID, err := store.GetRootID(container, "parent")
+		rootID, err := store.GetRootID(ctx, container, "parent")
 		require.NoError(t, err)
 		require.Equal(t, "parent", rootID)
 	})
 
 	t.Run("root from child id", func(t *testing.T) {
-		rootID, err := store.GetRootID(container, "child1")
+		rootID, err := store.GetRootID(ctx, container, "child1")
 		require.NoError(t, err)
 		require.Equal(t, "parent", rootID)
 	})
 
 	t.Run("root from not existing id", func(t *testing.T) {
-		_, err := store.GetRootID(container, "not-exists")
+		_, err := store.GetRootID(ctx, container, "not-exists")
 		require.Error(t, err)
 	})
 
 	t.Run("parent from root id", func(t *testing.T) {
-		parentID, err := store.GetParentID(container, "parent")
+		parentID, err := store.GetParentID(ctx, container, "parent")
 		require.NoError(t, err)
 		require.Equal(t, "", parentID)
 	})
 
 	t.Run("parent from child id", func(t *testing.T) {
-		parentID, err := store.GetParentID(container, "grandchild1")
+		parentID, err := store.GetParentID(ctx, container, "grandchild1")
 		require.NoError(t, err)
 		require.Equal(t, "child1", parentID)
 	})
 
 	t.Run("parent from not existing id", func(t *testing.T) {
-		_, err := store.GetParentID(container, "not-exists")
+		_, err := store.GetParentID(ctx, container, "not-exists")
 		require.Error(t, err)
 	})
 }
 
 func testDeleteBlock(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
 	userID := testUserID
 
-	blocks, err := store.GetAllBlocks(container)
+	blocks, err := store.GetAllBlocks(ctx, container)
 	require.NoError(t, err)
 	initialCount := len(blocks)
 
@@ -497,160 +607,587 @@ func testDeleteBlock(t *testing.T, store store.Store, container store.Container)
 	InsertBlocks(t, store, container, blocksToInsert, "user-id-1")
 	defer DeleteBlocks(t, store, container, blocksToInsert, "test")
 
-	blocks, err = store.GetAllBlocks(container)
+	blocks, err = store.GetAllBlocks(ctx, container)
 	require.NoError(t, err)
 	require.Len(t, blocks, initialCount+3)
 
 	t.Run("exiting id", func(t *testing.T) {
 		// Wait for not colliding the ID+insert_at key
 		time.Sleep(1 * time.Millisecond)
-		err := store.DeleteBlock(container, "block1", userID)
+		err := store.DeleteBlock(ctx, container, "block1", userID)
+		require.NoError(t, err)
+
+		block, err := store.GetBlock(ctx, container, "block1")
+		require.NoError(t, err)
+		require.Nil(t, block)
+
+		blocks, err := store.GetAllBlocks(ctx, container)
 		require.NoError(t, err)
+		require.Len(t, blocks, initialCount+2)
+		require.False(t, ContainsBlockWithID(blocks, "block1"))
+
+		deletedBlocks, err := store.GetDeletedBlocks(ctx, container, 0)
+		require.NoError(t, err)
+		require.Len(t, deletedBlocks, 1)
+		require.Equal(t, "block1", deletedBlocks[0].ID)
+		require.Equal(t, userID, deletedBlocks[0].ModifiedBy)
+		require.NotZero(t, deletedBlocks[0].DeleteAt)
 	})
 
 	t.Run("exiting id multiple times", func(t *testing.T) {
 		// Wait for not colliding the ID+insert_at key
 		time.Sleep(1 * time.Millisecond)
-		err := store.DeleteBlock(container, "block1", userID)
+		err := store.DeleteBlock(ctx, container, "block1", userID)
 		require.NoError(t, err)
 		// Wait for not colliding the ID+insert_at key
 		time.Sleep(1 * time.Millisecond)
-		err = store.DeleteBlock(container, "block1", userID)
+		err = store.DeleteBlock(ctx, container, "block1", userID)
 		require.NoError(t, err)
 	})
 
 	t.Run("from not existing id", func(t *testing.T) {
 		// Wait for not colliding the ID+insert_at key
 		time.Sleep(1 * time.Millisecond)
-		err := store.DeleteBlock(container, "not-exists", userID)
+		err := store.DeleteBlock(ctx, container, "not-exists", userID)
 		require.NoError(t, err)
 	})
 }
 
-func testGetBlocks(t *testing.T, store store.Store, container store.Container) {
-	blocks, err := store.GetAllBlocks(container)
+func testRestoreBlock(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	userID := testUserID
+
+	blocksToInsert := []model.Block{
+		{
+			ID:         "parent",
+			RootID:     "parent",
+			ModifiedBy: userID,
+		},
+		{
+			ID:         "child",
+			RootID:     "parent",
+			ParentID:   "parent",
+			ModifiedBy: userID,
+		},
+	}
+	InsertBlocks(t, store, container, blocksToInsert, "user-id-1")
+
+	// Wait for not colliding the ID+insert_at key
+	time.Sleep(1 * time.Millisecond)
+	err := store.DeleteBlock(ctx, container, "child", userID)
+	require.NoError(t, err)
+
+	blocks, err := store.GetBlocksWithParent(ctx, container, "parent")
 	require.NoError(t, err)
+	require.Empty(t, blocks)
+
+	t.Run("restore deleted block", func(t *testing.T) {
+		// Wait for not colliding the ID+insert_at key
+		time.Sleep(1 * time.Millisecond)
+		err := store.RestoreBlock(ctx, container, "child", "user-id-2")
+		require.NoError(t, err)
+
+		block, err := store.GetBlock(ctx, container, "child")
+		require.NoError(t, err)
+		require.NotNil(t, block)
+		require.Zero(t, block.DeleteAt)
+		require.Equal(t, "user-id-2", block.ModifiedBy)
+		require.Equal(t, "user-id-1", block.CreatedBy)
+
+		blocks, err := store.GetBlocksWithParent(ctx, container, "parent")
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+
+		deletedBlocks, err := store.GetDeletedBlocks(ctx, container, 0)
+		require.NoError(t, err)
+		require.Empty(t, deletedBlocks)
+	})
+
+	t.Run("restore block not in trash", func(t *testing.T) {
+		err := store.RestoreBlock(ctx, container, "parent", userID)
+		require.Error(t, err)
+	})
+
+	t.Run("restore not existing block", func(t *testing.T) {
+		err := store.RestoreBlock(ctx, container, "not-exists", userID)
+		require.Error(t, err)
+	})
+
+	t.Run("restore block from another workspace", func(t *testing.T) {
+		// Wait for not colliding the ID+insert_at key
+		time.Sleep(1 * time.Millisecond)
+		err := store.DeleteBlock(ctx, container, "child", userID)
+		require.NoError(t, err)
+
+		otherContainer := container
+		otherContainer.WorkspaceID = "other-workspace"
+		err = store.RestoreBlock(ctx, otherContainer, "child", userID)
+		require.Error(t, err)
+
+		block, err := store.GetBlock(ctx, container, "child")
+		require.NoError(t, err)
+		require.Nil(t, block)
+	})
+}
+
+func testGetDeletedBlocks(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	userID := testUserID
 
 	blocksToInsert := []model.Block{
 		{
 			ID:         "block1",
-			ParentID:   "",
 			RootID:     "block1",
-			ModifiedBy: testUserID,
-			Type:       "test",
+			ModifiedBy: userID,
 		},
 		{
 			ID:         "block2",
-			ParentID:   "block1",
-			RootID:     "block1",
-			ModifiedBy: testUserID,
-			Type:       "test",
+			RootID:     "block2",
+			ModifiedBy: userID,
 		},
 		{
 			ID:         "block3",
-			ParentID:   "block1",
-			RootID:     "block1",
-			ModifiedBy: testUserID,
-			Type:       "test",
+			RootID:     "block3",
+			ModifiedBy: userID,
 		},
+	}
+	InsertBlocks(t, store, container, blocksToInsert, "user-id-1")
+
+	deletedBlocks, err := store.GetDeletedBlocks(ctx, container, 0)
+	require.NoError(t, err)
+	require.Empty(t, deletedBlocks)
+
+	// Wait for not colliding the ID+insert_at key
+	time.Sleep(1 * time.Millisecond)
+	require.NoError(t, store.DeleteBlock(ctx, container, "block1", userID))
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, store.DeleteBlock(ctx, container, "block2", userID))
+
+	deletedBlocks, err = store.GetDeletedBlocks(ctx, container, 0)
+	require.NoError(t, err)
+	require.Len(t, deletedBlocks, 2)
+	require.Equal(t, "block2", deletedBlocks[0].ID)
+	require.Equal(t, "block1", deletedBlocks[1].ID)
+
+	t.Run("since timestamp", func(t *testing.T) {
+		deletedBlocks, err := store.GetDeletedBlocks(ctx, container, deletedBlocks[1].DeleteAt)
+		require.NoError(t, err)
+		require.Len(t, deletedBlocks, 1)
+		require.Equal(t, "block2", deletedBlocks[0].ID)
+	})
+
+	t.Run("other workspace", func(t *testing.T) {
+		otherContainer := container
+		otherContainer.WorkspaceID = "other-workspace"
+		deletedBlocks, err := store.GetDeletedBlocks(ctx, otherContainer, 0)
+		require.NoError(t, err)
+		require.Empty(t, deletedBlocks)
+	})
+}
+
+func testPurgeDeletedBlocks(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	userID := testUserID
+
+	blocksToInsert := []model.Block{
 		{
-			ID:         "block4",
-			ParentID:   "block1",
+			ID:         "block1",
 			RootID:     "block1",
-			ModifiedBy: testUserID,
-			Type:       "test2",
+			ModifiedBy: userID,
 		},
 		{
-			ID:         "block5",
-			ParentID:   "block2",
+			ID:         "block2",
 			RootID:     "block2",
-			ModifiedBy: testUserID,
-			Type:       "test",
+			ModifiedBy: userID,
+		},
+		{
+			ID:         "block3",
+			RootID:     "block3",
+			ModifiedBy: userID,
 		},
 	}
 	InsertBlocks(t, store, container, blocksToInsert, "user-id-1")
-	defer DeleteBlocks(t, store, container, blocksToInsert, "test")
 
-	t.Run("not existing parent", func(t *testing.T) {
-		time.Sleep(1 * time.Millisecond)
-		blocks, err = store.GetBlocksWithParentAndType(container, "not-exists", "test")
+	// Wait for not colliding the ID+insert_at key
+	time.Sleep(1 * time.Millisecond)
+	require.NoError(t, store.DeleteBlock(ctx, container, "block1", userID))
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, store.DeleteBlock(ctx, container, "block2", userID))
+
+	deletedBlocks, err := store.GetDeletedBlocks(ctx, container, 0)
+	require.NoError(t, err)
+	require.Len(t, deletedBlocks, 2)
+	block2DeleteAt := deletedBlocks[0].DeleteAt
+
+	count, err := store.PurgeDeletedBlocks(ctx, block2DeleteAt)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+
+	deletedBlocks, err = store.GetDeletedBlocks(ctx, container, 0)
+	require.NoError(t, err)
+	require.Len(t, deletedBlocks, 1)
+	require.Equal(t, "block2", deletedBlocks[0].ID)
+
+	// the purged block can't be restored anymore
+	err = store.RestoreBlock(ctx, container, "block1", userID)
+	require.Error(t, err)
+
+	count, err = store.PurgeDeletedBlocks(ctx, block2DeleteAt+1)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+
+	blocks, err := store.GetAllBlocks(ctx, container)
+	require.NoError(t, err)
+	require.True(t, ContainsBlockWithID(blocks, "block3"))
+	require.False(t, ContainsBlockWithID(blocks, "block1"))
+	require.False(t, ContainsBlockWithID(blocks, "block2"))
+}
+
+func testGetBlockHistory(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	userID := testUserID
+
+	block := model.Block{
+		ID:         "block1",
+		RootID:     "block1",
+		Title:      "version 1",
+		ModifiedBy: userID,
+		Fields:     map[string]interface{}{"field": "value 1"},
+	}
+	err := store.InsertBlock(ctx, container, &block, "user-id-1")
+	require.NoError(t, err)
+
+	// Wait for not colliding the ID+insert_at key
+	time.Sleep(1 * time.Millisecond)
+	block.Title = "version 2"
+	block.Fields = map[string]interface{}{"field": "value 2"}
+	err = store.InsertBlock(ctx, container, &block, "user-id-2")
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Millisecond)
+	err = store.DeleteBlock(ctx, container, "block1", "user-id-3")
+	require.NoError(t, err)
+
+	t.Run("all versions", func(t *testing.T) {
+		history, err := store.GetBlockHistory(ctx, container, "block1", model.QueryBlockHistoryOptions{})
 		require.NoError(t, err)
-		require.Len(t, blocks, 0)
+		require.Len(t, history, 3)
+
+		require.Equal(t, "version 1", history[0].Title)
+		require.Equal(t, "user-id-1", history[0].ModifiedBy)
+		require.Equal(t, "value 1", history[0].Fields["field"])
+		require.Zero(t, history[0].DeleteAt)
+
+		require.Equal(t, "version 2", history[1].Title)
+		require.Equal(t, "user-id-2", history[1].ModifiedBy)
+		require.Equal(t, "value 2", history[1].Fields["field"])
+
+		require.Equal(t, "version 2", history[2].Title)
+		require.Equal(t, "user-id-3", history[2].ModifiedBy)
+		require.NotZero(t, history[2].DeleteAt)
 	})
 
-	t.Run("not existing type", func(t *testing.T) {
-		time.Sleep(1 * time.Millisecond)
-		blocks, err = store.GetBlocksWithParentAndType(container, "block1", "not-existing")
+	t.Run("limit and descending", func(t *testing.T) {
+		opts := model.QueryBlockHistoryOptions{Limit: 2, Descending: true}
+		history, err := store.GetBlockHistory(ctx, container, "block1", opts)
 		require.NoError(t, err)
-		require.Len(t, blocks, 0)
+		require.Len(t, history, 2)
+		require.NotZero(t, history[0].DeleteAt)
+		require.Equal(t, "user-id-2", history[1].ModifiedBy)
 	})
 
-	t.Run("valid parent and type", func(t *testing.T) {
-		time.Sleep(1 * time.Millisecond)
-		blocks, err = store.GetBlocksWithParentAndType(container, "block1", "test")
+	t.Run("before", func(t *testing.T) {
+		all, err := store.GetBlockHistory(ctx, container, "block1", model.QueryBlockHistoryOptions{})
 		require.NoError(t, err)
-		require.Len(t, blocks, 2)
-	})
 
-	t.Run("not existing parent", func(t *testing.T) {
-		time.Sleep(1 * time.Millisecond)
-		blocks, err = store.GetBlocksWithParent(container, "not-exists")
+		opts := model.QueryBlockHistoryOptions{Descending: true, Before: all[1].UpdateAt}
+		history, err := store.GetBlockHistory(ctx, container, "block1", opts)
 		require.NoError(t, err)
-		require.Len(t, blocks, 0)
+		require.Len(t, history, 1)
+		require.Equal(t, "version 1", history[0].Title)
 	})
 
-	t.Run("valid parent", func(t *testing.T) {
-		time.Sleep(1 * time.Millisecond)
-		blocks, err = store.GetBlocksWithParent(container, "block1")
+	t.Run("deleted and re-created", func(t *testing.T) {
+		_, err := store.PurgeDeletedBlocks(ctx, utils.GetMillis()+1)
 		require.NoError(t, err)
-		require.Len(t, blocks, 3)
-	})
 
-	t.Run("not existing type", func(t *testing.T) {
 		time.Sleep(1 * time.Millisecond)
-		blocks, err = store.GetBlocksWithType(container, "not-exists")
+		newBlock := model.Block{
+			ID:         "block1",
+			RootID:     "block1",
+			Title:      "re-created",
+			ModifiedBy: userID,
+		}
+		err = store.InsertBlock(ctx, container, &newBlock, "user-id-4")
 		require.NoError(t, err)
-		require.Len(t, blocks, 0)
-	})
 
-	t.Run("valid type", func(t *testing.T) {
-		time.Sleep(1 * time.Millisecond)
-		blocks, err = store.GetBlocksWithType(container, "test")
+		history, err := store.GetBlockHistory(ctx, container, "block1", model.QueryBlockHistoryOptions{})
 		require.NoError(t, err)
-		require.Len(t, blocks, 4)
+		require.Len(t, history, 4)
+		require.Equal(t, "version 1", history[0].Title)
+		require.NotZero(t, history[2].DeleteAt)
+		require.Equal(t, "re-created", history[3].Title)
+		require.Equal(t, "user-id-4", history[3].CreatedBy)
+		require.Zero(t, history[3].DeleteAt)
 	})
 
-	t.Run("not existing parent", func(t *testing.T) {
-		time.Sleep(1 * time.Millisecond)
-		blocks, err = store.GetBlocksWithRootID(container, "not-exists")
+	t.Run("other workspace", func(t *testing.T) {
+		otherContainer := container
+		otherContainer.WorkspaceID = "other-workspace"
+		history, err := store.GetBlockHistory(ctx, otherContainer, "block1", model.QueryBlockHistoryOptions{})
 		require.NoError(t, err)
-		require.Len(t, blocks, 0)
+		require.Empty(t, history)
 	})
 
-	t.Run("valid parent", func(t *testing.T) {
-		time.Sleep(1 * time.Millisecond)
-		blocks, err = store.GetBlocksWithRootID(container, "block1")
+	t.Run("not existing block", func(t *testing.T) {
+		history, err := store.GetBlockHistory(ctx, container, "not-exists", model.QueryBlockHistoryOptions{})
 		require.NoError(t, err)
-		require.Len(t, blocks, 4)
+		require.Empty(t, history)
 	})
 }
 
-func testGetBlock(t *testing.T, store store.Store, container store.Container) {
-	t.Run("get a block", func(t *testing.T) {
-		block := model.Block{
-			ID:         "block-id-10",
-			RootID:     "root-id-1",
-			ModifiedBy: "user-id-1",
+func testSearchBlocks(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	blocksToInsert := []model.Block{
+		{
+			ID:     "board1",
+			RootID: "board1",
+			Type:   "board",
+			Title:  "Roadmap board",
+		},
+		{
+			ID:       "card1",
+			RootID:   "board1",
+			ParentID: "board1",
+			Type:     "card",
+			Title:    "Plan the Roadmap",
+		},
+		{
+			ID:       "card2",
+			RootID:   "board1",
+			ParentID: "board1",
+			Type:     "card",
+			Title:    "Second card",
+			Fields: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"property1": "Needs a ROADMAP review",
+				},
+			},
+		},
+		{
+			ID:       "card3",
+			RootID:   "board1",
+			ParentID: "board1",
+			Type:     "card",
+			Title:    "Third card",
+			Fields: map[string]interface{}{
+				"icon": "roadmap",
+				"properties": map[string]interface{}{
+					"property1": []interface{}{"option1", "option2"},
+				},
+			},
+		},
+		{
+			ID:       "text1",
+			RootID:   "board1",
+			ParentID: "card1",
+			Type:     "text",
+			Title:    "roadmap in a text block",
+		},
+		{
+			ID:       "card4",
+			RootID:   "board1",
+			ParentID: "board1",
+			Type:     "card",
+			Title:    "100% done",
+		},
+	}
+	InsertBlocks(t, store, container, blocksToInsert, "user-id-1")
+
+	resultIDs := func(results []model.BlockSearchResult) []string {
+		ids := []string{}
+		for _, result := range results {
+			ids = append(ids, result.ID)
 		}
+		return ids
+	}
 
-		err := store.InsertBlock(container, &block, "user-id-1")
+	t.Run("matches card titles and property values", func(t *testing.T) {
+		results, err := store.SearchBlocks(ctx, container, "roadmap", 10)
 		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"card1", "card2"}, resultIDs(results))
+		for _, result := range results {
+			require.Equal(t, "board1", result.BoardID)
+		}
+	})
 
-		fetchedBlock, err := store.GetBlock(container, "block-id-10")
+	t.Run("matches multi value properties", func(t *testing.T) {
+		results, err := store.SearchBlocks(ctx, container, "option2", 10)
 		require.NoError(t, err)
-		require.NotNil(t, fetchedBlock)
-		require.Equal(t, "block-id-10", fetchedBlock.ID)
-		require.Equal(t, "root-id-1", fetchedBlock.RootID)
+		require.Equal(t, []string{"card3"}, resultIDs(results))
+	})
+
+	t.Run("wildcards are matched literally", func(t *testing.T) {
+		results, err := store.SearchBlocks(ctx, container, "0% d", 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"card4"}, resultIDs(results))
+
+		results, err = store.SearchBlocks(ctx, container, "%", 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"card4"}, resultIDs(results))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		results, err := store.SearchBlocks(ctx, container, "card", 2)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+	})
+
+	t.Run("deleted cards are excluded", func(t *testing.T) {
+		// Wait for not colliding the ID+insert_at key
+		time.Sleep(1 * time.Millisecond)
+		require.NoError(t, store.DeleteBlock(ctx, container, "card1", testUserID))
+
+		results, err := store.SearchBlocks(ctx, container, "roadmap", 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"card2"}, resultIDs(results))
+	})
+
+	t.Run("other workspace", func(t *testing.T) {
+		otherContainer := container
+		otherContainer.WorkspaceID = "other-workspace"
+		results, err := store.SearchBlocks(ctx, otherContainer, "roadmap", 10)
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+}
+
+func testGetBlocks(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	blocks, err := store.GetAllBlocks(ctx, container)
+	require.NoError(t, err)
+
+	blocksToInsert := []model.Block{
+		{
+			ID:         "block1",
+			ParentID:   "",
+			RootID:     "block1",
+			ModifiedBy: testUserID,
+			Type:       "test",
+		},
+		{
+			ID:         "block2",
+			ParentID:   "block1",
+			RootID:     "block1",
+			ModifiedBy: testUserID,
+			Type:       "test",
+		},
+		{
+			ID:         "block3",
+			ParentID:   "block1",
+			RootID:     "block1",
+			ModifiedBy: testUserID,
+			Type:       "test",
+		},
+		{
+			ID:         "block4",
+			ParentID:   "block1",
+			RootID:     "block1",
+			ModifiedBy: testUserID,
+			Type:       "test2",
+		},
+		{
+			ID:         "block5",
+			ParentID:   "block2",
+			RootID:     "block2",
+			ModifiedBy: testUserID,
+			Type:       "test",
+		},
+	}
+	InsertBlocks(t, store, container, blocksToInsert, "user-id-1")
+	defer DeleteBlocks(t, store, container, blocksToInsert, "test")
+
+	t.Run("not existing parent", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		blocks, err = store.GetBlocksWithParentAndType(ctx, container, "not-exists", "test")
+		require.NoError(t, err)
+		require.Len(t, blocks, 0)
+	})
+
+	t.Run("not existing type", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		blocks, err = store.GetBlocksWithParentAndType(ctx, container, "block1", "not-existing")
+		require.NoError(t, err)
+		require.Len(t, blocks, 0)
+	})
+
+	t.Run("valid parent and type", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		blocks, err = store.GetBlocksWithParentAndType(ctx, container, "block1", "test")
+		require.NoError(t, err)
+		require.Len(t, blocks, 2)
+	})
+
+	t.Run("not existing parent", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		blocks, err = store.GetBlocksWithParent(ctx, container, "not-exists")
+		require.NoError(t, err)
+		require.Len(t, blocks, 0)
+	})
+
+	t.Run("valid parent", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		blocks, err = store.GetBlocksWithParent(ctx, container, "block1")
+		require.NoError(t, err)
+		require.Len(t, blocks, 3)
+	})
+
+	t.Run("not existing type", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		blocks, err = store.GetBlocksWithType(ctx, container, "not-exists")
+		require.NoError(t, err)
+		require.Len(t, blocks, 0)
+	})
+
+	t.Run("valid type", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		blocks, err = store.GetBlocksWithType(ctx, container, "test")
+		require.NoError(t, err)
+		require.Len(t, blocks, 4)
+	})
+
+	t.Run("not existing parent", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		blocks, err = store.GetBlocksWithRootID(ctx, container, "not-exists")
+		require.NoError(t, err)
+		require.Len(t, blocks, 0)
+	})
+
+	t.Run("valid parent", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		blocks, err = store.GetBlocksWithRootID(ctx, container, "block1")
+		require.NoError(t, err)
+		require.Len(t, blocks, 4)
+	})
+}
+
+func testGetBlock(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	t.Run("get a block", func(t *testing.T) {
+		block := model.Block{
+			ID:         "block-id-10",
+			RootID:     "root-id-1",
+			ModifiedBy: "user-id-1",
+		}
+
+		err := store.InsertBlock(ctx, container, &block, "user-id-1")
+		require.NoError(t, err)
+
+		fetchedBlock, err := store.GetBlock(ctx, container, "block-id-10")
+		require.NoError(t, err)
+		require.NotNil(t, fetchedBlock)
+		require.Equal(t, "block-id-10", fetchedBlock.ID)
+		require.Equal(t, "root-id-1", fetchedBlock.RootID)
 		require.Equal(t, "user-id-1", fetchedBlock.CreatedBy)
 		require.Equal(t, "user-id-1", fetchedBlock.ModifiedBy)
 		assert.WithinDurationf(t, time.Now(), time.Unix(fetchedBlock.CreateAt/1000, 0), 1*time.Second, "create time should be current time")
@@ -658,8 +1195,807 @@ func testGetBlock(t *testing.T, store store.Store, container store.Container) {
 	})
 
 	t.Run("get a non-existing block", func(t *testing.T) {
-		fetchedBlock, err := store.GetBlock(container, "non-existing-id")
+		fetchedBlock, err := store.GetBlock(ctx, container, "non-existing-id")
 		require.NoError(t, err)
 		require.Nil(t, fetchedBlock)
 	})
 }
+
+func testInsertBlocks(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	userID := testUserID
+
+	blocks, errBlocks := store.GetAllBlocks(ctx, container)
+	require.NoError(t, errBlocks)
+	initialCount := len(blocks)
+
+	t.Run("empty batch", func(t *testing.T) {
+		result, err := store.InsertBlocks(ctx, container, []model.Block{}, userID)
+		require.NoError(t, err)
+		require.Empty(t, result.Inserted)
+		require.Empty(t, result.Updated)
+	})
+
+	t.Run("insert and update in the same batch", func(t *testing.T) {
+		existing := model.Block{ID: "batch-1", RootID: "batch-1", Title: "Old Title"}
+		require.NoError(t, store.InsertBlock(ctx, container, &existing, "user-id-2"))
+
+		// avoid violating the block history primary key
+		time.Sleep(1 * time.Second)
+
+		batch := []model.Block{
+			{ID: "batch-1", RootID: "batch-1", Title: "New Title"},
+			{ID: "batch-2", RootID: "batch-1", ParentID: "batch-1"},
+			{ID: "batch-3", RootID: "batch-1", ParentID: "batch-1"},
+		}
+		result, err := store.InsertBlocks(ctx, container, batch, userID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"batch-2", "batch-3"}, result.Inserted)
+		require.ElementsMatch(t, []string{"batch-1"}, result.Updated)
+
+		// blocks are updated in place
+		for _, block := range batch {
+			require.Equal(t, userID, block.ModifiedBy)
+			require.NotZero(t, block.UpdateAt)
+		}
+
+		blocks, err := store.GetAllBlocks(ctx, container)
+		require.NoError(t, err)
+		require.Len(t, blocks, initialCount+3)
+
+		updated, err := store.GetBlock(ctx, container, "batch-1")
+		require.NoError(t, err)
+		require.Equal(t, "New Title", updated.Title)
+		require.Equal(t, "user-id-2", updated.CreatedBy)
+		require.Equal(t, existing.CreateAt, updated.CreateAt)
+	})
+
+	t.Run("repeated IDs keep the last occurrence", func(t *testing.T) {
+		batch := []model.Block{
+			{ID: "batch-4", RootID: "batch-1", Title: "First"},
+			{ID: "batch-4", RootID: "batch-1", Title: "Last"},
+		}
+		result, err := store.InsertBlocks(ctx, container, batch, userID)
+		require.NoError(t, err)
+		require.Equal(t, []string{"batch-4"}, result.Inserted)
+
+		block, err := store.GetBlock(ctx, container, "batch-4")
+		require.NoError(t, err)
+		require.Equal(t, "Last", block.Title)
+	})
+
+	t.Run("a block without root ID fails the whole batch", func(t *testing.T) {
+		batch := []model.Block{
+			{ID: "batch-5", RootID: "batch-1"},
+			{ID: "batch-6"},
+		}
+		_, err := store.InsertBlocks(ctx, container, batch, userID)
+		require.Error(t, err)
+
+		block, err := store.GetBlock(ctx, container, "batch-5")
+		require.NoError(t, err)
+		require.Nil(t, block)
+	})
+}
+
+func testGetTemplateBoards(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	blocksToInsert := []model.Block{
+		{
+			ID:     "template1",
+			RootID: "template1",
+			Type:   "board",
+			Title:  "B template",
+			Fields: map[string]interface{}{"isTemplate": true, "icon": "🎯"},
+		},
+		{
+			ID:       "card1",
+			RootID:   "template1",
+			ParentID: "template1",
+			Type:     "card",
+		},
+		{
+			ID:       "card2",
+			RootID:   "template1",
+			ParentID: "template1",
+			Type:     "card",
+		},
+		{
+			ID:       "view1",
+			RootID:   "template1",
+			ParentID: "template1",
+			Type:     "view",
+		},
+		{
+			ID:     "template2",
+			RootID: "template2",
+			Type:   "board",
+			Title:  "A template",
+			Fields: map[string]interface{}{"isTemplate": true},
+		},
+		{
+			ID:     "board1",
+			RootID: "board1",
+			Type:   "board",
+			Title:  "Board",
+			Fields: map[string]interface{}{"isTemplate": false},
+		},
+		{
+			ID:       "card3",
+			RootID:   "board1",
+			ParentID: "board1",
+			Type:     "card",
+		},
+	}
+	_, err := store.InsertBlocks(ctx, container, blocksToInsert, testUserID)
+	require.NoError(t, err)
+
+	t.Run("list templates", func(t *testing.T) {
+		templates, err := store.GetTemplateBoards(ctx, container)
+		require.NoError(t, err)
+		require.Equal(t, []model.BoardTemplate{
+			{ID: "template2", Title: "A template", CardCount: 0},
+			{ID: "template1", Title: "B template", Icon: "🎯", CardCount: 2},
+		}, templates)
+	})
+
+	t.Run("ignore deleted cards and templates", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		require.NoError(t, store.DeleteBlock(ctx, container, "card1", testUserID))
+		require.NoError(t, store.DeleteBlock(ctx, container, "template2", testUserID))
+
+		templates, err := store.GetTemplateBoards(ctx, container)
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		require.Equal(t, "template1", templates[0].ID)
+		require.EqualValues(t, 1, templates[0].CardCount)
+	})
+
+	t.Run("ignore templates of other workspaces", func(t *testing.T) {
+		otherContainer := container
+		otherContainer.WorkspaceID = "other-workspace"
+		templates, err := store.GetTemplateBoards(ctx, otherContainer)
+		require.NoError(t, err)
+		require.Empty(t, templates)
+	})
+}
+
+func testGetBoardMetadata(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	blocksToInsert := []model.Block{
+		{
+			ID:     "board1",
+			RootID: "board1",
+			Type:   "board",
+		},
+		{
+			ID:       "card1",
+			RootID:   "board1",
+			ParentID: "board1",
+			Type:     "card",
+		},
+		{
+			ID:       "card2",
+			RootID:   "board1",
+			ParentID: "board1",
+			Type:     "card",
+		},
+		{
+			ID:       "card3",
+			RootID:   "board2",
+			ParentID: "board2",
+			Type:     "card",
+		},
+		{
+			ID:       "comment1",
+			RootID:   "board1",
+			ParentID: "card1",
+			Type:     "comment",
+		},
+		{
+			ID:       "comment2",
+			RootID:   "board1",
+			ParentID: "card1",
+			Type:     "comment",
+		},
+	}
+	_, err := store.InsertBlocks(ctx, container, blocksToInsert, "user-1")
+	require.NoError(t, err)
+
+	time.Sleep(1 * time.Millisecond)
+	text := model.Block{
+		ID:       "text1",
+		RootID:   "board1",
+		ParentID: "card1",
+		Type:     "text",
+	}
+	require.NoError(t, store.InsertBlock(ctx, container, &text, "user-2"))
+
+	t.Run("get the metadata of the cards", func(t *testing.T) {
+		metadata, err := store.GetBoardMetadata(ctx, container, "board1")
+		require.NoError(t, err)
+		require.Equal(t, []model.CardMetadata{
+			{CardID: "card1", CommentCount: 2, LastContentUpdateAt: text.UpdateAt, LastContentModifiedBy: "user-2"},
+			{CardID: "card2"},
+		}, metadata)
+	})
+
+	t.Run("update the counts after deleting a comment", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		require.NoError(t, store.DeleteBlock(ctx, container, "comment1", testUserID))
+		require.NoError(t, store.DeleteBlock(ctx, container, "text1", testUserID))
+
+		comment, err := store.GetBlock(ctx, container, "comment2")
+		require.NoError(t, err)
+
+		metadata, err := store.GetBoardMetadata(ctx, container, "board1")
+		require.NoError(t, err)
+		require.Len(t, metadata, 2)
+		require.Equal(t, "card1", metadata[0].CardID)
+		require.EqualValues(t, 1, metadata[0].CommentCount)
+		require.Equal(t, comment.UpdateAt, metadata[0].LastContentUpdateAt)
+		require.Equal(t, "user-1", metadata[0].LastContentModifiedBy)
+	})
+
+	t.Run("unknown board", func(t *testing.T) {
+		metadata, err := store.GetBoardMetadata(ctx, container, "unknown")
+		require.NoError(t, err)
+		require.Empty(t, metadata)
+	})
+}
+
+func testGetChecklistProgress(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	checkbox := func(id, parentID, rootID string, value bool) model.Block {
+		return model.Block{
+			ID:       id,
+			RootID:   rootID,
+			ParentID: parentID,
+			Type:     "checkbox",
+			Fields:   map[string]interface{}{"value": value},
+		}
+	}
+
+	blocksToInsert := []model.Block{
+		{ID: "board1", RootID: "board1", Type: "board"},
+		{ID: "card1", RootID: "board1", ParentID: "board1", Type: "card"},
+		{ID: "card2", RootID: "board1", ParentID: "board1", Type: "card"},
+		{ID: "card3", RootID: "board1", ParentID: "board1", Type: "card"},
+		{ID: "card4", RootID: "board2", ParentID: "board2", Type: "card"},
+		{ID: "text1", RootID: "board1", ParentID: "card2", Type: "text"},
+		checkbox("checkbox1", "card1", "board1", true),
+		checkbox("checkbox2", "card1", "board1", false),
+		checkbox("checkbox3", "card1", "board1", true),
+		checkbox("checkbox4", "text1", "board1", true),
+		checkbox("checkbox5", "text1", "board1", false),
+		checkbox("checkbox6", "card4", "board2", true),
+		checkbox("checkbox7", "card3", "board1", true),
+	}
+	blocksToInsert[len(blocksToInsert)-1].DeleteAt = 1
+	_, err := store.InsertBlocks(ctx, container, blocksToInsert, "user-1")
+	require.NoError(t, err)
+
+	t.Run("count the checkboxes of the cards, nested or not", func(t *testing.T) {
+		progress, err := store.GetChecklistProgress(ctx, container, "board1")
+		require.NoError(t, err)
+		require.Equal(t, []model.ChecklistProgress{
+			{CardID: "card1", Checked: 2, Total: 3},
+			{CardID: "card2", Checked: 1, Total: 2},
+		}, progress)
+	})
+
+	t.Run("unknown board", func(t *testing.T) {
+		progress, err := store.GetChecklistProgress(ctx, container, "unknown")
+		require.NoError(t, err)
+		require.Empty(t, progress)
+	})
+}
+
+func testGetBoardWorkspaceIDs(t *testing.T, store store.Store, container store.Container) {
+	workspaceIDs, err := store.GetBoardWorkspaceIDs()
+	require.NoError(t, err)
+	require.Empty(t, workspaceIDs)
+
+	other := container
+	other.WorkspaceID = "other"
+	noBoards := container
+	noBoards.WorkspaceID = "no-boards"
+	deletedBoards := container
+	deletedBoards.WorkspaceID = "deleted-boards"
+
+	InsertBlocks(t, store, container, []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+		{ID: "board-2", RootID: "board-2", Type: "board"},
+	}, "user-id-1")
+	InsertBlocks(t, store, other, []model.Block{
+		{ID: "board-3", RootID: "board-3", Type: "board"},
+	}, "user-id-1")
+	InsertBlocks(t, store, noBoards, []model.Block{
+		{ID: "card-1", RootID: "card-1", Type: "card"},
+	}, "user-id-1")
+	deleted := []model.Block{
+		{ID: "board-4", RootID: "board-4", Type: "board"},
+	}
+	InsertBlocks(t, store, deletedBoards, deleted, "user-id-1")
+	time.Sleep(1 * time.Millisecond)
+	DeleteBlocks(t, store, deletedBoards, deleted, "user-id-1")
+
+	workspaceIDs, err = store.GetBoardWorkspaceIDs()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"0", "other"}, workspaceIDs)
+}
+
+func testStreamBlocksWithParentAndType(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	blocks := []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+		{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"},
+		{ID: "view-1", ParentID: "board-1", RootID: "board-1", Type: "view"},
+		{ID: "card-3", ParentID: "board-2", RootID: "board-2", Type: "card"},
+	}
+	InsertBlocks(t, store, container, blocks, "user-id-1")
+	time.Sleep(1 * time.Millisecond)
+	InsertBlocks(t, store, container, []model.Block{
+		{ID: "card-2", ParentID: "board-1", RootID: "board-1", Type: "card"},
+	}, "user-id-1")
+
+	t.Run("Blocks are streamed in creation order", func(t *testing.T) {
+		ids := []string{}
+		err := store.StreamBlocksWithParentAndType(ctx, container, "board-1", "card", func(block model.Block) error {
+			ids = append(ids, block.ID)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"card-1", "card-2"}, ids)
+	})
+
+	t.Run("Streaming stops at the first error", func(t *testing.T) {
+		count := 0
+		err := store.StreamBlocksWithParentAndType(ctx, container, "board-1", "card", func(block model.Block) error {
+			count++
+			return errors.New("stop")
+		})
+		require.EqualError(t, err, "stop")
+		require.Equal(t, 1, count)
+	})
+}
+
+func testStreamAllBlocks(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	other := container
+	other.WorkspaceID = "other-workspace"
+
+	InsertBlocks(t, store, container, []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+		{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"},
+		{ID: "board-2", RootID: "board-2", Type: "board"},
+		{ID: "card-deleted", ParentID: "board-2", RootID: "board-2", Type: "card", DeleteAt: 1},
+	}, "user-id-1")
+	InsertBlocks(t, store, other, []model.Block{
+		{ID: "board-other", RootID: "board-other", Type: "board"},
+	}, "user-id-1")
+
+	ids := []string{}
+	err := store.StreamAllBlocks(ctx, container, func(block model.Block) error {
+		ids = append(ids, block.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"board-1", "card-1", "board-2"}, ids)
+}
+
+func testGetReferencedFileIDs(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	other := container
+	other.WorkspaceID = "other-workspace"
+
+	InsertBlocks(t, store, container, []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board", Fields: map[string]interface{}{"description": "fileId"}},
+		{ID: "image-1", ParentID: "board-1", RootID: "board-1", Type: "image", Fields: map[string]interface{}{"fileId": "file-1.png"}},
+		{ID: "image-2", ParentID: "board-1", RootID: "board-1", Type: "image", Fields: map[string]interface{}{"fileId": "file-2.png"}, DeleteAt: 1},
+		{ID: "image-3", ParentID: "board-1", RootID: "board-1", Type: "image", Fields: map[string]interface{}{"fileId": ""}},
+	}, "user-id-1")
+	InsertBlocks(t, store, other, []model.Block{
+		{ID: "image-other", RootID: "image-other", Type: "image", Fields: map[string]interface{}{"fileId": "file-other.png"}},
+	}, "user-id-1")
+
+	// the blocks in the trash still reference their files
+	fileIDs, err := store.GetReferencedFileIDs(ctx, container)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"file-1.png", "file-2.png"}, fileIDs)
+}
+
+func testGetBlocksDigest(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	InsertBlocks(t, store, container, []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+		{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"},
+		{ID: "view-1", ParentID: "board-1", RootID: "board-1", Type: "view"},
+	}, "user-id-1")
+
+	digest := func(opts model.QueryBlocksDigestOptions) model.BlocksDigest {
+		d, err := store.GetBlocksDigest(ctx, container, opts)
+		require.NoError(t, err)
+		return *d
+	}
+
+	t.Run("The digest counts the blocks matching the filters", func(t *testing.T) {
+		require.Equal(t, int64(1), digest(model.QueryBlocksDigestOptions{}).Count)
+		require.Equal(t, int64(2), digest(model.QueryBlocksDigestOptions{ParentID: "board-1"}).Count)
+		require.Equal(t, int64(1), digest(model.QueryBlocksDigestOptions{ParentID: "board-1", BlockType: "card"}).Count)
+		require.Equal(t, int64(1), digest(model.QueryBlocksDigestOptions{BlockType: "view"}).Count)
+		require.Equal(t, int64(3), digest(model.QueryBlocksDigestOptions{All: true}).Count)
+		require.Equal(t, model.BlocksDigest{}, digest(model.QueryBlocksDigestOptions{ParentID: "unknown"}))
+	})
+
+	t.Run("The digest changes when a block is updated or deleted", func(t *testing.T) {
+		opts := model.QueryBlocksDigestOptions{ParentID: "board-1"}
+		before := digest(opts)
+		unrelated := digest(model.QueryBlocksDigestOptions{})
+
+		time.Sleep(1 * time.Millisecond)
+		title := "New title"
+		require.NoError(t, store.PatchBlock(ctx, container, "card-1", &model.BlockPatch{Title: &title}, "user-id-1"))
+		afterUpdate := digest(opts)
+		require.NotEqual(t, before, afterUpdate)
+		require.Greater(t, afterUpdate.UpdateAt, before.UpdateAt)
+		require.Equal(t, unrelated, digest(model.QueryBlocksDigestOptions{}))
+
+		DeleteBlocks(t, store, container, []model.Block{{ID: "view-1"}}, "user-id-1")
+		afterDelete := digest(opts)
+		require.Equal(t, int64(1), afterDelete.Count)
+		require.NotEqual(t, afterUpdate, afterDelete)
+	})
+}
+
+func testCanceledContext(t *testing.T, store store.Store, container store.Container) {
+	blocks := []model.Block{}
+	for i := 0; i < 10; i++ {
+		blocks = append(blocks, model.Block{ID: fmt.Sprintf("block-%d", i), RootID: "board-1", Type: "card"})
+	}
+	InsertBlocks(t, store, container, blocks, testUserID)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("query", func(t *testing.T) {
+		_, err := store.GetAllBlocks(canceled, container)
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("row query", func(t *testing.T) {
+		_, err := store.GetBlocksDigest(canceled, container, model.QueryBlocksDigestOptions{})
+		require.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		_, err := store.InsertBlocks(canceled, container, []model.Block{{ID: "block-new", RootID: "board-1", Type: "card"}}, testUserID)
+		require.True(t, errors.Is(err, context.Canceled))
+
+		block, err := store.GetBlock(context.Background(), container, "block-new")
+		require.NoError(t, err)
+		require.Nil(t, block)
+	})
+
+	t.Run("abort a running query", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		count := 0
+		err := store.StreamAllBlocks(ctx, container, func(block model.Block) error {
+			count++
+			if count == 1 {
+				cancel()
+				// the rows are closed asynchronously once the context is done
+				time.Sleep(50 * time.Millisecond)
+			}
+			return nil
+		})
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Less(t, count, len(blocks))
+	})
+}
+
+func testGetBlocksByIDs(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	blocksToInsert := []model.Block{
+		{ID: "board1", RootID: "board1", Type: "board"},
+		{ID: "card1", RootID: "board1", ParentID: "board1", Type: "card"},
+		{ID: "card2", RootID: "board1", ParentID: "board1", Type: "card"},
+		{ID: "card3", RootID: "board1", ParentID: "board1", Type: "card"},
+	}
+	_, err := store.InsertBlocks(ctx, container, blocksToInsert, "user-1")
+	require.NoError(t, err)
+	time.Sleep(1 * time.Millisecond)
+	require.NoError(t, store.DeleteBlock(ctx, container, "card3", "user-1"))
+
+	t.Run("get the existing blocks", func(t *testing.T) {
+		blocks, err := store.GetBlocksByIDs(ctx, container, []string{"card1", "card2", "card3", "unknown"})
+		require.NoError(t, err)
+		ids := []string{}
+		for _, block := range blocks {
+			ids = append(ids, block.ID)
+		}
+		require.ElementsMatch(t, []string{"card1", "card2"}, ids)
+	})
+
+	t.Run("get no blocks", func(t *testing.T) {
+		blocks, err := store.GetBlocksByIDs(ctx, container, []string{})
+		require.NoError(t, err)
+		require.Empty(t, blocks)
+	})
+
+	t.Run("get no blocks of another workspace", func(t *testing.T) {
+		other := container
+		other.WorkspaceID = "other"
+		blocks, err := store.GetBlocksByIDs(ctx, other, []string{"card1"})
+		require.NoError(t, err)
+		require.Empty(t, blocks)
+	})
+}
+
+func testGetBlockWorkspaceIDs(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	other := container
+	other.WorkspaceID = "other"
+
+	_, err := store.InsertBlocks(ctx, container, []model.Block{
+		{ID: "board1", RootID: "board1", Type: "board"},
+		{ID: "card1", RootID: "board1", ParentID: "board1", Type: "card"},
+	}, "user-1")
+	require.NoError(t, err)
+	// the history of the blocks is keyed by their ID and time
+	time.Sleep(1 * time.Millisecond)
+	_, err = store.InsertBlocks(ctx, other, []model.Block{
+		{ID: "board1", RootID: "board1", Type: "board"},
+		{ID: "board2", RootID: "board2", Type: "board"},
+	}, "user-1")
+	require.NoError(t, err)
+	time.Sleep(1 * time.Millisecond)
+	require.NoError(t, store.DeleteBlock(ctx, container, "card1", "user-1"))
+
+	t.Run("get the workspaces of the blocks", func(t *testing.T) {
+		workspaceIDs, err := store.GetBlockWorkspaceIDs(ctx, []string{"board1", "board2", "card1", "unknown"})
+		require.NoError(t, err)
+		require.Len(t, workspaceIDs, 3)
+		require.ElementsMatch(t, []string{container.WorkspaceID, "other"}, workspaceIDs["board1"])
+		require.Equal(t, []string{"other"}, workspaceIDs["board2"])
+		require.Equal(t, []string{container.WorkspaceID}, workspaceIDs["card1"])
+	})
+
+	t.Run("get no workspaces", func(t *testing.T) {
+		workspaceIDs, err := store.GetBlockWorkspaceIDs(ctx, []string{})
+		require.NoError(t, err)
+		require.Empty(t, workspaceIDs)
+	})
+}
+
+func testBlockVersions(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+
+	block := model.Block{ID: "board1", RootID: "board1", Type: "board", Title: "Board"}
+	require.NoError(t, store.InsertBlock(ctx, container, &block, "user-1"))
+	require.Equal(t, int64(1), block.Version)
+
+	getVersion := func(t *testing.T) int64 {
+		t.Helper()
+		stored, err := store.GetBlock(ctx, container, "board1")
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		return stored.Version
+	}
+
+	t.Run("the updates increment the version", func(t *testing.T) {
+		require.Equal(t, int64(1), getVersion(t))
+
+		time.Sleep(1 * time.Millisecond)
+		block.Title = "Updated"
+		require.NoError(t, store.InsertBlock(ctx, container, &block, "user-1"))
+		require.Equal(t, int64(2), block.Version)
+		require.Equal(t, int64(2), getVersion(t))
+
+		time.Sleep(1 * time.Millisecond)
+		title := "Patched"
+		require.NoError(t, store.PatchBlock(ctx, container, "board1", &model.BlockPatch{Title: &title}, "user-1"))
+		require.Equal(t, int64(3), getVersion(t))
+	})
+
+	t.Run("patch the expected version", func(t *testing.T) {
+		time.Sleep(1 * time.Millisecond)
+		title := "Expected"
+		version := getVersion(t)
+		require.NoError(t, store.PatchBlock(ctx, container, "board1", &model.BlockPatch{Title: &title, ExpectedVersion: &version}, "user-1"))
+
+		stored, err := store.GetBlock(ctx, container, "board1")
+		require.NoError(t, err)
+		require.Equal(t, "Expected", stored.Title)
+		require.Equal(t, version+1, stored.Version)
+
+		history, err := store.GetBlockHistory(ctx, container, "board1", model.QueryBlockHistoryOptions{Descending: true, Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		require.Equal(t, version+1, history[0].Version)
+	})
+
+	t.Run("reject another version", func(t *testing.T) {
+		title := "Stale"
+		version := getVersion(t) - 1
+		err := store.PatchBlock(ctx, container, "board1", &model.BlockPatch{Title: &title, ExpectedVersion: &version}, "user-1")
+		require.ErrorIs(t, err, model.ErrBlockVersionConflict)
+
+		stored, err := store.GetBlock(ctx, container, "board1")
+		require.NoError(t, err)
+		require.Equal(t, "Expected", stored.Title)
+		require.Equal(t, version+1, stored.Version)
+	})
+
+	t.Run("deleting and restoring increment the version", func(t *testing.T) {
+		version := getVersion(t)
+		time.Sleep(1 * time.Millisecond)
+		require.NoError(t, store.DeleteBlock(ctx, container, "board1", "user-1"))
+		time.Sleep(1 * time.Millisecond)
+		require.NoError(t, store.RestoreBlock(ctx, container, "board1", "user-1"))
+		require.Equal(t, version+2, getVersion(t))
+	})
+}
+
+func testGetFilteredBoardBlocks(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	card := func(id string, properties map[string]interface{}) model.Block {
+		return model.Block{ID: id, RootID: "board1", ParentID: "board1", Type: "card", Fields: map[string]interface{}{"properties": properties}}
+	}
+	blocksToInsert := []model.Block{
+		{ID: "board1", RootID: "board1", Type: "board"},
+		{ID: "view1", RootID: "board1", ParentID: "board1", Type: "view"},
+		card("todo", map[string]interface{}{"status": "todo", "owner": "user-1", "due": "1000"}),
+		card("done", map[string]interface{}{"status": "done", "owner": "user-2", "due": "3000"}),
+		card("range", map[string]interface{}{"due": `{"from":1500,"to":2500}`}),
+		card("empty", map[string]interface{}{}),
+		{ID: "template", RootID: "board1", ParentID: "board1", Type: "card", Fields: map[string]interface{}{"isTemplate": true}},
+		{ID: "text1", RootID: "board1", ParentID: "todo", Type: "text"},
+		card("deleted", map[string]interface{}{"status": "todo"}),
+		{ID: "board2", RootID: "board2", Type: "board"},
+		{ID: "other", RootID: "board2", ParentID: "board2", Type: "card", Fields: map[string]interface{}{
+			"properties": map[string]interface{}{"status": "todo"},
+		}},
+	}
+	_, err := store.InsertBlocks(ctx, container, blocksToInsert, "user-1")
+	require.NoError(t, err)
+	time.Sleep(1 * time.Millisecond)
+	require.NoError(t, store.DeleteBlock(ctx, container, "deleted", "user-1"))
+
+	filteredIDs := func(t *testing.T, filter *model.FilterGroup) []string {
+		blocks, err := store.GetFilteredBoardBlocks(ctx, container, "board1", filter)
+		require.NoError(t, err)
+		ids := []string{}
+		for _, block := range blocks {
+			ids = append(ids, block.ID)
+		}
+		return ids
+	}
+	structure := []string{"board1", "view1", "template"}
+
+	t.Run("get all the cards without a filter", func(t *testing.T) {
+		require.ElementsMatch(t, append(structure, "todo", "done", "range", "empty"), filteredIDs(t, nil))
+	})
+
+	t.Run("filter a select property", func(t *testing.T) {
+		filter := &model.FilterGroup{Operation: model.FilterOperationAnd, Clauses: []model.FilterClause{
+			{PropertyID: "status", Condition: model.FilterConditionIncludes, Values: []string{"todo"}},
+		}}
+		require.ElementsMatch(t, append(structure, "todo"), filteredIDs(t, filter))
+	})
+
+	t.Run("filter the empty values", func(t *testing.T) {
+		filter := &model.FilterGroup{Operation: model.FilterOperationAnd, Clauses: []model.FilterClause{
+			{PropertyID: "status", Condition: model.FilterConditionIsEmpty},
+		}}
+		require.ElementsMatch(t, append(structure, "range", "empty"), filteredIDs(t, filter))
+	})
+
+	t.Run("combine the clauses", func(t *testing.T) {
+		filter := &model.FilterGroup{Operation: model.FilterOperationOr, Clauses: []model.FilterClause{
+			{PropertyID: "owner", Condition: model.FilterConditionIncludes, Values: []string{"user-2"}},
+		}, Groups: []model.FilterGroup{{Operation: model.FilterOperationAnd, Clauses: []model.FilterClause{
+			{PropertyID: "status", Condition: model.FilterConditionNotIncludes, Values: []string{"done"}},
+			{PropertyID: "owner", Condition: model.FilterConditionIsNotEmpty},
+		}}}}
+		require.ElementsMatch(t, append(structure, "todo", "done"), filteredIDs(t, filter))
+	})
+
+	t.Run("filter a date range", func(t *testing.T) {
+		// the date ranges of the cards are left to the caller to compare
+		filter := &model.FilterGroup{Operation: model.FilterOperationAnd, Clauses: []model.FilterClause{
+			{PropertyID: "due", Condition: model.FilterConditionIsBetween, Values: []string{"500", "2000"}},
+		}}
+		require.ElementsMatch(t, append(structure, "todo", "range"), filteredIDs(t, filter))
+
+		filter.Clauses[0].Values = []string{"2000"}
+		require.ElementsMatch(t, append(structure, "done", "range"), filteredIDs(t, filter))
+	})
+}
+
+func testGetBlocksPage(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		// the IDs don't follow the insertion order
+		_, err := store.InsertBlocks(ctx, container, []model.Block{
+			{ID: fmt.Sprintf("card%d", 5-i), RootID: "board1", ParentID: "board1", Type: "card"},
+		}, "user-1")
+		require.NoError(t, err)
+		time.Sleep(1 * time.Millisecond)
+	}
+	_, err := store.InsertBlocks(ctx, container, []model.Block{
+		{ID: "board1", RootID: "board1", Type: "board"},
+		{ID: "view1", RootID: "board1", ParentID: "board1", Type: "view"},
+	}, "user-1")
+	require.NoError(t, err)
+	time.Sleep(1 * time.Millisecond)
+
+	pageIDs := func(page *model.BlocksPage) []string {
+		ids := []string{}
+		for _, block := range page.Blocks {
+			ids = append(ids, block.ID)
+		}
+		return ids
+	}
+
+	t.Run("page through the blocks in insertion order", func(t *testing.T) {
+		opts := model.QueryBlocksPageOptions{ParentID: "board1", BlockType: "card", Limit: 2}
+		page, err := store.GetBlocksPage(ctx, container, opts)
+		require.NoError(t, err)
+		require.Equal(t, []string{"card5", "card4"}, pageIDs(page))
+		require.NotEmpty(t, page.NextCursor)
+
+		// updating a block of a previous page, or inserting new ones,
+		// doesn't shift the next pages
+		_, err = store.InsertBlocks(ctx, container, []model.Block{
+			{ID: "card5", RootID: "board1", ParentID: "board1", Type: "card", Title: "updated"},
+			{ID: "card0", RootID: "board1", ParentID: "board1", Type: "card"},
+		}, "user-1")
+		require.NoError(t, err)
+
+		opts.After, err = model.ParseBlocksCursor(page.NextCursor)
+		require.NoError(t, err)
+		page, err = store.GetBlocksPage(ctx, container, opts)
+		require.NoError(t, err)
+		require.Equal(t, []string{"card3", "card2"}, pageIDs(page))
+
+		opts.After, err = model.ParseBlocksCursor(page.NextCursor)
+		require.NoError(t, err)
+		page, err = store.GetBlocksPage(ctx, container, opts)
+		require.NoError(t, err)
+		require.Equal(t, []string{"card1", "card0"}, pageIDs(page))
+		require.Empty(t, page.NextCursor)
+	})
+
+	t.Run("the pages add up to all the blocks", func(t *testing.T) {
+		page, err := store.GetBlocksPage(ctx, container, model.QueryBlocksPageOptions{ParentID: "board1", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, page.Blocks, 7)
+		require.Empty(t, page.NextCursor)
+
+		first, err := store.GetBlocksPage(ctx, container, model.QueryBlocksPageOptions{ParentID: "board1", Limit: 5})
+		require.NoError(t, err)
+		cursor, err := model.ParseBlocksCursor(first.NextCursor)
+		require.NoError(t, err)
+		second, err := store.GetBlocksPage(ctx, container, model.QueryBlocksPageOptions{ParentID: "board1", Limit: 5, After: cursor})
+		require.NoError(t, err)
+		require.Equal(t, pageIDs(page), append(pageIDs(first), pageIDs(second)...))
+	})
+
+	t.Run("page the root blocks", func(t *testing.T) {
+		page, err := store.GetBlocksPage(ctx, container, model.QueryBlocksPageOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"board1"}, pageIDs(page))
+	})
+
+	t.Run("page no blocks of another workspace", func(t *testing.T) {
+		other := container
+		other.WorkspaceID = "other"
+		page, err := store.GetBlocksPage(ctx, other, model.QueryBlocksPageOptions{All: true})
+		require.NoError(t, err)
+		require.Empty(t, page.Blocks)
+		require.Empty(t, page.NextCursor)
+	})
+}
@@ -0,0 +1,83 @@
+package storetests
+
+import (
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestBoardMembers(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container1 := store.Container{WorkspaceID: "workspace-1"}
+	container2 := store.Container{WorkspaceID: "workspace-2"}
+
+	t.Run("BoardMembers", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testBoardMembers(t, store, container1, container2)
+	})
+	t.Run("GetBoardRolesForUser", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBoardRolesForUser(t, store, container1, container2)
+	})
+}
+
+func testBoardMembers(t *testing.T, store store.Store, container1, container2 store.Container) {
+	members, err := store.GetBoardMembers(container1, "board-1")
+	require.NoError(t, err)
+	require.Empty(t, members)
+
+	require.NoError(t, store.UpsertBoardMember(container1, model.BoardMember{BoardID: "board-1", UserID: "user-1", Role: model.BoardRoleAdmin, CreateAt: 1}))
+	require.NoError(t, store.UpsertBoardMember(container1, model.BoardMember{BoardID: "board-1", UserID: "user-2", Role: model.BoardRoleViewer, CreateAt: 2}))
+	require.NoError(t, store.UpsertBoardMember(container2, model.BoardMember{BoardID: "board-1", UserID: "user-3", Role: model.BoardRoleViewer, CreateAt: 3}))
+
+	t.Run("should list the members of the workspace board", func(t *testing.T) {
+		members, err := store.GetBoardMembers(container1, "board-1")
+		require.NoError(t, err)
+		require.Equal(t, []model.BoardMember{
+			{BoardID: "board-1", UserID: "user-1", Role: model.BoardRoleAdmin, CreateAt: 1},
+			{BoardID: "board-1", UserID: "user-2", Role: model.BoardRoleViewer, CreateAt: 2},
+		}, members)
+	})
+
+	t.Run("should change the role of a member", func(t *testing.T) {
+		require.NoError(t, store.UpsertBoardMember(container1, model.BoardMember{BoardID: "board-1", UserID: "user-2", Role: model.BoardRoleEditor, CreateAt: 4}))
+
+		members, err := store.GetBoardMembers(container1, "board-1")
+		require.NoError(t, err)
+		require.Len(t, members, 2)
+		require.Equal(t, model.BoardRoleEditor, members[1].Role)
+		require.EqualValues(t, 2, members[1].CreateAt)
+	})
+
+	t.Run("should remove a member", func(t *testing.T) {
+		require.NoError(t, store.DeleteBoardMember(container1, "board-1", "user-2"))
+		require.NoError(t, store.DeleteBoardMember(container1, "board-1", "unknown"))
+
+		members, err := store.GetBoardMembers(container1, "board-1")
+		require.NoError(t, err)
+		require.Len(t, members, 1)
+		require.Equal(t, "user-1", members[0].UserID)
+	})
+}
+
+func testGetBoardRolesForUser(t *testing.T, store store.Store, container1, container2 store.Container) {
+	for _, member := range []model.BoardMember{
+		{BoardID: "board-1", UserID: "user-1", Role: model.BoardRoleAdmin, CreateAt: 1},
+		{BoardID: "board-1", UserID: "user-2", Role: model.BoardRoleViewer, CreateAt: 2},
+		{BoardID: "board-2", UserID: "user-1", Role: model.BoardRoleAdmin, CreateAt: 3},
+	} {
+		require.NoError(t, store.UpsertBoardMember(container1, member))
+	}
+	require.NoError(t, store.UpsertBoardMember(container2, model.BoardMember{BoardID: "board-3", UserID: "user-2", Role: model.BoardRoleAdmin, CreateAt: 4}))
+
+	roles, err := store.GetBoardRolesForUser(container1, "user-2")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"board-1": model.BoardRoleViewer, "board-2": ""}, roles)
+
+	roles, err = store.GetBoardRolesForUser(container2, "user-1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"board-3": ""}, roles)
+}
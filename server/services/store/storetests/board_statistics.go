@@ -0,0 +1,137 @@
+package storetests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestBoardStatistics(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container := store.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("CountCardsByProperty", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCountCardsByProperty(t, store, container)
+	})
+	t.Run("CountCardsCreatedByWeek", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCountCardsCreatedByWeek(t, store, container)
+	})
+	t.Run("CountCardsCompletedByWeek", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCountCardsCompletedByWeek(t, store, container)
+	})
+}
+
+func insertStatisticsCards(t *testing.T, store store.Store, container store.Container) {
+	card := func(id, rootID string, properties map[string]interface{}) model.Block {
+		return model.Block{
+			ID:       id,
+			ParentID: rootID,
+			RootID:   rootID,
+			Type:     "card",
+			Fields:   map[string]interface{}{"properties": properties},
+		}
+	}
+
+	blocks := []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+		card("card-1", "board-1", map[string]interface{}{"status": "done", "owner": "user-1"}),
+		card("card-2", "board-1", map[string]interface{}{"status": "todo", "owner": "user-1"}),
+		card("card-3", "board-1", map[string]interface{}{"status": "done", "owner": "user-2"}),
+		card("card-4", "board-1", map[string]interface{}{"owner": "user-2"}),
+		card("card-5", "board-2", map[string]interface{}{"status": "done"}),
+	}
+	template := card("template-1", "board-1", map[string]interface{}{"status": "done"})
+	template.Fields["isTemplate"] = true
+	deleted := card("card-6", "board-1", map[string]interface{}{"status": "done", "owner": "user-1"})
+	deleted.DeleteAt = 1
+	blocks = append(blocks, template, deleted)
+
+	for i := range blocks {
+		err := store.InsertBlock(context.Background(), container, &blocks[i], testUserID)
+		require.NoError(t, err)
+	}
+}
+
+func testCountCardsByProperty(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	insertStatisticsCards(t, store, container)
+
+	t.Run("should count the cards by value of the property", func(t *testing.T) {
+		counts, err := store.CountCardsByProperty(ctx, container, "board-1", "status")
+		require.NoError(t, err)
+		require.Equal(t, []model.PropertyValueCount{
+			{Value: "done", Count: 2},
+			{Value: "", Count: 1},
+			{Value: "todo", Count: 1},
+		}, counts)
+	})
+
+	t.Run("should count all the cards without a property", func(t *testing.T) {
+		counts, err := store.CountCardsByProperty(ctx, container, "board-1", "")
+		require.NoError(t, err)
+		require.Equal(t, []model.PropertyValueCount{{Value: "", Count: 4}}, counts)
+	})
+
+	t.Run("should count the cards by person", func(t *testing.T) {
+		counts, err := store.CountCardsByProperty(ctx, container, "board-1", "owner")
+		require.NoError(t, err)
+		require.Equal(t, []model.PropertyValueCount{
+			{Value: "user-1", Count: 2},
+			{Value: "user-2", Count: 2},
+		}, counts)
+	})
+
+	t.Run("should return no counts for a board without cards", func(t *testing.T) {
+		counts, err := store.CountCardsByProperty(ctx, container, "board-3", "status")
+		require.NoError(t, err)
+		require.Empty(t, counts)
+	})
+}
+
+func testCountCardsCreatedByWeek(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	now := utils.GetMillis()
+	insertStatisticsCards(t, store, container)
+
+	t.Run("should count the cards created by week", func(t *testing.T) {
+		counts, err := store.CountCardsCreatedByWeek(ctx, container, "board-1", now-3*model.BoardStatisticsWeekMillis)
+		require.NoError(t, err)
+		require.Equal(t, map[int64]int64{3: 4}, counts)
+	})
+
+	t.Run("should not count the cards created before the first week", func(t *testing.T) {
+		counts, err := store.CountCardsCreatedByWeek(ctx, container, "board-1", now+model.BoardStatisticsWeekMillis)
+		require.NoError(t, err)
+		require.Empty(t, counts)
+	})
+}
+
+func testCountCardsCompletedByWeek(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	now := utils.GetMillis()
+	since := now - model.BoardStatisticsWeekMillis
+	insertStatisticsCards(t, store, container)
+
+	t.Run("should count the cards moved into the option", func(t *testing.T) {
+		counts, err := store.CountCardsCompletedByWeek(ctx, container, "board-1", "status", "done", since)
+		require.NoError(t, err)
+		require.Equal(t, map[int64]int64{1: 2}, counts)
+	})
+
+	t.Run("should not count the completions before the first week", func(t *testing.T) {
+		counts, err := store.CountCardsCompletedByWeek(ctx, container, "board-1", "status", "done", now+model.BoardStatisticsWeekMillis)
+		require.NoError(t, err)
+		require.Empty(t, counts)
+	})
+}
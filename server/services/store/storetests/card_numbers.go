@@ -0,0 +1,108 @@
+package storetests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestCardNumbers(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container := store.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("CardNumbers", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCardNumbers(t, store, container)
+	})
+}
+
+func testCardNumbers(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	number := func(block model.Block) int64 {
+		n, _ := model.CardNumber(block)
+		return n
+	}
+	getNumber := func(boardID string, n int64) *model.Block {
+		card, err := store.GetCardByNumber(ctx, container, boardID, n)
+		require.NoError(t, err)
+		return card
+	}
+
+	blocks := []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+		{ID: "board-2", RootID: "board-2", Type: "board"},
+		{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "Card 1"},
+		{ID: "card-2", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "Card 2"},
+		{ID: "card-3", ParentID: "board-2", RootID: "board-2", Type: "card", Title: "Card 3"},
+		{ID: "template-1", ParentID: "board-1", RootID: "board-1", Type: "card", Fields: map[string]interface{}{"isTemplate": true}},
+	}
+	_, err := store.InsertBlocks(ctx, container, blocks, testUserID)
+	require.NoError(t, err)
+
+	t.Run("should number the new cards per board", func(t *testing.T) {
+		require.EqualValues(t, 1, number(blocks[2]))
+		require.EqualValues(t, 2, number(blocks[3]))
+		require.EqualValues(t, 1, number(blocks[4]))
+		require.Zero(t, number(blocks[0]), "boards aren't numbered")
+		require.Zero(t, number(blocks[5]), "card templates aren't numbered")
+
+		card := getNumber("board-1", 2)
+		require.NotNil(t, card)
+		require.Equal(t, "card-2", card.ID)
+
+		card = getNumber("board-2", 1)
+		require.NotNil(t, card)
+		require.Equal(t, "card-3", card.ID)
+
+		require.Nil(t, getNumber("board-1", 3))
+		require.Nil(t, getNumber("board-3", 1))
+	})
+
+	t.Run("should continue after the numbers of the imported cards", func(t *testing.T) {
+		imported := []model.Block{
+			{ID: "card-4", ParentID: "board-1", RootID: "board-1", Type: "card", Fields: map[string]interface{}{"number": float64(10)}},
+			{ID: "card-5", ParentID: "board-1", RootID: "board-1", Type: "card"},
+		}
+		_, err := store.InsertBlocks(ctx, container, imported, testUserID)
+		require.NoError(t, err)
+		require.EqualValues(t, 10, number(imported[0]))
+		require.EqualValues(t, 11, number(imported[1]))
+
+		card := getNumber("board-1", 10)
+		require.NotNil(t, card)
+		require.Equal(t, "card-4", card.ID)
+
+		card = getNumber("board-1", 1)
+		require.NotNil(t, card)
+		require.Equal(t, "card-1", card.ID)
+	})
+
+	// avoid violating the block_history composite primary key constraint
+	// with a quick update of the cards
+	time.Sleep(1 * time.Second)
+
+	t.Run("should keep the number of an updated card", func(t *testing.T) {
+		updated := []model.Block{
+			{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card", Title: "Renamed"},
+		}
+		_, err := store.InsertBlocks(ctx, container, updated, testUserID)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, number(updated[0]))
+
+		card := getNumber("board-1", 1)
+		require.NotNil(t, card)
+		require.Equal(t, "Renamed", card.Title)
+	})
+
+	t.Run("should not return a deleted card", func(t *testing.T) {
+		err := store.DeleteBlock(ctx, container, "card-2", testUserID)
+		require.NoError(t, err)
+		require.Nil(t, getNumber("board-1", 2))
+	})
+}
@@ -0,0 +1,87 @@
+package storetests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestDigests(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("Digests", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDigests(t, store)
+	})
+	t.Run("BoardSubscriptions", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetBoardSubscriptions(t, store)
+	})
+}
+
+func testDigests(t *testing.T, store store.Store) {
+	ctx := context.Background()
+
+	lastDigestAt, err := store.GetLastDigestAt(ctx, "user-1")
+	require.NoError(t, err)
+	require.Zero(t, lastDigestAt)
+
+	t.Run("should claim the first digest once", func(t *testing.T) {
+		claimed, err := store.ClaimUserDigest(ctx, "user-1", 0, 1000)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = store.ClaimUserDigest(ctx, "user-1", 0, 1001)
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		lastDigestAt, err := store.GetLastDigestAt(ctx, "user-1")
+		require.NoError(t, err)
+		require.EqualValues(t, 1000, lastDigestAt)
+	})
+
+	t.Run("should only claim the next digest after the last one", func(t *testing.T) {
+		claimed, err := store.ClaimUserDigest(ctx, "user-1", 900, 2000)
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		claimed, err = store.ClaimUserDigest(ctx, "user-1", 1000, 2000)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = store.ClaimUserDigest(ctx, "user-1", 1000, 2001)
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		lastDigestAt, err := store.GetLastDigestAt(ctx, "user-1")
+		require.NoError(t, err)
+		require.EqualValues(t, 2000, lastDigestAt)
+	})
+}
+
+func testGetBoardSubscriptions(t *testing.T, store store.Store) {
+	ctx := context.Background()
+	subscriptions := []model.Subscription{
+		{BlockType: "board", BlockID: "board-1", WorkspaceID: "workspace-1", SubscriberID: "user-2"},
+		{BlockType: "card", BlockID: "card-1", WorkspaceID: "workspace-1", SubscriberID: "user-1"},
+		{BlockType: "board", BlockID: "board-2", WorkspaceID: "workspace-2", SubscriberID: "user-1"},
+		{BlockType: "board", BlockID: "board-1", WorkspaceID: "workspace-1", SubscriberID: "user-1"},
+	}
+	for _, subscription := range subscriptions {
+		subscription.CreateAt = utils.GetMillis()
+		require.NoError(t, store.CreateSubscription(ctx, subscription))
+	}
+
+	boardSubscriptions, err := store.GetBoardSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, boardSubscriptions, 3)
+	ids := []string{}
+	for _, subscription := range boardSubscriptions {
+		ids = append(ids, subscription.SubscriberID+"/"+subscription.WorkspaceID+"/"+subscription.BlockID)
+	}
+	require.Equal(t, []string{"user-1/workspace-1/board-1", "user-1/workspace-2/board-2", "user-2/workspace-1/board-1"}, ids)
+}
@@ -0,0 +1,110 @@
+package storetests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestFiles(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container1 := store.Container{WorkspaceID: "workspace-1"}
+	container2 := store.Container{WorkspaceID: "workspace-2"}
+
+	t.Run("GetWorkspaceUsage", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetWorkspaceUsage(t, store, container1, container2)
+	})
+
+	t.Run("GetFileInfo", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetFileInfo(t, store)
+	})
+}
+
+func testGetWorkspaceUsage(t *testing.T, store store.Store, container1, container2 store.Container) {
+	ctx := context.Background()
+
+	_, err := store.InsertBlocks(ctx, container1, []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+		{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"},
+		{ID: "card-2", ParentID: "board-1", RootID: "board-1", Type: "card", DeleteAt: utils.GetMillis()},
+	}, testUserID)
+	require.NoError(t, err)
+	_, err = store.InsertBlocks(ctx, container2, []model.Block{
+		{ID: "board-2", RootID: "board-2", Type: "board"},
+	}, testUserID)
+	require.NoError(t, err)
+
+	for _, info := range []model.FileInfo{
+		{ID: "file-1.png", WorkspaceID: "workspace-1", RootID: "board-1", Size: 100, CreateAt: 1},
+		{ID: "file-2.png", WorkspaceID: "workspace-1", RootID: "board-1", Size: 250, CreateAt: 2},
+		{ID: "file-3.png", WorkspaceID: "workspace-2", RootID: "board-2", Size: 1000, CreateAt: 3},
+	} {
+		require.NoError(t, store.InsertFileInfo(info))
+	}
+
+	t.Run("should count the blocks that aren't deleted and sum the files", func(t *testing.T) {
+		usage, err := store.GetWorkspaceUsage("workspace-1")
+		require.NoError(t, err)
+		require.Equal(t, model.WorkspaceUsage{BlockCount: 2, FileBytes: 350}, *usage)
+
+		usage, err = store.GetWorkspaceUsage("workspace-2")
+		require.NoError(t, err)
+		require.Equal(t, model.WorkspaceUsage{BlockCount: 1, FileBytes: 1000}, *usage)
+	})
+
+	t.Run("should return no usage for an empty workspace", func(t *testing.T) {
+		usage, err := store.GetWorkspaceUsage("workspace-3")
+		require.NoError(t, err)
+		require.Equal(t, model.WorkspaceUsage{}, *usage)
+	})
+
+	t.Run("should leave out the deleted files", func(t *testing.T) {
+		require.NoError(t, store.DeleteFileInfo("file-2.png"))
+		require.NoError(t, store.DeleteFileInfo("unknown.png"))
+
+		usage, err := store.GetWorkspaceUsage("workspace-1")
+		require.NoError(t, err)
+		require.EqualValues(t, 100, usage.FileBytes)
+	})
+}
+
+func testGetFileInfo(t *testing.T, store store.Store) {
+	info := model.FileInfo{
+		ID:          "file-1.png",
+		WorkspaceID: "workspace-1",
+		RootID:      "board-1",
+		Size:        100,
+		CreateAt:    1,
+		Path:        "workspace-1/board-1/file-1.png",
+		Name:        "diagram.png",
+		MimeType:    "image/png",
+	}
+	require.NoError(t, store.InsertFileInfo(info))
+
+	t.Run("should return the file without a thumbnail", func(t *testing.T) {
+		got, err := store.GetFileInfo("file-1.png")
+		require.NoError(t, err)
+		require.Equal(t, info, *got)
+	})
+
+	t.Run("should record the thumbnail", func(t *testing.T) {
+		require.NoError(t, store.SetFileThumbnailPath("file-1.png", "workspace-1/board-1/file-1.png.thumbnail.jpg"))
+
+		got, err := store.GetFileInfo("file-1.png")
+		require.NoError(t, err)
+		require.Equal(t, "workspace-1/board-1/file-1.png.thumbnail.jpg", got.ThumbnailPath)
+	})
+
+	t.Run("should return no rows for an unknown file", func(t *testing.T) {
+		_, err := store.GetFileInfo("unknown.png")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
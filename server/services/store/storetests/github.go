@@ -0,0 +1,125 @@
+package storetests
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestGitHubIntegrations(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container := store.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("GitHubIntegrations", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGitHubIntegrations(t, store, container)
+	})
+	t.Run("GitHubIssueLinks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGitHubIssueLinks(t, store, container)
+	})
+}
+
+func testGitHubIntegrations(t *testing.T, store store.Store, container store.Container) {
+	_, err := store.GetGitHubIntegration(container, "board-1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	integration := model.GitHubIntegration{
+		BoardID:                "board-1",
+		WorkspaceID:            container.WorkspaceID,
+		Repository:             "owner/repo",
+		TokenEncrypted:         "encrypted-token",
+		WebhookSecretEncrypted: "encrypted-secret",
+		LabelFilter:            []string{"bug", "ui"},
+		CommentOnClose:         true,
+		StatePropertyID:        "state-id",
+		LabelsPropertyID:       "labels-id",
+		CreatedBy:              testUserID,
+		CreateAt:               1000,
+	}
+	require.NoError(t, store.UpsertGitHubIntegration(container, integration))
+
+	stored, err := store.GetGitHubIntegration(container, "board-1")
+	require.NoError(t, err)
+	require.Equal(t, integration, *stored)
+
+	t.Run("should replace the configuration", func(t *testing.T) {
+		integration.Repository = "owner/other"
+		integration.LabelFilter = []string{}
+		integration.CommentOnClose = false
+		require.NoError(t, store.UpsertGitHubIntegration(container, integration))
+
+		stored, err := store.GetGitHubIntegration(container, "board-1")
+		require.NoError(t, err)
+		require.Equal(t, integration, *stored)
+	})
+
+	t.Run("should update the sync", func(t *testing.T) {
+		require.NoError(t, store.UpdateGitHubIntegrationSync(container, "board-1", 2000, 3000, "rate limited"))
+
+		integrations, err := store.GetGitHubIntegrations()
+		require.NoError(t, err)
+		require.Len(t, integrations, 1)
+		require.Equal(t, int64(2000), integrations[0].LastSyncAt)
+		require.Equal(t, int64(3000), integrations[0].RateLimitResetAt)
+		require.Equal(t, "rate limited", integrations[0].LastError)
+	})
+
+	t.Run("should not return the integration of another workspace", func(t *testing.T) {
+		other := container
+		other.WorkspaceID = "other-workspace"
+		_, err := store.GetGitHubIntegration(other, "board-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.ErrorIs(t, store.DeleteGitHubIntegration(other, "board-1"), sql.ErrNoRows)
+	})
+
+	t.Run("should delete the integration", func(t *testing.T) {
+		require.NoError(t, store.DeleteGitHubIntegration(container, "board-1"))
+		_, err := store.GetGitHubIntegration(container, "board-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.ErrorIs(t, store.DeleteGitHubIntegration(container, "board-1"), sql.ErrNoRows)
+	})
+}
+
+func testGitHubIssueLinks(t *testing.T, store store.Store, container store.Container) {
+	integration := model.GitHubIntegration{BoardID: "board-1", WorkspaceID: container.WorkspaceID, Repository: "owner/repo"}
+	require.NoError(t, store.UpsertGitHubIntegration(container, integration))
+
+	links, err := store.GetGitHubIssueLinks(container, "board-1")
+	require.NoError(t, err)
+	require.Empty(t, links)
+
+	link1 := model.GitHubIssueLink{BoardID: "board-1", IssueNumber: 1, CardID: "card-1", State: model.GitHubStateOpen, IssueUpdatedAt: 1000}
+	link2 := model.GitHubIssueLink{BoardID: "board-1", IssueNumber: 2, CardID: "card-2", State: model.GitHubStateClosed, CardClosed: true, IssueUpdatedAt: 2000}
+	require.NoError(t, store.UpsertGitHubIssueLink(container, link2))
+	require.NoError(t, store.UpsertGitHubIssueLink(container, link1))
+
+	links, err = store.GetGitHubIssueLinks(container, "board-1")
+	require.NoError(t, err)
+	require.Equal(t, []model.GitHubIssueLink{link1, link2}, links)
+
+	t.Run("should update the link of an issue", func(t *testing.T) {
+		link1.State = model.GitHubStateClosed
+		link1.CardClosed = true
+		link1.IssueUpdatedAt = 3000
+		require.NoError(t, store.UpsertGitHubIssueLink(container, link1))
+
+		links, err := store.GetGitHubIssueLinks(container, "board-1")
+		require.NoError(t, err)
+		require.Equal(t, []model.GitHubIssueLink{link1, link2}, links)
+	})
+
+	t.Run("should delete the links with the integration", func(t *testing.T) {
+		require.NoError(t, store.DeleteGitHubIntegration(container, "board-1"))
+
+		links, err := store.GetGitHubIssueLinks(container, "board-1")
+		require.NoError(t, err)
+		require.Empty(t, links)
+	})
+}
@@ -0,0 +1,83 @@
+package storetests
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestGuestInvites(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("CreateAndConsumeGuestInvite", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCreateAndConsumeGuestInvite(t, store)
+	})
+
+	t.Run("DeleteExpiredGuestInvites", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteExpiredGuestInvites(t, store)
+	})
+
+	t.Run("Guests", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGuestUsers(t, store)
+	})
+}
+
+func testCreateAndConsumeGuestInvite(t *testing.T, store store.Store) {
+	invite := model.GuestInvite{
+		TokenHash:   model.HashToken("secret"),
+		WorkspaceID: "0",
+		BoardID:     "board-1",
+		Role:        model.BoardRoleEditor,
+		CreatedBy:   "user-1",
+		CreateAt:    1000,
+		ExpireAt:    2000,
+	}
+	require.NoError(t, store.CreateGuestInvite(invite))
+
+	got, err := store.ConsumeGuestInvite(invite.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, invite, *got)
+
+	// the invites can only be used once
+	_, err = store.ConsumeGuestInvite(invite.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteExpiredGuestInvites(t *testing.T, store store.Store) {
+	for _, invite := range []model.GuestInvite{
+		{TokenHash: model.HashToken("expired"), BoardID: "board-1", CreateAt: 1000, ExpireAt: 2000},
+		{TokenHash: model.HashToken("valid"), BoardID: "board-1", CreateAt: 1000, ExpireAt: 4000},
+	} {
+		require.NoError(t, store.CreateGuestInvite(invite))
+	}
+
+	require.NoError(t, store.DeleteExpiredGuestInvites(3000))
+
+	_, err := store.ConsumeGuestInvite(model.HashToken("expired"))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.ConsumeGuestInvite(model.HashToken("valid"))
+	require.NoError(t, err)
+}
+
+func testGuestUsers(t *testing.T, store store.Store) {
+	guest := &model.User{ID: "guest-1", Username: "guest", Email: "guest@example.com", IsGuest: true}
+	require.NoError(t, store.CreateUser(guest))
+	user := &model.User{ID: "user-1", Username: "user", Email: "user@example.com"}
+	require.NoError(t, store.CreateUser(user))
+
+	got, err := store.GetUserByID(guest.ID)
+	require.NoError(t, err)
+	require.True(t, got.IsGuest)
+
+	got, err = store.GetUserByID(user.ID)
+	require.NoError(t, err)
+	require.False(t, got.IsGuest)
+}
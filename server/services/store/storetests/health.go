@@ -0,0 +1,94 @@
+package storetests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestHealth(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container := store.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("Ping", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testPing(t, store)
+	})
+	t.Run("GetSchemaVersion", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetSchemaVersion(t, store)
+	})
+	t.Run("GetMigrations", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetMigrations(t, store)
+	})
+	t.Run("CountOrphanedBlocks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCountOrphanedBlocks(t, store, container)
+	})
+}
+
+func testPing(t *testing.T, store store.Store) {
+	t.Run("should reach the database", func(t *testing.T) {
+		require.NoError(t, store.Ping(context.Background()))
+	})
+
+	t.Run("should fail with a canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.Error(t, store.Ping(ctx))
+	})
+}
+
+func testGetSchemaVersion(t *testing.T, store store.Store) {
+	version, err := store.GetSchemaVersion(context.Background())
+	require.NoError(t, err)
+	require.Positive(t, version)
+}
+
+func testGetMigrations(t *testing.T, store store.Store) {
+	ctx := context.Background()
+	version, err := store.GetSchemaVersion(ctx)
+	require.NoError(t, err)
+
+	t.Run("should have no pending migrations", func(t *testing.T) {
+		pending, err := store.GetPendingMigrations(ctx)
+		require.NoError(t, err)
+		require.Empty(t, pending)
+	})
+
+	t.Run("should list the applied migrations", func(t *testing.T) {
+		applied, err := store.GetAppliedMigrations(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, applied)
+		require.Equal(t, version, applied[len(applied)-1].Version)
+		for _, migration := range applied {
+			require.NotEmpty(t, migration.Name)
+		}
+	})
+}
+
+func testCountOrphanedBlocks(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+
+	blocks := []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board", ModifiedBy: "user-1"},
+		{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card", ModifiedBy: "user-1"},
+		{ID: "card-2", RootID: "missing-board", ParentID: "missing-board", Type: "card", ModifiedBy: "user-1"},
+	}
+	for _, block := range blocks {
+		require.NoError(t, store.InsertBlock(ctx, container, &block, "user-1"))
+	}
+
+	count, err := store.CountOrphanedBlocks(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+}
@@ -1,6 +1,7 @@
 package storetests
 
 import (
+	"context"
 	"testing"
 
 	"github.com/mattermost/focalboard/server/model"
@@ -9,15 +10,17 @@ import (
 )
 
 func InsertBlocks(t *testing.T, s store.Store, container store.Container, blocks []model.Block, userID string) {
+	ctx := context.Background()
 	for i := range blocks {
-		err := s.InsertBlock(container, &blocks[i], userID)
+		err := s.InsertBlock(ctx, container, &blocks[i], userID)
 		require.NoError(t, err)
 	}
 }
 
 func DeleteBlocks(t *testing.T, s store.Store, container store.Container, blocks []model.Block, modifiedBy string) {
+	ctx := context.Background()
 	for _, block := range blocks {
-		err := s.DeleteBlock(container, block.ID, modifiedBy)
+		err := s.DeleteBlock(ctx, container, block.ID, modifiedBy)
 		require.NoError(t, err)
 	}
 }
@@ -0,0 +1,92 @@
+package storetests
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestIdempotencyKeys(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("CreateAndGetIdempotencyKey", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCreateAndGetIdempotencyKey(t, store)
+	})
+
+	t.Run("DeleteIdempotencyKey", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteIdempotencyKey(t, store)
+	})
+
+	t.Run("DeleteExpiredIdempotencyKeys", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteExpiredIdempotencyKeys(t, store)
+	})
+}
+
+func testCreateAndGetIdempotencyKey(t *testing.T, store store.Store) {
+	key := model.IdempotencyKey{
+		Key:         "key-1",
+		UserID:      "user-1",
+		RequestHash: "hash-1",
+		CreateAt:    1000,
+		ExpireAt:    2000,
+	}
+	require.NoError(t, store.CreateIdempotencyKey(key))
+
+	got, err := store.GetIdempotencyKey("user-1", "key-1")
+	require.NoError(t, err)
+	require.Equal(t, key, *got)
+	require.True(t, got.IsPending())
+
+	// a key is only claimed once per user
+	require.Error(t, store.CreateIdempotencyKey(key))
+	other := key
+	other.UserID = "user-2"
+	require.NoError(t, store.CreateIdempotencyKey(other))
+
+	require.NoError(t, store.SetIdempotencyKeyResponse("user-1", "key-1", 200, `{"id":"block-1"}`))
+	got, err = store.GetIdempotencyKey("user-1", "key-1")
+	require.NoError(t, err)
+	require.Equal(t, 200, got.StatusCode)
+	require.Equal(t, `{"id":"block-1"}`, got.Response)
+
+	_, err = store.GetIdempotencyKey("user-1", "key-2")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteIdempotencyKey(t *testing.T, store store.Store) {
+	key := model.IdempotencyKey{Key: "key-1", UserID: "user-1", RequestHash: "hash-1", CreateAt: 1000, ExpireAt: 2000}
+	require.NoError(t, store.CreateIdempotencyKey(key))
+
+	// the key claimed again isn't deleted
+	require.NoError(t, store.DeleteIdempotencyKey("user-1", "key-1", 1001))
+	_, err := store.GetIdempotencyKey("user-1", "key-1")
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteIdempotencyKey("user-1", "key-1", 1000))
+	_, err = store.GetIdempotencyKey("user-1", "key-1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteExpiredIdempotencyKeys(t *testing.T, store store.Store) {
+	for _, key := range []model.IdempotencyKey{
+		{Key: "expired", UserID: "user-1", CreateAt: 1000, ExpireAt: 2000},
+		{Key: "valid", UserID: "user-1", CreateAt: 1000, ExpireAt: 4000},
+	} {
+		require.NoError(t, store.CreateIdempotencyKey(key))
+	}
+
+	require.NoError(t, store.DeleteExpiredIdempotencyKeys(3000))
+
+	_, err := store.GetIdempotencyKey("user-1", "expired")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.GetIdempotencyKey("user-1", "valid")
+	require.NoError(t, err)
+}
@@ -0,0 +1,111 @@
+package storetests
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestInboundHooks(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container := store.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("InboundHooks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testInboundHooks(t, store, container)
+	})
+	t.Run("InboundHookDeliveries", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testInboundHookDeliveries(t, store, container)
+	})
+}
+
+func testInboundHooks(t *testing.T, store store.Store, container store.Container) {
+	_, err := store.GetInboundHook(container, "board-1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	hook := model.InboundHook{BoardID: "board-1", WorkspaceID: container.WorkspaceID, TokenHash: "hash-1", CreatedBy: testUserID, CreateAt: 1000}
+	require.NoError(t, store.UpsertInboundHook(container, hook))
+
+	stored, err := store.GetInboundHook(container, "board-1")
+	require.NoError(t, err)
+	require.Equal(t, hook, *stored)
+
+	t.Run("should replace the token", func(t *testing.T) {
+		hook := model.InboundHook{BoardID: "board-1", WorkspaceID: container.WorkspaceID, TokenHash: "hash-2", AllowNewOptions: true, CreatedBy: "user-2", CreateAt: 2000}
+		require.NoError(t, store.UpsertInboundHook(container, hook))
+
+		stored, err := store.GetInboundHookByTokenHash("hash-2")
+		require.NoError(t, err)
+		require.Equal(t, hook, *stored)
+
+		_, err = store.GetInboundHookByTokenHash("hash-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("should not return the hook of another workspace", func(t *testing.T) {
+		other := container
+		other.WorkspaceID = "other-workspace"
+		_, err := store.GetInboundHook(other, "board-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.ErrorIs(t, store.DeleteInboundHook(other, "board-1"), sql.ErrNoRows)
+	})
+
+	t.Run("should revoke the hook", func(t *testing.T) {
+		require.NoError(t, store.DeleteInboundHook(container, "board-1"))
+		_, err := store.GetInboundHookByTokenHash("hash-2")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.ErrorIs(t, store.DeleteInboundHook(container, "board-1"), sql.ErrNoRows)
+	})
+}
+
+func testInboundHookDeliveries(t *testing.T, store store.Store, container store.Container) {
+	for i := 1; i <= 5; i++ {
+		delivery := model.InboundHookDelivery{
+			ID:         fmt.Sprintf("delivery-%d", i),
+			BoardID:    "board-1",
+			CardID:     "card-1",
+			Payload:    `{"title":"Card"}`,
+			StatusCode: 200,
+			CreateAt:   int64(i * 1000),
+		}
+		require.NoError(t, store.InsertInboundHookDelivery(container, delivery, 3))
+	}
+	failed := model.InboundHookDelivery{ID: "delivery-other", BoardID: "board-2", Payload: "{", StatusCode: 400, Error: "invalid payload", CreateAt: 1000}
+	require.NoError(t, store.InsertInboundHookDelivery(container, failed, 3))
+
+	t.Run("should keep the last deliveries of the board", func(t *testing.T) {
+		deliveries, err := store.GetInboundHookDeliveries(container, "board-1", 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 3)
+		require.Equal(t, "delivery-5", deliveries[0].ID)
+		require.Equal(t, "delivery-3", deliveries[2].ID)
+		require.Equal(t, "card-1", deliveries[0].CardID)
+	})
+
+	t.Run("should limit the deliveries", func(t *testing.T) {
+		deliveries, err := store.GetInboundHookDeliveries(container, "board-1", 1)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, "delivery-5", deliveries[0].ID)
+	})
+
+	t.Run("should return the deliveries of each board", func(t *testing.T) {
+		deliveries, err := store.GetInboundHookDeliveries(container, "board-2", 10)
+		require.NoError(t, err)
+		require.Equal(t, []model.InboundHookDelivery{failed}, deliveries)
+
+		other := container
+		other.WorkspaceID = "other-workspace"
+		deliveries, err = store.GetInboundHookDeliveries(other, "board-1", 10)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+	})
+}
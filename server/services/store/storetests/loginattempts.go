@@ -0,0 +1,62 @@
+package storetests
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestLoginAttemptsStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("UpsertAndGetLoginAttempts", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testUpsertAndGetLoginAttempts(t, store)
+	})
+
+	t.Run("DeleteStaleLoginAttempts", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteStaleLoginAttempts(t, store)
+	})
+}
+
+func testUpsertAndGetLoginAttempts(t *testing.T, store store.Store) {
+	_, err := store.GetLoginAttempts("user:unknown")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	attempts := model.LoginAttempts{Key: "user:username", Failures: 1, LastFailureAt: 1000}
+	require.NoError(t, store.UpsertLoginAttempts(attempts))
+
+	attempts.Failures = 2
+	attempts.LockedUntil = 5000
+	require.NoError(t, store.UpsertLoginAttempts(attempts))
+
+	got, err := store.GetLoginAttempts("user:username")
+	require.NoError(t, err)
+	require.Equal(t, attempts, *got)
+
+	require.NoError(t, store.DeleteLoginAttempts("user:username"))
+	_, err = store.GetLoginAttempts("user:username")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteStaleLoginAttempts(t *testing.T, store store.Store) {
+	stale := model.LoginAttempts{Key: "ip:stale", Failures: 2, LastFailureAt: 1000}
+	recent := model.LoginAttempts{Key: "ip:recent", Failures: 2, LastFailureAt: 3000}
+	locked := model.LoginAttempts{Key: "ip:locked", Failures: 10, LastFailureAt: 1000, LockedUntil: 4000}
+	for _, attempts := range []model.LoginAttempts{stale, recent, locked} {
+		require.NoError(t, store.UpsertLoginAttempts(attempts))
+	}
+
+	require.NoError(t, store.DeleteStaleLoginAttempts(2000))
+
+	_, err := store.GetLoginAttempts(stale.Key)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetLoginAttempts(recent.Key)
+	require.NoError(t, err)
+	_, err = store.GetLoginAttempts(locked.Key)
+	require.NoError(t, err)
+}
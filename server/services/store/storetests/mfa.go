@@ -0,0 +1,80 @@
+package storetests
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestMfaStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("UpdateUserMfa", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testUpdateUserMfa(t, store)
+	})
+
+	t.Run("MfaRecoveryCodes", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testMfaRecoveryCodes(t, store)
+	})
+}
+
+func testUpdateUserMfa(t *testing.T, store store.Store) {
+	user := &model.User{
+		ID:       utils.CreateGUID(),
+		Username: "mfa-user",
+		Email:    "mfa@example.com",
+	}
+	require.NoError(t, store.CreateUser(user))
+
+	got, err := store.GetUserByID(user.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.MfaSecret)
+	require.False(t, got.MfaActive)
+
+	require.NoError(t, store.UpdateUserMfa(user.ID, "encrypted", true))
+	got, err = store.GetUserByID(user.ID)
+	require.NoError(t, err)
+	require.Equal(t, "encrypted", got.MfaSecret)
+	require.True(t, got.MfaActive)
+
+	require.NoError(t, store.UpdateUserMfa(user.ID, "", false))
+	got, err = store.GetUserByID(user.ID)
+	require.NoError(t, err)
+	require.Empty(t, got.MfaSecret)
+	require.False(t, got.MfaActive)
+
+	t.Run("unknown user", func(t *testing.T) {
+		require.Error(t, store.UpdateUserMfa("unknown", "encrypted", true))
+	})
+}
+
+func testMfaRecoveryCodes(t *testing.T, store store.Store) {
+	require.NoError(t, store.SetMfaRecoveryCodes("user-1", []string{"hash-1", "hash-2"}))
+	require.NoError(t, store.SetMfaRecoveryCodes("user-2", []string{"hash-1"}))
+
+	t.Run("the codes can only be used once", func(t *testing.T) {
+		require.NoError(t, store.ConsumeMfaRecoveryCode("user-1", "hash-1"))
+		require.ErrorIs(t, store.ConsumeMfaRecoveryCode("user-1", "hash-1"), sql.ErrNoRows)
+
+		// the same code of another user is still valid
+		require.NoError(t, store.ConsumeMfaRecoveryCode("user-2", "hash-1"))
+	})
+
+	t.Run("setting the codes replaces the previous ones", func(t *testing.T) {
+		require.NoError(t, store.SetMfaRecoveryCodes("user-1", []string{"hash-3"}))
+		require.ErrorIs(t, store.ConsumeMfaRecoveryCode("user-1", "hash-2"), sql.ErrNoRows)
+		require.NoError(t, store.ConsumeMfaRecoveryCode("user-1", "hash-3"))
+	})
+
+	t.Run("delete the codes", func(t *testing.T) {
+		require.NoError(t, store.SetMfaRecoveryCodes("user-1", []string{"hash-4"}))
+		require.NoError(t, store.DeleteMfaRecoveryCodes("user-1"))
+		require.ErrorIs(t, store.ConsumeMfaRecoveryCode("user-1", "hash-4"), sql.ErrNoRows)
+	})
+}
@@ -0,0 +1,69 @@
+package storetests
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestPasswordResetStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("CreateAndConsumePasswordResetToken", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCreateAndConsumePasswordResetToken(t, store)
+	})
+
+	t.Run("DeletePasswordResetTokens", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeletePasswordResetTokens(t, store)
+	})
+}
+
+func testCreateAndConsumePasswordResetToken(t *testing.T, store store.Store) {
+	token := model.PasswordResetToken{
+		TokenHash: model.HashToken("secret"),
+		UserID:    "user-1",
+		CreateAt:  1000,
+		ExpireAt:  2000,
+	}
+	require.NoError(t, store.CreatePasswordResetToken(token))
+
+	got, err := store.ConsumePasswordResetToken(token.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, token, *got)
+
+	// the tokens can only be used once
+	_, err = store.ConsumePasswordResetToken(token.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeletePasswordResetTokens(t *testing.T, store store.Store) {
+	tokens := []model.PasswordResetToken{
+		{TokenHash: model.HashToken("first"), UserID: "user-1", CreateAt: 1000, ExpireAt: 2000},
+		{TokenHash: model.HashToken("second"), UserID: "user-1", CreateAt: 1000, ExpireAt: 5000},
+		{TokenHash: model.HashToken("third"), UserID: "user-2", CreateAt: 1000, ExpireAt: 5000},
+	}
+	for _, token := range tokens {
+		require.NoError(t, store.CreatePasswordResetToken(token))
+	}
+
+	t.Run("DeleteExpiredPasswordResetTokens", func(t *testing.T) {
+		require.NoError(t, store.DeleteExpiredPasswordResetTokens(3000))
+
+		_, err := store.ConsumePasswordResetToken(tokens[0].TokenHash)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("DeletePasswordResetTokensForUser", func(t *testing.T) {
+		require.NoError(t, store.DeletePasswordResetTokensForUser("user-1"))
+
+		_, err := store.ConsumePasswordResetToken(tokens[1].TokenHash)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = store.ConsumePasswordResetToken(tokens[2].TokenHash)
+		require.NoError(t, err)
+	})
+}
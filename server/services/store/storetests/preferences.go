@@ -0,0 +1,87 @@
+package storetests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestPreferences(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("UpsertAndGetPreferences", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testUpsertAndGetPreferences(t, store)
+	})
+
+	t.Run("DeletePreferences", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeletePreferences(t, store)
+	})
+}
+
+func testUpsertAndGetPreferences(t *testing.T, store store.Store) {
+	ctx := context.Background()
+
+	preferences, err := store.GetPreferences(ctx, "user-1")
+	require.NoError(t, err)
+	require.Empty(t, preferences)
+
+	require.NoError(t, store.UpsertPreferences(ctx, "user-1", []model.Preference{
+		{Category: "client_tours", Name: "onboarding", Value: "dismissed"},
+		{Category: "client_display", Name: "theme", Value: `{"mainBg":"#ffffff"}`},
+	}))
+	require.NoError(t, store.UpsertPreferences(ctx, "user-2", []model.Preference{
+		{Category: "client_display", Name: "theme", Value: "dark"},
+	}))
+
+	// the values of the preferences that are set are replaced
+	require.NoError(t, store.UpsertPreferences(ctx, "user-1", []model.Preference{
+		{Category: "client_display", Name: "theme", Value: "light"},
+		{Category: "client_display", Name: "language", Value: "fr"},
+	}))
+
+	preferences, err = store.GetPreferences(ctx, "user-1")
+	require.NoError(t, err)
+	require.Equal(t, []model.Preference{
+		{UserID: "user-1", Category: "client_display", Name: "language", Value: "fr"},
+		{UserID: "user-1", Category: "client_display", Name: "theme", Value: "light"},
+		{UserID: "user-1", Category: "client_tours", Name: "onboarding", Value: "dismissed"},
+	}, preferences)
+
+	preferences, err = store.GetPreferences(ctx, "user-2")
+	require.NoError(t, err)
+	require.Equal(t, []model.Preference{
+		{UserID: "user-2", Category: "client_display", Name: "theme", Value: "dark"},
+	}, preferences)
+}
+
+func testDeletePreferences(t *testing.T, store store.Store) {
+	ctx := context.Background()
+
+	require.NoError(t, store.UpsertPreferences(ctx, "user-1", []model.Preference{
+		{Category: "client_display", Name: "theme", Value: "light"},
+		{Category: "client_tours", Name: "onboarding", Value: "dismissed"},
+	}))
+	require.NoError(t, store.UpsertPreferences(ctx, "user-2", []model.Preference{
+		{Category: "client_tours", Name: "onboarding", Value: "dismissed"},
+	}))
+
+	require.NoError(t, store.DeletePreferences(ctx, "user-1", []model.Preference{
+		{Category: "client_tours", Name: "onboarding"},
+		{Category: "client_tours", Name: "unknown"},
+	}))
+
+	preferences, err := store.GetPreferences(ctx, "user-1")
+	require.NoError(t, err)
+	require.Equal(t, []model.Preference{
+		{UserID: "user-1", Category: "client_display", Name: "theme", Value: "light"},
+	}, preferences)
+
+	preferences, err = store.GetPreferences(ctx, "user-2")
+	require.NoError(t, err)
+	require.Len(t, preferences, 1, "the preferences of the other users are kept")
+}
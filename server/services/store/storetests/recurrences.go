@@ -0,0 +1,42 @@
+package storetests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestRecurrenceStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	t.Run("RecurrenceRuns", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testRecurrenceRuns(t, store)
+	})
+}
+
+func testRecurrenceRuns(t *testing.T, store store.Store) {
+	ctx := context.Background()
+
+	lastRun, err := store.GetLastRecurrenceRun(ctx, "recurrence-1")
+	require.NoError(t, err)
+	require.Zero(t, lastRun)
+
+	for _, run := range []model.RecurrenceRun{
+		{RecurrenceID: "recurrence-1", RunAt: 1000, CardID: "card-1", CreateAt: 1001},
+		{RecurrenceID: "recurrence-1", RunAt: 3000, CardID: "card-2", CreateAt: 3001},
+		{RecurrenceID: "recurrence-2", RunAt: 5000, CardID: "card-3", CreateAt: 5001},
+	} {
+		require.NoError(t, store.InsertRecurrenceRun(ctx, run))
+	}
+
+	lastRun, err = store.GetLastRecurrenceRun(ctx, "recurrence-1")
+	require.NoError(t, err)
+	require.EqualValues(t, 3000, lastRun)
+
+	// an occurrence only creates one card
+	err = store.InsertRecurrenceRun(ctx, model.RecurrenceRun{RecurrenceID: "recurrence-1", RunAt: 3000, CardID: "card-4", CreateAt: 3002})
+	require.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package storetests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestRelations(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container := store.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("GetCardBacklinks", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetCardBacklinks(t, store, container)
+	})
+}
+
+func testGetCardBacklinks(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	card := func(id string, properties map[string]interface{}) model.Block {
+		return model.Block{
+			ID:       id,
+			ParentID: "board-1",
+			RootID:   "board-1",
+			Type:     "card",
+			Fields:   map[string]interface{}{"properties": properties},
+		}
+	}
+
+	blocks := []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+		card("card-1", nil),
+		card("card-2", map[string]interface{}{"related": []interface{}{"card-1"}}),
+		card("card-3", map[string]interface{}{"related": []interface{}{"card-2", "card-1"}, "blocked": []interface{}{"card-1"}}),
+		card("card-4", map[string]interface{}{"related": []interface{}{"card-11"}}),
+		card("card-5", map[string]interface{}{"text": "card-1"}),
+		card("card-6", map[string]interface{}{"related": []interface{}{"card-1"}}),
+		{ID: "view-1", ParentID: "board-1", RootID: "board-1", Type: "view", Fields: map[string]interface{}{"cardOrder": []interface{}{"card-1"}}},
+	}
+	blocks[6].DeleteAt = 1
+	_, err := store.InsertBlocks(ctx, container, blocks, testUserID)
+	require.NoError(t, err)
+
+	otherContainer := container
+	otherContainer.WorkspaceID = "1"
+	otherWorkspaceCard := card("card-7", map[string]interface{}{"related": []interface{}{"card-1"}})
+	err = store.InsertBlock(ctx, otherContainer, &otherWorkspaceCard, testUserID)
+	require.NoError(t, err)
+
+	t.Run("should return the cards linking to the card", func(t *testing.T) {
+		backlinks, err := store.GetCardBacklinks(ctx, container, "card-1")
+		require.NoError(t, err)
+		ids := []string{}
+		for _, block := range backlinks {
+			ids = append(ids, block.ID)
+		}
+		require.Equal(t, []string{"card-2", "card-3"}, ids)
+	})
+
+	t.Run("should return no cards for a card without links", func(t *testing.T) {
+		backlinks, err := store.GetCardBacklinks(ctx, container, "card-3")
+		require.NoError(t, err)
+		require.Empty(t, backlinks)
+	})
+}
@@ -32,19 +32,32 @@ func StoreTestSessionStore(t *testing.T, setup func(t *testing.T) (store.Store,
 		defer tearDown()
 		testUpdateSession(t, store, container)
 	})
+
+	t.Run("DeleteSessionsForUser", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteSessionsForUser(t, store, container)
+	})
+
+	t.Run("SessionExpiry", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testSessionExpiry(t, store, container)
+	})
 }
 
 func testCreateAndGetAndDeleteSession(t *testing.T, store store.Store, _ store.Container) {
 	session := &model.Session{
-		ID:    "session-id",
-		Token: "token",
+		ID:       "session-id",
+		Token:    "token",
+		ExpireAt: time.Now().Unix() + 60*60,
 	}
 
 	t.Run("CreateAndGetSession", func(t *testing.T) {
 		err := store.CreateSession(session)
 		require.NoError(t, err)
 
-		got, err := store.GetSession(session.Token, 60*60)
+		got, err := store.GetSession(session.Token)
 		require.NoError(t, err)
 		require.Equal(t, session, got)
 	})
@@ -53,7 +66,7 @@ func testCreateAndGetAndDeleteSession(t *testing.T, store store.Store, _ store.C
 		err := store.DeleteSession(session.ID)
 		require.NoError(t, err)
 
-		_, err = store.GetSession(session.Token, 60*60)
+		_, err = store.GetSession(session.Token)
 		require.Error(t, err)
 	})
 }
@@ -86,9 +99,10 @@ func testGetActiveUserCount(t *testing.T, store store.Store, _ store.Container)
 
 func testUpdateSession(t *testing.T, store store.Store, _ store.Container) {
 	session := &model.Session{
-		ID:    "session-id",
-		Token: "token",
-		Props: map[string]interface{}{"field1": "A"},
+		ID:       "session-id",
+		Token:    "token",
+		Props:    map[string]interface{}{"field1": "A"},
+		ExpireAt: time.Now().Unix() + 60,
 	}
 
 	err := store.CreateSession(session)
@@ -99,7 +113,92 @@ func testUpdateSession(t *testing.T, store store.Store, _ store.Container) {
 	err = store.UpdateSession(session)
 	require.NoError(t, err)
 
-	got, err := store.GetSession(session.Token, 60)
+	got, err := store.GetSession(session.Token)
 	require.NoError(t, err)
 	require.Equal(t, session, got)
 }
+
+func testDeleteSessionsForUser(t *testing.T, store store.Store, _ store.Container) {
+	expireAt := time.Now().Unix() + 60
+	sessions := []*model.Session{
+		{ID: "session-1", Token: "token-1", UserID: "user-1", ExpireAt: expireAt},
+		{ID: "session-2", Token: "token-2", UserID: "user-1", ExpireAt: expireAt},
+		{ID: "session-3", Token: "token-3", UserID: "user-2", ExpireAt: expireAt},
+	}
+	for _, session := range sessions {
+		require.NoError(t, store.CreateSession(session))
+	}
+
+	require.NoError(t, store.DeleteSessionsForUser("user-1"))
+
+	_, err := store.GetSession("token-1")
+	require.Error(t, err)
+	_, err = store.GetSession("token-2")
+	require.Error(t, err)
+	_, err = store.GetSession("token-3")
+	require.NoError(t, err)
+}
+
+func testSessionExpiry(t *testing.T, store store.Store, _ store.Container) {
+	now := time.Now().Unix()
+
+	active := &model.Session{ID: "active-id", Token: "active-token", ExpireAt: now + 60}
+	expired := &model.Session{ID: "expired-id", Token: "expired-token", ExpireAt: now - 60}
+	legacy := &model.Session{ID: "legacy-id", Token: "legacy-token"}
+	for _, session := range []*model.Session{active, expired, legacy} {
+		require.NoError(t, store.CreateSession(session))
+	}
+
+	t.Run("the expired sessions aren't returned", func(t *testing.T) {
+		got, err := store.GetSession(active.Token)
+		require.NoError(t, err)
+		require.Equal(t, active.ExpireAt, got.ExpireAt)
+
+		_, err = store.GetSession(expired.Token)
+		require.Error(t, err)
+
+		_, err = store.GetSession(legacy.Token)
+		require.Error(t, err)
+	})
+
+	t.Run("RefreshSession extends the expiry", func(t *testing.T) {
+		expired.ExpireAt = now + 120
+		require.NoError(t, store.RefreshSession(expired))
+
+		got, err := store.GetSession(expired.Token)
+		require.NoError(t, err)
+		require.Equal(t, now+120, got.ExpireAt)
+		require.GreaterOrEqual(t, got.UpdateAt, now)
+
+		expired.ExpireAt = now - 60
+		require.NoError(t, store.RefreshSession(expired))
+	})
+
+	t.Run("SetLegacySessionsExpireAt", func(t *testing.T) {
+		require.NoError(t, store.SetLegacySessionsExpireAt(now+300))
+
+		got, err := store.GetSession(legacy.Token)
+		require.NoError(t, err)
+		require.Equal(t, now+300, got.ExpireAt)
+
+		// the sessions with an expiry are left unchanged
+		got, err = store.GetSession(active.Token)
+		require.NoError(t, err)
+		require.Equal(t, now+60, got.ExpireAt)
+	})
+
+	t.Run("DeleteExpiredSessions", func(t *testing.T) {
+		require.NoError(t, store.DeleteExpiredSessions())
+
+		_, err := store.GetSession(active.Token)
+		require.NoError(t, err)
+		_, err = store.GetSession(legacy.Token)
+		require.NoError(t, err)
+
+		// a deleted session can't be extended anymore
+		expired.ExpireAt = now + 60
+		require.NoError(t, store.RefreshSession(expired))
+		_, err = store.GetSession(expired.Token)
+		require.Error(t, err)
+	})
+}
@@ -1,6 +1,7 @@
 package storetests
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/mattermost/focalboard/server/model"
@@ -18,6 +19,16 @@ func StoreTestSharingStore(t *testing.T, setup func(t *testing.T) (store.Store,
 		defer tearDown()
 		testUpsertSharingAndGetSharing(t, store, container)
 	})
+	t.Run("SharingTokens", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testSharingTokens(t, store, container)
+	})
+	t.Run("CalendarFeeds", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testCalendarFeeds(t, store, container)
+	})
 }
 
 func testUpsertSharingAndGetSharing(t *testing.T, store store.Store, container store.Container) {
@@ -61,3 +72,110 @@ func testUpsertSharingAndGetSharing(t *testing.T, store store.Store, container s
 		require.Error(t, err)
 	})
 }
+
+func testSharingTokens(t *testing.T, store store.Store, container store.Container) {
+	token1 := model.SharingToken{
+		Token:     "token-1",
+		RootID:    "root-id",
+		CreatedBy: testUserID,
+		CreateAt:  1,
+	}
+	token2 := model.SharingToken{
+		Token:     "token-2",
+		RootID:    "root-id",
+		CreatedBy: testUserID,
+		CreateAt:  2,
+		ExpireAt:  1000,
+	}
+
+	t.Run("no tokens", func(t *testing.T) {
+		tokens, err := store.GetSharingTokens(container, "root-id")
+		require.NoError(t, err)
+		require.Empty(t, tokens)
+	})
+
+	t.Run("create tokens", func(t *testing.T) {
+		require.NoError(t, store.RegenerateSharingToken(container, token1, ""))
+		require.NoError(t, store.RegenerateSharingToken(container, token2, ""))
+
+		tokens, err := store.GetSharingTokens(container, "root-id")
+		require.NoError(t, err)
+		require.Equal(t, []model.SharingToken{token1, token2}, tokens)
+	})
+
+	t.Run("regenerate a token", func(t *testing.T) {
+		token3 := model.SharingToken{
+			Token:     "token-3",
+			RootID:    "root-id",
+			CreatedBy: "user-id-2",
+			CreateAt:  3,
+		}
+		require.NoError(t, store.RegenerateSharingToken(container, token3, "token-1"))
+
+		tokens, err := store.GetSharingTokens(container, "root-id")
+		require.NoError(t, err)
+		require.Equal(t, []model.SharingToken{token2, token3}, tokens)
+	})
+
+	t.Run("tokens of other workspaces are not visible", func(t *testing.T) {
+		otherContainer := container
+		otherContainer.WorkspaceID = "other-workspace"
+
+		tokens, err := store.GetSharingTokens(otherContainer, "root-id")
+		require.NoError(t, err)
+		require.Empty(t, tokens)
+
+		err = store.RevokeSharingToken(otherContainer, "root-id", "token-2")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("revoke a token", func(t *testing.T) {
+		require.NoError(t, store.RevokeSharingToken(container, "root-id", "token-2"))
+
+		tokens, err := store.GetSharingTokens(container, "root-id")
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		require.Equal(t, "token-3", tokens[0].Token)
+	})
+
+	t.Run("revoke not existing token", func(t *testing.T) {
+		err := store.RevokeSharingToken(container, "root-id", "token-2")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func testCalendarFeeds(t *testing.T, store store.Store, container store.Container) {
+	_, err := store.GetCalendarFeed(container, "board-1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	feed := model.CalendarFeed{BoardID: "board-1", Token: "token-1", CreatedBy: testUserID, CreateAt: 1000}
+	require.NoError(t, store.UpsertCalendarFeed(container, feed))
+
+	stored, err := store.GetCalendarFeed(container, "board-1")
+	require.NoError(t, err)
+	require.Equal(t, feed, *stored)
+
+	t.Run("should replace the token", func(t *testing.T) {
+		feed := model.CalendarFeed{BoardID: "board-1", Token: "token-2", CreatedBy: "user-2", CreateAt: 2000}
+		require.NoError(t, store.UpsertCalendarFeed(container, feed))
+
+		stored, err := store.GetCalendarFeed(container, "board-1")
+		require.NoError(t, err)
+		require.Equal(t, feed, *stored)
+	})
+
+	t.Run("should not return the feed of another workspace", func(t *testing.T) {
+		other := container
+		other.WorkspaceID = "other-workspace"
+		_, err := store.GetCalendarFeed(other, "board-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.ErrorIs(t, store.DeleteCalendarFeed(other, "board-1"), sql.ErrNoRows)
+	})
+
+	t.Run("should revoke the feed", func(t *testing.T) {
+		require.NoError(t, store.DeleteCalendarFeed(container, "board-1"))
+		_, err := store.GetCalendarFeed(container, "board-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.ErrorIs(t, store.DeleteCalendarFeed(container, "board-1"), sql.ErrNoRows)
+	})
+}
@@ -0,0 +1,119 @@
+package storetests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestSubscriptions(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container := store.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("Subscriptions", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testSubscriptions(t, store, container)
+	})
+}
+
+func testSubscriptions(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	blocks := []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+		{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"},
+		{ID: "card-2", ParentID: "board-1", RootID: "board-1", Type: "card"},
+	}
+	_, err := store.InsertBlocks(ctx, container, blocks, testUserID)
+	require.NoError(t, err)
+
+	subscribe := func(block model.Block, subscriberID string) {
+		err := store.CreateSubscription(ctx, model.Subscription{
+			BlockType:    block.Type,
+			BlockID:      block.ID,
+			WorkspaceID:  container.WorkspaceID,
+			SubscriberID: subscriberID,
+			CreateAt:     utils.GetMillis(),
+		})
+		require.NoError(t, err)
+	}
+	subscribe(blocks[0], "user-1")
+	subscribe(blocks[1], "user-1")
+	subscribe(blocks[1], "user-2")
+	subscribe(blocks[2], "user-2")
+
+	t.Run("should keep the existing subscription", func(t *testing.T) {
+		subscribe(blocks[1], "user-1")
+
+		subscriptions, err := store.GetSubscriptionsForUser(ctx, container, "user-1")
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 2)
+	})
+
+	t.Run("should get the subscriptions of the blocks", func(t *testing.T) {
+		subscriptions, err := store.GetSubscriptions(ctx, container, []string{"card-1", "board-1"})
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 3)
+
+		otherContainer := container
+		otherContainer.WorkspaceID = "other"
+		subscriptions, err = store.GetSubscriptions(ctx, otherContainer, []string{"card-1"})
+		require.NoError(t, err)
+		require.Empty(t, subscriptions)
+	})
+
+	t.Run("should claim a notification once per window", func(t *testing.T) {
+		claimed, err := store.ClaimSubscriptionNotification(ctx, "card-1", "user-1", 1000, 500)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = store.ClaimSubscriptionNotification(ctx, "card-1", "user-1", 1200, 700)
+		require.NoError(t, err)
+		require.False(t, claimed)
+
+		claimed, err = store.ClaimSubscriptionNotification(ctx, "card-1", "user-1", 1600, 1100)
+		require.NoError(t, err)
+		require.True(t, claimed)
+
+		claimed, err = store.ClaimSubscriptionNotification(ctx, "card-1", "user-3", 1000, 500)
+		require.NoError(t, err)
+		require.False(t, claimed, "the user isn't subscribed")
+	})
+
+	t.Run("should delete the subscription", func(t *testing.T) {
+		err := store.DeleteSubscription(ctx, container, "board-1", "user-1")
+		require.NoError(t, err)
+
+		err = store.DeleteSubscription(ctx, container, "board-1", "user-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		subscriptions, err := store.GetSubscriptionsForUser(ctx, container, "user-1")
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 1)
+		require.Equal(t, "card-1", subscriptions[0].BlockID)
+	})
+
+	t.Run("should delete the subscriptions of the deleted blocks", func(t *testing.T) {
+		err := store.DeleteBlock(ctx, container, "card-1", testUserID)
+		require.NoError(t, err)
+
+		subscriptions, err := store.GetSubscriptions(ctx, container, []string{"card-1", "card-2"})
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 1)
+		require.Equal(t, "card-2", subscriptions[0].BlockID)
+
+		subscribe(blocks[0], "user-1")
+		err = store.DeleteBlock(ctx, container, "board-1", testUserID)
+		require.NoError(t, err)
+
+		subscriptions, err = store.GetSubscriptions(ctx, container, []string{"board-1", "card-2"})
+		require.NoError(t, err)
+		require.Empty(t, subscriptions)
+	})
+}
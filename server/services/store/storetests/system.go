@@ -2,8 +2,10 @@ package storetests
 
 import (
 	"testing"
+	"time"
 
 	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,6 +19,12 @@ func StoreTestSystemStore(t *testing.T, setup func(t *testing.T) (store.Store, f
 		defer tearDown()
 		testSetGetSystemSettings(t, store, container)
 	})
+
+	t.Run("AcquireClusterLock", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testAcquireClusterLock(t, store)
+	})
 }
 
 func testSetGetSystemSettings(t *testing.T, store store.Store, _ /*container*/ store.Container) {
@@ -42,3 +50,43 @@ func testSetGetSystemSettings(t *testing.T, store store.Store, _ /*container*/ s
 		require.Equal(t, map[string]string{"test-1": "test-value-1", "test-2": "test-value-updated-2"}, settings)
 	})
 }
+
+func testAcquireClusterLock(t *testing.T, store store.Store) {
+	expireAt := utils.GetMillis() + time.Hour.Milliseconds()
+
+	t.Run("Acquire a new lock", func(t *testing.T) {
+		acquired, err := store.AcquireClusterLock("lock-1", "owner-1", expireAt)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+
+	t.Run("The owner renews the lock", func(t *testing.T) {
+		acquired, err := store.AcquireClusterLock("lock-1", "owner-1", expireAt+1)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+
+	t.Run("Another owner can't take the lock", func(t *testing.T) {
+		acquired, err := store.AcquireClusterLock("lock-1", "owner-2", expireAt)
+		require.NoError(t, err)
+		require.False(t, acquired)
+
+		acquired, err = store.AcquireClusterLock("lock-2", "owner-2", expireAt)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+
+	t.Run("Another owner takes the expired lock", func(t *testing.T) {
+		acquired, err := store.AcquireClusterLock("lock-3", "owner-1", utils.GetMillis()-1)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = store.AcquireClusterLock("lock-3", "owner-2", expireAt)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = store.AcquireClusterLock("lock-3", "owner-1", expireAt)
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+}
@@ -0,0 +1,121 @@
+package storetests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/stretchr/testify/require"
+)
+
+func StoreTestTransactions(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	container := store.Container{
+		WorkspaceID: "0",
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testTransactionCommit(t, store, container)
+	})
+	t.Run("Rollback", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testTransactionRollback(t, store, container)
+	})
+	t.Run("NestedTransaction", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testNestedTransaction(t, store)
+	})
+}
+
+func testTransactionCommit(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	InsertBlocks(t, store, container, []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+	}, testUserID)
+
+	// avoid violating the block_history composite primary key constraint
+	// with a quick update of the board
+	time.Sleep(1 * time.Second)
+
+	tx, err := store.BeginTx(ctx)
+	require.NoError(t, err)
+
+	err = tx.InsertBlock(ctx, container, &model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"}, testUserID)
+	require.NoError(t, err)
+	err = tx.DeleteBlock(ctx, container, "board-1", testUserID)
+	require.NoError(t, err)
+
+	// the transaction reads its own writes
+	block, err := tx.GetBlock(ctx, container, "card-1")
+	require.NoError(t, err)
+	require.NotNil(t, block)
+
+	require.NoError(t, tx.Commit())
+
+	block, err = store.GetBlock(ctx, container, "card-1")
+	require.NoError(t, err)
+	require.NotNil(t, block)
+
+	block, err = store.GetBlock(ctx, container, "board-1")
+	require.NoError(t, err)
+	require.Nil(t, block)
+
+	history, err := store.GetBlockHistory(ctx, container, "board-1", model.QueryBlockHistoryOptions{})
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+}
+
+func testTransactionRollback(t *testing.T, store store.Store, container store.Container) {
+	ctx := context.Background()
+	InsertBlocks(t, store, container, []model.Block{
+		{ID: "board-1", RootID: "board-1", Type: "board"},
+	}, testUserID)
+
+	tx, err := store.BeginTx(ctx)
+	require.NoError(t, err)
+
+	err = tx.InsertBlock(ctx, container, &model.Block{ID: "card-1", ParentID: "board-1", RootID: "board-1", Type: "card"}, testUserID)
+	require.NoError(t, err)
+	err = tx.DeleteWorkspace(ctx, container.WorkspaceID)
+	require.NoError(t, err)
+
+	// a block whose fields can't be stored fails the operation midway
+	_, err = tx.InsertBlocks(ctx, container, []model.Block{
+		{ID: "card-2", ParentID: "board-1", RootID: "board-1", Type: "card"},
+		{ID: "card-3", ParentID: "board-1", RootID: "board-1", Type: "card", Fields: map[string]interface{}{"invalid": make(chan int)}},
+	}, testUserID)
+	require.Error(t, err)
+
+	require.NoError(t, tx.Rollback())
+
+	blocks, err := store.GetAllBlocks(ctx, container)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, "board-1", blocks[0].ID)
+
+	for _, blockID := range []string{"card-1", "card-2"} {
+		history, err := store.GetBlockHistory(ctx, container, blockID, model.QueryBlockHistoryOptions{})
+		require.NoError(t, err)
+		require.Empty(t, history)
+	}
+
+	history, err := store.GetBlockHistory(ctx, container, "board-1", model.QueryBlockHistoryOptions{})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+}
+
+func testNestedTransaction(t *testing.T, store store.Store) {
+	ctx := context.Background()
+	tx, err := store.BeginTx(ctx)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, tx.Rollback()) }()
+
+	nested, err := tx.BeginTx(ctx)
+	require.Error(t, err)
+	require.Nil(t, nested)
+}
@@ -4,6 +4,7 @@
 package storetests
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 	"time"
@@ -39,6 +40,64 @@ func StoreTestUserStore(t *testing.T, setup func(t *testing.T) (store.Store, fun
 		defer tearDown()
 		testCreateAndGetRegisteredUserCount(t, store)
 	})
+
+	t.Run("Notifications", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testNotifications(t, store)
+	})
+
+	t.Run("RemindersSent", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testRemindersSent(t, store)
+	})
+
+	t.Run("GetUsersAndUpdateUserActive", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetUsersAndUpdateUserActive(t, store)
+	})
+
+	t.Run("SearchUsersByWorkspace", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testSearchUsersByWorkspace(t, store)
+	})
+
+	t.Run("GetUserByAuthData", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetUserByAuthData(t, store)
+	})
+}
+
+func testGetUserByAuthData(t *testing.T, store store.Store) {
+	require.NoError(t, store.CreateUser(&model.User{ID: "user-1", Username: "alice", Email: "alice@example.com"}))
+	require.NoError(t, store.CreateUser(&model.User{ID: "user-2", Username: "bob", Email: "bob@example.com"}))
+
+	_, err := store.GetUserByAuthData("")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetUserByAuthData("subject-1")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, store.UpdateUserAuthData("user-1", "subject-1"))
+	got, err := store.GetUserByAuthData("subject-1")
+	require.NoError(t, err)
+	require.Equal(t, "user-1", got.ID)
+	require.Equal(t, "subject-1", got.AuthData)
+
+	t.Run("includes the deactivated users", func(t *testing.T) {
+		require.NoError(t, store.UpdateUserActive("user-1", false))
+		got, err := store.GetUserByAuthData("subject-1")
+		require.NoError(t, err)
+		require.Equal(t, "user-1", got.ID)
+		require.NotZero(t, got.DeleteAt)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		require.ErrorIs(t, store.UpdateUserAuthData("unknown", "subject-2"), sql.ErrNoRows)
+	})
 }
 
 func testGetWorkspaceUsers(t *testing.T, store store.Store) {
@@ -164,3 +223,196 @@ func testCreateAndGetRegisteredUserCount(t *testing.T, store store.Store) {
 	require.NoError(t, err)
 	require.Equal(t, randomN, got)
 }
+
+func testNotifications(t *testing.T, store store.Store) {
+	notification := func(id, userID, blockID string, createAt int64) model.Notification {
+		return model.Notification{
+			ID:          id,
+			Type:        model.NotificationTypeMention,
+			WorkspaceID: "workspace_1",
+			UserID:      userID,
+			AuthorID:    "author",
+			BoardID:     "board",
+			CardID:      "card",
+			BlockID:     blockID,
+			CreateAt:    createAt,
+		}
+	}
+
+	require.NoError(t, store.InsertNotification(notification("notification-1", "user-1", "block-1", 1)))
+	require.NoError(t, store.InsertNotification(notification("notification-2", "user-2", "block-1", 2)))
+	require.NoError(t, store.InsertNotification(notification("notification-3", "user-1", "block-2", 3)))
+
+	t.Run("GetNotifiedUserIDs", func(t *testing.T) {
+		userIDs, err := store.GetNotifiedUserIDs("block-1")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"user-1", "user-2"}, userIDs)
+
+		userIDs, err = store.GetNotifiedUserIDs("unknown")
+		require.NoError(t, err)
+		require.Empty(t, userIDs)
+	})
+
+	t.Run("GetNotificationsForUser", func(t *testing.T) {
+		notifications, err := store.GetNotificationsForUser("user-1", 10)
+		require.NoError(t, err)
+		require.Equal(t, []model.Notification{
+			notification("notification-3", "user-1", "block-2", 3),
+			notification("notification-1", "user-1", "block-1", 1),
+		}, notifications)
+
+		notifications, err = store.GetNotificationsForUser("user-1", 1)
+		require.NoError(t, err)
+		require.Len(t, notifications, 1)
+		require.Equal(t, "notification-3", notifications[0].ID)
+
+		notifications, err = store.GetNotificationsForUser("unknown", 10)
+		require.NoError(t, err)
+		require.Empty(t, notifications)
+	})
+}
+
+func testRemindersSent(t *testing.T, store store.Store) {
+	userIDs, err := store.GetSentReminderUserIDs("card-1", 100)
+	require.NoError(t, err)
+	require.Empty(t, userIDs)
+
+	for _, reminder := range []model.ReminderSent{
+		{CardID: "card-1", UserID: "user-1", DueAt: 100, CreateAt: 1},
+		{CardID: "card-1", UserID: "user-2", DueAt: 100, CreateAt: 1},
+		{CardID: "card-1", UserID: "user-1", DueAt: 200, CreateAt: 2},
+		{CardID: "card-2", UserID: "user-3", DueAt: 100, CreateAt: 1},
+	} {
+		require.NoError(t, store.InsertReminderSent(reminder))
+	}
+
+	userIDs, err = store.GetSentReminderUserIDs("card-1", 100)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"user-1", "user-2"}, userIDs)
+
+	userIDs, err = store.GetSentReminderUserIDs("card-1", 200)
+	require.NoError(t, err)
+	require.Equal(t, []string{"user-1"}, userIDs)
+
+	err = store.InsertReminderSent(model.ReminderSent{CardID: "card-1", UserID: "user-1", DueAt: 100, CreateAt: 3})
+	require.Error(t, err)
+}
+
+func testGetUsersAndUpdateUserActive(t *testing.T, store store.Store) {
+	for _, user := range []*model.User{
+		{ID: "user-1", Username: "charlie", IsAdmin: true},
+		{ID: "user-2", Username: "alice"},
+		{ID: "user-3", Username: "al_ex"},
+	} {
+		require.NoError(t, store.CreateUser(user))
+	}
+	require.NoError(t, store.CreateAccessToken(model.AccessToken{
+		ID:        "token-1",
+		UserID:    "user-2",
+		TokenHash: "hash-1",
+		CreateAt:  1,
+	}))
+
+	usernames := func(users []*model.User) []string {
+		names := []string{}
+		for _, user := range users {
+			names = append(names, user.Username)
+		}
+		return names
+	}
+
+	t.Run("IsAdmin", func(t *testing.T) {
+		got, err := store.GetUserByID("user-1")
+		require.NoError(t, err)
+		require.True(t, got.IsAdmin)
+
+		got, err = store.GetUserByID("user-2")
+		require.NoError(t, err)
+		require.False(t, got.IsAdmin)
+	})
+
+	t.Run("UpdateUserActive", func(t *testing.T) {
+		require.NoError(t, store.UpdateUserActive("user-2", false))
+
+		_, err := store.GetUserByID("user-2")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = store.GetAccessTokenByHash("hash-1")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		err = store.UpdateUserActive("unknown", false)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
+	t.Run("GetUsers", func(t *testing.T) {
+		users, err := store.GetUsers(model.QueryUsersOptions{PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []string{"al_ex", "alice", "charlie"}, usernames(users))
+
+		active := true
+		users, err = store.GetUsers(model.QueryUsersOptions{Active: &active, PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []string{"al_ex", "charlie"}, usernames(users))
+
+		inactive := false
+		users, err = store.GetUsers(model.QueryUsersOptions{Active: &inactive, PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []string{"alice"}, usernames(users))
+
+		// the underscore of the prefix isn't a wildcard
+		users, err = store.GetUsers(model.QueryUsersOptions{Username: "al_", PerPage: 10})
+		require.NoError(t, err)
+		require.Equal(t, []string{"al_ex"}, usernames(users))
+
+		users, err = store.GetUsers(model.QueryUsersOptions{Page: 1, PerPage: 2})
+		require.NoError(t, err)
+		require.Equal(t, []string{"charlie"}, usernames(users))
+	})
+
+	t.Run("activate again", func(t *testing.T) {
+		require.NoError(t, store.UpdateUserActive("user-2", true))
+
+		got, err := store.GetUserByID("user-2")
+		require.NoError(t, err)
+		require.Equal(t, int64(0), got.DeleteAt)
+		_, err = store.GetAccessTokenByHash("hash-1")
+		require.NoError(t, err)
+	})
+}
+
+func testSearchUsersByWorkspace(t *testing.T, store store.Store) {
+	ctx := context.Background()
+	for _, user := range []*model.User{
+		{ID: "user-1", Username: "Alice"},
+		{ID: "user-2", Username: "al_ex"},
+		{ID: "user-3", Username: "alan"},
+		{ID: "user-4", Username: "bob"},
+		{ID: "user-5", Username: "alba", IsGuest: true},
+	} {
+		require.NoError(t, store.CreateUser(user))
+	}
+	require.NoError(t, store.UpdateUserActive("user-3", false))
+	require.NoError(t, store.AddWorkspaceMember(ctx, "workspace-1", "user-1"))
+	require.NoError(t, store.AddWorkspaceMember(ctx, "workspace-1", "user-4"))
+
+	usernames := func(workspaceID, prefix string, limit int) []string {
+		users, err := store.SearchUsersByWorkspace(ctx, workspaceID, prefix, limit)
+		require.NoError(t, err)
+		names := []string{}
+		for _, user := range users {
+			names = append(names, user.Username)
+		}
+		return names
+	}
+
+	t.Run("should match the active users by prefix ignoring the case", func(t *testing.T) {
+		require.Equal(t, []string{"Alice", "al_ex"}, usernames("0", "AL", 10))
+		require.Equal(t, []string{"al_ex"}, usernames("0", "al_", 10))
+		require.Equal(t, []string{"Alice"}, usernames("0", "al", 1))
+	})
+
+	t.Run("should only match the members of the workspace", func(t *testing.T) {
+		require.Equal(t, []string{"Alice"}, usernames("workspace-1", "al", 10))
+		require.Equal(t, []string{"Alice", "bob"}, usernames("workspace-1", "", 10))
+		require.Empty(t, usernames("workspace-2", "", 10))
+	})
+}
@@ -4,6 +4,9 @@
 package storetests
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -18,6 +21,10 @@ import (
 )
 
 func StoreTestWorkspaceStore(t *testing.T, setup func(t *testing.T) (store.Store, func())) {
+	rootContainer := store.Container{WorkspaceID: "0"}
+	otherContainer := store.Container{WorkspaceID: "other-workspace"}
+	foreignContainer := store.Container{WorkspaceID: "foreign-workspace"}
+
 	t.Run("UpsertWorkspaceSignupToken", func(t *testing.T) {
 		store, tearDown := setup(t)
 		defer tearDown()
@@ -35,9 +42,64 @@ func StoreTestWorkspaceStore(t *testing.T, setup func(t *testing.T) (store.Store
 		defer tearDown()
 		testGetWorkspaceCount(t, store)
 	})
+
+	t.Run("GetWorkspaceStats", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetWorkspaceStats(t, store, rootContainer, otherContainer)
+	})
+
+	t.Run("GetUserWorkspaces", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetUserWorkspaces(t, store, rootContainer, otherContainer, foreignContainer)
+	})
+
+	t.Run("HasWorkspaceAccess", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testHasWorkspaceAccess(t, store, rootContainer)
+	})
+
+	t.Run("SharesWorkspace", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testSharesWorkspace(t, store, rootContainer, otherContainer)
+	})
+
+	t.Run("DeleteWorkspace", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testDeleteWorkspace(t, store, otherContainer, foreignContainer)
+	})
+
+	t.Run("GetInactiveWorkspaces", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetInactiveWorkspaces(t, store, rootContainer, otherContainer)
+	})
+
+	t.Run("WorkspaceRetentionNotices", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testWorkspaceRetentionNotices(t, store, otherContainer, foreignContainer)
+	})
+
+	t.Run("GetWorkspaces", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testGetWorkspaces(t, store)
+	})
+
+	t.Run("WebhookDeliveries", func(t *testing.T) {
+		store, tearDown := setup(t)
+		defer tearDown()
+		testWebhookDeliveries(t, store)
+	})
 }
 
 func testUpsertWorkspaceSignupToken(t *testing.T, store store.Store) {
+	ctx := context.Background()
 	t.Run("Insert and update workspace with signup token", func(t *testing.T) {
 		workspaceID := "0"
 		workspace := &model.Workspace{
@@ -46,20 +108,20 @@ func testUpsertWorkspaceSignupToken(t *testing.T, store store.Store) {
 		}
 
 		// insert
-		err := store.UpsertWorkspaceSignupToken(*workspace)
+		err := store.UpsertWorkspaceSignupToken(ctx, *workspace)
 		require.NoError(t, err)
 
-		got, err := store.GetWorkspace(workspaceID)
+		got, err := store.GetWorkspace(ctx, workspaceID)
 		require.NoError(t, err)
 		require.Equal(t, workspace.ID, got.ID)
 		require.Equal(t, workspace.SignupToken, got.SignupToken)
 
 		// update signup token
 		workspace.SignupToken = utils.CreateGUID()
-		err = store.UpsertWorkspaceSignupToken(*workspace)
+		err = store.UpsertWorkspaceSignupToken(ctx, *workspace)
 		require.NoError(t, err)
 
-		got, err = store.GetWorkspace(workspaceID)
+		got, err = store.GetWorkspace(ctx, workspaceID)
 		require.NoError(t, err)
 		require.Equal(t, workspace.ID, got.ID)
 		require.Equal(t, workspace.SignupToken, got.SignupToken)
@@ -67,40 +129,64 @@ func testUpsertWorkspaceSignupToken(t *testing.T, store store.Store) {
 }
 
 func testUpsertWorkspaceSettings(t *testing.T, store store.Store) {
+	ctx := context.Background()
 	t.Run("Insert and update workspace with settings", func(t *testing.T) {
 		workspaceID := "0"
 		workspace := &model.Workspace{
 			ID: workspaceID,
-			Settings: map[string]interface{}{
-				"field1": "A",
+			Settings: model.WorkspaceSettings{
+				SignupAllowed: true,
+				Locale:        "en",
 			},
 		}
 
 		// insert
-		err := store.UpsertWorkspaceSettings(*workspace)
+		err := store.UpsertWorkspaceSettings(ctx, *workspace)
 		require.NoError(t, err)
 
-		got, err := store.GetWorkspace(workspaceID)
+		got, err := store.GetWorkspace(ctx, workspaceID)
 		require.NoError(t, err)
 		require.Equal(t, workspace.ID, got.ID)
 		require.Equal(t, workspace.Settings, got.Settings)
 
 		// update settings
-		workspace.Settings = map[string]interface{}{
-			"field1": "B",
+		workspace.Settings = model.WorkspaceSettings{
+			CardLimit:         10,
+			DefaultTemplateID: "template-id",
 		}
-		err = store.UpsertWorkspaceSettings(*workspace)
+		err = store.UpsertWorkspaceSettings(ctx, *workspace)
 		require.NoError(t, err)
 
-		got2, err := store.GetWorkspace(workspaceID)
+		got2, err := store.GetWorkspace(ctx, workspaceID)
 		require.NoError(t, err)
 		require.Equal(t, workspace.ID, got2.ID)
 		require.Equal(t, workspace.Settings, got2.Settings)
 		require.Equal(t, got.SignupToken, got2.SignupToken)
 	})
+
+	t.Run("Unknown settings are preserved", func(t *testing.T) {
+		workspaceID := "1"
+		workspace := &model.Workspace{ID: workspaceID}
+		err := json.Unmarshal([]byte(`{"locale": "pt-BR", "field1": "A", "cardLimit": "invalid"}`), &workspace.Settings)
+		require.NoError(t, err)
+		require.Equal(t, "pt-BR", workspace.Settings.Locale)
+		require.Zero(t, workspace.Settings.CardLimit)
+
+		err = store.UpsertWorkspaceSettings(ctx, *workspace)
+		require.NoError(t, err)
+
+		got, err := store.GetWorkspace(ctx, workspaceID)
+		require.NoError(t, err)
+		require.Equal(t, workspace.Settings, got.Settings)
+
+		data, err := json.Marshal(got.Settings)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"locale": "pt-BR", "field1": "A", "cardLimit": "invalid"}`, string(data))
+	})
 }
 
 func testGetWorkspaceCount(t *testing.T, store store.Store) {
+	ctx := context.Background()
 	t.Run("Insert multiple workspace and get workspace count", func(t *testing.T) {
 		// insert
 		n := time.Now().Unix() % 10
@@ -111,12 +197,589 @@ func testGetWorkspaceCount(t *testing.T, store store.Store) {
 				SignupToken: utils.CreateGUID(),
 			}
 
-			err := store.UpsertWorkspaceSignupToken(*workspace)
+			err := store.UpsertWorkspaceSignupToken(ctx, *workspace)
 			require.NoError(t, err)
 		}
 
-		got, err := store.GetWorkspaceCount()
+		got, err := store.GetWorkspaceCount(ctx)
 		require.NoError(t, err)
 		require.Equal(t, n, got)
 	})
 }
+
+func testGetWorkspaceStats(t *testing.T, store store.Store, rootContainer, otherContainer store.Container) {
+	ctx := context.Background()
+
+	t.Run("No workspaces", func(t *testing.T) {
+		stats, err := store.GetWorkspaceStats(ctx, utils.GetMillis())
+		require.NoError(t, err)
+		require.Zero(t, stats.Total)
+		require.Zero(t, stats.Active30Days)
+		require.Len(t, stats.CreatedByDay, model.WorkspaceStatsDays)
+	})
+
+	for _, workspaceID := range []string{rootContainer.WorkspaceID, otherContainer.WorkspaceID} {
+		err := store.UpsertWorkspaceSignupToken(ctx, model.Workspace{ID: workspaceID, SignupToken: utils.CreateGUID()})
+		require.NoError(t, err)
+	}
+	block := model.Block{ID: "board-1", RootID: "board-1", Type: "board"}
+	require.NoError(t, store.InsertBlock(ctx, rootContainer, &block, "user-id-1"))
+
+	t.Run("Active and created workspaces", func(t *testing.T) {
+		now := utils.GetMillis()
+		stats, err := store.GetWorkspaceStats(ctx, now)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, stats.Total)
+		require.EqualValues(t, 1, stats.Active7Days)
+		require.EqualValues(t, 1, stats.Active30Days)
+
+		require.Len(t, stats.CreatedByDay, model.WorkspaceStatsDays)
+		today := stats.CreatedByDay[model.WorkspaceStatsDays-1]
+		require.EqualValues(t, 2, today.Count)
+		require.LessOrEqual(t, today.DayStart, now)
+		require.Greater(t, today.DayStart+model.WorkspaceStatsDayMillis, now)
+		for _, day := range stats.CreatedByDay[:model.WorkspaceStatsDays-1] {
+			require.Zero(t, day.Count)
+		}
+	})
+
+	t.Run("Workspaces inactive for a week", func(t *testing.T) {
+		later := utils.GetMillis() + 8*model.WorkspaceStatsDayMillis
+		stats, err := store.GetWorkspaceStats(ctx, later)
+		require.NoError(t, err)
+		require.Zero(t, stats.Active7Days)
+		require.EqualValues(t, 1, stats.Active30Days)
+		require.EqualValues(t, 2, stats.CreatedByDay[model.WorkspaceStatsDays-9].Count)
+	})
+}
+
+func testGetUserWorkspaces(t *testing.T, store store.Store, rootContainer, otherContainer, foreignContainer store.Container) {
+	ctx := context.Background()
+	userID := "user-id-1"
+
+	t.Run("No workspaces and no boards", func(t *testing.T) {
+		userWorkspaces, hasMore, err := store.GetUserWorkspaces(ctx, userID, "", 0)
+		require.NoError(t, err)
+		require.Empty(t, userWorkspaces)
+		require.False(t, hasMore)
+	})
+
+	t.Run("Boards are counted per workspace, skipping templates and archived boards", func(t *testing.T) {
+		err := store.UpsertWorkspaceSignupToken(ctx, model.Workspace{ID: "0", SignupToken: utils.CreateGUID()})
+		require.NoError(t, err)
+
+		newBoard := func(id string, isTemplate bool) model.Block {
+			return model.Block{
+				ID:     id,
+				RootID: id,
+				Type:   "board",
+				Fields: map[string]interface{}{"isTemplate": isTemplate},
+			}
+		}
+
+		InsertBlocks(t, store, rootContainer, []model.Block{
+			newBoard("board-1", false),
+			newBoard("board-2", false),
+			newBoard("template-1", true),
+			{ID: "archived-1", RootID: "archived-1", Type: "board", Fields: map[string]interface{}{"isTemplate": false, "isArchived": true}},
+			{ID: "card-1", RootID: "board-1", ParentID: "board-1", Type: "card"},
+		}, userID)
+		InsertBlocks(t, store, otherContainer, []model.Block{
+			newBoard("board-3", false),
+		}, userID)
+		InsertBlocks(t, store, foreignContainer, []model.Block{
+			newBoard("board-4", false),
+		}, "another-user-id")
+
+		userWorkspaces, hasMore, err := store.GetUserWorkspaces(ctx, userID, "", 0)
+		require.NoError(t, err)
+		require.False(t, hasMore)
+		require.ElementsMatch(t, []model.UserWorkspace{
+			{ID: "0", BoardCount: 2},
+			{ID: "other-workspace", BoardCount: 1},
+		}, userWorkspaces)
+	})
+
+	t.Run("Workspaces are paginated by ID", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			err := store.UpsertWorkspaceSignupToken(ctx, model.Workspace{
+				ID:          fmt.Sprintf("paginated-%d", i),
+				SignupToken: utils.CreateGUID(),
+			})
+			require.NoError(t, err)
+		}
+
+		// "0", "other-workspace" and five paginated workspaces
+		expectedIDs := []string{"0", "other-workspace", "paginated-0", "paginated-1", "paginated-2", "paginated-3", "paginated-4"}
+
+		firstPage, hasMore, err := store.GetUserWorkspaces(ctx, userID, "", 4)
+		require.NoError(t, err)
+		require.True(t, hasMore)
+		require.Len(t, firstPage, 4)
+
+		secondPage, hasMore, err := store.GetUserWorkspaces(ctx, userID, firstPage[3].ID, 4)
+		require.NoError(t, err)
+		require.False(t, hasMore)
+		require.Len(t, secondPage, 3)
+
+		gotIDs := []string{}
+		for _, userWorkspace := range append(firstPage, secondPage...) {
+			gotIDs = append(gotIDs, userWorkspace.ID)
+		}
+		require.Equal(t, expectedIDs, gotIDs)
+	})
+
+	t.Run("Guests only get the workspaces of their boards", func(t *testing.T) {
+		guest := &model.User{ID: "guest-id", Username: "guest", Email: "guest@example.com", IsGuest: true}
+		require.NoError(t, store.CreateUser(guest))
+
+		userWorkspaces, hasMore, err := store.GetUserWorkspaces(ctx, guest.ID, "", 0)
+		require.NoError(t, err)
+		require.Empty(t, userWorkspaces)
+		require.False(t, hasMore)
+
+		require.NoError(t, store.UpsertBoardMember(otherContainer, model.BoardMember{BoardID: "board-3", UserID: guest.ID, Role: model.BoardRoleViewer}))
+		// the boards that don't exist aren't accessible
+		require.NoError(t, store.UpsertBoardMember(otherContainer, model.BoardMember{BoardID: "missing-board", UserID: guest.ID, Role: model.BoardRoleViewer}))
+		require.NoError(t, store.UpsertBoardMember(rootContainer, model.BoardMember{BoardID: "missing-board", UserID: guest.ID, Role: model.BoardRoleViewer}))
+
+		userWorkspaces, hasMore, err = store.GetUserWorkspaces(ctx, guest.ID, "", 0)
+		require.NoError(t, err)
+		require.False(t, hasMore)
+		require.Equal(t, []model.UserWorkspace{{ID: "other-workspace", BoardCount: 1}}, userWorkspaces)
+	})
+}
+
+func testHasWorkspaceAccess(t *testing.T, store store.Store, rootContainer store.Container) {
+	ctx := context.Background()
+	workspaceID := "workspace-id-1"
+
+	activeUser := &model.User{
+		ID:       "active-user-id",
+		Username: "active",
+		Email:    "active@example.com",
+		CreateAt: time.Now().Unix(),
+		UpdateAt: time.Now().Unix(),
+	}
+	require.NoError(t, store.CreateUser(activeUser))
+
+	t.Run("User that is not a member", func(t *testing.T) {
+		hasAccess, err := store.HasWorkspaceAccess(ctx, activeUser.ID, workspaceID)
+		require.NoError(t, err)
+		require.False(t, hasAccess)
+	})
+
+	t.Run("User that is a member", func(t *testing.T) {
+		require.NoError(t, store.AddWorkspaceMember(ctx, workspaceID, activeUser.ID))
+		// adding the same member twice should not fail
+		require.NoError(t, store.AddWorkspaceMember(ctx, workspaceID, activeUser.ID))
+
+		hasAccess, err := store.HasWorkspaceAccess(ctx, activeUser.ID, workspaceID)
+		require.NoError(t, err)
+		require.True(t, hasAccess)
+
+		hasAccess, err = store.HasWorkspaceAccess(ctx, activeUser.ID, "another-workspace-id")
+		require.NoError(t, err)
+		require.False(t, hasAccess)
+	})
+
+	t.Run("Removed member", func(t *testing.T) {
+		require.NoError(t, store.RemoveWorkspaceMember(ctx, workspaceID, activeUser.ID))
+
+		hasAccess, err := store.HasWorkspaceAccess(ctx, activeUser.ID, workspaceID)
+		require.NoError(t, err)
+		require.False(t, hasAccess)
+	})
+
+	t.Run("Root workspace", func(t *testing.T) {
+		hasAccess, err := store.HasWorkspaceAccess(ctx, activeUser.ID, "0")
+		require.NoError(t, err)
+		require.True(t, hasAccess)
+
+		hasAccess, err = store.HasWorkspaceAccess(ctx, "unknown-user-id", "0")
+		require.NoError(t, err)
+		require.False(t, hasAccess)
+	})
+
+	t.Run("Guest", func(t *testing.T) {
+		guest := &model.User{
+			ID:       "guest-user-id",
+			Username: "guest",
+			Email:    "guest@example.com",
+			IsGuest:  true,
+		}
+		require.NoError(t, store.CreateUser(guest))
+
+		// the guests only have access to the workspaces of their boards
+		hasAccess, err := store.HasWorkspaceAccess(ctx, guest.ID, "0")
+		require.NoError(t, err)
+		require.False(t, hasAccess)
+
+		member := model.BoardMember{BoardID: "board-id", UserID: guest.ID, Role: model.BoardRoleViewer}
+		require.NoError(t, store.UpsertBoardMember(rootContainer, member))
+
+		hasAccess, err = store.HasWorkspaceAccess(ctx, guest.ID, "0")
+		require.NoError(t, err)
+		require.True(t, hasAccess)
+	})
+}
+
+func testSharesWorkspace(t *testing.T, store store.Store, rootContainer, otherContainer store.Container) {
+	ctx := context.Background()
+
+	for _, user := range []*model.User{
+		{ID: "user-id-1", Username: "user1", Email: "user1@example.com"},
+		{ID: "user-id-2", Username: "user2", Email: "user2@example.com"},
+		{ID: "guest-id-1", Username: "guest1", Email: "guest1@example.com", IsGuest: true},
+		{ID: "guest-id-2", Username: "guest2", Email: "guest2@example.com", IsGuest: true},
+	} {
+		require.NoError(t, store.CreateUser(user))
+	}
+
+	t.Run("Users of the root workspace", func(t *testing.T) {
+		shares, err := store.SharesWorkspace(ctx, "user-id-1", "user-id-2")
+		require.NoError(t, err)
+		require.True(t, shares)
+
+		shares, err = store.SharesWorkspace(ctx, "unknown-user-id", "user-id-2")
+		require.NoError(t, err)
+		require.False(t, shares)
+	})
+
+	t.Run("Guests", func(t *testing.T) {
+		shares, err := store.SharesWorkspace(ctx, "user-id-1", "guest-id-1")
+		require.NoError(t, err)
+		require.False(t, shares)
+
+		require.NoError(t, store.UpsertBoardMember(rootContainer, model.BoardMember{BoardID: "board-id", UserID: "guest-id-1", Role: model.BoardRoleViewer}))
+		shares, err = store.SharesWorkspace(ctx, "guest-id-1", "user-id-1")
+		require.NoError(t, err)
+		require.True(t, shares)
+
+		// a guest of another workspace only shares it with its members
+		require.NoError(t, store.UpsertBoardMember(otherContainer, model.BoardMember{BoardID: "other-board-id", UserID: "guest-id-2", Role: model.BoardRoleViewer}))
+		require.NoError(t, store.AddWorkspaceMember(ctx, otherContainer.WorkspaceID, "guest-id-2"))
+		shares, err = store.SharesWorkspace(ctx, "guest-id-2", "user-id-1")
+		require.NoError(t, err)
+		require.False(t, shares)
+
+		require.NoError(t, store.AddWorkspaceMember(ctx, otherContainer.WorkspaceID, "user-id-1"))
+		shares, err = store.SharesWorkspace(ctx, "guest-id-2", "user-id-1")
+		require.NoError(t, err)
+		require.True(t, shares)
+		shares, err = store.SharesWorkspace(ctx, "user-id-2", "guest-id-2")
+		require.NoError(t, err)
+		require.False(t, shares)
+	})
+
+	t.Run("Deactivated users", func(t *testing.T) {
+		require.NoError(t, store.UpdateUserActive("user-id-2", false))
+
+		shares, err := store.SharesWorkspace(ctx, "user-id-1", "user-id-2")
+		require.NoError(t, err)
+		require.True(t, shares, "the deactivated users are still shown")
+
+		shares, err = store.SharesWorkspace(ctx, "user-id-2", "user-id-1")
+		require.NoError(t, err)
+		require.False(t, shares)
+	})
+}
+
+func testDeleteWorkspace(t *testing.T, store store.Store, container, keptContainer store.Container) {
+	ctx := context.Background()
+	userID := "user-id-1"
+
+	seedWorkspace(t, store, container, userID)
+	seedWorkspace(t, store, keptContainer, userID)
+
+	err := store.DeleteWorkspace(ctx, container.WorkspaceID)
+	require.NoError(t, err)
+
+	t.Run("Workspace data is removed", func(t *testing.T) {
+		_, err := store.GetWorkspace(ctx, container.WorkspaceID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		blocks, err := store.GetAllBlocks(ctx, container)
+		require.NoError(t, err)
+		require.Empty(t, blocks)
+
+		_, err = store.GetSharing(container, container.WorkspaceID+"-board")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = store.GetGitHubIntegration(container, container.WorkspaceID+"-board")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		links, err := store.GetGitHubIssueLinks(container, container.WorkspaceID+"-board")
+		require.NoError(t, err)
+		require.Empty(t, links)
+
+		_, err = store.GetInboundHook(container, container.WorkspaceID+"-board")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = store.GetInboundHookByTokenHash(container.WorkspaceID + "-hash")
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		deliveries, err := store.GetInboundHookDeliveries(container, container.WorkspaceID+"-board", 10)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		hasAccess, err := store.HasWorkspaceAccess(ctx, userID, container.WorkspaceID)
+		require.NoError(t, err)
+		require.False(t, hasAccess)
+	})
+
+	t.Run("Other workspaces are untouched", func(t *testing.T) {
+		_, err := store.GetWorkspace(ctx, keptContainer.WorkspaceID)
+		require.NoError(t, err)
+
+		blocks, err := store.GetAllBlocks(ctx, keptContainer)
+		require.NoError(t, err)
+		require.Len(t, blocks, 2)
+
+		_, err = store.GetSharing(keptContainer, keptContainer.WorkspaceID+"-board")
+		require.NoError(t, err)
+
+		_, err = store.GetGitHubIntegration(keptContainer, keptContainer.WorkspaceID+"-board")
+		require.NoError(t, err)
+
+		links, err := store.GetGitHubIssueLinks(keptContainer, keptContainer.WorkspaceID+"-board")
+		require.NoError(t, err)
+		require.Len(t, links, 1)
+
+		_, err = store.GetInboundHook(keptContainer, keptContainer.WorkspaceID+"-board")
+		require.NoError(t, err)
+
+		deliveries, err := store.GetInboundHookDeliveries(keptContainer, keptContainer.WorkspaceID+"-board", 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+	})
+
+	t.Run("Deleting a non existing workspace", func(t *testing.T) {
+		err := store.DeleteWorkspace(ctx, "non-existing-workspace")
+		require.NoError(t, err)
+	})
+}
+
+func testGetInactiveWorkspaces(t *testing.T, store store.Store, rootContainer, otherContainer store.Container) {
+	ctx := context.Background()
+
+	t.Run("No blocks", func(t *testing.T) {
+		workspaces, err := store.GetInactiveWorkspaces(ctx, utils.GetMillis())
+		require.NoError(t, err)
+		require.Empty(t, workspaces)
+	})
+
+	InsertBlocks(t, store, otherContainer, []model.Block{
+		{ID: "other-board", RootID: "other-board", Type: "board"},
+	}, "user-id-1")
+	InsertBlocks(t, store, rootContainer, []model.Block{
+		{ID: "root-board", RootID: "root-board", Type: "board"},
+	}, "user-id-1")
+	blocks, err := store.GetAllBlocks(ctx, rootContainer)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	rootUpdateAt := blocks[0].UpdateAt
+
+	t.Run("Workspaces updated before the time", func(t *testing.T) {
+		workspaces, err := store.GetInactiveWorkspaces(ctx, utils.GetMillis()+1)
+		require.NoError(t, err)
+		require.Len(t, workspaces, 2)
+		require.Equal(t, rootContainer.WorkspaceID, workspaces[0].WorkspaceID)
+		require.Equal(t, rootUpdateAt, workspaces[0].LastActivityAt)
+		require.Equal(t, otherContainer.WorkspaceID, workspaces[1].WorkspaceID)
+	})
+
+	t.Run("The newest block is the last activity", func(t *testing.T) {
+		time.Sleep(10 * time.Millisecond)
+		InsertBlocks(t, store, rootContainer, []model.Block{
+			{ID: "root-card", RootID: "root-board", ParentID: "root-board", Type: "card"},
+		}, "user-id-1")
+
+		workspaces, err := store.GetInactiveWorkspaces(ctx, rootUpdateAt+1)
+		require.NoError(t, err)
+		require.Len(t, workspaces, 1)
+		require.Equal(t, otherContainer.WorkspaceID, workspaces[0].WorkspaceID)
+	})
+}
+
+func testWorkspaceRetentionNotices(t *testing.T, store store.Store, container, keptContainer store.Container) {
+	ctx := context.Background()
+
+	notices, err := store.GetWorkspaceRetentionNotices(ctx)
+	require.NoError(t, err)
+	require.Empty(t, notices)
+
+	notice := model.WorkspaceRetentionNotice{WorkspaceID: container.WorkspaceID, LastActivityAt: 10, NotifiedAt: 20}
+	require.NoError(t, store.UpsertWorkspaceRetentionNotice(ctx, notice))
+	kept := model.WorkspaceRetentionNotice{WorkspaceID: keptContainer.WorkspaceID, LastActivityAt: 5, NotifiedAt: 6}
+	require.NoError(t, store.UpsertWorkspaceRetentionNotice(ctx, kept))
+
+	t.Run("Upserting replaces the notice", func(t *testing.T) {
+		notice.LastActivityAt = 30
+		notice.NotifiedAt = 40
+		require.NoError(t, store.UpsertWorkspaceRetentionNotice(ctx, notice))
+
+		notices, err := store.GetWorkspaceRetentionNotices(ctx)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []model.WorkspaceRetentionNotice{notice, kept}, notices)
+	})
+
+	t.Run("Deleting a notice", func(t *testing.T) {
+		require.NoError(t, store.DeleteWorkspaceRetentionNotice(ctx, keptContainer.WorkspaceID))
+		require.NoError(t, store.DeleteWorkspaceRetentionNotice(ctx, "non-existing-workspace"))
+
+		notices, err := store.GetWorkspaceRetentionNotices(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []model.WorkspaceRetentionNotice{notice}, notices)
+	})
+
+	t.Run("Deleting the workspace removes its notice", func(t *testing.T) {
+		require.NoError(t, store.DeleteWorkspace(ctx, container.WorkspaceID))
+
+		notices, err := store.GetWorkspaceRetentionNotices(ctx)
+		require.NoError(t, err)
+		require.Empty(t, notices)
+	})
+}
+
+func seedWorkspace(t *testing.T, s store.Store, c store.Container, userID string) {
+	ctx := context.Background()
+	err := s.UpsertWorkspaceSignupToken(ctx, model.Workspace{ID: c.WorkspaceID, SignupToken: utils.CreateGUID()})
+	require.NoError(t, err)
+
+	blocks := []model.Block{
+		{ID: c.WorkspaceID + "-board", RootID: c.WorkspaceID + "-board", Type: "board"},
+		{ID: c.WorkspaceID + "-card", RootID: c.WorkspaceID + "-board", ParentID: c.WorkspaceID + "-board", Type: "card"},
+	}
+	InsertBlocks(t, s, c, blocks, userID)
+
+	err = s.UpsertSharing(c, model.Sharing{ID: c.WorkspaceID + "-board", Enabled: true, Token: "token"})
+	require.NoError(t, err)
+
+	err = s.UpsertGitHubIntegration(c, model.GitHubIntegration{
+		BoardID:                c.WorkspaceID + "-board",
+		Repository:             "owner/repo",
+		TokenEncrypted:         "encrypted-token",
+		WebhookSecretEncrypted: "encrypted-secret",
+		CreatedBy:              userID,
+	})
+	require.NoError(t, err)
+
+	err = s.UpsertGitHubIssueLink(c, model.GitHubIssueLink{
+		BoardID:     c.WorkspaceID + "-board",
+		IssueNumber: 1,
+		CardID:      c.WorkspaceID + "-card",
+		State:       model.GitHubStateOpen,
+	})
+	require.NoError(t, err)
+
+	err = s.UpsertInboundHook(c, model.InboundHook{
+		BoardID:   c.WorkspaceID + "-board",
+		TokenHash: c.WorkspaceID + "-hash",
+		CreatedBy: userID,
+	})
+	require.NoError(t, err)
+
+	err = s.InsertInboundHookDelivery(c, model.InboundHookDelivery{
+		ID:         c.WorkspaceID + "-delivery",
+		BoardID:    c.WorkspaceID + "-board",
+		CardID:     c.WorkspaceID + "-card",
+		Payload:    "{}",
+		StatusCode: 200,
+	}, 10)
+	require.NoError(t, err)
+
+	err = s.AddWorkspaceMember(ctx, c.WorkspaceID, userID)
+	require.NoError(t, err)
+}
+
+func testGetWorkspaces(t *testing.T, store store.Store) {
+	ctx := context.Background()
+	t.Run("No workspaces", func(t *testing.T) {
+		workspaces, err := store.GetWorkspaces(ctx, 0, 0)
+		require.NoError(t, err)
+		require.Empty(t, workspaces)
+	})
+
+	t.Run("Workspaces modified since a timestamp", func(t *testing.T) {
+		for _, id := range []string{"workspace-b", "workspace-a", "workspace-c"} {
+			err := store.UpsertWorkspaceSignupToken(ctx, model.Workspace{ID: id, SignupToken: utils.CreateGUID()})
+			require.NoError(t, err)
+		}
+
+		workspaces, err := store.GetWorkspaces(ctx, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, workspaces, 3)
+
+		var lastUpdateAt int64
+		for _, workspace := range workspaces {
+			require.GreaterOrEqual(t, workspace.UpdateAt, lastUpdateAt)
+			lastUpdateAt = workspace.UpdateAt
+		}
+
+		// workspaces modified exactly at the timestamp are excluded
+		workspaces, err = store.GetWorkspaces(ctx, lastUpdateAt, 0)
+		require.NoError(t, err)
+		require.Empty(t, workspaces)
+
+		workspaces, err = store.GetWorkspaces(ctx, 0, 2)
+		require.NoError(t, err)
+		require.Len(t, workspaces, 2)
+	})
+}
+
+func testWebhookDeliveries(t *testing.T, store store.Store) {
+	ctx := context.Background()
+	workspaceID := utils.CreateGUID()
+	otherWorkspaceID := utils.CreateGUID()
+
+	for i := 1; i <= 3; i++ {
+		err := store.InsertWebhookDelivery(model.WebhookDelivery{
+			ID:          fmt.Sprintf("delivery-%d", i),
+			WorkspaceID: workspaceID,
+			URL:         "https://example.com/hook",
+			Payload:     `{"workspaceId":"` + workspaceID + `"}`,
+			StatusCode:  500,
+			Error:       "Internal Server Error",
+			Attempts:    4,
+			CreateAt:    int64(i),
+		})
+		require.NoError(t, err)
+	}
+	err := store.InsertWebhookDelivery(model.WebhookDelivery{
+		ID:          "other-delivery",
+		WorkspaceID: otherWorkspaceID,
+		CreateAt:    10,
+	})
+	require.NoError(t, err)
+
+	t.Run("Deliveries are listed most recent first", func(t *testing.T) {
+		deliveries, err := store.GetWebhookDeliveries(workspaceID, 2)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		require.Equal(t, "delivery-3", deliveries[0].ID)
+		require.Equal(t, "delivery-2", deliveries[1].ID)
+		require.Equal(t, model.WebhookDelivery{
+			ID:          "delivery-3",
+			WorkspaceID: workspaceID,
+			URL:         "https://example.com/hook",
+			Payload:     `{"workspaceId":"` + workspaceID + `"}`,
+			StatusCode:  500,
+			Error:       "Internal Server Error",
+			Attempts:    4,
+			CreateAt:    3,
+		}, deliveries[0])
+	})
+
+	t.Run("Deliveries are removed with the workspace", func(t *testing.T) {
+		require.NoError(t, store.DeleteWorkspace(ctx, workspaceID))
+
+		deliveries, err := store.GetWebhookDeliveries(workspaceID, 10)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		deliveries, err = store.GetWebhookDeliveries(otherWorkspaceID, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+	})
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package telemetry
+
+import (
+	"encoding/json"
+	"os"
+
+	rudder "github.com/rudderlabs/analytics-go"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// localSinkMaxBytes is the size of the local telemetry file beyond which
+// it's rotated, the previous file being kept with the .1 suffix.
+const localSinkMaxBytes = 10 * 1024 * 1024
+
+// Sink is where the batches of telemetry events are sent.
+type Sink interface {
+	Send(events []Event) error
+	Close() error
+}
+
+// rudderSink sends the events to the Rudder data plane. The events are
+// dropped if the Rudder key or data plane aren't configured.
+type rudderSink struct {
+	service *Service
+	client  rudder.Client
+}
+
+func (rs *rudderSink) Send(events []Event) error {
+	config := rs.service.getRudderConfig()
+	if config.DataplaneURL == "" || config.RudderKey == "" {
+		return nil
+	}
+	if err := rs.init(config.DataplaneURL, config.RudderKey); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		var context *rudder.Context
+		_ = rs.client.Enqueue(rudder.Track{
+			Event:      event.Event,
+			UserId:     event.UserID,
+			Properties: event.Properties,
+			Context:    context,
+			Timestamp:  event.Timestamp,
+		})
+	}
+	return nil
+}
+
+func (rs *rudderSink) init(endpoint, rudderKey string) error {
+	if rs.client != nil {
+		return nil
+	}
+
+	config := rudder.Config{}
+	config.Logger = rudder.StdLogger(rs.service.logger.StdLogger(mlog.LvlFBTelemetry))
+	config.Endpoint = endpoint
+	// For testing
+	if endpoint != rudderDataplaneURL {
+		config.Verbose = true
+		config.BatchSize = 1
+	}
+	client, err := rudder.NewWithConfig(rudderKey, endpoint, config)
+	if err != nil {
+		return err
+	}
+	_ = client.Enqueue(rudder.Identify{
+		UserId: rs.service.telemetryID,
+	})
+
+	rs.client = client
+	return nil
+}
+
+func (rs *rudderSink) Close() error {
+	if rs.client != nil {
+		return rs.client.Close()
+	}
+	return nil
+}
+
+// localSink appends the events to a local file, one JSON object per line,
+// for the admins to inspect what would be sent.
+type localSink struct {
+	path string
+	file *os.File
+	size int64
+}
+
+func (ls *localSink) Send(events []Event) error {
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		line = append(line, '\n')
+
+		if ls.file == nil {
+			if err := ls.open(); err != nil {
+				return err
+			}
+		}
+		if ls.size > 0 && ls.size+int64(len(line)) > localSinkMaxBytes {
+			if err := ls.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := ls.file.Write(line)
+		ls.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ls *localSink) open() error {
+	file, err := os.OpenFile(ls.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	ls.file = file
+	ls.size = info.Size()
+	return nil
+}
+
+// rotate keeps the current file with the .1 suffix, replacing the
+// previous one, and opens a new one.
+func (ls *localSink) rotate() error {
+	if err := ls.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(ls.path, ls.path+".1"); err != nil {
+		return err
+	}
+	return ls.open()
+}
+
+func (ls *localSink) Close() error {
+	if ls.file == nil {
+		return nil
+	}
+	err := ls.file.Close()
+	ls.file = nil
+	ls.size = 0
+	return err
+}
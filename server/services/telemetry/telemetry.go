@@ -6,10 +6,11 @@ package telemetry
 import (
 	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/mattermost/focalboard/server/services/config"
 	"github.com/mattermost/focalboard/server/services/scheduler"
-	rudder "github.com/rudderlabs/analytics-go"
 
 	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 )
@@ -18,18 +19,54 @@ const (
 	rudderKey                  = "placeholder_rudder_key"
 	rudderDataplaneURL         = "placeholder_rudder_dataplane_url"
 	timeBetweenTelemetryChecks = 10 * time.Minute
+	timeBetweenFlushes         = time.Minute
+)
+
+// The sinks of the telemetry.
+const (
+	SinkRemote   = "remote"
+	SinkLocal    = "local"
+	SinkDisabled = "disabled"
+)
+
+// The categories of the telemetry events, which can be disabled
+// separately.
+const (
+	CategoryUsage  = "usage"
+	CategoryErrors = "errors"
 )
 
 type TrackerFunc func() (Tracker, error)
 
 type Tracker map[string]interface{}
 
+// Event is a telemetry event, as written by the local sink.
+type Event struct {
+	Category   string                 `json:"category"`
+	Event      string                 `json:"event"`
+	UserID     string                 `json:"userId"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
 type Service struct {
 	trackers                   map[string]TrackerFunc
 	logger                     *mlog.Logger
-	rudderClient               rudder.Client
 	telemetryID                string
 	timestampLastTelemetrySent time.Time
+
+	// sink is nil when the telemetry is disabled
+	sink               Sink
+	disabledCategories map[string]bool
+
+	// pending are the events not sent yet, flushed by the flush task
+	// and at shutdown
+	pending   []Event
+	pendingMu sync.Mutex
+	sinkMu    sync.Mutex
+
+	telemetryTask *scheduler.ScheduledTask
+	flushTask     *scheduler.ScheduledTask
 }
 
 type RudderConfig struct {
@@ -37,12 +74,29 @@ type RudderConfig struct {
 	DataplaneURL string
 }
 
-func New(telemetryID string, logger *mlog.Logger) *Service {
+// New returns the telemetry service of the configuration. Nothing is
+// sent when the telemetry is disabled, or when its sink is.
+func New(telemetryID string, cfg *config.Configuration, logger *mlog.Logger) *Service {
 	service := &Service{
-		logger:      logger,
-		telemetryID: telemetryID,
-		trackers:    map[string]TrackerFunc{},
+		logger:             logger,
+		telemetryID:        telemetryID,
+		trackers:           map[string]TrackerFunc{},
+		disabledCategories: map[string]bool{},
+	}
+
+	switch {
+	case !cfg.Telemetry || cfg.TelemetrySink == SinkDisabled:
+	case cfg.TelemetrySink == SinkLocal:
+		path := cfg.TelemetryLocalPath
+		if path == "" {
+			path = config.DefaultTelemetryLocalPath
+		}
+		service.sink = &localSink{path: path}
+	default:
+		service.sink = &rudderSink{service: service}
 	}
+	service.disabledCategories[CategoryUsage] = cfg.TelemetryDisableUsage
+	service.disabledCategories[CategoryErrors] = cfg.TelemetryDisableErrors
 
 	return service
 }
@@ -61,54 +115,58 @@ func (ts *Service) getRudderConfig() RudderConfig {
 	return RudderConfig{}
 }
 
-func (ts *Service) sendDailyTelemetry(override bool) {
-	config := ts.getRudderConfig()
-	if (config.DataplaneURL != "" && config.RudderKey != "") || override {
-		ts.initRudder(config.DataplaneURL, config.RudderKey)
-
-		for name, tracker := range ts.trackers {
-			m, err := tracker()
-			if err != nil {
-				ts.logger.Error("Error fetching telemetry data", mlog.String("name", name), mlog.Err(err))
-				continue
-			}
-			ts.sendTelemetry(name, m)
+func (ts *Service) sendDailyTelemetry() {
+	if ts.sink == nil || ts.disabledCategories[CategoryUsage] {
+		return
+	}
+
+	for name, tracker := range ts.trackers {
+		m, err := tracker()
+		if err != nil {
+			ts.logger.Error("Error fetching telemetry data", mlog.String("name", name), mlog.Err(err))
+			ts.TrackError("tracker_error", map[string]interface{}{"tracker": name})
+			continue
 		}
+		ts.enqueue(CategoryUsage, name, m)
 	}
 }
 
-func (ts *Service) sendTelemetry(event string, properties map[string]interface{}) {
-	if ts.rudderClient != nil {
-		var context *rudder.Context
-		_ = ts.rudderClient.Enqueue(rudder.Track{
-			Event:      event,
-			UserId:     ts.telemetryID,
-			Properties: properties,
-			Context:    context,
-		})
+// TrackError queues an error event, unless the error events are
+// disabled. The properties must not identify the users nor their data.
+func (ts *Service) TrackError(event string, properties map[string]interface{}) {
+	if ts.sink == nil || ts.disabledCategories[CategoryErrors] {
+		return
 	}
+	ts.enqueue(CategoryErrors, event, properties)
 }
 
-func (ts *Service) initRudder(endpoint, rudderKey string) {
-	if ts.rudderClient == nil {
-		config := rudder.Config{}
-		config.Logger = rudder.StdLogger(ts.logger.StdLogger(mlog.LvlFBTelemetry))
-		config.Endpoint = endpoint
-		// For testing
-		if endpoint != rudderDataplaneURL {
-			config.Verbose = true
-			config.BatchSize = 1
-		}
-		client, err := rudder.NewWithConfig(rudderKey, endpoint, config)
-		if err != nil {
-			ts.logger.Fatal("Failed to create Rudder instance")
-			return
-		}
-		_ = client.Enqueue(rudder.Identify{
-			UserId: ts.telemetryID,
-		})
+func (ts *Service) enqueue(category, event string, properties map[string]interface{}) {
+	ts.pendingMu.Lock()
+	defer ts.pendingMu.Unlock()
+	ts.pending = append(ts.pending, Event{
+		Category:   category,
+		Event:      event,
+		UserID:     ts.telemetryID,
+		Timestamp:  time.Now().UTC(),
+		Properties: properties,
+	})
+}
+
+// flush sends the pending events to the sink in a single batch.
+func (ts *Service) flush() {
+	ts.pendingMu.Lock()
+	events := ts.pending
+	ts.pending = nil
+	ts.pendingMu.Unlock()
+
+	if ts.sink == nil || len(events) == 0 {
+		return
+	}
 
-		ts.rudderClient = client
+	ts.sinkMu.Lock()
+	defer ts.sinkMu.Unlock()
+	if err := ts.sink.Send(events); err != nil {
+		ts.logger.Error("Error sending telemetry", mlog.Int("event_count", len(events)), mlog.Err(err))
 	}
 }
 
@@ -135,22 +193,37 @@ func (ts *Service) doTelemetryIfNeeded(firstRun time.Time) {
 }
 
 func (ts *Service) RunTelemetryJob(firstRun int64) {
+	if ts.sink == nil {
+		return
+	}
+
 	// Send on boot
 	ts.doTelemetry()
-	scheduler.CreateRecurringTask("Telemetry", func() {
+	ts.telemetryTask = scheduler.CreateRecurringTask("Telemetry", func() {
 		ts.doTelemetryIfNeeded(time.Unix(0, firstRun*int64(time.Millisecond)))
 	}, timeBetweenTelemetryChecks)
+	ts.flushTask = scheduler.CreateRecurringTask("TelemetryFlush", ts.flush, timeBetweenFlushes)
 }
 
 func (ts *Service) doTelemetry() {
 	ts.timestampLastTelemetrySent = time.Now()
-	ts.sendDailyTelemetry(false)
+	ts.sendDailyTelemetry()
 }
 
-// Shutdown closes the telemetry client.
+// Shutdown sends the pending events and closes the sink.
 func (ts *Service) Shutdown() error {
-	if ts.rudderClient != nil {
-		return ts.rudderClient.Close()
+	if ts.telemetryTask != nil {
+		ts.telemetryTask.Cancel()
+	}
+	if ts.flushTask != nil {
+		ts.flushTask.Cancel()
+	}
+	ts.flush()
+
+	if ts.sink != nil {
+		ts.sinkMu.Lock()
+		defer ts.sinkMu.Unlock()
+		return ts.sink.Close()
 	}
 
 	return nil
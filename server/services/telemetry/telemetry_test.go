@@ -3,14 +3,20 @@ package telemetry
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/mattermost/focalboard/server/services/config"
+
 	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 	"github.com/stretchr/testify/require"
 )
@@ -52,7 +58,10 @@ func TestTelemetry(t *testing.T) {
 	os.Setenv("RUDDER_KEY", "mock-test-rudder-key")
 	os.Setenv("RUDDER_DATAPLANE_URL", server.URL)
 
-	checkMockRudderServer := func(t *testing.T) {
+	checkMockRudderServer := func(t *testing.T, service *Service) {
+		// the events are sent in batches
+		service.flush()
+
 		// check mock rudder server got
 		got := string(<-receiveChan)
 		require.Contains(t, got, "mockTrackerKey")
@@ -60,7 +69,7 @@ func TestTelemetry(t *testing.T) {
 	}
 
 	t.Run("Register tracker and run telemetry job", func(t *testing.T) {
-		service := New("mockTelemetryID", mlog.CreateConsoleTestLogger(false, mlog.LvlDebug))
+		service := New("mockTelemetryID", &config.Configuration{Telemetry: true}, mlog.CreateConsoleTestLogger(false, mlog.LvlDebug))
 		service.RegisterTracker("mockTracker", func() (Tracker, error) {
 			return map[string]interface{}{
 				"mockTrackerKey": "mockTrackerValue",
@@ -68,11 +77,12 @@ func TestTelemetry(t *testing.T) {
 		})
 
 		service.RunTelemetryJob(time.Now().UnixNano() / int64(time.Millisecond))
-		checkMockRudderServer(t)
+		defer service.Shutdown()
+		checkMockRudderServer(t, service)
 	})
 
 	t.Run("do telemetry if needed", func(t *testing.T) {
-		service := New("mockTelemetryID", mlog.CreateConsoleTestLogger(false, mlog.LvlDebug))
+		service := New("mockTelemetryID", &config.Configuration{Telemetry: true}, mlog.CreateConsoleTestLogger(false, mlog.LvlDebug))
 		service.RegisterTracker("mockTracker", func() (Tracker, error) {
 			return map[string]interface{}{
 				"mockTrackerKey": "mockTrackerValue",
@@ -82,7 +92,7 @@ func TestTelemetry(t *testing.T) {
 		firstRun := time.Now()
 		t.Run("Send once every 10 minutes for the first hour", func(t *testing.T) {
 			service.doTelemetryIfNeeded(firstRun.Add(-30 * time.Minute))
-			checkMockRudderServer(t)
+			checkMockRudderServer(t, service)
 		})
 
 		t.Run("Send once every hour thereafter for the first 12 hours", func(t *testing.T) {
@@ -90,7 +100,7 @@ func TestTelemetry(t *testing.T) {
 			// need to do telemetry
 			service.timestampLastTelemetrySent = time.Now().Add(-time.Hour)
 			service.doTelemetryIfNeeded(firstRun.Add(-2 * time.Hour))
-			checkMockRudderServer(t)
+			checkMockRudderServer(t, service)
 
 			// firstRun is 2 hours ago and timestampLastTelemetrySent is just now
 			// no need to do telemetry
@@ -102,7 +112,7 @@ func TestTelemetry(t *testing.T) {
 			// need to do telemetry
 			service.timestampLastTelemetrySent = time.Now().Add(-24 * time.Hour)
 			service.doTelemetryIfNeeded(firstRun.Add(-24 * time.Hour))
-			checkMockRudderServer(t)
+			checkMockRudderServer(t, service)
 
 			// firstRun is 24 hours ago and timestampLastTelemetrySent is just now
 			// no need to do telemetry
@@ -111,3 +121,87 @@ func TestTelemetry(t *testing.T) {
 		})
 	})
 }
+
+func TestTelemetrySinks(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	os.Setenv("RUDDER_KEY", "mock-test-rudder-key")
+	os.Setenv("RUDDER_DATAPLANE_URL", server.URL)
+
+	run := func(t *testing.T, cfg *config.Configuration) {
+		service := New("mockTelemetryID", cfg, mlog.CreateConsoleTestLogger(false, mlog.LvlDebug))
+		service.RegisterTracker("mockTracker", func() (Tracker, error) {
+			return map[string]interface{}{
+				"mockTrackerKey": "mockTrackerValue",
+			}, nil
+		})
+		service.RegisterTracker("failingTracker", func() (Tracker, error) {
+			return nil, errors.New("tracker error")
+		})
+
+		service.doTelemetry()
+		service.TrackError("mockError", map[string]interface{}{"mockErrorKey": "mockErrorValue"})
+		require.NoError(t, service.Shutdown())
+	}
+
+	readEvents := func(t *testing.T, path string) []Event {
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		events := []Event{}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var event Event
+			require.NoError(t, json.Unmarshal([]byte(line), &event))
+			events = append(events, event)
+		}
+		return events
+	}
+
+	t.Run("Write the events to the local file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+		run(t, &config.Configuration{Telemetry: true, TelemetrySink: SinkLocal, TelemetryLocalPath: path})
+
+		events := readEvents(t, path)
+		require.Len(t, events, 3)
+		categories := map[string]string{}
+		for _, event := range events {
+			categories[event.Event] = event.Category
+			require.Equal(t, "mockTelemetryID", event.UserID)
+		}
+		require.Equal(t, map[string]string{
+			"mockTracker":   CategoryUsage,
+			"tracker_error": CategoryErrors,
+			"mockError":     CategoryErrors,
+		}, categories)
+	})
+
+	t.Run("Skip the disabled categories", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+		run(t, &config.Configuration{Telemetry: true, TelemetrySink: SinkLocal, TelemetryLocalPath: path, TelemetryDisableUsage: true})
+
+		events := readEvents(t, path)
+		require.Len(t, events, 1)
+		require.Equal(t, "mockError", events[0].Event)
+	})
+
+	t.Run("Rotate the local file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+		require.NoError(t, ioutil.WriteFile(path, bytes.Repeat([]byte("x"), localSinkMaxBytes), 0600))
+		run(t, &config.Configuration{Telemetry: true, TelemetrySink: SinkLocal, TelemetryLocalPath: path, TelemetryDisableUsage: true})
+
+		require.Len(t, readEvents(t, path), 1)
+		rotated, err := os.Stat(path + ".1")
+		require.NoError(t, err)
+		require.EqualValues(t, localSinkMaxBytes, rotated.Size())
+	})
+
+	t.Run("Send nothing when disabled", func(t *testing.T) {
+		run(t, &config.Configuration{Telemetry: true, TelemetrySink: SinkDisabled})
+		run(t, &config.Configuration{Telemetry: false})
+	})
+
+	require.Zero(t, atomic.LoadInt32(&requests), "the local and disabled sinks must not send any request")
+}
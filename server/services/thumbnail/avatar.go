@@ -0,0 +1,246 @@
+package thumbnail
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// AvatarSize is the size in pixels of the edges of the avatars.
+const AvatarSize = 128
+
+// GenerateAvatar writes the PNG avatar of the image, cropped to the
+// square at its center and scaled to size pixels. The image is turned by
+// its EXIF orientation, and its metadata isn't kept.
+func GenerateAvatar(r io.ReadSeeker, w io.Writer, size int) error {
+	src, format, err := decode(r)
+	if err != nil {
+		return err
+	}
+
+	if format == "jpeg" {
+		if _, err = r.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		src = orient(src, jpegOrientation(r))
+	}
+
+	bounds := src.Bounds()
+	edge := bounds.Dx()
+	if bounds.Dy() < edge {
+		edge = bounds.Dy()
+	}
+	x0 := bounds.Min.X + (bounds.Dx()-edge)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-edge)/2
+	area := image.Rect(x0, y0, x0+edge, y0+edge)
+
+	return png.Encode(w, resample(src, area, size, size, false))
+}
+
+// GenerateIdenticon writes the PNG identicon of the seed, a symmetric
+// pattern of 5x5 cells with a color that are both derived from it, of
+// size pixels.
+func GenerateIdenticon(seed string, w io.Writer, size int) error {
+	hash := sha256.Sum256([]byte(seed))
+	hue := float64(binary.BigEndian.Uint16(hash[0:2])) / 65536 * 360
+	fill := hslColor(hue, 0.55, 0.55)
+	background := color.RGBA{R: 0xf0, G: 0xf0, B: 0xf0, A: 0xff}
+
+	const cells = 5
+	cell := size / (cells + 1)
+	margin := (size - cells*cell) / 2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dst.SetRGBA(x, y, background)
+		}
+	}
+
+	// each cell of the columns up to the middle one is filled by a bit of
+	// the hash, and the left half is mirrored at the right
+	bit := 16
+	for col := 0; col < (cells+1)/2; col++ {
+		for row := 0; row < cells; row++ {
+			filled := hash[bit/8]&(1<<(bit%8)) != 0
+			bit++
+			if !filled {
+				continue
+			}
+			for y := margin + row*cell; y < margin+(row+1)*cell; y++ {
+				for x := margin + col*cell; x < margin+(col+1)*cell; x++ {
+					dst.SetRGBA(x, y, fill)
+				}
+			}
+		}
+	}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size/2; x++ {
+			dst.SetRGBA(size-1-x, y, dst.RGBAAt(x, y))
+		}
+	}
+
+	return png.Encode(w, dst)
+}
+
+// hslColor returns the opaque color of the hue in degrees, and of the
+// saturation and lightness between 0 and 1.
+func hslColor(hue, saturation, lightness float64) color.RGBA {
+	chroma := (1 - math.Abs(2*lightness-1)) * saturation
+	h := hue / 60
+	second := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+
+	var r, g, b float64
+	switch {
+	case h < 1:
+		r, g = chroma, second
+	case h < 2:
+		r, g = second, chroma
+	case h < 3:
+		g, b = chroma, second
+	case h < 4:
+		g, b = second, chroma
+	case h < 5:
+		r, b = second, chroma
+	default:
+		r, b = chroma, second
+	}
+
+	m := lightness - chroma/2
+	return color.RGBA{
+		R: uint8(math.Round((r + m) * 255)),
+		G: uint8(math.Round((g + m) * 255)),
+		B: uint8(math.Round((b + m) * 255)),
+		A: 0xff,
+	}
+}
+
+// jpegOrientation returns the EXIF orientation of the JPEG image, from 1
+// to 8, or 1 if it has none.
+func jpegOrientation(r io.Reader) int {
+	br := bufio.NewReader(r)
+	var marker [2]byte
+	if _, err := io.ReadFull(br, marker[:]); err != nil || marker != [2]byte{0xff, 0xd8} {
+		return 1
+	}
+
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil || header[0] != 0xff {
+			return 1
+		}
+		// the metadata segments are before the start of the scan
+		if header[1] == 0xda || header[1] == 0xd9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(header[2:])) - 2
+		if length < 0 {
+			return 1
+		}
+		segment := make([]byte, length)
+		if _, err := io.ReadFull(br, segment); err != nil {
+			return 1
+		}
+		if header[1] == 0xe1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+	}
+}
+
+// exifOrientation returns the orientation tag of the first IFD of the EXIF
+// data, or 1 if it has none.
+func exifOrientation(data []byte) int {
+	if len(data) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(data[4:8]))
+	if offset < 8 || offset+2 > len(data) {
+		return 1
+	}
+	count := int(order.Uint16(data[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(data) {
+			return 1
+		}
+		if order.Uint16(data[entry:]) != 0x0112 {
+			continue
+		}
+		// the orientation is a short in the value of the entry
+		orientation := int(order.Uint16(data[entry+8:]))
+		if orientation < 1 || orientation > 8 {
+			return 1
+		}
+		return orientation
+	}
+	return 1
+}
+
+// orient returns the image turned by the EXIF orientation.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	return &orientedImage{src: src, orientation: orientation}
+}
+
+// orientedImage is an image turned by an EXIF orientation, mapping its
+// pixels to the ones of the source.
+type orientedImage struct {
+	src         image.Image
+	orientation int
+}
+
+func (o *orientedImage) ColorModel() color.Model {
+	return o.src.ColorModel()
+}
+
+func (o *orientedImage) Bounds() image.Rectangle {
+	bounds := o.src.Bounds()
+	if o.orientation >= 5 {
+		// the orientations from 5 turn the image by a quarter
+		return image.Rect(0, 0, bounds.Dy(), bounds.Dx())
+	}
+	return image.Rect(0, 0, bounds.Dx(), bounds.Dy())
+}
+
+func (o *orientedImage) At(x, y int) color.Color {
+	bounds := o.src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var sx, sy int
+	switch o.orientation {
+	case 2: // mirrored
+		sx, sy = width-1-x, y
+	case 3: // upside down
+		sx, sy = width-1-x, height-1-y
+	case 4: // flipped upside down
+		sx, sy = x, height-1-y
+	case 5: // transposed
+		sx, sy = y, x
+	case 6: // turned a quarter clockwise
+		sx, sy = y, height-1-x
+	case 7: // transversed
+		sx, sy = width-1-y, height-1-x
+	case 8: // turned a quarter counterclockwise
+		sx, sy = width-1-y, x
+	default:
+		sx, sy = x, y
+	}
+	return o.src.At(bounds.Min.X+sx, bounds.Min.Y+sy)
+}
@@ -0,0 +1,147 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// halvesImage returns an image whose left half is red and right half is
+// blue.
+func halvesImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// withOrientation inserts an EXIF segment with the orientation after the
+// start of the JPEG image.
+func withOrientation(data []byte, orientation uint16) []byte {
+	// a big-endian TIFF header with a single entry in the first IFD, the
+	// orientation as a short
+	var tiff bytes.Buffer
+	tiff.WriteString("MM")
+	for _, v := range []interface{}{uint16(42), uint32(8), uint16(1), uint16(0x0112), uint16(3), uint32(1), orientation, uint16(0), uint32(0)} {
+		_ = binary.Write(&tiff, binary.BigEndian, v)
+	}
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	var buf bytes.Buffer
+	buf.Write(data[:2])
+	buf.Write([]byte{0xff, 0xe1})
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(segment)+2))
+	buf.Write(segment)
+	buf.Write(data[2:])
+	return buf.Bytes()
+}
+
+func generateAvatar(t *testing.T, data []byte) image.Image {
+	var buf bytes.Buffer
+	require.NoError(t, GenerateAvatar(bytes.NewReader(data), &buf, AvatarSize))
+	avatar, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, AvatarSize, AvatarSize), avatar.Bounds())
+	return avatar
+}
+
+// requireColorAt checks the color of the pixel, with the tolerance of the
+// JPEG compression.
+func requireColorAt(t *testing.T, expected color.RGBA, img image.Image, x, y int) {
+	r, g, b, a := img.At(x, y).RGBA()
+	require.InDelta(t, expected.R, r>>8, 16)
+	require.InDelta(t, expected.G, g>>8, 16)
+	require.InDelta(t, expected.B, b>>8, 16)
+	require.InDelta(t, expected.A, a>>8, 16)
+}
+
+func TestGenerateAvatar(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	t.Run("crops the center of the image", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, halvesImage(400, 200)))
+
+		avatar := generateAvatar(t, buf.Bytes())
+		requireColorAt(t, red, avatar, 10, 64)
+		requireColorAt(t, blue, avatar, 118, 64)
+	})
+
+	t.Run("scales the small images up", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, uniformImage(16, 16, color.RGBA{G: 255, A: 255})))
+
+		requireColorAt(t, color.RGBA{G: 255, A: 255}, generateAvatar(t, buf.Bytes()), 64, 64)
+	})
+
+	t.Run("keeps the transparency", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, uniformImage(300, 300, color.NRGBA{})))
+
+		requireColorAt(t, color.RGBA{}, generateAvatar(t, buf.Bytes()), 64, 64)
+	})
+
+	t.Run("turns the JPEG images by their orientation", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, halvesImage(200, 100), &jpeg.Options{Quality: 100}))
+
+		avatar := generateAvatar(t, withOrientation(buf.Bytes(), 1))
+		requireColorAt(t, red, avatar, 10, 64)
+		requireColorAt(t, blue, avatar, 118, 64)
+
+		// turned a quarter clockwise, the left half is at the top
+		avatar = generateAvatar(t, withOrientation(buf.Bytes(), 6))
+		requireColorAt(t, red, avatar, 64, 10)
+		requireColorAt(t, blue, avatar, 64, 118)
+
+		avatar = generateAvatar(t, withOrientation(buf.Bytes(), 8))
+		requireColorAt(t, blue, avatar, 64, 10)
+		requireColorAt(t, red, avatar, 64, 118)
+
+		avatar = generateAvatar(t, withOrientation(buf.Bytes(), 2))
+		requireColorAt(t, blue, avatar, 10, 64)
+		requireColorAt(t, red, avatar, 118, 64)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := GenerateAvatar(bytes.NewReader([]byte("not an image")), &buf, AvatarSize)
+		require.ErrorIs(t, err, ErrUnsupported)
+	})
+}
+
+func TestGenerateIdenticon(t *testing.T) {
+	generateIdenticon := func(seed string) image.Image {
+		var buf bytes.Buffer
+		require.NoError(t, GenerateIdenticon(seed, &buf, AvatarSize))
+		identicon, err := png.Decode(&buf)
+		require.NoError(t, err)
+		return identicon
+	}
+
+	identicon := generateIdenticon("user-1")
+	require.Equal(t, image.Rect(0, 0, AvatarSize, AvatarSize), identicon.Bounds())
+
+	for y := 0; y < AvatarSize; y++ {
+		for x := 0; x < AvatarSize/2; x++ {
+			require.Equal(t, identicon.At(x, y), identicon.At(AvatarSize-1-x, y), "the identicon is symmetric")
+		}
+	}
+
+	require.Equal(t, identicon, generateIdenticon("user-1"))
+	require.NotEqual(t, identicon, generateIdenticon("user-2"))
+}
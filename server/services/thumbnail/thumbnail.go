@@ -0,0 +1,139 @@
+// Package thumbnail generates the thumbnails of the uploaded images, and
+// the avatars of the users.
+package thumbnail
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	// the decoders of the formats of the images that have a thumbnail,
+	// the GIF decoder returning the first frame of the animations
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	// MaxEdge is the size in pixels of the long edge of the thumbnails.
+	MaxEdge = 400
+
+	// maxPixels is the size of the largest image that is decoded, so that
+	// a small file can't take all the memory.
+	maxPixels = 64 * 1024 * 1024
+
+	jpegQuality = 80
+)
+
+var (
+	// ErrUnsupported is returned for the images whose format can't be
+	// decoded.
+	ErrUnsupported = errors.New("the image format isn't supported")
+
+	// ErrImageTooLarge is returned for the images with too many pixels to
+	// be decoded.
+	ErrImageTooLarge = errors.New("the image is too large")
+)
+
+// Generate writes the JPEG thumbnail of the image, scaled down so that its
+// long edge is at most maxEdge pixels. The transparent pixels are white.
+func Generate(r io.ReadSeeker, w io.Writer, maxEdge int) error {
+	src, _, err := decode(r)
+	if err != nil {
+		return err
+	}
+
+	bounds := src.Bounds()
+	width, height := Size(bounds.Dx(), bounds.Dy(), maxEdge)
+	return jpeg.Encode(w, resample(src, bounds, width, height, true), &jpeg.Options{Quality: jpegQuality})
+}
+
+// decode decodes the image, unless its format isn't supported or it has
+// too many pixels, and returns the name of its format.
+func decode(r io.ReadSeeker) (image.Image, string, error) {
+	config, _, err := image.DecodeConfig(r)
+	if errors.Is(err, image.ErrFormat) {
+		return nil, "", ErrUnsupported
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+	return image.Decode(r)
+}
+
+// Size returns the size of the thumbnail of an image of the size.
+func Size(width, height, maxEdge int) (int, int) {
+	if width <= maxEdge && height <= maxEdge {
+		return width, height
+	}
+	if width >= height {
+		return maxEdge, max(1, height*maxEdge/width)
+	}
+	return max(1, width*maxEdge/height), maxEdge
+}
+
+// resample scales the area of the image to the size by averaging the
+// pixels of the source covered by each pixel of the result. The alpha is
+// kept, or the pixels are made opaque over a white background.
+func resample(src image.Image, area image.Rectangle, width, height int, opaque bool) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := span(y, height, area.Dy())
+		for x := 0; x < width; x++ {
+			x0, x1 := span(x, width, area.Dx())
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(area.Min.X+sx, area.Min.Y+sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			// the colors are premultiplied by the alpha, so the white
+			// shows through by the missing alpha
+			var white uint64
+			if opaque {
+				white = 0xffff*n - a
+				a = 0xffff * n
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(((r + white) / n) >> 8),
+				G: uint8(((g + white) / n) >> 8),
+				B: uint8(((b + white) / n) >> 8),
+				A: uint8((a / n) >> 8),
+			})
+		}
+	}
+	return dst
+}
+
+// span returns the range of the pixels of the source covered by the
+// pixel of the thumbnail.
+func span(i, size, srcSize int) (int, int) {
+	start := i * srcSize / size
+	end := (i + 1) * srcSize / size
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
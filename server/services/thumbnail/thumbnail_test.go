@@ -0,0 +1,114 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func uniformImage(width, height int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func generate(t *testing.T, data []byte) image.Image {
+	var buf bytes.Buffer
+	require.NoError(t, Generate(bytes.NewReader(data), &buf, MaxEdge))
+	thumbnail, err := jpeg.Decode(&buf)
+	require.NoError(t, err)
+	return thumbnail
+}
+
+// requireColor checks the color of the center of the image, with the
+// tolerance of the JPEG compression.
+func requireColor(t *testing.T, expected color.RGBA, img image.Image) {
+	bounds := img.Bounds()
+	r, g, b, _ := img.At(bounds.Dx()/2, bounds.Dy()/2).RGBA()
+	require.InDelta(t, expected.R, r>>8, 8)
+	require.InDelta(t, expected.G, g>>8, 8)
+	require.InDelta(t, expected.B, b>>8, 8)
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("scales the long edge down", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, uniformImage(1000, 500, color.RGBA{R: 200, G: 100, B: 50, A: 255})))
+
+		thumbnail := generate(t, buf.Bytes())
+		require.Equal(t, image.Rect(0, 0, 400, 200), thumbnail.Bounds())
+		requireColor(t, color.RGBA{R: 200, G: 100, B: 50}, thumbnail)
+	})
+
+	t.Run("keeps the size of small images", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, uniformImage(120, 300, color.Black), nil))
+
+		thumbnail := generate(t, buf.Bytes())
+		require.Equal(t, image.Rect(0, 0, 120, 300), thumbnail.Bounds())
+	})
+
+	t.Run("makes the transparent pixels white", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, uniformImage(800, 800, color.NRGBA{})))
+
+		requireColor(t, color.RGBA{R: 255, G: 255, B: 255}, generate(t, buf.Bytes()))
+	})
+
+	t.Run("keeps the first frame of animations", func(t *testing.T) {
+		animation := &gif.GIF{}
+		for _, c := range []color.Color{color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}} {
+			frame := image.NewPaletted(image.Rect(0, 0, 500, 500), palette.Plan9)
+			for y := 0; y < 500; y++ {
+				for x := 0; x < 500; x++ {
+					frame.Set(x, y, c)
+				}
+			}
+			animation.Image = append(animation.Image, frame)
+			animation.Delay = append(animation.Delay, 10)
+		}
+		var buf bytes.Buffer
+		require.NoError(t, gif.EncodeAll(&buf, animation))
+
+		thumbnail := generate(t, buf.Bytes())
+		require.Equal(t, image.Rect(0, 0, 400, 400), thumbnail.Bounds())
+		requireColor(t, color.RGBA{R: 255}, thumbnail)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := Generate(bytes.NewReader([]byte("RIFF\x00\x00\x00\x00WEBPVP8 ")), &buf, MaxEdge)
+		require.ErrorIs(t, err, ErrUnsupported)
+		require.Zero(t, buf.Len())
+	})
+}
+
+func TestSize(t *testing.T) {
+	testCases := []struct {
+		width, height                 int
+		expectedWidth, expectedHeight int
+	}{
+		{100, 50, 100, 50},
+		{400, 400, 400, 400},
+		{1000, 500, 400, 200},
+		{500, 1000, 200, 400},
+		{4000, 1, 400, 1},
+	}
+
+	for _, tc := range testCases {
+		width, height := Size(tc.width, tc.height, MaxEdge)
+		require.Equal(t, tc.expectedWidth, width)
+		require.Equal(t, tc.expectedHeight, height)
+	}
+}
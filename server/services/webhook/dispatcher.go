@@ -0,0 +1,274 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/utils"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+// SignatureHeader is the header holding the HMAC-SHA256 signature of
+// the payload, as "sha256=" followed by the hex encoded signature.
+const SignatureHeader = "X-Focalboard-Signature"
+
+const (
+	defaultWorkerCount    = 4
+	defaultQueueSize      = 1000
+	defaultRequestTimeout = 10 * time.Second
+	defaultRetryBackoff   = 1 * time.Second
+
+	// maxRetries is the number of times a delivery is retried after
+	// its first attempt
+	maxRetries = 3
+)
+
+// BlockChangedPayload is the payload posted to the workspace webhooks
+// when a block changes. Before is nil for new blocks and After is nil
+// for deleted blocks.
+type BlockChangedPayload struct {
+	WorkspaceID string       `json:"workspaceId"`
+	Before      *model.Block `json:"before"`
+	After       *model.Block `json:"after"`
+	ActorID     string       `json:"actorId"`
+}
+
+// DeliveryStore persists the deliveries that failed.
+type DeliveryStore interface {
+	InsertWebhookDelivery(delivery model.WebhookDelivery) error
+}
+
+type delivery struct {
+	workspaceID string
+	webhook     model.WorkspaceWebhook
+	body        []byte
+}
+
+// Dispatcher delivers the block changes to the workspace webhooks in
+// the background, with a fixed number of workers.
+type Dispatcher struct {
+	store        DeliveryStore
+	logger       *mlog.Logger
+	httpClient   *http.Client
+	retryBackoff time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan delivery
+	wg     sync.WaitGroup
+
+	// ctx is canceled when the deliveries are aborted on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewDispatcher creates a Dispatcher and starts its workers.
+func NewDispatcher(store DeliveryStore, logger *mlog.Logger) *Dispatcher {
+	return newDispatcher(store, logger, defaultWorkerCount, defaultQueueSize, defaultRequestTimeout, defaultRetryBackoff)
+}
+
+func newDispatcher(store DeliveryStore, logger *mlog.Logger, workerCount, queueSize int, timeout, retryBackoff time.Duration) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		store:        store,
+		logger:       logger,
+		httpClient:   &http.Client{Timeout: timeout},
+		retryBackoff: retryBackoff,
+		queue:        make(chan delivery, queueSize),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+
+	d.wg.Add(workerCount)
+	for i := 0; i < workerCount; i++ {
+		go d.worker()
+	}
+
+	return d
+}
+
+// NotifyBlockChanged queues the change for delivery to the webhooks.
+// It doesn't block, if the queue is full the delivery is recorded as
+// failed.
+func (d *Dispatcher) NotifyBlockChanged(webhooks []model.WorkspaceWebhook, payload BlockChangedPayload) {
+	if len(webhooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.logger.Error("NotifyBlockChanged: json.Marshal", mlog.Err(err))
+		return
+	}
+
+	dropped := []delivery{}
+	reason := "delivery queue is full"
+
+	d.mu.RLock()
+	for _, webhook := range webhooks {
+		item := delivery{
+			workspaceID: payload.WorkspaceID,
+			webhook:     webhook,
+			body:        body,
+		}
+
+		if d.closed {
+			reason = "dispatcher is shut down"
+			dropped = append(dropped, item)
+			continue
+		}
+
+		select {
+		case d.queue <- item:
+		default:
+			dropped = append(dropped, item)
+		}
+	}
+	d.mu.RUnlock()
+
+	for _, item := range dropped {
+		d.recordFailure(item, 0, 0, reason)
+	}
+}
+
+// Shutdown stops accepting deliveries and waits for the queued ones. If
+// the context is done first, the remaining deliveries are aborted and
+// recorded as failed.
+func (d *Dispatcher) Shutdown(ctx context.Context) {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+	}
+	d.cancel()
+}
+
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+
+	for item := range d.queue {
+		if d.ctx.Err() != nil {
+			d.recordFailure(item, 0, 0, "dispatcher is shut down")
+			continue
+		}
+		d.deliver(item)
+	}
+}
+
+// deliver posts the payload, retrying with exponential backoff on
+// server errors and timeouts.
+func (d *Dispatcher) deliver(item delivery) {
+	backoff := d.retryBackoff
+	for attempt := 1; ; attempt++ {
+		statusCode, err := d.post(item)
+		if err == nil && statusCode >= 200 && statusCode < 300 {
+			d.logger.Debug("webhook delivered",
+				mlog.String("workspaceID", item.workspaceID),
+				mlog.String("url", item.webhook.URL),
+				mlog.Int("attempt", attempt),
+			)
+			return
+		}
+
+		if !isRetryable(statusCode, err) || attempt > maxRetries || d.ctx.Err() != nil {
+			errorMessage := http.StatusText(statusCode)
+			if err != nil {
+				errorMessage = err.Error()
+			}
+			d.recordFailure(item, statusCode, attempt, errorMessage)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-d.ctx.Done():
+		}
+		backoff *= 2
+	}
+}
+
+func (d *Dispatcher) post(item delivery) (int, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, item.webhook.URL, bytes.NewReader(item.body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if item.webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(item.webhook.Secret, item.body))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = ioutil.ReadAll(resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) recordFailure(item delivery, statusCode, attempts int, errorMessage string) {
+	d.logger.Warn("webhook delivery failed",
+		mlog.String("workspaceID", item.workspaceID),
+		mlog.String("url", item.webhook.URL),
+		mlog.Int("statusCode", statusCode),
+		mlog.Int("attempts", attempts),
+		mlog.String("error", errorMessage),
+	)
+
+	err := d.store.InsertWebhookDelivery(model.WebhookDelivery{
+		ID:          utils.CreateGUID(),
+		WorkspaceID: item.workspaceID,
+		URL:         item.webhook.URL,
+		Payload:     string(item.body),
+		StatusCode:  statusCode,
+		Error:       errorMessage,
+		Attempts:    attempts,
+		CreateAt:    utils.GetMillis(),
+	})
+	if err != nil {
+		d.logger.Error("Unable to store failed webhook delivery", mlog.Err(err))
+	}
+}
+
+// isRetryable returns true for server errors and timeouts.
+func isRetryable(statusCode int, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout()
+	}
+	return statusCode >= 500
+}
+
+// Sign returns the value of the signature header for the body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+)
+
+type testDeliveryStore struct {
+	mu         sync.Mutex
+	deliveries []model.WebhookDelivery
+}
+
+func (s *testDeliveryStore) InsertWebhookDelivery(delivery model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func setupDispatcher(t *testing.T, timeout time.Duration) (*Dispatcher, *testDeliveryStore) {
+	logger := mlog.CreateConsoleTestLogger(false, mlog.LvlDebug)
+	t.Cleanup(func() {
+		assert.NoError(t, logger.Shutdown())
+	})
+
+	store := &testDeliveryStore{}
+	return newDispatcher(store, logger, 2, 10, timeout, time.Millisecond), store
+}
+
+func TestDispatcherNotifyBlockChanged(t *testing.T) {
+	payload := BlockChangedPayload{
+		WorkspaceID: "workspace-id",
+		After:       &model.Block{ID: "block-id"},
+		ActorID:     "user-id",
+	}
+
+	t.Run("should post the signed payload", func(t *testing.T) {
+		var body []byte
+		var signature string
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = ioutil.ReadAll(r.Body)
+			signature = r.Header.Get(SignatureHeader)
+		}))
+		defer ts.Close()
+
+		dispatcher, store := setupDispatcher(t, time.Second)
+		dispatcher.NotifyBlockChanged([]model.WorkspaceWebhook{{URL: ts.URL, Secret: "secret"}}, payload)
+		dispatcher.Shutdown(context.Background())
+
+		require.Contains(t, string(body), `"workspaceId":"workspace-id"`)
+		require.Contains(t, string(body), `"before":null`)
+		require.Contains(t, string(body), `"actorId":"user-id"`)
+		require.Equal(t, Sign("secret", body), signature)
+		require.Empty(t, store.deliveries)
+	})
+
+	t.Run("should retry on server errors", func(t *testing.T) {
+		var attempts int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer ts.Close()
+
+		dispatcher, store := setupDispatcher(t, time.Second)
+		dispatcher.NotifyBlockChanged([]model.WorkspaceWebhook{{URL: ts.URL}}, payload)
+		dispatcher.Shutdown(context.Background())
+
+		require.EqualValues(t, 3, attempts)
+		require.Empty(t, store.deliveries)
+	})
+
+	t.Run("should store the delivery after the last retry", func(t *testing.T) {
+		var attempts int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		dispatcher, store := setupDispatcher(t, time.Second)
+		dispatcher.NotifyBlockChanged([]model.WorkspaceWebhook{{URL: ts.URL}}, payload)
+		dispatcher.Shutdown(context.Background())
+
+		require.EqualValues(t, maxRetries+1, attempts)
+		require.Len(t, store.deliveries, 1)
+		require.Equal(t, "workspace-id", store.deliveries[0].WorkspaceID)
+		require.Equal(t, ts.URL, store.deliveries[0].URL)
+		require.Equal(t, http.StatusInternalServerError, store.deliveries[0].StatusCode)
+		require.Equal(t, maxRetries+1, store.deliveries[0].Attempts)
+		require.Contains(t, store.deliveries[0].Payload, "block-id")
+	})
+
+	t.Run("should not retry on client errors", func(t *testing.T) {
+		var attempts int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer ts.Close()
+
+		dispatcher, store := setupDispatcher(t, time.Second)
+		dispatcher.NotifyBlockChanged([]model.WorkspaceWebhook{{URL: ts.URL}}, payload)
+		dispatcher.Shutdown(context.Background())
+
+		require.EqualValues(t, 1, attempts)
+		require.Len(t, store.deliveries, 1)
+		require.Equal(t, http.StatusNotFound, store.deliveries[0].StatusCode)
+	})
+
+	t.Run("should retry on timeouts", func(t *testing.T) {
+		var attempts int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				time.Sleep(100 * time.Millisecond)
+			}
+		}))
+		defer ts.Close()
+
+		dispatcher, store := setupDispatcher(t, 50*time.Millisecond)
+		dispatcher.NotifyBlockChanged([]model.WorkspaceWebhook{{URL: ts.URL}}, payload)
+		dispatcher.Shutdown(context.Background())
+
+		require.EqualValues(t, 2, attempts)
+		require.Empty(t, store.deliveries)
+	})
+
+	t.Run("should abort the deliveries when the shutdown times out", func(t *testing.T) {
+		release := make(chan struct{})
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer ts.Close()
+		defer close(release)
+
+		dispatcher, store := setupDispatcher(t, time.Minute)
+		dispatcher.NotifyBlockChanged([]model.WorkspaceWebhook{{URL: ts.URL}, {URL: ts.URL}, {URL: ts.URL}}, payload)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		dispatcher.Shutdown(ctx)
+
+		require.Less(t, time.Since(start), 10*time.Second)
+		require.Len(t, store.deliveries, 3)
+	})
+
+	t.Run("should store the deliveries queued after shutdown", func(t *testing.T) {
+		dispatcher, store := setupDispatcher(t, time.Second)
+		dispatcher.Shutdown(context.Background())
+
+		dispatcher.NotifyBlockChanged([]model.WorkspaceWebhook{{URL: "http://localhost"}}, payload)
+		require.Len(t, store.deliveries, 1)
+		require.Zero(t, store.deliveries[0].Attempts)
+	})
+}
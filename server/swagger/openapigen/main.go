@@ -0,0 +1,696 @@
+// Command openapigen generates the OpenAPI 3 spec of the API from the
+// swagger:operation comments of its handlers. The schemas of the request
+// and response models are generated from their Go structs: the
+// properties from their json tags, and the descriptions and required
+// properties from their doc comments.
+//
+// Usage:
+//
+//	go run ./swagger/openapigen -api ./api -models ./api,./model -o ./api/openapi.json
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	operationMarker = "swagger:operation"
+	definitionsRef  = "#/definitions/"
+	schemasRef      = "#/components/schemas/"
+	defaultMimeType = "application/json"
+	requiredMarker  = "required: true"
+	swaggerMarker   = "swagger:"
+)
+
+var errUnknownSchema = errors.New("unknown schema")
+
+func main() {
+	apiDir := flag.String("api", "./api", "directory of the package of the handlers")
+	modelDirs := flag.String("models", "./api,./model", "comma separated directories of the packages of the models")
+	output := flag.String("o", "./api/openapi.json", "path of the generated spec")
+	flag.Parse()
+
+	if err := generate(*apiDir, strings.Split(*modelDirs, ","), *output); err != nil {
+		fmt.Fprintln(os.Stderr, "openapigen:", err)
+		os.Exit(1)
+	}
+}
+
+func generate(apiDir string, modelDirs []string, output string) error {
+	operations, err := parseOperations(apiDir)
+	if err != nil {
+		return err
+	}
+
+	models := newModels()
+	for _, dir := range modelDirs {
+		if err := models.parse(dir); err != nil {
+			return err
+		}
+	}
+
+	paths := map[string]map[string]interface{}{}
+	for _, op := range operations {
+		converted, err := op.convert(models)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", op.method, op.path, err)
+		}
+		if paths[op.path] == nil {
+			paths[op.path] = map[string]interface{}{}
+		}
+		paths[op.path][strings.ToLower(op.method)] = converted
+	}
+
+	schemas, err := models.schemas()
+	if err != nil {
+		return err
+	}
+
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Focalboard Server",
+			"description": "Server for Focalboard",
+			"version":     "1.0.0",
+			"license": map[string]interface{}{
+				"name": "Custom",
+				"url":  "https://github.com/mattermost/focalboard/blob/main/LICENSE.txt",
+			},
+			"contact": map[string]interface{}{
+				"name":  "Focalboard",
+				"url":   "https://www.focalboard.com",
+				"email": "api@focalboard.com",
+			},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"BearerAuth": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": `Pass session token using Bearer authentication, e.g. set header "Authorization: Bearer <session token>"`,
+				},
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(output, append(data, '\n'), 0600)
+}
+
+// operation is an operation documented by a swagger:operation comment,
+// whose YAML is in the Swagger 2 format.
+type operation struct {
+	method      string
+	path        string
+	id          string
+	summary     string
+	description string
+	spec        map[string]interface{}
+}
+
+func parseOperations(dir string) ([]*operation, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, excludeTests, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	operations := []*operation{}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, group := range file.Comments {
+				text := group.Text()
+				if !strings.HasPrefix(text, operationMarker) {
+					continue
+				}
+				op, err := parseOperation(text)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", fset.Position(group.Pos()), err)
+				}
+				key := op.method + " " + op.path
+				if seen[key] {
+					return nil, fmt.Errorf("%s: %s is documented twice", fset.Position(group.Pos()), key)
+				}
+				seen[key] = true
+				operations = append(operations, op)
+			}
+		}
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].path != operations[j].path {
+			return operations[i].path < operations[j].path
+		}
+		return operations[i].method < operations[j].method
+	})
+	return operations, nil
+}
+
+func parseOperation(text string) (*operation, error) {
+	lines := strings.Split(text, "\n")
+	header := strings.Fields(lines[0])
+	if len(header) != 4 {
+		return nil, fmt.Errorf("invalid operation header %q", lines[0])
+	}
+	op := &operation{method: strings.ToUpper(header[1]), path: header[2], id: header[3]}
+
+	paragraphs := []string{}
+	var paragraph []string
+	i := 1
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != "---"; i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			if len(paragraph) > 0 {
+				paragraphs = append(paragraphs, strings.Join(paragraph, " "))
+				paragraph = nil
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	if len(paragraph) > 0 {
+		paragraphs = append(paragraphs, strings.Join(paragraph, " "))
+	}
+	if len(paragraphs) > 0 {
+		op.summary = paragraphs[0]
+		op.description = strings.Join(paragraphs[1:], "\n\n")
+	}
+
+	op.spec = map[string]interface{}{}
+	if i < len(lines) {
+		if err := yaml.Unmarshal([]byte(strings.Join(lines[i+1:], "\n")), &op.spec); err != nil {
+			return nil, err
+		}
+	}
+	return op, nil
+}
+
+// convert returns the OpenAPI 3 operation, the body and form parameters
+// becoming the request body, and the response schemas the content of the
+// produced media types.
+func (op *operation) convert(models *models) (map[string]interface{}, error) {
+	converted := map[string]interface{}{"operationId": op.id}
+	if op.summary != "" {
+		converted["summary"] = op.summary
+	}
+	if op.description != "" {
+		converted["description"] = op.description
+	}
+	if security, ok := op.spec["security"]; ok {
+		converted["security"] = security
+	}
+
+	consumes := mimeTypes(op.spec["consumes"])
+	produces := mimeTypes(op.spec["produces"])
+
+	parameters := []interface{}{}
+	form := map[string]interface{}{}
+	formRequired := []interface{}{}
+	params, _ := op.spec["parameters"].([]interface{})
+	for _, p := range params {
+		param, _ := p.(map[string]interface{})
+		switch param["in"] {
+		case "body":
+			schema, err := models.convertSchema(param["schema"])
+			if err != nil {
+				return nil, err
+			}
+			body := map[string]interface{}{"content": content(consumes, schema)}
+			copyKeys(body, param, "description", "required")
+			converted["requestBody"] = body
+		case "formData":
+			form[param["name"].(string)] = parameterSchema(param)
+			if param["required"] == true {
+				formRequired = append(formRequired, param["name"])
+			}
+		default:
+			converted := map[string]interface{}{"schema": parameterSchema(param)}
+			copyKeys(converted, param, "name", "in", "description", "required")
+			parameters = append(parameters, converted)
+		}
+	}
+	if len(form) > 0 {
+		schema := map[string]interface{}{"type": "object", "properties": form}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+		converted["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  content([]string{"multipart/form-data"}, schema),
+		}
+	}
+	if len(parameters) > 0 {
+		converted["parameters"] = parameters
+	}
+
+	responses := map[string]interface{}{}
+	specResponses, _ := op.spec["responses"].(map[string]interface{})
+	for code, r := range specResponses {
+		response, _ := r.(map[string]interface{})
+		convertedResponse := map[string]interface{}{"description": ""}
+		copyKeys(convertedResponse, response, "description")
+		if schema, ok := response["schema"]; ok {
+			convertedSchema, err := models.convertSchema(schema)
+			if err != nil {
+				return nil, err
+			}
+			convertedResponse["content"] = content(produces, convertedSchema)
+		}
+		if headers, ok := response["headers"].(map[string]interface{}); ok {
+			convertedHeaders := map[string]interface{}{}
+			for name, h := range headers {
+				header, _ := h.(map[string]interface{})
+				convertedHeader := map[string]interface{}{"schema": parameterSchema(header)}
+				copyKeys(convertedHeader, header, "description")
+				convertedHeaders[name] = convertedHeader
+			}
+			convertedResponse["headers"] = convertedHeaders
+		}
+		responses[code] = convertedResponse
+	}
+	if len(responses) == 0 {
+		return nil, errors.New("no responses")
+	}
+	converted["responses"] = responses
+
+	return converted, nil
+}
+
+func mimeTypes(value interface{}) []string {
+	types := []string{}
+	list, _ := value.([]interface{})
+	for _, t := range list {
+		if s, ok := t.(string); ok {
+			types = append(types, s)
+		}
+	}
+	if len(types) == 0 {
+		types = append(types, defaultMimeType)
+	}
+	return types
+}
+
+func content(mimeTypes []string, schema interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, mimeType := range mimeTypes {
+		result[mimeType] = map[string]interface{}{"schema": schema}
+	}
+	return result
+}
+
+// parameterSchema returns the schema of a Swagger 2 non-body parameter,
+// whose type is one of its keys.
+func parameterSchema(param map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{}
+	copyKeys(schema, param, "type", "format", "items", "enum", "default", "minimum", "maximum")
+	if schema["type"] == "file" {
+		schema["type"] = "string"
+		schema["format"] = "binary"
+	}
+	return schema
+}
+
+func copyKeys(dst, src map[string]interface{}, keys ...string) {
+	for _, key := range keys {
+		if value, ok := src[key]; ok {
+			dst[key] = value
+		}
+	}
+}
+
+// typeSpec is a type of the packages of the models, with its package.
+type typeSpec struct {
+	pkg  string
+	spec *ast.TypeSpec
+	doc  string
+}
+
+// models generates the schemas of the Go types referenced by the
+// operations, and of the types they reference.
+type models struct {
+	types     map[string]map[string]*typeSpec // by package, then name
+	ordered   []string                        // the packages, in order of lookup
+	referred  map[string]typeSpec
+	generated map[string]interface{}
+	pending   []string
+}
+
+func newModels() *models {
+	return &models{
+		types:     map[string]map[string]*typeSpec{},
+		referred:  map[string]typeSpec{},
+		generated: map[string]interface{}{},
+	}
+}
+
+func excludeTests(info os.FileInfo) bool {
+	return !strings.HasSuffix(info.Name(), "_test.go")
+}
+
+func (m *models) parse(dir string) error {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, filepath.Clean(dir), excludeTests, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	for name, pkg := range pkgs {
+		if m.types[name] == nil {
+			m.types[name] = map[string]*typeSpec{}
+			m.ordered = append(m.ordered, name)
+		}
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.TYPE {
+					continue
+				}
+				for _, s := range genDecl.Specs {
+					spec := s.(*ast.TypeSpec)
+					doc := spec.Doc
+					if doc == nil && len(genDecl.Specs) == 1 {
+						doc = genDecl.Doc
+					}
+					m.types[name][spec.Name.Name] = &typeSpec{pkg: name, spec: spec, doc: doc.Text()}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// lookup returns the type of the name in the package, or in the first
+// package that has it if no package is given.
+func (m *models) lookup(pkg, name string) (*typeSpec, bool) {
+	if pkg != "" {
+		spec, ok := m.types[pkg][name]
+		return spec, ok
+	}
+	for _, p := range m.ordered {
+		if spec, ok := m.types[p][name]; ok {
+			return spec, true
+		}
+	}
+	return nil, false
+}
+
+// ref returns the reference to the schema of the type, whose schema is
+// generated later.
+func (m *models) ref(spec *typeSpec) (map[string]interface{}, error) {
+	name := spec.spec.Name.Name
+	if previous, ok := m.referred[name]; ok {
+		if previous.pkg != spec.pkg {
+			return nil, fmt.Errorf("the schema %s is defined by both %s and %s", name, previous.pkg, spec.pkg)
+		}
+	} else {
+		m.referred[name] = *spec
+		m.pending = append(m.pending, name)
+	}
+	return map[string]interface{}{"$ref": schemasRef + name}, nil
+}
+
+// convertSchema returns the schema of a Swagger 2 operation, with its
+// references to the definitions replaced by references to the schemas of
+// the Go types.
+func (m *models) convertSchema(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && strings.HasPrefix(ref, definitionsRef) {
+			name := strings.TrimPrefix(ref, definitionsRef)
+			spec, ok := m.lookup("", name)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", errUnknownSchema, name)
+			}
+			return m.ref(spec)
+		}
+		converted := map[string]interface{}{}
+		for key, item := range v {
+			c, err := m.convertSchema(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[key] = c
+		}
+		return converted, nil
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			c, err := m.convertSchema(item)
+			if err != nil {
+				return nil, err
+			}
+			converted[i] = c
+		}
+		return converted, nil
+	default:
+		return v, nil
+	}
+}
+
+// schemas generates the schemas of the referenced types.
+func (m *models) schemas() (map[string]interface{}, error) {
+	for len(m.pending) > 0 {
+		name := m.pending[0]
+		m.pending = m.pending[1:]
+		spec := m.referred[name]
+
+		schema, err := m.typeSchema(spec.pkg, spec.spec.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", spec.pkg, name, err)
+		}
+		if description := docDescription(spec.doc); description != "" {
+			schema["description"] = description
+		}
+		m.generated[name] = schema
+	}
+	return m.generated, nil
+}
+
+// typeSchema returns the schema of a Go type of the package. The structs
+// and the named struct types are referenced, the other named types are
+// replaced by their underlying type.
+func (m *models) typeSchema(pkg string, expr ast.Expr) (map[string]interface{}, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if schema, ok := basicSchema(t.Name); ok {
+			return schema, nil
+		}
+		spec, ok := m.lookup(pkg, t.Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownSchema, t.Name)
+		}
+		return m.namedSchema(spec)
+	case *ast.SelectorExpr:
+		qualifier, _ := t.X.(*ast.Ident)
+		switch qualifier.Name + "." + t.Sel.Name {
+		case "time.Time":
+			return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+		case "json.RawMessage":
+			return map[string]interface{}{}, nil
+		}
+		spec, ok := m.lookup(qualifier.Name, t.Sel.Name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s.%s", errUnknownSchema, qualifier.Name, t.Sel.Name)
+		}
+		return m.namedSchema(spec)
+	case *ast.StarExpr:
+		return m.typeSchema(pkg, t.X)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return map[string]interface{}{"type": "string", "format": "byte"}, nil
+		}
+		items, err := m.typeSchema(pkg, t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case *ast.MapType:
+		values, err := m.typeSchema(pkg, t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case *ast.InterfaceType:
+		return map[string]interface{}{}, nil
+	case *ast.StructType:
+		return m.structSchema(pkg, t)
+	default:
+		return nil, fmt.Errorf("%w: unsupported type %T", errUnknownSchema, expr)
+	}
+}
+
+func (m *models) namedSchema(spec *typeSpec) (map[string]interface{}, error) {
+	if _, ok := spec.spec.Type.(*ast.StructType); ok {
+		return m.ref(spec)
+	}
+	return m.typeSchema(spec.pkg, spec.spec.Type)
+}
+
+func basicSchema(name string) (map[string]interface{}, bool) {
+	switch name {
+	case "string":
+		return map[string]interface{}{"type": "string"}, true
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}, true
+	case "int", "int64", "uint", "uint64":
+		return map[string]interface{}{"type": "integer", "format": "int64"}, true
+	case "int32", "int16", "int8", "uint32", "uint16", "uint8":
+		return map[string]interface{}{"type": "integer", "format": "int32"}, true
+	case "float64":
+		return map[string]interface{}{"type": "number", "format": "double"}, true
+	case "float32":
+		return map[string]interface{}{"type": "number", "format": "float"}, true
+	case "interface{}", "any":
+		return map[string]interface{}{}, true
+	}
+	return nil, false
+}
+
+// structSchema returns the schema of the properties of a struct, named by
+// their json tags. The fields of the embedded structs without a json tag
+// are properties of the struct.
+func (m *models) structSchema(pkg string, st *ast.StructType) (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for _, field := range st.Fields.List {
+		name, options := "", ""
+		if field.Tag != nil {
+			tag := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("json")
+			name, options = splitTag(tag)
+			if name == "-" && options == "" {
+				continue
+			}
+		}
+
+		if len(field.Names) == 0 {
+			if name == "" {
+				embedded, err := m.embeddedSchema(pkg, field.Type)
+				if err != nil {
+					return nil, err
+				}
+				for key, value := range embedded.properties {
+					properties[key] = value
+				}
+				required = append(required, embedded.required...)
+				continue
+			}
+		} else {
+			if !field.Names[0].IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Names[0].Name
+			}
+		}
+
+		var schema map[string]interface{}
+		if strings.Contains(options, "string") {
+			schema = map[string]interface{}{"type": "string"}
+		} else {
+			var err error
+			if schema, err = m.typeSchema(pkg, field.Type); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+
+		doc := field.Doc.Text()
+		if description := docDescription(doc); description != "" {
+			if _, ok := schema["$ref"]; ok {
+				// the siblings of a reference are ignored
+				schema = map[string]interface{}{"allOf": []interface{}{schema}}
+			}
+			schema["description"] = description
+		}
+		if strings.Contains(doc, requiredMarker) {
+			required = append(required, name)
+		}
+		properties[name] = schema
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+type embeddedProperties struct {
+	properties map[string]interface{}
+	required   []string
+}
+
+func (m *models) embeddedSchema(pkg string, expr ast.Expr) (*embeddedProperties, error) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	var spec *typeSpec
+	switch t := expr.(type) {
+	case *ast.Ident:
+		spec, _ = m.lookup(pkg, t.Name)
+	case *ast.SelectorExpr:
+		if qualifier, ok := t.X.(*ast.Ident); ok {
+			spec, _ = m.lookup(qualifier.Name, t.Sel.Name)
+		}
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("%w: embedded %T", errUnknownSchema, expr)
+	}
+	st, ok := spec.spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("%w: embedded %s isn't a struct", errUnknownSchema, spec.spec.Name.Name)
+	}
+
+	schema, err := m.structSchema(spec.pkg, st)
+	if err != nil {
+		return nil, err
+	}
+	embedded := &embeddedProperties{properties: schema["properties"].(map[string]interface{})}
+	if required, ok := schema["required"].([]string); ok {
+		embedded.required = required
+	}
+	return embedded, nil
+}
+
+func splitTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// docDescription returns the doc comment without its swagger and
+// required lines, on a single line.
+func docDescription(doc string) string {
+	lines := []string{}
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, swaggerMarker) || strings.HasPrefix(line, "required:") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, " ")
+}
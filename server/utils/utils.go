@@ -23,3 +23,13 @@ func CreateGUID() string {
 func GetMillis() int64 {
 	return time.Now().UnixNano() / int64(time.Millisecond)
 }
+
+// MillisFromTime returns the milliseconds since epoch of the time.
+func MillisFromTime(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// TimeFromMillis returns the time of the milliseconds since epoch.
+func TimeFromMillis(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
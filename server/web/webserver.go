@@ -1,6 +1,7 @@
 package web
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -123,8 +124,16 @@ func (ws *Server) Start() {
 	}()
 }
 
-func (ws *Server) Shutdown() error {
-	return ws.Close()
+// Shutdown stops accepting connections and waits for the requests in
+// flight to finish. The connections still open when the context is done
+// are closed, aborting their requests.
+func (ws *Server) Shutdown(ctx context.Context) error {
+	err := ws.Server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		ws.logger.Warn("the requests didn't finish in time, closing the connections")
+		return ws.Close()
+	}
+	return err
 }
 
 // fileExists returns true if a file exists at the path.
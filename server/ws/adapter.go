@@ -11,9 +11,36 @@ const (
 	websocketActionSubscribeBlocks      = "SUBSCRIBE_BLOCKS"
 	websocketActionUnsubscribeBlocks    = "UNSUBSCRIBE_BLOCKS"
 	websocketActionUpdateBlock          = "UPDATE_BLOCK"
+	websocketActionUpdateBlocks         = "UPDATE_BLOCKS"
+	websocketActionSetActiveBoard       = "SET_ACTIVE_BOARD"
+	websocketActionPresenceJoin         = "PRESENCE_JOIN"
+	websocketActionPresenceLeave        = "PRESENCE_LEAVE"
+	websocketActionLockBlock            = "LOCK_BLOCK"
+	websocketActionUnlockBlock          = "UNLOCK_BLOCK"
+	websocketActionBlockLocked          = "BLOCK_LOCKED"
+	websocketActionBlockUnlocked        = "BLOCK_UNLOCKED"
+	websocketActionResume               = "RESUME"
+	websocketActionFullResyncRequired   = "FULL_RESYNC_REQUIRED"
+	websocketActionCardProgress         = "CARD_PROGRESS"
+	websocketActionServerShutdown       = "SERVER_SHUTDOWN"
+	websocketActionBoardArchived        = "BOARD_ARCHIVED"
 )
 
 type Adapter interface {
 	BroadcastBlockChange(workspaceID string, block model.Block)
-	BroadcastBlockDelete(workspaceID, blockID, parentID string)
+	BroadcastBlockChanges(workspaceID string, blocks []model.Block)
+	BroadcastBlockDelete(workspaceID, blockID, parentID, rootID string)
+	BroadcastCardProgress(workspaceID, boardID string, progress model.ChecklistProgress)
+	BroadcastBoardArchived(workspaceID, boardID string)
+	GetBoardPresence(workspaceID, boardID string) []string
+	GetBlockLockHolder(workspaceID, blockID string) string
+}
+
+// BlockCacheInvalidator is implemented by the adapters of the servers
+// running in a cluster, whose block caches are invalidated by the block
+// changes of the other nodes. An empty list of root IDs invalidates the
+// whole workspace.
+type BlockCacheInvalidator interface {
+	PublishBlockCacheInvalidation(workspaceID string, rootIDs []string)
+	OnBlockCacheInvalidation(handler func(workspaceID string, rootIDs []string))
 }
@@ -0,0 +1,69 @@
+package ws
+
+import (
+	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/store"
+)
+
+// boardVisibility tells which users can view the boards of a workspace
+// while broadcasting, reading the members of each board and whether each
+// user is a guest at most once. The boards without members are open to
+// the users of the workspace but the guests.
+type boardVisibility struct {
+	auth      *auth.Auth
+	container store.Container
+	roles     map[string]map[string]string
+	guests    map[string]bool
+}
+
+func newBoardVisibility(auth *auth.Auth, workspaceID string) *boardVisibility {
+	return &boardVisibility{
+		auth:      auth,
+		container: store.Container{WorkspaceID: workspaceID},
+		roles:     map[string]map[string]string{},
+		guests:    map[string]bool{},
+	}
+}
+
+// canView tells if the user can view the board. The connections of
+// single user mode see every board, and the members of a board, or the
+// users, that can't be read are denied.
+func (v *boardVisibility) canView(userID, boardID string) bool {
+	if userID == singleUserID || boardID == "" {
+		return true
+	}
+
+	roles, ok := v.roles[boardID]
+	if !ok {
+		var err error
+		if roles, err = v.auth.GetBoardRoles(v.container, boardID); err != nil {
+			roles = map[string]string{}
+		}
+		v.roles[boardID] = roles
+	}
+	if roles != nil {
+		return model.BoardRoleAllows(roles[userID], model.BoardRoleViewer)
+	}
+
+	guest, ok := v.guests[userID]
+	if !ok {
+		var err error
+		if guest, err = v.auth.IsGuest(userID); err != nil {
+			guest = true
+		}
+		v.guests[userID] = guest
+	}
+	return !guest
+}
+
+// filterBlocks returns the blocks that the user can view.
+func (v *boardVisibility) filterBlocks(userID string, blocks []model.Block) []model.Block {
+	visible := make([]model.Block, 0, len(blocks))
+	for _, block := range blocks {
+		if v.canView(userID, block.BoardID()) {
+			visible = append(visible, block)
+		}
+	}
+	return visible
+}
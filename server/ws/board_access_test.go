@@ -0,0 +1,60 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/websocket"
+	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/config"
+	"github.com/mattermost/focalboard/server/services/metrics"
+	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/services/store/mockstore"
+
+	"github.com/mattermost/mattermost-server/v6/shared/mlog"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastRespectsBoardMembers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockStore := mockstore.NewMockStore(ctrl)
+	logger := mlog.CreateConsoleTestLogger(false, mlog.LvlError)
+	server := NewServer(auth.New(&config.Configuration{}, mockStore), "", false, logger, metrics.NoopInstrumentation{})
+
+	workspaceID := "workspace"
+	container := store.Container{WorkspaceID: workspaceID}
+	newClient := func(userID string) *wsClient {
+		client := &wsClient{Conn: &websocket.Conn{}, workspaces: []string{}, blocks: []string{}, roots: []string{}, userID: userID}
+		server.subscribeListenerToWorkspace(client, workspaceID)
+		return client
+	}
+	member := newClient("member")
+	outsider := newClient("outsider")
+	newClient("guest")
+
+	mockStore.EXPECT().GetBoardMembers(gomock.Eq(container), gomock.Eq("private-board")).
+		Return([]model.BoardMember{{BoardID: "private-board", UserID: "member", Role: model.BoardRoleViewer}}, nil)
+	mockStore.EXPECT().GetBoardMembers(gomock.Eq(container), gomock.Eq("open-board")).Return([]model.BoardMember{}, nil)
+	mockStore.EXPECT().GetUserByID(gomock.Eq("member")).Return(&model.User{ID: "member"}, nil)
+	mockStore.EXPECT().GetUserByID(gomock.Eq("outsider")).Return(&model.User{ID: "outsider"}, nil)
+	mockStore.EXPECT().GetUserByID(gomock.Eq("guest")).Return(&model.User{ID: "guest", IsGuest: true}, nil)
+
+	visibility := newBoardVisibility(server.auth, workspaceID)
+	card := model.Block{ID: "card", ParentID: "private-board", RootID: "private-board"}
+	require.Equal(t, []*wsClient{member}, server.getListenersForBlockChange(workspaceID, card, visibility))
+
+	// the members of each board, and the guests, are read once per
+	// broadcast
+	board := model.Block{ID: "private-board", RootID: "private-board"}
+	require.Equal(t, []*wsClient{member}, server.getListenersForBlockChange(workspaceID, board, visibility))
+
+	open := model.Block{ID: "open-card", ParentID: "open-board", RootID: "open-board"}
+	// the guests don't see the open boards
+	require.Equal(t, []*wsClient{member, outsider}, server.getListenersForBlockChange(workspaceID, open, visibility))
+	require.Empty(t, visibility.filterBlocks("guest", []model.Block{card, open}))
+
+	require.Equal(t, []model.Block{open}, visibility.filterBlocks("outsider", []model.Block{card, open}))
+}
@@ -0,0 +1,200 @@
+package ws
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// blockLockTimeout is how long a block stays locked without a
+	// renewal from its holder.
+	blockLockTimeout = 60 * time.Second
+
+	// blockLockSweepInterval is how often the locks without a recent
+	// renewal are looked for.
+	blockLockSweepInterval = 5 * time.Second
+)
+
+// BlockLockMsg is sent when a user locks or unlocks a block for editing.
+type BlockLockMsg struct {
+	Action      string `json:"action"`
+	WorkspaceID string `json:"workspaceId"`
+	BoardID     string `json:"boardId"`
+	BlockID     string `json:"blockId"`
+	UserID      string `json:"userId"`
+}
+
+type blockLockKey struct {
+	workspaceID string
+	blockID     string
+}
+
+type blockLock struct {
+	connID    string
+	userID    string
+	boardID   string
+	expiresAt time.Time
+}
+
+// blockLocks tracks the advisory editing locks of the blocks. A lock is
+// held by a connection until it's released, the connection closes or it
+// isn't renewed within the timeout.
+type blockLocks struct {
+	timeout       time.Duration
+	sweepInterval time.Duration
+
+	// onExpire is called with the unlock messages of the locks that
+	// weren't renewed
+	onExpire func(messages []BlockLockMsg)
+
+	mu      sync.Mutex
+	locks   map[blockLockKey]*blockLock
+	sweeper *time.Timer
+
+	// now returns the current time, and is replaced in the tests.
+	now func() time.Time
+}
+
+func newBlockLocks(onExpire func(messages []BlockLockMsg)) *blockLocks {
+	return &blockLocks{
+		timeout:       blockLockTimeout,
+		sweepInterval: blockLockSweepInterval,
+		onExpire:      onExpire,
+		locks:         map[blockLockKey]*blockLock{},
+		now:           time.Now,
+	}
+}
+
+// lock gives the lock of the block to the connection, unless another
+// user holds it. Locking a held block again renews the lock. It returns
+// the resulting lock message, if the holder changed.
+func (bl *blockLocks) lock(connID, userID, workspaceID, boardID, blockID string) []BlockLockMsg {
+	return bl.acquire(connID, userID, workspaceID, boardID, blockID, false)
+}
+
+// forceLock gives the lock of the block to the connection, even if
+// another user holds it.
+func (bl *blockLocks) forceLock(connID, userID, workspaceID, boardID, blockID string) []BlockLockMsg {
+	return bl.acquire(connID, userID, workspaceID, boardID, blockID, true)
+}
+
+// unlock releases the lock of the block if the connection holds it, and
+// returns the resulting unlock message, if any.
+func (bl *blockLocks) unlock(connID, workspaceID, blockID string) []BlockLockMsg {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+
+	key := blockLockKey{workspaceID: workspaceID, blockID: blockID}
+	if lock, ok := bl.locks[key]; !ok || lock.connID != connID {
+		return nil
+	}
+	return bl.removeLock(key)
+}
+
+// removeConnection releases the locks of a closed connection, and
+// returns the resulting unlock messages.
+func (bl *blockLocks) removeConnection(connID string) []BlockLockMsg {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+
+	messages := []BlockLockMsg{}
+	for key, lock := range bl.locks {
+		if lock.connID == connID {
+			messages = append(messages, bl.removeLock(key)...)
+		}
+	}
+	return messages
+}
+
+// expire releases the locks that weren't renewed within the timeout, and
+// returns the resulting unlock messages.
+func (bl *blockLocks) expire() []BlockLockMsg {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+
+	messages := []BlockLockMsg{}
+	now := bl.now()
+	for key, lock := range bl.locks {
+		if !now.Before(lock.expiresAt) {
+			messages = append(messages, bl.removeLock(key)...)
+		}
+	}
+	return messages
+}
+
+// holder returns the ID of the user holding the lock of the block, or an
+// empty string if it isn't locked.
+func (bl *blockLocks) holder(workspaceID, blockID string) string {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+
+	lock, ok := bl.locks[blockLockKey{workspaceID: workspaceID, blockID: blockID}]
+	if !ok || !bl.now().Before(lock.expiresAt) {
+		return ""
+	}
+	return lock.userID
+}
+
+func (bl *blockLocks) acquire(connID, userID, workspaceID, boardID, blockID string, force bool) []BlockLockMsg {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+
+	now := bl.now()
+	key := blockLockKey{workspaceID: workspaceID, blockID: blockID}
+	previous, ok := bl.locks[key]
+	if ok && previous.expiresAt.After(now) && previous.userID != userID && !force {
+		return nil
+	}
+
+	bl.locks[key] = &blockLock{
+		connID:    connID,
+		userID:    userID,
+		boardID:   boardID,
+		expiresAt: now.Add(bl.timeout),
+	}
+
+	if bl.sweeper == nil && bl.onExpire != nil {
+		bl.sweeper = time.AfterFunc(bl.sweepInterval, bl.sweep)
+	}
+
+	// the other users only need to know when the holder changes
+	if ok && previous.expiresAt.After(now) && previous.userID == userID {
+		return nil
+	}
+	return []BlockLockMsg{{
+		Action:      websocketActionBlockLocked,
+		WorkspaceID: workspaceID,
+		BoardID:     boardID,
+		BlockID:     blockID,
+		UserID:      userID,
+	}}
+}
+
+func (bl *blockLocks) sweep() {
+	messages := bl.expire()
+	if len(messages) > 0 {
+		bl.onExpire(messages)
+	}
+
+	// the sweeper only runs while there are locks
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	if len(bl.locks) == 0 {
+		bl.sweeper = nil
+		return
+	}
+	bl.sweeper = time.AfterFunc(bl.sweepInterval, bl.sweep)
+}
+
+func (bl *blockLocks) removeLock(key blockLockKey) []BlockLockMsg {
+	lock := bl.locks[key]
+	delete(bl.locks, key)
+
+	return []BlockLockMsg{{
+		Action:      websocketActionBlockUnlocked,
+		WorkspaceID: key.workspaceID,
+		BoardID:     lock.boardID,
+		BlockID:     key.blockID,
+		UserID:      lock.userID,
+	}}
+}
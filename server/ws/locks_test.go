@@ -0,0 +1,77 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockLocks(t *testing.T) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	locks := newBlockLocks(nil)
+	locks.now = clock.Now
+
+	locked := func(userID, blockID string) BlockLockMsg {
+		return BlockLockMsg{Action: websocketActionBlockLocked, WorkspaceID: "workspace", BoardID: "board", BlockID: blockID, UserID: userID}
+	}
+	unlocked := func(userID, blockID string) BlockLockMsg {
+		return BlockLockMsg{Action: websocketActionBlockUnlocked, WorkspaceID: "workspace", BoardID: "board", BlockID: blockID, UserID: userID}
+	}
+
+	t.Run("Should grant an unheld lock", func(t *testing.T) {
+		require.Equal(t, []BlockLockMsg{locked("user1", "block1")}, locks.lock("conn1", "user1", "workspace", "board", "block1"))
+		require.Equal(t, "user1", locks.holder("workspace", "block1"))
+		require.Empty(t, locks.holder("other-workspace", "block1"))
+	})
+
+	t.Run("Should not grant a lock held by another user", func(t *testing.T) {
+		require.Empty(t, locks.lock("conn2", "user2", "workspace", "board", "block1"))
+		require.Equal(t, "user1", locks.holder("workspace", "block1"))
+	})
+
+	t.Run("Should renew the lock without a message", func(t *testing.T) {
+		require.Empty(t, locks.lock("conn1", "user1", "workspace", "board", "block1"))
+		require.Empty(t, locks.lock("conn3", "user1", "workspace", "board", "block1"))
+	})
+
+	t.Run("Should only unlock from the holding connection", func(t *testing.T) {
+		require.Empty(t, locks.unlock("conn1", "workspace", "block1"))
+		require.Equal(t, []BlockLockMsg{unlocked("user1", "block1")}, locks.unlock("conn3", "workspace", "block1"))
+		require.Empty(t, locks.holder("workspace", "block1"))
+	})
+
+	t.Run("Should force a lock held by another user", func(t *testing.T) {
+		locks.lock("conn1", "user1", "workspace", "board", "block1")
+		require.Equal(t, []BlockLockMsg{locked("user2", "block1")}, locks.forceLock("conn2", "user2", "workspace", "board", "block1"))
+		require.Equal(t, "user2", locks.holder("workspace", "block1"))
+	})
+
+	t.Run("Should unlock the blocks of a closed connection", func(t *testing.T) {
+		locks.lock("conn2", "user2", "workspace", "board", "block2")
+		messages := locks.removeConnection("conn2")
+		require.ElementsMatch(t, []BlockLockMsg{unlocked("user2", "block1"), unlocked("user2", "block2")}, messages)
+		require.Empty(t, locks.holder("workspace", "block1"))
+		require.Empty(t, locks.holder("workspace", "block2"))
+	})
+
+	t.Run("Should expire the locks without a renewal", func(t *testing.T) {
+		locks.lock("conn1", "user1", "workspace", "board", "block1")
+		locks.lock("conn2", "user2", "workspace", "board", "block2")
+
+		clock.Advance(40 * time.Second)
+		locks.lock("conn2", "user2", "workspace", "board", "block2")
+		require.Empty(t, locks.expire())
+
+		clock.Advance(20 * time.Second)
+		require.Empty(t, locks.holder("workspace", "block1"))
+		require.Equal(t, "user2", locks.holder("workspace", "block2"))
+
+		// an expired lock can be taken by another user before the sweep
+		require.Equal(t, []BlockLockMsg{locked("user3", "block1")}, locks.lock("conn3", "user3", "workspace", "board", "block1"))
+		require.Empty(t, locks.expire())
+
+		clock.Advance(time.Minute)
+		require.ElementsMatch(t, []BlockLockMsg{unlocked("user2", "block2"), unlocked("user3", "block1")}, locks.expire())
+	})
+}
@@ -1,8 +1,10 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,7 +15,21 @@ import (
 	"github.com/mattermost/mattermost-server/v6/plugin"
 )
 
-const websocketMessagePrefix = "custom_focalboard_"
+const (
+	websocketMessagePrefix = "custom_focalboard_"
+
+	// presenceClusterEventID is the cluster event with the presence
+	// changes of the connections of a node.
+	presenceClusterEventID = "focalboard_presence"
+
+	// blockLockClusterEventID is the cluster event with the block lock
+	// changes of the connections of a node.
+	blockLockClusterEventID = "focalboard_block_lock"
+
+	// blockCacheClusterEventID is the cluster event with the boards
+	// whose cached blocks are invalidated by the changes of a node.
+	blockCacheClusterEventID = "focalboard_block_cache"
+)
 
 var errMissingWorkspaceInCommand = fmt.Errorf("command doesn't contain workspaceId")
 
@@ -59,17 +75,73 @@ type PluginAdapter struct {
 	listenersByWorkspace map[string][]*PluginAdapterClient
 	listenersByBlock     map[string][]*PluginAdapterClient
 	mu                   sync.RWMutex
+
+	// queues coalesce and batch the block changes of each workspace, so
+	// that they are published as fewer cluster events
+	queues   map[string]*blockQueue
+	queuesMu sync.Mutex
+
+	// presence tracks the connections of this node, and remotePresence
+	// the connections of the other nodes of the cluster
+	presence       *presence
+	remotePresence *presence
+
+	// locks are the block locks held by the connections of this node,
+	// and remoteLocks the ones held by the connections of the other nodes
+	locks       *blockLocks
+	remoteLocks *blockLocks
+
+	// blockCacheHandler invalidates the block cache of this node with
+	// the block changes of the other nodes
+	blockCacheHandler func(workspaceID string, rootIDs []string)
+}
+
+// presenceClusterMsg is the active board of a connection, sent to the
+// other nodes of the cluster. An empty board ID clears it.
+type presenceClusterMsg struct {
+	ConnID      string `json:"connId"`
+	UserID      string `json:"userId"`
+	WorkspaceID string `json:"workspaceId"`
+	BoardID     string `json:"boardId"`
+}
+
+// blockLockClusterMsg is a block lock change of a connection, sent to the
+// other nodes of the cluster. An unlock without a block ID releases all
+// the locks of the connection.
+type blockLockClusterMsg struct {
+	ConnID      string `json:"connId"`
+	UserID      string `json:"userId"`
+	WorkspaceID string `json:"workspaceId"`
+	BoardID     string `json:"boardId"`
+	BlockID     string `json:"blockId"`
+	Locked      bool   `json:"locked"`
+}
+
+// blockCacheClusterMsg is the boards whose cached blocks are invalidated
+// by the changes of a node, sent to the other nodes of the cluster. No
+// root IDs invalidate the whole workspace.
+type blockCacheClusterMsg struct {
+	WorkspaceID string   `json:"workspaceId"`
+	RootIDs     []string `json:"rootIds"`
 }
 
 func NewPluginAdapter(api plugin.API, auth *auth.Auth) *PluginAdapter {
-	return &PluginAdapter{
+	pa := &PluginAdapter{
 		api:                  api,
 		auth:                 auth,
 		listeners:            make(map[string]*PluginAdapterClient),
 		listenersByWorkspace: make(map[string][]*PluginAdapterClient),
 		listenersByBlock:     make(map[string][]*PluginAdapterClient),
 		mu:                   sync.RWMutex{},
+		queues:               make(map[string]*blockQueue),
+		remotePresence:       newPresence(nil),
+		remoteLocks:          newBlockLocks(nil),
 	}
+	pa.presence = newPresence(pa.broadcastPresence)
+	// the remote copies of the expired locks expire on their own, as the
+	// renewals are published too
+	pa.locks = newBlockLocks(pa.broadcastBlockLocks)
+	return pa
 }
 
 func (pa *PluginAdapter) addListener(pac *PluginAdapterClient) {
@@ -188,6 +260,10 @@ func (pa *PluginAdapter) OnWebSocketDisconnect(webConnID, userID string) {
 	}
 
 	pa.removeListener(pac)
+	pa.broadcastPresence(pa.presence.removeConnection(webConnID))
+	pa.publishPresence(presenceClusterMsg{ConnID: webConnID, UserID: userID})
+	pa.broadcastBlockLocks(pa.locks.removeConnection(webConnID))
+	pa.publishBlockLock(blockLockClusterMsg{ConnID: webConnID, UserID: userID})
 }
 
 func commandFromRequest(req *mmModel.WebSocketRequest) (*WebsocketCommand, error) {
@@ -207,6 +283,14 @@ func commandFromRequest(req *mmModel.WebSocketRequest) (*WebsocketCommand, error
 		c.BlockIDs = blockIDs.([]string)
 	}
 
+	if boardID, ok := req.Data["boardId"]; ok {
+		c.BoardID = boardID.(string)
+	}
+
+	if blockID, ok := req.Data["blockId"]; ok {
+		c.BlockID = blockID.(string)
+	}
+
 	return c, nil
 }
 
@@ -249,6 +333,17 @@ func (pa *PluginAdapter) WebSocketMessageHasBeenPosted(webConnID, userID string,
 			"workspaceID", command.WorkspaceID,
 		)
 
+	// The missed changes are not replayed in plugin mode, as the
+	// reconnections are handled by the mattermost websocket. Only a
+	// debug line is logged
+	case websocketActionResume:
+		pa.api.LogDebug(`Command not implemented in plugin mode`,
+			"command", command.Action,
+			"webConnID", webConnID,
+			"userID", userID,
+			"workspaceID", command.WorkspaceID,
+		)
+
 	case websocketActionSubscribeWorkspace:
 		pa.api.LogDebug(`Command: SUBSCRIBE_WORKSPACE`,
 			"webConnID", webConnID,
@@ -256,7 +351,7 @@ func (pa *PluginAdapter) WebSocketMessageHasBeenPosted(webConnID, userID string,
 			"workspaceID", command.WorkspaceID,
 		)
 
-		if !pa.auth.DoesUserHaveWorkspaceAccess(userID, command.WorkspaceID) {
+		if !pa.auth.DoesUserHaveWorkspaceAccess(context.Background(), userID, command.WorkspaceID) {
 			return
 		}
 
@@ -269,10 +364,272 @@ func (pa *PluginAdapter) WebSocketMessageHasBeenPosted(webConnID, userID string,
 		)
 
 		pa.unsubscribeListenerFromWorkspace(pac, command.WorkspaceID)
+	case websocketActionSetActiveBoard:
+		pa.api.LogDebug(`Command: SET_ACTIVE_BOARD`,
+			"webConnID", webConnID,
+			"userID", userID,
+			"workspaceID", command.WorkspaceID,
+			"boardID", command.BoardID,
+		)
+
+		if !pac.isSubscribedToWorkspace(command.WorkspaceID) {
+			return
+		}
+
+		pa.broadcastPresence(pa.presence.setActiveBoard(webConnID, userID, command.WorkspaceID, command.BoardID))
+		pa.publishPresence(presenceClusterMsg{
+			ConnID:      webConnID,
+			UserID:      userID,
+			WorkspaceID: command.WorkspaceID,
+			BoardID:     command.BoardID,
+		})
+	case websocketActionLockBlock:
+		pa.api.LogDebug(`Command: LOCK_BLOCK`,
+			"webConnID", webConnID,
+			"userID", userID,
+			"workspaceID", command.WorkspaceID,
+			"blockID", command.BlockID,
+		)
+
+		if command.BlockID == "" || !pac.isSubscribedToWorkspace(command.WorkspaceID) {
+			return
+		}
+
+		pa.lockBlock(webConnID, userID, command)
+	case websocketActionUnlockBlock:
+		pa.api.LogDebug(`Command: UNLOCK_BLOCK`,
+			"webConnID", webConnID,
+			"userID", userID,
+			"workspaceID", command.WorkspaceID,
+			"blockID", command.BlockID,
+		)
+
+		messages := pa.locks.unlock(webConnID, command.WorkspaceID, command.BlockID)
+		if len(messages) == 0 {
+			return
+		}
+		pa.broadcastBlockLocks(messages)
+		pa.publishBlockLock(blockLockClusterMsg{
+			ConnID:      webConnID,
+			UserID:      userID,
+			WorkspaceID: command.WorkspaceID,
+			BlockID:     command.BlockID,
+		})
+	}
+}
+
+// lockBlock gives the lock of the block to the connection unless another
+// user holds it on any node, and tells the user who holds it.
+func (pa *PluginAdapter) lockBlock(webConnID, userID string, command *WebsocketCommand) {
+	holder := pa.GetBlockLockHolder(command.WorkspaceID, command.BlockID)
+	if holder == "" || holder == userID {
+		messages := pa.locks.lock(webConnID, userID, command.WorkspaceID, command.BoardID, command.BlockID)
+		pa.publishBlockLock(blockLockClusterMsg{
+			ConnID:      webConnID,
+			UserID:      userID,
+			WorkspaceID: command.WorkspaceID,
+			BoardID:     command.BoardID,
+			BlockID:     command.BlockID,
+			Locked:      true,
+		})
+
+		// the broadcast already reaches the user
+		if len(messages) > 0 {
+			pa.broadcastBlockLocks(messages)
+			return
+		}
+	}
+
+	message := BlockLockMsg{
+		Action:      websocketActionBlockLocked,
+		WorkspaceID: command.WorkspaceID,
+		BoardID:     command.BoardID,
+		BlockID:     command.BlockID,
+		UserID:      pa.GetBlockLockHolder(command.WorkspaceID, command.BlockID),
+	}
+	pa.api.PublishWebSocketEvent(message.Action, structToMap(message), &mmModel.WebsocketBroadcast{UserId: userID})
+}
+
+// GetBlockLockHolder returns the ID of the user holding the editing lock
+// of a block on any node of the cluster, or an empty string if it isn't
+// locked.
+func (pa *PluginAdapter) GetBlockLockHolder(workspaceID, blockID string) string {
+	if holder := pa.locks.holder(workspaceID, blockID); holder != "" {
+		return holder
+	}
+	return pa.remoteLocks.holder(workspaceID, blockID)
+}
+
+// broadcastBlockLocks sends the lock messages to the users of the
+// workspace that can view their boards.
+func (pa *PluginAdapter) broadcastBlockLocks(messages []BlockLockMsg) {
+	for _, message := range messages {
+		data := structToMap(message)
+		for _, userID := range pa.getUserIDsForBoard(message.WorkspaceID, message.BoardID) {
+			pa.api.PublishWebSocketEvent(message.Action, data, &mmModel.WebsocketBroadcast{UserId: userID})
+		}
+	}
+}
+
+func (pa *PluginAdapter) publishBlockLock(msg blockLockClusterMsg) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		pa.api.LogError("unable to marshal the block lock cluster event", "err", err)
+		return
+	}
+
+	ev := mmModel.PluginClusterEvent{Id: blockLockClusterEventID, Data: data}
+	opts := mmModel.PluginClusterEventSendOptions{SendType: mmModel.PluginClusterEventSendTypeReliable}
+	if err := pa.api.PublishPluginClusterEvent(ev, opts); err != nil {
+		pa.api.LogError("unable to publish the block lock cluster event", "err", err)
+	}
+}
+
+func (pa *PluginAdapter) applyBlockLockClusterMsg(msg blockLockClusterMsg) {
+	switch {
+	case msg.Locked:
+		pa.remoteLocks.forceLock(msg.ConnID, msg.UserID, msg.WorkspaceID, msg.BoardID, msg.BlockID)
+	case msg.BlockID == "":
+		pa.remoteLocks.removeConnection(msg.ConnID)
+	default:
+		pa.remoteLocks.unlock(msg.ConnID, msg.WorkspaceID, msg.BlockID)
+	}
+}
+
+// GetBoardPresence returns the IDs of the users viewing a board on any
+// node of the cluster.
+func (pa *PluginAdapter) GetBoardPresence(workspaceID, boardID string) []string {
+	userIDs := map[string]bool{}
+	for _, userID := range pa.presence.boardViewers(workspaceID, boardID) {
+		userIDs[userID] = true
+	}
+	for _, userID := range pa.getRemoteBoardViewers(workspaceID, boardID) {
+		userIDs[userID] = true
+	}
+
+	viewers := make([]string, 0, len(userIDs))
+	for userID := range userIDs {
+		viewers = append(viewers, userID)
+	}
+	sort.Strings(viewers)
+	return viewers
+}
+
+// HandleClusterEvent applies the presence, block lock and block cache
+// changes of the other nodes of the cluster. Their messages were already
+// sent by the node of the connection.
+func (pa *PluginAdapter) HandleClusterEvent(ev mmModel.PluginClusterEvent) {
+	switch ev.Id {
+	case presenceClusterEventID:
+		var msg presenceClusterMsg
+		if err := json.Unmarshal(ev.Data, &msg); err != nil {
+			pa.api.LogError("invalid presence cluster event", "err", err)
+			return
+		}
+
+		pa.remotePresence.setActiveBoard(msg.ConnID, msg.UserID, msg.WorkspaceID, msg.BoardID)
+	case blockLockClusterEventID:
+		var msg blockLockClusterMsg
+		if err := json.Unmarshal(ev.Data, &msg); err != nil {
+			pa.api.LogError("invalid block lock cluster event", "err", err)
+			return
+		}
+
+		pa.applyBlockLockClusterMsg(msg)
+	case blockCacheClusterEventID:
+		var msg blockCacheClusterMsg
+		if err := json.Unmarshal(ev.Data, &msg); err != nil {
+			pa.api.LogError("invalid block cache cluster event", "err", err)
+			return
+		}
+
+		pa.mu.RLock()
+		handler := pa.blockCacheHandler
+		pa.mu.RUnlock()
+		if handler != nil {
+			handler(msg.WorkspaceID, msg.RootIDs)
+		}
 	}
 }
 
+// PublishBlockCacheInvalidation sends the boards whose cached blocks are
+// invalidated to the other nodes of the cluster.
+func (pa *PluginAdapter) PublishBlockCacheInvalidation(workspaceID string, rootIDs []string) {
+	data, err := json.Marshal(blockCacheClusterMsg{WorkspaceID: workspaceID, RootIDs: rootIDs})
+	if err != nil {
+		pa.api.LogError("unable to marshal the block cache cluster event", "err", err)
+		return
+	}
+
+	ev := mmModel.PluginClusterEvent{Id: blockCacheClusterEventID, Data: data}
+	opts := mmModel.PluginClusterEventSendOptions{SendType: mmModel.PluginClusterEventSendTypeReliable}
+	if err := pa.api.PublishPluginClusterEvent(ev, opts); err != nil {
+		pa.api.LogError("unable to publish the block cache cluster event", "err", err)
+	}
+}
+
+// OnBlockCacheInvalidation sets the handler of the block cache
+// invalidations of the other nodes of the cluster.
+func (pa *PluginAdapter) OnBlockCacheInvalidation(handler func(workspaceID string, rootIDs []string)) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	pa.blockCacheHandler = handler
+}
+
+func (pa *PluginAdapter) publishPresence(msg presenceClusterMsg) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		pa.api.LogError("unable to marshal the presence cluster event", "err", err)
+		return
+	}
+
+	ev := mmModel.PluginClusterEvent{Id: presenceClusterEventID, Data: data}
+	opts := mmModel.PluginClusterEventSendOptions{SendType: mmModel.PluginClusterEventSendTypeReliable}
+	if err := pa.api.PublishPluginClusterEvent(ev, opts); err != nil {
+		pa.api.LogError("unable to publish the presence cluster event", "err", err)
+	}
+}
+
+// broadcastPresence sends the presence messages to the other users of the
+// workspace that can view their boards, skipping the ones that don't change the presence of the user
+// on the cluster.
+func (pa *PluginAdapter) broadcastPresence(messages []PresenceMsg) {
+	for _, message := range messages {
+		if pa.isViewingOnOtherNode(message) {
+			continue
+		}
+
+		data := structToMap(message)
+		for _, userID := range pa.getUserIDsForBoard(message.WorkspaceID, message.BoardID) {
+			if userID == message.UserID {
+				continue
+			}
+			pa.api.PublishWebSocketEvent(message.Action, data, &mmModel.WebsocketBroadcast{UserId: userID})
+		}
+	}
+}
+
+// getRemoteBoardViewers returns the users viewing a board on the other
+// nodes, without the connections that missed their keepalives, as the
+// nodes that crashed don't send their leave messages.
+func (pa *PluginAdapter) getRemoteBoardViewers(workspaceID, boardID string) []string {
+	pa.remotePresence.expire()
+	return pa.remotePresence.boardViewers(workspaceID, boardID)
+}
+
+func (pa *PluginAdapter) isViewingOnOtherNode(message PresenceMsg) bool {
+	for _, userID := range pa.getRemoteBoardViewers(message.WorkspaceID, message.BoardID) {
+		if userID == message.UserID {
+			return true
+		}
+	}
+	return false
+}
+
 func (pa *PluginAdapter) getUserIDsForWorkspace(workspaceID string) []string {
+	pa.mu.RLock()
+	defer pa.mu.RUnlock()
+
 	userMap := map[string]bool{}
 	for _, pac := range pa.listenersByWorkspace[workspaceID] {
 		userMap[pac.userID] = true
@@ -285,30 +642,106 @@ func (pa *PluginAdapter) getUserIDsForWorkspace(workspaceID string) []string {
 	return userIDs
 }
 
+// getUserIDsForBoard returns the users of the workspace that can view the
+// board.
+func (pa *PluginAdapter) getUserIDsForBoard(workspaceID, boardID string) []string {
+	visibility := newBoardVisibility(pa.auth, workspaceID)
+	userIDs := []string{}
+	for _, userID := range pa.getUserIDsForWorkspace(workspaceID) {
+		if visibility.canView(userID, boardID) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs
+}
+
+func (pa *PluginAdapter) getWorkspaceQueue(workspaceID string) *blockQueue {
+	pa.queuesMu.Lock()
+	defer pa.queuesMu.Unlock()
+
+	queue, ok := pa.queues[workspaceID]
+	if !ok {
+		queue = newBlockQueue(blockQueueDelay, blockQueueMaxBatch, func(blocks []model.Block, _ int64) {
+			pa.publishBlockChanges(workspaceID, blocks)
+		})
+		pa.queues[workspaceID] = queue
+	}
+	return queue
+}
+
+// publishBlockChanges publishes the changes of the blocks to the users
+// of the workspace, each of them getting the changes of the boards that
+// they can view.
+func (pa *PluginAdapter) publishBlockChanges(workspaceID string, blocks []model.Block) {
+	visibility := newBoardVisibility(pa.auth, workspaceID)
+	for _, userID := range pa.getUserIDsForWorkspace(workspaceID) {
+		visible := visibility.filterBlocks(userID, blocks)
+		if len(visible) == 0 {
+			continue
+		}
+
+		event := websocketActionUpdateBlock
+		var message interface{} = UpdateMsg{Action: websocketActionUpdateBlock, Block: visible[0]}
+		if len(visible) > 1 {
+			event = websocketActionUpdateBlocks
+			message = UpdateBlocksMsg{Action: websocketActionUpdateBlocks, Blocks: visible}
+		}
+		pa.api.PublishWebSocketEvent(event, structToMap(message), &mmModel.WebsocketBroadcast{UserId: userID})
+	}
+}
+
+// BroadcastBlockChange queues the change of the block, which is
+// coalesced and batched with the other changes of the workspace.
 func (pa *PluginAdapter) BroadcastBlockChange(workspaceID string, block model.Block) {
 	pa.api.LogInfo("BroadcastingBlockChange",
 		"workspaceID", workspaceID,
 		"blockID", block.ID,
 	)
 
-	message := UpdateMsg{
-		Action: websocketActionUpdateBlock,
-		Block:  block,
-	}
+	pa.getWorkspaceQueue(workspaceID).add(block, 0)
+}
 
-	userIDs := pa.getUserIDsForWorkspace(workspaceID)
-	for _, userID := range userIDs {
-		pa.api.PublishWebSocketEvent(websocketActionUpdateBlock, structToMap(message), &mmModel.WebsocketBroadcast{UserId: userID})
+// BroadcastBlockChanges publishes the changes of the blocks in a single
+// event, along with the pending changes of the workspace.
+func (pa *PluginAdapter) BroadcastBlockChanges(workspaceID string, blocks []model.Block) {
+	if len(blocks) == 0 {
+		return
 	}
+
+	pa.api.LogInfo("BroadcastingBlockChanges",
+		"workspaceID", workspaceID,
+		"blockCount", len(blocks),
+	)
+
+	pa.getWorkspaceQueue(workspaceID).addBatch(blocks, 0)
 }
 
-func (pa *PluginAdapter) BroadcastBlockDelete(workspaceID, blockID, parentID string) {
+func (pa *PluginAdapter) BroadcastBlockDelete(workspaceID, blockID, parentID, rootID string) {
 	now := time.Now().Unix()
 	block := model.Block{}
 	block.ID = blockID
 	block.ParentID = parentID
+	block.RootID = rootID
 	block.UpdateAt = now
 	block.DeleteAt = now
 
 	pa.BroadcastBlockChange(workspaceID, block)
 }
+
+// BroadcastCardProgress publishes the checklist progress of a card to
+// the users of the workspace that can view its board.
+func (pa *PluginAdapter) BroadcastCardProgress(workspaceID, boardID string, progress model.ChecklistProgress) {
+	data := structToMap(newCardProgressMsg(workspaceID, boardID, progress))
+	for _, userID := range pa.getUserIDsForBoard(workspaceID, boardID) {
+		pa.api.PublishWebSocketEvent(websocketActionCardProgress, data, &mmModel.WebsocketBroadcast{UserId: userID})
+	}
+}
+
+// BroadcastBoardArchived publishes the archiving of a board to the users
+// of the workspace that can view it.
+func (pa *PluginAdapter) BroadcastBoardArchived(workspaceID, boardID string) {
+	data := structToMap(newBoardArchivedMsg(workspaceID, boardID))
+	for _, userID := range pa.getUserIDsForBoard(workspaceID, boardID) {
+		pa.api.PublishWebSocketEvent(websocketActionBoardArchived, data, &mmModel.WebsocketBroadcast{UserId: userID})
+	}
+}
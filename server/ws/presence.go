@@ -0,0 +1,190 @@
+package ws
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// presenceTimeout is how long a connection is a viewer of its active
+	// board without a keepalive, so that the crashed clients age out.
+	presenceTimeout = 30 * time.Second
+
+	// presenceSweepInterval is how often the viewers without a recent
+	// keepalive are looked for.
+	presenceSweepInterval = 5 * time.Second
+)
+
+// PresenceMsg is sent when a user starts or stops viewing a board.
+type PresenceMsg struct {
+	Action      string `json:"action"`
+	WorkspaceID string `json:"workspaceId"`
+	BoardID     string `json:"boardId"`
+	UserID      string `json:"userId"`
+}
+
+type presenceViewer struct {
+	userID      string
+	workspaceID string
+	boardID     string
+	lastSeen    time.Time
+}
+
+// presence tracks the active board of each connection. The users join a
+// board when their first connection views it, and leave it when their
+// last connection stops viewing it, disconnects or misses its keepalives.
+type presence struct {
+	timeout       time.Duration
+	sweepInterval time.Duration
+
+	// onExpire is called with the leave messages of the viewers that
+	// missed their keepalives
+	onExpire func(messages []PresenceMsg)
+
+	mu      sync.Mutex
+	viewers map[string]*presenceViewer
+	sweeper *time.Timer
+
+	// now returns the current time, and is replaced in the tests.
+	now func() time.Time
+}
+
+func newPresence(onExpire func(messages []PresenceMsg)) *presence {
+	return &presence{
+		timeout:       presenceTimeout,
+		sweepInterval: presenceSweepInterval,
+		onExpire:      onExpire,
+		viewers:       map[string]*presenceViewer{},
+		now:           time.Now,
+	}
+}
+
+// setActiveBoard sets the board viewed by the connection, or clears it if
+// boardID is empty. Setting the same board again is a keepalive. It
+// returns the resulting join and leave messages.
+func (p *presence) setActiveBoard(connID, userID, workspaceID, boardID string) []PresenceMsg {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	messages := []PresenceMsg{}
+	if viewer, ok := p.viewers[connID]; ok {
+		if viewer.workspaceID == workspaceID && viewer.boardID == boardID && viewer.userID == userID {
+			viewer.lastSeen = p.now()
+			return messages
+		}
+		messages = append(messages, p.removeViewer(connID)...)
+	}
+
+	if boardID == "" {
+		return messages
+	}
+
+	if !p.isViewing(userID, workspaceID, boardID) {
+		messages = append(messages, PresenceMsg{
+			Action:      websocketActionPresenceJoin,
+			WorkspaceID: workspaceID,
+			BoardID:     boardID,
+			UserID:      userID,
+		})
+	}
+	p.viewers[connID] = &presenceViewer{
+		userID:      userID,
+		workspaceID: workspaceID,
+		boardID:     boardID,
+		lastSeen:    p.now(),
+	}
+
+	if p.sweeper == nil && p.onExpire != nil {
+		p.sweeper = time.AfterFunc(p.sweepInterval, p.sweep)
+	}
+	return messages
+}
+
+// removeConnection clears the active board of a closed connection, and
+// returns the resulting leave message, if any.
+func (p *presence) removeConnection(connID string) []PresenceMsg {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.removeViewer(connID)
+}
+
+// expire removes the viewers without a keepalive within the timeout, and
+// returns the resulting leave messages.
+func (p *presence) expire() []PresenceMsg {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	messages := []PresenceMsg{}
+	expireBefore := p.now().Add(-p.timeout)
+	for connID, viewer := range p.viewers {
+		if viewer.lastSeen.Before(expireBefore) {
+			messages = append(messages, p.removeViewer(connID)...)
+		}
+	}
+	return messages
+}
+
+// boardViewers returns the IDs of the users viewing the board, sorted.
+func (p *presence) boardViewers(workspaceID, boardID string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	userIDs := map[string]bool{}
+	for _, viewer := range p.viewers {
+		if viewer.workspaceID == workspaceID && viewer.boardID == boardID {
+			userIDs[viewer.userID] = true
+		}
+	}
+
+	viewers := make([]string, 0, len(userIDs))
+	for userID := range userIDs {
+		viewers = append(viewers, userID)
+	}
+	sort.Strings(viewers)
+	return viewers
+}
+
+func (p *presence) sweep() {
+	messages := p.expire()
+	if len(messages) > 0 {
+		p.onExpire(messages)
+	}
+
+	// the sweeper only runs while there are viewers
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.viewers) == 0 {
+		p.sweeper = nil
+		return
+	}
+	p.sweeper = time.AfterFunc(p.sweepInterval, p.sweep)
+}
+
+func (p *presence) removeViewer(connID string) []PresenceMsg {
+	viewer, ok := p.viewers[connID]
+	if !ok {
+		return nil
+	}
+	delete(p.viewers, connID)
+
+	if p.isViewing(viewer.userID, viewer.workspaceID, viewer.boardID) {
+		return nil
+	}
+	return []PresenceMsg{{
+		Action:      websocketActionPresenceLeave,
+		WorkspaceID: viewer.workspaceID,
+		BoardID:     viewer.boardID,
+		UserID:      viewer.userID,
+	}}
+}
+
+func (p *presence) isViewing(userID, workspaceID, boardID string) bool {
+	for _, viewer := range p.viewers {
+		if viewer.userID == userID && viewer.workspaceID == workspaceID && viewer.boardID == boardID {
+			return true
+		}
+	}
+	return false
+}
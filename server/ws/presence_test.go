@@ -0,0 +1,111 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPresence() (*presence, *testClock) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+	p := newPresence(nil)
+	p.now = clock.Now
+	return p, clock
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestPresence(t *testing.T) {
+	p, clock := newTestPresence()
+
+	join := func(userID, boardID string) PresenceMsg {
+		return PresenceMsg{Action: websocketActionPresenceJoin, WorkspaceID: "workspace", BoardID: boardID, UserID: userID}
+	}
+	leave := func(userID, boardID string) PresenceMsg {
+		return PresenceMsg{Action: websocketActionPresenceLeave, WorkspaceID: "workspace", BoardID: boardID, UserID: userID}
+	}
+
+	t.Run("Should join the active board", func(t *testing.T) {
+		require.Equal(t, []PresenceMsg{join("user1", "board1")}, p.setActiveBoard("conn1", "user1", "workspace", "board1"))
+		require.Equal(t, []PresenceMsg{join("user2", "board1")}, p.setActiveBoard("conn2", "user2", "workspace", "board1"))
+		require.Equal(t, []string{"user1", "user2"}, p.boardViewers("workspace", "board1"))
+		require.Empty(t, p.boardViewers("other-workspace", "board1"))
+	})
+
+	t.Run("Should only join once per user", func(t *testing.T) {
+		require.Empty(t, p.setActiveBoard("conn3", "user1", "workspace", "board1"))
+		require.Equal(t, []string{"user1", "user2"}, p.boardViewers("workspace", "board1"))
+	})
+
+	t.Run("Should not send anything on a keepalive", func(t *testing.T) {
+		require.Empty(t, p.setActiveBoard("conn1", "user1", "workspace", "board1"))
+	})
+
+	t.Run("Should leave the previous board when changing boards", func(t *testing.T) {
+		require.Equal(t, []PresenceMsg{leave("user2", "board1"), join("user2", "board2")}, p.setActiveBoard("conn2", "user2", "workspace", "board2"))
+		require.Equal(t, []string{"user1"}, p.boardViewers("workspace", "board1"))
+		require.Equal(t, []string{"user2"}, p.boardViewers("workspace", "board2"))
+	})
+
+	t.Run("Should leave when the last connection of the user is removed", func(t *testing.T) {
+		require.Empty(t, p.removeConnection("conn3"))
+		require.Equal(t, []PresenceMsg{leave("user1", "board1")}, p.removeConnection("conn1"))
+		require.Empty(t, p.removeConnection("conn1"))
+		require.Empty(t, p.boardViewers("workspace", "board1"))
+	})
+
+	t.Run("Should leave when clearing the active board", func(t *testing.T) {
+		require.Equal(t, []PresenceMsg{leave("user2", "board2")}, p.setActiveBoard("conn2", "user2", "workspace", ""))
+		require.Empty(t, p.boardViewers("workspace", "board2"))
+	})
+
+	t.Run("Should expire the viewers without a keepalive", func(t *testing.T) {
+		p.setActiveBoard("conn1", "user1", "workspace", "board1")
+		p.setActiveBoard("conn2", "user2", "workspace", "board1")
+
+		clock.Advance(20 * time.Second)
+		p.setActiveBoard("conn2", "user2", "workspace", "board1")
+		require.Empty(t, p.expire())
+
+		clock.Advance(20 * time.Second)
+		require.Equal(t, []PresenceMsg{leave("user1", "board1")}, p.expire())
+		require.Equal(t, []string{"user2"}, p.boardViewers("workspace", "board1"))
+	})
+}
+
+func TestPresenceSweeper(t *testing.T) {
+	expired := make(chan []PresenceMsg, 1)
+	p := newPresence(func(messages []PresenceMsg) {
+		expired <- messages
+	})
+	p.timeout = 10 * time.Millisecond
+	p.sweepInterval = 5 * time.Millisecond
+
+	p.setActiveBoard("conn1", "user1", "workspace", "board1")
+
+	select {
+	case messages := <-expired:
+		require.Len(t, messages, 1)
+		require.Equal(t, websocketActionPresenceLeave, messages[0].Action)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the viewer didn't expire")
+	}
+
+	// the sweeper stops once there are no viewers
+	require.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.sweeper == nil
+	}, time.Second, 5*time.Millisecond)
+}
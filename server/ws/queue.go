@@ -0,0 +1,142 @@
+package ws
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+const (
+	// blockQueueDelay is how long the block changes are held so that
+	// the changes of the same block are coalesced and the changes of
+	// different blocks are batched.
+	blockQueueDelay = 50 * time.Millisecond
+
+	// blockQueueMaxBatch is the most block changes sent in one message.
+	blockQueueMaxBatch = 100
+)
+
+// blockQueue coalesces the changes sent to a receiver, keeping only the
+// last change of each block, and sends them in batches. A batch is sent
+// once it's full, or blockQueueDelay after its first change, with the
+// newest sequence of its changes.
+type blockQueue struct {
+	delay    time.Duration
+	maxBatch int
+	send     func(blocks []model.Block, sequence int64)
+
+	// sendMu keeps the batches in order when they're sent concurrently
+	// by the timer and by a full batch
+	sendMu sync.Mutex
+
+	mu       sync.Mutex
+	pending  []model.Block
+	sequence int64
+	indexes  map[string]int
+	timer    *time.Timer
+	closed   bool
+}
+
+func newBlockQueue(delay time.Duration, maxBatch int, send func(blocks []model.Block, sequence int64)) *blockQueue {
+	return &blockQueue{
+		delay:    delay,
+		maxBatch: maxBatch,
+		send:     send,
+		indexes:  map[string]int{},
+	}
+}
+
+// add queues the change of a block with its sequence, replacing the
+// pending change of the same block if any. The changes without a
+// sequence use zero.
+func (q *blockQueue) add(block model.Block, sequence int64) {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+
+	if sequence > q.sequence {
+		q.sequence = sequence
+	}
+	if i, ok := q.indexes[block.ID]; ok {
+		q.pending[i] = block
+		q.mu.Unlock()
+		return
+	}
+
+	q.indexes[block.ID] = len(q.pending)
+	q.pending = append(q.pending, block)
+	full := len(q.pending) >= q.maxBatch
+	if !full && q.timer == nil {
+		q.timer = time.AfterFunc(q.delay, q.flush)
+	}
+	q.mu.Unlock()
+
+	if full {
+		q.flush()
+	}
+}
+
+// addBatch queues the changes of the blocks with their newest sequence,
+// and sends them right away along with the pending changes, in a single
+// batch whatever its size.
+func (q *blockQueue) addBatch(blocks []model.Block, sequence int64) {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+
+	if sequence > q.sequence {
+		q.sequence = sequence
+	}
+	for _, block := range blocks {
+		if i, ok := q.indexes[block.ID]; ok {
+			q.pending[i] = block
+			continue
+		}
+		q.indexes[block.ID] = len(q.pending)
+		q.pending = append(q.pending, block)
+	}
+	q.mu.Unlock()
+
+	q.flush()
+}
+
+// flush sends the pending changes, if any.
+func (q *blockQueue) flush() {
+	q.sendMu.Lock()
+	defer q.sendMu.Unlock()
+
+	q.mu.Lock()
+	blocks := q.pending
+	sequence := q.sequence
+	q.pending = nil
+	q.sequence = 0
+	q.indexes = map[string]int{}
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	q.mu.Unlock()
+
+	if len(blocks) > 0 {
+		q.send(blocks, sequence)
+	}
+}
+
+// close drops the pending changes, and ignores the next ones.
+func (q *blockQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.pending = nil
+	q.indexes = map[string]int{}
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+}
@@ -0,0 +1,156 @@
+package ws
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+// testSender records the batches sent by a queue.
+type testSender struct {
+	mu      sync.Mutex
+	batches [][]model.Block
+}
+
+func (s *testSender) send(blocks []model.Block, _ int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, blocks)
+}
+
+func (s *testSender) getBatches() [][]model.Block {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.batches
+}
+
+func blockIDsOf(blocks []model.Block) []string {
+	ids := make([]string, len(blocks))
+	for i, block := range blocks {
+		ids[i] = block.ID
+	}
+	return ids
+}
+
+func TestBlockQueue(t *testing.T) {
+	t.Run("Should coalesce the changes of the same block", func(t *testing.T) {
+		sender := &testSender{}
+		queue := newBlockQueue(time.Hour, 100, sender.send)
+
+		queue.add(model.Block{ID: "block1", Title: "first"}, 0)
+		queue.add(model.Block{ID: "block2"}, 0)
+		queue.add(model.Block{ID: "block1", Title: "second"}, 0)
+		queue.flush()
+
+		batches := sender.getBatches()
+		require.Len(t, batches, 1)
+		require.Equal(t, []string{"block1", "block2"}, blockIDsOf(batches[0]))
+		require.Equal(t, "second", batches[0][0].Title)
+	})
+
+	t.Run("Should send the newest sequence of the batch", func(t *testing.T) {
+		sequences := []int64{}
+		queue := newBlockQueue(time.Hour, 100, func(_ []model.Block, sequence int64) {
+			sequences = append(sequences, sequence)
+		})
+
+		queue.add(model.Block{ID: "block1"}, 4)
+		queue.add(model.Block{ID: "block2"}, 5)
+		queue.add(model.Block{ID: "block1"}, 6)
+		queue.flush()
+		queue.add(model.Block{ID: "block3"}, 7)
+		queue.flush()
+
+		require.Equal(t, []int64{6, 7}, sequences)
+	})
+
+	t.Run("Should send a full batch right away", func(t *testing.T) {
+		sender := &testSender{}
+		queue := newBlockQueue(time.Hour, 3, sender.send)
+
+		for i := 0; i < 7; i++ {
+			queue.add(model.Block{ID: fmt.Sprintf("block%d", i)}, 0)
+		}
+
+		batches := sender.getBatches()
+		require.Len(t, batches, 2)
+		require.Equal(t, []string{"block0", "block1", "block2"}, blockIDsOf(batches[0]))
+		require.Equal(t, []string{"block3", "block4", "block5"}, blockIDsOf(batches[1]))
+
+		queue.flush()
+		batches = sender.getBatches()
+		require.Len(t, batches, 3)
+		require.Equal(t, []string{"block6"}, blockIDsOf(batches[2]))
+	})
+
+	t.Run("Should send a batch right away in a single message", func(t *testing.T) {
+		sender := &testSender{}
+		queue := newBlockQueue(time.Hour, 3, sender.send)
+
+		queue.add(model.Block{ID: "block0", Title: "first"}, 0)
+		blocks := []model.Block{{ID: "block0", Title: "second"}}
+		for i := 1; i < 5; i++ {
+			blocks = append(blocks, model.Block{ID: fmt.Sprintf("block%d", i)})
+		}
+		queue.addBatch(blocks, 0)
+
+		batches := sender.getBatches()
+		require.Len(t, batches, 1)
+		require.Equal(t, []string{"block0", "block1", "block2", "block3", "block4"}, blockIDsOf(batches[0]))
+		require.Equal(t, "second", batches[0][0].Title)
+	})
+
+	t.Run("Should send the batch after the delay", func(t *testing.T) {
+		sender := &testSender{}
+		queue := newBlockQueue(10*time.Millisecond, 100, sender.send)
+
+		queue.add(model.Block{ID: "block1"}, 0)
+		require.Eventually(t, func() bool { return len(sender.getBatches()) == 1 }, time.Second, 5*time.Millisecond)
+
+		// a change after the flush starts a new batch
+		queue.add(model.Block{ID: "block1"}, 0)
+		require.Eventually(t, func() bool { return len(sender.getBatches()) == 2 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("Should drop the changes once closed", func(t *testing.T) {
+		sender := &testSender{}
+		queue := newBlockQueue(10*time.Millisecond, 100, sender.send)
+
+		queue.add(model.Block{ID: "block1"}, 0)
+		queue.close()
+		queue.add(model.Block{ID: "block2"}, 0)
+		queue.flush()
+		time.Sleep(20 * time.Millisecond)
+
+		require.Empty(t, sender.getBatches())
+	})
+}
+
+// BenchmarkBlockQueue inserts 1,000 blocks, as a board duplication or an
+// import does, and reports the messages sent for them.
+func BenchmarkBlockQueue(b *testing.B) {
+	const blockCount = 1000
+
+	blocks := make([]model.Block, blockCount)
+	for i := range blocks {
+		blocks[i] = model.Block{ID: fmt.Sprintf("block%d", i)}
+	}
+
+	var messages int
+	for n := 0; n < b.N; n++ {
+		sender := &testSender{}
+		queue := newBlockQueue(blockQueueDelay, blockQueueMaxBatch, sender.send)
+		for _, block := range blocks {
+			queue.add(block, 0)
+		}
+		queue.flush()
+		messages += len(sender.getBatches())
+	}
+
+	b.ReportMetric(float64(blockCount), "unbatched-messages/op")
+	b.ReportMetric(float64(messages)/float64(b.N), "messages/op")
+}
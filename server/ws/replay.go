@@ -0,0 +1,101 @@
+package ws
+
+import (
+	"sync"
+
+	"github.com/mattermost/focalboard/server/model"
+)
+
+// defaultReplayBufferSize is the number of block changes kept per
+// workspace for the clients that resume their connection.
+const defaultReplayBufferSize = 1000
+
+// ResyncMsg is sent when the changes missed by a resuming client aren't
+// in the replay buffer anymore, so that it has to fetch the blocks again.
+// Sequence is the newest sequence of the workspace, to resume from next.
+type ResyncMsg struct {
+	Action      string `json:"action"`
+	WorkspaceID string `json:"workspaceId"`
+	Sequence    int64  `json:"sequence"`
+}
+
+type replayEvent struct {
+	sequence int64
+	block    model.Block
+}
+
+// replayBuffer numbers the block changes of a workspace, and keeps the
+// last ones in a ring so that the clients that reconnect can get the
+// changes they missed.
+type replayBuffer struct {
+	mu       sync.Mutex
+	events   []replayEvent
+	size     int
+	next     int
+	sequence int64
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	if size < 0 {
+		size = 0
+	}
+
+	return &replayBuffer{
+		events: make([]replayEvent, 0, size),
+		size:   size,
+	}
+}
+
+// record numbers and keeps the change of a block, overwriting the oldest
+// change once the buffer is full. The sequence of the change is passed to
+// send under the lock of the buffer, so that the changes are sent in the
+// order of their sequences.
+func (b *replayBuffer) record(block model.Block, send func(sequence int64)) {
+	b.recordBatch([]model.Block{block}, send)
+}
+
+// recordBatch numbers and keeps the changes of the blocks like record,
+// passing the newest sequence to send once for all of them.
+func (b *replayBuffer) recordBatch(blocks []model.Block, send func(sequence int64)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, block := range blocks {
+		b.sequence++
+		if b.size > 0 {
+			event := replayEvent{sequence: b.sequence, block: block}
+			if len(b.events) < b.size {
+				b.events = append(b.events, event)
+			} else {
+				b.events[b.next] = event
+			}
+			b.next = (b.next + 1) % b.size
+		}
+	}
+
+	send(b.sequence)
+}
+
+// since returns the changes after the sequence, in order, and the newest
+// sequence. It returns false if the changes after the sequence aren't all
+// in the buffer anymore, or if the sequence is newer than the buffer, as
+// it is after a restart.
+func (b *replayBuffer) since(sequence int64) ([]model.Block, int64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	oldest := b.sequence - int64(len(b.events)) + 1
+	if sequence > b.sequence || sequence < oldest-1 {
+		return nil, b.sequence, false
+	}
+
+	blocks := make([]model.Block, 0, b.sequence-sequence)
+	for i := 0; i < len(b.events); i++ {
+		// the oldest event is the next one to be overwritten
+		event := b.events[(b.next+i)%len(b.events)]
+		if event.sequence > sequence {
+			blocks = append(blocks, event.block)
+		}
+	}
+	return blocks, b.sequence, true
+}
@@ -0,0 +1,115 @@
+package ws
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/stretchr/testify/require"
+)
+
+func recordBlocks(buffer *replayBuffer, count int) []int64 {
+	sequences := []int64{}
+	for i := 0; i < count; i++ {
+		// the blocks are named after the changes, from block0
+		block := model.Block{ID: fmt.Sprintf("block%d", buffer.sequence)}
+		buffer.record(block, func(sequence int64) {
+			sequences = append(sequences, sequence)
+		})
+	}
+	return sequences
+}
+
+func TestReplayBuffer(t *testing.T) {
+	t.Run("Should number the changes in order", func(t *testing.T) {
+		buffer := newReplayBuffer(10)
+		require.Equal(t, []int64{1, 2, 3}, recordBlocks(buffer, 3))
+
+		blocks, newest, ok := buffer.since(1)
+		require.True(t, ok)
+		require.Equal(t, int64(3), newest)
+		require.Equal(t, []string{"block1", "block2"}, blockIDsOf(blocks))
+	})
+
+	t.Run("Should number the changes of a batch in order", func(t *testing.T) {
+		buffer := newReplayBuffer(10)
+		recordBlocks(buffer, 1)
+
+		sequences := []int64{}
+		buffer.recordBatch([]model.Block{{ID: "block1"}, {ID: "block2"}}, func(sequence int64) {
+			sequences = append(sequences, sequence)
+		})
+		require.Equal(t, []int64{3}, sequences)
+
+		blocks, newest, ok := buffer.since(1)
+		require.True(t, ok)
+		require.Equal(t, int64(3), newest)
+		require.Equal(t, []string{"block1", "block2"}, blockIDsOf(blocks))
+	})
+
+	t.Run("Should replay nothing when resuming at the newest sequence", func(t *testing.T) {
+		buffer := newReplayBuffer(10)
+		recordBlocks(buffer, 3)
+
+		blocks, newest, ok := buffer.since(3)
+		require.True(t, ok)
+		require.Equal(t, int64(3), newest)
+		require.Empty(t, blocks)
+	})
+
+	t.Run("Should replay nothing for an empty buffer", func(t *testing.T) {
+		blocks, newest, ok := newReplayBuffer(10).since(0)
+		require.True(t, ok)
+		require.Zero(t, newest)
+		require.Empty(t, blocks)
+	})
+
+	t.Run("Should keep the newest changes after a wraparound", func(t *testing.T) {
+		buffer := newReplayBuffer(4)
+		recordBlocks(buffer, 10)
+
+		// the buffer holds the changes 7 to 10
+		blocks, newest, ok := buffer.since(6)
+		require.True(t, ok)
+		require.Equal(t, int64(10), newest)
+		require.Equal(t, []string{"block6", "block7", "block8", "block9"}, blockIDsOf(blocks))
+
+		blocks, _, ok = buffer.since(8)
+		require.True(t, ok)
+		require.Equal(t, []string{"block8", "block9"}, blockIDsOf(blocks))
+
+		blocks, _, ok = buffer.since(10)
+		require.True(t, ok)
+		require.Empty(t, blocks)
+	})
+
+	t.Run("Should require a resync when the missed changes were overwritten", func(t *testing.T) {
+		buffer := newReplayBuffer(4)
+		recordBlocks(buffer, 10)
+
+		blocks, newest, ok := buffer.since(5)
+		require.False(t, ok)
+		require.Equal(t, int64(10), newest)
+		require.Nil(t, blocks)
+	})
+
+	t.Run("Should require a resync for a sequence newer than the buffer", func(t *testing.T) {
+		buffer := newReplayBuffer(4)
+		recordBlocks(buffer, 2)
+
+		_, newest, ok := buffer.since(5)
+		require.False(t, ok)
+		require.Equal(t, int64(2), newest)
+	})
+
+	t.Run("Should only resume at the newest sequence without a buffer", func(t *testing.T) {
+		buffer := newReplayBuffer(0)
+		require.Equal(t, []int64{1, 2}, recordBlocks(buffer, 2))
+
+		_, _, ok := buffer.since(1)
+		require.False(t, ok)
+		blocks, _, ok := buffer.since(2)
+		require.True(t, ok)
+		require.Empty(t, blocks)
+	})
+}
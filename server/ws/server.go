@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"sync"
@@ -10,18 +11,58 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/mattermost/focalboard/server/auth"
 	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/metrics"
 	"github.com/mattermost/focalboard/server/services/store"
+	"github.com/mattermost/focalboard/server/utils"
 
 	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 )
 
 const singleUserID = "single-user-id"
 
+// requestIDHeader is the header of the request ID set by the API.
+const requestIDHeader = "X-Request-ID"
+
 type wsClient struct {
 	*websocket.Conn
+	id         string
 	mu         sync.Mutex
 	workspaces []string
 	blocks     []string
+
+	// roots are the boards of the workspace subscriptions that the client
+	// wants the changes of. The clients that never subscribed to a board
+	// get the changes of the whole workspaces.
+	roots       []string
+	filterRoots bool
+
+	// userID is the user of the connection once it's authenticated,
+	// whose boards the workspace changes are filtered by
+	userID string
+
+	// queue coalesces and batches the block changes sent to the client
+	queue *blockQueue
+
+	// requestID is the ID of the request the connection was upgraded from
+	requestID string
+}
+
+func newWSClient(conn *websocket.Conn, logger *mlog.Logger) *wsClient {
+	client := &wsClient{Conn: conn, id: utils.CreateGUID(), workspaces: []string{}, blocks: []string{}, roots: []string{}}
+	client.queue = newBlockQueue(blockQueueDelay, blockQueueMaxBatch, func(blocks []model.Block, sequence int64) {
+		var message interface{}
+		if len(blocks) == 1 {
+			message = UpdateMsg{Action: websocketActionUpdateBlock, Block: blocks[0], Sequence: sequence}
+		} else {
+			message = UpdateBlocksMsg{Action: websocketActionUpdateBlocks, Blocks: blocks, Sequence: sequence}
+		}
+
+		if err := client.WriteJSON(message); err != nil {
+			logger.Error("broadcast error", mlog.Err(err))
+			client.Close()
+		}
+	})
+	return client
 }
 
 func (c *wsClient) WriteJSON(v interface{}) error {
@@ -51,6 +92,26 @@ func (c *wsClient) isSubscribedToBlock(blockID string) bool {
 	return false
 }
 
+func (c *wsClient) isSubscribedToRoot(rootID string) bool {
+	for _, id := range c.roots {
+		if id == rootID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wantsWorkspaceChange returns true if the client, subscribed to the
+// workspace of the block, should get the change of the block.
+func (c *wsClient) wantsWorkspaceChange(block model.Block) bool {
+	if !c.filterRoots {
+		return true
+	}
+
+	return c.isSubscribedToRoot(block.RootID) || c.isSubscribedToRoot(block.ID)
+}
+
 // Server is a WebSocket server.
 type Server struct {
 	upgrader             websocket.Upgrader
@@ -62,12 +123,81 @@ type Server struct {
 	singleUserToken      string
 	isMattermostAuth     bool
 	logger               *mlog.Logger
+	instrumentation      metrics.Instrumentation
+	presence             *presence
+	locks                *blockLocks
+
+	// replayBuffers keep the last block changes of each workspace for
+	// the clients that resume their connection
+	replayBuffers    map[string]*replayBuffer
+	replayBufferSize int
+	replayMu         sync.Mutex
+
+	// shutdown is set once the server is shutting down, the new
+	// connections being refused
+	shutdown bool
 }
 
-// UpdateMsg is sent on block updates.
+// UpdateMsg is sent on block updates. Sequence is the sequence of the
+// change in the workspace, for the clients to resume from after a
+// reconnect.
 type UpdateMsg struct {
-	Action string      `json:"action"`
-	Block  model.Block `json:"block"`
+	Action   string      `json:"action"`
+	Block    model.Block `json:"block"`
+	Sequence int64       `json:"sequence,omitempty"`
+}
+
+// UpdateBlocksMsg is sent on batched block updates, with the newest
+// sequence of the changes.
+type UpdateBlocksMsg struct {
+	Action   string        `json:"action"`
+	Blocks   []model.Block `json:"blocks"`
+	Sequence int64         `json:"sequence,omitempty"`
+}
+
+// CardProgressMsg is sent when the checklist progress of a card changes,
+// so that the board views don't need to get the checkboxes of the card.
+type CardProgressMsg struct {
+	Action      string `json:"action"`
+	WorkspaceID string `json:"workspaceId"`
+	BoardID     string `json:"boardId"`
+	CardID      string `json:"cardId"`
+	Checked     int64  `json:"checked"`
+	Total       int64  `json:"total"`
+}
+
+func newCardProgressMsg(workspaceID, boardID string, progress model.ChecklistProgress) CardProgressMsg {
+	return CardProgressMsg{
+		Action:      websocketActionCardProgress,
+		WorkspaceID: workspaceID,
+		BoardID:     boardID,
+		CardID:      progress.CardID,
+		Checked:     progress.Checked,
+		Total:       progress.Total,
+	}
+}
+
+// BoardArchivedMsg is sent when a board is archived, so that the clients
+// viewing it can leave it, its blocks being read-only.
+type BoardArchivedMsg struct {
+	Action      string `json:"action"`
+	WorkspaceID string `json:"workspaceId"`
+	BoardID     string `json:"boardId"`
+}
+
+func newBoardArchivedMsg(workspaceID, boardID string) BoardArchivedMsg {
+	return BoardArchivedMsg{
+		Action:      websocketActionBoardArchived,
+		WorkspaceID: workspaceID,
+		BoardID:     boardID,
+	}
+}
+
+// ServerShutdownMsg is sent to the clients when the server shuts down,
+// before their connection is closed, so that they reconnect to another
+// server and resume from their last sequence.
+type ServerShutdownMsg struct {
+	Action string `json:"action"`
 }
 
 // WebsocketCommand is an incoming command from the client.
@@ -77,6 +207,10 @@ type WebsocketCommand struct {
 	Token       string   `json:"token"`
 	ReadToken   string   `json:"readToken"`
 	BlockIDs    []string `json:"blockIds"`
+	RootIDs     []string `json:"rootIds"`
+	BoardID     string   `json:"boardId"`
+	BlockID     string   `json:"blockId"`
+	Sequence    int64    `json:"sequence"`
 }
 
 type websocketSession struct {
@@ -89,8 +223,8 @@ func (wss *websocketSession) isAuthenticated() bool {
 }
 
 // NewServer creates a new Server.
-func NewServer(auth *auth.Auth, singleUserToken string, isMattermostAuth bool, logger *mlog.Logger) *Server {
-	return &Server{
+func NewServer(auth *auth.Auth, singleUserToken string, isMattermostAuth bool, logger *mlog.Logger, instrumentation metrics.Instrumentation) *Server {
+	ws := &Server{
 		listeners:            make(map[*wsClient]bool),
 		listenersByWorkspace: make(map[string][]*wsClient),
 		listenersByBlock:     make(map[string][]*wsClient),
@@ -98,12 +232,41 @@ func NewServer(auth *auth.Auth, singleUserToken string, isMattermostAuth bool, l
 			CheckOrigin: func(r *http.Request) bool {
 				return true
 			},
+			// the block updates compress well, the clients supporting
+			// permessage-deflate get their frames compressed
+			EnableCompression: true,
 		},
 		auth:             auth,
 		singleUserToken:  singleUserToken,
 		isMattermostAuth: isMattermostAuth,
 		logger:           logger,
+		instrumentation:  instrumentation,
+		replayBuffers:    make(map[string]*replayBuffer),
+		replayBufferSize: defaultReplayBufferSize,
 	}
+	ws.presence = newPresence(func(messages []PresenceMsg) {
+		ws.broadcastPresence(messages, nil)
+	})
+	ws.locks = newBlockLocks(func(messages []BlockLockMsg) {
+		ws.broadcastBlockLocks(messages, nil)
+	})
+	return ws
+}
+
+// SetReplayBufferSize sets the number of block changes kept per workspace
+// for the clients that resume their connection. It should be called
+// before any change is broadcast.
+func (ws *Server) SetReplayBufferSize(size int) {
+	ws.replayMu.Lock()
+	defer ws.replayMu.Unlock()
+	ws.replayBufferSize = size
+}
+
+// SetCheckOrigin sets the check of the origins of the upgrade requests,
+// which are all accepted by default. It should be called before the
+// routes are served.
+func (ws *Server) SetCheckOrigin(checkOrigin func(r *http.Request) bool) {
+	ws.upgrader.CheckOrigin = checkOrigin
 }
 
 // RegisterRoutes registers routes.
@@ -112,6 +275,14 @@ func (ws *Server) RegisterRoutes(r *mux.Router) {
 }
 
 func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	ws.mu.RLock()
+	shutdown := ws.shutdown
+	ws.mu.RUnlock()
+	if shutdown {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
 	// Upgrade initial GET request to a websocket
 	client, err := ws.upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -121,22 +292,35 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	// create an empty session with websocket client
 	wsSession := websocketSession{
-		client: &wsClient{client, sync.Mutex{}, []string{}, []string{}},
+		client: newWSClient(client, ws.logger),
 		userID: "",
 	}
+	// the ID of the upgrade request, set by the API, traces the events of
+	// the connection back to it
+	wsSession.client.requestID = r.Header.Get(requestIDHeader)
+	ws.logger.Debug("CONNECT WebSocket",
+		mlog.Stringer("client", wsSession.client.RemoteAddr()),
+		mlog.String("requestID", wsSession.client.requestID),
+	)
 
 	if ws.isMattermostAuth {
 		wsSession.userID = r.Header.Get("Mattermost-User-Id")
+		wsSession.client.userID = wsSession.userID
 	}
 
 	ws.addListener(wsSession.client)
 
 	// Make sure we close the connection when the function returns
 	defer func() {
-		ws.logger.Debug("DISCONNECT WebSocket", mlog.Stringer("client", wsSession.client.RemoteAddr()))
+		ws.logger.Debug("DISCONNECT WebSocket",
+			mlog.Stringer("client", wsSession.client.RemoteAddr()),
+			mlog.String("requestID", wsSession.client.requestID),
+		)
 
 		// Remove client from listeners
 		ws.removeListener(wsSession.client)
+		ws.broadcastPresence(ws.presence.removeConnection(wsSession.client.id), wsSession.client)
+		ws.broadcastBlockLocks(ws.locks.removeConnection(wsSession.client.id), wsSession.client)
 		wsSession.client.Close()
 	}()
 
@@ -146,6 +330,7 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			ws.logger.Error("ERROR WebSocket",
 				mlog.Stringer("client", wsSession.client.RemoteAddr()),
+				mlog.String("requestID", wsSession.client.requestID),
 				mlog.Err(err),
 			)
 			ws.removeListener(wsSession.client)
@@ -172,7 +357,7 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		// if the client wants to subscribe to a set of blocks and it
 		// is sending a read token, we don't need to check for
 		// authentication
-		if command.Action == websocketActionSubscribeBlocks {
+		if command.Action == websocketActionSubscribeBlocks && len(command.ReadToken) != 0 {
 			ws.logger.Debug(`Command: SUBSCRIBE_BLOCKS`,
 				mlog.String("workspaceID", command.WorkspaceID),
 				mlog.Stringer("client", wsSession.client.RemoteAddr()),
@@ -192,7 +377,7 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		if command.Action == websocketActionUnsubscribeBlocks {
+		if command.Action == websocketActionUnsubscribeBlocks && len(command.ReadToken) != 0 {
 			ws.logger.Debug(`Command: UNSUBSCRIBE_BLOCKS`,
 				mlog.String("workspaceID", command.WorkspaceID),
 				mlog.Stringer("client", wsSession.client.RemoteAddr()),
@@ -240,7 +425,7 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 				// if not in single user mode validate that the session
 				// has permissions to the workspace
 			} else {
-				if !ws.auth.DoesUserHaveWorkspaceAccess(wsSession.userID, command.WorkspaceID) {
+				if !ws.auth.DoesUserHaveWorkspaceAccess(context.Background(), wsSession.userID, command.WorkspaceID) {
 					continue
 				}
 			}
@@ -253,6 +438,85 @@ func (ws *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			)
 
 			ws.unsubscribeListenerFromWorkspace(wsSession.client, command.WorkspaceID)
+		case websocketActionSubscribeBlocks:
+			ws.logger.Debug(`Command: SUBSCRIBE_BLOCKS`,
+				mlog.String("workspaceID", command.WorkspaceID),
+				mlog.Int("root_count", len(command.RootIDs)),
+				mlog.Stringer("client", wsSession.client.RemoteAddr()),
+			)
+
+			// the boards only narrow down the workspace subscriptions,
+			// so that the access was checked when subscribing to them
+			ws.subscribeListenerToRoots(wsSession.client, command.RootIDs)
+		case websocketActionUnsubscribeBlocks:
+			ws.logger.Debug(`Command: UNSUBSCRIBE_BLOCKS`,
+				mlog.String("workspaceID", command.WorkspaceID),
+				mlog.Int("root_count", len(command.RootIDs)),
+				mlog.Stringer("client", wsSession.client.RemoteAddr()),
+			)
+
+			ws.unsubscribeListenerFromRoots(wsSession.client, command.RootIDs)
+		case websocketActionSetActiveBoard:
+			ws.logger.Debug(`Command: SET_ACTIVE_BOARD`,
+				mlog.String("workspaceID", command.WorkspaceID),
+				mlog.String("boardID", command.BoardID),
+				mlog.Stringer("client", wsSession.client.RemoteAddr()),
+			)
+
+			// the access to the workspace was checked when subscribing to it
+			if !ws.isListenerSubscribedToWorkspace(wsSession.client, command.WorkspaceID) {
+				continue
+			}
+
+			messages := ws.presence.setActiveBoard(wsSession.client.id, wsSession.userID, command.WorkspaceID, command.BoardID)
+			ws.broadcastPresence(messages, wsSession.client)
+		case websocketActionResume:
+			ws.logger.Debug(`Command: RESUME`,
+				mlog.String("workspaceID", command.WorkspaceID),
+				mlog.Int64("sequence", command.Sequence),
+				mlog.Stringer("client", wsSession.client.RemoteAddr()),
+			)
+
+			// the client subscribes to the workspace again before resuming
+			if !ws.isListenerSubscribedToWorkspace(wsSession.client, command.WorkspaceID) {
+				continue
+			}
+
+			ws.resumeListener(wsSession.client, command.WorkspaceID, command.Sequence)
+		case websocketActionLockBlock:
+			ws.logger.Debug(`Command: LOCK_BLOCK`,
+				mlog.String("workspaceID", command.WorkspaceID),
+				mlog.String("blockID", command.BlockID),
+				mlog.Stringer("client", wsSession.client.RemoteAddr()),
+			)
+
+			if command.BlockID == "" || !ws.isListenerSubscribedToWorkspace(wsSession.client, command.WorkspaceID) {
+				continue
+			}
+
+			messages := ws.locks.lock(wsSession.client.id, wsSession.userID, command.WorkspaceID, command.BoardID, command.BlockID)
+			ws.broadcastBlockLocks(messages, wsSession.client)
+
+			// the client is told who holds the lock, whether it got it
+			// or not
+			holder := BlockLockMsg{
+				Action:      websocketActionBlockLocked,
+				WorkspaceID: command.WorkspaceID,
+				BoardID:     command.BoardID,
+				BlockID:     command.BlockID,
+				UserID:      ws.locks.holder(command.WorkspaceID, command.BlockID),
+			}
+			if err := wsSession.client.WriteJSON(holder); err != nil {
+				ws.logger.Error("send block lock error", mlog.Err(err))
+			}
+		case websocketActionUnlockBlock:
+			ws.logger.Debug(`Command: UNLOCK_BLOCK`,
+				mlog.String("workspaceID", command.WorkspaceID),
+				mlog.String("blockID", command.BlockID),
+				mlog.Stringer("client", wsSession.client.RemoteAddr()),
+			)
+
+			ws.broadcastBlockLocks(ws.locks.unlock(wsSession.client.id, command.WorkspaceID, command.BlockID), nil)
 		default:
 			ws.logger.Error(`ERROR webSocket command, invalid action`, mlog.String("action", command.Action))
 		}
@@ -271,7 +535,7 @@ func (ws *Server) isCommandReadTokenValid(command WebsocketCommand) bool {
 	if len(command.ReadToken) != 0 && len(command.BlockIDs) != 0 {
 		// Read token must be valid for all block IDs
 		for _, blockID := range command.BlockIDs {
-			isValid, _ := ws.auth.IsValidReadToken(container, blockID, command.ReadToken)
+			isValid, _ := ws.auth.IsValidReadToken(context.Background(), container, blockID, command.ReadToken)
 			if !isValid {
 				return false
 			}
@@ -290,6 +554,7 @@ func (ws *Server) addListener(client *wsClient) {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
 	ws.listeners[client] = true
+	ws.instrumentation.IncrementWebSocketConnections()
 }
 
 // removeListener removes a listener and all its subscriptions, if
@@ -310,7 +575,37 @@ func (ws *Server) removeListener(client *wsClient) {
 		ws.removeListenerFromBlock(client, block)
 	}
 
-	delete(ws.listeners, client)
+	if client.queue != nil {
+		client.queue.close()
+	}
+
+	// the listener may be removed twice when the connection fails
+	if ws.listeners[client] {
+		delete(ws.listeners, client)
+		ws.instrumentation.DecrementWebSocketConnections()
+	}
+}
+
+// Shutdown refuses the new connections, and sends the shutdown message to
+// the connected clients before closing their connection.
+func (ws *Server) Shutdown() {
+	ws.mu.Lock()
+	ws.shutdown = true
+	listeners := make([]*wsClient, 0, len(ws.listeners))
+	for listener := range ws.listeners {
+		listeners = append(listeners, listener)
+	}
+	ws.mu.Unlock()
+
+	message := ServerShutdownMsg{Action: websocketActionServerShutdown}
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down")
+	for _, listener := range listeners {
+		if err := listener.WriteJSON(message); err != nil {
+			ws.logger.Debug("send shutdown error", mlog.Err(err))
+		}
+		_ = listener.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(time.Second))
+		listener.Close()
+	}
 }
 
 // subscribeListenerToWorkspace safely modifies the listener and the
@@ -371,6 +666,43 @@ func (ws *Server) unsubscribeListenerFromBlocks(client *wsClient, blockIDs []str
 	}
 }
 
+// subscribeListenerToRoots safely modifies the listener to only get the
+// changes of the given boards from its workspace subscriptions.
+func (ws *Server) subscribeListenerToRoots(client *wsClient, rootIDs []string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	client.filterRoots = true
+	for _, rootID := range rootIDs {
+		if !client.isSubscribedToRoot(rootID) {
+			client.roots = append(client.roots, rootID)
+		}
+	}
+}
+
+// unsubscribeListenerFromRoots safely modifies the listener to stop
+// getting the changes of the given boards. The listener doesn't go back
+// to getting the changes of the whole workspaces.
+func (ws *Server) unsubscribeListenerFromRoots(client *wsClient, rootIDs []string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	newClientRoots := []string{}
+	for _, id := range client.roots {
+		keep := true
+		for _, rootID := range rootIDs {
+			if id == rootID {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			newClientRoots = append(newClientRoots, id)
+		}
+	}
+	client.roots = newClientRoots
+}
+
 // removeListenerFromWorkspace removes the listener from both its own
 // block subscribed list and the server listeners by workspace map.
 func (ws *Server) removeListenerFromWorkspace(client *wsClient, workspaceID string) {
@@ -456,6 +788,9 @@ func (ws *Server) authenticateListener(wsSession *websocketSession, token string
 
 	// Authenticated
 	wsSession.userID = userID
+	ws.mu.Lock()
+	wsSession.client.userID = userID
+	ws.mu.Unlock()
 	ws.logger.Debug("authenticateListener: Authenticated", mlog.String("userID", userID), mlog.Stringer("client", wsSession.client.RemoteAddr()))
 }
 
@@ -471,52 +806,250 @@ func (ws *Server) getListenersForWorkspace(workspaceID string) []*wsClient {
 	return ws.listenersByWorkspace[workspaceID]
 }
 
+// isListenerSubscribedToWorkspace safely checks the workspace
+// subscriptions of the listener.
+func (ws *Server) isListenerSubscribedToWorkspace(client *wsClient, workspaceID string) bool {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	return client.isSubscribedToWorkspace(workspaceID)
+}
+
+// getListenersForBoard returns the workspace listeners that get the
+// changes of a board, except the given one.
+func (ws *Server) getListenersForBoard(workspaceID, boardID string, except *wsClient) []*wsClient {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	board := model.Block{ID: boardID, RootID: boardID}
+	visibility := newBoardVisibility(ws.auth, workspaceID)
+	listeners := []*wsClient{}
+	for _, listener := range ws.getListenersForWorkspace(workspaceID) {
+		if listener != except && listener.wantsWorkspaceChange(board) && visibility.canView(listener.userID, boardID) {
+			listeners = append(listeners, listener)
+		}
+	}
+	return listeners
+}
+
+// GetBoardPresence returns the IDs of the users viewing a board.
+func (ws *Server) GetBoardPresence(workspaceID, boardID string) []string {
+	return ws.presence.boardViewers(workspaceID, boardID)
+}
+
+// broadcastPresence sends the presence messages to the workspace
+// listeners that get the changes of their boards, except to the
+// connection that caused them.
+func (ws *Server) broadcastPresence(messages []PresenceMsg, except *wsClient) {
+	for _, message := range messages {
+		for _, listener := range ws.getListenersForBoard(message.WorkspaceID, message.BoardID, except) {
+			if err := listener.WriteJSON(message); err != nil {
+				ws.logger.Error("broadcast presence error", mlog.Err(err))
+				listener.Close()
+			}
+		}
+	}
+}
+
+// GetBlockLockHolder returns the ID of the user holding the editing lock
+// of a block, or an empty string if it isn't locked.
+func (ws *Server) GetBlockLockHolder(workspaceID, blockID string) string {
+	return ws.locks.holder(workspaceID, blockID)
+}
+
+// broadcastBlockLocks sends the lock messages to the workspace listeners
+// that get the changes of their boards, except to the given connection.
+func (ws *Server) broadcastBlockLocks(messages []BlockLockMsg, except *wsClient) {
+	for _, message := range messages {
+		for _, listener := range ws.getListenersForBoard(message.WorkspaceID, message.BoardID, except) {
+			if err := listener.WriteJSON(message); err != nil {
+				ws.logger.Error("broadcast block lock error", mlog.Err(err))
+				listener.Close()
+			}
+		}
+	}
+}
+
 // BroadcastBlockDelete broadcasts delete messages to clients.
-func (ws *Server) BroadcastBlockDelete(workspaceID, blockID, parentID string) {
+func (ws *Server) BroadcastBlockDelete(workspaceID, blockID, parentID, rootID string) {
 	now := time.Now().Unix()
 	block := model.Block{}
 	block.ID = blockID
 	block.ParentID = parentID
+	block.RootID = rootID
 	block.UpdateAt = now
 	block.DeleteAt = now
 
 	ws.BroadcastBlockChange(workspaceID, block)
 }
 
-// BroadcastBlockChange broadcasts update messages to clients.
-func (ws *Server) BroadcastBlockChange(workspaceID string, block model.Block) {
-	blockIDsToNotify := []string{block.ID, block.ParentID}
+// BroadcastCardProgress sends the checklist progress of a card to the
+// workspace listeners that get the changes of its board.
+func (ws *Server) BroadcastCardProgress(workspaceID, boardID string, progress model.ChecklistProgress) {
+	message := newCardProgressMsg(workspaceID, boardID, progress)
+	for _, listener := range ws.getListenersForBoard(workspaceID, boardID, nil) {
+		if err := listener.WriteJSON(message); err != nil {
+			ws.logger.Error("broadcast card progress error", mlog.Err(err))
+			listener.Close()
+		}
+	}
+}
 
-	message := UpdateMsg{
-		Action: websocketActionUpdateBlock,
-		Block:  block,
+// BroadcastBoardArchived tells the workspace listeners that get the
+// changes of the board that it was archived.
+func (ws *Server) BroadcastBoardArchived(workspaceID, boardID string) {
+	message := newBoardArchivedMsg(workspaceID, boardID)
+	for _, listener := range ws.getListenersForBoard(workspaceID, boardID, nil) {
+		if err := listener.WriteJSON(message); err != nil {
+			ws.logger.Error("broadcast board archived error", mlog.Err(err))
+			listener.Close()
+		}
+	}
+}
+
+// getListenersForBlockChange returns the listeners that should get the
+// change of a block, each of them once: the workspace listeners
+// subscribed to its board, or to the whole workspace, that can view the
+// board, and the listeners of the block and its parent, which subscribed
+// with the read token of the board.
+func (ws *Server) getListenersForBlockChange(workspaceID string, block model.Block, visibility *boardVisibility) []*wsClient {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	seen := map[*wsClient]bool{}
+	listeners := []*wsClient{}
+	add := func(listener *wsClient) {
+		if !seen[listener] {
+			seen[listener] = true
+			listeners = append(listeners, listener)
+		}
 	}
 
-	listeners := ws.getListenersForWorkspace(workspaceID)
+	workspaceListeners := ws.getListenersForWorkspace(workspaceID)
+	for _, listener := range workspaceListeners {
+		if listener.wantsWorkspaceChange(block) && visibility.canView(listener.userID, block.BoardID()) {
+			add(listener)
+		}
+	}
 	ws.logger.Debug("listener(s) for workspaceID",
 		mlog.Int("listener_count", len(listeners)),
+		mlog.Int("workspace_listener_count", len(workspaceListeners)),
 		mlog.String("workspaceID", workspaceID),
 	)
 
-	for _, blockID := range blockIDsToNotify {
-		listeners = append(listeners, ws.getListenersForBlock(blockID)...)
+	for _, blockID := range []string{block.ID, block.ParentID} {
+		for _, listener := range ws.getListenersForBlock(blockID) {
+			add(listener)
+		}
 		ws.logger.Debug("listener(s) for blockID",
 			mlog.Int("listener_count", len(listeners)),
 			mlog.String("blockID", blockID),
 		)
 	}
 
-	for _, listener := range listeners {
-		ws.logger.Debug("Broadcast change",
-			mlog.String("workspaceID", workspaceID),
-			mlog.String("blockID", block.ID),
-			mlog.Stringer("remoteAddr", listener.RemoteAddr()),
-		)
+	return listeners
+}
 
-		err := listener.WriteJSON(message)
-		if err != nil {
-			ws.logger.Error("broadcast error", mlog.Err(err))
-			listener.Close()
+// BroadcastBlockChange queues update messages to clients, which are
+// coalesced and batched per client.
+func (ws *Server) BroadcastBlockChange(workspaceID string, block model.Block) {
+	ws.getReplayBuffer(workspaceID).record(block, func(sequence int64) {
+		listeners := ws.getListenersForBlockChange(workspaceID, block, newBoardVisibility(ws.auth, workspaceID))
+		for _, listener := range listeners {
+			ws.logger.Debug("Broadcast change",
+				mlog.String("workspaceID", workspaceID),
+				mlog.String("blockID", block.ID),
+				mlog.Int64("sequence", sequence),
+				mlog.Stringer("remoteAddr", listener.RemoteAddr()),
+			)
+
+			listener.queue.add(block, sequence)
+		}
+	})
+}
+
+// BroadcastBlockChanges sends the changes of the blocks to each client
+// in a single message, along with its pending changes.
+func (ws *Server) BroadcastBlockChanges(workspaceID string, blocks []model.Block) {
+	if len(blocks) == 0 {
+		return
+	}
+
+	ws.getReplayBuffer(workspaceID).recordBatch(blocks, func(sequence int64) {
+		visibility := newBoardVisibility(ws.auth, workspaceID)
+		listenerBlocks := map[*wsClient][]model.Block{}
+		listeners := []*wsClient{}
+		for _, block := range blocks {
+			for _, listener := range ws.getListenersForBlockChange(workspaceID, block, visibility) {
+				if _, ok := listenerBlocks[listener]; !ok {
+					listeners = append(listeners, listener)
+				}
+				listenerBlocks[listener] = append(listenerBlocks[listener], block)
+			}
+		}
+
+		for _, listener := range listeners {
+			ws.logger.Debug("Broadcast changes",
+				mlog.String("workspaceID", workspaceID),
+				mlog.Int("block_count", len(listenerBlocks[listener])),
+				mlog.Int64("sequence", sequence),
+				mlog.Stringer("remoteAddr", listener.RemoteAddr()),
+			)
+
+			listener.queue.addBatch(listenerBlocks[listener], sequence)
+		}
+	})
+}
+
+func (ws *Server) getReplayBuffer(workspaceID string) *replayBuffer {
+	ws.replayMu.Lock()
+	defer ws.replayMu.Unlock()
+
+	buffer, ok := ws.replayBuffers[workspaceID]
+	if !ok {
+		buffer = newReplayBuffer(ws.replayBufferSize)
+		ws.replayBuffers[workspaceID] = buffer
+	}
+	return buffer
+}
+
+// resumeListener sends the block changes of the workspace that the
+// client missed after the sequence, or tells it to fetch the blocks
+// again if they aren't all in the replay buffer anymore.
+func (ws *Server) resumeListener(client *wsClient, workspaceID string, sequence int64) {
+	blocks, newest, ok := ws.getReplayBuffer(workspaceID).since(sequence)
+	if !ok {
+		message := ResyncMsg{
+			Action:      websocketActionFullResyncRequired,
+			WorkspaceID: workspaceID,
+			Sequence:    newest,
 		}
+		if err := client.WriteJSON(message); err != nil {
+			ws.logger.Error("send resync error", mlog.Err(err))
+		}
+		return
+	}
+
+	ws.mu.RLock()
+	visibility := newBoardVisibility(ws.auth, workspaceID)
+	missed := []model.Block{}
+	for _, block := range blocks {
+		if client.wantsWorkspaceChange(block) && visibility.canView(client.userID, block.BoardID()) {
+			missed = append(missed, block)
+		}
+	}
+	ws.mu.RUnlock()
+
+	if len(missed) == 0 {
+		return
+	}
+
+	message := UpdateBlocksMsg{
+		Action:   websocketActionUpdateBlocks,
+		Blocks:   missed,
+		Sequence: newest,
+	}
+	if err := client.WriteJSON(message); err != nil {
+		ws.logger.Error("send replay error", mlog.Err(err))
 	}
 }
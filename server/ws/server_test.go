@@ -1,10 +1,16 @@
 package ws
 
 import (
-	"sync"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/mattermost/focalboard/server/auth"
+	"github.com/mattermost/focalboard/server/model"
+	"github.com/mattermost/focalboard/server/services/metrics"
 
 	"github.com/mattermost/mattermost-server/v6/shared/mlog"
 
@@ -13,8 +19,8 @@ import (
 )
 
 func TestWorkspaceSubscription(t *testing.T) {
-	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{})
-	client := &wsClient{&websocket.Conn{}, sync.Mutex{}, []string{}, []string{}}
+	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, metrics.NoopInstrumentation{})
+	client := &wsClient{Conn: &websocket.Conn{}, workspaces: []string{}, blocks: []string{}, roots: []string{}}
 	session := &websocketSession{client: client}
 	workspaceID := "fake-workspace-id"
 
@@ -99,8 +105,8 @@ func TestWorkspaceSubscription(t *testing.T) {
 }
 
 func TestBlocksSubscription(t *testing.T) {
-	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{})
-	client := &wsClient{&websocket.Conn{}, sync.Mutex{}, []string{}, []string{}}
+	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, metrics.NoopInstrumentation{})
+	client := &wsClient{Conn: &websocket.Conn{}, workspaces: []string{}, blocks: []string{}, roots: []string{}}
 	session := &websocketSession{client: client}
 	blockID1 := "block1"
 	blockID2 := "block2"
@@ -215,7 +221,7 @@ func TestBlocksSubscription(t *testing.T) {
 
 func TestGetUserIDForTokenInSingleUserMode(t *testing.T) {
 	singleUserToken := "single-user-token"
-	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{})
+	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, metrics.NoopInstrumentation{})
 	server.singleUserToken = singleUserToken
 
 	t.Run("Should return nothing if the token is empty", func(t *testing.T) {
@@ -230,3 +236,253 @@ func TestGetUserIDForTokenInSingleUserMode(t *testing.T) {
 		require.Equal(t, singleUserID, server.getUserIDForToken(singleUserToken))
 	})
 }
+
+func TestRootsSubscription(t *testing.T) {
+	server := NewServer(&auth.Auth{}, "token", false, &mlog.Logger{}, metrics.NoopInstrumentation{})
+	client := &wsClient{Conn: &websocket.Conn{}, workspaces: []string{}, blocks: []string{}, roots: []string{}}
+	card := model.Block{ID: "card", ParentID: "board1", RootID: "board1"}
+	board := model.Block{ID: "board1", RootID: "board1"}
+
+	t.Run("Should get the changes of the whole workspace before subscribing to a board", func(t *testing.T) {
+		require.True(t, client.wantsWorkspaceChange(card))
+		require.True(t, client.wantsWorkspaceChange(model.Block{ID: "other", RootID: "board2"}))
+	})
+
+	t.Run("Should only get the changes of the subscribed boards", func(t *testing.T) {
+		server.subscribeListenerToRoots(client, []string{"board1"})
+		server.subscribeListenerToRoots(client, []string{"board1"})
+
+		require.Equal(t, []string{"board1"}, client.roots)
+		require.True(t, client.wantsWorkspaceChange(card))
+		require.True(t, client.wantsWorkspaceChange(board))
+		require.False(t, client.wantsWorkspaceChange(model.Block{ID: "other", RootID: "board2"}))
+	})
+
+	t.Run("Should not go back to the whole workspace after unsubscribing", func(t *testing.T) {
+		server.unsubscribeListenerFromRoots(client, []string{"board1"})
+
+		require.Empty(t, client.roots)
+		require.False(t, client.wantsWorkspaceChange(card))
+	})
+}
+
+func TestBroadcastToBoardSubscribers(t *testing.T) {
+	logger := mlog.CreateConsoleTestLogger(false, mlog.LvlError)
+	server := NewServer(&auth.Auth{}, "token", false, logger, metrics.NoopInstrumentation{})
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	workspaceID := "workspace"
+	connect := func(rootIDs ...string) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: "token"}))
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionSubscribeWorkspace, WorkspaceID: workspaceID}))
+		if len(rootIDs) > 0 {
+			require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionSubscribeBlocks, WorkspaceID: workspaceID, RootIDs: rootIDs}))
+		}
+		return conn
+	}
+
+	board1Listener := connect("board1")
+	defer board1Listener.Close()
+	board2Listener := connect("board2")
+	defer board2Listener.Close()
+	workspaceListener := connect()
+	defer workspaceListener.Close()
+
+	// wait for the server to process the subscriptions
+	require.Eventually(t, func() bool {
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+
+		filtering := 0
+		for _, listener := range server.listenersByWorkspace[workspaceID] {
+			if len(listener.roots) > 0 {
+				filtering++
+			}
+		}
+		return len(server.listenersByWorkspace[workspaceID]) == 3 && filtering == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// reads the blocks of the single and batched updates, until count
+	// blocks were read
+	readBlockIDs := func(conn *websocket.Conn, count int) []string {
+		blockIDs := []string{}
+		for len(blockIDs) < count {
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			var message struct {
+				Action string        `json:"action"`
+				Block  model.Block   `json:"block"`
+				Blocks []model.Block `json:"blocks"`
+			}
+			require.NoError(t, conn.ReadJSON(&message))
+			switch message.Action {
+			case websocketActionUpdateBlock:
+				blockIDs = append(blockIDs, message.Block.ID)
+			case websocketActionUpdateBlocks:
+				blockIDs = append(blockIDs, blockIDsOf(message.Blocks)...)
+			default:
+				require.Fail(t, "unexpected action", message.Action)
+			}
+		}
+		return blockIDs
+	}
+
+	server.BroadcastBlockChange(workspaceID, model.Block{ID: "card1", ParentID: "board1", RootID: "board1"})
+	server.BroadcastBlockChange(workspaceID, model.Block{ID: "card2", ParentID: "board2", RootID: "board2"})
+	server.BroadcastBlockChange(workspaceID, model.Block{ID: "comment2", ParentID: "card2", RootID: "board2"})
+	server.BroadcastBlockDelete(workspaceID, "card3", "board1", "board1")
+
+	// a final change of both boards marks the end of the messages, so that
+	// no message was skipped
+	server.BroadcastBlockChange(workspaceID, model.Block{ID: "board1", RootID: "board1"})
+	server.BroadcastBlockChange(workspaceID, model.Block{ID: "board2", RootID: "board2"})
+
+	require.Equal(t, []string{"card1", "card3", "board1"}, readBlockIDs(board1Listener, 3))
+	require.Equal(t, []string{"card2", "comment2", "board2"}, readBlockIDs(board2Listener, 3))
+	require.Equal(t, []string{"card1", "card2", "comment2", "card3", "board1", "board2"}, readBlockIDs(workspaceListener, 6))
+}
+
+func TestResume(t *testing.T) {
+	logger := mlog.CreateConsoleTestLogger(false, mlog.LvlError)
+	server := NewServer(&auth.Auth{}, "token", false, logger, metrics.NoopInstrumentation{})
+	server.SetReplayBufferSize(3)
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	workspaceID := "workspace"
+	connect := func() *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionAuth, Token: "token"}))
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionSubscribeWorkspace, WorkspaceID: workspaceID}))
+		return conn
+	}
+
+	type message struct {
+		Action   string        `json:"action"`
+		Block    model.Block   `json:"block"`
+		Blocks   []model.Block `json:"blocks"`
+		Sequence int64         `json:"sequence"`
+	}
+	read := func(conn *websocket.Conn) message {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		var m message
+		require.NoError(t, conn.ReadJSON(&m))
+		return m
+	}
+
+	// waits for the server to process the subscriptions and the
+	// disconnections
+	waitForListeners := func(count int) {
+		require.Eventually(t, func() bool {
+			server.mu.RLock()
+			defer server.mu.RUnlock()
+			return len(server.listenersByWorkspace[workspaceID]) == count
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	conn := connect()
+	waitForListeners(1)
+
+	server.BroadcastBlockChange(workspaceID, model.Block{ID: "card1", RootID: "board"})
+	m := read(conn)
+	require.Equal(t, websocketActionUpdateBlock, m.Action)
+	require.Equal(t, int64(1), m.Sequence)
+	require.NoError(t, conn.Close())
+	waitForListeners(0)
+
+	// the changes while the client is disconnected
+	server.BroadcastBlockChange(workspaceID, model.Block{ID: "card2", RootID: "board"})
+	server.BroadcastBlockChange(workspaceID, model.Block{ID: "card3", RootID: "board"})
+
+	t.Run("Should replay the missed changes", func(t *testing.T) {
+		conn := connect()
+		defer func() {
+			require.NoError(t, conn.Close())
+			waitForListeners(0)
+		}()
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionResume, WorkspaceID: workspaceID, Sequence: 1}))
+
+		m := read(conn)
+		require.Equal(t, websocketActionUpdateBlocks, m.Action)
+		require.Equal(t, []string{"card2", "card3"}, blockIDsOf(m.Blocks))
+		require.Equal(t, int64(3), m.Sequence)
+	})
+
+	t.Run("Should replay nothing at the newest sequence", func(t *testing.T) {
+		conn := connect()
+		defer func() {
+			require.NoError(t, conn.Close())
+			waitForListeners(0)
+		}()
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionResume, WorkspaceID: workspaceID, Sequence: 3}))
+		waitForListeners(1)
+
+		// the next message is the next change
+		server.BroadcastBlockChange(workspaceID, model.Block{ID: "card4", RootID: "board"})
+		m := read(conn)
+		require.Equal(t, websocketActionUpdateBlock, m.Action)
+		require.Equal(t, "card4", m.Block.ID)
+		require.Equal(t, int64(4), m.Sequence)
+	})
+
+	t.Run("Should require a resync once the buffer wrapped around", func(t *testing.T) {
+		conn := connect()
+		defer func() {
+			require.NoError(t, conn.Close())
+			waitForListeners(0)
+		}()
+		require.NoError(t, conn.WriteJSON(WebsocketCommand{Action: websocketActionResume, WorkspaceID: workspaceID, Sequence: 0}))
+
+		m := read(conn)
+		require.Equal(t, websocketActionFullResyncRequired, m.Action)
+		require.Equal(t, int64(4), m.Sequence)
+	})
+}
+
+func TestShutdown(t *testing.T) {
+	logger := mlog.CreateConsoleTestLogger(false, mlog.LvlError)
+	server := NewServer(&auth.Auth{}, "token", false, logger, metrics.NoopInstrumentation{})
+	router := mux.NewRouter()
+	server.RegisterRoutes(router)
+	httpServer := httptest.NewServer(router)
+	defer httpServer.Close()
+
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Eventually(t, func() bool {
+		server.mu.RLock()
+		defer server.mu.RUnlock()
+		return len(server.listeners) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	server.Shutdown()
+
+	t.Run("Should send the shutdown message before closing", func(t *testing.T) {
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		var m ServerShutdownMsg
+		require.NoError(t, conn.ReadJSON(&m))
+		require.Equal(t, websocketActionServerShutdown, m.Action)
+
+		_, _, err := conn.ReadMessage()
+		require.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+	})
+
+	t.Run("Should refuse the new connections", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		require.Error(t, err)
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	})
+}
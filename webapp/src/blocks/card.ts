@@ -8,6 +8,9 @@ type CardFields = {
     isTemplate?: boolean
     properties: Record<string, string | string[]>
     contentOrder: Array<string | string[]>
+
+    // Number of the card in its board, set by the server
+    number?: number
 }
 
 type Card = Block & {
@@ -34,6 +37,7 @@ function createCard(block?: Block): Card {
             properties: {...(block?.fields.properties || {})},
             contentOrder,
             isTemplate: block?.fields.isTemplate || false,
+            ...(block?.fields.number ? {number: block.fields.number} : {}),
         },
     }
 }
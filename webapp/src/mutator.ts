@@ -594,6 +594,9 @@ class Mutator {
             }
         }
         newCard.fields.isTemplate = asTemplate
+
+        // The server numbers the new card
+        delete newCard.fields.number
         await this.insertBlocks(
             newBlocks,
             description,
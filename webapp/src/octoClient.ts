@@ -80,8 +80,20 @@ class OctoClient {
         return false
     }
 
-    logout() {
+    async logout(): Promise<boolean> {
+        const path = '/api/v1/logout'
+        const headers = this.headers()
         localStorage.removeItem('focalboardSessionId')
+        try {
+            const response = await fetch(this.getBaseURL() + path, {
+                method: 'POST',
+                headers,
+            })
+            return response.status === 200
+        } catch {
+            // The session is forgotten even if the server can't be reached
+            return false
+        }
     }
 
     async getClientConfig(): Promise<ClientConfig | null> {
@@ -204,6 +216,21 @@ class OctoClient {
         return this.getBlocksWithPath(path)
     }
 
+    // getBlocksPage gets a page of the child blocks of the parent, starting
+    // after the nextCursor of the previous page
+    async getBlocksPage(parentId: string, limit: number, after = ''): Promise<{blocks: Block[], nextCursor: string}> {
+        let path = this.workspacePath() + `/blocks?parent_id=${encodeURIComponent(parentId)}&limit=${limit}`
+        if (after) {
+            path += `&after=${encodeURIComponent(after)}`
+        }
+        const response = await fetch(this.getBaseURL() + path, {headers: this.headers()})
+        if (response.status !== 200) {
+            return {blocks: [], nextCursor: ''}
+        }
+        const page = (await this.getJson(response, {blocks: [], nextCursor: ''})) as {blocks: Block[], nextCursor: string}
+        return {blocks: this.fixBlocks(page.blocks), nextCursor: page.nextCursor}
+    }
+
     async getBlocksWithType(type: string): Promise<Block[]> {
         const path = this.workspacePath() + `/blocks?type=${encodeURIComponent(type)}`
         return this.getBlocksWithPath(path)
@@ -214,6 +241,27 @@ class OctoClient {
         return this.getBlocksWithPath(path)
     }
 
+    async getBlockHistory(blockId: string, limit = 0, descending = true): Promise<Block[]> {
+        let path = this.workspacePath() + `/blocks/${encodeURIComponent(blockId)}/history?descending=${descending}`
+        if (limit > 0) {
+            path += `&limit=${limit}`
+        }
+        return this.getBlocksWithPath(path)
+    }
+
+    async duplicateBoard(boardId: string, asTemplate: boolean): Promise<Block | undefined> {
+        const path = this.workspacePath() + `/blocks/${encodeURIComponent(boardId)}/duplicate?asTemplate=${asTemplate}`
+        const response = await fetch(this.getBaseURL() + path, {
+            method: 'POST',
+            headers: this.headers(),
+        })
+        if (response.status !== 200) {
+            return undefined
+        }
+        const board = (await this.getJson(response, {})) as Block
+        return this.fixBlocks([board])[0]
+    }
+
     private async getBlocksWithPath(path: string): Promise<Block[]> {
         const response = await fetch(this.getBaseURL() + path, {headers: this.headers()})
         if (response.status !== 200) {
@@ -417,13 +465,24 @@ class OctoClient {
     }
 
     async getUserWorkspaces(): Promise<UserWorkspace[]> {
-        const path = '/api/v1/workspaces'
-        const response = await fetch(this.getBaseURL() + path, {headers: this.headers()})
-        if (response.status !== 200) {
-            return []
-        }
+        const userWorkspaces: UserWorkspace[] = []
+        let cursor = ''
+        for (;;) {
+            const path = '/api/v1/workspaces?cursor=' + encodeURIComponent(cursor)
+            // eslint-disable-next-line no-await-in-loop
+            const response = await fetch(this.getBaseURL() + path, {headers: this.headers()})
+            if (response.status !== 200) {
+                return userWorkspaces
+            }
 
-        return (await this.getJson(response, [])) as UserWorkspace[]
+            // eslint-disable-next-line no-await-in-loop
+            const page = (await this.getJson(response, {workspaces: [], hasMore: false})) as {workspaces: UserWorkspace[], hasMore: boolean}
+            userWorkspaces.push(...page.workspaces)
+            if (!page.hasMore || page.workspaces.length === 0) {
+                return userWorkspaces
+            }
+            cursor = page.workspaces[page.workspaces.length - 1].id
+        }
     }
 }
 
@@ -17,10 +17,12 @@ type WSCommand = {
 type WSMessage = {
     action?: string
     block?: Block
+    blocks?: Block[]
     error?: string
 }
 
 export const ACTION_UPDATE_BLOCK = 'UPDATE_BLOCK'
+export const ACTION_UPDATE_BLOCKS = 'UPDATE_BLOCKS'
 export const ACTION_AUTH = 'AUTH'
 export const ACTION_SUBSCRIBE_BLOCKS = 'SUBSCRIBE_BLOCKS'
 export const ACTION_SUBSCRIBE_WORKSPACE = 'SUBSCRIBE_WORKSPACE'
@@ -225,6 +227,9 @@ class WSClient {
                 case ACTION_UPDATE_BLOCK:
                     this.updateBlockHandler(message)
                     break
+                case ACTION_UPDATE_BLOCKS:
+                    this.updateBlocksHandler(message)
+                    break
                 default:
                     Utils.logError(`Unexpected action: ${message.action}`)
                 }
@@ -242,6 +247,12 @@ class WSClient {
         this.queueUpdateNotification(Utils.fixBlock(message.block!))
     }
 
+    updateBlocksHandler(message: WSMessage): void {
+        for (const block of message.blocks || []) {
+            this.queueUpdateNotification(Utils.fixBlock(block))
+        }
+    }
+
     authenticate(workspaceId: string, token: string): void {
         if (!this.hasConn()) {
             Utils.assertFailure('WSClient.addBlocks: ws is not open')